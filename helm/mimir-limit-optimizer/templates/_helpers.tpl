@@ -84,4 +84,56 @@ Generate the docker image name
 {{- define "mimir-limit-optimizer.image" -}}
 {{- $tag := .Values.image.tag | default .Chart.AppVersion }}
 {{- printf "%s:%s" .Values.image.repository $tag }}
-{{- end }} 
\ No newline at end of file
+{{- end }} 
+{{/*
+Rules of the optimizer's ClusterRole, or of its Role in each scanned namespace with the
+namespaces scan scope
+*/}}
+{{- define "mimir-limit-optimizer.rbacRules" -}}
+# ConfigMap permissions for runtime overrides; empty per-tenant shards are deleted
+- apiGroups: [""]
+  resources: ["configmaps"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+
+# Service discovery permissions
+- apiGroups: [""]
+  resources: ["services", "endpoints"]
+  verbs: ["get", "list", "watch"]
+
+# Infrastructure and tenant namespace scans
+- apiGroups: [""]
+  resources: ["pods", "persistentvolumeclaims", "secrets", "resourcequotas"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["apps"]
+  resources: ["statefulsets", "daemonsets"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses"]
+  verbs: ["get", "list", "watch"]
+
+# Deployment management for triggering rollouts
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["get", "list", "watch", "update", "patch"]
+
+# Rollouts are deferred while a PodDisruptionBudget of the component allows no disruption
+- apiGroups: ["policy"]
+  resources: ["poddisruptionbudgets"]
+  verbs: ["get", "list"]
+
+# Leader election permissions
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+
+# Event creation for informational purposes, and the events of a resource in its
+# health detail
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["get", "list", "create", "patch"]
+
+# Live pod usage from metrics-server for the health dashboard
+- apiGroups: ["metrics.k8s.io"]
+  resources: ["pods"]
+  verbs: ["get", "list"]
+{{- end }}
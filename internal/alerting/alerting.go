@@ -2,7 +2,9 @@ package alerting
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -15,16 +17,38 @@ import (
 type AlertType string
 
 const (
-	AlertTypeCostViolation     AlertType = "cost_violation"
-	AlertTypeCircuitBreaker    AlertType = "circuit_breaker"
-	AlertTypePanicMode         AlertType = "panic_mode"
-	AlertTypeEmergency         AlertType = "emergency"
-	AlertTypeRecovery          AlertType = "recovery"
-	AlertTypeRecommendation    AlertType = "recommendation"
-	AlertTypeHealthCheck       AlertType = "health_check"
+	AlertTypeCostViolation      AlertType = "cost_violation"
+	AlertTypeCircuitBreaker     AlertType = "circuit_breaker"
+	AlertTypePanicMode          AlertType = "panic_mode"
+	AlertTypeEmergency          AlertType = "emergency"
+	AlertTypeRecovery           AlertType = "recovery"
+	AlertTypeRecommendation     AlertType = "recommendation"
+	AlertTypeHealthCheck        AlertType = "health_check"
 	AlertTypeConfigurationError AlertType = "configuration_error"
+	AlertTypeSpike              AlertType = "spike"
+	AlertTypeLimitChange        AlertType = "limit_change"
+	AlertTypeLimitDrift         AlertType = "limit_drift"
+	AlertTypeEmergencyFreeze    AlertType = "emergency_freeze"
+	AlertTypeLimitsNotLoaded    AlertType = "limits_not_loaded"
+	AlertTypeAnomaly            AlertType = "anomaly"
 )
 
+// ErrDuplicateAlert is returned by channels that suppressed an alert because an
+// identical one was already delivered within the channel's dedup window
+var ErrDuplicateAlert = errors.New("duplicate alert suppressed")
+
+// LimitChange describes a single limit update for a tenant
+type LimitChange struct {
+	Limit  string      `json:"limit"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+
+	// BufferPercent is the headroom applied on top of observed usage
+	BufferPercent float64 `json:"buffer_percent,omitempty"`
+	// Usage holds recent samples of the metric the limit is derived from, oldest first
+	Usage []float64 `json:"usage,omitempty"`
+}
+
 // Priority levels for alerts
 type Priority string
 
@@ -50,6 +74,14 @@ type Alert struct {
 	MaxRetries  int                    `json:"max_retries"`
 	CreatedAt   time.Time              `json:"created_at"`
 	LastAttempt time.Time              `json:"last_attempt"`
+
+	// DedupKey groups repeated alerts for the same condition into a single incident
+	DedupKey string `json:"dedup_key,omitempty"`
+	// Resolved marks the alert as clearing a previously triggered condition
+	Resolved bool `json:"resolved,omitempty"`
+
+	// escalated marks re-notifications raised by an escalation policy
+	escalated bool
 }
 
 // Channel represents an alerting channel
@@ -173,17 +205,26 @@ func (cb *ChannelCircuitBreaker) TryHalfOpen() bool {
 
 // Manager manages all alerting operations with fault tolerance
 type Manager struct {
-	config         *config.AlertingConfig
-	channels       map[string]Channel
+	config          *config.AlertingConfig
+	channels        map[string]Channel
 	circuitBreakers map[string]*ChannelCircuitBreaker
-	alertQueue     chan *Alert
-	retryQueue     chan *Alert
-	logger         logr.Logger
-	metrics        *metrics.AlertingMetrics
-	mu             sync.RWMutex
-	ctx            context.Context
-	cancel         context.CancelFunc
-	wg             sync.WaitGroup
+	alertQueue      chan *Alert
+	retryQueue      chan *Alert
+	logger          logr.Logger
+	metrics         *metrics.AlertingMetrics
+	mu              sync.RWMutex
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+	router          *Router
+	instances       *InstanceTracker
+	policies        map[string]config.EscalationPolicy
+
+	// deliveryObserver is told the outcome of every alert delivery
+	deliveryObserver func(err error)
+
+	// silences suppress matching alerts, such as during planned maintenance
+	silences *silencer
 }
 
 // NewManager creates a new alerting manager
@@ -200,6 +241,9 @@ func NewManager(config *config.AlertingConfig, logger logr.Logger) *Manager {
 		metrics:         metrics.AlertingMetricsInstance,
 		ctx:             ctx,
 		cancel:          cancel,
+		router:          &Router{defaultChannels: config.DefaultChannels},
+		instances:       NewInstanceTracker(),
+		silences:        newSilencer(),
 	}
 }
 
@@ -207,17 +251,33 @@ func NewManager(config *config.AlertingConfig, logger logr.Logger) *Manager {
 func (m *Manager) Start() error {
 	m.logger.Info("Starting alerting manager")
 	
+	m.mu.RLock()
+	cfg := m.config
+	m.mu.RUnlock()
+
 	// Initialize channels
-	if err := m.initializeChannels(); err != nil {
+	if err := m.initializeChannels(cfg); err != nil {
 		m.logger.Error(err, "Failed to initialize channels")
 		// Don't return error - continue with available channels
 	}
 	
+	// Compile routing rules
+	router, policies := m.compileRouting(cfg)
+	m.mu.Lock()
+	m.router = router
+	m.policies = policies
+	m.mu.Unlock()
+
+	// Restore the silences of planned maintenance
+	m.loadSilences()
+
 	// Start workers
-	m.wg.Add(3)
+	m.wg.Add(5)
 	go m.alertWorker()
 	go m.retryWorker()
 	go m.healthCheckWorker()
+	go m.escalationWorker()
+	go m.silenceWorker()
 	
 	m.logger.Info("Alerting manager started successfully")
 	return nil
@@ -262,13 +322,25 @@ func (m *Manager) SendAlert(alert *Alert) {
 
 // SendAlertSync sends an alert synchronously with timeout
 func (m *Manager) SendAlertSync(alert *Alert, timeout time.Duration) error {
+	if m.silenced(alert) {
+		return ErrAlertSilenced
+	}
+
 	ctx, cancel := context.WithTimeout(m.ctx, timeout)
 	defer cancel()
 	
+	route, channels := m.routeAlert(alert)
+	if len(channels) == 0 {
+		return fmt.Errorf("no alert channels configured")
+	}
+	if alert.RetryCount == 0 && !alert.escalated {
+		m.instances.Record(alert, route, channels)
+	}
+
 	var lastErr error
 	successCount := 0
 	
-	for _, channelName := range alert.Channels {
+	for _, channelName := range channels {
 		if err := m.sendToChannel(ctx, alert, channelName); err != nil {
 			lastErr = err
 			m.logger.Error(err, "Failed to send alert to channel",
@@ -288,54 +360,104 @@ func (m *Manager) SendAlertSync(alert *Alert, timeout time.Duration) error {
 	return lastErr
 }
 
+// Reload rebuilds the channels, routing rules and escalation policies after the
+// configuration was reloaded. Alert instances and their escalation state are kept.
+func (m *Manager) Reload(cfg *config.AlertingConfig) {
+	channels, circuitBreakers, err := m.buildChannels(cfg)
+	if err != nil {
+		m.logger.Error(err, "Failed to initialize channels on reload")
+	}
+	router, policies := m.compileRouting(cfg)
+
+	m.mu.Lock()
+	m.config = cfg
+	m.channels = channels
+	m.circuitBreakers = circuitBreakers
+	m.router = router
+	m.policies = policies
+	m.mu.Unlock()
+
+	m.logger.Info("Alerting configuration reloaded", "channels", len(channels))
+}
+
+// compileRouting compiles the routing rules and indexes the escalation policies. With
+// invalid routing rules only the default route is used.
+func (m *Manager) compileRouting(cfg *config.AlertingConfig) (*Router, map[string]config.EscalationPolicy) {
+	router, err := NewRouter(cfg)
+	if err != nil {
+		m.logger.Error(err, "Invalid alert routing rules, using default route only")
+		m.metrics.IncAlertConfigurationErrors("routing", "invalid_rule")
+		router = &Router{defaultChannels: cfg.DefaultChannels}
+	}
+	policies := make(map[string]config.EscalationPolicy, len(cfg.EscalationPolicies))
+	for _, policy := range cfg.EscalationPolicies {
+		policies[policy.Name] = policy
+	}
+	return router, policies
+}
+
 // initializeChannels initializes all configured channels
-func (m *Manager) initializeChannels() error {
+func (m *Manager) initializeChannels(cfg *config.AlertingConfig) error {
+	channels, circuitBreakers, err := m.buildChannels(cfg)
+
+	m.mu.Lock()
+	m.channels = channels
+	m.circuitBreakers = circuitBreakers
+	m.mu.Unlock()
+
+	return err
+}
+
+// buildChannels creates the channels enabled in the configuration, each with its own circuit breaker
+func (m *Manager) buildChannels(cfg *config.AlertingConfig) (map[string]Channel, map[string]*ChannelCircuitBreaker, error) {
+	channels := make(map[string]Channel)
+	circuitBreakers := make(map[string]*ChannelCircuitBreaker)
 	var errors []error
 	
 	// Initialize Slack channel
-	if m.config.Slack.Enabled {
-		slack := NewSlackChannel(m.config.Slack, m.logger.WithName("slack"))
+	if cfg.Slack.Enabled {
+		slack := NewSlackChannel(cfg.Slack, m.logger.WithName("slack"))
 		if err := slack.ValidateConfiguration(); err != nil {
 			m.logger.Error(err, "Invalid Slack configuration")
 			m.metrics.IncAlertConfigurationErrors("slack", "invalid_config")
 			errors = append(errors, err)
 		} else {
-			m.channels["slack"] = slack
-			m.circuitBreakers["slack"] = NewChannelCircuitBreaker(5, 5*time.Minute)
+			channels["slack"] = slack
+			circuitBreakers["slack"] = NewChannelCircuitBreaker(5, 5*time.Minute)
 			m.logger.Info("Slack channel initialized successfully")
 		}
 	}
 	
 	// Initialize PagerDuty channel
-	if m.config.PagerDuty.Enabled {
-		pagerduty := NewPagerDutyChannel(m.config.PagerDuty, m.logger.WithName("pagerduty"))
+	if cfg.PagerDuty.Enabled {
+		pagerduty := NewPagerDutyChannel(cfg.PagerDuty, m.logger.WithName("pagerduty"))
 		if err := pagerduty.ValidateConfiguration(); err != nil {
 			m.logger.Error(err, "Invalid PagerDuty configuration")
 			m.metrics.IncAlertConfigurationErrors("pagerduty", "invalid_config")
 			errors = append(errors, err)
 		} else {
-			m.channels["pagerduty"] = pagerduty
-			m.circuitBreakers["pagerduty"] = NewChannelCircuitBreaker(3, 5*time.Minute)
+			channels["pagerduty"] = pagerduty
+			circuitBreakers["pagerduty"] = NewChannelCircuitBreaker(3, 5*time.Minute)
 			m.logger.Info("PagerDuty channel initialized successfully")
 		}
 	}
 	
 	// Initialize Email channel
-	if m.config.Email.Enabled {
-		email := NewEmailChannel(m.config.Email, m.logger.WithName("email"))
+	if cfg.Email.Enabled {
+		email := NewEmailChannel(cfg.Email, m.logger.WithName("email"))
 		if err := email.ValidateConfiguration(); err != nil {
 			m.logger.Error(err, "Invalid Email configuration")
 			m.metrics.IncAlertConfigurationErrors("email", "invalid_config")
 			errors = append(errors, err)
 		} else {
-			m.channels["email"] = email
-			m.circuitBreakers["email"] = NewChannelCircuitBreaker(5, 10*time.Minute)
+			channels["email"] = email
+			circuitBreakers["email"] = NewChannelCircuitBreaker(5, 10*time.Minute)
 			m.logger.Info("Email channel initialized successfully")
 		}
 	}
 	
 	// Initialize Webhook channels
-	for i, webhookConfig := range m.config.Webhooks {
+	for i, webhookConfig := range cfg.Webhooks {
 		if webhookConfig.Enabled {
 			webhook := NewWebhookChannel(webhookConfig.Name, webhookConfig, m.logger.WithName("webhook").WithValues("name", webhookConfig.Name))
 			if err := webhook.ValidateConfiguration(); err != nil {
@@ -343,8 +465,8 @@ func (m *Manager) initializeChannels() error {
 				m.metrics.IncAlertConfigurationErrors("webhook_"+webhookConfig.Name, "invalid_config")
 				errors = append(errors, err)
 			} else {
-				m.channels["webhook_"+webhookConfig.Name] = webhook
-				m.circuitBreakers["webhook_"+webhookConfig.Name] = NewChannelCircuitBreaker(5, 5*time.Minute)
+				channels["webhook_"+webhookConfig.Name] = webhook
+				circuitBreakers["webhook_"+webhookConfig.Name] = NewChannelCircuitBreaker(5, 5*time.Minute)
 				m.logger.Info("Webhook channel initialized successfully", "webhook", webhookConfig.Name, "index", i)
 			}
 		}
@@ -352,14 +474,14 @@ func (m *Manager) initializeChannels() error {
 	
 	// Log summary
 	m.logger.Info("Channel initialization complete",
-		"total_channels", len(m.channels),
+		"total_channels", len(channels),
 		"errors", len(errors))
 	
 	if len(errors) > 0 {
-		return fmt.Errorf("failed to initialize %d channels", len(errors))
+		return channels, circuitBreakers, fmt.Errorf("failed to initialize %d channels", len(errors))
 	}
 	
-	return nil
+	return channels, circuitBreakers, nil
 }
 
 // alertWorker processes alerts from the main queue
@@ -436,19 +558,70 @@ func (m *Manager) healthCheckWorker() {
 	}
 }
 
+// escalationWorker re-notifies unacknowledged alerts according to their escalation policy
+func (m *Manager) escalationWorker() {
+	defer m.wg.Done()
+
+	m.logger.Info("Escalation worker started")
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.mu.RLock()
+			policies := m.policies
+			m.mu.RUnlock()
+			for _, escalation := range m.instances.dueEscalations(policies, time.Now()) {
+				m.logger.Info("Escalating unacknowledged alert",
+					"alert_id", escalation.ID,
+					"type", escalation.Type,
+					"channels", escalation.Channels)
+				m.SendAlert(escalation)
+			}
+
+		case <-m.ctx.Done():
+			m.logger.Info("Context cancelled, stopping escalation worker")
+			return
+		}
+	}
+}
+
+// SetDeliveryObserver registers a function told the outcome of every alert delivery:
+// nil when every channel received the alert, otherwise the channels that failed
+func (m *Manager) SetDeliveryObserver(observer func(err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveryObserver = observer
+}
+
 // processAlert processes a single alert
 func (m *Manager) processAlert(alert *Alert) {
 	alert.LastAttempt = time.Now()
 	
+	// Silenced alerts are not delivered; a resolved one still closes its instance
+	if m.silenced(alert) {
+		if alert.Resolved {
+			m.instances.Record(alert, nil, nil)
+		}
+		return
+	}
+
+	route, channels := m.routeAlert(alert)
+	if alert.RetryCount == 0 && !alert.escalated {
+		m.instances.Record(alert, route, channels)
+	}
+
 	m.logger.Info("Processing alert",
 		"alert_id", alert.ID,
 		"type", alert.Type,
 		"priority", alert.Priority,
-		"channels", alert.Channels)
+		"channels", channels)
 	
 	// Send to all configured channels
 	var failedChannels []string
-	for _, channelName := range alert.Channels {
+	for _, channelName := range channels {
 		ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
 		err := m.sendToChannel(ctx, alert, channelName)
 		cancel()
@@ -461,6 +634,17 @@ func (m *Manager) processAlert(alert *Alert) {
 		}
 	}
 	
+	m.mu.RLock()
+	observer := m.deliveryObserver
+	m.mu.RUnlock()
+	if observer != nil && len(channels) > 0 {
+		if len(failedChannels) > 0 {
+			observer(fmt.Errorf("failed to deliver alert %s to channels %s", alert.ID, strings.Join(failedChannels, ", ")))
+		} else {
+			observer(nil)
+		}
+	}
+
 	// Handle failed channels
 	if len(failedChannels) > 0 && alert.RetryCount < alert.MaxRetries {
 		alert.RetryCount++
@@ -486,6 +670,38 @@ func (m *Manager) processAlert(alert *Alert) {
 	}
 }
 
+// routeAlert returns the matching route and the channels an alert should be delivered
+// to. Channels named on the alert take precedence, then the first matching routing
+// rule, then the default channels, and finally every initialized channel.
+func (m *Manager) routeAlert(alert *Alert) (*Route, []string) {
+	if len(alert.Channels) > 0 {
+		return nil, alert.Channels
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if route := m.router.Match(alert); route != nil {
+		return route, route.Channels
+	}
+
+	if defaults := m.router.DefaultChannels(); len(defaults) > 0 {
+		return nil, defaults
+	}
+
+	channels := make([]string, 0, len(m.channels))
+	for name := range m.channels {
+		channels = append(channels, name)
+	}
+	return nil, channels
+}
+
+// PreviewRoute returns the route and channels an alert would be delivered to, without
+// sending it. The route is nil when the default channels apply.
+func (m *Manager) PreviewRoute(alert *Alert) (*Route, []string) {
+	return m.routeAlert(alert)
+}
+
 // sendToChannel sends an alert to a specific channel
 func (m *Manager) sendToChannel(ctx context.Context, alert *Alert, channelName string) error {
 	startTime := time.Now()
@@ -494,14 +710,16 @@ func (m *Manager) sendToChannel(ctx context.Context, alert *Alert, channelName s
 	m.metrics.SetAlertQueueSize(channelName, float64(len(m.alertQueue)))
 	
 	// Check if channel exists
+	m.mu.RLock()
 	channel, exists := m.channels[channelName]
+	cb := m.circuitBreakers[channelName]
+	m.mu.RUnlock()
 	if !exists {
 		m.metrics.IncAlertChannelErrors(channelName, "channel_not_found")
 		return fmt.Errorf("channel %s not found", channelName)
 	}
 	
 	// Check circuit breaker
-	cb := m.circuitBreakers[channelName]
 	if cb != nil && !cb.CanSend() {
 		m.metrics.IncAlertChannelErrors(channelName, "circuit_breaker_open")
 		m.metrics.SetAlertChannelCircuitBreakerState(channelName, float64(cb.GetState()))
@@ -517,6 +735,13 @@ func (m *Manager) sendToChannel(ctx context.Context, alert *Alert, channelName s
 	err := channel.Send(ctx, alert)
 	duration := time.Since(startTime).Seconds()
 	
+	// Suppressed duplicates are neither a delivery nor a channel failure
+	if errors.Is(err, ErrDuplicateAlert) {
+		m.metrics.IncAlertDeliveryTotal(channelName, string(alert.Type), "deduplicated")
+		m.metrics.IncAlertDeduplicated(channelName, string(alert.Type))
+		return nil
+	}
+
 	// Record metrics
 	if err != nil {
 		m.metrics.IncAlertDeliveryTotal(channelName, string(alert.Type), "failure")
@@ -566,6 +791,9 @@ func (m *Manager) processRetryQueue() {
 func (m *Manager) performHealthChecks() {
 	m.logger.V(1).Info("Performing channel health checks")
 	
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for name, channel := range m.channels {
 		healthy := channel.IsHealthy()
 		m.metrics.SetAlertChannelHealth(name, map[bool]float64{true: 1, false: 0}[healthy])
@@ -596,18 +824,42 @@ func (m *Manager) GetChannelStatus() map[string]interface{} {
 	return status
 }
 
+// GetActiveAlerts returns the alert instances that are firing or acknowledged
+func (m *Manager) GetActiveAlerts() []AlertInstance {
+	return m.instances.Active()
+}
+
+// GetAlertHistory returns resolved alert instances, most recent first
+func (m *Manager) GetAlertHistory() []AlertInstance {
+	return m.instances.History()
+}
+
+// AcknowledgeAlert acknowledges an active alert by instance ID or key, stopping its escalation
+func (m *Manager) AcknowledgeAlert(id, by string) (*AlertInstance, error) {
+	instance, err := m.instances.Acknowledge(id, by)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("Alert acknowledged",
+		"alert_id", instance.ID,
+		"key", instance.Key,
+		"acknowledged_by", by)
+	return instance, nil
+}
+
 // CreateAlert creates a new alert with default values
 func CreateAlert(alertType AlertType, priority Priority, title, message string) *Alert {
 	return &Alert{
-		ID:          fmt.Sprintf("%s-%d", alertType, time.Now().UnixNano()),
-		Type:        alertType,
-		Priority:    priority,
-		Title:       title,
-		Message:     message,
-		Timestamp:   time.Now(),
-		CreatedAt:   time.Now(),
-		MaxRetries:  3,
-		Details:     make(map[string]interface{}),
+		ID:         fmt.Sprintf("%s-%d", alertType, time.Now().UnixNano()),
+		Type:       alertType,
+		Priority:   priority,
+		Title:      title,
+		Message:    message,
+		Timestamp:  time.Now(),
+		CreatedAt:  time.Now(),
+		MaxRetries: 3,
+		Details:    make(map[string]interface{}),
 	}
 }
 
@@ -631,6 +883,50 @@ func CreateCostViolationAlert(tenant string, currentCost, budgetLimit float64, v
 	return alert
 }
 
+// CreateBudgetThresholdAlert creates an alert for spend crossing a percentage of a
+// budget. An empty tenant is the spend of all tenants.
+func CreateBudgetThresholdAlert(tenant, period string, spent, budget, thresholdPercent float64, currency string) *Alert {
+	subject := "all tenants"
+	if tenant != "" {
+		subject = fmt.Sprintf("tenant %s", tenant)
+	}
+
+	priority := PriorityP3
+	if thresholdPercent >= 90 {
+		priority = PriorityP2
+	}
+	alert := CreateAlert(AlertTypeCostViolation, priority,
+		fmt.Sprintf("%s budget %.0f%% used by %s", period, thresholdPercent, subject),
+		fmt.Sprintf("Spend of %s reached %.2f %s of its %s budget of %.2f %s",
+			subject, spent, currency, period, budget, currency))
+
+	alert.Tenant = tenant
+	alert.Details = map[string]interface{}{
+		"current_cost":      spent,
+		"budget_limit":      budget,
+		"violation_level":   period,
+		"threshold_percent": thresholdPercent,
+		"percentage":        spent / budget * 100,
+	}
+
+	return alert
+}
+
+// CreateResolvedAlert creates an alert that clears the condition previously raised
+// for the same alert type and tenant
+func CreateResolvedAlert(alertType AlertType, tenant, message string) *Alert {
+	title := fmt.Sprintf("Resolved: %s", alertType)
+	if tenant != "" {
+		title = fmt.Sprintf("Resolved: %s for tenant %s", alertType, tenant)
+	}
+
+	alert := CreateAlert(alertType, PriorityP1, title, message)
+	alert.Tenant = tenant
+	alert.Resolved = true
+
+	return alert
+}
+
 // CreatePanicModeAlert creates a panic mode alert
 func CreatePanicModeAlert(reason string, details map[string]interface{}) *Alert {
 	alert := CreateAlert(AlertTypePanicMode, PriorityP0,
@@ -642,14 +938,123 @@ func CreatePanicModeAlert(reason string, details map[string]interface{}) *Alert
 	return alert
 }
 
+// CreateSpikeAlert creates a spike detection alert
+func CreateSpikeAlert(tenant, metricName string, observed, baseline, multiplier float64) *Alert {
+	alert := CreateAlert(AlertTypeSpike, PriorityP2,
+		fmt.Sprintf("Usage spike detected for tenant %s", tenant),
+		fmt.Sprintf("Metric %s is at %.2f against a baseline of %.2f (%.2fx)",
+			metricName, observed, baseline, multiplier))
+
+	alert.Tenant = tenant
+	alert.Details = map[string]interface{}{
+		"metric":     metricName,
+		"observed":   observed,
+		"baseline":   baseline,
+		"multiplier": multiplier,
+	}
+
+	return alert
+}
+
+// CreateLimitChangeAlert creates an alert describing limit updates applied to a tenant
+func CreateLimitChangeAlert(tenant string, changes []LimitChange, reason string) *Alert {
+	alert := CreateAlert(AlertTypeLimitChange, PriorityP3,
+		fmt.Sprintf("Limits updated for tenant %s", tenant),
+		fmt.Sprintf("%d limit(s) updated for tenant %s (%s)", len(changes), tenant, reason))
+
+	alert.Tenant = tenant
+	alert.Details = map[string]interface{}{
+		"changes": changes,
+		"reason":  reason,
+	}
+
+	return alert
+}
+
+// CreateAnomalyAlert creates an informational alert for a tenant whose ingestion rate
+// deviates from the other tenants of its tier
+func CreateAnomalyAlert(tenant, tier string, rate, peerMean, zScore float64, peers int) *Alert {
+	group := fmt.Sprintf("tier %s", tier)
+	if tier == "" {
+		group = "tenants without a tier"
+	}
+	alert := CreateAlert(AlertTypeAnomaly, PriorityP3,
+		fmt.Sprintf("Anomalous usage for tenant %s", tenant),
+		fmt.Sprintf("Ingestion rate of %.2f samples/sec is %.1f standard deviations from the mean of %.2f across %d peers in %s",
+			rate, zScore, peerMean, peers, group))
+
+	alert.Tenant = tenant
+	alert.Details = map[string]interface{}{
+		"tier":      tier,
+		"rate":      rate,
+		"peer_mean": peerMean,
+		"z_score":   zScore,
+		"peers":     peers,
+	}
+
+	return alert
+}
+
+// CreateLimitDriftAlert creates an alert for limits that diverge between clusters
+func CreateLimitDriftAlert(secondary string, driftedLimits int, thresholdPercent float64, details map[string]interface{}) *Alert {
+	alert := CreateAlert(AlertTypeLimitDrift, PriorityP2,
+		fmt.Sprintf("Limit drift detected against %s", secondary),
+		fmt.Sprintf("%d tenant limit(s) differ from %s by more than %.1f%%", driftedLimits, secondary, thresholdPercent))
+
+	alert.Details = details
+
+	return alert
+}
+
+// CreateLimitsNotLoadedAlert creates an alert for ConfigMap limits Mimir has not loaded
+// within the grace period, so limit changes are not taking effect
+func CreateLimitsNotLoadedAlert(staleLimits int, gracePeriod time.Duration, details map[string]interface{}) *Alert {
+	alert := CreateAlert(AlertTypeLimitsNotLoaded, PriorityP2,
+		"Limit changes not loaded by Mimir",
+		fmt.Sprintf("%d tenant limit(s) in the runtime overrides ConfigMap differ from the limits Mimir has loaded for more than %s",
+			staleLimits, gracePeriod))
+
+	alert.Details = details
+
+	return alert
+}
+
+// CreateEmergencyFreezeAlert creates an alert announcing that limit changes are frozen
+func CreateEmergencyFreezeAlert(activatedBy, reason string, expiresAt *time.Time) *Alert {
+	message := "All automated limit changes are halted until the freeze is lifted"
+	if expiresAt != nil {
+		message = fmt.Sprintf("All automated limit changes are halted until %s", expiresAt.Format(time.RFC3339))
+	}
+
+	alert := CreateAlert(AlertTypeEmergencyFreeze, PriorityP1, "Emergency freeze activated", message)
+	alert.Details = map[string]interface{}{
+		"activated_by": activatedBy,
+		"reason":       reason,
+	}
+	if expiresAt != nil {
+		alert.Details["expires_at"] = expiresAt.Format(time.RFC3339)
+	}
+
+	return alert
+}
+
 // CreateCircuitBreakerAlert creates a circuit breaker alert
 func CreateCircuitBreakerAlert(tenant string, blastType string, details map[string]interface{}) *Alert {
-	alert := CreateAlert(AlertTypeCircuitBreaker, PriorityP1,
-		fmt.Sprintf("Circuit breaker activated for tenant %s", tenant),
+	title := fmt.Sprintf("Circuit breaker activated for tenant %s", tenant)
+	if tenant == "" {
+		title = "Circuit breaker activated"
+	}
+	alert := CreateAlert(AlertTypeCircuitBreaker, PriorityP1, title,
 		fmt.Sprintf("Circuit breaker has been activated due to %s blast detection", blastType))
 	
 	alert.Tenant = tenant
 	alert.Details = details
+	if alert.Details == nil {
+		alert.Details = make(map[string]interface{})
+	}
+	if _, exists := alert.Details["blast_type"]; !exists {
+		alert.Details["blast_type"] = blastType
+	}
 	
 	return alert
 } 
\ No newline at end of file
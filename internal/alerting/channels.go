@@ -3,222 +3,57 @@ package alerting
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"net/smtp"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/go-logr/logr"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 )
 
-// SlackChannel implements the Channel interface for Slack
-type SlackChannel struct {
-	config *config.SlackConfig
-	logger logr.Logger
-	client *http.Client
-}
-
-// NewSlackChannel creates a new Slack channel
-func NewSlackChannel(config config.SlackConfig, logger logr.Logger) *SlackChannel {
-	return &SlackChannel{
-		config: &config,
-		logger: logger,
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
 	}
+	return text[:max-3] + "..."
 }
 
-func (s *SlackChannel) Name() string {
-	return "slack"
-}
-
-func (s *SlackChannel) ValidateConfiguration() error {
-	if !s.config.Enabled {
-		return fmt.Errorf("slack channel is disabled")
-	}
-	if s.config.WebhookURL == "" {
-		return fmt.Errorf("slack webhook URL is required")
-	}
-	if s.config.Channel == "" {
-		return fmt.Errorf("slack channel is required")
-	}
-	return nil
-}
-
-func (s *SlackChannel) GetConfiguration() interface{} {
-	// Return safe configuration (without sensitive data)
-	return map[string]interface{}{
-		"enabled":  s.config.Enabled,
-		"channel":  s.config.Channel,
-		"username": s.config.Username,
-		"timeout":  s.config.Timeout,
-	}
-}
-
-func (s *SlackChannel) IsHealthy() bool {
-	// Perform a simple health check without sending an actual alert
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://slack.com/api/api.test", nil)
-	if err != nil {
-		s.logger.V(1).Info("Slack health check failed - request creation", "error", err)
-		return false
-	}
-	
-	resp, err := s.client.Do(req)
-	if err != nil {
-		s.logger.V(1).Info("Slack health check failed - request execution", "error", err)
-		return false
-	}
-	defer func() { _ = resp.Body.Close() }()
-	
-	return resp.StatusCode == http.StatusOK
-}
-
-func (s *SlackChannel) Send(ctx context.Context, alert *Alert) error {
-	if !s.config.Enabled {
-		return fmt.Errorf("slack channel is disabled")
-	}
-	
-	payload := s.buildSlackPayload(alert)
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		s.logger.Error(err, "Failed to marshal Slack payload", "alert_id", alert.ID)
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-	
-	req, err := http.NewRequestWithContext(ctx, "POST", s.config.WebhookURL, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		s.logger.Error(err, "Failed to create Slack request", "alert_id", alert.ID)
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	
-	req.Header.Set("Content-Type", "application/json")
-	
-	startTime := time.Now()
-	resp, err := s.client.Do(req)
-	duration := time.Since(startTime)
-	
-	if err != nil {
-		s.logger.Error(err, "Failed to send Slack alert", 
-			"alert_id", alert.ID,
-			"duration", duration)
-				return fmt.Errorf("failed to send request: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		s.logger.Error(fmt.Errorf("slack returned non-200 status"), 
-			"Failed to send Slack alert",
-			"alert_id", alert.ID,
-			"status_code", resp.StatusCode,
-			"duration", duration)
-		return fmt.Errorf("slack returned status %d", resp.StatusCode)
-	}
-	
-	s.logger.Info("Slack alert sent successfully", 
-		"alert_id", alert.ID,
-		"channel", s.config.Channel,
-		"duration", duration)
-	
-	return nil
-}
-
-func (s *SlackChannel) buildSlackPayload(alert *Alert) map[string]interface{} {
-	color := s.getColorForPriority(alert.Priority)
-	
-	attachment := map[string]interface{}{
-		"color":     color,
-		"title":     alert.Title,
-		"text":      alert.Message,
-		"timestamp": alert.Timestamp.Unix(),
-		"fields": []map[string]interface{}{
-			{
-				"title": "Priority",
-				"value": string(alert.Priority),
-				"short": true,
-			},
-			{
-				"title": "Type",
-				"value": string(alert.Type),
-				"short": true,
-			},
-		},
-	}
-	
-	if alert.Tenant != "" {
-		fields := attachment["fields"].([]map[string]interface{})
-		fields = append(fields, map[string]interface{}{
-			"title": "Tenant",
-			"value": alert.Tenant,
-			"short": true,
-		})
-		attachment["fields"] = fields
-	}
-	
-	// Add details as fields
-	if len(alert.Details) > 0 {
-		fields := attachment["fields"].([]map[string]interface{})
-		for key, value := range alert.Details {
-			// Use cases.Title instead of deprecated strings.Title
-			caser := cases.Title(language.English)
-			fields = append(fields, map[string]interface{}{
-				"title": caser.String(strings.ReplaceAll(key, "_", " ")),
-				"value": fmt.Sprintf("%v", value),
-				"short": true,
-			})
-		}
-		attachment["fields"] = fields
-	}
-	
-	payload := map[string]interface{}{
-		"channel":     s.config.Channel,
-		"username":    s.config.Username,
-		"icon_emoji":  ":warning:",
-		"attachments": []map[string]interface{}{attachment},
-	}
-	
-	return payload
-}
-
-func (s *SlackChannel) getColorForPriority(priority Priority) string {
-	switch priority {
-	case PriorityP0:
-		return "danger"
-	case PriorityP1:
-		return "warning"
-	case PriorityP2:
-		return "good"
-	case PriorityP3:
-		return "#439FE0"
-	default:
-		return "good"
-	}
-}
+// defaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const defaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
 
 // PagerDutyChannel implements the Channel interface for PagerDuty
 type PagerDutyChannel struct {
-	config *config.PagerDutyConfig
-	logger logr.Logger
-	client *http.Client
+	config       *config.PagerDutyConfig
+	logger       logr.Logger
+	client       *http.Client
+	retryBackoff time.Duration
 }
 
 // NewPagerDutyChannel creates a new PagerDuty channel
 func NewPagerDutyChannel(config config.PagerDutyConfig, logger logr.Logger) *PagerDutyChannel {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if config.EventsURL == "" {
+		config.EventsURL = defaultPagerDutyEventsURL
+	}
+	
 	return &PagerDutyChannel{
 		config: &config,
 		logger: logger,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: timeout,
 		},
+		retryBackoff: time.Second,
 	}
 }
 
@@ -238,8 +73,11 @@ func (p *PagerDutyChannel) ValidateConfiguration() error {
 
 func (p *PagerDutyChannel) GetConfiguration() interface{} {
 	return map[string]interface{}{
-		"enabled": p.config.Enabled,
-		"timeout": p.config.Timeout,
+		"enabled":     p.config.Enabled,
+		"severity":    p.config.Severity,
+		"timeout":     p.config.Timeout,
+		"events_url":  p.config.EventsURL,
+		"max_retries": p.config.MaxRetries,
 	}
 }
 
@@ -254,8 +92,9 @@ func (p *PagerDutyChannel) Send(ctx context.Context, alert *Alert) error {
 		return fmt.Errorf("pagerduty channel is disabled")
 	}
 	
-	// Only send P0 and P1 alerts to PagerDuty
-	if alert.Priority != PriorityP0 && alert.Priority != PriorityP1 {
+	// Only page for P0 and P1 alerts; resolve events always go through so that
+	// incidents opened earlier are closed once the condition clears
+	if !alert.Resolved && alert.Priority != PriorityP0 && alert.Priority != PriorityP1 {
 		p.logger.V(1).Info("Skipping non-critical alert for PagerDuty", 
 			"alert_id", alert.ID,
 			"priority", alert.Priority)
@@ -269,65 +108,148 @@ func (p *PagerDutyChannel) Send(ctx context.Context, alert *Alert) error {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 	
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://events.pagerduty.com/v2/enqueue", bytes.NewBuffer(payloadBytes))
+	var lastErr error
+	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := p.retryBackoff * time.Duration(1<<uint(attempt-1))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+		
+		startTime := time.Now()
+		retryable, err := p.postEvent(ctx, payloadBytes)
+		duration := time.Since(startTime)
+		
+		if err == nil {
+			p.logger.Info("PagerDuty event sent successfully", 
+				"alert_id", alert.ID,
+				"event_action", payload["event_action"],
+				"dedup_key", payload["dedup_key"],
+				"duration", duration)
+			return nil
+		}
+		
+		lastErr = err
+		p.logger.Error(err, "Failed to send PagerDuty event", 
+			"alert_id", alert.ID,
+			"event_action", payload["event_action"],
+			"dedup_key", payload["dedup_key"],
+			"attempt", attempt+1,
+			"retryable", retryable,
+			"duration", duration)
+		
+		if !retryable {
+			break
+		}
+	}
+	
+	return lastErr
+}
+
+// postEvent posts a single event and reports whether a failure may be retried
+func (p *PagerDutyChannel) postEvent(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.EventsURL, bytes.NewBuffer(payload))
 	if err != nil {
-		p.logger.Error(err, "Failed to create PagerDuty request", "alert_id", alert.ID)
-		return fmt.Errorf("failed to create request: %w", err)
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
 	
-	startTime := time.Now()
 	resp, err := p.client.Do(req)
-	duration := time.Since(startTime)
-	
 	if err != nil {
-		p.logger.Error(err, "Failed to send PagerDuty alert", 
-			"alert_id", alert.ID,
-			"duration", duration)
-		return fmt.Errorf("failed to send request: %w", err)
+		return false, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 	
-	if resp.StatusCode != http.StatusAccepted {
-		p.logger.Error(fmt.Errorf("pagerduty returned non-202 status"), 
-			"Failed to send PagerDuty alert",
-			"alert_id", alert.ID,
-			"status_code", resp.StatusCode,
-			"duration", duration)
-		return fmt.Errorf("pagerduty returned status %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("pagerduty returned status %d", resp.StatusCode)
+	default:
+		// Rejected events, e.g. an invalid routing key, are not retried
+		return false, fmt.Errorf("pagerduty returned status %d: %s", resp.StatusCode, pagerDutyErrorReason(resp.Body))
 	}
-	
-	p.logger.Info("PagerDuty alert sent successfully", 
-		"alert_id", alert.ID,
-		"duration", duration)
-	
-	return nil
+}
+
+// pagerDutyErrorReason returns the reason of a rejected event from the Events API
+// response, which carries a message and the list of validation errors
+func pagerDutyErrorReason(body io.Reader) string {
+	raw, _ := io.ReadAll(io.LimitReader(body, 1024))
+
+	var response struct {
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil || response.Message == "" {
+		return strings.TrimSpace(string(raw))
+	}
+	if len(response.Errors) == 0 {
+		return response.Message
+	}
+	return fmt.Sprintf("%s (%s)", response.Message, strings.Join(response.Errors, "; "))
 }
 
 func (p *PagerDutyChannel) buildPagerDutyPayload(alert *Alert) map[string]interface{} {
-	severity := p.getSeverityForPriority(alert.Priority)
+	dedupKey := pagerDutyDedupKey(alert)
 	
-	payload := map[string]interface{}{
+	if alert.Resolved {
+		return map[string]interface{}{
+			"routing_key":  p.config.IntegrationKey,
+			"event_action": "resolve",
+			"dedup_key":    dedupKey,
+		}
+	}
+	
+	customDetails := make(map[string]interface{}, len(alert.Details)+1)
+	for key, value := range alert.Details {
+		customDetails[key] = value
+	}
+	if alert.Tenant != "" {
+		customDetails["tenant"] = alert.Tenant
+	}
+	
+	return map[string]interface{}{
 		"routing_key":  p.config.IntegrationKey,
 		"event_action": "trigger",
-		"dedup_key":    fmt.Sprintf("mimir-limit-optimizer-%s", alert.ID),
+		"dedup_key":    dedupKey,
 		"payload": map[string]interface{}{
 			"summary":        alert.Title,
 			"source":         "mimir-limit-optimizer",
-			"severity":       severity,
+			"severity":       p.getSeverityForAlert(alert),
+			"timestamp":      alert.Timestamp.Format(time.RFC3339),
 			"component":      "mimir",
 			"group":          "limit-optimizer",
 			"class":          string(alert.Type),
-			"custom_details": alert.Details,
+			"custom_details": customDetails,
 		},
 	}
-	
+}
+
+// pagerDutyDedupKey returns a key that is stable per alert type and tenant, so that
+// repeated reconciles update the same incident rather than opening new ones
+func pagerDutyDedupKey(alert *Alert) string {
+	if alert.DedupKey != "" {
+		return alert.DedupKey
+	}
 	if alert.Tenant != "" {
-		payload["payload"].(map[string]interface{})["custom_details"].(map[string]interface{})["tenant"] = alert.Tenant
+		return fmt.Sprintf("mimir-limit-optimizer-%s-%s", alert.Type, alert.Tenant)
 	}
-	
-	return payload
+	return fmt.Sprintf("mimir-limit-optimizer-%s", alert.Type)
+}
+
+// getSeverityForAlert prefers an explicit internal severity in the alert details
+// and falls back to the alert priority
+func (p *PagerDutyChannel) getSeverityForAlert(alert *Alert) string {
+	if severity, ok := alert.Details["severity"].(string); ok {
+		if mapped := mapPagerDutySeverity(severity); mapped != "" {
+			return mapped
+		}
+	}
+	return p.getSeverityForPriority(alert.Priority)
 }
 
 func (p *PagerDutyChannel) getSeverityForPriority(priority Priority) string {
@@ -341,7 +263,26 @@ func (p *PagerDutyChannel) getSeverityForPriority(priority Priority) string {
 	case PriorityP3:
 		return "info"
 	default:
+		if mapped := mapPagerDutySeverity(p.config.Severity); mapped != "" {
+			return mapped
+		}
+		return "info"
+	}
+}
+
+// mapPagerDutySeverity maps internal severities to the PagerDuty severity levels
+func mapPagerDutySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical", "panic", "emergency":
+		return "critical"
+	case "high", "error":
+		return "error"
+	case "medium", "warning":
+		return "warning"
+	case "low", "info":
 		return "info"
+	default:
+		return ""
 	}
 }
 
@@ -349,6 +290,7 @@ func (p *PagerDutyChannel) getSeverityForPriority(priority Priority) string {
 type EmailChannel struct {
 	config *config.EmailConfig
 	logger logr.Logger
+	sender *SMTPSender
 }
 
 // NewEmailChannel creates a new Email channel
@@ -356,6 +298,7 @@ func NewEmailChannel(config config.EmailConfig, logger logr.Logger) *EmailChanne
 	return &EmailChannel{
 		config: &config,
 		logger: logger,
+		sender: NewSMTPSender(config),
 	}
 }
 
@@ -390,32 +333,23 @@ func (e *EmailChannel) GetConfiguration() interface{} {
 		"from":      e.config.From,
 		"to_count":  len(e.config.To),
 		"use_tls":   e.config.UseTLS,
+		"tls_mode":  e.sender.TLSMode(),
+		"digest":    e.config.Digest.Enabled,
 	}
 }
 
 func (e *EmailChannel) IsHealthy() bool {
-	// Try to connect to SMTP server
-	addr := fmt.Sprintf("%s:%d", e.config.SMTPHost, e.config.SMTPPort)
-	
-	var conn interface {
-		Close() error
-	}
-	var err error
-	
-	if e.config.UseTLS {
-		conn, err = tls.Dial("tcp", addr, &tls.Config{
-			ServerName: e.config.SMTPHost,
-		})
-	} else {
-		conn, err = smtp.Dial(addr)
-	}
+	// Try to connect to SMTP server, including TLS negotiation
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 	
+	client, err := e.sender.Dial(ctx)
 	if err != nil {
 		e.logger.V(1).Info("Email health check failed", "error", err)
 		return false
 	}
 	
-	defer func() { _ = conn.Close() }()
+	defer func() { _ = client.Close() }()
 	return true
 }
 
@@ -424,6 +358,15 @@ func (e *EmailChannel) Send(ctx context.Context, alert *Alert) error {
 		return fmt.Errorf("email channel is disabled")
 	}
 	
+	// With the daily digest enabled only critical alerts are emailed immediately;
+	// everything else is summarized from the audit log
+	if e.config.Digest.Enabled && alert.Priority != PriorityP0 {
+		e.logger.V(1).Info("Deferring non-critical alert to daily digest", 
+			"alert_id", alert.ID,
+			"priority", alert.Priority)
+		return nil
+	}
+	
 	startTime := time.Now()
 	
 	// Build email content
@@ -434,7 +377,7 @@ func (e *EmailChannel) Send(ctx context.Context, alert *Alert) error {
 	msg := e.buildEmailMessage(subject, body, alert)
 	
 	// Send email
-	err := e.sendEmail(msg)
+	err := e.sender.Send(ctx, "alert", msg)
 	duration := time.Since(startTime)
 	
 	if err != nil {
@@ -481,17 +424,9 @@ func (e *EmailChannel) buildEmailBody(alert *Alert) string {
 }
 
 func (e *EmailChannel) buildEmailMessage(subject, body string, alert *Alert) []byte {
-	var msg strings.Builder
-	
-	msg.WriteString(fmt.Sprintf("From: %s\r\n", e.config.From))
-	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(e.config.To, ",")))
-	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
-	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
-	msg.WriteString(fmt.Sprintf("X-Priority: %s\r\n", e.getEmailPriority(alert.Priority)))
-	msg.WriteString("\r\n")
-	msg.WriteString(body)
-	
-	return []byte(msg.String())
+	return e.sender.BuildMessage(subject, "text/plain", body, map[string]string{
+		"X-Priority": e.getEmailPriority(alert.Priority),
+	})
 }
 
 func (e *EmailChannel) getEmailPriority(priority Priority) string {
@@ -509,35 +444,76 @@ func (e *EmailChannel) getEmailPriority(priority Priority) string {
 	}
 }
 
-func (e *EmailChannel) sendEmail(msg []byte) error {
-	addr := fmt.Sprintf("%s:%d", e.config.SMTPHost, e.config.SMTPPort)
-	
-	var auth smtp.Auth
-	if e.config.Username != "" && e.config.Password != "" {
-		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.SMTPHost)
-	}
-	
-	return smtp.SendMail(addr, auth, e.config.From, e.config.To, msg)
+// Webhook retry defaults, used when the webhook configuration leaves them unset
+const (
+	defaultWebhookTimeout         = 10 * time.Second
+	defaultWebhookRetryBackoff    = time.Second
+	defaultWebhookMaxRetryBackoff = 30 * time.Second
+)
+
+// WebhookDelivery describes the outcome of the most recent webhook delivery
+type WebhookDelivery struct {
+	AlertID    string    `json:"alert_id"`
+	Success    bool      `json:"success"`
+	Attempts   int       `json:"attempts"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
 }
 
 // WebhookChannel implements the Channel interface for generic webhooks
 type WebhookChannel struct {
-	name   string
-	config *config.WebhookConfig
-	logger logr.Logger
-	client *http.Client
+	name     string
+	config   *config.WebhookConfig
+	logger   logr.Logger
+	client   *http.Client
+	template *template.Template
+	// templateErr is reported by ValidateConfiguration so a broken template disables the webhook
+	templateErr error
+
+	mu           sync.RWMutex
+	lastDelivery *WebhookDelivery
 }
 
 // NewWebhookChannel creates a new Webhook channel
 func NewWebhookChannel(name string, config config.WebhookConfig, logger logr.Logger) *WebhookChannel {
-	return &WebhookChannel{
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultWebhookRetryBackoff
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = defaultWebhookMaxRetryBackoff
+	}
+	
+	w := &WebhookChannel{
 		name:   name,
 		config: &config,
 		logger: logger,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: timeout,
 		},
 	}
+	
+	if config.PayloadTemplate != "" {
+		w.template, w.templateErr = template.New(name).Funcs(webhookTemplateFuncs).Parse(config.PayloadTemplate)
+	}
+	
+	return w
+}
+
+// webhookTemplateFuncs are available in webhook payload templates
+var webhookTemplateFuncs = template.FuncMap{
+	// json renders a value as a JSON literal, so strings are quoted and escaped
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
 }
 
 func (w *WebhookChannel) Name() string {
@@ -551,17 +527,37 @@ func (w *WebhookChannel) ValidateConfiguration() error {
 	if w.config.URL == "" {
 		return fmt.Errorf("webhook %s URL is required", w.name)
 	}
+	if w.templateErr != nil {
+		return fmt.Errorf("webhook %s payload template is invalid: %w", w.name, w.templateErr)
+	}
 	return nil
 }
 
 func (w *WebhookChannel) GetConfiguration() interface{} {
-	return map[string]interface{}{
-		"name":    w.name,
-		"enabled": w.config.Enabled,
-		"method":  w.config.Method,
-		"timeout": w.config.Timeout,
-		"headers": len(w.config.Headers),
+	configuration := map[string]interface{}{
+		"name":        w.name,
+		"enabled":     w.config.Enabled,
+		"method":      w.config.Method,
+		"timeout":     w.config.Timeout,
+		"headers":     len(w.config.Headers),
+		"max_retries": w.config.MaxRetries,
+		"templated":   w.template != nil,
+	}
+	if delivery := w.LastDelivery(); delivery != nil {
+		configuration["last_delivery"] = delivery
+	}
+	return configuration
+}
+
+// LastDelivery returns the outcome of the most recent delivery, or nil before the first one
+func (w *WebhookChannel) LastDelivery() *WebhookDelivery {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.lastDelivery == nil {
+		return nil
 	}
+	delivery := *w.lastDelivery
+	return &delivery
 }
 
 func (w *WebhookChannel) IsHealthy() bool {
@@ -593,29 +589,87 @@ func (w *WebhookChannel) IsHealthy() bool {
 	return resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound
 }
 
+// Send delivers the alert, retrying timed out and 429/5xx responses with exponential
+// backoff up to the configured number of retries
 func (w *WebhookChannel) Send(ctx context.Context, alert *Alert) error {
 	if !w.config.Enabled {
 		return fmt.Errorf("webhook %s is disabled", w.name)
 	}
 	
-	payload := w.buildWebhookPayload(alert)
-	payloadBytes, err := json.Marshal(payload)
+	payloadBytes, err := w.renderPayload(alert)
 	if err != nil {
-		w.logger.Error(err, "Failed to marshal webhook payload", 
+		w.logger.Error(err, "Failed to render webhook payload", 
 			"webhook", w.name, "alert_id", alert.ID)
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		w.recordDelivery(alert, 0, 0, err)
+		return fmt.Errorf("failed to render payload: %w", err)
+	}
+	
+	var lastErr error
+	var statusCode int
+	attempts := 0
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				w.recordDelivery(alert, attempts, statusCode, ctx.Err())
+				return ctx.Err()
+			case <-time.After(w.retryBackoff(attempt)):
+			}
+			metrics.AlertingMetricsInstance.IncAlertRetryAttempts(w.Name(), string(alert.Type))
+		}
+		
+		attempts++
+		startTime := time.Now()
+		var retryable bool
+		statusCode, retryable, err = w.post(ctx, payloadBytes)
+		duration := time.Since(startTime)
+		
+		if err == nil {
+			w.logger.Info("Webhook alert sent successfully", 
+				"webhook", w.name,
+				"alert_id", alert.ID,
+				"status_code", statusCode,
+				"attempt", attempts,
+				"duration", duration)
+			w.recordDelivery(alert, attempts, statusCode, nil)
+			return nil
+		}
+		
+		lastErr = err
+		w.logger.Error(err, "Failed to send webhook alert", 
+			"webhook", w.name,
+			"alert_id", alert.ID,
+			"status_code", statusCode,
+			"attempt", attempts,
+			"retryable", retryable,
+			"duration", duration)
+		
+		if !retryable {
+			break
+		}
 	}
 	
-	method := w.config.Method
-	if method == "" {
-		method = "POST"
+	w.recordDelivery(alert, attempts, statusCode, lastErr)
+	return fmt.Errorf("webhook %s gave up after %d attempt(s): %w", w.name, attempts, lastErr)
+}
+
+// retryBackoff returns the delay before the given retry attempt
+func (w *WebhookChannel) retryBackoff(attempt int) time.Duration {
+	backoff := w.config.RetryBackoff
+	for i := 1; i < attempt && backoff < w.config.MaxRetryBackoff; i++ {
+		backoff *= 2
 	}
-	
-	req, err := http.NewRequestWithContext(ctx, method, w.config.URL, bytes.NewBuffer(payloadBytes))
+	if backoff > w.config.MaxRetryBackoff {
+		backoff = w.config.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// post sends a single request and reports whether a failure may be retried
+func (w *WebhookChannel) post(ctx context.Context, payload []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, w.config.Method, w.config.URL, bytes.NewBuffer(payload))
 	if err != nil {
-		w.logger.Error(err, "Failed to create webhook request", 
-			"webhook", w.name, "alert_id", alert.ID)
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
 	}
 	
 	req.Header.Set("Content-Type", "application/json")
@@ -626,36 +680,58 @@ func (w *WebhookChannel) Send(ctx context.Context, alert *Alert) error {
 		req.Header.Set(key, value)
 	}
 	
-	startTime := time.Now()
 	resp, err := w.client.Do(req)
-	duration := time.Since(startTime)
-	
 	if err != nil {
-		w.logger.Error(err, "Failed to send webhook alert", 
-			"webhook", w.name,
-			"alert_id", alert.ID,
-			"duration", duration)
-				return fmt.Errorf("failed to send request: %w", err)
+		// Timeouts are retried unless the alert's own deadline has passed
+		var netErr net.Error
+		retryable := errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil
+		return 0, retryable, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.StatusCode, true, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	default:
+		return resp.StatusCode, false, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+}
 
-	if resp.StatusCode >= 400 {
-		w.logger.Error(fmt.Errorf("webhook returned error status"), 
-			"Failed to send webhook alert",
-			"webhook", w.name,
-			"alert_id", alert.ID,
-			"status_code", resp.StatusCode,
-			"duration", duration)
-		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+// recordDelivery keeps the outcome of a delivery for the channel status
+func (w *WebhookChannel) recordDelivery(alert *Alert, attempts, statusCode int, err error) {
+	delivery := &WebhookDelivery{
+		AlertID:    alert.ID,
+		Success:    err == nil,
+		Attempts:   attempts,
+		StatusCode: statusCode,
+		Timestamp:  time.Now(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
 	}
 	
-	w.logger.Info("Webhook alert sent successfully", 
-		"webhook", w.name,
-		"alert_id", alert.ID,
-		"status_code", resp.StatusCode,
-		"duration", duration)
+	w.mu.Lock()
+	w.lastDelivery = delivery
+	w.mu.Unlock()
+}
+
+// renderPayload renders the configured payload template, or the default payload when
+// there is none
+func (w *WebhookChannel) renderPayload(alert *Alert) ([]byte, error) {
+	if w.template == nil {
+		return json.Marshal(w.buildWebhookPayload(alert))
+	}
 	
-	return nil
+	var body bytes.Buffer
+	if err := w.template.Execute(&body, alert); err != nil {
+		return nil, err
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, fmt.Errorf("payload template rendered invalid JSON")
+	}
+	return body.Bytes(), nil
 }
 
 func (w *WebhookChannel) buildWebhookPayload(alert *Alert) map[string]interface{} {
@@ -675,6 +751,10 @@ func (w *WebhookChannel) buildWebhookPayload(alert *Alert) map[string]interface{
 		payload["tenant"] = alert.Tenant
 	}
 	
+	if alert.Resolved {
+		payload["resolved"] = true
+	}
+	
 	if len(alert.Details) > 0 {
 		payload["details"] = alert.Details
 	}
@@ -687,4 +767,4 @@ func (w *WebhookChannel) buildWebhookPayload(alert *Alert) map[string]interface{
 	}
 	
 	return payload
-} 
\ No newline at end of file
+}
@@ -0,0 +1,332 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// fakePagerDuty is an Events API v2 server that records the events it accepts and
+// answers the first requests with the queued statuses
+type fakePagerDuty struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	events   []map[string]interface{}
+}
+
+func (f *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	if len(f.statuses) > 0 {
+		status := f.statuses[0]
+		f.statuses = f.statuses[1:]
+		if status != http.StatusAccepted {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"status":"invalid event","message":"Event object is invalid","errors":["'routing_key' is invalid"]}`))
+			return
+		}
+	}
+
+	var event map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.events = append(f.events, event)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte(`{"status":"success","dedup_key":"` + event["dedup_key"].(string) + `"}`))
+}
+
+func newTestPagerDuty(t *testing.T, statuses ...int) (*PagerDutyChannel, *fakePagerDuty) {
+	fake := &fakePagerDuty{statuses: statuses}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	channel := NewPagerDutyChannel(config.PagerDutyConfig{
+		Enabled:        true,
+		IntegrationKey: "routing-key",
+		Severity:       "warning",
+		Timeout:        time.Second,
+		EventsURL:      server.URL,
+		MaxRetries:     2,
+	}, logr.Discard())
+	channel.retryBackoff = time.Millisecond
+	return channel, fake
+}
+
+func TestPagerDutyTriggerResolveLifecycle(t *testing.T) {
+	channel, fake := newTestPagerDuty(t)
+	ctx := context.Background()
+
+	emergency := CreateAlert(AlertTypeEmergency, PriorityP1, "Emergency mode", "ingestion spike")
+	emergency.Tenant = "tenant-a"
+	emergency.Details = map[string]interface{}{"severity": "panic"}
+	if err := channel.Send(ctx, emergency); err != nil {
+		t.Fatalf("trigger: %v", err)
+	}
+	// A later reconcile raises the same condition again
+	repeated := CreateAlert(AlertTypeEmergency, PriorityP1, "Emergency mode", "ingestion spike")
+	repeated.Tenant = "tenant-a"
+	if err := channel.Send(ctx, repeated); err != nil {
+		t.Fatalf("repeated trigger: %v", err)
+	}
+	if err := channel.Send(ctx, CreateResolvedAlert(AlertTypeEmergency, "tenant-a", "emergency mode exited")); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	if len(fake.events) != 3 {
+		t.Fatalf("PagerDuty received %d events, want 3", len(fake.events))
+	}
+	const dedupKey = "mimir-limit-optimizer-emergency-tenant-a"
+	for i, action := range []string{"trigger", "trigger", "resolve"} {
+		event := fake.events[i]
+		if event["event_action"] != action || event["dedup_key"] != dedupKey || event["routing_key"] != "routing-key" {
+			t.Errorf("event %d = action %v, dedup key %v, routing key %v; want %s, %s",
+				i, event["event_action"], event["dedup_key"], event["routing_key"], action, dedupKey)
+		}
+	}
+
+	payload := fake.events[0]["payload"].(map[string]interface{})
+	if payload["severity"] != "critical" {
+		t.Errorf("severity = %v, want the panic severity mapped to critical", payload["severity"])
+	}
+	if details := payload["custom_details"].(map[string]interface{}); details["tenant"] != "tenant-a" {
+		t.Errorf("custom details = %v, want the tenant", details)
+	}
+	if _, hasPayload := fake.events[2]["payload"]; hasPayload {
+		t.Errorf("resolve event has a payload: %v", fake.events[2])
+	}
+}
+
+func TestPagerDutyDedupKeyPerTenant(t *testing.T) {
+	a := CreateAlert(AlertTypeCostViolation, PriorityP0, "Budget exceeded", "")
+	a.Tenant = "tenant-a"
+	b := CreateAlert(AlertTypeCostViolation, PriorityP0, "Budget exceeded", "")
+	b.Tenant = "tenant-b"
+	panicMode := CreatePanicModeAlert("spike", nil)
+
+	if pagerDutyDedupKey(a) == pagerDutyDedupKey(b) {
+		t.Errorf("tenants share dedup key %q", pagerDutyDedupKey(a))
+	}
+	if got := pagerDutyDedupKey(panicMode); got != "mimir-limit-optimizer-panic_mode" {
+		t.Errorf("panic mode dedup key = %q", got)
+	}
+}
+
+func TestPagerDutyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{"retries rate limiting", []int{http.StatusTooManyRequests}, false, 2},
+		{"retries server errors", []int{http.StatusServiceUnavailable, http.StatusBadGateway}, false, 3},
+		{"gives up after the configured retries", []int{500, 500, 500}, true, 3},
+		{"does not retry a rejected event", []int{http.StatusBadRequest}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, fake := newTestPagerDuty(t, tt.statuses...)
+
+			err := channel.Send(context.Background(), CreatePanicModeAlert("spike", nil))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send error = %v, want error %v", err, tt.wantErr)
+			}
+			if fake.requests != tt.wantRequests {
+				t.Errorf("PagerDuty received %d requests, want %d", fake.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPagerDutySkipsNonCriticalAlerts(t *testing.T) {
+	channel, fake := newTestPagerDuty(t)
+
+	if err := channel.Send(context.Background(), CreateAlert(AlertTypeRecommendation, PriorityP3, "Recommendation", "")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if fake.requests != 0 {
+		t.Errorf("a P3 alert paged on-call")
+	}
+}
+
+func TestMapPagerDutySeverity(t *testing.T) {
+	for severity, want := range map[string]string{
+		"emergency": "critical",
+		"HIGH":      "error",
+		"medium":    "warning",
+		"low":       "info",
+		"unknown":   "",
+	} {
+		if got := mapPagerDutySeverity(severity); got != want {
+			t.Errorf("mapPagerDutySeverity(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+// fakeWebhook answers the first requests with the queued statuses and records the
+// requests it accepts
+type fakeWebhook struct {
+	mu       sync.Mutex
+	statuses []int
+	requests int
+	methods  []string
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	if len(f.statuses) > 0 {
+		status := f.statuses[0]
+		f.statuses = f.statuses[1:]
+		if status >= 300 {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	f.methods = append(f.methods, r.Method)
+	f.headers = append(f.headers, r.Header.Clone())
+	f.bodies = append(f.bodies, body)
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestWebhook(t *testing.T, webhook config.WebhookConfig, statuses ...int) (*WebhookChannel, *fakeWebhook) {
+	fake := &fakeWebhook{statuses: statuses}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	webhook.Enabled = true
+	webhook.URL = server.URL
+	webhook.RetryBackoff = time.Millisecond
+	webhook.MaxRetryBackoff = 4 * time.Millisecond
+	return NewWebhookChannel("incidents", webhook, logr.Discard()), fake
+}
+
+func TestWebhookRetriesUntilDelivered(t *testing.T) {
+	channel, fake := newTestWebhook(t, config.WebhookConfig{MaxRetries: 3},
+		http.StatusInternalServerError, http.StatusBadGateway)
+
+	alert := CreateLimitChangeAlert("tenant-a", []LimitChange{{Limit: "ingestion_rate", Before: 10000, After: 20000}}, "spike")
+	if err := channel.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if fake.requests != 3 {
+		t.Errorf("webhook received %d requests, want 2 failures and the delivery", fake.requests)
+	}
+
+	delivery := channel.LastDelivery()
+	if delivery == nil || !delivery.Success || delivery.Attempts != 3 || delivery.StatusCode != http.StatusOK {
+		t.Fatalf("last delivery = %+v, want success on attempt 3", delivery)
+	}
+	if delivery.AlertID != alert.ID {
+		t.Errorf("last delivery alert = %q, want %q", delivery.AlertID, alert.ID)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(fake.bodies[0], &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["tenant"] != "tenant-a" || payload["id"] != alert.ID {
+		t.Errorf("payload = %v, want the alert", payload)
+	}
+}
+
+func TestWebhookGivesUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+	}{
+		{"after the configured retries", []int{500, 503, 500, 500}, 3},
+		{"on rate limiting", []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests}, 3},
+		{"at once on a rejected alert", []int{http.StatusBadRequest}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, fake := newTestWebhook(t, config.WebhookConfig{MaxRetries: 2}, tt.statuses...)
+
+			if err := channel.Send(context.Background(), CreatePanicModeAlert("spike", nil)); err == nil {
+				t.Fatalf("Send succeeded against a failing endpoint")
+			}
+			if fake.requests != tt.wantRequests {
+				t.Errorf("webhook received %d requests, want %d", fake.requests, tt.wantRequests)
+			}
+			delivery := channel.LastDelivery()
+			if delivery == nil || delivery.Success || delivery.Attempts != tt.wantRequests || delivery.Error == "" {
+				t.Errorf("last delivery = %+v, want a failure after %d attempt(s)", delivery, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWebhookRequest(t *testing.T) {
+	channel, fake := newTestWebhook(t, config.WebhookConfig{
+		Method:          http.MethodPut,
+		Headers:         map[string]string{"Authorization": "Bearer token"},
+		PayloadTemplate: `{"summary": {{ json .Title }}, "tenant": {{ json .Tenant }}}`,
+	})
+
+	alert := CreateAlert(AlertTypeLimitChange, PriorityP2, `Limit "raised"`, "")
+	alert.Tenant = "tenant-a"
+	if err := channel.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if fake.methods[0] != http.MethodPut {
+		t.Errorf("method = %s, want the configured method", fake.methods[0])
+	}
+	if got := fake.headers[0].Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization header = %q, want the configured header", got)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(fake.bodies[0], &payload); err != nil {
+		t.Fatalf("templated payload is not JSON: %v (%s)", err, fake.bodies[0])
+	}
+	if payload["summary"] != `Limit "raised"` || payload["tenant"] != "tenant-a" {
+		t.Errorf("payload = %v, want the rendered template", payload)
+	}
+}
+
+func TestWebhookInvalidTemplate(t *testing.T) {
+	channel := NewWebhookChannel("incidents", config.WebhookConfig{
+		Enabled:         true,
+		URL:             "http://incidents.example",
+		PayloadTemplate: `{"summary": {{ .Title }`,
+	}, logr.Discard())
+
+	if err := channel.ValidateConfiguration(); err == nil {
+		t.Errorf("ValidateConfiguration accepted a broken payload template")
+	}
+}
+
+func TestWebhookRetryBackoffIsCapped(t *testing.T) {
+	channel := NewWebhookChannel("incidents", config.WebhookConfig{
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 5 * time.Second,
+	}, logr.Discard())
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := channel.retryBackoff(attempt); got != want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
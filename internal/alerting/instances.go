@@ -0,0 +1,268 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// Alert instance states
+const (
+	InstanceStatusFiring       = "firing"
+	InstanceStatusAcknowledged = "acknowledged"
+	InstanceStatusResolved     = "resolved"
+)
+
+// maxInstanceHistory bounds the number of resolved alert instances kept in memory
+const maxInstanceHistory = 500
+
+// ErrInstanceNotFound is returned when acknowledging an unknown alert instance
+var ErrInstanceNotFound = errors.New("alert instance not found")
+
+// AlertInstance tracks one alerting condition, identified by its key, across the
+// repeated alerts raised for it
+type AlertInstance struct {
+	ID               string     `json:"id"`
+	Key              string     `json:"key"`
+	Type             AlertType  `json:"type"`
+	Priority         Priority   `json:"priority"`
+	Severity         string     `json:"severity"`
+	Tenant           string     `json:"tenant,omitempty"`
+	Title            string     `json:"title"`
+	Message          string     `json:"message"`
+	Route            string     `json:"route,omitempty"`
+	Channels         []string   `json:"channels"`
+	EscalationPolicy string     `json:"escalation_policy,omitempty"`
+	EscalationLevel  int        `json:"escalation_level"`
+	Status           string     `json:"status"`
+	Count            int        `json:"count"`
+	FirstSeen        time.Time  `json:"first_seen"`
+	LastSeen         time.Time  `json:"last_seen"`
+	LastNotified     time.Time  `json:"last_notified"`
+	AcknowledgedAt   *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy   string     `json:"acknowledged_by,omitempty"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+
+	alert *Alert
+}
+
+// InstanceTracker keeps active and historical alert instances
+type InstanceTracker struct {
+	mu      sync.RWMutex
+	active  map[string]*AlertInstance
+	history []*AlertInstance
+}
+
+// NewInstanceTracker creates an empty instance tracker
+func NewInstanceTracker() *InstanceTracker {
+	return &InstanceTracker{
+		active: make(map[string]*AlertInstance),
+	}
+}
+
+// alertKey identifies the condition an alert belongs to
+func alertKey(alert *Alert) string {
+	if alert.DedupKey != "" {
+		return alert.DedupKey
+	}
+	if alert.Tenant != "" {
+		return fmt.Sprintf("%s/%s", alert.Type, alert.Tenant)
+	}
+	return string(alert.Type)
+}
+
+// Record registers an alert and returns its instance. Resolved alerts close the
+// active instance for their key and move it to the history.
+func (t *InstanceTracker) Record(alert *Alert, route *Route, channels []string) *AlertInstance {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := alertKey(alert)
+	now := time.Now()
+
+	if alert.Resolved {
+		instance, exists := t.active[key]
+		if !exists {
+			return nil
+		}
+		instance.Status = InstanceStatusResolved
+		instance.ResolvedAt = &now
+		instance.LastSeen = now
+		delete(t.active, key)
+		t.appendHistory(instance)
+		return instance
+	}
+
+	if instance, exists := t.active[key]; exists {
+		instance.Count++
+		instance.LastSeen = now
+		instance.Priority = alert.Priority
+		instance.Severity = AlertSeverity(alert)
+		instance.Title = alert.Title
+		instance.Message = alert.Message
+		instance.alert = alert
+		return instance
+	}
+
+	instance := &AlertInstance{
+		ID:           alert.ID,
+		Key:          key,
+		Type:         alert.Type,
+		Priority:     alert.Priority,
+		Severity:     AlertSeverity(alert),
+		Tenant:       alert.Tenant,
+		Title:        alert.Title,
+		Message:      alert.Message,
+		Channels:     channels,
+		Status:       InstanceStatusFiring,
+		Count:        1,
+		FirstSeen:    now,
+		LastSeen:     now,
+		LastNotified: now,
+		alert:        alert,
+	}
+	if route != nil {
+		instance.Route = route.Name
+		instance.EscalationPolicy = route.EscalationPolicy
+	}
+	t.active[key] = instance
+
+	return instance
+}
+
+// Acknowledge marks an active instance, looked up by ID or key, as acknowledged,
+// which stops further escalation
+func (t *InstanceTracker) Acknowledge(idOrKey, by string) (*AlertInstance, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, instance := range t.active {
+		if instance.ID != idOrKey && key != idOrKey {
+			continue
+		}
+		if instance.Status != InstanceStatusAcknowledged {
+			now := time.Now()
+			instance.Status = InstanceStatusAcknowledged
+			instance.AcknowledgedAt = &now
+			instance.AcknowledgedBy = by
+		}
+		copied := *instance
+		return &copied, nil
+	}
+
+	return nil, ErrInstanceNotFound
+}
+
+// Active returns the active instances, most recent first
+func (t *InstanceTracker) Active() []AlertInstance {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	instances := make([]AlertInstance, 0, len(t.active))
+	for _, instance := range t.active {
+		instances = append(instances, *instance)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].LastSeen.After(instances[j].LastSeen)
+	})
+	return instances
+}
+
+// History returns resolved instances, most recent first
+func (t *InstanceTracker) History() []AlertInstance {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	instances := make([]AlertInstance, 0, len(t.history))
+	for i := len(t.history) - 1; i >= 0; i-- {
+		instances = append(instances, *t.history[i])
+	}
+	return instances
+}
+
+// dueEscalations returns escalation alerts for unacknowledged instances whose next
+// level delay has elapsed, advancing each instance to that level
+func (t *InstanceTracker) dueEscalations(policies map[string]config.EscalationPolicy, now time.Time) []*Alert {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var escalations []*Alert
+	for _, instance := range t.active {
+		if instance.Status != InstanceStatusFiring || instance.EscalationPolicy == "" {
+			continue
+		}
+
+		policy, exists := policies[instance.EscalationPolicy]
+		if !exists || instance.EscalationLevel >= len(policy.Levels) {
+			continue
+		}
+
+		// The policy timeout bounds how long an instance keeps escalating
+		if policy.Timeout > 0 && now.Sub(instance.FirstSeen) > policy.Timeout {
+			continue
+		}
+
+		level := sortedLevels(policy)[instance.EscalationLevel]
+		if now.Sub(instance.LastNotified) < level.Delay {
+			continue
+		}
+
+		instance.EscalationLevel++
+		instance.LastNotified = now
+		instance.Channels = appendUnique(instance.Channels, level.Channels...)
+
+		escalation := *instance.alert
+		escalation.ID = fmt.Sprintf("%s-escalation-%d", instance.ID, instance.EscalationLevel)
+		escalation.Channels = level.Channels
+		escalation.RetryCount = 0
+		escalation.Title = fmt.Sprintf("[Escalation level %d] %s", instance.EscalationLevel, instance.Title)
+		escalation.Details = make(map[string]interface{}, len(instance.alert.Details)+2)
+		for key, value := range instance.alert.Details {
+			escalation.Details[key] = value
+		}
+		escalation.Details["escalation_level"] = instance.EscalationLevel
+		escalation.Details["escalation_policy"] = instance.EscalationPolicy
+		escalation.DedupKey = instance.Key
+		escalation.escalated = true
+
+		escalations = append(escalations, &escalation)
+	}
+
+	return escalations
+}
+
+func (t *InstanceTracker) appendHistory(instance *AlertInstance) {
+	t.history = append(t.history, instance)
+	if len(t.history) > maxInstanceHistory {
+		t.history = t.history[len(t.history)-maxInstanceHistory:]
+	}
+}
+
+// sortedLevels returns the policy levels ordered by level number
+func sortedLevels(policy config.EscalationPolicy) []config.EscalationLevel {
+	levels := make([]config.EscalationLevel, len(policy.Levels))
+	copy(levels, policy.Levels)
+	sort.SliceStable(levels, func(i, j int) bool { return levels[i].Level < levels[j].Level })
+	return levels
+}
+
+func appendUnique(values []string, additions ...string) []string {
+	result := append([]string{}, values...)
+	for _, addition := range additions {
+		found := false
+		for _, value := range result {
+			if value == addition {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, addition)
+		}
+	}
+	return result
+}
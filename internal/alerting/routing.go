@@ -0,0 +1,381 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// Condition is a compiled routing rule condition. Conditions are simple expressions
+// over alert fields, for example:
+//
+//	severity == "critical" && tenant =~ "prod-.*"
+//	type == "panic_mode" || (priority == "P1" && !(tenant == ""))
+//
+// Supported fields are severity, priority, type, tenant, title, message and
+// details.<key>. Operators are ==, !=, =~ and !~ (fully anchored regular
+// expressions), combined with &&, || and !. An empty condition matches every alert.
+type Condition interface {
+	Match(alert *Alert) bool
+}
+
+// Route is a routing rule with its compiled condition
+type Route struct {
+	Name             string
+	Condition        Condition
+	Channels         []string
+	EscalationPolicy string
+}
+
+// Router picks the destination channels for an alert from the configured routing rules
+type Router struct {
+	routes          []*Route
+	defaultChannels []string
+}
+
+// NewRouter compiles the routing rules of the alerting configuration
+func NewRouter(cfg *config.AlertingConfig) (*Router, error) {
+	router := &Router{defaultChannels: cfg.DefaultChannels}
+
+	for _, rule := range cfg.RoutingRules {
+		condition, err := ParseCondition(rule.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("routing rule %q: %w", rule.Name, err)
+		}
+		router.routes = append(router.routes, &Route{
+			Name:             rule.Name,
+			Condition:        condition,
+			Channels:         rule.Channels,
+			EscalationPolicy: rule.EscalationPolicy,
+		})
+	}
+
+	return router, nil
+}
+
+// ValidateRoutingRules checks that the condition of every routing rule compiles,
+// reporting all malformed rules at once
+func ValidateRoutingRules(cfg *config.AlertingConfig) error {
+	var errs []error
+	for _, rule := range cfg.RoutingRules {
+		if _, err := ParseCondition(rule.Condition); err != nil {
+			errs = append(errs, fmt.Errorf("alerting.routingRules[%s] condition %q: %w", rule.Name, rule.Condition, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Match returns the first route whose condition matches the alert, or nil when the
+// default route applies
+func (r *Router) Match(alert *Alert) *Route {
+	for _, route := range r.routes {
+		if route.Condition.Match(alert) {
+			return route
+		}
+	}
+	return nil
+}
+
+// DefaultChannels returns the channels of the default route
+func (r *Router) DefaultChannels() []string {
+	return r.defaultChannels
+}
+
+// AlertSeverity returns the internal severity of an alert, preferring an explicit
+// severity in the details and falling back to one derived from the priority
+func AlertSeverity(alert *Alert) string {
+	if severity, ok := alert.Details["severity"].(string); ok && severity != "" {
+		return severity
+	}
+
+	switch alert.Priority {
+	case PriorityP0:
+		return "critical"
+	case PriorityP1:
+		return "high"
+	case PriorityP2:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// alertField resolves a condition field against an alert
+func alertField(alert *Alert, field string) string {
+	switch field {
+	case "severity":
+		return AlertSeverity(alert)
+	case "priority":
+		return string(alert.Priority)
+	case "type":
+		return string(alert.Type)
+	case "tenant":
+		return alert.Tenant
+	case "title":
+		return alert.Title
+	case "message":
+		return alert.Message
+	}
+
+	if key := strings.TrimPrefix(field, "details."); key != field {
+		if value, exists := alert.Details[key]; exists && value != nil {
+			return fmt.Sprintf("%v", value)
+		}
+	}
+	return ""
+}
+
+// ParseCondition compiles a routing condition expression
+func ParseCondition(expression string) (Condition, error) {
+	if strings.TrimSpace(expression) == "" {
+		return matchAll{}, nil
+	}
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &conditionParser{tokens: tokens}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().value, p.peek().pos)
+	}
+	return condition, nil
+}
+
+type matchAll struct{}
+
+func (matchAll) Match(*Alert) bool { return true }
+
+type andCondition struct{ left, right Condition }
+
+func (c andCondition) Match(alert *Alert) bool { return c.left.Match(alert) && c.right.Match(alert) }
+
+type orCondition struct{ left, right Condition }
+
+func (c orCondition) Match(alert *Alert) bool { return c.left.Match(alert) || c.right.Match(alert) }
+
+type notCondition struct{ inner Condition }
+
+func (c notCondition) Match(alert *Alert) bool { return !c.inner.Match(alert) }
+
+type comparison struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (c comparison) Match(alert *Alert) bool {
+	actual := alertField(alert, c.field)
+	switch c.op {
+	case "==":
+		return actual == c.value
+	case "!=":
+		return actual != c.value
+	case "=~":
+		return c.re.MatchString(actual)
+	case "!~":
+		return !c.re.MatchString(actual)
+	default:
+		return false
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '"':
+			start := i
+			var value strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value.String(), pos: start})
+			i++
+		case strings.ContainsRune("=!~&|", r):
+			if i+1 < len(runes) {
+				op := string(runes[i : i+2])
+				switch op {
+				case "==", "!=", "=~", "!~", "&&", "||":
+					tokens = append(tokens, token{kind: tokenOperator, value: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if r == '!' {
+				tokens = append(tokens, token{kind: tokenOperator, value: "!", pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", string(r), i)
+		}
+	}
+
+	return tokens, nil
+}
+
+type conditionParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *conditionParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *conditionParser) peek() token { return p.tokens[p.pos] }
+
+func (p *conditionParser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *conditionParser) accept(kind tokenKind, value string) bool {
+	if !p.done() && p.peek().kind == kind && p.peek().value == value {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenOperator, "||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCondition{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (Condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokenOperator, "&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andCondition{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (Condition, error) {
+	if p.accept(tokenOperator, "!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner: inner}, nil
+	}
+
+	if p.accept(tokenLParen, "(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(tokenRParen, ")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (Condition, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.kind != tokenIdent {
+		return nil, fmt.Errorf("expected field name at position %d, got %q", field.pos, field.value)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if op.kind != tokenOperator || (op.value != "==" && op.value != "!=" && op.value != "=~" && op.value != "!~") {
+		return nil, fmt.Errorf("expected comparison operator at position %d, got %q", op.pos, op.value)
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if value.kind != tokenString {
+		return nil, fmt.Errorf("expected quoted string at position %d, got %q", value.pos, value.value)
+	}
+
+	c := comparison{field: field.value, op: op.value, value: value.value}
+	if op.value == "=~" || op.value == "!~" {
+		c.re, err = regexp.Compile("^(?:" + value.value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value.value, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,164 @@
+package alerting
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// sampleAlert is an alert of tenant with the priority and details
+func sampleAlert(alertType AlertType, priority Priority, tenant string, details map[string]interface{}) *Alert {
+	alert := CreateAlert(alertType, priority, "Sample alert", "sample message")
+	alert.Tenant = tenant
+	alert.Details = details
+	return alert
+}
+
+func TestConditionMatch(t *testing.T) {
+	prodCritical := sampleAlert(AlertTypeCostViolation, PriorityP0, "prod-payments", nil)
+	stagingCritical := sampleAlert(AlertTypeCostViolation, PriorityP0, "staging-payments", nil)
+	prodLow := sampleAlert(AlertTypeRecommendation, PriorityP3, "prod-payments", nil)
+	panicMode := sampleAlert(AlertTypePanicMode, PriorityP1, "", map[string]interface{}{"severity": "panic", "region": "eu-west-1"})
+
+	tests := []struct {
+		condition string
+		alert     *Alert
+		want      bool
+	}{
+		{`severity == "critical" && tenant =~ "prod-.*"`, prodCritical, true},
+		{`severity == "critical" && tenant =~ "prod-.*"`, stagingCritical, false},
+		{`severity == "critical" && tenant =~ "prod-.*"`, prodLow, false},
+		{`tenant =~ "prod"`, prodCritical, false}, // regular expressions are anchored
+		{`tenant !~ "prod-.*"`, stagingCritical, true},
+		{`priority == "P3" || type == "panic_mode"`, prodLow, true},
+		{`priority == "P3" || type == "panic_mode"`, panicMode, true},
+		{`priority == "P3" || type == "panic_mode"`, stagingCritical, false},
+		{`severity == "panic"`, panicMode, true},
+		{`details.region == "eu-west-1"`, panicMode, true},
+		{`details.missing == ""`, panicMode, true},
+		{`!(tenant == "")`, panicMode, false},
+		{`!(tenant == "")`, prodCritical, true},
+		{`tenant != "" && (priority == "P0" || priority == "P1")`, prodCritical, true},
+		{``, prodLow, true},
+	}
+	for _, tt := range tests {
+		condition, err := ParseCondition(tt.condition)
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", tt.condition, err)
+			continue
+		}
+		if got := condition.Match(tt.alert); got != tt.want {
+			t.Errorf("%q matched alert of %q (%s) = %v, want %v", tt.condition, tt.alert.Tenant, tt.alert.Priority, got, tt.want)
+		}
+	}
+}
+
+func TestParseConditionMalformed(t *testing.T) {
+	tests := []struct {
+		condition string
+		wantError string
+	}{
+		{`severity = "critical"`, "unexpected"},
+		{`severity == critical`, "expected quoted string"},
+		{`severity ==`, "unexpected end of expression"},
+		{`"critical" == severity`, "expected field name"},
+		{`severity == "critical" &&`, "unexpected end of expression"},
+		{`(severity == "critical"`, "missing closing parenthesis"},
+		{`severity == "critical")`, "unexpected"},
+		{`tenant == "prod`, "unterminated string"},
+		{`tenant =~ "prod-(.*"`, "invalid regular expression"},
+		{`severity == "critical" tenant == "a"`, "unexpected"},
+		{`severity < "critical"`, "unexpected"},
+	}
+	for _, tt := range tests {
+		_, err := ParseCondition(tt.condition)
+		if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+			t.Errorf("ParseCondition(%q) = %v, want an error containing %q", tt.condition, err, tt.wantError)
+		}
+	}
+}
+
+// routingConfig routes prod-tenant criticals to PagerDuty and panic mode to every
+// channel, and everything else to Slack
+func routingConfig() *config.AlertingConfig {
+	return &config.AlertingConfig{
+		DefaultChannels: []string{"slack"},
+		RoutingRules: []config.AlertRoutingRule{
+			{Name: "prod-critical", Condition: `severity == "critical" && tenant =~ "prod-.*"`, Channels: []string{"pagerduty"}},
+			{Name: "panic", Condition: `type == "panic_mode"`, Channels: []string{"pagerduty", "slack", "email"}},
+		},
+	}
+}
+
+func TestRouterSelectsChannels(t *testing.T) {
+	router, err := NewRouter(routingConfig())
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	manager := NewManager(routingConfig(), logr.Discard())
+	manager.router = router
+
+	tests := []struct {
+		name         string
+		alert        *Alert
+		wantRule     string
+		wantChannels []string
+	}{
+		{"prod critical pages", sampleAlert(AlertTypeCostViolation, PriorityP0, "prod-payments", nil),
+			"prod-critical", []string{"pagerduty"}},
+		{"first matching rule wins", sampleAlert(AlertTypePanicMode, PriorityP0, "prod-payments", nil),
+			"prod-critical", []string{"pagerduty"}},
+		{"panic mode goes everywhere", sampleAlert(AlertTypePanicMode, PriorityP1, "", nil),
+			"panic", []string{"pagerduty", "slack", "email"}},
+		{"staging critical falls through", sampleAlert(AlertTypeCostViolation, PriorityP0, "staging-payments", nil),
+			"", []string{"slack"}},
+		{"prod warning falls through", sampleAlert(AlertTypeLimitChange, PriorityP2, "prod-payments", nil),
+			"", []string{"slack"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, channels := manager.PreviewRoute(tt.alert)
+			rule := ""
+			if route != nil {
+				rule = route.Name
+			}
+			if rule != tt.wantRule || !reflect.DeepEqual(channels, tt.wantChannels) {
+				t.Errorf("routed to rule %q, channels %v; want rule %q, channels %v", rule, channels, tt.wantRule, tt.wantChannels)
+			}
+		})
+	}
+}
+
+func TestRouterWithoutDefaultChannelsUsesEveryChannel(t *testing.T) {
+	manager := NewManager(&config.AlertingConfig{}, logr.Discard())
+	manager.channels = map[string]Channel{
+		"slack": NewSlackChannel(config.SlackConfig{}, logr.Discard()),
+	}
+
+	if _, channels := manager.PreviewRoute(sampleAlert(AlertTypeLimitChange, PriorityP2, "tenant-a", nil)); !reflect.DeepEqual(channels, []string{"slack"}) {
+		t.Errorf("channels = %v, want every configured channel", channels)
+	}
+}
+
+func TestMalformedRoutingRulesRejected(t *testing.T) {
+	cfg := routingConfig()
+	cfg.RoutingRules = append(cfg.RoutingRules,
+		config.AlertRoutingRule{Name: "broken-regex", Condition: `tenant =~ "("`},
+		config.AlertRoutingRule{Name: "broken-syntax", Condition: `severity = "critical"`},
+	)
+
+	if _, err := NewRouter(cfg); err == nil || !strings.Contains(err.Error(), "broken-regex") {
+		t.Errorf("NewRouter = %v, want the first malformed rule reported", err)
+	}
+	err := ValidateRoutingRules(cfg)
+	if err == nil || !strings.Contains(err.Error(), "broken-regex") || !strings.Contains(err.Error(), "broken-syntax") {
+		t.Errorf("ValidateRoutingRules = %v, want both malformed rules reported", err)
+	}
+	if err := ValidateRoutingRules(routingConfig()); err != nil {
+		t.Errorf("ValidateRoutingRules of valid rules: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// DefaultRuleTenantLabel is the label Mimir puts the tenant ID in on per-tenant metrics
+const DefaultRuleTenantLabel = "user"
+
+// limitUsageRuleFor is how long usage must stay above a threshold before the alert fires
+const limitUsageRuleFor = "15m"
+
+// limitUsageThresholds are the shares of the current limit each generated rule alerts at
+var limitUsageThresholds = []struct {
+	percent  int
+	severity string
+}{
+	{80, "info"},
+	{90, "warning"},
+	{100, "critical"},
+}
+
+// alertRuleLimitTypes are the limit types whose value can be compared to their metric
+var alertRuleLimitTypes = map[string]bool{
+	"count": true,
+	"rate":  true,
+	"size":  true,
+}
+
+// PrometheusRuleFile is a Prometheus rule file
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `json:"groups"`
+}
+
+// PrometheusRuleGroup is a group of Prometheus rules evaluated together
+type PrometheusRuleGroup struct {
+	Name  string           `json:"name"`
+	Rules []PrometheusRule `json:"rules"`
+}
+
+// PrometheusRule is a Prometheus alerting rule
+type PrometheusRule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GenerateLimitAlertRules builds alerting rules that fire when a tenant's usage of a
+// limit stays above 80%, 90% and 100% of its current value. One group is generated per
+// enabled limit with a metric source. Tenants with an override are compared to their
+// own value; all other tenants are compared to the limit's default value.
+//
+// currentLimits maps tenants to their overridden limit values, as read from the
+// runtime overrides ConfigMap. tenantLabel is the metric label holding the tenant ID.
+func GenerateLimitAlertRules(definitions map[string]config.LimitDefinition, currentLimits map[string]map[string]interface{}, tenantLabel string) *PrometheusRuleFile {
+	if tenantLabel == "" {
+		tenantLabel = DefaultRuleTenantLabel
+	}
+
+	limitNames := make([]string, 0, len(definitions))
+	for name, definition := range definitions {
+		if definition.Enabled && definition.MetricSource != "" && alertRuleLimitTypes[definition.Type] {
+			limitNames = append(limitNames, name)
+		}
+	}
+	sort.Strings(limitNames)
+
+	tenants := make([]string, 0, len(currentLimits))
+	for tenant := range currentLimits {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	file := &PrometheusRuleFile{Groups: []PrometheusRuleGroup{}}
+	for _, limitName := range limitNames {
+		definition := definitions[limitName]
+		group := PrometheusRuleGroup{
+			Name:  "mimir-tenant-limits-" + strings.ReplaceAll(limitName, "_", "-"),
+			Rules: []PrometheusRule{},
+		}
+
+		var overridden []string
+		for _, tenant := range tenants {
+			value, ok := ruleLimitValue(currentLimits[tenant][limitName])
+			if !ok {
+				continue
+			}
+			overridden = append(overridden, tenant)
+			selector := fmt.Sprintf("%s=%s", tenantLabel, strconv.Quote(tenant))
+			group.Rules = append(group.Rules, limitUsageRules(limitName, definition, tenantLabel, selector, value, tenant)...)
+		}
+
+		if value, ok := ruleLimitValue(definition.DefaultValue.Interface()); ok {
+			selector := ""
+			if len(overridden) > 0 {
+				quoted := make([]string, len(overridden))
+				for i, tenant := range overridden {
+					quoted[i] = regexp.QuoteMeta(tenant)
+				}
+				selector = fmt.Sprintf("%s!~%s", tenantLabel, strconv.Quote(strings.Join(quoted, "|")))
+			}
+			group.Rules = append(group.Rules, limitUsageRules(limitName, definition, tenantLabel, selector, value, "")...)
+		}
+
+		if len(group.Rules) > 0 {
+			file.Groups = append(file.Groups, group)
+		}
+	}
+
+	return file
+}
+
+// limitUsageRules returns one rule per threshold comparing the limit's metric, for the
+// tenants matched by selector, to the limit value. tenant is empty for the rules
+// covering tenants on the default value.
+func limitUsageRules(limitName string, definition config.LimitDefinition, tenantLabel, selector string, value float64, tenant string) []PrometheusRule {
+	usage := definition.MetricSource
+	if selector != "" {
+		usage = fmt.Sprintf("%s{%s}", usage, selector)
+	}
+	if strings.HasSuffix(definition.MetricSource, "_total") || strings.Contains(limitName, "rate") {
+		usage = fmt.Sprintf("rate(%s[5m])", usage)
+	}
+	usage = fmt.Sprintf("sum by (%s) (%s)", tenantLabel, usage)
+
+	source := "override"
+	if tenant == "" {
+		source = "default"
+	}
+
+	rules := make([]PrometheusRule, 0, len(limitUsageThresholds))
+	for _, threshold := range limitUsageThresholds {
+		comparison := ">"
+		if threshold.percent == 100 {
+			comparison = ">="
+		}
+		rule := PrometheusRule{
+			Alert: fmt.Sprintf("MimirTenant%sAbove%dPercent", camelCase(limitName), threshold.percent),
+			Expr: fmt.Sprintf("%s %s %s", usage, comparison,
+				strconv.FormatFloat(value*float64(threshold.percent)/100, 'f', -1, 64)),
+			For: limitUsageRuleFor,
+			Labels: map[string]string{
+				"severity":     threshold.severity,
+				"limit":        limitName,
+				"limit_source": source,
+			},
+			Annotations: map[string]string{
+				"summary": fmt.Sprintf("Tenant {{ $labels.%s }} is using more than %d%% of %s", tenantLabel, threshold.percent, limitName),
+				"description": fmt.Sprintf("%s of tenant {{ $labels.%s }} is {{ $value | humanize }}, %d%% of the limit is %s (limit %s). %s",
+					definition.MetricSource, tenantLabel, threshold.percent,
+					strconv.FormatFloat(value*float64(threshold.percent)/100, 'f', -1, 64),
+					strconv.FormatFloat(value, 'f', -1, 64), definition.Description),
+			},
+		}
+		if tenant != "" {
+			rule.Labels["tenant"] = tenant
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// MarshalRuleFile encodes a rule file as "yaml" (the default) or "json"
+func MarshalRuleFile(file *PrometheusRuleFile, format string) ([]byte, error) {
+	switch format {
+	case "", "yaml":
+		return yaml.Marshal(file)
+	case "json":
+		return json.MarshalIndent(file, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported rule file format %q, must be yaml or json", format)
+	}
+}
+
+// ruleLimitValue returns a positive numeric limit value
+func ruleLimitValue(value interface{}) (float64, bool) {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case float32:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	case int64:
+		number = float64(v)
+	case int32:
+		number = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		number = parsed
+	default:
+		return 0, false
+	}
+	return number, number > 0
+}
+
+// camelCase turns a snake_case limit name into CamelCase for alert names
+func camelCase(name string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return builder.String()
+}
@@ -0,0 +1,183 @@
+package alerting
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ruleThreshold is the trailing comparison of a generated rule expression
+var ruleThreshold = regexp.MustCompile(`\) (>|>=) ([0-9.]+)$`)
+
+// validateRuleFile checks a rule file the way Prometheus does when loading it: group
+// names are unique and every rule has a valid alert name, duration, labels and an
+// expression with balanced brackets and quotes
+func validateRuleFile(t *testing.T, file *PrometheusRuleFile) {
+	t.Helper()
+	groups := make(map[string]bool)
+	for _, group := range file.Groups {
+		if group.Name == "" || groups[group.Name] {
+			t.Errorf("group name %q is empty or repeated", group.Name)
+		}
+		groups[group.Name] = true
+		if len(group.Rules) == 0 {
+			t.Errorf("group %s has no rules", group.Name)
+		}
+
+		for _, rule := range group.Rules {
+			if !model.IsValidMetricName(model.LabelValue(rule.Alert)) {
+				t.Errorf("invalid alert name %q", rule.Alert)
+			}
+			if _, err := model.ParseDuration(rule.For); err != nil {
+				t.Errorf("alert %s: invalid for %q: %v", rule.Alert, rule.For, err)
+			}
+			for name := range rule.Labels {
+				if !model.LabelName(name).IsValid() {
+					t.Errorf("alert %s: invalid label name %q", rule.Alert, name)
+				}
+			}
+			for name := range rule.Annotations {
+				if !model.LabelName(name).IsValid() {
+					t.Errorf("alert %s: invalid annotation name %q", rule.Alert, name)
+				}
+			}
+			if !balancedExpr(rule.Expr) {
+				t.Errorf("alert %s: unbalanced expression %q", rule.Alert, rule.Expr)
+			}
+		}
+	}
+}
+
+// balancedExpr reports whether the brackets of a PromQL expression are balanced outside
+// its quoted strings
+func balancedExpr(expr string) bool {
+	closing := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	var open []rune
+	inString, escaped := false, false
+	for _, r := range expr {
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == '"' {
+				inString = false
+			}
+		case r == '"':
+			inString = true
+		case r == '(' || r == '[' || r == '{':
+			open = append(open, r)
+		case closing[r] != 0:
+			if len(open) == 0 || open[len(open)-1] != closing[r] {
+				return false
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	return !inString && len(open) == 0
+}
+
+func TestGenerateLimitAlertRulesParse(t *testing.T) {
+	definitions := config.GetDefaultLimitDefinitions()
+	currentLimits := map[string]map[string]interface{}{
+		"tenant-a":    {"ingestion_rate": float64(50000), "max_global_series_per_user": 2000000},
+		`team.b|prod`: {"ingestion_rate": "75000"},
+	}
+	file := GenerateLimitAlertRules(definitions, currentLimits, "")
+
+	for _, format := range []string{"yaml", "json"} {
+		t.Run(format, func(t *testing.T) {
+			data, err := MarshalRuleFile(file, format)
+			if err != nil {
+				t.Fatalf("MarshalRuleFile: %v", err)
+			}
+			parsed := &PrometheusRuleFile{}
+			if format == "json" {
+				err = json.Unmarshal(data, parsed)
+			} else {
+				err = yaml.UnmarshalStrict(data, parsed)
+			}
+			if err != nil {
+				t.Fatalf("generated %s does not parse as a rule file: %v\n%s", format, err, data)
+			}
+			if len(parsed.Groups) == 0 {
+				t.Fatalf("rule file has no groups")
+			}
+			validateRuleFile(t, parsed)
+		})
+	}
+}
+
+func TestGenerateLimitAlertRulesThresholds(t *testing.T) {
+	definitions := map[string]config.LimitDefinition{
+		"ingestion_rate": {
+			Name:         "ingestion_rate",
+			Type:         "rate",
+			MetricSource: "cortex_distributor_received_samples_total",
+			DefaultValue: config.IntValue(10000),
+			Enabled:      true,
+		},
+		"ingestion_rate_strategy": {Name: "ingestion_rate_strategy", Type: "string", MetricSource: "unused", Enabled: true},
+		"max_fetched_series_per_query": {
+			Name: "max_fetched_series_per_query", Type: "count", MetricSource: "cortex_query_series", Enabled: false,
+		},
+	}
+	file := GenerateLimitAlertRules(definitions, map[string]map[string]interface{}{
+		"tenant-a": {"ingestion_rate": float64(50000)},
+	}, "tenant")
+
+	if len(file.Groups) != 1 || file.Groups[0].Name != "mimir-tenant-limits-ingestion-rate" {
+		t.Fatalf("groups = %+v, want only the enabled numeric limit", file.Groups)
+	}
+	rules := file.Groups[0].Rules
+	if len(rules) != 6 {
+		t.Fatalf("rules = %d, want three thresholds for tenant-a and three for the default", len(rules))
+	}
+
+	want := []struct {
+		tenant     string
+		comparison string
+		threshold  float64
+		severity   string
+	}{
+		{"tenant-a", ">", 40000, "info"},
+		{"tenant-a", ">", 45000, "warning"},
+		{"tenant-a", ">=", 50000, "critical"},
+		{"", ">", 8000, "info"},
+		{"", ">", 9000, "warning"},
+		{"", ">=", 10000, "critical"},
+	}
+	for i, rule := range rules {
+		match := ruleThreshold.FindStringSubmatch(rule.Expr)
+		if match == nil {
+			t.Fatalf("rule %d expression %q does not end in a comparison", i, rule.Expr)
+		}
+		threshold, _ := strconv.ParseFloat(match[2], 64)
+		if match[1] != want[i].comparison || threshold != want[i].threshold {
+			t.Errorf("rule %d compares %s %v, want %s %v", i, match[1], threshold, want[i].comparison, want[i].threshold)
+		}
+		if rule.Labels["severity"] != want[i].severity || rule.Labels["tenant"] != want[i].tenant {
+			t.Errorf("rule %d labels = %v, want severity %s and tenant %q", i, rule.Labels, want[i].severity, want[i].tenant)
+		}
+		if !strings.HasPrefix(rule.Expr, "sum by (tenant) (rate(cortex_distributor_received_samples_total{") {
+			t.Errorf("rule %d expression %q does not sum the rate of the metric by tenant", i, rule.Expr)
+		}
+	}
+	if !strings.Contains(rules[0].Expr, `tenant="tenant-a"`) || !strings.Contains(rules[3].Expr, `tenant!~"tenant-a"`) {
+		t.Errorf("expressions %q and %q do not split the overridden tenant from the default", rules[0].Expr, rules[3].Expr)
+	}
+}
+
+func TestMarshalRuleFileUnsupportedFormat(t *testing.T) {
+	if _, err := MarshalRuleFile(&PrometheusRuleFile{}, "toml"); err == nil {
+		t.Errorf("MarshalRuleFile with format toml succeeded")
+	}
+}
@@ -0,0 +1,445 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// silencesDataKey is the ConfigMap key holding the silences
+const silencesDataKey = "silences.json"
+
+// ErrSilenceNotFound is returned when deleting an unknown silence
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// ErrAlertSilenced is returned by SendAlertSync for an alert suppressed by a silence
+var ErrAlertSilenced = errors.New("alert suppressed by a silence")
+
+// SilenceRule suppresses the alerts matching all of its non-empty fields from StartsAt
+// until EndsAt, such as during planned maintenance
+type SilenceRule struct {
+	ID string `json:"id"`
+	// TenantID matches the tenant of the alert
+	TenantID string `json:"tenant_id,omitempty"`
+	// LimitName matches the limit an alert is about, such as the limits of a limit
+	// change alert
+	LimitName string `json:"limit_name,omitempty"`
+	// Severity matches the severity of the alert: critical, high, medium or low
+	Severity  string    `json:"severity,omitempty"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+}
+
+// Validate checks that a silence matches some alerts and ends after it starts
+func (s *SilenceRule) Validate() error {
+	if s.TenantID == "" && s.LimitName == "" && s.Severity == "" {
+		return fmt.Errorf("silence must match a tenant_id, limit_name or severity")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("silence must end after it starts")
+	}
+	return nil
+}
+
+// Active reports whether the silence suppresses alerts at now
+func (s *SilenceRule) Active(now time.Time) bool {
+	return !now.Before(s.StartsAt) && now.Before(s.EndsAt)
+}
+
+// Matches reports whether an alert matches every field the silence sets
+func (s *SilenceRule) Matches(alert *Alert) bool {
+	if s.TenantID != "" && s.TenantID != alert.Tenant {
+		return false
+	}
+	if s.Severity != "" && !strings.EqualFold(s.Severity, AlertSeverity(alert)) {
+		return false
+	}
+	if s.LimitName != "" {
+		for _, limit := range alertLimits(alert) {
+			if limit == s.LimitName {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// alertLimits returns the limits an alert is about
+func alertLimits(alert *Alert) []string {
+	var limits []string
+	for _, key := range []string{"limit", "limit_name"} {
+		if limit, ok := alert.Details[key].(string); ok && limit != "" {
+			limits = append(limits, limit)
+		}
+	}
+	if changes, ok := alert.Details["changes"].([]LimitChange); ok {
+		for _, change := range changes {
+			limits = append(limits, change.Limit)
+		}
+	}
+	return limits
+}
+
+// SilenceStore keeps the silences across restarts
+type SilenceStore interface {
+	Load(ctx context.Context) ([]SilenceRule, error)
+	Save(ctx context.Context, silences []SilenceRule) error
+}
+
+// ConfigMapSilenceStore keeps the silences in a ConfigMap
+type ConfigMapSilenceStore struct {
+	client        client.Client
+	configMapName string
+	namespace     string
+}
+
+// NewConfigMapSilenceStore creates a ConfigMap-backed silence store
+func NewConfigMapSilenceStore(c client.Client, configMapName, namespace string) *ConfigMapSilenceStore {
+	return &ConfigMapSilenceStore{
+		client:        c,
+		configMapName: configMapName,
+		namespace:     namespace,
+	}
+}
+
+func (c *ConfigMapSilenceStore) Load(ctx context.Context) ([]SilenceRule, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.client.Get(ctx, types.NamespacedName{Name: c.configMapName, Namespace: c.namespace}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get silences ConfigMap: %w", err)
+	}
+
+	data := configMap.Data[silencesDataKey]
+	if data == "" {
+		return nil, nil
+	}
+	var silences []SilenceRule
+	if err := json.Unmarshal([]byte(data), &silences); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal silences: %w", err)
+	}
+	return silences, nil
+}
+
+func (c *ConfigMapSilenceStore) Save(ctx context.Context, silences []SilenceRule) error {
+	if silences == nil {
+		silences = []SilenceRule{}
+	}
+	data, err := json.Marshal(silences)
+	if err != nil {
+		return fmt.Errorf("failed to marshal silences: %w", err)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.client.Get(ctx, types.NamespacedName{Name: c.configMapName, Namespace: c.namespace}, configMap)
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.configMapName,
+					Namespace: c.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       "mimir-limit-optimizer",
+						"app.kubernetes.io/component":  "alert-silences",
+						"app.kubernetes.io/managed-by": "mimir-limit-optimizer",
+					},
+				},
+				Data: map[string]string{silencesDataKey: string(data)},
+			}
+			return c.client.Create(ctx, configMap)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get silences ConfigMap: %w", err)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		if configMap.Data[silencesDataKey] == string(data) {
+			return nil
+		}
+		configMap.Data[silencesDataKey] = string(data)
+		return c.client.Update(ctx, configMap)
+	})
+}
+
+// silencer holds the silences and the latest alert each one suppressed per alerting
+// condition, resent when the silence ends while the condition is still firing
+type silencer struct {
+	mu         sync.Mutex
+	rules      []SilenceRule
+	suppressed map[string]*Alert
+	store      SilenceStore
+}
+
+func newSilencer() *silencer {
+	return &silencer{suppressed: make(map[string]*Alert)}
+}
+
+// matching returns the active silence suppressing an alert, nil when none does
+func (s *silencer) matching(alert *Alert, now time.Time) *SilenceRule {
+	for i := range s.rules {
+		if s.rules[i].Active(now) && s.rules[i].Matches(alert) {
+			return &s.rules[i]
+		}
+	}
+	return nil
+}
+
+// suppress reports whether an active silence matches an alert, remembering a firing
+// alert for its condition and forgetting the condition once it resolves
+func (s *silencer) suppress(alert *Alert, now time.Time) *SilenceRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule := s.matching(alert, now)
+	key := alertKey(alert)
+	if alert.Resolved {
+		delete(s.suppressed, key)
+	} else if rule != nil && !alert.escalated {
+		s.suppressed[key] = alert
+	}
+	if rule == nil {
+		return nil
+	}
+	silence := *rule
+	return &silence
+}
+
+// released removes the suppressed alerts no active silence matches anymore and returns
+// them, to be sent again
+func (s *silencer) released(now time.Time) []*Alert {
+	var alerts []*Alert
+	for key, alert := range s.suppressed {
+		if s.matching(alert, now) == nil {
+			delete(s.suppressed, key)
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// snapshot returns a copy of the silences, for persisting
+func (s *silencer) snapshot() []SilenceRule {
+	return append([]SilenceRule{}, s.rules...)
+}
+
+// SetSilenceStore sets the store silences are persisted to; call it before Start so the
+// stored silences are loaded
+func (m *Manager) SetSilenceStore(store SilenceStore) {
+	m.silences.mu.Lock()
+	defer m.silences.mu.Unlock()
+	m.silences.store = store
+}
+
+// loadSilences restores the stored silences that have not ended yet
+func (m *Manager) loadSilences() {
+	m.silences.mu.Lock()
+	store := m.silences.store
+	m.silences.mu.Unlock()
+	if store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+	defer cancel()
+	rules, err := store.Load(ctx)
+	if err != nil {
+		m.logger.Error(err, "Failed to load alert silences")
+		return
+	}
+
+	now := time.Now()
+	m.silences.mu.Lock()
+	m.silences.rules = m.silences.rules[:0]
+	for _, rule := range rules {
+		if now.Before(rule.EndsAt) {
+			m.silences.rules = append(m.silences.rules, rule)
+		}
+	}
+	loaded := len(m.silences.rules)
+	m.silences.mu.Unlock()
+
+	m.logger.Info("Alert silences loaded", "silences", loaded)
+}
+
+// CreateSilence adds a silence, starting now when StartsAt is not set, and persists it
+func (m *Manager) CreateSilence(ctx context.Context, rule SilenceRule) (*SilenceRule, error) {
+	now := time.Now()
+	if rule.StartsAt.IsZero() {
+		rule.StartsAt = now
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	if !rule.EndsAt.After(now) {
+		return nil, fmt.Errorf("silence must end in the future")
+	}
+	rule.ID = fmt.Sprintf("silence-%d", now.UnixNano())
+
+	m.silences.mu.Lock()
+	m.silences.rules = append(m.silences.rules, rule)
+	err := m.saveSilences(ctx)
+	if err != nil {
+		m.silences.rules = m.silences.rules[:len(m.silences.rules)-1]
+	}
+	m.silences.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("Alert silence created",
+		"silence_id", rule.ID,
+		"tenant", rule.TenantID,
+		"limit", rule.LimitName,
+		"severity", rule.Severity,
+		"ends_at", rule.EndsAt,
+		"created_by", rule.CreatedBy)
+	return &rule, nil
+}
+
+// DeleteSilence ends a silence. Alerts it suppressed whose condition is still firing
+// are sent again.
+func (m *Manager) DeleteSilence(ctx context.Context, id string) error {
+	m.silences.mu.Lock()
+	index := -1
+	for i, rule := range m.silences.rules {
+		if rule.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		m.silences.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrSilenceNotFound, id)
+	}
+
+	previous := m.silences.snapshot()
+	m.silences.rules = append(m.silences.rules[:index], m.silences.rules[index+1:]...)
+	if err := m.saveSilences(ctx); err != nil {
+		m.silences.rules = previous
+		m.silences.mu.Unlock()
+		return err
+	}
+	released := m.silences.released(time.Now())
+	m.silences.mu.Unlock()
+
+	m.logger.Info("Alert silence deleted", "silence_id", id, "released_alerts", len(released))
+	m.resend(released)
+	return nil
+}
+
+// GetSilences returns the silences that have not ended, by end time
+func (m *Manager) GetSilences() []SilenceRule {
+	m.silences.mu.Lock()
+	rules := m.silences.snapshot()
+	m.silences.mu.Unlock()
+
+	sort.Slice(rules, func(i, j int) bool { return rules[i].EndsAt.Before(rules[j].EndsAt) })
+	return rules
+}
+
+// silenced reports whether a silence suppresses an alert, counting and logging it
+func (m *Manager) silenced(alert *Alert) bool {
+	rule := m.silences.suppress(alert, time.Now())
+	if rule == nil {
+		return false
+	}
+
+	m.metrics.IncAlertSuppressed(string(alert.Type))
+	m.logger.Info("Alert suppressed by silence",
+		"alert_id", alert.ID,
+		"type", alert.Type,
+		"tenant", alert.Tenant,
+		"silence_id", rule.ID)
+	return true
+}
+
+// expireSilences drops the silences that have ended and sends again the alerts they
+// suppressed whose condition is still firing
+func (m *Manager) expireSilences(now time.Time) {
+	m.silences.mu.Lock()
+	kept := m.silences.rules[:0]
+	var expired []string
+	for _, rule := range m.silences.rules {
+		if now.Before(rule.EndsAt) {
+			kept = append(kept, rule)
+		} else {
+			expired = append(expired, rule.ID)
+		}
+	}
+	m.silences.rules = kept
+	if len(expired) > 0 {
+		ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+		if err := m.saveSilences(ctx); err != nil {
+			m.logger.Error(err, "Failed to persist expired alert silences")
+		}
+		cancel()
+	}
+	released := m.silences.released(now)
+	m.silences.mu.Unlock()
+
+	if len(expired) > 0 {
+		m.logger.Info("Alert silences expired", "silences", expired, "released_alerts", len(released))
+	}
+	m.resend(released)
+}
+
+// saveSilences persists the silences; the caller holds the silencer lock
+func (m *Manager) saveSilences(ctx context.Context) error {
+	if m.silences.store == nil {
+		return nil
+	}
+	if err := m.silences.store.Save(ctx, m.silences.snapshot()); err != nil {
+		return fmt.Errorf("failed to persist alert silences: %w", err)
+	}
+	return nil
+}
+
+// resend queues again the alerts released by ended silences
+func (m *Manager) resend(alerts []*Alert) {
+	for _, alert := range alerts {
+		m.logger.Info("Sending alert suppressed by an ended silence",
+			"alert_id", alert.ID,
+			"type", alert.Type,
+			"tenant", alert.Tenant)
+		m.SendAlert(alert)
+	}
+}
+
+// silenceWorker expires ended silences
+func (m *Manager) silenceWorker() {
+	defer m.wg.Done()
+
+	m.logger.Info("Silence worker started")
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.expireSilences(now)
+
+		case <-m.ctx.Done():
+			m.logger.Info("Context cancelled, stopping silence worker")
+			return
+		}
+	}
+}
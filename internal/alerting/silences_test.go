@@ -0,0 +1,271 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// recordingChannel records the alerts delivered to it
+type recordingChannel struct {
+	mu     sync.Mutex
+	alerts []*Alert
+}
+
+func (c *recordingChannel) Name() string { return "recorder" }
+
+func (c *recordingChannel) Send(ctx context.Context, alert *Alert) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alerts = append(c.alerts, alert)
+	return nil
+}
+
+func (c *recordingChannel) IsHealthy() bool { return true }
+
+func (c *recordingChannel) GetConfiguration() interface{} { return nil }
+
+func (c *recordingChannel) ValidateConfiguration() error { return nil }
+
+// delivered returns the alerts delivered so far
+func (c *recordingChannel) delivered() []*Alert {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Alert(nil), c.alerts...)
+}
+
+// newSilenceTestManager creates a manager delivering to a recording channel, persisting
+// its silences to store when it is set. Its workers are not started: deliverQueued
+// processes the queued alerts in the test goroutine.
+func newSilenceTestManager(t *testing.T, store SilenceStore) (*Manager, *recordingChannel) {
+	t.Helper()
+	manager := NewManager(&config.AlertingConfig{Enabled: true}, logr.Discard())
+	channel := &recordingChannel{}
+	manager.channels[channel.Name()] = channel
+	if store != nil {
+		manager.SetSilenceStore(store)
+	}
+	manager.loadSilences()
+	t.Cleanup(manager.cancel)
+	return manager, channel
+}
+
+// deliverQueued processes the alerts queued by SendAlert until the queue is empty
+func deliverQueued(manager *Manager) {
+	for {
+		select {
+		case alert := <-manager.alertQueue:
+			manager.processAlert(alert)
+		default:
+			return
+		}
+	}
+}
+
+func maintenanceSilence(d time.Duration) SilenceRule {
+	return SilenceRule{TenantID: "tenant-a", EndsAt: time.Now().Add(d), CreatedBy: "alice"}
+}
+
+func ingestionRateAlert(tenant string) *Alert {
+	return CreateLimitChangeAlert(tenant, []LimitChange{{Limit: "ingestion_rate", Before: 10000, After: 20000}}, "spike")
+}
+
+func TestSilenceSuppressesUntilExpiry(t *testing.T) {
+	manager, channel := newSilenceTestManager(t, nil)
+	suppressed := map[string]string{"alert_type": string(AlertTypeLimitChange)}
+	before := metricstest.Value(t, "mimir_limit_optimizer_alerts_suppressed_total", suppressed)
+
+	silence, err := manager.CreateSilence(context.Background(), maintenanceSilence(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+	if silence.ID == "" || silence.StartsAt.IsZero() {
+		t.Errorf("silence = %+v, want an ID and starting now", silence)
+	}
+
+	manager.SendAlert(ingestionRateAlert("tenant-a"))
+	manager.SendAlert(ingestionRateAlert("tenant-b"))
+	deliverQueued(manager)
+	delivered := channel.delivered()
+	if len(delivered) != 1 || delivered[0].Tenant != "tenant-b" {
+		t.Fatalf("delivered alerts = %+v, want only the one of tenant-b outside the silence", delivered)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_alerts_suppressed_total", suppressed) - before; got != 1 {
+		t.Errorf("mimir_limit_optimizer_alerts_suppressed_total rose by %v, want 1", got)
+	}
+	latest := ingestionRateAlert("tenant-a")
+	if err := manager.SendAlertSync(latest, time.Second); !errors.Is(err, ErrAlertSilenced) {
+		t.Errorf("SendAlertSync of a silenced alert = %v, want ErrAlertSilenced", err)
+	}
+
+	// Once the silence expires the condition still firing is alerted, once with its
+	// latest alert
+	manager.expireSilences(time.Now().Add(2 * time.Hour))
+	if silences := manager.GetSilences(); len(silences) != 0 {
+		t.Errorf("silences after the expiry = %+v, want none", silences)
+	}
+	deliverQueued(manager)
+	delivered = channel.delivered()
+	if len(delivered) != 2 || delivered[1].ID != latest.ID {
+		t.Fatalf("delivered alerts after the expiry = %+v, want the latest suppressed alert of tenant-a", delivered)
+	}
+}
+
+func TestExpiredSilenceDoesNotResendResolvedCondition(t *testing.T) {
+	manager, channel := newSilenceTestManager(t, nil)
+	if _, err := manager.CreateSilence(context.Background(), maintenanceSilence(time.Hour)); err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+
+	firing := sampleAlert(AlertTypeCostViolation, PriorityP1, "tenant-a", nil)
+	resolved := sampleAlert(AlertTypeCostViolation, PriorityP1, "tenant-a", nil)
+	resolved.Resolved = true
+	manager.SendAlert(firing)
+	manager.SendAlert(resolved)
+	deliverQueued(manager)
+
+	manager.expireSilences(time.Now().Add(2 * time.Hour))
+	deliverQueued(manager)
+	if delivered := channel.delivered(); len(delivered) != 0 {
+		t.Errorf("delivered alerts = %+v, want none for a condition resolved during the silence", delivered)
+	}
+}
+
+func TestDeleteSilenceReleasesSuppressedAlerts(t *testing.T) {
+	manager, channel := newSilenceTestManager(t, nil)
+	ctx := context.Background()
+	silence, err := manager.CreateSilence(ctx, maintenanceSilence(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+	manager.SendAlert(ingestionRateAlert("tenant-a"))
+	deliverQueued(manager)
+	if delivered := channel.delivered(); len(delivered) != 0 {
+		t.Fatalf("delivered alerts = %+v, want the alert of tenant-a silenced", delivered)
+	}
+
+	if err := manager.DeleteSilence(ctx, silence.ID); err != nil {
+		t.Fatalf("DeleteSilence: %v", err)
+	}
+	deliverQueued(manager)
+	if delivered := channel.delivered(); len(delivered) != 1 || delivered[0].Tenant != "tenant-a" {
+		t.Errorf("delivered alerts = %+v, want the alert of tenant-a released", delivered)
+	}
+	if err := manager.DeleteSilence(ctx, silence.ID); !errors.Is(err, ErrSilenceNotFound) {
+		t.Errorf("second DeleteSilence = %v, want ErrSilenceNotFound", err)
+	}
+}
+
+func TestSilencesPersistedInConfigMap(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	store := NewConfigMapSilenceStore(c, "mimir-limit-optimizer-silences", "mimir")
+	manager, _ := newSilenceTestManager(t, store)
+
+	silence, err := manager.CreateSilence(context.Background(), maintenanceSilence(time.Hour))
+	if err != nil {
+		t.Fatalf("CreateSilence: %v", err)
+	}
+	stored, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(stored) != 1 || stored[0].ID != silence.ID || stored[0].CreatedBy != "alice" {
+		t.Fatalf("stored silences = %+v, want %s", stored, silence.ID)
+	}
+
+	// A restarted manager restores the silence, but not the ones that have ended
+	ended := SilenceRule{ID: "ended", TenantID: "tenant-b", StartsAt: time.Now().Add(-2 * time.Hour), EndsAt: time.Now().Add(-time.Hour)}
+	if err := store.Save(context.Background(), append(stored, ended)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	restarted, _ := newSilenceTestManager(t, store)
+	if silences := restarted.GetSilences(); len(silences) != 1 || silences[0].ID != silence.ID {
+		t.Errorf("restored silences = %+v, want %s", silences, silence.ID)
+	}
+
+	restarted.expireSilences(time.Now().Add(2 * time.Hour))
+	if stored, _ := store.Load(context.Background()); len(stored) != 0 {
+		t.Errorf("stored silences after the expiry = %+v, want none", stored)
+	}
+}
+
+func TestCreateSilenceValidation(t *testing.T) {
+	manager := NewManager(&config.AlertingConfig{}, logr.Discard())
+	now := time.Now()
+	tests := []struct {
+		name string
+		rule SilenceRule
+	}{
+		{"without matchers", SilenceRule{EndsAt: now.Add(time.Hour)}},
+		{"ending before it starts", SilenceRule{TenantID: "tenant-a", StartsAt: now.Add(time.Hour), EndsAt: now}},
+		{"already ended", SilenceRule{TenantID: "tenant-a", StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}},
+	}
+	for _, tt := range tests {
+		if _, err := manager.CreateSilence(context.Background(), tt.rule); err == nil {
+			t.Errorf("CreateSilence of a silence %s succeeded", tt.name)
+		}
+	}
+	if silences := manager.GetSilences(); len(silences) != 0 {
+		t.Errorf("silences = %+v, want none created", silences)
+	}
+}
+
+// failingStore fails to save silences
+type failingStore struct{}
+
+func (failingStore) Load(ctx context.Context) ([]SilenceRule, error) { return nil, nil }
+
+func (failingStore) Save(ctx context.Context, silences []SilenceRule) error {
+	return errors.New("etcd unavailable")
+}
+
+func TestCreateSilenceNotKeptWhenNotPersisted(t *testing.T) {
+	manager := NewManager(&config.AlertingConfig{}, logr.Discard())
+	manager.SetSilenceStore(failingStore{})
+	if _, err := manager.CreateSilence(context.Background(), maintenanceSilence(time.Hour)); err == nil {
+		t.Fatalf("CreateSilence with a failing store succeeded")
+	}
+	if silences := manager.GetSilences(); len(silences) != 0 {
+		t.Errorf("silences = %+v, want the unpersisted silence dropped", silences)
+	}
+}
+
+func TestSilenceRuleMatches(t *testing.T) {
+	limitChange := ingestionRateAlert("tenant-a")
+	critical := sampleAlert(AlertTypeCostViolation, PriorityP0, "tenant-b", map[string]interface{}{"limit": "max_global_series_per_user"})
+
+	tests := []struct {
+		rule  SilenceRule
+		alert *Alert
+		want  bool
+	}{
+		{SilenceRule{TenantID: "tenant-a"}, limitChange, true},
+		{SilenceRule{TenantID: "tenant-a"}, critical, false},
+		{SilenceRule{LimitName: "ingestion_rate"}, limitChange, true},
+		{SilenceRule{LimitName: "ingestion_rate"}, critical, false},
+		{SilenceRule{LimitName: "max_global_series_per_user"}, critical, true},
+		{SilenceRule{Severity: "CRITICAL"}, critical, true},
+		{SilenceRule{Severity: "critical"}, limitChange, false},
+		{SilenceRule{TenantID: "tenant-a", Severity: "low"}, limitChange, true},
+		{SilenceRule{TenantID: "tenant-a", LimitName: "max_global_series_per_user"}, limitChange, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.alert); got != tt.want {
+			t.Errorf("silence %+v matched the %s alert of %s = %v, want %v", tt.rule, tt.alert.Type, tt.alert.Tenant, got, tt.want)
+		}
+	}
+
+	now := time.Now()
+	scheduled := SilenceRule{TenantID: "tenant-a", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}
+	if scheduled.Active(now) || !scheduled.Active(now.Add(time.Hour)) || scheduled.Active(now.Add(2*time.Hour)) {
+		t.Errorf("silence from %v to %v is not active exactly in between", scheduled.StartsAt, scheduled.EndsAt)
+	}
+}
@@ -0,0 +1,606 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// SlackChannel implements the Channel interface for Slack
+type SlackChannel struct {
+	config *config.SlackConfig
+	logger logr.Logger
+	client *http.Client
+
+	// Fingerprints of recently delivered messages, used for deduplication
+	dedupMu  sync.Mutex
+	lastSent map[string]time.Time
+}
+
+// NewSlackChannel creates a new Slack channel
+func NewSlackChannel(config config.SlackConfig, logger logr.Logger) *SlackChannel {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &SlackChannel{
+		config: &config,
+		logger: logger,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+func (s *SlackChannel) Name() string {
+	return "slack"
+}
+
+func (s *SlackChannel) ValidateConfiguration() error {
+	if !s.config.Enabled {
+		return fmt.Errorf("slack channel is disabled")
+	}
+	if s.config.WebhookURL == "" {
+		return fmt.Errorf("slack webhook URL is required")
+	}
+	if s.config.Channel == "" {
+		return fmt.Errorf("slack channel is required")
+	}
+	return nil
+}
+
+func (s *SlackChannel) GetConfiguration() interface{} {
+	// Return safe configuration (without sensitive data)
+	return map[string]interface{}{
+		"enabled":             s.config.Enabled,
+		"channel":             s.config.Channel,
+		"username":            s.config.Username,
+		"timeout":             s.config.Timeout,
+		"mention_here":        s.config.MentionHere,
+		"mention_on_critical": s.config.MentionOnCritical,
+		"dedup_window":        s.config.DedupWindow,
+	}
+}
+
+func (s *SlackChannel) IsHealthy() bool {
+	// Perform a simple health check without sending an actual alert
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://slack.com/api/api.test", nil)
+	if err != nil {
+		s.logger.V(1).Info("Slack health check failed - request creation", "error", err)
+		return false
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.V(1).Info("Slack health check failed - request execution", "error", err)
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (s *SlackChannel) Send(ctx context.Context, alert *Alert) error {
+	if !s.config.Enabled {
+		return fmt.Errorf("slack channel is disabled")
+	}
+
+	fingerprint := s.fingerprint(alert)
+	claimedAt, claimed := s.claim(fingerprint)
+	if !claimed {
+		s.logger.V(1).Info("Suppressing duplicate Slack alert",
+			"alert_id", alert.ID,
+			"type", alert.Type,
+			"dedup_window", s.config.DedupWindow)
+		return ErrDuplicateAlert
+	}
+	delivered := false
+	defer func() {
+		if !delivered {
+			s.unclaim(fingerprint, claimedAt)
+		}
+	}()
+
+	payload := s.buildSlackPayload(alert)
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.Error(err, "Failed to marshal Slack payload", "alert_id", alert.ID)
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.config.WebhookURL, bytes.NewBuffer(payloadBytes))
+	if err != nil {
+		s.logger.Error(err, "Failed to create Slack request", "alert_id", alert.ID)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	startTime := time.Now()
+	resp, err := s.client.Do(req)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		s.logger.Error(err, "Failed to send Slack alert",
+			"alert_id", alert.ID,
+			"duration", duration)
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		// Slack explains rejected messages in the body, e.g. "invalid_payload" or "channel_not_found"
+		reason := slackErrorReason(resp.Body)
+		s.logger.Error(fmt.Errorf("slack returned non-200 status"),
+			"Failed to send Slack alert",
+			"alert_id", alert.ID,
+			"status_code", resp.StatusCode,
+			"reason", reason,
+			"duration", duration)
+		if reason != "" {
+			return fmt.Errorf("slack returned status %d: %s", resp.StatusCode, reason)
+		}
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+
+	delivered = true
+	s.logger.Info("Slack alert sent successfully",
+		"alert_id", alert.ID,
+		"channel", s.config.Channel,
+		"duration", duration)
+
+	return nil
+}
+
+// fingerprint identifies alerts with identical content regardless of ID or timestamp
+func (s *SlackChannel) fingerprint(alert *Alert) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		string(alert.Type), string(alert.Priority), alert.Tenant, alert.Title, alert.Message,
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// claim reports whether no identical message was delivered or is being delivered
+// within the dedup window, recording the delivery under the same lock as the check so
+// concurrent sends of identical alerts post one message. It returns the time of the
+// claim, which a failed delivery releases with unclaim.
+func (s *SlackChannel) claim(fingerprint string) (time.Time, bool) {
+	now := time.Now()
+	if s.config.DedupWindow <= 0 {
+		return now, true
+	}
+
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+
+	for key, sentAt := range s.lastSent {
+		if now.Sub(sentAt) >= s.config.DedupWindow {
+			delete(s.lastSent, key)
+		}
+	}
+
+	if _, exists := s.lastSent[fingerprint]; exists {
+		return time.Time{}, false
+	}
+	s.lastSent[fingerprint] = now
+	return now, true
+}
+
+// unclaim releases the claim of a failed delivery, so the alert is not suppressed as a
+// duplicate when it is retried
+func (s *SlackChannel) unclaim(fingerprint string, claimedAt time.Time) {
+	if s.config.DedupWindow <= 0 {
+		return
+	}
+
+	s.dedupMu.Lock()
+	defer s.dedupMu.Unlock()
+	if sentAt, exists := s.lastSent[fingerprint]; exists && sentAt.Equal(claimedAt) {
+		delete(s.lastSent, fingerprint)
+	}
+}
+
+// buildSlackPayload renders an alert as a Block Kit message. Blocks are wrapped
+// in an attachment so the priority color is shown alongside the message.
+func (s *SlackChannel) buildSlackPayload(alert *Alert) map[string]interface{} {
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": slackPlainText(truncate(alert.Title, 150)),
+		},
+		{
+			"type": "section",
+			"text": slackMarkdown(alert.Message),
+		},
+	}
+
+	summary := []map[string]interface{}{
+		slackMarkdown(fmt.Sprintf("*Priority*\n%s", alert.Priority)),
+		slackMarkdown(fmt.Sprintf("*Type*\n%s", alert.Type)),
+	}
+	if alert.Tenant != "" {
+		summary = append(summary, slackMarkdown(fmt.Sprintf("*Tenant*\n%s", alert.Tenant)))
+	}
+	blocks = append(blocks, map[string]interface{}{
+		"type":   "section",
+		"fields": summary,
+	})
+
+	switch alert.Type {
+	case AlertTypeSpike:
+		blocks = append(blocks, s.buildSpikeBlocks(alert)...)
+	case AlertTypeLimitChange:
+		blocks = append(blocks, s.buildLimitChangeBlocks(alert)...)
+	case AlertTypeCircuitBreaker:
+		blocks = append(blocks, s.buildCircuitBreakerBlocks(alert)...)
+	default:
+		blocks = append(blocks, s.buildDetailBlocks(alert.Details)...)
+	}
+
+	blocks = append(blocks, map[string]interface{}{
+		"type": "context",
+		"elements": []map[string]interface{}{
+			slackMarkdown(fmt.Sprintf("mimir-limit-optimizer • %s • <!date^%d^{date_short_pretty} {time_secs}|%s>",
+				alert.ID, alert.Timestamp.Unix(), alert.Timestamp.Format(time.RFC3339))),
+		},
+	})
+
+	text := alert.Title
+	if isCriticalAlert(alert) {
+		if mentions := s.criticalMentions(); mentions != "" {
+			text = mentions + " " + text
+		}
+	}
+
+	iconEmoji := s.config.IconEmoji
+	if iconEmoji == "" {
+		iconEmoji = ":warning:"
+	}
+
+	payload := map[string]interface{}{
+		"channel":    s.config.Channel,
+		"icon_emoji": iconEmoji,
+		"text":       text, // Notification fallback and critical mentions
+		"attachments": []map[string]interface{}{
+			{
+				"color":  s.getColorForAlert(alert),
+				"blocks": blocks,
+			},
+		},
+	}
+	// Without a username Slack shows the name the webhook was installed with
+	if s.config.Username != "" {
+		payload["username"] = s.config.Username
+	}
+
+	return payload
+}
+
+// slackErrorReason reads the short error text Slack returns for a rejected message
+func slackErrorReason(body io.Reader) string {
+	reason, _ := io.ReadAll(io.LimitReader(body, 256))
+	return strings.TrimSpace(string(reason))
+}
+
+// buildSpikeBlocks renders observed vs baseline values for a spike alert
+func (s *SlackChannel) buildSpikeBlocks(alert *Alert) []map[string]interface{} {
+	fields := []map[string]interface{}{
+		slackMarkdown(fmt.Sprintf("*Metric*\n`%v`", alert.Details["metric"])),
+		slackMarkdown(fmt.Sprintf("*Multiplier*\n%s", formatSlackNumber(alert.Details["multiplier"], "x"))),
+		slackMarkdown(fmt.Sprintf("*Observed*\n%s", formatSlackNumber(alert.Details["observed"], ""))),
+		slackMarkdown(fmt.Sprintf("*Baseline*\n%s", formatSlackNumber(alert.Details["baseline"], ""))),
+	}
+
+	return []map[string]interface{}{
+		{"type": "divider"},
+		{"type": "section", "fields": fields},
+	}
+}
+
+// buildLimitChangeBlocks renders a table of the limit changes with the buffer applied
+// and a sparkline of recent usage for each limit
+func (s *SlackChannel) buildLimitChangeBlocks(alert *Alert) []map[string]interface{} {
+	changes, _ := alert.Details["changes"].([]LimitChange)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	limitWidth := len("Limit")
+	beforeWidth := len("Before")
+	afterWidth := len("After")
+	for _, change := range changes {
+		if len(change.Limit) > limitWidth {
+			limitWidth = len(change.Limit)
+		}
+		if n := len(formatLimitValue(change.Before)); n > beforeWidth {
+			beforeWidth = n
+		}
+		if n := len(formatLimitValue(change.After)); n > afterWidth {
+			afterWidth = n
+		}
+	}
+
+	var table strings.Builder
+	table.WriteString("```\n")
+	table.WriteString(fmt.Sprintf("%-*s  %-*s  %-*s  %-6s  %s\n",
+		limitWidth, "Limit", beforeWidth, "Before", afterWidth, "After", "Buffer", "Usage"))
+	for _, change := range changes {
+		buffer := "-"
+		if change.BufferPercent > 0 {
+			buffer = fmt.Sprintf("%.0f%%", change.BufferPercent)
+		}
+		table.WriteString(fmt.Sprintf("%-*s  %-*s  %-*s  %-6s  %s\n",
+			limitWidth, change.Limit,
+			beforeWidth, formatLimitValue(change.Before),
+			afterWidth, formatLimitValue(change.After),
+			buffer, sparkline(change.Usage)))
+	}
+	table.WriteString("```")
+
+	return []map[string]interface{}{
+		{"type": "divider"},
+		{"type": "section", "text": slackMarkdown(truncate(table.String(), 3000))},
+	}
+}
+
+// buildDetailBlocks renders arbitrary alert details as section fields
+func (s *SlackChannel) buildDetailBlocks(details map[string]interface{}) []map[string]interface{} {
+	if len(details) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Use cases.Title instead of deprecated strings.Title
+	caser := cases.Title(language.English)
+	var blocks []map[string]interface{}
+	var fields []map[string]interface{}
+	for _, key := range keys {
+		fields = append(fields, slackMarkdown(fmt.Sprintf("*%s*\n%v",
+			caser.String(strings.ReplaceAll(key, "_", " ")), details[key])))
+
+		// Slack allows at most 10 fields per section
+		if len(fields) == 10 {
+			blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+			fields = nil
+		}
+	}
+	if len(fields) > 0 {
+		blocks = append(blocks, map[string]interface{}{"type": "section", "fields": fields})
+	}
+
+	return blocks
+}
+
+// buildCircuitBreakerBlocks renders the trip reason and projected recovery time of
+// a circuit breaker alert
+func (s *SlackChannel) buildCircuitBreakerBlocks(alert *Alert) []map[string]interface{} {
+	reason := alert.Details["reason"]
+	if reason == nil {
+		reason = alert.Details["blast_type"]
+	}
+
+	recovery := "unknown"
+	switch v := alert.Details["recovery_time"].(type) {
+	case time.Time:
+		recovery = slackDate(v)
+	default:
+		if timeout, ok := alert.Details["recovery_timeout"].(time.Duration); ok && timeout > 0 {
+			recovery = fmt.Sprintf("%s (in %s)", slackDate(alert.Timestamp.Add(timeout)), timeout)
+		}
+	}
+
+	fields := []map[string]interface{}{
+		slackMarkdown(fmt.Sprintf("*Trip reason*\n%v", reason)),
+		slackMarkdown(fmt.Sprintf("*Projected recovery*\n%s", recovery)),
+	}
+
+	blocks := []map[string]interface{}{
+		{"type": "divider"},
+		{"type": "section", "fields": fields},
+	}
+
+	// Remaining details, without the fields already rendered above
+	rest := make(map[string]interface{}, len(alert.Details))
+	for key, value := range alert.Details {
+		switch key {
+		case "reason", "blast_type", "recovery_time", "recovery_timeout":
+			continue
+		}
+		rest[key] = value
+	}
+	return append(blocks, s.buildDetailBlocks(rest)...)
+}
+
+// criticalMentions returns the mentions prefixed to emergency and panic mode messages
+func (s *SlackChannel) criticalMentions() string {
+	var mentions []string
+	if s.config.MentionHere {
+		mentions = append(mentions, "<!here>")
+	}
+	for _, id := range s.config.MentionOnCritical {
+		if mention := slackMention(id); mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	return strings.Join(mentions, " ")
+}
+
+func (s *SlackChannel) getColorForAlert(alert *Alert) string {
+	if alert.Resolved {
+		return "#2EB67D"
+	}
+	if isCriticalAlert(alert) {
+		return "#E01E5A"
+	}
+	if alert.Type == AlertTypeLimitChange {
+		// Green when limits only grew, yellow when any limit was lowered
+		changes, _ := alert.Details["changes"].([]LimitChange)
+		for _, change := range changes {
+			if limitDirection(change) < 0 {
+				return "#ECB22E"
+			}
+		}
+		return "#2EB67D"
+	}
+
+	switch alert.Priority {
+	case PriorityP0:
+		return "#E01E5A"
+	case PriorityP1:
+		return "#ECB22E"
+	case PriorityP2:
+		return "#2EB67D"
+	case PriorityP3:
+		return "#439FE0"
+	default:
+		return "#2EB67D"
+	}
+}
+
+// isCriticalAlert reports whether an alert signals emergency or panic mode
+func isCriticalAlert(alert *Alert) bool {
+	return !alert.Resolved && (alert.Type == AlertTypePanicMode || alert.Type == AlertTypeEmergency)
+}
+
+// slackMention formats a user or user group ID as a Slack mention. Already formatted
+// mentions and the special here/channel/everyone keywords are accepted as well.
+func slackMention(id string) string {
+	id = strings.TrimSpace(id)
+	switch {
+	case id == "":
+		return ""
+	case strings.HasPrefix(id, "<"):
+		return id
+	case id == "here" || id == "channel" || id == "everyone":
+		return "<!" + id + ">"
+	case strings.HasPrefix(id, "S"):
+		return "<!subteam^" + id + ">"
+	default:
+		return "<@" + id + ">"
+	}
+}
+
+// slackDate renders a timestamp that Slack localizes for each reader
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", t.Unix(), t.Format(time.RFC3339))
+}
+
+// sparkline renders samples as a Unicode bar chart scaled between their minimum and maximum
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	bars := []rune("▁▂▃▄▅▆▇█")
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	var line strings.Builder
+	for _, v := range values {
+		index := len(bars) - 1
+		if max > min {
+			index = int((v - min) / (max - min) * float64(len(bars)-1))
+		}
+		line.WriteRune(bars[index])
+	}
+	return line.String()
+}
+
+// limitDirection reports whether a limit change raised (1), lowered (-1) or did not
+// numerically change (0) the limit
+func limitDirection(change LimitChange) int {
+	before, beforeOK := limitNumber(change.Before)
+	after, afterOK := limitNumber(change.After)
+	if !beforeOK || !afterOK {
+		if change.Before == nil && change.After != nil {
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case after > before:
+		return 1
+	case after < before:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func limitNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func slackPlainText(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "plain_text", "text": text, "emoji": true}
+}
+
+func slackMarkdown(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": text}
+}
+
+// formatSlackNumber formats numeric detail values with two decimals
+func formatSlackNumber(value interface{}, suffix string) string {
+	if v, ok := value.(float64); ok {
+		return fmt.Sprintf("%.2f%s", v, suffix)
+	}
+	return fmt.Sprintf("%v%s", value, suffix)
+}
+
+// formatLimitValue formats a limit value for the before/after table
+func formatLimitValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
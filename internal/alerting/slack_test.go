@@ -0,0 +1,329 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// slackMessage is the schema of the Block Kit messages the Slack channel posts
+type slackMessage struct {
+	Channel     string `json:"channel"`
+	Username    string `json:"username"`
+	IconEmoji   string `json:"icon_emoji"`
+	Text        string `json:"text"`
+	Attachments []struct {
+		Color  string       `json:"color"`
+		Blocks []slackBlock `json:"blocks"`
+	} `json:"attachments"`
+}
+
+type slackBlock struct {
+	Type     string             `json:"type"`
+	Text     *slackTextElement  `json:"text"`
+	Fields   []slackTextElement `json:"fields"`
+	Elements []slackTextElement `json:"elements"`
+}
+
+type slackTextElement struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// renderSlackMessage builds the payload of alert and decodes it through its JSON
+// encoding, as Slack receives it
+func renderSlackMessage(t *testing.T, channel *SlackChannel, alert *Alert) slackMessage {
+	t.Helper()
+	raw, err := json.Marshal(channel.buildSlackPayload(alert))
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	var message slackMessage
+	if err := json.Unmarshal(raw, &message); err != nil {
+		t.Fatalf("payload does not match the Block Kit schema: %v\n%s", err, raw)
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("payload has %d attachments, want 1", len(message.Attachments))
+	}
+	return message
+}
+
+// checkBlocks verifies the layout every message shares and returns the blocks
+func checkBlocks(t *testing.T, message slackMessage) []slackBlock {
+	t.Helper()
+	blocks := message.Attachments[0].Blocks
+	if len(blocks) < 4 {
+		t.Fatalf("message has %d blocks, want at least a header, message, summary and context", len(blocks))
+	}
+	if blocks[0].Type != "header" || blocks[0].Text == nil || blocks[0].Text.Type != "plain_text" {
+		t.Errorf("first block = %+v, want a plain text header", blocks[0])
+	}
+	if last := blocks[len(blocks)-1]; last.Type != "context" || len(last.Elements) != 1 {
+		t.Errorf("last block = %+v, want a context block", last)
+	}
+	for _, block := range blocks {
+		if block.Type == "section" && block.Text == nil && len(block.Fields) == 0 {
+			t.Errorf("section block without text or fields")
+		}
+		if len(block.Fields) > 10 {
+			t.Errorf("section block with %d fields, Slack allows 10", len(block.Fields))
+		}
+	}
+	return blocks
+}
+
+// blockText joins the text of every block and field
+func blockText(blocks []slackBlock) string {
+	var text strings.Builder
+	for _, block := range blocks {
+		if block.Text != nil {
+			text.WriteString(block.Text.Text + "\n")
+		}
+		for _, field := range append(block.Fields, block.Elements...) {
+			text.WriteString(field.Text + "\n")
+		}
+	}
+	return text.String()
+}
+
+func newTestSlackChannel() *SlackChannel {
+	return NewSlackChannel(config.SlackConfig{
+		Enabled:           true,
+		Channel:           "#mimir",
+		IconEmoji:         ":chart_with_upwards_trend:",
+		MentionOnCritical: []string{"U123", "S456"},
+	}, logr.Discard())
+}
+
+func TestSlackLimitChangeMessage(t *testing.T) {
+	channel := newTestSlackChannel()
+
+	increase := CreateLimitChangeAlert("tenant-a", []LimitChange{
+		{Limit: "ingestion_rate", Before: 10000.0, After: 12000.0, BufferPercent: 20, Usage: []float64{1, 2, 3, 4, 5, 6, 7, 8}},
+	}, "trend-analysis")
+	message := renderSlackMessage(t, channel, increase)
+	text := blockText(checkBlocks(t, message))
+
+	for _, want := range []string{"tenant-a", "ingestion_rate", "10000", "12000", "20%", "▁▂▃▄▅▆▇█"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("limit change message does not contain %q:\n%s", want, text)
+		}
+	}
+	if message.Attachments[0].Color != "#2EB67D" {
+		t.Errorf("increase color = %s, want green", message.Attachments[0].Color)
+	}
+	if message.Channel != "#mimir" || message.IconEmoji != ":chart_with_upwards_trend:" {
+		t.Errorf("channel %q, icon %q", message.Channel, message.IconEmoji)
+	}
+
+	decrease := CreateLimitChangeAlert("tenant-a", []LimitChange{
+		{Limit: "ingestion_rate", Before: 12000.0, After: 8000.0},
+		{Limit: "max_global_series_per_user", Before: 1000.0, After: 2000.0},
+	}, "trend-analysis")
+	if color := renderSlackMessage(t, channel, decrease).Attachments[0].Color; color != "#ECB22E" {
+		t.Errorf("decrease color = %s, want yellow", color)
+	}
+}
+
+func TestSlackCircuitBreakerMessage(t *testing.T) {
+	channel := newTestSlackChannel()
+	alert := CreateCircuitBreakerAlert("tenant-a", "ingestion_spike", map[string]interface{}{
+		"recovery_timeout": 5 * time.Minute,
+		"spike_ratio":      4.5,
+	})
+
+	text := blockText(checkBlocks(t, renderSlackMessage(t, channel, alert)))
+
+	for _, want := range []string{"*Trip reason*\ningestion_spike", "*Projected recovery*\n<!date^", "(in 5m0s)", "Spike Ratio"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("circuit breaker message does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestSlackEmergencyMessage(t *testing.T) {
+	channel := newTestSlackChannel()
+	alert := CreateAlert(AlertTypeEmergency, PriorityP1, "Emergency mode", "ingestion spike across tenants")
+
+	message := renderSlackMessage(t, channel, alert)
+	checkBlocks(t, message)
+
+	if message.Attachments[0].Color != "#E01E5A" {
+		t.Errorf("emergency color = %s, want red", message.Attachments[0].Color)
+	}
+	if !strings.HasPrefix(message.Text, "<@U123> <!subteam^S456> ") {
+		t.Errorf("emergency text = %q, want the critical mentions", message.Text)
+	}
+	resolved := CreateResolvedAlert(AlertTypeEmergency, "", "emergency mode exited")
+	if text := renderSlackMessage(t, channel, resolved).Text; strings.Contains(text, "<@U123>") {
+		t.Errorf("resolved alert mentions on-call: %q", text)
+	}
+}
+
+func TestSlackDetailMessage(t *testing.T) {
+	channel := newTestSlackChannel()
+	alert := CreateAlert(AlertTypeRecommendation, PriorityP3, "Recommendation", "raise limits")
+	for i := 0; i < 12; i++ {
+		alert.Details[string(rune('a'+i))+"_key"] = i
+	}
+
+	blocks := checkBlocks(t, renderSlackMessage(t, channel, alert))
+	if text := blockText(blocks); !strings.Contains(text, "*A Key*\n0") {
+		t.Errorf("detail fields not rendered:\n%s", text)
+	}
+}
+
+func TestSlackSendPostsBlockKit(t *testing.T) {
+	var received slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid_payload"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	channel := newTestSlackChannel()
+	channel.config.WebhookURL = server.URL
+	if err := channel.Send(context.Background(), CreateAlert(AlertTypeRecommendation, PriorityP3, "Recommendation", "")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(received.Attachments) != 1 || len(received.Attachments[0].Blocks) == 0 {
+		t.Errorf("webhook received %+v, want a Block Kit message", received)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   string
+	}{
+		{nil, ""},
+		{[]float64{0, 7}, "▁█"},
+		{[]float64{5, 5, 5}, "███"},
+		{[]float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+// slackAlertManager is a manager routing criticals and payments tenants to the Slack
+// webhook url and every other alert to PagerDuty
+func slackAlertManager(t *testing.T, url string, enabled bool) *Manager {
+	t.Helper()
+	cfg := &config.AlertingConfig{
+		Slack: config.SlackConfig{Enabled: enabled, WebhookURL: url, Channel: "#mimir"},
+		RoutingRules: []config.AlertRoutingRule{
+			{Name: "critical", Condition: `severity == "critical"`, Channels: []string{"slack"}},
+			{Name: "payments", Condition: `tenant =~ "payments-.*"`, Channels: []string{"slack"}},
+			{Name: "rest", Condition: ``, Channels: []string{"pagerduty"}},
+		},
+	}
+	manager := NewManager(cfg, logr.Discard())
+	_ = manager.initializeChannels(cfg)
+	manager.router, manager.policies = manager.compileRouting(cfg)
+	return manager
+}
+
+func TestSlackRoutedBySeverityAndTenant(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		alert *Alert
+		want  int32
+	}{
+		{"critical alert", sampleAlert(AlertTypeCostViolation, PriorityP0, "team-a", nil), 1},
+		{"alert of a payments tenant", sampleAlert(AlertTypeLimitChange, PriorityP3, "payments-eu", nil), 1},
+		{"alert routed elsewhere", sampleAlert(AlertTypeLimitChange, PriorityP3, "team-a", nil), 0},
+	}
+	for _, tt := range tests {
+		for _, enabled := range []bool{true, false} {
+			atomic.StoreInt32(&requests, 0)
+			manager := slackAlertManager(t, server.URL, enabled)
+			_ = manager.SendAlertSync(tt.alert, 5*time.Second)
+
+			want := tt.want
+			if !enabled {
+				// A disabled Slack channel sends nothing, whatever the route
+				want = 0
+			}
+			if got := atomic.LoadInt32(&requests); got != want {
+				t.Errorf("%s with Slack enabled %v: %d webhook requests, want %d", tt.name, enabled, got, want)
+			}
+		}
+	}
+}
+
+func TestSlackConcurrentDuplicatesPostOnce(t *testing.T) {
+	var requests int32
+	fail := int32(1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&fail) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Overlap the concurrent sends with the delivery
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	channel := newTestSlackChannel()
+	channel.config.WebhookURL = server.URL
+	channel.config.DedupWindow = time.Minute
+	alert := sampleAlert(AlertTypeLimitChange, PriorityP2, "tenant-a", nil)
+
+	// A failed delivery does not suppress the retry
+	if err := channel.Send(context.Background(), alert); err == nil {
+		t.Fatalf("Send to a failing webhook succeeded")
+	}
+	atomic.StoreInt32(&fail, 0)
+
+	var wg sync.WaitGroup
+	var sent, duplicates int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch err := channel.Send(context.Background(), alert); {
+			case err == nil:
+				atomic.AddInt32(&sent, 1)
+			case errors.Is(err, ErrDuplicateAlert):
+				atomic.AddInt32(&duplicates, 1)
+			default:
+				t.Errorf("Send: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if requests != 2 || sent != 1 || duplicates != 19 {
+		t.Errorf("%d webhook requests, %d sent, %d duplicates; want the failure and one delivery with 19 duplicates",
+			requests, sent, duplicates)
+	}
+}
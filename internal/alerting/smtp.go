@@ -0,0 +1,167 @@
+package alerting
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// ErrSMTPAuth is returned when the SMTP server rejects the configured credentials
+var ErrSMTPAuth = errors.New("smtp authentication failed")
+
+// SMTP TLS modes
+const (
+	SMTPTLSModeStartTLS = "starttls"
+	SMTPTLSModeImplicit = "tls"
+	SMTPTLSModeNone     = "none"
+)
+
+// SMTPSender delivers pre-built messages over SMTP with STARTTLS or implicit TLS
+type SMTPSender struct {
+	config config.EmailConfig
+}
+
+// NewSMTPSender creates a new SMTP sender
+func NewSMTPSender(cfg config.EmailConfig) *SMTPSender {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &SMTPSender{config: cfg}
+}
+
+// TLSMode returns the effective TLS mode
+func (s *SMTPSender) TLSMode() string {
+	if s.config.TLSMode != "" {
+		return s.config.TLSMode
+	}
+	if s.config.UseTLS {
+		return SMTPTLSModeImplicit
+	}
+	return SMTPTLSModeStartTLS
+}
+
+// Send delivers a message and records the outcome under the given kind ("alert", "digest")
+func (s *SMTPSender) Send(ctx context.Context, kind string, msg []byte) error {
+	err := s.send(ctx, msg)
+
+	result := "success"
+	switch {
+	case errors.Is(err, ErrSMTPAuth):
+		result = "auth_failure"
+		metrics.AlertingMetricsInstance.IncAlertChannelErrors("email", "auth")
+	case err != nil:
+		result = "error"
+		metrics.AlertingMetricsInstance.IncAlertChannelErrors("email", "send")
+	}
+	metrics.AlertingMetricsInstance.IncEmailSent(kind, result)
+
+	return err
+}
+
+// BuildMessage renders the headers and body of an email addressed to the configured recipients
+func (s *SMTPSender) BuildMessage(subject, contentType, body string, extraHeaders map[string]string) []byte {
+	var msg strings.Builder
+
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", s.config.From))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(s.config.To, ",")))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	msg.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString(fmt.Sprintf("Content-Type: %s; charset=UTF-8\r\n", contentType))
+	for key, value := range extraHeaders {
+		msg.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+
+	return []byte(msg.String())
+}
+
+// Dial opens a connection to the SMTP server, negotiating TLS according to the configured mode
+func (s *SMTPSender) Dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.config.SMTPHost, fmt.Sprintf("%d", s.config.SMTPPort))
+	tlsConfig := &tls.Config{ServerName: s.config.SMTPHost}
+
+	deadline := time.Now().Add(s.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if s.TLSMode() == SMTPTLSModeImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+
+	if s.TLSMode() == SMTPTLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			_ = client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+func (s *SMTPSender) send(ctx context.Context, msg []byte) error {
+	client, err := s.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if s.config.Username != "" && s.config.Password != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("%w: %v", ErrSMTPAuth, err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range s.config.To {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := writer.Write(msg); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish message: %w", err)
+	}
+
+	return client.Quit()
+}
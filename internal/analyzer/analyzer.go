@@ -6,6 +6,7 @@ import (
 	"math"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -22,6 +23,9 @@ type TenantLimits struct {
 	LastUpdated time.Time
 	Reason      string
 	Source      string
+	Tier        string // limits.tenantTiers tier the limits were calculated for, if any
+	// Query-path limits left uncalculated as the per-query usage of the tenant was not collected
+	InsufficientData []string
 }
 
 // LimitDefinition defines how to handle a specific limit type
@@ -56,35 +60,100 @@ type Analyzer interface {
 	AnalyzeTrends(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string][]AnalysisResult, error)
 	CalculateLimits(ctx context.Context, analysisResults map[string][]AnalysisResult) (map[string]*TenantLimits, error)
 	DetectSpikes(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string]map[string]bool, error)
+	GetSpikeInfo(tenant, metricName string) *SpikeInfo
+	GetTenantSpikeState(tenant string) *TenantSpikeState
+	GetSeasonalityProfile(tenant string) (*SeasonalityProfile, bool)
+	PredictSpikes(rates, limits map[string]float64, now time.Time) ([]PredictedBreach, []string)
 }
 
 // TrendAnalyzer implements the Analyzer interface
 type TrendAnalyzer struct {
-	config          *config.Config
+	live            *config.Live
 	log             logr.Logger
+
+	// historyMu guards historicalData, which previews read while reconciles update it
+	historyMu       sync.RWMutex
 	historicalData  map[string]map[string][]collector.MetricData
+
+	// mu guards spikeState, which the API reads while reconciles update it
+	mu              sync.RWMutex
 	spikeState      map[string]map[string]*SpikeInfo
+
+	// tierConflicts holds the conflicting tiers last warned about per tenant, so a
+	// conflict is logged when it appears rather than on every reconciliation
+	tierConflicts   map[string]string
+
+	// seasonality detects the weekly usage pattern of each tenant
+	seasonality *SeasonalityDetector
+
+	// predictiveRates holds the ingestion rates observed for predictive spike detection
+	// within the detection window (guarded by mu)
+	predictiveRates map[string][]rateSample
+
+	// now is the clock the analysis windows and spike state are timed with
+	now func() time.Time
 }
 
 // SpikeInfo tracks spike detection state
 type SpikeInfo struct {
 	Detected     bool
 	StartTime    time.Time
-	Multiplier   float64
+	Multiplier   float64 // Multiplier currently applied, decaying after the cooldown
 	BaseValue    float64
 	CooldownUntil time.Time
+
+	Phase          string
+	PeakMultiplier float64   // Multiplier when the decay started
+	LastSpikeTime  time.Time // Last time the spike was observed
+	DecayStartTime time.Time
+
+	// Predictive is set on a spike started before a predicted breach and not observed
+	// since; it is cancelled when the rate stops rising
+	Predictive bool
 }
 
 // NewTrendAnalyzer creates a new TrendAnalyzer
-func NewTrendAnalyzer(cfg *config.Config, log logr.Logger) *TrendAnalyzer {
+func NewTrendAnalyzer(live *config.Live, log logr.Logger) *TrendAnalyzer {
 	return &TrendAnalyzer{
-		config:         cfg,
+		live:           live,
 		log:            log,
 		historicalData: make(map[string]map[string][]collector.MetricData),
 		spikeState:     make(map[string]map[string]*SpikeInfo),
+		tierConflicts:  make(map[string]string),
+		seasonality:    NewSeasonalityDetector(live),
+
+		predictiveRates: make(map[string][]rateSample),
+		now:             time.Now,
 	}
 }
 
+// config returns the configuration in effect
+func (a *TrendAnalyzer) config() *config.Config {
+	return a.live.Load()
+}
+
+// SetClock replaces the clock the analysis windows and spike state are timed with, for
+// analyzing metrics generated on a simulated clock. It must be called before the first
+// analysis.
+func (a *TrendAnalyzer) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// previewKey marks contexts of analyses that must not record their metrics
+type previewKey struct{}
+
+// WithPreview returns a context whose trend analyses leave the historical data and the
+// seasonality profiles unchanged, so previewing limits does not count samples twice
+func WithPreview(ctx context.Context) context.Context {
+	return context.WithValue(ctx, previewKey{}, true)
+}
+
+// isPreview reports whether the context asks for an analysis without recording
+func isPreview(ctx context.Context) bool {
+	preview, _ := ctx.Value(previewKey{}).(bool)
+	return preview
+}
+
 // AnalyzeTrends analyzes trends in tenant metrics
 func (a *TrendAnalyzer) AnalyzeTrends(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string][]AnalysisResult, error) {
 	startTime := time.Now()
@@ -96,7 +165,15 @@ func (a *TrendAnalyzer) AnalyzeTrends(ctx context.Context, tenantMetrics map[str
 	results := make(map[string][]AnalysisResult)
 
 	// Update historical data
-	a.updateHistoricalData(tenantMetrics)
+	preview := isPreview(ctx)
+	if !preview {
+		a.updateHistoricalData(tenantMetrics)
+	}
+	if seasonality := a.config().TrendAnalysis.Seasonality; seasonality.Enabled && !preview {
+		for tenant, tm := range tenantMetrics {
+			a.seasonality.Observe(tenant, tm.Metrics[seasonality.Metric])
+		}
+	}
 
 	for tenant, tm := range tenantMetrics {
 		var tenantResults []AnalysisResult
@@ -115,8 +192,10 @@ func (a *TrendAnalyzer) AnalyzeTrends(ctx context.Context, tenantMetrics map[str
 			tenantResults = append(tenantResults, *analysis)
 
 			// Update metrics
-			metrics.TenantMetricsInstance.SetTenantUsagePercentile(
-				tenant, metricName, fmt.Sprintf("%.0f", a.config.TrendAnalysis.Percentile), analysis.Percentile)
+			if !preview {
+				metrics.TenantMetricsInstance.SetTenantUsagePercentile(
+					tenant, metricName, fmt.Sprintf("%.0f", a.config().TrendAnalysis.Percentile), analysis.Percentile)
+			}
 		}
 
 		if len(tenantResults) > 0 {
@@ -124,7 +203,7 @@ func (a *TrendAnalyzer) AnalyzeTrends(ctx context.Context, tenantMetrics map[str
 		}
 	}
 
-	a.log.Info("analyzed trends", "tenants", len(results))
+	a.log.V(1).Info("analyzed trends", "tenants", len(results))
 
 	return results, nil
 }
@@ -132,14 +211,17 @@ func (a *TrendAnalyzer) AnalyzeTrends(ctx context.Context, tenantMetrics map[str
 // CalculateLimits calculates optimal limits based on analysis results
 func (a *TrendAnalyzer) CalculateLimits(ctx context.Context, analysisResults map[string][]AnalysisResult) (map[string]*TenantLimits, error) {
 	limits := make(map[string]*TenantLimits)
+	preview := isPreview(ctx)
 
 	for tenant, results := range analysisResults {
+		tier := a.resolveTier(tenant)
 		tenantLimits := &TenantLimits{
 			Tenant:      tenant,
 			Limits:      make(map[string]interface{}),
-			LastUpdated: time.Now(),
+			LastUpdated: a.now(),
 			Reason:      "trend-analysis",
 			Source:      "analyzer",
+			Tier:        tier,
 		}
 
 		// Calculate limits based on different metrics
@@ -147,11 +229,29 @@ func (a *TrendAnalyzer) CalculateLimits(ctx context.Context, analysisResults map
 			a.applyMetricToLimits(tenantLimits, result)
 		}
 
+		// Calculate the query-path limits from the per-query usage only
+		a.applyQueryPathLimits(tenantLimits, results)
+
+		// Carry configured bool/string limits through unchanged
+		a.applyPassthroughLimits(tenantLimits)
+
 		// Apply buffer percentage
 		a.applyBufferPercentage(tenantLimits, tenant)
 
+		// Overlay the tier's limits as floors and defaults
+		a.applyTierLimits(tenantLimits)
+
 		// Apply min/max constraints
-		a.applyConstraints(tenantLimits, tenant)
+		a.applyConstraints(tenantLimits, tenant, !preview)
+
+		// Thanos Ruler only enforces the rule group limits
+		if a.config().Mimir.ThanosModeEnabled {
+			for limitName := range tenantLimits.Limits {
+				if config.IsRulerLimit(limitName) && config.ThanosRulerLimits[limitName] == "" {
+					delete(tenantLimits.Limits, limitName)
+				}
+			}
+		}
 
 		limits[tenant] = tenantLimits
 	}
@@ -159,12 +259,16 @@ func (a *TrendAnalyzer) CalculateLimits(ctx context.Context, analysisResults map
 	return limits, nil
 }
 
-// DetectSpikes detects usage spikes in real-time
+// DetectSpikes detects usage spikes in real-time and advances the cooldown and decay
+// of earlier spikes. It returns the spikes that started in this call.
 func (a *TrendAnalyzer) DetectSpikes(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string]map[string]bool, error) {
-	if !a.config.EventSpike.Enabled {
+	if !a.config().EventSpike.Enabled {
 		return nil, nil
 	}
 
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	spikes := make(map[string]map[string]bool)
 
 	for tenant, tm := range tenantMetrics {
@@ -185,6 +289,7 @@ func (a *TrendAnalyzer) DetectSpikes(ctx context.Context, tenantMetrics map[stri
 		if len(tenantSpikes) > 0 {
 			spikes[tenant] = tenantSpikes
 		}
+		a.updateSpikeMetrics(tenant)
 	}
 
 	return spikes, nil
@@ -198,7 +303,7 @@ func (a *TrendAnalyzer) analyzeMetric(tenant, metricName string, data []collecto
 
 	// Get historical data for better analysis
 	historical := a.getHistoricalData(tenant, metricName)
-	allData := append(historical, data...)
+	allData := append(append([]collector.MetricData(nil), historical...), data...)
 
 	// Sort by timestamp
 	sort.Slice(allData, func(i, j int) bool {
@@ -206,7 +311,7 @@ func (a *TrendAnalyzer) analyzeMetric(tenant, metricName string, data []collecto
 	})
 
 	// Filter data within analysis window
-	cutoff := time.Now().Add(-a.config.TrendAnalysis.AnalysisWindow)
+	cutoff := a.now().Add(-a.config().TrendAnalysis.AnalysisWindow)
 	var windowData []collector.MetricData
 	for _, d := range allData {
 		if d.Timestamp.After(cutoff) {
@@ -228,19 +333,19 @@ func (a *TrendAnalyzer) analyzeMetric(tenant, metricName string, data []collecto
 		Tenant:       tenant,
 		MetricName:   metricName,
 		CurrentValue: values[len(values)-1],
-		AnalysisTime: time.Now(),
+		AnalysisTime: a.now(),
 	}
 
 	// Calculate moving average
-	if a.config.TrendAnalysis.UseMovingAverage {
+	if a.config().TrendAnalysis.UseMovingAverage {
 		result.MovingAverage = a.calculateMovingAverage(values)
 	}
 
 	// Calculate percentile
-	result.Percentile = a.calculatePercentile(values, a.config.TrendAnalysis.Percentile)
+	result.Percentile = a.calculatePercentile(values, a.config().TrendAnalysis.Percentile)
 
 	// Calculate peak
-	if a.config.TrendAnalysis.IncludePeaks {
+	if a.config().TrendAnalysis.IncludePeaks {
 		result.Peak = a.calculatePeak(values)
 	}
 
@@ -248,12 +353,14 @@ func (a *TrendAnalyzer) analyzeMetric(tenant, metricName string, data []collecto
 	result.Trend = a.calculateTrend(values)
 
 	// Check for spikes
-	if a.config.EventSpike.Enabled {
+	if a.config().EventSpike.Enabled {
+		a.mu.RLock()
 		spikeInfo := a.getSpikeInfo(tenant, metricName)
-		if spikeInfo != nil && spikeInfo.Detected {
+		if spikeInfo != nil && spikeInfo.Elevated() {
 			result.SpikeDetected = true
 			result.SpikeMultiplier = spikeInfo.Multiplier
 		}
+		a.mu.RUnlock()
 	}
 
 	// Calculate recommended limit
@@ -264,6 +371,9 @@ func (a *TrendAnalyzer) analyzeMetric(tenant, metricName string, data []collecto
 
 // updateHistoricalData updates the historical data cache
 func (a *TrendAnalyzer) updateHistoricalData(tenantMetrics map[string]*collector.TenantMetrics) {
+	a.historyMu.Lock()
+	defer a.historyMu.Unlock()
+
 	for tenant, tm := range tenantMetrics {
 		if a.historicalData[tenant] == nil {
 			a.historicalData[tenant] = make(map[string][]collector.MetricData)
@@ -274,7 +384,7 @@ func (a *TrendAnalyzer) updateHistoricalData(tenantMetrics map[string]*collector
 			a.historicalData[tenant][metricName] = append(a.historicalData[tenant][metricName], data...)
 
 			// Cleanup old data (keep only data within analysis window + buffer)
-			cutoff := time.Now().Add(-a.config.TrendAnalysis.AnalysisWindow * 2)
+			cutoff := a.now().Add(-a.config().TrendAnalysis.AnalysisWindow * 2)
 			var filtered []collector.MetricData
 			for _, d := range a.historicalData[tenant][metricName] {
 				if d.Timestamp.After(cutoff) {
@@ -286,15 +396,25 @@ func (a *TrendAnalyzer) updateHistoricalData(tenantMetrics map[string]*collector
 	}
 }
 
-// getHistoricalData retrieves historical data for a metric
+// SeedHistory adds the samples kept by a previous run to the historical data, so the
+// first analysis after a restart covers the same window as an uninterrupted run
+func (a *TrendAnalyzer) SeedHistory(tenantMetrics map[string]*collector.TenantMetrics) {
+	a.updateHistoricalData(tenantMetrics)
+}
+
+// getHistoricalData returns a copy of the historical data of a metric
 func (a *TrendAnalyzer) getHistoricalData(tenant, metricName string) []collector.MetricData {
+	a.historyMu.RLock()
+	defer a.historyMu.RUnlock()
+
 	if a.historicalData[tenant] == nil {
 		return nil
 	}
-	return a.historicalData[tenant][metricName]
+	return append([]collector.MetricData(nil), a.historicalData[tenant][metricName]...)
 }
 
-// detectSpikeForMetric detects spikes for a specific metric
+// detectSpikeForMetric detects spikes for a specific metric and reports whether a
+// new spike started; callers must hold a.mu
 func (a *TrendAnalyzer) detectSpikeForMetric(tenant, metricName string, data []collector.MetricData) bool {
 	if len(data) == 0 {
 		return false
@@ -303,23 +423,46 @@ func (a *TrendAnalyzer) detectSpikeForMetric(tenant, metricName string, data []c
 	// Get spike state
 	spikeInfo := a.getSpikeInfo(tenant, metricName)
 	if spikeInfo == nil {
-		spikeInfo = &SpikeInfo{}
+		spikeInfo = &SpikeInfo{Phase: SpikePhaseNone, Multiplier: 1.0}
 		a.setSpikeInfo(tenant, metricName, spikeInfo)
 	}
 
-	// Check if we're in cooldown
-	if time.Now().Before(spikeInfo.CooldownUntil) {
-		return spikeInfo.Detected
+	now := a.now()
+	baseline, ok := a.spikeBaseline(tenant, metricName)
+	currentValue := data[len(data)-1].Value
+
+	// Check for spike
+	if ok && currentValue > baseline*a.config().EventSpike.Threshold {
+		multiplier := math.Min(currentValue/baseline, a.config().EventSpike.MaxSpikeMultiplier)
+		if a.recordSpike(spikeInfo, multiplier, baseline, now) {
+			return true
+		}
+		a.log.V(1).Info("spike extended", "tenant", tenant, "metric", metricName,
+			"multiplier", spikeInfo.Multiplier, "cooldown_until", spikeInfo.CooldownUntil)
+		return false
+	}
+
+	// No spike observed: keep the elevated limit through the cooldown, then decay it
+	previousPhase := spikeInfo.Phase
+	a.advanceSpike(spikeInfo, now)
+	if spikeInfo.Phase != previousPhase {
+		a.log.Info("spike state changed", "tenant", tenant, "metric", metricName,
+			"from", previousPhase, "to", spikeInfo.Phase, "multiplier", spikeInfo.Multiplier)
 	}
 
-	// Get recent baseline
+	return false
+}
+
+// spikeBaseline returns the average of the metric's values from before the detection
+// window, or false when there is not enough history for a baseline
+func (a *TrendAnalyzer) spikeBaseline(tenant, metricName string) (float64, bool) {
 	historical := a.getHistoricalData(tenant, metricName)
 	if len(historical) < 10 { // Need enough data for baseline
-		return false
+		return 0, false
 	}
 
 	// Calculate baseline (average of older data)
-	baselineCutoff := time.Now().Add(-a.config.EventSpike.DetectionWindow * 2)
+	baselineCutoff := a.now().Add(-a.config().EventSpike.DetectionWindow * 2)
 	var baselineValues []float64
 	for _, d := range historical {
 		if d.Timestamp.Before(baselineCutoff) {
@@ -328,35 +471,11 @@ func (a *TrendAnalyzer) detectSpikeForMetric(tenant, metricName string, data []c
 	}
 
 	if len(baselineValues) < 5 {
-		return false
+		return 0, false
 	}
 
 	baseline := a.calculateMovingAverage(baselineValues)
-	currentValue := data[len(data)-1].Value
-
-	// Check for spike
-	if currentValue > baseline*a.config.EventSpike.Threshold {
-		if !spikeInfo.Detected {
-			// New spike detected
-			spikeInfo.Detected = true
-			spikeInfo.StartTime = time.Now()
-			spikeInfo.BaseValue = baseline
-			spikeInfo.Multiplier = math.Min(currentValue/baseline, a.config.EventSpike.MaxSpikeMultiplier)
-			spikeInfo.CooldownUntil = time.Now().Add(a.config.EventSpike.CooldownPeriod)
-
-			metrics.SpikeMetricsInstance.SetSpikeMultiplier(tenant, spikeInfo.Multiplier)
-		}
-		return true
-	}
-
-	// No spike detected, reset if previously detected
-	if spikeInfo.Detected {
-		spikeInfo.Detected = false
-		spikeInfo.Multiplier = 1.0
-		metrics.SpikeMetricsInstance.SetSpikeMultiplier(tenant, 1.0)
-	}
-
-	return false
+	return baseline, baseline > 0
 }
 
 // Helper methods for calculations
@@ -432,11 +551,11 @@ func (a *TrendAnalyzer) calculateTrend(values []float64) float64 {
 func (a *TrendAnalyzer) calculateRecommendedLimit(result *AnalysisResult) float64 {
 	base := result.Percentile
 
-	if a.config.TrendAnalysis.UseMovingAverage && result.MovingAverage > 0 {
+	if a.config().TrendAnalysis.UseMovingAverage && result.MovingAverage > 0 {
 		base = math.Max(base, result.MovingAverage)
 	}
 
-	if a.config.TrendAnalysis.IncludePeaks && result.Peak > 0 {
+	if a.config().TrendAnalysis.IncludePeaks && result.Peak > 0 {
 		base = math.Max(base, result.Peak*0.8) // Use 80% of peak
 	}
 
@@ -562,6 +681,19 @@ func (a *TrendAnalyzer) isAnalyzableMetric(metricName string) bool {
 	return false
 }
 
+// GetSpikeInfo returns a copy of the current spike state for a tenant metric, or nil if none is tracked
+func (a *TrendAnalyzer) GetSpikeInfo(tenant, metricName string) *SpikeInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	info := a.getSpikeInfo(tenant, metricName)
+	if info == nil {
+		return nil
+	}
+	spikeInfo := *info
+	return &spikeInfo
+}
+
 func (a *TrendAnalyzer) getSpikeInfo(tenant, metricName string) *SpikeInfo {
 	if a.spikeState[tenant] == nil {
 		return nil
@@ -583,158 +715,360 @@ func (a *TrendAnalyzer) applyMetricToLimits(limits *TenantLimits, result Analysi
 	
 	if limitName, exists := limitMapping[result.MetricName]; exists {
 		// Check if this limit is enabled in configuration
-		if limitDef, found := a.config.DynamicLimits.LimitDefinitions[limitName]; found && limitDef.Enabled {
+		if limitDef, found := a.config().DynamicLimits.LimitDefinitions[limitName]; found && limitDef.Enabled {
 			limits.Limits[limitName] = result.RecommendedLimit
 		}
 	}
 }
 
+// applyQueryPathLimits sets each enabled query-path limit to the recommendation of its
+// per-query usage histogram. Limits whose histogram was not collected for the tenant are
+// left out and listed as having insufficient data, rather than falling back to another
+// metric or the default.
+func (a *TrendAnalyzer) applyQueryPathLimits(limits *TenantLimits, results []AnalysisResult) {
+	limitNames := make([]string, 0, len(config.QueryPathLimits))
+	queryPathMetrics := a.config().QueryPathMetrics()
+	for limitName := range queryPathMetrics {
+		limitNames = append(limitNames, limitName)
+	}
+	sort.Strings(limitNames)
+
+	for _, limitName := range limitNames {
+		delete(limits.Limits, limitName)
+		for _, result := range results {
+			if result.MetricName == queryPathMetrics[limitName] {
+				limits.Limits[limitName] = result.RecommendedLimit
+			}
+		}
+		if _, calculated := limits.Limits[limitName]; !calculated {
+			limits.InsufficientData = append(limits.InsufficientData, limitName)
+		}
+	}
+
+	if len(limits.InsufficientData) > 0 {
+		a.log.V(1).Info("insufficient per-query usage for query-path limits",
+			"tenant", limits.Tenant,
+			"limits", limits.InsufficientData)
+	}
+}
+
 // getMetricToLimitMapping returns mapping from metric names to Mimir limit names
 func (a *TrendAnalyzer) getMetricToLimitMapping() map[string]string {
-	return map[string]string{
-		// MIMIR/CORTEX METRICS - Primary mappings for Mimir deployments
-		"cortex_distributor_received_samples_total":     "ingestion_rate",
-		"cortex_distributor_samples_in_total":           "ingestion_rate",
-		"cortex_ingester_ingested_samples_total":        "ingestion_rate",
-		"cortex_ingester_memory_series":                 "max_global_series_per_user",
-		"cortex_ingester_memory_users":                  "max_tenants",
-		"cortex_query_frontend_queries_total":           "max_samples_per_query",
-		"cortex_querier_queries_total":                  "max_samples_per_query",
-		"cortex_query_frontend_query_duration_seconds":  "query_timeout",
-		"cortex_querier_query_duration_seconds":         "query_timeout",
-		"cortex_ingester_ingested_samples_failures_total": "ingestion_rate",
-		
-		// BYTE-BASED INGESTION METRICS - NEW COMPREHENSIVE MAPPINGS
-		"cortex_distributor_received_samples_bytes_total": "max_ingestion_rate_bytes",
-		"cortex_distributor_push_duration_seconds":       "remote_write_deadline",
-		"cortex_distributor_latest_seen_sample_timestamp_seconds": "max_sample_age",
-		"cortex_ingester_oldest_unshipped_block_timestamp_seconds": "max_chunk_age",
-		"cortex_ingester_chunk_size_bytes":               "max_chunk_size_bytes",
-		
-		// QUERY SCHEDULER METRICS - NEW COMPREHENSIVE MAPPINGS
-		"cortex_query_scheduler_queue_length":            "query_scheduler_max_outstanding_requests_per_tenant",
-		"cortex_query_scheduler_queriers_connected":      "query_scheduler_max_queriers_per_tenant", 
-		"cortex_query_scheduler_queries_in_progress":     "query_scheduler_max_active_requests",
-		"cortex_query_frontend_queue_length":             "max_outstanding_per_tenant",
-		"cortex_query_frontend_queries_in_progress":      "max_concurrent_queries",
-		
-		// STORAGE GATEWAY METRICS - NEW COMPREHENSIVE MAPPINGS
-		"cortex_bucket_store_queries_in_flight":          "store_gateway_max_queries_in_flight",
-		
-		// REQUEST & CONCURRENCY METRICS - NEW COMPREHENSIVE MAPPINGS
-		"cortex_request_duration_seconds":                "max_concurrent_requests",
-		"cortex_querier_chunks_fetched_bytes":            "max_bytes_per_query",
-		
-		// CARDINALITY & SERIES METRICS - ENHANCED MAPPINGS
-		"cortex_ingester_memory_series_per_metric":       "max_global_series_per_metric",
-		"cortex_ingester_memory_metadata":                "max_global_metadata_per_user",
-		"cortex_ingester_memory_metadata_per_metric":     "max_global_metadata_per_metric",
-		
-		// PROMETHEUS METRICS - Fallback mappings for Prometheus deployments
-		"prometheus_remote_storage_samples_in_total":     "ingestion_rate",
-		"prometheus_remote_storage_samples_burst":        "ingestion_burst_size",
-		"prometheus_tsdb_head_series":                    "max_global_series_per_user",
-		"prometheus_tsdb_blocks_loaded":                  "retention_period",
-		"prometheus_engine_query_samples_total":          "max_samples_per_query",
-		"prometheus_engine_query_series_total":           "max_series_per_query",
-		"prometheus_tsdb_head_chunks":                    "max_fetched_chunks_per_query",
-		"prometheus_tsdb_compaction_chunk_size_bytes":    "max_fetched_chunk_bytes_per_query",
-		"prometheus_tsdb_exemplar_exemplars_total":       "max_global_exemplars_per_user",
-		"prometheus_rule_group_rules":                    "ruler_max_rules_per_rule_group",
-		"alertmanager_notifications_total":              "alertmanager_notification_rate_limit",
-		"alertmanager_alerts":                            "alertmanager_max_alerts_count",
-		"http_requests_total":                            "request_rate",
-		
-		// EXTENDED MIMIR METRICS - Comprehensive limit support
-		"cortex_distributor_deduped_samples_total":       "ingestion_rate",
-		"cortex_distributor_non_ha_samples_received_total": "ingestion_rate",
-		"cortex_ingester_chunks_created_total":           "max_chunks_per_query",
-		"cortex_ingester_series_removed_total":           "max_global_series_per_user",
-		"cortex_querier_series_fetched_total":            "max_series_per_query",
-		"cortex_querier_series_fetched":                  "max_fetched_series_per_query",
-		"cortex_querier_chunks_fetched_total":            "max_fetched_chunks_per_query",
-		"cortex_querier_chunks_fetched":                  "max_fetched_chunks_per_query",
-		"cortex_querier_estimated_series_count":          "max_series_per_query",
-		"cortex_querier_estimated_memory_consumption_bytes": "max_estimated_memory_consumption_per_query",
-		"cortex_querier_estimated_chunks_fetched":        "max_estimated_fetched_chunks_per_query",
-		"cortex_querier_exemplars_fetched":               "max_exemplars_per_query",
-		"cortex_compactor_runs_total":                    "compactor_blocks_retention_period",
-		"cortex_ruler_queries_total":                     "ruler_max_rule_groups_per_tenant",
-		"cortex_ruler_rule_group_rules":                  "ruler_max_rules_per_rule_group",
-		"cortex_ruler_rule_groups_per_user":              "ruler_max_rule_groups_per_tenant",
-		"cortex_ruler_rules_per_user":                    "ruler_max_rules_per_tenant",
-		
-		// ALERTMANAGER METRICS - Comprehensive coverage
-		"cortex_alertmanager_notifications_total":        "alertmanager_notification_rate_limit",
-		"cortex_alertmanager_dispatcher_aggregation_groups": "alertmanager_max_dispatcher_aggregation_groups",
-		"cortex_alertmanager_alerts":                     "alertmanager_max_alerts_count",
-		"cortex_alertmanager_alerts_size_bytes":          "alertmanager_max_alerts_size_bytes",
-		
-		// INGESTER TSDB METRICS - Enhanced coverage
-		"cortex_ingester_tsdb_exemplar_series_with_exemplars_in_storage": "max_global_exemplars_per_user",
-		"cortex_ingester_active_series":                  "max_label_names_per_series",
+	return metricToLimitMapping
+}
+
+// LimitForMetric returns the Mimir limit whose recommendation is calculated from a metric
+func LimitForMetric(metricName string) (string, bool) {
+	limitName, exists := metricToLimitMapping[metricName]
+	return limitName, exists
+}
+
+// metricToLimitMapping maps metric names to the Mimir limits calculated from them
+var metricToLimitMapping = map[string]string{
+	// MIMIR/CORTEX METRICS - Primary mappings for Mimir deployments
+	"cortex_distributor_received_samples_total":     "ingestion_rate",
+	"cortex_distributor_samples_in_total":           "ingestion_rate",
+	"cortex_ingester_ingested_samples_total":        "ingestion_rate",
+	"cortex_ingester_memory_series":                 "max_global_series_per_user",
+	"cortex_ingester_memory_users":                  "max_tenants",
+	"cortex_querier_samples_per_query":              "max_samples_per_query",
+	"cortex_query_frontend_query_duration_seconds":  "query_timeout",
+	"cortex_querier_query_duration_seconds":         "query_timeout",
+	"cortex_ingester_ingested_samples_failures_total": "ingestion_rate",
+	
+	// BYTE-BASED INGESTION METRICS - NEW COMPREHENSIVE MAPPINGS
+	"cortex_distributor_received_samples_bytes_total": "max_ingestion_rate_bytes",
+	"cortex_distributor_push_duration_seconds":       "remote_write_deadline",
+	"cortex_distributor_latest_seen_sample_timestamp_seconds": "max_sample_age",
+	"cortex_ingester_oldest_unshipped_block_timestamp_seconds": "max_chunk_age",
+	"cortex_ingester_chunk_size_bytes":               "max_chunk_size_bytes",
+	
+	// QUERY SCHEDULER METRICS - NEW COMPREHENSIVE MAPPINGS
+	"cortex_query_scheduler_queue_length":            "query_scheduler_max_outstanding_requests_per_tenant",
+	"cortex_query_scheduler_queriers_connected":      "query_scheduler_max_queriers_per_tenant", 
+	"cortex_query_scheduler_queries_in_progress":     "query_scheduler_max_active_requests",
+	"cortex_query_frontend_queue_length":             "max_outstanding_per_tenant",
+	"cortex_query_frontend_queries_in_progress":      "max_concurrent_queries",
+	
+	// STORAGE GATEWAY METRICS - NEW COMPREHENSIVE MAPPINGS
+	"cortex_bucket_store_queries_in_flight":          "store_gateway_max_queries_in_flight",
+	
+	// REQUEST & CONCURRENCY METRICS - NEW COMPREHENSIVE MAPPINGS
+	"cortex_request_duration_seconds":                "max_concurrent_requests",
+	"cortex_querier_chunks_fetched_bytes":            "max_bytes_per_query",
+	
+	// CARDINALITY & SERIES METRICS - ENHANCED MAPPINGS
+	"cortex_ingester_memory_series_per_metric":       "max_global_series_per_metric",
+	"cortex_ingester_memory_metadata":                "max_global_metadata_per_user",
+	"cortex_ingester_memory_metadata_per_metric":     "max_global_metadata_per_metric",
+	
+	// PROMETHEUS METRICS - Fallback mappings for Prometheus deployments
+	"prometheus_remote_storage_samples_in_total":     "ingestion_rate",
+	"prometheus_remote_storage_samples_burst":        "ingestion_burst_size",
+	"prometheus_tsdb_head_series":                    "max_global_series_per_user",
+	"prometheus_tsdb_blocks_loaded":                  "retention_period",
+	"prometheus_engine_query_samples_total":          "max_samples_per_query",
+	"prometheus_engine_query_series_total":           "max_series_per_query",
+	"prometheus_tsdb_head_chunks":                    "max_fetched_chunks_per_query",
+	"prometheus_tsdb_compaction_chunk_size_bytes":    "max_fetched_chunk_bytes_per_query",
+	"prometheus_tsdb_exemplar_exemplars_total":       "max_global_exemplars_per_user",
+	"prometheus_rule_group_rules":                    "ruler_max_rules_per_rule_group",
+	"alertmanager_notifications_total":              "alertmanager_notification_rate_limit",
+	"alertmanager_alerts":                            "alertmanager_max_alerts_count",
+	"http_requests_total":                            "request_rate",
+	
+	// EXTENDED MIMIR METRICS - Comprehensive limit support
+	"cortex_distributor_deduped_samples_total":       "ingestion_rate",
+	"cortex_distributor_non_ha_samples_received_total": "ingestion_rate",
+	"cortex_ingester_chunks_created_total":           "max_chunks_per_query",
+	"cortex_ingester_series_removed_total":           "max_global_series_per_user",
+	"cortex_querier_series_fetched_total":            "max_series_per_query",
+	"cortex_querier_series_fetched":                  "max_fetched_series_per_query",
+	"cortex_querier_chunks_fetched_total":            "max_fetched_chunks_per_query",
+	"cortex_querier_chunks_fetched":                  "max_fetched_chunks_per_query",
+	"cortex_querier_estimated_series_count":          "max_series_per_query",
+	"cortex_querier_estimated_memory_consumption_bytes": "max_estimated_memory_consumption_per_query",
+	"cortex_querier_estimated_chunks_fetched":        "max_estimated_fetched_chunks_per_query",
+	"cortex_querier_exemplars_fetched":               "max_exemplars_per_query",
+	"cortex_compactor_runs_total":                    "compactor_blocks_retention_period",
+	"cortex_ruler_queries_total":                     "ruler_max_rule_groups_per_tenant",
+	"cortex_ruler_rule_group_rules":                  "ruler_max_rules_per_rule_group",
+	"cortex_ruler_rule_groups_per_user":              "ruler_max_rule_groups_per_tenant",
+	"cortex_ruler_rules_per_user":                    "ruler_max_rules_per_tenant",
+	
+	// ALERTMANAGER METRICS - Comprehensive coverage
+	"cortex_alertmanager_notifications_total":        "alertmanager_notification_rate_limit",
+	"cortex_alertmanager_dispatcher_aggregation_groups": "alertmanager_max_dispatcher_aggregation_groups",
+	"cortex_alertmanager_alerts":                     "alertmanager_max_alerts_count",
+	"cortex_alertmanager_alerts_size_bytes":          "alertmanager_max_alerts_size_bytes",
+	
+	// INGESTER TSDB METRICS - Enhanced coverage
+	"cortex_ingester_tsdb_exemplar_series_with_exemplars_in_storage": "max_global_exemplars_per_user",
+	"cortex_ingester_active_series":                  "max_label_names_per_series",
+}
+
+// applyPassthroughLimits copies enabled bool and string limits from the configured
+// default limits verbatim. These have no recommendation to calculate and are never
+// buffered or clamped.
+func (a *TrendAnalyzer) applyPassthroughLimits(limits *TenantLimits) {
+	for limitName, limitDef := range a.config().DynamicLimits.LimitDefinitions {
+		if !limitDef.Enabled || (limitDef.Type != "bool" && limitDef.Type != "string") {
+			continue
+		}
+		if value, exists := a.config().Limits.DefaultLimits[limitName]; exists {
+			limits.Limits[limitName] = value
+		}
 	}
 }
 
-// applyBufferPercentage applies buffer to all dynamic limits
+// applyBufferPercentage applies buffer to all dynamic limits, raised by the seasonal
+// buffer of the current hour for the buffered limit types
 func (a *TrendAnalyzer) applyBufferPercentage(limits *TenantLimits, tenant string) {
+	seasonalBuffer := a.seasonalBufferPercent(tenant, a.now())
 	for limitName, limitValue := range limits.Limits {
-		if limitDef, exists := a.config.DynamicLimits.LimitDefinitions[limitName]; exists {
-			bufferFactor := limitDef.BufferFactor
-			if bufferFactor == 0 {
-				bufferFactor = a.config.DynamicLimits.DefaultBuffer
-			}
+		if limitDef, exists := a.config().DynamicLimits.LimitDefinitions[limitName]; exists {
+			bufferFactor := TierLimitBufferPercent(a.config(), limits.Tier, limitName) + seasonalBuffer
 			
-			// Apply buffer based on limit type
+			// Only calculated recommendations are buffered: numbers, with durations
+			// arriving as latency quantiles in seconds
+			if _, calculated := limitValue.(float64); !calculated {
+				continue
+			}
 			switch limitDef.Type {
 			case "rate", "count", "size":
-				if val, ok := limitValue.(float64); ok && bufferFactor > 0 {
-					bufferedValue := val * (1 + bufferFactor/100)
-					limits.Limits[limitName] = bufferedValue
+				if bufferFactor <= 0 {
+					continue
 				}
-			case "percentage":
-				// Percentage limits don't typically need buffers
 			case "duration":
-				// Duration limits are handled as strings, skip buffering
+			default:
+				// Percentage limits don't typically need buffers
+				continue
+			}
+
+			value, err := config.ParseLimitValue(limitValue, limitDef.Type)
+			if err != nil {
+				a.log.V(1).Info("skipping buffer of invalid limit value", "tenant", tenant, "limit", limitName, "error", err.Error())
+				continue
+			}
+			buffered := value.ApplyBuffer(bufferFactor)
+			if d, isDuration := buffered.Duration(); isDuration {
+				buffered = config.DurationValue(roundDuration(d))
+			}
+			limits.Limits[limitName] = calculatedValue(buffered)
+		}
+	}
+}
+
+// LimitBufferPercent returns the buffer, in percent, added on top of a limit's
+// recommendation: the limit definition's buffer factor, or dynamicLimits.defaultBuffer
+// when the definition has none. Limit types that are not buffered return 0.
+func LimitBufferPercent(cfg *config.Config, limitName string) float64 {
+	limitDef, exists := cfg.DynamicLimits.LimitDefinitions[limitName]
+	if !exists {
+		return 0
+	}
+	switch limitDef.Type {
+	case "rate", "count", "size", "duration":
+	default:
+		return 0
+	}
+	if limitDef.BufferFactor == 0 {
+		return cfg.DynamicLimits.DefaultBuffer
+	}
+	return limitDef.BufferFactor
+}
+
+// TierLimitBufferPercent returns the buffer, in percent, added on top of a limit's
+// recommendation for tenants in a tier: the tier's bufferPercentage when set, and
+// LimitBufferPercent otherwise
+func TierLimitBufferPercent(cfg *config.Config, tier, limitName string) float64 {
+	tierConfig, exists := cfg.Limits.TenantTiers[tier]
+	if !exists || tierConfig.BufferPercentage <= 0 {
+		return LimitBufferPercent(cfg, limitName)
+	}
+	switch cfg.DynamicLimits.LimitDefinitions[limitName].Type {
+	case "rate", "count", "size", "duration":
+		return tierConfig.BufferPercentage
+	default:
+		return 0
+	}
+}
+
+// resolveTier returns the tier of a tenant, logging when it matches more than one tier
+func (a *TrendAnalyzer) resolveTier(tenant string) string {
+	assignment := a.config().Limits.TierForTenant(tenant)
+	conflicts := strings.Join(assignment.Conflicts, ",")
+
+	a.mu.Lock()
+	warned := a.tierConflicts[tenant]
+	if conflicts == "" {
+		delete(a.tierConflicts, tenant)
+	} else {
+		a.tierConflicts[tenant] = conflicts
+	}
+	a.mu.Unlock()
+
+	if conflicts != "" && conflicts != warned {
+		a.log.Info("warning: tenant matches more than one tier, using the most specific pattern",
+			"tenant", tenant,
+			"tier", assignment.Tier,
+			"pattern", assignment.Pattern,
+			"conflicting_tiers", assignment.Conflicts)
+	}
+	return assignment.Tier
+}
+
+// applyTierLimits overlays the limits of the tenant's tier on the calculated limits.
+// Numeric and duration tier limits are floors, raising lower recommendations; these
+// and the other tier limits are also the defaults of limits not calculated. The
+// global min/max constraints are still applied afterwards.
+func (a *TrendAnalyzer) applyTierLimits(limits *TenantLimits) {
+	tier, exists := a.config().Limits.TenantTiers[limits.Tier]
+	if !exists {
+		return
+	}
+
+	for limitName, tierValue := range tier.Limits {
+		limitDef, exists := a.config().DynamicLimits.LimitDefinitions[limitName]
+		if !exists || !limitDef.Enabled {
+			continue
+		}
+		current, calculated := limits.Limits[limitName]
+
+		switch limitDef.Type {
+		case "rate", "count", "size", "percentage", "duration":
+			floor, err := config.ParseLimitValue(tierValue, limitDef.Type)
+			if err != nil {
+				continue
 			}
+			value, err := config.ParseLimitValue(current, limitDef.Type)
+			if cmp, cmpErr := value.Compare(floor); !calculated || err != nil || cmpErr != nil || cmp < 0 {
+				limits.Limits[limitName] = calculatedValue(floor)
+			}
+		default:
+			limits.Limits[limitName] = tierValue
 		}
 	}
 }
 
-// applyConstraints applies min/max constraints to all dynamic limits
-func (a *TrendAnalyzer) applyConstraints(limits *TenantLimits, tenant string) {
+// applyConstraints clamps all dynamic limits to their enforced floor and ceiling:
+// the operator's limits.minLimits and limits.maxLimits, falling back to the limit
+// definition's MinValue and MaxValue. Clamped recommendations are counted when record is
+// set, which previews leave unset.
+func (a *TrendAnalyzer) applyConstraints(limits *TenantLimits, tenant string, record bool) {
 	for limitName, limitValue := range limits.Limits {
-		if limitDef, exists := a.config.DynamicLimits.LimitDefinitions[limitName]; exists {
-			// Apply constraints based on limit type
+		if limitDef, exists := a.config().DynamicLimits.LimitDefinitions[limitName]; exists {
 			switch limitDef.Type {
-			case "rate", "count", "size", "percentage":
-				val, ok := limitValue.(float64)
-				if !ok {
-					continue
-				}
-				
-				// Apply minimum constraint
-				if limitDef.MinValue != nil {
-					if minVal, ok := limitDef.MinValue.(float64); ok && val < minVal {
-						limits.Limits[limitName] = minVal
-						val = minVal
-					}
-				}
-				
-				// Apply maximum constraint
-				if limitDef.MaxValue != nil {
-					if maxVal, ok := limitDef.MaxValue.(float64); ok && val > maxVal {
-						limits.Limits[limitName] = maxVal
-					}
+			case "rate", "count", "size", "percentage", "duration":
+			default:
+				continue
+			}
+
+			value, err := config.ParseLimitValue(limitValue, limitDef.Type)
+			if err != nil {
+				continue
+			}
+			bounds := a.config().GetLimitBounds(limitName)
+			if record {
+				if cmp, err := value.Compare(bounds.Floor); err == nil && cmp < 0 {
+					metrics.TenantMetricsInstance.IncRecommendationsClamped("min")
+				} else if cmp, err := value.Compare(bounds.Ceiling); err == nil && cmp > 0 {
+					metrics.TenantMetricsInstance.IncRecommendationsClamped("max")
 				}
-			case "duration":
-				// Duration constraints would need parsing - skip for now
 			}
+			limits.Limits[limitName] = calculatedValue(value.Clamp(bounds.Floor, bounds.Ceiling))
 		}
 	}
 }
 
+// calculatedValue returns a typed limit value in the representation of calculated
+// limits, float64 numbers and time.Duration durations, which the patcher formats for
+// Mimir when writing the overrides
+func calculatedValue(value config.LimitValue) interface{} {
+	if d, isDuration := value.Duration(); isDuration {
+		return d
+	}
+	if n, isNumber := value.Number(); isNumber {
+		return n
+	}
+	return value.Interface()
+}
+
+// roundDuration rounds a duration up to a granularity that reads naturally in
+// Mimir configuration: whole seconds below a minute, whole minutes below an
+// hour, and whole hours beyond that
+func roundDuration(d time.Duration) time.Duration {
+	granularity := time.Second
+	if d >= time.Hour {
+		granularity = time.Hour
+	} else if d >= time.Minute {
+		granularity = time.Minute
+	}
+
+	rounded := d.Truncate(granularity)
+	if rounded < d {
+		rounded += granularity
+	}
+	return rounded
+}
+
+// GetSeasonalityProfile returns the weekly usage pattern detected for a tenant, or false
+// for a tenant whose usage was never observed
+func (a *TrendAnalyzer) GetSeasonalityProfile(tenant string) (*SeasonalityProfile, bool) {
+	return a.seasonality.Profile(tenant)
+}
+
+// seasonalBufferPercent returns the buffer added for the hour of the week of now: the
+// larger of the detected seasonal buffer and the configured time-of-day buffer
+func (a *TrendAnalyzer) seasonalBufferPercent(tenant string, now time.Time) float64 {
+	buffer := timeOfDayBufferPercent(a.config(), now)
+	if a.config().TrendAnalysis.Seasonality.Enabled {
+		buffer = math.Max(buffer, a.seasonality.BufferPercent(tenant, now))
+	}
+	return buffer
+}
+
 // NewAnalyzer creates the appropriate analyzer based on configuration
-func NewAnalyzer(cfg *config.Config, log logr.Logger) Analyzer {
-	return NewTrendAnalyzer(cfg, log)
+func NewAnalyzer(live *config.Live, log logr.Logger) Analyzer {
+	return NewTrendAnalyzer(live, log)
 } 
\ No newline at end of file
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// ingestionRecommendations recommends each tenant's ingestion rate
+func ingestionRecommendations(recommended map[string]float64) map[string][]AnalysisResult {
+	results := make(map[string][]AnalysisResult, len(recommended))
+	for tenant, limit := range recommended {
+		results[tenant] = []AnalysisResult{{
+			Tenant:           tenant,
+			MetricName:       "cortex_distributor_received_samples_total",
+			RecommendedLimit: limit,
+		}}
+	}
+	return results
+}
+
+func TestClampedRecommendationsCounted(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	a := newTestAnalyzer(cfg, time.Now())
+	bounds := cfg.GetLimitBounds("ingestion_rate")
+	floor, _ := bounds.Floor.Number()
+	ceiling, _ := bounds.Ceiling.Number()
+
+	clamped := func(reason string) float64 {
+		return metricstest.Value(t, "mimir_limit_optimizer_recommendations_clamped_total", map[string]string{"reason": reason})
+	}
+	minBefore, maxBefore := clamped("min"), clamped("max")
+
+	limits, err := a.CalculateLimits(context.Background(), ingestionRecommendations(map[string]float64{
+		"tenant-low":  10,
+		"tenant-high": ceiling * 10,
+		"tenant-ok":   floor * 2,
+	}))
+	if err != nil {
+		t.Fatalf("CalculateLimits: %v", err)
+	}
+	if got := limits["tenant-low"].Limits["ingestion_rate"]; got != floor {
+		t.Errorf("ingestion_rate of tenant-low = %v, want raised to the floor %v", got, floor)
+	}
+	if got := limits["tenant-high"].Limits["ingestion_rate"]; got != ceiling {
+		t.Errorf("ingestion_rate of tenant-high = %v, want lowered to the ceiling %v", got, ceiling)
+	}
+	if got := clamped("min") - minBefore; got != 1 {
+		t.Errorf("recommendations clamped to the floor rose by %v, want 1", got)
+	}
+	if got := clamped("max") - maxBefore; got != 1 {
+		t.Errorf("recommendations clamped to the ceiling rose by %v, want 1", got)
+	}
+
+	// Previews do not count their clamps
+	minBefore, maxBefore = clamped("min"), clamped("max")
+	if _, err := a.CalculateLimits(WithPreview(context.Background()), ingestionRecommendations(map[string]float64{
+		"tenant-low":  10,
+		"tenant-high": ceiling * 10,
+	})); err != nil {
+		t.Fatalf("CalculateLimits: %v", err)
+	}
+	if clamped("min") != minBefore || clamped("max") != maxBefore {
+		t.Errorf("a preview counted clamped recommendations")
+	}
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// newTestAnalyzer creates an analyzer of cfg on a fixed clock
+func newTestAnalyzer(cfg *config.Config, now time.Time) *TrendAnalyzer {
+	a := NewTrendAnalyzer(config.NewLive(cfg), logr.Discard())
+	a.SetClock(func() time.Time { return now })
+	return a
+}
+
+// queryLatency returns latency quantiles of a query duration histogram, in seconds, as
+// the collector reports them
+func queryLatency(tenant string, now time.Time, seconds ...float64) map[string]*collector.TenantMetrics {
+	const metricName = "cortex_query_frontend_query_duration_seconds"
+	data := make([]collector.MetricData, len(seconds))
+	for i, value := range seconds {
+		data[i] = collector.MetricData{
+			Tenant:     tenant,
+			MetricName: metricName,
+			Value:      value,
+			Timestamp:  now.Add(time.Duration(i-len(seconds)) * time.Minute),
+		}
+	}
+	return map[string]*collector.TenantMetrics{
+		tenant: {Tenant: tenant, Metrics: map[string][]collector.MetricData{metricName: data}},
+	}
+}
+
+func TestDurationLimitRecommendation(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		seconds []float64
+		want    time.Duration
+	}{
+		{"buffered and rounded up to whole seconds", []float64{42.3}, 51 * time.Second},
+		{"rounded up to whole minutes", []float64{100}, 2 * time.Minute},
+		{"raised to the minimum", []float64{0.2}, time.Second},
+		{"capped at the maximum", []float64{5000}, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.GetDefaultConfig()
+			cfg.DynamicLimits.DefaultBuffer = 20
+			cfg.TrendAnalysis.UseMovingAverage = false
+			cfg.TrendAnalysis.IncludePeaks = false
+			cfg.EventSpike.Enabled = false
+			a := newTestAnalyzer(cfg, now)
+
+			results, err := a.AnalyzeTrends(context.Background(), queryLatency("tenant-a", now, tt.seconds...))
+			if err != nil {
+				t.Fatalf("AnalyzeTrends: %v", err)
+			}
+			limits, err := a.CalculateLimits(context.Background(), results)
+			if err != nil {
+				t.Fatalf("CalculateLimits: %v", err)
+			}
+
+			value := limits["tenant-a"].Limits["query_timeout"]
+			got, ok := value.(time.Duration)
+			if !ok {
+				t.Fatalf("query_timeout = %v (%T), want a time.Duration", value, value)
+			}
+			if got != tt.want {
+				t.Errorf("query_timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundDuration(t *testing.T) {
+	tests := []struct {
+		in, want time.Duration
+	}{
+		{1500 * time.Millisecond, 2 * time.Second},
+		{30 * time.Second, 30 * time.Second},
+		{61 * time.Second, 2 * time.Minute},
+		{time.Hour + time.Second, 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := roundDuration(tt.in); got != tt.want {
+			t.Errorf("roundDuration(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"time"
+)
+
+const (
+	// PredictiveSpikeMetric is the metric whose trend predictive spike detection follows
+	PredictiveSpikeMetric = "cortex_distributor_received_samples_total"
+
+	// predictiveBreachRatio is the share of the limit a predicted spike must not reach
+	predictiveBreachRatio = 0.8
+
+	// minPredictiveSamples is the number of rate observations needed for a trend
+	minPredictiveSamples = 3
+)
+
+// rateSample is one observation of the ingestion rate of a tenant
+type rateSample struct {
+	at   time.Time
+	rate float64
+}
+
+// PredictedBreach is a tenant whose ingestion rate is predicted to reach 80% of its
+// ingestion limit within the spike detection window
+type PredictedBreach struct {
+	Tenant string
+	// Rate is the last observed ingestion rate in samples/sec
+	Rate float64
+	// Slope is the rate of change of the ingestion rate in samples/sec²
+	Slope float64
+	// Threshold is the share of the limit the rate is predicted to reach
+	Threshold float64
+	// BreachAt is when the rate is predicted to reach the threshold
+	BreachAt time.Time
+	// Multiplier is the spike multiplier the limits are raised by
+	Multiplier float64
+}
+
+// PredictSpikes records the current ingestion rate of each tenant and starts a
+// predictive spike for tenants whose rate, at its current rate of change, reaches 80%
+// of their ingestion limit within eventSpike.detectionWindow. A predictive spike is
+// cancelled as soon as the rate stops rising, unless spike detection has since observed
+// the spike. It returns the predicted breaches that started a spike and the tenants
+// whose predictive spike was cancelled.
+func (a *TrendAnalyzer) PredictSpikes(rates, limits map[string]float64, now time.Time) ([]PredictedBreach, []string) {
+	spikeConfig := a.config().EventSpike
+	if !spikeConfig.Enabled || !spikeConfig.PredictiveSpike {
+		return nil, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	window := spikeConfig.DetectionWindow
+	for tenant := range a.predictiveRates {
+		if _, observed := rates[tenant]; !observed {
+			delete(a.predictiveRates, tenant)
+		}
+	}
+
+	var breaches []PredictedBreach
+	var cancelled []string
+	for tenant, rate := range rates {
+		samples := append(a.predictiveRates[tenant], rateSample{at: now, rate: rate})
+		kept := 0
+		for kept < len(samples) && now.Sub(samples[kept].at) > window {
+			kept++
+		}
+		samples = samples[kept:]
+		a.predictiveRates[tenant] = samples
+
+		if len(samples) < minPredictiveSamples {
+			continue
+		}
+		slope := rateSlope(samples)
+
+		info := a.getSpikeInfo(tenant, PredictiveSpikeMetric)
+		if info != nil && info.Elevated() {
+			if info.Predictive && slope <= 0 {
+				a.cancelPredictiveSpike(tenant, info, slope)
+				cancelled = append(cancelled, tenant)
+			}
+			continue
+		}
+
+		limit := limits[tenant]
+		if limit <= 0 || slope <= 0 {
+			continue
+		}
+		threshold := limit * predictiveBreachRatio
+		breachAt := now
+		if rate < threshold {
+			breachAt = now.Add(time.Duration((threshold - rate) / slope * float64(time.Second)))
+			if breachAt.Sub(now) > window {
+				continue
+			}
+		}
+
+		if info == nil {
+			info = &SpikeInfo{Phase: SpikePhaseNone, Multiplier: 1.0}
+			a.setSpikeInfo(tenant, PredictiveSpikeMetric, info)
+		}
+		a.recordSpike(info, spikeConfig.MaxSpikeMultiplier, rate, now)
+		info.Predictive = true
+		a.updateSpikeMetrics(tenant)
+
+		a.log.Info("predictive spike started", "tenant", tenant, "rate", rate, "slope", slope,
+			"threshold", threshold, "breach_at", breachAt, "multiplier", info.Multiplier)
+		breaches = append(breaches, PredictedBreach{
+			Tenant:     tenant,
+			Rate:       rate,
+			Slope:      slope,
+			Threshold:  threshold,
+			BreachAt:   breachAt,
+			Multiplier: info.Multiplier,
+		})
+	}
+
+	return breaches, cancelled
+}
+
+// cancelPredictiveSpike drops a predictive spike whose rate stopped rising, so the
+// limits return to the trend-based ones; callers must hold a.mu
+func (a *TrendAnalyzer) cancelPredictiveSpike(tenant string, info *SpikeInfo, slope float64) {
+	info.Detected = false
+	info.Predictive = false
+	info.Phase = SpikePhaseNone
+	info.Multiplier = 1
+	info.PeakMultiplier = 1
+	info.CooldownUntil = time.Time{}
+	info.DecayStartTime = time.Time{}
+	a.updateSpikeMetrics(tenant)
+
+	a.log.Info("predictive spike cancelled, ingestion rate no longer rising", "tenant", tenant, "slope", slope)
+}
+
+// rateSlope is the least-squares rate of change of the samples per second
+func rateSlope(samples []rateSample) float64 {
+	n := float64(len(samples))
+	origin := samples[0].at
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := sample.at.Sub(origin).Seconds()
+		sumX += x
+		sumY += sample.rate
+		sumXY += x * sample.rate
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+var predictiveEpoch = time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+func predictiveConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.EventSpike.Enabled = true
+	cfg.EventSpike.PredictiveSpike = true
+	return cfg
+}
+
+// growingRate is an ingestion rate starting at 10000 samples/s and growing at 1000
+// samples/s²
+func growingRate(elapsed time.Duration) float64 {
+	return 10000 + 1000*elapsed.Seconds()
+}
+
+func TestPredictSpikesBeforeBreach(t *testing.T) {
+	a := newTestAnalyzer(predictiveConfig(), predictiveEpoch)
+	// 80% of the limit is reached 790s in
+	limits := map[string]float64{"tenant-a": 1000000}
+	const breachAfter = 790 * time.Second
+
+	var breach *PredictedBreach
+	var breachCheck time.Duration
+	for elapsed := time.Duration(0); elapsed < breachAfter && breach == nil; elapsed += 30 * time.Second {
+		breaches, cancelled := a.PredictSpikes(map[string]float64{"tenant-a": growingRate(elapsed)}, limits, predictiveEpoch.Add(elapsed))
+		if len(cancelled) != 0 {
+			t.Fatalf("spike cancelled at %v while the rate rises", elapsed)
+		}
+		if len(breaches) > 0 {
+			breach, breachCheck = &breaches[0], elapsed
+		}
+	}
+	if breach == nil {
+		t.Fatalf("no breach predicted before the rate reached 80%% of the limit")
+	}
+
+	// The first check predicting the breach within the 5 minute detection window
+	if breachCheck != 510*time.Second {
+		t.Errorf("breach predicted %v in, want at the 510s check, 280s ahead of it", breachCheck)
+	}
+	if breach.Rate >= breach.Threshold || breach.Threshold != 800000 {
+		t.Errorf("breach at rate %v against threshold %v, want predicted below the 800000 threshold", breach.Rate, breach.Threshold)
+	}
+	if math.Abs(breach.Slope-1000) > 1e-6 {
+		t.Errorf("slope = %v, want 1000 samples/s²", breach.Slope)
+	}
+	if want := predictiveEpoch.Add(breachAfter); breach.BreachAt.Sub(want).Abs() > time.Millisecond {
+		t.Errorf("breach predicted at %v, want %v", breach.BreachAt, want)
+	}
+	if breach.Multiplier != 5 {
+		t.Errorf("multiplier = %v, want the maximum spike multiplier 5", breach.Multiplier)
+	}
+
+	info := a.GetSpikeInfo("tenant-a", PredictiveSpikeMetric)
+	if info == nil || !info.Predictive || !info.Elevated() {
+		t.Fatalf("spike info = %+v, want an elevated predictive spike", info)
+	}
+
+	// An elevated spike is not predicted again
+	elapsed := breachCheck + 30*time.Second
+	if breaches, _ := a.PredictSpikes(map[string]float64{"tenant-a": growingRate(elapsed)}, limits, predictiveEpoch.Add(elapsed)); len(breaches) != 0 {
+		t.Errorf("breach predicted again while the spike is elevated")
+	}
+}
+
+func TestPredictSpikesCancelledWhenRateReverses(t *testing.T) {
+	a := newTestAnalyzer(predictiveConfig(), predictiveEpoch)
+	limits := map[string]float64{"tenant-a": 1000000}
+	check := func(elapsed time.Duration, rate float64) ([]PredictedBreach, []string) {
+		return a.PredictSpikes(map[string]float64{"tenant-a": rate}, limits, predictiveEpoch.Add(elapsed))
+	}
+
+	for elapsed := 450 * time.Second; elapsed <= 510*time.Second; elapsed += 30 * time.Second {
+		check(elapsed, growingRate(elapsed))
+	}
+	if info := a.GetSpikeInfo("tenant-a", PredictiveSpikeMetric); info == nil || !info.Predictive {
+		t.Fatalf("no predictive spike after the rate grew toward the limit")
+	}
+
+	// The rate falls from 520000: the trend reverses once the falling samples outweigh
+	// the rising ones
+	var cancelledAt time.Duration
+	for elapsed, rate := 540*time.Second, 500000.0; elapsed < 660*time.Second && cancelledAt == 0; elapsed, rate = elapsed+30*time.Second, rate-60000 {
+		if _, cancelled := check(elapsed, rate); len(cancelled) == 1 && cancelled[0] == "tenant-a" {
+			cancelledAt = elapsed
+		}
+	}
+	if cancelledAt == 0 {
+		t.Fatalf("predictive spike not cancelled after the rate fell")
+	}
+	info := a.GetSpikeInfo("tenant-a", PredictiveSpikeMetric)
+	if info.Elevated() || info.Predictive || info.Multiplier != 1 {
+		t.Errorf("spike info after cancelling = %+v, want no spike", info)
+	}
+}
+
+func TestPredictSpikesIgnoresSlowGrowth(t *testing.T) {
+	a := newTestAnalyzer(predictiveConfig(), predictiveEpoch)
+	limits := map[string]float64{"tenant-a": 1000000, "tenant-b": 0}
+	for elapsed := time.Duration(0); elapsed <= 10*time.Minute; elapsed += 30 * time.Second {
+		// 10 samples/s²: 80% of the limit is hours away
+		rates := map[string]float64{"tenant-a": 10000 + 10*elapsed.Seconds(), "tenant-b": growingRate(elapsed)}
+		if breaches, _ := a.PredictSpikes(rates, limits, predictiveEpoch.Add(elapsed)); len(breaches) != 0 {
+			t.Fatalf("breach predicted at %v for %v", elapsed, breaches[0].Tenant)
+		}
+	}
+}
+
+func TestPredictSpikesDisabled(t *testing.T) {
+	cfg := predictiveConfig()
+	cfg.EventSpike.PredictiveSpike = false
+	a := newTestAnalyzer(cfg, predictiveEpoch)
+	for elapsed := time.Duration(0); elapsed <= 10*time.Minute; elapsed += 30 * time.Second {
+		if breaches, _ := a.PredictSpikes(map[string]float64{"tenant-a": growingRate(elapsed)}, map[string]float64{"tenant-a": 100000}, predictiveEpoch.Add(elapsed)); len(breaches) != 0 {
+			t.Fatalf("breach predicted with predictive spikes disabled")
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package analyzer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// HoursPerWeek is the number of hour-of-week buckets of a seasonality profile
+const HoursPerWeek = 7 * 24
+
+// SeasonalityProfile is the weekly usage pattern of a tenant. Buckets are indexed by
+// HourOfWeek, so index 0 is Monday 00:00 to 01:00 UTC.
+type SeasonalityProfile struct {
+	Tenant string `json:"tenant"`
+	Metric string `json:"metric"`
+	// Ready is set once the history spans trendAnalysis.seasonality.minHistory; until
+	// then no seasonal buffer is applied
+	Ready        bool      `json:"ready"`
+	HistoryStart time.Time `json:"history_start"`
+	HistoryEnd   time.Time `json:"history_end"`
+	// Median is the median of the hourly usage averages across the week
+	Median float64 `json:"median"`
+	// Multipliers are each hour's average usage over the median; hours without usage
+	// are 1
+	Multipliers []float64 `json:"multipliers"`
+	// Buffers are the seasonal buffers of each hour in percent, 0 for hours within
+	// thresholdPercent of the median
+	Buffers []float64 `json:"buffers"`
+}
+
+// SeasonalityDetector keeps the hourly usage of each tenant over the seasonality
+// retention and derives its weekly usage pattern
+type SeasonalityDetector struct {
+	live *config.Live
+
+	mu      sync.RWMutex
+	tenants map[string]*tenantUsageHistory
+}
+
+// tenantUsageHistory is the usage of a tenant aggregated per UTC hour
+type tenantUsageHistory struct {
+	hours      map[time.Time]*hourlyUsage
+	lastSample time.Time
+}
+
+// hourlyUsage sums the samples observed in one hour
+type hourlyUsage struct {
+	sum   float64
+	count int
+}
+
+// NewSeasonalityDetector creates a seasonality detector
+func NewSeasonalityDetector(live *config.Live) *SeasonalityDetector {
+	return &SeasonalityDetector{
+		live:    live,
+		tenants: make(map[string]*tenantUsageHistory),
+	}
+}
+
+// config returns the configuration in effect
+func (d *SeasonalityDetector) config() *config.Config {
+	return d.live.Load()
+}
+
+// HourOfWeek returns the hour-of-week bucket of t in UTC, counting from Monday 00:00
+func HourOfWeek(t time.Time) int {
+	t = t.UTC()
+	day := (int(t.Weekday()) + 6) % 7
+	return day*24 + t.Hour()
+}
+
+// Observe records the samples of a tenant newer than the last one observed, so samples
+// returned again by the next collection are not counted twice, and drops the hours
+// older than the retention
+func (d *SeasonalityDetector) Observe(tenant string, data []collector.MetricData) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	history, exists := d.tenants[tenant]
+	if !exists {
+		history = &tenantUsageHistory{hours: make(map[time.Time]*hourlyUsage)}
+		d.tenants[tenant] = history
+	}
+
+	latest := history.lastSample
+	for _, sample := range data {
+		if !sample.Timestamp.After(history.lastSample) {
+			continue
+		}
+		hour := sample.Timestamp.UTC().Truncate(time.Hour)
+		usage, exists := history.hours[hour]
+		if !exists {
+			usage = &hourlyUsage{}
+			history.hours[hour] = usage
+		}
+		usage.sum += sample.Value
+		usage.count++
+		if sample.Timestamp.After(latest) {
+			latest = sample.Timestamp
+		}
+	}
+	history.lastSample = latest
+
+	cutoff := latest.Add(-d.config().TrendAnalysis.Seasonality.Retention)
+	for hour := range history.hours {
+		if hour.Before(cutoff) {
+			delete(history.hours, hour)
+		}
+	}
+}
+
+// Profile returns the weekly usage pattern of a tenant, or false for a tenant whose
+// usage was never observed
+func (d *SeasonalityDetector) Profile(tenant string) (*SeasonalityProfile, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	history, exists := d.tenants[tenant]
+	if !exists || len(history.hours) == 0 {
+		return nil, false
+	}
+	settings := d.config().TrendAnalysis.Seasonality
+
+	var sums [HoursPerWeek]float64
+	var counts [HoursPerWeek]int
+	var start time.Time
+	for hour, usage := range history.hours {
+		if start.IsZero() || hour.Before(start) {
+			start = hour
+		}
+		bucket := HourOfWeek(hour)
+		sums[bucket] += usage.sum / float64(usage.count)
+		counts[bucket]++
+	}
+
+	profile := &SeasonalityProfile{
+		Tenant:       tenant,
+		Metric:       settings.Metric,
+		Ready:        history.lastSample.Sub(start) >= settings.MinHistory,
+		HistoryStart: start,
+		HistoryEnd:   history.lastSample,
+		Multipliers:  make([]float64, HoursPerWeek),
+		Buffers:      make([]float64, HoursPerWeek),
+	}
+
+	averages := make([]float64, 0, HoursPerWeek)
+	for bucket := range sums {
+		if counts[bucket] > 0 {
+			averages = append(averages, sums[bucket]/float64(counts[bucket]))
+		}
+	}
+	profile.Median = median(averages)
+
+	threshold := 1 + settings.ThresholdPercent/100
+	for bucket := range sums {
+		profile.Multipliers[bucket] = 1
+		if counts[bucket] == 0 || profile.Median <= 0 {
+			continue
+		}
+		multiplier := sums[bucket] / float64(counts[bucket]) / profile.Median
+		profile.Multipliers[bucket] = multiplier
+		if multiplier > threshold {
+			profile.Buffers[bucket] = (multiplier - 1) * 100
+		}
+	}
+	return profile, true
+}
+
+// BufferPercent returns the seasonal buffer of a tenant for the hour of the week of
+// now, or 0 until its history spans the minimum history
+func (d *SeasonalityDetector) BufferPercent(tenant string, now time.Time) float64 {
+	profile, exists := d.Profile(tenant)
+	if !exists || !profile.Ready {
+		return 0
+	}
+	return profile.Buffers[HourOfWeek(now)]
+}
+
+// median returns the median of values, 0 for none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// timeOfDayBufferPercent returns the largest trendAnalysis.timeOfDayBuffers entry that
+// applies to now, by hour of day or by day of week and hour
+func timeOfDayBufferPercent(cfg *config.Config, now time.Time) float64 {
+	now = now.UTC()
+	buffer := 0.0
+	for key, value := range cfg.TrendAnalysis.TimeOfDayBuffers {
+		weekday, hour, err := config.ParseTimeOfDayBucket(key)
+		if err != nil || hour != now.Hour() || (weekday >= 0 && weekday != now.Weekday()) {
+			continue
+		}
+		if value > buffer {
+			buffer = value
+		}
+	}
+	return buffer
+}
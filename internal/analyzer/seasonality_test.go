@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// seasonalityEpoch is a Monday midnight in UTC
+var seasonalityEpoch = time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+// mondayMorningSpike is days of ingestion sampled every 15 minutes from
+// seasonalityEpoch through the last midnight: 1000 samples/s, tripled on Mondays from
+// 09:00 to 10:00
+func mondayMorningSpike(days int) []collector.MetricData {
+	var data []collector.MetricData
+	for at := seasonalityEpoch; !at.After(seasonalityEpoch.AddDate(0, 0, days)); at = at.Add(15 * time.Minute) {
+		value := 1000.0
+		if at.Weekday() == time.Monday && at.Hour() == 9 {
+			value = 3000
+		}
+		data = append(data, collector.MetricData{
+			Tenant:     "tenant-a",
+			MetricName: "cortex_distributor_received_samples_total",
+			Value:      value,
+			Timestamp:  at,
+		})
+	}
+	return data
+}
+
+func seasonalityConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.TrendAnalysis.Seasonality.Enabled = true
+	return cfg
+}
+
+func TestSeasonalityDetectsMondayMorningSpike(t *testing.T) {
+	detector := NewSeasonalityDetector(config.NewLive(seasonalityConfig()))
+	detector.Observe("tenant-a", mondayMorningSpike(14))
+
+	profile, exists := detector.Profile("tenant-a")
+	if !exists {
+		t.Fatalf("no profile for an observed tenant")
+	}
+	if !profile.Ready {
+		t.Errorf("profile of 14 days of history is not ready")
+	}
+	if len(profile.Multipliers) != HoursPerWeek || len(profile.Buffers) != HoursPerWeek {
+		t.Fatalf("profile has %d multipliers and %d buffers, want %d", len(profile.Multipliers), len(profile.Buffers), HoursPerWeek)
+	}
+	if profile.Median != 1000 {
+		t.Errorf("median = %v, want 1000", profile.Median)
+	}
+
+	mondayNine := HourOfWeek(time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC))
+	for hour := 0; hour < HoursPerWeek; hour++ {
+		wantMultiplier, wantBuffer := 1.0, 0.0
+		if hour == mondayNine {
+			wantMultiplier, wantBuffer = 3, 200
+		}
+		if profile.Multipliers[hour] != wantMultiplier || profile.Buffers[hour] != wantBuffer {
+			t.Errorf("hour %d multiplier %v, buffer %v; want %v and %v",
+				hour, profile.Multipliers[hour], profile.Buffers[hour], wantMultiplier, wantBuffer)
+		}
+	}
+
+	thirdMonday := seasonalityEpoch.AddDate(0, 0, 14)
+	if got := detector.BufferPercent("tenant-a", thirdMonday.Add(9*time.Hour+20*time.Minute)); got != 200 {
+		t.Errorf("buffer on Monday at 09:20 = %v, want 200", got)
+	}
+	if got := detector.BufferPercent("tenant-a", thirdMonday.Add(10*time.Hour+20*time.Minute)); got != 0 {
+		t.Errorf("buffer on Monday at 10:20 = %v, want 0", got)
+	}
+}
+
+func TestSeasonalityNeedsMinHistory(t *testing.T) {
+	detector := NewSeasonalityDetector(config.NewLive(seasonalityConfig()))
+	detector.Observe("tenant-a", mondayMorningSpike(7))
+
+	profile, _ := detector.Profile("tenant-a")
+	if profile.Ready {
+		t.Errorf("profile of 7 days is ready before the 14 day minimum history")
+	}
+	if profile.Multipliers[9] != 3 {
+		t.Errorf("Monday 09:00 multiplier = %v, want the spike detected already", profile.Multipliers[9])
+	}
+	if got := detector.BufferPercent("tenant-a", seasonalityEpoch.AddDate(0, 0, 7).Add(9*time.Hour)); got != 0 {
+		t.Errorf("buffer before the minimum history = %v, want 0", got)
+	}
+	if _, exists := detector.Profile("tenant-b"); exists {
+		t.Errorf("profile for a tenant never observed")
+	}
+}
+
+func TestSeasonalityCountsSamplesOnce(t *testing.T) {
+	once := NewSeasonalityDetector(config.NewLive(seasonalityConfig()))
+	once.Observe("tenant-a", mondayMorningSpike(14))
+
+	// Each collection returns the samples of the last days again
+	repeated := NewSeasonalityDetector(config.NewLive(seasonalityConfig()))
+	data := mondayMorningSpike(14)
+	for end := 0; end < len(data); {
+		end += 96
+		if end > len(data) {
+			end = len(data)
+		}
+		start := end - 3*96
+		if start < 0 {
+			start = 0
+		}
+		repeated.Observe("tenant-a", data[start:end])
+	}
+
+	want, _ := once.Profile("tenant-a")
+	got, _ := repeated.Profile("tenant-a")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("profile from overlapping collections = %+v, want %+v", got, want)
+	}
+}
+
+func TestSeasonalityRetention(t *testing.T) {
+	cfg := seasonalityConfig()
+	cfg.TrendAnalysis.Seasonality.Retention = 7 * 24 * time.Hour
+	detector := NewSeasonalityDetector(config.NewLive(cfg))
+	data := mondayMorningSpike(14)
+	detector.Observe("tenant-a", data)
+
+	profile, _ := detector.Profile("tenant-a")
+	last := data[len(data)-1].Timestamp
+	if want := last.Add(-7 * 24 * time.Hour); !profile.HistoryStart.Equal(want) {
+		t.Errorf("history starts %v, want the hours older than the 7 day retention dropped (%v)", profile.HistoryStart, want)
+	}
+}
+
+func TestHourOfWeek(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2026, 5, 4, 9, 59, 0, 0, time.UTC), 9},
+		{time.Date(2026, 5, 5, 0, 0, 0, 0, time.UTC), 24},
+		{time.Date(2026, 5, 10, 23, 30, 0, 0, time.UTC), HoursPerWeek - 1},
+		// Buckets are in UTC
+		{time.Date(2026, 5, 4, 11, 0, 0, 0, time.FixedZone("CEST", 2*3600)), 9},
+	}
+	for _, tt := range tests {
+		if got := HourOfWeek(tt.at); got != tt.want {
+			t.Errorf("HourOfWeek(%v) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonalBufferRaisesLimits(t *testing.T) {
+	mondayNine := seasonalityEpoch.AddDate(0, 0, 14).Add(9*time.Hour + 20*time.Minute)
+	tests := []struct {
+		name       string
+		now        time.Time
+		timeOfDay  map[string]float64
+		wantBuffer float64
+	}{
+		{"detected spike hour", mondayNine, nil, 200},
+		{"quiet hour", mondayNine.Add(3 * time.Hour), nil, 0},
+		{"larger time-of-day buffer wins", mondayNine, map[string]float64{"mon-09": 250}, 250},
+		{"smaller time-of-day buffer", mondayNine, map[string]float64{"09": 50}, 200},
+		{"time-of-day buffer in a quiet hour", mondayNine.Add(3 * time.Hour), map[string]float64{"12": 30}, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := seasonalityConfig()
+			cfg.TrendAnalysis.TimeOfDayBuffers = tt.timeOfDay
+			a := newTestAnalyzer(cfg, tt.now)
+			a.seasonality.Observe("tenant-a", mondayMorningSpike(14))
+
+			if got := a.seasonalBufferPercent("tenant-a", tt.now); got != tt.wantBuffer {
+				t.Fatalf("seasonal buffer = %v, want %v", got, tt.wantBuffer)
+			}
+
+			limits := &TenantLimits{Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 10000.0}}
+			a.applyBufferPercentage(limits, "tenant-a")
+			want := 10000 * (1 + (LimitBufferPercent(cfg, "ingestion_rate")+tt.wantBuffer)/100)
+			if got, _ := limits.Limits["ingestion_rate"].(float64); math.Abs(got-want) > 1e-6 {
+				t.Errorf("buffered ingestion_rate = %v, want %v", limits.Limits["ingestion_rate"], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"math"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Spike phases of a tenant metric. A spike is active while it is observed, keeps its
+// multiplier through the cooldown once it ends, then decays in steps back to the
+// trend-based limit.
+const (
+	SpikePhaseNone        = "none"
+	SpikePhaseActive      = "active"
+	SpikePhaseCoolingDown = "cooling_down"
+	SpikePhaseDecaying    = "decaying"
+	SpikePhaseDecayed     = "decayed"
+)
+
+// spikePhases lists the phases from the most to the least significant
+var spikePhases = []string{
+	SpikePhaseActive,
+	SpikePhaseCoolingDown,
+	SpikePhaseDecaying,
+	SpikePhaseDecayed,
+	SpikePhaseNone,
+}
+
+// TenantSpikeState summarizes the spike state of a tenant across its metrics
+type TenantSpikeState struct {
+	Tenant string
+	// Phase is the most significant phase of any of the tenant's metrics
+	Phase string
+	// Multiplier is the largest multiplier currently applied to the tenant's limits
+	Multiplier float64
+	Metrics    map[string]SpikeInfo
+}
+
+// Elevated reports whether the spike multiplier still applies to the limit
+func (s *SpikeInfo) Elevated() bool {
+	switch s.Phase {
+	case SpikePhaseActive, SpikePhaseCoolingDown, SpikePhaseDecaying:
+		return s.Multiplier > 1
+	}
+	return false
+}
+
+// recordSpike starts a spike or, when one is still elevated, extends it. An
+// overlapping spike renews the cooldown and never lowers the applied multiplier, and
+// an observed spike is no longer predictive. It reports whether a new spike started.
+func (a *TrendAnalyzer) recordSpike(info *SpikeInfo, multiplier, baseline float64, now time.Time) bool {
+	newSpike := !info.Elevated()
+	if newSpike {
+		info.StartTime = now
+		info.BaseValue = baseline
+		info.Multiplier = multiplier
+	} else {
+		info.Multiplier = math.Max(info.Multiplier, multiplier)
+	}
+
+	info.Detected = true
+	info.Predictive = false
+	info.Phase = SpikePhaseActive
+	info.PeakMultiplier = info.Multiplier
+	info.LastSpikeTime = now
+	info.CooldownUntil = now.Add(a.config().EventSpike.CooldownPeriod)
+	info.DecayStartTime = time.Time{}
+
+	return newSpike
+}
+
+// advanceSpike moves a spike that is no longer observed through its cooldown and decay
+func (a *TrendAnalyzer) advanceSpike(info *SpikeInfo, now time.Time) {
+	switch info.Phase {
+	case SpikePhaseActive:
+		info.Detected = false
+		info.Phase = SpikePhaseCoolingDown
+		fallthrough
+	case SpikePhaseCoolingDown:
+		if now.Before(info.CooldownUntil) {
+			return
+		}
+		info.Phase = SpikePhaseDecaying
+		info.DecayStartTime = info.CooldownUntil
+		fallthrough
+	case SpikePhaseDecaying:
+		info.Multiplier = a.decayedMultiplier(info, now)
+		if info.Multiplier <= 1 {
+			info.Multiplier = 1
+			info.Phase = SpikePhaseDecayed
+		}
+	}
+}
+
+// decayedMultiplier removes DecayStepPercent of the peak increase for every decay
+// interval started since the cooldown ended
+func (a *TrendAnalyzer) decayedMultiplier(info *SpikeInfo, now time.Time) float64 {
+	interval := a.config().EventSpike.DecayInterval
+	step := a.config().EventSpike.DecayStepPercent
+	if interval <= 0 || step <= 0 {
+		return 1
+	}
+
+	steps := 1 + int(now.Sub(info.DecayStartTime)/interval)
+	remaining := 1 - float64(steps)*step/100
+	if remaining <= 0 {
+		return 1
+	}
+	return 1 + (info.PeakMultiplier-1)*remaining
+}
+
+// tenantSpikeState summarizes the tenant's spike state; callers must hold a.mu
+func (a *TrendAnalyzer) tenantSpikeState(tenant string) *TenantSpikeState {
+	state := &TenantSpikeState{
+		Tenant:     tenant,
+		Phase:      SpikePhaseNone,
+		Multiplier: 1,
+		Metrics:    make(map[string]SpikeInfo),
+	}
+
+	rank := func(phase string) int {
+		for i, candidate := range spikePhases {
+			if candidate == phase {
+				return i
+			}
+		}
+		return len(spikePhases)
+	}
+
+	for metricName, info := range a.spikeState[tenant] {
+		if info.Phase == SpikePhaseNone {
+			continue
+		}
+		state.Metrics[metricName] = *info
+		if rank(info.Phase) < rank(state.Phase) {
+			state.Phase = info.Phase
+		}
+		if info.Elevated() && info.Multiplier > state.Multiplier {
+			state.Multiplier = info.Multiplier
+		}
+	}
+
+	return state
+}
+
+// updateSpikeMetrics publishes the tenant's spike phase and applied multiplier;
+// callers must hold a.mu
+func (a *TrendAnalyzer) updateSpikeMetrics(tenant string) {
+	state := a.tenantSpikeState(tenant)
+	metrics.SpikeMetricsInstance.SetSpikeState(tenant, state.Phase, spikePhases)
+	metrics.SpikeMetricsInstance.SetSpikeMultiplier(tenant, state.Multiplier)
+}
+
+// GetTenantSpikeState returns the spike state of a tenant across all its metrics
+func (a *TrendAnalyzer) GetTenantSpikeState(tenant string) *TenantSpikeState {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.tenantSpikeState(tenant)
+}
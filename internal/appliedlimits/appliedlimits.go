@@ -0,0 +1,347 @@
+package appliedlimits
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+)
+
+// OverridesMetric is the metric the overrides-exporter exposes the loaded per-tenant
+// limits as, labelled by limit_name and user
+const OverridesMetric = "cortex_limits_overrides"
+
+// overridesExporterComponent is the component type the infrastructure scanner gives
+// the overrides-exporter
+const overridesExporterComponent = "overrides-exporter"
+
+// scrapeTimeout bounds a scrape of the overrides-exporter
+const scrapeTimeout = 10 * time.Second
+
+// Statuses of a limit compared between the ConfigMap and Mimir
+const (
+	StatusIdentical  = "identical"
+	StatusMismatched = "mismatched"
+	// StatusNotLoaded is a limit the exporter exposes for other tenants but not for this
+	// one, so Mimir has not loaded the tenant's overrides
+	StatusNotLoaded = "not_loaded"
+)
+
+// TenantLimits maps tenant IDs to limit names and the values Mimir has loaded
+type TenantLimits map[string]map[string]float64
+
+// Item describes how a limit in the ConfigMap compares with the value Mimir has loaded
+type Item struct {
+	TenantID       string      `json:"tenant_id"`
+	LimitName      string      `json:"limit_name"`
+	ConfigMapValue interface{} `json:"configmap_value"`
+	LoadedValue    interface{} `json:"loaded_value"`
+	Status         string      `json:"status"`
+	// MismatchSince is when the limit was first seen differing, unset while identical
+	MismatchSince *time.Time `json:"mismatch_since,omitempty"`
+	// ExceedsGracePeriod is set once the limit has differed for longer than
+	// mimir.overridesExporter.mismatchGracePeriod
+	ExceedsGracePeriod bool `json:"exceeds_grace_period"`
+}
+
+// Report is the result of comparing the ConfigMap with the loaded limits
+type Report struct {
+	GeneratedAt     time.Time     `json:"generated_at"`
+	Endpoint        string        `json:"endpoint"`
+	GracePeriod     time.Duration `json:"grace_period"`
+	Items           []Item        `json:"items"`
+	IdenticalCount  int           `json:"identical_count"`
+	MismatchedCount int           `json:"mismatched_count"`
+	NotLoadedCount  int           `json:"not_loaded_count"`
+	StaleCount      int           `json:"stale_count"` // Items exceeding the grace period
+}
+
+// Stale returns the items that differ for longer than the grace period
+func (r *Report) Stale() []Item {
+	var stale []Item
+	for _, item := range r.Items {
+		if item.ExceedsGracePeriod {
+			stale = append(stale, item)
+		}
+	}
+	return stale
+}
+
+// Reader scrapes the limits Mimir has loaded from the overrides-exporter and tracks how
+// long they differ from the ConfigMap. Without a configured endpoint the exporter is
+// discovered with the infrastructure scanner, and rediscovered when a scrape fails.
+type Reader struct {
+	live       *config.Live
+	scanner    *discovery.AutonomousScanner
+	httpClient *http.Client
+	log        logr.Logger
+	// now is the clock mismatches are timed by
+	now func() time.Time
+
+	mu         sync.Mutex
+	discovered string
+	// mismatchSince is when each differing limit, keyed by tenant and limit name, was
+	// first seen differing
+	mismatchSince map[string]time.Time
+}
+
+// NewReader creates a reader of the loaded limits. The Kubernetes client is used to
+// discover the overrides-exporter and may be nil when an endpoint is configured.
+func NewReader(live *config.Live, kubeClient kubernetes.Interface, log logr.Logger) *Reader {
+	reader := &Reader{
+		live:          live,
+		httpClient:    &http.Client{Timeout: scrapeTimeout},
+		log:           log,
+		now:           time.Now,
+		mismatchSince: make(map[string]time.Time),
+	}
+	if kubeClient != nil {
+		reader.scanner = discovery.NewAutonomousScanner(kubeClient, live, log)
+	}
+	return reader
+}
+
+// config returns the configuration in effect
+func (r *Reader) config() *config.Config {
+	return r.live.Load()
+}
+
+// Read scrapes the limits Mimir has loaded
+func (r *Reader) Read(ctx context.Context) (TenantLimits, error) {
+	endpoint, err := r.endpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := r.scrape(ctx, endpoint)
+	if err != nil {
+		// The exporter may have moved; discover it again on the next read
+		r.mu.Lock()
+		r.discovered = ""
+		r.mu.Unlock()
+		return nil, err
+	}
+	return limits, nil
+}
+
+// Check reads the loaded limits, compares them with the ConfigMap limits and updates how
+// long each limit has differed
+func (r *Reader) Check(ctx context.Context, configMap map[string]map[string]interface{}) (*Report, error) {
+	loaded, err := r.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	report := Compare(configMap, loaded)
+	report.GeneratedAt = now
+	report.Endpoint = r.currentEndpoint()
+	report.GracePeriod = r.config().Mimir.OverridesExporter.MismatchGracePeriod
+
+	differing := make(map[string]bool)
+	for i := range report.Items {
+		item := &report.Items[i]
+		if item.Status == StatusIdentical {
+			continue
+		}
+		key := item.TenantID + "/" + item.LimitName
+		differing[key] = true
+		since, exists := r.mismatchSince[key]
+		if !exists {
+			since = now
+			r.mismatchSince[key] = since
+		}
+		item.MismatchSince = &since
+		if now.Sub(since) >= report.GracePeriod {
+			item.ExceedsGracePeriod = true
+			report.StaleCount++
+		}
+	}
+	for key := range r.mismatchSince {
+		if !differing[key] {
+			delete(r.mismatchSince, key)
+		}
+	}
+
+	r.log.V(1).Info("compared ConfigMap limits with loaded limits",
+		"endpoint", report.Endpoint,
+		"items", len(report.Items),
+		"mismatched", report.MismatchedCount,
+		"not_loaded", report.NotLoadedCount,
+		"stale", report.StaleCount)
+
+	return report, nil
+}
+
+// Compare compares the numeric ConfigMap limits with the loaded ones. Only the limits
+// the exporter exposes for some tenant are compared, as it does not export every limit.
+func Compare(configMap map[string]map[string]interface{}, loaded TenantLimits) *Report {
+	exported := make(map[string]bool)
+	for _, limits := range loaded {
+		for limitName := range limits {
+			exported[limitName] = true
+		}
+	}
+
+	report := &Report{Items: []Item{}}
+	for tenant, limits := range configMap {
+		for limitName, value := range limits {
+			configured, numeric := toFloat64(value)
+			if !numeric || !exported[limitName] {
+				continue
+			}
+
+			item := Item{
+				TenantID:       tenant,
+				LimitName:      limitName,
+				ConfigMapValue: value,
+			}
+			loadedValue, exists := loaded[tenant][limitName]
+			switch {
+			case !exists:
+				item.Status = StatusNotLoaded
+				report.NotLoadedCount++
+			case loadedValue == configured:
+				item.LoadedValue = loadedValue
+				item.Status = StatusIdentical
+				report.IdenticalCount++
+			default:
+				item.LoadedValue = loadedValue
+				item.Status = StatusMismatched
+				report.MismatchedCount++
+			}
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].TenantID != report.Items[j].TenantID {
+			return report.Items[i].TenantID < report.Items[j].TenantID
+		}
+		return report.Items[i].LimitName < report.Items[j].LimitName
+	})
+	return report
+}
+
+// endpoint returns the configured exporter URL, or discovers the first answering
+// metrics URL of an overrides-exporter component
+func (r *Reader) endpoint(ctx context.Context) (string, error) {
+	if endpoint := r.config().Mimir.OverridesExporter.Endpoint; endpoint != "" {
+		return endpoint, nil
+	}
+
+	r.mu.Lock()
+	discovered := r.discovered
+	r.mu.Unlock()
+	if discovered != "" {
+		return discovered, nil
+	}
+
+	if r.scanner == nil {
+		return "", fmt.Errorf("no overrides-exporter endpoint configured and no Kubernetes client to discover one")
+	}
+	urls, err := r.scanner.ComponentMetricsURLs(ctx, overridesExporterComponent)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover the overrides-exporter: %w", err)
+	}
+	if len(urls) == 0 {
+		return "", fmt.Errorf("no overrides-exporter found in namespace %s", r.config().Mimir.Namespace)
+	}
+
+	r.mu.Lock()
+	r.discovered = urls[0]
+	r.mu.Unlock()
+	r.log.Info("discovered overrides-exporter", "endpoint", urls[0])
+	return urls[0], nil
+}
+
+// currentEndpoint returns the endpoint the last read used. The caller must hold r.mu.
+func (r *Reader) currentEndpoint() string {
+	if endpoint := r.config().Mimir.OverridesExporter.Endpoint; endpoint != "" {
+		return endpoint
+	}
+	return r.discovered
+}
+
+// scrape reads the cortex_limits_overrides samples of an exporter
+func (r *Reader) scrape(ctx context.Context, endpoint string) (TenantLimits, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape overrides-exporter %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("overrides-exporter %s returned status %d", endpoint, resp.StatusCode)
+	}
+
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overrides-exporter metrics: %w", err)
+	}
+
+	limits := make(TenantLimits)
+	family, exists := families[OverridesMetric]
+	if !exists {
+		return limits, nil
+	}
+	for _, metric := range family.Metric {
+		var tenant, limitName string
+		for _, label := range metric.Label {
+			switch label.GetName() {
+			case "user":
+				tenant = label.GetValue()
+			case "limit_name":
+				limitName = label.GetValue()
+			}
+		}
+		if tenant == "" || limitName == "" || metric.Gauge == nil {
+			continue
+		}
+		if limits[tenant] == nil {
+			limits[tenant] = make(map[string]float64)
+		}
+		limits[tenant][limitName] = metric.Gauge.GetValue()
+	}
+	return limits, nil
+}
+
+// toFloat64 returns the numeric value of a ConfigMap limit
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,231 @@
+package appliedlimits
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// exporterMetrics is an overrides-exporter scrape with an unknown limit name, a series
+// without the user label and an unrelated metric
+const exporterMetrics = `# HELP cortex_limits_overrides Resource limit overrides applied to tenants
+# TYPE cortex_limits_overrides gauge
+cortex_limits_overrides{limit_name="ingestion_rate",user="tenant-a"} 10000
+cortex_limits_overrides{limit_name="max_global_series_per_user",user="tenant-a"} 150000
+cortex_limits_overrides{limit_name="ingestion_rate",user="tenant-b"} 5000
+cortex_limits_overrides{limit_name="some_future_limit",user="tenant-b"} 7
+cortex_limits_overrides{limit_name="ingestion_rate"} 1
+cortex_limits_overrides{user="tenant-c"} 2
+# HELP cortex_limits_defaults Resource limit defaults for tenants without overrides
+# TYPE cortex_limits_defaults gauge
+cortex_limits_defaults{limit_name="ingestion_rate"} 25000
+`
+
+// exporter serves the metrics text, which can be swapped by the test
+type exporter struct {
+	metrics string
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, e.metrics)
+}
+
+// newTestReader returns a reader of the exporter with the grace period, and a clock the
+// test advances
+func newTestReader(t *testing.T, handler http.Handler, gracePeriod time.Duration) (*Reader, *time.Time) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{}
+	cfg.Mimir.OverridesExporter.Endpoint = server.URL
+	cfg.Mimir.OverridesExporter.MismatchGracePeriod = gracePeriod
+
+	reader := NewReader(config.NewLive(cfg), nil, logr.Discard())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	reader.now = func() time.Time { return now }
+	return reader, &now
+}
+
+func TestReadParsesOverrides(t *testing.T) {
+	reader, _ := newTestReader(t, &exporter{metrics: exporterMetrics}, time.Minute)
+
+	got, err := reader.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	want := TenantLimits{
+		"tenant-a": {"ingestion_rate": 10000, "max_global_series_per_user": 150000},
+		"tenant-b": {"ingestion_rate": 5000, "some_future_limit": 7},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+}
+
+func TestReadWithoutOverridesMetric(t *testing.T) {
+	reader, _ := newTestReader(t, &exporter{metrics: "# TYPE up gauge\nup 1\n"}, time.Minute)
+
+	got, err := reader.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Read() = %v, want no limits", got)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"server error", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}},
+		{"malformed metrics", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "cortex_limits_overrides{limit_name=\"ingestion_rate\" 1\n")
+		}},
+	}
+	for _, tt := range tests {
+		reader, _ := newTestReader(t, tt.handler, time.Minute)
+		if _, err := reader.Read(context.Background()); err == nil {
+			t.Errorf("%s: Read succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	configMap := map[string]map[string]interface{}{
+		"tenant-a": {
+			"ingestion_rate":             10000,
+			"max_global_series_per_user": "200000",
+			"compactor_blocks_retention": "30d", // not numeric
+			"max_fetched_chunks":         1000,  // not exported
+		},
+		"tenant-b": {"ingestion_rate": 5000.0},
+		"tenant-c": {"ingestion_rate": int64(8000)},
+	}
+	loaded := TenantLimits{
+		"tenant-a": {"ingestion_rate": 10000, "max_global_series_per_user": 150000},
+		"tenant-b": {"ingestion_rate": 5000, "some_future_limit": 7},
+	}
+
+	report := Compare(configMap, loaded)
+
+	var got []string
+	for _, item := range report.Items {
+		got = append(got, item.TenantID+"/"+item.LimitName+"="+item.Status)
+	}
+	want := []string{
+		"tenant-a/ingestion_rate=" + StatusIdentical,
+		"tenant-a/max_global_series_per_user=" + StatusMismatched,
+		"tenant-b/ingestion_rate=" + StatusIdentical,
+		"tenant-c/ingestion_rate=" + StatusNotLoaded,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+	if report.IdenticalCount != 2 || report.MismatchedCount != 1 || report.NotLoadedCount != 1 {
+		t.Errorf("counts = %d identical, %d mismatched, %d not loaded, want 2, 1, 1",
+			report.IdenticalCount, report.MismatchedCount, report.NotLoadedCount)
+	}
+}
+
+func TestCheckMismatchGracePeriod(t *testing.T) {
+	source := &exporter{metrics: exporterMetrics}
+	reader, now := newTestReader(t, source, 5*time.Minute)
+	ctx := context.Background()
+
+	// ingestion_rate of tenant-a has just been raised in the ConfigMap
+	configMap := map[string]map[string]interface{}{
+		"tenant-a": {"ingestion_rate": 20000, "max_global_series_per_user": 150000},
+	}
+
+	report, err := reader.Check(ctx, configMap)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.MismatchedCount != 1 || report.StaleCount != 0 {
+		t.Fatalf("fresh override: %d mismatched, %d stale, want 1 mismatched within the grace period",
+			report.MismatchedCount, report.StaleCount)
+	}
+	firstSeen := *now
+
+	*now = now.Add(4 * time.Minute)
+	if report, err = reader.Check(ctx, configMap); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.StaleCount != 0 {
+		t.Errorf("after 4m: stale = %d, want 0", report.StaleCount)
+	}
+
+	*now = now.Add(time.Minute)
+	if report, err = reader.Check(ctx, configMap); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	stale := report.Stale()
+	if len(stale) != 1 || stale[0].TenantID != "tenant-a" || stale[0].LimitName != "ingestion_rate" {
+		t.Fatalf("after 5m: stale = %+v, want tenant-a ingestion_rate", stale)
+	}
+	if !stale[0].MismatchSince.Equal(firstSeen) {
+		t.Errorf("MismatchSince = %v, want %v", stale[0].MismatchSince, firstSeen)
+	}
+
+	// Once the exporter catches up the mismatch is forgotten, so a later change gets a
+	// full grace period again
+	source.metrics = `# TYPE cortex_limits_overrides gauge
+cortex_limits_overrides{limit_name="ingestion_rate",user="tenant-a"} 20000
+cortex_limits_overrides{limit_name="max_global_series_per_user",user="tenant-a"} 150000
+`
+	*now = now.Add(time.Minute)
+	if report, err = reader.Check(ctx, configMap); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.IdenticalCount != 2 || report.MismatchedCount != 0 || report.StaleCount != 0 {
+		t.Errorf("caught up: %d identical, %d mismatched, %d stale, want 2 identical",
+			report.IdenticalCount, report.MismatchedCount, report.StaleCount)
+	}
+
+	configMap["tenant-a"]["ingestion_rate"] = 30000
+	*now = now.Add(time.Minute)
+	if report, err = reader.Check(ctx, configMap); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if report.MismatchedCount != 1 || report.StaleCount != 0 {
+		t.Errorf("next change: %d mismatched, %d stale, want 1 mismatched within the grace period",
+			report.MismatchedCount, report.StaleCount)
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{2.5, 2.5, true},
+		{float32(0.5), 0.5, true},
+		{10, 10, true},
+		{int64(20), 20, true},
+		{uint64(30), 30, true},
+		{"1e3", 1000, true},
+		{"30d", 0, false},
+		{true, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := toFloat64(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("toFloat64(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
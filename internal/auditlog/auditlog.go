@@ -16,6 +16,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 )
 
 // AuditEntry represents a single audit log entry
@@ -23,6 +24,7 @@ type AuditEntry struct {
 	ID          string                 `json:"id"`
 	Timestamp   time.Time              `json:"timestamp"`
 	Tenant      string                 `json:"tenant,omitempty"`
+	Tier        string                 `json:"tier,omitempty"`
 	Action      string                 `json:"action"`
 	Reason      string                 `json:"reason"`
 	Changes     map[string]interface{} `json:"changes"`
@@ -34,6 +36,7 @@ type AuditEntry struct {
 	Component   string                 `json:"component"`
 	User        string                 `json:"user,omitempty"`
 	RequestID   string                 `json:"request_id,omitempty"`
+	ReconcileID int64                  `json:"reconcile_id,omitempty"`
 }
 
 // AuditLogger interface defines methods for audit logging
@@ -52,8 +55,10 @@ type AuditFilter struct {
 	StartTime *time.Time
 	EndTime   *time.Time
 	Success   *bool
-	Limit     int
-	Offset    int
+	// ReconcileID selects the entries written during one reconciliation
+	ReconcileID int64
+	Limit       int
+	Offset      int
 }
 
 // MemoryAuditLogger implements audit logging in memory
@@ -103,6 +108,7 @@ func (m *MemoryAuditLogger) LogEntry(entry *AuditEntry) error {
 		removeCount := len(m.entries) - m.maxEntries
 		m.entries = m.entries[removeCount:]
 	}
+	metrics.AuditLogMetricsInstance.SetAuditLogEntries("memory", len(m.entries))
 
 	m.log.Info("audit entry logged",
 		"id", entry.ID,
@@ -171,6 +177,7 @@ func (m *MemoryAuditLogger) PurgeOldEntries(ctx context.Context, olderThan time.
 	}
 
 	m.entries = filtered
+	metrics.AuditLogMetricsInstance.SetAuditLogEntries("memory", len(m.entries))
 	m.log.Info("purged old audit entries", "count", purgedCount, "older_than", olderThan)
 
 	return nil
@@ -207,6 +214,10 @@ func (m *MemoryAuditLogger) matchesFilter(entry *AuditEntry, filter *AuditFilter
 		return false
 	}
 
+	if filter.ReconcileID != 0 && entry.ReconcileID != filter.ReconcileID {
+		return false
+	}
+
 	return true
 }
 
@@ -612,7 +623,11 @@ func (c *ConfigMapAuditLogger) updateConfigMap(ctx context.Context, configMap *c
 	}
 	configMap.Data["audit.json"] = string(auditJSON)
 
-	return c.client.Update(ctx, configMap)
+	if err := c.client.Update(ctx, configMap); err != nil {
+		return err
+	}
+	metrics.AuditLogMetricsInstance.SetAuditLogEntries("configmap", len(entries))
+	return nil
 }
 
 func (c *ConfigMapAuditLogger) matchesFilter(entry *AuditEntry, filter *AuditFilter) bool {
@@ -640,18 +655,24 @@ func (c *ConfigMapAuditLogger) matchesFilter(entry *AuditEntry, filter *AuditFil
 		return false
 	}
 
+	if filter.ReconcileID != 0 && entry.ReconcileID != filter.ReconcileID {
+		return false
+	}
+
 	return true
 }
 
 // NewAuditLogger creates the appropriate audit logger based on configuration
-func NewAuditLogger(cfg *config.Config, client client.Client, log logr.Logger) AuditLogger {
+func NewAuditLogger(live *config.Live, client client.Client, log logr.Logger) AuditLogger {
+	cfg := live.Load()
 	if !cfg.AuditLog.Enabled {
 		return &NoOpAuditLogger{}
 	}
 
+	var logger AuditLogger
 	switch cfg.AuditLog.StorageType {
 	case "configmap":
-		return NewConfigMapAuditLogger(
+		logger = NewConfigMapAuditLogger(
 			client,
 			cfg.AuditLog.ConfigMapName,
 			cfg.Mimir.Namespace,
@@ -659,11 +680,36 @@ func NewAuditLogger(cfg *config.Config, client client.Client, log logr.Logger) A
 			log,
 		)
 	case "memory":
-		return NewMemoryAuditLogger(cfg.AuditLog.MaxEntries, log)
+		logger = NewMemoryAuditLogger(cfg.AuditLog.MaxEntries, log)
 	default:
 		log.Info("unknown audit log storage type, using memory", "type", cfg.AuditLog.StorageType)
-		return NewMemoryAuditLogger(cfg.AuditLog.MaxEntries, log)
+		logger = NewMemoryAuditLogger(cfg.AuditLog.MaxEntries, log)
+	}
+	return &tierAuditLogger{AuditLogger: logger, live: live}
+}
+
+// tierAuditLogger annotates the entries of tenants with the tier they are resolved to
+type tierAuditLogger struct {
+	AuditLogger
+	live *config.Live
+}
+
+// config returns the configuration in effect
+func (t *tierAuditLogger) config() *config.Config {
+	return t.live.Load()
+}
+
+func (t *tierAuditLogger) LogEntry(entry *AuditEntry) error {
+	if entry.Tenant != "" && entry.Tier == "" {
+		entry.Tier = t.config().Limits.TierForTenant(entry.Tenant).Tier
+	}
+	err := t.AuditLogger.LogEntry(entry)
+	result := "success"
+	if err != nil {
+		result = "error"
 	}
+	metrics.AuditLogMetricsInstance.IncAuditEntries(entry.Action, result)
+	return err
 }
 
 // NoOpAuditLogger is a no-op implementation of AuditLogger
@@ -732,8 +778,9 @@ func NewErrorEntry(tenant, action, reason string, err error) *AuditEntry {
 	}
 }
 
-// NewSpikeDetectionEntry creates an audit entry for spike detection
-func NewSpikeDetectionEntry(tenant, metricName string, oldValue, newValue float64) *AuditEntry {
+// NewSpikeDetectionEntry creates an audit entry for spike detection; predictive marks a
+// spike predicted from the trend of the metric before it was observed
+func NewSpikeDetectionEntry(tenant, metricName string, oldValue, newValue float64, predictive bool) *AuditEntry {
 	return &AuditEntry{
 		Tenant: tenant,
 		Action: "spike-detected",
@@ -744,6 +791,7 @@ func NewSpikeDetectionEntry(tenant, metricName string, oldValue, newValue float6
 				"old": oldValue,
 				"new": newValue,
 			},
+			"predictive": predictive,
 		},
 		Success: true,
 	}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// Cache stores JSON-serializable values under string keys for a limited time
+type Cache interface {
+	// Get decodes the value stored under key into dest and reports whether it was found
+	Get(ctx context.Context, key string, dest interface{}) (bool, error)
+
+	// Set stores value under key; a non-positive ttl uses the cache's default TTL
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// Delete removes key from the cache
+	Delete(ctx context.Context, key string) error
+
+	// Name returns the backend name used in metrics and logs
+	Name() string
+}
+
+// Cache backend types
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+// New creates the cache configured in cfg. It returns nil when caching is disabled.
+// A Redis cache always falls back to an in-memory cache while Redis is unreachable.
+func New(cfg config.CacheConfig, log logr.Logger) Cache {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	memory := NewMemoryCache(cfg.TTL, cfg.SizeMB)
+
+	switch cfg.Type {
+	case TypeRedis:
+		redis := NewRedisCache(cfg.Redis, cfg.TTL)
+		log.Info("using redis cache with in-memory fallback", "address", cfg.Redis.Address, "db", cfg.Redis.DB)
+		return NewFallbackCache(redis, memory, log.WithName("fallback"))
+	default:
+		log.Info("using in-memory cache", "size_mb", cfg.SizeMB, "ttl", cfg.TTL)
+		return memory
+	}
+}
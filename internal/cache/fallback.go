@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Fallback circuit breaker settings
+const (
+	fallbackFailureThreshold = 3
+	fallbackCooldown         = 30 * time.Second
+)
+
+// FallbackCache serves from a remote primary cache and switches to a local fallback
+// after repeated primary failures. The primary is retried after a cooldown, so an
+// outage of the remote cache degrades to per-replica caching instead of failing callers.
+type FallbackCache struct {
+	primary  Cache
+	fallback Cache
+	log      logr.Logger
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewFallbackCache creates a cache that falls back to fallback while primary is failing
+func NewFallbackCache(primary, fallback Cache, log logr.Logger) *FallbackCache {
+	metrics.CacheMetricsInstance.SetCacheBackendAvailable(primary.Name(), true)
+	return &FallbackCache{
+		primary:  primary,
+		fallback: fallback,
+		log:      log,
+	}
+}
+
+// Name returns the primary backend name
+func (f *FallbackCache) Name() string {
+	return f.primary.Name()
+}
+
+// Get reads from the primary, or from the fallback while the primary is unavailable
+func (f *FallbackCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	if f.usePrimary() {
+		found, err := f.primary.Get(ctx, key, dest)
+		if err == nil {
+			f.recordSuccess()
+			return found, nil
+		}
+		f.recordFailure(err)
+	}
+	return f.fallback.Get(ctx, key, dest)
+}
+
+// Set writes to the fallback and, when available, the primary. Keeping the fallback
+// warm means a primary outage does not start from an empty cache.
+func (f *FallbackCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	fallbackErr := f.fallback.Set(ctx, key, value, ttl)
+
+	if f.usePrimary() {
+		if err := f.primary.Set(ctx, key, value, ttl); err != nil {
+			f.recordFailure(err)
+		} else {
+			f.recordSuccess()
+		}
+	}
+
+	return fallbackErr
+}
+
+// Delete removes key from both caches
+func (f *FallbackCache) Delete(ctx context.Context, key string) error {
+	fallbackErr := f.fallback.Delete(ctx, key)
+
+	if f.usePrimary() {
+		if err := f.primary.Delete(ctx, key); err != nil {
+			f.recordFailure(err)
+		} else {
+			f.recordSuccess()
+		}
+	}
+
+	return fallbackErr
+}
+
+// usePrimary reports whether the primary should be tried; once the cooldown has
+// passed the primary is tried again and the next result decides its state
+func (f *FallbackCache) usePrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.failures < fallbackFailureThreshold || time.Now().After(f.openUntil)
+}
+
+func (f *FallbackCache) recordSuccess() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures >= fallbackFailureThreshold {
+		f.log.Info("cache backend recovered", "backend", f.primary.Name())
+		metrics.CacheMetricsInstance.SetCacheBackendAvailable(f.primary.Name(), true)
+	}
+	f.failures = 0
+}
+
+func (f *FallbackCache) recordFailure(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.failures++
+	if f.failures >= fallbackFailureThreshold {
+		if f.failures == fallbackFailureThreshold {
+			f.log.Error(err, "cache backend unavailable, using in-memory fallback",
+				"backend", f.primary.Name(), "cooldown", fallbackCooldown)
+			metrics.CacheMetricsInstance.SetCacheBackendAvailable(f.primary.Name(), false)
+		}
+		f.openUntil = time.Now().Add(fallbackCooldown)
+	}
+}
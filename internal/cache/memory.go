@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+type memoryEntry struct {
+	data      []byte
+	expiresAt time.Time
+	storedAt  time.Time
+}
+
+// MemoryCache is a process-local cache bounded by the total size of the stored values
+type MemoryCache struct {
+	mu         sync.Mutex
+	entries    map[string]*memoryEntry
+	size       int
+	maxSize    int
+	defaultTTL time.Duration
+}
+
+// NewMemoryCache creates an in-memory cache holding at most sizeMB of serialized values
+func NewMemoryCache(defaultTTL time.Duration, sizeMB int) *MemoryCache {
+	if defaultTTL <= 0 {
+		defaultTTL = 5 * time.Minute
+	}
+	if sizeMB <= 0 {
+		sizeMB = 64
+	}
+	return &MemoryCache{
+		entries:    make(map[string]*memoryEntry),
+		maxSize:    sizeMB * 1024 * 1024,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// Name returns the backend name
+func (m *MemoryCache) Name() string {
+	return TypeMemory
+}
+
+// Get decodes a live entry into dest
+func (m *MemoryCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	start := time.Now()
+	defer func() {
+		metrics.CacheMetricsInstance.ObserveCacheOperation(TypeMemory, "get", time.Since(start).Seconds())
+	}()
+
+	m.mu.Lock()
+	entry, exists := m.entries[key]
+	if exists && time.Now().After(entry.expiresAt) {
+		m.removeLocked(key)
+		exists = false
+	}
+	m.mu.Unlock()
+
+	if !exists {
+		metrics.CacheMetricsInstance.IncCacheRequest(TypeMemory, "miss")
+		return false, nil
+	}
+
+	if err := json.Unmarshal(entry.data, dest); err != nil {
+		metrics.CacheMetricsInstance.IncCacheRequest(TypeMemory, "error")
+		return false, fmt.Errorf("failed to decode cached value %s: %w", key, err)
+	}
+
+	metrics.CacheMetricsInstance.IncCacheRequest(TypeMemory, "hit")
+	return true, nil
+}
+
+// Set stores value under key, evicting expired and then the oldest entries when the
+// cache is full
+func (m *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	start := time.Now()
+	defer func() {
+		metrics.CacheMetricsInstance.ObserveCacheOperation(TypeMemory, "set", time.Since(start).Seconds())
+	}()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s: %w", key, err)
+	}
+	if len(data) > m.maxSize {
+		return fmt.Errorf("value for %s is %d bytes, larger than the cache", key, len(data))
+	}
+	if ttl <= 0 {
+		ttl = m.defaultTTL
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(key)
+	m.evictLocked(len(data))
+
+	now := time.Now()
+	m.entries[key] = &memoryEntry{data: data, expiresAt: now.Add(ttl), storedAt: now}
+	m.size += len(data)
+
+	return nil
+}
+
+// Delete removes key from the cache
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeLocked(key)
+	return nil
+}
+
+func (m *MemoryCache) removeLocked(key string) {
+	if entry, exists := m.entries[key]; exists {
+		m.size -= len(entry.data)
+		delete(m.entries, key)
+	}
+}
+
+// evictLocked frees room for an entry of the given size
+func (m *MemoryCache) evictLocked(needed int) {
+	if m.size+needed <= m.maxSize {
+		return
+	}
+
+	now := time.Now()
+	for key, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			m.removeLocked(key)
+		}
+	}
+
+	for m.size+needed > m.maxSize && len(m.entries) > 0 {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range m.entries {
+			if oldestKey == "" || entry.storedAt.Before(oldest) {
+				oldestKey = key
+				oldest = entry.storedAt
+			}
+		}
+		m.removeLocked(oldestKey)
+	}
+}
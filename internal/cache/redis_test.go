@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+func cacheRequests(t *testing.T, backend, result string) float64 {
+	return metricstest.Value(t, "mimir_limit_optimizer_cache_requests_total", map[string]string{"backend": backend, "result": result})
+}
+
+// cachedSample is shaped like the collected tenant metrics the collector caches
+type cachedSample struct {
+	Tenant    string    `json:"tenant"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func newTestRedis(t *testing.T, cfg config.RedisConfig) (*RedisCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	cfg.Address = server.Addr()
+	cache := NewRedisCache(cfg, time.Minute)
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, server
+}
+
+func TestRedisCacheRoundTrip(t *testing.T) {
+	cache, server := newTestRedis(t, config.RedisConfig{Password: "secret", DB: 2})
+	server.RequireAuth("secret")
+	ctx := context.Background()
+
+	metricsBefore := map[string][]cachedSample{
+		"cortex_distributor_received_samples_total": {{Tenant: "tenant-a", Value: 1500, Timestamp: time.Unix(1700000000, 0).UTC()}},
+	}
+	hits, misses := cacheRequests(t, TypeRedis, "hit"), cacheRequests(t, TypeRedis, "miss")
+	gets := metricstest.Value(t, "mimir_limit_optimizer_cache_operation_duration_seconds", map[string]string{"backend": TypeRedis, "operation": "get"})
+
+	var missing map[string][]cachedSample
+	if found, err := cache.Get(ctx, "tenant-metrics", &missing); found || err != nil {
+		t.Fatalf("Get before Set = %v, %v", found, err)
+	}
+	if err := cache.Set(ctx, "tenant-metrics", metricsBefore, 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	var metricsAfter map[string][]cachedSample
+	if found, err := cache.Get(ctx, "tenant-metrics", &metricsAfter); !found || err != nil {
+		t.Fatalf("Get = %v, %v", found, err)
+	}
+	sample := metricsAfter["cortex_distributor_received_samples_total"][0]
+	if sample.Value != 1500 || !sample.Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("cached sample = %+v", sample)
+	}
+
+	// Values are stored in the configured database under the key prefix
+	server.Select(2)
+	if !server.Exists(defaultRedisKeyPrefix + "tenant-metrics") {
+		t.Errorf("key not stored in database 2 under the default prefix; keys %v", server.Keys())
+	}
+	if ttl := server.TTL(defaultRedisKeyPrefix + "tenant-metrics"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want the default TTL", ttl)
+	}
+
+	if got := cacheRequests(t, TypeRedis, "hit") - hits; got != 1 {
+		t.Errorf("recorded %v hits, want 1", got)
+	}
+	if got := cacheRequests(t, TypeRedis, "miss") - misses; got != 1 {
+		t.Errorf("recorded %v misses, want 1", got)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_cache_operation_duration_seconds", map[string]string{"backend": TypeRedis, "operation": "get"}) - gets; got != 2 {
+		t.Errorf("recorded %v get latencies, want 2", got)
+	}
+}
+
+func TestRedisCacheExpiry(t *testing.T) {
+	cache, server := newTestRedis(t, config.RedisConfig{KeyPrefix: "test:"})
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "scan", map[string]int{"components": 3}, 10*time.Second); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	server.FastForward(11 * time.Second)
+
+	var scan map[string]int
+	if found, err := cache.Get(ctx, "scan", &scan); found || err != nil {
+		t.Errorf("Get after expiry = %v, %v", found, err)
+	}
+	if err := cache.Delete(ctx, "scan"); err != nil {
+		t.Errorf("Delete of an expired key: %v", err)
+	}
+}
+
+func TestFallbackCacheDuringRedisOutage(t *testing.T) {
+	redis, server := newTestRedis(t, config.RedisConfig{Timeout: 100 * time.Millisecond})
+	cache := NewFallbackCache(redis, NewMemoryCache(time.Minute, 1), logr.Discard())
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "limits", "before-outage", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	server.Close()
+
+	// Reads keep working from the in-memory copy while Redis is down
+	for i := 0; i < fallbackFailureThreshold; i++ {
+		var value string
+		if found, err := cache.Get(ctx, "limits", &value); !found || err != nil || value != "before-outage" {
+			t.Fatalf("Get during outage = %q, %v, %v", value, found, err)
+		}
+	}
+	if available := metricstest.Value(t, "mimir_limit_optimizer_cache_backend_available", map[string]string{"backend": TypeRedis}); available != 0 {
+		t.Errorf("redis backend reported available during the outage")
+	}
+	if cache.usePrimary() {
+		t.Errorf("redis is still tried after %d failures", fallbackFailureThreshold)
+	}
+
+	if err := cache.Set(ctx, "limits", "during-outage", 0); err != nil {
+		t.Errorf("Set during outage: %v", err)
+	}
+	var value string
+	if found, _ := cache.Get(ctx, "limits", &value); !found || value != "during-outage" {
+		t.Errorf("Get during outage = %q, %v", value, found)
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	if New(config.CacheConfig{Enabled: false}, logr.Discard()) != nil {
+		t.Errorf("disabled cache is not nil")
+	}
+	if c := New(config.CacheConfig{Enabled: true, Type: TypeMemory}, logr.Discard()); c.Name() != TypeMemory {
+		t.Errorf("memory cache backend = %s", c.Name())
+	}
+	if c, ok := New(config.CacheConfig{Enabled: true, Type: TypeRedis}, logr.Discard()).(*FallbackCache); !ok || c.Name() != TypeRedis {
+		t.Errorf("redis cache = %T, want a redis cache with fallback", c)
+	}
+}
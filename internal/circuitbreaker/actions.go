@@ -0,0 +1,257 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// emergencyActionTimeout bounds a single action execution or recovery
+	emergencyActionTimeout = 30 * time.Second
+
+	// maxActionResults is the number of action outcomes kept for the protection status
+	maxActionResults = 50
+)
+
+// Phases of an emergency action
+const (
+	ActionPhaseExecute = "execute"
+	ActionPhaseRecover = "recover"
+)
+
+// EmergencyEvent describes the emergency an action responds to
+type EmergencyEvent struct {
+	Reason string
+	Panic  bool
+	// Results are the outcomes of the actions run before this one in the same batch
+	Results []ActionResult
+}
+
+// ActionResult is the outcome of one emergency action execution or recovery
+type ActionResult struct {
+	Action    string    `json:"action"`
+	Phase     string    `json:"phase"`
+	Reason    string    `json:"reason,omitempty"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EmergencyAction is run when emergency or panic mode is entered. Recover undoes it
+// when emergency mode is exited and may be nil.
+type EmergencyAction struct {
+	Execute func(ctx context.Context, event EmergencyEvent) error
+	Recover func(ctx context.Context) error
+}
+
+// pendingAction is an action execution or recovery queued while bp.mu is held
+type pendingAction struct {
+	name  string
+	phase string
+	event EmergencyEvent
+}
+
+// RegisterEmergencyAction sets the implementation of an action named in
+// emergency.panicMode.actions, replacing any previous one
+func (bp *BlastProtector) RegisterEmergencyAction(name string, action EmergencyAction) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.actions[name] = action
+}
+
+// queueEmergencyActions queues the configured actions not executed yet in this
+// emergency. The caller must hold bp.mu and call runPendingActions after releasing it.
+func (bp *BlastProtector) queueEmergencyActions(reason string, panicMode bool) {
+	for _, name := range bp.config().Emergency.PanicMode.Actions {
+		if bp.executedActions[name] {
+			continue
+		}
+		bp.executedActions[name] = true
+		bp.actionOrder = append(bp.actionOrder, name)
+		bp.pendingActions = append(bp.pendingActions, pendingAction{
+			name:  name,
+			phase: ActionPhaseExecute,
+			event: EmergencyEvent{Reason: reason, Panic: panicMode},
+		})
+	}
+}
+
+// queueActionRecovery queues the recovery of the executed actions in reverse order.
+// The caller must hold bp.mu and call runPendingActions after releasing it.
+func (bp *BlastProtector) queueActionRecovery(reason string) {
+	for i := len(bp.actionOrder) - 1; i >= 0; i-- {
+		name := bp.actionOrder[i]
+		if action, registered := bp.actions[name]; registered && action.Recover != nil {
+			bp.pendingActions = append(bp.pendingActions, pendingAction{
+				name:  name,
+				phase: ActionPhaseRecover,
+				event: EmergencyEvent{Reason: reason},
+			})
+		}
+	}
+	bp.actionOrder = nil
+	bp.executedActions = make(map[string]bool)
+}
+
+// ResumeEmergencyActions puts the protector back into emergency mode for actions executed
+// before a restart whose changes are still in effect. The actions are not executed
+// again; exiting emergency mode runs their recovery.
+func (bp *BlastProtector) ResumeEmergencyActions(reason string, actions []string) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	for _, name := range actions {
+		if !bp.executedActions[name] {
+			bp.executedActions[name] = true
+			bp.actionOrder = append(bp.actionOrder, name)
+		}
+	}
+	if bp.emergencyMode {
+		return
+	}
+
+	before := bp.protectionState()
+	bp.emergencyMode = true
+	counters, _ := bp.setState(StateOpen, "emergency mode: "+reason)
+	bp.auditProtectionEvent("circuit_breaker_emergency_mode", before, reason, []string{}, counters)
+	bp.log.Info("emergency mode resumed, recovery restores the limits of the emergency actions",
+		"reason", reason, "actions", actions)
+}
+
+// runPendingActions runs the queued actions in order. Actions may write ConfigMaps and
+// send alerts, so they run without bp.mu; actionsMu keeps them in queue order.
+func (bp *BlastProtector) runPendingActions() {
+	bp.actionsMu.Lock()
+	defer bp.actionsMu.Unlock()
+
+	bp.mu.Lock()
+	pending := bp.pendingActions
+	bp.pendingActions = nil
+	bp.mu.Unlock()
+
+	var results []ActionResult
+	for _, queued := range pending {
+		bp.mu.RLock()
+		action, registered := bp.actions[queued.name]
+		bp.mu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), emergencyActionTimeout)
+		var err error
+		switch {
+		case !registered || action.Execute == nil:
+			err = fmt.Errorf("no implementation registered for emergency action %s", queued.name)
+		case queued.phase == ActionPhaseRecover:
+			err = action.Recover(ctx)
+		default:
+			event := queued.event
+			event.Results = results
+			err = action.Execute(ctx, event)
+		}
+		cancel()
+
+		result := ActionResult{
+			Action:    queued.name,
+			Phase:     queued.phase,
+			Reason:    queued.event.Reason,
+			Success:   err == nil,
+			Timestamp: time.Now(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+		bp.recordActionResult(result, queued.event.Panic)
+	}
+}
+
+// recordActionResult logs and audits an action outcome and keeps it for the status
+func (bp *BlastProtector) recordActionResult(result ActionResult, panicMode bool) {
+	if result.Success {
+		bp.log.Info("emergency action completed", "action", result.Action, "phase", result.Phase)
+	} else {
+		metrics.HealthMetricsInstance.IncErrorTotal("circuit-breaker", "emergency-action")
+		bp.log.Error(fmt.Errorf("%s", result.Error), "emergency action failed", "action", result.Action, "phase", result.Phase)
+	}
+
+	bp.mu.Lock()
+	bp.actionResults = append(bp.actionResults, result)
+	if len(bp.actionResults) > maxActionResults {
+		bp.actionResults = bp.actionResults[len(bp.actionResults)-maxActionResults:]
+	}
+	auditLogger := bp.auditLogger
+	bp.mu.Unlock()
+
+	if auditLogger == nil {
+		return
+	}
+	entry := &auditlog.AuditEntry{
+		Action: "emergency_action",
+		Reason: result.Reason,
+		Changes: map[string]interface{}{
+			"action":     result.Action,
+			"phase":      result.Phase,
+			"panic_mode": panicMode,
+		},
+		Source:    "circuit-breaker",
+		Component: "circuit-breaker",
+		Success:   result.Success,
+		Error:     result.Error,
+	}
+	if err := auditLogger.LogEntry(entry); err != nil {
+		bp.log.Error(err, "failed to audit emergency action", "action", result.Action, "phase", result.Phase)
+	}
+}
+
+// alertAction sends the outcomes of the actions run before it through the alerting
+// pipeline
+func (bp *BlastProtector) alertAction(ctx context.Context, event EmergencyEvent) error {
+	bp.mu.RLock()
+	manager := bp.alertManager
+	state := bp.state.String()
+	bp.mu.RUnlock()
+
+	if manager == nil {
+		return fmt.Errorf("alerting is not configured")
+	}
+
+	outcomes := make([]string, 0, len(event.Results))
+	for _, result := range event.Results {
+		outcome := result.Action + ": ok"
+		if !result.Success {
+			outcome = fmt.Sprintf("%s: failed (%s)", result.Action, result.Error)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	message := fmt.Sprintf("Emergency actions executed due to: %s", event.Reason)
+	if len(outcomes) > 0 {
+		message += "\n" + strings.Join(outcomes, "\n")
+	}
+
+	alertType := alerting.AlertTypeEmergency
+	if event.Panic {
+		alertType = alerting.AlertTypePanicMode
+	}
+	alert := alerting.CreateAlert(alertType, priorityForSeverity("high"), "EMERGENCY ACTIONS EXECUTED", message)
+	alert.Details = map[string]interface{}{
+		"event":                 "emergency_actions_executed",
+		"reason":                event.Reason,
+		"actions":               event.Results,
+		"circuit_breaker_state": state,
+	}
+	manager.SendAlert(alert)
+	return nil
+}
+
+// defaultEmergencyActions are the actions implemented by the protector itself
+func (bp *BlastProtector) defaultEmergencyActions() map[string]EmergencyAction {
+	return map[string]EmergencyAction{
+		config.EmergencyActionAlert: {Execute: bp.alertAction},
+	}
+}
@@ -0,0 +1,97 @@
+package circuitbreaker
+
+import (
+	"math"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+)
+
+// defaultAdaptationPercentile is used when realtimeAdaptation.percentile is not set
+const defaultAdaptationPercentile = 95.0
+
+// adaptThresholds moves the auto thresholds of each collected tenant toward the
+// realtimeAdaptation.percentile of its observed rates plus its safety margin, as an
+// exponentially weighted moving average with realtimeAdaptation.learningRate. A cycle
+// changes a threshold by at most realtimeAdaptation.maxChangePercent, and a threshold
+// never drops below the observed percentile plus safetyMargins.minMargin. The caller
+// must hold bp.mu.
+func (bp *BlastProtector) adaptThresholds(tenantMetrics map[string]*collector.TenantMetrics, now time.Time) {
+	adaptation := bp.config().CircuitBreaker.AutoConfig.RealtimeAdaptation
+	minMargin := bp.config().CircuitBreaker.AutoConfig.SafetyMargins.MinMargin
+	percentile := adaptation.Percentile
+	if percentile <= 0 || percentile > 100 {
+		percentile = defaultAdaptationPercentile
+	}
+
+	bd := bp.blastDetector
+	bd.mu.RLock()
+	defer bd.mu.RUnlock()
+	bp.autoConfig.mu.Lock()
+	defer bp.autoConfig.mu.Unlock()
+
+	for tenant := range tenantMetrics {
+		threshold, exists := bp.autoConfig.tenantThresholds[tenant]
+		if !exists {
+			continue
+		}
+		blastMetrics, observed := bd.metrics[tenant]
+		if !observed || len(blastMetrics.samples) == 0 {
+			continue
+		}
+
+		adapt := func(current float64, rate func(baselineSample) float64) float64 {
+			values := make([]float64, len(blastMetrics.samples))
+			for i, sample := range blastMetrics.samples {
+				values[i] = rate(sample)
+			}
+			return adaptThreshold(current, percentileOf(values, percentile), threshold.SafetyMargin, minMargin,
+				adaptation.LearningRate, adaptation.MaxChangePercent)
+		}
+
+		threshold.IngestionThreshold = adapt(threshold.IngestionThreshold, func(s baselineSample) float64 { return s.ingestion })
+		threshold.QueryThreshold = adapt(threshold.QueryThreshold, func(s baselineSample) float64 { return s.query })
+		threshold.SeriesThreshold = adapt(threshold.SeriesThreshold, func(s baselineSample) float64 { return s.series })
+		threshold.LastAdapted = now
+		threshold.AdaptationCycles++
+
+		bp.log.V(2).Info("adapted thresholds for tenant",
+			"tenant", tenant,
+			"newIngestionThreshold", threshold.IngestionThreshold,
+			"newQueryThreshold", threshold.QueryThreshold,
+			"newSeriesThreshold", threshold.SeriesThreshold,
+			"cycles", threshold.AdaptationCycles)
+	}
+}
+
+// adaptThreshold returns a threshold moved toward the observed rate plus the safety
+// margin by the learning rate, by at most maxChangePercent of the current threshold and
+// no lower than the observed rate plus the minimum margin. Thresholds that are not
+// positive belong to limits without an applied value and are left unset, as are
+// thresholds without observed load.
+func adaptThreshold(current, observed, margin, minMargin, learningRate, maxChangePercent float64) float64 {
+	if current <= 0 || observed <= 0 {
+		return current
+	}
+
+	target := observed * (1 + margin/100)
+	change := (target - current) * learningRate
+	if maxChange := current * maxChangePercent / 100; maxChangePercent > 0 && math.Abs(change) > maxChange {
+		change = math.Copysign(maxChange, change)
+	}
+
+	adapted := current + change
+	if floor := observed * (1 + minMargin/100); adapted < floor {
+		adapted = floor
+	}
+	return adapted
+}
+
+// keptThreshold returns an adapted threshold kept over the one derived from the limits,
+// unless the limit no longer has a value or the threshold was never set
+func keptThreshold(adapted, derived float64) float64 {
+	if derived <= 0 || adapted <= 0 {
+		return derived
+	}
+	return adapted
+}
@@ -0,0 +1,188 @@
+package circuitbreaker
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+var adaptationEpoch = time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+// adaptingProtector is a circuit breaker adapting its auto thresholds every 10 minutes
+// on a simulated clock, with a 25% safety margin never narrower than 10%
+type adaptingProtector struct {
+	*BlastProtector
+	now time.Time
+}
+
+func newAdaptingProtector(learningRate, maxChangePercent float64) *adaptingProtector {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.CircuitBreaker.RateLimit.Enabled = false
+	cfg.Emergency.PanicMode.Actions = nil
+	autoConfig := &cfg.CircuitBreaker.AutoConfig
+	autoConfig.Enabled = true
+	autoConfig.MinObservationPeriod = 0
+	autoConfig.SafetyMargins.DefaultMargin = 25
+	autoConfig.SafetyMargins.MinMargin = 10
+	autoConfig.RealtimeAdaptation = config.RealtimeAdaptationConfig{
+		Enabled:          true,
+		Interval:         10 * time.Minute,
+		LearningRate:     learningRate,
+		MaxChangePercent: maxChangePercent,
+		Percentile:       95,
+	}
+
+	ap := &adaptingProtector{BlastProtector: NewBlastProtector(config.NewLive(cfg), logr.Discard()), now: adaptationEpoch}
+	ap.SetClock(func() time.Time { return ap.now })
+	return ap
+}
+
+// cycle processes one adaptation interval of tenant-a ingesting rate samples/s
+func (ap *adaptingProtector) cycle(t *testing.T, rate float64) {
+	t.Helper()
+	tenantMetrics := map[string]*collector.TenantMetrics{
+		"tenant-a": {
+			Tenant: "tenant-a",
+			Metrics: map[string][]collector.MetricData{
+				"cortex_distributor_received_samples_total": {{Value: rate, Timestamp: ap.now}},
+			},
+		},
+	}
+	if _, err := ap.ProcessMetrics(context.Background(), tenantMetrics); err != nil {
+		t.Fatalf("ProcessMetrics: %v", err)
+	}
+	ap.now = ap.now.Add(10 * time.Minute)
+}
+
+func (ap *adaptingProtector) ingestionThreshold() float64 {
+	ap.autoConfig.mu.RLock()
+	defer ap.autoConfig.mu.RUnlock()
+	return ap.autoConfig.tenantThresholds["tenant-a"].IngestionThreshold
+}
+
+func ingestionRateLimit(rate float64) map[string]*analyzer.TenantLimits {
+	return map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": rate}},
+	}
+}
+
+// assertCappedChange fails when a cycle moved the threshold by more than maxChangePercent
+func assertCappedChange(t *testing.T, cycle int, previous, current, maxChangePercent float64) {
+	t.Helper()
+	if change := math.Abs(current - previous); change > previous*maxChangePercent/100+1e-6 {
+		t.Errorf("cycle %d moved the threshold from %.1f to %.1f, more than %v%%", cycle, previous, current, maxChangePercent)
+	}
+}
+
+func TestAdaptationConvergesToObservedLoad(t *testing.T) {
+	ap := newAdaptingProtector(0.5, 20)
+	// 100000 samples/s allowed at 150% plus the 25% margin
+	ap.UpdateCurrentLimits(ingestionRateLimit(100000))
+	if got := ap.ingestionThreshold(); got != 187500 {
+		t.Fatalf("threshold derived from the limits = %v, want 187500", got)
+	}
+
+	// A steady 10000 samples/s converges to its 95th percentile plus the 25% margin
+	const target = 12500
+	previous := ap.ingestionThreshold()
+	ap.cycle(t, 10000)
+	for cycle := 1; cycle <= 30; cycle++ {
+		ap.cycle(t, 10000)
+		current := ap.ingestionThreshold()
+		assertCappedChange(t, cycle, previous, current, 20)
+		if current > previous {
+			t.Errorf("cycle %d raised the threshold from %.1f to %.1f above the observed load", cycle, previous, current)
+		}
+		if current < 10000*1.1 {
+			t.Errorf("cycle %d lowered the threshold to %.1f, below the 10%% minimum margin", cycle, current)
+		}
+		previous = current
+	}
+	if math.Abs(previous-target) > target*0.01 {
+		t.Errorf("threshold after 30 cycles = %.1f, want converged to %v", previous, target)
+	}
+}
+
+func TestAdaptationChangeCappedPerCycle(t *testing.T) {
+	ap := newAdaptingProtector(1, 5)
+	// 8000 samples/s allowed at 150% plus the 25% margin
+	ap.UpdateCurrentLimits(ingestionRateLimit(8000))
+
+	// 13000 samples/s moves the 15000 threshold toward 16250, by at most 5% a cycle
+	ap.cycle(t, 13000)
+	for cycle, want := range []float64{15750, 16250, 16250} {
+		ap.cycle(t, 13000)
+		if got := ap.ingestionThreshold(); math.Abs(got-want) > 1e-6 {
+			t.Errorf("cycle %d threshold = %.1f, want %.1f", cycle+1, got, want)
+		}
+	}
+}
+
+func TestAdaptationRunsOncePerInterval(t *testing.T) {
+	ap := newAdaptingProtector(0.5, 20)
+	ap.UpdateCurrentLimits(ingestionRateLimit(100000))
+	ap.cycle(t, 10000)
+	ap.cycle(t, 10000)
+	adapted := ap.ingestionThreshold()
+
+	// Reconciles within the interval leave the thresholds alone
+	ap.now = ap.now.Add(-9 * time.Minute)
+	ap.cycle(t, 10000)
+	if got := ap.ingestionThreshold(); got != adapted {
+		t.Errorf("threshold = %v within the adaptation interval, want %v", got, adapted)
+	}
+}
+
+func TestAdaptedThresholdsKeptOnLimitUpdates(t *testing.T) {
+	ap := newAdaptingProtector(0.5, 20)
+	ap.UpdateCurrentLimits(ingestionRateLimit(100000))
+	ap.cycle(t, 10000)
+	ap.cycle(t, 10000)
+	adapted := ap.ingestionThreshold()
+
+	ap.UpdateCurrentLimits(ingestionRateLimit(120000))
+	if got := ap.ingestionThreshold(); got != adapted {
+		t.Errorf("threshold after new limits = %v, want the adapted %v kept", got, adapted)
+	}
+
+	// A reload derives the thresholds from the limits again
+	ap.ReloadConfig()
+	if got := ap.ingestionThreshold(); got != 120000*1.5*1.25 {
+		t.Errorf("threshold after reload = %v, want %v derived from the limits", got, 120000*1.5*1.25)
+	}
+}
+
+func TestAdaptThreshold(t *testing.T) {
+	tests := []struct {
+		name                             string
+		current, observed, margin, floor float64
+		learningRate, maxChange          float64
+		want                             float64
+	}{
+		{"moves by the learning rate", 20000, 10000, 20, 10, 0.5, 100, 16000},
+		{"capped per cycle", 20000, 10000, 20, 10, 0.5, 10, 18000},
+		{"capped increase", 10000, 9000, 20, 10, 1, 5, 10500},
+		{"minimum margin wins over the cap", 10000, 50000, 20, 10, 0.5, 10, 55000},
+		{"uncapped without maxChangePercent", 20000, 10000, 20, 10, 1, 0, 12000},
+		{"never below the minimum margin", 20000, 10000, 5, 10, 1, 0, 11000},
+		{"unset threshold left unset", 0, 10000, 20, 10, 0.5, 20, 0},
+		{"no observed load", 20000, 0, 20, 10, 0.5, 20, 20000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adaptThreshold(tt.current, tt.observed, tt.margin, tt.floor, tt.learningRate, tt.maxChange)
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("adaptThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// maxBaselineSamples bounds the observations kept per tenant for its baseline.
+	// Observations closer together than the analysis window over this are skipped.
+	maxBaselineSamples = 1000
+
+	// defaultBaselineWindow is used when neither autoConfig.baselineWindow nor
+	// trendAnalysis.analysisWindow is set
+	defaultBaselineWindow = 24 * time.Hour
+
+	// defaultBaselineMultiplier is used when blastProtection.baselineMultiplier is not set
+	defaultBaselineMultiplier = 5.0
+
+	// defaultBaselinePercentile is used when trendAnalysis.percentile is not set
+	defaultBaselinePercentile = 95.0
+)
+
+// baselineSample is one observation of the rates of a tenant
+type baselineSample struct {
+	at        time.Time
+	ingestion float64
+	query     float64
+	series    float64
+	errors    float64
+}
+
+// observeBaseline records the current rates of a tenant and recomputes its baseline
+// as the trendAnalysis.percentile of the observations in the baseline window. Until
+// the observations span autoConfig.minObservationPeriod the previous baseline, if
+// any, is kept. The caller must hold bd.mu.
+func (bd *BlastDetector) observeBaseline(blastMetrics *BlastMetrics, now time.Time) {
+	window := bd.baselineWindow()
+
+	samples := blastMetrics.samples
+	if n := len(samples); n > 0 && now.Sub(samples[n-1].at) < window/maxBaselineSamples {
+		return
+	}
+
+	cutoff := now.Add(-window)
+	kept := 0
+	for kept < len(samples) && samples[kept].at.Before(cutoff) {
+		kept++
+	}
+	samples = append(samples[kept:], baselineSample{
+		at:        now,
+		ingestion: blastMetrics.IngestionRate,
+		query:     blastMetrics.QueryRate,
+		series:    blastMetrics.SeriesRate,
+		errors:    blastMetrics.ErrorRate,
+	})
+	blastMetrics.samples = samples
+
+	if now.Sub(samples[0].at) < bd.config().CircuitBreaker.AutoConfig.MinObservationPeriod {
+		return
+	}
+
+	percentile := bd.config().TrendAnalysis.Percentile
+	if percentile <= 0 || percentile > 100 {
+		percentile = defaultBaselinePercentile
+	}
+	rates := ratesOf(samples, percentile)
+	blastMetrics.BaselineRates = BaselineRates{
+		IngestionRate:  rates.IngestionRate,
+		QueryRate:      rates.QueryRate,
+		SeriesRate:     rates.SeriesRate,
+		ErrorRate:      rates.ErrorRate,
+		LastCalculated: now,
+		Samples:        len(samples),
+		Seasonal:       bd.seasonalBaselines(samples, percentile),
+	}
+}
+
+// baselineWindow returns the window the baselines are computed over:
+// autoConfig.baselineWindow, or trendAnalysis.analysisWindow when it is not set
+func (bd *BlastDetector) baselineWindow() time.Duration {
+	if window := bd.config().CircuitBreaker.AutoConfig.BaselineWindow; window > 0 {
+		return window
+	}
+	if window := bd.config().TrendAnalysis.AnalysisWindow; window > 0 {
+		return window
+	}
+	return defaultBaselineWindow
+}
+
+// baselineMultiplier returns the multiple of its baseline above which a rate is a blast
+func (bd *BlastDetector) baselineMultiplier() float64 {
+	if multiplier := bd.config().CircuitBreaker.BlastProtection.BaselineMultiplier; multiplier > 0 {
+		return multiplier
+	}
+	return defaultBaselineMultiplier
+}
+
+// observing reports whether autoConfig.minObservationPeriod has not yet passed since
+// the detector started observing. No blast is detected until then, as after a cold
+// start there are neither baselines nor auto thresholds to compare against. The caller
+// must hold bd.mu.
+func (bd *BlastDetector) observing(now time.Time) bool {
+	autoConfig := bd.config().CircuitBreaker.AutoConfig
+	if !autoConfig.Enabled {
+		return false
+	}
+
+	bd.autoConfig.mu.RLock()
+	observationEnd := bd.autoConfig.observationStartTime.Add(autoConfig.MinObservationPeriod)
+	bd.autoConfig.mu.RUnlock()
+	return now.Before(observationEnd)
+}
+
+// Baselines returns the current baseline of every tenant that has one
+func (bp *BlastProtector) Baselines() map[string]BaselineRates {
+	bd := bp.blastDetector
+	bd.mu.RLock()
+	defer bd.mu.RUnlock()
+
+	baselines := make(map[string]BaselineRates)
+	for tenant, blastMetrics := range bd.metrics {
+		if !blastMetrics.BaselineRates.LastCalculated.IsZero() {
+			baselines[tenant] = blastMetrics.BaselineRates
+		}
+	}
+	return baselines
+}
+
+// RestoreBaselines reloads checkpointed baselines for tenants without one, skipping
+// baselines calculated more than maxAge before now. It returns the number restored
+// and the number skipped as stale.
+func (bp *BlastProtector) RestoreBaselines(baselines map[string]BaselineRates, maxAge time.Duration, now time.Time) (int, int) {
+	bd := bp.blastDetector
+	bd.mu.Lock()
+	defer bd.mu.Unlock()
+
+	restored, stale := 0, 0
+	for tenant, baseline := range baselines {
+		if baseline.LastCalculated.IsZero() || now.Sub(baseline.LastCalculated) > maxAge {
+			stale++
+			continue
+		}
+		if existing, exists := bd.metrics[tenant]; exists && !existing.BaselineRates.LastCalculated.IsZero() {
+			continue
+		}
+
+		baseline.Restored = true
+		bd.getOrCreateBlastMetrics(tenant).BaselineRates = baseline
+		restored++
+	}
+	return restored, stale
+}
+
+// ratesOf returns the percentile of each rate over the samples
+func ratesOf(samples []baselineSample, percentile float64) SeasonalRates {
+	values := func(rate func(baselineSample) float64) []float64 {
+		result := make([]float64, len(samples))
+		for i, sample := range samples {
+			result[i] = rate(sample)
+		}
+		return result
+	}
+
+	return SeasonalRates{
+		IngestionRate: percentileOf(values(func(s baselineSample) float64 { return s.ingestion }), percentile),
+		QueryRate:     percentileOf(values(func(s baselineSample) float64 { return s.query }), percentile),
+		SeriesRate:    percentileOf(values(func(s baselineSample) float64 { return s.series }), percentile),
+		ErrorRate:     percentileOf(values(func(s baselineSample) float64 { return s.errors }), percentile),
+		Samples:       len(samples),
+	}
+}
+
+// percentileOf interpolates the percentile of values linearly between closest ranks
+func percentileOf(values []float64, percentile float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	index := (percentile / 100.0) * float64(len(sorted)-1)
+	lower := int(index)
+	upper := lower + 1
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+
+	weight := index - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
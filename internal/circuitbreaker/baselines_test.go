@@ -0,0 +1,131 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+var baselineEpoch = time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+// baselineProtector is a circuit breaker on a simulated clock observing tenant-a for
+// an hour before detecting blasts, against a 6h baseline with a 3x multiplier and a
+// manual ingestion threshold of 500000 samples/s
+type baselineProtector struct {
+	*BlastProtector
+	now time.Time
+}
+
+func newBaselineProtector() *baselineProtector {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.CircuitBreaker.AutoConfig.Enabled = true
+	cfg.CircuitBreaker.AutoConfig.MinObservationPeriod = time.Hour
+	cfg.CircuitBreaker.AutoConfig.BaselineWindow = 6 * time.Hour
+	cfg.CircuitBreaker.BlastProtection.BaselineMultiplier = 3
+	cfg.CircuitBreaker.BlastProtection.ManualThresholds.IngestionSpikeThreshold = 500000
+	cfg.Emergency.PanicMode.Actions = nil
+
+	bp := &baselineProtector{BlastProtector: NewBlastProtector(config.NewLive(cfg), logr.Discard()), now: baselineEpoch}
+	bp.SetClock(func() time.Time { return bp.now })
+	return bp
+}
+
+// observe records tenant-a ingesting rate samples/s at the current time and reports
+// whether it is blasting
+func (bp *baselineProtector) observe(rate float64) bool {
+	tenantMetrics := map[string]*collector.TenantMetrics{
+		"tenant-a": {
+			Tenant: "tenant-a",
+			Metrics: map[string][]collector.MetricData{
+				"cortex_distributor_received_samples_total": {{Value: rate, Timestamp: bp.now}},
+			},
+		},
+	}
+	bp.blastDetector.updateMetrics(tenantMetrics)
+	return bp.blastDetector.blastTenants(tenantMetrics)["tenant-a"]
+}
+
+// observeSteady observes tenant-a ingesting 10000 samples/s every 5 minutes until end
+func (bp *baselineProtector) observeSteady(t *testing.T, end time.Time) {
+	t.Helper()
+	for ; bp.now.Before(end); bp.now = bp.now.Add(5 * time.Minute) {
+		if bp.observe(10000) {
+			t.Fatalf("steady load detected as a blast at %v", bp.now.Sub(baselineEpoch))
+		}
+	}
+}
+
+func TestBlastDetectionHeldDuringObservation(t *testing.T) {
+	bp := newBaselineProtector()
+
+	// A cold start meets neither a baseline nor the manual threshold check
+	if bp.observe(1000000) {
+		t.Errorf("rate above the manual threshold detected at cold start")
+	}
+	bp.now = bp.now.Add(5 * time.Minute)
+	bp.observeSteady(t, baselineEpoch.Add(30*time.Minute))
+	if bp.observe(1000000) {
+		t.Errorf("rate above the manual threshold detected 30m into the observation period")
+	}
+	if baselines := bp.Baselines(); len(baselines) != 0 {
+		t.Errorf("baselines %v before the observation period passed", baselines)
+	}
+
+	bp.now = baselineEpoch.Add(time.Hour)
+	if !bp.observe(1000000) {
+		t.Errorf("rate above the manual threshold not detected once the observation period passed")
+	}
+}
+
+func TestBlastDetectedPastBaselineMultiplier(t *testing.T) {
+	bp := newBaselineProtector()
+	// The startup spike ages out of the 6h baseline window
+	bp.observe(100000)
+	bp.now = bp.now.Add(5 * time.Minute)
+	bp.observeSteady(t, baselineEpoch.Add(7*time.Hour))
+
+	baseline, exists := bp.Baselines()["tenant-a"]
+	if !exists {
+		t.Fatalf("no baseline after 7h of observations")
+	}
+	if baseline.IngestionRate != 10000 {
+		t.Errorf("baseline ingestion rate = %v, want the steady 10000", baseline.IngestionRate)
+	}
+	if baseline.Samples != 73 {
+		t.Errorf("baseline of %d samples, want the 73 of the last 6h", baseline.Samples)
+	}
+
+	tests := []struct {
+		rate float64
+		want bool
+	}{
+		{25000, false},
+		{30000, false},
+		{30001, true},
+		{100000, true},
+	}
+	for _, tt := range tests {
+		if got := bp.observe(tt.rate); got != tt.want {
+			t.Errorf("blast at %v samples/s = %v, want %v against the %v baseline", tt.rate, got, tt.want, baseline.IngestionRate)
+		}
+		bp.now = bp.now.Add(5 * time.Minute)
+	}
+}
+
+func TestBlastDetectionWithoutAutoConfig(t *testing.T) {
+	bp := newBaselineProtector()
+	cfg := *bp.config()
+	cfg.CircuitBreaker.AutoConfig.Enabled = false
+	bp.live.Store(&cfg)
+
+	// Without auto-configuration there is no observation period to wait for
+	if !bp.observe(1000000) {
+		t.Errorf("rate above the manual threshold not detected without auto-configuration")
+	}
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
 )
@@ -37,7 +39,7 @@ func (s CircuitBreakerState) String() string {
 
 // BlastProtector implements circuit breaker pattern with blast protection
 type BlastProtector struct {
-	config         *config.Config
+	live           *config.Live
 	log            logr.Logger
 	mu             sync.RWMutex
 	state          CircuitBreakerState
@@ -45,9 +47,18 @@ type BlastProtector struct {
 	requests       int
 	lastStateChange time.Time
 	halfOpenRequests int
+
+	// Half-open probing: consecutive successful probes and where the next probes start
+	consecutiveSuccesses int
+	probeOffset          int
+	lastTransitionReason string
+	// forcedOpen holds the circuit open past the sleep window until it is force closed
+	forcedOpen bool
 	
 	// Rate limiting
 	rateLimiters map[string]*TenantRateLimiter
+	// now is the clock the token buckets are refilled by
+	now func() time.Time
 	
 	// Blast detection
 	blastDetector  *BlastDetector
@@ -58,6 +69,28 @@ type BlastProtector struct {
 	autoConfig     *AutoConfig
 	lastAdaptation time.Time
 	initialized    bool
+	
+	// Alerting
+	alertManager   *alerting.Manager
+	// modeIncidents are the alert types of the emergency and panic mode incidents opened
+	// since emergency mode was entered, resolved when it is exited
+	modeIncidents []alerting.AlertType
+
+	// auditLogger records state transitions
+	auditLogger auditlog.AuditLogger
+
+	// emergencyMonitor evaluates the emergency triggers, nil when none is running
+	emergencyMonitor *EmergencyMonitor
+
+	// Emergency actions: the registry, the actions executed in the current emergency
+	// in execution order, the queue run once bp.mu is released and the last outcomes.
+	// actionsMu serializes the action runs.
+	actions         map[string]EmergencyAction
+	executedActions map[string]bool
+	actionOrder     []string
+	pendingActions  []pendingAction
+	actionResults   []ActionResult
+	actionsMu       sync.Mutex
 }
 
 // AutoConfig holds dynamic configuration based on real-time metrics
@@ -78,25 +111,33 @@ type TenantThresholds struct {
 	LastCalculated     time.Time
 	BasedOnLimits      *analyzer.TenantLimits
 	SafetyMargin       float64
-}
 
-// TenantRateLimiter implements per-tenant rate limiting
-type TenantRateLimiter struct {
-	tenant        string
-	tokens        float64
-	lastUpdate    time.Time
-	requestsPerSec float64
-	burstCapacity  int
-	mu            sync.Mutex
+	// LastAdapted is when realtime adaptation last moved the thresholds toward the
+	// observed load, zero while they are still derived from the limits alone
+	LastAdapted time.Time
+	// AdaptationCycles counts the adaptations since the thresholds were derived
+	AdaptationCycles int
 }
 
 // BlastDetector monitors for sudden traffic spikes
 type BlastDetector struct {
-	config          *config.Config
+	live            *config.Live
 	log             logr.Logger
 	mu              sync.RWMutex
 	metrics         map[string]*BlastMetrics
 	alertSent       map[string]time.Time
+
+	// autoConfig provides the auto-calculated thresholds
+	autoConfig *AutoConfig
+	// warmingUp is the number of tenants last reported as warming up
+	warmingUp int
+	// now is the clock observations and blast checks are timed with
+	now func() time.Time
+}
+
+// config returns the configuration in effect
+func (bd *BlastDetector) config() *config.Config {
+	return bd.live.Load()
 }
 
 // BlastMetrics tracks metrics for blast detection
@@ -105,17 +146,28 @@ type BlastMetrics struct {
 	QueryRate      float64
 	SeriesRate     float64
 	ErrorRate      float64
+	FirstSeen      time.Time
 	LastUpdate     time.Time
 	BaselineRates  BaselineRates
+
+	// samples are the observations in the analysis window the baseline is computed from
+	samples []baselineSample
 }
 
 // BaselineRates stores normal operating rates for comparison
 type BaselineRates struct {
-	IngestionRate float64
-	QueryRate     float64
-	SeriesRate    float64
-	ErrorRate     float64
-	LastCalculated time.Time
+	IngestionRate  float64   `json:"ingestion_rate"`
+	QueryRate      float64   `json:"query_rate"`
+	SeriesRate     float64   `json:"series_rate"`
+	ErrorRate      float64   `json:"error_rate"`
+	LastCalculated time.Time `json:"last_calculated"`
+	// Samples is the number of observations the baseline was computed from
+	Samples int `json:"samples"`
+	// Restored is set on a baseline reloaded from a checkpoint and not recomputed since
+	Restored bool `json:"restored,omitempty"`
+	// Seasonal are the baselines per time bucket when seasonal patterns are enabled,
+	// keyed by hour of day ("14") or day of week and hour ("mon-14")
+	Seasonal map[string]SeasonalRates `json:"seasonal,omitempty"`
 }
 
 // ProtectionAction represents actions to take during protection
@@ -129,36 +181,48 @@ type ProtectionAction struct {
 }
 
 // NewBlastProtector creates a new circuit breaker with blast protection
-func NewBlastProtector(cfg *config.Config, log logr.Logger) *BlastProtector {
+func NewBlastProtector(live *config.Live, log logr.Logger) *BlastProtector {
 	bp := &BlastProtector{
-		config:          cfg,
+		live:            live,
 		log:             log,
 		state:           StateClosed,
 		rateLimiters:    make(map[string]*TenantRateLimiter),
+		now:             time.Now,
 		lastStateChange: time.Now(),
 		blastDetector: &BlastDetector{
-			config:    cfg,
+			live:      live,
 			log:       log,
 			metrics:   make(map[string]*BlastMetrics),
 			alertSent: make(map[string]time.Time),
+			now:       time.Now,
 		},
 		autoConfig: &AutoConfig{
 			tenantThresholds:     make(map[string]*TenantThresholds),
 			currentLimits:        make(map[string]*analyzer.TenantLimits),
 			observationStartTime: time.Now(),
 		},
-		initialized: false,
+		initialized:     false,
+		executedActions: make(map[string]bool),
 	}
+	bp.actions = bp.defaultEmergencyActions()
+	bp.blastDetector.autoConfig = bp.autoConfig
+	bp.setStateMetrics()
 	
 	return bp
 }
 
+// config returns the configuration in effect
+func (bp *BlastProtector) config() *config.Config {
+	return bp.live.Load()
+}
+
 // ProcessMetrics processes incoming metrics and applies protection
 func (bp *BlastProtector) ProcessMetrics(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string]*collector.TenantMetrics, error) {
-	if !bp.config.CircuitBreaker.Enabled || !bp.config.CircuitBreaker.RuntimeEnabled {
+	if !bp.config().CircuitBreaker.Enabled || !bp.config().CircuitBreaker.RuntimeEnabled {
 		return tenantMetrics, nil
 	}
 
+	defer bp.runPendingActions()
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
 
@@ -168,7 +232,7 @@ func (bp *BlastProtector) ProcessMetrics(ctx context.Context, tenantMetrics map[
 	}
 
 	// Update auto-configuration if enabled
-	if bp.config.CircuitBreaker.AutoConfig.Enabled {
+	if bp.config().CircuitBreaker.AutoConfig.Enabled {
 		bp.updateAutoConfiguration(tenantMetrics)
 	}
 
@@ -176,23 +240,23 @@ func (bp *BlastProtector) ProcessMetrics(ctx context.Context, tenantMetrics map[
 	bp.blastDetector.updateMetrics(tenantMetrics)
 
 	// Check for blast conditions using auto-calculated or manual thresholds
-	blastDetected := bp.blastDetector.detectBlast(tenantMetrics)
-	if blastDetected {
-		bp.handleBlastDetection(ctx)
+	blastTenants := bp.blastDetector.blastTenants(tenantMetrics)
+	if len(blastTenants) > 0 {
+		bp.handleBlastDetection(ctx, sortedTenants(blastTenants))
 	}
 
 	// Apply rate limiting
 	filteredMetrics := bp.applyRateLimiting(tenantMetrics)
 
-	// Update circuit breaker state
-	bp.updateCircuitBreakerState()
+	// Run the tenants through the circuit breaker, probing them while half-open
+	filteredMetrics = bp.evaluateTenants(filteredMetrics, blastTenants)
 
 	return filteredMetrics, nil
 }
 
 // ApplyProtection applies protection measures to tenant limits
 func (bp *BlastProtector) ApplyProtection(ctx context.Context, limits map[string]*analyzer.TenantLimits) (map[string]*analyzer.TenantLimits, error) {
-	if !bp.config.CircuitBreaker.Enabled {
+	if !bp.config().CircuitBreaker.Enabled {
 		return limits, nil
 	}
 
@@ -211,28 +275,36 @@ func (bp *BlastProtector) ApplyProtection(ctx context.Context, limits map[string
 
 // EnterEmergencyMode puts the system into emergency protection mode
 func (bp *BlastProtector) EnterEmergencyMode(reason string) {
+	defer bp.runPendingActions()
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
+	bp.enterEmergencyMode(reason, nil)
+}
 
+// enterEmergencyMode opens the circuit until emergency mode is exited, recording it as
+// one audit entry and one alert naming the tenants that caused it. The caller must hold
+// bp.mu and run the queued emergency actions after releasing it.
+func (bp *BlastProtector) enterEmergencyMode(reason string, tenants []string) {
 	if bp.emergencyMode {
 		return
 	}
 
+	before := bp.protectionState()
 	bp.emergencyMode = true
-	bp.state = StateOpen
-	bp.lastStateChange = time.Now()
+	counters, _ := bp.setState(StateOpen, "emergency mode: "+reason)
+	bp.auditProtectionEvent("circuit_breaker_emergency_mode", before, reason, orNoTenants(tenants), counters)
 
 	bp.log.Error(fmt.Errorf("emergency mode activated: %s", reason), "EMERGENCY MODE ACTIVATED", "reason", reason)
 
 	// Send emergency alerts
-	bp.sendEmergencyAlert("emergency_mode_activated", reason, "critical")
+	bp.sendEmergencyAlert("emergency_mode_activated", reason, "critical", orNoTenants(tenants))
 
-	// Execute emergency actions
-	bp.executeEmergencyActions()
+	bp.queueEmergencyActions(reason, false)
 }
 
 // EnterPanicMode puts the system into panic mode for extreme situations
 func (bp *BlastProtector) EnterPanicMode(reason string) {
+	defer bp.runPendingActions()
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
 
@@ -240,22 +312,25 @@ func (bp *BlastProtector) EnterPanicMode(reason string) {
 		return
 	}
 
+	before := bp.protectionState()
 	bp.panicMode = true
 	bp.emergencyMode = true
-	bp.state = StateOpen
-	bp.lastStateChange = time.Now()
+	counters, _ := bp.setState(StateOpen, "panic mode: "+reason)
+	bp.auditProtectionEvent("circuit_breaker_panic_mode", before, reason, []string{}, counters)
 
 	bp.log.Error(fmt.Errorf("panic mode activated: %s", reason), "PANIC MODE ACTIVATED", "reason", reason)
 
 	// Send panic alerts
-	bp.sendEmergencyAlert("panic_mode_activated", reason, "critical")
+	bp.sendEmergencyAlert("panic_mode_activated", reason, "critical", []string{})
 
-	// Execute panic actions
-	bp.executePanicActions()
+	// Actions already executed for emergency mode are not repeated
+	bp.queueEmergencyActions(reason, true)
 }
 
-// ExitEmergencyMode exits emergency mode with recovery procedures
+// ExitEmergencyMode exits emergency mode with recovery procedures, undoing the
+// executed emergency actions
 func (bp *BlastProtector) ExitEmergencyMode() error {
+	defer bp.runPendingActions()
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
 
@@ -268,13 +343,26 @@ func (bp *BlastProtector) ExitEmergencyMode() error {
 		return fmt.Errorf("recovery conditions not met")
 	}
 
+	before := bp.protectionState()
 	bp.emergencyMode = false
 	bp.panicMode = false
-	bp.state = StateHalfOpen
-	bp.lastStateChange = time.Now()
-	bp.halfOpenRequests = 0
+	// Recovery is probed like after the sleep window, so the circuit only closes once
+	// tenants evaluate cleanly again
+	counters, _ := bp.setState(StateHalfOpen, "emergency mode exited")
+	bp.auditProtectionEvent("circuit_breaker_emergency_mode_exited", before, "emergency mode exited", []string{}, counters)
 
 	bp.log.Info("exiting emergency mode, entering recovery phase")
+	bp.queueActionRecovery("emergency mode exited")
+
+	// Resolve the incidents opened when emergency / panic mode was entered
+	for _, alertType := range bp.modeIncidents {
+		message := "Emergency mode exited, circuit breaker entering recovery phase"
+		if alertType == alerting.AlertTypePanicMode {
+			message = "Panic mode exited, circuit breaker entering recovery phase"
+		}
+		bp.resolveEmergencyAlert(alertType, message)
+	}
+	bp.modeIncidents = nil
 
 	return nil
 }
@@ -284,47 +372,58 @@ func (bp *BlastProtector) GetProtectionStatus() map[string]interface{} {
 	bp.mu.RLock()
 	defer bp.mu.RUnlock()
 
-	return map[string]interface{}{
-		"circuit_breaker_state": bp.state.String(),
-		"emergency_mode":        bp.emergencyMode,
-		"panic_mode":            bp.panicMode,
-		"failures":              bp.failures,
-		"requests":              bp.requests,
-		"last_state_change":     bp.lastStateChange,
-		"half_open_requests":    bp.halfOpenRequests,
-		"active_rate_limiters":  len(bp.rateLimiters),
-	}
+	status := map[string]interface{}{
+		"circuit_breaker_state":       bp.state.String(),
+		"emergency_mode":              bp.emergencyMode,
+		"panic_mode":                  bp.panicMode,
+		"failures":                    bp.failures,
+		"requests":                    bp.requests,
+		"last_state_change":           bp.lastStateChange,
+		"half_open_requests":          bp.halfOpenRequests,
+		"active_rate_limiters":        len(bp.rateLimiters),
+		"rate_limiters":               bp.rateLimiterStatus(),
+		"consecutive_successes":       bp.consecutiveSuccesses,
+		"max_requests_in_half_open":   bp.config().CircuitBreaker.MaxRequestsInHalfOpen,
+		"half_open_success_threshold": bp.config().CircuitBreaker.HalfOpenSuccessThreshold,
+		"last_transition_reason":      bp.lastTransitionReason,
+		"forced_open":                 bp.forcedOpen,
+		"emergency_actions":           append([]ActionResult(nil), bp.actionResults...),
+	}
+	if bp.emergencyMonitor != nil {
+		status["emergency_triggers"] = bp.emergencyMonitor.Status()
+	}
+	return status
 }
 
 // Private methods
 
-func (bp *BlastProtector) handleBlastDetection(ctx context.Context) {
-	bp.log.Info("blast detected, applying protection measures")
+func (bp *BlastProtector) handleBlastDetection(ctx context.Context, tenants []string) {
+	bp.log.Info("blast detected, applying protection measures", "tenants", tenants)
 
-	if bp.config.CircuitBreaker.BlastProtection.AutoEmergencyShutdown {
-		bp.EnterEmergencyMode("blast_detected")
+	if bp.config().CircuitBreaker.BlastProtection.AutoEmergencyShutdown {
+		bp.enterEmergencyMode("blast_detected", tenants)
 	} else {
-		// Apply gradual protection
-		bp.failures += 10 // Increase failure count to trigger circuit breaker
+		// Gradual protection: blasting tenants count as circuit breaker failures
 		bp.log.Info("applied gradual protection due to blast detection")
 	}
 }
 
 func (bp *BlastProtector) applyRateLimiting(tenantMetrics map[string]*collector.TenantMetrics) map[string]*collector.TenantMetrics {
-	if !bp.config.CircuitBreaker.RateLimit.Enabled {
+	if !bp.config().CircuitBreaker.RateLimit.Enabled {
 		return tenantMetrics
 	}
 
 	filteredMetrics := make(map[string]*collector.TenantMetrics)
 
+	now := bp.now()
 	for tenant, metrics := range tenantMetrics {
-		rateLimiter := bp.getRateLimiter(tenant)
-		
-		if rateLimiter.allowRequest() {
+		rateLimiter := bp.getRateLimiter(tenant, now)
+
+		if rateLimiter.allowRequest(now) {
 			filteredMetrics[tenant] = metrics
 		} else {
 			// Rate limited - reduce metrics or block
-			bp.log.V(1).Info("rate limited tenant", "tenant", tenant)
+			bp.log.V(1).Info("rate limited tenant", "tenant", tenant, "override", rateLimiter.override)
 			// Could implement partial metrics reduction here
 		}
 	}
@@ -332,89 +431,13 @@ func (bp *BlastProtector) applyRateLimiting(tenantMetrics map[string]*collector.
 	return filteredMetrics
 }
 
-func (bp *BlastProtector) getRateLimiter(tenant string) *TenantRateLimiter {
-	if limiter, exists := bp.rateLimiters[tenant]; exists {
-		return limiter
-	}
-
-	// Create new rate limiter for tenant
-	limiter := &TenantRateLimiter{
-		tenant:         tenant,
-		tokens:         float64(bp.config.CircuitBreaker.RateLimit.BurstCapacity),
-		lastUpdate:     time.Now(),
-		requestsPerSec: bp.config.CircuitBreaker.RateLimit.RequestsPerSecond,
-		burstCapacity:  bp.config.CircuitBreaker.RateLimit.BurstCapacity,
-	}
-
-	bp.rateLimiters[tenant] = limiter
-	return limiter
-}
-
-func (trl *TenantRateLimiter) allowRequest() bool {
-	trl.mu.Lock()
-	defer trl.mu.Unlock()
-
-	now := time.Now()
-	elapsed := now.Sub(trl.lastUpdate).Seconds()
-
-	// Add tokens based on rate
-	trl.tokens += elapsed * trl.requestsPerSec
-	if trl.tokens > float64(trl.burstCapacity) {
-		trl.tokens = float64(trl.burstCapacity)
-	}
-
-	trl.lastUpdate = now
-
-	if trl.tokens >= 1.0 {
-		trl.tokens -= 1.0
-		return true
-	}
-
-	return false
-}
-
-func (bp *BlastProtector) updateCircuitBreakerState() {
-	now := time.Now()
-
-	switch bp.state {
-	case StateClosed:
-		if bp.shouldOpenCircuit() {
-			bp.state = StateOpen
-			bp.lastStateChange = now
-			bp.log.Info("circuit breaker opened", "failures", bp.failures, "requests", bp.requests)
-		}
-
-	case StateOpen:
-		if now.Sub(bp.lastStateChange) >= bp.config.CircuitBreaker.SleepWindow {
-			bp.state = StateHalfOpen
-			bp.halfOpenRequests = 0
-			bp.lastStateChange = now
-			bp.log.Info("circuit breaker entered half-open state")
-		}
-
-	case StateHalfOpen:
-		if bp.halfOpenRequests >= bp.config.CircuitBreaker.MaxRequestsInHalfOpen {
-			if bp.failures == 0 {
-				bp.state = StateClosed
-				bp.failures = 0
-				bp.requests = 0
-				bp.log.Info("circuit breaker closed - recovery successful")
-			} else {
-				bp.state = StateOpen
-				bp.log.Info("circuit breaker reopened - recovery failed")
-			}
-			bp.lastStateChange = now
-		}
-	}
-}
-
 func (bp *BlastProtector) shouldOpenCircuit() bool {
-	if bp.requests < bp.config.CircuitBreaker.RequestVolumeThreshold {
+	if bp.requests < bp.config().CircuitBreaker.RequestVolumeThreshold {
 		return false
 	}
 
 	failureRate := float64(bp.failures) / float64(bp.requests) * 100
-	return failureRate >= bp.config.CircuitBreaker.FailureThreshold
+	return failureRate >= bp.config().CircuitBreaker.FailureThreshold
 }
 
 func (bp *BlastProtector) adjustLimitsBasedOnState(tenant string, limit *analyzer.TenantLimits) *analyzer.TenantLimits {
@@ -424,6 +447,7 @@ func (bp *BlastProtector) adjustLimitsBasedOnState(tenant string, limit *analyze
 		LastUpdated: time.Now(),
 		Reason:      limit.Reason,
 		Source:      "circuit-breaker",
+		Tier:        limit.Tier,
 	}
 
 	// Copy all limits and apply reduction factors based on state
@@ -479,47 +503,80 @@ func (bp *BlastProtector) adjustLimitsBasedOnState(tenant string, limit *analyze
 	return adjustedLimit
 }
 
-func (bp *BlastProtector) executeEmergencyActions() {
-	actions := bp.config.Emergency.PanicMode.Actions
-
-	for _, action := range actions {
-		switch action {
-		case "reduce_limits":
-			bp.log.Info("executing emergency action: reduce_limits")
-			// This is handled in adjustLimitsBasedOnState
-		case "throttle_ingestion":
-			bp.log.Info("executing emergency action: throttle_ingestion")
-			// Additional throttling logic could go here
-		case "alert":
-			bp.log.Info("executing emergency action: alert")
-			bp.sendEmergencyAlert("emergency_action_executed", action, "high")
-		}
-	}
-}
-
-func (bp *BlastProtector) executePanicActions() {
-	// Execute even more aggressive actions in panic mode
-	bp.log.Error(fmt.Errorf("executing panic mode actions"), "executing panic mode actions")
-	
-	// Could implement additional panic actions like:
-	// - Temporary service degradation
-	// - Emergency contact notification
-	// - Automated rollbacks
+// isRecoveryPossible reports whether the emergency triggers allow leaving emergency
+// mode. Without an emergency monitor there is nothing to check.
+func (bp *BlastProtector) isRecoveryPossible() bool {
+	return bp.emergencyMonitor == nil || bp.emergencyMonitor.Healthy()
 }
 
-func (bp *BlastProtector) isRecoveryPossible() bool {
-	// Check if system metrics are back to normal
-	// This would integrate with system monitoring
-	return true // Simplified for now
+// inEmergencyMode reports whether emergency or panic mode is active
+func (bp *BlastProtector) inEmergencyMode() bool {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	return bp.emergencyMode
 }
 
-func (bp *BlastProtector) sendEmergencyAlert(alertType, reason, severity string) {
-	// TODO: Integrate with alerting system
+func (bp *BlastProtector) sendEmergencyAlert(alertType, reason, severity string, tenants []string) {
 	bp.log.Error(fmt.Errorf("emergency alert: %s - %s", alertType, reason), "EMERGENCY ALERT", 
 		"type", alertType,
 		"reason", reason,
 		"severity", severity,
 		"timestamp", time.Now())
+
+	if bp.alertManager == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"event":                 alertType,
+		"reason":                reason,
+		"severity":              severity,
+		"circuit_breaker_state": bp.state.String(),
+		"affected_tenants":      tenants,
+	}
+
+	var alert *alerting.Alert
+	if alertType == "panic_mode_activated" {
+		alert = alerting.CreatePanicModeAlert(reason, details)
+	} else {
+		// Emergency activation and emergency actions share one incident
+		alert = alerting.CreateAlert(alerting.AlertTypeEmergency, priorityForSeverity(severity),
+			"EMERGENCY MODE ACTIVATED",
+			fmt.Sprintf("Emergency mode is active due to: %s", reason))
+		alert.Details = details
+	}
+
+	bp.modeIncidents = append(bp.modeIncidents, alert.Type)
+	bp.alertManager.SendAlert(alert)
+}
+
+func (bp *BlastProtector) resolveEmergencyAlert(alertType alerting.AlertType, message string) {
+	if bp.alertManager == nil {
+		return
+	}
+	bp.alertManager.SendAlert(alerting.CreateResolvedAlert(alertType, "", message))
+}
+
+// orNoTenants returns the tenants, or an empty list for none
+func orNoTenants(tenants []string) []string {
+	if tenants == nil {
+		return []string{}
+	}
+	return tenants
+}
+
+// priorityForSeverity maps protection severities to alert priorities
+func priorityForSeverity(severity string) alerting.Priority {
+	switch severity {
+	case "critical":
+		return alerting.PriorityP0
+	case "high":
+		return alerting.PriorityP1
+	case "medium":
+		return alerting.PriorityP2
+	default:
+		return alerting.PriorityP3
+	}
 }
 
 // BlastDetector methods
@@ -528,6 +585,7 @@ func (bd *BlastDetector) updateMetrics(tenantMetrics map[string]*collector.Tenan
 	bd.mu.Lock()
 	defer bd.mu.Unlock()
 
+	now := bd.now()
 	for tenant, metrics := range tenantMetrics {
 		blastMetrics := bd.getOrCreateBlastMetrics(tenant)
 		
@@ -535,100 +593,76 @@ func (bd *BlastDetector) updateMetrics(tenantMetrics map[string]*collector.Tenan
 		blastMetrics.IngestionRate = bd.calculateRate(metrics, "cortex_distributor_received_samples_total")
 		blastMetrics.QueryRate = bd.calculateRate(metrics, "cortex_querier_queries_total")
 		blastMetrics.SeriesRate = bd.calculateRate(metrics, "cortex_ingester_memory_series")
-		blastMetrics.LastUpdate = time.Now()
+		blastMetrics.ErrorRate = bd.calculateRate(metrics, "cortex_ingester_ingested_samples_failures_total")
+		blastMetrics.LastUpdate = now
 
-		// Update baseline if needed
-		if time.Since(blastMetrics.BaselineRates.LastCalculated) > 24*time.Hour {
-			bd.updateBaseline(blastMetrics)
-		}
+		bd.observeBaseline(blastMetrics, now)
 	}
+
+	bd.reportWarmUp(tenantMetrics)
 }
 
-func (bd *BlastDetector) detectBlast(tenantMetrics map[string]*collector.TenantMetrics) bool {
+// blastTenants returns the tenants of the reconcile that meet a blast condition
+func (bd *BlastDetector) blastTenants(tenantMetrics map[string]*collector.TenantMetrics) map[string]bool {
 	bd.mu.RLock()
 	defer bd.mu.RUnlock()
 
-	for tenant, blastMetrics := range bd.metrics {
-		if bd.isBlastCondition(tenant, blastMetrics) {
+	blasting := make(map[string]bool)
+	for tenant := range tenantMetrics {
+		blastMetrics, exists := bd.metrics[tenant]
+		if exists && bd.isBlastCondition(tenant, blastMetrics) {
 			bd.log.Info("blast condition detected", "tenant", tenant)
-			return true
+			blasting[tenant] = true
 		}
 	}
 
-	return false
+	return blasting
 }
 
+// isBlastCondition reports whether a tenant exceeds its thresholds or its baseline by
+// blastProtection.baselineMultiplier; never during the minimum observation period
 func (bd *BlastDetector) isBlastCondition(tenant string, metrics *BlastMetrics) bool {
-	config := bd.config.CircuitBreaker.BlastProtection
-
-	// Use auto-calculated thresholds if available and enabled
-	if config.UseAutoThresholds {
-		return bd.checkAutoThresholds(tenant, metrics)
-	}
-
-	// Fall back to manual thresholds
-	return bd.checkManualThresholds(tenant, metrics)
-}
-
-// checkAutoThresholds checks blast conditions using auto-calculated thresholds
-func (bd *BlastDetector) checkAutoThresholds(tenant string, metrics *BlastMetrics) bool {
-	// This method needs access to the BlastProtector's autoConfig
-	// For now, we'll implement a simplified version
-	// In a full implementation, we'd need a reference to the BlastProtector
-	
-	// Check if we have tenant-specific overrides
-	config := bd.config.CircuitBreaker.BlastProtection
-	if override, exists := config.TenantOverrides[tenant]; exists {
-		return bd.checkThresholds(metrics, override.IngestionSpikeThreshold, 
-			override.QuerySpikeThreshold, override.SeriesSpikeThreshold)
-	}
-
-	// Fall back to manual thresholds
-	return bd.checkManualThresholds(tenant, metrics)
-}
-
-// checkManualThresholds checks blast conditions using manual thresholds
-func (bd *BlastDetector) checkManualThresholds(tenant string, metrics *BlastMetrics) bool {
-	config := bd.config.CircuitBreaker.BlastProtection.ManualThresholds
-
-	// Check if we have tenant-specific overrides
-	if override, exists := bd.config.CircuitBreaker.BlastProtection.TenantOverrides[tenant]; exists {
-		return bd.checkThresholds(metrics, override.IngestionSpikeThreshold, 
-			override.QuerySpikeThreshold, override.SeriesSpikeThreshold)
+	now := bd.now()
+	if bd.observing(now) {
+		return false
 	}
-
-	// Use global manual thresholds
-	return bd.checkThresholds(metrics, config.IngestionSpikeThreshold, 
-		config.QuerySpikeThreshold, config.SeriesSpikeThreshold)
+	thresholds := bd.effectiveThresholds(tenant, metrics, now)
+	return bd.checkThresholds(metrics, bd.baselineAt(metrics, now), thresholds.Ingestion.Value,
+		thresholds.Query.Value, thresholds.Series.Value)
 }
 
-// checkThresholds performs the actual threshold comparison
-func (bd *BlastDetector) checkThresholds(metrics *BlastMetrics, ingestionThreshold, queryThreshold, seriesThreshold float64) bool {
+// checkThresholds performs the actual threshold comparison against the thresholds and
+// the baseline in effect; a threshold that is not positive is not configured and skips
+// its check
+func (bd *BlastDetector) checkThresholds(metrics *BlastMetrics, baseline BaselineRates, ingestionThreshold, queryThreshold, seriesThreshold float64) bool {
 	// Check absolute thresholds
-	if metrics.IngestionRate > ingestionThreshold {
+	if ingestionThreshold > 0 && metrics.IngestionRate > ingestionThreshold {
 		bd.log.V(1).Info("ingestion rate blast detected", 
 			"rate", metrics.IngestionRate, "threshold", ingestionThreshold)
 		return true
 	}
 
-	if metrics.QueryRate > queryThreshold {
+	if queryThreshold > 0 && metrics.QueryRate > queryThreshold {
 		bd.log.V(1).Info("query rate blast detected", 
 			"rate", metrics.QueryRate, "threshold", queryThreshold)
 		return true
 	}
 
-	if metrics.SeriesRate > seriesThreshold {
+	if seriesThreshold > 0 && metrics.SeriesRate > seriesThreshold {
 		bd.log.V(1).Info("series rate blast detected", 
 			"rate", metrics.SeriesRate, "threshold", seriesThreshold)
 		return true
 	}
 
-	// Check against baseline (if available)
-	if !metrics.BaselineRates.LastCalculated.IsZero() {
-		blastMultiplier := 5.0
-		if metrics.IngestionRate > metrics.BaselineRates.IngestionRate*blastMultiplier ||
-			metrics.QueryRate > metrics.BaselineRates.QueryRate*blastMultiplier ||
-			metrics.SeriesRate > metrics.BaselineRates.SeriesRate*blastMultiplier {
+	// Check against baseline (if available); a zero baseline rate has no spike to compare
+	if !baseline.LastCalculated.IsZero() {
+		blastMultiplier := bd.baselineMultiplier()
+		exceeds := func(rate, baselineRate float64) bool {
+			return baselineRate > 0 && rate > baselineRate*blastMultiplier
+		}
+		if exceeds(metrics.IngestionRate, baseline.IngestionRate) ||
+			exceeds(metrics.QueryRate, baseline.QueryRate) ||
+			exceeds(metrics.SeriesRate, baseline.SeriesRate) {
 			bd.log.V(1).Info("baseline multiplier blast detected", 
 				"multiplier", blastMultiplier)
 			return true
@@ -640,30 +674,46 @@ func (bd *BlastDetector) checkThresholds(metrics *BlastMetrics, ingestionThresho
 
 // EnableCircuitBreaker enables the circuit breaker at runtime
 func (bp *BlastProtector) EnableCircuitBreaker() {
-	bp.mu.Lock()
-	defer bp.mu.Unlock()
-	bp.config.CircuitBreaker.RuntimeEnabled = true
+	bp.live.Update(func(cfg *config.Config) {
+		cfg.CircuitBreaker.RuntimeEnabled = true
+	})
 	bp.log.Info("circuit breaker enabled at runtime")
 }
 
 // DisableCircuitBreaker disables the circuit breaker at runtime
 func (bp *BlastProtector) DisableCircuitBreaker() {
+	bp.live.Update(func(cfg *config.Config) {
+		cfg.CircuitBreaker.RuntimeEnabled = false
+	})
+	bp.log.Info("circuit breaker disabled at runtime")
+}
+
+// SetAlertManager sets the alerting manager used for emergency and panic notifications
+func (bp *BlastProtector) SetAlertManager(manager *alerting.Manager) {
 	bp.mu.Lock()
 	defer bp.mu.Unlock()
-	bp.config.CircuitBreaker.RuntimeEnabled = false
-	bp.log.Info("circuit breaker disabled at runtime")
+	bp.alertManager = manager
 }
 
 // IsEnabled returns the current runtime enabled state
 func (bp *BlastProtector) IsEnabled() bool {
 	bp.mu.RLock()
 	defer bp.mu.RUnlock()
-	return bp.config.CircuitBreaker.Enabled && bp.config.CircuitBreaker.RuntimeEnabled
+	return bp.config().CircuitBreaker.Enabled && bp.config().CircuitBreaker.RuntimeEnabled
+}
+
+// SetClock replaces the clock observations, baselines, blast checks and rate limiting
+// are timed with, for protecting against metrics generated on a simulated clock. It
+// must be called before the first metrics are processed.
+func (bp *BlastProtector) SetClock(now func() time.Time) {
+	bp.now = now
+	bp.blastDetector.now = now
+	bp.autoConfig.observationStartTime = now()
 }
 
 // UpdateCurrentLimits updates the current tenant limits for auto-configuration
 func (bp *BlastProtector) UpdateCurrentLimits(limits map[string]*analyzer.TenantLimits) {
-	if !bp.config.CircuitBreaker.AutoConfig.Enabled {
+	if !bp.config().CircuitBreaker.AutoConfig.Enabled {
 		return
 	}
 
@@ -674,31 +724,53 @@ func (bp *BlastProtector) UpdateCurrentLimits(limits map[string]*analyzer.Tenant
 	bp.autoConfig.lastUpdate = time.Now()
 
 	// Recalculate thresholds based on new limits
-	bp.recalculateThresholds()
+	bp.recalculateThresholds(true)
+}
+
+// ReloadConfig rebuilds the state derived from the configuration after it was
+// reloaded: the per-tenant rate limiters and the auto-configured thresholds
+func (bp *BlastProtector) ReloadConfig() {
+	bp.mu.Lock()
+	rateLimit := bp.config().CircuitBreaker.RateLimit
+	now := bp.now()
+	for tenant, limiter := range bp.rateLimiters {
+		settings, override := rateLimit.ForTenant(tenant)
+		limiter.reconfigure(settings, override, now)
+	}
+	rateLimiters := len(bp.rateLimiters)
+	bp.mu.Unlock()
+
+	if bp.config().CircuitBreaker.AutoConfig.Enabled {
+		bp.autoConfig.mu.Lock()
+		bp.recalculateThresholds(false)
+		bp.autoConfig.mu.Unlock()
+	}
+
+	bp.log.Info("circuit breaker reloaded configuration", "rate_limiters", rateLimiters)
 }
 
 // initializeAutoConfig initializes the auto-configuration system
 func (bp *BlastProtector) initializeAutoConfig() {
-	if !bp.config.CircuitBreaker.AutoConfig.Enabled {
+	if !bp.config().CircuitBreaker.AutoConfig.Enabled {
 		bp.initialized = true
 		return
 	}
 
 	bp.log.Info("initializing circuit breaker auto-configuration")
-	bp.autoConfig.observationStartTime = time.Now()
+	bp.autoConfig.observationStartTime = bp.now()
 	bp.initialized = true
 }
 
 // updateAutoConfiguration updates thresholds based on real-time metrics
 func (bp *BlastProtector) updateAutoConfiguration(tenantMetrics map[string]*collector.TenantMetrics) {
-	config := bp.config.CircuitBreaker.AutoConfig
+	config := bp.config().CircuitBreaker.AutoConfig
 	
 	// Check if we should adapt thresholds
 	if !config.RealtimeAdaptation.Enabled {
 		return
 	}
 
-	now := time.Now()
+	now := bp.now()
 	if now.Sub(bp.lastAdaptation) < config.RealtimeAdaptation.Interval {
 		return
 	}
@@ -708,15 +780,20 @@ func (bp *BlastProtector) updateAutoConfiguration(tenantMetrics map[string]*coll
 		return
 	}
 
-	bp.adaptThresholds(tenantMetrics)
+	bp.adaptThresholds(tenantMetrics, now)
 	bp.lastAdaptation = now
 }
 
-// recalculateThresholds recalculates thresholds based on current limits
-func (bp *BlastProtector) recalculateThresholds() {
-	multipliers := bp.config.CircuitBreaker.AutoConfig.LimitMultipliers
-	safetyConfig := bp.config.CircuitBreaker.AutoConfig.SafetyMargins
+// recalculateThresholds recalculates thresholds based on current limits. With
+// keepAdapted, thresholds realtime adaptation has moved toward the observed load are
+// kept and only those of limits without a threshold yet are derived from the limits.
+func (bp *BlastProtector) recalculateThresholds(keepAdapted bool) {
+	multipliers := bp.config().CircuitBreaker.AutoConfig.LimitMultipliers
+	safetyConfig := bp.config().CircuitBreaker.AutoConfig.SafetyMargins
 
+	// Tenants without applied limits no longer have auto thresholds
+	previous := bp.autoConfig.tenantThresholds
+	bp.autoConfig.tenantThresholds = make(map[string]*TenantThresholds, len(bp.autoConfig.currentLimits))
 	for tenant, limits := range bp.autoConfig.currentLimits {
 		safetyMargin := safetyConfig.DefaultMargin
 		if tenantMargin, exists := safetyConfig.TenantMargins[tenant]; exists {
@@ -727,25 +804,25 @@ func (bp *BlastProtector) recalculateThresholds() {
 		var ingestionRate, querySamples, maxSeries, ingestionBurst float64
 
 		if val, exists := limits.Limits["ingestion_rate"]; exists {
-			if v, ok := val.(float64); ok {
+			if v, ok := toFloat64(val); ok {
 				ingestionRate = v
 			}
 		}
 
 		if val, exists := limits.Limits["max_samples_per_query"]; exists {
-			if v, ok := val.(float64); ok {
+			if v, ok := toFloat64(val); ok {
 				querySamples = v
 			}
 		}
 
 		if val, exists := limits.Limits["max_global_series_per_user"]; exists {
-			if v, ok := val.(float64); ok {
+			if v, ok := toFloat64(val); ok {
 				maxSeries = v
 			}
 		}
 
 		if val, exists := limits.Limits["ingestion_burst_size"]; exists {
-			if v, ok := val.(float64); ok {
+			if v, ok := toFloat64(val); ok {
 				ingestionBurst = v
 			}
 		}
@@ -759,6 +836,13 @@ func (bp *BlastProtector) recalculateThresholds() {
 			BasedOnLimits:      limits,
 			SafetyMargin:       safetyMargin,
 		}
+		if adapted, exists := previous[tenant]; keepAdapted && exists && !adapted.LastAdapted.IsZero() {
+			threshold.IngestionThreshold = keptThreshold(adapted.IngestionThreshold, threshold.IngestionThreshold)
+			threshold.QueryThreshold = keptThreshold(adapted.QueryThreshold, threshold.QueryThreshold)
+			threshold.SeriesThreshold = keptThreshold(adapted.SeriesThreshold, threshold.SeriesThreshold)
+			threshold.LastAdapted = adapted.LastAdapted
+			threshold.AdaptationCycles = adapted.AdaptationCycles
+		}
 
 		bp.autoConfig.tenantThresholds[tenant] = threshold
 		
@@ -771,87 +855,18 @@ func (bp *BlastProtector) recalculateThresholds() {
 	}
 }
 
-// adaptThresholds adapts thresholds based on observed metrics
-func (bp *BlastProtector) adaptThresholds(tenantMetrics map[string]*collector.TenantMetrics) {
-	config := bp.config.CircuitBreaker.AutoConfig.RealtimeAdaptation
-	
-	for tenant := range tenantMetrics {
-		threshold, exists := bp.autoConfig.tenantThresholds[tenant]
-		if !exists {
-			continue
-		}
-
-		// Get baseline metrics for comparison
-		blastMetrics := bp.blastDetector.getOrCreateBlastMetrics(tenant)
-		
-		// Calculate percentile values from recent metrics
-		ingestionPercentile := bp.calculatePercentile(blastMetrics.IngestionRate, config.Percentile)
-		queryPercentile := bp.calculatePercentile(blastMetrics.QueryRate, config.Percentile)
-		seriesPercentile := bp.calculatePercentile(blastMetrics.SeriesRate, config.Percentile)
-
-		// Adapt thresholds with learning rate
-		learningRate := config.LearningRate
-		maxChange := config.MaxChangePercent / 100
-
-		// Adapt ingestion threshold
-		targetIngestion := ingestionPercentile * 1.2 // 20% above observed percentile
-		change := (targetIngestion - threshold.IngestionThreshold) * learningRate
-		if abs(change/threshold.IngestionThreshold) > maxChange {
-			if change > 0 {
-				change = threshold.IngestionThreshold * maxChange
-			} else {
-				change = -threshold.IngestionThreshold * maxChange
-			}
-		}
-		threshold.IngestionThreshold += change
-
-		// Adapt query threshold
-		targetQuery := queryPercentile * 1.3 // 30% above observed percentile
-		change = (targetQuery - threshold.QueryThreshold) * learningRate
-		if abs(change/threshold.QueryThreshold) > maxChange {
-			if change > 0 {
-				change = threshold.QueryThreshold * maxChange
-			} else {
-				change = -threshold.QueryThreshold * maxChange
-			}
-		}
-		threshold.QueryThreshold += change
-
-		// Adapt series threshold
-		targetSeries := seriesPercentile * 1.25 // 25% above observed percentile
-		change = (targetSeries - threshold.SeriesThreshold) * learningRate
-		if abs(change/threshold.SeriesThreshold) > maxChange {
-			if change > 0 {
-				change = threshold.SeriesThreshold * maxChange
-			} else {
-				change = -threshold.SeriesThreshold * maxChange
-			}
-		}
-		threshold.SeriesThreshold += change
-
-		threshold.LastCalculated = time.Now()
-		
-		bp.log.V(2).Info("adapted thresholds for tenant",
-			"tenant", tenant,
-			"newIngestionThreshold", threshold.IngestionThreshold,
-			"newQueryThreshold", threshold.QueryThreshold,
-			"newSeriesThreshold", threshold.SeriesThreshold)
-	}
-}
-
-// calculatePercentile calculates the specified percentile (simplified implementation)
-func (bp *BlastProtector) calculatePercentile(value float64, percentile float64) float64 {
-	// Simplified percentile calculation - in a real implementation, 
-	// you would maintain a sliding window of values and calculate the actual percentile
-	return value * (percentile / 100)
-}
-
-// abs returns the absolute value of x
-func abs(x float64) float64 {
-	if x < 0 {
-		return -x
+// toFloat64 converts a numeric limit value
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
 	}
-	return x
 }
 
 // GetAutoConfiguration returns the current auto-configuration state
@@ -860,8 +875,8 @@ func (bp *BlastProtector) GetAutoConfiguration() map[string]interface{} {
 	defer bp.autoConfig.mu.RUnlock()
 
 	result := map[string]interface{}{
-		"enabled":             bp.config.CircuitBreaker.AutoConfig.Enabled,
-		"mode":               bp.config.CircuitBreaker.Mode,
+		"enabled":             bp.config().CircuitBreaker.AutoConfig.Enabled,
+		"mode":               bp.config().CircuitBreaker.Mode,
 		"initialized":        bp.initialized,
 		"observationStart":   bp.autoConfig.observationStartTime,
 		"lastUpdate":         bp.autoConfig.lastUpdate,
@@ -877,6 +892,8 @@ func (bp *BlastProtector) GetAutoConfiguration() map[string]interface{} {
 			"burstThreshold":     threshold.BurstThreshold,
 			"lastCalculated":     threshold.LastCalculated,
 			"safetyMargin":       threshold.SafetyMargin,
+			"lastAdapted":        threshold.LastAdapted,
+			"adaptationCycles":   threshold.AdaptationCycles,
 		}
 	}
 
@@ -888,8 +905,10 @@ func (bd *BlastDetector) getOrCreateBlastMetrics(tenant string) *BlastMetrics {
 		return metrics
 	}
 
+	now := bd.now()
 	metrics := &BlastMetrics{
-		LastUpdate: time.Now(),
+		FirstSeen:  now,
+		LastUpdate: now,
 	}
 	bd.metrics[tenant] = metrics
 	return metrics
@@ -901,11 +920,4 @@ func (bd *BlastDetector) calculateRate(metrics *collector.TenantMetrics, metricN
 	}
 	return 0
 }
-
-func (bd *BlastDetector) updateBaseline(metrics *BlastMetrics) {
-	// Calculate baseline as average of recent stable periods
-	metrics.BaselineRates.IngestionRate = metrics.IngestionRate * 0.8 // Conservative baseline
-	metrics.BaselineRates.QueryRate = metrics.QueryRate * 0.8
-	metrics.BaselineRates.SeriesRate = metrics.SeriesRate * 0.8
-	metrics.BaselineRates.LastCalculated = time.Now()
-} 
\ No newline at end of file
+ 
\ No newline at end of file
@@ -0,0 +1,122 @@
+package circuitbreaker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// pagerDutyStub is an Events API v2 endpoint recording the events it receives
+type pagerDutyStub struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (p *pagerDutyStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	p.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write([]byte(`{"status":"success"}`))
+}
+
+// waitForEvents waits until the stub received count events and returns them
+func (p *pagerDutyStub) waitForEvents(t *testing.T, count int) []map[string]interface{} {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		p.mu.Lock()
+		events := append([]map[string]interface{}(nil), p.events...)
+		p.mu.Unlock()
+		if len(events) >= count || time.Now().After(deadline) {
+			if len(events) != count {
+				t.Fatalf("PagerDuty received %d events, want %d: %v", len(events), count, events)
+			}
+			return events
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newPagingProtector creates a circuit breaker whose alerts page the PagerDuty stub
+func newPagingProtector(t *testing.T) (*BlastProtector, *pagerDutyStub) {
+	stub := &pagerDutyStub{}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.Emergency.PanicMode.Actions = nil
+	cfg.Alerting = config.AlertingConfig{
+		Enabled: true,
+		PagerDuty: config.PagerDutyConfig{
+			Enabled:        true,
+			IntegrationKey: "routing-key",
+			Severity:       "critical",
+			Timeout:        time.Second,
+			EventsURL:      server.URL,
+		},
+	}
+
+	manager := alerting.NewManager(&cfg.Alerting, logr.Discard())
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start alerting manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+
+	bp := NewBlastProtector(config.NewLive(cfg), logr.Discard())
+	bp.SetAlertManager(manager)
+	return bp, stub
+}
+
+func TestEmergencyModePagesAndResolves(t *testing.T) {
+	tests := []struct {
+		name         string
+		enter        func(bp *BlastProtector)
+		wantDedup    string
+		wantSeverity string
+	}{
+		{"emergency mode", func(bp *BlastProtector) { bp.EnterEmergencyMode("ingestion spike") },
+			"mimir-limit-optimizer-emergency", "critical"},
+		{"panic mode", func(bp *BlastProtector) { bp.EnterPanicMode("ingestion spike") },
+			"mimir-limit-optimizer-panic_mode", "critical"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp, stub := newPagingProtector(t)
+
+			tt.enter(bp)
+			trigger := stub.waitForEvents(t, 1)[0]
+			if trigger["event_action"] != "trigger" || trigger["dedup_key"] != tt.wantDedup {
+				t.Fatalf("event = action %v, dedup key %v; want a trigger keyed %s",
+					trigger["event_action"], trigger["dedup_key"], tt.wantDedup)
+			}
+			if payload, _ := trigger["payload"].(map[string]interface{}); payload["severity"] != tt.wantSeverity {
+				t.Errorf("trigger payload = %v, want severity %s", payload, tt.wantSeverity)
+			}
+
+			if err := bp.ExitEmergencyMode(); err != nil {
+				t.Fatalf("ExitEmergencyMode: %v", err)
+			}
+			resolve := stub.waitForEvents(t, 2)[1]
+			if resolve["event_action"] != "resolve" || resolve["dedup_key"] != trigger["dedup_key"] {
+				t.Errorf("event = action %v, dedup key %v; want a resolve of the trigger's dedup key %v",
+					resolve["event_action"], resolve["dedup_key"], trigger["dedup_key"])
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package circuitbreaker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Built-in emergency trigger metrics, measured over the Mimir namespace. Any other
+// trigger metric is evaluated as a PromQL expression.
+const (
+	TriggerMetricCPU       = "cpu_percent"
+	TriggerMetricMemory    = "memory_percent"
+	TriggerMetricErrorRate = "error_rate"
+)
+
+// Actions of an emergency trigger
+const (
+	TriggerActionEmergency = "emergency_mode"
+	TriggerActionPanic     = "panic_mode"
+)
+
+// defaultEmergencyCheckInterval is used when recoveryProcedures.checkInterval is unset
+const defaultEmergencyCheckInterval = 30 * time.Second
+
+// recoveryStableChecks is the number of consecutive healthy checks after which the
+// automatic recovery attempts are reset, also after they were exhausted
+const recoveryStableChecks = 10
+
+// MeasureFunc returns the current value of a PromQL expression
+type MeasureFunc func(ctx context.Context, query string) (float64, error)
+
+// TriggerStatus is the latest evaluation of an emergency trigger
+type TriggerStatus struct {
+	Name      string  `json:"name"`
+	Metric    string  `json:"metric"`
+	Action    string  `json:"action"`
+	Threshold float64 `json:"threshold"`
+	Duration  string  `json:"duration"`
+	Value     float64 `json:"value"`
+	// ThresholdRatio is the value as a fraction of the threshold; 1 is at the threshold
+	ThresholdRatio float64    `json:"threshold_ratio"`
+	Breached       bool       `json:"breached"`
+	BreachedSince  *time.Time `json:"breached_since,omitempty"`
+	// Fired is set once the threshold has been breached for the trigger's duration
+	Fired       bool      `json:"fired"`
+	Error       string    `json:"error,omitempty"`
+	EvaluatedAt time.Time `json:"evaluated_at"`
+}
+
+// RecoveryStatus is the state of the automatic emergency recovery
+type RecoveryStatus struct {
+	AutoRecovery bool       `json:"auto_recovery"`
+	Attempts     int        `json:"attempts"`
+	MaxAttempts  int        `json:"max_attempts"`
+	HealthyFor   int        `json:"healthy_checks"`
+	LastAttempt  *time.Time `json:"last_attempt,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+// EmergencyMonitorStatus describes the emergency triggers and the recovery loop
+type EmergencyMonitorStatus struct {
+	CheckInterval string          `json:"check_interval"`
+	LastCheck     time.Time       `json:"last_check"`
+	Triggers      []TriggerStatus `json:"triggers"`
+	Recovery      RecoveryStatus  `json:"recovery"`
+}
+
+// EmergencyMonitor measures the Mimir components against the panic mode thresholds and
+// the emergency shutdown triggers, enters panic or emergency mode when one is breached
+// for its duration, and exits emergency mode again once every trigger is healthy
+type EmergencyMonitor struct {
+	live      *config.Live
+	protector *BlastProtector
+	measure   MeasureFunc
+	log       logr.Logger
+
+	mu           sync.RWMutex
+	status       map[string]*TriggerStatus
+	order        []string
+	lastCheck    time.Time
+	attempts     int
+	healthyFor   int
+	lastAttempt  time.Time
+	lastRecovery string
+}
+
+// NewEmergencyMonitor creates the monitor of a protector and makes ExitEmergencyMode
+// require healthy triggers
+func NewEmergencyMonitor(live *config.Live, protector *BlastProtector, measure MeasureFunc, log logr.Logger) *EmergencyMonitor {
+	m := &EmergencyMonitor{
+		live:      live,
+		protector: protector,
+		measure:   measure,
+		log:       log,
+		status:    make(map[string]*TriggerStatus),
+	}
+
+	protector.mu.Lock()
+	protector.emergencyMonitor = m
+	protector.mu.Unlock()
+	return m
+}
+
+// config returns the configuration in effect
+func (m *EmergencyMonitor) config() *config.Config {
+	return m.live.Load()
+}
+
+// Start checks the triggers every recoveryProcedures.checkInterval until ctx is cancelled
+func (m *EmergencyMonitor) Start(ctx context.Context) {
+	interval := m.checkInterval()
+	m.log.Info("starting emergency trigger monitor", "check_interval", interval, "triggers", len(m.triggers()))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check evaluates every trigger once, entering panic or emergency mode for fired
+// triggers and attempting recovery when all of them are healthy
+func (m *EmergencyMonitor) Check(ctx context.Context) {
+	fired, healthy := m.evaluate(ctx, time.Now())
+
+	for _, trigger := range fired {
+		reason := fmt.Sprintf("trigger %s: %s is %.2f, threshold %.2f for %s",
+			trigger.Name, trigger.Metric, trigger.Value, trigger.Threshold, trigger.Duration)
+		if trigger.Action == TriggerActionPanic {
+			m.protector.EnterPanicMode(reason)
+		} else {
+			m.protector.EnterEmergencyMode(reason)
+		}
+	}
+
+	m.recover(healthy)
+}
+
+// Status returns the latest evaluation of every trigger and the recovery state
+func (m *EmergencyMonitor) Status() EmergencyMonitorStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	recovery := m.config().Emergency.RecoveryProcedures
+	status := EmergencyMonitorStatus{
+		CheckInterval: m.checkInterval().String(),
+		LastCheck:     m.lastCheck,
+		Triggers:      make([]TriggerStatus, 0, len(m.order)),
+		Recovery: RecoveryStatus{
+			AutoRecovery: recovery.AutoRecovery,
+			Attempts:     m.attempts,
+			MaxAttempts:  recovery.MaxAttempts,
+			HealthyFor:   m.healthyFor,
+			LastError:    m.lastRecovery,
+		},
+	}
+	if !m.lastAttempt.IsZero() {
+		lastAttempt := m.lastAttempt
+		status.Recovery.LastAttempt = &lastAttempt
+	}
+	for _, name := range m.order {
+		status.Triggers = append(status.Triggers, *m.status[name])
+	}
+	return status
+}
+
+// Healthy reports whether the last check measured every trigger below its threshold.
+// Before the first check there is nothing to object to recovery.
+func (m *EmergencyMonitor) Healthy() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.lastCheck.IsZero() {
+		return true
+	}
+	for _, name := range m.order {
+		if trigger := m.status[name]; trigger.Breached || trigger.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluate measures every trigger and returns those that fired, and whether all of
+// them were measured below their threshold
+func (m *EmergencyMonitor) evaluate(ctx context.Context, now time.Time) ([]TriggerStatus, bool) {
+	triggers := m.triggers()
+	timeout := m.config().Emergency.RecoveryProcedures.HealthCheckTimeout
+
+	type measurement struct {
+		value float64
+		err   error
+	}
+	measurements := make([]measurement, len(triggers))
+	for i, trigger := range triggers {
+		measureCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			measureCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		measurements[i].value, measurements[i].err = m.measure(measureCtx, m.triggerQuery(trigger.Metric))
+		cancel()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous := m.status
+	m.status = make(map[string]*TriggerStatus, len(triggers))
+	m.order = make([]string, 0, len(triggers))
+	m.lastCheck = now
+
+	var fired []TriggerStatus
+	healthy := true
+	for i, trigger := range triggers {
+		status := &TriggerStatus{
+			Name:        trigger.Name,
+			Metric:      trigger.Metric,
+			Action:      trigger.Action,
+			Threshold:   trigger.Threshold,
+			Duration:    trigger.Duration.String(),
+			EvaluatedAt: now,
+		}
+		last := previous[trigger.Name]
+
+		if err := measurements[i].err; err != nil {
+			// An unmeasurable trigger keeps its breach, so a failing metrics pipeline
+			// neither fires nor clears it
+			status.Error = err.Error()
+			if last != nil {
+				status.Value, status.ThresholdRatio = last.Value, last.ThresholdRatio
+				status.Breached, status.BreachedSince, status.Fired = last.Breached, last.BreachedSince, last.Fired
+			}
+			healthy = false
+			m.log.V(1).Info("failed to measure emergency trigger", "trigger", trigger.Name, "error", err)
+		} else {
+			status.Value = measurements[i].value
+			status.ThresholdRatio = status.Value / trigger.Threshold
+			status.Breached = status.Value >= trigger.Threshold
+			if status.Breached {
+				since := now
+				if last != nil && last.BreachedSince != nil {
+					since = *last.BreachedSince
+				}
+				status.BreachedSince = &since
+				status.Fired = now.Sub(since) >= trigger.Duration
+				if status.Fired {
+					fired = append(fired, *status)
+				}
+				healthy = false
+			}
+		}
+
+		metrics.CircuitBreakerMetricsInstance.SetEmergencyTriggerRatio(trigger.Name, status.ThresholdRatio)
+		m.status[trigger.Name] = status
+		m.order = append(m.order, trigger.Name)
+	}
+
+	return fired, healthy
+}
+
+// recover exits emergency mode once every trigger is healthy. Each exit counts as an
+// attempt; after recoveryProcedures.maxAttempts of them, emergency mode is only left
+// again once the triggers stayed healthy for recoveryStableChecks checks, so a
+// relapsing system does not flap in and out of emergency mode.
+func (m *EmergencyMonitor) recover(healthy bool) {
+	recovery := m.config().Emergency.RecoveryProcedures
+
+	m.mu.Lock()
+	if !healthy {
+		m.healthyFor = 0
+		m.mu.Unlock()
+		return
+	}
+	m.healthyFor++
+	if m.healthyFor >= recoveryStableChecks {
+		m.attempts = 0
+	}
+	exhausted := recovery.MaxAttempts > 0 && m.attempts >= recovery.MaxAttempts
+	m.mu.Unlock()
+
+	if !recovery.AutoRecovery || !m.protector.inEmergencyMode() || exhausted {
+		return
+	}
+
+	err := m.protector.ExitEmergencyMode()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempts++
+	m.lastAttempt = time.Now()
+	m.lastRecovery = ""
+	if err != nil {
+		m.lastRecovery = err.Error()
+		m.log.Error(err, "automatic emergency recovery failed", "attempt", m.attempts)
+		return
+	}
+	m.log.Info("emergency triggers healthy, exited emergency mode", "attempt", m.attempts, "max_attempts", recovery.MaxAttempts)
+}
+
+// triggers returns the panic mode thresholds and the shutdown triggers to evaluate
+func (m *EmergencyMonitor) triggers() []config.EmergencyTrigger {
+	emergency := m.config().Emergency
+	var triggers []config.EmergencyTrigger
+
+	if emergency.PanicMode.Enabled {
+		for _, builtin := range []struct {
+			name, metric string
+			threshold    float64
+		}{
+			{"panic_cpu", TriggerMetricCPU, emergency.PanicMode.CPUThreshold},
+			{"panic_memory", TriggerMetricMemory, emergency.PanicMode.MemoryThreshold},
+			{"panic_error_rate", TriggerMetricErrorRate, emergency.PanicMode.ErrorRateThreshold},
+		} {
+			if builtin.threshold > 0 {
+				triggers = append(triggers, config.EmergencyTrigger{
+					Name:      builtin.name,
+					Metric:    builtin.metric,
+					Threshold: builtin.threshold,
+					Action:    TriggerActionPanic,
+				})
+			}
+		}
+	}
+
+	for _, trigger := range emergency.ShutdownTriggers {
+		if trigger.Action == "" {
+			trigger.Action = TriggerActionEmergency
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers
+}
+
+// triggerQuery returns the PromQL expression measuring a trigger metric. CPU and memory
+// are the highest usage of any Mimir pod as a percentage of its limits, and the error
+// rate is the rate of 5xx responses of all Mimir components.
+func (m *EmergencyMonitor) triggerQuery(metric string) string {
+	namespace := m.config().Mimir.Namespace
+	switch metric {
+	case TriggerMetricCPU:
+		return fmt.Sprintf(`max(sum by (pod) (rate(container_cpu_usage_seconds_total{namespace=%q,container!="",container!="POD"}[5m])) / sum by (pod) (kube_pod_container_resource_limits{namespace=%q,resource="cpu"})) * 100`,
+			namespace, namespace)
+	case TriggerMetricMemory:
+		return fmt.Sprintf(`max(sum by (pod) (container_memory_working_set_bytes{namespace=%q,container!="",container!="POD"}) / sum by (pod) (kube_pod_container_resource_limits{namespace=%q,resource="memory"})) * 100`,
+			namespace, namespace)
+	case TriggerMetricErrorRate:
+		return fmt.Sprintf(`sum(rate(cortex_request_duration_seconds_count{namespace=%q,status_code=~"5.."}[5m]))`, namespace)
+	default:
+		return metric
+	}
+}
+
+func (m *EmergencyMonitor) checkInterval() time.Duration {
+	if interval := m.config().Emergency.RecoveryProcedures.CheckInterval; interval > 0 {
+		return interval
+	}
+	return defaultEmergencyCheckInterval
+}
@@ -0,0 +1,189 @@
+package circuitbreaker
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// TenantRateLimiter implements per-tenant rate limiting with a token bucket
+type TenantRateLimiter struct {
+	tenant         string
+	tokens         float64
+	lastUpdate     time.Time
+	requestsPerSec float64
+	burstCapacity  int
+	// override is the tenantOverrides pattern the settings come from, if any
+	override string
+	allowed  uint64
+	rejected uint64
+	mu       sync.Mutex
+}
+
+// RateLimiterStatus is the token bucket state of a tenant
+type RateLimiterStatus struct {
+	Tokens            float64 `json:"tokens"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	BurstCapacity     int     `json:"burst_capacity"`
+	Override          string  `json:"override,omitempty"`
+	Allowed           uint64  `json:"allowed"`
+	Rejected          uint64  `json:"rejected"`
+}
+
+// getRateLimiter returns the rate limiter of a tenant, creating it with a full bucket as
+// of now. The caller must hold bp.mu for writing.
+func (bp *BlastProtector) getRateLimiter(tenant string, now time.Time) *TenantRateLimiter {
+	if limiter, exists := bp.rateLimiters[tenant]; exists {
+		return limiter
+	}
+
+	settings, override := bp.config().CircuitBreaker.RateLimit.ForTenant(tenant)
+	limiter := &TenantRateLimiter{
+		tenant:         tenant,
+		tokens:         float64(settings.BurstCapacity),
+		lastUpdate:     now,
+		requestsPerSec: settings.RequestsPerSecond,
+		burstCapacity:  settings.BurstCapacity,
+		override:       override,
+	}
+
+	bp.rateLimiters[tenant] = limiter
+	return limiter
+}
+
+// rateLimiterStatus returns the token bucket state of every tenant. The caller must
+// hold bp.mu.
+func (bp *BlastProtector) rateLimiterStatus() map[string]RateLimiterStatus {
+	now := bp.now()
+	status := make(map[string]RateLimiterStatus, len(bp.rateLimiters))
+	for tenant, limiter := range bp.rateLimiters {
+		status[tenant] = limiter.status(now)
+	}
+	return status
+}
+
+// ResetRateLimiter refills the token bucket of a tenant, so it is not throttled until it
+// uses up its burst capacity again. It reports false for a tenant without a bucket.
+func (bp *BlastProtector) ResetRateLimiter(tenant string) bool {
+	bp.mu.RLock()
+	limiter, exists := bp.rateLimiters[tenant]
+	bp.mu.RUnlock()
+	if !exists {
+		return false
+	}
+
+	limiter.Reset(bp.now())
+	bp.log.Info("rate limiter reset", "tenant", tenant)
+	return true
+}
+
+// allowRequest takes a token from the bucket if one is left
+func (trl *TenantRateLimiter) allowRequest(now time.Time) bool {
+	trl.mu.Lock()
+	defer trl.mu.Unlock()
+
+	trl.refill(now)
+
+	allowed := trl.tokens >= 1.0
+	if allowed {
+		trl.tokens -= 1.0
+		trl.allowed++
+		metrics.CircuitBreakerMetricsInstance.IncRateLimitRequests(trl.tenant, "allowed")
+	} else {
+		trl.rejected++
+		metrics.CircuitBreakerMetricsInstance.IncRateLimitRequests(trl.tenant, "rejected")
+	}
+	metrics.CircuitBreakerMetricsInstance.SetRateLimitTokens(trl.tenant, trl.tokens)
+	return allowed
+}
+
+// Reset fills the bucket to its burst capacity as of now. The allowed and rejected
+// counts are kept.
+func (trl *TenantRateLimiter) Reset(now time.Time) {
+	trl.mu.Lock()
+	defer trl.mu.Unlock()
+
+	trl.tokens = trl.capacity()
+	trl.lastUpdate = now
+	metrics.CircuitBreakerMetricsInstance.SetRateLimitTokens(trl.tenant, trl.tokens)
+}
+
+// Tokens returns the tokens left in the bucket as of now, including those earned since
+// the last request
+func (trl *TenantRateLimiter) Tokens(now time.Time) float64 {
+	trl.mu.Lock()
+	defer trl.mu.Unlock()
+
+	trl.refill(now)
+	return trl.tokens
+}
+
+// reconfigure applies new token bucket settings, settling the tokens earned at the
+// previous rate first
+func (trl *TenantRateLimiter) reconfigure(settings config.RateLimitOverride, override string, now time.Time) {
+	trl.mu.Lock()
+	defer trl.mu.Unlock()
+
+	trl.refill(now)
+	trl.requestsPerSec = settings.RequestsPerSecond
+	trl.burstCapacity = settings.BurstCapacity
+	trl.override = override
+	trl.tokens = math.Min(trl.tokens, trl.capacity())
+}
+
+// status returns the bucket state as of now without taking a token
+func (trl *TenantRateLimiter) status(now time.Time) RateLimiterStatus {
+	trl.mu.Lock()
+	defer trl.mu.Unlock()
+
+	trl.refill(now)
+	return RateLimiterStatus{
+		Tokens:            trl.tokens,
+		RequestsPerSecond: trl.requestsPerSec,
+		BurstCapacity:     trl.burstCapacity,
+		Override:          trl.override,
+		Allowed:           trl.allowed,
+		Rejected:          trl.rejected,
+	}
+}
+
+// refill adds the tokens earned since the last update, capped at the burst capacity,
+// so an idle bucket is full again after missing/rate seconds however long it idled.
+// A clock going backwards earns nothing. The caller must hold trl.mu.
+func (trl *TenantRateLimiter) refill(now time.Time) {
+	capacity := trl.capacity()
+	if math.IsNaN(trl.tokens) || trl.tokens < 0 {
+		trl.tokens = 0
+	}
+
+	elapsed := now.Sub(trl.lastUpdate).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	trl.lastUpdate = now
+
+	missing := capacity - trl.tokens
+	if missing <= 0 {
+		trl.tokens = capacity
+		return
+	}
+	if trl.requestsPerSec <= 0 {
+		return
+	}
+	// Compare durations rather than adding elapsed * rate, which is NaN or +Inf for
+	// an infinite rate
+	if elapsed >= missing/trl.requestsPerSec {
+		trl.tokens = capacity
+		return
+	}
+	trl.tokens += elapsed * trl.requestsPerSec
+}
+
+// capacity is the burst capacity of the bucket; a bucket always holds at least one
+// token so that a tenant is never rejected forever. The caller must hold trl.mu.
+func (trl *TenantRateLimiter) capacity() float64 {
+	return math.Max(float64(trl.burstCapacity), 1)
+}
@@ -0,0 +1,324 @@
+package circuitbreaker
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+var rateLimitEpoch = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func newTestRateLimiter(tenant string, rps float64, burst int) *TenantRateLimiter {
+	return &TenantRateLimiter{
+		tenant:         tenant,
+		tokens:         math.Max(float64(burst), 1),
+		lastUpdate:     rateLimitEpoch,
+		requestsPerSec: rps,
+		burstCapacity:  burst,
+	}
+}
+
+// drain takes every token left at now
+func drain(limiter *TenantRateLimiter, now time.Time) {
+	for limiter.allowRequest(now) {
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	tests := []struct {
+		name       string
+		rps        float64
+		burst      int
+		idle       time.Duration
+		wantTokens float64
+	}{
+		{"stays empty without time passing", 2, 10, 0, 0},
+		{"refills at the rate", 2, 10, 3 * time.Second, 6},
+		{"caps at the burst capacity", 2, 10, 10 * time.Second, 10},
+		{"is full after an hour idle", 2, 10, time.Hour, 10},
+		{"is full after a year idle", 0.001, 10, 365 * 24 * time.Hour, 10},
+		{"refills with an infinite rate", math.Inf(1), 10, time.Millisecond, 10},
+		{"never refills without a rate", 0, 10, time.Hour, 0},
+		{"holds one token with a zero burst", 1, 0, time.Hour, 1},
+		{"earns nothing when the clock goes backwards", 2, 10, -time.Hour, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newTestRateLimiter("tenant-a", tt.rps, tt.burst)
+			drain(limiter, rateLimitEpoch)
+
+			if got := limiter.Tokens(rateLimitEpoch.Add(tt.idle)); math.Abs(got-tt.wantTokens) > 1e-9 {
+				t.Errorf("tokens after %v idle = %v, want %v", tt.idle, got, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestFirstRequestAfterLongIdleIsAllowed(t *testing.T) {
+	limiter := newTestRateLimiter("tenant-a", 10, 20)
+	drain(limiter, rateLimitEpoch)
+
+	later := rateLimitEpoch.Add(time.Hour)
+	for i := 0; i < 20; i++ {
+		if !limiter.allowRequest(later) {
+			t.Fatalf("request %d after an hour idle was rejected", i+1)
+		}
+	}
+	if limiter.allowRequest(later) {
+		t.Errorf("request beyond the burst capacity was allowed")
+	}
+}
+
+func TestTokenBucketBurstThenSteadyState(t *testing.T) {
+	limiter := newTestRateLimiter("tenant-a", 4, 20)
+
+	// A burst of 30 requests at once is allowed up to the burst capacity
+	allowed := 0
+	for i := 0; i < 30; i++ {
+		if limiter.allowRequest(rateLimitEpoch) {
+			allowed++
+		}
+	}
+	if allowed != 20 {
+		t.Errorf("burst allowed %d of 30 requests, want the burst capacity 20", allowed)
+	}
+
+	// 8 requests a second for 10 seconds are then allowed at the refill rate of 4
+	allowed = 0
+	now := rateLimitEpoch
+	for i := 0; i < 80; i++ {
+		now = now.Add(125 * time.Millisecond)
+		if limiter.allowRequest(now) {
+			allowed++
+		}
+	}
+	if allowed != 40 {
+		t.Errorf("steady state allowed %d of 80 requests, want the 40 earned in 10s", allowed)
+	}
+	if status := limiter.status(now); status.Allowed != 60 || status.Rejected != 50 {
+		t.Errorf("counted %d allowed and %d rejected, want 60 and 50", status.Allowed, status.Rejected)
+	}
+}
+
+func TestTokenBucketReset(t *testing.T) {
+	limiter := newTestRateLimiter("tenant-a", 1, 10)
+	drain(limiter, rateLimitEpoch)
+
+	limiter.Reset(rateLimitEpoch)
+	if got := limiter.Tokens(rateLimitEpoch); got != 10 {
+		t.Errorf("tokens after Reset = %v, want the burst capacity 10", got)
+	}
+	if status := limiter.status(rateLimitEpoch); status.Allowed != 10 || status.Rejected != 1 {
+		t.Errorf("Reset changed the counts to %d allowed and %d rejected", status.Allowed, status.Rejected)
+	}
+}
+
+func TestResetRateLimiter(t *testing.T) {
+	bp := newRateLimitedProtector()
+	if bp.ResetRateLimiter("team-b") {
+		t.Errorf("ResetRateLimiter of a tenant without a bucket reported true")
+	}
+
+	bp.mu.Lock()
+	limiter := bp.getRateLimiter("team-b", rateLimitEpoch)
+	bp.mu.Unlock()
+	drain(limiter, rateLimitEpoch)
+
+	// Resets racing requests leave a consistent bucket, checked by go test -race
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			bp.ResetRateLimiter("team-b")
+		}()
+		go func() {
+			defer wg.Done()
+			limiter.allowRequest(rateLimitEpoch)
+		}()
+	}
+	wg.Wait()
+
+	if !bp.ResetRateLimiter("team-b") {
+		t.Fatalf("ResetRateLimiter of team-b reported false")
+	}
+	if got := limiter.Tokens(rateLimitEpoch); got != 3 {
+		t.Errorf("tokens after ResetRateLimiter = %v, want the burst capacity 3", got)
+	}
+}
+
+func TestTokenBucketReconfigure(t *testing.T) {
+	limiter := newTestRateLimiter("tenant-a", 1, 10)
+	drain(limiter, rateLimitEpoch)
+
+	// Tokens earned at the old rate are settled before switching
+	now := rateLimitEpoch.Add(4 * time.Second)
+	limiter.reconfigure(config.RateLimitOverride{RequestsPerSecond: 100, BurstCapacity: 3}, "team-*", now)
+	status := limiter.status(now)
+	if status.Tokens != 3 || status.RequestsPerSecond != 100 || status.BurstCapacity != 3 || status.Override != "team-*" {
+		t.Errorf("status after reconfigure = %+v, want 3 tokens capped at the new burst", status)
+	}
+}
+
+// TestAllowRequestConcurrent is meant for go test -race: many goroutines take tokens
+// from one bucket while its status is read, and exactly the burst capacity is allowed
+func TestAllowRequestConcurrent(t *testing.T) {
+	const goroutines, requests, burst = 32, 200, 1000
+	limiter := newTestRateLimiter("tenant-a", 0, burst)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := 0
+			for j := 0; j < requests; j++ {
+				if limiter.allowRequest(rateLimitEpoch) {
+					local++
+				}
+				if j%50 == 0 {
+					_ = limiter.status(rateLimitEpoch)
+				}
+			}
+			mu.Lock()
+			allowed += local
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	status := limiter.status(rateLimitEpoch)
+	if allowed != burst || status.Allowed != burst {
+		t.Errorf("allowed %d requests (counted %d), want the burst capacity %d", allowed, status.Allowed, burst)
+	}
+	if status.Allowed+status.Rejected != goroutines*requests {
+		t.Errorf("allowed %d + rejected %d, want %d requests", status.Allowed, status.Rejected, goroutines*requests)
+	}
+	if status.Tokens != 0 {
+		t.Errorf("tokens left = %v, want 0", status.Tokens)
+	}
+}
+
+// TestAllowRequestConcurrentRefill is meant for go test -race: goroutines take tokens
+// while the clock advances, and no more tokens are allowed than were earned
+func TestAllowRequestConcurrentRefill(t *testing.T) {
+	const goroutines, requests, burst, rps = 16, 500, 10, 100.0
+	limiter := newTestRateLimiter("tenant-a", rps, burst)
+
+	var clockMu sync.Mutex
+	now := rateLimitEpoch
+	tick := func() time.Time {
+		clockMu.Lock()
+		defer clockMu.Unlock()
+		now = now.Add(time.Millisecond)
+		return now
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				limiter.allowRequest(tick())
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := now.Sub(rateLimitEpoch).Seconds()
+	status := limiter.status(now)
+	if earned := float64(burst) + elapsed*rps; float64(status.Allowed) > earned+1e-9 {
+		t.Errorf("allowed %d requests, want at most the %v earned in %vs", status.Allowed, earned, elapsed)
+	}
+	if status.Allowed+status.Rejected != goroutines*requests {
+		t.Errorf("allowed %d + rejected %d, want %d requests", status.Allowed, status.Rejected, goroutines*requests)
+	}
+}
+
+func newRateLimitedProtector() *BlastProtector {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.CircuitBreaker.AutoConfig.Enabled = false
+	cfg.CircuitBreaker.RateLimit = config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: 1,
+		BurstCapacity:     1,
+		TenantOverrides: map[string]config.RateLimitOverride{
+			"team-*":     {BurstCapacity: 3},
+			"team-a-*":   {BurstCapacity: 5},
+			"team-a-vip": {RequestsPerSecond: 50},
+		},
+	}
+	bp := NewBlastProtector(config.NewLive(cfg), logr.Discard())
+	bp.SetClock(func() time.Time { return rateLimitEpoch })
+	return bp
+}
+
+func TestRateLimitTenantOverrides(t *testing.T) {
+	bp := newRateLimitedProtector()
+	tenants := []string{"other", "team-b", "team-a-prod", "team-a-vip"}
+	tenantMetrics := make(map[string]*collector.TenantMetrics, len(tenants))
+	for _, tenant := range tenants {
+		tenantMetrics[tenant] = &collector.TenantMetrics{Tenant: tenant}
+	}
+
+	bp.mu.Lock()
+	admitted := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		for tenant := range bp.applyRateLimiting(tenantMetrics) {
+			admitted[tenant]++
+		}
+	}
+	bp.mu.Unlock()
+
+	want := map[string]int{"other": 1, "team-b": 3, "team-a-prod": 5, "team-a-vip": 1}
+	for tenant, count := range want {
+		if admitted[tenant] != count {
+			t.Errorf("%s admitted %d times, want its burst capacity %d", tenant, admitted[tenant], count)
+		}
+	}
+
+	limiters := bp.GetProtectionStatus()["rate_limiters"].(map[string]RateLimiterStatus)
+	status := limiters["team-a-vip"]
+	if status.Override != "team-a-vip" || status.RequestsPerSecond != 50 || status.BurstCapacity != 1 {
+		t.Errorf("team-a-vip status = %+v, want the exact override with the default burst", status)
+	}
+	if status := limiters["team-a-prod"]; status.Override != "team-a-*" || status.Allowed != 5 || status.Rejected != 1 {
+		t.Errorf("team-a-prod status = %+v, want the longest matching pattern and its counts", status)
+	}
+	if status := limiters["other"]; status.Override != "" || status.Tokens != 0 {
+		t.Errorf("other status = %+v, want the defaults with no tokens left", status)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_rate_limit_requests_total",
+		map[string]string{"tenant": "team-b", "result": "rejected"}); got < 3 {
+		t.Errorf("rejected requests of team-b = %v, want at least 3", got)
+	}
+}
+
+func TestRateLimitReloadReresolvesOverrides(t *testing.T) {
+	bp := newRateLimitedProtector()
+	bp.mu.Lock()
+	bp.getRateLimiter("team-b", rateLimitEpoch)
+	bp.mu.Unlock()
+
+	bp.live.Update(func(cfg *config.Config) {
+		cfg.CircuitBreaker.RateLimit.TenantOverrides = map[string]config.RateLimitOverride{"team-b": {BurstCapacity: 7}}
+	})
+	bp.ReloadConfig()
+
+	status := bp.GetProtectionStatus()["rate_limiters"].(map[string]RateLimiterStatus)["team-b"]
+	if status.Override != "team-b" || status.BurstCapacity != 7 {
+		t.Errorf("team-b status after reload = %+v, want the new override", status)
+	}
+}
@@ -0,0 +1,100 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minSeasonalSamples is the number of observations a time bucket needs before its
+// seasonal baseline is used; sparser buckets fall back to the overall baseline
+const minSeasonalSamples = 3
+
+// SeasonalRates are the baseline rates of a tenant in one time bucket
+type SeasonalRates struct {
+	IngestionRate float64 `json:"ingestion_rate"`
+	QueryRate     float64 `json:"query_rate"`
+	SeriesRate    float64 `json:"series_rate"`
+	ErrorRate     float64 `json:"error_rate"`
+	// Samples is the number of observations in the bucket
+	Samples int `json:"samples"`
+}
+
+// seasonalBucket returns the time bucket of t in UTC: its hour of day ("14") or, with
+// dayOfWeek, its day of week and hour ("mon-14")
+func seasonalBucket(t time.Time, dayOfWeek bool) string {
+	t = t.UTC()
+	if dayOfWeek {
+		return fmt.Sprintf("%s-%02d", strings.ToLower(t.Weekday().String()[:3]), t.Hour())
+	}
+	return fmt.Sprintf("%02d", t.Hour())
+}
+
+// seasonalBaselines groups the samples by time bucket and returns the percentile of
+// each rate per bucket with at least minSeasonalSamples observations, or nil when
+// realtimeAdaptation.seasonalPatterns is disabled
+func (bd *BlastDetector) seasonalBaselines(samples []baselineSample, percentile float64) map[string]SeasonalRates {
+	adaptation := bd.config().CircuitBreaker.AutoConfig.RealtimeAdaptation
+	if !adaptation.SeasonalPatterns {
+		return nil
+	}
+
+	buckets := make(map[string][]baselineSample)
+	for _, sample := range samples {
+		bucket := seasonalBucket(sample.at, adaptation.SeasonalDayOfWeek)
+		buckets[bucket] = append(buckets[bucket], sample)
+	}
+
+	seasonal := make(map[string]SeasonalRates, len(buckets))
+	for bucket, bucketSamples := range buckets {
+		if len(bucketSamples) >= minSeasonalSamples {
+			seasonal[bucket] = ratesOf(bucketSamples, percentile)
+		}
+	}
+	return seasonal
+}
+
+// At returns the baseline in effect at t: the rates of the seasonal bucket of t when
+// it has a baseline, the overall rates otherwise
+func (b BaselineRates) At(t time.Time, dayOfWeek bool) BaselineRates {
+	rates, exists := b.Seasonal[seasonalBucket(t, dayOfWeek)]
+	if !exists {
+		return b
+	}
+	b.IngestionRate = rates.IngestionRate
+	b.QueryRate = rates.QueryRate
+	b.SeriesRate = rates.SeriesRate
+	b.ErrorRate = rates.ErrorRate
+	return b
+}
+
+// baselineAt returns the baseline of a tenant blast detection compares against at now
+func (bd *BlastDetector) baselineAt(blastMetrics *BlastMetrics, now time.Time) BaselineRates {
+	adaptation := bd.config().CircuitBreaker.AutoConfig.RealtimeAdaptation
+	if !adaptation.SeasonalPatterns {
+		return blastMetrics.BaselineRates
+	}
+	return blastMetrics.BaselineRates.At(now, adaptation.SeasonalDayOfWeek)
+}
+
+// seasonalFactors returns how much the seasonal bucket of now raises the auto
+// thresholds of a tenant: the ratio of its bucket baseline to its overall baseline per
+// rate. A factor is never below 1, so a quiet bucket does not tighten the thresholds
+// derived from the limits.
+func (bd *BlastDetector) seasonalFactors(blastMetrics *BlastMetrics, now time.Time) (ingestion, query, series float64) {
+	if blastMetrics == nil {
+		return 1, 1, 1
+	}
+	overall := blastMetrics.BaselineRates
+	bucket := bd.baselineAt(blastMetrics, now)
+
+	factor := func(bucketRate, overallRate float64) float64 {
+		if overallRate <= 0 || bucketRate <= overallRate {
+			return 1
+		}
+		return bucketRate / overallRate
+	}
+	return factor(bucket.IngestionRate, overall.IngestionRate),
+		factor(bucket.QueryRate, overall.QueryRate),
+		factor(bucket.SeriesRate, overall.SeriesRate)
+}
@@ -0,0 +1,173 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// seasonalEpoch is a Monday midnight in UTC
+var seasonalEpoch = time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+
+// seasonalProtector is a circuit breaker on a simulated clock with a 72h baseline
+type seasonalProtector struct {
+	*BlastProtector
+	now time.Time
+}
+
+func newSeasonalProtector(seasonal bool) *seasonalProtector {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.CircuitBreaker.AutoConfig.Enabled = true
+	cfg.CircuitBreaker.AutoConfig.BaselineWindow = 72 * time.Hour
+	cfg.CircuitBreaker.AutoConfig.RealtimeAdaptation.SeasonalPatterns = seasonal
+	cfg.CircuitBreaker.BlastProtection.UseAutoThresholds = true
+	cfg.CircuitBreaker.BlastProtection.BaselineMultiplier = 5
+	cfg.Emergency.PanicMode.Actions = nil
+
+	sp := &seasonalProtector{BlastProtector: NewBlastProtector(config.NewLive(cfg), logr.Discard()), now: seasonalEpoch}
+	sp.SetClock(func() time.Time { return sp.now })
+	// 100000 samples/s allowed at 150% plus the 25% margin
+	sp.UpdateCurrentLimits(ingestionRateLimit(100000))
+	return sp
+}
+
+// observe records tenant-a ingesting rate samples/s at the current time
+func (sp *seasonalProtector) observe(rate float64) map[string]*collector.TenantMetrics {
+	tenantMetrics := map[string]*collector.TenantMetrics{
+		"tenant-a": {
+			Tenant: "tenant-a",
+			Metrics: map[string][]collector.MetricData{
+				"cortex_distributor_received_samples_total": {{Value: rate, Timestamp: sp.now}},
+			},
+		},
+	}
+	sp.blastDetector.updateMetrics(tenantMetrics)
+	return tenantMetrics
+}
+
+// nightlyBatch is 5000 samples/s with a nightly batch job ingesting 50000 between
+// 02:00 and 03:00
+func nightlyBatch(t time.Time) float64 {
+	if t.Hour() == 2 {
+		return 50000
+	}
+	return 5000
+}
+
+// observeDays observes tenant-a every 5 minutes for days of the nightly batch load
+func (sp *seasonalProtector) observeDays(days int) {
+	for end := sp.now.Add(time.Duration(days) * 24 * time.Hour); sp.now.Before(end); sp.now = sp.now.Add(5 * time.Minute) {
+		sp.observe(nightlyBatch(sp.now))
+	}
+}
+
+func (sp *seasonalProtector) ingestionThresholdAt(t time.Time) float64 {
+	sp.now = t
+	return sp.EffectiveThresholds().Tenants["tenant-a"].Ingestion.Value
+}
+
+func TestSeasonalBaselinesFollowDailyCycle(t *testing.T) {
+	sp := newSeasonalProtector(true)
+	sp.observeDays(4)
+
+	baseline := sp.Baselines()["tenant-a"]
+	if got := baseline.Seasonal["02"].IngestionRate; got != 50000 {
+		t.Errorf("02:00 bucket baseline = %v, want the batch job's 50000", got)
+	}
+	if got := baseline.Seasonal["14"].IngestionRate; got != 5000 {
+		t.Errorf("14:00 bucket baseline = %v, want 5000", got)
+	}
+	// One hour a day is below the 95th percentile of the whole day
+	if baseline.IngestionRate != 5000 {
+		t.Errorf("overall baseline = %v, want the daytime 5000", baseline.IngestionRate)
+	}
+	if got := baseline.At(seasonalEpoch.Add(98*time.Hour+30*time.Minute), false).IngestionRate; got != 50000 {
+		t.Errorf("baseline at 02:30 = %v, want the 02:00 bucket's 50000", got)
+	}
+
+	night := sp.ingestionThresholdAt(seasonalEpoch.Add(98*time.Hour + 30*time.Minute))
+	day := sp.ingestionThresholdAt(seasonalEpoch.Add(110*time.Hour + 30*time.Minute))
+	if day != 187500 {
+		t.Errorf("14:30 ingestion threshold = %v, want the 187500 derived from the limits", day)
+	}
+	if night != day*10 {
+		t.Errorf("02:30 ingestion threshold = %v, want raised by the batch job to %v", night, day*10)
+	}
+}
+
+func TestSeasonalBaselinesStopNightlyTrips(t *testing.T) {
+	tests := []struct {
+		name      string
+		seasonal  bool
+		wantBlast bool
+	}{
+		{"global baseline", false, true},
+		{"seasonal baselines", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sp := newSeasonalProtector(tt.seasonal)
+			sp.observeDays(4)
+
+			// The fifth night's batch job is 10 times the daytime baseline
+			sp.now = seasonalEpoch.Add(98 * time.Hour)
+			blasting := sp.blastDetector.blastTenants(sp.observe(50000))
+			if blasting["tenant-a"] != tt.wantBlast {
+				t.Errorf("nightly batch job blast = %v, want %v", blasting["tenant-a"], tt.wantBlast)
+			}
+
+			// A daytime spike of the same size trips either way
+			sp.now = seasonalEpoch.Add(110 * time.Hour)
+			if blasting := sp.blastDetector.blastTenants(sp.observe(50000)); !blasting["tenant-a"] {
+				t.Errorf("daytime spike to 50000 was not detected")
+			}
+		})
+	}
+}
+
+func TestSeasonalBaselineNeedsSamples(t *testing.T) {
+	sp := newSeasonalProtector(true)
+	for i := 0; i < 10; i++ {
+		sp.observe(5000)
+		sp.now = sp.now.Add(5 * time.Minute)
+	}
+	// Two observations in the 01:00 bucket are too few for a seasonal baseline
+	sp.now = seasonalEpoch.Add(time.Hour + 40*time.Minute)
+	for i := 0; i < minSeasonalSamples-1; i++ {
+		sp.observe(50000)
+		sp.now = sp.now.Add(5 * time.Minute)
+	}
+
+	baseline := sp.Baselines()["tenant-a"]
+	if _, exists := baseline.Seasonal["01"]; exists {
+		t.Errorf("01:00 bucket has a baseline from %d observations", minSeasonalSamples-1)
+	}
+	if got := baseline.At(seasonalEpoch.Add(time.Hour+50*time.Minute), false); got.IngestionRate != baseline.IngestionRate {
+		t.Errorf("baseline of a sparse bucket = %v, want the overall %v", got.IngestionRate, baseline.IngestionRate)
+	}
+}
+
+func TestSeasonalBucket(t *testing.T) {
+	tests := []struct {
+		at        time.Time
+		dayOfWeek bool
+		want      string
+	}{
+		{time.Date(2026, 5, 4, 2, 30, 0, 0, time.UTC), false, "02"},
+		{time.Date(2026, 5, 4, 2, 30, 0, 0, time.UTC), true, "mon-02"},
+		{time.Date(2026, 5, 9, 23, 59, 0, 0, time.UTC), true, "sat-23"},
+		// Buckets are in UTC
+		{time.Date(2026, 5, 4, 4, 30, 0, 0, time.FixedZone("CEST", 2*3600)), true, "mon-02"},
+	}
+	for _, tt := range tests {
+		if got := seasonalBucket(tt.at, tt.dayOfWeek); got != tt.want {
+			t.Errorf("seasonalBucket(%v, %v) = %s, want %s", tt.at, tt.dayOfWeek, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// allStates lists the states exported by the current state metric
+var allStates = []CircuitBreakerState{StateClosed, StateOpen, StateHalfOpen}
+
+// SetAuditLogger sets the audit logger recording circuit breaker state transitions
+func (bp *BlastProtector) SetAuditLogger(logger auditlog.AuditLogger) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.auditLogger = logger
+}
+
+// evaluateTenants runs the tenants of a reconcile through the circuit breaker; a tenant
+// that meets a blast condition counts as a failure, any other as a success. In half-open
+// state at most MaxRequestsInHalfOpen tenants are evaluated per reconcile and the others
+// are held back until the probing outcome is known. The caller must hold bp.mu.
+func (bp *BlastProtector) evaluateTenants(tenantMetrics map[string]*collector.TenantMetrics, blastTenants map[string]bool) map[string]*collector.TenantMetrics {
+	// Emergency mode and a forced open keep the circuit open until they are ended
+	if bp.state == StateOpen && !bp.emergencyMode && !bp.forcedOpen &&
+		time.Since(bp.lastStateChange) >= bp.config().CircuitBreaker.SleepWindow {
+		bp.transition(StateHalfOpen, "sleep window elapsed", nil)
+	}
+
+	switch bp.state {
+	case StateClosed:
+		failed := make(map[string]bool)
+		for tenant := range tenantMetrics {
+			bp.requests++
+			if blastTenants[tenant] {
+				bp.failures++
+				failed[tenant] = true
+			}
+		}
+		if bp.shouldOpenCircuit() {
+			bp.transition(StateOpen, fmt.Sprintf("failure rate exceeded %.1f%%", bp.config().CircuitBreaker.FailureThreshold),
+				sortedTenants(failed))
+		}
+		return tenantMetrics
+
+	case StateHalfOpen:
+		return bp.probeTenants(tenantMetrics, blastTenants)
+
+	default:
+		return tenantMetrics
+	}
+}
+
+// probeTenants lets a bounded number of tenants through in half-open state. The circuit
+// reopens on the first failed probe and closes after HalfOpenSuccessThreshold
+// consecutive successes, which may span several reconciles.
+func (bp *BlastProtector) probeTenants(tenantMetrics map[string]*collector.TenantMetrics, blastTenants map[string]bool) map[string]*collector.TenantMetrics {
+	tenants := make([]string, 0, len(tenantMetrics))
+	for tenant := range tenantMetrics {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	if len(tenants) == 0 {
+		return tenantMetrics
+	}
+
+	maxProbes := bp.config().CircuitBreaker.MaxRequestsInHalfOpen
+	successThreshold := bp.config().CircuitBreaker.HalfOpenSuccessThreshold
+
+	// Rotate the probed tenants so every tenant is eventually evaluated
+	start := bp.probeOffset % len(tenants)
+	bp.probeOffset += maxProbes
+	bp.halfOpenRequests = 0
+
+	admitted := make(map[string]*collector.TenantMetrics, len(tenants))
+	probed := make(map[string]bool)
+	held := 0
+	for i := range tenants {
+		tenant := tenants[(start+i)%len(tenants)]
+
+		// Once probing decided the state, tenants are no longer held back
+		if bp.state != StateHalfOpen {
+			admitted[tenant] = tenantMetrics[tenant]
+			continue
+		}
+		if bp.halfOpenRequests >= maxProbes {
+			held++
+			continue
+		}
+
+		bp.halfOpenRequests++
+		admitted[tenant] = tenantMetrics[tenant]
+		if blastTenants[tenant] {
+			bp.transition(StateOpen, fmt.Sprintf("half-open probe of tenant %s failed", tenant), []string{tenant})
+			continue
+		}
+		probed[tenant] = true
+		bp.consecutiveSuccesses++
+		if bp.consecutiveSuccesses >= successThreshold {
+			bp.transition(StateClosed, fmt.Sprintf("%d consecutive half-open probes succeeded", bp.consecutiveSuccesses),
+				sortedTenants(probed))
+		}
+	}
+
+	if held > 0 {
+		bp.log.Info("holding back tenants while the circuit breaker is half-open",
+			"probed", bp.halfOpenRequests,
+			"held_back", held,
+			"consecutive_successes", bp.consecutiveSuccesses,
+			"success_threshold", successThreshold)
+	}
+	return admitted
+}
+
+// ForceOpen opens the circuit breaker on an operator's request, e.g. during a known-bad
+// deploy, and holds it open past the sleep window until ForceClose is called
+func (bp *BlastProtector) ForceOpen(reason string) error {
+	if reason == "" {
+		return fmt.Errorf("a reason is required to force the circuit breaker open")
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	before := bp.protectionState()
+	bp.forcedOpen = true
+	counters, changed := bp.setState(StateOpen, "forced open: "+reason)
+	bp.auditProtectionEvent("circuit_breaker_forced_open", before, reason, []string{}, counters)
+
+	if changed && bp.alertManager != nil {
+		alert := alerting.CreateAlert(alerting.AlertTypeCircuitBreaker, alerting.PriorityP1,
+			"Circuit breaker forced open",
+			fmt.Sprintf("Circuit breaker was forced open: %s", reason))
+		alert.Details = map[string]interface{}{
+			"reason":                reason,
+			"circuit_breaker_state": StateOpen.String(),
+			"forced":                true,
+		}
+		bp.alertManager.SendAlert(alert)
+	}
+	return nil
+}
+
+// ForceClose ends a forced open, emergency or panic mode on an operator's request after
+// manual remediation, resets the failure counters and moves the circuit breaker to
+// half-open, so it closes once tenants are probed cleanly. Unlike ExitEmergencyMode it
+// does not wait for the emergency triggers to recover.
+func (bp *BlastProtector) ForceClose(reason string) error {
+	if reason == "" {
+		return fmt.Errorf("a reason is required to force the circuit breaker closed")
+	}
+
+	defer bp.runPendingActions()
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+
+	before := bp.protectionState()
+	bp.forcedOpen = false
+	if bp.emergencyMode {
+		bp.emergencyMode = false
+		bp.panicMode = false
+		bp.queueActionRecovery("circuit breaker force closed")
+		for _, alertType := range bp.modeIncidents {
+			bp.resolveEmergencyAlert(alertType, fmt.Sprintf("Circuit breaker force closed: %s", reason))
+		}
+		bp.modeIncidents = nil
+	}
+
+	counters, changed := bp.setState(StateHalfOpen, "force closed: "+reason)
+	// A half-open circuit restarts probing from scratch
+	bp.failures = 0
+	bp.requests = 0
+	bp.halfOpenRequests = 0
+	bp.consecutiveSuccesses = 0
+	bp.auditProtectionEvent("circuit_breaker_force_closed", before, reason, []string{}, counters)
+
+	if changed {
+		bp.sendStateAlert(StateHalfOpen, "force closed: "+reason, []string{}, counters)
+	}
+	return nil
+}
+
+// protectionState is the circuit breaker state together with the protection modes
+type protectionState struct {
+	state         CircuitBreakerState
+	emergencyMode bool
+	panicMode     bool
+}
+
+// protectionState returns the current state and modes. The caller must hold bp.mu.
+func (bp *BlastProtector) protectionState() protectionState {
+	return protectionState{state: bp.state, emergencyMode: bp.emergencyMode, panicMode: bp.panicMode}
+}
+
+// Protecting reports whether ApplyProtection currently reduces limits: the circuit is
+// open or half-open, or emergency or panic mode is active
+func (bp *BlastProtector) Protecting() bool {
+	if !bp.config().CircuitBreaker.Enabled {
+		return false
+	}
+
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	return bp.state != StateClosed || bp.emergencyMode || bp.panicMode
+}
+
+// values returns the state and modes as audit log values
+func (s protectionState) values() map[string]interface{} {
+	return map[string]interface{}{
+		"state":          s.state.String(),
+		"emergency_mode": s.emergencyMode,
+		"panic_mode":     s.panicMode,
+	}
+}
+
+// transition moves the circuit breaker to a new state and records the change as one
+// audit entry and one alert, naming the tenants that caused it. The caller must hold
+// bp.mu.
+func (bp *BlastProtector) transition(to CircuitBreakerState, reason string, tenants []string) {
+	before := bp.protectionState()
+	counters, changed := bp.setState(to, reason)
+	if !changed {
+		return
+	}
+	tenants = orNoTenants(tenants)
+
+	bp.auditProtectionEvent("circuit_breaker_state_change", before, reason, tenants, counters)
+	bp.sendStateAlert(to, reason, tenants, counters)
+}
+
+// setState moves the circuit breaker to a new state, resetting the counters of the
+// previous one, and records the change in the logs and metrics. It returns the counters
+// of the previous state, and false when the circuit breaker already was in the state.
+// The protection modes are exported either way, as they change before the state. The
+// caller must hold bp.mu.
+func (bp *BlastProtector) setState(to CircuitBreakerState, reason string) (map[string]interface{}, bool) {
+	defer bp.setStateMetrics()
+
+	from := bp.state
+	counters := map[string]interface{}{
+		"failures":              bp.failures,
+		"requests":              bp.requests,
+		"consecutive_successes": bp.consecutiveSuccesses,
+	}
+	if from == to {
+		return counters, false
+	}
+
+	bp.state = to
+	bp.lastStateChange = time.Now()
+	bp.lastTransitionReason = reason
+	bp.failures = 0
+	bp.requests = 0
+	bp.halfOpenRequests = 0
+	bp.consecutiveSuccesses = 0
+
+	bp.log.Info("circuit breaker state changed",
+		"from", from.String(),
+		"to", to.String(),
+		"reason", reason,
+		"emergency_mode", bp.emergencyMode,
+		"panic_mode", bp.panicMode)
+
+	metrics.CircuitBreakerMetricsInstance.IncCircuitBreakerTransitions(from.String(), to.String())
+	return counters, true
+}
+
+// setStateMetrics exports the current state and protection modes
+func (bp *BlastProtector) setStateMetrics() {
+	for _, state := range allStates {
+		metrics.CircuitBreakerMetricsInstance.SetCircuitBreakerCurrentState(state.String(), state == bp.state)
+	}
+	metrics.EmergencyMetricsInstance.SetProtectionModes(bp.emergencyMode, bp.panicMode)
+}
+
+// auditProtectionEvent records a state transition or protection mode change in the
+// audit log, with the state and modes before and after it and the affected tenants
+func (bp *BlastProtector) auditProtectionEvent(action string, before protectionState, reason string, tenants []string, counters map[string]interface{}) {
+	if bp.auditLogger == nil {
+		return
+	}
+	after := bp.protectionState()
+
+	changes := map[string]interface{}{
+		"from":             before.state.String(),
+		"to":               after.state.String(),
+		"emergency_mode":   after.emergencyMode,
+		"panic_mode":       after.panicMode,
+		"affected_tenants": tenants,
+	}
+	for name, value := range counters {
+		changes[name] = value
+	}
+
+	entry := &auditlog.AuditEntry{
+		Action:    action,
+		Reason:    reason,
+		Changes:   changes,
+		OldValues: before.values(),
+		NewValues: after.values(),
+		Source:    "circuit-breaker",
+		Component: "circuit-breaker",
+		Success:   true,
+	}
+	if len(tenants) == 1 {
+		entry.Tenant = tenants[0]
+	}
+	if err := bp.auditLogger.LogEntry(entry); err != nil {
+		bp.log.Error(err, "failed to audit circuit breaker event", "action", action,
+			"from", before.state.String(), "to", after.state.String())
+	}
+}
+
+// sendStateAlert notifies the alert channels of a state transition: opening raises the
+// circuit breaker alert, half-open updates it with the recovery probing and closing
+// resolves it
+func (bp *BlastProtector) sendStateAlert(to CircuitBreakerState, reason string, tenants []string, counters map[string]interface{}) {
+	if bp.alertManager == nil {
+		return
+	}
+
+	details := map[string]interface{}{
+		"reason":                reason,
+		"circuit_breaker_state": to.String(),
+		"affected_tenants":      tenants,
+	}
+	for name, value := range counters {
+		details[name] = value
+	}
+
+	var alert *alerting.Alert
+	switch to {
+	case StateOpen:
+		alert = alerting.CreateCircuitBreakerAlert("", "failure-rate", details)
+	case StateHalfOpen:
+		alert = alerting.CreateAlert(alerting.AlertTypeCircuitBreaker, alerting.PriorityP3,
+			"Circuit breaker half-open",
+			fmt.Sprintf("Circuit breaker is probing recovery: %s", reason))
+		alert.Details = details
+	default:
+		alert = alerting.CreateResolvedAlert(alerting.AlertTypeCircuitBreaker, "",
+			fmt.Sprintf("Circuit breaker closed after successful recovery: %s", reason))
+		alert.Details = details
+	}
+	bp.alertManager.SendAlert(alert)
+}
+
+// sortedTenants returns the tenants of a set in order
+func sortedTenants(tenants map[string]bool) []string {
+	sorted := make([]string, 0, len(tenants))
+	for tenant, included := range tenants {
+		if included {
+			sorted = append(sorted, tenant)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
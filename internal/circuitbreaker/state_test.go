@@ -0,0 +1,429 @@
+package circuitbreaker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// newStateTestProtector creates a circuit breaker opening at 50% failures of 4 tenant
+// evaluations, probing 2 tenants per reconcile while half-open and closing after 3
+// consecutive successful probes
+func newStateTestProtector() (*BlastProtector, *auditlog.MemoryAuditLogger) {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.CircuitBreaker.FailureThreshold = 50
+	cfg.CircuitBreaker.RequestVolumeThreshold = 4
+	cfg.CircuitBreaker.MaxRequestsInHalfOpen = 2
+	cfg.CircuitBreaker.HalfOpenSuccessThreshold = 3
+	cfg.Emergency.PanicMode.Actions = nil
+
+	audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	bp := NewBlastProtector(config.NewLive(cfg), logr.Discard())
+	bp.SetAuditLogger(audit)
+	return bp, audit
+}
+
+// stateTestTenants are the tenants evaluated on every reconcile of the state tests
+var stateTestTenants = []string{"tenant-0", "tenant-1", "tenant-2", "tenant-3"}
+
+// reconcileTenants runs one reconcile worth of tenants through the circuit breaker,
+// the failing ones meeting a blast condition, and returns the tenants admitted
+func reconcileTenants(bp *BlastProtector, failing ...string) map[string]*collector.TenantMetrics {
+	tenantMetrics := make(map[string]*collector.TenantMetrics, len(stateTestTenants))
+	for _, tenant := range stateTestTenants {
+		tenantMetrics[tenant] = &collector.TenantMetrics{Tenant: tenant}
+	}
+	blastTenants := make(map[string]bool, len(failing))
+	for _, tenant := range failing {
+		blastTenants[tenant] = true
+	}
+
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	return bp.evaluateTenants(tenantMetrics, blastTenants)
+}
+
+// elapseSleepWindow moves the last state change back by the sleep window
+func elapseSleepWindow(bp *BlastProtector) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	bp.lastStateChange = bp.lastStateChange.Add(-bp.config().CircuitBreaker.SleepWindow)
+}
+
+// stateStep is one event of a state machine test and the state expected after it
+type stateStep struct {
+	// event is "reconcile", "sleep", "emergency", "panic", "exit-emergency",
+	// "force-open" or "force-close"
+	event string
+	// failing are the tenants meeting a blast condition in a reconcile
+	failing []string
+
+	wantState CircuitBreakerState
+	// wantAdmitted is the number of tenants a reconcile lets through
+	wantAdmitted  int
+	wantEmergency bool
+}
+
+func (s stateStep) run(t *testing.T, bp *BlastProtector) int {
+	t.Helper()
+	switch s.event {
+	case "reconcile":
+		return len(reconcileTenants(bp, s.failing...))
+	case "sleep":
+		elapseSleepWindow(bp)
+	case "emergency":
+		bp.EnterEmergencyMode("ingestion spike")
+	case "panic":
+		bp.EnterPanicMode("ingestion spike")
+	case "exit-emergency":
+		if err := bp.ExitEmergencyMode(); err != nil {
+			t.Fatalf("ExitEmergencyMode: %v", err)
+		}
+	case "force-open":
+		if err := bp.ForceOpen("bad deploy"); err != nil {
+			t.Fatalf("ForceOpen: %v", err)
+		}
+	case "force-close":
+		if err := bp.ForceClose("remediated"); err != nil {
+			t.Fatalf("ForceClose: %v", err)
+		}
+	default:
+		t.Fatalf("unknown event %q", s.event)
+	}
+	return 0
+}
+
+func reconcileStep(wantState CircuitBreakerState, wantAdmitted int, failing ...string) stateStep {
+	return stateStep{event: "reconcile", failing: failing, wantState: wantState, wantAdmitted: wantAdmitted}
+}
+
+func eventStep(event string, wantState CircuitBreakerState, wantEmergency bool) stateStep {
+	return stateStep{event: event, wantState: wantState, wantEmergency: wantEmergency}
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	// tripped opens the circuit with half of the tenants failing
+	tripped := reconcileStep(StateOpen, 4, "tenant-0", "tenant-1")
+	sleep := eventStep("sleep", StateOpen, false)
+
+	tests := []struct {
+		name  string
+		steps []stateStep
+	}{
+		{"closed stays closed below the failure threshold", []stateStep{
+			reconcileStep(StateClosed, 4, "tenant-0"),
+		}},
+		{"closed opens at the failure threshold", []stateStep{
+			tripped,
+		}},
+		{"open stays open within the sleep window", []stateStep{
+			tripped,
+			reconcileStep(StateOpen, 4),
+		}},
+		{"open moves to half-open after the sleep window and probes a bounded number of tenants", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateHalfOpen, 2),
+		}},
+		{"half-open closes after consecutive successes across reconciles", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateHalfOpen, 2),
+			reconcileStep(StateClosed, 4),
+			reconcileStep(StateClosed, 4),
+		}},
+		{"half-open reopens on a failed probe", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateOpen, 4, "tenant-0"),
+		}},
+		{"half-open reopens on a failure after earlier successes", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateHalfOpen, 2),
+			// The second reconcile probes tenant-2 and tenant-3
+			reconcileStep(StateOpen, 4, "tenant-2"),
+			reconcileStep(StateOpen, 4),
+		}},
+		{"failures of held back tenants do not reopen half-open", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateHalfOpen, 2, "tenant-2", "tenant-3"),
+		}},
+		{"reopened circuit waits for another sleep window", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateOpen, 4, "tenant-0"),
+			reconcileStep(StateOpen, 4),
+			sleep,
+			reconcileStep(StateHalfOpen, 2),
+		}},
+		{"emergency mode keeps the circuit open past the sleep window", []stateStep{
+			eventStep("emergency", StateOpen, true),
+			sleep,
+			reconcileStep(StateOpen, 4),
+		}},
+		{"exiting emergency mode probes before closing", []stateStep{
+			eventStep("emergency", StateOpen, true),
+			eventStep("exit-emergency", StateHalfOpen, false),
+			reconcileStep(StateHalfOpen, 2),
+			reconcileStep(StateClosed, 4),
+		}},
+		{"exiting emergency mode reopens on a failed probe", []stateStep{
+			eventStep("emergency", StateOpen, true),
+			eventStep("exit-emergency", StateHalfOpen, false),
+			reconcileStep(StateOpen, 4, "tenant-1"),
+		}},
+		{"emergency mode while half-open reopens the circuit", []stateStep{
+			tripped, sleep,
+			reconcileStep(StateHalfOpen, 2),
+			eventStep("emergency", StateOpen, true),
+			sleep,
+			reconcileStep(StateOpen, 4),
+		}},
+		{"emergency mode while open holds the circuit open", []stateStep{
+			tripped,
+			eventStep("emergency", StateOpen, true),
+			sleep,
+			reconcileStep(StateOpen, 4),
+			eventStep("exit-emergency", StateHalfOpen, false),
+		}},
+		{"panic mode opens the circuit until exited", []stateStep{
+			eventStep("panic", StateOpen, true),
+			sleep,
+			reconcileStep(StateOpen, 4),
+			eventStep("exit-emergency", StateHalfOpen, false),
+		}},
+		{"forced open is held past the sleep window", []stateStep{
+			eventStep("force-open", StateOpen, false),
+			sleep,
+			reconcileStep(StateOpen, 4),
+			eventStep("force-close", StateHalfOpen, false),
+			reconcileStep(StateHalfOpen, 2),
+			reconcileStep(StateClosed, 4),
+		}},
+		{"force close ends emergency mode", []stateStep{
+			eventStep("emergency", StateOpen, true),
+			eventStep("force-close", StateHalfOpen, false),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bp, _ := newStateTestProtector()
+
+			for i, step := range tt.steps {
+				admitted := step.run(t, bp)
+
+				status := bp.GetProtectionStatus()
+				if got := status["circuit_breaker_state"]; got != step.wantState.String() {
+					t.Fatalf("step %d (%s): state = %v, want %s", i, step.event, got, step.wantState)
+				}
+				if step.event == "reconcile" && admitted != step.wantAdmitted {
+					t.Errorf("step %d: %d tenants admitted, want %d", i, admitted, step.wantAdmitted)
+				}
+				if step.event != "reconcile" && step.event != "sleep" && status["emergency_mode"] != step.wantEmergency {
+					t.Errorf("step %d (%s): emergency mode = %v, want %v", i, step.event, status["emergency_mode"], step.wantEmergency)
+				}
+			}
+		})
+	}
+}
+
+func TestHalfOpenProbesEveryTenant(t *testing.T) {
+	bp, _ := newStateTestProtector()
+	bp.live.Update(func(cfg *config.Config) {
+		cfg.CircuitBreaker.HalfOpenSuccessThreshold = 10
+	})
+	reconcileTenants(bp, "tenant-0", "tenant-1")
+	elapseSleepWindow(bp)
+
+	probed := make(map[string]int)
+	for i := 0; i < 2; i++ {
+		for tenant := range reconcileTenants(bp) {
+			probed[tenant]++
+		}
+	}
+	for _, tenant := range stateTestTenants {
+		if probed[tenant] != 1 {
+			t.Errorf("%s was probed %d time(s) in two half-open reconciles, want the probes rotated", tenant, probed[tenant])
+		}
+	}
+}
+
+func TestStateTransitionsRecorded(t *testing.T) {
+	bp, audit := newStateTestProtector()
+	transitions := func(from, to CircuitBreakerState) float64 {
+		return metricstest.Value(t, "mimir_limit_optimizer_circuit_breaker_transitions_total",
+			map[string]string{"from": from.String(), "to": to.String()})
+	}
+	opened, probing, closed := transitions(StateClosed, StateOpen), transitions(StateOpen, StateHalfOpen), transitions(StateHalfOpen, StateClosed)
+
+	reconcileTenants(bp, "tenant-0", "tenant-1")
+	elapseSleepWindow(bp)
+	reconcileTenants(bp)
+	if got := metricstest.Value(t, "mimir_limit_optimizer_circuit_breaker_current_state", map[string]string{"state": StateHalfOpen.String()}); got != 1 {
+		t.Errorf("current state metric for %s = %v while half-open, want 1", StateHalfOpen, got)
+	}
+	reconcileTenants(bp)
+
+	for _, transition := range []struct {
+		from, to CircuitBreakerState
+		before   float64
+	}{
+		{StateClosed, StateOpen, opened},
+		{StateOpen, StateHalfOpen, probing},
+		{StateHalfOpen, StateClosed, closed},
+	} {
+		if got := transitions(transition.from, transition.to) - transition.before; got != 1 {
+			t.Errorf("%s -> %s transitions increased by %v, want 1", transition.from, transition.to, got)
+		}
+	}
+	for _, state := range allStates {
+		want := 0.0
+		if state == StateClosed {
+			want = 1
+		}
+		if got := metricstest.Value(t, "mimir_limit_optimizer_circuit_breaker_current_state", map[string]string{"state": state.String()}); got != want {
+			t.Errorf("current state metric for %s = %v, want %v", state, got, want)
+		}
+	}
+
+	entries, err := audit.GetEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	var recorded []string
+	for _, entry := range entries {
+		if entry.Action == "circuit_breaker_state_change" {
+			recorded = append(recorded, fmt.Sprintf("%s->%s", entry.Changes["from"], entry.Changes["to"]))
+		}
+	}
+	if len(recorded) != 3 {
+		t.Fatalf("state change audit entries = %v, want the three transitions", recorded)
+	}
+
+	status := bp.GetProtectionStatus()
+	if status["last_transition_reason"] == "" || status["consecutive_successes"] != 0 {
+		t.Errorf("protection status = %v, want the closing reason and reset counters", status)
+	}
+}
+
+// webhookRecorder is a webhook receiving the default alert payload
+type webhookRecorder struct {
+	mu     sync.Mutex
+	alerts []string
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var alert struct {
+		Type     string `json:"type"`
+		Resolved bool   `json:"resolved"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&alert); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if alert.Resolved {
+		alert.Type += " resolved"
+	}
+	r.mu.Lock()
+	r.alerts = append(r.alerts, alert.Type)
+	r.mu.Unlock()
+}
+
+// waitForAlerts waits until count alerts were delivered, and a moment longer for any
+// unexpected ones, and returns them
+func (r *webhookRecorder) waitForAlerts(count int) []string {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		delivered := len(r.alerts)
+		r.mu.Unlock()
+		if delivered >= count || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.alerts...)
+}
+
+func TestProtectionEventsAuditedAndAlertedOnce(t *testing.T) {
+	bp, audit := newStateTestProtector()
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	alertingConfig := config.AlertingConfig{
+		Enabled:  true,
+		Webhooks: []config.WebhookConfig{{Name: "recorder", URL: server.URL, Enabled: true, Timeout: time.Second}},
+	}
+	manager := alerting.NewManager(&alertingConfig, logr.Discard())
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start alerting manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+	bp.SetAlertManager(manager)
+
+	steps := []struct {
+		step       stateStep
+		wantAction string
+		wantAlert  string
+	}{
+		{reconcileStep(StateOpen, 4, "tenant-0", "tenant-1"), "circuit_breaker_state_change", "circuit_breaker"},
+		{eventStep("sleep", StateOpen, false), "", ""},
+		{reconcileStep(StateHalfOpen, 2), "circuit_breaker_state_change", "circuit_breaker"},
+		{reconcileStep(StateClosed, 2), "circuit_breaker_state_change", "circuit_breaker resolved"},
+		{eventStep("emergency", StateOpen, true), "circuit_breaker_emergency_mode", "emergency"},
+		{eventStep("emergency", StateOpen, true), "", ""},
+		{eventStep("exit-emergency", StateHalfOpen, false), "circuit_breaker_emergency_mode_exited", "emergency resolved"},
+		{eventStep("panic", StateOpen, true), "circuit_breaker_panic_mode", "panic_mode"},
+		{eventStep("exit-emergency", StateHalfOpen, false), "circuit_breaker_emergency_mode_exited", "panic_mode resolved"},
+	}
+
+	entries, alerts := 0, 0
+	for i, step := range steps {
+		step.step.run(t, bp)
+
+		recorded, err := audit.GetEntries(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("GetEntries: %v", err)
+		}
+		var actions []string
+		for _, entry := range recorded[entries:] {
+			actions = append(actions, entry.Action)
+		}
+		entries = len(recorded)
+
+		wantAlerts := alerts
+		if step.wantAlert != "" {
+			wantAlerts++
+		}
+		delivered := recorder.waitForAlerts(wantAlerts)[alerts:]
+		alerts += len(delivered)
+
+		if step.wantAction == "" {
+			if len(actions) != 0 || len(delivered) != 0 {
+				t.Errorf("step %d (%s) recorded %v and alerted %v, want nothing", i, step.step.event, actions, delivered)
+			}
+			continue
+		}
+		if len(actions) != 1 || actions[0] != step.wantAction {
+			t.Errorf("step %d (%s) audit entries = %v, want one %s", i, step.step.event, actions, step.wantAction)
+		}
+		if len(delivered) != 1 || delivered[0] != step.wantAlert {
+			t.Errorf("step %d (%s) alerts = %v, want one %s", i, step.step.event, delivered, step.wantAlert)
+		}
+	}
+}
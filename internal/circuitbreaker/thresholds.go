@@ -0,0 +1,183 @@
+package circuitbreaker
+
+import (
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Sources of an effective blast detection threshold
+const (
+	ThresholdSourceOverride = "override"
+	ThresholdSourceAuto     = "auto"
+	ThresholdSourceManual   = "manual"
+)
+
+// EffectiveThreshold is a threshold used by blast detection and where it comes from
+type EffectiveThreshold struct {
+	Value  float64 `json:"value"`
+	Source string  `json:"source"`
+}
+
+// EffectiveThresholds are the thresholds blast detection applies to a tenant
+type EffectiveThresholds struct {
+	Ingestion EffectiveThreshold `json:"ingestion"`
+	Query     EffectiveThreshold `json:"query"`
+	Series    EffectiveThreshold `json:"series"`
+	// WarmingUp is set while auto thresholds are replaced by the manual ones
+	WarmingUp bool `json:"warming_up"`
+	// WarmUpEndsAt is when the auto thresholds can be used at the earliest
+	WarmUpEndsAt *time.Time `json:"warm_up_ends_at,omitempty"`
+}
+
+// ThresholdsReport describes the blast detection thresholds of all known tenants
+type ThresholdsReport struct {
+	UseAutoThresholds bool                           `json:"use_auto_thresholds"`
+	AutoConfigEnabled bool                           `json:"auto_config_enabled"`
+	ObservationStart  time.Time                      `json:"observation_start"`
+	Sources           config.ThresholdSourceConfig   `json:"sources"`
+	Manual            config.ManualThresholdConfig   `json:"manual"`
+	Tenants           map[string]EffectiveThresholds `json:"tenants"`
+}
+
+// EffectiveThresholds returns the blast detection thresholds currently applied to each
+// tenant with blast metrics or auto-calculated thresholds
+func (bp *BlastProtector) EffectiveThresholds() ThresholdsReport {
+	bp.mu.RLock()
+	defer bp.mu.RUnlock()
+	bd := bp.blastDetector
+	bd.mu.RLock()
+	defer bd.mu.RUnlock()
+
+	bp.autoConfig.mu.RLock()
+	tenants := make(map[string]bool, len(bd.metrics)+len(bp.autoConfig.tenantThresholds))
+	for tenant := range bp.autoConfig.tenantThresholds {
+		tenants[tenant] = true
+	}
+	observationStart := bp.autoConfig.observationStartTime
+	bp.autoConfig.mu.RUnlock()
+	for tenant := range bd.metrics {
+		tenants[tenant] = true
+	}
+
+	protection := bp.config().CircuitBreaker.BlastProtection
+	report := ThresholdsReport{
+		UseAutoThresholds: protection.UseAutoThresholds,
+		AutoConfigEnabled: bp.config().CircuitBreaker.AutoConfig.Enabled,
+		ObservationStart:  observationStart,
+		Sources:           protection.ThresholdSources,
+		Manual:            protection.ManualThresholds,
+		Tenants:           make(map[string]EffectiveThresholds, len(tenants)),
+	}
+
+	now := bd.now()
+	for tenant := range tenants {
+		report.Tenants[tenant] = bd.effectiveThresholds(tenant, bd.metrics[tenant], now)
+	}
+	return report
+}
+
+// effectiveThresholds resolves the thresholds of a tenant per limit: a tenant override
+// wins, then the auto-calculated threshold if the limit uses auto thresholds and they
+// are warmed up, raised for the seasonal bucket of now, then the manual threshold. The
+// caller must hold bd.mu.
+func (bd *BlastDetector) effectiveThresholds(tenant string, blastMetrics *BlastMetrics, now time.Time) EffectiveThresholds {
+	protection := bd.config().CircuitBreaker.BlastProtection
+	manual := protection.ManualThresholds
+	override := protection.TenantOverrides[tenant]
+
+	var thresholds EffectiveThresholds
+	var auto TenantThresholds
+	useAuto := protection.UseAutoThresholds && bd.config().CircuitBreaker.AutoConfig.Enabled
+	if useAuto {
+		warmUpEnd, observed := bd.warmUpEnd(blastMetrics)
+		if !observed || now.Before(warmUpEnd) {
+			thresholds.WarmingUp = true
+			if observed {
+				thresholds.WarmUpEndsAt = &warmUpEnd
+			}
+		} else {
+			bd.autoConfig.mu.RLock()
+			if calculated, exists := bd.autoConfig.tenantThresholds[tenant]; exists {
+				auto = *calculated
+			}
+			bd.autoConfig.mu.RUnlock()
+
+			ingestion, query, series := bd.seasonalFactors(blastMetrics, now)
+			auto.IngestionThreshold *= ingestion
+			auto.QueryThreshold *= query
+			auto.SeriesThreshold *= series
+		}
+	}
+
+	resolve := func(overrideValue float64, source string, autoValue, manualValue float64) EffectiveThreshold {
+		switch {
+		case overrideValue > 0:
+			return EffectiveThreshold{Value: overrideValue, Source: ThresholdSourceOverride}
+		case useAuto && !thresholds.WarmingUp && source != ThresholdSourceManual && autoValue > 0:
+			return EffectiveThreshold{Value: autoValue, Source: ThresholdSourceAuto}
+		default:
+			// Limits without an applied value have no auto threshold either
+			return EffectiveThreshold{Value: manualValue, Source: ThresholdSourceManual}
+		}
+	}
+
+	sources := protection.ThresholdSources
+	thresholds.Ingestion = resolve(override.IngestionSpikeThreshold, sources.Ingestion, auto.IngestionThreshold, manual.IngestionSpikeThreshold)
+	thresholds.Query = resolve(override.QuerySpikeThreshold, sources.Query, auto.QueryThreshold, manual.QuerySpikeThreshold)
+	thresholds.Series = resolve(override.SeriesSpikeThreshold, sources.Series, auto.SeriesThreshold, manual.SeriesSpikeThreshold)
+	return thresholds
+}
+
+// warmUpEnd returns when the auto thresholds of a tenant are warmed up: once the
+// minimum observation period has passed and a baseline window of its metrics exists.
+// It reports false for a tenant without metrics, which is warming up.
+func (bd *BlastDetector) warmUpEnd(blastMetrics *BlastMetrics) (time.Time, bool) {
+	autoConfig := bd.config().CircuitBreaker.AutoConfig
+	if blastMetrics == nil {
+		return time.Time{}, false
+	}
+
+	bd.autoConfig.mu.RLock()
+	observationEnd := bd.autoConfig.observationStartTime.Add(autoConfig.MinObservationPeriod)
+	bd.autoConfig.mu.RUnlock()
+
+	baselineEnd := blastMetrics.FirstSeen.Add(autoConfig.BaselineWindow)
+	if baselineEnd.After(observationEnd) {
+		return baselineEnd, true
+	}
+	return observationEnd, true
+}
+
+// reportWarmUp exports the number of tenants whose auto thresholds are warming up and
+// logs when it changes. The caller must hold bd.mu.
+func (bd *BlastDetector) reportWarmUp(tenantMetrics map[string]*collector.TenantMetrics) {
+	protection := bd.config().CircuitBreaker.BlastProtection
+	if !protection.UseAutoThresholds || !bd.config().CircuitBreaker.AutoConfig.Enabled {
+		return
+	}
+
+	now := bd.now()
+	warming := 0
+	for tenant := range tenantMetrics {
+		if end, observed := bd.warmUpEnd(bd.metrics[tenant]); !observed || now.Before(end) {
+			warming++
+		}
+	}
+	metrics.CircuitBreakerMetricsInstance.SetThresholdWarmupTenants(warming)
+
+	if warming == bd.warmingUp {
+		return
+	}
+	bd.warmingUp = warming
+	if warming == 0 {
+		bd.log.Info("auto thresholds warmed up, blast detection uses auto-calculated thresholds")
+		return
+	}
+	bd.log.Info("auto thresholds warming up, blast detection uses manual thresholds",
+		"tenants", warming,
+		"min_observation_period", bd.config().CircuitBreaker.AutoConfig.MinObservationPeriod,
+		"baseline_window", bd.config().CircuitBreaker.AutoConfig.BaselineWindow)
+}
@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+)
+
+// NamespaceAnnotationPrefix prefixes the Namespace annotations holding tenant limits
+const NamespaceAnnotationPrefix = "mimir.io/"
+
+// NamespaceAnnotationKey returns the Namespace annotation of a limit, its name with
+// underscores replaced by hyphens under NamespaceAnnotationPrefix
+func NamespaceAnnotationKey(limitName string) string {
+	return NamespaceAnnotationPrefix + strings.ReplaceAll(limitName, "_", "-")
+}
+
+// NamespaceMetricLabelPrefix prefixes the Namespace annotations holding the values of
+// metrics.extraLabels
+const NamespaceMetricLabelPrefix = NamespaceAnnotationPrefix + "metric-label-"
+
+// NamespaceMetricLabelKey returns the Namespace annotation of an extra metric label
+func NamespaceMetricLabelKey(label string) string {
+	return NamespaceMetricLabelPrefix + label
+}
+
+// CollectNamespaceAnnotationLimits reads the limits of namespace-per-tenant deployments
+// from the annotations of the Namespaces matching
+// tenantDiscovery.namespaceLabelSelector, keyed by namespace name as the tenant. Only the
+// known limits are read; annotations whose values do not parse are skipped.
+func CollectNamespaceAnnotationLimits(ctx context.Context, client kubernetes.Interface, cfg *config.Config, log logr.Logger) (map[string]map[string]interface{}, error) {
+	namespaces, err := selectTenantNamespaces(ctx, client, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := cfg.LimitCatalog()
+	tenantLimits := make(map[string]map[string]interface{})
+	for _, namespace := range namespaces {
+		limits := parseNamespaceAnnotations(namespace, definitions, log)
+		if len(limits) > 0 {
+			tenantLimits[namespace.Name] = limits
+		}
+	}
+
+	log.V(1).Info("read namespace annotation limits", "namespaces", len(namespaces), "tenants", len(tenantLimits))
+	return tenantLimits, nil
+}
+
+// CollectNamespaceMetricLabels reads the values of the given metric labels from the
+// mimir.io/metric-label-<name> annotations of the Namespaces matching
+// tenantDiscovery.namespaceLabelSelector, keyed by namespace name as the tenant
+func CollectNamespaceMetricLabels(ctx context.Context, client kubernetes.Interface, cfg *config.Config, labelNames []string, log logr.Logger) (map[string]map[string]string, error) {
+	namespaces, err := selectTenantNamespaces(ctx, client, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantLabels := make(map[string]map[string]string)
+	for _, namespace := range namespaces {
+		values := make(map[string]string)
+		for _, label := range labelNames {
+			if value := strings.TrimSpace(namespace.Annotations[NamespaceMetricLabelKey(label)]); value != "" {
+				values[label] = value
+			}
+		}
+		if len(values) > 0 {
+			tenantLabels[namespace.Name] = values
+		}
+	}
+	return tenantLabels, nil
+}
+
+// selectTenantNamespaces returns the scanned Namespaces matching
+// tenantDiscovery.namespaceLabelSelector
+func selectTenantNamespaces(ctx context.Context, client kubernetes.Interface, cfg *config.Config, log logr.Logger) ([]corev1.Namespace, error) {
+	selector := labels.Everything()
+	if expression := cfg.MetricsDiscovery.TenantDiscovery.NamespaceLabelSelector; expression != "" {
+		parsed, err := labels.Parse(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenantDiscovery.namespaceLabelSelector %q: %w", expression, err)
+		}
+		selector = parsed
+	}
+
+	namespaces, _, err := discovery.ScanNamespaces(ctx, client, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]corev1.Namespace, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		if selector.Matches(labels.Set(namespace.Labels)) {
+			selected = append(selected, namespace)
+		}
+	}
+	return selected, nil
+}
+
+// parseNamespaceAnnotations returns the known limits annotated on a Namespace
+func parseNamespaceAnnotations(namespace corev1.Namespace, definitions map[string]config.LimitDefinition, log logr.Logger) map[string]interface{} {
+	limits := make(map[string]interface{})
+	for limitName, definition := range definitions {
+		raw, exists := namespace.Annotations[NamespaceAnnotationKey(limitName)]
+		if !exists {
+			continue
+		}
+		value, err := parseLimitValue(definition, strings.TrimSpace(raw))
+		if err != nil {
+			log.Info("skipping invalid limit annotation",
+				"namespace", namespace.Name,
+				"annotation", NamespaceAnnotationKey(limitName),
+				"error", err.Error())
+			continue
+		}
+		limits[limitName] = value
+	}
+	return limits
+}
+
+// parseLimitValue parses an annotation value like the analyzer calculates the limit:
+// numeric limits as float64 and durations as time.Duration
+func parseLimitValue(definition config.LimitDefinition, raw string) (interface{}, error) {
+	switch definition.Type {
+	case "rate", "count", "size", "percentage":
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number: %w", definition.Name, err)
+		}
+		return value, nil
+	case "duration":
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a duration: %w", definition.Name, err)
+		}
+		return value, nil
+	case "bool":
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false: %w", definition.Name, err)
+		}
+		return value, nil
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func tenantNamespace(name string, labels, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+}
+
+// annotatedNamespaces are two tenant namespaces and one without limit annotations
+func annotatedNamespaces() *fake.Clientset {
+	tenant := map[string]string{"mimir.io/tenant": "true"}
+	return fake.NewSimpleClientset(
+		tenantNamespace("team-a", tenant, map[string]string{
+			"mimir.io/ingestion-rate":             "25000",
+			"mimir.io/max-global-series-per-user": " 150000 ",
+			"mimir.io/max-query-lookback":         "720h",
+			"mimir.io/not-a-limit":                "1",
+			"kubectl.kubernetes.io/last-applied":  "{}",
+		}),
+		tenantNamespace("team-b", map[string]string{"mimir.io/tenant": "true", "tier": "gold"}, map[string]string{
+			"mimir.io/ingestion-burst-size": "400000",
+			// Unparseable values are skipped
+			"mimir.io/ingestion-rate": "fast",
+		}),
+		tenantNamespace("kube-system", nil, map[string]string{"scheduler.alpha.kubernetes.io/node-selector": "x"}),
+	)
+}
+
+func TestCollectNamespaceAnnotationLimits(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	limits, err := CollectNamespaceAnnotationLimits(context.Background(), annotatedNamespaces(), cfg, logr.Discard())
+	if err != nil {
+		t.Fatalf("CollectNamespaceAnnotationLimits: %v", err)
+	}
+
+	want := map[string]map[string]interface{}{
+		"team-a": {
+			"ingestion_rate":             25000.0,
+			"max_global_series_per_user": 150000.0,
+			"max_query_lookback":         720 * time.Hour,
+		},
+		"team-b": {"ingestion_burst_size": 400000.0},
+	}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("namespace annotation limits = %v, want %v", limits, want)
+	}
+}
+
+func TestCollectNamespaceAnnotationLimitsSelector(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.MetricsDiscovery.TenantDiscovery.NamespaceLabelSelector = "mimir.io/tenant=true,tier=gold"
+	limits, err := CollectNamespaceAnnotationLimits(context.Background(), annotatedNamespaces(), cfg, logr.Discard())
+	if err != nil {
+		t.Fatalf("CollectNamespaceAnnotationLimits: %v", err)
+	}
+	if len(limits) != 1 || limits["team-b"] == nil {
+		t.Errorf("limits of namespaces matching the selector = %v, want only team-b", limits)
+	}
+
+	cfg.MetricsDiscovery.TenantDiscovery.NamespaceLabelSelector = "tier in (gold"
+	if _, err := CollectNamespaceAnnotationLimits(context.Background(), annotatedNamespaces(), cfg, logr.Discard()); err == nil {
+		t.Errorf("invalid label selector accepted")
+	}
+}
+
+func TestCollectNamespaceMetricLabels(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.MetricsDiscovery.TenantDiscovery.NamespaceLabelSelector = "mimir.io/tenant=true"
+	tenant := map[string]string{"mimir.io/tenant": "true"}
+	client := fake.NewSimpleClientset(
+		tenantNamespace("team-a", tenant, map[string]string{
+			"mimir.io/metric-label-env":  " prod ",
+			"mimir.io/metric-label-team": "payments",
+		}),
+		tenantNamespace("team-b", tenant, map[string]string{"mimir.io/metric-label-env": ""}),
+		tenantNamespace("kube-system", nil, map[string]string{"mimir.io/metric-label-env": "infra"}),
+	)
+
+	labels, err := CollectNamespaceMetricLabels(context.Background(), client, cfg, []string{"env"}, logr.Discard())
+	if err != nil {
+		t.Fatalf("CollectNamespaceMetricLabels: %v", err)
+	}
+	// Only the requested labels of the selected namespaces with values are read
+	want := map[string]map[string]string{"team-a": {"env": "prod"}}
+	if !reflect.DeepEqual(labels, want) {
+		t.Errorf("namespace metric labels = %v, want %v", labels, want)
+	}
+}
+
+func TestNamespaceAnnotationKey(t *testing.T) {
+	if got := NamespaceAnnotationKey("max_global_series_per_user"); got != "mimir.io/max-global-series-per-user" {
+		t.Errorf("NamespaceAnnotationKey = %s, want mimir.io/max-global-series-per-user", got)
+	}
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/cache"
+)
+
+const (
+	// tenantMetricsCacheKey is the cache key of the last collected tenant metrics
+	tenantMetricsCacheKey = "collector:tenant-metrics"
+
+	// tenantListCacheKey is the cache key of the last discovered tenant list
+	tenantListCacheKey = "collector:tenant-list"
+)
+
+// bypassCacheKey marks contexts whose collections skip the cache
+type bypassCacheKey struct{}
+
+// WithCacheBypass returns a context whose collections through a CachedCollector skip
+// the cached result and replace it with a fresh one
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// cacheBypassed reports whether the context asks to skip the cache
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// CachedCollector serves collected tenant metrics and discovered tenants from a cache
+// shared between replicas, so only one replica pays for a collection within the TTL
+type CachedCollector struct {
+	Collector
+	cache cache.Cache
+	ttl   time.Duration
+	log   logr.Logger
+}
+
+// NewCachedCollector wraps a collector with a cache. The TTL is capped at half the
+// update interval so that every reconcile still sees metrics collected since the
+// previous one.
+func NewCachedCollector(inner Collector, c cache.Cache, ttl, updateInterval time.Duration, log logr.Logger) *CachedCollector {
+	if updateInterval > 0 && (ttl <= 0 || ttl > updateInterval/2) {
+		ttl = updateInterval / 2
+	}
+	return &CachedCollector{
+		Collector: inner,
+		cache:     c,
+		ttl:       ttl,
+		log:       log,
+	}
+}
+
+// CollectMetrics returns cached tenant metrics when present, collecting and caching
+// them otherwise. Cache errors never fail the collection.
+func (c *CachedCollector) CollectMetrics(ctx context.Context) (map[string]*TenantMetrics, error) {
+	if !cacheBypassed(ctx) {
+		var cached map[string]*TenantMetrics
+		found, err := c.cache.Get(ctx, tenantMetricsCacheKey, &cached)
+		if err != nil {
+			c.log.V(1).Info("failed to read cached tenant metrics", "backend", c.cache.Name(), "error", err.Error())
+		}
+		if found {
+			c.log.V(1).Info("using cached tenant metrics", "backend", c.cache.Name(), "tenants", len(cached))
+			return cached, nil
+		}
+	}
+
+	tenantMetrics, err := c.Collector.CollectMetrics(ctx)
+	if err != nil {
+		// Partial results are returned but not cached, so the next call retries the failed sources
+		if IsPartialFailure(err) {
+			return tenantMetrics, err
+		}
+		return nil, err
+	}
+
+	if err := c.cache.Set(ctx, tenantMetricsCacheKey, tenantMetrics, c.ttl); err != nil {
+		c.log.V(1).Info("failed to cache tenant metrics", "backend", c.cache.Name(), "error", err.Error())
+	}
+
+	return tenantMetrics, nil
+}
+
+// GetTenantList returns the cached tenant list when present, discovering and caching
+// it otherwise, so repeated API requests within the TTL discover tenants once. Cache
+// errors never fail the discovery.
+func (c *CachedCollector) GetTenantList(ctx context.Context) ([]string, error) {
+	if !cacheBypassed(ctx) {
+		var cached []string
+		found, err := c.cache.Get(ctx, tenantListCacheKey, &cached)
+		if err != nil {
+			c.log.V(1).Info("failed to read cached tenant list", "backend", c.cache.Name(), "error", err.Error())
+		}
+		if found {
+			c.log.V(1).Info("using cached tenant list", "backend", c.cache.Name(), "tenants", len(cached))
+			return cached, nil
+		}
+	}
+
+	tenants, err := c.Collector.GetTenantList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cache.Set(ctx, tenantListCacheKey, tenants, c.ttl); err != nil {
+		c.log.V(1).Info("failed to cache tenant list", "backend", c.cache.Name(), "error", err.Error())
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,147 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/cache"
+)
+
+// countingCollector discovers a fixed tenant list and counts the discoveries
+type countingCollector struct {
+	mu          sync.Mutex
+	discoveries int
+	tenants     []string
+	err         error
+}
+
+func (c *countingCollector) CollectMetrics(ctx context.Context) (map[string]*TenantMetrics, error) {
+	return map[string]*TenantMetrics{}, nil
+}
+
+func (c *countingCollector) GetTenantList(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.discoveries++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return append([]string(nil), c.tenants...), nil
+}
+
+func (c *countingCollector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.discoveries
+}
+
+// failingCache fails every read and write
+type failingCache struct{}
+
+func (failingCache) Name() string { return "failing" }
+
+func (failingCache) Get(ctx context.Context, key string, dest interface{}) (bool, error) {
+	return false, errors.New("cache unavailable")
+}
+
+func (failingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return errors.New("cache unavailable")
+}
+
+func (failingCache) Delete(ctx context.Context, key string) error {
+	return errors.New("cache unavailable")
+}
+
+func newCountingCachedCollector(ttl time.Duration) (*CachedCollector, *countingCollector) {
+	inner := &countingCollector{tenants: []string{"tenant-a", "tenant-b"}}
+	return NewCachedCollector(inner, cache.NewMemoryCache(time.Minute, 1), ttl, 0, logr.Discard()), inner
+}
+
+func TestCachedTenantListDiscoversOncePerTTL(t *testing.T) {
+	c, inner := newCountingCachedCollector(time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		tenants, err := c.GetTenantList(context.Background())
+		if err != nil {
+			t.Fatalf("GetTenantList: %v", err)
+		}
+		if !reflect.DeepEqual(tenants, inner.tenants) {
+			t.Errorf("call %d tenants = %v, want %v", i+1, tenants, inner.tenants)
+		}
+	}
+	// Concurrent API requests share the cached list as well
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetTenantList(context.Background()); err != nil {
+				t.Errorf("GetTenantList: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := inner.count(); got != 1 {
+		t.Errorf("20 calls within the TTL discovered tenants %d times, want once", got)
+	}
+}
+
+func TestCachedTenantListBypass(t *testing.T) {
+	c, inner := newCountingCachedCollector(time.Minute)
+	ctx := context.Background()
+	c.GetTenantList(ctx)
+
+	inner.mu.Lock()
+	inner.tenants = append(inner.tenants, "tenant-c")
+	inner.mu.Unlock()
+
+	tenants, err := c.GetTenantList(WithCacheBypass(ctx))
+	if err != nil {
+		t.Fatalf("GetTenantList: %v", err)
+	}
+	if len(tenants) != 3 || inner.count() != 2 {
+		t.Errorf("bypassing the cache returned %v after %d discoveries, want the 3 tenants rediscovered", tenants, inner.count())
+	}
+
+	// The fresh list replaces the cached one
+	if tenants, _ := c.GetTenantList(ctx); len(tenants) != 3 || inner.count() != 2 {
+		t.Errorf("call after the bypass returned %v after %d discoveries, want the refreshed list from the cache", tenants, inner.count())
+	}
+}
+
+func TestCachedTenantListExpires(t *testing.T) {
+	c, inner := newCountingCachedCollector(10 * time.Millisecond)
+	c.GetTenantList(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	c.GetTenantList(context.Background())
+
+	if got := inner.count(); got != 2 {
+		t.Errorf("discoveries after the TTL expired = %d, want 2", got)
+	}
+}
+
+func TestCachedTenantListErrors(t *testing.T) {
+	inner := &countingCollector{tenants: []string{"tenant-a"}}
+	c := NewCachedCollector(inner, failingCache{}, time.Minute, 0, logr.Discard())
+	if tenants, err := c.GetTenantList(context.Background()); err != nil || len(tenants) != 1 {
+		t.Errorf("GetTenantList with a failing cache = %v, %v; want the discovered tenants", tenants, err)
+	}
+
+	// Failed discoveries are not cached
+	c, inner = newCountingCachedCollector(time.Minute)
+	inner.err = errors.New("prometheus rate limited")
+	if _, err := c.GetTenantList(context.Background()); err == nil {
+		t.Fatalf("GetTenantList returned no error for a failed discovery")
+	}
+	inner.err = nil
+	if tenants, err := c.GetTenantList(context.Background()); err != nil || len(tenants) != 2 || inner.count() != 2 {
+		t.Errorf("GetTenantList after a failed discovery = %v, %v after %d discoveries; want discovered again", tenants, err, inner.count())
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"encoding/json"
@@ -47,7 +50,7 @@ type Collector interface {
 
 // MimirCollector implements the Collector interface for Mimir/Prometheus
 type MimirCollector struct {
-	config      *config.Config
+	live        *config.Live
 	client      kubernetes.Interface
 	discovery   *discovery.ServiceDiscovery
 	httpClient  *http.Client
@@ -55,11 +58,11 @@ type MimirCollector struct {
 }
 
 // NewMimirCollector creates a new MimirCollector
-func NewMimirCollector(cfg *config.Config, client kubernetes.Interface, log logr.Logger) *MimirCollector {
+func NewMimirCollector(live *config.Live, client kubernetes.Interface, log logr.Logger) *MimirCollector {
 	return &MimirCollector{
-		config: cfg,
+		live:   live,
 		client: client,
-		discovery: discovery.NewServiceDiscovery(client, cfg, log.WithName("discovery")),
+		discovery: discovery.NewServiceDiscovery(client, live, log.WithName("discovery")),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -67,20 +70,25 @@ func NewMimirCollector(cfg *config.Config, client kubernetes.Interface, log logr
 	}
 }
 
-// CollectMetrics collects metrics from all configured sources
+// config returns the configuration in effect
+func (c *MimirCollector) config() *config.Config {
+	return c.live.Load()
+}
+
+// CollectMetrics collects metrics from all configured sources. When some sources fail,
+// the metrics of the others are returned with a *PartialCollectionError.
 func (c *MimirCollector) CollectMetrics(ctx context.Context) (map[string]*TenantMetrics, error) {
 	startTime := time.Now()
-	tenantMetrics := make(map[string]*TenantMetrics)
 	
 	var sources []string
 	
 	// Add primary metrics endpoint if configured
-	if c.config.MetricsEndpoint != "" {
-		sources = append(sources, c.config.MetricsEndpoint)
+	if c.config().MetricsEndpoint != "" {
+		sources = append(sources, c.config().MetricsEndpoint)
 	}
 	
 	// Add discovered services if auto-discovery is enabled
-	if c.config.MetricsDiscovery.Enabled {
+	if c.config().MetricsDiscovery.Enabled {
 		c.log.V(1).Info("auto-discovery enabled, discovering metrics endpoints")
 		discoveredSources, err := c.discovery.DiscoverMetricsEndpoints(ctx)
 		if err != nil {
@@ -99,36 +107,27 @@ func (c *MimirCollector) CollectMetrics(ctx context.Context) (map[string]*Tenant
 		return nil, fmt.Errorf("no metrics sources configured")
 	}
 	
-	// Collect from all sources
-	for _, source := range sources {
-		sourceMetrics, err := c.collectFromSource(ctx, source)
-		if err != nil {
-			c.log.Error(err, "failed to collect from source", "source", source)
-			metrics.CollectionMetricsInstance.IncMetricsCollectionTotal(source, "error")
-			continue
-		}
-		
-		// Merge metrics
-		for tenant, tm := range sourceMetrics {
+	// Collect from all sources concurrently
+	tenantMetrics, err := c.collectSources(ctx, sources)
+
+	// Add the per-query usage of the query-path limits
+	if c.config().TrendAnalysis.QueryPath.Enabled {
+		for tenant, queryPathMetrics := range c.collectQueryPath(ctx) {
 			if existing, exists := tenantMetrics[tenant]; exists {
-				c.mergeMetrics(existing, tm)
-			} else {
-				tenantMetrics[tenant] = tm
+				c.mergeMetrics(existing, queryPathMetrics)
+				continue
+			}
+			if tenantMetrics == nil {
+				tenantMetrics = make(map[string]*TenantMetrics)
 			}
+			tenantMetrics[tenant] = queryPathMetrics
 		}
-		
-		metrics.CollectionMetricsInstance.IncMetricsCollectionTotal(source, "success")
-		metrics.CollectionMetricsInstance.SetLastMetricsCollectionTime(source, float64(time.Now().Unix()))
 	}
 	
 	duration := time.Since(startTime).Seconds()
-	for _, source := range sources {
-		metrics.CollectionMetricsInstance.ObserveMetricsCollectionDuration(source, duration)
-	}
-	
 	c.log.Info("collected metrics", "tenants", len(tenantMetrics), "sources", len(sources), "duration", duration)
 	
-	return tenantMetrics, nil
+	return tenantMetrics, err
 }
 
 // collectFromSource collects metrics from a single source
@@ -213,6 +212,10 @@ func (c *MimirCollector) collectFromSource(ctx context.Context, source string) (
 			}
 			
 			value := c.extractValue(metric)
+			if metric.Histogram != nil && (isDurationMetric(name) || c.isQueryPathHistogram(name)) {
+				// Duration and per-query limits are driven by quantiles, not sample counts
+				value = histogramQuantile(metric.Histogram, c.config().TrendAnalysis.Percentile/100)
+			}
 			labels := c.extractLabels(metric.Label)
 			
 			metricData := MetricData{
@@ -236,7 +239,7 @@ func (c *MimirCollector) collectFromSource(ctx context.Context, source string) (
 func (c *MimirCollector) GetTenantList(ctx context.Context) ([]string, error) {
 	// Try metrics-based discovery first
 	tenantMetrics, err := c.CollectMetrics(ctx)
-	if err == nil {
+	if err == nil || IsPartialFailure(err) {
 		tenants := make([]string, 0, len(tenantMetrics))
 		for tenant := range tenantMetrics {
 			tenants = append(tenants, tenant)
@@ -253,13 +256,13 @@ func (c *MimirCollector) GetTenantList(ctx context.Context) ([]string, error) {
 // getTenantListFromConfigMap discovers tenants from Mimir runtime overrides ConfigMap
 func (c *MimirCollector) getTenantListFromConfigMap(ctx context.Context) ([]string, error) {
 	// First try predefined fallback tenants from configuration
-	if len(c.config.MetricsDiscovery.TenantDiscovery.FallbackTenants) > 0 {
-		c.log.Info("using configured fallback tenants", "count", len(c.config.MetricsDiscovery.TenantDiscovery.FallbackTenants))
-		return c.config.MetricsDiscovery.TenantDiscovery.FallbackTenants, nil
+	if len(c.config().MetricsDiscovery.TenantDiscovery.FallbackTenants) > 0 {
+		c.log.Info("using configured fallback tenants", "count", len(c.config().MetricsDiscovery.TenantDiscovery.FallbackTenants))
+		return c.config().MetricsDiscovery.TenantDiscovery.FallbackTenants, nil
 	}
 	
 	// Try configured ConfigMap names
-	configMapNames := c.config.MetricsDiscovery.TenantDiscovery.ConfigMapNames
+	configMapNames := c.config().MetricsDiscovery.TenantDiscovery.ConfigMapNames
 	if len(configMapNames) == 0 {
 		// Default ConfigMap names to try
 		configMapNames = []string{"overrides", "mimir-runtime-overrides", "runtime-config"}
@@ -269,7 +272,7 @@ func (c *MimirCollector) getTenantListFromConfigMap(ctx context.Context) ([]stri
 	var err error
 	
 	for _, cmName := range configMapNames {
-		configMap, err = c.client.CoreV1().ConfigMaps(c.config.Mimir.Namespace).Get(ctx, cmName, metav1.GetOptions{})
+		configMap, err = c.client.CoreV1().ConfigMaps(c.config().Mimir.Namespace).Get(ctx, cmName, metav1.GetOptions{})
 		if err == nil {
 			c.log.Info("found tenant configuration in ConfigMap", "configMap", cmName)
 			break
@@ -279,7 +282,7 @@ func (c *MimirCollector) getTenantListFromConfigMap(ctx context.Context) ([]stri
 	
 	if err != nil {
 		// Try synthetic tenants if enabled
-		if c.config.MetricsDiscovery.TenantDiscovery.EnableSynthetic {
+		if c.config().MetricsDiscovery.TenantDiscovery.EnableSynthetic {
 			return c.generateSyntheticTenants(), nil
 		}
 		return nil, fmt.Errorf("failed to discover tenants: metrics collection failed and ConfigMap fallback failed: %w", err)
@@ -335,7 +338,7 @@ func (c *MimirCollector) getTenantListFromConfigMap(ctx context.Context) ([]stri
 
 // generateSyntheticTenants creates synthetic tenant IDs for testing
 func (c *MimirCollector) generateSyntheticTenants() []string {
-	count := c.config.MetricsDiscovery.TenantDiscovery.SyntheticCount
+	count := c.config().MetricsDiscovery.TenantDiscovery.SyntheticCount
 	if count <= 0 {
 		count = 3 // Default to 3 synthetic tenants
 	}
@@ -352,15 +355,15 @@ func (c *MimirCollector) generateSyntheticTenants() []string {
 // addTenantHeaders adds tenant-specific headers for multi-tenant Mimir access
 func (c *MimirCollector) addTenantHeaders(req *http.Request) {
 	// Add primary tenant ID if configured
-	if c.config.MetricsDiscovery.TenantDiscovery.MetricsTenantID != "" {
-		req.Header.Set("X-Scope-OrgID", c.config.MetricsDiscovery.TenantDiscovery.MetricsTenantID)
-		c.log.V(1).Info("added tenant header", "tenant", c.config.MetricsDiscovery.TenantDiscovery.MetricsTenantID)
+	if c.config().MetricsDiscovery.TenantDiscovery.MetricsTenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.config().MetricsDiscovery.TenantDiscovery.MetricsTenantID)
+		c.log.V(1).Info("added tenant header", "tenant", c.config().MetricsDiscovery.TenantDiscovery.MetricsTenantID)
 	} else {
 		c.log.V(1).Info("no tenant ID configured - will query without tenant scoping")
 	}
 	
 	// Add any additional custom headers
-	for key, value := range c.config.MetricsDiscovery.TenantDiscovery.TenantHeaders {
+	for key, value := range c.config().MetricsDiscovery.TenantDiscovery.TenantHeaders {
 		req.Header.Set(key, value)
 		c.log.V(1).Info("added custom tenant header", "header", key, "value", value)
 	}
@@ -466,6 +469,47 @@ func (c *MimirCollector) extractValue(metric *dto.Metric) float64 {
 	return 0
 }
 
+// isDurationMetric reports whether a metric measures latency in seconds
+func isDurationMetric(metricName string) bool {
+	return strings.HasSuffix(metricName, "_duration_seconds")
+}
+
+// histogramQuantile estimates the q-quantile (0-1) of a histogram by linear
+// interpolation within the bucket containing the target rank, mirroring
+// PromQL's histogram_quantile. The result is in the histogram's unit (seconds).
+func histogramQuantile(h *dto.Histogram, q float64) float64 {
+	total := float64(h.GetSampleCount())
+	if total == 0 || len(h.GetBucket()) == 0 {
+		return 0
+	}
+
+	buckets := make([]*dto.Bucket, len(h.GetBucket()))
+	copy(buckets, h.GetBucket())
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].GetUpperBound() < buckets[j].GetUpperBound()
+	})
+
+	rank := q * total
+	lowerBound, lowerCount := 0.0, 0.0
+	for _, b := range buckets {
+		upperBound := b.GetUpperBound()
+		count := float64(b.GetCumulativeCount())
+		if count >= rank {
+			if math.IsInf(upperBound, 1) {
+				// Quantile falls in the +Inf bucket; the best we can report is the highest finite bound
+				return lowerBound
+			}
+			if count == lowerCount {
+				return upperBound
+			}
+			return lowerBound + (upperBound-lowerBound)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lowerBound, lowerCount = upperBound, count
+	}
+
+	return lowerBound
+}
+
 // extractLabels extracts all labels from a metric
 func (c *MimirCollector) extractLabels(labels []*dto.LabelPair) map[string]string {
 	result := make(map[string]string)
@@ -485,80 +529,12 @@ func (c *MimirCollector) mergeMetrics(existing, new *TenantMetrics) {
 	}
 }
 
-// SyntheticCollector implements synthetic metrics for testing
-type SyntheticCollector struct {
-	config *config.Config
-	log    logr.Logger
-}
-
-// NewSyntheticCollector creates a new synthetic collector
-func NewSyntheticCollector(cfg *config.Config, log logr.Logger) *SyntheticCollector {
-	return &SyntheticCollector{
-		config: cfg,
-		log:    log,
-	}
-}
-
-// CollectMetrics generates synthetic metrics for testing
-func (s *SyntheticCollector) CollectMetrics(ctx context.Context) (map[string]*TenantMetrics, error) {
-	tenantMetrics := make(map[string]*TenantMetrics)
-	
-	for i := 0; i < s.config.Synthetic.TenantCount; i++ {
-		tenant := fmt.Sprintf("synthetic-tenant-%d", i)
-		
-		tm := &TenantMetrics{
-			Tenant:     tenant,
-			Metrics:    make(map[string][]MetricData),
-			LastUpdate: time.Now(),
-		}
-		
-		// Generate synthetic ingestion metrics
-		tm.Metrics["cortex_distributor_received_samples_total"] = []MetricData{
-			{
-				Tenant:     tenant,
-				MetricName: "cortex_distributor_received_samples_total",
-				Value:      float64(1000 + i*500), // Varying sample rates
-				Timestamp:  time.Now(),
-				Labels:     map[string]string{"user": tenant},
-				Source:     "synthetic",
-			},
-		}
-		
-		// Generate synthetic series metrics
-		tm.Metrics["cortex_ingester_memory_series"] = []MetricData{
-			{
-				Tenant:     tenant,
-				MetricName: "cortex_ingester_memory_series",
-				Value:      float64(10000 + i*2000), // Varying series counts
-				Timestamp:  time.Now(),
-				Labels:     map[string]string{"user": tenant},
-				Source:     "synthetic",
-			},
-		}
-		
-		tenantMetrics[tenant] = tm
-	}
-	
-	s.log.Info("generated synthetic metrics", "tenants", len(tenantMetrics))
-	
-	return tenantMetrics, nil
-}
-
-// GetTenantList returns synthetic tenant list
-func (s *SyntheticCollector) GetTenantList(ctx context.Context) ([]string, error) {
-	tenants := make([]string, s.config.Synthetic.TenantCount)
-	for i := 0; i < s.config.Synthetic.TenantCount; i++ {
-		tenants[i] = fmt.Sprintf("synthetic-tenant-%d", i)
-	}
-	return tenants, nil
-}
-
 // NewCollector creates the appropriate collector based on configuration
-func NewCollector(cfg *config.Config, client kubernetes.Interface, log logr.Logger) Collector {
-	if cfg.Synthetic.Enabled {
-		return NewSyntheticCollector(cfg, log.WithName("synthetic"))
+func NewCollector(live *config.Live, client kubernetes.Interface, log logr.Logger) Collector {
+	if live.Load().Synthetic.Enabled {
+		return NewSyntheticCollector(live, log.WithName("synthetic"))
 	}
-	return NewMimirCollector(cfg, client, log.WithName("mimir"))
+	return NewMimirCollector(live, client, log.WithName("mimir"))
 }
 
 // PromQLResult represents a PromQL query result
@@ -576,20 +552,28 @@ type PromQLResult struct {
 
 // QueryHistoricalData queries historical metrics using PromQL
 func (c *MimirCollector) QueryHistoricalData(ctx context.Context, query string, startTime, endTime time.Time, step time.Duration) ([]MetricData, error) {
-	if c.config.MetricsEndpoint == "" {
+	if c.config().MetricsEndpoint == "" {
 		return nil, fmt.Errorf("no metrics endpoint configured for PromQL queries")
 	}
 
-	// Build PromQL query URL
-	baseURL := strings.TrimSuffix(c.config.MetricsEndpoint, "/metrics")
-	queryURL := fmt.Sprintf("%s/api/v1/query_range", baseURL)
-
 	params := url.Values{}
 	params.Set("query", query)
 	params.Set("start", fmt.Sprintf("%d", startTime.Unix()))
 	params.Set("end", fmt.Sprintf("%d", endTime.Unix()))
 	params.Set("step", fmt.Sprintf("%.0fs", step.Seconds()))
 
+	return c.queryPromQL(ctx, "query_range", extractMetricName(query), params)
+}
+
+// queryPromQL runs a PromQL query against the Prometheus API of the metrics endpoint,
+// returning its samples under metricName
+func (c *MimirCollector) queryPromQL(ctx context.Context, api, metricName string, params url.Values) ([]MetricData, error) {
+	query := params.Get("query")
+
+	// Build PromQL query URL
+	baseURL := strings.TrimSuffix(c.config().MetricsEndpoint, "/metrics")
+	queryURL := fmt.Sprintf("%s/api/v1/%s", baseURL, api)
+
 	fullURL := fmt.Sprintf("%s?%s", queryURL, params.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -603,9 +587,9 @@ func (c *MimirCollector) QueryHistoricalData(ctx context.Context, query string,
 	c.log.V(1).Info("executing PromQL query",
 		"url", fullURL,
 		"query", query,
-		"start", startTime,
-		"end", endTime,
-		"step", step)
+		"start", params.Get("start"),
+		"end", params.Get("end"),
+		"step", params.Get("step"))
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -646,7 +630,7 @@ func (c *MimirCollector) QueryHistoricalData(ctx context.Context, query string,
 
 					metricData = append(metricData, MetricData{
 						Tenant:     tenant,
-						MetricName: extractMetricName(query),
+						MetricName: metricName,
 						Value:      value,
 						Timestamp:  timestamp,
 						Labels:     series.Metric,
@@ -666,7 +650,7 @@ func (c *MimirCollector) QueryHistoricalData(ctx context.Context, query string,
 
 			metricData = append(metricData, MetricData{
 				Tenant:     tenant,
-				MetricName: extractMetricName(query),
+				MetricName: metricName,
 				Value:      value,
 				Timestamp:  timestamp,
 				Labels:     series.Metric,
@@ -690,7 +674,8 @@ func parseFloat(val interface{}) (float64, error) {
 		if v == "NaN" || v == "+Inf" || v == "-Inf" {
 			return 0, fmt.Errorf("invalid float value: %s", v)
 		}
-		return 0, fmt.Errorf("unexpected string value: %s", v)
+		// The Prometheus API encodes sample values as strings
+		return strconv.ParseFloat(v, 64)
 	case float64:
 		return v, nil
 	default:
@@ -719,6 +704,11 @@ func countUniqueTenants(data []MetricData) int {
 
 // GetHistoricalTrendData fetches sophisticated historical data for trend analysis
 func (c *MimirCollector) GetHistoricalTrendData(ctx context.Context, tenant string, limitName string, analysisWindow time.Duration) ([]MetricData, error) {
+	// Disabled limits are not optimized, so their usage is not queried
+	if def, exists := c.config().DynamicLimits.LimitDefinitions[limitName]; exists && !def.Enabled {
+		return nil, fmt.Errorf("limit %s is disabled", limitName)
+	}
+
 	// Get metric name for this limit
 	metricMapping := c.getMetricMappingForLimits()
 	metricName, exists := metricMapping[limitName]
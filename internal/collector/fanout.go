@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// allSources is the source label of the duration of a whole collection
+const allSources = "all"
+
+// SourceError is a failed collection from one metrics source
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+// PartialCollectionError reports the sources that failed during a collection. The
+// metrics of the other sources are still returned alongside it.
+type PartialCollectionError struct {
+	Failed  []SourceError
+	Sources int
+}
+
+func (e *PartialCollectionError) Error() string {
+	failures := make([]string, 0, len(e.Failed))
+	for _, failed := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%s: %v", failed.Source, failed.Err))
+	}
+	return fmt.Sprintf("%d of %d metrics sources failed: %s", len(e.Failed), e.Sources, strings.Join(failures, "; "))
+}
+
+// AllFailed reports whether no source could be collected
+func (e *PartialCollectionError) AllFailed() bool {
+	return len(e.Failed) >= e.Sources
+}
+
+// collectionConcurrency is the number of sources collected at once, bounded by
+// performance.batchProcessing.maxConcurrent
+func (c *MimirCollector) collectionConcurrency() int {
+	if batch := c.config().Performance.BatchProcessing; c.config().Performance.Enabled && batch.Enabled && batch.MaxConcurrent > 0 {
+		return batch.MaxConcurrent
+	}
+	return 1
+}
+
+// collectSources scrapes the sources concurrently and merges their tenant metrics in
+// source order. Failed sources are returned as a PartialCollectionError and do not
+// fail the others.
+func (c *MimirCollector) collectSources(ctx context.Context, sources []string) (map[string]*TenantMetrics, error) {
+	startTime := time.Now()
+	semaphore := make(chan struct{}, c.collectionConcurrency())
+
+	var results sync.Map
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results.Store(source, ctx.Err())
+				return
+			}
+
+			sourceStart := time.Now()
+			sourceMetrics, err := c.collectFromSource(ctx, source)
+			metrics.CollectionMetricsInstance.ObserveMetricsCollectionDuration(source, time.Since(sourceStart).Seconds())
+			if err != nil {
+				results.Store(source, err)
+				return
+			}
+			results.Store(source, sourceMetrics)
+		}(source)
+	}
+	wg.Wait()
+
+	tenantMetrics := make(map[string]*TenantMetrics)
+	var failed []SourceError
+	for _, source := range sources {
+		result, _ := results.Load(source)
+		sourceMetrics, ok := result.(map[string]*TenantMetrics)
+		if !ok {
+			err, _ := result.(error)
+			c.log.Error(err, "failed to collect from source", "source", source)
+			metrics.CollectionMetricsInstance.IncMetricsCollectionTotal(source, "error")
+			failed = append(failed, SourceError{Source: source, Err: err})
+			continue
+		}
+
+		for tenant, tm := range sourceMetrics {
+			if existing, exists := tenantMetrics[tenant]; exists {
+				c.mergeMetrics(existing, tm)
+			} else {
+				tenantMetrics[tenant] = tm
+			}
+		}
+
+		metrics.CollectionMetricsInstance.IncMetricsCollectionTotal(source, "success")
+		metrics.CollectionMetricsInstance.SetLastMetricsCollectionTime(source, float64(time.Now().Unix()))
+	}
+
+	metrics.CollectionMetricsInstance.ObserveMetricsCollectionDuration(allSources, time.Since(startTime).Seconds())
+
+	if len(failed) > 0 {
+		return tenantMetrics, &PartialCollectionError{Failed: failed, Sources: len(sources)}
+	}
+	return tenantMetrics, nil
+}
+
+// IsPartialFailure reports whether err is a PartialCollectionError with at least one
+// source collected, so the metrics returned with it are usable
+func IsPartialFailure(err error) bool {
+	var partial *PartialCollectionError
+	return errors.As(err, &partial) && !partial.AllFailed()
+}
@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// slowSources serves one tenant's samples per path after a fixed latency and records
+// the highest number of requests in flight at once
+type slowSources struct {
+	latency time.Duration
+	failing map[string]bool
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (s *slowSources) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	time.Sleep(s.latency)
+	tenant := strings.TrimPrefix(r.URL.Path, "/")
+	if s.failing[tenant] {
+		http.Error(w, "scrape failed", http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprintf(w, "cortex_distributor_received_samples_total{user=%q} 100\n", tenant)
+}
+
+func (s *slowSources) peakConcurrency() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.maxInFlight
+}
+
+func newFanoutTestCollector(concurrency int) *MimirCollector {
+	cfg := config.GetDefaultConfig()
+	cfg.Performance.Enabled = true
+	cfg.Performance.BatchProcessing.Enabled = true
+	cfg.Performance.BatchProcessing.MaxConcurrent = concurrency
+	return NewMimirCollector(config.NewLive(cfg), fake.NewSimpleClientset(), logr.Discard())
+}
+
+func tenantSources(serverURL string, count int) []string {
+	sources := make([]string, count)
+	for i := range sources {
+		sources[i] = fmt.Sprintf("%s/tenant-%d", serverURL, i)
+	}
+	return sources
+}
+
+func TestCollectSourcesBoundsConcurrency(t *testing.T) {
+	const latency = 100 * time.Millisecond
+	sources := &slowSources{latency: latency}
+	server := httptest.NewServer(sources)
+	defer server.Close()
+
+	c := newFanoutTestCollector(3)
+	start := time.Now()
+	tenantMetrics, err := c.collectSources(context.Background(), tenantSources(server.URL, 10))
+	elapsed := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("collectSources() error = %v", err)
+	}
+	if len(tenantMetrics) != 10 {
+		t.Errorf("collected %d tenants, want 10", len(tenantMetrics))
+	}
+	if got := sources.peakConcurrency(); got != 3 {
+		t.Errorf("peak concurrent scrapes = %d, want 3", got)
+	}
+
+	// 10 sources at a concurrency of 3 take ceil(10/3) = 4 rounds of scrapes
+	if minimum, maximum := 4*latency, 6*latency; elapsed < minimum || elapsed > maximum {
+		t.Errorf("collection took %v, want between %v and %v", elapsed, minimum, maximum)
+	}
+}
+
+func TestCollectSourcesWithoutBatchProcessingIsSerial(t *testing.T) {
+	sources := &slowSources{latency: 10 * time.Millisecond}
+	server := httptest.NewServer(sources)
+	defer server.Close()
+
+	c := newFanoutTestCollector(3)
+	c.live.Update(func(cfg *config.Config) {
+		cfg.Performance.BatchProcessing.Enabled = false
+	})
+	if _, err := c.collectSources(context.Background(), tenantSources(server.URL, 4)); err != nil {
+		t.Fatalf("collectSources() error = %v", err)
+	}
+	if got := sources.peakConcurrency(); got != 1 {
+		t.Errorf("peak concurrent scrapes = %d, want 1", got)
+	}
+}
+
+func TestCollectSourcesKeepsPartialResults(t *testing.T) {
+	sources := &slowSources{failing: map[string]bool{"tenant-1": true, "tenant-3": true}}
+	server := httptest.NewServer(sources)
+	defer server.Close()
+
+	c := newFanoutTestCollector(3)
+	tenantMetrics, err := c.collectSources(context.Background(), tenantSources(server.URL, 5))
+
+	var partial *PartialCollectionError
+	if !errors.As(err, &partial) {
+		t.Fatalf("collectSources() error = %v, want a *PartialCollectionError", err)
+	}
+	if !IsPartialFailure(err) {
+		t.Errorf("IsPartialFailure() = false with 3 of 5 sources collected")
+	}
+	if partial.Sources != 5 || len(partial.Failed) != 2 {
+		t.Errorf("partial failure = %d of %d sources failed, want 2 of 5", len(partial.Failed), partial.Sources)
+	}
+	for i, want := range []string{"tenant-1", "tenant-3"} {
+		if i < len(partial.Failed) && !strings.HasSuffix(partial.Failed[i].Source, want) {
+			t.Errorf("failed source %d = %q, want %s in source order", i, partial.Failed[i].Source, want)
+		}
+	}
+	for _, tenant := range []string{"tenant-0", "tenant-2", "tenant-4"} {
+		if tenantMetrics[tenant] == nil {
+			t.Errorf("metrics of %s were dropped by the other sources' failures", tenant)
+		}
+	}
+	if len(tenantMetrics) != 3 {
+		t.Errorf("collected %d tenants, want 3", len(tenantMetrics))
+	}
+}
+
+func TestCollectSourcesAllFailed(t *testing.T) {
+	sources := &slowSources{failing: map[string]bool{"tenant-0": true, "tenant-1": true}}
+	server := httptest.NewServer(sources)
+	defer server.Close()
+
+	c := newFanoutTestCollector(3)
+	_, err := c.collectSources(context.Background(), tenantSources(server.URL, 2))
+
+	var partial *PartialCollectionError
+	if !errors.As(err, &partial) || !partial.AllFailed() {
+		t.Fatalf("collectSources() error = %v, want every source failed", err)
+	}
+	if IsPartialFailure(err) {
+		t.Errorf("IsPartialFailure() = true with no source collected")
+	}
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"math"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// testHistogram builds a cumulative histogram from upper bounds and cumulative counts
+func testHistogram(upperBounds []float64, counts []uint64) *dto.Histogram {
+	buckets := make([]*dto.Bucket, len(upperBounds))
+	var sampleCount uint64
+	for i := range upperBounds {
+		upperBound, count := upperBounds[i], counts[i]
+		buckets[i] = &dto.Bucket{UpperBound: &upperBound, CumulativeCount: &count}
+		if count > sampleCount {
+			sampleCount = count
+		}
+	}
+	return &dto.Histogram{SampleCount: &sampleCount, Bucket: buckets}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	latencies := testHistogram([]float64{1, 5, 10, math.Inf(1)}, []uint64{50, 90, 100, 100})
+	tail := testHistogram([]float64{1, 10, math.Inf(1)}, []uint64{50, 90, 100})
+
+	tests := []struct {
+		name      string
+		histogram *dto.Histogram
+		quantile  float64
+		want      float64
+	}{
+		{"median at a bucket bound", latencies, 0.5, 1},
+		{"p95 interpolated within a bucket", latencies, 0.95, 7.5},
+		{"p70 interpolated within a bucket", latencies, 0.7, 3},
+		{"quantile in the +Inf bucket reports the highest finite bound", tail, 0.99, 10},
+		{"empty histogram", &dto.Histogram{}, 0.95, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := histogramQuantile(tt.histogram, tt.quantile); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("histogramQuantile(%v) = %v, want %v", tt.quantile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogramQuantileUnsortedBuckets(t *testing.T) {
+	sorted := testHistogram([]float64{1, 5, 10, math.Inf(1)}, []uint64{50, 90, 100, 100})
+	unsorted := testHistogram([]float64{10, math.Inf(1), 1, 5}, []uint64{100, 100, 50, 90})
+
+	if got, want := histogramQuantile(unsorted, 0.95), histogramQuantile(sorted, 0.95); got != want {
+		t.Errorf("histogramQuantile of unsorted buckets = %v, want %v", got, want)
+	}
+}
+
+func TestIsDurationMetric(t *testing.T) {
+	if !isDurationMetric("cortex_query_frontend_query_duration_seconds") {
+		t.Errorf("query duration histogram is not a duration metric")
+	}
+	if isDurationMetric("cortex_querier_samples_per_query") {
+		t.Errorf("samples per query histogram is a duration metric")
+	}
+}
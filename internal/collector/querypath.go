@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// isQueryPathHistogram reports whether a metric is the per-query usage histogram of an
+// enabled query-path limit, whose value is its quantile rather than its sample count
+func (c *MimirCollector) isQueryPathHistogram(metricName string) bool {
+	for _, histogram := range c.config().QueryPathMetrics() {
+		if histogram == metricName {
+			return true
+		}
+	}
+	return false
+}
+
+// collectQueryPath queries the trendAnalysis.percentile of each query-path histogram per
+// tenant from the Prometheus API of the metrics endpoint, as
+// histogram_quantile over the bucket rates of trendAnalysis.queryPath.rateWindow. Each
+// tenant gets the quantiles under the histogram's name. Histograms that cannot be
+// queried are skipped, leaving their limits without per-query usage.
+func (c *MimirCollector) collectQueryPath(ctx context.Context) map[string]*TenantMetrics {
+	queryPath := c.config().TrendAnalysis.QueryPath
+	quantile := strconv.FormatFloat(c.config().TrendAnalysis.Percentile/100, 'f', -1, 64)
+
+	histograms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, histogram := range c.config().QueryPathMetrics() {
+		if !seen[histogram] {
+			seen[histogram] = true
+			histograms = append(histograms, histogram)
+		}
+	}
+	sort.Strings(histograms)
+
+	tenantMetrics := make(map[string]*TenantMetrics)
+	for _, histogram := range histograms {
+		params := url.Values{}
+		params.Set("query", fmt.Sprintf(`histogram_quantile(%s, sum by (user, le) (rate(%s_bucket[%s])))`,
+			quantile, histogram, promQLDuration(queryPath.RateWindow)))
+		params.Set("time", fmt.Sprintf("%d", time.Now().Unix()))
+
+		data, err := c.queryPromQL(ctx, "query", histogram, params)
+		if err != nil {
+			c.log.Error(err, "failed to query per-query usage, its limits have insufficient data",
+				"metric", histogram)
+			continue
+		}
+
+		for _, sample := range data {
+			if sample.Tenant == "" {
+				continue
+			}
+			sample.Source = "promql"
+			tm, exists := tenantMetrics[sample.Tenant]
+			if !exists {
+				tm = &TenantMetrics{Tenant: sample.Tenant, Metrics: make(map[string][]MetricData)}
+				tenantMetrics[sample.Tenant] = tm
+			}
+			tm.Metrics[histogram] = append(tm.Metrics[histogram], sample)
+			if sample.Timestamp.After(tm.LastUpdate) {
+				tm.LastUpdate = sample.Timestamp
+			}
+		}
+	}
+
+	c.log.V(1).Info("collected per-query usage", "histograms", len(histograms), "tenants", len(tenantMetrics))
+	return tenantMetrics
+}
+
+// promQLDuration formats a duration as a PromQL range in whole seconds
+func promQLDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,286 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// maxSyntheticSamples bounds the samples of a metric generated in one collection; the
+// samples are spread wider apart when the simulated time of a collection holds more
+const maxSyntheticSamples = 1000
+
+// syntheticProfiles are the profiles given in turn to the tenants generated from
+// synthetic.tenantCount
+var syntheticProfiles = []string{
+	config.SyntheticProfileSteady,
+	config.SyntheticProfileDiurnal,
+	config.SyntheticProfileBursty,
+	config.SyntheticProfileGrowing,
+}
+
+// SyntheticCollector generates the metrics of synthetic tenants from their usage
+// profiles, on a simulated clock advanced by each collection. The noise and bursts are
+// drawn from a seeded source, so a configuration always generates the same metrics.
+type SyntheticCollector struct {
+	live *config.Live
+	log  logr.Logger
+
+	mu      sync.Mutex
+	tenants []config.SyntheticTenantProfile
+	rand    *rand.Rand
+	start   time.Time
+	// now is the simulated time of the last collection
+	now time.Time
+}
+
+// NewSyntheticCollector creates a new synthetic collector
+func NewSyntheticCollector(live *config.Live, log logr.Logger) *SyntheticCollector {
+	cfg := live.Load()
+	metricsConfig := cfg.Synthetic.MetricsConfig
+	start := metricsConfig.StartTime
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	return &SyntheticCollector{
+		live:    live,
+		log:     log,
+		tenants: syntheticTenants(cfg),
+		rand:    rand.New(rand.NewSource(metricsConfig.Seed)),
+		start:   start,
+		now:     start,
+	}
+}
+
+// config returns the configuration in effect
+func (s *SyntheticCollector) config() *config.Config {
+	return s.live.Load()
+}
+
+// syntheticTenants returns the configured tenant profiles, or tenantCount tenants with
+// the profiles in turn, with the defaults of their profile applied
+func syntheticTenants(cfg *config.Config) []config.SyntheticTenantProfile {
+	tenants := cfg.Synthetic.MetricsConfig.Tenants
+	if len(tenants) == 0 {
+		tenants = make([]config.SyntheticTenantProfile, cfg.Synthetic.TenantCount)
+		for i := range tenants {
+			tenants[i] = config.SyntheticTenantProfile{
+				Name:          fmt.Sprintf("synthetic-tenant-%d", i),
+				Profile:       syntheticProfiles[i%len(syntheticProfiles)],
+				IngestionRate: float64(1000 + i*500),
+				Series:        float64(10000 + i*2000),
+				QueryRate:     float64(10 + i*5),
+				Noise:         0.05,
+			}
+		}
+	}
+
+	profiles := make([]config.SyntheticTenantProfile, len(tenants))
+	for i, tenant := range tenants {
+		if tenant.Profile == "" {
+			tenant.Profile = config.SyntheticProfileSteady
+		}
+		if tenant.IngestionRate == 0 {
+			tenant.IngestionRate = 1000
+		}
+		if tenant.Series == 0 {
+			tenant.Series = 10000
+		}
+		if tenant.QueryRate == 0 {
+			tenant.QueryRate = 10
+		}
+		if tenant.Amplitude == 0 {
+			tenant.Amplitude = 0.5
+		}
+		if tenant.BurstProbability == 0 {
+			tenant.BurstProbability = 0.05
+		}
+		if tenant.BurstMultiplier == 0 {
+			tenant.BurstMultiplier = 2
+		}
+		if tenant.GrowthPerDay == 0 {
+			tenant.GrowthPerDay = 0.1
+		}
+		profiles[i] = tenant
+	}
+	return profiles
+}
+
+// Tenants returns the profiles of the synthetic tenants, with the profile defaults
+// applied
+func (s *SyntheticCollector) Tenants() []config.SyntheticTenantProfile {
+	return append([]config.SyntheticTenantProfile(nil), s.tenants...)
+}
+
+// Now returns the simulated time of the last collection. The trend analysis and the
+// circuit breaker are timed with it in synthetic mode, so the generated samples fall in
+// their windows.
+func (s *SyntheticCollector) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// CollectMetrics advances the simulated clock by updateInterval times timeAcceleration
+// and generates the samples of each tenant since the last collection
+func (s *SyntheticCollector) CollectMetrics(ctx context.Context) (map[string]*TenantMetrics, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metricsConfig := s.config().Synthetic.MetricsConfig
+	elapsed := time.Duration(float64(s.config().UpdateInterval) * metricsConfig.TimeAcceleration)
+	interval := metricsConfig.SampleInterval
+	if elapsed > interval*maxSyntheticSamples {
+		interval = elapsed / maxSyntheticSamples
+	}
+	from := s.now
+	s.now = s.now.Add(elapsed)
+
+	var timestamps []time.Time
+	for t := from.Add(interval); !t.After(s.now); t = t.Add(interval) {
+		timestamps = append(timestamps, t)
+	}
+	if len(timestamps) == 0 {
+		timestamps = append(timestamps, s.now)
+	}
+
+	tenantMetrics := make(map[string]*TenantMetrics, len(s.tenants))
+	for i, tenant := range s.tenants {
+		tm := &TenantMetrics{
+			Tenant:     tenant.Name,
+			Metrics:    make(map[string][]MetricData),
+			LastUpdate: s.now,
+		}
+
+		for _, t := range timestamps {
+			usage := s.usageFactor(tenant, t)
+			s.addSample(tm, "cortex_distributor_received_samples_total", tenant.IngestionRate*s.noisy(usage, tenant.Noise), t)
+			s.addSample(tm, "cortex_ingester_memory_series", tenant.Series*s.noisy(usage, tenant.Noise), t)
+			s.addSample(tm, "cortex_querier_queries_total", tenant.QueryRate*s.noisy(usage, tenant.Noise), t)
+		}
+
+		// Query latency (p-quantile of a query duration histogram, in seconds)
+		s.addSample(tm, "cortex_query_frontend_query_duration_seconds",
+			histogramQuantile(syntheticQueryDurationHistogram(i), s.config().TrendAnalysis.Percentile/100), s.now)
+
+		// Per-query usage (p-quantile of each query-path histogram)
+		for limitName, histogram := range s.config().QueryPathMetrics() {
+			s.addSample(tm, histogram,
+				histogramQuantile(syntheticPerQueryHistogram(i, syntheticPerQueryScale[limitName]), s.config().TrendAnalysis.Percentile/100), s.now)
+		}
+
+		tenantMetrics[tenant.Name] = tm
+	}
+
+	s.log.Info("generated synthetic metrics", "tenants", len(tenantMetrics),
+		"samples", len(timestamps), "simulated_time", s.now)
+
+	return tenantMetrics, nil
+}
+
+// usageFactor is the usage of a tenant at a simulated time relative to its base usage,
+// from its profile and spike
+func (s *SyntheticCollector) usageFactor(tenant config.SyntheticTenantProfile, t time.Time) float64 {
+	factor := 1.0
+	switch tenant.Profile {
+	case config.SyntheticProfileDiurnal:
+		// Peaks at noon and bottoms out at midnight, UTC
+		hour := float64(t.UTC().Hour()) + float64(t.UTC().Minute())/60
+		factor += tenant.Amplitude * math.Sin(2*math.Pi*(hour-6)/24)
+	case config.SyntheticProfileBursty:
+		if s.rand.Float64() < tenant.BurstProbability {
+			factor *= tenant.BurstMultiplier
+		}
+	case config.SyntheticProfileGrowing:
+		factor += tenant.GrowthPerDay * t.Sub(s.start).Hours() / 24
+	}
+
+	if spike := tenant.Spike; spike != nil {
+		spikeStart := s.start.Add(spike.After)
+		if !t.Before(spikeStart) && t.Before(spikeStart.Add(spike.Duration)) {
+			factor *= spike.Multiplier
+		}
+	}
+	return factor
+}
+
+// noisy applies random noise with the given relative standard deviation to a value
+func (s *SyntheticCollector) noisy(value, noise float64) float64 {
+	if noise == 0 {
+		return value
+	}
+	return math.Max(0, value*(1+noise*s.rand.NormFloat64()))
+}
+
+// addSample appends a generated sample to a tenant's metric
+func (s *SyntheticCollector) addSample(tm *TenantMetrics, metricName string, value float64, t time.Time) {
+	tm.Metrics[metricName] = append(tm.Metrics[metricName], MetricData{
+		Tenant:     tm.Tenant,
+		MetricName: metricName,
+		Value:      value,
+		Timestamp:  t,
+		Labels:     map[string]string{"user": tm.Tenant},
+		Source:     "synthetic",
+	})
+}
+
+// syntheticQueryDurationHistogram builds a query duration histogram whose tail
+// grows with the tenant index, so later tenants look like they run longer queries
+func syntheticQueryDurationHistogram(tenantIndex int) *dto.Histogram {
+	upperBounds := []float64{0.5, 1, 5, 30, 60, 120, 300, 600, math.Inf(1)}
+	// Fraction of queries finishing within each bucket for the first tenant
+	cumulative := []float64{0.40, 0.60, 0.80, 0.90, 0.95, 0.98, 0.995, 1.0, 1.0}
+
+	const sampleCount = 10000
+	shift := float64(tenantIndex) * 0.02
+	buckets := make([]*dto.Bucket, len(upperBounds))
+	for i, ub := range upperBounds {
+		fraction := math.Max(0, cumulative[i]-shift)
+		if math.IsInf(ub, 1) {
+			fraction = 1.0
+		}
+		count := uint64(fraction * sampleCount)
+		upperBound := ub
+		buckets[i] = &dto.Bucket{UpperBound: &upperBound, CumulativeCount: &count}
+	}
+
+	total := uint64(sampleCount)
+	return &dto.Histogram{SampleCount: &total, Bucket: buckets}
+}
+
+// syntheticPerQueryScale is the per-query usage of each query-path limit at the upper
+// bound of the synthetic histogram's first bucket
+var syntheticPerQueryScale = map[string]float64{
+	"max_samples_per_query":        200000,
+	"max_fetched_series_per_query": 500,
+	"max_fetched_chunks_per_query": 10000,
+}
+
+// syntheticPerQueryHistogram builds a per-query usage histogram shaped like the query
+// duration histogram, with its bucket bounds scaled from seconds to the usage of a query
+func syntheticPerQueryHistogram(tenantIndex int, scale float64) *dto.Histogram {
+	h := syntheticQueryDurationHistogram(tenantIndex)
+	for _, b := range h.Bucket {
+		upperBound := b.GetUpperBound() * scale / 0.5
+		b.UpperBound = &upperBound
+	}
+	return h
+}
+
+// GetTenantList returns the synthetic tenants
+func (s *SyntheticCollector) GetTenantList(ctx context.Context) ([]string, error) {
+	tenants := make([]string, len(s.tenants))
+	for i, tenant := range s.tenants {
+		tenants[i] = tenant.Name
+	}
+	return tenants, nil
+}
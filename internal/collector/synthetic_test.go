@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+var syntheticStart = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// newTestSyntheticCollector generates the tenants from midnight, a simulated hour per
+// collection with a sample every 5 minutes
+func newTestSyntheticCollector(seed int64, tenants ...config.SyntheticTenantProfile) *SyntheticCollector {
+	cfg := config.GetDefaultConfig()
+	cfg.UpdateInterval = time.Minute
+	cfg.Synthetic.Enabled = true
+	cfg.Synthetic.MetricsConfig = config.SyntheticMetricsConfig{
+		Seed:             seed,
+		StartTime:        syntheticStart,
+		TimeAcceleration: 60,
+		SampleInterval:   5 * time.Minute,
+		Tenants:          tenants,
+	}
+	return NewSyntheticCollector(config.NewLive(cfg), logr.Discard())
+}
+
+// ingestionSamples collects n times and returns the ingestion rate samples of tenant
+func ingestionSamples(t *testing.T, s *SyntheticCollector, tenant string, n int) []MetricData {
+	t.Helper()
+	var samples []MetricData
+	for i := 0; i < n; i++ {
+		tenantMetrics, err := s.CollectMetrics(context.Background())
+		if err != nil {
+			t.Fatalf("CollectMetrics: %v", err)
+		}
+		samples = append(samples, tenantMetrics[tenant].Metrics["cortex_distributor_received_samples_total"]...)
+	}
+	return samples
+}
+
+func TestSyntheticCollectorIsSeeded(t *testing.T) {
+	bursty := config.SyntheticTenantProfile{Name: "bursty", Profile: config.SyntheticProfileBursty, Noise: 0.05, BurstProbability: 0.2}
+
+	first := ingestionSamples(t, newTestSyntheticCollector(42, bursty), "bursty", 3)
+	second := ingestionSamples(t, newTestSyntheticCollector(42, bursty), "bursty", 3)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("two collectors of seed 42 generated different samples")
+	}
+	if other := ingestionSamples(t, newTestSyntheticCollector(7, bursty), "bursty", 3); reflect.DeepEqual(first, other) {
+		t.Errorf("seeds 42 and 7 generated the same samples")
+	}
+}
+
+func TestSyntheticCollectorAcceleratesTime(t *testing.T) {
+	s := newTestSyntheticCollector(1, config.SyntheticTenantProfile{Name: "steady"})
+
+	samples := ingestionSamples(t, s, "steady", 2)
+	if want := syntheticStart.Add(2 * time.Hour); !s.Now().Equal(want) {
+		t.Errorf("simulated time after 2 collections = %v, want %v", s.Now(), want)
+	}
+	// A sample every 5 minutes of each simulated hour
+	if len(samples) != 24 {
+		t.Fatalf("2 collections generated %d samples, want 24", len(samples))
+	}
+	if !samples[0].Timestamp.Equal(syntheticStart.Add(5*time.Minute)) || !samples[23].Timestamp.Equal(s.Now()) {
+		t.Errorf("samples span %v to %v, want the 2 simulated hours", samples[0].Timestamp, samples[23].Timestamp)
+	}
+}
+
+func TestSyntheticProfiles(t *testing.T) {
+	s := newTestSyntheticCollector(1,
+		config.SyntheticTenantProfile{Name: "steady", IngestionRate: 1000},
+		config.SyntheticTenantProfile{Name: "diurnal", Profile: config.SyntheticProfileDiurnal, IngestionRate: 1000, Amplitude: 0.5},
+		config.SyntheticTenantProfile{Name: "growing", Profile: config.SyntheticProfileGrowing, IngestionRate: 1000, GrowthPerDay: 0.5},
+		config.SyntheticTenantProfile{Name: "spiking", IngestionRate: 1000,
+			Spike: &config.SyntheticSpikeConfig{After: 2 * time.Hour, Duration: time.Hour, Multiplier: 3}},
+	)
+	tenants := make(map[string]config.SyntheticTenantProfile)
+	for _, tenant := range s.Tenants() {
+		tenants[tenant.Name] = tenant
+	}
+
+	tests := []struct {
+		tenant string
+		at     time.Duration
+		want   float64
+	}{
+		{"steady", 12 * time.Hour, 1},
+		{"diurnal", 0, 0.5},
+		{"diurnal", 12 * time.Hour, 1.5},
+		{"growing", 0, 1},
+		{"growing", 48 * time.Hour, 2},
+		{"spiking", time.Hour, 1},
+		{"spiking", 2 * time.Hour, 3},
+		{"spiking", 3 * time.Hour, 1},
+	}
+	for _, tt := range tests {
+		if got := s.usageFactor(tenants[tt.tenant], syntheticStart.Add(tt.at)); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("%s usage %v after the start = %v, want %v", tt.tenant, tt.at, got, tt.want)
+		}
+	}
+
+	// Without noise the samples follow the profile exactly
+	for _, sample := range ingestionSamples(t, s, "steady", 1) {
+		if sample.Value != 1000 {
+			t.Errorf("steady sample at %v = %v, want 1000", sample.Timestamp, sample.Value)
+		}
+	}
+}
+
+func TestSyntheticTenantsFromTenantCount(t *testing.T) {
+	s := newTestSyntheticCollector(1)
+	tenants, err := s.GetTenantList(context.Background())
+	if err != nil {
+		t.Fatalf("GetTenantList: %v", err)
+	}
+	if len(tenants) != s.config().Synthetic.TenantCount {
+		t.Fatalf("%d tenants generated, want synthetic.tenantCount %d", len(tenants), s.config().Synthetic.TenantCount)
+	}
+	for i, tenant := range s.Tenants() {
+		if want := syntheticProfiles[i%len(syntheticProfiles)]; tenant.Profile != want {
+			t.Errorf("%s profile = %q, want %q in turn", tenant.Name, tenant.Profile, want)
+		}
+	}
+}
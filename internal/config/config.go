@@ -1,11 +1,20 @@
 package config
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 )
 
 // Config holds all configuration for the mimir-limit-optimizer
@@ -37,15 +46,27 @@ type Config struct {
 	// Trend analysis configuration
 	TrendAnalysis TrendAnalysisConfig `yaml:"trendAnalysis" json:"trendAnalysis"`
 
+	// Peer-group anomaly detection
+	AnomalyDetection AnomalyConfig `yaml:"anomalyDetection" json:"anomalyDetection"`
+
 	// Limits configuration
 	Limits LimitsConfig `yaml:"limits" json:"limits"`
 
 	// Audit logging configuration
 	AuditLog AuditLogConfig `yaml:"auditLog" json:"auditLog"`
 
+	// Per-tenant recommendation history
+	RecommendationHistory RecommendationHistoryConfig `yaml:"recommendationHistory" json:"recommendationHistory"`
+
 	// Synthetic mode for testing
 	Synthetic SyntheticConfig `yaml:"synthetic" json:"synthetic"`
 
+	// Standalone mode, without Kubernetes
+	Standalone StandaloneConfig `yaml:"standalone" json:"standalone"`
+
+	// Labels of the optimizer's own metrics
+	Metrics MetricsConfig `yaml:"metrics" json:"metrics"`
+
 	// Cost control and budget management
 	CostControl CostControlConfig `yaml:"costControl" json:"costControl"`
 
@@ -69,6 +90,11 @@ type Config struct {
 
 	// Health scanner configuration
 	HealthScanner HealthScannerConfig `yaml:"healthScanner" json:"healthScanner"`
+
+	// SourceHash is the SHA-256 of the config file this configuration was loaded
+	// from and LoadedAt the time it was loaded; neither is read from the file
+	SourceHash string    `yaml:"-" json:"-"`
+	LoadedAt   time.Time `yaml:"-" json:"-"`
 }
 
 type MimirConfig struct {
@@ -85,6 +111,100 @@ type MimirConfig struct {
 
 	// Components to rollout (if TriggerRollout is true)
 	RolloutComponents []string `yaml:"rolloutComponents" json:"rolloutComponents"`
+
+	// Mirror cluster (e.g. DR) whose overrides are compared against this cluster for drift
+	SecondaryCluster ClusterConfig `yaml:"secondaryCluster" json:"secondaryCluster"`
+
+	// Alert when a limit differs between clusters by more than this percentage
+	DriftAlertThresholdPercent float64 `yaml:"driftAlertThresholdPercent" json:"driftAlertThresholdPercent"`
+
+	// Layout of overrides.yaml: "mimir-native" nests tenants under the top-level
+	// overrides key as Mimir's runtime config expects; "flat" keys tenants at the top level;
+	// "sharded" writes each tenant to its own mimir-native ConfigMap, indexed by this one
+	ConfigMapFormat string `yaml:"configMapFormat" json:"configMapFormat"`
+
+	// Percentage of the 1MiB ConfigMap size limit at which a size warning is raised
+	ConfigMapSizeWarningPercent float64 `yaml:"configMapSizeWarningPercent" json:"configMapSizeWarningPercent"`
+
+	// Consecutive failed applies of the limits after which readyz fails in prod mode;
+	// 0 keeps the optimizer ready regardless
+	ReadinessFailureThreshold int `yaml:"readinessFailureThreshold" json:"readinessFailureThreshold"`
+
+	// Split the runtime overrides across several ConfigMaps
+	Sharding OverridesShardingConfig `yaml:"sharding" json:"sharding"`
+
+	// Read the limits Mimir has actually loaded from the overrides-exporter
+	OverridesExporter OverridesExporterConfig `yaml:"overridesExporter" json:"overridesExporter"`
+
+	// Thanos Ruler evaluates the rules instead of the Mimir ruler: ruler_* limits are not
+	// written to the runtime overrides, and the rule group limits go to the Thanos Ruler
+	// limits ConfigMap instead
+	ThanosModeEnabled bool `yaml:"thanosModeEnabled" json:"thanosModeEnabled"`
+
+	// Name of the Thanos Ruler limits ConfigMap, in the Mimir namespace
+	ThanosRulerConfigMapName string `yaml:"thanosRulerConfigMapName" json:"thanosRulerConfigMapName"`
+}
+
+// OverridesExporterConfig reads the cortex_limits_overrides metrics of Mimir's
+// overrides-exporter, which reflect the runtime config Mimir has loaded rather than the
+// ConfigMap it was written to
+type OverridesExporterConfig struct {
+	// Enable reading the loaded limits
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Metrics URL of the overrides-exporter; empty discovers it with the infrastructure
+	// scanner
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// How long a limit may differ between the ConfigMap and Mimir before a warning is
+	// raised, covering the runtime config reload period
+	MismatchGracePeriod time.Duration `yaml:"mismatchGracePeriod" json:"mismatchGracePeriod"`
+}
+
+// OverridesShardingConfig splits tenants across ConfigMaps named <configMapName>-0,
+// <configMapName>-1, ... by a stable hash of the tenant ID
+type OverridesShardingConfig struct {
+	// Enable sharded runtime overrides
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Number of shard ConfigMaps; changing it moves tenants between shards
+	Shards int `yaml:"shards" json:"shards"`
+}
+
+// OverridesConfigMapNames returns the names of the ConfigMaps holding the runtime
+// overrides: the configured ConfigMap, or every shard when sharding is enabled
+func (m MimirConfig) OverridesConfigMapNames() []string {
+	if !m.Sharding.Enabled || m.Sharding.Shards <= 0 {
+		return []string{m.ConfigMapName}
+	}
+
+	names := make([]string, m.Sharding.Shards)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s-%d", m.ConfigMapName, i)
+	}
+	return names
+}
+
+// Supported runtime overrides ConfigMap formats
+const (
+	ConfigMapFormatFlat        = "flat"
+	ConfigMapFormatMimirNative = "mimir-native"
+	ConfigMapFormatSharded     = "sharded"
+)
+
+// ClusterConfig identifies the runtime overrides ConfigMap in another Mimir cluster
+type ClusterConfig struct {
+	// Enable drift detection against this cluster
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Path to the kubeconfig used to reach the cluster
+	KubeconfigPath string `yaml:"kubeconfigPath" json:"kubeconfigPath"`
+
+	// Namespace where Mimir is deployed in the cluster
+	Namespace string `yaml:"namespace" json:"namespace"`
+
+	// Name of the runtime overrides ConfigMap in the cluster
+	ConfigMapName string `yaml:"configMapName" json:"configMapName"`
 }
 
 type TenantScopingConfig struct {
@@ -94,8 +214,16 @@ type TenantScopingConfig struct {
 	// List of tenant patterns to include (empty means all, glob or regex)
 	IncludeList []string `yaml:"includeList" json:"includeList"`
 
+	// List of tenant patterns whose limits are never modified while they stay
+	// monitored (glob or regex)
+	PausedTenants []string `yaml:"pausedTenants" json:"pausedTenants"`
+
 	// Whether to use regex instead of glob patterns
 	UseRegex bool `yaml:"useRegex" json:"useRegex"`
+
+	// Name of the ConfigMap persisting skip, include and paused lists changed through the API.
+	// It is created in the optimizer's namespace and, once present, replaces the lists above.
+	RuntimeConfigMapName string `yaml:"runtimeConfigMapName" json:"runtimeConfigMapName"`
 }
 
 type MetricsDiscoveryConfig struct {
@@ -108,6 +236,12 @@ type MetricsDiscoveryConfig struct {
 	// Label selector for discovering services
 	ServiceLabelSelector string `yaml:"serviceLabelSelector" json:"serviceLabelSelector"`
 
+	// Label selector identifying Mimir workloads; workloads labeled app.kubernetes.io/name=mimir always match
+	ComponentLabelSelector string `yaml:"componentLabelSelector" json:"componentLabelSelector"`
+
+	// Label holding the Mimir component of a workload
+	ComponentLabel string `yaml:"componentLabel" json:"componentLabel"`
+
 	// List of known service names to discover
 	ServiceNames []string `yaml:"serviceNames" json:"serviceNames"`
 
@@ -122,6 +256,50 @@ type MetricsDiscoveryConfig struct {
 
 	// Tenant discovery configuration
 	TenantDiscovery TenantDiscoveryConfig `yaml:"tenantDiscovery" json:"tenantDiscovery"`
+
+	// Scope of the namespace, workload and tenant namespace scans: "cluster" lists every
+	// namespace, "namespaces" only lists in scanNamespaces and needs no cluster-wide RBAC
+	ScanScope string `yaml:"scanScope" json:"scanScope"`
+
+	// Namespaces scanned with scanScope "namespaces", and the fallback when listing
+	// namespaces is forbidden; empty scans the Mimir namespace
+	ScanNamespaces []string `yaml:"scanNamespaces" json:"scanNamespaces"`
+}
+
+// Supported scopes of the namespace scans
+const (
+	ScanScopeCluster    = "cluster"
+	ScanScopeNamespaces = "namespaces"
+)
+
+// ScopedNamespaces returns the namespaces scanned without cluster-wide access: the
+// configured scanNamespaces, or the Mimir namespace
+func (c *Config) ScopedNamespaces() []string {
+	namespaces := make([]string, 0, len(c.MetricsDiscovery.ScanNamespaces))
+	seen := make(map[string]bool)
+	for _, namespace := range c.MetricsDiscovery.ScanNamespaces {
+		if namespace != "" && !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		namespaces = append(namespaces, c.Mimir.Namespace)
+	}
+	return namespaces
+}
+
+// NamespaceInScope reports whether a namespace may be scanned under the scan scope
+func (c *Config) NamespaceInScope(namespace string) bool {
+	if c.MetricsDiscovery.ScanScope != ScanScopeNamespaces {
+		return true
+	}
+	for _, scoped := range c.ScopedNamespaces() {
+		if scoped == namespace {
+			return true
+		}
+	}
+	return false
 }
 
 type TenantDiscoveryConfig struct {
@@ -142,6 +320,13 @@ type TenantDiscoveryConfig struct {
 
 	// Additional tenant headers (for custom auth)
 	TenantHeaders map[string]string `yaml:"tenantHeaders" json:"tenantHeaders"`
+
+	// Read baseline limits of namespace-per-tenant deployments from mimir.io/<limit-name>
+	// annotations on the Namespaces, the tenant being the namespace name
+	NamespaceAnnotations bool `yaml:"namespaceAnnotations" json:"namespaceAnnotations"`
+
+	// Label selector of the Namespaces read for annotations; empty reads every namespace
+	NamespaceLabelSelector string `yaml:"namespaceLabelSelector" json:"namespaceLabelSelector"`
 }
 
 type EventSpikeConfig struct {
@@ -159,6 +344,32 @@ type EventSpikeConfig struct {
 
 	// Maximum spike multiplier to apply
 	MaxSpikeMultiplier float64 `yaml:"maxSpikeMultiplier" json:"maxSpikeMultiplier"`
+
+	// Percentage of the spike increase removed per decay interval once the cooldown ends
+	DecayStepPercent float64 `yaml:"decayStepPercent" json:"decayStepPercent"`
+
+	// Interval between decay steps
+	DecayInterval time.Duration `yaml:"decayInterval" json:"decayInterval"`
+
+	// Raise the ingestion limits by maxSpikeMultiplier when the trend of the ingestion
+	// rate is predicted to reach 80% of the limit within the detection window
+	PredictiveSpike bool `yaml:"predictiveSpike" json:"predictiveSpike"`
+
+	// Interval at which the ingestion rates are re-checked for predicted breaches
+	PredictiveCheckInterval time.Duration `yaml:"predictiveCheckInterval" json:"predictiveCheckInterval"`
+}
+
+// AnomalyConfig flags tenants whose ingestion rate deviates from the other tenants of
+// their tier
+type AnomalyConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Z-score of the ingestion rate against the tier peers beyond which a tenant is
+	// anomalous
+	ZScoreThreshold float64 `yaml:"zScoreThreshold" json:"zScoreThreshold"`
+
+	// Tenants a tier needs, the tenant included, before its tenants are compared
+	MinPeerGroupSize int `yaml:"minPeerGroupSize" json:"minPeerGroupSize"`
 }
 
 type TrendAnalysisConfig struct {
@@ -174,8 +385,71 @@ type TrendAnalysisConfig struct {
 	// Include peak usage in calculations
 	IncludePeaks bool `yaml:"includePeaks" json:"includePeaks"`
 
-	// Time-of-day specific buffers
+	// Time-of-day specific buffers, in percent on top of the limit's buffer, keyed by
+	// UTC hour of day ("09") or day of week and hour ("mon-09")
 	TimeOfDayBuffers map[string]float64 `yaml:"timeOfDayBuffers" json:"timeOfDayBuffers"`
+
+	// Seasonality detection of weekly usage patterns
+	Seasonality SeasonalityConfig `yaml:"seasonality" json:"seasonality"`
+
+	// Collection of the per-query usage behind the query-path limits
+	QueryPath QueryPathConfig `yaml:"queryPath" json:"queryPath"`
+}
+
+// QueryPathConfig defines how the per-query usage of each tenant is gathered from the
+// querier and query-frontend histograms named by the MetricSource of the QueryPathLimits.
+// Scraped histograms are always read at the analysis percentile; when enabled, the same
+// quantile is also queried with PromQL from the metricsEndpoint Prometheus API.
+type QueryPathConfig struct {
+	// Query the per-query histograms with histogram_quantile
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Window of the rate the histogram buckets are queried over
+	RateWindow time.Duration `yaml:"rateWindow" json:"rateWindow"`
+}
+
+// SeasonalityConfig defines the detection of weekly usage patterns. Each tenant's usage
+// is averaged per hour of the week; hours running more than thresholdPercent above the
+// weekly median get a buffer of their excess on top of the limit's buffer.
+type SeasonalityConfig struct {
+	// Enable seasonality detection
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Metric whose usage pattern is detected
+	Metric string `yaml:"metric" json:"metric"`
+
+	// History needed before buffers are applied
+	MinHistory time.Duration `yaml:"minHistory" json:"minHistory"`
+
+	// How long usage history is kept
+	Retention time.Duration `yaml:"retention" json:"retention"`
+
+	// How far above the weekly median an hour must run to get a buffer (percentage)
+	ThresholdPercent float64 `yaml:"thresholdPercent" json:"thresholdPercent"`
+}
+
+// ParseTimeOfDayBucket parses a timeOfDayBuffers key: an hour of day ("09"), for which
+// weekday is -1, or a day of week and hour ("mon-09")
+func ParseTimeOfDayBucket(key string) (weekday time.Weekday, hour int, err error) {
+	weekday = -1
+	hourPart := key
+	if day, rest, found := strings.Cut(key, "-"); found {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(day, d.String()[:3]) {
+				weekday = d
+			}
+		}
+		if weekday < 0 {
+			return 0, 0, fmt.Errorf("unknown day of week %q, use mon to sun", day)
+		}
+		hourPart = rest
+	}
+
+	hour, err = strconv.Atoi(hourPart)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("hour must be 00 to 23, got %q", hourPart)
+	}
+	return weekday, hour, nil
 }
 
 type LimitsConfig struct {
@@ -188,19 +462,77 @@ type LimitsConfig struct {
 	// Default limits for new tenants
 	DefaultLimits map[string]interface{} `yaml:"defaultLimits" json:"defaultLimits"`
 
+	// Limits optimized, or left alone, whatever the Enabled field of their limit
+	// definition; a limit may not be in both lists
+	EnabledLimits  []string `yaml:"enabledLimits" json:"enabledLimits"`
+	DisabledLimits []string `yaml:"disabledLimits" json:"disabledLimits"`
+
 	// TTL for removing limits of inactive tenants
 	InactiveTenantTTL time.Duration `yaml:"inactiveTenantTTL" json:"inactiveTenantTTL"`
 
+	// Tenant IDs or globs whose limits are kept however long they are idle, e.g.
+	// disaster recovery tenants
+	InactiveTenantAllowlist []string `yaml:"inactiveTenantAllowlist" json:"inactiveTenantAllowlist"`
+
 	// Tenant tiers configuration
 	TenantTiers map[string]TenantTierConfig `yaml:"tenantTiers" json:"tenantTiers"`
+
+	// Explicit tenant ID to tier assignments, taking precedence over the tiers' tenant
+	// patterns
+	TierAssignments map[string]string `yaml:"tierAssignments" json:"tierAssignments"`
+
+	// Remote source of per-tenant limits, e.g. a raw file in a GitOps repository
+	RemoteOverrideSource RemoteOverrideConfig `yaml:"remoteOverrideSource" json:"remoteOverrideSource"`
+}
+
+// InactiveTenantAllowed reports whether a tenant matches limits.inactiveTenantAllowlist
+// and keeps its limits while idle
+func (l LimitsConfig) InactiveTenantAllowed(tenant string) bool {
+	for _, pattern := range l.InactiveTenantAllowlist {
+		if ok, err := path.Match(pattern, tenant); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteOverrideConfig configures fetching per-tenant limits over HTTP. The document
+// maps tenant IDs to limit names and values; remote values take precedence over
+// calculated limits.
+type RemoteOverrideConfig struct {
+	// Enable the remote override source
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// URL of the overrides document
+	URL string `yaml:"url" json:"url"`
+
+	// How often to fetch the document
+	PollInterval time.Duration `yaml:"pollInterval" json:"pollInterval"`
+
+	// HTTP headers sent with every request (e.g. Authorization)
+	Headers map[string]string `yaml:"headers" json:"headers"`
+
+	// Document format: "yaml" or "json"
+	Format string `yaml:"format" json:"format"`
+
+	// Timeout for a single fetch
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
 }
 
 type TenantTierConfig struct {
 	// Buffer percentage for this tier
 	BufferPercentage float64 `yaml:"bufferPercentage" json:"bufferPercentage"`
 
-	// Specific limits for this tier
+	// Specific limits for this tier: floors for numeric and duration limits, defaults
+	// for the others
 	Limits map[string]interface{} `yaml:"limits" json:"limits"`
+
+	// Tenant ID glob patterns assigned to this tier; patterns prefixed with "regex:"
+	// are regular expressions matched against the whole tenant ID
+	Tenants []string `yaml:"tenants" json:"tenants"`
+
+	// Values of metrics.extraLabels exported on the tenant metrics of this tier's tenants
+	MetricLabels map[string]string `yaml:"metricLabels" json:"metricLabels"`
 }
 
 type AuditLogConfig struct {
@@ -244,6 +576,44 @@ type AuditRetentionConfig struct {
 	EmergencyThresholdPercent float64 `yaml:"emergencyThresholdPercent" json:"emergencyThresholdPercent"`
 }
 
+// RecommendationHistoryConfig controls how long the recommendations of each tenant are kept,
+// so it can be seen how they evolved
+type RecommendationHistoryConfig struct {
+	// Enable recommendation history
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Storage type: "memory" or "configmap"
+	StorageType string `yaml:"storageType" json:"storageType"`
+
+	// ConfigMap name for history storage
+	ConfigMapName string `yaml:"configMapName" json:"configMapName"`
+
+	// How long recommendations are kept
+	Retention time.Duration `yaml:"retention" json:"retention"`
+
+	// Maximum recommendations kept per tenant, across all its limits
+	MaxEntriesPerTenant int `yaml:"maxEntriesPerTenant" json:"maxEntriesPerTenant"`
+
+	// Unchanged recommendations are recorded at most this often
+	SampleInterval time.Duration `yaml:"sampleInterval" json:"sampleInterval"`
+}
+
+// MetricsConfig defines the labels of the exported metrics
+type MetricsConfig struct {
+	// Extra labels of the per-tenant limit, usage and cost metrics, such as business unit,
+	// environment or SLA tier. Their values come from limits.tenantTiers[].metricLabels
+	// and the mimir.io/metric-label-<name> annotations of tenant namespaces. At most
+	// five, as each label multiplies the series of the tenant metrics.
+	ExtraLabels []string `yaml:"extraLabels" json:"extraLabels"`
+}
+
+// StandaloneConfig defines the state kept by standalone mode
+type StandaloneConfig struct {
+	// Path of a SQLite database persisting the collected metrics, limit history, audit
+	// entries and circuit breaker state across restarts; empty keeps them in memory
+	StateDB string `yaml:"stateDB" json:"stateDB"`
+}
+
 type SyntheticConfig struct {
 	// Enable synthetic mode for testing
 	Enabled bool `yaml:"enabled" json:"enabled"`
@@ -252,7 +622,84 @@ type SyntheticConfig struct {
 	TenantCount int `yaml:"tenantCount" json:"tenantCount"`
 
 	// Synthetic metrics configuration
-	MetricsConfig map[string]interface{} `yaml:"metricsConfig" json:"metricsConfig"`
+	MetricsConfig SyntheticMetricsConfig `yaml:"metricsConfig" json:"metricsConfig"`
+}
+
+// Profiles of the usage generated for a synthetic tenant
+const (
+	SyntheticProfileSteady  = "steady"
+	SyntheticProfileDiurnal = "diurnal"
+	SyntheticProfileBursty  = "bursty"
+	SyntheticProfileGrowing = "growing"
+)
+
+// SyntheticMetricsConfig configures the metrics generated in synthetic mode. The
+// generated metrics follow a simulated clock, which the trend analysis and the circuit
+// breaker are timed with, so a long analysis window can be replayed in little time.
+type SyntheticMetricsConfig struct {
+	// Seed of the noise and bursts; the same seed and start time generate the same metrics
+	Seed int64 `yaml:"seed" json:"seed"`
+
+	// Simulated time of the first collection, the current time when unset
+	StartTime time.Time `yaml:"startTime" json:"startTime"`
+
+	// Speed of the simulated clock over real time: each collection advances it by
+	// updateInterval times timeAcceleration
+	TimeAcceleration float64 `yaml:"timeAcceleration" json:"timeAcceleration"`
+
+	// Simulated time between generated samples
+	SampleInterval time.Duration `yaml:"sampleInterval" json:"sampleInterval"`
+
+	// Profiles of the synthetic tenants. When empty, tenantCount tenants are generated
+	// with the steady, diurnal, bursty and growing profiles in turn.
+	Tenants []SyntheticTenantProfile `yaml:"tenants" json:"tenants"`
+}
+
+// SyntheticTenantProfile is the usage generated for one synthetic tenant. Zero values
+// take the defaults of the profile.
+type SyntheticTenantProfile struct {
+	// Tenant ID
+	Name string `yaml:"name" json:"name"`
+
+	// Usage profile: steady, diurnal, bursty or growing
+	Profile string `yaml:"profile" json:"profile"`
+
+	// Base ingestion rate, in samples per second
+	IngestionRate float64 `yaml:"ingestionRate" json:"ingestionRate"`
+
+	// Base number of active series
+	Series float64 `yaml:"series" json:"series"`
+
+	// Base query rate, in queries per second
+	QueryRate float64 `yaml:"queryRate" json:"queryRate"`
+
+	// Standard deviation of the random noise, as a fraction of the value; 0 for none
+	Noise float64 `yaml:"noise" json:"noise"`
+
+	// Diurnal profile: daily swing around the base, as a fraction of it
+	Amplitude float64 `yaml:"amplitude" json:"amplitude"`
+
+	// Bursty profile: probability of a burst at each sample, and its multiplier
+	BurstProbability float64 `yaml:"burstProbability" json:"burstProbability"`
+	BurstMultiplier  float64 `yaml:"burstMultiplier" json:"burstMultiplier"`
+
+	// Growing profile: growth per simulated day, as a fraction of the base
+	GrowthPerDay float64 `yaml:"growthPerDay" json:"growthPerDay"`
+
+	// Spike multiplying the tenant's usage for a while
+	Spike *SyntheticSpikeConfig `yaml:"spike,omitempty" json:"spike,omitempty"`
+}
+
+// SyntheticSpikeConfig is a spike of a synthetic tenant, in simulated time
+type SyntheticSpikeConfig struct {
+	// Simulated time after the start time the spike starts at
+	After time.Duration `yaml:"after" json:"after"`
+
+	// How long the spike lasts in simulated time
+	Duration time.Duration `yaml:"duration" json:"duration"`
+
+	// Multiplier of the usage during the spike
+	Multiplier float64 `yaml:"multiplier" json:"multiplier"`
 }
 
 // CostControlConfig defines cost management and budget controls
@@ -263,9 +710,13 @@ type CostControlConfig struct {
 	// Cost calculation method: "samples", "series", "queries", "composite"
 	CostMethod string `yaml:"costMethod" json:"costMethod"`
 
-	// Cost per unit (e.g., per million samples)
+	// Cost of a million samples, series-hours or queries. Spend accrues each reconcile
+	// from the collected per-second sample and query rates and the active series.
 	CostPerUnit float64 `yaml:"costPerUnit" json:"costPerUnit"`
 
+	// Weights of samples, series-hours, queries and storage in the composite cost method
+	CostWeights CostWeights `yaml:"costWeights" json:"costWeights"`
+
 	// Budget limits per tenant
 	TenantBudgets map[string]BudgetConfig `yaml:"tenantBudgets" json:"tenantBudgets"`
 
@@ -280,6 +731,41 @@ type CostControlConfig struct {
 
 	// Cost estimation window
 	EstimationWindow time.Duration `yaml:"estimationWindow" json:"estimationWindow"`
+
+	// Coefficients of the projected monthly cost
+	Coefficients CostCoefficients `yaml:"coefficients" json:"coefficients"`
+}
+
+// CostWeights weight the usage priced by the composite cost method. The cost is the
+// weighted sum of the usage, in millions of units, times costPerUnit.
+type CostWeights struct {
+	// Weight of a sample ingested
+	Samples float64 `yaml:"samples" json:"samples"`
+
+	// Weight of a series active for an hour
+	Series float64 `yaml:"series" json:"series"`
+
+	// Weight of a query served
+	Queries float64 `yaml:"queries" json:"queries"`
+
+	// Weight of a megabyte of TSDB block storage kept for an hour
+	Storage float64 `yaml:"storage" json:"storage"`
+}
+
+// CostCoefficients price an hour of sustained usage for the projected monthly cost. A
+// zero coefficient is derived from costPerUnit.
+type CostCoefficients struct {
+	// Cost of ingesting one sample per second for an hour
+	SamplesCost float64 `yaml:"samplesCost" json:"samplesCost"`
+
+	// Cost of one active series for an hour
+	SeriesCost float64 `yaml:"seriesCost" json:"seriesCost"`
+
+	// Cost of serving one query per second for an hour
+	QueryCost float64 `yaml:"queryCost" json:"queryCost"`
+
+	// Cost of one megabyte of TSDB block storage for an hour
+	StorageCost float64 `yaml:"storageCost" json:"storageCost"`
 }
 
 type BudgetConfig struct {
@@ -319,9 +805,13 @@ type CircuitBreakerConfig struct {
 	// Sleep window for half-open state
 	SleepWindow time.Duration `yaml:"sleepWindow" json:"sleepWindow"`
 
-	// Maximum requests in half-open state
+	// Maximum tenants evaluated per reconcile in half-open state; the others are held back
 	MaxRequestsInHalfOpen int `yaml:"maxRequestsInHalfOpen" json:"maxRequestsInHalfOpen"`
 
+	// Consecutive successful half-open probes after which the circuit closes again;
+	// they may span several reconciles
+	HalfOpenSuccessThreshold int `yaml:"halfOpenSuccessThreshold" json:"halfOpenSuccessThreshold"`
+
 	// Automatic configuration based on limits and metrics
 	AutoConfig AutoCircuitBreakerConfig `yaml:"autoConfig" json:"autoConfig"`
 
@@ -385,8 +875,13 @@ type RealtimeAdaptationConfig struct {
 	// Percentile to use for threshold calculation
 	Percentile float64 `yaml:"percentile" json:"percentile"`
 
-	// Enable seasonal pattern detection
+	// Enable seasonal pattern detection: baselines per hour of day, so blast
+	// detection follows daily cycles such as nightly batch jobs
 	SeasonalPatterns bool `yaml:"seasonalPatterns" json:"seasonalPatterns"`
+
+	// Bucket seasonal baselines by day of week and hour instead of hour of day.
+	// Needs a trendAnalysis.analysisWindow of at least a week.
+	SeasonalDayOfWeek bool `yaml:"seasonalDayOfWeek" json:"seasonalDayOfWeek"`
 }
 
 // SafetyMarginConfig defines safety margins for auto-calculated thresholds
@@ -416,6 +911,48 @@ type RateLimitConfig struct {
 
 	// Rate limit window
 	Window time.Duration `yaml:"window" json:"window"`
+
+	// Per-tenant overrides keyed by tenant glob pattern. Exact tenant IDs win over
+	// patterns, then the longest matching pattern. Zero fields inherit the defaults above.
+	TenantOverrides map[string]RateLimitOverride `yaml:"tenantOverrides" json:"tenantOverrides"`
+}
+
+// RateLimitOverride overrides the token bucket of the tenants matching a pattern
+type RateLimitOverride struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond"`
+	BurstCapacity     int     `yaml:"burstCapacity" json:"burstCapacity"`
+}
+
+// ForTenant returns the token bucket settings of a tenant and the tenantOverrides
+// pattern they come from, or "" when the tenant uses the defaults
+func (r RateLimitConfig) ForTenant(tenant string) (RateLimitOverride, string) {
+	settings := RateLimitOverride{RequestsPerSecond: r.RequestsPerSecond, BurstCapacity: r.BurstCapacity}
+
+	matched := ""
+	if _, exact := r.TenantOverrides[tenant]; exact {
+		matched = tenant
+	} else {
+		for pattern := range r.TenantOverrides {
+			if ok, err := path.Match(pattern, tenant); err != nil || !ok {
+				continue
+			}
+			if matched == "" || len(pattern) > len(matched) || (len(pattern) == len(matched) && pattern < matched) {
+				matched = pattern
+			}
+		}
+	}
+	if matched == "" {
+		return settings, ""
+	}
+
+	override := r.TenantOverrides[matched]
+	if override.RequestsPerSecond > 0 {
+		settings.RequestsPerSecond = override.RequestsPerSecond
+	}
+	if override.BurstCapacity > 0 {
+		settings.BurstCapacity = override.BurstCapacity
+	}
+	return settings, matched
 }
 
 type BlastProtectionConfig struct {
@@ -425,14 +962,28 @@ type BlastProtectionConfig struct {
 	// Use automatic threshold calculation based on current limits
 	UseAutoThresholds bool `yaml:"useAutoThresholds" json:"useAutoThresholds"`
 
+	// Per-limit threshold source ("auto" or "manual") when auto thresholds are used
+	ThresholdSources ThresholdSourceConfig `yaml:"thresholdSources" json:"thresholdSources"`
+
 	// Automatic emergency shutdown
 	AutoEmergencyShutdown bool `yaml:"autoEmergencyShutdown" json:"autoEmergencyShutdown"`
 
 	// Recovery time after blast
 	RecoveryTime time.Duration `yaml:"recoveryTime" json:"recoveryTime"`
 
+	// Multiple of its baseline above which a tenant rate is a blast
+	BaselineMultiplier float64 `yaml:"baselineMultiplier" json:"baselineMultiplier"`
+
 	// Per-tenant threshold overrides
 	TenantOverrides map[string]ManualThresholdConfig `yaml:"tenantOverrides" json:"tenantOverrides"`
+
+	// Interval at which the tenant baseline rates are checkpointed to a ConfigMap so
+	// they survive restarts (0 disables persistence)
+	BaselineCheckpointInterval time.Duration `yaml:"baselineCheckpointInterval" json:"baselineCheckpointInterval"`
+
+	// Maximum age of a checkpointed baseline reloaded at startup; older baselines are
+	// recomputed from scratch
+	BaselineMaxAge time.Duration `yaml:"baselineMaxAge" json:"baselineMaxAge"`
 }
 
 // ManualThresholdConfig defines manual threshold values
@@ -447,6 +998,14 @@ type ManualThresholdConfig struct {
 	SeriesSpikeThreshold float64 `yaml:"seriesSpikeThreshold" json:"seriesSpikeThreshold"`
 }
 
+// ThresholdSourceConfig selects per limit whether blast detection uses the auto-calculated
+// or the manual threshold. Auto thresholds fall back to the manual ones until warmed up.
+type ThresholdSourceConfig struct {
+	Ingestion string `yaml:"ingestion" json:"ingestion"`
+	Query     string `yaml:"query" json:"query"`
+	Series    string `yaml:"series" json:"series"`
+}
+
 // EmergencyConfig defines emergency controls
 type EmergencyConfig struct {
 	// Enable emergency controls
@@ -461,7 +1020,8 @@ type EmergencyConfig struct {
 	// Panic mode configuration
 	PanicMode PanicModeConfig `yaml:"panicMode" json:"panicMode"`
 
-	// Emergency shutdown triggers
+	// Emergency shutdown triggers, evaluated with the panic mode thresholds every
+	// recoveryProcedures.checkInterval
 	ShutdownTriggers []EmergencyTrigger `yaml:"shutdownTriggers" json:"shutdownTriggers"`
 
 	// Recovery procedures
@@ -481,15 +1041,31 @@ type PanicModeConfig struct {
 	// Error rate threshold for panic mode (errors/sec)
 	ErrorRateThreshold float64 `yaml:"errorRateThreshold" json:"errorRateThreshold"`
 
-	// Actions to take in panic mode
+	// Actions run when emergency or panic mode is entered: reduce_limits,
+	// throttle_ingestion and alert
 	Actions []string `yaml:"actions" json:"actions"`
+
+	// Percentage cut of the ingestion and series limits by reduce_limits
+	LimitReductionPercent float64 `yaml:"limitReductionPercent" json:"limitReductionPercent"`
+
+	// request_rate (requests/sec) and request_burst_size set by throttle_ingestion.
+	// Tenants with lower values keep them.
+	ThrottleRequestRate      float64 `yaml:"throttleRequestRate" json:"throttleRequestRate"`
+	ThrottleRequestBurstSize int64   `yaml:"throttleRequestBurstSize" json:"throttleRequestBurstSize"`
 }
 
+// Emergency actions accepted in emergency.panicMode.actions
+const (
+	EmergencyActionReduceLimits      = "reduce_limits"
+	EmergencyActionThrottleIngestion = "throttle_ingestion"
+	EmergencyActionAlert             = "alert"
+)
+
 type EmergencyTrigger struct {
 	// Trigger name
 	Name string `yaml:"name" json:"name"`
 
-	// Metric to monitor
+	// Metric to monitor: cpu_percent, memory_percent, error_rate or a PromQL expression
 	Metric string `yaml:"metric" json:"metric"`
 
 	// Threshold value
@@ -498,7 +1074,7 @@ type EmergencyTrigger struct {
 	// Duration threshold must be exceeded
 	Duration time.Duration `yaml:"duration" json:"duration"`
 
-	// Action to take
+	// Action to take: emergency_mode (default) or panic_mode
 	Action string `yaml:"action" json:"action"`
 }
 
@@ -512,7 +1088,8 @@ type RecoveryConfig struct {
 	// Health check timeout
 	HealthCheckTimeout time.Duration `yaml:"healthCheckTimeout" json:"healthCheckTimeout"`
 
-	// Maximum recovery attempts
+	// Maximum automatic exits from emergency mode before the triggers must stay healthy
+	// for ten checks again (0 is unlimited)
 	MaxAttempts int `yaml:"maxAttempts" json:"maxAttempts"`
 }
 
@@ -533,11 +1110,18 @@ type AlertingConfig struct {
 	// Webhook endpoints
 	Webhooks []WebhookConfig `yaml:"webhooks" json:"webhooks"`
 
-	// Alert routing rules
+	// Channels for alerts no routing rule matches (empty means all enabled channels)
+	DefaultChannels []string `yaml:"defaultChannels" json:"defaultChannels"`
+
+	// Alert routing rules, evaluated in order; the first matching rule wins
 	RoutingRules []AlertRoutingRule `yaml:"routingRules" json:"routingRules"`
 
 	// Escalation policies
 	EscalationPolicies []EscalationPolicy `yaml:"escalationPolicies" json:"escalationPolicies"`
+
+	// ConfigMap in the Mimir namespace persisting the alert silences; empty keeps them
+	// in memory only
+	SilenceConfigMapName string `yaml:"silenceConfigMapName" json:"silenceConfigMapName"`
 }
 
 type SlackConfig struct {
@@ -546,6 +1130,14 @@ type SlackConfig struct {
 	Channel    string        `yaml:"channel" json:"channel"`
 	Username   string        `yaml:"username" json:"username"`
 	Timeout    time.Duration `yaml:"timeout" json:"timeout"`
+	// Prefix emergency and panic mode messages with an @here mention
+	MentionHere bool `yaml:"mentionHere" json:"mentionHere"`
+	// Suppress identical messages sent again within this window
+	DedupWindow time.Duration `yaml:"dedupWindow" json:"dedupWindow"`
+	// Emoji used as the bot icon, e.g. ":chart_with_upwards_trend:"
+	IconEmoji string `yaml:"iconEmoji" json:"iconEmoji"`
+	// Slack user (U...) or user group (S...) IDs mentioned on emergency and panic mode messages
+	MentionOnCritical []string `yaml:"mentionOnCritical" json:"mentionOnCritical"`
 }
 
 type PagerDutyConfig struct {
@@ -553,6 +1145,12 @@ type PagerDutyConfig struct {
 	IntegrationKey string        `yaml:"integrationKey" json:"integrationKey"`
 	Severity       string        `yaml:"severity" json:"severity"`
 	Timeout        time.Duration `yaml:"timeout" json:"timeout"`
+
+	// Events API v2 endpoint (override for EU service regions)
+	EventsURL string `yaml:"eventsURL" json:"eventsURL"`
+
+	// Maximum retries for rate-limited (429) or failed (5xx) requests
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries"`
 }
 
 type EmailConfig struct {
@@ -564,6 +1162,28 @@ type EmailConfig struct {
 	From     string   `yaml:"from" json:"from"`
 	To       []string `yaml:"to" json:"to"`
 	UseTLS   bool     `yaml:"useTLS" json:"useTLS"`
+
+	// TLS mode: "starttls", "tls" (implicit TLS, e.g. port 465) or "none".
+	// When empty, useTLS selects implicit TLS and STARTTLS is used otherwise.
+	TLSMode string `yaml:"tlsMode" json:"tlsMode"`
+
+	// Timeout for SMTP connections
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+
+	// Daily digest of limit changes
+	Digest EmailDigestConfig `yaml:"digest" json:"digest"`
+}
+
+// EmailDigestConfig configures the daily limit-change digest email. While the
+// digest is enabled only critical (P0) alerts are emailed immediately.
+type EmailDigestConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Time of day to send the digest (HH:MM, 24h clock)
+	SendAt string `yaml:"sendAt" json:"sendAt"`
+
+	// IANA time zone used to interpret sendAt
+	Timezone string `yaml:"timezone" json:"timezone"`
 }
 
 type WebhookConfig struct {
@@ -573,13 +1193,29 @@ type WebhookConfig struct {
 	Timeout time.Duration     `yaml:"timeout" json:"timeout"`
 	Enabled bool              `yaml:"enabled" json:"enabled"`
 	Method  string            `yaml:"method" json:"method"`
+
+	// Maximum retries for timed out or failed (429, 5xx) requests
+	MaxRetries int `yaml:"maxRetries" json:"maxRetries"`
+
+	// Delay before the first retry, doubled on every further retry
+	RetryBackoff time.Duration `yaml:"retryBackoff" json:"retryBackoff"`
+
+	// Upper bound for the delay between retries
+	MaxRetryBackoff time.Duration `yaml:"maxRetryBackoff" json:"maxRetryBackoff"`
+
+	// Go template rendering the JSON body from the alert, e.g.
+	// {"summary": {{ json .Title }}, "tenant": {{ json .Tenant }}}; the default payload is sent when empty
+	PayloadTemplate string `yaml:"payloadTemplate" json:"payloadTemplate"`
 }
 
 type AlertRoutingRule struct {
-	Name      string            `yaml:"name" json:"name"`
+	Name string `yaml:"name" json:"name"`
+	// Condition expression, e.g. severity == "critical" && tenant =~ "prod-.*"
 	Condition string            `yaml:"condition" json:"condition"`
 	Channels  []string          `yaml:"channels" json:"channels"`
 	Metadata  map[string]string `yaml:"metadata" json:"metadata"`
+	// Name of the escalation policy applied to unacknowledged alerts matching this rule
+	EscalationPolicy string `yaml:"escalationPolicy" json:"escalationPolicy"`
 }
 
 type EscalationPolicy struct {
@@ -610,6 +1246,22 @@ type PerformanceConfig struct {
 
 	// Compression settings
 	Compression CompressionConfig `yaml:"compression" json:"compression"`
+
+	// Retry budget for tenants whose limits repeatedly fail to be written
+	RetryBudget RetryBudgetConfig `yaml:"retryBudget" json:"retryBudget"`
+}
+
+// RetryBudgetConfig bounds how often the limits of one tenant are retried after failed
+// ConfigMap writes, so a tenant that can never be written does not hold back the others
+type RetryBudgetConfig struct {
+	// Failed writes after which a tenant is held back (0 disables the budget)
+	MaxAttemptsPerTenant int `yaml:"maxAttemptsPerTenant" json:"maxAttemptsPerTenant"`
+
+	// How long a tenant that exhausted its budget is held back before it is retried
+	BudgetResetInterval time.Duration `yaml:"budgetResetInterval" json:"budgetResetInterval"`
+
+	// Record held back tenants and their last error in the mimir-optimizer-dead-letter ConfigMap
+	DeadLetterEnabled bool `yaml:"deadLetterEnabled" json:"deadLetterEnabled"`
 }
 
 type CacheConfig struct {
@@ -622,7 +1274,7 @@ type CacheConfig struct {
 	// Cache size (MB)
 	SizeMB int `yaml:"sizeMB" json:"sizeMB"`
 
-	// Cache type: "memory", "redis", "memcached"
+	// Cache type: "memory" or "redis"
 	Type string `yaml:"type" json:"type"`
 
 	// Redis configuration (if type is redis)
@@ -633,6 +1285,15 @@ type RedisConfig struct {
 	Address  string `yaml:"address" json:"address"`
 	Password string `yaml:"password" json:"password"`
 	DB       int    `yaml:"db" json:"db"`
+
+	// Maximum number of pooled connections (0 uses the client default of 10 per CPU)
+	PoolSize int `yaml:"poolSize" json:"poolSize"`
+
+	// Dial, read and write timeout
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+
+	// Prefix for all keys written by the optimizer
+	KeyPrefix string `yaml:"keyPrefix" json:"keyPrefix"`
 }
 
 type BatchConfig struct {
@@ -689,15 +1350,15 @@ type DynamicLimitsConfig struct {
 
 // LimitDefinition defines how to handle a specific limit type
 type LimitDefinition struct {
-	Name         string      `yaml:"name"`
-	Type         string      `yaml:"type"` // "rate", "count", "size", "duration", "percentage"
-	MetricSource string      `yaml:"metric_source"`
-	DefaultValue interface{} `yaml:"default_value"`
-	MinValue     interface{} `yaml:"min_value"`
-	MaxValue     interface{} `yaml:"max_value"`
-	BufferFactor float64     `yaml:"buffer_factor"`
-	Enabled      bool        `yaml:"enabled"`
-	Description  string      `yaml:"description"`
+	Name         string     `yaml:"name"`
+	Type         string     `yaml:"type"` // "rate", "count", "size", "duration", "percentage"
+	MetricSource string     `yaml:"metric_source"`
+	DefaultValue LimitValue `yaml:"default_value"`
+	MinValue     LimitValue `yaml:"min_value"`
+	MaxValue     LimitValue `yaml:"max_value"`
+	BufferFactor float64    `yaml:"buffer_factor"`
+	Enabled      bool       `yaml:"enabled"`
+	Description  string     `yaml:"description"`
 }
 
 // UIConfig holds web UI configuration
@@ -707,6 +1368,110 @@ type UIConfig struct {
 
 	// Port for the web UI and API server
 	Port int `yaml:"port" json:"port"`
+
+	// Provision a Grafana dashboard of the optimizer's own metrics as a ConfigMap on
+	// startup, for the Grafana dashboard sidecar to load
+	GenerateDashboard bool `yaml:"generateDashboard" json:"generateDashboard"`
+
+	// Namespace of the dashboard ConfigMap, watched by the Grafana sidecar
+	DashboardNamespace string `yaml:"dashboardNamespace" json:"dashboardNamespace"`
+
+	// Grafana folder the dashboard is placed in
+	DashboardFolder string `yaml:"dashboardFolder" json:"dashboardFolder"`
+
+	// Per-client rate limiting of the API
+	RateLimit APIRateLimitConfig `yaml:"rateLimit" json:"rateLimit"`
+
+	// URL of the Swagger UI of the API, which GET /api/v1 redirects clients that do not
+	// accept JSON to; empty serves the endpoint catalog to every client
+	APIDocsURL string `yaml:"apiDocsURL" json:"apiDocsURL"`
+
+	// Authentication and authorization of the API
+	Auth APIAuthConfig `yaml:"auth" json:"auth"`
+
+	// TLS of the UI and API listener; without a certificate it serves plain HTTP
+	TLS APITLSConfig `yaml:"tls" json:"tls"`
+}
+
+// APITLSConfig defines the certificate the UI and API listener serves HTTPS with. TLS is
+// enabled when certFile and keyFile are set; both files are read again when they change,
+// as when the Secret they are mounted from is rotated.
+type APITLSConfig struct {
+	// PEM certificate chain of the listener
+	CertFile string `yaml:"certFile" json:"certFile"`
+
+	// PEM private key of the certificate
+	KeyFile string `yaml:"keyFile" json:"keyFile"`
+
+	// PEM bundle of the CAs client certificates must be signed by; when set every client
+	// must present one (mutual TLS)
+	ClientCAFile string `yaml:"clientCAFile" json:"clientCAFile"`
+}
+
+// Enabled reports whether the listener serves HTTPS
+func (t APITLSConfig) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// APIAuthConfig defines how API requests authenticate with a bearer token. Viewers may
+// send GET requests; every other method needs the admin role. /health, /metrics and the
+// UI assets stay unauthenticated.
+type APIAuthConfig struct {
+	// Require authentication for the /api endpoints
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// File of static bearer tokens with the user and role of each, typically mounted from
+	// a Secret; it is read again when it changes
+	TokensFile string `yaml:"tokensFile" json:"tokensFile"`
+
+	// Authenticate Kubernetes service account and user tokens with a TokenReview
+	TokenReview APITokenReviewConfig `yaml:"tokenReview" json:"tokenReview"`
+}
+
+// APITokenReviewConfig maps the groups of tokens authenticated by the Kubernetes API to
+// API roles
+type APITokenReviewConfig struct {
+	// Enable TokenReview authentication, for tokens not in the tokens file
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Audiences the tokens must be issued for; empty accepts the API server's audiences
+	Audiences []string `yaml:"audiences" json:"audiences"`
+
+	// Groups granted the admin role
+	AdminGroups []string `yaml:"adminGroups" json:"adminGroups"`
+
+	// Groups granted the viewer role; empty grants it to no reviewed token
+	ViewerGroups []string `yaml:"viewerGroups" json:"viewerGroups"`
+
+	// How long a reviewed token is trusted before it is reviewed again
+	CacheTTL time.Duration `yaml:"cacheTTL" json:"cacheTTL"`
+}
+
+// APIRateLimitConfig defines the token buckets limiting the API requests of each client
+// IP. Endpoints without an override share one bucket per client; each override has a
+// bucket of its own per client.
+type APIRateLimitConfig struct {
+	// Enable API rate limiting
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Requests per second a client may send
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond"`
+
+	// Requests a client may send at once
+	BurstCapacity int `yaml:"burstCapacity" json:"burstCapacity"`
+
+	// Limits of single endpoints, keyed by route path such as "/api/infrastructure/scan"
+	// or "/api/tenants/{tenant_id}"
+	Endpoints map[string]APIEndpointRateLimit `yaml:"endpoints" json:"endpoints"`
+}
+
+// APIEndpointRateLimit overrides the rate limit of one API endpoint
+type APIEndpointRateLimit struct {
+	// Requests per second a client may send to the endpoint
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond"`
+
+	// Requests a client may send to the endpoint at once
+	BurstCapacity int `yaml:"burstCapacity" json:"burstCapacity"`
 }
 
 // HealthScannerConfig defines health scanner configuration
@@ -722,6 +1487,25 @@ type HealthScannerConfig struct {
 
 	// Maximum health check attempts
 	MaxAttempts int `yaml:"maxAttempts" json:"maxAttempts"`
+
+	// How long health scan results are kept for trend charts (0 disables the history)
+	HistoryRetention time.Duration `yaml:"historyRetention" json:"historyRetention"`
+
+	// Age of a health scan past which its score decays (0 means twice checkInterval)
+	StalenessThreshold time.Duration `yaml:"stalenessThreshold" json:"stalenessThreshold"`
+
+	// Score points deducted per second a scan is older than the staleness threshold
+	StalenessPenalty float64 `yaml:"stalenessPenalty" json:"stalenessPenalty"`
+
+	// Resource types listed in parallel by a scan (0 means 8)
+	MaxConcurrentScans int `yaml:"maxConcurrentScans" json:"maxConcurrentScans"`
+
+	// Objects requested per List call when reading from the API server (0 disables paging)
+	ListPageSize int64 `yaml:"listPageSize" json:"listPageSize"`
+
+	// Read workloads, pods, services and PVCs of the Mimir namespace from watches
+	// instead of listing them on every scan
+	WatchCache bool `yaml:"watchCache" json:"watchCache"`
 }
 
 // GetDefaultConfig returns a configuration with sensible defaults
@@ -740,34 +1524,69 @@ func GetDefaultConfig() *Config {
 			ConfigMapName:     getEnvOrDefault("MIMIR_CONFIGMAP_NAME", "mimir-runtime-overrides"),
 			TriggerRollout:    false,
 			RolloutComponents: []string{"ingester", "querier", "query-frontend"},
+			SecondaryCluster: ClusterConfig{
+				Enabled:       false,
+				Namespace:     "mimir",
+				ConfigMapName: "mimir-runtime-overrides",
+			},
+			DriftAlertThresholdPercent:  10.0,
+			ConfigMapFormat:             ConfigMapFormatMimirNative,
+			ConfigMapSizeWarningPercent: 80.0,
+			ReadinessFailureThreshold:   5,
+			Sharding: OverridesShardingConfig{
+				Enabled: false,
+				Shards:  4,
+			},
+			OverridesExporter: OverridesExporterConfig{
+				Enabled:             false,
+				MismatchGracePeriod: 10 * time.Minute,
+			},
+			ThanosModeEnabled:        false,
+			ThanosRulerConfigMapName: "thanos-ruler-limits",
 		},
 		TenantScoping: TenantScopingConfig{
-			SkipList:    []string{},
-			IncludeList: []string{},
-			UseRegex:    false,
+			SkipList:             []string{},
+			IncludeList:          []string{},
+			UseRegex:             false,
+			RuntimeConfigMapName: "mimir-limit-optimizer-tenant-scoping",
 		},
 		MetricsDiscovery: MetricsDiscoveryConfig{
-			Enabled:              false,
-			Namespace:            getEnvOrDefault("MIMIR_NAMESPACE", "mimir"),
-			ServiceLabelSelector: "mimir-metrics=true",
-			MetricsPath:          "/metrics",
-			PortName:             "http-metrics",
-			Port:                 8080,
+			Enabled:                false,
+			Namespace:              getEnvOrDefault("MIMIR_NAMESPACE", "mimir"),
+			ServiceLabelSelector:   "mimir-metrics=true",
+			ComponentLabelSelector: "app.kubernetes.io/name=mimir",
+			ComponentLabel:         "app.kubernetes.io/component",
+			MetricsPath:            "/metrics",
+			PortName:               "http-metrics",
+			Port:                   8080,
+			ScanScope:              ScanScopeCluster,
+			ScanNamespaces:         []string{},
 			TenantDiscovery: TenantDiscoveryConfig{
-				FallbackTenants: []string{}, // Empty by default, user can configure
-				ConfigMapNames:  []string{"overrides", "mimir-runtime-overrides", "runtime-config"},
-				EnableSynthetic: true,                    // Enable synthetic tenants as final fallback
-				SyntheticCount:  3,                       // Default to 3 synthetic tenants
-				MetricsTenantID: "",                      // Empty by default, user must configure for multi-tenant
-				TenantHeaders:   make(map[string]string), // Empty by default
+				FallbackTenants:        []string{}, // Empty by default, user can configure
+				ConfigMapNames:         []string{"overrides", "mimir-runtime-overrides", "runtime-config"},
+				EnableSynthetic:        true,                    // Enable synthetic tenants as final fallback
+				SyntheticCount:         3,                       // Default to 3 synthetic tenants
+				MetricsTenantID:        "",                      // Empty by default, user must configure for multi-tenant
+				TenantHeaders:          make(map[string]string), // Empty by default
+				NamespaceAnnotations:   false,
+				NamespaceLabelSelector: "",
 			},
 		},
 		EventSpike: EventSpikeConfig{
-			Enabled:            true,
-			Threshold:          2.0,
-			DetectionWindow:    5 * time.Minute,
-			CooldownPeriod:     30 * time.Minute,
-			MaxSpikeMultiplier: 5.0,
+			Enabled:                 true,
+			Threshold:               2.0,
+			DetectionWindow:         5 * time.Minute,
+			CooldownPeriod:          30 * time.Minute,
+			MaxSpikeMultiplier:      5.0,
+			DecayStepPercent:        25,
+			DecayInterval:           5 * time.Minute,
+			PredictiveSpike:         false,
+			PredictiveCheckInterval: 30 * time.Second,
+		},
+		AnomalyDetection: AnomalyConfig{
+			Enabled:          false,
+			ZScoreThreshold:  3.0,
+			MinPeerGroupSize: 5,
 		},
 		TrendAnalysis: TrendAnalysisConfig{
 			AnalysisWindow:   48 * time.Hour,
@@ -775,6 +1594,17 @@ func GetDefaultConfig() *Config {
 			UseMovingAverage: true,
 			IncludePeaks:     true,
 			TimeOfDayBuffers: make(map[string]float64),
+			Seasonality: SeasonalityConfig{
+				Enabled:          false,
+				Metric:           "cortex_distributor_received_samples_total",
+				MinHistory:       14 * 24 * time.Hour,
+				Retention:        28 * 24 * time.Hour,
+				ThresholdPercent: 20,
+			},
+			QueryPath: QueryPathConfig{
+				Enabled:    false,
+				RateWindow: 5 * time.Minute,
+			},
 		},
 		Limits: LimitsConfig{
 			MinLimits:         make(map[string]interface{}),
@@ -782,6 +1612,14 @@ func GetDefaultConfig() *Config {
 			DefaultLimits:     make(map[string]interface{}),
 			InactiveTenantTTL: 7 * 24 * time.Hour,
 			TenantTiers:       make(map[string]TenantTierConfig),
+			TierAssignments:   make(map[string]string),
+			RemoteOverrideSource: RemoteOverrideConfig{
+				Enabled:      false,
+				PollInterval: 5 * time.Minute,
+				Headers:      make(map[string]string),
+				Format:       "yaml",
+				Timeout:      30 * time.Second,
+			},
 		},
 		AuditLog: AuditLogConfig{
 			Enabled:       true,
@@ -797,14 +1635,28 @@ func GetDefaultConfig() *Config {
 				EmergencyThresholdPercent: 90.0,               // Emergency cleanup at 90% capacity
 			},
 		},
+		RecommendationHistory: RecommendationHistoryConfig{
+			Enabled:             true,
+			StorageType:         "memory",
+			ConfigMapName:       "mimir-limit-optimizer-recommendation-history",
+			Retention:           14 * 24 * time.Hour,
+			MaxEntriesPerTenant: 1000,
+			SampleInterval:      1 * time.Hour,
+		},
 		Synthetic: SyntheticConfig{
 			Enabled:     false,
 			TenantCount: 10,
+			MetricsConfig: SyntheticMetricsConfig{
+				Seed:             1,
+				TimeAcceleration: 1,
+				SampleInterval:   1 * time.Minute,
+			},
 		},
 		CostControl: CostControlConfig{
 			Enabled:            true,
 			CostMethod:         "composite",
 			CostPerUnit:        0.001, // $0.001 per million samples
+			CostWeights:        CostWeights{Samples: 0.4, Series: 0.3, Queries: 0.3},
 			TenantBudgets:      make(map[string]BudgetConfig),
 			GlobalBudget:       BudgetConfig{Daily: 1000, Monthly: 30000, Annual: 365000, Currency: "USD", EnforceBudget: false},
 			AlertThresholds:    []float64{50, 75, 90, 95},
@@ -812,13 +1664,14 @@ func GetDefaultConfig() *Config {
 			EstimationWindow:   24 * time.Hour,
 		},
 		CircuitBreaker: CircuitBreakerConfig{
-			Enabled:                circuitBreakerEnabled, // Disabled in dry-run, enabled in prod
-			RuntimeEnabled:         circuitBreakerEnabled, // Disabled in dry-run, enabled in prod
-			Mode:                   "auto",                // "manual", "auto", "hybrid"
-			FailureThreshold:       50.0,
-			RequestVolumeThreshold: 20,
-			SleepWindow:            30 * time.Second,
-			MaxRequestsInHalfOpen:  5,
+			Enabled:                  circuitBreakerEnabled, // Disabled in dry-run, enabled in prod
+			RuntimeEnabled:           circuitBreakerEnabled, // Disabled in dry-run, enabled in prod
+			Mode:                     "auto",                // "manual", "auto", "hybrid"
+			FailureThreshold:         50.0,
+			RequestVolumeThreshold:   20,
+			SleepWindow:              30 * time.Second,
+			MaxRequestsInHalfOpen:    5,
+			HalfOpenSuccessThreshold: 3,
 			AutoConfig: AutoCircuitBreakerConfig{
 				Enabled:              true,
 				BaselineWindow:       24 * time.Hour,
@@ -830,12 +1683,13 @@ func GetDefaultConfig() *Config {
 					BurstMultiplier:         1.2, // Trip at 120% of burst limit
 				},
 				RealtimeAdaptation: RealtimeAdaptationConfig{
-					Enabled:          true,
-					Interval:         5 * time.Minute,
-					LearningRate:     0.1,
-					MaxChangePercent: 20.0,
-					Percentile:       95.0,
-					SeasonalPatterns: false,
+					Enabled:           true,
+					Interval:          5 * time.Minute,
+					LearningRate:      0.1,
+					MaxChangePercent:  20.0,
+					Percentile:        95.0,
+					SeasonalPatterns:  false,
+					SeasonalDayOfWeek: false,
 				},
 				SafetyMargins: SafetyMarginConfig{
 					MinMargin:     10.0,
@@ -849,17 +1703,26 @@ func GetDefaultConfig() *Config {
 				RequestsPerSecond: 100,
 				BurstCapacity:     200,
 				Window:            time.Minute,
+				TenantOverrides:   make(map[string]RateLimitOverride),
 			},
 			BlastProtection: BlastProtectionConfig{
 				UseAutoThresholds: true,
+				ThresholdSources: ThresholdSourceConfig{
+					Ingestion: "auto",
+					Query:     "auto",
+					Series:    "auto",
+				},
 				ManualThresholds: ManualThresholdConfig{
 					IngestionSpikeThreshold: 1000000, // Fallback values
 					QuerySpikeThreshold:     10000,
 					SeriesSpikeThreshold:    100000,
 				},
-				AutoEmergencyShutdown: true,
-				RecoveryTime:          5 * time.Minute,
-				TenantOverrides:       make(map[string]ManualThresholdConfig),
+				AutoEmergencyShutdown:      true,
+				RecoveryTime:               5 * time.Minute,
+				BaselineMultiplier:         5.0,
+				TenantOverrides:            make(map[string]ManualThresholdConfig),
+				BaselineCheckpointInterval: 10 * time.Minute,
+				BaselineMaxAge:             24 * time.Hour,
 			},
 		},
 		Emergency: EmergencyConfig{
@@ -870,6 +1733,10 @@ func GetDefaultConfig() *Config {
 				MemoryThreshold:    90.0,
 				ErrorRateThreshold: 100,
 				Actions:            []string{"reduce_limits", "throttle_ingestion", "alert"},
+
+				LimitReductionPercent:    50,
+				ThrottleRequestRate:      10,
+				ThrottleRequestBurstSize: 20,
 			},
 			RecoveryProcedures: RecoveryConfig{
 				AutoRecovery:       true,
@@ -879,9 +1746,28 @@ func GetDefaultConfig() *Config {
 			},
 		},
 		Alerting: AlertingConfig{
-			Enabled:            true,
-			RoutingRules:       []AlertRoutingRule{},
-			EscalationPolicies: []EscalationPolicy{},
+			Enabled: true,
+			Slack: SlackConfig{
+				Username:    "mimir-limit-optimizer",
+				Timeout:     10 * time.Second,
+				DedupWindow: 5 * time.Minute,
+			},
+			Email: EmailConfig{
+				Timeout: 30 * time.Second,
+				Digest: EmailDigestConfig{
+					SendAt:   "08:00",
+					Timezone: "UTC",
+				},
+			},
+			PagerDuty: PagerDutyConfig{
+				Severity:   "critical",
+				Timeout:    10 * time.Second,
+				EventsURL:  "https://events.pagerduty.com/v2/enqueue",
+				MaxRetries: 3,
+			},
+			RoutingRules:         []AlertRoutingRule{},
+			EscalationPolicies:   []EscalationPolicy{},
+			SilenceConfigMapName: "mimir-limit-optimizer-silences",
 		},
 		Performance: PerformanceConfig{
 			Enabled: true,
@@ -910,6 +1796,11 @@ func GetDefaultConfig() *Config {
 				Algorithm: "gzip",
 				Level:     6,
 			},
+			RetryBudget: RetryBudgetConfig{
+				MaxAttemptsPerTenant: 5,
+				BudgetResetInterval:  time.Hour,
+				DeadLetterEnabled:    true,
+			},
 		},
 		DynamicLimits: DynamicLimitsConfig{
 			Enabled:          true,
@@ -918,14 +1809,36 @@ func GetDefaultConfig() *Config {
 			AutoDetect:       true,
 		},
 		UI: UIConfig{
-			Enabled: true,
-			Port:    8082,
+			Enabled:            true,
+			Port:               8082,
+			GenerateDashboard:  false,
+			DashboardNamespace: "grafana",
+			DashboardFolder:    "Mimir",
+			RateLimit: APIRateLimitConfig{
+				Enabled:           true,
+				RequestsPerSecond: 20,
+				BurstCapacity:     40,
+				Endpoints: map[string]APIEndpointRateLimit{
+					"/api/infrastructure/scan": {RequestsPerSecond: 0.1, BurstCapacity: 2},
+				},
+			},
+			Auth: APIAuthConfig{
+				Enabled: false,
+				TokenReview: APITokenReviewConfig{
+					CacheTTL: time.Minute,
+				},
+			},
 		},
 		HealthScanner: HealthScannerConfig{
 			Enabled:            true,
 			CheckInterval:      5 * time.Minute,
 			HealthCheckTimeout: 10 * time.Second,
 			MaxAttempts:        3,
+			HistoryRetention:   6 * time.Hour,
+			StalenessPenalty:   0.1,
+			MaxConcurrentScans: 8,
+			ListPageSize:       500,
+			WatchCache:         true,
 		},
 	}
 }
@@ -945,6 +1858,7 @@ func LoadConfig() (*Config, error) {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
 	}
+	cfg.ApplyLimitSelection()
 
 	return cfg, nil
 }
@@ -954,8 +1868,10 @@ func LoadConfigFromFile(configFile string) (*Config, error) {
 	cfg := GetDefaultConfig()
 
 	// Load from specified file if provided
+	var data []byte
 	if configFile != "" {
-		data, err := os.ReadFile(configFile)
+		var err error
+		data, err = os.ReadFile(configFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read config file %s: %w", configFile, err)
 		}
@@ -964,6 +1880,11 @@ func LoadConfigFromFile(configFile string) (*Config, error) {
 			return nil, fmt.Errorf("failed to parse config file %s: %w", configFile, err)
 		}
 	}
+	cfg.ApplyLimitSelection()
+
+	sum := sha256.Sum256(data)
+	cfg.SourceHash = hex.EncodeToString(sum[:])
+	cfg.LoadedAt = time.Now()
 
 	return cfg, nil
 }
@@ -989,6 +1910,10 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("updateInterval must be positive, got %v", c.UpdateInterval)
 	}
 
+	if err := c.TenantScoping.validate(); err != nil {
+		return err
+	}
+
 	if c.Mimir.Namespace == "" {
 		return fmt.Errorf("mimir.namespace cannot be empty")
 	}
@@ -997,6 +1922,60 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("mimir.configMapName cannot be empty")
 	}
 
+	switch c.Mimir.ConfigMapFormat {
+	case ConfigMapFormatFlat, ConfigMapFormatMimirNative, ConfigMapFormatSharded:
+	default:
+		return fmt.Errorf("mimir.configMapFormat must be %q, %q or %q, got %q",
+			ConfigMapFormatFlat, ConfigMapFormatMimirNative, ConfigMapFormatSharded, c.Mimir.ConfigMapFormat)
+	}
+	if c.Mimir.ConfigMapFormat == ConfigMapFormatSharded && c.Mimir.Sharding.Enabled {
+		return fmt.Errorf("mimir.sharding cannot be enabled with mimir.configMapFormat %q, which already shards per tenant", ConfigMapFormatSharded)
+	}
+
+	if c.Mimir.ThanosModeEnabled {
+		if c.Mimir.ThanosRulerConfigMapName == "" {
+			return fmt.Errorf("mimir.thanosRulerConfigMapName cannot be empty when mimir.thanosModeEnabled is set")
+		}
+		if c.Mimir.ThanosRulerConfigMapName == c.Mimir.ConfigMapName {
+			return fmt.Errorf("mimir.thanosRulerConfigMapName must differ from mimir.configMapName, got %q", c.Mimir.ThanosRulerConfigMapName)
+		}
+	}
+
+	if c.Mimir.ConfigMapSizeWarningPercent <= 0 || c.Mimir.ConfigMapSizeWarningPercent > 100 {
+		return fmt.Errorf("mimir.configMapSizeWarningPercent must be between 0 and 100, got %f", c.Mimir.ConfigMapSizeWarningPercent)
+	}
+
+	if c.Mimir.ReadinessFailureThreshold < 0 {
+		return fmt.Errorf("mimir.readinessFailureThreshold cannot be negative, got %d", c.Mimir.ReadinessFailureThreshold)
+	}
+
+	if c.Mimir.Sharding.Enabled && c.Mimir.Sharding.Shards < 1 {
+		return fmt.Errorf("mimir.sharding.shards must be at least 1, got %d", c.Mimir.Sharding.Shards)
+	}
+
+	if c.Mimir.SecondaryCluster.Enabled {
+		if c.Mimir.SecondaryCluster.Namespace == "" {
+			return fmt.Errorf("mimir.secondaryCluster.namespace cannot be empty")
+		}
+		if c.Mimir.SecondaryCluster.ConfigMapName == "" {
+			return fmt.Errorf("mimir.secondaryCluster.configMapName cannot be empty")
+		}
+		if c.Mimir.DriftAlertThresholdPercent < 0 {
+			return fmt.Errorf("mimir.driftAlertThresholdPercent cannot be negative, got %f", c.Mimir.DriftAlertThresholdPercent)
+		}
+	}
+
+	if c.Mimir.OverridesExporter.Enabled && c.Mimir.OverridesExporter.MismatchGracePeriod <= 0 {
+		return fmt.Errorf("mimir.overridesExporter.mismatchGracePeriod must be positive, got %v", c.Mimir.OverridesExporter.MismatchGracePeriod)
+	}
+
+	switch c.MetricsDiscovery.ScanScope {
+	case "", ScanScopeCluster, ScanScopeNamespaces:
+	default:
+		return fmt.Errorf("metricsDiscovery.scanScope must be %q or %q, got %q",
+			ScanScopeCluster, ScanScopeNamespaces, c.MetricsDiscovery.ScanScope)
+	}
+
 	if c.EventSpike.Enabled {
 		if c.EventSpike.Threshold <= 1.0 {
 			return fmt.Errorf("eventSpike.threshold must be greater than 1.0, got %f", c.EventSpike.Threshold)
@@ -1007,6 +1986,24 @@ func (c *Config) Validate() error {
 		if c.EventSpike.CooldownPeriod <= 0 {
 			return fmt.Errorf("eventSpike.cooldownPeriod must be positive, got %v", c.EventSpike.CooldownPeriod)
 		}
+		if c.EventSpike.DecayStepPercent <= 0 || c.EventSpike.DecayStepPercent > 100 {
+			return fmt.Errorf("eventSpike.decayStepPercent must be between 0 and 100, got %f", c.EventSpike.DecayStepPercent)
+		}
+		if c.EventSpike.DecayInterval <= 0 {
+			return fmt.Errorf("eventSpike.decayInterval must be positive, got %v", c.EventSpike.DecayInterval)
+		}
+		if c.EventSpike.PredictiveSpike && c.EventSpike.PredictiveCheckInterval <= 0 {
+			return fmt.Errorf("eventSpike.predictiveCheckInterval must be positive when predictiveSpike is enabled, got %v", c.EventSpike.PredictiveCheckInterval)
+		}
+	}
+
+	if c.AnomalyDetection.Enabled {
+		if c.AnomalyDetection.ZScoreThreshold <= 0 {
+			return fmt.Errorf("anomalyDetection.zScoreThreshold must be positive, got %f", c.AnomalyDetection.ZScoreThreshold)
+		}
+		if c.AnomalyDetection.MinPeerGroupSize < 3 {
+			return fmt.Errorf("anomalyDetection.minPeerGroupSize must be at least 3, got %d", c.AnomalyDetection.MinPeerGroupSize)
+		}
 	}
 
 	if c.TrendAnalysis.AnalysisWindow <= 0 {
@@ -1017,9 +2014,438 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("trendAnalysis.percentile must be between 0 and 100, got %f", c.TrendAnalysis.Percentile)
 	}
 
+	for key, buffer := range c.TrendAnalysis.TimeOfDayBuffers {
+		if _, _, err := ParseTimeOfDayBucket(key); err != nil {
+			return fmt.Errorf("trendAnalysis.timeOfDayBuffers key %q is invalid: %w", key, err)
+		}
+		if buffer < 0 {
+			return fmt.Errorf("trendAnalysis.timeOfDayBuffers[%q] must be non-negative, got %f", key, buffer)
+		}
+	}
+
+	if seasonality := c.TrendAnalysis.Seasonality; seasonality.Enabled {
+		if seasonality.Metric == "" {
+			return fmt.Errorf("trendAnalysis.seasonality.metric is required when seasonality is enabled")
+		}
+		if seasonality.MinHistory < 7*24*time.Hour {
+			return fmt.Errorf("trendAnalysis.seasonality.minHistory must cover at least a week, got %v", seasonality.MinHistory)
+		}
+		if seasonality.Retention < seasonality.MinHistory {
+			return fmt.Errorf("trendAnalysis.seasonality.retention must be at least minHistory (%v), got %v", seasonality.MinHistory, seasonality.Retention)
+		}
+		if seasonality.ThresholdPercent < 0 {
+			return fmt.Errorf("trendAnalysis.seasonality.thresholdPercent must be non-negative, got %f", seasonality.ThresholdPercent)
+		}
+	}
+
+	if queryPath := c.TrendAnalysis.QueryPath; queryPath.Enabled {
+		if c.MetricsEndpoint == "" {
+			return fmt.Errorf("metricsEndpoint is required when trendAnalysis.queryPath is enabled")
+		}
+		if queryPath.RateWindow <= 0 {
+			return fmt.Errorf("trendAnalysis.queryPath.rateWindow must be positive, got %v", queryPath.RateWindow)
+		}
+	}
+
+	if errs := c.ValidateLimitOverrides(); len(errs) > 0 {
+		return errs
+	}
+
+	if err := c.validateLimitDefinitions(); err != nil {
+		return err
+	}
+
+	if err := c.validateTenantTiers(); err != nil {
+		return err
+	}
+
+	if remote := c.Limits.RemoteOverrideSource; remote.Enabled {
+		if !strings.HasPrefix(remote.URL, "http://") && !strings.HasPrefix(remote.URL, "https://") {
+			return fmt.Errorf("limits.remoteOverrideSource.url must be an http or https URL, got %q", remote.URL)
+		}
+		if remote.PollInterval <= 0 {
+			return fmt.Errorf("limits.remoteOverrideSource.pollInterval must be positive, got %v", remote.PollInterval)
+		}
+		if remote.Format != "yaml" && remote.Format != "json" {
+			return fmt.Errorf("limits.remoteOverrideSource.format must be yaml or json, got %q", remote.Format)
+		}
+	}
+
+	if c.UI.GenerateDashboard && c.UI.DashboardNamespace == "" {
+		return fmt.Errorf("ui.dashboardNamespace cannot be empty when ui.generateDashboard is enabled")
+	}
+
+	if c.HealthScanner.StalenessThreshold < 0 {
+		return fmt.Errorf("healthScanner.stalenessThreshold cannot be negative, got %v", c.HealthScanner.StalenessThreshold)
+	}
+	if c.HealthScanner.StalenessPenalty < 0 {
+		return fmt.Errorf("healthScanner.stalenessPenalty cannot be negative, got %f", c.HealthScanner.StalenessPenalty)
+	}
+	if c.HealthScanner.HistoryRetention < 0 {
+		return fmt.Errorf("healthScanner.historyRetention cannot be negative, got %v", c.HealthScanner.HistoryRetention)
+	}
+	if c.HealthScanner.MaxConcurrentScans < 0 {
+		return fmt.Errorf("healthScanner.maxConcurrentScans cannot be negative, got %d", c.HealthScanner.MaxConcurrentScans)
+	}
+	if c.HealthScanner.ListPageSize < 0 {
+		return fmt.Errorf("healthScanner.listPageSize cannot be negative, got %d", c.HealthScanner.ListPageSize)
+	}
+
+	if history := c.RecommendationHistory; history.Enabled {
+		if history.StorageType != "memory" && history.StorageType != "configmap" {
+			return fmt.Errorf("recommendationHistory.storageType must be memory or configmap, got %q", history.StorageType)
+		}
+		if history.StorageType == "configmap" && history.ConfigMapName == "" {
+			return fmt.Errorf("recommendationHistory.configMapName is required for configmap storage")
+		}
+		if history.Retention <= 0 {
+			return fmt.Errorf("recommendationHistory.retention must be positive, got %v", history.Retention)
+		}
+		if history.MaxEntriesPerTenant < 0 {
+			return fmt.Errorf("recommendationHistory.maxEntriesPerTenant cannot be negative, got %d", history.MaxEntriesPerTenant)
+		}
+		if history.SampleInterval < 0 {
+			return fmt.Errorf("recommendationHistory.sampleInterval cannot be negative, got %v", history.SampleInterval)
+		}
+	}
+
+	if c.Synthetic.Enabled {
+		if err := c.Synthetic.MetricsConfig.validate(); err != nil {
+			return err
+		}
+	}
+
+	if slack := c.Alerting.Slack; slack.Enabled &&
+		!strings.HasPrefix(slack.WebhookURL, "https://") && !strings.HasPrefix(slack.WebhookURL, "http://") {
+		return fmt.Errorf("alerting.slack.webhookURL must be an http or https URL")
+	}
+
+	if c.Alerting.Slack.Enabled && c.Alerting.Slack.DedupWindow < 0 {
+		return fmt.Errorf("alerting.slack.dedupWindow cannot be negative, got %v", c.Alerting.Slack.DedupWindow)
+	}
+
+	if c.Alerting.Email.Enabled {
+		switch c.Alerting.Email.TLSMode {
+		case "", "starttls", "tls", "none":
+		default:
+			return fmt.Errorf("alerting.email.tlsMode must be one of starttls, tls, none, got %s", c.Alerting.Email.TLSMode)
+		}
+		if c.Alerting.Email.Digest.Enabled {
+			if _, err := time.Parse("15:04", c.Alerting.Email.Digest.SendAt); err != nil {
+				return fmt.Errorf("alerting.email.digest.sendAt must be in HH:MM format, got %s", c.Alerting.Email.Digest.SendAt)
+			}
+			if _, err := time.LoadLocation(c.Alerting.Email.Digest.Timezone); err != nil {
+				return fmt.Errorf("alerting.email.digest.timezone is invalid: %w", err)
+			}
+		}
+	}
+
+	if c.Alerting.PagerDuty.Enabled {
+		switch c.Alerting.PagerDuty.Severity {
+		case "", "critical", "error", "warning", "info":
+		default:
+			return fmt.Errorf("alerting.pagerDuty.severity must be one of critical, error, warning, info, got %s", c.Alerting.PagerDuty.Severity)
+		}
+		if c.Alerting.PagerDuty.MaxRetries < 0 {
+			return fmt.Errorf("alerting.pagerDuty.maxRetries cannot be negative, got %d", c.Alerting.PagerDuty.MaxRetries)
+		}
+	}
+
+	for _, webhook := range c.Alerting.Webhooks {
+		if !webhook.Enabled {
+			continue
+		}
+		if webhook.MaxRetries < 0 {
+			return fmt.Errorf("alerting.webhooks[%s].maxRetries cannot be negative, got %d", webhook.Name, webhook.MaxRetries)
+		}
+		if webhook.RetryBackoff < 0 || webhook.MaxRetryBackoff < 0 {
+			return fmt.Errorf("alerting.webhooks[%s] retry backoff cannot be negative", webhook.Name)
+		}
+	}
+
+	policies := make(map[string]bool, len(c.Alerting.EscalationPolicies))
+	for _, policy := range c.Alerting.EscalationPolicies {
+		policies[policy.Name] = true
+		for _, level := range policy.Levels {
+			if level.Delay < 0 {
+				return fmt.Errorf("alerting.escalationPolicies[%s] level %d delay cannot be negative, got %v", policy.Name, level.Level, level.Delay)
+			}
+		}
+	}
+	for _, rule := range c.Alerting.RoutingRules {
+		if rule.EscalationPolicy != "" && !policies[rule.EscalationPolicy] {
+			return fmt.Errorf("alerting.routingRules[%s] references unknown escalation policy %s", rule.Name, rule.EscalationPolicy)
+		}
+	}
+
+	if budget := c.Performance.RetryBudget; budget.MaxAttemptsPerTenant < 0 {
+		return fmt.Errorf("performance.retryBudget.maxAttemptsPerTenant cannot be negative, got %d", budget.MaxAttemptsPerTenant)
+	} else if budget.MaxAttemptsPerTenant > 0 && budget.BudgetResetInterval <= 0 {
+		return fmt.Errorf("performance.retryBudget.budgetResetInterval must be positive, got %v", budget.BudgetResetInterval)
+	}
+
+	if breaker := c.CircuitBreaker; breaker.Enabled {
+		if breaker.MaxRequestsInHalfOpen < 1 {
+			return fmt.Errorf("circuitBreaker.maxRequestsInHalfOpen must be at least 1, got %d", breaker.MaxRequestsInHalfOpen)
+		}
+		if breaker.HalfOpenSuccessThreshold < 1 {
+			return fmt.Errorf("circuitBreaker.halfOpenSuccessThreshold must be at least 1, got %d", breaker.HalfOpenSuccessThreshold)
+		}
+		sources := breaker.BlastProtection.ThresholdSources
+		for _, source := range []struct{ name, value string }{
+			{"ingestion", sources.Ingestion}, {"query", sources.Query}, {"series", sources.Series},
+		} {
+			if source.value != "auto" && source.value != "manual" {
+				return fmt.Errorf("circuitBreaker.blastProtection.thresholdSources.%s must be auto or manual, got %q", source.name, source.value)
+			}
+		}
+		if multiplier := breaker.BlastProtection.BaselineMultiplier; multiplier != 0 && multiplier <= 1 {
+			return fmt.Errorf("circuitBreaker.blastProtection.baselineMultiplier must be greater than 1, got %v", multiplier)
+		}
+		if protection := breaker.BlastProtection; protection.BaselineCheckpointInterval < 0 {
+			return fmt.Errorf("circuitBreaker.blastProtection.baselineCheckpointInterval cannot be negative, got %v", protection.BaselineCheckpointInterval)
+		} else if protection.BaselineCheckpointInterval > 0 && protection.BaselineMaxAge <= 0 {
+			return fmt.Errorf("circuitBreaker.blastProtection.baselineMaxAge must be positive when baselines are checkpointed, got %v", protection.BaselineMaxAge)
+		}
+		if rateLimit := breaker.RateLimit; rateLimit.Enabled {
+			if rateLimit.RequestsPerSecond <= 0 {
+				return fmt.Errorf("circuitBreaker.rateLimit.requestsPerSecond must be positive, got %v", rateLimit.RequestsPerSecond)
+			}
+			if rateLimit.BurstCapacity < 1 {
+				return fmt.Errorf("circuitBreaker.rateLimit.burstCapacity must be at least 1, got %d", rateLimit.BurstCapacity)
+			}
+			for pattern, override := range rateLimit.TenantOverrides {
+				if _, err := path.Match(pattern, ""); err != nil || strings.TrimSpace(pattern) == "" {
+					return fmt.Errorf("circuitBreaker.rateLimit.tenantOverrides has an invalid tenant glob %q", pattern)
+				}
+				if override.RequestsPerSecond < 0 || override.BurstCapacity < 0 {
+					return fmt.Errorf("circuitBreaker.rateLimit.tenantOverrides[%s] cannot be negative", pattern)
+				}
+			}
+		}
+	}
+
+	if cost := c.CostControl; cost.Enabled {
+		switch cost.CostMethod {
+		case "", "samples", "series", "queries", "composite":
+		default:
+			return fmt.Errorf("costControl.costMethod must be one of samples, series, queries, composite, got %q", cost.CostMethod)
+		}
+		if cost.CostPerUnit < 0 {
+			return fmt.Errorf("costControl.costPerUnit cannot be negative, got %v", cost.CostPerUnit)
+		}
+		weights := cost.CostWeights
+		if weights.Samples < 0 || weights.Series < 0 || weights.Queries < 0 || weights.Storage < 0 {
+			return fmt.Errorf("costControl.costWeights cannot be negative")
+		}
+		if (cost.CostMethod == "" || cost.CostMethod == "composite") && weights.Samples+weights.Series+weights.Queries+weights.Storage == 0 {
+			return fmt.Errorf("costControl.costWeights must have a positive weight with the composite cost method")
+		}
+		if coefficients := cost.Coefficients; coefficients.SamplesCost < 0 || coefficients.SeriesCost < 0 || coefficients.QueryCost < 0 || coefficients.StorageCost < 0 {
+			return fmt.Errorf("costControl.coefficients cannot be negative")
+		}
+		if cost.EstimationWindow < 0 {
+			return fmt.Errorf("costControl.estimationWindow cannot be negative, got %v", cost.EstimationWindow)
+		}
+		for _, threshold := range cost.AlertThresholds {
+			if threshold <= 0 {
+				return fmt.Errorf("costControl.alertThresholds must be positive percentages, got %v", threshold)
+			}
+		}
+		budgets := map[string]BudgetConfig{"globalBudget": cost.GlobalBudget}
+		for tenant, budget := range cost.TenantBudgets {
+			budgets["tenantBudgets["+tenant+"]"] = budget
+		}
+		for name, budget := range budgets {
+			if budget.Daily < 0 || budget.Monthly < 0 || budget.Annual < 0 {
+				return fmt.Errorf("costControl.%s budgets cannot be negative", name)
+			}
+		}
+	}
+
+	panicMode := c.Emergency.PanicMode
+	for _, action := range panicMode.Actions {
+		switch action {
+		case EmergencyActionReduceLimits:
+			if panicMode.LimitReductionPercent <= 0 || panicMode.LimitReductionPercent >= 100 {
+				return fmt.Errorf("emergency.panicMode.limitReductionPercent must be between 0 and 100, got %v", panicMode.LimitReductionPercent)
+			}
+		case EmergencyActionThrottleIngestion:
+			if panicMode.ThrottleRequestRate <= 0 {
+				return fmt.Errorf("emergency.panicMode.throttleRequestRate must be positive, got %v", panicMode.ThrottleRequestRate)
+			}
+			if panicMode.ThrottleRequestBurstSize < 1 {
+				return fmt.Errorf("emergency.panicMode.throttleRequestBurstSize must be at least 1, got %d", panicMode.ThrottleRequestBurstSize)
+			}
+		case EmergencyActionAlert:
+		default:
+			return fmt.Errorf("emergency.panicMode.actions must be reduce_limits, throttle_ingestion or alert, got %q", action)
+		}
+	}
+
+	if emergency := c.Emergency; emergency.Enabled {
+		names := make(map[string]bool, len(emergency.ShutdownTriggers))
+		for _, trigger := range emergency.ShutdownTriggers {
+			if trigger.Name == "" || trigger.Metric == "" {
+				return fmt.Errorf("emergency.shutdownTriggers entries need a name and a metric")
+			}
+			if names[trigger.Name] {
+				return fmt.Errorf("emergency.shutdownTriggers[%s] is defined more than once", trigger.Name)
+			}
+			names[trigger.Name] = true
+			if trigger.Threshold <= 0 {
+				return fmt.Errorf("emergency.shutdownTriggers[%s] threshold must be positive, got %v", trigger.Name, trigger.Threshold)
+			}
+			if trigger.Duration < 0 {
+				return fmt.Errorf("emergency.shutdownTriggers[%s] duration cannot be negative, got %v", trigger.Name, trigger.Duration)
+			}
+			if trigger.Action != "" && trigger.Action != "emergency_mode" && trigger.Action != "panic_mode" {
+				return fmt.Errorf("emergency.shutdownTriggers[%s] action must be emergency_mode or panic_mode, got %q", trigger.Name, trigger.Action)
+			}
+		}
+		if emergency.RecoveryProcedures.MaxAttempts < 0 {
+			return fmt.Errorf("emergency.recoveryProcedures.maxAttempts cannot be negative, got %d", emergency.RecoveryProcedures.MaxAttempts)
+		}
+	}
+
+	if compression := c.Performance.Compression; c.Performance.Enabled && compression.Enabled && compression.Algorithm == "gzip" {
+		if compression.Level < gzip.HuffmanOnly || compression.Level > gzip.BestCompression {
+			return fmt.Errorf("performance.compression.level must be between %d and %d for gzip, got %d",
+				gzip.HuffmanOnly, gzip.BestCompression, compression.Level)
+		}
+	}
+
+	if c.Performance.Enabled && c.Performance.Cache.Enabled {
+		switch c.Performance.Cache.Type {
+		case "memory":
+		case "redis":
+			if c.Performance.Cache.Redis.Address == "" {
+				return fmt.Errorf("performance.cache.redis.address is required when performance.cache.type is redis")
+			}
+			if c.Performance.Cache.Redis.DB < 0 {
+				return fmt.Errorf("performance.cache.redis.db cannot be negative, got %d", c.Performance.Cache.Redis.DB)
+			}
+		default:
+			return fmt.Errorf("performance.cache.type must be one of memory, redis, got %s", c.Performance.Cache.Type)
+		}
+	}
+
 	if c.UI.Enabled && (c.UI.Port < 1024 || c.UI.Port > 65535) {
 		return fmt.Errorf("ui.port must be between 1024 and 65535, got %d", c.UI.Port)
 	}
 
+	if rateLimit := c.UI.RateLimit; rateLimit.Enabled {
+		if rateLimit.RequestsPerSecond <= 0 {
+			return fmt.Errorf("ui.rateLimit.requestsPerSecond must be positive, got %f", rateLimit.RequestsPerSecond)
+		}
+		if rateLimit.BurstCapacity < 1 {
+			return fmt.Errorf("ui.rateLimit.burstCapacity must be at least 1, got %d", rateLimit.BurstCapacity)
+		}
+		for endpoint, limit := range rateLimit.Endpoints {
+			if limit.RequestsPerSecond <= 0 {
+				return fmt.Errorf("ui.rateLimit.endpoints[%q].requestsPerSecond must be positive, got %f", endpoint, limit.RequestsPerSecond)
+			}
+			if limit.BurstCapacity < 1 {
+				return fmt.Errorf("ui.rateLimit.endpoints[%q].burstCapacity must be at least 1, got %d", endpoint, limit.BurstCapacity)
+			}
+		}
+	}
+
+	if len(c.Metrics.ExtraLabels) > metrics.MaxExtraTenantLabels {
+		return fmt.Errorf("metrics.extraLabels allows at most %d labels, got %d", metrics.MaxExtraTenantLabels, len(c.Metrics.ExtraLabels))
+	}
+	extraLabels := make(map[string]bool, len(c.Metrics.ExtraLabels))
+	for _, label := range c.Metrics.ExtraLabels {
+		if !model.LabelName(label).IsValid() || strings.HasPrefix(label, model.ReservedLabelPrefix) {
+			return fmt.Errorf("metrics.extraLabels has an invalid Prometheus label name %q", label)
+		}
+		if extraLabels[label] {
+			return fmt.Errorf("metrics.extraLabels has the label %q twice", label)
+		}
+		extraLabels[label] = true
+	}
+	for name, tier := range c.Limits.TenantTiers {
+		for label := range tier.MetricLabels {
+			if !extraLabels[label] {
+				return fmt.Errorf("limits.tenantTiers[%s].metricLabels sets %q, which is not in metrics.extraLabels", name, label)
+			}
+		}
+	}
+
+	uiTLS := c.UI.TLS
+	if (uiTLS.CertFile == "") != (uiTLS.KeyFile == "") {
+		return fmt.Errorf("ui.tls needs both certFile and keyFile, got certFile %q and keyFile %q", uiTLS.CertFile, uiTLS.KeyFile)
+	}
+	if uiTLS.ClientCAFile != "" && !uiTLS.Enabled() {
+		return fmt.Errorf("ui.tls.clientCAFile needs certFile and keyFile")
+	}
+
+	if auth := c.UI.Auth; auth.Enabled {
+		if auth.TokensFile == "" && !auth.TokenReview.Enabled {
+			return fmt.Errorf("ui.auth needs a tokensFile or tokenReview.enabled")
+		}
+		if auth.TokenReview.CacheTTL < 0 {
+			return fmt.Errorf("ui.auth.tokenReview.cacheTTL must not be negative, got %v", auth.TokenReview.CacheTTL)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the synthetic metrics configuration
+func (m SyntheticMetricsConfig) validate() error {
+	if m.TimeAcceleration <= 0 {
+		return fmt.Errorf("synthetic.metricsConfig.timeAcceleration must be positive, got %f", m.TimeAcceleration)
+	}
+	if m.SampleInterval <= 0 {
+		return fmt.Errorf("synthetic.metricsConfig.sampleInterval must be positive, got %v", m.SampleInterval)
+	}
+
+	names := make(map[string]bool, len(m.Tenants))
+	for i, tenant := range m.Tenants {
+		field := fmt.Sprintf("synthetic.metricsConfig.tenants[%d]", i)
+		if tenant.Name == "" {
+			return fmt.Errorf("%s.name cannot be empty", field)
+		}
+		if names[tenant.Name] {
+			return fmt.Errorf("%s.name %q is duplicated", field, tenant.Name)
+		}
+		names[tenant.Name] = true
+
+		switch tenant.Profile {
+		case "", SyntheticProfileSteady, SyntheticProfileDiurnal, SyntheticProfileBursty, SyntheticProfileGrowing:
+		default:
+			return fmt.Errorf("%s.profile must be steady, diurnal, bursty or growing, got %q", field, tenant.Profile)
+		}
+		if tenant.IngestionRate < 0 || tenant.Series < 0 || tenant.QueryRate < 0 {
+			return fmt.Errorf("%s ingestionRate, series and queryRate cannot be negative", field)
+		}
+		if tenant.Noise < 0 || tenant.Noise >= 1 {
+			return fmt.Errorf("%s.noise must be between 0 and 1, got %f", field, tenant.Noise)
+		}
+		if tenant.Amplitude < 0 || tenant.Amplitude >= 1 {
+			return fmt.Errorf("%s.amplitude must be between 0 and 1, got %f", field, tenant.Amplitude)
+		}
+		if tenant.BurstProbability < 0 || tenant.BurstProbability > 1 {
+			return fmt.Errorf("%s.burstProbability must be between 0 and 1, got %f", field, tenant.BurstProbability)
+		}
+		if tenant.BurstMultiplier != 0 && tenant.BurstMultiplier < 1 {
+			return fmt.Errorf("%s.burstMultiplier must be at least 1, got %f", field, tenant.BurstMultiplier)
+		}
+		if tenant.GrowthPerDay < 0 {
+			return fmt.Errorf("%s.growthPerDay cannot be negative, got %f", field, tenant.GrowthPerDay)
+		}
+		if spike := tenant.Spike; spike != nil {
+			if spike.After < 0 {
+				return fmt.Errorf("%s.spike.after cannot be negative, got %v", field, spike.After)
+			}
+			if spike.Duration <= 0 {
+				return fmt.Errorf("%s.spike.duration must be positive, got %v", field, spike.Duration)
+			}
+			if spike.Multiplier <= 1 {
+				return fmt.Errorf("%s.spike.multiplier must be greater than 1, got %f", field, spike.Multiplier)
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigMapFormat(t *testing.T) {
+	for _, format := range []string{ConfigMapFormatFlat, ConfigMapFormatMimirNative, ConfigMapFormatSharded} {
+		cfg := GetDefaultConfig()
+		cfg.Mimir.ConfigMapFormat = format
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate with configMapFormat %q: %v", format, err)
+		}
+	}
+
+	cfg := GetDefaultConfig()
+	cfg.Mimir.ConfigMapFormat = "per_tenant_override"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "per_tenant_override") {
+		t.Errorf("Validate with an unknown configMapFormat = %v, want an error naming it", err)
+	}
+}
+
+func TestValidateLimitBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		minLimits map[string]interface{}
+		maxLimits map[string]interface{}
+		wantField string
+	}{
+		{"floor within the definition's bounds", map[string]interface{}{"ingestion_rate": 10000}, nil, ""},
+		{"floor below the definition's minimum", map[string]interface{}{"ingestion_rate": 10}, nil, "limits.minLimits.ingestion_rate"},
+		{"ceiling above the definition's maximum", nil, map[string]interface{}{"ingestion_rate": 1e9}, "limits.maxLimits.ingestion_rate"},
+		{"floor above the ceiling", map[string]interface{}{"ingestion_rate": 50000}, map[string]interface{}{"ingestion_rate": 20000}, "limits.minLimits.ingestion_rate"},
+		{"invalid value", map[string]interface{}{"ingestion_rate": "lots"}, nil, "limits.minLimits.ingestion_rate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			cfg.Limits.MinLimits = tt.minLimits
+			cfg.Limits.MaxLimits = tt.maxLimits
+			err := cfg.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantField) {
+				t.Errorf("Validate = %v, want an error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateCostWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		weights CostWeights
+		wantErr string
+	}{
+		{"composite weights", "composite", CostWeights{Samples: 0.1, Series: 1, Queries: 0.2, Storage: 0.05}, ""},
+		{"negative samples weight", "composite", CostWeights{Samples: -1, Series: 1}, "cannot be negative"},
+		{"negative storage weight", "samples", CostWeights{Series: 1, Storage: -0.5}, "cannot be negative"},
+		{"composite without any weight", "composite", CostWeights{}, "positive weight"},
+		{"default method without any weight", "", CostWeights{}, "positive weight"},
+		{"single-dimension method ignores the weights", "series", CostWeights{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			cfg.CostControl.CostMethod = tt.method
+			cfg.CostControl.CostWeights = tt.weights
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetLimitBounds(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.Limits.MinLimits = map[string]interface{}{"ingestion_rate": 10000}
+	cfg.Limits.DefaultLimits = map[string]interface{}{"ingestion_rate": 30000}
+
+	bounds := cfg.GetLimitBounds("ingestion_rate")
+	if got := bounds.Floor.String(); got != "10000" {
+		t.Errorf("floor = %s, want the operator's minLimits 10000", got)
+	}
+	if got := bounds.Ceiling.String(); got != "10000000" {
+		t.Errorf("ceiling = %s, want the definition's MaxValue 10000000", got)
+	}
+	if got := bounds.Default.String(); got != "30000" {
+		t.Errorf("default = %s, want the operator's defaultLimits 30000", got)
+	}
+}
+
+func TestRateLimitForTenant(t *testing.T) {
+	rateLimit := RateLimitConfig{
+		RequestsPerSecond: 10,
+		BurstCapacity:     20,
+		TenantOverrides: map[string]RateLimitOverride{
+			"team-*":     {BurstCapacity: 50},
+			"team-a-*":   {RequestsPerSecond: 100},
+			"team-a-vip": {RequestsPerSecond: 500, BurstCapacity: 1000},
+			"[invalid":   {RequestsPerSecond: 1},
+		},
+	}
+	tests := []struct {
+		tenant       string
+		want         RateLimitOverride
+		wantOverride string
+	}{
+		{"other", RateLimitOverride{RequestsPerSecond: 10, BurstCapacity: 20}, ""},
+		{"team-b", RateLimitOverride{RequestsPerSecond: 10, BurstCapacity: 50}, "team-*"},
+		{"team-a-prod", RateLimitOverride{RequestsPerSecond: 100, BurstCapacity: 20}, "team-a-*"},
+		{"team-a-vip", RateLimitOverride{RequestsPerSecond: 500, BurstCapacity: 1000}, "team-a-vip"},
+	}
+	for _, tt := range tests {
+		got, override := rateLimit.ForTenant(tt.tenant)
+		if got != tt.want || override != tt.wantOverride {
+			t.Errorf("ForTenant(%q) = %+v from %q, want %+v from %q", tt.tenant, got, override, tt.want, tt.wantOverride)
+		}
+	}
+}
+
+func TestValidateTenantScopingPatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		scoping   TenantScopingConfig
+		wantError string
+	}{
+		{"valid globs", TenantScopingConfig{SkipList: []string{"*-test"}, IncludeList: []string{"prod-?", "[ds]*"}}, ""},
+		{"valid regexes", TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-.*"}}, ""},
+		{"regex typo", TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-[a-z"}}, `tenantScoping.includeList pattern "prod-[a-z"`},
+		{"unclosed glob class", TenantScopingConfig{SkipList: []string{"prod-[ab"}}, `tenantScoping.skipList pattern "prod-[ab"`},
+		{"invalid paused pattern", TenantScopingConfig{UseRegex: true, PausedTenants: []string{"(dev"}}, `tenantScoping.pausedTenants pattern "(dev"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			cfg.TenantScoping = tt.scoping
+			err := cfg.Validate()
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Validate = %v, want an error containing %s", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestTenantScopingAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		scoping TenantScopingConfig
+		tenant  string
+		want    bool
+	}{
+		{"no lists", TenantScopingConfig{}, "prod-a", true},
+		{"included", TenantScopingConfig{IncludeList: []string{"prod-*"}}, "prod-a", true},
+		{"not included", TenantScopingConfig{IncludeList: []string{"prod-*"}}, "dev", false},
+		{"skip wins over include", TenantScopingConfig{IncludeList: []string{"prod-*"}, SkipList: []string{"*-test"}}, "prod-test", false},
+		{"regex included", TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-[ab]"}}, "prod-b", true},
+		{"regex is anchored", TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod"}}, "prod-a", false},
+		{"invalid pattern matches nothing", TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-[a"}}, "prod-a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.scoping.Allows(tt.tenant); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.tenant, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMetricsExtraLabels(t *testing.T) {
+	tests := []struct {
+		name         string
+		extraLabels  []string
+		metricLabels map[string]string
+		want         string
+	}{
+		{"one label", []string{"env"}, map[string]string{"env": "prod"}, ""},
+		{"five labels", []string{"env", "business_unit", "sla", "region", "team"}, nil, ""},
+		{"six labels", []string{"env", "business_unit", "sla", "region", "team", "cost_center"}, nil, "at most 5"},
+		{"invalid name", []string{"business-unit"}, nil, "invalid Prometheus label name"},
+		{"repeated label", []string{"env", "env"}, nil, "twice"},
+		{"tier label not extra", []string{"env"}, map[string]string{"sla": "gold"}, "not in metrics.extraLabels"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := GetDefaultConfig()
+			cfg.Metrics.ExtraLabels = tt.extraLabels
+			cfg.Limits.TenantTiers = map[string]TenantTierConfig{
+				"gold": {BufferPercentage: 30, Tenants: []string{"prod-*"}, MetricLabels: tt.metricLabels},
+			}
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldError is a configuration field whose value is not valid
+type FieldError struct {
+	Field   string      `json:"field"`
+	Value   interface{} `json:"value,omitempty"`
+	Message string      `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// FieldErrors lists every invalid field found, so an operator can fix them all at once
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// LimitCatalog returns the limits the optimizer knows: the default limit definitions
+// and the configured dynamicLimits.limitDefinitions, which take precedence, enabled or
+// disabled by limits.enabledLimits and limits.disabledLimits
+func (c *Config) LimitCatalog() map[string]LimitDefinition {
+	catalog := GetDefaultLimitDefinitions()
+	for name, def := range c.DynamicLimits.LimitDefinitions {
+		catalog[name] = def
+	}
+	for _, selection := range []struct {
+		names   []string
+		enabled bool
+	}{{c.Limits.EnabledLimits, true}, {c.Limits.DisabledLimits, false}} {
+		for _, name := range selection.names {
+			if def, exists := catalog[name]; exists {
+				def.Enabled = selection.enabled
+				catalog[name] = def
+			}
+		}
+	}
+	return catalog
+}
+
+// ApplyLimitSelection replaces dynamicLimits.limitDefinitions with the limit catalog, so
+// the analyzer, collector and patcher only optimize the limits enabled by the
+// configuration
+func (c *Config) ApplyLimitSelection() {
+	c.DynamicLimits.LimitDefinitions = c.LimitCatalog()
+}
+
+// EnabledLimitNames returns the limits of the catalog that are optimized, by name
+func (c *Config) EnabledLimitNames() []string {
+	var names []string
+	catalog := c.LimitCatalog()
+	for _, name := range sortedLimitNames(catalog) {
+		if catalog[name].Enabled {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateLimitOverrides checks limits.minLimits, limits.maxLimits, limits.defaultLimits,
+// the limits of each tenant tier, limits.enabledLimits and limits.disabledLimits against
+// the limit catalog: the limit must be known, its value must be valid for the limit
+// type, minLimits and maxLimits must be within the limit's own bounds and must not
+// cross, defaultLimits must fall between them, and a limit may not be both enabled and
+// disabled. Every invalid field is reported, sorted by field.
+func (c *Config) ValidateLimitOverrides() FieldErrors {
+	catalog := c.LimitCatalog()
+	var errs FieldErrors
+
+	floors := validateLimitMap(catalog, "limits.minLimits", c.Limits.MinLimits, true, &errs)
+	ceilings := validateLimitMap(catalog, "limits.maxLimits", c.Limits.MaxLimits, true, &errs)
+	defaults := validateLimitMap(catalog, "limits.defaultLimits", c.Limits.DefaultLimits, false, &errs)
+	for _, name := range c.Limits.tierNames() {
+		field := fmt.Sprintf("limits.tenantTiers[%s].limits", name)
+		validateLimitMap(catalog, field, c.Limits.TenantTiers[name].Limits, false, &errs)
+	}
+
+	errs = append(errs, c.ValidateLimitNames("limits.enabledLimits", c.Limits.EnabledLimits)...)
+	errs = append(errs, c.ValidateLimitNames("limits.disabledLimits", c.Limits.DisabledLimits)...)
+	enabled := make(map[string]bool, len(c.Limits.EnabledLimits))
+	for _, name := range c.Limits.EnabledLimits {
+		enabled[name] = true
+	}
+	for i, name := range c.Limits.DisabledLimits {
+		if enabled[name] {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("limits.disabledLimits[%d]", i),
+				Value:   name,
+				Message: "must not also be in limits.enabledLimits",
+			})
+		}
+	}
+
+	for _, limitName := range sortedLimitNames(floors) {
+		ceiling, exists := ceilings[limitName]
+		if !exists {
+			continue
+		}
+		if cmp, err := floors[limitName].Compare(ceiling); err == nil && cmp > 0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.minLimits." + limitName,
+				Value:   c.Limits.MinLimits[limitName],
+				Message: fmt.Sprintf("must not exceed limits.maxLimits.%s, got %v > %v", limitName, floors[limitName], ceiling),
+			})
+		}
+	}
+
+	for _, limitName := range sortedLimitNames(defaults) {
+		def := catalog[limitName]
+		floor, ceiling := def.MinValue, def.MaxValue
+		if value, exists := floors[limitName]; exists {
+			floor = value
+		}
+		if value, exists := ceilings[limitName]; exists {
+			ceiling = value
+		}
+
+		value := defaults[limitName]
+		if cmp, err := value.Compare(floor); err == nil && cmp < 0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.defaultLimits." + limitName,
+				Value:   c.Limits.DefaultLimits[limitName],
+				Message: fmt.Sprintf("must be at least the minimum %v, got %v", floor, value),
+			})
+		} else if cmp, err := value.Compare(ceiling); err == nil && cmp > 0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.defaultLimits." + limitName,
+				Value:   c.Limits.DefaultLimits[limitName],
+				Message: fmt.Sprintf("must be at most the maximum %v, got %v", ceiling, value),
+			})
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+// ValidateLimitNames checks that each name is a limit of the catalog
+func (c *Config) ValidateLimitNames(field string, names []string) FieldErrors {
+	catalog := c.LimitCatalog()
+	var errs FieldErrors
+	for i, name := range names {
+		if _, exists := catalog[name]; !exists {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Value:   name,
+				Message: unknownLimitMessage(name, catalog),
+			})
+		}
+	}
+	return errs
+}
+
+// validateLimitMap checks the limits of one field against the catalog, appending the
+// invalid ones to errs, and returns the valid values parsed for their limit type. With
+// withinBounds, ordered values must also be within the MinValue and MaxValue of the
+// limit definition.
+func validateLimitMap(catalog map[string]LimitDefinition, field string, limits map[string]interface{}, withinBounds bool, errs *FieldErrors) map[string]LimitValue {
+	parsed := make(map[string]LimitValue, len(limits))
+	for _, limitName := range sortedLimitNames(limits) {
+		value := limits[limitName]
+		fieldName := field + "." + limitName
+		def, exists := catalog[limitName]
+		if !exists {
+			*errs = append(*errs, FieldError{Field: fieldName, Value: value, Message: unknownLimitMessage(limitName, catalog)})
+			continue
+		}
+
+		v, err := ParseLimitValue(value, def.Type)
+		if err != nil {
+			*errs = append(*errs, FieldError{
+				Field:   fieldName,
+				Value:   value,
+				Message: fmt.Sprintf("must be a valid %s value: %v", def.Type, err),
+			})
+			continue
+		}
+		if withinBounds {
+			if cmp, err := v.Compare(def.MinValue); err == nil && cmp < 0 {
+				*errs = append(*errs, FieldError{Field: fieldName, Value: value, Message: fmt.Sprintf("must be at least %v, got %v", def.MinValue, v)})
+				continue
+			}
+			if cmp, err := v.Compare(def.MaxValue); err == nil && cmp > 0 {
+				*errs = append(*errs, FieldError{Field: fieldName, Value: value, Message: fmt.Sprintf("must be at most %v, got %v", def.MaxValue, v)})
+				continue
+			}
+		}
+		parsed[limitName] = v
+	}
+	return parsed
+}
+
+// unknownLimitMessage explains that a limit is not in the catalog, suggesting the
+// closest known limit name
+func unknownLimitMessage(limitName string, catalog map[string]LimitDefinition) string {
+	if suggestion := closestLimitName(limitName, catalog); suggestion != "" {
+		return fmt.Sprintf("is not a known limit, did you mean %q?", suggestion)
+	}
+	return "is not a known limit"
+}
+
+// closestLimitName returns the catalog limit name within a few edits of a name, the
+// closest first and then the first by name, or "" when none is close enough to be the
+// intended one
+func closestLimitName(limitName string, catalog map[string]LimitDefinition) string {
+	maxDistance := len(limitName) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	closest, closestDistance := "", maxDistance+1
+	for _, candidate := range sortedLimitNames(catalog) {
+		if distance := editDistance(limitName, candidate); distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+	return closest
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// sortedLimitNames returns the keys of a limits map in order
+func sortedLimitNames[V any](limits map[string]V) []string {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // GetDefaultLimitDefinitions returns comprehensive configurations for all major Mimir runtime overrides
 func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 	return map[string]LimitDefinition{
@@ -12,9 +18,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ingestion_rate",
 			Type:         "count",
 			MetricSource: "cortex_distributor_received_samples_total",
-			DefaultValue: int64(25000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(10000000),
+			DefaultValue: IntValue(25000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(10000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Rate limit for sample ingestion per tenant (samples/sec)",
@@ -23,9 +29,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ingestion_burst_size",
 			Type:         "count",
 			MetricSource: "cortex_distributor_received_samples_total",
-			DefaultValue: int64(50000),
-			MinValue:     int64(2000),
-			MaxValue:     int64(20000000),
+			DefaultValue: IntValue(50000),
+			MinValue:     IntValue(2000),
+			MaxValue:     IntValue(20000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Burst size for sample ingestion per tenant",
@@ -34,9 +40,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ingestion_rate_strategy",
 			Type:         "string",
 			MetricSource: "",
-			DefaultValue: "global",
-			MinValue:     "",
-			MaxValue:     "",
+			DefaultValue: StringValue("global"),
+			MinValue:     LimitValue{},
+			MaxValue:     LimitValue{},
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Strategy for ingestion rate limiting (local/global)",
@@ -45,9 +51,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ingestion_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for ingestion (0 = no sharding)",
@@ -61,9 +67,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_global_series_per_user",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series",
-			DefaultValue: int64(150000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(150000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum active series per tenant across all ingesters",
@@ -72,9 +78,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_global_series_per_metric",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series_per_metric",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum series per metric name across all ingesters (0 = unlimited)",
@@ -87,10 +93,10 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 		"max_samples_per_query": {
 			Name:         "max_samples_per_query",
 			Type:         "count",
-			MetricSource: "cortex_query_frontend_query_range_duration_seconds",
-			DefaultValue: int64(50000000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(1000000000),
+			MetricSource: "cortex_querier_samples_per_query",
+			DefaultValue: IntValue(50000000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(1000000000),
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum samples a single query can load",
@@ -99,9 +105,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_series_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_series_fetched",
-			DefaultValue: int64(100000),
-			MinValue:     int64(100),
-			MaxValue:     int64(10000000),
+			DefaultValue: IntValue(100000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(10000000),
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum series a single query can return",
@@ -110,9 +116,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_concurrent_queries",
 			Type:         "count",
 			MetricSource: "cortex_query_frontend_queries_in_progress",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum concurrent queries per tenant",
@@ -121,9 +127,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_query_length",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "8760h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("8760h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum query time range (0 = unlimited)",
@@ -132,9 +138,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_query_lookback",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "8760h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("8760h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum lookback period for queries (0 = unlimited)",
@@ -143,9 +149,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_partial_query_length",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "8760h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("8760h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum partial query time range (0 = unlimited)",
@@ -154,9 +160,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_query_parallelism",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(14),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(14),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum parallelism for query execution",
@@ -165,9 +171,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_cache_freshness",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "1m",
-			MinValue:     "0s",
-			MaxValue:     "1h",
+			DefaultValue: mustDurationValue("1m"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("1h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum age for cached query results",
@@ -181,9 +187,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_fetched_chunks_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_chunks_fetched",
-			DefaultValue: int64(2000000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(2000000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum chunks a single query can fetch",
@@ -192,9 +198,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_fetched_series_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_series_fetched",
-			DefaultValue: int64(100000),
-			MinValue:     int64(100),
-			MaxValue:     int64(10000000),
+			DefaultValue: IntValue(100000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(10000000),
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum series a single query can fetch",
@@ -203,9 +209,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_fetched_chunk_bytes_per_query",
 			Type:         "size",
 			MetricSource: "cortex_querier_chunks_fetched_bytes",
-			DefaultValue: int64(50000000),
-			MinValue:     int64(1000000),
-			MaxValue:     int64(1000000000),
+			DefaultValue: SizeValue(50000000),
+			MinValue:     SizeValue(1000000),
+			MaxValue:     SizeValue(1000000000),
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum chunk bytes a single query can fetch",
@@ -214,9 +220,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_estimated_memory_consumption_per_query",
 			Type:         "size",
 			MetricSource: "cortex_querier_estimated_memory_consumption_bytes",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000000000),
+			DefaultValue: SizeValue(0),
+			MinValue:     SizeValue(0),
+			MaxValue:     SizeValue(1000000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum estimated memory consumption per query (0 = unlimited)",
@@ -225,9 +231,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_estimated_fetched_chunks_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_estimated_chunks_fetched",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum estimated chunks per query (0 = unlimited)",
@@ -241,9 +247,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_global_metadata_per_user",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_metadata",
-			DefaultValue: int64(8000),
-			MinValue:     int64(100),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(8000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum metadata entries per tenant",
@@ -252,9 +258,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_global_metadata_per_metric",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_metadata_per_metric",
-			DefaultValue: int64(10),
-			MinValue:     int64(1),
-			MaxValue:     int64(100),
+			DefaultValue: IntValue(10),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(100),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum metadata entries per metric",
@@ -268,9 +274,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_global_exemplars_per_user",
 			Type:         "count",
 			MetricSource: "cortex_ingester_tsdb_exemplar_series_with_exemplars_in_storage",
-			DefaultValue: int64(100000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(10000000),
+			DefaultValue: IntValue(100000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(10000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum exemplars per tenant",
@@ -279,9 +285,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_exemplars_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_exemplars_fetched",
-			DefaultValue: int64(100000),
-			MinValue:     int64(100),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(100000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum exemplars per query",
@@ -295,9 +301,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "request_rate",
 			Type:         "rate",
 			MetricSource: "cortex_request_duration_seconds",
-			DefaultValue: 0.0,
-			MinValue:     0.0,
-			MaxValue:     10000.0,
+			DefaultValue: FloatValue(0.0),
+			MinValue:     FloatValue(0.0),
+			MaxValue:     FloatValue(10000.0),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Request rate limit per tenant (requests/sec, 0 = unlimited)",
@@ -306,9 +312,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "request_burst_size",
 			Type:         "count",
 			MetricSource: "cortex_request_duration_seconds",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Request burst size per tenant (0 = unlimited)",
@@ -322,9 +328,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ruler_max_rules_per_rule_group",
 			Type:         "count",
 			MetricSource: "cortex_ruler_rule_group_rules",
-			DefaultValue: int64(20),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(20),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum rules per rule group",
@@ -333,9 +339,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ruler_max_rule_groups_per_tenant",
 			Type:         "count",
 			MetricSource: "cortex_ruler_rule_groups_per_user",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum rule groups per tenant",
@@ -344,9 +350,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ruler_evaluation_delay_duration",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "10m",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("10m"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Delay between rule evaluation time and rule execution",
@@ -355,9 +361,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ruler_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for ruler (0 = no sharding)",
@@ -366,9 +372,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ruler_max_rules_per_tenant",
 			Type:         "count",
 			MetricSource: "cortex_ruler_rules_per_user",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(100000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(100000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum rules per tenant (0 = unlimited)",
@@ -382,9 +388,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_notification_rate_limit",
 			Type:         "rate",
 			MetricSource: "cortex_alertmanager_notifications_total",
-			DefaultValue: 0.0,
-			MinValue:     0.0,
-			MaxValue:     1000.0,
+			DefaultValue: FloatValue(0.0),
+			MinValue:     FloatValue(0.0),
+			MaxValue:     FloatValue(1000.0),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Alertmanager notification rate limit (0 = unlimited)",
@@ -393,9 +399,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_dispatcher_aggregation_groups",
 			Type:         "count",
 			MetricSource: "cortex_alertmanager_dispatcher_aggregation_groups",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum dispatcher aggregation groups (0 = unlimited)",
@@ -404,9 +410,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_alerts_count",
 			Type:         "count",
 			MetricSource: "cortex_alertmanager_alerts",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum number of alerts (0 = unlimited)",
@@ -415,9 +421,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_alerts_size_bytes",
 			Type:         "size",
 			MetricSource: "cortex_alertmanager_alerts_size_bytes",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(100000000),
+			DefaultValue: SizeValue(0),
+			MinValue:     SizeValue(0),
+			MaxValue:     SizeValue(100000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum size of all alerts in bytes (0 = unlimited)",
@@ -426,9 +432,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_config_size_bytes",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(10000000),
+			DefaultValue: SizeValue(0),
+			MinValue:     SizeValue(0),
+			MaxValue:     SizeValue(10000000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum Alertmanager configuration size in bytes (0 = unlimited)",
@@ -437,9 +443,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_templates_count",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum number of templates (0 = unlimited)",
@@ -448,9 +454,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "alertmanager_max_template_size_bytes",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000000),
+			DefaultValue: SizeValue(0),
+			MinValue:     SizeValue(0),
+			MaxValue:     SizeValue(1000000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum template size in bytes (0 = unlimited)",
@@ -464,9 +470,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "compactor_blocks_retention_period",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "8760h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("8760h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Retention period for compacted blocks (0 = unlimited)",
@@ -475,9 +481,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "compactor_split_and_merge_shards",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Number of shards for split-and-merge compaction (0 = disabled)",
@@ -486,9 +492,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "compactor_split_groups",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(1),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(1),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Number of groups for split compaction",
@@ -497,9 +503,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "compactor_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for compactor (0 = no sharding)",
@@ -513,9 +519,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "store_gateway_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for store gateway (0 = no sharding)",
@@ -529,9 +535,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_label_names_per_series",
 			Type:         "count",
 			MetricSource: "cortex_ingester_active_series",
-			DefaultValue: int64(30),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(30),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum label names per series",
@@ -540,9 +546,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_label_name_length",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(1024),
-			MinValue:     int64(10),
-			MaxValue:     int64(10000),
+			DefaultValue: SizeValue(1024),
+			MinValue:     SizeValue(10),
+			MaxValue:     SizeValue(10000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum length of label names in bytes",
@@ -551,9 +557,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_label_value_length",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(2048),
-			MinValue:     int64(10),
-			MaxValue:     int64(100000),
+			DefaultValue: SizeValue(2048),
+			MinValue:     SizeValue(10),
+			MaxValue:     SizeValue(100000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum length of label values in bytes",
@@ -562,9 +568,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_metadata_length",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(1024),
-			MinValue:     int64(10),
-			MaxValue:     int64(10000),
+			DefaultValue: SizeValue(1024),
+			MinValue:     SizeValue(10),
+			MaxValue:     SizeValue(10000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum length of metric metadata in bytes",
@@ -578,9 +584,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "cardinality_analysis_enabled",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Enable cardinality analysis endpoints",
@@ -589,9 +595,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "label_names_and_values_results_max_size_bytes",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(4194304),
-			MinValue:     int64(1024),
-			MaxValue:     int64(104857600),
+			DefaultValue: SizeValue(4194304),
+			MinValue:     SizeValue(1024),
+			MaxValue:     SizeValue(104857600),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum size of label names and values query results",
@@ -600,9 +606,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "label_values_max_cardinality_label_names_per_request",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum label names per cardinality request",
@@ -616,9 +622,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_outstanding_per_tenant",
 			Type:         "count",
 			MetricSource: "cortex_query_frontend_queue_length",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Maximum outstanding queries per tenant in queue",
@@ -627,9 +633,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_queriers_per_tenant",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum queriers per tenant (0 = unlimited)",
@@ -638,9 +644,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_ingesters_within",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "13h",
-			MinValue:     "0s",
-			MaxValue:     "168h",
+			DefaultValue: mustDurationValue("13h"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("168h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Maximum lookback to query ingesters",
@@ -649,9 +655,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "split_queries_by_interval",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "24h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("24h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Split queries by time interval (0 = disabled)",
@@ -665,9 +671,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "out_of_order_time_window",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "0s",
-			MinValue:     "0s",
-			MaxValue:     "1h",
+			DefaultValue: mustDurationValue("0s"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("1h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Time window to accept out-of-order samples (0 = disabled)",
@@ -676,9 +682,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "out_of_order_blocks_external_label_enabled",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Enable external labels on out-of-order blocks",
@@ -687,9 +693,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "separate_metrics_group_label",
 			Type:         "string",
 			MetricSource: "",
-			DefaultValue: "",
-			MinValue:     "",
-			MaxValue:     "",
+			DefaultValue: LimitValue{},
+			MinValue:     LimitValue{},
+			MaxValue:     LimitValue{},
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Label to separate metrics into groups",
@@ -698,9 +704,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_chunks_per_query",
 			Type:         "count",
 			MetricSource: "cortex_querier_chunks_fetched",
-			DefaultValue: int64(2000000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(2000000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 50.0,
 			Enabled:      false,
 			Description:  "Maximum chunks per query (deprecated, use max_fetched_chunks_per_query)",
@@ -714,9 +720,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "native_histograms_ingestion_enabled",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Enable native histogram ingestion",
@@ -725,9 +731,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "active_series_metrics_enabled",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Enable active series metrics",
@@ -736,9 +742,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "active_series_metrics_idle_timeout",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "10m",
-			MinValue:     "1m",
-			MaxValue:     "1h",
+			DefaultValue: mustDurationValue("10m"),
+			MinValue:     mustDurationValue("1m"),
+			MaxValue:     mustDurationValue("1h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Idle timeout for active series metrics",
@@ -752,9 +758,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "create_grace_period",
 			Type:         "duration",
 			MetricSource: "",
-			DefaultValue: "10m",
-			MinValue:     "0s",
-			MaxValue:     "1h",
+			DefaultValue: mustDurationValue("10m"),
+			MinValue:     mustDurationValue("0s"),
+			MaxValue:     mustDurationValue("1h"),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Grace period for timestamp validation on sample creation",
@@ -763,9 +769,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "enforce_metadata_metric_name",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: true,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(true),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Enforce metadata metric name validation",
@@ -774,9 +780,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "ingestion_partition_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for ingestion partitions (0 = no sharding)",
@@ -790,9 +796,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_ingestion_rate_bytes",
 			Type:         "size",
 			MetricSource: "cortex_distributor_received_samples_bytes_total",
-			DefaultValue: int64(25000000), // 25MB/sec
-			MinValue:     int64(1000000),  // 1MB/sec
-			MaxValue:     int64(10000000000), // 10GB/sec
+			DefaultValue: SizeValue(25000000), // 25MB/sec
+			MinValue:     SizeValue(1000000),  // 1MB/sec
+			MaxValue:     SizeValue(10000000000), // 10GB/sec
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Rate limit for ingestion in bytes per second per tenant",
@@ -801,9 +807,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_ingestion_burst_size_bytes", 
 			Type:         "size",
 			MetricSource: "cortex_distributor_received_samples_bytes_total",
-			DefaultValue: int64(50000000), // 50MB burst
-			MinValue:     int64(2000000),  // 2MB
-			MaxValue:     int64(20000000000), // 20GB
+			DefaultValue: SizeValue(50000000), // 50MB burst
+			MinValue:     SizeValue(2000000),  // 2MB
+			MaxValue:     SizeValue(20000000000), // 20GB
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Burst size for ingestion in bytes per tenant",
@@ -817,9 +823,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_sample_age",
 			Type:         "duration",
 			MetricSource: "cortex_distributor_latest_seen_sample_timestamp_seconds",
-			DefaultValue: "336h", // 14 days
-			MinValue:     "1h",
-			MaxValue:     "8760h", // 1 year
+			DefaultValue: mustDurationValue("336h"), // 14 days
+			MinValue:     mustDurationValue("1h"),
+			MaxValue:     mustDurationValue("8760h"), // 1 year
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "Maximum age of samples that can be ingested",
@@ -828,9 +834,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "enforce_metric_name_validation",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: true,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(true),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether to enforce metric name validation",
@@ -844,9 +850,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_chunk_age",
 			Type:         "duration",
 			MetricSource: "cortex_ingester_oldest_unshipped_block_timestamp_seconds",
-			DefaultValue: "12h",
-			MinValue:     "1h", 
-			MaxValue:     "72h",
+			DefaultValue: mustDurationValue("12h"),
+			MinValue:     mustDurationValue("1h"), 
+			MaxValue:     mustDurationValue("72h"),
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "Maximum age of chunks before they must be shipped",
@@ -855,9 +861,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_chunk_size_bytes",
 			Type:         "size",
 			MetricSource: "cortex_ingester_chunk_size_bytes",
-			DefaultValue: int64(1048576), // 1MB
-			MinValue:     int64(1024),    // 1KB
-			MaxValue:     int64(104857600), // 100MB
+			DefaultValue: SizeValue(1048576), // 1MB
+			MinValue:     SizeValue(1024),    // 1KB
+			MaxValue:     SizeValue(104857600), // 100MB
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum size of individual chunks in bytes",
@@ -871,9 +877,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_tenants",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_users",
-			DefaultValue: int64(1000),
-			MinValue:     int64(1),
-			MaxValue:     int64(100000),
+			DefaultValue: IntValue(1000),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(100000),
 			BufferFactor: 10.0,
 			Enabled:      true,
 			Description:  "Maximum number of tenants per ingester",
@@ -882,9 +888,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "enforce_tenant_id_header",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: true,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(true),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether to enforce X-Scope-OrgID header presence",
@@ -893,9 +899,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "per_tenant_override",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: true,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(true),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether per-tenant overrides are enabled",
@@ -904,9 +910,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "subtenant_limits",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether hierarchical sub-tenant limits are enabled",
@@ -920,9 +926,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "remote_write_deadline",
 			Type:         "duration",
 			MetricSource: "cortex_distributor_push_duration_seconds",
-			DefaultValue: "30s",
-			MinValue:     "1s",
-			MaxValue:     "300s",
+			DefaultValue: mustDurationValue("30s"),
+			MinValue:     mustDurationValue("1s"),
+			MaxValue:     mustDurationValue("300s"),
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "Deadline for remote write requests",
@@ -931,9 +937,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "remote_write_max_samples_per_send",
 			Type:         "count",
 			MetricSource: "cortex_distributor_samples_in_total",
-			DefaultValue: int64(10000),
-			MinValue:     int64(100),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(10000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum samples per remote write request",
@@ -947,9 +953,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "trace_sampling_rate",
 			Type:         "percentage",
 			MetricSource: "",
-			DefaultValue: 1.0, // 1%
-			MinValue:     0.0,
-			MaxValue:     100.0,
+			DefaultValue: FloatValue(1.0), // 1%
+			MinValue:     FloatValue(0.0),
+			MaxValue:     FloatValue(100.0),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Sampling rate for distributed tracing (0-100%)",
@@ -958,9 +964,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "log_level",
 			Type:         "string",
 			MetricSource: "",
-			DefaultValue: "info",
-			MinValue:     "",
-			MaxValue:     "",
+			DefaultValue: StringValue("info"),
+			MinValue:     LimitValue{},
+			MaxValue:     LimitValue{},
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Log level for tenant operations (debug, info, warn, error)",
@@ -974,9 +980,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_timeout",
 			Type:         "duration",
 			MetricSource: "cortex_query_frontend_query_duration_seconds",
-			DefaultValue: "300s", // 5 minutes
-			MinValue:     "1s",
-			MaxValue:     "3600s", // 1 hour
+			DefaultValue: mustDurationValue("300s"), // 5 minutes
+			MinValue:     mustDurationValue("1s"),
+			MaxValue:     mustDurationValue("3600s"), // 1 hour
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "Maximum query execution timeout",
@@ -985,9 +991,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_scheduler_max_outstanding_requests_per_tenant",
 			Type:         "count",
 			MetricSource: "cortex_query_scheduler_queue_length",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum outstanding requests per tenant in query scheduler",
@@ -996,9 +1002,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_scheduler_max_queriers_per_tenant",
 			Type:         "count",
 			MetricSource: "cortex_query_scheduler_queriers_connected",
-			DefaultValue: int64(10),
-			MinValue:     int64(1),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(10),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum queriers per tenant in query scheduler",
@@ -1007,9 +1013,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_scheduler_max_outstanding_requests",
 			Type:         "count",
 			MetricSource: "cortex_query_scheduler_queue_length",
-			DefaultValue: int64(1000),
-			MinValue:     int64(10),
-			MaxValue:     int64(100000),
+			DefaultValue: IntValue(1000),
+			MinValue:     IntValue(10),
+			MaxValue:     IntValue(100000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Global maximum outstanding requests in query scheduler",
@@ -1018,9 +1024,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "query_scheduler_max_active_requests",
 			Type:         "count",
 			MetricSource: "cortex_query_scheduler_queries_in_progress",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum active requests in query scheduler",
@@ -1029,9 +1035,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "enable_query_scheduling",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether query scheduling is enabled for tenant",
@@ -1045,9 +1051,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "store_gateway_max_queries_in_flight",
 			Type:         "count",
 			MetricSource: "cortex_bucket_store_queries_in_flight",
-			DefaultValue: int64(100),
-			MinValue:     int64(1),
-			MaxValue:     int64(10000),
+			DefaultValue: IntValue(100),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(10000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum concurrent queries per store gateway",
@@ -1056,9 +1062,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "blocks_storage_tenant_shard_size",
 			Type:         "count",
 			MetricSource: "",
-			DefaultValue: int64(0), // 0 = no sharding
-			MinValue:     int64(0),
-			MaxValue:     int64(1000),
+			DefaultValue: IntValue(0), // 0 = no sharding
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Tenant shard size for blocks storage (0 = no sharding)",
@@ -1067,9 +1073,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "blocks_storage_per_tenant_override",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: false,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(false),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether per-tenant blocks storage overrides are enabled",
@@ -1083,9 +1089,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "tsdb_retention_period",
 			Type:         "duration",
 			MetricSource: "prometheus_tsdb_blocks_loaded",
-			DefaultValue: "336h", // 14 days
-			MinValue:     "24h",
-			MaxValue:     "8760h", // 1 year
+			DefaultValue: mustDurationValue("336h"), // 14 days
+			MinValue:     mustDurationValue("24h"),
+			MaxValue:     mustDurationValue("8760h"), // 1 year
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "TSDB block retention period per tenant",
@@ -1099,9 +1105,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "api_limit_max_series_per_metric_name",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series_per_metric",
-			DefaultValue: int64(50000),
-			MinValue:     int64(100),
-			MaxValue:     int64(10000000),
+			DefaultValue: IntValue(50000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(10000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "API limit for maximum series per metric name",
@@ -1110,9 +1116,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "api_limit_max_label_value_length",
 			Type:         "size",
 			MetricSource: "",
-			DefaultValue: int64(4096), // 4KB
-			MinValue:     int64(256),
-			MaxValue:     int64(65536), // 64KB
+			DefaultValue: SizeValue(4096), // 4KB
+			MinValue:     SizeValue(256),
+			MaxValue:     SizeValue(65536), // 64KB
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "API limit for maximum label value length in API responses",
@@ -1126,9 +1132,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_concurrent_requests",
 			Type:         "count",
 			MetricSource: "cortex_request_duration_seconds",
-			DefaultValue: int64(1000),
-			MinValue:     int64(1),
-			MaxValue:     int64(100000),
+			DefaultValue: IntValue(1000),
+			MinValue:     IntValue(1),
+			MaxValue:     IntValue(100000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Maximum concurrent requests per tenant across all components",
@@ -1142,9 +1148,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_bytes_per_query",
 			Type:         "size",
 			MetricSource: "cortex_querier_chunks_fetched_bytes",
-			DefaultValue: int64(1073741824), // 1GB
-			MinValue:     int64(1048576),    // 1MB
-			MaxValue:     int64(107374182400), // 100GB
+			DefaultValue: SizeValue(1073741824), // 1GB
+			MinValue:     SizeValue(1048576),    // 1MB
+			MaxValue:     SizeValue(107374182400), // 100GB
 			BufferFactor: 50.0,
 			Enabled:      true,
 			Description:  "Maximum bytes a single query can process",
@@ -1158,9 +1164,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "retention_period",
 			Type:         "duration",
 			MetricSource: "prometheus_tsdb_blocks_loaded",
-			DefaultValue: "336h", // 14 days
-			MinValue:     "24h",
-			MaxValue:     "8760h", // 1 year
+			DefaultValue: mustDurationValue("336h"), // 14 days
+			MinValue:     mustDurationValue("24h"),
+			MaxValue:     mustDurationValue("8760h"), // 1 year
 			BufferFactor: 0.0,
 			Enabled:      true,
 			Description:  "General retention period per tenant",
@@ -1174,9 +1180,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "cardinality_limit",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series",
-			DefaultValue: int64(100000),
-			MinValue:     int64(1000),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(100000),
+			MinValue:     IntValue(1000),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 20.0,
 			Enabled:      true,
 			Description:  "Overall cardinality limit per tenant",
@@ -1185,9 +1191,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "enforce_metadata_validation",
 			Type:         "bool",
 			MetricSource: "",
-			DefaultValue: true,
-			MinValue:     false,
-			MaxValue:     true,
+			DefaultValue: BoolValue(true),
+			MinValue:     BoolValue(false),
+			MaxValue:     BoolValue(true),
 			BufferFactor: 0.0,
 			Enabled:      false,
 			Description:  "Whether to enforce metadata validation",
@@ -1201,9 +1207,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_metadata_per_user",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_metadata",
-			DefaultValue: int64(8000),
-			MinValue:     int64(100),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(8000),
+			MinValue:     IntValue(100),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "DEPRECATED: Use max_global_metadata_per_user instead",
@@ -1217,9 +1223,9 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_series_per_metric",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series_per_metric",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(1000000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(1000000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Deprecated: use max_global_series_per_metric instead",
@@ -1228,12 +1234,240 @@ func GetDefaultLimitDefinitions() map[string]LimitDefinition {
 			Name:         "max_series_per_user",
 			Type:         "count",
 			MetricSource: "cortex_ingester_memory_series",
-			DefaultValue: int64(0),
-			MinValue:     int64(0),
-			MaxValue:     int64(100000000),
+			DefaultValue: IntValue(0),
+			MinValue:     IntValue(0),
+			MaxValue:     IntValue(100000000),
 			BufferFactor: 20.0,
 			Enabled:      false,
 			Description:  "Deprecated: use max_global_series_per_user instead",
 		},
 	}
-} 
\ No newline at end of file
+}
+
+// QueryPathLimits are the per-query limits recommended from the per-query usage of the
+// querier and query-frontend. The usage of each is the histogram named by its
+// MetricSource, read at trendAnalysis.percentile.
+var QueryPathLimits = []string{
+	"max_samples_per_query",
+	"max_fetched_series_per_query",
+	"max_fetched_chunks_per_query",
+}
+
+// QueryPathMetrics returns the histogram of each enabled query-path limit, by limit name
+func (c *Config) QueryPathMetrics() map[string]string {
+	metrics := make(map[string]string)
+	for _, limitName := range QueryPathLimits {
+		def, exists := c.DynamicLimits.LimitDefinitions[limitName]
+		if !exists || !def.Enabled || def.MetricSource == "" {
+			continue
+		}
+		metrics[limitName] = def.MetricSource
+	}
+	return metrics
+}
+
+// ThanosRulerLimits maps the ruler limits Thanos Ruler enforces, with
+// mimir.thanosModeEnabled, to their name in the Thanos Ruler limits file
+var ThanosRulerLimits = map[string]string{
+	"ruler_max_rules_per_rule_group":   "max_rules_per_rule_group",
+	"ruler_max_rule_groups_per_tenant": "max_rule_groups_per_tenant",
+}
+
+// IsRulerLimit reports whether a limit configures the Mimir ruler
+func IsRulerLimit(limitName string) bool {
+	return strings.HasPrefix(limitName, "ruler_")
+}
+
+// LimitBounds holds the floor and ceiling enforced on calculated values of a limit
+// and its default value
+type LimitBounds struct {
+	Floor   LimitValue `json:"floor"`
+	Ceiling LimitValue `json:"ceiling"`
+	Default LimitValue `json:"default"`
+}
+
+// GetLimitBounds returns the bounds enforced for a limit. Operator-defined
+// limits.minLimits, limits.maxLimits and limits.defaultLimits take precedence over
+// the limit definition's MinValue, MaxValue and DefaultValue; those that are not valid
+// values of the limit type are rejected by Validate and ignored here.
+func (c *Config) GetLimitBounds(limitName string) LimitBounds {
+	def := c.DynamicLimits.LimitDefinitions[limitName]
+	bounds := LimitBounds{
+		Floor:   def.MinValue,
+		Ceiling: def.MaxValue,
+		Default: def.DefaultValue,
+	}
+
+	if value, exists := c.Limits.MinLimits[limitName]; exists {
+		if parsed, err := ParseLimitValue(value, def.Type); err == nil {
+			bounds.Floor = parsed
+		}
+	}
+	if value, exists := c.Limits.MaxLimits[limitName]; exists {
+		if parsed, err := ParseLimitValue(value, def.Type); err == nil {
+			bounds.Ceiling = parsed
+		}
+	}
+	if value, exists := c.Limits.DefaultLimits[limitName]; exists {
+		if parsed, err := ParseLimitValue(value, def.Type); err == nil {
+			bounds.Default = parsed
+		}
+	}
+
+	return bounds
+}
+
+// LimitBoundValue converts a limit value of the given type to a comparable number:
+// the value itself for numeric limits and seconds for duration limits. It reports
+// false for unset bounds and for limit types that have no ordering.
+func LimitBoundValue(value interface{}, limitType string) (float64, bool) {
+	// Plain numbers compare as they are, without the rounding of counts and sizes
+	switch limitType {
+	case "rate", "count", "size", "percentage":
+		if n, err := parseNumber(value); err == nil {
+			return n, true
+		}
+	}
+
+	parsed, err := ParseLimitValue(value, limitType)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Number()
+}
+
+// limitDefinitionTypes are the limit types a limit definition may declare
+var limitDefinitionTypes = map[string]bool{
+	"rate":       true,
+	"count":      true,
+	"size":       true,
+	"duration":   true,
+	"percentage": true,
+	"bool":       true,
+	"string":     true,
+}
+
+// validateLimitDefinitions checks that each dynamicLimits.limitDefinitions entry has a
+// known type, a non-negative buffer factor and, for enabled limits with ordered values,
+// bounds and a default value that are consistent with each other
+func (c *Config) validateLimitDefinitions() error {
+	for limitName, def := range c.DynamicLimits.LimitDefinitions {
+		field := "dynamicLimits.limitDefinitions." + limitName
+		if !limitDefinitionTypes[def.Type] {
+			return fmt.Errorf("%s.type must be one of rate, count, size, duration, percentage, bool, string, got %q", field, def.Type)
+		}
+		if def.BufferFactor < 0 {
+			return fmt.Errorf("%s.buffer_factor cannot be negative, got %f", field, def.BufferFactor)
+		}
+		if !def.Enabled {
+			continue
+		}
+
+		kind, _ := LimitKindOf(def.Type)
+		if !kind.ordered() {
+			continue
+		}
+		for _, bound := range []struct {
+			name  string
+			value LimitValue
+		}{
+			{"min_value", def.MinValue},
+			{"max_value", def.MaxValue},
+			{"default_value", def.DefaultValue},
+		} {
+			if bound.value.IsSet() && bound.value.Kind() != kind {
+				return fmt.Errorf("%s.%s must be a valid %s value, got %s value %v", field, bound.name, def.Type, bound.value.Kind(), bound.value)
+			}
+		}
+
+		if cmp, err := def.MinValue.Compare(def.MaxValue); err == nil && cmp > 0 {
+			return fmt.Errorf("%s.min_value must not exceed max_value, got %v > %v", field, def.MinValue, def.MaxValue)
+		}
+		if cmp, err := def.DefaultValue.Compare(def.MinValue); err == nil && cmp < 0 {
+			return fmt.Errorf("%s.default_value must be at least min_value %v, got %v", field, def.MinValue, def.DefaultValue)
+		}
+		if cmp, err := def.DefaultValue.Compare(def.MaxValue); err == nil && cmp > 0 {
+			return fmt.Errorf("%s.default_value must be at most max_value %v, got %v", field, def.MaxValue, def.DefaultValue)
+		}
+	}
+
+	return nil
+}
+
+// ValidationError describes a proposed limit value that is not a valid value for the limit
+type ValidationError struct {
+	LimitName     string      `json:"limit_name"`
+	ProvidedValue interface{} `json:"provided_value"`
+	ExpectedType  string      `json:"expected_type"`
+	Message       string      `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.LimitName, e.Message)
+}
+
+// ValidateLimitSet checks a proposed set of limits against the default limit
+// definitions: unknown limit names, values of the wrong type, invalid durations and
+// booleans, and values outside the MinValue and MaxValue of the limit. The errors are
+// sorted by limit name.
+func ValidateLimitSet(limits map[string]interface{}) []ValidationError {
+	return validateLimitSet(limits, GetDefaultLimitDefinitions())
+}
+
+// ValidateLimitSet checks a proposed set of limits like the package-level
+// ValidateLimitSet, against the configured dynamicLimits.limitDefinitions
+func (c *Config) ValidateLimitSet(limits map[string]interface{}) []ValidationError {
+	return validateLimitSet(limits, c.DynamicLimits.LimitDefinitions)
+}
+
+func validateLimitSet(limits map[string]interface{}, definitions map[string]LimitDefinition) []ValidationError {
+	names := make([]string, 0, len(limits))
+	for name := range limits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []ValidationError
+	for _, name := range names {
+		value := limits[name]
+		def, exists := definitions[name]
+		if !exists {
+			errs = append(errs, ValidationError{
+				LimitName:     name,
+				ProvidedValue: value,
+				Message:       "unknown limit",
+			})
+			continue
+		}
+
+		if message := validateLimitValue(value, def); message != "" {
+			errs = append(errs, ValidationError{
+				LimitName:     name,
+				ProvidedValue: value,
+				ExpectedType:  def.Type,
+				Message:       message,
+			})
+		}
+	}
+	return errs
+}
+
+// validateLimitValue returns why a value is not valid for a limit, or "" when it is
+func validateLimitValue(value interface{}, def LimitDefinition) string {
+	if _, known := LimitKindOf(def.Type); !known {
+		return ""
+	}
+	v, err := ParseLimitValue(value, def.Type)
+	if err != nil {
+		return err.Error()
+	}
+
+	// Ordered values must be within the limit's hard bounds
+	if cmp, err := v.Compare(def.MinValue); err == nil && cmp < 0 {
+		return fmt.Sprintf("value %v is below the minimum %v", value, def.MinValue)
+	}
+	if cmp, err := v.Compare(def.MaxValue); err == nil && cmp > 0 {
+		return fmt.Sprintf("value %v is above the maximum %v", value, def.MaxValue)
+	}
+	return ""
+}
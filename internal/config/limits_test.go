@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateLimitSet(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit        string
+		value        interface{}
+		wantType     string
+		wantContains string
+	}{
+		{"unknown limit name", "ingestion_rates", 10000, "", "unknown limit"},
+		{"count below the minimum", "ingestion_rate", 10, "count", "below the minimum"},
+		{"count above the maximum", "ingestion_rate", 20000000, "count", "above the maximum"},
+		{"count given a word", "ingestion_rate", "fast", "count", "type mismatch"},
+		{"count given a boolean", "ingestion_rate", true, "count", "type mismatch"},
+		{"size above the maximum", "max_fetched_chunk_bytes_per_query", "2GB", "size", "above the maximum"},
+		{"size with an invalid unit", "max_fetched_chunk_bytes_per_query", "50 parsecs", "size", ""},
+		{"duration above the maximum", "max_cache_freshness", "2h", "duration", "above the maximum"},
+		{"invalid duration string", "max_query_lookback", "thirteen hours", "duration", "invalid duration"},
+		{"duration given a boolean", "max_query_lookback", false, "duration", "type mismatch"},
+		{"invalid boolean string", "cardinality_analysis_enabled", "yes please", "bool", "invalid boolean"},
+		{"boolean given a number", "cardinality_analysis_enabled", 1, "bool", "invalid boolean"},
+		{"string given a number", "ingestion_rate_strategy", 3, "string", "type mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateLimitSet(map[string]interface{}{tt.limit: tt.value})
+			if len(errs) != 1 {
+				t.Fatalf("ValidateLimitSet(%s: %v) = %v, want one error", tt.limit, tt.value, errs)
+			}
+			err := errs[0]
+			if err.LimitName != tt.limit || err.ProvidedValue != tt.value || err.ExpectedType != tt.wantType {
+				t.Errorf("error = %+v, want limit %s, value %v and expected type %q", err, tt.limit, tt.value, tt.wantType)
+			}
+			if !strings.Contains(err.Message, tt.wantContains) {
+				t.Errorf("message = %q, want it to contain %q", err.Message, tt.wantContains)
+			}
+			if !strings.HasPrefix(err.Error(), tt.limit+": ") {
+				t.Errorf("Error() = %q, want it prefixed with the limit name", err.Error())
+			}
+		})
+	}
+}
+
+func TestValidateLimitSetAcceptsValidValues(t *testing.T) {
+	limits := map[string]interface{}{
+		"ingestion_rate":                    25000,
+		"ingestion_burst_size":              "500000",
+		"max_fetched_chunk_bytes_per_query": "50MB",
+		"max_query_lookback":                "13h",
+		"max_cache_freshness":               30 * time.Second,
+		"cardinality_analysis_enabled":      "true",
+		"ingestion_rate_strategy":           "global",
+		"max_global_series_per_user":        IntValue(150000),
+	}
+	if errs := ValidateLimitSet(limits); len(errs) != 0 {
+		t.Errorf("ValidateLimitSet of valid values = %v, want no errors", errs)
+	}
+}
+
+func TestValidateLimitSetReportsEveryErrorInOrder(t *testing.T) {
+	errs := ValidateLimitSet(map[string]interface{}{
+		"max_query_lookback":      "soon",
+		"ingestion_rate":          0,
+		"unknown_limit":           1,
+		"ingestion_rate_strategy": "local",
+	})
+
+	var names []string
+	for _, err := range errs {
+		names = append(names, err.LimitName)
+	}
+	if got := strings.Join(names, ","); got != "ingestion_rate,max_query_lookback,unknown_limit" {
+		t.Errorf("errors for %s, want ingestion_rate, max_query_lookback and unknown_limit in order", got)
+	}
+}
+
+func TestConfigValidateLimitSetUsesConfiguredDefinitions(t *testing.T) {
+	cfg := GetDefaultConfig()
+	def := cfg.DynamicLimits.LimitDefinitions["ingestion_rate"]
+	def.MaxValue = IntValue(50000)
+	cfg.DynamicLimits.LimitDefinitions["ingestion_rate"] = def
+
+	if errs := cfg.ValidateLimitSet(map[string]interface{}{"ingestion_rate": 60000}); len(errs) != 1 {
+		t.Errorf("errors = %v, want the configured maximum enforced", errs)
+	}
+	if errs := ValidateLimitSet(map[string]interface{}{"ingestion_rate": 60000}); len(errs) != 0 {
+		t.Errorf("errors = %v, want the default definitions to allow 60000", errs)
+	}
+}
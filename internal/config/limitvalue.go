@@ -0,0 +1,603 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+// LimitKind is the representation of a limit value, following from the limit type
+type LimitKind int
+
+const (
+	// KindUnset is the kind of an unset value, such as the bounds of a string limit
+	KindUnset LimitKind = iota
+	// KindInt is a whole number, the kind of count limits
+	KindInt
+	// KindFloat is a real number, the kind of rate and percentage limits
+	KindFloat
+	// KindDuration is a duration, written in Mimir's duration format such as "13h"
+	KindDuration
+	// KindSize is a number of bytes, parsed from plain numbers or sizes such as "50MB"
+	KindSize
+	// KindBool is a boolean
+	KindBool
+	// KindString is a string
+	KindString
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindDuration:
+		return "duration"
+	case KindSize:
+		return "size"
+	case KindBool:
+		return "bool"
+	case KindString:
+		return "string"
+	default:
+		return "unset"
+	}
+}
+
+// LimitKindOf returns the kind of the values of a limit type
+func LimitKindOf(limitType string) (LimitKind, bool) {
+	switch limitType {
+	case "count":
+		return KindInt, true
+	case "rate", "percentage":
+		return KindFloat, true
+	case "size":
+		return KindSize, true
+	case "duration":
+		return KindDuration, true
+	case "bool":
+		return KindBool, true
+	case "string":
+		return KindString, true
+	default:
+		return KindUnset, false
+	}
+}
+
+// LimitValue is a typed limit value. Values are parsed for a limit type with
+// ParseLimitValue and always serialize in the format Mimir reads for that type: counts
+// and sizes as integers, rates and percentages as numbers, durations as strings such as
+// "13h", booleans and strings as themselves. The zero value is unset.
+type LimitValue struct {
+	kind LimitKind
+	// i holds KindInt values and KindSize values in bytes
+	i int64
+	f float64
+	d time.Duration
+	b bool
+	s string
+}
+
+// IntValue returns a count value
+func IntValue(v int64) LimitValue {
+	return LimitValue{kind: KindInt, i: v}
+}
+
+// FloatValue returns a rate or percentage value
+func FloatValue(v float64) LimitValue {
+	return LimitValue{kind: KindFloat, f: v}
+}
+
+// DurationValue returns a duration value
+func DurationValue(d time.Duration) LimitValue {
+	return LimitValue{kind: KindDuration, d: d}
+}
+
+// SizeValue returns a size value of a number of bytes
+func SizeValue(bytes int64) LimitValue {
+	return LimitValue{kind: KindSize, i: bytes}
+}
+
+// BoolValue returns a boolean value
+func BoolValue(b bool) LimitValue {
+	return LimitValue{kind: KindBool, b: b}
+}
+
+// StringValue returns a string value
+func StringValue(s string) LimitValue {
+	return LimitValue{kind: KindString, s: s}
+}
+
+// Kind returns the kind of the value
+func (v LimitValue) Kind() LimitKind {
+	return v.kind
+}
+
+// IsSet reports whether the value is set
+func (v LimitValue) IsSet() bool {
+	return v.kind != KindUnset
+}
+
+// Number returns the value as a comparable number: the value of counts, sizes, rates
+// and percentages and the seconds of durations. It reports false for the other kinds.
+func (v LimitValue) Number() (float64, bool) {
+	switch v.kind {
+	case KindInt, KindSize:
+		return float64(v.i), true
+	case KindFloat:
+		return v.f, true
+	case KindDuration:
+		return v.d.Seconds(), true
+	default:
+		return 0, false
+	}
+}
+
+// Duration returns the value of a duration, reporting false for the other kinds
+func (v LimitValue) Duration() (time.Duration, bool) {
+	return v.d, v.kind == KindDuration
+}
+
+// ordered reports whether values of the kind have an order
+func (k LimitKind) ordered() bool {
+	switch k {
+	case KindInt, KindFloat, KindDuration, KindSize:
+		return true
+	default:
+		return false
+	}
+}
+
+// Compare returns -1, 0 or 1 as the value is below, equal to or above other. Counts,
+// sizes, rates and percentages compare with each other by number and durations with
+// durations; other kinds have no order.
+func (v LimitValue) Compare(other LimitValue) (int, error) {
+	if !v.kind.ordered() || !other.kind.ordered() || (v.kind == KindDuration) != (other.kind == KindDuration) {
+		return 0, fmt.Errorf("cannot compare %s value %v with %s value %v", v.kind, v, other.kind, other)
+	}
+
+	if v.kind == KindDuration {
+		return compareOrdered(v.d, other.d), nil
+	}
+	if (v.kind == KindInt || v.kind == KindSize) && (other.kind == KindInt || other.kind == KindSize) {
+		return compareOrdered(v.i, other.i), nil
+	}
+	a, _ := v.Number()
+	b, _ := other.Number()
+	return compareOrdered(a, b), nil
+}
+
+func compareOrdered[T int64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ApplyBuffer returns the value raised by a buffer in percent. Counts and sizes are
+// rounded to the nearest whole number; values without an order are returned unchanged.
+func (v LimitValue) ApplyBuffer(percent float64) LimitValue {
+	factor := 1 + percent/100
+	switch v.kind {
+	case KindInt, KindSize:
+		v.i = roundToInt64(float64(v.i) * factor)
+	case KindFloat:
+		v.f *= factor
+	case KindDuration:
+		v.d = time.Duration(float64(v.d) * factor)
+	}
+	return v
+}
+
+// Clamp returns the value raised to floor and lowered to ceiling, converted to the kind
+// of the value. Unset bounds and bounds that do not compare with the value are ignored.
+func (v LimitValue) Clamp(floor, ceiling LimitValue) LimitValue {
+	if cmp, err := v.Compare(floor); err == nil && cmp < 0 {
+		v = floor.convert(v.kind)
+	}
+	if cmp, err := v.Compare(ceiling); err == nil && cmp > 0 {
+		v = ceiling.convert(v.kind)
+	}
+	return v
+}
+
+// convert returns an ordered value as another ordered kind of the same dimension
+func (v LimitValue) convert(kind LimitKind) LimitValue {
+	if v.kind == kind || kind == KindDuration || v.kind == KindDuration {
+		return v
+	}
+	n, _ := v.Number()
+	switch kind {
+	case KindInt:
+		return IntValue(roundToInt64(n))
+	case KindSize:
+		return SizeValue(roundToInt64(n))
+	case KindFloat:
+		return FloatValue(n)
+	default:
+		return v
+	}
+}
+
+// Interface returns the value in the representation Mimir reads: int64 counts and
+// sizes, float64 rates and percentages, duration strings, booleans and strings. It
+// returns nil for an unset value.
+func (v LimitValue) Interface() interface{} {
+	switch v.kind {
+	case KindInt, KindSize:
+		return v.i
+	case KindFloat:
+		return v.f
+	case KindDuration:
+		return model.Duration(v.d).String()
+	case KindBool:
+		return v.b
+	case KindString:
+		return v.s
+	default:
+		return nil
+	}
+}
+
+func (v LimitValue) String() string {
+	switch v.kind {
+	case KindUnset:
+		return ""
+	case KindFloat:
+		return strconv.FormatFloat(v.f, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// MarshalJSON encodes the value in the format Mimir reads
+func (v LimitValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.Interface())
+}
+
+// MarshalYAML encodes the value in the format Mimir reads
+func (v LimitValue) MarshalYAML() (interface{}, error) {
+	return v.Interface(), nil
+}
+
+// UnmarshalJSON decodes a value whose kind is inferred from the document; values are
+// parsed for their limit type by the LimitDefinition holding them
+func (v *LimitValue) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+	if number, ok := raw.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			raw = i
+		} else if raw, err = number.Float64(); err != nil {
+			return fmt.Errorf("invalid limit value %s: %w", number, err)
+		}
+	}
+	return v.infer(raw)
+}
+
+// UnmarshalYAML decodes a value whose kind is inferred from the document; values are
+// parsed for their limit type by the LimitDefinition holding them
+func (v *LimitValue) UnmarshalYAML(node *yaml.Node) error {
+	var raw interface{}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	return v.infer(raw)
+}
+
+// infer sets the value to a decoded scalar of the kind it reads as
+func (v *LimitValue) infer(raw interface{}) error {
+	switch r := raw.(type) {
+	case nil:
+		*v = LimitValue{}
+	case int:
+		*v = IntValue(int64(r))
+	case int64:
+		*v = IntValue(r)
+	case uint64:
+		if r > math.MaxInt64 {
+			return fmt.Errorf("limit value %d is out of range", r)
+		}
+		*v = IntValue(int64(r))
+	case float64:
+		*v = FloatValue(r)
+	case bool:
+		*v = BoolValue(r)
+	case string:
+		*v = StringValue(r)
+	default:
+		return fmt.Errorf("limit value must be a scalar, got %T", raw)
+	}
+	return nil
+}
+
+// UnmarshalYAML decodes a limit definition, parsing its default, min and max values for
+// its type so a value that is not valid for the type fails loading the configuration
+func (d *LimitDefinition) UnmarshalYAML(node *yaml.Node) error {
+	type plain LimitDefinition
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+
+	def := LimitDefinition(decoded)
+	if _, known := LimitKindOf(def.Type); known {
+		for _, field := range []struct {
+			name  string
+			value *LimitValue
+		}{
+			{"default_value", &def.DefaultValue},
+			{"min_value", &def.MinValue},
+			{"max_value", &def.MaxValue},
+		} {
+			// An empty string leaves the value unset, like the bounds of string limits
+			if field.value.kind == KindString && field.value.s == "" {
+				*field.value = LimitValue{}
+			}
+			if !field.value.IsSet() {
+				continue
+			}
+			parsed, err := ParseLimitValue(*field.value, def.Type)
+			if err != nil {
+				return fmt.Errorf("limit definition %s: %s: %w", def.Name, field.name, err)
+			}
+			*field.value = parsed
+		}
+	}
+
+	*d = def
+	return nil
+}
+
+// ParseLimitValue parses a value for a limit type. Numbers, numeric strings and
+// LimitValues are accepted for every ordered type; duration strings such as "13h" or
+// "1h30m" and numbers of seconds for durations; byte counts and sizes such as "50MB",
+// "1.5GiB" or "512KiB" for sizes; booleans and "true"/"false" for bools; strings for
+// strings. Counts and sizes are rounded to the nearest whole number.
+func ParseLimitValue(value interface{}, limitType string) (LimitValue, error) {
+	kind, ok := LimitKindOf(limitType)
+	if !ok {
+		return LimitValue{}, fmt.Errorf("unknown limit type %q", limitType)
+	}
+
+	if typed, ok := value.(LimitValue); ok {
+		if typed.kind == kind {
+			return typed, nil
+		}
+		if !typed.IsSet() {
+			return LimitValue{}, fmt.Errorf("no value for %s limit", limitType)
+		}
+		value = typed.Interface()
+	}
+
+	switch kind {
+	case KindInt, KindFloat:
+		n, err := parseNumber(value)
+		if err != nil {
+			return LimitValue{}, err
+		}
+		if kind == KindFloat {
+			return FloatValue(n), nil
+		}
+		if math.Abs(n) >= math.MaxInt64 {
+			return LimitValue{}, fmt.Errorf("value %v is out of range for a count", value)
+		}
+		return IntValue(roundToInt64(n)), nil
+
+	case KindSize:
+		if s, ok := value.(string); ok {
+			bytes, err := ParseSize(s)
+			if err != nil {
+				return LimitValue{}, err
+			}
+			return SizeValue(bytes), nil
+		}
+		n, err := parseNumber(value)
+		if err != nil {
+			return LimitValue{}, err
+		}
+		if math.Abs(n) >= math.MaxInt64 {
+			return LimitValue{}, fmt.Errorf("value %v is out of range for a size", value)
+		}
+		return SizeValue(roundToInt64(n)), nil
+
+	case KindDuration:
+		switch v := value.(type) {
+		case time.Duration:
+			return DurationValue(v), nil
+		case string:
+			d, err := parseDuration(v)
+			if err != nil {
+				return LimitValue{}, fmt.Errorf("invalid duration %q, expected a duration such as 30s, 5m or 12h", v)
+			}
+			return DurationValue(d), nil
+		case float64, float32, int, int32, int64:
+			seconds, _ := parseNumber(v)
+			return DurationValue(time.Duration(seconds * float64(time.Second))), nil
+		default:
+			return LimitValue{}, fmt.Errorf("type mismatch: expected a duration string, got %T", value)
+		}
+
+	case KindBool:
+		switch v := value.(type) {
+		case bool:
+			return BoolValue(v), nil
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return LimitValue{}, fmt.Errorf("invalid boolean value %q, expected true or false", v)
+			}
+			return BoolValue(parsed), nil
+		default:
+			return LimitValue{}, fmt.Errorf("invalid boolean value of type %T, expected true or false", value)
+		}
+
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return LimitValue{}, fmt.Errorf("type mismatch: expected a string, got %T", value)
+		}
+		return StringValue(s), nil
+	}
+}
+
+// parseNumber converts a number or numeric string to a finite float64
+func parseNumber(value interface{}) (float64, error) {
+	var n float64
+	switch v := value.(type) {
+	case float64:
+		n = v
+	case float32:
+		n = float64(v)
+	case int:
+		n = float64(v)
+	case int32:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("type mismatch: expected a number, got string %q", v)
+		}
+		n = parsed
+	default:
+		return 0, fmt.Errorf("type mismatch: expected a number, got %T", value)
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("value %v is not a finite number", value)
+	}
+	return n, nil
+}
+
+// parseDuration parses Mimir's duration format, which adds days, weeks and years to
+// the units of Go durations, falling back to Go durations with fractions like "1.5h"
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := model.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// sizeUnits are the multipliers of the size units, decimal and binary
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ParseSize parses a number of bytes with an optional unit, decimal (KB, MB, GB, TB,
+// PB) or binary (KiB, MiB, GiB, TiB, PiB), such as "50MB" or "1.5GiB"
+func ParseSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	split := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split == -1 {
+		split = len(trimmed)
+	}
+
+	number, err := strconv.ParseFloat(trimmed[:split], 64)
+	multiplier, known := sizeUnits[strings.ToLower(strings.TrimSpace(trimmed[split:]))]
+	if err != nil || !known {
+		return 0, fmt.Errorf("invalid size %q, expected bytes or a size such as 512KiB, 50MB or 1GiB", s)
+	}
+	bytes := number * multiplier
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("size %q is out of range", s)
+	}
+	return roundToInt64(bytes), nil
+}
+
+func roundToInt64(n float64) int64 {
+	return int64(math.Round(n))
+}
+
+// mustDurationValue parses a duration of the default limit definitions, which are
+// known to be valid
+func mustDurationValue(s string) LimitValue {
+	d, err := parseDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return DurationValue(d)
+}
+
+// NumericValue returns a limit value held as a Go number as a float64
+func NumericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// LimitValuesEqual reports whether two values of a limit are the same. Numbers compare
+// exactly by value rather than Go type, since parsed YAML yields float64 numbers while
+// converted limits may be int64, and durations compare whether they are held as a
+// time.Duration or written as a duration string such as "12h".
+func LimitValuesEqual(a, b interface{}) bool {
+	if typed, ok := a.(LimitValue); ok {
+		a = typed.Interface()
+	}
+	if typed, ok := b.(LimitValue); ok {
+		b = typed.Interface()
+	}
+
+	if an, ok := NumericValue(a); ok {
+		bn, ok := NumericValue(b)
+		return ok && an == bn
+	}
+	if ad, ok := durationOf(a); ok {
+		if bd, ok := durationOf(b); ok {
+			return ad == bd
+		}
+	}
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+// durationOf returns a duration held as a time.Duration or a duration string
+func durationOf(value interface{}) (time.Duration, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		d, err := parseDuration(v)
+		return d, err == nil
+	default:
+		return 0, false
+	}
+}
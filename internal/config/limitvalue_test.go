@@ -0,0 +1,365 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseLimitValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		limitType string
+		want      LimitValue
+	}{
+		{"count from an int", 150000, "count", IntValue(150000)},
+		{"count from a float", 1.2e6, "count", IntValue(1200000)},
+		{"count rounded", 99.6, "count", IntValue(100)},
+		{"count from a string", "1.2e+06", "count", IntValue(1200000)},
+		{"rate from an int", int64(25000), "rate", FloatValue(25000)},
+		{"rate from a string", " 12.5 ", "rate", FloatValue(12.5)},
+		{"percentage", 0.5, "percentage", FloatValue(0.5)},
+		{"size in bytes", 1048576, "size", SizeValue(1048576)},
+		{"size from a float", 1.5e6, "size", SizeValue(1500000)},
+		{"size in MB", "50MB", "size", SizeValue(50000000)},
+		{"size in lowercase mb", "50mb", "size", SizeValue(50000000)},
+		{"size in KiB", "512KiB", "size", SizeValue(512 * 1024)},
+		{"size in fractional GiB", "1.5GiB", "size", SizeValue(1536 * 1024 * 1024)},
+		{"size with a space", "10 KB", "size", SizeValue(10000)},
+		{"size in plain bytes", "2048", "size", SizeValue(2048)},
+		{"duration in hours", "13h", "duration", DurationValue(13 * time.Hour)},
+		{"compound duration", "1h30m", "duration", DurationValue(90 * time.Minute)},
+		{"duration in days", "2d", "duration", DurationValue(48 * time.Hour)},
+		{"fractional duration", "1.5h", "duration", DurationValue(90 * time.Minute)},
+		{"duration in seconds", 3600, "duration", DurationValue(time.Hour)},
+		{"time.Duration", 5 * time.Minute, "duration", DurationValue(5 * time.Minute)},
+		{"bool", true, "bool", BoolValue(true)},
+		{"bool from a string", "false", "bool", BoolValue(false)},
+		{"string", "local", "string", StringValue("local")},
+		{"typed value of the kind", IntValue(7), "count", IntValue(7)},
+		{"typed value converted", FloatValue(7.4), "count", IntValue(7)},
+		{"typed duration string", StringValue("13h"), "duration", DurationValue(13 * time.Hour)},
+		{"typed size string", StringValue("50MB"), "size", SizeValue(50000000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimitValue(tt.value, tt.limitType)
+			if err != nil {
+				t.Fatalf("ParseLimitValue(%v, %s): %v", tt.value, tt.limitType, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimitValue(%v, %s) = %s %v, want %s %v", tt.value, tt.limitType, got.Kind(), got, tt.want.Kind(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLimitValueErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		limitType string
+	}{
+		{"count from text", "lots", "count"},
+		{"count from a bool", true, "count"},
+		{"count out of range", 1e19, "count"},
+		{"rate NaN", "NaN", "rate"},
+		{"rate infinite", "+Inf", "rate"},
+		{"unknown size unit", "50XB", "size"},
+		{"size without a number", "MB", "size"},
+		{"size out of range", "20EiB", "size"},
+		{"duration in words", "13 hours", "duration"},
+		{"duration of a bool", true, "duration"},
+		{"bool from text", "yes please", "bool"},
+		{"bool from a number", 1, "bool"},
+		{"string from a number", 42, "string"},
+		{"unset typed value", LimitValue{}, "count"},
+		{"unknown limit type", 1, "speed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseLimitValue(tt.value, tt.limitType); err == nil {
+				t.Errorf("ParseLimitValue(%v, %s) = %s %v, want an error", tt.value, tt.limitType, got.Kind(), got)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"0", 0},
+		{"1B", 1},
+		{"1KB", 1000},
+		{"1MB", 1000000},
+		{"1GB", 1000000000},
+		{"1TB", 1000000000000},
+		{"1KiB", 1 << 10},
+		{"1MiB", 1 << 20},
+		{"1GiB", 1 << 30},
+		{"1TiB", 1 << 40},
+		{"0.5MiB", 1 << 19},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.size)
+		if err != nil {
+			t.Errorf("ParseSize(%q): %v", tt.size, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestLimitValueApplyBuffer(t *testing.T) {
+	tests := []struct {
+		name  string
+		value LimitValue
+		want  LimitValue
+	}{
+		// A float buffer on a count must stay a whole number, not become "1.2e+06"
+		{"count", IntValue(1000000), IntValue(1200000)},
+		{"count rounded", IntValue(333), IntValue(400)},
+		{"size", SizeValue(50000000), SizeValue(60000000)},
+		{"rate", FloatValue(12.5), FloatValue(15)},
+		{"duration", DurationValue(10 * time.Hour), DurationValue(12 * time.Hour)},
+		{"bool unchanged", BoolValue(true), BoolValue(true)},
+		{"string unchanged", StringValue("local"), StringValue("local")},
+		{"unset unchanged", LimitValue{}, LimitValue{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.ApplyBuffer(20); got != tt.want {
+				t.Errorf("%v buffered by 20%% = %s %v, want %s %v", tt.value, got.Kind(), got, tt.want.Kind(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitValueCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b LimitValue
+		want int
+	}{
+		{"counts", IntValue(1), IntValue(2), -1},
+		{"equal counts", IntValue(2), IntValue(2), 0},
+		{"count and size", SizeValue(3), IntValue(2), 1},
+		{"count and rate", IntValue(2), FloatValue(1.5), 1},
+		{"rates", FloatValue(0.25), FloatValue(0.5), -1},
+		{"durations", DurationValue(13 * time.Hour), DurationValue(12 * time.Hour), 1},
+		{"large counts compared exactly", IntValue(1<<62 + 1), IntValue(1 << 62), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Compare(tt.b)
+			if err != nil {
+				t.Fatalf("Compare(%v, %v): %v", tt.a, tt.b, err)
+			}
+			if got != tt.want {
+				t.Errorf("Compare(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+
+	for _, pair := range [][2]LimitValue{
+		{DurationValue(time.Hour), IntValue(3600)},
+		{BoolValue(true), BoolValue(false)},
+		{StringValue("a"), StringValue("b")},
+		{IntValue(1), LimitValue{}},
+	} {
+		if _, err := pair[0].Compare(pair[1]); err == nil {
+			t.Errorf("Compare(%s %v, %s %v) succeeded, want an error", pair[0].Kind(), pair[0], pair[1].Kind(), pair[1])
+		}
+	}
+}
+
+func TestLimitValuesEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"parsed and converted number", 25000.0, int64(25000), true},
+		{"fractional change", 1.0, 1.5, false},
+		{"sub-unit rate change", 0.1, 0.2, false},
+		{"duration and duration string", "12h", 12 * time.Hour, true},
+		{"equal duration strings", "1d", "24h", true},
+		{"changed duration", "12h", 6 * time.Hour, false},
+		{"typed value", IntValue(100), 100.0, true},
+		{"number and string", 100.0, "100", false},
+		{"bools", true, true, true},
+		{"strings", "a", "b", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LimitValuesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("LimitValuesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitValueClamp(t *testing.T) {
+	tests := []struct {
+		name                  string
+		value, floor, ceiling LimitValue
+		want                  LimitValue
+	}{
+		{"within the bounds", IntValue(50), IntValue(10), IntValue(100), IntValue(50)},
+		{"raised to the floor", IntValue(5), IntValue(10), IntValue(100), IntValue(10)},
+		{"lowered to the ceiling", IntValue(500), IntValue(10), IntValue(100), IntValue(100)},
+		{"float ceiling converted to a count", IntValue(500), LimitValue{}, FloatValue(99.6), IntValue(100)},
+		{"count floor converted to a rate", FloatValue(0.5), IntValue(1), LimitValue{}, FloatValue(1)},
+		{"size", SizeValue(1 << 30), SizeValue(1 << 20), SizeValue(50000000), SizeValue(50000000)},
+		{"duration", DurationValue(48 * time.Hour), DurationValue(time.Hour), DurationValue(13 * time.Hour), DurationValue(13 * time.Hour)},
+		{"unset bounds ignored", IntValue(5), LimitValue{}, LimitValue{}, IntValue(5)},
+		{"incomparable bounds ignored", DurationValue(time.Minute), IntValue(3600), IntValue(7200), DurationValue(time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.Clamp(tt.floor, tt.ceiling); got != tt.want {
+				t.Errorf("Clamp(%v, %v, %v) = %s %v, want %s %v", tt.value, tt.floor, tt.ceiling, got.Kind(), got, tt.want.Kind(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitValueMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    LimitValue
+		wantJSON string
+		wantYAML string
+	}{
+		{"count", IntValue(1200000), "1200000", "1200000"},
+		{"buffered count", IntValue(1000000).ApplyBuffer(20), "1200000", "1200000"},
+		{"rate", FloatValue(12.5), "12.5", "12.5"},
+		{"size", SizeValue(50000000), "50000000", "50000000"},
+		{"duration", DurationValue(13 * time.Hour), `"13h"`, "13h"},
+		{"compound duration", DurationValue(90 * time.Minute), `"1h30m"`, "1h30m"},
+		{"duration in days", DurationValue(48 * time.Hour), `"2d"`, "2d"},
+		{"bool", BoolValue(true), "true", "true"},
+		{"string", StringValue("local"), `"local"`, "local"},
+		{"unset", LimitValue{}, "null", "null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("marshal JSON: %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("JSON = %s, want %s", data, tt.wantJSON)
+			}
+			data, err = yaml.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("marshal YAML: %v", err)
+			}
+			if got := strings.TrimSpace(string(data)); got != tt.wantYAML {
+				t.Errorf("YAML = %s, want %s", got, tt.wantYAML)
+			}
+		})
+	}
+}
+
+func TestLimitValueUnmarshalInfersKind(t *testing.T) {
+	tests := []struct {
+		document string
+		want     LimitValue
+	}{
+		{"1200000", IntValue(1200000)},
+		{"12.5", FloatValue(12.5)},
+		{"true", BoolValue(true)},
+		{`"13h"`, StringValue("13h")},
+		{"null", LimitValue{}},
+	}
+	for _, tt := range tests {
+		var fromJSON, fromYAML LimitValue
+		if err := json.Unmarshal([]byte(tt.document), &fromJSON); err != nil {
+			t.Errorf("unmarshal JSON %s: %v", tt.document, err)
+		} else if fromJSON != tt.want {
+			t.Errorf("JSON %s = %s %v, want %s %v", tt.document, fromJSON.Kind(), fromJSON, tt.want.Kind(), tt.want)
+		}
+		if err := yaml.Unmarshal([]byte(tt.document), &fromYAML); err != nil {
+			t.Errorf("unmarshal YAML %s: %v", tt.document, err)
+		} else if fromYAML != tt.want {
+			t.Errorf("YAML %s = %s %v, want %s %v", tt.document, fromYAML.Kind(), fromYAML, tt.want.Kind(), tt.want)
+		}
+	}
+}
+
+func TestLimitDefinitionUnmarshalYAMLParsesValuesForType(t *testing.T) {
+	document := `
+ingestion_rate:
+  name: ingestion_rate
+  type: rate
+  default_value: 25000
+  min_value: 1000
+max_global_series_per_user:
+  name: max_global_series_per_user
+  type: count
+  default_value: 1.5e5
+compactor_blocks_retention_period:
+  name: compactor_blocks_retention_period
+  type: duration
+  default_value: 13h
+  min_value: 1h
+  max_value: 2d
+max_fetched_chunk_bytes_per_query:
+  name: max_fetched_chunk_bytes_per_query
+  type: size
+  default_value: 50MB
+  max_value: 1GiB
+ingestion_rate_strategy:
+  name: ingestion_rate_strategy
+  type: string
+  default_value: local
+  min_value: ""
+`
+	var definitions map[string]LimitDefinition
+	if err := yaml.Unmarshal([]byte(document), &definitions); err != nil {
+		t.Fatalf("unmarshal limit definitions: %v", err)
+	}
+
+	want := map[string][3]LimitValue{
+		"ingestion_rate":                    {FloatValue(25000), FloatValue(1000), {}},
+		"max_global_series_per_user":        {IntValue(150000), {}, {}},
+		"compactor_blocks_retention_period": {DurationValue(13 * time.Hour), DurationValue(time.Hour), DurationValue(48 * time.Hour)},
+		"max_fetched_chunk_bytes_per_query": {SizeValue(50000000), {}, SizeValue(1 << 30)},
+		"ingestion_rate_strategy":           {StringValue("local"), {}, {}},
+	}
+	for name, values := range want {
+		def := definitions[name]
+		if got := [3]LimitValue{def.DefaultValue, def.MinValue, def.MaxValue}; got != values {
+			t.Errorf("%s default, min and max = %v, want %v", name, got, values)
+		}
+	}
+
+	invalid := "retention:\n  name: retention\n  type: duration\n  default_value: 13 hours\n"
+	if err := yaml.Unmarshal([]byte(invalid), &definitions); err == nil || !strings.Contains(err.Error(), "default_value") {
+		t.Errorf("unmarshal of an invalid duration default = %v, want an error naming default_value", err)
+	}
+}
+
+func TestDefaultLimitDefinitionsAreTyped(t *testing.T) {
+	for name, def := range GetDefaultLimitDefinitions() {
+		kind, known := LimitKindOf(def.Type)
+		if !known {
+			t.Errorf("%s has unknown type %q", name, def.Type)
+			continue
+		}
+		for field, value := range map[string]LimitValue{"default": def.DefaultValue, "min": def.MinValue, "max": def.MaxValue} {
+			if value.IsSet() && value.Kind() != kind {
+				t.Errorf("%s %s value %v is a %s, want a %s for type %s", name, field, value, value.Kind(), kind, def.Type)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Live publishes the configuration in effect to the components sharing it. A change
+// publishes a new Config instead of modifying the one in use, so a reader never sees a
+// configuration that is half replaced; a Config loaded from Live must not be modified.
+type Live struct {
+	current atomic.Pointer[Config]
+
+	// mu serializes the writers, so concurrent updates do not drop each other's changes
+	mu sync.Mutex
+}
+
+// NewLive creates a Live publishing cfg
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.current.Store(cfg)
+	return l
+}
+
+// Load returns the configuration in effect
+func (l *Live) Load() *Config {
+	return l.current.Load()
+}
+
+// Store publishes cfg as the configuration in effect
+func (l *Live) Store(cfg *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.current.Store(cfg)
+}
+
+// Update publishes a copy of the configuration in effect changed by update, and returns
+// it. The copy is shallow: update must replace, not modify, the maps and slices it
+// changes.
+func (l *Live) Update(update func(cfg *Config)) *Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := *l.current.Load()
+	update(&next)
+	l.current.Store(&next)
+	return &next
+}
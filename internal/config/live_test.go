@@ -0,0 +1,95 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLiveUpdatePublishesCopy(t *testing.T) {
+	original := GetDefaultConfig()
+	original.Mode = "dry-run"
+	original.TenantScoping.SkipList = []string{"internal-*"}
+	live := NewLive(original)
+
+	next := live.Update(func(cfg *Config) {
+		cfg.Mode = "prod"
+		cfg.TenantScoping.SkipList = []string{"test-*"}
+	})
+
+	if live.Load() != next {
+		t.Fatalf("Load() did not return the updated configuration")
+	}
+	if next.Mode != "prod" || next.TenantScoping.SkipList[0] != "test-*" {
+		t.Errorf("updated configuration = mode %q, skip list %v", next.Mode, next.TenantScoping.SkipList)
+	}
+	if original.Mode != "dry-run" || original.TenantScoping.SkipList[0] != "internal-*" {
+		t.Errorf("Update modified the previous configuration: mode %q, skip list %v",
+			original.Mode, original.TenantScoping.SkipList)
+	}
+}
+
+func TestLiveStoreReplacesConfig(t *testing.T) {
+	live := NewLive(GetDefaultConfig())
+	reloaded := GetDefaultConfig()
+	reloaded.SourceHash = "reloaded"
+
+	live.Store(reloaded)
+
+	if got := live.Load().SourceHash; got != "reloaded" {
+		t.Errorf("SourceHash = %q, want %q", got, "reloaded")
+	}
+}
+
+// TestLiveConcurrentAccess is meant for go test -race: readers run while updates and
+// reloads publish new configurations
+func TestLiveConcurrentAccess(t *testing.T) {
+	live := NewLive(GetDefaultConfig())
+	const updates = 100
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < updates; j++ {
+				cfg := live.Load()
+				_ = cfg.Mode
+				_ = len(cfg.TenantScoping.SkipList)
+			}
+		}()
+	}
+	for i := 0; i < updates; i++ {
+		live.Update(func(cfg *Config) {
+			cfg.BufferPercentage++
+		})
+		if i%10 == 0 {
+			live.Store(GetDefaultConfig())
+		}
+	}
+	wg.Wait()
+}
+
+func TestLiveConcurrentUpdatesKeepEveryChange(t *testing.T) {
+	cfg := GetDefaultConfig()
+	cfg.BufferPercentage = 0
+	live := NewLive(cfg)
+	const writers, updates = 8, 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < updates; j++ {
+				live.Update(func(cfg *Config) {
+					cfg.BufferPercentage++
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := live.Load().BufferPercentage; got != writers*updates {
+		t.Errorf("BufferPercentage = %v, want %d", got, writers*updates)
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+// RedactedValue replaces the secrets of a redacted configuration
+const RedactedValue = "<redacted>"
+
+// Redacted returns a copy of the configuration with its credentials replaced by
+// RedactedValue: the alerting and emergency webhook URLs, which embed their tokens, the
+// PagerDuty integration key, the SMTP and Redis passwords and the values of the custom
+// HTTP headers. Secrets that are not set stay empty, so the copy still shows which are.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+
+	alerting := &redacted.Alerting
+	alerting.Slack.WebhookURL = redactSecret(c.Alerting.Slack.WebhookURL)
+	alerting.PagerDuty.IntegrationKey = redactSecret(c.Alerting.PagerDuty.IntegrationKey)
+	alerting.Email.Password = redactSecret(c.Alerting.Email.Password)
+	alerting.Webhooks = make([]WebhookConfig, len(c.Alerting.Webhooks))
+	for i, webhook := range c.Alerting.Webhooks {
+		webhook.URL = redactSecret(webhook.URL)
+		webhook.Headers = redactHeaders(webhook.Headers)
+		alerting.Webhooks[i] = webhook
+	}
+
+	redacted.Emergency.WebhookURL = redactSecret(c.Emergency.WebhookURL)
+	redacted.Performance.Cache.Redis.Password = redactSecret(c.Performance.Cache.Redis.Password)
+	redacted.MetricsDiscovery.TenantDiscovery.TenantHeaders = redactHeaders(c.MetricsDiscovery.TenantDiscovery.TenantHeaders)
+	redacted.Limits.RemoteOverrideSource.Headers = redactHeaders(c.Limits.RemoteOverrideSource.Headers)
+	return &redacted
+}
+
+// redactSecret returns RedactedValue for a secret that is set
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
+
+// redactHeaders returns the header names with redacted values
+func redactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(headers))
+	for name, value := range headers {
+		redacted[name] = redactSecret(value)
+	}
+	return redacted
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// errInvalidGlob explains the glob syntax to the author of a malformed pattern
+var errInvalidGlob = errors.New("invalid glob: use * for any characters, ? for one character and [...] for a character class with a closing ]")
+
+// CompileTenantRegex compiles a tenantScoping regex pattern, anchored to the whole
+// tenant ID
+func CompileTenantRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// ValidateTenantPattern checks that a tenantScoping pattern is a valid glob, or a valid
+// regular expression when useRegex is set
+func ValidateTenantPattern(pattern string, useRegex bool) error {
+	if useRegex {
+		if _, err := CompileTenantRegex(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return errInvalidGlob
+	}
+	return nil
+}
+
+// validate checks every pattern of the lists, so a typo fails loading the
+// configuration instead of silently matching no tenant
+func (s TenantScopingConfig) validate() error {
+	lists := []struct {
+		name     string
+		patterns []string
+	}{
+		{"skipList", s.SkipList},
+		{"includeList", s.IncludeList},
+		{"pausedTenants", s.PausedTenants},
+	}
+	for _, list := range lists {
+		for _, pattern := range list.patterns {
+			if err := ValidateTenantPattern(pattern, s.UseRegex); err != nil {
+				return fmt.Errorf("tenantScoping.%s pattern %q: %w", list.name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchTenantPattern reports whether a tenant matches a tenantScoping pattern: a glob,
+// or a regular expression anchored to the whole tenant ID when useRegex is set. Invalid
+// patterns match no tenant.
+func MatchTenantPattern(tenant, pattern string, useRegex bool) bool {
+	if useRegex {
+		re, err := CompileTenantRegex(pattern)
+		return err == nil && re.MatchString(tenant)
+	}
+	matched, err := path.Match(pattern, tenant)
+	return err == nil && matched
+}
+
+// Allows reports whether the lists select a tenant: it must match the include list,
+// when one is set, and then no pattern of the skip list
+func (s TenantScopingConfig) Allows(tenant string) bool {
+	if len(s.IncludeList) > 0 {
+		included := false
+		for _, pattern := range s.IncludeList {
+			if MatchTenantPattern(tenant, pattern, s.UseRegex) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	for _, pattern := range s.SkipList {
+		if MatchTenantPattern(tenant, pattern, s.UseRegex) {
+			return false
+		}
+	}
+	return true
+}
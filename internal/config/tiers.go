@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// tierRegexPrefix marks a limits.tenantTiers tenant pattern as a regular expression
+const tierRegexPrefix = "regex:"
+
+// tierRegexes caches the compiled regular expressions of tier tenant patterns, which
+// are matched against every tenant on every reconciliation
+var tierRegexes sync.Map
+
+// TierAssignment is the tier a tenant is resolved to
+type TierAssignment struct {
+	// Tier is empty when the tenant is in no tier
+	Tier string `json:"tier"`
+
+	// Pattern is the limits.tenantTiers pattern that matched, empty for an explicit
+	// limits.tierAssignments entry
+	Pattern string `json:"pattern,omitempty"`
+
+	// Conflicts are the other tiers with a matching pattern, sorted by name
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// TierForTenant resolves the tier of a tenant. An explicit limits.tierAssignments
+// entry wins; otherwise the most specific matching tier pattern does: a pattern equal
+// to the tenant ID, then the longest pattern, then the tier name in lexical order.
+func (l LimitsConfig) TierForTenant(tenant string) TierAssignment {
+	if tier, assigned := l.TierAssignments[tenant]; assigned {
+		if _, exists := l.TenantTiers[tier]; exists {
+			return TierAssignment{Tier: tier}
+		}
+	}
+
+	names := l.tierNames()
+	var best TierAssignment
+	matched := make(map[string]bool)
+	for _, tier := range names {
+		for _, pattern := range l.TenantTiers[tier].Tenants {
+			if !matchTierPattern(pattern, tenant) {
+				continue
+			}
+			matched[tier] = true
+			if best.Tier == "" || moreSpecificTierPattern(pattern, best.Pattern, tenant) {
+				best = TierAssignment{Tier: tier, Pattern: pattern}
+			}
+		}
+	}
+
+	for _, tier := range names {
+		if matched[tier] && tier != best.Tier {
+			best.Conflicts = append(best.Conflicts, tier)
+		}
+	}
+	return best
+}
+
+// tierNames returns the configured tiers sorted by name
+func (l LimitsConfig) tierNames() []string {
+	names := make([]string, 0, len(l.TenantTiers))
+	for name := range l.TenantTiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// moreSpecificTierPattern reports whether pattern is more specific than current for a
+// tenant both match. Patterns are visited in tier name order, so ties keep current.
+func moreSpecificTierPattern(pattern, current, tenant string) bool {
+	exact, currentExact := pattern == tenant, current == tenant
+	if exact != currentExact {
+		return exact
+	}
+	return len(strings.TrimPrefix(pattern, tierRegexPrefix)) > len(strings.TrimPrefix(current, tierRegexPrefix))
+}
+
+// matchTierPattern matches a tenant against a tier pattern: a glob, or a regular
+// expression anchored to the whole tenant ID when prefixed with "regex:"
+func matchTierPattern(pattern, tenant string) bool {
+	if !strings.HasPrefix(pattern, tierRegexPrefix) {
+		ok, err := path.Match(pattern, tenant)
+		return err == nil && ok
+	}
+
+	if cached, exists := tierRegexes.Load(pattern); exists {
+		re, _ := cached.(*regexp.Regexp)
+		return re != nil && re.MatchString(tenant)
+	}
+	re, err := compileTierRegex(pattern)
+	if err != nil {
+		re = nil
+	}
+	tierRegexes.Store(pattern, re)
+	return re != nil && re.MatchString(tenant)
+}
+
+// compileTierRegex compiles a "regex:" tier pattern anchored to the whole tenant ID
+func compileTierRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + strings.TrimPrefix(pattern, tierRegexPrefix) + ")$")
+}
+
+// validateTenantTiers checks the buffer and tenant patterns of each limits.tenantTiers
+// entry and that limits.tierAssignments refers to defined tiers
+func (c *Config) validateTenantTiers() error {
+	for _, name := range c.Limits.tierNames() {
+		tier := c.Limits.TenantTiers[name]
+		if tier.BufferPercentage < 0 || tier.BufferPercentage > 1000 {
+			return fmt.Errorf("limits.tenantTiers[%s].bufferPercentage must be between 0 and 1000, got %f", name, tier.BufferPercentage)
+		}
+		for _, pattern := range tier.Tenants {
+			if strings.TrimSpace(strings.TrimPrefix(pattern, tierRegexPrefix)) == "" {
+				return fmt.Errorf("limits.tenantTiers[%s].tenants cannot contain an empty pattern", name)
+			}
+			if strings.HasPrefix(pattern, tierRegexPrefix) {
+				if _, err := compileTierRegex(pattern); err != nil {
+					return fmt.Errorf("limits.tenantTiers[%s].tenants has an invalid regular expression %q: %w", name, pattern, err)
+				}
+				continue
+			}
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("limits.tenantTiers[%s].tenants has an invalid tenant glob %q", name, pattern)
+			}
+		}
+	}
+
+	for tenant, tier := range c.Limits.TierAssignments {
+		if _, exists := c.Limits.TenantTiers[tier]; !exists {
+			return fmt.Errorf("limits.tierAssignments[%s] refers to undefined tier %q", tenant, tier)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// reloadDebounce coalesces the burst of events a single ConfigMap update produces
+const reloadDebounce = time.Second
+
+// ReloadHook applies a reloaded configuration that passed validation
+type ReloadHook func(cfg *Config)
+
+// ReloadValidator checks a reloaded configuration beyond Validate, for settings
+// validated by other packages
+type ReloadValidator func(cfg *Config) error
+
+// Watcher reloads the config file when it changes. A reloaded file is parsed and
+// validated first; only a valid configuration is handed to the reload hooks, an
+// invalid one is logged and the live configuration is kept.
+//
+// The directory holding the file is watched rather than the file itself, because a
+// mounted ConfigMap is updated by swapping a symlink, not by writing to the file.
+type Watcher struct {
+	path string
+	log  logr.Logger
+
+	mu          sync.Mutex
+	currentHash string
+	hooks       []ReloadHook
+	validators  []ReloadValidator
+}
+
+// NewWatcher creates a watcher for the config file at path; current is the
+// configuration loaded from it at startup
+func NewWatcher(path string, current *Config, log logr.Logger) *Watcher {
+	return &Watcher{
+		path:        path,
+		log:         log,
+		currentHash: current.SourceHash,
+	}
+}
+
+// OnReload registers a hook called, in registration order, with each valid reloaded configuration
+func (w *Watcher) OnReload(hook ReloadHook) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.hooks = append(w.hooks, hook)
+}
+
+// AddValidator registers a check a reloaded configuration must pass before it is applied
+func (w *Watcher) AddValidator(validator ReloadValidator) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.validators = append(w.validators, validator)
+}
+
+// Start watches the config file until ctx is cancelled
+func (w *Watcher) Start(ctx context.Context) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config file watcher: %w", err)
+	}
+	dir := filepath.Dir(w.path)
+	if err := fsWatcher.Add(dir); err != nil {
+		_ = fsWatcher.Close()
+		return fmt.Errorf("failed to watch config directory %s: %w", dir, err)
+	}
+
+	w.log.Info("watching config file for changes", "path", w.path)
+
+	go func() {
+		defer func() {
+			if err := fsWatcher.Close(); err != nil {
+				w.log.Error(err, "failed to close config file watcher")
+			}
+		}()
+
+		debounce := time.NewTimer(reloadDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				if w.affectsConfig(event) {
+					debounce.Reset(reloadDebounce)
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				w.log.Error(err, "config file watcher error")
+			case <-debounce.C:
+				w.Reload()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// affectsConfig reports whether a change in the watched directory may have changed the
+// config file: a write to it, or a swap of the ConfigMap's ..data symlink
+func (w *Watcher) affectsConfig(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return false
+	}
+	name := filepath.Base(event.Name)
+	return name == filepath.Base(w.path) || name == "..data"
+}
+
+// Reload re-reads the config file and applies it when it changed and is valid. It
+// reports whether a new configuration was applied.
+func (w *Watcher) Reload() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg, err := LoadConfigFromFile(w.path)
+	if err != nil {
+		w.reject(err)
+		return false
+	}
+	if cfg.SourceHash == w.currentHash {
+		return false
+	}
+	if err := cfg.Validate(); err != nil {
+		w.reject(fmt.Errorf("invalid configuration: %w", err))
+		return false
+	}
+	for _, validator := range w.validators {
+		if err := validator(cfg); err != nil {
+			w.reject(fmt.Errorf("invalid configuration: %w", err))
+			return false
+		}
+	}
+
+	for _, hook := range w.hooks {
+		hook(cfg)
+	}
+	w.currentHash = cfg.SourceHash
+
+	metrics.ConfigReloadMetricsInstance.IncConfigReload("applied")
+	w.log.Info("reloaded config file", "path", w.path, "hash", cfg.SourceHash)
+	return true
+}
+
+// reject records a reload that left the live configuration in place
+func (w *Watcher) reject(err error) {
+	metrics.ConfigReloadMetricsInstance.IncConfigReload("rejected")
+	w.log.Error(err, "rejected config file reload, keeping the current configuration", "path", w.path)
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"math"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// anomalyMetric is the ingestion rate tenants are compared by
+	anomalyMetric = "cortex_distributor_received_samples_total"
+
+	// anomalyMinSpread floors the spread of the peers at this share of their mean, so
+	// tenants are not anomalous for small differences from peers with identical rates
+	anomalyMinSpread = 0.1
+)
+
+// TenantAnomaly is a tenant whose ingestion rate deviates from the other tenants of its
+// tier by more than anomalyDetection.zScoreThreshold standard deviations
+type TenantAnomaly struct {
+	Tenant string `json:"tenant"`
+	// Tier is empty for the group of tenants without a tier
+	Tier       string    `json:"tier"`
+	Rate       float64   `json:"rate"`
+	PeerMean   float64   `json:"peer_mean"`
+	PeerStdDev float64   `json:"peer_std_dev"`
+	ZScore     float64   `json:"z_score"`
+	Peers      int       `json:"peers"`
+	Since      time.Time `json:"since"`
+}
+
+// detectAnomalies compares the ingestion rate of each tenant with the other tenants of
+// its tier, flagging those whose Z-score exceeds the threshold. A tenant is compared
+// with its peers only, so a single outlier does not hide itself by skewing the mean.
+// Tenants back within the threshold are no longer flagged.
+func (r *MimirLimitController) detectAnomalies(tenantMetrics map[string]*collector.TenantMetrics) {
+	cfg := r.config().AnomalyDetection
+	if !cfg.Enabled {
+		r.setAnomalies(nil)
+		return
+	}
+
+	groups := make(map[string]map[string]float64)
+	for tenant, tm := range tenantMetrics {
+		data := tm.Metrics[anomalyMetric]
+		if len(data) == 0 {
+			continue
+		}
+		tier := r.config().Limits.TierForTenant(tenant).Tier
+		if groups[tier] == nil {
+			groups[tier] = make(map[string]float64)
+		}
+		groups[tier][tenant] = data[len(data)-1].Value
+	}
+
+	r.anomalyMu.RLock()
+	previous := r.anomalies
+	r.anomalyMu.RUnlock()
+
+	now := time.Now()
+	anomalies := make(map[string]TenantAnomaly)
+	for tier, rates := range groups {
+		if len(rates) < cfg.MinPeerGroupSize {
+			continue
+		}
+		for tenant, rate := range rates {
+			mean, stdDev := peerStats(rates, tenant)
+			spread := math.Max(stdDev, anomalyMinSpread*mean)
+			if spread == 0 {
+				continue
+			}
+			zScore := (rate - mean) / spread
+			if math.Abs(zScore) <= cfg.ZScoreThreshold {
+				continue
+			}
+
+			anomaly := TenantAnomaly{
+				Tenant:     tenant,
+				Tier:       tier,
+				Rate:       rate,
+				PeerMean:   mean,
+				PeerStdDev: stdDev,
+				ZScore:     zScore,
+				Peers:      len(rates) - 1,
+				Since:      now,
+			}
+			if flagged, exists := previous[tenant]; exists {
+				anomaly.Since = flagged.Since
+			} else {
+				r.Log.Info("anomalous tenant usage detected", "tenant", tenant, "tier", tier,
+					"rate", rate, "peer_mean", mean, "z_score", zScore, "peers", anomaly.Peers)
+				if manager := r.GetAlertManager(); manager != nil {
+					manager.SendAlert(alerting.CreateAnomalyAlert(tenant, tier, rate, mean, zScore, anomaly.Peers))
+				}
+			}
+			anomalies[tenant] = anomaly
+		}
+	}
+
+	for tenant := range previous {
+		if _, exists := anomalies[tenant]; !exists {
+			r.Log.Info("tenant usage no longer anomalous", "tenant", tenant)
+		}
+	}
+	r.setAnomalies(anomalies)
+}
+
+// peerStats returns the mean and standard deviation of the rates of every tenant but one
+func peerStats(rates map[string]float64, tenant string) (float64, float64) {
+	var sum, sumSquares float64
+	n := 0
+	for peer, rate := range rates {
+		if peer == tenant {
+			continue
+		}
+		sum += rate
+		sumSquares += rate * rate
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	mean := sum / float64(n)
+	variance := sumSquares/float64(n) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, math.Sqrt(variance)
+}
+
+// setAnomalies replaces the flagged tenants
+func (r *MimirLimitController) setAnomalies(anomalies map[string]TenantAnomaly) {
+	r.anomalyMu.Lock()
+	r.anomalies = anomalies
+	r.anomalyMu.Unlock()
+
+	metrics.TenantMetricsInstance.SetAnomalousTenants(float64(len(anomalies)))
+}
+
+// GetAnomaly returns the anomaly of a tenant flagged by the last reconciliation
+func (r *MimirLimitController) GetAnomaly(tenant string) (TenantAnomaly, bool) {
+	r.anomalyMu.RLock()
+	defer r.anomalyMu.RUnlock()
+	anomaly, exists := r.anomalies[tenant]
+	return anomaly, exists
+}
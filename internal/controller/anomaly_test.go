@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// goldRates are the ingestion rates of the gold tier, whose median is 10000 samples/s
+var goldRates = map[string]float64{
+	"gold-a": 9000,
+	"gold-b": 9500,
+	"gold-c": 10000,
+	"gold-d": 10000,
+	"gold-e": 10500,
+	"gold-f": 11000,
+}
+
+// alertRecorder is a webhook receiving the default alert payload
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []alerting.Alert
+}
+
+func (r *alertRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var alert alerting.Alert
+	if err := json.NewDecoder(req.Body).Decode(&alert); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.alerts = append(r.alerts, alert)
+	r.mu.Unlock()
+}
+
+// delivered returns the delivered alerts of the type
+func (r *alertRecorder) delivered(alertType alerting.AlertType) []alerting.Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var alerts []alerting.Alert
+	for _, alert := range r.alerts {
+		if alert.Type == alertType {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// waitForAlerts waits until count alerts of the type were delivered, and a moment
+// longer for any unexpected ones, and returns them
+func (r *alertRecorder) waitForAlerts(alertType alerting.AlertType, count int) []alerting.Alert {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.delivered(alertType)) < count && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	return r.delivered(alertType)
+}
+
+// newAnomalyTestController creates a controller detecting anomalies among the gold and
+// silver tiers
+func newAnomalyTestController() *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.AnomalyDetection.Enabled = true
+	cfg.Limits.TenantTiers = map[string]config.TenantTierConfig{
+		"gold":   {BufferPercentage: 20, Tenants: []string{"gold-*"}},
+		"silver": {BufferPercentage: 20, Tenants: []string{"silver-*"}},
+	}
+	return newTestController(cfg, overridesConfigMap(cfg, "overrides: {}\n"))
+}
+
+// recordAlerts has the controller alert to a recorded webhook
+func (tc *testController) recordAlerts(t *testing.T) *alertRecorder {
+	recorder := &alertRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	alertingConfig := config.AlertingConfig{
+		Enabled:  true,
+		Webhooks: []config.WebhookConfig{{Name: "recorder", URL: server.URL, Enabled: true, Timeout: time.Second}},
+	}
+	manager := alerting.NewManager(&alertingConfig, logr.Discard())
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start alerting manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+	tc.SetAlertManager(manager)
+	return recorder
+}
+
+// tierMetrics reports the ingestion rate of each tenant
+func tierMetrics(rates map[string]float64) map[string]*collector.TenantMetrics {
+	result := make(map[string]*collector.TenantMetrics, len(rates))
+	for tenant, rate := range rates {
+		for name, tm := range ingestionMetrics(rate, tenant) {
+			result[name] = tm
+		}
+	}
+	return result
+}
+
+func withRate(rates map[string]float64, tenant string, rate float64) map[string]float64 {
+	result := make(map[string]float64, len(rates)+1)
+	for name, value := range rates {
+		result[name] = value
+	}
+	result[tenant] = rate
+	return result
+}
+
+func TestAnomalousTenantFlagged(t *testing.T) {
+	tc := newAnomalyTestController()
+	recorder := tc.recordAlerts(t)
+	ctx := context.Background()
+
+	// gold-g ingests 10 times the median of its peers
+	rates := withRate(goldRates, "gold-g", 100000)
+	tc.collector.setMetrics(tierMetrics(rates))
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	anomaly, anomalous := tc.GetAnomaly("gold-g")
+	if !anomalous {
+		t.Fatalf("gold-g at 10x the peer median is not anomalous")
+	}
+	if anomaly.Tier != "gold" || anomaly.Peers != 6 || anomaly.PeerMean != 10000 || anomaly.ZScore <= 3 {
+		t.Errorf("anomaly = %+v, want gold-g 10000 mean of 6 gold peers beyond a Z-score of 3", anomaly)
+	}
+	for tenant := range goldRates {
+		if _, anomalous := tc.GetAnomaly(tenant); anomalous {
+			t.Errorf("%s within its peer group is anomalous", tenant)
+		}
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_anomalous_tenants", nil); got != 1 {
+		t.Errorf("mimir_limit_optimizer_anomalous_tenants = %v, want 1", got)
+	}
+
+	alerts := recorder.waitForAlerts(alerting.AlertTypeAnomaly, 1)
+	if len(alerts) != 1 || alerts[0].Tenant != "gold-g" || alerts[0].Priority != alerting.PriorityP3 {
+		t.Fatalf("alerts = %+v, want one P3 anomaly alert for gold-g", alerts)
+	}
+
+	// A tenant still anomalous is not alerted again
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if alerts := recorder.delivered(alerting.AlertTypeAnomaly); len(alerts) != 1 {
+		t.Errorf("alerts after the second reconcile = %d, want the anomaly alerted once", len(alerts))
+	}
+
+	// The anomaly resets once the rate is back within the threshold
+	tc.collector.setMetrics(tierMetrics(withRate(goldRates, "gold-g", 10000)))
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if _, anomalous := tc.GetAnomaly("gold-g"); anomalous {
+		t.Errorf("gold-g is still anomalous back at the peer median")
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_anomalous_tenants", nil); got != 0 {
+		t.Errorf("mimir_limit_optimizer_anomalous_tenants after the reset = %v, want 0", got)
+	}
+	// Let the limit changes of the first reconcile be delivered before the manager stops
+	recorder.waitForAlerts(alerting.AlertTypeLimitChange, len(rates))
+}
+
+func TestAnomalyNeedsPeerGroup(t *testing.T) {
+	tc := newAnomalyTestController()
+
+	// Four silver tenants are fewer than the minimum peer group of 5
+	tc.collector.setMetrics(tierMetrics(map[string]float64{
+		"silver-a": 10000,
+		"silver-b": 10000,
+		"silver-c": 11000,
+		"silver-d": 100000,
+	}))
+	if _, err := tc.reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if anomaly, anomalous := tc.GetAnomaly("silver-d"); anomalous {
+		t.Errorf("silver-d is anomalous in a group of 4 tenants: %+v", anomaly)
+	}
+}
+
+func TestAnomalyDetectionDisabled(t *testing.T) {
+	tc := newAnomalyTestController()
+	tc.Config.Update(func(cfg *config.Config) { cfg.AnomalyDetection.Enabled = false })
+
+	tc.collector.setMetrics(tierMetrics(withRate(goldRates, "gold-g", 100000)))
+	if _, err := tc.reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if _, anomalous := tc.GetAnomaly("gold-g"); anomalous {
+		t.Errorf("gold-g is anomalous with anomaly detection disabled")
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/appliedlimits"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// checkLoadedLimits compares the ConfigMap limits with the limits Mimir has loaded and
+// warns about limits that differ for longer than the mismatch grace period, as written
+// changes are then not taking effect. The warning is resolved once they match again.
+func (r *MimirLimitController) checkLoadedLimits(ctx context.Context) {
+	report, err := r.compareLoadedLimits(ctx)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("applied-limits", "loaded-limits-check")
+		r.Log.Error(err, "failed to compare ConfigMap limits with the limits loaded by Mimir")
+		return
+	}
+
+	if report.StaleCount == 0 {
+		if manager := r.GetAlertManager(); r.limitsNotLoadedAlerted && manager != nil {
+			manager.SendAlert(alerting.CreateResolvedAlert(alerting.AlertTypeLimitsNotLoaded, "",
+				"Mimir has loaded the limits of the runtime overrides ConfigMap"))
+		}
+		r.limitsNotLoadedAlerted = false
+		return
+	}
+
+	tenants := make(map[string]bool)
+	for _, item := range report.Stale() {
+		tenants[item.TenantID] = true
+	}
+
+	r.Log.Info("limits in the ConfigMap not loaded by Mimir",
+		"endpoint", report.Endpoint,
+		"stale", report.StaleCount,
+		"tenants", len(tenants),
+		"grace_period", report.GracePeriod)
+
+	manager := r.GetAlertManager()
+	if manager == nil {
+		return
+	}
+
+	manager.SendAlert(alerting.CreateLimitsNotLoadedAlert(report.StaleCount, report.GracePeriod,
+		map[string]interface{}{
+			"endpoint":         report.Endpoint,
+			"stale_limits":     report.StaleCount,
+			"affected_tenants": len(tenants),
+			"mismatched":       report.MismatchedCount,
+			"not_loaded":       report.NotLoadedCount,
+			"grace_period":     report.GracePeriod.String(),
+		}))
+	r.limitsNotLoadedAlerted = true
+}
+
+// compareLoadedLimits reads the ConfigMap limits and compares them with the loaded ones
+func (r *MimirLimitController) compareLoadedLimits(ctx context.Context) (*appliedlimits.Report, error) {
+	currentLimits, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap limits: %w", err)
+	}
+
+	configMap := make(map[string]map[string]interface{}, len(currentLimits))
+	for tenant, tenantLimits := range currentLimits {
+		configMap[tenant] = tenantLimits.Limits
+	}
+	return r.AppliedLimits.Check(ctx, configMap)
+}
+
+// GetLoadedLimits scrapes the limits Mimir has loaded from the overrides-exporter
+func (r *MimirLimitController) GetLoadedLimits(ctx context.Context) (appliedlimits.TenantLimits, error) {
+	if r.AppliedLimits == nil {
+		return nil, fmt.Errorf("overrides-exporter is not enabled")
+	}
+	return r.AppliedLimits.Read(ctx)
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+)
+
+const (
+	// blastBaselineConfigMapName checkpoints the blast detector baselines across restarts
+	blastBaselineConfigMapName = "mimir-optimizer-blast-baselines"
+	blastBaselineDataKey       = "baselines.yaml"
+
+	// baselineCheckpointTimeout bounds the final checkpoint written on shutdown
+	baselineCheckpointTimeout = 10 * time.Second
+)
+
+// BlastBaselineState is the checkpoint of the blast detector baselines
+type BlastBaselineState struct {
+	CheckpointedAt time.Time                               `json:"checkpointedAt"`
+	Tenants        map[string]circuitbreaker.BaselineRates `json:"tenants"`
+}
+
+// startBlastBaselineCheckpoints reloads the checkpointed baselines and checkpoints
+// them every circuitBreaker.blastProtection.baselineCheckpointInterval, and once more
+// on shutdown
+func (r *MimirLimitController) startBlastBaselineCheckpoints(ctx context.Context) {
+	interval := r.config().CircuitBreaker.BlastProtection.BaselineCheckpointInterval
+	if r.BlastProtector == nil || r.KubeClient == nil || interval <= 0 {
+		return
+	}
+
+	r.restoreBlastBaselines(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), baselineCheckpointTimeout)
+				if err := r.checkpointBlastBaselines(shutdownCtx); err != nil {
+					r.Log.Error(err, "failed to checkpoint blast baselines on shutdown")
+				}
+				cancel()
+				return
+			case <-ticker.C:
+				if err := r.checkpointBlastBaselines(ctx); err != nil {
+					r.Log.Error(err, "failed to checkpoint blast baselines")
+				}
+			}
+		}
+	}()
+}
+
+// restoreBlastBaselines reloads the checkpointed baselines. Without a checkpoint, on a
+// first run, or with an unreadable one the baselines are computed from scratch.
+func (r *MimirLimitController) restoreBlastBaselines(ctx context.Context) {
+	configMap, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Get(ctx, blastBaselineConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		r.Log.Info("no checkpointed blast baselines, computing baselines from scratch")
+		return
+	}
+	if err != nil {
+		r.Log.Error(err, "failed to read checkpointed blast baselines, computing baselines from scratch")
+		return
+	}
+
+	var state BlastBaselineState
+	if err := yaml.Unmarshal([]byte(configMap.Data[blastBaselineDataKey]), &state); err != nil {
+		r.Log.Error(err, "failed to parse checkpointed blast baselines, computing baselines from scratch")
+		return
+	}
+
+	maxAge := r.config().CircuitBreaker.BlastProtection.BaselineMaxAge
+	restored, stale := r.BlastProtector.RestoreBaselines(state.Tenants, maxAge, time.Now())
+	r.Log.Info("restored checkpointed blast baselines",
+		"restored", restored,
+		"stale", stale,
+		"checkpointed_at", state.CheckpointedAt,
+		"max_age", maxAge)
+}
+
+// checkpointBlastBaselines writes the current baselines to the checkpoint ConfigMap
+func (r *MimirLimitController) checkpointBlastBaselines(ctx context.Context) error {
+	state := BlastBaselineState{
+		CheckpointedAt: time.Now(),
+		Tenants:        r.BlastProtector.Baselines(),
+	}
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blast baselines: %w", err)
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config()))
+	configMap, err := configMaps.Get(ctx, blastBaselineConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if len(state.Tenants) == 0 {
+			return nil
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      blastBaselineConfigMapName,
+				Namespace: lockNamespace(r.config()),
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "mimir-limit-optimizer",
+					"app.kubernetes.io/component": "blast-baselines",
+				},
+			},
+			Data: map[string]string{blastBaselineDataKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create blast baseline ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get blast baseline ConfigMap: %w", err)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[blastBaselineDataKey] = string(data)
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update blast baseline ConfigMap: %w", err)
+	}
+
+	r.Log.V(1).Info("checkpointed blast baselines", "tenants", len(state.Tenants))
+	return nil
+}
+
+// GetBlastBaselines returns the baseline rates the blast detector compares each
+// tenant against
+func (r *MimirLimitController) GetBlastBaselines() (map[string]circuitbreaker.BaselineRates, error) {
+	if r.BlastProtector == nil {
+		return nil, fmt.Errorf("blast protection not initialized")
+	}
+	return r.BlastProtector.Baselines(), nil
+}
+
+// ResetRateLimiter refills the rate limiter token bucket of a tenant. It reports false
+// for a tenant without a bucket.
+func (r *MimirLimitController) ResetRateLimiter(tenant string) (bool, error) {
+	if r.BlastProtector == nil {
+		return false, fmt.Errorf("blast protection not initialized")
+	}
+	return r.BlastProtector.ResetRateLimiter(tenant), nil
+}
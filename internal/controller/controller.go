@@ -2,7 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -11,99 +19,390 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/appliedlimits"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/cache"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/costcontrol"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/digest"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/drift"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/history"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/locking"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/remoteoverrides"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/statestore"
+)
+
+// ConfigMap write lock settings used when leader election is disabled
+const (
+	writeLockName          = "mimir-limit-optimizer-write-lock"
+	writeLockTTL           = 30 * time.Second
+	writeLockRetryInterval = 1 * time.Second
+	writeLockTimeout       = 5 * time.Second
 )
 
 // MimirLimitController orchestrates the complete limit optimization workflow
 type MimirLimitController struct {
 	client.Client
 	Scheme     *runtime.Scheme
-	Config     *config.Config
+	Config     *config.Live
 	Log        logr.Logger
 	KubeClient kubernetes.Interface
 
+	// APIReader reads from the API server directly, bypassing the manager's cache
+	APIReader client.Reader
+
+	// HealthCache watches the workloads of the Mimir namespace for health scans; nil
+	// when healthScanner.watchCache is disabled
+	HealthCache *discovery.HealthCache
+
+	// LeaderElection reports whether the controller manager runs with leader
+	// election; without it ConfigMap writes are serialized through WriteLock
+	LeaderElection bool
+
 	// Core components
 	Collector   collector.Collector
 	Analyzer    analyzer.Analyzer
 	Patcher     patcher.Patcher
 	AuditLogger auditlog.AuditLogger
 
+	// RecommendationHistory keeps how the recommendations of each tenant evolved
+	RecommendationHistory history.Store
+
 	// Enterprise components
 	CostController *costcontrol.CostController
 	BlastProtector *circuitbreaker.BlastProtector
 
+	// EmergencyMonitor enters and exits panic and emergency mode on the emergency triggers
+	EmergencyMonitor *circuitbreaker.EmergencyMonitor
+
+	DriftDetector *drift.Detector
+	WriteLock     *locking.LeaseLock
+	Digest        *digest.Scheduler
+	Cache         cache.Cache
+
+	// RemoteOverrides supplies per-tenant limits that replace calculated ones
+	RemoteOverrides *remoteoverrides.Source
+
+	// AppliedLimits reads the limits Mimir has loaded from the overrides-exporter
+	AppliedLimits *appliedlimits.Reader
+
+	// StateStore persists the recommendations and circuit breaker state of standalone
+	// mode; nil in Kubernetes and without standalone.stateDB
+	StateStore *statestore.Store
+
 	// Internal state
 	lastReconcile  time.Time
 	reconcileCount int64
 	tenantFilter   *TenantFilter
+
+	// inactiveMu guards tenantLastSeen and prunedTenants, which previews read while
+	// reconciles update them
+	inactiveMu sync.Mutex
+
+	// tenantLastSeen records when each tenant with overrides was last reported by the
+	// collector, for removing the limits of inactive tenants
+	tenantLastSeen map[string]time.Time
+
+	// prunedTenants records when each tenant was removed for inactivity, so its
+	// DefaultLimits are reapplied when it reappears
+	prunedTenants map[string]time.Time
+
+	// limitsNotLoadedAlerted is set while a limits-not-loaded alert is open
+	limitsNotLoadedAlerted bool
+
+	// limitSelection holds the limits enabled through the API, nil when the
+	// configured limit selection applies
+	limitSelection *limitSelection
+
+	// alertManager delivers the alerts, nil while alerting is disabled. It is started by
+	// startAlertManager and read through GetAlertManager.
+	alertMu      sync.RWMutex
+	alertManager *alerting.Manager
+
+	// freeze is the active emergency freeze, nil when limit changes are allowed
+	freezeMu sync.RWMutex
+	freeze   *EmergencyFreeze
+
+	// syntheticSpikes holds the active synthetic spike of each tenant (*SyntheticSpike)
+	syntheticSpikes sync.Map
+
+	// prewarmed holds the ingestion limits of each tenant from before a predictive spike
+	// raised them (map[string]interface{})
+	prewarmed sync.Map
+
+	// health records the outcome of each subsystem's last operation
+	health *HealthRegistry
+
+	// writeBudgets tracks the failed ConfigMap writes of each tenant (*tenantWriteBudget)
+	writeBudgets sync.Map
+
+	// emergencyLimits holds the limits written by the emergency actions until recovery
+	emergencyLimits *emergencyLimits
+
+	// reports holds the recommendations of the latest reconciliations, oldest first
+	reportsMu sync.RWMutex
+	reports   []*RecommendationReport
+	// historyMarks holds the last recommendation recorded in the history per tenant
+	// and limit (guarded by reportsMu)
+	historyMarks map[string]historyMark
+
+	// managedLimits holds the limits the last reconciliation applied, nil until one has
+	managedMu     sync.RWMutex
+	managedLimits map[string]*analyzer.TenantLimits
+
+	// anomalies holds the tenants the last reconciliation flagged as deviating from
+	// their tier peers
+	anomalyMu sync.RWMutex
+	anomalies map[string]TenantAnomaly
+
+	// metricLabels holds the values of metrics.extraLabels of each tenant, set by the
+	// last reconciliation
+	metricLabelsMu sync.RWMutex
+	metricLabels   map[string]map[string]string
+
+	// results holds the results of the latest reconciliations, oldest first
+	resultsMu sync.RWMutex
+	results   []*ReconcileResult
+	// activeReconcile is the ID of the running reconciliation, stamped on the audit
+	// entries written meanwhile; 0 between reconciliations
+	activeReconcile atomic.Int64
+
+	// configMu is held for reading by a reconciliation and for writing while a
+	// reloaded configuration is swapped in, so a reconciliation sees a single config
+	configMu sync.RWMutex
+}
+
+// config returns the configuration in effect. A reconciliation holds configMu, so all
+// its reads see the same configuration.
+func (r *MimirLimitController) config() *config.Config {
+	return r.Config.Load()
 }
 
 // TenantFilter handles tenant filtering logic
 type TenantFilter struct {
-	config *config.Config
-	log    logr.Logger
+	live *config.Live
+	log  logr.Logger
+
+	mu sync.RWMutex
+	// configured holds the lists from the configuration file, restored when the
+	// runtime scoping ConfigMap is deleted
+	configured config.TenantScopingConfig
+	// source is "config" or "configmap", depending on where the active lists came from
+	source  string
+	regexes map[string]*regexp.Regexp
+	// err reports the invalid patterns of the active lists
+	err error
 }
 
 // NewTenantFilter creates a new tenant filter
-func NewTenantFilter(cfg *config.Config, log logr.Logger) *TenantFilter {
-	return &TenantFilter{
-		config: cfg,
-		log:    log,
+func NewTenantFilter(live *config.Live, log logr.Logger) *TenantFilter {
+	cfg := live.Load()
+	tf := &TenantFilter{
+		live: live,
+		log:  log,
+		configured: config.TenantScopingConfig{
+			SkipList:      append([]string(nil), cfg.TenantScoping.SkipList...),
+			IncludeList:   append([]string(nil), cfg.TenantScoping.IncludeList...),
+			PausedTenants: append([]string(nil), cfg.TenantScoping.PausedTenants...),
+		},
+		source: scopingSourceConfig,
 	}
+	tf.compilePatterns()
+	return tf
 }
 
-// ShouldProcessTenant determines if a tenant should be processed
+// config returns the configuration in effect
+func (tf *TenantFilter) config() *config.Config {
+	return tf.live.Load()
+}
+
+// ShouldProcessTenant determines if a tenant should be processed: it must match the
+// include list, when one is set, and then no pattern of the skip list
 func (tf *TenantFilter) ShouldProcessTenant(tenant string) bool {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+
+	// Check include list (if specified, only include matching tenants)
+	if len(tf.config().TenantScoping.IncludeList) > 0 {
+		included := false
+		for _, pattern := range tf.config().TenantScoping.IncludeList {
+			if tf.matchPattern(tenant, pattern) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			tf.log.V(1).Info("skipping tenant not in include list", "tenant", tenant)
+			return false
+		}
+	}
+
 	// Check skip list
-	for _, pattern := range tf.config.TenantScoping.SkipList {
+	for _, pattern := range tf.config().TenantScoping.SkipList {
 		if tf.matchPattern(tenant, pattern) {
 			tf.log.V(1).Info("skipping tenant due to skip list", "tenant", tenant, "pattern", pattern)
 			return false
 		}
 	}
 
-	// Check include list (if specified, only include matching tenants)
-	if len(tf.config.TenantScoping.IncludeList) > 0 {
-		for _, pattern := range tf.config.TenantScoping.IncludeList {
-			if tf.matchPattern(tenant, pattern) {
-				return true
-			}
+	return true
+}
+
+// IsPaused reports whether a tenant matches the paused list, so its limits must be left
+// as they are although it stays monitored
+func (tf *TenantFilter) IsPaused(tenant string) bool {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+
+	for _, pattern := range tf.config().TenantScoping.PausedTenants {
+		if tf.matchPattern(tenant, pattern) {
+			return true
 		}
-		tf.log.V(1).Info("skipping tenant not in include list", "tenant", tenant)
-		return false
 	}
-
-	return true
+	return false
 }
 
-// matchPattern performs pattern matching (glob or regex)
+// matchPattern performs pattern matching (glob or regex); callers must hold tf.mu
 func (tf *TenantFilter) matchPattern(tenant, pattern string) bool {
-	// Simple glob matching for now
-	if pattern == "*" {
-		return true
+	if tf.config().TenantScoping.UseRegex {
+		re, ok := tf.regexes[pattern]
+		return ok && re.MatchString(tenant)
 	}
 
-	// Prefix matching
-	if len(pattern) > 0 && pattern[len(pattern)-1] == '*' {
-		prefix := pattern[:len(pattern)-1]
-		return len(tenant) >= len(prefix) && tenant[:len(prefix)] == prefix
+	matched, err := path.Match(pattern, tenant)
+	return err == nil && matched
+}
+
+// compilePatterns compiles the regex patterns of the lists once and checks the glob
+// patterns; callers must hold tf.mu or own tf exclusively. Invalid patterns never match
+// and are reported by Err.
+func (tf *TenantFilter) compilePatterns() {
+	tf.regexes = make(map[string]*regexp.Regexp)
+	tf.err = nil
+
+	var invalid []error
+	patterns := append(append([]string(nil), tf.config().TenantScoping.SkipList...), tf.config().TenantScoping.IncludeList...)
+	patterns = append(patterns, tf.config().TenantScoping.PausedTenants...)
+	for _, pattern := range patterns {
+		if err := config.ValidateTenantPattern(pattern, tf.config().TenantScoping.UseRegex); err != nil {
+			tf.log.Error(err, "invalid tenant pattern", "pattern", pattern)
+			invalid = append(invalid, &InvalidPatternError{Pattern: pattern, Reason: err.Error()})
+			continue
+		}
+		if tf.config().TenantScoping.UseRegex {
+			tf.regexes[pattern], _ = config.CompileTenantRegex(pattern)
+		}
 	}
+	tf.err = errors.Join(invalid...)
+}
+
+// Err reports the invalid patterns of the active lists, nil when every pattern is valid.
+// Tenants must not be filtered while it is set, or a typo in the include list would
+// silently skip every tenant.
+func (tf *TenantFilter) Err() error {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+	return tf.err
+}
 
-	// Suffix matching
-	if len(pattern) > 0 && pattern[0] == '*' {
-		suffix := pattern[1:]
-		return len(tenant) >= len(suffix) && tenant[len(tenant)-len(suffix):] == suffix
+// ValidatePattern checks that a pattern is a valid glob, or a valid regex when
+// tenantScoping.useRegex is set
+func (tf *TenantFilter) ValidatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return &InvalidPatternError{Pattern: pattern, Reason: "pattern must not be empty"}
 	}
 
-	// Exact matching
-	return tenant == pattern
+	if err := config.ValidateTenantPattern(pattern, tf.config().TenantScoping.UseRegex); err != nil {
+		return &InvalidPatternError{Pattern: pattern, Reason: err.Error()}
+	}
+	return nil
+}
+
+// Lists returns copies of the active skip and include lists
+func (tf *TenantFilter) Lists() (skipList, includeList []string) {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+
+	return append([]string{}, tf.config().TenantScoping.SkipList...),
+		append([]string{}, tf.config().TenantScoping.IncludeList...)
+}
+
+// PausedTenants returns a copy of the active paused list
+func (tf *TenantFilter) PausedTenants() []string {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+	return append([]string{}, tf.config().TenantScoping.PausedTenants...)
+}
+
+// Source reports whether the active lists come from the configuration file ("config")
+// or the runtime scoping ConfigMap ("configmap")
+func (tf *TenantFilter) Source() string {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+	return tf.source
+}
+
+// SetLists replaces the active skip, include and paused lists, publishing them with
+// the configuration
+func (tf *TenantFilter) SetLists(skipList, includeList, pausedTenants []string, source string) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	tf.live.Update(func(cfg *config.Config) {
+		cfg.TenantScoping.SkipList = append([]string{}, skipList...)
+		cfg.TenantScoping.IncludeList = append([]string{}, includeList...)
+		cfg.TenantScoping.PausedTenants = append([]string{}, pausedTenants...)
+	})
+	tf.source = source
+	tf.compilePatterns()
+}
+
+// ReloadConfig publishes a reloaded configuration and keeps the file's lists to restore.
+// The file's lists become active unless lists set through the scoping ConfigMap are in
+// effect; those are carried over to cfg.
+func (tf *TenantFilter) ReloadConfig(cfg *config.Config) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	tf.configured = config.TenantScopingConfig{
+		SkipList:      append([]string(nil), cfg.TenantScoping.SkipList...),
+		IncludeList:   append([]string(nil), cfg.TenantScoping.IncludeList...),
+		PausedTenants: append([]string(nil), cfg.TenantScoping.PausedTenants...),
+	}
+	if tf.source == scopingSourceConfigMap {
+		active := tf.config().TenantScoping
+		cfg.TenantScoping.SkipList = append([]string{}, active.SkipList...)
+		cfg.TenantScoping.IncludeList = append([]string{}, active.IncludeList...)
+		cfg.TenantScoping.PausedTenants = append([]string{}, active.PausedTenants...)
+	}
+	tf.live.Store(cfg)
+	tf.compilePatterns()
+}
+
+// ResetLists restores the lists from the configuration file
+func (tf *TenantFilter) ResetLists() {
+	tf.SetLists(tf.configured.SkipList, tf.configured.IncludeList, tf.configured.PausedTenants, scopingSourceConfig)
+}
+
+// MatchingTenants returns the tenants matched by a pattern
+func (tf *TenantFilter) MatchingTenants(pattern string, tenants []string) []string {
+	tf.mu.RLock()
+	defer tf.mu.RUnlock()
+
+	matched := []string{}
+	for _, tenant := range tenants {
+		if tf.matchPattern(tenant, pattern) {
+			matched = append(matched, tenant)
+		}
+	}
+	return matched
 }
 
 // FilterTenants filters a list of tenants based on configuration
@@ -127,26 +426,122 @@ func (r *MimirLimitController) SetupWithManager(mgr ctrl.Manager) error {
 		return fmt.Errorf("failed to create Kubernetes client: %w", err)
 	}
 	r.KubeClient = kubeClient
+	r.APIReader = mgr.GetAPIReader()
 
 	// Initialize components
-	r.AuditLogger = auditlog.NewAuditLogger(r.Config, r.Client, r.Log.WithName("audit"))
+	components := []string{ComponentCollector, ComponentAnalyzer, ComponentPatcher}
+	if r.config().AuditLog.Enabled {
+		components = append(components, ComponentAuditLogger)
+	}
+	if r.config().Alerting.Enabled {
+		components = append(components, ComponentAlerting)
+	}
+	r.health = NewHealthRegistry(components...)
+	r.AuditLogger = &reconcileAuditLogger{
+		AuditLogger: &healthAuditLogger{
+			AuditLogger: auditlog.NewAuditLogger(r.Config, r.Client, r.Log.WithName("audit")),
+			health:      r.health,
+		},
+		reconcileID: &r.activeReconcile,
+	}
+	r.RecommendationHistory = history.NewStore(r.config(), r.Client, r.Log.WithName("recommendation-history"))
 	r.Collector = collector.NewCollector(r.Config, kubeClient, r.Log.WithName("collector"))
+	synthetic, _ := r.Collector.(*collector.SyntheticCollector)
+	if r.config().Performance.Enabled {
+		r.Cache = cache.New(r.config().Performance.Cache, r.Log.WithName("cache"))
+	}
+	if r.Cache != nil {
+		r.Collector = collector.NewCachedCollector(r.Collector, r.Cache, r.config().Performance.Cache.TTL,
+			r.config().UpdateInterval, r.Log.WithName("collector-cache"))
+	}
 	r.Analyzer = analyzer.NewAnalyzer(r.Config, r.Log.WithName("analyzer"))
 	r.Patcher = patcher.NewPatcher(r.Client, kubeClient, r.Config, r.AuditLogger, r.Log.WithName("patcher"))
 	r.tenantFilter = NewTenantFilter(r.Config, r.Log.WithName("filter"))
+	if configMapPatcher, ok := r.Patcher.(*patcher.ConfigMapPatcher); ok {
+		configMapPatcher.SetTenantFilter(r.tenantFilter.ShouldProcessTenant)
+		configMapPatcher.SetPauseFilter(r.tenantFilter.IsPaused)
+	}
 
 	// Initialize enterprise components
 	r.CostController = costcontrol.NewCostController(r.Config, r.Log.WithName("cost"))
+	r.CostController.SetAuditLogger(r.AuditLogger)
 	r.BlastProtector = circuitbreaker.NewBlastProtector(r.Config, r.Log.WithName("protection"))
+	r.BlastProtector.SetAuditLogger(r.AuditLogger)
+	if synthetic != nil {
+		// Synthetic metrics follow a simulated clock, which the analysis and the blast
+		// protection must be timed with
+		if trendAnalyzer, ok := r.Analyzer.(*analyzer.TrendAnalyzer); ok {
+			trendAnalyzer.SetClock(synthetic.Now)
+		}
+		r.BlastProtector.SetClock(synthetic.Now)
+	}
+	r.emergencyLimits = newEmergencyLimits()
+	r.registerEmergencyActions()
+	if r.config().Emergency.Enabled {
+		if r.config().MetricsEndpoint != "" {
+			r.EmergencyMonitor = circuitbreaker.NewEmergencyMonitor(r.Config, r.BlastProtector,
+				r.emergencyTriggerMeasure(kubeClient), r.Log.WithName("emergency"))
+		} else {
+			r.Log.Info("emergency triggers need metricsEndpoint for their PromQL queries, not monitoring them")
+		}
+	}
+	if r.config().Alerting.Enabled {
+		r.startAlertManager()
+	}
+	if r.config().Mimir.SecondaryCluster.Enabled {
+		r.DriftDetector, err = drift.NewDetector(r.Config, kubeClient, r.Log.WithName("drift"))
+		if err != nil {
+			// Drift detection is advisory; don't block the controller from starting
+			r.Log.Error(err, "failed to initialize secondary cluster drift detection")
+		}
+	}
+
+	if r.config().Mimir.OverridesExporter.Enabled {
+		r.AppliedLimits = appliedlimits.NewReader(r.Config, kubeClient, r.Log.WithName("applied-limits"))
+	}
+
+	if r.config().Limits.RemoteOverrideSource.Enabled {
+		r.RemoteOverrides = remoteoverrides.NewSource(r.config().Limits.RemoteOverrideSource, r.Log.WithName("remote-overrides"))
+	}
+
+	if r.config().Alerting.Email.Enabled && r.config().Alerting.Email.Digest.Enabled {
+		r.Digest = digest.NewScheduler(r.Config, r.AuditLogger, r.Log.WithName("digest"))
+	}
+	if r.config().HealthScanner.WatchCache {
+		r.HealthCache, err = discovery.NewHealthCache(config, mgr.GetScheme(), r.config().Mimir.Namespace, r.Log.WithName("health-cache"))
+		if err != nil {
+			return err
+		}
+		if err := mgr.Add(r.HealthCache); err != nil {
+			return fmt.Errorf("failed to add health cache: %w", err)
+		}
+	}
+
+	if !r.LeaderElection {
+		r.WriteLock = locking.NewLeaseLock(kubeClient, lockNamespace(r.config()), writeLockName, lockIdentity(),
+			writeLockTTL, writeLockRetryInterval, r.Log.WithName("lock"))
+	}
 
 	// Set up periodic reconciliation instead of watching resources
 	return mgr.Add(&PeriodicReconciler{
 		Controller: r,
-		Interval:   r.Config.UpdateInterval,
+		Interval:   r.config().UpdateInterval,
 		Log:        r.Log,
 	})
 }
 
+// pendingRollouts returns the components whose rollout the patcher deferred
+func (r *MimirLimitController) pendingRollouts() []string {
+	if configMapPatcher, ok := r.Patcher.(*patcher.ConfigMapPatcher); ok {
+		return configMapPatcher.PendingRollouts()
+	}
+	return nil
+}
+
+// transientRequeueDelay is how soon a reconcile with transient tenant errors is retried,
+// at most the reconcile interval
+const transientRequeueDelay = 30 * time.Second
+
 // PeriodicReconciler runs the reconciliation loop periodically
 type PeriodicReconciler struct {
 	Controller *MimirLimitController
@@ -165,6 +560,39 @@ func (pr *PeriodicReconciler) Start(ctx context.Context) error {
 		ticker := time.NewTicker(pr.Interval)
 		defer ticker.Stop()
 
+		// requeue fires once after a reconcile in which tenants failed transiently
+		var requeue <-chan time.Time
+		reconcile := func() {
+			requeue = nil
+			_, err := pr.Controller.reconcile(ctx)
+			if pending := pr.Controller.pendingRollouts(); len(pending) > 0 {
+				delay := patcher.RolloutRetryDelay
+				if delay > pr.Interval {
+					delay = pr.Interval
+				}
+				pr.Log.Info("requeueing reconciliation to retry deferred rollouts",
+					"components", pending,
+					"after", delay)
+				requeue = time.After(delay)
+			}
+			if err == nil {
+				return
+			}
+			pr.Log.Error(err, "reconciliation failed")
+
+			var tenantErrs *MultiError
+			if errors.As(err, &tenantErrs) && tenantErrs.Transient() != nil {
+				delay := transientRequeueDelay
+				if delay > pr.Interval {
+					delay = pr.Interval
+				}
+				pr.Log.Info("requeueing reconciliation after transient tenant errors",
+					"tenants_failed", tenantErrs.Len(),
+					"after", delay)
+				requeue = time.After(delay)
+			}
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -174,15 +602,45 @@ func (pr *PeriodicReconciler) Start(ctx context.Context) error {
 				pr.Log.Info("stopping periodic reconciler due to stop signal")
 				return
 			case <-ticker.C:
-				if err := pr.Controller.reconcile(ctx); err != nil {
-					pr.Log.Error(err, "reconciliation failed")
-				}
+				reconcile()
+			case <-requeue:
+				reconcile()
 			}
 		}
 	}()
 
+	// Load tenant scoping changed at runtime and follow changes made by other replicas
+	pr.Controller.startTenantScopingWatch(ctx)
+
+	// Restore an emergency freeze set before a restart or through another replica
+	pr.Controller.startEmergencyFreezeWatch(ctx)
+
+	// Keep holding the emergency limits written before a restart until recovery
+	pr.Controller.loadEmergencyLimits(ctx)
+
+	// Reload the blast detector baselines from before a restart and checkpoint them
+	pr.Controller.startBlastBaselineCheckpoints(ctx)
+
+	// Raise ingestion limits ahead of predicted spikes between reconciliations
+	pr.Controller.startPredictiveSpikeChecks(ctx)
+
+	// Provision the Grafana dashboard of the optimizer's metrics
+	if err := pr.Controller.provisionGrafanaDashboard(ctx); err != nil {
+		pr.Log.Error(err, "failed to provision Grafana dashboard")
+	}
+
+	// Evaluate the panic mode thresholds and emergency shutdown triggers
+	if pr.Controller.EmergencyMonitor != nil {
+		pr.Controller.EmergencyMonitor.Start(ctx)
+	}
+
+	// Start the daily limit-change digest if configured
+	if pr.Controller.Digest != nil {
+		pr.Controller.Digest.Start(ctx)
+	}
+
 	// Start audit log cleanup goroutine if audit logging is enabled
-	if pr.Controller.Config.AuditLog.Enabled {
+	if pr.Controller.config().AuditLog.Enabled {
 		pr.startAuditCleanup(ctx)
 	}
 
@@ -191,14 +649,14 @@ func (pr *PeriodicReconciler) Start(ctx context.Context) error {
 
 // startAuditCleanup starts a background goroutine for audit log cleanup
 func (pr *PeriodicReconciler) startAuditCleanup(ctx context.Context) {
-	cleanupInterval := pr.Controller.Config.AuditLog.Retention.CleanupInterval
+	cleanupInterval := pr.Controller.config().AuditLog.Retention.CleanupInterval
 	if cleanupInterval <= 0 {
 		cleanupInterval = 1 * time.Hour // Default: 1 hour
 	}
 
 	pr.Log.Info("starting audit log cleanup goroutine",
 		"interval", cleanupInterval,
-		"storage_type", pr.Controller.Config.AuditLog.StorageType)
+		"storage_type", pr.Controller.config().AuditLog.StorageType)
 
 	go func() {
 		ticker := time.NewTicker(cleanupInterval)
@@ -221,7 +679,7 @@ func (pr *PeriodicReconciler) startAuditCleanup(ctx context.Context) {
 
 // runAuditCleanup performs scheduled audit log cleanup
 func (pr *PeriodicReconciler) runAuditCleanup(ctx context.Context) {
-	retentionPeriod := pr.Controller.Config.AuditLog.Retention.RetentionPeriod
+	retentionPeriod := pr.Controller.config().AuditLog.Retention.RetentionPeriod
 	if retentionPeriod <= 0 {
 		retentionPeriod = 7 * 24 * time.Hour // Default: 7 days
 	}
@@ -231,12 +689,12 @@ func (pr *PeriodicReconciler) runAuditCleanup(ctx context.Context) {
 	pr.Log.V(1).Info("running scheduled audit log cleanup",
 		"retention_period", retentionPeriod,
 		"cutoff_time", cutoff,
-		"storage_type", pr.Controller.Config.AuditLog.StorageType)
+		"storage_type", pr.Controller.config().AuditLog.StorageType)
 
 	if err := pr.Controller.AuditLogger.PurgeOldEntries(ctx, cutoff); err != nil {
 		pr.Log.Error(err, "scheduled audit log cleanup failed",
 			"retention_period", retentionPeriod,
-			"storage_type", pr.Controller.Config.AuditLog.StorageType)
+			"storage_type", pr.Controller.config().AuditLog.StorageType)
 	} else {
 		pr.Log.V(1).Info("scheduled audit log cleanup completed",
 			"retention_period", retentionPeriod)
@@ -252,40 +710,67 @@ func (pr *PeriodicReconciler) Stop() {
 }
 
 // reconcile performs the main reconciliation logic
-func (r *MimirLimitController) reconcile(ctx context.Context) error {
-	startTime := time.Now()
-	r.reconcileCount++
+func (r *MimirLimitController) reconcile(ctx context.Context) (int64, error) {
+	r.refreshLimitSelection(ctx)
+
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+
+	tracker := r.beginReconcileResult(time.Now())
+	err := r.runReconcile(ctx, tracker)
+	r.finishReconcileResult(tracker, err, time.Now())
+	return tracker.result.ReconcileID, err
+}
+
+// runReconcile runs one reconciliation, recording the outcome of each tenant
+func (r *MimirLimitController) runReconcile(ctx context.Context, tracker *reconcileTracker) error {
+	startTime := tracker.result.StartTime
+	reconcileID := tracker.result.ReconcileID
 
 	defer func() {
-		duration := time.Since(startTime).Seconds()
-		metrics.ReconcileMetricsInstance.ObserveReconcileDuration("success", duration)
 		metrics.ReconcileMetricsInstance.SetLastReconcileTime(float64(time.Now().Unix()))
 		r.lastReconcile = time.Now()
 	}()
 
-	r.Log.Info("starting reconciliation", "count", r.reconcileCount)
+	r.Log.Info("starting reconciliation", "count", reconcileID)
 
 	// Update health status
 	metrics.HealthMetricsInstance.SetHealthStatus("controller", 1)
 
 	// Step 1: Collect metrics from all sources
 	tenantMetrics, err := r.Collector.CollectMetrics(ctx)
-	if err != nil {
-		metrics.HealthMetricsInstance.SetHealthStatus("collector", 0)
+	if collector.IsPartialFailure(err) {
+		// The tenants of the sources that were collected are still reconciled
+		r.Log.Error(err, "some metrics sources failed, reconciling the collected tenants")
+		metrics.HealthMetricsInstance.IncErrorTotal("collector", "partial-collection")
+		r.health.RecordFailure(ComponentCollector, err)
+	} else if err != nil {
+		r.health.RecordFailure(ComponentCollector, err)
 		metrics.HealthMetricsInstance.IncErrorTotal("collector", "metrics-collection")
 		return fmt.Errorf("failed to collect metrics: %w", err)
+	} else {
+		r.health.RecordSuccess(ComponentCollector)
 	}
-	metrics.HealthMetricsInstance.SetHealthStatus("collector", 1)
 
 	r.Log.Info("collected metrics", "tenants", len(tenantMetrics))
 
+	// Step 1.5: Multiply the metrics of tenants under an injected synthetic spike
+	tenantMetrics = r.applySyntheticSpikes(tenantMetrics)
+
 	// Step 2: Filter tenants based on configuration
 	allTenants := make([]string, 0, len(tenantMetrics))
 	for tenant := range tenantMetrics {
 		allTenants = append(allTenants, tenant)
 	}
 
+	if err := r.tenantFilter.Err(); err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("controller", "tenant-scoping")
+		return fmt.Errorf("tenant scoping lists contain invalid patterns, not reconciling: %w", err)
+	}
 	monitoredTenants, skippedTenants := r.tenantFilter.FilterTenants(allTenants)
+	for _, tenant := range skippedTenants {
+		tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonTenantScoping, nil)
+	}
 
 	// Update metrics
 	metrics.TenantMetricsInstance.SetTenantsMonitored(float64(len(monitoredTenants)))
@@ -295,6 +780,8 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 		"monitored", len(monitoredTenants),
 		"skipped", len(skippedTenants))
 
+	r.updateTenantMetricLabels(ctx, monitoredTenants)
+
 	// Filter tenant metrics to only include monitored tenants
 	filteredMetrics := make(map[string]*collector.TenantMetrics)
 	for _, tenant := range monitoredTenants {
@@ -309,10 +796,15 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 		r.Log.Error(err, "failed to apply blast protection")
 		protectedMetrics = filteredMetrics // Continue with original metrics
 	}
+	for tenant := range filteredMetrics {
+		if _, exists := protectedMetrics[tenant]; !exists {
+			tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonCircuitBreaker, nil)
+		}
+	}
 
 	// Step 3: Calculate costs (enterprise feature)
 	var tenantCosts map[string]*costcontrol.TenantCostData
-	if r.Config.CostControl.Enabled {
+	if r.config().CostControl.Enabled {
 		tenantCosts, err = r.CostController.CalculateCosts(ctx, protectedMetrics)
 		if err != nil {
 			r.Log.Error(err, "failed to calculate costs")
@@ -322,57 +814,134 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 	}
 
 	// Step 4: Detect spikes (if enabled)
-	if r.Config.EventSpike.Enabled {
+	if r.config().EventSpike.Enabled {
 		spikes, err := r.Analyzer.DetectSpikes(ctx, protectedMetrics)
 		if err != nil {
 			r.Log.Error(err, "failed to detect spikes")
 			metrics.HealthMetricsInstance.IncErrorTotal("analyzer", "spike-detection")
 		} else {
-			r.handleSpikes(ctx, spikes)
+			r.handleSpikes(ctx, spikes, protectedMetrics)
 		}
 	}
 
-	// Step 5: Analyze trends and calculate recommended limits
-	analysisResults, err := r.Analyzer.AnalyzeTrends(ctx, protectedMetrics)
-	if err != nil {
-		metrics.HealthMetricsInstance.SetHealthStatus("analyzer", 0)
-		metrics.HealthMetricsInstance.IncErrorTotal("analyzer", "trend-analysis")
-		return fmt.Errorf("failed to analyze trends: %w", err)
+	// Step 4.5: Flag tenants deviating from the other tenants of their tier (if enabled)
+	r.detectAnomalies(filteredMetrics)
+
+	// Step 5-6: Analyze trends and calculate optimized limits tenant by tenant, so one
+	// failing tenant does not hold back the others
+	analysisResults, optimizedLimits, tenantErrs := r.analyzeTenants(ctx, protectedMetrics)
+	r.health.Record(ComponentAnalyzer, tenantErrs.ErrorOrNil())
+	for _, tenantErr := range tenantErrs.Errors {
+		tracker.set(tenantErr.Tenant, TenantOutcomeFailed, ReconcileReasonCalculationError, tenantErr.Err)
+	}
+	if tenantErrs.Len() > 0 && tenantErrs.Len() == len(protectedMetrics) {
+		return fmt.Errorf("failed to calculate limits for every tenant: %w", tenantErrs)
 	}
-	metrics.HealthMetricsInstance.SetHealthStatus("analyzer", 1)
 
-	r.Log.Info("analyzed trends", "tenants", len(analysisResults))
+	r.Log.Info("analyzed trends", "tenants", len(analysisResults), "tenants_failed", tenantErrs.Len())
 
-	// Step 6: Calculate optimized limits
-	optimizedLimits, err := r.Analyzer.CalculateLimits(ctx, analysisResults)
-	if err != nil {
-		metrics.HealthMetricsInstance.IncErrorTotal("analyzer", "limit-calculation")
-		return fmt.Errorf("failed to calculate limits: %w", err)
+	r.Log.Info("calculated optimized limits", "tenants", len(optimizedLimits))
+
+	// Step 6.5: Reapply the default limits of tenants returning after being pruned
+	returningTenants := r.reapplyDefaultLimits(optimizedLimits)
+	for _, tenant := range returningTenants {
+		tracker.addReason(tenant, ReconcileReasonDefaultLimits)
+		r.Log.Info("tenant reappeared after inactive tenant cleanup, reapplying default limits",
+			"tenant", tenant)
 	}
 
-	r.Log.Info("calculated optimized limits", "tenants", len(optimizedLimits))
+	// Step 6.6: Raise limits to the baselines annotated on tenant namespaces (if enabled)
+	if r.config().MetricsDiscovery.TenantDiscovery.NamespaceAnnotations && r.KubeClient != nil {
+		var raisedTenants []string
+		optimizedLimits, raisedTenants = r.applyNamespaceAnnotationLimits(ctx, optimizedLimits)
+		for _, tenant := range raisedTenants {
+			tracker.addReason(tenant, ReconcileReasonNamespaceLimits)
+		}
+	}
 
 	// Step 7: Apply cost control and budget enforcement
 	finalLimits := optimizedLimits
-	if r.Config.CostControl.Enabled && tenantCosts != nil {
+	if r.config().CostControl.Enabled && tenantCosts != nil {
 		finalLimits, err = r.CostController.EnforceBudgets(ctx, tenantCosts, optimizedLimits)
 		if err != nil {
 			r.Log.Error(err, "failed to enforce budgets")
 			finalLimits = optimizedLimits // Continue with original limits
 		} else {
 			r.Log.Info("applied cost control", "tenants", len(finalLimits))
+			tracker.clamped(optimizedLimits, finalLimits)
 		}
 	}
 
+	// Step 7.5: Replace calculated limits with remotely managed values
+	if r.RemoteOverrides != nil {
+		finalLimits = r.applyRemoteOverrides(ctx, finalLimits)
+	}
+
 	// Step 8: Apply blast protection to final limits
 	protectedLimits, err := r.BlastProtector.ApplyProtection(ctx, finalLimits)
 	if err != nil {
 		r.Log.Error(err, "failed to apply blast protection to limits")
 		protectedLimits = finalLimits // Continue with unprotected limits
 	}
+	if r.emergencyLimits != nil {
+		// Keep the limits written by emergency actions until recovery restores them
+		r.restoreDeferredEmergencyLimits(ctx)
+		protectedLimits = r.emergencyLimits.apply(protectedLimits)
+	}
+	tracker.clamped(finalLimits, protectedLimits)
+	for tenant := range finalLimits {
+		if _, exists := protectedLimits[tenant]; !exists {
+			tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonCircuitBreaker, nil)
+		}
+	}
+
+	// Snapshot applied limits so changes can be reported after the update
+	previousLimits, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		r.Log.V(1).Info("failed to read current limits for change reports", "error", err)
+	} else {
+		// Auto-calculated blast thresholds derive from the applied limits
+		r.BlastProtector.UpdateCurrentLimits(previousLimits)
+	}
+
+	// Keep the recommendations for the recommendations report and history
+	r.recordRecommendations(ctx, reconcileID, previousLimits, protectedLimits, analysisResults)
+	r.pruneRecommendationHistory(ctx)
+
+	// Step 8.4: Leave the limits of paused tenants as they are
+	for tenant := range protectedLimits {
+		if r.tenantFilter.IsPaused(tenant) {
+			delete(protectedLimits, tenant)
+			tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonPaused, nil)
+		}
+	}
+
+	// Step 8.5: Halt all ConfigMap writes while an emergency freeze is active
+	if freeze := r.refreshEmergencyFreeze(ctx); freeze != nil {
+		r.Log.Info("WARNING: emergency freeze active, skipping all ConfigMap writes",
+			"reason", freeze.Reason,
+			"activated_by", freeze.ActivatedBy,
+			"expires_at", freeze.ExpiresAt,
+			"tenants_not_updated", len(protectedLimits))
+		tracker.setAll(protectedLimits, TenantOutcomeSkipped, ReconcileReasonEmergencyFreeze)
+		return nil
+	}
 
 	// Step 9: Apply limits to ConfigMap (both dry-run and production modes)
-	if r.Config.Mode == "dry-run" {
+	if r.WriteLock != nil {
+		acquired := r.acquireWriteLock(ctx)
+		if !acquired {
+			// Another replica is writing; retry on the next interval
+			r.Log.Info("skipping ConfigMap write, write lock held by another replica",
+				"lease", writeLockName,
+				"next_attempt", r.config().UpdateInterval)
+			tracker.setAll(protectedLimits, TenantOutcomeSkipped, ReconcileReasonWriteLockHeld)
+			return nil
+		}
+		defer r.releaseWriteLock()
+	}
+
+	if r.config().Mode == "dry-run" {
 		r.Log.Info("DRY-RUN mode: writing optimized values to ConfigMap for verification")
 
 		// First get preview for logging purposes
@@ -385,16 +954,19 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 		}
 
 		// Apply the actual values to ConfigMap for user verification
-		if err := r.Patcher.ApplyLimits(ctx, protectedLimits); err != nil {
-			metrics.HealthMetricsInstance.SetHealthStatus("patcher", 0)
+		appliedLimits, err := r.applyLimits(ctx, protectedLimits)
+		tracker.applied(previousLimits, protectedLimits, appliedLimits, err)
+		if err != nil {
+			r.health.RecordFailure(ComponentPatcher, err)
 			metrics.HealthMetricsInstance.IncErrorTotal("patcher", "apply-limits")
 			return fmt.Errorf("failed to write optimized limits to ConfigMap for verification: %w", err)
 		}
-		metrics.HealthMetricsInstance.SetHealthStatus("patcher", 1)
+		r.health.RecordSuccess(ComponentPatcher)
+		protectedLimits = appliedLimits
 
 		r.Log.Info("DRY-RUN: Optimized limits written to ConfigMap for verification",
-			"configmap", r.Config.Mimir.ConfigMapName,
-			"namespace", r.Config.Mimir.Namespace,
+			"configmap", r.config().Mimir.ConfigMapName,
+			"namespace", r.config().Mimir.Namespace,
 			"tenants_updated", len(protectedLimits),
 			"note", "These values are for verification only - Mimir is not using them yet")
 
@@ -403,26 +975,53 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 		// Production mode: apply limits for actual Mimir consumption
 		r.Log.Info("PRODUCTION mode: applying optimized limits for Mimir consumption")
 
-		if err := r.Patcher.ApplyLimits(ctx, protectedLimits); err != nil {
-			metrics.HealthMetricsInstance.SetHealthStatus("patcher", 0)
+		appliedLimits, err := r.applyLimits(ctx, protectedLimits)
+		tracker.applied(previousLimits, protectedLimits, appliedLimits, err)
+		if err != nil {
+			r.health.RecordFailure(ComponentPatcher, err)
 			metrics.HealthMetricsInstance.IncErrorTotal("patcher", "apply-limits")
 			return fmt.Errorf("failed to apply limits for production use: %w", err)
 		}
-		metrics.HealthMetricsInstance.SetHealthStatus("patcher", 1)
+		r.health.RecordSuccess(ComponentPatcher)
+		protectedLimits = appliedLimits
 
 		r.Log.Info("PRODUCTION: Optimized limits applied and active",
-			"configmap", r.Config.Mimir.ConfigMapName,
-			"namespace", r.Config.Mimir.Namespace,
+			"configmap", r.config().Mimir.ConfigMapName,
+			"namespace", r.config().Mimir.Namespace,
 			"tenants_updated", len(protectedLimits),
 			"note", "Mimir will use these limits at runtime")
 	}
 
+	r.recordManagedLimits(protectedLimits)
+	r.notifyLimitChanges(previousLimits, protectedLimits, tenantMetrics)
+
+	r.inactiveMu.Lock()
+	for _, tenant := range returningTenants {
+		delete(r.prunedTenants, tenant)
+	}
+	r.inactiveMu.Unlock()
+
 	// Step 10: Update current limits metrics
 	r.updateCurrentLimitsMetrics(ctx, protectedLimits)
 
+	// Step 10.5: Compare overrides against the secondary cluster (if configured)
+	if r.DriftDetector != nil {
+		r.checkDrift(ctx)
+	}
+
+	// Step 10.55: Compare the ConfigMap with the limits Mimir has loaded (if configured)
+	if r.AppliedLimits != nil {
+		r.checkLoadedLimits(ctx)
+	}
+
+	// Step 10.6: Remove limits of tenants inactive for longer than the TTL
+	if r.config().Limits.InactiveTenantTTL > 0 {
+		r.cleanupInactiveTenants(ctx, tenantMetrics)
+	}
+
 	// Step 11: Cleanup old audit entries (if enabled)
-	if r.Config.AuditLog.Enabled {
-		retentionPeriod := r.Config.AuditLog.Retention.RetentionPeriod
+	if r.config().AuditLog.Enabled {
+		retentionPeriod := r.config().AuditLog.Retention.RetentionPeriod
 		if retentionPeriod <= 0 {
 			retentionPeriod = 7 * 24 * time.Hour // Default fallback
 		}
@@ -431,41 +1030,440 @@ func (r *MimirLimitController) reconcile(ctx context.Context) error {
 		r.Log.V(1).Info("running audit log retention cleanup",
 			"retention_period", retentionPeriod,
 			"cutoff_time", cutoff,
-			"storage_type", r.Config.AuditLog.StorageType)
+			"storage_type", r.config().AuditLog.StorageType)
 
 		if err := r.AuditLogger.PurgeOldEntries(ctx, cutoff); err != nil {
 			r.Log.Error(err, "failed to purge old audit entries",
 				"retention_period", retentionPeriod,
-				"storage_type", r.Config.AuditLog.StorageType)
+				"storage_type", r.config().AuditLog.StorageType)
 		} else {
 			r.Log.V(1).Info("audit log retention cleanup completed",
 				"retention_period", retentionPeriod)
 		}
 	}
 
-	metrics.ReconcileMetricsInstance.IncReconcileTotal("success")
 	r.Log.Info("reconciliation completed successfully with enterprise protection",
 		"duration", time.Since(startTime),
 		"tenants_processed", len(protectedLimits),
-		"cost_control_enabled", r.Config.CostControl.Enabled,
-		"blast_protection_enabled", r.Config.CircuitBreaker.Enabled)
-
+		"tenants_failed", tenantErrs.Len(),
+		"cost_control_enabled", r.config().CostControl.Enabled,
+		"blast_protection_enabled", r.config().CircuitBreaker.Enabled)
+
+	// Permanent tenant errors were logged and the tenants skipped; transient ones are
+	// returned so the reconcile is requeued
+	if transient := tenantErrs.Transient(); transient != nil {
+		return transient
+	}
 	return nil
 }
 
 // handleSpikes processes detected spikes
-func (r *MimirLimitController) handleSpikes(ctx context.Context, spikes map[string]map[string]bool) {
+func (r *MimirLimitController) handleSpikes(ctx context.Context, spikes map[string]map[string]bool, tenantMetrics map[string]*collector.TenantMetrics) {
 	for tenant, tenantSpikes := range spikes {
 		for metricName := range tenantSpikes {
-			r.Log.Info("spike detected", "tenant", tenant, "metric", metricName)
+			var observed, baseline, multiplier float64
+			if tm, exists := tenantMetrics[tenant]; exists {
+				if data := tm.Metrics[metricName]; len(data) > 0 {
+					observed = data[len(data)-1].Value
+				}
+			}
+			if info := r.Analyzer.GetSpikeInfo(tenant, metricName); info != nil {
+				baseline = info.BaseValue
+				multiplier = info.Multiplier
+			}
+
+			r.Log.Info("spike detected", "tenant", tenant, "metric", metricName,
+				"observed", observed, "baseline", baseline, "multiplier", multiplier)
 
 			// Log spike detection to audit trail
-			entry := auditlog.NewSpikeDetectionEntry(tenant, metricName, 0, 0) // Values would be filled by analyzer
+			entry := auditlog.NewSpikeDetectionEntry(tenant, metricName, baseline, observed, false)
 			if err := r.AuditLogger.LogEntry(entry); err != nil {
 				r.Log.Error(err, "failed to log spike detection", "tenant", tenant)
 			}
+
+			if manager := r.GetAlertManager(); manager != nil {
+				manager.SendAlert(alerting.CreateSpikeAlert(tenant, metricName, observed, baseline, multiplier))
+			}
+		}
+	}
+}
+
+// notifyLimitChanges sends a limit change alert for every tenant whose applied limits differ from before
+func (r *MimirLimitController) notifyLimitChanges(previous, applied map[string]*analyzer.TenantLimits, tenantMetrics map[string]*collector.TenantMetrics) {
+	manager := r.GetAlertManager()
+	if manager == nil {
+		return
+	}
+
+	for tenant, tenantLimits := range applied {
+		var before map[string]interface{}
+		if prev, exists := previous[tenant]; exists {
+			before = prev.Limits
+		}
+
+		var changes []alerting.LimitChange
+		for limitName, value := range tenantLimits.Limits {
+			old, existed := before[limitName]
+			if existed && config.LimitValuesEqual(old, value) {
+				continue
+			}
+			changes = append(changes, r.describeLimitChange(tenant, limitName, old, value, tenantMetrics))
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Limit < changes[j].Limit })
+		manager.SendAlert(alerting.CreateLimitChangeAlert(tenant, changes, tenantLimits.Reason))
+	}
+}
+
+// limitChangeUsageSamples is the number of recent usage samples shown with a limit change
+const limitChangeUsageSamples = 16
+
+// describeLimitChange builds a limit change with the buffer applied to the limit and
+// the recent usage of the metric it is derived from
+func (r *MimirLimitController) describeLimitChange(tenant, limitName string, before, after interface{}, tenantMetrics map[string]*collector.TenantMetrics) alerting.LimitChange {
+	change := alerting.LimitChange{
+		Limit:         limitName,
+		Before:        before,
+		After:         after,
+		BufferPercent: r.config().BufferPercentage,
+	}
+
+	limitDef, exists := r.config().DynamicLimits.LimitDefinitions[limitName]
+	if !exists {
+		return change
+	}
+	if limitDef.BufferFactor > 0 {
+		change.BufferPercent = limitDef.BufferFactor
+	}
+
+	tm, exists := tenantMetrics[tenant]
+	if !exists || limitDef.MetricSource == "" {
+		return change
+	}
+
+	samples := append([]collector.MetricData(nil), tm.Metrics[limitDef.MetricSource]...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp.Before(samples[j].Timestamp) })
+	if len(samples) > limitChangeUsageSamples {
+		samples = samples[len(samples)-limitChangeUsageSamples:]
+	}
+	for _, sample := range samples {
+		change.Usage = append(change.Usage, sample.Value)
+	}
+
+	return change
+}
+
+// checkDrift computes the drift report against the secondary cluster and alerts on limits beyond the threshold
+func (r *MimirLimitController) checkDrift(ctx context.Context) {
+	report, err := r.DriftDetector.Check(ctx)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("drift", "drift-check")
+		r.Log.Error(err, "failed to check limit drift against secondary cluster")
+		return
+	}
+
+	if report.DriftedCount == 0 {
+		return
+	}
+
+	r.Log.Info("limit drift detected against secondary cluster",
+		"secondary", report.SecondaryConfigMap,
+		"drifted", report.DriftedCount,
+		"threshold_percent", report.ThresholdPercent)
+
+	manager := r.GetAlertManager()
+	if manager == nil {
+		return
+	}
+
+	tenants := make(map[string]bool)
+	for _, item := range report.Drifted() {
+		tenants[item.TenantID] = true
+	}
+
+	manager.SendAlert(alerting.CreateLimitDriftAlert(report.SecondaryConfigMap, report.DriftedCount, report.ThresholdPercent,
+		map[string]interface{}{
+			"primary":          report.PrimaryConfigMap,
+			"secondary":        report.SecondaryConfigMap,
+			"drifted_limits":   report.DriftedCount,
+			"affected_tenants": len(tenants),
+			"primary_only":     report.PrimaryOnlyCount,
+			"secondary_only":   report.SecondaryOnlyCount,
+		}))
+}
+
+// cleanupInactiveTenants removes the overrides of tenants that have been missing from the
+// collector's tenant list, or listed without receiving samples, for longer than
+// InactiveTenantTTL. Absence is measured from the first reconcile that noticed it, so a
+// restart restarts the TTL rather than pruning early. Tenants in
+// limits.inactiveTenantAllowlist are never removed.
+func (r *MimirLimitController) cleanupInactiveTenants(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) {
+	activeTenants, err := r.Collector.GetTenantList(ctx)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("collector", "tenant-list")
+		r.Log.Error(err, "failed to list tenants for inactive tenant cleanup")
+		return
+	}
+	if len(activeTenants) == 0 {
+		// An empty list is far more likely a collection problem than every tenant going idle
+		r.Log.V(1).Info("collector reported no tenants, skipping inactive tenant cleanup")
+		return
+	}
+
+	currentLimits, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("patcher", "get-current-limits")
+		r.Log.Error(err, "failed to read current limits for inactive tenant cleanup")
+		return
+	}
+
+	now := time.Now()
+	r.inactiveMu.Lock()
+	if r.tenantLastSeen == nil {
+		r.tenantLastSeen = make(map[string]time.Time)
+	}
+
+	active := make(map[string]bool, len(activeTenants))
+	for _, tenant := range activeTenants {
+		if !receivedSamples(tenantMetrics[tenant]) {
+			continue
+		}
+		active[tenant] = true
+		r.tenantLastSeen[tenant] = now
+	}
+
+	ttl := r.config().Limits.InactiveTenantTTL
+	var inactive []string
+	var remote remoteoverrides.TenantOverrides
+	if r.RemoteOverrides != nil {
+		remote = r.RemoteOverrides.Cached()
+	}
+
+	for tenant := range currentLimits {
+		if active[tenant] || !r.tenantFilter.ShouldProcessTenant(tenant) {
+			continue
+		}
+		if r.config().Limits.InactiveTenantAllowed(tenant) {
+			continue
+		}
+		if _, managed := remote[tenant]; managed {
+			// Remotely managed limits are kept, and would be written back anyway
+			continue
+		}
+		lastSeen, tracked := r.tenantLastSeen[tenant]
+		if !tracked {
+			r.tenantLastSeen[tenant] = now
+			continue
+		}
+		if now.Sub(lastSeen) >= ttl {
+			inactive = append(inactive, tenant)
+		}
+	}
+
+	// Forget tenants that are neither active nor in the overrides anymore
+	for tenant := range r.tenantLastSeen {
+		if _, hasLimits := currentLimits[tenant]; !hasLimits && !active[tenant] {
+			delete(r.tenantLastSeen, tenant)
+		}
+	}
+	r.inactiveMu.Unlock()
+
+	if len(inactive) == 0 {
+		return
+	}
+	sort.Strings(inactive)
+
+	if r.config().Mode == "dry-run" {
+		metrics.TenantMetricsInstance.AddInactiveTenantsCleaned("would_remove", len(inactive))
+		r.Log.Info("DRY-RUN: would remove limits of inactive tenants",
+			"tenants", inactive,
+			"inactive_ttl", ttl)
+		for _, tenant := range inactive {
+			var oldLimits map[string]interface{}
+			if limits := currentLimits[tenant]; limits != nil {
+				oldLimits = limits.Limits
+			}
+			entry := auditlog.NewLimitUpdateEntry(tenant, "DRY-RUN: would remove, inactive-tenant-ttl", oldLimits, nil)
+			entry.Action = "tenant_cleanup"
+			entry.Source = "inactive-tenant-cleanup"
+			entry.Component = "mimir-limit-optimizer"
+			if err := r.AuditLogger.LogEntry(entry); err != nil {
+				r.Log.Error(err, "failed to log dry-run tenant cleanup", "tenant", tenant)
+			}
+		}
+		return
+	}
+
+	removed, err := r.Patcher.RemoveTenants(ctx, inactive, "inactive-tenant-ttl")
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("patcher", "tenant-cleanup")
+		r.Log.Error(err, "failed to remove limits of inactive tenants", "tenants", inactive)
+		return
+	}
+
+	r.inactiveMu.Lock()
+	if r.prunedTenants == nil {
+		r.prunedTenants = make(map[string]time.Time)
+	}
+	for _, tenant := range removed {
+		delete(r.tenantLastSeen, tenant)
+		r.prunedTenants[tenant] = now
+	}
+	r.inactiveMu.Unlock()
+	r.forgetManagedTenants(removed)
+	metrics.TenantMetricsInstance.AddInactiveTenantsCleaned("removed", len(removed))
+	r.Log.Info("removed limits of inactive tenants",
+		"tenants", removed,
+		"inactive_ttl", ttl)
+}
+
+// receivedSamples reports whether a listed tenant received samples in the last
+// collection. Tenants whose ingestion was not collected are taken as active.
+func receivedSamples(tenantMetrics *collector.TenantMetrics) bool {
+	if tenantMetrics == nil {
+		return true
+	}
+	data, collected := tenantMetrics.Metrics["cortex_distributor_received_samples_total"]
+	if !collected {
+		return true
+	}
+	for _, d := range data {
+		if d.Value > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reapplyDefaultLimits sets the enabled DefaultLimits of tenants that reappear after
+// their limits were removed for inactivity, which are calculated from hardly any data
+// on their first reconcile back. It returns the returning tenants, which are forgotten
+// once the limits are written.
+func (r *MimirLimitController) reapplyDefaultLimits(limits map[string]*analyzer.TenantLimits) []string {
+	r.inactiveMu.Lock()
+	pruned := make([]string, 0, len(r.prunedTenants))
+	for tenant := range r.prunedTenants {
+		pruned = append(pruned, tenant)
+	}
+	r.inactiveMu.Unlock()
+
+	var returning []string
+	for _, tenant := range pruned {
+		tenantLimits, exists := limits[tenant]
+		if !exists || tenantLimits == nil {
+			continue
+		}
+		if tenantLimits.Limits == nil {
+			tenantLimits.Limits = make(map[string]interface{})
+		}
+		for limitName, value := range r.config().Limits.DefaultLimits {
+			if limitDef, defined := r.config().DynamicLimits.LimitDefinitions[limitName]; defined && !limitDef.Enabled {
+				continue
+			}
+			tenantLimits.Limits[limitName] = value
+		}
+		tenantLimits.Reason = "default limits reapplied after inactive tenant cleanup"
+		returning = append(returning, tenant)
+	}
+	sort.Strings(returning)
+	return returning
+}
+
+// applyRemoteOverrides replaces calculated limits with the values of the remote
+// override source. Tenants only present in the remote document get its limits as
+// well, so teams can manage limits of tenants without usage yet.
+func (r *MimirLimitController) applyRemoteOverrides(ctx context.Context, limits map[string]*analyzer.TenantLimits) map[string]*analyzer.TenantLimits {
+	remote := r.RemoteOverrides.Overrides(ctx)
+	if len(remote) == 0 {
+		return limits
+	}
+
+	merged := make(map[string]*analyzer.TenantLimits, len(limits)+len(remote))
+	for tenant, tenantLimits := range limits {
+		merged[tenant] = tenantLimits
+	}
+
+	now := time.Now()
+	overridden := 0
+	for tenant, remoteLimits := range remote {
+		if len(remoteLimits) == 0 || !r.tenantFilter.ShouldProcessTenant(tenant) {
+			continue
+		}
+
+		tenantLimits := &analyzer.TenantLimits{
+			Tenant:      tenant,
+			Limits:      make(map[string]interface{}, len(remoteLimits)),
+			LastUpdated: now,
+			Reason:      "remote-override",
+			Source:      "remote-override",
+		}
+		if calculated, exists := merged[tenant]; exists {
+			for limitName, value := range calculated.Limits {
+				tenantLimits.Limits[limitName] = value
+			}
+			tenantLimits.Reason = calculated.Reason + "+remote-override"
+			tenantLimits.Tier = calculated.Tier
 		}
+		for limitName, value := range remoteLimits {
+			tenantLimits.Limits[limitName] = value
+		}
+
+		merged[tenant] = tenantLimits
+		overridden++
+	}
+
+	r.Log.Info("applied remote overrides", "tenants", overridden, "url", r.config().Limits.RemoteOverrideSource.URL)
+	return merged
+}
+
+// GetDriftReport returns the latest drift report against the secondary cluster
+func (r *MimirLimitController) GetDriftReport() (*drift.Report, error) {
+	if r.DriftDetector == nil {
+		return nil, fmt.Errorf("secondary cluster drift detection not enabled")
+	}
+	return r.DriftDetector.LastReport(), nil
+}
+
+// GetProtectionThresholds returns the blast detection thresholds applied to each tenant
+func (r *MimirLimitController) GetProtectionThresholds() (*circuitbreaker.ThresholdsReport, error) {
+	if r.BlastProtector == nil || !r.config().CircuitBreaker.Enabled {
+		return nil, fmt.Errorf("circuit breaker not enabled")
+	}
+	report := r.BlastProtector.EffectiveThresholds()
+	return &report, nil
+}
+
+// GetCostReport projects the monthly cost of each tenant from its recent usage and
+// its applied limits
+func (r *MimirLimitController) GetCostReport(ctx context.Context) (*costcontrol.CostReport, error) {
+	if r.CostController == nil || !r.config().CostControl.Enabled {
+		return nil, fmt.Errorf("cost control not enabled")
 	}
+
+	limits, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		// The usage estimate does not depend on the limits, so report it without them
+		r.Log.Error(err, "failed to read applied limits for the cost report")
+		limits = nil
+	}
+	return r.CostController.CostReport(limits, time.Now()), nil
+}
+
+// GetCostSpend returns the spend accumulated by each tenant, or only by tenant when it
+// is not empty, and by all tenants
+func (r *MimirLimitController) GetCostSpend(tenant string) (*costcontrol.SpendReport, error) {
+	if r.CostController == nil || !r.config().CostControl.Enabled {
+		return nil, fmt.Errorf("cost control not enabled")
+	}
+	report, found := r.CostController.SpendReport(tenant, time.Now())
+	if !found {
+		return nil, fmt.Errorf("no spend recorded for tenant %s", tenant)
+	}
+	return report, nil
 }
 
 // logPreview logs the preview results in dry-run mode
@@ -483,7 +1481,7 @@ func (r *MimirLimitController) logPreview(preview *patcher.PreviewResult) {
 
 // logEnterpriseStatus logs enterprise feature status in dry-run mode
 func (r *MimirLimitController) logEnterpriseStatus(ctx context.Context, costs map[string]*costcontrol.TenantCostData, protectionStatus map[string]interface{}) {
-	if r.Config.CostControl.Enabled && costs != nil {
+	if r.config().CostControl.Enabled && costs != nil {
 		totalCost := 0.0
 		for _, cost := range costs {
 			totalCost += cost.DailyCost
@@ -491,10 +1489,10 @@ func (r *MimirLimitController) logEnterpriseStatus(ctx context.Context, costs ma
 		r.Log.Info("DRY-RUN Cost Control Status",
 			"total_daily_cost", totalCost,
 			"tenants_with_costs", len(costs),
-			"currency", r.Config.CostControl.GlobalBudget.Currency)
+			"currency", r.config().CostControl.GlobalBudget.Currency)
 	}
 
-	if r.Config.CircuitBreaker.Enabled {
+	if r.config().CircuitBreaker.Enabled {
 		r.Log.Info("DRY-RUN Blast Protection Status",
 			"circuit_breaker_state", protectionStatus["circuit_breaker_state"],
 			"emergency_mode", protectionStatus["emergency_mode"],
@@ -507,6 +1505,9 @@ func (r *MimirLimitController) updateCurrentLimitsMetrics(ctx context.Context, l
 	for tenant, limit := range limits {
 		// Update metrics for all dynamic limits
 		for limitName, limitValue := range limit.Limits {
+			if limitDef, defined := r.config().DynamicLimits.LimitDefinitions[limitName]; defined && !limitDef.Enabled {
+				continue
+			}
 			if val, ok := limitValue.(float64); ok && val > 0 {
 				metrics.TenantMetricsInstance.SetTenantCurrentLimits(tenant, limitName, val)
 			}
@@ -516,36 +1517,42 @@ func (r *MimirLimitController) updateCurrentLimitsMetrics(ctx context.Context, l
 
 // GetStatus returns the current status of the controller
 func (r *MimirLimitController) GetStatus() *ControllerStatus {
+	components := r.health.Snapshot()
 	return &ControllerStatus{
 		LastReconcile:    r.lastReconcile,
 		ReconcileCount:   r.reconcileCount,
-		Mode:             r.Config.Mode,
-		UpdateInterval:   r.Config.UpdateInterval,
-		ComponentsHealth: r.getComponentsHealth(),
+		Mode:             r.config().Mode,
+		UpdateInterval:   r.config().UpdateInterval,
+		ComponentsHealth: componentsHealthy(components),
+		Components:       components,
 	}
 }
 
 // ControllerStatus represents the current status of the controller
 type ControllerStatus struct {
-	LastReconcile    time.Time       `json:"last_reconcile"`
-	ReconcileCount   int64           `json:"reconcile_count"`
-	Mode             string          `json:"mode"`
-	UpdateInterval   time.Duration   `json:"update_interval"`
-	ComponentsHealth map[string]bool `json:"components_health"`
+	LastReconcile  time.Time     `json:"last_reconcile"`
+	ReconcileCount int64         `json:"reconcile_count"`
+	Mode           string        `json:"mode"`
+	UpdateInterval time.Duration `json:"update_interval"`
+
+	// ComponentsHealth is whether each component's last operation succeeded, kept for
+	// clients predating Components
+	ComponentsHealth map[string]bool            `json:"components_health"`
+	Components       map[string]ComponentHealth `json:"components"`
 }
 
-// getComponentsHealth checks the health of all components
-func (r *MimirLimitController) getComponentsHealth() map[string]bool {
-	return map[string]bool{
-		"collector":    true, // TODO: Implement actual health checks
-		"analyzer":     true,
-		"patcher":      true,
-		"audit_logger": r.Config.AuditLog.Enabled,
+// componentsHealthy reduces the health of each component to whether it is ok
+func componentsHealthy(components map[string]ComponentHealth) map[string]bool {
+	healthy := make(map[string]bool, len(components))
+	for component, health := range components {
+		healthy[component] = health.OK
 	}
+	return healthy
 }
 
-// TriggerReconciliation manually triggers a reconciliation (for testing/debugging)
-func (r *MimirLimitController) TriggerReconciliation(ctx context.Context) error {
+// TriggerReconciliation manually triggers a reconciliation (for testing/debugging) and
+// returns its reconcile ID, under which its result is kept
+func (r *MimirLimitController) TriggerReconciliation(ctx context.Context) (int64, error) {
 	r.Log.Info("manually triggered reconciliation")
 	return r.reconcile(ctx)
 }
@@ -558,6 +1565,14 @@ func (r *MimirLimitController) GetAuditEntries(ctx context.Context, filter *audi
 	return r.AuditLogger.GetEntries(ctx, filter)
 }
 
+// GetRecommendationHistory retrieves recorded recommendations with optional filtering, oldest first
+func (r *MimirLimitController) GetRecommendationHistory(ctx context.Context, filter history.Filter) ([]history.Recommendation, error) {
+	if r.RecommendationHistory == nil || !r.config().RecommendationHistory.Enabled {
+		return nil, fmt.Errorf("recommendation history not enabled")
+	}
+	return r.RecommendationHistory.Query(ctx, filter)
+}
+
 // RollbackLastChange rolls back the last configuration change
 func (r *MimirLimitController) RollbackLastChange(ctx context.Context) error {
 	r.Log.Info("rolling back last configuration change")
@@ -591,9 +1606,103 @@ func (r *MimirLimitController) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if manager := r.GetAlertManager(); manager != nil {
+		manager.Stop()
+	}
+
 	return nil
 }
 
+// acquireWriteLock waits up to writeLockTimeout for the ConfigMap write lease
+func (r *MimirLimitController) acquireWriteLock(ctx context.Context) bool {
+	waitStart := time.Now()
+	acquired, err := r.WriteLock.Acquire(ctx, writeLockTimeout)
+	result := "acquired"
+	if !acquired {
+		result = "timeout"
+	}
+	metrics.ReconcileMetricsInstance.ObserveLockWaitDuration(result, time.Since(waitStart).Seconds())
+
+	if err != nil {
+		r.Log.Error(err, "failed to acquire ConfigMap write lock")
+	}
+	return acquired
+}
+
+func (r *MimirLimitController) releaseWriteLock() {
+	ctx, cancel := context.WithTimeout(context.Background(), writeLockTimeout)
+	defer cancel()
+	if err := r.WriteLock.Release(ctx); err != nil {
+		r.Log.Error(err, "failed to release ConfigMap write lock")
+	}
+}
+
+// lockNamespace returns the namespace holding the write lease, preferring the
+// optimizer's own namespace over the Mimir namespace
+func lockNamespace(cfg *config.Config) string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	return cfg.Mimir.Namespace
+}
+
+// lockIdentity returns a per-replica identity for the write lease
+func lockIdentity() string {
+	if podName := os.Getenv("POD_NAME"); podName != "" {
+		return podName
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return fmt.Sprintf("mimir-limit-optimizer-%d", os.Getpid())
+}
+
+// GetAlertManager returns the alerting manager, nil while alerting is not enabled
+func (r *MimirLimitController) GetAlertManager() *alerting.Manager {
+	r.alertMu.RLock()
+	defer r.alertMu.RUnlock()
+	return r.alertManager
+}
+
+// SetAlertManager replaces the alerting manager with one started by the caller
+func (r *MimirLimitController) SetAlertManager(manager *alerting.Manager) {
+	r.alertMu.Lock()
+	defer r.alertMu.Unlock()
+	r.alertManager = manager
+}
+
+// startAlertManager creates and starts the alerting manager unless one is running.
+// It runs on setup and when a configuration reload enables alerting.
+func (r *MimirLimitController) startAlertManager() *alerting.Manager {
+	r.alertMu.Lock()
+	defer r.alertMu.Unlock()
+	if r.alertManager != nil {
+		return r.alertManager
+	}
+
+	cfg := r.config()
+	manager := alerting.NewManager(&cfg.Alerting, r.Log.WithName("alerting"))
+	manager.SetDeliveryObserver(func(err error) {
+		r.health.Record(ComponentAlerting, err)
+	})
+	if r.Client != nil && cfg.Alerting.SilenceConfigMapName != "" {
+		manager.SetSilenceStore(alerting.NewConfigMapSilenceStore(r.Client,
+			cfg.Alerting.SilenceConfigMapName, cfg.Mimir.Namespace))
+	}
+	if err := manager.Start(); err != nil {
+		r.health.RecordFailure(ComponentAlerting, err)
+		r.Log.Error(err, "failed to start alerting manager")
+	}
+	if r.BlastProtector != nil {
+		r.BlastProtector.SetAlertManager(manager)
+	}
+	if r.CostController != nil {
+		r.CostController.SetAlertManager(manager)
+	}
+	r.alertManager = manager
+	return manager
+}
+
 // GetTenantFilter returns the tenant filter instance
 func (r *MimirLimitController) GetTenantFilter() *TenantFilter {
 	// Lazy initialization to ensure tenant filter is always available
@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/costcontrol"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/remoteoverrides"
+)
+
+// fakeCollector reports a settable tenant list and its metrics
+type fakeCollector struct {
+	mu      sync.Mutex
+	tenants []string
+	metrics map[string]*collector.TenantMetrics
+}
+
+func (f *fakeCollector) setTenants(tenants ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tenants = tenants
+}
+
+func (f *fakeCollector) CollectMetrics(ctx context.Context) (map[string]*collector.TenantMetrics, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.metrics, nil
+}
+
+func (f *fakeCollector) GetTenantList(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tenants...), nil
+}
+
+// testController is a controller writing the runtime overrides ConfigMap of a fake client
+type testController struct {
+	*MimirLimitController
+	client    client.Client
+	collector *fakeCollector
+	audit     *auditlog.MemoryAuditLogger
+}
+
+// newTestController creates a controller of cfg whose components are wired as
+// SetupWithManager does, reading metrics from a fake collector and writing to a fake
+// client holding objs
+func newTestController(cfg *config.Config, objs ...client.Object) *testController {
+	return newInterceptedTestController(cfg, interceptor.Funcs{}, objs...)
+}
+
+// newInterceptedTestController is newTestController with funcs intercepting the calls
+// to the fake client
+func newInterceptedTestController(cfg *config.Config, funcs interceptor.Funcs, objs ...client.Object) *testController {
+	c := fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(funcs).Build()
+	audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	fakeCollector := &fakeCollector{}
+
+	r := &MimirLimitController{
+		Client:      c,
+		KubeClient:  kubefake.NewSimpleClientset(),
+		Config:      config.NewLive(cfg),
+		Log:         logr.Discard(),
+		Collector:   fakeCollector,
+		AuditLogger: audit,
+	}
+	r.health = NewHealthRegistry(ComponentCollector, ComponentAnalyzer, ComponentPatcher)
+	r.tenantFilter = NewTenantFilter(r.Config, r.Log)
+	r.Analyzer = analyzer.NewAnalyzer(r.Config, r.Log)
+	configMapPatcher := patcher.NewConfigMapPatcher(c, nil, r.Config, audit, r.Log)
+	configMapPatcher.SetTenantFilter(r.tenantFilter.ShouldProcessTenant)
+	configMapPatcher.SetPauseFilter(r.tenantFilter.IsPaused)
+	r.Patcher = configMapPatcher
+	r.CostController = costcontrol.NewCostController(r.Config, r.Log)
+	r.BlastProtector = circuitbreaker.NewBlastProtector(r.Config, r.Log)
+	r.emergencyLimits = newEmergencyLimits()
+	return &testController{MimirLimitController: r, client: c, collector: fakeCollector, audit: audit}
+}
+
+// ingestionMetrics reports samplesPerSecond received for each tenant over the last
+// ten minutes
+func ingestionMetrics(samplesPerSecond float64, tenants ...string) map[string]*collector.TenantMetrics {
+	const metricName = "cortex_distributor_received_samples_total"
+	now := time.Now()
+	result := make(map[string]*collector.TenantMetrics, len(tenants))
+	for _, tenant := range tenants {
+		data := make([]collector.MetricData, 10)
+		for i := range data {
+			data[i] = collector.MetricData{
+				Tenant:     tenant,
+				MetricName: metricName,
+				Value:      samplesPerSecond,
+				Timestamp:  now.Add(time.Duration(i-len(data)) * time.Minute),
+			}
+		}
+		result[tenant] = &collector.TenantMetrics{
+			Tenant:  tenant,
+			Metrics: map[string][]collector.MetricData{metricName: data},
+		}
+	}
+	return result
+}
+
+// setMetrics replaces the metrics the collector reports, listing their tenants
+func (f *fakeCollector) setMetrics(tenantMetrics map[string]*collector.TenantMetrics) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metrics = tenantMetrics
+	f.tenants = f.tenants[:0]
+	for tenant := range tenantMetrics {
+		f.tenants = append(f.tenants, tenant)
+	}
+}
+
+// overridesConfigMap is the runtime overrides ConfigMap of cfg holding overridesYAML
+func overridesConfigMap(cfg *config.Config, overridesYAML string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Mimir.ConfigMapName, Namespace: cfg.Mimir.Namespace},
+		Data:       map[string]string{"overrides.yaml": overridesYAML},
+	}
+}
+
+// tenantOverrides returns the tenants in the runtime overrides ConfigMap
+func (tc *testController) tenantOverrides(t *testing.T) map[string]interface{} {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: tc.config().Mimir.ConfigMapName, Namespace: tc.config().Mimir.Namespace}
+	if err := tc.client.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	overrides, _, err := patcher.ParseOverridesYAML(configMap.Data["overrides.yaml"])
+	if err != nil {
+		t.Fatalf("parse runtime overrides: %v", err)
+	}
+	return patcher.TenantOverrides(overrides)
+}
+
+// auditEntries returns the audit entries with action
+func (tc *testController) auditEntries(t *testing.T, action string) []*auditlog.AuditEntry {
+	t.Helper()
+	entries, err := tc.audit.GetEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	var matched []*auditlog.AuditEntry
+	for _, entry := range entries {
+		if entry.Action == action {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+const twoTenantOverrides = `overrides:
+  tenant-a:
+    ingestion_rate: 10000
+  tenant-b:
+    ingestion_rate: 5000
+`
+
+func TestInactiveTenantCleanup(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Limits.InactiveTenantTTL = time.Second
+	tc := newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+	ctx := context.Background()
+
+	tc.collector.setTenants("tenant-a", "tenant-b")
+	tc.cleanupInactiveTenants(ctx, nil)
+
+	// tenant-b disappears; it is only removed once it has been gone for the TTL
+	tc.collector.setTenants("tenant-a")
+	tc.cleanupInactiveTenants(ctx, nil)
+	if _, exists := tc.tenantOverrides(t)["tenant-b"]; !exists {
+		t.Fatalf("tenant-b was removed before the inactive TTL passed")
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	tc.cleanupInactiveTenants(ctx, nil)
+
+	overrides := tc.tenantOverrides(t)
+	if _, exists := overrides["tenant-b"]; exists {
+		t.Errorf("inactive tenant-b is still in the overrides")
+	}
+	if _, exists := overrides["tenant-a"]; !exists {
+		t.Errorf("active tenant-a was removed")
+	}
+	entries := tc.auditEntries(t, "tenant_cleanup")
+	if len(entries) != 1 || entries[0].Tenant != "tenant-b" {
+		t.Errorf("tenant_cleanup audit entries = %d, want one for tenant-b", len(entries))
+	}
+}
+
+func TestInactiveTenantCleanupDryRun(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "dry-run"
+	cfg.Limits.InactiveTenantTTL = time.Second
+	tc := newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+	ctx := context.Background()
+
+	tc.collector.setTenants("tenant-a", "tenant-b")
+	tc.cleanupInactiveTenants(ctx, nil)
+	tc.collector.setTenants("tenant-a")
+	time.Sleep(1100 * time.Millisecond)
+	tc.cleanupInactiveTenants(ctx, nil)
+
+	if _, exists := tc.tenantOverrides(t)["tenant-b"]; !exists {
+		t.Errorf("dry-run removed tenant-b")
+	}
+	entries := tc.auditEntries(t, "tenant_cleanup")
+	if len(entries) != 1 || entries[0].Tenant != "tenant-b" {
+		t.Errorf("dry-run tenant_cleanup audit entries = %d, want one for tenant-b", len(entries))
+	}
+}
+
+func TestInactiveTenantCleanupSkipsEmptyTenantList(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Limits.InactiveTenantTTL = time.Nanosecond
+	tc := newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+
+	tc.cleanupInactiveTenants(context.Background(), nil)
+	tc.cleanupInactiveTenants(context.Background(), nil)
+
+	if overrides := tc.tenantOverrides(t); len(overrides) != 2 {
+		t.Errorf("overrides = %v, want both tenants kept when the collector lists none", overrides)
+	}
+}
+
+func TestApplyRemoteOverridesMergesFixture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`overrides:
+  tenant-a:
+    ingestion_rate: 50000
+  tenant-new:
+    max_global_series_per_user: 300000
+  internal-metrics:
+    ingestion_rate: 1
+`))
+	}))
+	defer server.Close()
+
+	cfg := config.GetDefaultConfig()
+	cfg.TenantScoping.SkipList = []string{"internal-*"}
+	cfg.Limits.RemoteOverrideSource = config.RemoteOverrideConfig{Enabled: true, URL: server.URL, Format: "yaml"}
+	r := newReloadTestController(cfg)
+	r.RemoteOverrides = remoteoverrides.NewSource(cfg.Limits.RemoteOverrideSource, logr.Discard())
+
+	calculated := map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Reason: "trend-analysis", Tier: "gold", Limits: map[string]interface{}{
+			"ingestion_rate":             float64(20000),
+			"max_global_series_per_user": float64(150000),
+		}},
+		"tenant-b": {Tenant: "tenant-b", Reason: "trend-analysis", Limits: map[string]interface{}{
+			"ingestion_rate": float64(10000),
+		}},
+	}
+	merged := r.applyRemoteOverrides(context.Background(), calculated)
+
+	want := map[string]map[string]interface{}{
+		"tenant-a":   {"ingestion_rate": float64(50000), "max_global_series_per_user": float64(150000)},
+		"tenant-b":   {"ingestion_rate": float64(10000)},
+		"tenant-new": {"max_global_series_per_user": float64(300000)},
+	}
+	if len(merged) != len(want) {
+		t.Errorf("merged tenants = %d, want %d; skipped tenants are not added", len(merged), len(want))
+	}
+	for tenant, limits := range want {
+		if got := merged[tenant]; got == nil || !reflect.DeepEqual(got.Limits, limits) {
+			t.Errorf("limits of %s = %v, want %v", tenant, got, limits)
+		}
+	}
+	if got := merged["tenant-a"]; got.Reason != "trend-analysis+remote-override" || got.Tier != "gold" {
+		t.Errorf("tenant-a reason %q, tier %q, want the calculated ones marked as remote-override", got.Reason, got.Tier)
+	}
+	if got := calculated["tenant-a"].Limits["ingestion_rate"]; got != float64(20000) {
+		t.Errorf("applyRemoteOverrides modified the calculated limits: ingestion_rate = %v", got)
+	}
+}
+
+// scopingTenants are the tenants the tenant filter tests filter
+var scopingTenants = []string{"prod-a", "prod-b", "prod-test", "staging-a", "dev"}
+
+func newTestTenantFilter(scoping config.TenantScopingConfig) *TenantFilter {
+	cfg := config.GetDefaultConfig()
+	cfg.TenantScoping = scoping
+	return NewTenantFilter(config.NewLive(cfg), logr.Discard())
+}
+
+func TestFilterTenantsPrecedence(t *testing.T) {
+	tests := []struct {
+		name          string
+		scoping       config.TenantScopingConfig
+		wantMonitored []string
+	}{
+		{"no lists", config.TenantScopingConfig{}, scopingTenants},
+		{"empty include list includes all", config.TenantScopingConfig{SkipList: []string{"dev"}},
+			[]string{"prod-a", "prod-b", "prod-test", "staging-a"}},
+		{"glob include list", config.TenantScopingConfig{IncludeList: []string{"prod-*"}},
+			[]string{"prod-a", "prod-b", "prod-test"}},
+		{"skip wins over include", config.TenantScopingConfig{IncludeList: []string{"prod-*"}, SkipList: []string{"*-test"}},
+			[]string{"prod-a", "prod-b"}},
+		{"glob ? and classes", config.TenantScopingConfig{IncludeList: []string{"prod-?", "[ds]*"}},
+			[]string{"prod-a", "prod-b", "staging-a", "dev"}},
+		{"glob matches the whole ID", config.TenantScopingConfig{IncludeList: []string{"prod"}}, nil},
+		{"regex include list", config.TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-[ab]|dev"}},
+			[]string{"prod-a", "prod-b", "dev"}},
+		{"regex skip wins over include", config.TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-.*"}, SkipList: []string{".*test"}},
+			[]string{"prod-a", "prod-b"}},
+		{"regex is anchored", config.TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod"}}, nil},
+		{"glob not matched as regex", config.TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-*"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf := newTestTenantFilter(tt.scoping)
+			if err := tf.Err(); err != nil {
+				t.Fatalf("Err: %v", err)
+			}
+			monitored, skipped := tf.FilterTenants(scopingTenants)
+			if !reflect.DeepEqual(monitored, tt.wantMonitored) {
+				t.Errorf("monitored = %v, want %v", monitored, tt.wantMonitored)
+			}
+			if len(monitored)+len(skipped) != len(scopingTenants) {
+				t.Errorf("monitored %v and skipped %v do not partition the tenants", monitored, skipped)
+			}
+		})
+	}
+}
+
+func TestTenantFilterInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		scoping config.TenantScopingConfig
+		pattern string
+	}{
+		{"regex typo", config.TenantScopingConfig{UseRegex: true, IncludeList: []string{"prod-[a-z"}}, "prod-[a-z"},
+		{"unclosed glob class", config.TenantScopingConfig{SkipList: []string{"prod-[ab"}}, "prod-[ab"},
+		{"invalid paused pattern", config.TenantScopingConfig{UseRegex: true, PausedTenants: []string{"(dev"}}, "(dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf := newTestTenantFilter(tt.scoping)
+			var invalid *InvalidPatternError
+			if err := tf.Err(); !errors.As(err, &invalid) || invalid.Pattern != tt.pattern {
+				t.Errorf("Err = %v, want an invalid pattern error for %q", err, tt.pattern)
+			}
+
+			// The pattern is also rejected before it is added to the lists
+			tf = newTestTenantFilter(config.TenantScopingConfig{UseRegex: tt.scoping.UseRegex})
+			if err := tf.ValidatePattern(tt.pattern); !errors.As(err, &invalid) {
+				t.Errorf("ValidatePattern(%q) = %v, want an invalid pattern error", tt.pattern, err)
+			}
+		})
+	}
+}
+
+func TestReconcileRefusesInvalidTenantPatterns(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	cfg.TenantScoping.UseRegex = true
+	cfg.TenantScoping.IncludeList = []string{"tenant-[ab"}
+	tc := newTestController(cfg, overridesConfigMap(cfg, "overrides: {}\n"))
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+
+	if _, err := tc.reconcile(context.Background()); err == nil || !strings.Contains(err.Error(), "tenant-[ab") {
+		t.Fatalf("reconcile = %v, want an error naming the invalid pattern", err)
+	}
+	if overrides := tc.tenantOverrides(t); len(overrides) != 0 {
+		t.Errorf("reconcile with an invalid include pattern wrote overrides %v", overrides)
+	}
+
+	// Fixing the pattern resumes reconciling with the corrected list
+	tc.tenantFilter.SetLists(nil, []string{"tenant-[ab]"}, nil, scopingSourceConfigMap)
+	if _, err := tc.reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if overrides := tc.tenantOverrides(t); len(overrides) != 2 {
+		t.Errorf("reconcile with the fixed pattern wrote overrides for %d tenants, want 2", len(overrides))
+	}
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// grafanaDashboardConfigMapName holds the generated Grafana dashboard
+	grafanaDashboardConfigMapName = "grafana-dashboard-mimir-optimizer"
+	grafanaDashboardDataKey       = "mimir-optimizer.json"
+)
+
+// provisionGrafanaDashboard creates or updates the ConfigMap holding the Grafana
+// dashboard of the optimizer's metrics, labelled for the Grafana dashboard sidecar
+func (r *MimirLimitController) provisionGrafanaDashboard(ctx context.Context) error {
+	if !r.config().UI.GenerateDashboard || r.KubeClient == nil {
+		return nil
+	}
+
+	dashboard, err := metrics.GenerateGrafanaDashboard()
+	if err != nil {
+		return err
+	}
+
+	namespace := r.config().UI.DashboardNamespace
+	labels := map[string]string{
+		"app.kubernetes.io/name":      "mimir-limit-optimizer",
+		"app.kubernetes.io/component": "grafana-dashboard",
+		"grafana_dashboard":           "1",
+	}
+	annotations := map[string]string{
+		"grafana_folder": r.config().UI.DashboardFolder,
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(namespace)
+	configMap, err := configMaps.Get(ctx, grafanaDashboardConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        grafanaDashboardConfigMapName,
+				Namespace:   namespace,
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Data: map[string]string{grafanaDashboardDataKey: string(dashboard)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create Grafana dashboard ConfigMap: %w", err)
+		}
+		r.Log.Info("provisioned Grafana dashboard", "configmap", grafanaDashboardConfigMapName, "namespace", namespace)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Grafana dashboard ConfigMap: %w", err)
+	}
+
+	if configMap.Labels == nil {
+		configMap.Labels = make(map[string]string)
+	}
+	for key, value := range labels {
+		configMap.Labels[key] = value
+	}
+	if configMap.Annotations == nil {
+		configMap.Annotations = make(map[string]string)
+	}
+	for key, value := range annotations {
+		configMap.Annotations[key] = value
+	}
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[grafanaDashboardDataKey] = string(dashboard)
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update Grafana dashboard ConfigMap: %w", err)
+	}
+
+	r.Log.Info("updated Grafana dashboard", "configmap", grafanaDashboardConfigMapName, "namespace", namespace)
+	return nil
+}
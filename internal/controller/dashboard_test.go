@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+func newDashboardTestController(generate bool, objs ...runtime.Object) *MimirLimitController {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	cfg.UI.GenerateDashboard = generate
+	cfg.UI.DashboardNamespace = "monitoring"
+	cfg.UI.DashboardFolder = "Mimir"
+
+	r := newReloadTestController(cfg)
+	r.KubeClient = kubefake.NewSimpleClientset(objs...)
+	return r
+}
+
+func dashboardConfigMap(t *testing.T, r *MimirLimitController) *corev1.ConfigMap {
+	t.Helper()
+	configMap, err := r.KubeClient.CoreV1().ConfigMaps("monitoring").Get(context.Background(), grafanaDashboardConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get dashboard ConfigMap: %v", err)
+	}
+	return configMap
+}
+
+func TestProvisionGrafanaDashboardCreatesConfigMap(t *testing.T) {
+	r := newDashboardTestController(true)
+
+	if err := r.provisionGrafanaDashboard(context.Background()); err != nil {
+		t.Fatalf("provisionGrafanaDashboard: %v", err)
+	}
+
+	configMap := dashboardConfigMap(t, r)
+	if configMap.Labels["grafana_dashboard"] != "1" {
+		t.Errorf("labels = %v, want the grafana_dashboard sidecar label", configMap.Labels)
+	}
+	if configMap.Annotations["grafana_folder"] != "Mimir" {
+		t.Errorf("annotations = %v, want the grafana_folder annotation", configMap.Annotations)
+	}
+	var dashboard struct {
+		UID string `json:"uid"`
+	}
+	if err := json.Unmarshal([]byte(configMap.Data[grafanaDashboardDataKey]), &dashboard); err != nil {
+		t.Fatalf("dashboard under %s is not valid JSON: %v", grafanaDashboardDataKey, err)
+	}
+	if dashboard.UID != metrics.DashboardUID {
+		t.Errorf("dashboard uid = %q, want %q", dashboard.UID, metrics.DashboardUID)
+	}
+}
+
+func TestProvisionGrafanaDashboardUpdatesConfigMap(t *testing.T) {
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      grafanaDashboardConfigMapName,
+			Namespace: "monitoring",
+			Labels:    map[string]string{"team": "observability"},
+		},
+		Data: map[string]string{grafanaDashboardDataKey: "{}", "other.json": "{}"},
+	}
+	r := newDashboardTestController(true, existing)
+
+	if err := r.provisionGrafanaDashboard(context.Background()); err != nil {
+		t.Fatalf("provisionGrafanaDashboard: %v", err)
+	}
+
+	configMap := dashboardConfigMap(t, r)
+	if configMap.Data[grafanaDashboardDataKey] == "{}" {
+		t.Errorf("dashboard was not replaced")
+	}
+	if configMap.Data["other.json"] != "{}" || configMap.Labels["team"] != "observability" {
+		t.Errorf("update dropped the other keys or labels: labels %v", configMap.Labels)
+	}
+	if configMap.Labels["grafana_dashboard"] != "1" {
+		t.Errorf("labels = %v, want the grafana_dashboard sidecar label added", configMap.Labels)
+	}
+}
+
+func TestProvisionGrafanaDashboardDisabled(t *testing.T) {
+	r := newDashboardTestController(false)
+
+	if err := r.provisionGrafanaDashboard(context.Background()); err != nil {
+		t.Fatalf("provisionGrafanaDashboard: %v", err)
+	}
+	configMaps, err := r.KubeClient.CoreV1().ConfigMaps("monitoring").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("list ConfigMaps: %v", err)
+	}
+	if len(configMaps.Items) != 0 {
+		t.Errorf("dashboard ConfigMap created with generateDashboard disabled")
+	}
+}
@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+)
+
+// emergencyTriggerMeasure returns the measurement of the emergency triggers: the
+// highest current value of a PromQL expression on the metrics endpoint
+func (r *MimirLimitController) emergencyTriggerMeasure(kubeClient kubernetes.Interface) circuitbreaker.MeasureFunc {
+	mimirCollector := collector.NewMimirCollector(r.Config, kubeClient, r.Log.WithName("emergency-triggers"))
+
+	return func(ctx context.Context, query string) (float64, error) {
+		now := time.Now()
+		data, err := mimirCollector.QueryHistoricalData(ctx, query, now, now, time.Minute)
+		if err != nil {
+			return 0, err
+		}
+
+		value, found := 0.0, false
+		for _, point := range data {
+			if math.IsNaN(point.Value) {
+				continue
+			}
+			if !found || point.Value > value {
+				value, found = point.Value, true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("query returned no samples")
+		}
+		return value, nil
+	}
+}
+
+// GetProtectionStatus returns the circuit breaker and emergency mode state, with the
+// latest evaluation of each emergency trigger when the trigger monitor runs
+func (r *MimirLimitController) GetProtectionStatus() (map[string]interface{}, error) {
+	if r.BlastProtector == nil {
+		return nil, fmt.Errorf("blast protection not initialized")
+	}
+	return r.BlastProtector.GetProtectionStatus(), nil
+}
+
+// ForceOpenCircuitBreaker holds the circuit breaker open until it is force closed
+func (r *MimirLimitController) ForceOpenCircuitBreaker(reason string) (map[string]interface{}, error) {
+	if r.BlastProtector == nil {
+		return nil, fmt.Errorf("blast protection not initialized")
+	}
+	if err := r.BlastProtector.ForceOpen(reason); err != nil {
+		return nil, err
+	}
+	return r.BlastProtector.GetProtectionStatus(), nil
+}
+
+// ForceCloseCircuitBreaker ends a forced open, emergency or panic mode and moves the
+// circuit breaker to half-open
+func (r *MimirLimitController) ForceCloseCircuitBreaker(reason string) (map[string]interface{}, error) {
+	if r.BlastProtector == nil {
+		return nil, fmt.Errorf("blast protection not initialized")
+	}
+	if err := r.BlastProtector.ForceClose(reason); err != nil {
+		return nil, err
+	}
+	return r.BlastProtector.GetProtectionStatus(), nil
+}
@@ -0,0 +1,515 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+const (
+	// emergencyActionSource is the source of the limits written by the emergency actions
+	emergencyActionSource = "emergency-action"
+
+	// emergencyLimitsAnnotation keeps the emergency limits on the runtime overrides
+	// ConfigMap, so the values they replaced are still restored after a restart
+	emergencyLimitsAnnotation = "mimir-limit-optimizer/emergency-limits"
+)
+
+var (
+	// reducedLimitNames are the ingestion and series limits cut by reduce_limits
+	reducedLimitNames = []string{"ingestion_rate", "ingestion_burst_size", "max_global_series_per_user", "max_global_series_per_metric"}
+
+	// throttledLimitNames are the request limits set by throttle_ingestion
+	throttledLimitNames = []string{"request_rate", "request_burst_size"}
+)
+
+// emergencyLimits holds the limits written by the emergency actions until recovery:
+// the values they replaced and the emergency values every reconciliation keeps
+type emergencyLimits struct {
+	mu sync.Mutex
+	// original is the previous value of each overridden limit, nil when it was unset
+	original map[string]map[string]interface{}
+	// held is the emergency value of each overridden limit
+	held map[string]map[string]interface{}
+	// deferred are the limits whose restore an emergency freeze postponed
+	deferred map[string]bool
+}
+
+// emergencyLimitsState is the persisted form of the emergency limits
+type emergencyLimitsState struct {
+	Original map[string]map[string]interface{} `json:"original"`
+	Held     map[string]map[string]interface{} `json:"held"`
+}
+
+func newEmergencyLimits() *emergencyLimits {
+	return &emergencyLimits{
+		original: make(map[string]map[string]interface{}),
+		held:     make(map[string]map[string]interface{}),
+		deferred: make(map[string]bool),
+	}
+}
+
+// hold records an emergency value, keeping the first original of the limit
+func (e *emergencyLimits) hold(tenant, name string, original, value interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.held[tenant] == nil {
+		e.held[tenant] = make(map[string]interface{})
+		e.original[tenant] = make(map[string]interface{})
+	}
+	if _, held := e.held[tenant][name]; !held {
+		e.original[tenant][name] = original
+	}
+	e.held[tenant][name] = value
+}
+
+// state returns a copy of the held limits and the values they replaced
+func (e *emergencyLimits) state() emergencyLimitsState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state := emergencyLimitsState{
+		Original: make(map[string]map[string]interface{}, len(e.original)),
+		Held:     make(map[string]map[string]interface{}, len(e.held)),
+	}
+	for tenant, held := range e.held {
+		state.Held[tenant] = make(map[string]interface{}, len(held))
+		state.Original[tenant] = make(map[string]interface{}, len(held))
+		for name, value := range held {
+			state.Held[tenant][name] = value
+			state.Original[tenant][name] = e.original[tenant][name]
+		}
+	}
+	return state
+}
+
+// load replaces the held limits with a persisted state
+func (e *emergencyLimits) load(state emergencyLimitsState) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.original = make(map[string]map[string]interface{})
+	e.held = make(map[string]map[string]interface{})
+	for tenant, held := range state.Held {
+		if len(held) == 0 {
+			continue
+		}
+		e.held[tenant] = make(map[string]interface{}, len(held))
+		e.original[tenant] = make(map[string]interface{}, len(held))
+		for name, value := range held {
+			e.held[tenant][name] = value
+			e.original[tenant][name] = state.Original[tenant][name]
+		}
+	}
+}
+
+// holdsAny reports whether any tenant holds an emergency value of the named limits
+func (e *emergencyLimits) holdsAny(names []string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, held := range e.held {
+		for _, name := range names {
+			if _, ok := held[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isHeld reports whether the limit of a tenant holds an emergency value
+func (e *emergencyLimits) isHeld(tenant, name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, held := e.held[tenant][name]
+	return held
+}
+
+// deferRestore records limits to restore once the emergency freeze is lifted
+func (e *emergencyLimits) deferRestore(names []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, name := range names {
+		e.deferred[name] = true
+	}
+}
+
+// takeDeferred returns the limits whose restore was deferred and clears them
+func (e *emergencyLimits) takeDeferred() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]string, 0, len(e.deferred))
+	for name := range e.deferred {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	e.deferred = make(map[string]bool)
+	return names
+}
+
+// release stops holding the named limits and returns their original values by tenant
+func (e *emergencyLimits) release(names []string) map[string]map[string]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	released := make(map[string]map[string]interface{})
+	for tenant, held := range e.held {
+		for _, name := range names {
+			if _, ok := held[name]; !ok {
+				continue
+			}
+			if released[tenant] == nil {
+				released[tenant] = make(map[string]interface{})
+			}
+			released[tenant][name] = e.original[tenant][name]
+			delete(held, name)
+			delete(e.original[tenant], name)
+		}
+		if len(held) == 0 {
+			delete(e.held, tenant)
+			delete(e.original, tenant)
+		}
+	}
+	return released
+}
+
+// apply returns the limits with the held emergency values in place of higher
+// calculated ones, so a reconciliation does not undo the emergency actions
+func (e *emergencyLimits) apply(limits map[string]*analyzer.TenantLimits) map[string]*analyzer.TenantLimits {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.held) == 0 {
+		return limits
+	}
+
+	result := make(map[string]*analyzer.TenantLimits, len(limits))
+	for tenant, tenantLimits := range limits {
+		held := e.held[tenant]
+		if len(held) == 0 {
+			result[tenant] = tenantLimits
+			continue
+		}
+
+		copied := *tenantLimits
+		copied.Limits = make(map[string]interface{}, len(tenantLimits.Limits)+len(held))
+		for name, value := range tenantLimits.Limits {
+			copied.Limits[name] = value
+		}
+		for name, value := range held {
+			calculated, ok := config.LimitBoundValue(copied.Limits[name], "count")
+			if emergency, _ := config.LimitBoundValue(value, "count"); ok && calculated > 0 && calculated < emergency {
+				continue
+			}
+			copied.Limits[name] = value
+		}
+		result[tenant] = &copied
+	}
+	return result
+}
+
+// registerEmergencyActions registers the emergency actions that write limits
+func (r *MimirLimitController) registerEmergencyActions() {
+	r.BlastProtector.RegisterEmergencyAction(config.EmergencyActionReduceLimits, circuitbreaker.EmergencyAction{
+		Execute: r.reduceLimitsAction,
+		Recover: func(ctx context.Context) error {
+			return r.restoreEmergencyLimits(ctx, reducedLimitNames)
+		},
+	})
+	r.BlastProtector.RegisterEmergencyAction(config.EmergencyActionThrottleIngestion, circuitbreaker.EmergencyAction{
+		Execute: r.throttleIngestionAction,
+		Recover: func(ctx context.Context) error {
+			return r.restoreEmergencyLimits(ctx, throttledLimitNames)
+		},
+	})
+}
+
+// reduceLimitsAction cuts the ingestion and series limits of every tenant with
+// overrides by emergency.panicMode.limitReductionPercent, down to the limit minimum
+func (r *MimirLimitController) reduceLimitsAction(ctx context.Context, event circuitbreaker.EmergencyEvent) error {
+	factor := 1 - r.config().Emergency.PanicMode.LimitReductionPercent/100
+
+	return r.writeEmergencyLimits(ctx, config.EmergencyActionReduceLimits, event, reducedLimitNames,
+		func(def config.LimitDefinition, current float64, set bool) (float64, bool) {
+			if !set || current <= 0 {
+				// Unset and unlimited values have nothing to reduce
+				return 0, false
+			}
+			reduced := current * factor
+			if minValue, ok := def.MinValue.Number(); ok && reduced < minValue {
+				reduced = minValue
+			}
+			return reduced, reduced < current
+		})
+}
+
+// throttleIngestionAction sets the request rate and burst size of every tenant with
+// overrides to the conservative emergency.panicMode values, keeping lower values
+func (r *MimirLimitController) throttleIngestionAction(ctx context.Context, event circuitbreaker.EmergencyEvent) error {
+	panicMode := r.config().Emergency.PanicMode
+	throttle := map[string]float64{
+		"request_rate":       panicMode.ThrottleRequestRate,
+		"request_burst_size": float64(panicMode.ThrottleRequestBurstSize),
+	}
+
+	return r.writeEmergencyLimits(ctx, config.EmergencyActionThrottleIngestion, event, throttledLimitNames,
+		func(def config.LimitDefinition, current float64, set bool) (float64, bool) {
+			value := throttle[def.Name]
+			return value, !set || current <= 0 || value < current
+		})
+}
+
+// writeEmergencyLimits writes the emergency values of the named limits for every
+// tenant with overrides through the normal applier and holds them until recovery.
+// emergencyValue returns the value for a limit and whether it changes.
+func (r *MimirLimitController) writeEmergencyLimits(ctx context.Context, action string, event circuitbreaker.EmergencyEvent,
+	names []string, emergencyValue func(def config.LimitDefinition, current float64, set bool) (float64, bool)) error {
+	if freeze := r.refreshEmergencyFreeze(ctx); freeze != nil {
+		return fmt.Errorf("emergency freeze active, not writing limits: %s", freeze.Reason)
+	}
+
+	definitions := make(map[string]config.LimitDefinition, len(names))
+	for _, name := range names {
+		if def, exists := r.config().DynamicLimits.LimitDefinitions[name]; exists && def.Enabled {
+			definitions[name] = def
+		}
+	}
+	if len(definitions) == 0 {
+		return fmt.Errorf("none of the limits %v is enabled in dynamicLimits.limitDefinitions", names)
+	}
+
+	current, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read current limits: %w", err)
+	}
+
+	now := time.Now()
+	originals := make(map[string]map[string]interface{})
+	limits := make(map[string]*analyzer.TenantLimits)
+	for tenant, tenantLimits := range current {
+		for name, def := range definitions {
+			if r.emergencyLimits.isHeld(tenant, name) {
+				continue
+			}
+			original, set := tenantLimits.Limits[name]
+			currentValue, numeric := config.LimitBoundValue(original, def.Type)
+			value, changed := emergencyValue(def, currentValue, set && numeric)
+			if !changed {
+				continue
+			}
+
+			if limits[tenant] == nil {
+				limits[tenant] = &analyzer.TenantLimits{
+					Tenant:      tenant,
+					Limits:      make(map[string]interface{}),
+					LastUpdated: now,
+					Reason:      fmt.Sprintf("emergency action %s: %s", action, event.Reason),
+					Source:      emergencyActionSource,
+				}
+				originals[tenant] = make(map[string]interface{})
+			}
+			limits[tenant].Limits[name] = emergencyLimitValue(value, def.Type)
+			if set && numeric && currentValue != 0 {
+				originals[tenant][name] = original
+			} else {
+				originals[tenant][name] = nil
+			}
+		}
+	}
+	if len(limits) == 0 {
+		r.Log.Info("emergency action found no limits to change", "action", action)
+		return nil
+	}
+
+	applied, err := r.applyLimits(ctx, limits)
+	if err != nil {
+		return fmt.Errorf("failed to write emergency limits: %w", err)
+	}
+	for tenant, tenantLimits := range applied {
+		for name, value := range tenantLimits.Limits {
+			r.emergencyLimits.hold(tenant, name, originals[tenant][name], value)
+		}
+	}
+	if err := r.persistEmergencyLimits(ctx); err != nil {
+		r.Log.Error(err, "failed to persist emergency limits, a restart loses the values they replaced")
+	}
+
+	r.Log.Info("emergency action wrote limits", "action", action, "tenants", len(applied), "panic_mode", event.Panic)
+	return nil
+}
+
+// restoreEmergencyLimits writes back the values the emergency actions replaced for
+// the named limits. Limits that were unset are removed again. While an emergency
+// freeze is active the limits stay held and are restored once it is lifted.
+func (r *MimirLimitController) restoreEmergencyLimits(ctx context.Context, names []string) error {
+	if freeze := r.refreshEmergencyFreeze(ctx); freeze != nil {
+		r.emergencyLimits.deferRestore(names)
+		return fmt.Errorf("emergency freeze active, restoring limits once it is lifted: %s", freeze.Reason)
+	}
+
+	released := r.emergencyLimits.release(names)
+	if len(released) == 0 {
+		return nil
+	}
+	if err := r.persistEmergencyLimits(ctx); err != nil {
+		r.Log.Error(err, "failed to persist released emergency limits")
+	}
+
+	now := time.Now()
+	restored := make(map[string]*analyzer.TenantLimits)
+	removed := make(map[string][]string)
+	for tenant, originals := range released {
+		for name, original := range originals {
+			if original == nil {
+				removed[tenant] = append(removed[tenant], name)
+				continue
+			}
+			if restored[tenant] == nil {
+				restored[tenant] = &analyzer.TenantLimits{
+					Tenant:      tenant,
+					Limits:      make(map[string]interface{}),
+					LastUpdated: now,
+					Reason:      "emergency recovery: restoring limits from before the emergency actions",
+					Source:      emergencyActionSource,
+				}
+			}
+			restored[tenant].Limits[name] = original
+		}
+	}
+
+	// The hold is released either way, so a failed restore is corrected by the next
+	// reconciliations rather than pinning the emergency values
+	if len(restored) > 0 {
+		if _, err := r.applyLimits(ctx, restored); err != nil {
+			return fmt.Errorf("failed to restore limits: %w", err)
+		}
+	}
+	if err := r.Patcher.RemoveLimits(ctx, removed, "emergency recovery: removing limits set by the emergency actions"); err != nil {
+		return err
+	}
+
+	r.Log.Info("restored limits changed by emergency actions", "tenants", len(released), "limits", names)
+	return nil
+}
+
+// restoreDeferredEmergencyLimits restores the limits whose recovery an emergency freeze
+// deferred, once the freeze is lifted
+func (r *MimirLimitController) restoreDeferredEmergencyLimits(ctx context.Context) {
+	names := r.emergencyLimits.takeDeferred()
+	if len(names) == 0 {
+		return
+	}
+	if err := r.restoreEmergencyLimits(ctx, names); err != nil {
+		r.Log.Info("deferred restore of emergency limits not done", "limits", names, "reason", err.Error())
+	}
+}
+
+// emergencyLimitsKey is the runtime overrides ConfigMap the emergency limits are kept on,
+// the first one holding overrides in every format
+func (r *MimirLimitController) emergencyLimitsKey() types.NamespacedName {
+	mimir := r.config().Mimir
+	return types.NamespacedName{Name: mimir.OverridesConfigMapNames()[0], Namespace: mimir.Namespace}
+}
+
+// persistEmergencyLimits records the held emergency limits and the values they replaced
+// on the runtime overrides ConfigMap, removing the annotation once none is held
+func (r *MimirLimitController) persistEmergencyLimits(ctx context.Context) error {
+	if r.Client == nil {
+		return nil
+	}
+
+	var value string
+	if state := r.emergencyLimits.state(); len(state.Held) > 0 {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to marshal emergency limits: %w", err)
+		}
+		value = string(data)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, r.emergencyLimitsKey(), configMap); err != nil {
+			if apierrors.IsNotFound(err) && value == "" {
+				return nil
+			}
+			return fmt.Errorf("failed to get runtime overrides ConfigMap: %w", err)
+		}
+		if configMap.Annotations[emergencyLimitsAnnotation] == value {
+			return nil
+		}
+
+		if value == "" {
+			delete(configMap.Annotations, emergencyLimitsAnnotation)
+		} else {
+			if configMap.Annotations == nil {
+				configMap.Annotations = make(map[string]string)
+			}
+			configMap.Annotations[emergencyLimitsAnnotation] = value
+		}
+		return r.Client.Update(ctx, configMap)
+	})
+}
+
+// loadEmergencyLimits restores the emergency limits held before a restart and resumes
+// emergency mode for their actions, so recovery writes back the values they replaced
+func (r *MimirLimitController) loadEmergencyLimits(ctx context.Context) {
+	if r.Client == nil || r.emergencyLimits == nil {
+		return
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, r.emergencyLimitsKey(), configMap); err != nil {
+		if !apierrors.IsNotFound(err) {
+			r.Log.Error(err, "failed to read persisted emergency limits")
+		}
+		return
+	}
+	value := configMap.Annotations[emergencyLimitsAnnotation]
+	if value == "" {
+		return
+	}
+	var state emergencyLimitsState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		r.Log.Error(err, "failed to parse persisted emergency limits")
+		return
+	}
+	r.emergencyLimits.load(state)
+
+	var actions []string
+	if r.emergencyLimits.holdsAny(reducedLimitNames) {
+		actions = append(actions, config.EmergencyActionReduceLimits)
+	}
+	if r.emergencyLimits.holdsAny(throttledLimitNames) {
+		actions = append(actions, config.EmergencyActionThrottleIngestion)
+	}
+	if len(actions) == 0 {
+		return
+	}
+	r.BlastProtector.ResumeEmergencyActions("emergency limits held from before restart", actions)
+	r.Log.Info("restored emergency limits from before restart", "tenants", len(state.Held), "actions", actions)
+}
+
+// emergencyLimitValue converts a value to the Go type used for the limit type
+func emergencyLimitValue(value float64, limitType string) interface{} {
+	if limitType == "rate" {
+		return value
+	}
+	return int64(math.Floor(value))
+}
@@ -0,0 +1,167 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// emergencyTestConfig enables the limits of both emergency actions
+func emergencyTestConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	for _, name := range throttledLimitNames {
+		def := cfg.DynamicLimits.LimitDefinitions[name]
+		def.Enabled = true
+		cfg.DynamicLimits.LimitDefinitions[name] = def
+	}
+	return cfg
+}
+
+const emergencyOverrides = `overrides:
+  tenant-a:
+    ingestion_rate: 10000
+    max_global_series_per_user: 100000
+    request_rate: 5
+  tenant-b:
+    ingestion_rate: 20000
+`
+
+// runtimeOverridesConfigMap returns the runtime overrides ConfigMap of tc
+func (tc *testController) runtimeOverridesConfigMap(t *testing.T) *corev1.ConfigMap {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	if err := tc.client.Get(context.Background(), tc.emergencyLimitsKey(), configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	return configMap
+}
+
+func TestEmergencyLimitsRestoredAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	cfg := emergencyTestConfig()
+	tc := newTestController(cfg, overridesConfigMap(cfg, emergencyOverrides))
+	event := circuitbreaker.EmergencyEvent{Reason: "ingestion spike", Panic: true}
+	if err := tc.reduceLimitsAction(ctx, event); err != nil {
+		t.Fatalf("reduceLimitsAction: %v", err)
+	}
+	if err := tc.throttleIngestionAction(ctx, event); err != nil {
+		t.Fatalf("throttleIngestionAction: %v", err)
+	}
+
+	overrides := tc.tenantOverrides(t)
+	tenantA := overrides["tenant-a"].(map[string]interface{})
+	if rate, _ := config.NumericValue(tenantA["ingestion_rate"]); rate != 5000 {
+		t.Errorf("ingestion_rate of tenant-a during the emergency = %v, want it halved to 5000", rate)
+	}
+	if rate, _ := config.NumericValue(overrides["tenant-b"].(map[string]interface{})["request_rate"]); rate != 10 {
+		t.Errorf("request_rate of tenant-b during the emergency = %v, want the throttle of 10", rate)
+	}
+	configMap := tc.runtimeOverridesConfigMap(t)
+	if configMap.Annotations[emergencyLimitsAnnotation] == "" {
+		t.Fatalf("emergency limits are not persisted on the runtime overrides ConfigMap")
+	}
+
+	// The process restarts before the emergency ends
+	restarted := newTestController(cfg, configMap)
+	restarted.registerEmergencyActions()
+	restarted.loadEmergencyLimits(ctx)
+	if status := restarted.BlastProtector.GetProtectionStatus(); status["emergency_mode"] != true {
+		t.Fatalf("protection status after the restart = %v, want emergency mode resumed", status)
+	}
+	if !restarted.emergencyLimits.isHeld("tenant-a", "ingestion_rate") || !restarted.emergencyLimits.isHeld("tenant-b", "request_burst_size") {
+		t.Errorf("emergency limits are not held after the restart")
+	}
+
+	if err := restarted.BlastProtector.ExitEmergencyMode(); err != nil {
+		t.Fatalf("ExitEmergencyMode: %v", err)
+	}
+
+	overrides = restarted.tenantOverrides(t)
+	tenantA = overrides["tenant-a"].(map[string]interface{})
+	tenantB := overrides["tenant-b"].(map[string]interface{})
+	tests := []struct {
+		tenant string
+		limits map[string]interface{}
+		name   string
+		want   float64
+	}{
+		{"tenant-a", tenantA, "ingestion_rate", 10000},
+		{"tenant-a", tenantA, "max_global_series_per_user", 100000},
+		{"tenant-a", tenantA, "request_rate", 5},
+		{"tenant-b", tenantB, "ingestion_rate", 20000},
+	}
+	for _, tt := range tests {
+		if value, _ := config.NumericValue(tt.limits[tt.name]); value != tt.want {
+			t.Errorf("%s of %s after recovery = %v, want the original %v", tt.name, tt.tenant, value, tt.want)
+		}
+	}
+	// Limits the emergency actions added are removed again
+	for tenant, limits := range map[string]map[string]interface{}{"tenant-a": tenantA, "tenant-b": tenantB} {
+		if value, exists := limits["request_burst_size"]; exists {
+			t.Errorf("request_burst_size of %s after recovery = %v, want it removed", tenant, value)
+		}
+	}
+	if value, exists := tenantB["request_rate"]; exists {
+		t.Errorf("request_rate of tenant-b after recovery = %v, want it removed", value)
+	}
+	if value, exists := restarted.runtimeOverridesConfigMap(t).Annotations[emergencyLimitsAnnotation]; exists {
+		t.Errorf("emergency limits annotation after recovery = %q, want it removed", value)
+	}
+}
+
+func TestEmergencyLimitsNotPersistedLoadNothing(t *testing.T) {
+	cfg := emergencyTestConfig()
+	tc := newTestController(cfg, overridesConfigMap(cfg, emergencyOverrides))
+	tc.loadEmergencyLimits(context.Background())
+
+	if status := tc.BlastProtector.GetProtectionStatus(); status["emergency_mode"] != false {
+		t.Errorf("protection status without persisted emergency limits = %v, want no emergency mode", status)
+	}
+	if tc.emergencyLimits.holdsAny(reducedLimitNames) {
+		t.Errorf("emergency limits held without persisted ones")
+	}
+}
+
+func TestEmergencyRestoreDeferredByFreeze(t *testing.T) {
+	ctx := context.Background()
+	cfg := emergencyTestConfig()
+	tc := newTestController(cfg, overridesConfigMap(cfg, emergencyOverrides))
+	if err := tc.reduceLimitsAction(ctx, circuitbreaker.EmergencyEvent{Reason: "ingestion spike"}); err != nil {
+		t.Fatalf("reduceLimitsAction: %v", err)
+	}
+	ingestionRate := func() float64 {
+		rate, _ := config.NumericValue(tc.tenantOverrides(t)["tenant-a"].(map[string]interface{})["ingestion_rate"])
+		return rate
+	}
+
+	if _, err := tc.ActivateEmergencyFreeze(ctx, "incident-42", "sre@example.com", 0); err != nil {
+		t.Fatalf("ActivateEmergencyFreeze: %v", err)
+	}
+	if err := tc.restoreEmergencyLimits(ctx, reducedLimitNames); err == nil {
+		t.Errorf("restoreEmergencyLimits during a freeze succeeded, want it deferred")
+	}
+	if rate := ingestionRate(); rate != 5000 {
+		t.Errorf("ingestion_rate of tenant-a restored during the freeze to %v, want the emergency 5000", rate)
+	}
+	if !tc.emergencyLimits.isHeld("tenant-a", "ingestion_rate") {
+		t.Errorf("emergency limits released during the freeze")
+	}
+
+	// Lifting the freeze lets the deferred restore through
+	if _, err := tc.DeactivateEmergencyFreeze(ctx, "sre@example.com"); err != nil {
+		t.Fatalf("DeactivateEmergencyFreeze: %v", err)
+	}
+	tc.restoreDeferredEmergencyLimits(ctx)
+	if rate := ingestionRate(); rate != 10000 {
+		t.Errorf("ingestion_rate of tenant-a after the freeze = %v, want the original 10000", rate)
+	}
+	if tc.emergencyLimits.holdsAny(reducedLimitNames) {
+		t.Errorf("emergency limits still held after the deferred restore")
+	}
+}
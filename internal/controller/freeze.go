@@ -0,0 +1,278 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// emergencyStateConfigMapName persists the emergency freeze across restarts and replicas
+	emergencyStateConfigMapName = "mimir-optimizer-emergency-state"
+	emergencyStateDataKey       = "freeze.yaml"
+
+	// emergencyFreezeCheckInterval paces re-reading the freeze state, so freezes set
+	// through another replica are picked up and expired freezes are lifted promptly
+	emergencyFreezeCheckInterval = 30 * time.Second
+)
+
+// EmergencyFreeze halts all automated limit changes, e.g. while an incident is ongoing
+type EmergencyFreeze struct {
+	Reason      string     `json:"reason,omitempty"`
+	ActivatedBy string     `json:"activatedBy,omitempty"`
+	ActivatedAt time.Time  `json:"activatedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+// expired reports whether the freeze had a TTL that has passed
+func (f *EmergencyFreeze) expired(now time.Time) bool {
+	return f.ExpiresAt != nil && !now.Before(*f.ExpiresAt)
+}
+
+// GetEmergencyFreeze returns the active emergency freeze, or nil when limit changes are allowed
+func (r *MimirLimitController) GetEmergencyFreeze() *EmergencyFreeze {
+	r.freezeMu.RLock()
+	defer r.freezeMu.RUnlock()
+
+	if r.freeze == nil || r.freeze.expired(time.Now()) {
+		return nil
+	}
+	freeze := *r.freeze
+	return &freeze
+}
+
+// ActivateEmergencyFreeze halts all limit changes until the freeze is lifted or, when
+// ttl is positive, until it expires. The freeze is persisted so it survives restarts.
+func (r *MimirLimitController) ActivateEmergencyFreeze(ctx context.Context, reason, user string, ttl time.Duration) (*EmergencyFreeze, error) {
+	now := time.Now()
+	freeze := &EmergencyFreeze{
+		Reason:      reason,
+		ActivatedBy: user,
+		ActivatedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		freeze.ExpiresAt = &expiresAt
+	}
+
+	if r.KubeClient != nil {
+		if err := r.writeEmergencyState(ctx, freeze); err != nil {
+			return nil, err
+		}
+	}
+	r.setEmergencyFreeze(freeze)
+
+	r.Log.Info("emergency freeze activated, all limit changes are halted",
+		"reason", reason, "activated_by", user, "expires_at", freeze.ExpiresAt)
+	r.auditEmergencyFreeze("emergency-freeze", reason, user, freeze)
+	if manager := r.GetAlertManager(); manager != nil {
+		manager.SendAlert(alerting.CreateEmergencyFreezeAlert(user, reason, freeze.ExpiresAt))
+	}
+
+	return freeze, nil
+}
+
+// DeactivateEmergencyFreeze lifts the emergency freeze. It reports whether a freeze was active.
+func (r *MimirLimitController) DeactivateEmergencyFreeze(ctx context.Context, user string) (bool, error) {
+	freeze := r.GetEmergencyFreeze()
+
+	if r.KubeClient != nil {
+		err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Delete(ctx, emergencyStateConfigMapName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to delete emergency state ConfigMap: %w", err)
+		}
+	}
+	r.setEmergencyFreeze(nil)
+
+	if freeze == nil {
+		return false, nil
+	}
+
+	r.Log.Info("emergency freeze lifted, limit changes resume", "lifted_by", user)
+	r.auditEmergencyFreeze("emergency-unfreeze", "manual-unfreeze", user, freeze)
+	if manager := r.GetAlertManager(); manager != nil {
+		manager.SendAlert(alerting.CreateResolvedAlert(alerting.AlertTypeEmergencyFreeze, "",
+			fmt.Sprintf("Emergency freeze lifted by %s, automated limit changes resume", displayUser(user))))
+	}
+	return true, nil
+}
+
+// refreshEmergencyFreeze re-reads the persisted freeze state and lifts a freeze whose
+// TTL has passed. When the state cannot be read, the last known state is kept.
+func (r *MimirLimitController) refreshEmergencyFreeze(ctx context.Context) *EmergencyFreeze {
+	if r.KubeClient == nil {
+		r.expireEmergencyFreeze(ctx, nil)
+		return r.GetEmergencyFreeze()
+	}
+
+	configMap, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Get(ctx, emergencyStateConfigMapName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if r.GetEmergencyFreeze() != nil {
+			r.Log.Info("emergency freeze lifted by another replica")
+		}
+		r.setEmergencyFreeze(nil)
+	case err != nil:
+		r.Log.Error(err, "failed to read emergency state, keeping last known freeze state")
+	default:
+		freeze := &EmergencyFreeze{}
+		if err := yaml.Unmarshal([]byte(configMap.Data[emergencyStateDataKey]), freeze); err != nil {
+			r.Log.Error(err, "failed to parse emergency state, keeping last known freeze state")
+			break
+		}
+		r.setEmergencyFreeze(freeze)
+		r.expireEmergencyFreeze(ctx, configMap)
+	}
+
+	return r.GetEmergencyFreeze()
+}
+
+// expireEmergencyFreeze lifts the current freeze once its TTL has passed. When several
+// replicas notice the expiry, only the one whose delete succeeds sends the notification.
+func (r *MimirLimitController) expireEmergencyFreeze(ctx context.Context, configMap *corev1.ConfigMap) {
+	r.freezeMu.RLock()
+	freeze := r.freeze
+	r.freezeMu.RUnlock()
+	if freeze == nil || !freeze.expired(time.Now()) {
+		return
+	}
+
+	if configMap != nil {
+		resourceVersion := configMap.ResourceVersion
+		err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Delete(ctx, emergencyStateConfigMapName, metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &resourceVersion},
+		})
+		if err != nil {
+			if !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+				r.Log.Error(err, "failed to delete expired emergency state")
+			}
+			r.setEmergencyFreeze(nil)
+			return
+		}
+	}
+	r.setEmergencyFreeze(nil)
+
+	r.Log.Info("emergency freeze expired, limit changes resume", "expired_at", freeze.ExpiresAt)
+	r.auditEmergencyFreeze("emergency-unfreeze", "freeze-expired", "", freeze)
+	if manager := r.GetAlertManager(); manager != nil {
+		manager.SendAlert(alerting.CreateResolvedAlert(alerting.AlertTypeEmergencyFreeze, "",
+			fmt.Sprintf("Emergency freeze expired at %s, automated limit changes resume", freeze.ExpiresAt.Format(time.RFC3339))))
+	}
+}
+
+// startEmergencyFreezeWatch loads the persisted freeze state and keeps re-reading it
+func (r *MimirLimitController) startEmergencyFreezeWatch(ctx context.Context) {
+	if freeze := r.refreshEmergencyFreeze(ctx); freeze != nil {
+		r.Log.Info("emergency freeze active from before restart, limit changes are halted",
+			"reason", freeze.Reason, "activated_by", freeze.ActivatedBy, "expires_at", freeze.ExpiresAt)
+	}
+
+	go func() {
+		ticker := time.NewTicker(emergencyFreezeCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if r.refreshEmergencyFreeze(ctx) == nil && r.emergencyLimits != nil {
+					r.restoreDeferredEmergencyLimits(ctx)
+				}
+			}
+		}
+	}()
+}
+
+// writeEmergencyState persists the freeze to the emergency state ConfigMap
+func (r *MimirLimitController) writeEmergencyState(ctx context.Context, freeze *EmergencyFreeze) error {
+	data, err := yaml.Marshal(freeze)
+	if err != nil {
+		return fmt.Errorf("failed to marshal emergency state: %w", err)
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config()))
+	configMap, err := configMaps.Get(ctx, emergencyStateConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      emergencyStateConfigMapName,
+				Namespace: lockNamespace(r.config()),
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "mimir-limit-optimizer",
+					"app.kubernetes.io/component": "emergency-state",
+				},
+			},
+			Data: map[string]string{emergencyStateDataKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create emergency state ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get emergency state ConfigMap: %w", err)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[emergencyStateDataKey] = string(data)
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update emergency state ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// setEmergencyFreeze replaces the in-memory freeze state
+func (r *MimirLimitController) setEmergencyFreeze(freeze *EmergencyFreeze) {
+	r.freezeMu.Lock()
+	r.freeze = freeze
+	r.freezeMu.Unlock()
+
+	metrics.EmergencyMetricsInstance.SetEmergencyFreezeActive(freeze != nil && !freeze.expired(time.Now()))
+}
+
+// auditEmergencyFreeze records a freeze activation or lift in the audit trail
+func (r *MimirLimitController) auditEmergencyFreeze(action, reason, user string, freeze *EmergencyFreeze) {
+	if r.AuditLogger == nil {
+		return
+	}
+
+	changes := map[string]interface{}{
+		"freeze_reason": freeze.Reason,
+		"activated_by":  freeze.ActivatedBy,
+		"activated_at":  freeze.ActivatedAt,
+	}
+	if freeze.ExpiresAt != nil {
+		changes["expires_at"] = *freeze.ExpiresAt
+	}
+
+	entry := &auditlog.AuditEntry{
+		Action:  action,
+		Reason:  reason,
+		User:    user,
+		Changes: changes,
+		Success: true,
+	}
+	if err := r.AuditLogger.LogEntry(entry); err != nil {
+		r.Log.Error(err, "failed to log emergency freeze change", "action", action)
+	}
+}
+
+// displayUser names the requester in notifications
+func displayUser(user string) string {
+	if user == "" {
+		return "an operator"
+	}
+	return user
+}
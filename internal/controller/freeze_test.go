@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// runtimeOverrides returns the overrides YAML and resource version of the runtime
+// overrides ConfigMap
+func (tc *testController) runtimeOverrides(t *testing.T) (string, string) {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: tc.config().Mimir.ConfigMapName, Namespace: tc.config().Mimir.Namespace}
+	if err := tc.client.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	return configMap.Data["overrides.yaml"], configMap.ResourceVersion
+}
+
+func newFreezeTestController() *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	tc := newTestController(cfg, overridesConfigMap(cfg, "overrides:\n  tenant-a:\n    ingestion_rate: 1000\n"))
+	tc.collector.setMetrics(ingestionMetrics(50000, "tenant-a"))
+	return tc
+}
+
+func TestReconcileWritesNothingWhileFrozen(t *testing.T) {
+	tc := newFreezeTestController()
+	ctx := context.Background()
+	if _, err := tc.ActivateEmergencyFreeze(ctx, "incident-42", "sre@example.com", 0); err != nil {
+		t.Fatalf("ActivateEmergencyFreeze: %v", err)
+	}
+	overrides, resourceVersion := tc.runtimeOverrides(t)
+
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	gotOverrides, gotVersion := tc.runtimeOverrides(t)
+	if gotVersion != resourceVersion || gotOverrides != overrides {
+		t.Errorf("runtime overrides changed while frozen:\n%s", gotOverrides)
+	}
+	result, err := tc.GetReconcileResult(0)
+	if err != nil {
+		t.Fatalf("GetReconcileResult: %v", err)
+	}
+	if len(result.Tenants) != 1 || !hasReason(result.Tenants[0], ReconcileReasonEmergencyFreeze) {
+		t.Errorf("tenant outcomes = %+v, want tenant-a skipped for the emergency freeze", result.Tenants)
+	}
+
+	// Once lifted the next reconcile writes the calculated limits again
+	if _, err := tc.DeactivateEmergencyFreeze(ctx, "sre@example.com"); err != nil {
+		t.Fatalf("DeactivateEmergencyFreeze: %v", err)
+	}
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile after unfreeze: %v", err)
+	}
+	if _, gotVersion := tc.runtimeOverrides(t); gotVersion == resourceVersion {
+		t.Errorf("runtime overrides were not updated after the freeze was lifted")
+	}
+}
+
+func TestEmergencyFreezePersistedAcrossRestarts(t *testing.T) {
+	tc := newFreezeTestController()
+	ctx := context.Background()
+	if _, err := tc.ActivateEmergencyFreeze(ctx, "incident-42", "sre@example.com", time.Hour); err != nil {
+		t.Fatalf("ActivateEmergencyFreeze: %v", err)
+	}
+	if _, err := tc.KubeClient.CoreV1().ConfigMaps(lockNamespace(tc.config())).Get(ctx, emergencyStateConfigMapName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("emergency state ConfigMap: %v", err)
+	}
+
+	// A restarted replica reads the freeze from the emergency state ConfigMap
+	restarted := newFreezeTestController()
+	restarted.KubeClient = tc.KubeClient
+	freeze := restarted.refreshEmergencyFreeze(ctx)
+	if freeze == nil || freeze.Reason != "incident-42" || freeze.ExpiresAt == nil {
+		t.Fatalf("restored freeze = %+v, want the persisted freeze with its expiry", freeze)
+	}
+}
+
+func TestEmergencyFreezeExpires(t *testing.T) {
+	tc := newFreezeTestController()
+	ctx := context.Background()
+	if _, err := tc.ActivateEmergencyFreeze(ctx, "incident-42", "sre@example.com", 50*time.Millisecond); err != nil {
+		t.Fatalf("ActivateEmergencyFreeze: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if freeze := tc.refreshEmergencyFreeze(ctx); freeze != nil {
+		t.Fatalf("freeze = %+v after its TTL, want it lifted", freeze)
+	}
+	_, err := tc.KubeClient.CoreV1().ConfigMaps(lockNamespace(tc.config())).Get(ctx, emergencyStateConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		t.Errorf("emergency state ConfigMap was not deleted when the freeze expired")
+	}
+	if entries := tc.auditEntries(t, "emergency-unfreeze"); len(entries) != 1 || entries[0].Reason != "freeze-expired" {
+		t.Errorf("emergency-unfreeze audit entries = %d, want one for the expiry", len(entries))
+	}
+}
+
+// hasReason reports whether a tenant outcome lists reason
+func hasReason(outcome TenantReconcileOutcome, reason string) bool {
+	for _, r := range outcome.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Components whose health is tracked in the controller's health registry
+const (
+	ComponentCollector   = "collector"
+	ComponentAnalyzer    = "analyzer"
+	ComponentPatcher     = "patcher"
+	ComponentAuditLogger = "audit_logger"
+	ComponentAlerting    = "alerting"
+)
+
+// ComponentHealth is the health of a subsystem as of its last operation
+type ComponentHealth struct {
+	OK                  bool       `json:"ok"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorTime       *time.Time `json:"last_error_time,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// HealthRegistry records the outcome of each subsystem's operations. A component that
+// has not reported yet is healthy. The methods of a nil registry do nothing.
+type HealthRegistry struct {
+	mu         sync.RWMutex
+	components map[string]*ComponentHealth
+}
+
+// NewHealthRegistry creates a health registry reporting the given components as healthy
+// until they record an outcome
+func NewHealthRegistry(components ...string) *HealthRegistry {
+	h := &HealthRegistry{components: make(map[string]*ComponentHealth)}
+	for _, component := range components {
+		h.components[component] = &ComponentHealth{OK: true}
+	}
+	return h
+}
+
+// RecordSuccess marks a component healthy and resets its consecutive failures
+func (h *HealthRegistry) RecordSuccess(component string) {
+	if h == nil {
+		return
+	}
+	now := time.Now()
+
+	h.mu.Lock()
+	health := h.component(component)
+	health.OK = true
+	health.LastSuccess = &now
+	health.ConsecutiveFailures = 0
+	h.mu.Unlock()
+
+	metrics.HealthMetricsInstance.SetHealthStatus(component, 1)
+}
+
+// RecordFailure marks a component unhealthy with the error of its last operation
+func (h *HealthRegistry) RecordFailure(component string, err error) {
+	if h == nil {
+		return
+	}
+	now := time.Now()
+
+	h.mu.Lock()
+	health := h.component(component)
+	health.OK = false
+	if err != nil {
+		health.LastError = err.Error()
+	}
+	health.LastErrorTime = &now
+	health.ConsecutiveFailures++
+	h.mu.Unlock()
+
+	metrics.HealthMetricsInstance.SetHealthStatus(component, 0)
+}
+
+// Record records the outcome of an operation: a success when err is nil
+func (h *HealthRegistry) Record(component string, err error) {
+	if err != nil {
+		h.RecordFailure(component, err)
+		return
+	}
+	h.RecordSuccess(component)
+}
+
+// Get returns the health of a component
+func (h *HealthRegistry) Get(component string) (ComponentHealth, bool) {
+	if h == nil {
+		return ComponentHealth{}, false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	health, exists := h.components[component]
+	if !exists {
+		return ComponentHealth{}, false
+	}
+	return *health, true
+}
+
+// Snapshot returns a copy of the health of every component
+func (h *HealthRegistry) Snapshot() map[string]ComponentHealth {
+	snapshot := make(map[string]ComponentHealth)
+	if h == nil {
+		return snapshot
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for component, health := range h.components {
+		snapshot[component] = *health
+	}
+	return snapshot
+}
+
+// component returns the record of a component, creating it; callers must hold h.mu
+func (h *HealthRegistry) component(component string) *ComponentHealth {
+	health, exists := h.components[component]
+	if !exists {
+		health = &ComponentHealth{OK: true}
+		h.components[component] = health
+	}
+	return health
+}
+
+// ReadyzCheck fails in prod mode once the patcher has failed to apply limits
+// mimir.readinessFailureThreshold times in a row, as the optimizer is then no longer
+// doing its job
+func (r *MimirLimitController) ReadyzCheck(_ *http.Request) error {
+	threshold := r.config().Mimir.ReadinessFailureThreshold
+	if r.config().Mode != "prod" || threshold <= 0 {
+		return nil
+	}
+	health, exists := r.health.Get(ComponentPatcher)
+	if !exists || health.ConsecutiveFailures < threshold {
+		return nil
+	}
+	return fmt.Errorf("limits failed to apply %d consecutive times, last error: %s",
+		health.ConsecutiveFailures, health.LastError)
+}
+
+// healthAuditLogger records the outcome of audit log writes and purges in the health
+// registry
+type healthAuditLogger struct {
+	auditlog.AuditLogger
+	health *HealthRegistry
+}
+
+// LogEntry logs an entry and records whether the audit store accepted it
+func (l *healthAuditLogger) LogEntry(entry *auditlog.AuditEntry) error {
+	err := l.AuditLogger.LogEntry(entry)
+	l.health.Record(ComponentAuditLogger, err)
+	return err
+}
+
+// PurgeOldEntries purges old entries and records whether the audit store allowed it
+func (l *healthAuditLogger) PurgeOldEntries(ctx context.Context, olderThan time.Time) error {
+	err := l.AuditLogger.PurgeOldEntries(ctx, olderThan)
+	l.health.Record(ComponentAuditLogger, err)
+	return err
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+)
+
+// ErrEmergencyFreezeActive is returned by writes outside a reconciliation while an
+// emergency freeze halts all ConfigMap writes
+var ErrEmergencyFreezeActive = errors.New("emergency freeze active, limit changes are halted")
+
+// Reason codes of the tenants left out of an import
+const (
+	ImportReasonExists          = "exists"
+	ImportReasonNoEnabledLimits = "no_enabled_limits"
+)
+
+// LimitImportError is an imported limit that is not valid for the limit catalog
+type LimitImportError struct {
+	Tenant  string      `json:"tenant"`
+	Limit   string      `json:"limit"`
+	Value   interface{} `json:"value,omitempty"`
+	Message string      `json:"message"`
+}
+
+// LimitImportResult summarizes an import of tenant limits
+type LimitImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	// Errors is the number of tenants not imported because of invalid limits
+	Errors       int                `json:"errors"`
+	ErrorDetails []LimitImportError `json:"error_details"`
+	// ImportedTenants are the tenants whose limits were written, by tenant
+	ImportedTenants []string `json:"imported_tenants"`
+	// SkippedTenants maps the tenants left out to the reason code: exists,
+	// no_enabled_limits, tenant_scoping or paused
+	SkippedTenants map[string]string `json:"skipped_tenants"`
+	// IgnoredLimits are the disabled limits found in the document, which the optimizer
+	// does not write
+	IgnoredLimits []string `json:"ignored_limits,omitempty"`
+}
+
+// ImportLimits writes the limits of an existing runtime overrides document to the
+// runtime overrides ConfigMap, for seeding the optimizer with manually managed limits.
+// Each tenant's limits are validated against the limit catalog, and a tenant with an
+// invalid limit is not imported. Imported values replace the current values of the
+// same limits; with overwrite false, tenants already in the ConfigMap are skipped.
+// Disabled limits, tenants excluded by tenant scoping and paused tenants are left out,
+// as in a reconciliation.
+func (r *MimirLimitController) ImportLimits(ctx context.Context, overrides map[string]map[string]interface{}, overwrite bool, user string) (*LimitImportResult, error) {
+	if r.Patcher == nil {
+		return nil, fmt.Errorf("controller not initialized")
+	}
+	if r.GetEmergencyFreeze() != nil {
+		return nil, ErrEmergencyFreezeActive
+	}
+
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+
+	var current map[string]*analyzer.TenantLimits
+	if !overwrite {
+		var err error
+		if current, err = r.Patcher.GetCurrentLimits(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read current limits: %w", err)
+		}
+	}
+
+	result := &LimitImportResult{
+		ErrorDetails:    []LimitImportError{},
+		ImportedTenants: []string{},
+		SkippedTenants:  make(map[string]string),
+	}
+	filter := r.GetTenantFilter()
+	ignored := make(map[string]bool)
+	imported := make(map[string]*analyzer.TenantLimits)
+	now := time.Now()
+
+	tenants := make([]string, 0, len(overrides))
+	for tenant := range overrides {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		limits := make(map[string]interface{}, len(overrides[tenant]))
+		for limitName, value := range overrides[tenant] {
+			// Metadata written by the optimizer is commented out
+			if !strings.HasPrefix(limitName, "#") {
+				limits[limitName] = value
+			}
+		}
+
+		if errs := r.config().ValidateLimitSet(limits); len(errs) > 0 {
+			result.Errors++
+			for _, err := range errs {
+				result.ErrorDetails = append(result.ErrorDetails, LimitImportError{
+					Tenant:  tenant,
+					Limit:   err.LimitName,
+					Value:   err.ProvidedValue,
+					Message: err.Message,
+				})
+			}
+			continue
+		}
+
+		switch {
+		case current[tenant] != nil:
+			result.SkippedTenants[tenant] = ImportReasonExists
+			continue
+		case !filter.ShouldProcessTenant(tenant):
+			result.SkippedTenants[tenant] = ReconcileReasonTenantScoping
+			continue
+		case filter.IsPaused(tenant):
+			result.SkippedTenants[tenant] = ReconcileReasonPaused
+			continue
+		}
+
+		for limitName := range limits {
+			if !r.config().DynamicLimits.LimitDefinitions[limitName].Enabled {
+				ignored[limitName] = true
+				delete(limits, limitName)
+			}
+		}
+		if len(limits) == 0 {
+			result.SkippedTenants[tenant] = ImportReasonNoEnabledLimits
+			continue
+		}
+
+		imported[tenant] = &analyzer.TenantLimits{
+			Tenant:      tenant,
+			Limits:      limits,
+			LastUpdated: now,
+			Reason:      "import",
+			Source:      "api",
+		}
+	}
+	for limitName := range ignored {
+		result.IgnoredLimits = append(result.IgnoredLimits, limitName)
+	}
+	sort.Strings(result.IgnoredLimits)
+	result.Skipped = len(result.SkippedTenants)
+
+	if len(imported) > 0 {
+		if r.WriteLock != nil {
+			if !r.acquireWriteLock(ctx) {
+				return nil, fmt.Errorf("ConfigMap write lock held by another replica")
+			}
+			defer r.releaseWriteLock()
+		}
+		if err := r.Patcher.ApplyLimits(ctx, imported); err != nil {
+			return nil, fmt.Errorf("failed to write imported limits: %w", err)
+		}
+		for tenant, tenantLimits := range imported {
+			r.setManagedTenant(tenantLimits)
+			result.ImportedTenants = append(result.ImportedTenants, tenant)
+		}
+		sort.Strings(result.ImportedTenants)
+	}
+	result.Imported = len(result.ImportedTenants)
+
+	r.Log.Info("imported tenant limits", "imported", result.Imported, "skipped", result.Skipped,
+		"errors", result.Errors, "overwrite", overwrite, "user", user)
+
+	if r.AuditLogger != nil {
+		entry := &auditlog.AuditEntry{
+			Action: "limits-import",
+			Reason: "bulk-import",
+			Source: "api",
+			User:   user,
+			Changes: map[string]interface{}{
+				"imported_tenants": result.ImportedTenants,
+				"skipped_tenants":  result.SkippedTenants,
+				"failed_tenants":   result.Errors,
+				"overwrite":        overwrite,
+			},
+			Success: true,
+		}
+		if err := r.AuditLogger.LogEntry(entry); err != nil {
+			r.Log.Error(err, "failed to log limit import")
+		}
+	}
+
+	return result, nil
+}
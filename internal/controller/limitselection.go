@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// limitSelectionConfigMapName persists the limits enabled through the API across
+	// restarts and replicas
+	limitSelectionConfigMapName = "mimir-limit-optimizer-limit-selection"
+	limitSelectionDataKey       = "limits.yaml"
+)
+
+// limitSelection is the persisted form of the limits enabled at runtime. It replaces
+// limits.enabledLimits and limits.disabledLimits of the configuration file.
+type limitSelection struct {
+	EnabledLimits  []string  `json:"enabledLimits"`
+	DisabledLimits []string  `json:"disabledLimits"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	UpdatedBy      string    `json:"updatedBy,omitempty"`
+}
+
+// LimitSelectionChange describes a runtime change of the optimized limits
+type LimitSelectionChange struct {
+	EnabledLimits []string `json:"enabled_limits"`
+	NewlyEnabled  []string `json:"newly_enabled"`
+	NewlyDisabled []string `json:"newly_disabled"`
+	Persisted     bool     `json:"persisted"`
+}
+
+// SetEnabledLimits makes the given limits the only ones optimized, disabling every other
+// limit of the catalog. The selection is persisted to the limit selection ConfigMap,
+// survives configuration reloads and is audited with the limits enabled and disabled.
+func (r *MimirLimitController) SetEnabledLimits(ctx context.Context, enabled []string, user string) (*LimitSelectionChange, error) {
+	if errs := r.config().ValidateLimitNames("enabled_limits", enabled); len(errs) > 0 {
+		return nil, errs
+	}
+
+	enabledSet := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		enabledSet[name] = true
+	}
+	selection := &limitSelection{UpdatedAt: time.Now(), UpdatedBy: user}
+	for name := range r.config().LimitCatalog() {
+		if enabledSet[name] {
+			selection.EnabledLimits = append(selection.EnabledLimits, name)
+		} else {
+			selection.DisabledLimits = append(selection.DisabledLimits, name)
+		}
+	}
+	sort.Strings(selection.EnabledLimits)
+	sort.Strings(selection.DisabledLimits)
+
+	persisted := false
+	if r.KubeClient != nil {
+		if err := r.writeLimitSelection(ctx, selection); err != nil {
+			return nil, err
+		}
+		persisted = true
+	} else {
+		r.Log.Info("no Kubernetes client, limit selection change is not persisted")
+	}
+
+	before := r.config().EnabledLimitNames()
+	r.applyLimitSelection(selection)
+	after := r.config().EnabledLimitNames()
+
+	change := &LimitSelectionChange{
+		EnabledLimits: after,
+		NewlyEnabled:  setDifference(after, before),
+		NewlyDisabled: setDifference(before, after),
+		Persisted:     persisted,
+	}
+	r.Log.Info("enabled limits changed", "newly_enabled", change.NewlyEnabled,
+		"newly_disabled", change.NewlyDisabled, "user", user)
+
+	if r.AuditLogger != nil {
+		entry := &auditlog.AuditEntry{
+			Action: "limit-selection-change",
+			Reason: "enabled-limits-updated",
+			Source: "api",
+			User:   user,
+			Changes: map[string]interface{}{
+				"enabled":  change.NewlyEnabled,
+				"disabled": change.NewlyDisabled,
+			},
+			OldValues: map[string]interface{}{"enabled_limits": before},
+			NewValues: map[string]interface{}{"enabled_limits": after},
+			Success:   true,
+		}
+		if err := r.AuditLogger.LogEntry(entry); err != nil {
+			r.Log.Error(err, "failed to log limit selection change")
+		}
+	}
+
+	return change, nil
+}
+
+// applyLimitSelection makes a limit selection active, or the selection of the
+// configuration file for nil, waiting for a running reconciliation to finish
+func (r *MimirLimitController) applyLimitSelection(selection *limitSelection) {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	r.limitSelection = selection
+	before := r.config()
+	r.deleteDisabledLimitSeries(before, r.Config.Update(r.mergeLimitSelection))
+}
+
+// mergeLimitSelection merges the runtime limit selection over the configured one; the
+// caller holds configMu
+func (r *MimirLimitController) mergeLimitSelection(cfg *config.Config) {
+	if r.limitSelection == nil {
+		return
+	}
+	cfg.Limits.EnabledLimits = r.limitSelection.EnabledLimits
+	cfg.Limits.DisabledLimits = r.limitSelection.DisabledLimits
+	cfg.ApplyLimitSelection()
+}
+
+// deleteDisabledLimitSeries stops exporting the current limits of the limits enabled in
+// the previous configuration but not in the next one
+func (r *MimirLimitController) deleteDisabledLimitSeries(previous, next *config.Config) {
+	for _, limitName := range setDifference(previous.EnabledLimitNames(), next.EnabledLimitNames()) {
+		metrics.TenantMetricsInstance.DeleteTenantLimitSeries(limitName)
+	}
+}
+
+// refreshLimitSelection re-reads the persisted limit selection, so selections made
+// before a restart or through another replica apply. When it cannot be read, the
+// current selection is kept.
+func (r *MimirLimitController) refreshLimitSelection(ctx context.Context) {
+	if r.KubeClient == nil {
+		return
+	}
+
+	configMap, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Get(ctx, limitSelectionConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		r.Log.Error(err, "failed to read limit selection, keeping the current enabled limits")
+		return
+	}
+
+	selection := &limitSelection{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[limitSelectionDataKey]), selection); err != nil {
+		r.Log.Error(err, "failed to parse limit selection, keeping the current enabled limits")
+		return
+	}
+	if errs := r.config().ValidateLimitNames("enabledLimits", selection.EnabledLimits); len(errs) > 0 {
+		metrics.HealthMetricsInstance.IncErrorTotal("controller", "limit-selection")
+		r.Log.Error(errs, "limit selection ConfigMap contains unknown limits, keeping the current enabled limits")
+		return
+	}
+
+	r.configMu.RLock()
+	current := r.limitSelection
+	r.configMu.RUnlock()
+	if current != nil && reflect.DeepEqual(current.EnabledLimits, selection.EnabledLimits) &&
+		reflect.DeepEqual(current.DisabledLimits, selection.DisabledLimits) {
+		return
+	}
+
+	r.applyLimitSelection(selection)
+	r.Log.Info("applied limit selection from ConfigMap", "enabled_limits", selection.EnabledLimits,
+		"updated_by", selection.UpdatedBy)
+}
+
+// writeLimitSelection persists a limit selection to the limit selection ConfigMap
+func (r *MimirLimitController) writeLimitSelection(ctx context.Context, selection *limitSelection) error {
+	data, err := yaml.Marshal(selection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal limit selection: %w", err)
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config()))
+	configMap, err := configMaps.Get(ctx, limitSelectionConfigMapName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      limitSelectionConfigMapName,
+				Namespace: lockNamespace(r.config()),
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "mimir-limit-optimizer",
+					"app.kubernetes.io/component": "limit-selection",
+				},
+			},
+			Data: map[string]string{limitSelectionDataKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create limit selection ConfigMap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get limit selection ConfigMap: %w", err)
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[limitSelectionDataKey] = string(data)
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update limit selection ConfigMap: %w", err)
+	}
+	return nil
+}
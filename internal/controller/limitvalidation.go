@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// withValidLimits returns the limits without the values that fail validation against
+// the limit definitions, so an invalid value never reaches the runtime overrides.
+// Tenants keep their valid limits; a tenant left without any limit is dropped.
+func (r *MimirLimitController) withValidLimits(limits map[string]*analyzer.TenantLimits) map[string]*analyzer.TenantLimits {
+	validated := make(map[string]*analyzer.TenantLimits, len(limits))
+	for tenant, tenantLimits := range limits {
+		errs := r.config().ValidateLimitSet(tenantLimits.Limits)
+		if len(errs) == 0 {
+			validated[tenant] = tenantLimits
+			continue
+		}
+
+		valid := make(map[string]interface{}, len(tenantLimits.Limits))
+		for name, value := range tenantLimits.Limits {
+			valid[name] = value
+		}
+		for _, err := range errs {
+			delete(valid, err.LimitName)
+			metrics.HealthMetricsInstance.IncErrorTotal("validation", "invalid-limit")
+			r.Log.Info("dropping invalid limit value before ConfigMap write",
+				"tenant", tenant,
+				"limit", err.LimitName,
+				"value", err.ProvidedValue,
+				"expected_type", err.ExpectedType,
+				"reason", err.Message)
+		}
+		if len(valid) == 0 {
+			continue
+		}
+
+		copied := *tenantLimits
+		copied.Limits = valid
+		validated[tenant] = &copied
+	}
+	return validated
+}
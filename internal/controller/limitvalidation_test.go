@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func TestWithValidLimitsDropsInvalidValues(t *testing.T) {
+	tc := newTestController(config.GetDefaultConfig())
+	limits := map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{
+			"ingestion_rate":     20000,
+			"max_query_lookback": "forever",
+		}},
+		"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"unknown_limit": 1}},
+		"tenant-c": {Tenant: "tenant-c", Limits: map[string]interface{}{"ingestion_rate": 30000}},
+	}
+
+	validated := tc.withValidLimits(limits)
+
+	if got := validated["tenant-a"].Limits; !reflect.DeepEqual(got, map[string]interface{}{"ingestion_rate": 20000}) {
+		t.Errorf("tenant-a limits = %v, want only the valid ingestion_rate", got)
+	}
+	if _, kept := validated["tenant-b"]; kept {
+		t.Errorf("tenant-b without any valid limit was kept")
+	}
+	if validated["tenant-c"] != limits["tenant-c"] {
+		t.Errorf("tenant-c with only valid limits was copied")
+	}
+	if _, exists := limits["tenant-a"].Limits["max_query_lookback"]; !exists {
+		t.Errorf("withValidLimits modified the calculated limits")
+	}
+}
+
+func TestApplyLimitsNeverWritesInvalidValues(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	tc := newTestController(cfg, overridesConfigMap(cfg, "overrides: {}\n"))
+
+	applied, err := tc.applyLimits(context.Background(), map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{
+			"ingestion_rate":               20000,
+			"cardinality_analysis_enabled": "maybe",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("applyLimits: %v", err)
+	}
+	if _, invalid := applied["tenant-a"].Limits["cardinality_analysis_enabled"]; invalid {
+		t.Errorf("applied limits include the invalid value")
+	}
+
+	written, _ := tc.tenantOverrides(t)["tenant-a"].(map[string]interface{})
+	if _, invalid := written["cardinality_analysis_enabled"]; invalid || written["ingestion_rate"] == nil {
+		t.Errorf("written overrides = %v, want ingestion_rate without the invalid value", written)
+	}
+}
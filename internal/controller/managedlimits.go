@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+)
+
+// recordManagedLimits keeps the limits a reconciliation applied as the limits the
+// optimizer manages. Each TenantLimits is copied, so later changes to the reconciled
+// maps do not leak into the recorded view.
+func (r *MimirLimitController) recordManagedLimits(limits map[string]*analyzer.TenantLimits) {
+	managed := copyTenantLimits(limits)
+
+	r.managedMu.Lock()
+	r.managedLimits = managed
+	r.managedMu.Unlock()
+}
+
+// setManagedTenant replaces the managed limits of a tenant written outside a
+// reconciliation
+func (r *MimirLimitController) setManagedTenant(tenantLimits *analyzer.TenantLimits) {
+	copied := copyTenantLimits(map[string]*analyzer.TenantLimits{tenantLimits.Tenant: tenantLimits})
+
+	r.managedMu.Lock()
+	defer r.managedMu.Unlock()
+	if r.managedLimits != nil {
+		r.managedLimits[tenantLimits.Tenant] = copied[tenantLimits.Tenant]
+	}
+}
+
+// forgetManagedTenants drops tenants whose limits were removed from the managed view
+func (r *MimirLimitController) forgetManagedTenants(tenants []string) {
+	r.managedMu.Lock()
+	defer r.managedMu.Unlock()
+	for _, tenant := range tenants {
+		delete(r.managedLimits, tenant)
+	}
+}
+
+// GetManagedLimits returns the limits the optimizer currently manages per tenant: those
+// the last reconciliation applied. Before the first reconciliation has applied limits
+// they are read from the runtime overrides ConfigMap. The result is a copy the caller
+// may modify.
+func (r *MimirLimitController) GetManagedLimits(ctx context.Context) (map[string]*analyzer.TenantLimits, error) {
+	r.managedMu.RLock()
+	managed := r.managedLimits
+	r.managedMu.RUnlock()
+	if managed != nil {
+		return copyTenantLimits(managed), nil
+	}
+
+	if r.Patcher == nil {
+		return nil, fmt.Errorf("patcher not initialized")
+	}
+	current, err := r.Patcher.GetCurrentLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ConfigMap limits: %w", err)
+	}
+	return current, nil
+}
+
+// copyTenantLimits copies the limits of each tenant
+func copyTenantLimits(limits map[string]*analyzer.TenantLimits) map[string]*analyzer.TenantLimits {
+	copied := make(map[string]*analyzer.TenantLimits, len(limits))
+	for tenant, tenantLimits := range limits {
+		if tenantLimits == nil {
+			continue
+		}
+		tenantCopy := *tenantLimits
+		tenantCopy.Limits = make(map[string]interface{}, len(tenantLimits.Limits))
+		for limitName, value := range tenantLimits.Limits {
+			tenantCopy.Limits[limitName] = value
+		}
+		copied[tenant] = &tenantCopy
+	}
+	return copied
+}
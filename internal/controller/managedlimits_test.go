@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func newManagedLimitsTestController() *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	return newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+}
+
+func TestGetManagedLimitsReadsConfigMapBeforeFirstReconcile(t *testing.T) {
+	tc := newManagedLimitsTestController()
+
+	managed, err := tc.GetManagedLimits(context.Background())
+	if err != nil {
+		t.Fatalf("GetManagedLimits: %v", err)
+	}
+	if len(managed) != 2 || ingestionRate(managed, "tenant-a") != 10000 || ingestionRate(managed, "tenant-b") != 5000 {
+		t.Errorf("managed limits before a reconcile = %d tenants, tenant-a at %v and tenant-b at %v; want the ConfigMap's",
+			len(managed), ingestionRate(managed, "tenant-a"), ingestionRate(managed, "tenant-b"))
+	}
+}
+
+func TestGetManagedLimitsReturnsLastReconcile(t *testing.T) {
+	tc := newManagedLimitsTestController()
+	ctx := context.Background()
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a"))
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	written, _ := config.NumericValue(tc.tenantOverrides(t)["tenant-a"].(map[string]interface{})["ingestion_rate"])
+	if written == 0 {
+		t.Fatalf("reconcile wrote no ingestion_rate for tenant-a")
+	}
+
+	// Changes to the ConfigMap after the reconcile are not the optimizer's view
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: tc.config().Mimir.ConfigMapName, Namespace: tc.config().Mimir.Namespace}
+	if err := tc.client.Get(ctx, key, configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	configMap.Data["overrides.yaml"] = "overrides:\n  tenant-a:\n    ingestion_rate: 1\n"
+	if err := tc.client.Update(ctx, configMap); err != nil {
+		t.Fatalf("update runtime overrides ConfigMap: %v", err)
+	}
+
+	managed, err := tc.GetManagedLimits(ctx)
+	if err != nil {
+		t.Fatalf("GetManagedLimits: %v", err)
+	}
+	if len(managed) != 1 || managed["tenant-a"] == nil {
+		t.Fatalf("managed limits = %v, want the reconciled tenant-a only", managed)
+	}
+	if got := ingestionRate(managed, "tenant-a"); got != written {
+		t.Errorf("managed tenant-a ingestion_rate = %v, want the %v the reconcile wrote", got, written)
+	}
+
+	// The result is a copy
+	managed["tenant-a"].Limits["ingestion_rate"] = 0.0
+	delete(managed, "tenant-a")
+	again, _ := tc.GetManagedLimits(ctx)
+	if got := ingestionRate(again, "tenant-a"); got != written {
+		t.Errorf("managed tenant-a ingestion_rate = %v after changing a returned copy, want %v", got, written)
+	}
+}
+
+func TestManagedTenantUpdates(t *testing.T) {
+	tc := newManagedLimitsTestController()
+	tc.recordManagedLimits(map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 10000.0}},
+		"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"ingestion_rate": 5000.0}},
+	})
+
+	imported := &analyzer.TenantLimits{Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 30000.0}}
+	tc.setManagedTenant(imported)
+	imported.Limits["ingestion_rate"] = 1.0
+	tc.forgetManagedTenants([]string{"tenant-b"})
+
+	managed, err := tc.GetManagedLimits(context.Background())
+	if err != nil {
+		t.Fatalf("GetManagedLimits: %v", err)
+	}
+	if len(managed) != 1 || ingestionRate(managed, "tenant-a") != 30000 {
+		t.Errorf("managed limits = %d tenants with tenant-a at %v, want tenant-a at the set 30000 and tenant-b forgotten",
+			len(managed), ingestionRate(managed, "tenant-a"))
+	}
+}
+
+// ingestionRate returns the ingestion_rate of a tenant, 0 when it has none
+func ingestionRate(limits map[string]*analyzer.TenantLimits, tenant string) float64 {
+	if limits[tenant] == nil {
+		return 0
+	}
+	rate, _ := config.NumericValue(limits[tenant].Limits["ingestion_rate"])
+	return rate
+}
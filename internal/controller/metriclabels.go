@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// updateTenantMetricLabels sets the values of metrics.extraLabels of the monitored
+// tenants on the tenant metrics: the metricLabels of each tenant's tier, overridden by
+// the mimir.io/metric-label-<name> annotations of its namespace when namespace
+// annotations are enabled
+func (r *MimirLimitController) updateTenantMetricLabels(ctx context.Context, tenants []string) {
+	labelNames := r.config().Metrics.ExtraLabels
+	if len(labelNames) == 0 {
+		return
+	}
+
+	var annotated map[string]map[string]string
+	if r.config().MetricsDiscovery.TenantDiscovery.NamespaceAnnotations && r.KubeClient != nil {
+		var err error
+		annotated, err = collector.CollectNamespaceMetricLabels(ctx, r.KubeClient, r.config(), labelNames, r.Log.WithName("namespace-annotations"))
+		if err != nil {
+			metrics.HealthMetricsInstance.IncErrorTotal("collector", "namespace-annotations")
+			r.Log.Error(err, "failed to read metric labels from namespace annotations")
+		}
+	}
+
+	values := make(map[string]map[string]string, len(tenants))
+	for _, tenant := range tenants {
+		tenantValues := make(map[string]string, len(labelNames))
+		if tier, exists := r.config().Limits.TenantTiers[r.config().Limits.TierForTenant(tenant).Tier]; exists {
+			for label, value := range tier.MetricLabels {
+				tenantValues[label] = value
+			}
+		}
+		for label, value := range annotated[tenant] {
+			tenantValues[label] = value
+		}
+		values[tenant] = tenantValues
+	}
+
+	r.metricLabelsMu.Lock()
+	r.metricLabels = values
+	r.metricLabelsMu.Unlock()
+	metrics.TenantMetricsInstance.SetTenantMetricLabels(values)
+}
+
+// TenantMetricLabels returns the values of metrics.extraLabels exported on the metrics
+// of a tenant, nil when none are set
+func (r *MimirLimitController) TenantMetricLabels(tenant string) map[string]string {
+	r.metricLabelsMu.RLock()
+	defer r.metricLabelsMu.RUnlock()
+
+	values := r.metricLabels[tenant]
+	if len(values) == 0 {
+		return nil
+	}
+	copied := make(map[string]string, len(values))
+	for label, value := range values {
+		copied[label] = value
+	}
+	return copied
+}
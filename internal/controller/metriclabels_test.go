@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func TestTenantMetricLabelsFromTiersAndAnnotations(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Metrics.ExtraLabels = []string{"env", "sla"}
+	cfg.Limits.TenantTiers = map[string]config.TenantTierConfig{
+		"gold": {BufferPercentage: 30, Tenants: []string{"prod-*"}, MetricLabels: map[string]string{"env": "prod", "sla": "gold"}},
+	}
+	cfg.MetricsDiscovery.TenantDiscovery.NamespaceAnnotations = true
+	tc := newTestController(cfg)
+	tc.KubeClient = kubefake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "prod-payments",
+		Annotations: map[string]string{"mimir.io/metric-label-env": "prod-eu"},
+	}})
+
+	tc.updateTenantMetricLabels(context.Background(), []string{"prod-payments", "prod-search", "dev-sandbox"})
+
+	tests := []struct {
+		tenant string
+		want   map[string]string
+	}{
+		// The namespace annotation overrides the tier's value
+		{"prod-payments", map[string]string{"env": "prod-eu", "sla": "gold"}},
+		{"prod-search", map[string]string{"env": "prod", "sla": "gold"}},
+		{"dev-sandbox", nil},
+	}
+	for _, tt := range tests {
+		if got := tc.TenantMetricLabels(tt.tenant); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("metric labels of %s = %v, want %v", tt.tenant, got, tt.want)
+		}
+	}
+}
+
+func TestTenantMetricLabelsWithoutExtraLabels(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Limits.TenantTiers = map[string]config.TenantTierConfig{
+		"gold": {BufferPercentage: 30, Tenants: []string{"prod-*"}},
+	}
+	tc := newTestController(cfg)
+
+	tc.updateTenantMetricLabels(context.Background(), []string{"prod-payments"})
+	if got := tc.TenantMetricLabels("prod-payments"); got != nil {
+		t.Errorf("metric labels without metrics.extraLabels = %v, want none", got)
+	}
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// TenantError is the failure of one tenant in a reconcile
+type TenantError struct {
+	Tenant string
+	Err    error
+
+	// Transient errors, such as timeouts and API server throttling, are likely to
+	// succeed when retried; permanent errors are logged and the tenant is skipped
+	Transient bool
+}
+
+// Error formats the error as "tenant: error"
+func (e *TenantError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Tenant, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TenantError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects the per-tenant errors of a reconcile, so the other tenants are
+// still processed when one of them fails
+type MultiError struct {
+	Errors []*TenantError
+}
+
+// Add records the error of a tenant, classifying it as transient or permanent, and
+// counts it in mimir_limit_optimizer_reconcile_tenant_errors_total. Nil errors are
+// ignored.
+func (m *MultiError) Add(tenant string, err error) {
+	if err == nil {
+		return
+	}
+	m.Errors = append(m.Errors, &TenantError{Tenant: tenant, Err: err, Transient: IsTransientError(err)})
+	metrics.ReconcileMetricsInstance.IncTenantErrors(tenant)
+}
+
+// Len returns the number of tenant errors
+func (m *MultiError) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Errors)
+}
+
+// Error formats the errors sorted by tenant as "N errors: tenant-a: ...; tenant-b: ..."
+func (m *MultiError) Error() string {
+	tenantErrors := append([]*TenantError(nil), m.Errors...)
+	sort.SliceStable(tenantErrors, func(i, j int) bool {
+		return tenantErrors[i].Tenant < tenantErrors[j].Tenant
+	})
+
+	messages := make([]string, 0, len(tenantErrors))
+	for _, tenantErr := range tenantErrors {
+		messages = append(messages, tenantErr.Error())
+	}
+	noun := "errors"
+	if len(messages) == 1 {
+		noun = "error"
+	}
+	return fmt.Sprintf("%d %s: %s", len(messages), noun, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the tenant errors for errors.Is and errors.As
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(m.Errors))
+	for _, tenantErr := range m.Errors {
+		errs = append(errs, tenantErr)
+	}
+	return errs
+}
+
+// Transient returns the transient tenant errors as a MultiError, nil when there are none
+func (m *MultiError) Transient() *MultiError {
+	if m == nil {
+		return nil
+	}
+	transient := &MultiError{}
+	for _, tenantErr := range m.Errors {
+		if tenantErr.Transient {
+			transient.Errors = append(transient.Errors, tenantErr)
+		}
+	}
+	if len(transient.Errors) == 0 {
+		return nil
+	}
+	return transient
+}
+
+// ErrorOrNil returns the MultiError as an error, nil when no tenant failed
+func (m *MultiError) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// IsTransientError reports whether an error is likely to succeed when retried: timeouts,
+// network errors and Kubernetes API conflicts, throttling and unavailability
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err)
+}
+
+// analyzeTenants analyzes the trends and calculates the limits of each tenant on its
+// own, so a tenant that fails, or panics, is skipped without holding back the others
+func (r *MimirLimitController) analyzeTenants(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string][]analyzer.AnalysisResult, map[string]*analyzer.TenantLimits, *MultiError) {
+	analysisResults := make(map[string][]analyzer.AnalysisResult)
+	limits := make(map[string]*analyzer.TenantLimits)
+	tenantErrs := &MultiError{}
+
+	tenants := make([]string, 0, len(tenantMetrics))
+	for tenant := range tenantMetrics {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		results, tenantLimits, err := r.analyzeTenant(ctx, tenant, tenantMetrics[tenant])
+		if err != nil {
+			tenantErrs.Add(tenant, err)
+			r.Log.Error(err, "failed to calculate limits for tenant, skipping it in this reconcile",
+				"tenant", tenant,
+				"transient", IsTransientError(err))
+			continue
+		}
+		for t, tenantResults := range results {
+			analysisResults[t] = tenantResults
+		}
+		for t, l := range tenantLimits {
+			limits[t] = l
+		}
+	}
+
+	return analysisResults, limits, tenantErrs
+}
+
+// analyzeTenant analyzes the trends and calculates the limits of one tenant
+func (r *MimirLimitController) analyzeTenant(ctx context.Context, tenant string, tenantMetrics *collector.TenantMetrics) (results map[string][]analyzer.AnalysisResult, limits map[string]*analyzer.TenantLimits, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic while calculating limits: %v", recovered)
+		}
+	}()
+
+	results, err = r.Analyzer.AnalyzeTrends(ctx, map[string]*collector.TenantMetrics{tenant: tenantMetrics})
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("analyzer", "trend-analysis")
+		return nil, nil, fmt.Errorf("failed to analyze trends: %w", err)
+	}
+
+	limits, err = r.Analyzer.CalculateLimits(ctx, results)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("analyzer", "limit-calculation")
+		return nil, nil, fmt.Errorf("failed to calculate limits: %w", err)
+	}
+	return results, limits, nil
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// failingAnalyzer fails the limit calculation of the tenants in failures with their
+// error, or panics for a nil error
+type failingAnalyzer struct {
+	analyzer.Analyzer
+	failures map[string]error
+}
+
+func (a *failingAnalyzer) CalculateLimits(ctx context.Context, results map[string][]analyzer.AnalysisResult) (map[string]*analyzer.TenantLimits, error) {
+	for tenant := range results {
+		if err, failing := a.failures[tenant]; failing {
+			if err == nil {
+				panic("analyzer bug")
+			}
+			return nil, err
+		}
+	}
+	return a.Analyzer.CalculateLimits(ctx, results)
+}
+
+func newMultiErrorTestController(failures map[string]error) *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	tc := newTestController(cfg, overridesConfigMap(cfg, "overrides: {}\n"))
+	tc.Analyzer = &failingAnalyzer{Analyzer: tc.Analyzer, failures: failures}
+	return tc
+}
+
+func TestReconcileUpdatesTenantsDespiteOthersFailing(t *testing.T) {
+	tenants := make([]string, 6)
+	failures := make(map[string]error)
+	for i := range tenants {
+		tenants[i] = fmt.Sprintf("tenant-%d", i)
+		if i%2 == 1 {
+			failures[tenants[i]] = fmt.Errorf("no usable samples for %s", tenants[i])
+		}
+	}
+	tc := newMultiErrorTestController(failures)
+	errorsBefore := metricstest.Value(t, "mimir_limit_optimizer_reconcile_tenant_errors_total", map[string]string{"tenant": "tenant-1"})
+
+	tc.collector.setMetrics(ingestionMetrics(20000, tenants...))
+	// Permanent tenant errors are logged and skipped, not returned
+	if _, err := tc.reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	overrides := tc.tenantOverrides(t)
+	for i, tenant := range tenants {
+		_, written := overrides[tenant]
+		if i%2 == 0 && !written {
+			t.Errorf("%s was not updated while odd-indexed tenants failed", tenant)
+		}
+		if i%2 == 1 && written {
+			t.Errorf("failing %s was written", tenant)
+		}
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_reconcile_tenant_errors_total", map[string]string{"tenant": "tenant-1"}) - errorsBefore; got != 1 {
+		t.Errorf("mimir_limit_optimizer_reconcile_tenant_errors_total{tenant-1} increased by %v, want 1", got)
+	}
+}
+
+func TestReconcileReturnsTransientTenantErrors(t *testing.T) {
+	throttled := apierrors.NewTooManyRequests("slow down", 1)
+	tc := newMultiErrorTestController(map[string]error{
+		"tenant-throttled": throttled,
+		"tenant-broken":    errors.New("invalid limit definition"),
+		"tenant-panics":    nil,
+	})
+
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-ok", "tenant-throttled", "tenant-broken", "tenant-panics"))
+	_, err := tc.reconcile(context.Background())
+
+	var tenantErrs *MultiError
+	if !errors.As(err, &tenantErrs) {
+		t.Fatalf("reconcile error = %v, want a *MultiError", err)
+	}
+	if tenantErrs.Len() != 1 || tenantErrs.Errors[0].Tenant != "tenant-throttled" {
+		t.Errorf("returned tenant errors = %v, want only the transient one", err)
+	}
+	if !errors.Is(err, throttled) {
+		t.Errorf("reconcile error does not wrap the tenant's error")
+	}
+	if _, written := tc.tenantOverrides(t)["tenant-ok"]; !written {
+		t.Errorf("tenant-ok was not updated while the others failed")
+	}
+}
+
+func TestReconcileFailsWhenEveryTenantFails(t *testing.T) {
+	tc := newMultiErrorTestController(map[string]error{
+		"tenant-a": errors.New("broken"),
+		"tenant-b": errors.New("broken"),
+	})
+
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+	_, err := tc.reconcile(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "every tenant") {
+		t.Errorf("reconcile error = %v, want every tenant failed", err)
+	}
+}
+
+func TestMultiErrorFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors map[string]error
+		want   string
+	}{
+		{"single error", map[string]error{"tenant-a": errors.New("boom")}, "1 error: tenant-a: boom"},
+		{"sorted by tenant", map[string]error{"tenant-b": errors.New("second"), "tenant-a": errors.New("first")},
+			"2 errors: tenant-a: first; tenant-b: second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MultiError{}
+			for tenant, err := range tt.errors {
+				m.Add(tenant, err)
+			}
+			if got := m.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	m := &MultiError{}
+	m.Add("tenant-a", nil)
+	if m.ErrorOrNil() != nil {
+		t.Errorf("ErrorOrNil() with only nil errors = %v, want nil", m.ErrorOrNil())
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	resource := schema.GroupResource{Resource: "configmaps"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"deadline exceeded", fmt.Errorf("write: %w", context.DeadlineExceeded), true},
+		{"conflict", apierrors.NewConflict(resource, "overrides", errors.New("modified")), true},
+		{"throttled", apierrors.NewTooManyRequests("slow down", 1), true},
+		{"unavailable", apierrors.NewServiceUnavailable("starting"), true},
+		{"not found", apierrors.NewNotFound(resource, "overrides"), false},
+		{"invalid", errors.New("invalid limit value"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientError(tt.err); got != tt.want {
+				t.Errorf("IsTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// namespaceAnnotationSource marks limits taken from the annotations of tenant namespaces
+const namespaceAnnotationSource = "namespace-annotation"
+
+// applyNamespaceAnnotationLimits merges the limits annotated on tenant namespaces as
+// baselines: a calculated limit below its annotation is raised to it, and annotated
+// limits missing from the calculation are added, also for tenants without usage yet. It
+// returns the merged limits and the tenants whose limits changed.
+func (r *MimirLimitController) applyNamespaceAnnotationLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) (map[string]*analyzer.TenantLimits, []string) {
+	annotated, err := collector.CollectNamespaceAnnotationLimits(ctx, r.KubeClient, r.config(), r.Log.WithName("namespace-annotations"))
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("collector", "namespace-annotations")
+		r.Log.Error(err, "failed to read limits from namespace annotations")
+		return limits, nil
+	}
+	if len(annotated) == 0 {
+		return limits, nil
+	}
+
+	merged := make(map[string]*analyzer.TenantLimits, len(limits)+len(annotated))
+	for tenant, tenantLimits := range limits {
+		merged[tenant] = tenantLimits
+	}
+
+	now := time.Now()
+	var raised []string
+	for tenant, baselines := range annotated {
+		if !r.tenantFilter.ShouldProcessTenant(tenant) {
+			continue
+		}
+
+		tenantLimits := &analyzer.TenantLimits{
+			Tenant:      tenant,
+			Limits:      make(map[string]interface{}, len(baselines)),
+			LastUpdated: now,
+			Reason:      namespaceAnnotationSource,
+			Source:      namespaceAnnotationSource,
+		}
+		calculated, exists := merged[tenant]
+		if exists {
+			for limitName, value := range calculated.Limits {
+				tenantLimits.Limits[limitName] = value
+			}
+			tenantLimits.LastUpdated = calculated.LastUpdated
+			tenantLimits.Reason = calculated.Reason
+			tenantLimits.Source = calculated.Source
+			tenantLimits.Tier = calculated.Tier
+			tenantLimits.InsufficientData = calculated.InsufficientData
+		}
+
+		changed := false
+		for limitName, baseline := range baselines {
+			current, set := tenantLimits.Limits[limitName]
+			if set && !limitBelow(current, baseline) {
+				continue
+			}
+			tenantLimits.Limits[limitName] = baseline
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if exists {
+			tenantLimits.Reason = calculated.Reason + "+" + namespaceAnnotationSource
+		}
+
+		merged[tenant] = tenantLimits
+		raised = append(raised, tenant)
+	}
+
+	sort.Strings(raised)
+	r.Log.Info("applied namespace annotation limits", "annotated_tenants", len(annotated), "tenants_changed", len(raised))
+	return merged, raised
+}
+
+// limitBelow reports whether a limit is lower than a numeric or duration baseline, or
+// not comparable with it. Other baselines never replace a limit that is set.
+func limitBelow(value, baseline interface{}) bool {
+	switch b := baseline.(type) {
+	case float64:
+		v, ok := config.NumericValue(value)
+		return !ok || v < b
+	case time.Duration:
+		switch v := value.(type) {
+		case time.Duration:
+			return v < b
+		case string:
+			d, err := time.ParseDuration(v)
+			return err != nil || d < b
+		default:
+			return true
+		}
+	default:
+		return false
+	}
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func annotatedNamespace(name string, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+}
+
+func TestApplyNamespaceAnnotationLimitsPrefersHigherValue(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.MetricsDiscovery.TenantDiscovery.NamespaceAnnotations = true
+	tc := newTestController(cfg)
+	tc.KubeClient = kubefake.NewSimpleClientset(
+		annotatedNamespace("tenant-a", map[string]string{
+			"mimir.io/ingestion-rate":       "25000",
+			"mimir.io/ingestion-burst-size": "100000",
+			"mimir.io/max-query-lookback":   "720h",
+		}),
+		annotatedNamespace("tenant-b", map[string]string{"mimir.io/ingestion-rate": "5000"}),
+		annotatedNamespace("tenant-c", map[string]string{"mimir.io/max-global-series-per-user": "150000"}),
+	)
+
+	calculated := map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Reason: "trend", Limits: map[string]interface{}{
+			"ingestion_rate":       10000.0,
+			"ingestion_burst_size": 200000.0,
+			"max_query_lookback":   "24h",
+		}},
+		"tenant-b": {Tenant: "tenant-b", Reason: "trend", Limits: map[string]interface{}{"ingestion_rate": 8000.0}},
+	}
+	merged, raised := tc.applyNamespaceAnnotationLimits(context.Background(), calculated)
+
+	if want := []string{"tenant-a", "tenant-c"}; !reflect.DeepEqual(raised, want) {
+		t.Errorf("changed tenants = %v, want %v", raised, want)
+	}
+	wantLimits := map[string]map[string]interface{}{
+		// The annotation raises the lower rate and lookback, the higher burst size stays
+		"tenant-a": {"ingestion_rate": 25000.0, "ingestion_burst_size": 200000.0, "max_query_lookback": 720 * time.Hour},
+		// The calculated rate is above the annotation
+		"tenant-b": {"ingestion_rate": 8000.0},
+		// A tenant without usage gets its annotated limits
+		"tenant-c": {"max_global_series_per_user": 150000.0},
+	}
+	for tenant, want := range wantLimits {
+		if merged[tenant] == nil || !reflect.DeepEqual(merged[tenant].Limits, want) {
+			t.Errorf("%s limits = %v, want %v", tenant, merged[tenant], want)
+		}
+	}
+	if reason := merged["tenant-a"].Reason; reason != "trend+namespace-annotation" {
+		t.Errorf("tenant-a reason = %q, want trend+namespace-annotation", reason)
+	}
+	if merged["tenant-b"] != calculated["tenant-b"] {
+		t.Errorf("unchanged tenant-b limits were replaced")
+	}
+	if source := merged["tenant-c"].Source; source != namespaceAnnotationSource {
+		t.Errorf("tenant-c source = %q, want %s", source, namespaceAnnotationSource)
+	}
+	if calculated["tenant-a"].Limits["ingestion_rate"] != 10000.0 {
+		t.Errorf("merge modified the calculated limits")
+	}
+}
+
+func TestLimitBelow(t *testing.T) {
+	tests := []struct {
+		value, baseline interface{}
+		want            bool
+	}{
+		{10.0, 20.0, true},
+		{30.0, 20.0, false},
+		{20, 20.0, false},
+		{"not a number", 20.0, true},
+		{time.Hour, 2 * time.Hour, true},
+		{"3h", 2 * time.Hour, false},
+		{"soon", 2 * time.Hour, true},
+		{"local", "global", false},
+	}
+	for _, tt := range tests {
+		if got := limitBelow(tt.value, tt.baseline); got != tt.want {
+			t.Errorf("limitBelow(%v, %v) = %v, want %v", tt.value, tt.baseline, got, tt.want)
+		}
+	}
+}
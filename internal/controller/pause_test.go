@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func newPauseTestController(configure func(cfg *config.Config)) *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	if configure != nil {
+		configure(cfg)
+	}
+	tc := newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+	return tc
+}
+
+// persistedScoping returns the scoping lists persisted to the runtime scoping ConfigMap
+func (tc *testController) persistedScoping(t *testing.T) *scopingDocument {
+	t.Helper()
+	configMap, err := tc.KubeClient.CoreV1().ConfigMaps(lockNamespace(tc.config())).Get(
+		context.Background(), tc.config().TenantScoping.RuntimeConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get tenant scoping ConfigMap: %v", err)
+	}
+	document, err := parseScopingConfigMap(configMap)
+	if err != nil {
+		t.Fatalf("parse tenant scoping ConfigMap: %v", err)
+	}
+	return document
+}
+
+// tenantOutcome returns the outcome of a tenant in the latest reconciliation
+func (tc *testController) tenantOutcome(t *testing.T, tenant string) TenantReconcileOutcome {
+	t.Helper()
+	result, err := tc.GetReconcileResult(0)
+	if err != nil {
+		t.Fatalf("GetReconcileResult: %v", err)
+	}
+	for _, outcome := range result.Tenants {
+		if outcome.Tenant == tenant {
+			return outcome
+		}
+	}
+	t.Fatalf("latest reconciliation has no outcome for %s", tenant)
+	return TenantReconcileOutcome{}
+}
+
+func TestPausedTenantOverridesUntouched(t *testing.T) {
+	tc := newPauseTestController(nil)
+	ctx := context.Background()
+
+	change, err := tc.SetTenantPaused(ctx, "tenant-a", true, "alice")
+	if err != nil {
+		t.Fatalf("SetTenantPaused: %v", err)
+	}
+	if !change.Paused || change.Pattern != "tenant-a" || !change.Persisted || !reflect.DeepEqual(change.PausedTenants, []string{"tenant-a"}) {
+		t.Errorf("pause change = %+v, want tenant-a paused and persisted", change)
+	}
+	if paused := tc.persistedScoping(t).PausedTenants; !reflect.DeepEqual(paused, []string{"tenant-a"}) {
+		t.Errorf("persisted paused tenants = %v, want [tenant-a]", paused)
+	}
+	entries := tc.auditEntries(t, "tenant-pause")
+	if len(entries) != 1 || entries[0].Tenant != "tenant-a" || entries[0].User != "alice" {
+		t.Fatalf("pause audit entries = %+v, want one for tenant-a by alice", entries)
+	}
+
+	before := tc.tenantOverrides(t)["tenant-a"]
+	for i := 0; i < 2; i++ {
+		if _, err := tc.reconcile(ctx); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if got := tc.tenantOverrides(t)["tenant-a"]; !reflect.DeepEqual(got, before) {
+			t.Errorf("reconcile %d changed the overrides of paused tenant-a from %v to %v", i+1, before, got)
+		}
+		if outcome := tc.tenantOutcome(t, "tenant-a"); outcome.Outcome != TenantOutcomeSkipped || !reflect.DeepEqual(outcome.Reasons, []string{ReconcileReasonPaused}) {
+			t.Errorf("reconcile %d outcome of tenant-a = %+v, want skipped as paused", i+1, outcome)
+		}
+	}
+	if rate, _ := config.NumericValue(tc.tenantOverrides(t)["tenant-b"].(map[string]interface{})["ingestion_rate"]); rate == 5000 {
+		t.Errorf("ingestion_rate of tenant-b was not optimized alongside the paused tenant")
+	}
+
+	// Unpausing resumes the optimization
+	if _, err := tc.SetTenantPaused(ctx, "tenant-a", false, "alice"); err != nil {
+		t.Fatalf("unpause: %v", err)
+	}
+	if len(tc.auditEntries(t, "tenant-unpause")) != 1 {
+		t.Errorf("unpause was not audited")
+	}
+	if paused := tc.persistedScoping(t).PausedTenants; len(paused) != 0 {
+		t.Errorf("persisted paused tenants after unpause = %v, want none", paused)
+	}
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := tc.tenantOverrides(t)["tenant-a"]; reflect.DeepEqual(got, before) {
+		t.Errorf("overrides of unpaused tenant-a = %v, want optimized", got)
+	}
+}
+
+func TestPausedByPatternInConfig(t *testing.T) {
+	tc := newPauseTestController(func(cfg *config.Config) {
+		cfg.TenantScoping.PausedTenants = []string{"tenant-*"}
+	})
+	ctx := context.Background()
+
+	before := tc.tenantOverrides(t)
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := tc.tenantOverrides(t); !reflect.DeepEqual(got, before) {
+		t.Errorf("reconcile changed the overrides of tenants paused by pattern from %v to %v", before, got)
+	}
+	if !tc.IsTenantPaused("tenant-b") {
+		t.Errorf("tenant-b is not paused by tenant-*")
+	}
+
+	if _, err := tc.SetTenantPaused(ctx, "tenant-b", false, "alice"); !errors.Is(err, ErrTenantPausedByPattern) {
+		t.Errorf("unpause of a tenant paused by pattern = %v, want ErrTenantPausedByPattern", err)
+	}
+	if _, err := tc.SetTenantPaused(ctx, "tenant-c", false, "alice"); !errors.Is(err, ErrTenantPausedByPattern) {
+		t.Errorf("unpause of tenant-c = %v, want ErrTenantPausedByPattern", err)
+	}
+}
+
+func TestUnpauseTenantNotPaused(t *testing.T) {
+	tc := newPauseTestController(nil)
+	if _, err := tc.SetTenantPaused(context.Background(), "tenant-a", false, "alice"); !errors.Is(err, ErrTenantNotPaused) {
+		t.Errorf("unpause of a tenant never paused = %v, want ErrTenantNotPaused", err)
+	}
+	var invalid *InvalidPatternError
+	if _, err := tc.SetTenantPaused(context.Background(), " ", true, "alice"); !errors.As(err, &invalid) {
+		t.Errorf("pause of an empty tenant ID = %v, want an invalid pattern error", err)
+	}
+}
+
+func TestTenantPattern(t *testing.T) {
+	tests := []struct {
+		tenant   string
+		useRegex bool
+		want     string
+	}{
+		{"tenant-a", false, "tenant-a"},
+		{"team[1]*?", false, `team\[1]\*\?`},
+		{"tenant.a+", true, `tenant\.a\+`},
+	}
+	for _, tt := range tests {
+		pattern := tenantPattern(tt.tenant, tt.useRegex)
+		if pattern != tt.want {
+			t.Errorf("tenantPattern(%q, %v) = %q, want %q", tt.tenant, tt.useRegex, pattern, tt.want)
+		}
+		if !config.MatchTenantPattern(tt.tenant, pattern, tt.useRegex) {
+			t.Errorf("pattern %q does not match %q", pattern, tt.tenant)
+		}
+		if config.MatchTenantPattern(tt.tenant+"x", pattern, tt.useRegex) {
+			t.Errorf("pattern %q matches more than %q", pattern, tt.tenant)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// prewarmedLimits are the limits raised before a predicted ingestion spike
+var prewarmedLimits = []string{"ingestion_rate", "ingestion_burst_size"}
+
+// startPredictiveSpikeChecks re-checks the ingestion rates for predicted spikes every
+// eventSpike.predictiveCheckInterval, between reconciliations, so limits are raised
+// before a spike reaches them
+func (r *MimirLimitController) startPredictiveSpikeChecks(ctx context.Context) {
+	interval := r.config().EventSpike.PredictiveCheckInterval
+	if !r.config().EventSpike.PredictiveSpike || interval <= 0 {
+		return
+	}
+
+	r.Log.Info("starting predictive spike checks", "interval", interval,
+		"detection_window", r.config().EventSpike.DetectionWindow)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.checkPredictiveSpikes(ctx)
+			}
+		}
+	}()
+}
+
+// checkPredictiveSpikes collects the ingestion rates, pre-warms the limits of tenants
+// predicted to breach them and restores those whose predicted spike was cancelled
+func (r *MimirLimitController) checkPredictiveSpikes(ctx context.Context) {
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+
+	if !r.config().EventSpike.Enabled || !r.config().EventSpike.PredictiveSpike {
+		return
+	}
+	if freeze := r.GetEmergencyFreeze(); freeze != nil {
+		r.Log.V(1).Info("skipping predictive spike check, limit changes are frozen", "reason", freeze.Reason)
+		return
+	}
+
+	tenantMetrics, err := r.Collector.CollectMetrics(ctx)
+	if err != nil && !collector.IsPartialFailure(err) {
+		metrics.HealthMetricsInstance.IncErrorTotal("collector", "predictive-spike")
+		r.Log.Error(err, "failed to collect metrics for predictive spike detection")
+		return
+	}
+	tenantMetrics = r.applySyntheticSpikes(tenantMetrics)
+
+	managed, err := r.GetManagedLimits(ctx)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("controller", "predictive-spike")
+		r.Log.Error(err, "failed to read managed limits for predictive spike detection")
+		return
+	}
+
+	filter := r.GetTenantFilter()
+	rates := make(map[string]float64, len(tenantMetrics))
+	limits := make(map[string]float64, len(tenantMetrics))
+	for tenant, tm := range tenantMetrics {
+		data := tm.Metrics[analyzer.PredictiveSpikeMetric]
+		if len(data) == 0 || !filter.ShouldProcessTenant(tenant) {
+			continue
+		}
+		rates[tenant] = data[len(data)-1].Value
+		if tenantLimits, exists := managed[tenant]; exists {
+			if limit, numeric := config.NumericValue(tenantLimits.Limits["ingestion_rate"]); numeric {
+				limits[tenant] = limit
+			}
+		}
+	}
+
+	breaches, cancelled := r.Analyzer.PredictSpikes(rates, limits, time.Now())
+	for _, breach := range breaches {
+		r.prewarmLimits(ctx, breach, managed[breach.Tenant])
+	}
+	for _, tenant := range cancelled {
+		r.restorePrewarmedLimits(ctx, tenant, managed[tenant])
+	}
+
+	// Spikes that were observed since, or ran through their cooldown, are not restored
+	r.prewarmed.Range(func(key, _ interface{}) bool {
+		tenant := key.(string)
+		if info := r.Analyzer.GetSpikeInfo(tenant, analyzer.PredictiveSpikeMetric); info == nil || !info.Predictive || !info.Elevated() {
+			r.prewarmed.Delete(tenant)
+		}
+		return true
+	})
+}
+
+// prewarmLimits raises the ingestion limits of a tenant by the spike multiplier ahead
+// of a predicted breach, keeping the previous values to restore if it is cancelled.
+// The raised limits hold through the next reconciliations while the spike is elevated.
+func (r *MimirLimitController) prewarmLimits(ctx context.Context, breach analyzer.PredictedBreach, current *analyzer.TenantLimits) {
+	if current == nil {
+		return
+	}
+
+	if r.BlastProtector.Protecting() {
+		// The circuit breaker is reducing limits, so raising them would work against it
+		metrics.SpikeMetricsInstance.IncPredictiveSpikeActivations(breach.Tenant, "skipped")
+		r.Log.Info("skipping pre-warming for predicted spike, circuit breaker protection is active",
+			"tenant", breach.Tenant, "breach_at", breach.BreachAt)
+		return
+	}
+
+	raised := copyTenantLimits(map[string]*analyzer.TenantLimits{breach.Tenant: current})[breach.Tenant]
+	previous := make(map[string]interface{}, len(prewarmedLimits))
+	for _, limitName := range prewarmedLimits {
+		value, numeric := config.NumericValue(current.Limits[limitName])
+		if !numeric {
+			continue
+		}
+		previous[limitName] = current.Limits[limitName]
+		raised.Limits[limitName] = value * breach.Multiplier
+	}
+	raised.Reason = "predictive-spike"
+	raised.LastUpdated = time.Now()
+
+	written, err := r.writeTenantLimits(ctx, raised)
+	result := "applied"
+	switch {
+	case err != nil:
+		result = "failed"
+		metrics.HealthMetricsInstance.IncErrorTotal("patcher", "predictive-spike")
+		r.Log.Error(err, "failed to pre-warm limits for predicted spike", "tenant", breach.Tenant)
+	case !written:
+		metrics.SpikeMetricsInstance.IncPredictiveSpikeActivations(breach.Tenant, "skipped")
+		return
+	default:
+		r.prewarmed.Store(breach.Tenant, previous)
+	}
+	metrics.SpikeMetricsInstance.IncPredictiveSpikeActivations(breach.Tenant, result)
+
+	r.Log.Info("pre-warmed limits for predicted spike", "tenant", breach.Tenant,
+		"rate", breach.Rate, "slope", breach.Slope, "threshold", breach.Threshold,
+		"breach_at", breach.BreachAt, "multiplier", breach.Multiplier, "result", result)
+
+	if r.AuditLogger == nil {
+		return
+	}
+	entry := auditlog.NewSpikeDetectionEntry(breach.Tenant, analyzer.PredictiveSpikeMetric, breach.Threshold, breach.Rate, true)
+	entry.Changes["slope"] = breach.Slope
+	entry.Changes["breach_at"] = breach.BreachAt
+	entry.Changes["multiplier"] = breach.Multiplier
+	entry.Success = err == nil
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if logErr := r.AuditLogger.LogEntry(entry); logErr != nil {
+		r.Log.Error(logErr, "failed to log predictive spike", "tenant", breach.Tenant)
+	}
+}
+
+// restorePrewarmedLimits writes back the ingestion limits a cancelled predictive spike
+// raised
+func (r *MimirLimitController) restorePrewarmedLimits(ctx context.Context, tenant string, current *analyzer.TenantLimits) {
+	value, exists := r.prewarmed.LoadAndDelete(tenant)
+	if !exists || current == nil {
+		return
+	}
+
+	restored := copyTenantLimits(map[string]*analyzer.TenantLimits{tenant: current})[tenant]
+	for limitName, previous := range value.(map[string]interface{}) {
+		restored.Limits[limitName] = previous
+	}
+	restored.Reason = "predictive-spike-cancelled"
+	restored.LastUpdated = time.Now()
+
+	written, err := r.writeTenantLimits(ctx, restored)
+	if err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("patcher", "predictive-spike")
+		r.Log.Error(err, "failed to restore limits of cancelled predictive spike", "tenant", tenant)
+		return
+	}
+	if !written {
+		return
+	}
+	r.Log.Info("restored limits of cancelled predictive spike", "tenant", tenant)
+}
+
+// writeTenantLimits writes the limits of one tenant outside a reconciliation, through the
+// guards of a reconciliation: nothing is written while limit changes are frozen or the
+// tenant is paused, the limits go through the circuit breaker's protection and the
+// emergency holds, and the write is validated and charged to the retry budget. It
+// reports whether the limits were written.
+func (r *MimirLimitController) writeTenantLimits(ctx context.Context, tenantLimits *analyzer.TenantLimits) (bool, error) {
+	tenant := tenantLimits.Tenant
+	if freeze := r.GetEmergencyFreeze(); freeze != nil {
+		r.Log.V(1).Info("skipping limit write, limit changes are frozen", "tenant", tenant, "reason", freeze.Reason)
+		return false, nil
+	}
+	if r.GetTenantFilter().IsPaused(tenant) {
+		r.Log.V(1).Info("skipping limit write, tenant is paused", "tenant", tenant)
+		return false, nil
+	}
+
+	limits := map[string]*analyzer.TenantLimits{tenant: tenantLimits}
+	protected, err := r.BlastProtector.ApplyProtection(ctx, limits)
+	if err != nil {
+		r.Log.Error(err, "failed to apply blast protection to limits", "tenant", tenant)
+		protected = limits
+	}
+	if r.emergencyLimits != nil {
+		protected = r.emergencyLimits.apply(protected)
+	}
+
+	if r.WriteLock != nil {
+		if !r.acquireWriteLock(ctx) {
+			return false, fmt.Errorf("ConfigMap write lock held by another replica")
+		}
+		defer r.releaseWriteLock()
+	}
+
+	applied, err := r.applyLimits(ctx, protected)
+	if err != nil {
+		return false, err
+	}
+	written, exists := applied[tenant]
+	if !exists {
+		// Every value failed validation or the retry budget holds the tenant back
+		return false, nil
+	}
+	r.setManagedTenant(written)
+	return true, nil
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// newPredictiveTestController creates a controller pre-warming tenant-a, whose
+// ingestion limit is 100000 samples/s
+func newPredictiveTestController() *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	cfg.EventSpike.Enabled = true
+	cfg.EventSpike.PredictiveSpike = true
+	return newTestController(cfg, overridesConfigMap(cfg, `overrides:
+  tenant-a:
+    ingestion_rate: 100000
+    ingestion_burst_size: 200000
+`))
+}
+
+// observeGrowth records tenant-a's ingestion rate growing at 1000 samples/s² over the
+// last two seconds, and has the collector report rate samples/s now. The checks run on
+// the real clock, so the samples are close together for one falling rate to reverse
+// the trend.
+func (tc *testController) observeGrowth(rate float64) {
+	now := time.Now()
+	for _, ago := range []time.Duration{2 * time.Second, time.Second} {
+		rates := map[string]float64{"tenant-a": rate - 1000*ago.Seconds()}
+		// Without limits nothing is predicted yet
+		tc.Analyzer.PredictSpikes(rates, nil, now.Add(-ago))
+	}
+	tc.collector.setMetrics(ingestionMetrics(rate, "tenant-a"))
+}
+
+func TestPredictiveSpikeRaisesLimitsBeforeBreach(t *testing.T) {
+	tc := newPredictiveTestController()
+	activations := metricstest.Value(t, "mimir_limit_optimizer_predictive_spike_activations_total", map[string]string{"tenant": "tenant-a", "result": "applied"})
+
+	// 60000 samples/s reaches 80% of the limit in 20 seconds
+	tc.observeGrowth(60000)
+	tc.checkPredictiveSpikes(context.Background())
+
+	limits := tc.tenantOverrides(t)["tenant-a"].(map[string]interface{})
+	if rate, _ := config.NumericValue(limits["ingestion_rate"]); rate != 500000 {
+		t.Errorf("ingestion_rate = %v, want raised ahead of the breach by the 5x spike multiplier", limits["ingestion_rate"])
+	}
+	if burst, _ := config.NumericValue(limits["ingestion_burst_size"]); burst != 1000000 {
+		t.Errorf("ingestion_burst_size = %v, want raised by the 5x spike multiplier", limits["ingestion_burst_size"])
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_predictive_spike_activations_total", map[string]string{"tenant": "tenant-a", "result": "applied"}) - activations; got != 1 {
+		t.Errorf("mimir_limit_optimizer_predictive_spike_activations_total{applied} increased by %v, want 1", got)
+	}
+
+	entries := tc.auditEntries(t, "spike-detected")
+	if len(entries) != 1 || entries[0].Changes["predictive"] != true {
+		t.Fatalf("spike audit entries = %+v, want one predictive", entries)
+	}
+	if threshold := entries[0].Changes["value"].(map[string]interface{})["old"]; threshold != 80000.0 {
+		t.Errorf("audited threshold = %v, want 80%% of the limit", threshold)
+	}
+
+	// The rate falling reverses the trend and restores the limits
+	tc.collector.setMetrics(ingestionMetrics(10000, "tenant-a"))
+	tc.checkPredictiveSpikes(context.Background())
+
+	limits = tc.tenantOverrides(t)["tenant-a"].(map[string]interface{})
+	if rate, _ := config.NumericValue(limits["ingestion_rate"]); rate != 100000 {
+		t.Errorf("ingestion_rate after the trend reversed = %v, want the 100000 restored", limits["ingestion_rate"])
+	}
+	if info := tc.Analyzer.GetSpikeInfo("tenant-a", analyzer.PredictiveSpikeMetric); info == nil || info.Elevated() {
+		t.Errorf("spike info after the trend reversed = %+v, want no spike", info)
+	}
+}
+
+func TestPredictiveSpikeSkippedWhileFrozen(t *testing.T) {
+	tc := newPredictiveTestController()
+	ctx := context.Background()
+	if _, err := tc.ActivateEmergencyFreeze(ctx, "incident-7", "sre@example.com", 0); err != nil {
+		t.Fatalf("ActivateEmergencyFreeze: %v", err)
+	}
+	_, resourceVersion := tc.runtimeOverrides(t)
+
+	tc.observeGrowth(60000)
+	tc.checkPredictiveSpikes(ctx)
+
+	if _, got := tc.runtimeOverrides(t); got != resourceVersion {
+		t.Errorf("runtime overrides changed by a predictive spike while frozen")
+	}
+	if entries := tc.auditEntries(t, "spike-detected"); len(entries) != 0 {
+		t.Errorf("predictive spike audited while frozen: %+v", entries)
+	}
+}
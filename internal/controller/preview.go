@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+)
+
+// LimitPreview is what the next reconciliation would write, computed without writing
+type LimitPreview struct {
+	GeneratedAt time.Time
+	Mode        string
+	// Frozen reports an active emergency freeze, under which nothing would be written
+	Frozen bool
+	// Proposed holds the limits calculated for each tenant that would be written
+	Proposed map[string]*analyzer.TenantLimits
+	// Changes holds the current and proposed values of the limits that would change,
+	// by tenant
+	Changes map[string]*patcher.TenantLimitChange
+	// Skipped maps the tenants left out to the reason code, such as tenant_scoping,
+	// paused or calculation_error
+	Skipped map[string]string
+}
+
+// PreviewLimits runs the analysis of a reconciliation and returns the limit changes it
+// would write to the runtime overrides ConfigMap, whatever the mode. Nothing is written,
+// the analyzer does not record the collected metrics and no clamped recommendations are
+// counted. Blast detection, spike handling and cost enforcement keep state across
+// reconciliations and are not run; the circuit breaker, emergency and paused tenant
+// adjustments of the limits are applied on copies. A preview may run during a
+// reconciliation: the state both read is guarded.
+func (r *MimirLimitController) PreviewLimits(ctx context.Context) (*LimitPreview, error) {
+	if r.Collector == nil || r.Analyzer == nil || r.Patcher == nil {
+		return nil, fmt.Errorf("controller not initialized")
+	}
+
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+
+	ctx = analyzer.WithPreview(ctx)
+	preview := &LimitPreview{
+		GeneratedAt: time.Now(),
+		Mode:        r.config().Mode,
+		Frozen:      r.GetEmergencyFreeze() != nil,
+		Skipped:     make(map[string]string),
+	}
+
+	tenantMetrics, err := r.Collector.CollectMetrics(ctx)
+	if err != nil && !collector.IsPartialFailure(err) {
+		return nil, fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	tenantMetrics = r.applySyntheticSpikes(tenantMetrics)
+
+	filter := r.GetTenantFilter()
+	if err := filter.Err(); err != nil {
+		return nil, fmt.Errorf("tenant scoping lists contain invalid patterns: %w", err)
+	}
+	allTenants := make([]string, 0, len(tenantMetrics))
+	for tenant := range tenantMetrics {
+		allTenants = append(allTenants, tenant)
+	}
+	monitoredTenants, skippedTenants := filter.FilterTenants(allTenants)
+	for _, tenant := range skippedTenants {
+		preview.Skipped[tenant] = ReconcileReasonTenantScoping
+	}
+	filteredMetrics := make(map[string]*collector.TenantMetrics, len(monitoredTenants))
+	for _, tenant := range monitoredTenants {
+		filteredMetrics[tenant] = tenantMetrics[tenant]
+	}
+
+	_, limits, tenantErrs := r.analyzeTenants(ctx, filteredMetrics)
+	for _, tenantErr := range tenantErrs.Errors {
+		preview.Skipped[tenantErr.Tenant] = ReconcileReasonCalculationError
+	}
+	r.reapplyDefaultLimits(limits)
+	if r.config().MetricsDiscovery.TenantDiscovery.NamespaceAnnotations && r.KubeClient != nil {
+		limits, _ = r.applyNamespaceAnnotationLimits(ctx, limits)
+	}
+	if r.RemoteOverrides != nil {
+		limits = r.applyRemoteOverrides(ctx, limits)
+	}
+	if r.BlastProtector != nil {
+		if protected, err := r.BlastProtector.ApplyProtection(ctx, limits); err == nil {
+			limits = protected
+		}
+	}
+	if r.emergencyLimits != nil {
+		limits = r.emergencyLimits.apply(limits)
+	}
+	for tenant := range limits {
+		if filter.IsPaused(tenant) {
+			delete(limits, tenant)
+			preview.Skipped[tenant] = ReconcileReasonPaused
+		}
+	}
+	limits = r.withValidLimits(limits)
+
+	result, err := r.Patcher.PreviewLimits(ctx, limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview limit changes: %w", err)
+	}
+	preview.Proposed = limits
+	preview.Changes = result.Changes
+	return preview, nil
+}
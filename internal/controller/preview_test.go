@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// writeCounter counts the writes to the fake client
+type writeCounter struct {
+	mu     sync.Mutex
+	writes int
+}
+
+func (w *writeCounter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+func (w *writeCounter) funcs() interceptor.Funcs {
+	record := func() {
+		w.mu.Lock()
+		w.writes++
+		w.mu.Unlock()
+	}
+	return interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			record()
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			record()
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			record()
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			record()
+			return c.Delete(ctx, obj, opts...)
+		},
+	}
+}
+
+// newPreviewTestController creates a controller of mode whose fake client counts its
+// writes, with tenant-a and tenant-b ingesting 20000 samples/s
+func newPreviewTestController(mode string) (*testController, *writeCounter) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = mode
+	cfg.Alerting.Enabled = false
+	writes := &writeCounter{}
+	tc := newInterceptedTestController(cfg, writes.funcs(), overridesConfigMap(cfg, twoTenantOverrides))
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+	return tc, writes
+}
+
+func (tc *testController) overridesConfigMap(t *testing.T) *corev1.ConfigMap {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: tc.config().Mimir.ConfigMapName, Namespace: tc.config().Mimir.Namespace}
+	if err := tc.client.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	return configMap
+}
+
+func TestPreviewLimitsDoesNotWrite(t *testing.T) {
+	for _, mode := range []string{"prod", "dry-run"} {
+		t.Run(mode, func(t *testing.T) {
+			tc, writes := newPreviewTestController(mode)
+			before := tc.overridesConfigMap(t)
+
+			preview, err := tc.PreviewLimits(context.Background())
+			if err != nil {
+				t.Fatalf("PreviewLimits: %v", err)
+			}
+			if preview.Mode != mode || preview.Frozen {
+				t.Errorf("preview mode %q, frozen %v; want %q and not frozen", preview.Mode, preview.Frozen, mode)
+			}
+			for tenant, old := range map[string]float64{"tenant-a": 10000, "tenant-b": 5000} {
+				change := preview.Changes[tenant]
+				if change == nil {
+					t.Fatalf("preview has no change for %s", tenant)
+				}
+				if got, _ := config.NumericValue(change.OldValues["ingestion_rate"]); got != old {
+					t.Errorf("%s current ingestion_rate = %v, want %v", tenant, change.OldValues["ingestion_rate"], old)
+				}
+				if got, _ := config.NumericValue(change.NewValues["ingestion_rate"]); got <= 20000 {
+					t.Errorf("%s proposed ingestion_rate = %v, want above the 20000 samples/s ingested", tenant, change.NewValues["ingestion_rate"])
+				}
+			}
+
+			if got := writes.count(); got != 0 {
+				t.Errorf("preview issued %d writes, want none", got)
+			}
+			after := tc.overridesConfigMap(t)
+			if after.ResourceVersion != before.ResourceVersion || !reflect.DeepEqual(after.Data, before.Data) {
+				t.Errorf("preview modified the runtime overrides ConfigMap:\n%s\nwant:\n%s", after.Data["overrides.yaml"], before.Data["overrides.yaml"])
+			}
+			if entries, _ := tc.audit.GetEntries(context.Background(), nil); len(entries) != 0 {
+				t.Errorf("preview audited %d entries, want none", len(entries))
+			}
+			if _, err := tc.GetReconcileResult(0); err == nil {
+				t.Errorf("preview recorded a reconcile result")
+			}
+		})
+	}
+}
+
+func TestPreviewLimitsMatchesNextReconcile(t *testing.T) {
+	tc, _ := newPreviewTestController("prod")
+	ctx := context.Background()
+
+	preview, err := tc.PreviewLimits(ctx)
+	if err != nil {
+		t.Fatalf("PreviewLimits: %v", err)
+	}
+	// A second preview sees the same state: the first recorded nothing
+	again, err := tc.PreviewLimits(ctx)
+	if err != nil {
+		t.Fatalf("PreviewLimits: %v", err)
+	}
+	if !reflect.DeepEqual(again.Changes, preview.Changes) {
+		t.Errorf("second preview = %v, want the first %v", again.Changes, preview.Changes)
+	}
+
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	overrides := tc.tenantOverrides(t)
+	for tenant, change := range preview.Changes {
+		written, _ := config.NumericValue(overrides[tenant].(map[string]interface{})["ingestion_rate"])
+		if proposed, _ := config.NumericValue(change.NewValues["ingestion_rate"]); written != proposed {
+			t.Errorf("%s ingestion_rate written = %v, want the previewed %v", tenant, written, proposed)
+		}
+	}
+
+	// Once applied, nothing is left to change
+	preview, err = tc.PreviewLimits(ctx)
+	if err != nil {
+		t.Fatalf("PreviewLimits: %v", err)
+	}
+	if len(preview.Changes) != 0 {
+		t.Errorf("preview after the reconcile = %v, want no changes", preview.Changes)
+	}
+}
+
+func TestPreviewLimitsSkipsPausedTenants(t *testing.T) {
+	tc, writes := newPreviewTestController("prod")
+	tc.GetTenantFilter().SetLists(nil, nil, []string{"tenant-a"}, scopingSourceConfigMap)
+
+	preview, err := tc.PreviewLimits(context.Background())
+	if err != nil {
+		t.Fatalf("PreviewLimits: %v", err)
+	}
+	if _, changed := preview.Changes["tenant-a"]; changed {
+		t.Errorf("preview proposes changes for paused tenant-a")
+	}
+	if preview.Skipped["tenant-a"] != ReconcileReasonPaused {
+		t.Errorf("tenant-a skipped as %q, want %q", preview.Skipped["tenant-a"], ReconcileReasonPaused)
+	}
+	if _, changed := preview.Changes["tenant-b"]; !changed {
+		t.Errorf("preview proposes no changes for tenant-b")
+	}
+	if got := writes.count(); got != 0 {
+		t.Errorf("preview issued %d writes, want none", got)
+	}
+}
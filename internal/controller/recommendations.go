@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/history"
+)
+
+// recommendationReportHistory is how many reconciliations' recommendations are kept,
+// so a report pinned to a reconcile ID stays available while it is being reviewed
+const recommendationReportHistory = 5
+
+// ErrRecommendationReportNotFound is returned when no recommendations were recorded
+// for the requested reconciliation
+var ErrRecommendationReportNotFound = errors.New("recommendation report not found")
+
+// RecommendationStatusInsufficientData marks a query-path limit that has no
+// recommendation, as the per-query usage of the tenant was not collected
+const RecommendationStatusInsufficientData = "insufficient_data"
+
+// Recommendation is the limit recommended for a tenant in one reconciliation
+type Recommendation struct {
+	Tenant           string      `json:"tenant"`
+	Tier             string      `json:"tier,omitempty"`
+	Limit            string      `json:"limit"`
+	CurrentValue     interface{} `json:"current_value"`
+	RecommendedValue interface{} `json:"recommended_value"`
+	// PercentChange is unset when there is no numeric current value to compare with
+	PercentChange *float64 `json:"percent_change"`
+	// PeakUsage is the highest usage of the limit's metrics over the analysis window
+	PeakUsage     *float64 `json:"peak_usage"`
+	BufferPercent float64  `json:"buffer_percent"`
+	// Status is RecommendationStatusInsufficientData for limits without a recommendation
+	Status string `json:"status,omitempty"`
+}
+
+// RecommendationReport holds the recommendations of one reconciliation, ordered by
+// tenant and limit
+type RecommendationReport struct {
+	ReconcileID     int64            `json:"reconcile_id"`
+	GeneratedAt     time.Time        `json:"generated_at"`
+	Mode            string           `json:"mode"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// GetRecommendationReport returns the recommendations of a reconciliation, or of the
+// latest one when reconcileID is 0. Reports are shared and must not be modified.
+func (r *MimirLimitController) GetRecommendationReport(reconcileID int64) (*RecommendationReport, error) {
+	r.reportsMu.RLock()
+	defer r.reportsMu.RUnlock()
+
+	if len(r.reports) == 0 {
+		return nil, ErrRecommendationReportNotFound
+	}
+	if reconcileID == 0 {
+		return r.reports[len(r.reports)-1], nil
+	}
+	for _, report := range r.reports {
+		if report.ReconcileID == reconcileID {
+			return report, nil
+		}
+	}
+	return nil, ErrRecommendationReportNotFound
+}
+
+// historyMark is the last recommendation of a tenant's limit recorded in the history
+type historyMark struct {
+	value      string
+	recordedAt time.Time
+}
+
+// recordRecommendations keeps the recommendations of a reconciliation together with the
+// values applied before it and the peak usage they were calculated from
+func (r *MimirLimitController) recordRecommendations(ctx context.Context, reconcileID int64, previousLimits, recommendedLimits map[string]*analyzer.TenantLimits, analysisResults map[string][]analyzer.AnalysisResult) {
+	report := &RecommendationReport{
+		ReconcileID:     reconcileID,
+		GeneratedAt:     time.Now(),
+		Mode:            r.config().Mode,
+		Recommendations: []Recommendation{},
+	}
+
+	tenants := make([]string, 0, len(recommendedLimits))
+	for tenant := range recommendedLimits {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+
+	for _, tenant := range tenants {
+		tenantLimits := recommendedLimits[tenant]
+		if tenantLimits == nil {
+			continue
+		}
+		peaks := peakUsageByLimit(analysisResults[tenant])
+
+		limitNames := make([]string, 0, len(tenantLimits.Limits)+len(tenantLimits.InsufficientData))
+		for limitName := range tenantLimits.Limits {
+			limitNames = append(limitNames, limitName)
+		}
+		insufficientData := make(map[string]bool)
+		for _, limitName := range tenantLimits.InsufficientData {
+			if _, exists := tenantLimits.Limits[limitName]; !exists {
+				limitNames = append(limitNames, limitName)
+			}
+			insufficientData[limitName] = true
+		}
+		sort.Strings(limitNames)
+
+		for _, limitName := range limitNames {
+			recommendation := Recommendation{
+				Tenant:           tenant,
+				Tier:             tenantLimits.Tier,
+				Limit:            limitName,
+				RecommendedValue: reportLimitValue(tenantLimits.Limits[limitName]),
+				BufferPercent:    analyzer.TierLimitBufferPercent(r.config(), tenantLimits.Tier, limitName),
+			}
+			if previous := previousLimits[tenant]; previous != nil {
+				recommendation.CurrentValue = reportLimitValue(previous.Limits[limitName])
+			}
+			recommendation.PercentChange = r.percentChange(limitName, recommendation.CurrentValue, recommendation.RecommendedValue)
+			if peak, exists := peaks[limitName]; exists {
+				recommendation.PeakUsage = &peak
+			}
+			if insufficientData[limitName] {
+				recommendation.Status = RecommendationStatusInsufficientData
+			}
+			report.Recommendations = append(report.Recommendations, recommendation)
+		}
+	}
+
+	r.reportsMu.Lock()
+	r.reports = append(r.reports, report)
+	if len(r.reports) > recommendationReportHistory {
+		r.reports = r.reports[len(r.reports)-recommendationReportHistory:]
+	}
+	entries := r.historyEntries(report)
+	r.reportsMu.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	if err := r.RecommendationHistory.Record(ctx, entries); err != nil {
+		r.Log.Error(err, "failed to record recommendation history", "reconcile_id", reconcileID)
+	}
+}
+
+// historyEntries returns the recommendations of the report to add to the history: those
+// whose value changed, and unchanged ones once the sample interval has passed. The caller
+// must hold reportsMu.
+func (r *MimirLimitController) historyEntries(report *RecommendationReport) []history.Recommendation {
+	if r.RecommendationHistory == nil || !r.config().RecommendationHistory.Enabled {
+		return nil
+	}
+	if r.historyMarks == nil {
+		r.historyMarks = make(map[string]historyMark)
+	}
+
+	sampleInterval := r.config().RecommendationHistory.SampleInterval
+	var entries []history.Recommendation
+	for _, recommendation := range report.Recommendations {
+		if recommendation.Status == RecommendationStatusInsufficientData {
+			continue
+		}
+		key := recommendation.Tenant + "/" + recommendation.Limit
+		value := fmt.Sprint(recommendation.RecommendedValue)
+		if mark, exists := r.historyMarks[key]; exists && mark.value == value &&
+			report.GeneratedAt.Sub(mark.recordedAt) < sampleInterval {
+			continue
+		}
+		r.historyMarks[key] = historyMark{value: value, recordedAt: report.GeneratedAt}
+
+		entries = append(entries, history.Recommendation{
+			Timestamp:        report.GeneratedAt,
+			ReconcileID:      report.ReconcileID,
+			Tenant:           recommendation.Tenant,
+			Tier:             recommendation.Tier,
+			Limit:            recommendation.Limit,
+			RecommendedValue: recommendation.RecommendedValue,
+			CurrentValue:     recommendation.CurrentValue,
+			PeakUsage:        recommendation.PeakUsage,
+			BufferPercent:    recommendation.BufferPercent,
+		})
+	}
+	return entries
+}
+
+// pruneRecommendationHistory drops recommendations older than the history retention.
+// Pruning happens here rather than on read, so the stored history stays bounded.
+func (r *MimirLimitController) pruneRecommendationHistory(ctx context.Context) {
+	if r.RecommendationHistory == nil || !r.config().RecommendationHistory.Enabled {
+		return
+	}
+	cutoff := time.Now().Add(-r.config().RecommendationHistory.Retention)
+	if err := r.RecommendationHistory.Prune(ctx, cutoff); err != nil {
+		r.Log.Error(err, "failed to prune recommendation history", "cutoff_time", cutoff)
+	}
+
+	r.reportsMu.Lock()
+	for key, mark := range r.historyMarks {
+		if mark.recordedAt.Before(cutoff) {
+			delete(r.historyMarks, key)
+		}
+	}
+	r.reportsMu.Unlock()
+}
+
+// percentChange returns how much the recommended value differs from the current one,
+// in percent, for limits with numeric or duration values
+func (r *MimirLimitController) percentChange(limitName string, current, recommended interface{}) *float64 {
+	limitType := r.config().DynamicLimits.LimitDefinitions[limitName].Type
+	currentValue, ok := config.LimitBoundValue(current, limitType)
+	if !ok || currentValue == 0 {
+		return nil
+	}
+	recommendedValue, ok := config.LimitBoundValue(recommended, limitType)
+	if !ok {
+		return nil
+	}
+	change := (recommendedValue - currentValue) / currentValue * 100
+	return &change
+}
+
+// reportLimitValue renders durations the way they are written to the overrides,
+// so reports show "1m30s" rather than nanoseconds
+func reportLimitValue(value interface{}) interface{} {
+	if duration, ok := value.(time.Duration); ok {
+		return duration.String()
+	}
+	return value
+}
+
+// peakUsageByLimit returns the highest peak among the metrics each limit is calculated from
+func peakUsageByLimit(results []analyzer.AnalysisResult) map[string]float64 {
+	peaks := make(map[string]float64)
+	for _, result := range results {
+		limitName, exists := analyzer.LimitForMetric(result.MetricName)
+		if !exists {
+			continue
+		}
+		if peak, seen := peaks[limitName]; !seen || result.Peak > peak {
+			peaks[limitName] = result.Peak
+		}
+	}
+	return peaks
+}
@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// reconcileResultHistory is how many reconciliations' results are kept in memory
+const reconcileResultHistory = 20
+
+// ErrReconcileResultNotFound is returned when the requested reconciliation is not one of
+// the latest ones
+var ErrReconcileResultNotFound = errors.New("reconcile result not found")
+
+// Outcomes of a tenant in a reconciliation
+const (
+	TenantOutcomeChanged   = "changed"
+	TenantOutcomeUnchanged = "unchanged"
+	TenantOutcomeSkipped   = "skipped"
+	TenantOutcomeFailed    = "failed"
+)
+
+// Reason codes explaining the outcome of a tenant
+const (
+	ReconcileReasonTenantScoping    = "tenant_scoping"
+	ReconcileReasonPaused           = "paused"
+	ReconcileReasonCircuitBreaker   = "circuit_breaker"
+	ReconcileReasonClamped          = "clamped"
+	ReconcileReasonCalculationError = "calculation_error"
+	ReconcileReasonDefaultLimits    = "default_limits_reapplied"
+	ReconcileReasonNamespaceLimits  = "namespace_annotation_limits"
+	ReconcileReasonEmergencyFreeze  = "emergency_freeze"
+	ReconcileReasonWriteLockHeld    = "write_lock_held"
+	ReconcileReasonNotWritten       = "not_written"
+	ReconcileReasonWriteFailed      = "write_failed"
+)
+
+// TenantReconcileOutcome is what a reconciliation did with a tenant
+type TenantReconcileOutcome struct {
+	Tenant  string   `json:"tenant"`
+	Outcome string   `json:"outcome"`
+	Reasons []string `json:"reasons,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// ReconcileResult describes one reconciliation. EndTime is unset while it runs.
+type ReconcileResult struct {
+	ReconcileID int64      `json:"reconcile_id"`
+	StartTime   time.Time  `json:"start_time"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	Mode        string     `json:"mode"`
+	// Error is set when the reconciliation failed as a whole
+	Error string `json:"error,omitempty"`
+	// Tenants are the outcomes of the tenants the reconciliation evaluated, by tenant
+	Tenants []TenantReconcileOutcome `json:"tenants"`
+	// Counts are the number of tenants per outcome
+	Counts map[string]int `json:"counts"`
+}
+
+// GetReconcileResult returns the result of a reconciliation, or of the latest one when
+// reconcileID is 0. Results are copies and may be modified.
+func (r *MimirLimitController) GetReconcileResult(reconcileID int64) (*ReconcileResult, error) {
+	r.resultsMu.RLock()
+	defer r.resultsMu.RUnlock()
+
+	if len(r.results) == 0 {
+		return nil, ErrReconcileResultNotFound
+	}
+	if reconcileID == 0 {
+		result := *r.results[len(r.results)-1]
+		return &result, nil
+	}
+	for _, result := range r.results {
+		if result.ReconcileID == reconcileID {
+			copied := *result
+			return &copied, nil
+		}
+	}
+	return nil, ErrReconcileResultNotFound
+}
+
+// reconcileTracker collects the outcome of each tenant while a reconciliation runs
+type reconcileTracker struct {
+	result   *ReconcileResult
+	outcomes map[string]*TenantReconcileOutcome
+	// clampedTenants are the tenants whose calculated limits were lowered or raised by
+	// cost control, blast protection or emergency limits
+	clampedTenants map[string]bool
+}
+
+// beginReconcileResult assigns the next reconcile ID and keeps a running result under
+// it, so the reconciliation can be looked up while it runs
+func (r *MimirLimitController) beginReconcileResult(startTime time.Time) *reconcileTracker {
+	r.resultsMu.Lock()
+	defer r.resultsMu.Unlock()
+
+	r.reconcileCount++
+	result := &ReconcileResult{
+		ReconcileID: r.reconcileCount,
+		StartTime:   startTime,
+		Mode:        r.config().Mode,
+		Tenants:     []TenantReconcileOutcome{},
+		Counts:      map[string]int{},
+	}
+	r.results = append(r.results, result)
+	if len(r.results) > reconcileResultHistory {
+		r.results = r.results[len(r.results)-reconcileResultHistory:]
+	}
+	r.activeReconcile.Store(result.ReconcileID)
+
+	return &reconcileTracker{
+		result:         result,
+		outcomes:       make(map[string]*TenantReconcileOutcome),
+		clampedTenants: make(map[string]bool),
+	}
+}
+
+// finishReconcileResult completes the result of a reconciliation with its tenant
+// outcomes and error, and exports its outcome
+func (r *MimirLimitController) finishReconcileResult(tracker *reconcileTracker, err error, endTime time.Time) {
+	tenants := make([]TenantReconcileOutcome, 0, len(tracker.outcomes))
+	counts := make(map[string]int)
+	for _, outcome := range tracker.outcomes {
+		tenants = append(tenants, *outcome)
+		counts[outcome.Outcome]++
+		if outcome.Outcome == TenantOutcomeSkipped {
+			metrics.TenantMetricsInstance.IncTenantsSkipped(skipReason(outcome))
+		}
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Tenant < tenants[j].Tenant })
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.ReconcileMetricsInstance.IncReconcileTotal(result)
+	metrics.ReconcileMetricsInstance.ObserveReconcileDuration(result, endTime.Sub(tracker.result.StartTime).Seconds())
+
+	r.resultsMu.Lock()
+	defer r.resultsMu.Unlock()
+
+	// Results are replaced rather than modified, as readers hold the previous copy
+	finished := *tracker.result
+	finished.EndTime = &endTime
+	finished.Tenants = tenants
+	finished.Counts = counts
+	if err != nil {
+		finished.Error = err.Error()
+	}
+	for i, result := range r.results {
+		if result == tracker.result {
+			r.results[i] = &finished
+		}
+	}
+	tracker.result = &finished
+	r.activeReconcile.CompareAndSwap(finished.ReconcileID, 0)
+}
+
+// skipReason returns the reason a skipped tenant was skipped for, the last one recorded
+func skipReason(outcome *TenantReconcileOutcome) string {
+	if len(outcome.Reasons) == 0 {
+		return "unknown"
+	}
+	return outcome.Reasons[len(outcome.Reasons)-1]
+}
+
+// set records the outcome of a tenant, keeping the reasons recorded before
+func (t *reconcileTracker) set(tenant, outcome, reason string, err error) {
+	entry := t.outcome(tenant)
+	entry.Outcome = outcome
+	if reason != "" {
+		entry.Reasons = append(entry.Reasons, reason)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+}
+
+// setAll records the same outcome for every tenant of the limits that has none yet
+func (t *reconcileTracker) setAll(limits map[string]*analyzer.TenantLimits, outcome, reason string) {
+	for tenant := range limits {
+		if t.decided(tenant) {
+			continue
+		}
+		t.set(tenant, outcome, reason, nil)
+	}
+}
+
+// addReason adds a reason to a tenant without deciding its outcome
+func (t *reconcileTracker) addReason(tenant, reason string) {
+	entry := t.outcome(tenant)
+	entry.Reasons = append(entry.Reasons, reason)
+}
+
+// clamped marks the tenants whose limits differ between two stages of the reconciliation
+func (t *reconcileTracker) clamped(before, after map[string]*analyzer.TenantLimits) {
+	for tenant, limits := range after {
+		if t.clampedTenants[tenant] || !limitsDiffer(before[tenant], limits) {
+			continue
+		}
+		t.clampedTenants[tenant] = true
+		t.addReason(tenant, ReconcileReasonClamped)
+	}
+}
+
+// applied records the outcome of writing the limits: tenants that were written changed
+// unless their limits equal the previous ones, and a tenant that kept its limits only
+// because they were clamped was skipped
+func (t *reconcileTracker) applied(previous, limits, written map[string]*analyzer.TenantLimits, err error) {
+	for tenant := range limits {
+		if t.decided(tenant) {
+			continue
+		}
+		if err != nil {
+			t.set(tenant, TenantOutcomeFailed, ReconcileReasonWriteFailed, err)
+			continue
+		}
+		writtenLimits, exists := written[tenant]
+		if !exists {
+			// Dropped by limit validation or held back by the write retry budget
+			t.set(tenant, TenantOutcomeSkipped, ReconcileReasonNotWritten, nil)
+			continue
+		}
+
+		previousLimits := previous[tenant]
+		switch {
+		case previousLimits == nil || limitsChanged(previousLimits, writtenLimits):
+			t.set(tenant, TenantOutcomeChanged, "", nil)
+		case t.clampedTenants[tenant]:
+			t.set(tenant, TenantOutcomeSkipped, "", nil)
+		default:
+			t.set(tenant, TenantOutcomeUnchanged, "", nil)
+		}
+	}
+}
+
+// decided reports whether a tenant already has an outcome
+func (t *reconcileTracker) decided(tenant string) bool {
+	entry, exists := t.outcomes[tenant]
+	return exists && entry.Outcome != ""
+}
+
+// outcome returns the outcome record of a tenant, creating it
+func (t *reconcileTracker) outcome(tenant string) *TenantReconcileOutcome {
+	entry, exists := t.outcomes[tenant]
+	if !exists {
+		entry = &TenantReconcileOutcome{Tenant: tenant}
+		t.outcomes[tenant] = entry
+	}
+	return entry
+}
+
+// limitsDiffer reports whether two tenant limits set a different value for any limit
+func limitsDiffer(a, b *analyzer.TenantLimits) bool {
+	if a == nil || b == nil {
+		return a != b
+	}
+	return len(a.Limits) != len(b.Limits) || limitsChanged(a, b)
+}
+
+// limitsChanged reports whether any limit of after has no or another value in before
+func limitsChanged(before, after *analyzer.TenantLimits) bool {
+	for name, value := range after.Limits {
+		old, exists := before.Limits[name]
+		if !exists || !config.LimitValuesEqual(old, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileAuditLogger stamps the audit entries written while a reconciliation runs
+// with its reconcile ID, so they can be correlated with its result
+type reconcileAuditLogger struct {
+	auditlog.AuditLogger
+	reconcileID *atomic.Int64
+}
+
+// LogEntry logs an entry under the running reconciliation, if any
+func (l *reconcileAuditLogger) LogEntry(entry *auditlog.AuditEntry) error {
+	if entry.ReconcileID == 0 {
+		entry.ReconcileID = l.reconcileID.Load()
+	}
+	return l.AuditLogger.LogEntry(entry)
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+)
+
+// metricDelta tracks how much metrics changed since it was created
+type metricDelta struct {
+	t      *testing.T
+	before map[string]float64
+	labels map[string]map[string]string
+}
+
+func newMetricDelta(t *testing.T) *metricDelta {
+	return &metricDelta{t: t, before: make(map[string]float64), labels: make(map[string]map[string]string)}
+}
+
+// watch records the current value of the metric with the label values under key
+func (d *metricDelta) watch(key, name string, labels map[string]string) {
+	d.labels[key] = labels
+	d.before[key+"\x00"+name] = metricstest.Value(d.t, name, labels)
+}
+
+// since returns how much the metric watched under key changed
+func (d *metricDelta) since(key, name string) float64 {
+	return metricstest.Value(d.t, name, d.labels[key]) - d.before[key+"\x00"+name]
+}
+
+// useAuditLogger has the controller and its patcher write audit entries through the
+// audit logger the manager wires, which exports them
+func (tc *testController) useAuditLogger() {
+	logger := auditlog.NewAuditLogger(tc.Config, tc.client, logr.Discard())
+	tc.AuditLogger = logger
+	configMapPatcher := patcher.NewConfigMapPatcher(tc.client, nil, tc.Config, logger, tc.Log)
+	configMapPatcher.SetTenantFilter(tc.tenantFilter.ShouldProcessTenant)
+	configMapPatcher.SetPauseFilter(tc.tenantFilter.IsPaused)
+	tc.Patcher = configMapPatcher
+}
+
+func TestReconcileExportsOutcomeMetrics(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	cfg.AuditLog.Enabled = true
+	cfg.AuditLog.StorageType = "memory"
+	tc := newTestController(cfg, overridesConfigMap(cfg, twoTenantOverrides))
+	tc.useAuditLogger()
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+	tc.GetTenantFilter().SetLists(nil, nil, []string{"tenant-a"}, scopingSourceConfigMap)
+
+	delta := newMetricDelta(t)
+	success := map[string]string{"result": "success"}
+	delta.watch("success", "mimir_limit_optimizer_reconcile_total", success)
+	delta.watch("success", "mimir_limit_optimizer_reconcile_duration_seconds", success)
+	delta.watch("paused", "mimir_limit_optimizer_reconcile_tenants_skipped_total", map[string]string{"reason": ReconcileReasonPaused})
+	delta.watch("ingestion_rate", "mimir_limit_optimizer_limit_changes_applied_total", map[string]string{"limit_type": "ingestion_rate"})
+	delta.watch("update-limits", "mimir_limit_optimizer_audit_entries_total", map[string]string{"action": "update-limits", "result": "success"})
+
+	if _, err := tc.reconcile(context.Background()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	tests := []struct {
+		key, name string
+		want      float64
+	}{
+		{"success", "mimir_limit_optimizer_reconcile_total", 1},
+		{"success", "mimir_limit_optimizer_reconcile_duration_seconds", 1},
+		{"paused", "mimir_limit_optimizer_reconcile_tenants_skipped_total", 1},
+		// Only tenant-b is optimized
+		{"ingestion_rate", "mimir_limit_optimizer_limit_changes_applied_total", 1},
+		{"update-limits", "mimir_limit_optimizer_audit_entries_total", 1},
+	}
+	for _, tt := range tests {
+		if got := delta.since(tt.key, tt.name); got != tt.want {
+			t.Errorf("%s{%v} rose by %v after the reconcile, want %v", tt.name, delta.labels[tt.key], got, tt.want)
+		}
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_circuit_breaker_current_state", map[string]string{"state": "CLOSED"}); got != 1 {
+		t.Errorf("circuit breaker CLOSED state = %v, want 1", got)
+	}
+}
+
+func TestFailedReconcileExportsError(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	funcs := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return errors.New("etcdserver: request timed out")
+		},
+	}
+	tc := newInterceptedTestController(cfg, funcs, overridesConfigMap(cfg, twoTenantOverrides))
+	tc.collector.setMetrics(ingestionMetrics(20000, "tenant-a", "tenant-b"))
+
+	delta := newMetricDelta(t)
+	failure := map[string]string{"result": "error"}
+	delta.watch("error", "mimir_limit_optimizer_reconcile_total", failure)
+	delta.watch("error", "mimir_limit_optimizer_reconcile_duration_seconds", failure)
+	delta.watch("success", "mimir_limit_optimizer_reconcile_total", map[string]string{"result": "success"})
+
+	if _, err := tc.reconcile(context.Background()); err == nil {
+		t.Fatalf("reconcile through a failing API server succeeded")
+	}
+	if got := delta.since("error", "mimir_limit_optimizer_reconcile_total"); got != 1 {
+		t.Errorf("failed reconciliations rose by %v, want 1", got)
+	}
+	if got := delta.since("error", "mimir_limit_optimizer_reconcile_duration_seconds"); got != 1 {
+		t.Errorf("failed reconciliation durations rose by %v, want 1", got)
+	}
+	if got := delta.since("success", "mimir_limit_optimizer_reconcile_total"); got != 0 {
+		t.Errorf("successful reconciliations rose by %v, want 0", got)
+	}
+}
+
+func TestCircuitBreakerStateMetrics(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	tc := newTestController(cfg)
+	bp := tc.BlastProtector
+
+	state := func(name string) float64 {
+		return metricstest.Value(t, "mimir_limit_optimizer_circuit_breaker_current_state", map[string]string{"state": name})
+	}
+	modes := func() (float64, float64) {
+		return metricstest.Value(t, "mimir_limit_optimizer_emergency_mode_active", nil),
+			metricstest.Value(t, "mimir_limit_optimizer_panic_mode_active", nil)
+	}
+
+	if err := bp.ForceOpen("bad deploy"); err != nil {
+		t.Fatalf("ForceOpen: %v", err)
+	}
+	if state("OPEN") != 1 || state("CLOSED") != 0 {
+		t.Errorf("forced open state: OPEN %v, CLOSED %v; want only OPEN", state("OPEN"), state("CLOSED"))
+	}
+
+	bp.EnterPanicMode("drill")
+	if emergency, panicking := modes(); emergency != 1 || panicking != 1 {
+		t.Errorf("in panic mode: emergency %v, panic %v; want both 1", emergency, panicking)
+	}
+
+	if err := bp.ForceClose("remediated"); err != nil {
+		t.Fatalf("ForceClose: %v", err)
+	}
+	if emergency, panicking := modes(); emergency != 0 || panicking != 0 {
+		t.Errorf("after ForceClose: emergency %v, panic %v; want both 0", emergency, panicking)
+	}
+	if state("HALF_OPEN") != 1 || state("OPEN") != 0 {
+		t.Errorf("after ForceClose: HALF_OPEN %v, OPEN %v; want only HALF_OPEN", state("HALF_OPEN"), state("OPEN"))
+	}
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"reflect"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ApplyConfig swaps in a reloaded configuration and rebuilds the state components
+// derived from it. It waits for a running reconciliation to finish, so every
+// reconciliation runs against a single configuration.
+//
+// The reloaded configuration is published to the live Config all components share,
+// so they read the new values without being recreated. The Config in use is never
+// modified.
+func (r *MimirLimitController) ApplyConfig(cfg *config.Config) {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	previous := r.config()
+	restartRequired := restartRequiredChanges(previous, cfg)
+
+	next := *cfg
+	// The limits enabled at runtime outlive the selection in the file
+	r.mergeLimitSelection(&next)
+
+	// So does the tenant scoping set at runtime, which the filter carries over
+	if r.tenantFilter != nil {
+		r.tenantFilter.ReloadConfig(&next)
+	} else {
+		r.Config.Store(&next)
+	}
+	r.deleteDisabledLimitSeries(previous, &next)
+
+	if r.BlastProtector != nil {
+		r.BlastProtector.ReloadConfig()
+	}
+	if manager := r.GetAlertManager(); manager != nil {
+		manager.Reload(&next.Alerting)
+	} else if next.Alerting.Enabled {
+		r.startAlertManager()
+	}
+
+	r.Log.Info("applied reloaded configuration", "hash", cfg.SourceHash, "mode", cfg.Mode)
+	if len(restartRequired) > 0 {
+		r.Log.Info("WARNING: some changed settings only take effect after a restart", "settings", restartRequired)
+	}
+}
+
+// UpdateConfig publishes a copy of the configuration changed by update, waiting for a
+// running reconciliation to finish. update must replace, not modify, the maps and slices
+// it changes. Changed tenant scoping lists are set through the tenant filter, which
+// compiles their patterns.
+func (r *MimirLimitController) UpdateConfig(update func(cfg *config.Config)) {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	if r.tenantFilter == nil {
+		r.Config.Update(update)
+		return
+	}
+
+	previous := r.config().TenantScoping
+	var next config.TenantScopingConfig
+	r.Config.Update(func(cfg *config.Config) {
+		update(cfg)
+		next = cfg.TenantScoping
+		cfg.TenantScoping.SkipList = previous.SkipList
+		cfg.TenantScoping.IncludeList = previous.IncludeList
+		cfg.TenantScoping.PausedTenants = previous.PausedTenants
+	})
+	if !reflect.DeepEqual(previous.SkipList, next.SkipList) || !reflect.DeepEqual(previous.IncludeList, next.IncludeList) ||
+		!reflect.DeepEqual(previous.PausedTenants, next.PausedTenants) {
+		r.tenantFilter.SetLists(next.SkipList, next.IncludeList, next.PausedTenants, r.tenantFilter.Source())
+	}
+}
+
+// restartRequiredChanges lists the changed settings that are only read at startup
+func restartRequiredChanges(previous, next *config.Config) []string {
+	settings := []struct {
+		name     string
+		previous interface{}
+		next     interface{}
+	}{
+		{"updateInterval", previous.UpdateInterval, next.UpdateInterval},
+		{"ui", previous.UI, next.UI},
+		{"metrics.extraLabels", previous.Metrics.ExtraLabels, next.Metrics.ExtraLabels},
+		{"healthScanner.historyRetention", previous.HealthScanner.HistoryRetention, next.HealthScanner.HistoryRetention},
+		{"healthScanner.watchCache", previous.HealthScanner.WatchCache, next.HealthScanner.WatchCache},
+		{"recommendationHistory.enabled", previous.RecommendationHistory.Enabled, next.RecommendationHistory.Enabled},
+		{"recommendationHistory.storageType", previous.RecommendationHistory.StorageType, next.RecommendationHistory.StorageType},
+		{"recommendationHistory.configMapName", previous.RecommendationHistory.ConfigMapName, next.RecommendationHistory.ConfigMapName},
+		{"recommendationHistory.maxEntriesPerTenant", previous.RecommendationHistory.MaxEntriesPerTenant, next.RecommendationHistory.MaxEntriesPerTenant},
+		{"performance", previous.Performance, next.Performance},
+		{"mimir.secondaryCluster", previous.Mimir.SecondaryCluster, next.Mimir.SecondaryCluster},
+		{"limits.remoteOverrideSource", previous.Limits.RemoteOverrideSource, next.Limits.RemoteOverrideSource},
+		{"alerting.email.digest", previous.Alerting.Email.Digest, next.Alerting.Email.Digest},
+	}
+
+	var changed []string
+	for _, setting := range settings {
+		if !reflect.DeepEqual(setting.previous, setting.next) {
+			changed = append(changed, setting.name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func newReloadTestController(cfg *config.Config) *MimirLimitController {
+	r := &MimirLimitController{Config: config.NewLive(cfg), Log: logr.Discard()}
+	r.tenantFilter = NewTenantFilter(r.Config, r.Log)
+	return r
+}
+
+func TestApplyConfigPublishesNewConfig(t *testing.T) {
+	previous := config.GetDefaultConfig()
+	previous.Mode = "dry-run"
+	previous.Alerting.Enabled = false
+	r := newReloadTestController(previous)
+
+	reloaded := config.GetDefaultConfig()
+	reloaded.Mode = "prod"
+	reloaded.Alerting.Enabled = false
+	reloaded.SourceHash = "reloaded"
+	r.ApplyConfig(reloaded)
+
+	if got := r.config().SourceHash; got != "reloaded" {
+		t.Errorf("SourceHash = %q, want the reloaded configuration", got)
+	}
+	if got := r.config().Mode; got != "prod" {
+		t.Errorf("Mode = %q, want %q", got, "prod")
+	}
+	if previous.Mode != "dry-run" {
+		t.Errorf("ApplyConfig modified the configuration in use: mode %q", previous.Mode)
+	}
+}
+
+func TestApplyConfigKeepsRuntimeScoping(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	cfg.TenantScoping.SkipList = []string{"file-*"}
+	r := newReloadTestController(cfg)
+	r.tenantFilter.SetLists([]string{"runtime-*"}, nil, []string{"paused"}, scopingSourceConfigMap)
+
+	reloaded := config.GetDefaultConfig()
+	reloaded.Alerting.Enabled = false
+	reloaded.TenantScoping.SkipList = []string{"reloaded-*"}
+	r.ApplyConfig(reloaded)
+
+	scoping := r.config().TenantScoping
+	if !reflect.DeepEqual(scoping.SkipList, []string{"runtime-*"}) {
+		t.Errorf("skip list = %v, want the runtime list", scoping.SkipList)
+	}
+	if !reflect.DeepEqual(scoping.PausedTenants, []string{"paused"}) {
+		t.Errorf("paused tenants = %v, want the runtime list", scoping.PausedTenants)
+	}
+	if r.tenantFilter.ShouldProcessTenant("runtime-a") {
+		t.Errorf("tenant matching the runtime skip list was processed")
+	}
+
+	r.tenantFilter.ResetLists()
+	if got := r.config().TenantScoping.SkipList; !reflect.DeepEqual(got, []string{"reloaded-*"}) {
+		t.Errorf("skip list after reset = %v, want the reloaded file's list", got)
+	}
+}
+
+func TestApplyConfigKeepsRuntimeLimitSelection(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	r := newReloadTestController(cfg)
+	r.applyLimitSelection(&limitSelection{EnabledLimits: []string{"ingestion_rate"}})
+
+	reloaded := config.GetDefaultConfig()
+	reloaded.Alerting.Enabled = false
+	r.ApplyConfig(reloaded)
+
+	if got := r.config().Limits.EnabledLimits; !reflect.DeepEqual(got, []string{"ingestion_rate"}) {
+		t.Errorf("enabled limits = %v, want the runtime selection", got)
+	}
+	if len(reloaded.Limits.EnabledLimits) != 0 {
+		t.Errorf("ApplyConfig modified the reloaded configuration: %v", reloaded.Limits.EnabledLimits)
+	}
+}
+
+func TestUpdateConfigCompilesScopingPatterns(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	cfg.TenantScoping.UseRegex = true
+	r := newReloadTestController(cfg)
+
+	r.UpdateConfig(func(cfg *config.Config) {
+		cfg.Mode = "prod"
+		cfg.TenantScoping.SkipList = []string{"^internal-[0-9]+$"}
+	})
+	if r.config().Mode != "prod" {
+		t.Errorf("mode = %q, want the update applied", r.config().Mode)
+	}
+	if r.tenantFilter.ShouldProcessTenant("internal-42") {
+		t.Errorf("tenant matching the updated skip regex was processed")
+	}
+	if !r.tenantFilter.ShouldProcessTenant("team-a") {
+		t.Errorf("tenant not matching the updated skip regex was skipped")
+	}
+
+	r.UpdateConfig(func(cfg *config.Config) {
+		cfg.TenantScoping.IncludeList = []string{"^team-(a|b)$"}
+	})
+	if !r.tenantFilter.ShouldProcessTenant("team-b") || r.tenantFilter.ShouldProcessTenant("team-c") {
+		t.Errorf("tenants processed with the updated include regex: team-b %v, team-c %v; want only team-b",
+			r.tenantFilter.ShouldProcessTenant("team-b"), r.tenantFilter.ShouldProcessTenant("team-c"))
+	}
+	if got := r.config().TenantScoping.SkipList; !reflect.DeepEqual(got, []string{"^internal-[0-9]+$"}) {
+		t.Errorf("skip list after updating the include list = %v, want it kept", got)
+	}
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// deadLetterConfigMapName records the tenants held back after exhausting their retry budget
+	deadLetterConfigMapName = "mimir-optimizer-dead-letter"
+	deadLetterDataKey       = "entries.yaml"
+
+	// isolatedWriteProbe is how many tenants are written one by one after a failed write
+	// before concluding the API server fails every write, not just some tenants
+	isolatedWriteProbe = 3
+)
+
+// tenantWriteBudget tracks the failed ConfigMap writes of one tenant. Values stored in
+// writeBudgets are replaced rather than modified.
+type tenantWriteBudget struct {
+	Attempts    int
+	LastError   string
+	LastFailure time.Time
+	// HeldUntil is set once the budget is exhausted; writes are skipped until then
+	HeldUntil time.Time
+}
+
+// DeadLetterEntry describes a tenant held back after exhausting its retry budget
+type DeadLetterEntry struct {
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"lastError"`
+	LastFailure time.Time `json:"lastFailure"`
+	RetryAfter  time.Time `json:"retryAfter"`
+}
+
+// retryBudgetEnabled reports whether failed writes are charged to per-tenant budgets
+func (r *MimirLimitController) retryBudgetEnabled() bool {
+	return r.config().Performance.Enabled && r.config().Performance.RetryBudget.MaxAttemptsPerTenant > 0
+}
+
+// applyLimits writes the limits and returns those that were applied. With a retry budget,
+// tenants that exhausted it are held back, and after a failed write the changed tenants
+// are written one by one, so a tenant that cannot be written does not block the others.
+// Limit values failing validation are never written.
+func (r *MimirLimitController) applyLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) (map[string]*analyzer.TenantLimits, error) {
+	limits = r.withValidLimits(limits)
+	if !r.retryBudgetEnabled() {
+		return limits, r.Patcher.ApplyLimits(ctx, limits)
+	}
+
+	limits = r.withoutHeldTenants(ctx, limits, time.Now())
+
+	err := r.Patcher.ApplyLimits(ctx, limits)
+	if err == nil {
+		for tenant := range limits {
+			r.recordWriteSuccess(tenant)
+		}
+		return limits, nil
+	}
+
+	preview, previewErr := r.Patcher.PreviewLimits(ctx, limits)
+	if previewErr != nil || len(preview.AffectedTenants) == 0 {
+		// Without knowing which tenants changed, no tenant can be blamed
+		return nil, err
+	}
+
+	r.Log.Info("ConfigMap write failed, writing changed tenants one by one",
+		"error", err.Error(),
+		"tenants_changed", len(preview.AffectedTenants))
+
+	applied := make(map[string]*analyzer.TenantLimits, len(limits))
+	for tenant, tenantLimits := range limits {
+		applied[tenant] = tenantLimits
+	}
+
+	failed := 0
+	for i, tenant := range preview.AffectedTenants {
+		if i == isolatedWriteProbe && failed == i {
+			// Every write fails, so charge the remaining tenants without writing them
+			for _, remaining := range preview.AffectedTenants[i:] {
+				r.recordWriteFailure(ctx, remaining, err)
+				delete(applied, remaining)
+			}
+			failed = len(preview.AffectedTenants)
+			break
+		}
+
+		tenantErr := r.Patcher.ApplyLimits(ctx, map[string]*analyzer.TenantLimits{tenant: limits[tenant]})
+		if tenantErr != nil {
+			failed++
+			r.recordWriteFailure(ctx, tenant, tenantErr)
+			delete(applied, tenant)
+			continue
+		}
+		r.recordWriteSuccess(tenant)
+	}
+
+	if failed == len(preview.AffectedTenants) {
+		return nil, err
+	}
+
+	r.Log.Info("applied limits tenant by tenant after a failed write",
+		"tenants_written", len(preview.AffectedTenants)-failed,
+		"tenants_failed", failed)
+	return applied, nil
+}
+
+// withoutHeldTenants drops the tenants whose retry budget is exhausted and releases
+// those whose hold has ended
+func (r *MimirLimitController) withoutHeldTenants(ctx context.Context, limits map[string]*analyzer.TenantLimits, now time.Time) map[string]*analyzer.TenantLimits {
+	var held, released []string
+	r.writeBudgets.Range(func(key, value interface{}) bool {
+		tenant := key.(string)
+		budget := value.(*tenantWriteBudget)
+		switch {
+		case budget.HeldUntil.IsZero():
+		case now.Before(budget.HeldUntil):
+			if _, exists := limits[tenant]; exists {
+				held = append(held, tenant)
+			}
+		default:
+			r.writeBudgets.Delete(tenant)
+			released = append(released, tenant)
+		}
+		return true
+	})
+
+	if len(released) > 0 {
+		sort.Strings(released)
+		r.Log.Info("retry budget reset, retrying held back tenants", "tenants", released)
+		r.removeDeadLetterEntries(ctx, released)
+	}
+	if len(held) == 0 {
+		return limits
+	}
+
+	sort.Strings(held)
+	r.Log.Info("skipping tenants that exhausted their ConfigMap write retry budget", "tenants", held)
+
+	filtered := make(map[string]*analyzer.TenantLimits, len(limits)-len(held))
+	for tenant, tenantLimits := range limits {
+		filtered[tenant] = tenantLimits
+	}
+	for _, tenant := range held {
+		delete(filtered, tenant)
+	}
+	return filtered
+}
+
+// recordWriteFailure charges a failed write to the tenant's budget and holds the tenant
+// back once the budget is exhausted
+func (r *MimirLimitController) recordWriteFailure(ctx context.Context, tenant string, err error) {
+	budget := &tenantWriteBudget{}
+	if value, ok := r.writeBudgets.Load(tenant); ok {
+		*budget = *value.(*tenantWriteBudget)
+	}
+
+	now := time.Now()
+	budget.Attempts++
+	budget.LastError = err.Error()
+	budget.LastFailure = now
+
+	maxAttempts := r.config().Performance.RetryBudget.MaxAttemptsPerTenant
+	if budget.Attempts < maxAttempts {
+		r.writeBudgets.Store(tenant, budget)
+		r.Log.Info("failed to write tenant limits", "tenant", tenant,
+			"attempts", budget.Attempts, "max_attempts", maxAttempts, "error", budget.LastError)
+		return
+	}
+
+	budget.HeldUntil = now.Add(r.config().Performance.RetryBudget.BudgetResetInterval)
+	r.writeBudgets.Store(tenant, budget)
+	metrics.ConfigMapMetricsInstance.IncDeadLetterEntries()
+
+	r.Log.Error(err, "tenant exhausted its ConfigMap write retry budget, holding it back",
+		"tenant", tenant,
+		"attempts", budget.Attempts,
+		"retry_after", budget.HeldUntil)
+
+	if !r.config().Performance.RetryBudget.DeadLetterEnabled {
+		return
+	}
+	entry := DeadLetterEntry{
+		Attempts:    budget.Attempts,
+		LastError:   budget.LastError,
+		LastFailure: budget.LastFailure,
+		RetryAfter:  budget.HeldUntil,
+	}
+	if err := r.updateDeadLetter(ctx, func(entries map[string]DeadLetterEntry) {
+		entries[tenant] = entry
+	}); err != nil {
+		r.Log.Error(err, "failed to record tenant in dead-letter ConfigMap", "tenant", tenant)
+	}
+}
+
+// recordWriteSuccess clears the tenant's failed writes
+func (r *MimirLimitController) recordWriteSuccess(tenant string) {
+	if _, loaded := r.writeBudgets.LoadAndDelete(tenant); loaded {
+		r.Log.Info("tenant limits written after earlier failures", "tenant", tenant)
+	}
+}
+
+// removeDeadLetterEntries drops released tenants from the dead-letter ConfigMap
+func (r *MimirLimitController) removeDeadLetterEntries(ctx context.Context, tenants []string) {
+	if !r.config().Performance.RetryBudget.DeadLetterEnabled {
+		return
+	}
+	if err := r.updateDeadLetter(ctx, func(entries map[string]DeadLetterEntry) {
+		for _, tenant := range tenants {
+			delete(entries, tenant)
+		}
+	}); err != nil {
+		r.Log.Error(err, "failed to remove tenants from dead-letter ConfigMap", "tenants", tenants)
+	}
+}
+
+// updateDeadLetter applies update to the entries of the dead-letter ConfigMap, creating it
+// when needed
+func (r *MimirLimitController) updateDeadLetter(ctx context.Context, update func(entries map[string]DeadLetterEntry)) error {
+	if r.KubeClient == nil {
+		return nil
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config()))
+	configMap, err := configMaps.Get(ctx, deadLetterConfigMapName, metav1.GetOptions{})
+	notFound := apierrors.IsNotFound(err)
+	if err != nil && !notFound {
+		return fmt.Errorf("failed to get dead-letter ConfigMap: %w", err)
+	}
+
+	entries := make(map[string]DeadLetterEntry)
+	if !notFound {
+		if err := yaml.Unmarshal([]byte(configMap.Data[deadLetterDataKey]), &entries); err != nil {
+			r.Log.Error(err, "failed to parse dead-letter ConfigMap, replacing its entries")
+			entries = make(map[string]DeadLetterEntry)
+		}
+	}
+	update(entries)
+
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entries: %w", err)
+	}
+
+	if notFound {
+		if len(entries) == 0 {
+			return nil
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      deadLetterConfigMapName,
+				Namespace: lockNamespace(r.config()),
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "mimir-limit-optimizer",
+					"app.kubernetes.io/component": "dead-letter",
+				},
+			},
+			Data: map[string]string{deadLetterDataKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create dead-letter ConfigMap: %w", err)
+		}
+		return nil
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[deadLetterDataKey] = string(data)
+	if _, err := configMaps.Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update dead-letter ConfigMap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// failingTenantWrites fails every write of the runtime overrides ConfigMap that holds
+// tenant, as an API server rejecting that tenant's overrides would, and counts them
+type failingTenantWrites struct {
+	tenant string
+
+	mu     sync.Mutex
+	failed int
+}
+
+func (f *failingTenantWrites) funcs() interceptor.Funcs {
+	return interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if configMap, ok := obj.(*corev1.ConfigMap); ok && strings.Contains(configMap.Data["overrides.yaml"], f.tenant+":") {
+				f.mu.Lock()
+				f.failed++
+				f.mu.Unlock()
+				return errors.New("etcdserver: request timed out")
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	}
+}
+
+func (f *failingTenantWrites) failures() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failed
+}
+
+func newRetryBudgetTestController(writes *failingTenantWrites) *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	cfg.Performance.Enabled = true
+	cfg.Performance.RetryBudget = config.RetryBudgetConfig{
+		MaxAttemptsPerTenant: 5,
+		BudgetResetInterval:  time.Hour,
+		DeadLetterEnabled:    true,
+	}
+	return newInterceptedTestController(cfg, writes.funcs(), overridesConfigMap(cfg, "overrides: {}\n"))
+}
+
+// deadLetterEntries returns the entries of the dead-letter ConfigMap
+func (tc *testController) deadLetterEntries(t *testing.T) map[string]DeadLetterEntry {
+	t.Helper()
+	configMap, err := tc.KubeClient.CoreV1().ConfigMaps(lockNamespace(tc.config())).Get(context.Background(), deadLetterConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get dead-letter ConfigMap: %v", err)
+	}
+	entries := make(map[string]DeadLetterEntry)
+	if err := yaml.Unmarshal([]byte(configMap.Data[deadLetterDataKey]), &entries); err != nil {
+		t.Fatalf("parse dead-letter entries: %v", err)
+	}
+	return entries
+}
+
+func TestRetryBudgetDeadLettersFailingTenant(t *testing.T) {
+	writes := &failingTenantWrites{tenant: "tenant-bad"}
+	tc := newRetryBudgetTestController(writes)
+	ctx := context.Background()
+	deadLettered := metricstest.Value(t, "mimir_limit_optimizer_dead_letter_entries_total", nil)
+
+	for i := 1; i <= 5; i++ {
+		// Usage grows each reconcile, so every tenant has new limits to write
+		tc.collector.setMetrics(ingestionMetrics(float64(10000*i), "tenant-a", "tenant-b", "tenant-bad"))
+		if _, err := tc.reconcile(ctx); err != nil {
+			t.Fatalf("reconcile %d: %v", i, err)
+		}
+
+		overrides := tc.tenantOverrides(t)
+		for _, tenant := range []string{"tenant-a", "tenant-b"} {
+			if _, exists := overrides[tenant]; !exists {
+				t.Fatalf("reconcile %d did not write %s while tenant-bad was failing", i, tenant)
+			}
+		}
+		if _, exists := overrides["tenant-bad"]; exists {
+			t.Fatalf("reconcile %d wrote tenant-bad through a failing API server", i)
+		}
+	}
+
+	entries := tc.deadLetterEntries(t)
+	entry, exists := entries["tenant-bad"]
+	if len(entries) != 1 || !exists {
+		t.Fatalf("dead-letter entries = %v, want only tenant-bad", entries)
+	}
+	if entry.Attempts != 5 || !strings.Contains(entry.LastError, "request timed out") {
+		t.Errorf("dead-letter entry = %+v, want 5 attempts and the API error", entry)
+	}
+	if !entry.RetryAfter.After(time.Now().Add(59 * time.Minute)) {
+		t.Errorf("retry after = %v, want the budget reset interval from now", entry.RetryAfter)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_dead_letter_entries_total", nil) - deadLettered; got != 1 {
+		t.Errorf("mimir_limit_optimizer_dead_letter_entries_total increased by %v, want 1", got)
+	}
+
+	// Held back, tenant-bad is no longer written while the others keep reconciling
+	failures := writes.failures()
+	rate := tc.ingestionRateOf(t, "tenant-a")
+	tc.collector.setMetrics(ingestionMetrics(60000, "tenant-a", "tenant-b", "tenant-bad"))
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile while held back: %v", err)
+	}
+	if got := writes.failures(); got != failures {
+		t.Errorf("tenant-bad was written %d more time(s) after exhausting its budget", got-failures)
+	}
+	if got := tc.ingestionRateOf(t, "tenant-a"); got <= rate {
+		t.Errorf("tenant-a ingestion rate = %v, want it raised above %v for the new usage", got, rate)
+	}
+}
+
+// tenantLimitsOf returns an ingestion_rate limit for each tenant
+func tenantLimitsOf(tenants ...string) map[string]*analyzer.TenantLimits {
+	limits := make(map[string]*analyzer.TenantLimits, len(tenants))
+	for _, tenant := range tenants {
+		limits[tenant] = &analyzer.TenantLimits{Tenant: tenant, Limits: map[string]interface{}{"ingestion_rate": 10000}}
+	}
+	return limits
+}
+
+func TestRetryBudgetReleasesHeldTenant(t *testing.T) {
+	writes := &failingTenantWrites{tenant: "tenant-bad"}
+	tc := newRetryBudgetTestController(writes)
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		tc.recordWriteFailure(ctx, "tenant-bad", errors.New("etcdserver: request timed out"))
+	}
+	if _, exists := tc.deadLetterEntries(t)["tenant-bad"]; !exists {
+		t.Fatalf("tenant-bad was not dead-lettered")
+	}
+
+	limits := tc.withoutHeldTenants(ctx, tenantLimitsOf("tenant-a", "tenant-bad"), time.Now())
+	if _, held := limits["tenant-bad"]; held {
+		t.Errorf("tenant-bad was not held back before the reset interval")
+	}
+
+	limits = tc.withoutHeldTenants(ctx, tenantLimitsOf("tenant-a", "tenant-bad"), time.Now().Add(2*time.Hour))
+	if _, released := limits["tenant-bad"]; !released {
+		t.Errorf("tenant-bad was still held back after the reset interval")
+	}
+	if entries := tc.deadLetterEntries(t); len(entries) != 0 {
+		t.Errorf("dead-letter entries after release = %v, want none", entries)
+	}
+}
+
+func TestRecordWriteSuccessResetsBudget(t *testing.T) {
+	tc := newRetryBudgetTestController(&failingTenantWrites{tenant: "tenant-bad"})
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		tc.recordWriteFailure(ctx, "tenant-a", errors.New("conflict"))
+	}
+	tc.recordWriteSuccess("tenant-a")
+	tc.recordWriteFailure(ctx, "tenant-a", errors.New("conflict"))
+
+	value, _ := tc.writeBudgets.Load("tenant-a")
+	if budget := value.(*tenantWriteBudget); budget.Attempts != 1 || !budget.HeldUntil.IsZero() {
+		t.Errorf("budget after a successful write = %+v, want the count to restart", budget)
+	}
+}
@@ -0,0 +1,457 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// Tenant scoping lists that can be changed at runtime
+const (
+	ScopingListSkip    = "skip"
+	ScopingListInclude = "include"
+)
+
+// Tenant scoping operations
+const (
+	ScopingOperationAdd    = "add"
+	ScopingOperationRemove = "remove"
+)
+
+// Sources of the active tenant scoping lists
+const (
+	scopingSourceConfig    = "config"
+	scopingSourceConfigMap = "configmap"
+)
+
+const (
+	// scopingDataKey is the ConfigMap key holding the persisted scoping lists
+	scopingDataKey = "scoping.yaml"
+
+	// scopingWatchRetryInterval paces re-establishing a closed or failed watch
+	scopingWatchRetryInterval = 5 * time.Second
+)
+
+// ErrScopingPatternNotFound is returned when removing a pattern that is not in the list
+var ErrScopingPatternNotFound = errors.New("pattern not found")
+
+// InvalidPatternError reports a tenant pattern that is not a valid glob or regex
+type InvalidPatternError struct {
+	Pattern string
+	Reason  string
+}
+
+func (e *InvalidPatternError) Error() string {
+	return fmt.Sprintf("invalid tenant pattern %q: %s", e.Pattern, e.Reason)
+}
+
+// scopingDocument is the persisted form of the runtime scoping lists
+type scopingDocument struct {
+	SkipList    []string `json:"skipList"`
+	IncludeList []string `json:"includeList"`
+	// PausedTenants is unset in documents written before tenants could be paused, which
+	// keep the active paused list
+	PausedTenants []string  `json:"pausedTenants"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	UpdatedBy     string    `json:"updatedBy,omitempty"`
+}
+
+// ScopingChange describes a runtime change of the tenant scoping lists and the
+// tenants whose status it flipped
+type ScopingChange struct {
+	Operation      string   `json:"operation"`
+	List           string   `json:"list"`
+	Patterns       []string `json:"patterns"`
+	SkipList       []string `json:"skip_list"`
+	IncludeList    []string `json:"include_list"`
+	NewlySkipped   []string `json:"newly_skipped"`
+	NewlyMonitored []string `json:"newly_monitored"`
+	Persisted      bool     `json:"persisted"`
+}
+
+// UpdateTenantScoping adds patterns to or removes them from the skip or include list,
+// persists the resulting lists to the runtime scoping ConfigMap and records an audit
+// entry listing the tenants whose monitored or skipped status flipped
+func (r *MimirLimitController) UpdateTenantScoping(ctx context.Context, operation, list string, patterns []string, user string) (*ScopingChange, error) {
+	if operation != ScopingOperationAdd && operation != ScopingOperationRemove {
+		return nil, fmt.Errorf("unsupported scoping operation %q", operation)
+	}
+	if list != ScopingListSkip && list != ScopingListInclude {
+		return nil, fmt.Errorf("list must be %q or %q, got %q", ScopingListSkip, ScopingListInclude, list)
+	}
+	if len(patterns) == 0 {
+		return nil, &InvalidPatternError{Reason: "at least one pattern is required"}
+	}
+
+	filter := r.GetTenantFilter()
+	for _, pattern := range patterns {
+		if err := filter.ValidatePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	tenants := r.knownTenants(ctx)
+	monitoredBefore, _ := filter.FilterTenants(tenants)
+	oldSkip, oldInclude := filter.Lists()
+
+	document, persisted, err := r.updateScopingDocument(ctx, user, func(document *scopingDocument) error {
+		var err error
+		document.SkipList, document.IncludeList, err = applyScopingChange(document.SkipList, document.IncludeList, operation, list, patterns)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	skipList, includeList := document.SkipList, document.IncludeList
+
+	monitoredAfter, _ := filter.FilterTenants(tenants)
+
+	change := &ScopingChange{
+		Operation:      operation,
+		List:           list,
+		Patterns:       patterns,
+		SkipList:       skipList,
+		IncludeList:    includeList,
+		NewlySkipped:   setDifference(monitoredBefore, monitoredAfter),
+		NewlyMonitored: setDifference(monitoredAfter, monitoredBefore),
+		Persisted:      persisted,
+	}
+
+	r.Log.Info("tenant scoping changed", "operation", operation, "list", list, "patterns", patterns,
+		"newly_skipped", len(change.NewlySkipped), "newly_monitored", len(change.NewlyMonitored))
+
+	if r.AuditLogger != nil {
+		entry := &auditlog.AuditEntry{
+			Action: "tenant-scoping-change",
+			Reason: fmt.Sprintf("%s-%s-list", operation, list),
+			Source: "api",
+			User:   user,
+			Changes: map[string]interface{}{
+				"operation":       operation,
+				"list":            list,
+				"patterns":        patterns,
+				"newly_skipped":   change.NewlySkipped,
+				"newly_monitored": change.NewlyMonitored,
+			},
+			OldValues: map[string]interface{}{"skip_list": oldSkip, "include_list": oldInclude},
+			NewValues: map[string]interface{}{"skip_list": skipList, "include_list": includeList},
+			Success:   true,
+		}
+		if err := r.AuditLogger.LogEntry(entry); err != nil {
+			r.Log.Error(err, "failed to log tenant scoping change")
+		}
+	}
+
+	return change, nil
+}
+
+// updateScopingDocument applies a change to the persisted scoping lists, starting from
+// the active lists when nothing is persisted yet, makes the result active and reports
+// whether it was persisted to the runtime scoping ConfigMap
+func (r *MimirLimitController) updateScopingDocument(ctx context.Context, user string, change func(document *scopingDocument) error) (*scopingDocument, bool, error) {
+	filter := r.GetTenantFilter()
+	active := func() *scopingDocument {
+		skipList, includeList := filter.Lists()
+		return &scopingDocument{SkipList: skipList, IncludeList: includeList, PausedTenants: filter.PausedTenants()}
+	}
+
+	var document *scopingDocument
+	persisted := false
+	if r.KubeClient != nil {
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			configMap, current, err := r.readScopingConfigMap(ctx)
+			if err != nil {
+				return err
+			}
+			if current == nil {
+				current = active()
+			} else if current.PausedTenants == nil {
+				// Written before tenants could be paused
+				current.PausedTenants = filter.PausedTenants()
+			}
+
+			if err := change(current); err != nil {
+				return err
+			}
+			current.UpdatedAt = time.Now()
+			current.UpdatedBy = user
+			document = current
+			return r.writeScopingConfigMap(ctx, configMap, current)
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		persisted = true
+	} else {
+		document = active()
+		if err := change(document); err != nil {
+			return nil, false, err
+		}
+		r.Log.Info("no Kubernetes client, tenant scoping change is not persisted")
+	}
+
+	filter.SetLists(document.SkipList, document.IncludeList, document.PausedTenants, scopingSourceConfigMap)
+	return document, persisted, nil
+}
+
+// applyScopingChange returns the skip and include lists with the patterns added or removed
+func applyScopingChange(skipList, includeList []string, operation, list string, patterns []string) ([]string, []string, error) {
+	target := &skipList
+	if list == ScopingListInclude {
+		target = &includeList
+	}
+	updated := append([]string{}, (*target)...)
+
+	for _, pattern := range patterns {
+		index := -1
+		for i, existing := range updated {
+			if existing == pattern {
+				index = i
+				break
+			}
+		}
+
+		switch operation {
+		case ScopingOperationAdd:
+			if index < 0 {
+				updated = append(updated, pattern)
+			}
+		case ScopingOperationRemove:
+			if index < 0 {
+				return nil, nil, fmt.Errorf("%w: %q is not in the %s list", ErrScopingPatternNotFound, pattern, list)
+			}
+			updated = append(updated[:index], updated[index+1:]...)
+		}
+	}
+
+	*target = updated
+	return append([]string{}, skipList...), append([]string{}, includeList...), nil
+}
+
+// knownTenants returns the tenants reported by the collector, or none when they cannot be listed
+func (r *MimirLimitController) knownTenants(ctx context.Context) []string {
+	if r.Collector == nil {
+		return nil
+	}
+	tenants, err := r.Collector.GetTenantList(ctx)
+	if err != nil {
+		r.Log.Error(err, "failed to list tenants for tenant scoping")
+		return nil
+	}
+	return tenants
+}
+
+// readScopingConfigMap reads the runtime scoping ConfigMap. Both results are nil when
+// it does not exist yet.
+func (r *MimirLimitController) readScopingConfigMap(ctx context.Context) (*corev1.ConfigMap, *scopingDocument, error) {
+	name := r.config().TenantScoping.RuntimeConfigMapName
+	configMap, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tenant scoping ConfigMap %s: %w", name, err)
+	}
+
+	document, err := parseScopingConfigMap(configMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return configMap, document, nil
+}
+
+// writeScopingConfigMap creates the runtime scoping ConfigMap or updates the given one
+func (r *MimirLimitController) writeScopingConfigMap(ctx context.Context, existing *corev1.ConfigMap, document *scopingDocument) error {
+	data, err := yaml.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant scoping: %w", err)
+	}
+
+	configMaps := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config()))
+	if existing == nil {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      r.config().TenantScoping.RuntimeConfigMapName,
+				Namespace: lockNamespace(r.config()),
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "mimir-limit-optimizer",
+					"app.kubernetes.io/component": "tenant-scoping",
+				},
+			},
+			Data: map[string]string{scopingDataKey: string(data)},
+		}
+		_, err = configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// Another replica created it first; retry against its copy
+			return apierrors.NewConflict(corev1.Resource("configmaps"), configMap.Name, err)
+		}
+		return err
+	}
+
+	configMap := existing.DeepCopy()
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[scopingDataKey] = string(data)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
+
+// parseScopingConfigMap decodes the scoping lists stored in a ConfigMap
+func parseScopingConfigMap(configMap *corev1.ConfigMap) (*scopingDocument, error) {
+	document := &scopingDocument{}
+	if err := yaml.Unmarshal([]byte(configMap.Data[scopingDataKey]), document); err != nil {
+		return nil, fmt.Errorf("failed to parse tenant scoping ConfigMap %s: %w", configMap.Name, err)
+	}
+	return document, nil
+}
+
+// applyScopingDocument makes the persisted lists active, or restores the configured
+// lists when the ConfigMap is gone
+func (r *MimirLimitController) applyScopingDocument(document *scopingDocument) {
+	filter := r.GetTenantFilter()
+	if document == nil {
+		if filter.Source() == scopingSourceConfigMap {
+			r.Log.Info("tenant scoping ConfigMap removed, restoring configured skip, include and paused lists")
+		}
+		filter.ResetLists()
+		return
+	}
+
+	pausedTenants := document.PausedTenants
+	if pausedTenants == nil {
+		pausedTenants = filter.PausedTenants()
+	}
+
+	patterns := append(append([]string(nil), document.SkipList...), document.IncludeList...)
+	for _, pattern := range append(patterns, pausedTenants...) {
+		if err := filter.ValidatePattern(pattern); err != nil {
+			metrics.HealthMetricsInstance.IncErrorTotal("controller", "tenant-scoping")
+			r.Log.Error(err, "tenant scoping ConfigMap contains an invalid pattern, keeping the current lists")
+			return
+		}
+	}
+	filter.SetLists(document.SkipList, document.IncludeList, pausedTenants, scopingSourceConfigMap)
+	r.Log.V(1).Info("applied tenant scoping from ConfigMap",
+		"skip_list", document.SkipList, "include_list", document.IncludeList, "paused_tenants", pausedTenants)
+}
+
+// startTenantScopingWatch loads the runtime scoping ConfigMap and watches it, so
+// changes made through any replica's API reach every replica
+func (r *MimirLimitController) startTenantScopingWatch(ctx context.Context) {
+	if r.KubeClient == nil || r.config().TenantScoping.RuntimeConfigMapName == "" {
+		return
+	}
+
+	resourceVersion, err := r.loadTenantScoping(ctx)
+	go func() {
+		for {
+			if err == nil {
+				err = r.watchTenantScoping(ctx, resourceVersion)
+			}
+			if err != nil {
+				r.Log.Error(err, "tenant scoping watch failed, retrying", "retry_in", scopingWatchRetryInterval)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(scopingWatchRetryInterval):
+			}
+			resourceVersion, err = r.loadTenantScoping(ctx)
+		}
+	}()
+}
+
+// loadTenantScoping applies the current content of the runtime scoping ConfigMap and
+// returns the resource version to watch from
+func (r *MimirLimitController) loadTenantScoping(ctx context.Context) (string, error) {
+	name := r.config().TenantScoping.RuntimeConfigMapName
+	list, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list tenant scoping ConfigMap %s: %w", name, err)
+	}
+
+	if len(list.Items) == 0 {
+		r.applyScopingDocument(nil)
+		return list.ResourceVersion, nil
+	}
+
+	document, err := parseScopingConfigMap(&list.Items[0])
+	if err != nil {
+		return "", err
+	}
+	r.applyScopingDocument(document)
+	return list.ResourceVersion, nil
+}
+
+// watchTenantScoping applies changes of the runtime scoping ConfigMap until the watch
+// ends or ctx is cancelled
+func (r *MimirLimitController) watchTenantScoping(ctx context.Context, resourceVersion string) error {
+	watcher, err := r.KubeClient.CoreV1().ConfigMaps(lockNamespace(r.config())).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", r.config().TenantScoping.RuntimeConfigMapName).String(),
+		ResourceVersion: resourceVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch tenant scoping ConfigMap: %w", err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				configMap, ok := event.Object.(*corev1.ConfigMap)
+				if !ok {
+					continue
+				}
+				document, err := parseScopingConfigMap(configMap)
+				if err != nil {
+					r.Log.Error(err, "ignoring unreadable tenant scoping ConfigMap update")
+					continue
+				}
+				r.applyScopingDocument(document)
+			case watch.Deleted:
+				r.applyScopingDocument(nil)
+			case watch.Error:
+				return fmt.Errorf("tenant scoping watch error: %v", apierrors.FromObject(event.Object))
+			}
+		}
+	}
+}
+
+// setDifference returns the entries of a that are not in b
+func setDifference(a, b []string) []string {
+	exclude := make(map[string]bool, len(b))
+	for _, entry := range b {
+		exclude[entry] = true
+	}
+
+	difference := []string{}
+	for _, entry := range a {
+		if !exclude[entry] {
+			difference = append(difference, entry)
+		}
+	}
+	return difference
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+)
+
+const (
+	// maxSyntheticSpikeMultiplier bounds injected spikes to what a real tenant could plausibly send
+	maxSyntheticSpikeMultiplier = 100.0
+
+	// maxSyntheticSpikeDuration bounds how long an injected spike can drive limit changes
+	maxSyntheticSpikeDuration = 24 * time.Hour
+)
+
+// ErrInvalidSyntheticSpike is returned for a spike with an out-of-range multiplier or duration
+var ErrInvalidSyntheticSpike = errors.New("invalid synthetic spike")
+
+// SyntheticSpike multiplies a tenant's collected metrics for a limited time, so spike
+// detection and the limit increases it triggers can be validated without real traffic
+type SyntheticSpike struct {
+	Tenant     string    `json:"tenant"`
+	Multiplier float64   `json:"multiplier"`
+	StartedAt  time.Time `json:"started_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedBy  string    `json:"created_by,omitempty"`
+}
+
+// InjectSyntheticSpike multiplies the tenant's metrics by multiplier in every
+// reconciliation until duration has passed. A new spike replaces an active one.
+func (r *MimirLimitController) InjectSyntheticSpike(tenant string, multiplier float64, duration time.Duration, user string) (*SyntheticSpike, error) {
+	if tenant == "" {
+		return nil, fmt.Errorf("%w: tenant is required", ErrInvalidSyntheticSpike)
+	}
+	if multiplier <= 1 || multiplier > maxSyntheticSpikeMultiplier {
+		return nil, fmt.Errorf("%w: multiplier must be greater than 1 and at most %v, got %v",
+			ErrInvalidSyntheticSpike, maxSyntheticSpikeMultiplier, multiplier)
+	}
+	if duration <= 0 || duration > maxSyntheticSpikeDuration {
+		return nil, fmt.Errorf("%w: duration must be positive and at most %v, got %v",
+			ErrInvalidSyntheticSpike, maxSyntheticSpikeDuration, duration)
+	}
+
+	now := time.Now()
+	spike := &SyntheticSpike{
+		Tenant:     tenant,
+		Multiplier: multiplier,
+		StartedAt:  now,
+		ExpiresAt:  now.Add(duration),
+		CreatedBy:  user,
+	}
+	r.syntheticSpikes.Store(tenant, spike)
+
+	// Only clear the spike this call stored, not one that replaced it since
+	time.AfterFunc(duration, func() {
+		if r.syntheticSpikes.CompareAndDelete(tenant, spike) {
+			r.Log.Info("synthetic spike expired", "tenant", tenant, "multiplier", multiplier)
+		}
+	})
+
+	r.Log.Info("synthetic spike injected", "tenant", tenant, "multiplier", multiplier,
+		"duration", duration, "created_by", user)
+	r.auditSyntheticSpike(spike, duration)
+
+	return spike, nil
+}
+
+// ActiveSyntheticSpike returns the tenant's active synthetic spike, or nil when there is none
+func (r *MimirLimitController) ActiveSyntheticSpike(tenant string) *SyntheticSpike {
+	value, ok := r.syntheticSpikes.Load(tenant)
+	if !ok {
+		return nil
+	}
+	spike := value.(*SyntheticSpike)
+	if !time.Now().Before(spike.ExpiresAt) {
+		return nil
+	}
+	active := *spike
+	return &active
+}
+
+// ActiveSyntheticSpikes returns all active synthetic spikes, ordered by tenant
+func (r *MimirLimitController) ActiveSyntheticSpikes() []SyntheticSpike {
+	now := time.Now()
+	spikes := []SyntheticSpike{}
+	r.syntheticSpikes.Range(func(_, value interface{}) bool {
+		spike := value.(*SyntheticSpike)
+		if now.Before(spike.ExpiresAt) {
+			spikes = append(spikes, *spike)
+		}
+		return true
+	})
+	sort.Slice(spikes, func(i, j int) bool { return spikes[i].Tenant < spikes[j].Tenant })
+	return spikes
+}
+
+// applySyntheticSpikes returns the collected metrics with the values of tenants under
+// a synthetic spike multiplied. The collected metrics may be shared with a collector
+// cache, so spiked tenants get copies instead of being modified in place.
+func (r *MimirLimitController) applySyntheticSpikes(tenantMetrics map[string]*collector.TenantMetrics) map[string]*collector.TenantMetrics {
+	spikes := r.ActiveSyntheticSpikes()
+	if len(spikes) == 0 {
+		return tenantMetrics
+	}
+
+	spiked := make(map[string]*collector.TenantMetrics, len(tenantMetrics))
+	for tenant, tm := range tenantMetrics {
+		spiked[tenant] = tm
+	}
+
+	for _, spike := range spikes {
+		tm, exists := tenantMetrics[spike.Tenant]
+		if !exists || tm == nil {
+			continue
+		}
+
+		copied := &collector.TenantMetrics{
+			Tenant:     tm.Tenant,
+			Metrics:    make(map[string][]collector.MetricData, len(tm.Metrics)),
+			LastUpdate: tm.LastUpdate,
+		}
+		for metricName, data := range tm.Metrics {
+			multiplied := make([]collector.MetricData, len(data))
+			for i, point := range data {
+				point.Value *= spike.Multiplier
+				multiplied[i] = point
+			}
+			copied.Metrics[metricName] = multiplied
+		}
+		spiked[spike.Tenant] = copied
+
+		r.Log.Info("applying synthetic spike", "tenant", spike.Tenant, "multiplier", spike.Multiplier,
+			"expires_at", spike.ExpiresAt)
+	}
+
+	return spiked
+}
+
+// auditSyntheticSpike records a spike injection in the audit trail
+func (r *MimirLimitController) auditSyntheticSpike(spike *SyntheticSpike, duration time.Duration) {
+	if r.AuditLogger == nil {
+		return
+	}
+
+	entry := &auditlog.AuditEntry{
+		Action: "synthetic-spike",
+		Tenant: spike.Tenant,
+		Reason: "synthetic-spike-injected",
+		User:   spike.CreatedBy,
+		Changes: map[string]interface{}{
+			"multiplier": spike.Multiplier,
+			"duration":   duration.String(),
+			"expires_at": spike.ExpiresAt,
+		},
+		Success: true,
+	}
+	if err := r.AuditLogger.LogEntry(entry); err != nil {
+		r.Log.Error(err, "failed to log synthetic spike", "tenant", spike.Tenant)
+	}
+}
@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ingestionRateOf returns the ingestion_rate written for tenant
+func (tc *testController) ingestionRateOf(t *testing.T, tenant string) float64 {
+	t.Helper()
+	limits, _ := tc.tenantOverrides(t)[tenant].(map[string]interface{})
+	value, ok := limits["ingestion_rate"]
+	if !ok {
+		t.Fatalf("no ingestion_rate written for %s: %v", tenant, limits)
+	}
+	parsed, err := config.ParseLimitValue(value, "rate")
+	if err != nil {
+		t.Fatalf("ingestion_rate %v of %s: %v", value, tenant, err)
+	}
+	rate, _ := parsed.Number()
+	return rate
+}
+
+func TestSyntheticSpikeRaisesLimits(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mode = "prod"
+	cfg.Alerting.Enabled = false
+	cfg.EventSpike.Enabled = false
+	tc := newTestController(cfg, overridesConfigMap(cfg, "overrides: {}\n"))
+	tc.collector.setMetrics(ingestionMetrics(10000, "tenant-a", "tenant-b"))
+	ctx := context.Background()
+
+	if _, err := tc.InjectSyntheticSpike("tenant-a", 2, time.Minute, "sre"); err != nil {
+		t.Fatalf("InjectSyntheticSpike: %v", err)
+	}
+	if _, err := tc.reconcile(ctx); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	// The buffer of ingestion_rate is added on top of the doubled usage
+	buffer := cfg.DynamicLimits.LimitDefinitions["ingestion_rate"].BufferFactor
+	want := 2 * 10000 * (1 + buffer/100)
+	if got := tc.ingestionRateOf(t, "tenant-a"); math.Abs(got-want) > want*0.01 {
+		t.Errorf("ingestion_rate of the spiked tenant = %v, want about %v", got, want)
+	}
+	if got := tc.ingestionRateOf(t, "tenant-b"); math.Abs(got-want/2) > want*0.01 {
+		t.Errorf("ingestion_rate of the other tenant = %v, want about %v", got, want/2)
+	}
+}
+
+func TestSyntheticSpikeLifecycle(t *testing.T) {
+	tc := newTestController(config.GetDefaultConfig())
+
+	spike, err := tc.InjectSyntheticSpike("tenant-a", 3.5, 50*time.Millisecond, "sre")
+	if err != nil {
+		t.Fatalf("InjectSyntheticSpike: %v", err)
+	}
+	if active := tc.ActiveSyntheticSpike("tenant-a"); active == nil || active.Multiplier != 3.5 {
+		t.Fatalf("ActiveSyntheticSpike = %+v, want the injected spike", active)
+	}
+	if entries := tc.auditEntries(t, "synthetic-spike"); len(entries) != 1 || entries[0].User != "sre" {
+		t.Errorf("synthetic-spike audit entries = %d, want one by sre", len(entries))
+	}
+
+	time.Sleep(spike.ExpiresAt.Sub(time.Now()) + 50*time.Millisecond)
+	if active := tc.ActiveSyntheticSpike("tenant-a"); active != nil {
+		t.Errorf("ActiveSyntheticSpike after expiry = %+v, want none", active)
+	}
+	if spikes := tc.ActiveSyntheticSpikes(); len(spikes) != 0 {
+		t.Errorf("ActiveSyntheticSpikes after expiry = %+v, want none", spikes)
+	}
+}
+
+func TestInjectSyntheticSpikeValidation(t *testing.T) {
+	tc := newTestController(config.GetDefaultConfig())
+	tests := []struct {
+		name       string
+		tenant     string
+		multiplier float64
+		duration   time.Duration
+	}{
+		{"no tenant", "", 2, time.Minute},
+		{"multiplier not above 1", "tenant-a", 1, time.Minute},
+		{"multiplier too large", "tenant-a", 1000, time.Minute},
+		{"no duration", "tenant-a", 2, 0},
+		{"duration too long", "tenant-a", 2, 48 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tc.InjectSyntheticSpike(tt.tenant, tt.multiplier, tt.duration, "")
+			if !errors.Is(err, ErrInvalidSyntheticSpike) {
+				t.Errorf("InjectSyntheticSpike error = %v, want ErrInvalidSyntheticSpike", err)
+			}
+		})
+	}
+}
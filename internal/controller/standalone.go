@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/statestore"
+)
+
+// standaloneShutdownTimeout bounds saving the circuit breaker state on shutdown
+const standaloneShutdownTimeout = 10 * time.Second
+
+// SetupStandalone initializes the components of a reconciliation without Kubernetes, on
+// the Collector set by the caller. Standalone reconciliations write no ConfigMap: the
+// recommended limits are logged and served by the API, and kept in store when it is
+// not nil.
+func (r *MimirLimitController) SetupStandalone(store *statestore.Store) {
+	r.health = NewHealthRegistry(ComponentCollector, ComponentAnalyzer)
+	r.StateStore = store
+	r.Analyzer = analyzer.NewAnalyzer(r.Config, r.Log.WithName("analyzer"))
+	r.tenantFilter = NewTenantFilter(r.Config, r.Log.WithName("filter"))
+	r.BlastProtector = circuitbreaker.NewBlastProtector(r.Config, r.Log.WithName("protection"))
+	if r.AuditLogger != nil {
+		r.BlastProtector.SetAuditLogger(r.AuditLogger)
+	}
+}
+
+// StandaloneReconciler recomputes the recommended limits every Interval in standalone
+// mode
+type StandaloneReconciler struct {
+	Controller *MimirLimitController
+	Interval   time.Duration
+	Log        logr.Logger
+
+	// ticks returns the channel of the interval ticks and a function stopping them, a
+	// time.Ticker unless replaced by tests
+	ticks func(interval time.Duration) (<-chan time.Time, func())
+}
+
+// newTicker returns the ticks of a time.Ticker of interval and its Stop function
+func newTicker(interval time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// Run reconciles once right away and then on every interval until ctx is done, saving
+// the circuit breaker state before it returns
+func (sr *StandaloneReconciler) Run(ctx context.Context) error {
+	r := sr.Controller
+	sr.Log.Info("starting standalone reconciler", "interval", sr.Interval)
+	r.restoreStandaloneState(ctx)
+
+	ticks := sr.ticks
+	if ticks == nil {
+		ticks = newTicker
+	}
+	tickC, stop := ticks(sr.Interval)
+	defer stop()
+
+	for {
+		if _, err := r.reconcileStandalone(ctx); err != nil && ctx.Err() == nil {
+			sr.Log.Error(err, "standalone reconciliation failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			sr.Log.Info("stopping standalone reconciler")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), standaloneShutdownTimeout)
+			defer cancel()
+			r.saveCircuitBreakerState(shutdownCtx)
+			return nil
+		case <-tickC:
+		}
+	}
+}
+
+// reconcileStandalone runs one standalone reconciliation under a reconcile ID, like
+// reconcile does in Kubernetes
+func (r *MimirLimitController) reconcileStandalone(ctx context.Context) (int64, error) {
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+
+	tracker := r.beginReconcileResult(time.Now())
+	err := r.runStandaloneReconcile(ctx, tracker)
+	r.finishReconcileResult(tracker, err, time.Now())
+	return tracker.result.ReconcileID, err
+}
+
+// runStandaloneReconcile collects, filters and analyzes the tenants like runReconcile,
+// then records the protected limits as the recommendations and managed limits instead of
+// writing them. The limits of the previous reconciliation are the current values the
+// recommendations are compared with.
+func (r *MimirLimitController) runStandaloneReconcile(ctx context.Context, tracker *reconcileTracker) error {
+	startTime := tracker.result.StartTime
+	reconcileID := tracker.result.ReconcileID
+
+	defer func() {
+		metrics.ReconcileMetricsInstance.SetLastReconcileTime(float64(time.Now().Unix()))
+		r.lastReconcile = time.Now()
+	}()
+
+	tenantMetrics, err := r.Collector.CollectMetrics(ctx)
+	if err != nil {
+		r.health.RecordFailure(ComponentCollector, err)
+		metrics.HealthMetricsInstance.IncErrorTotal("collector", "metrics-collection")
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+	r.health.RecordSuccess(ComponentCollector)
+	if r.StateStore != nil {
+		// The metrics are still used for this reconciliation when they cannot be stored
+		if err := r.StateStore.SaveMetrics(ctx, tenantMetrics); err != nil {
+			r.Log.Error(err, "failed to store collected metrics")
+		}
+	}
+
+	tenantMetrics = r.applySyntheticSpikes(tenantMetrics)
+
+	allTenants := make([]string, 0, len(tenantMetrics))
+	for tenant := range tenantMetrics {
+		allTenants = append(allTenants, tenant)
+	}
+	if err := r.tenantFilter.Err(); err != nil {
+		metrics.HealthMetricsInstance.IncErrorTotal("controller", "tenant-scoping")
+		return fmt.Errorf("tenant scoping lists contain invalid patterns, not reconciling: %w", err)
+	}
+	monitoredTenants, skippedTenants := r.tenantFilter.FilterTenants(allTenants)
+	for _, tenant := range skippedTenants {
+		tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonTenantScoping, nil)
+	}
+	metrics.TenantMetricsInstance.SetTenantsMonitored(float64(len(monitoredTenants)))
+	metrics.TenantMetricsInstance.SetTenantsSkipped(float64(len(skippedTenants)))
+	r.updateTenantMetricLabels(ctx, monitoredTenants)
+
+	filteredMetrics := make(map[string]*collector.TenantMetrics, len(monitoredTenants))
+	for _, tenant := range monitoredTenants {
+		if tm, exists := tenantMetrics[tenant]; exists {
+			filteredMetrics[tenant] = tm
+		}
+	}
+
+	protectedMetrics, err := r.BlastProtector.ProcessMetrics(ctx, filteredMetrics)
+	if err != nil {
+		r.Log.Error(err, "failed to apply blast protection")
+		protectedMetrics = filteredMetrics
+	}
+	for tenant := range filteredMetrics {
+		if _, exists := protectedMetrics[tenant]; !exists {
+			tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonCircuitBreaker, nil)
+		}
+	}
+
+	analysisResults, optimizedLimits, tenantErrs := r.analyzeTenants(ctx, protectedMetrics)
+	r.health.Record(ComponentAnalyzer, tenantErrs.ErrorOrNil())
+	for _, tenantErr := range tenantErrs.Errors {
+		tracker.set(tenantErr.Tenant, TenantOutcomeFailed, ReconcileReasonCalculationError, tenantErr.Err)
+	}
+	if tenantErrs.Len() > 0 && tenantErrs.Len() == len(protectedMetrics) {
+		return fmt.Errorf("failed to calculate limits for every tenant: %w", tenantErrs)
+	}
+
+	protectedLimits, err := r.BlastProtector.ApplyProtection(ctx, optimizedLimits)
+	if err != nil {
+		r.Log.Error(err, "failed to apply blast protection to limits")
+		protectedLimits = optimizedLimits
+	}
+	tracker.clamped(optimizedLimits, protectedLimits)
+	for tenant := range optimizedLimits {
+		if _, exists := protectedLimits[tenant]; !exists {
+			tracker.set(tenant, TenantOutcomeSkipped, ReconcileReasonCircuitBreaker, nil)
+		}
+	}
+
+	r.managedMu.RLock()
+	previousLimits := r.managedLimits
+	r.managedMu.RUnlock()
+	r.BlastProtector.UpdateCurrentLimits(previousLimits)
+
+	r.recordRecommendations(ctx, reconcileID, previousLimits, protectedLimits, analysisResults)
+	tracker.applied(previousLimits, protectedLimits, protectedLimits, nil)
+	r.recordManagedLimits(protectedLimits)
+	r.updateCurrentLimitsMetrics(ctx, protectedLimits)
+
+	for tenant, tenantLimits := range protectedLimits {
+		r.Log.Info("recommended limits", "tenant", tenant, "tier", tenantLimits.Tier,
+			"limits", tenantLimits.Limits, "reason", tenantLimits.Reason)
+	}
+
+	if r.StateStore != nil {
+		if err := r.StateStore.SaveLimits(ctx, protectedLimits); err != nil {
+			r.Log.Error(err, "failed to store recommended limits")
+		}
+		r.saveCircuitBreakerState(ctx)
+	}
+
+	r.Log.Info("standalone reconciliation completed, no limits written",
+		"duration", time.Since(startTime),
+		"reconcile_id", reconcileID,
+		"tenants_recommended", len(protectedLimits),
+		"tenants_failed", tenantErrs.Len())
+	return nil
+}
+
+// restoreStandaloneState reloads the recommendations of the previous run as the managed
+// limits, seeds the analyzer history with the stored metrics and restores the blast
+// baselines stored with the circuit breaker state
+func (r *MimirLimitController) restoreStandaloneState(ctx context.Context) {
+	if r.StateStore == nil {
+		return
+	}
+
+	if trendAnalyzer, ok := r.Analyzer.(*analyzer.TrendAnalyzer); ok {
+		stored, err := r.StateStore.LoadMetrics(ctx, time.Now().Add(-statestore.MetricsRetention))
+		if err != nil {
+			r.Log.Error(err, "failed to load stored metrics")
+		} else if len(stored) > 0 {
+			trendAnalyzer.SeedHistory(stored)
+			r.Log.Info("restored stored metrics", "tenants", len(stored))
+		}
+	}
+
+	limits, err := r.StateStore.LatestLimits(ctx)
+	if err != nil {
+		r.Log.Error(err, "failed to load stored recommendations")
+	} else if len(limits) > 0 {
+		r.recordManagedLimits(limits)
+		r.Log.Info("restored stored recommendations", "tenants", len(limits))
+	}
+
+	state, err := r.StateStore.LoadCircuitBreakerState(ctx)
+	if err != nil {
+		r.Log.Error(err, "failed to load stored circuit breaker state")
+		return
+	}
+	if state == nil {
+		return
+	}
+	maxAge := r.config().CircuitBreaker.BlastProtection.BaselineMaxAge
+	restored, stale := r.BlastProtector.RestoreBaselines(state.Baselines, maxAge, time.Now())
+	r.Log.Info("restored stored blast baselines",
+		"restored", restored,
+		"stale", stale,
+		"circuit_breaker_state", state.State,
+		"saved_at", state.SavedAt)
+}
+
+// saveCircuitBreakerState stores the circuit breaker state and blast baselines
+func (r *MimirLimitController) saveCircuitBreakerState(ctx context.Context) {
+	if r.StateStore == nil || r.BlastProtector == nil {
+		return
+	}
+	state := statestore.CircuitBreakerState{Baselines: r.BlastProtector.Baselines()}
+	if current, ok := r.BlastProtector.GetProtectionStatus()["circuit_breaker_state"].(string); ok {
+		state.State = current
+	}
+	if err := r.StateStore.SaveCircuitBreakerState(ctx, state); err != nil {
+		r.Log.Error(err, "failed to store circuit breaker state")
+	}
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/statestore"
+)
+
+// fakeTicker hands its ticks to a standalone reconciler on demand
+type fakeTicker struct {
+	c        chan time.Time
+	mu       sync.Mutex
+	interval time.Duration
+	stopped  bool
+}
+
+func newFakeTicker() *fakeTicker {
+	return &fakeTicker{c: make(chan time.Time)}
+}
+
+func (f *fakeTicker) ticks(interval time.Duration) (<-chan time.Time, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.interval = interval
+	return f.c, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.stopped = true
+	}
+}
+
+// standaloneTestController is a controller set up for standalone mode, keeping its state
+// in store when it is not nil
+func standaloneTestController(store *statestore.Store) *testController {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = false
+	tc := newTestController(cfg)
+	tc.SetupStandalone(store)
+	return tc
+}
+
+// runStandalone runs the standalone reconciler of tc on ticker until the returned
+// function is called, which waits for it to stop
+func runStandalone(t *testing.T, tc *testController, ticker *fakeTicker) func() {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	sr := &StandaloneReconciler{Controller: tc.MimirLimitController, Interval: 50 * time.Millisecond, Log: logr.Discard(), ticks: ticker.ticks}
+	done := make(chan error, 1)
+	go func() { done <- sr.Run(ctx) }()
+
+	return func() {
+		t.Helper()
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Run: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the standalone reconciler did not stop when its context was canceled")
+		}
+	}
+}
+
+// waitForReconcile waits for the reconciliation after the one of reconcileID to finish
+func waitForReconcile(t *testing.T, tc *testController, reconcileID int64) *ReconcileResult {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if result, err := tc.GetReconcileResult(0); err == nil && result.ReconcileID > reconcileID && result.EndTime != nil {
+			return result
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no standalone reconciliation after reconcile %d", reconcileID)
+	return nil
+}
+
+func managedIngestionRate(t *testing.T, tc *testController, tenant string) float64 {
+	t.Helper()
+	managed, err := tc.GetManagedLimits(context.Background())
+	if err != nil {
+		t.Fatalf("GetManagedLimits: %v", err)
+	}
+	if managed[tenant] == nil {
+		t.Fatalf("managed limits = %v, want limits of %s", managed, tenant)
+	}
+	rate, _ := config.NumericValue(managed[tenant].Limits["ingestion_rate"])
+	return rate
+}
+
+func TestStandaloneReconcilerRecomputesOnEveryTick(t *testing.T) {
+	tc := standaloneTestController(nil)
+	tc.collector.setMetrics(ingestionMetrics(10000, "tenant-a"))
+	ticker := newFakeTicker()
+	stop := runStandalone(t, tc, ticker)
+
+	// The first reconciliation runs right away
+	first := waitForReconcile(t, tc, 0)
+	before := managedIngestionRate(t, tc, "tenant-a")
+	if before <= 0 {
+		t.Fatalf("recommended ingestion_rate of tenant-a = %v, want a recommendation", before)
+	}
+
+	// Traffic grows and a new tenant appears before the next tick
+	tc.collector.setMetrics(ingestionMetrics(30000, "tenant-a", "tenant-b"))
+	ticker.c <- time.Now()
+	second := waitForReconcile(t, tc, first.ReconcileID)
+	if after := managedIngestionRate(t, tc, "tenant-a"); after <= before {
+		t.Errorf("recommended ingestion_rate of tenant-a after the tick = %v, want above %v", after, before)
+	}
+	managedIngestionRate(t, tc, "tenant-b")
+	if second.Counts[TenantOutcomeChanged] != 2 {
+		t.Errorf("tenant outcomes of the second reconciliation = %v, want both tenants changed", second.Counts)
+	}
+
+	stop()
+	if result, _ := tc.GetReconcileResult(0); result.ReconcileID != second.ReconcileID {
+		t.Errorf("reconcile %d ran without a tick, want the last to be %d", result.ReconcileID, second.ReconcileID)
+	}
+	ticker.mu.Lock()
+	defer ticker.mu.Unlock()
+	if ticker.interval != 50*time.Millisecond || !ticker.stopped {
+		t.Errorf("ticker interval %v, stopped %v; want the 50ms interval stopped on shutdown", ticker.interval, ticker.stopped)
+	}
+
+	// Standalone reconciliations write no runtime overrides
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: tc.config().Mimir.ConfigMapName, Namespace: tc.config().Mimir.Namespace}
+	if err := tc.client.Get(context.Background(), key, configMap); !apierrors.IsNotFound(err) {
+		t.Errorf("runtime overrides ConfigMap lookup = %v, want it never written", err)
+	}
+}
+
+func TestStandaloneReconcilerRestoresStoredState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := statestore.Open(path, logr.Discard())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	tc := standaloneTestController(store)
+	tc.collector.setMetrics(ingestionMetrics(10000, "tenant-a"))
+	stop := runStandalone(t, tc, newFakeTicker())
+	waitForReconcile(t, tc, 0)
+	recommended := managedIngestionRate(t, tc, "tenant-a")
+	stop()
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// After a restart the recommendations of the previous run are the current values
+	store, err = statestore.Open(path, logr.Discard())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	state, err := store.LoadCircuitBreakerState(context.Background())
+	if err != nil || state == nil || state.State != "CLOSED" {
+		t.Errorf("circuit breaker state saved on shutdown = %+v, %v; want CLOSED", state, err)
+	}
+
+	restarted := standaloneTestController(store)
+	restarted.restoreStandaloneState(context.Background())
+	if got := managedIngestionRate(t, restarted, "tenant-a"); got != recommended {
+		t.Errorf("restored ingestion_rate of tenant-a = %v, want the stored %v", got, recommended)
+	}
+}
+
+func TestStandaloneRecomputesFromStoredMetrics(t *testing.T) {
+	// The traffic drops, so the recommendation still follows the peak of the first
+	// collection when the analysis window covers both
+	first := ingestionMetrics(30000, "tenant-a")
+	second := ingestionMetrics(10000, "tenant-a")
+
+	// An uninterrupted run analyzes both collections
+	continuous := standaloneTestController(nil)
+	continuous.collector.setMetrics(first)
+	ticker := newFakeTicker()
+	stop := runStandalone(t, continuous, ticker)
+	result := waitForReconcile(t, continuous, 0)
+	continuous.collector.setMetrics(second)
+	ticker.c <- time.Now()
+	waitForReconcile(t, continuous, result.ReconcileID)
+	stop()
+	want := managedIngestionRate(t, continuous, "tenant-a")
+
+	// A run restarted between the collections reads the first from the store
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := statestore.Open(path, logr.Discard())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	tc := standaloneTestController(store)
+	tc.collector.setMetrics(first)
+	stop = runStandalone(t, tc, newFakeTicker())
+	waitForReconcile(t, tc, 0)
+	stop()
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	store, err = statestore.Open(path, logr.Discard())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+	stored, err := store.LoadMetrics(context.Background(), time.Time{})
+	if err != nil {
+		t.Fatalf("LoadMetrics: %v", err)
+	}
+	if got := len(stored["tenant-a"].Metrics["cortex_distributor_received_samples_total"]); got != 10 {
+		t.Fatalf("%d samples of tenant-a stored, want the 10 collected", got)
+	}
+
+	restarted := standaloneTestController(store)
+	restarted.collector.setMetrics(second)
+	stop = runStandalone(t, restarted, newFakeTicker())
+	waitForReconcile(t, restarted, 0)
+	stop()
+	if got := managedIngestionRate(t, restarted, "tenant-a"); got != want {
+		t.Errorf("ingestion_rate of tenant-a recomputed after the restart = %v, want %v as without the restart", got, want)
+	}
+}
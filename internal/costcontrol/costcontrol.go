@@ -4,21 +4,43 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 )
 
+// minBudgetReductionFactor is the smallest fraction of its limits an over-budget
+// tenant keeps
+const minBudgetReductionFactor = 0.1
+
 // CostController manages cost control and budget enforcement
 type CostController struct {
-	config     *config.Config
-	log        logr.Logger
-	costCache  map[string]*TenantCostData
-	budgetAlerts map[string]time.Time // Last alert time per tenant
+	live            *config.Live
+	log             logr.Logger
+	costCache       map[string]*TenantCostData
+	budgetAlerts    map[string]time.Time // Last alert time per tenant
+	enforcedTenants map[string]bool      // Tenants with an open hard-enforcement incident
+	alertManager    *alerting.Manager
+	auditLogger     auditlog.AuditLogger
+
+	// mu guards the spend and enforcement state
+	mu sync.Mutex
+	// spend accumulates the spend of each tenant, globalSpend that of all tenants
+	spend       map[string]*tenantSpend
+	globalSpend *tenantSpend
+	// enforcementFactors is the last audited reduction factor of each enforced tenant
+	enforcementFactors map[string]float64
+
+	// estimator projects the monthly cost of each tenant
+	estimator *CostEstimator
 }
 
 // TenantCostData tracks cost information for a tenant
@@ -65,48 +87,100 @@ type CostOptimizationSuggestion struct {
 }
 
 // NewCostController creates a new cost controller
-func NewCostController(cfg *config.Config, log logr.Logger) *CostController {
+func NewCostController(live *config.Live, log logr.Logger) *CostController {
 	return &CostController{
-		config:       cfg,
-		log:          log,
-		costCache:    make(map[string]*TenantCostData),
-		budgetAlerts: make(map[string]time.Time),
+		live:               live,
+		log:                log,
+		costCache:          make(map[string]*TenantCostData),
+		budgetAlerts:       make(map[string]time.Time),
+		enforcedTenants:    make(map[string]bool),
+		spend:              make(map[string]*tenantSpend),
+		enforcementFactors: make(map[string]float64),
+		estimator:          NewCostEstimator(live),
 	}
 }
 
-// CalculateCosts calculates costs for all tenants
+// config returns the configuration in effect
+func (cc *CostController) config() *config.Config {
+	return cc.live.Load()
+}
+
+// SetAlertManager sets the alerting manager used for budget notifications
+func (cc *CostController) SetAlertManager(manager *alerting.Manager) {
+	cc.alertManager = manager
+}
+
+// SetAuditLogger sets the audit logger recording budget enforcement
+func (cc *CostController) SetAuditLogger(logger auditlog.AuditLogger) {
+	cc.auditLogger = logger
+}
+
+// CalculateCosts accrues the spend of all tenants since their last observation and
+// returns their daily, monthly and yearly totals
 func (cc *CostController) CalculateCosts(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) (map[string]*TenantCostData, error) {
-	if !cc.config.CostControl.Enabled {
+	if !cc.config().CostControl.Enabled {
 		return nil, nil
 	}
 
-	costs := make(map[string]*TenantCostData)
+	now := time.Now()
+	costs := cc.calculateCosts(tenantMetrics, now)
+	cc.estimator.Observe(tenantMetrics, now)
+	cc.log.Info("calculated costs", "tenants", len(costs))
+	return costs, nil
+}
 
-	for tenant, metrics := range tenantMetrics {
-		costData, err := cc.calculateTenantCost(tenant, metrics)
-		if err != nil {
-			cc.log.Error(err, "failed to calculate cost", "tenant", tenant)
-			continue
+// calculateCosts charges each tenant its usage at the collected rates for the time
+// since it was last observed. A tenant accrues spend from its second observation on.
+func (cc *CostController) calculateCosts(tenantMetrics map[string]*collector.TenantMetrics, now time.Time) map[string]*TenantCostData {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.globalSpend == nil {
+		cc.globalSpend = newTenantSpend(now)
+	}
+
+	costs := make(map[string]*TenantCostData, len(tenantMetrics))
+	total := 0.0
+	for tenant, tenantMetrics := range tenantMetrics {
+		spend, tracked := cc.spend[tenant]
+		if !tracked {
+			spend = newTenantSpend(now)
+			cc.spend[tenant] = spend
 		}
 
+		cost := cc.costOf(usageOf(tenantMetrics, cc.accrualInterval(spend, now)))
+		spend.add(cost, now)
+		total += cost
+
+		budget := cc.getTenantBudget(tenant)
+		cc.checkAlertThresholds(tenant, spend, budget)
+
+		costData := cc.costData(tenant, spend, budget, now)
 		costs[tenant] = costData
 		cc.costCache[tenant] = costData
-
-		// Update metrics
 		cc.updateCostMetrics(tenant, costData)
 	}
 
-	cc.log.Info("calculated costs", "tenants", len(costs))
-	return costs, nil
+	cc.globalSpend.add(total, now)
+	cc.checkAlertThresholds("", cc.globalSpend, cc.config().CostControl.GlobalBudget)
+
+	return costs
 }
 
 // EnforceBudgets checks and enforces budget limits
 func (cc *CostController) EnforceBudgets(ctx context.Context, costs map[string]*TenantCostData, limits map[string]*analyzer.TenantLimits) (map[string]*analyzer.TenantLimits, error) {
-	if !cc.config.CostControl.Enabled {
+	if !cc.config().CostControl.Enabled {
 		return limits, nil
 	}
 
-	adjustedLimits := make(map[string]*analyzer.TenantLimits)
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	// Tenants without cost data keep their limits
+	adjustedLimits := make(map[string]*analyzer.TenantLimits, len(limits))
+	for tenant, tenantLimits := range limits {
+		adjustedLimits[tenant] = tenantLimits
+	}
 
 	for tenant, costData := range costs {
 		tenantLimits := limits[tenant]
@@ -120,17 +194,18 @@ func (cc *CostController) EnforceBudgets(ctx context.Context, costs map[string]*
 
 		if violation {
 			// Apply budget enforcement
-			if budget.EnforceBudget && cc.config.CostControl.AutoLimitReduction {
-				adjustedLimits[tenant] = cc.reduceLimitsForBudget(tenantLimits, costData, budget)
-				cc.log.Info("reduced limits due to budget violation", "tenant", tenant)
-			} else {
-				adjustedLimits[tenant] = tenantLimits
+			if budget.EnforceBudget && cc.config().CostControl.AutoLimitReduction {
+				reduced := cc.reduceLimitsForBudget(tenantLimits, costData, budget)
+				adjustedLimits[tenant] = reduced
+				cc.log.Info("reduced limits due to budget violation", "tenant", tenant, "spend", describeSpend(costData))
+				cc.auditEnforcement(tenant, tenantLimits, reduced, budgetReductionFactor(costData, budget), costData)
 			}
 
 			// Send budget alert
 			cc.sendBudgetAlert(tenant, costData, budget)
 		} else {
-			adjustedLimits[tenant] = tenantLimits
+			cc.auditEnforcementLifted(tenant, costData)
+			cc.resolveBudgetAlert(tenant)
 		}
 	}
 
@@ -139,35 +214,41 @@ func (cc *CostController) EnforceBudgets(ctx context.Context, costs map[string]*
 
 // GetCostBreakdown provides detailed cost analysis
 func (cc *CostController) GetCostBreakdown(tenant string, metrics *collector.TenantMetrics) (*CostBreakdown, error) {
-	if !cc.config.CostControl.Enabled {
+	if !cc.config().CostControl.Enabled {
 		return nil, fmt.Errorf("cost control not enabled")
 	}
 
 	breakdown := &CostBreakdown{}
-	costPerUnit := cc.config.CostControl.CostPerUnit
+	costPerUnit := cc.config().CostControl.CostPerUnit
+	weights := cc.config().CostControl.CostWeights
 
 	// Calculate ingestion cost (based on samples)
-	if ingestionData, ok := metrics.Metrics["cortex_distributor_received_samples_total"]; ok {
+	if ingestionData, ok := metrics.Metrics[samplesMetric]; ok {
 		totalSamples := cc.sumMetricValues(ingestionData)
-		breakdown.IngestionCost = totalSamples * costPerUnit * 0.4 // 40% of cost
-		breakdown.CostPerSample = costPerUnit * 0.4
+		breakdown.IngestionCost = totalSamples * costPerUnit * weights.Samples
+		breakdown.CostPerSample = costPerUnit * weights.Samples
 	}
 
-	// Calculate storage cost (based on series)
-	if seriesData, ok := metrics.Metrics["cortex_ingester_memory_series"]; ok {
+	// Calculate series cost (based on active series)
+	if seriesData, ok := metrics.Metrics[seriesMetric]; ok {
 		totalSeries := cc.sumMetricValues(seriesData)
-		breakdown.StorageCost = totalSeries * costPerUnit * 0.3 // 30% of cost
-		breakdown.CostPerSeries = costPerUnit * 0.3
+		breakdown.SeriesCost = totalSeries * costPerUnit * weights.Series
+		breakdown.CostPerSeries = costPerUnit * weights.Series
+	}
+
+	// Calculate storage cost (based on block storage)
+	if storageData, ok := metrics.Metrics[storageMetric]; ok {
+		breakdown.StorageCost = cc.sumMetricValues(storageData) / bytesPerMB * costPerUnit * weights.Storage
 	}
 
 	// Calculate query cost (based on queries)
-	if queryData, ok := metrics.Metrics["cortex_querier_queries_total"]; ok {
+	if queryData, ok := metrics.Metrics[queriesMetric]; ok {
 		totalQueries := cc.sumMetricValues(queryData)
-		breakdown.QueryCost = totalQueries * costPerUnit * 0.3 // 30% of cost
-		breakdown.CostPerQuery = costPerUnit * 0.3
+		breakdown.QueryCost = totalQueries * costPerUnit * weights.Queries
+		breakdown.CostPerQuery = costPerUnit * weights.Queries
 	}
 
-	breakdown.TotalCost = breakdown.IngestionCost + breakdown.StorageCost + breakdown.QueryCost
+	breakdown.TotalCost = breakdown.IngestionCost + breakdown.SeriesCost + breakdown.StorageCost + breakdown.QueryCost
 
 	return breakdown, nil
 }
@@ -186,7 +267,7 @@ func (cc *CostController) GenerateOptimizationSuggestions(tenant string, costDat
 			"High ingestion cost detected. Consider implementing sampling or data retention policies.")
 	}
 
-	if breakdown.StorageCost > breakdown.TotalCost*0.5 {
+	if breakdown.SeriesCost+breakdown.StorageCost > breakdown.TotalCost*0.5 {
 		suggestions.Recommendations = append(suggestions.Recommendations,
 			"High storage cost detected. Consider reducing series cardinality or implementing data lifecycle policies.")
 	}
@@ -213,10 +294,13 @@ func (cc *CostController) GenerateOptimizationSuggestions(tenant string, costDat
 
 // GetGlobalCostSummary provides organization-wide cost summary
 func (cc *CostController) GetGlobalCostSummary() map[string]interface{} {
-	if !cc.config.CostControl.Enabled {
+	if !cc.config().CostControl.Enabled {
 		return nil
 	}
 
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
 	totalDaily := 0.0
 	totalMonthly := 0.0
 	totalYearly := 0.0
@@ -229,7 +313,7 @@ func (cc *CostController) GetGlobalCostSummary() map[string]interface{} {
 		tenantCount++
 	}
 
-	budget := cc.config.CostControl.GlobalBudget
+	budget := cc.config().CostControl.GlobalBudget
 
 	return map[string]interface{}{
 		"total_daily_cost":     totalDaily,
@@ -248,52 +332,11 @@ func (cc *CostController) GetGlobalCostSummary() map[string]interface{} {
 
 // Helper methods
 
-func (cc *CostController) calculateTenantCost(tenant string, metrics *collector.TenantMetrics) (*TenantCostData, error) {
-	costData := &TenantCostData{
-		Tenant:      tenant,
-		Currency:    cc.config.CostControl.GlobalBudget.Currency,
-		LastUpdated: time.Now(),
-	}
-
-	breakdown, err := cc.GetCostBreakdown(tenant, metrics)
-	if err != nil {
-		return nil, err
-	}
-
-	// Calculate daily cost based on current usage
-	dailyCostRate := breakdown.TotalCost
-
-	// Project costs
-	costData.DailyCost = dailyCostRate
-	costData.MonthlyCost = dailyCostRate * 30
-	costData.YearlyCost = dailyCostRate * 365
-
-	// Calculate projections (with growth factor)
-	growthFactor := 1.1 // Assume 10% growth
-	costData.ProjectedDaily = dailyCostRate * growthFactor
-	costData.ProjectedMonthly = costData.ProjectedDaily * 30
-	costData.ProjectedYearly = costData.ProjectedDaily * 365
-
-	// Calculate budget utilization
-	budget := cc.getTenantBudget(tenant)
-	if budget.Daily > 0 {
-		costData.BudgetUtilization.DailyPercent = (costData.DailyCost / budget.Daily) * 100
-	}
-	if budget.Monthly > 0 {
-		costData.BudgetUtilization.MonthlyPercent = (costData.MonthlyCost / budget.Monthly) * 100
-	}
-	if budget.Annual > 0 {
-		costData.BudgetUtilization.YearlyPercent = (costData.YearlyCost / budget.Annual) * 100
-	}
-
-	return costData, nil
-}
-
 func (cc *CostController) getTenantBudget(tenant string) config.BudgetConfig {
-	if budget, exists := cc.config.CostControl.TenantBudgets[tenant]; exists {
+	if budget, exists := cc.config().CostControl.TenantBudgets[tenant]; exists {
 		return budget
 	}
-	return cc.config.CostControl.GlobalBudget
+	return cc.config().CostControl.GlobalBudget
 }
 
 func (cc *CostController) checkBudgetViolation(costData *TenantCostData, budget config.BudgetConfig) bool {
@@ -302,13 +345,10 @@ func (cc *CostController) checkBudgetViolation(costData *TenantCostData, budget
 		(budget.Annual > 0 && costData.YearlyCost > budget.Annual)
 }
 
+// reduceLimitsForBudget scales the limits by the tenant's budget over its spend, keeping
+// them at or above the limit minimums
 func (cc *CostController) reduceLimitsForBudget(limits *analyzer.TenantLimits, costData *TenantCostData, budget config.BudgetConfig) *analyzer.TenantLimits {
-	// Calculate reduction factor based on budget violation
-	reductionFactor := 0.8 // Reduce by 20%
-
-	if budget.Daily > 0 && costData.DailyCost > budget.Daily {
-		reductionFactor = math.Min(reductionFactor, budget.Daily/costData.DailyCost)
-	}
+	reductionFactor := budgetReductionFactor(costData, budget)
 
 	adjustedLimits := &analyzer.TenantLimits{
 		Tenant:      limits.Tenant,
@@ -316,17 +356,27 @@ func (cc *CostController) reduceLimitsForBudget(limits *analyzer.TenantLimits, c
 		LastUpdated: time.Now(),
 		Reason:      "budget-enforcement",
 		Source:      "cost-control",
+		Tier:        limits.Tier,
 	}
 
 	// Apply reduction to all dynamic limits
 	for limitName, limitValue := range limits.Limits {
 		adjustedValue := limitValue
 		
+		minValue, hasMin := cc.config().DynamicLimits.LimitDefinitions[limitName].MinValue.Number()
 		switch v := limitValue.(type) {
 		case float64:
-			adjustedValue = v * reductionFactor
+			reduced := v * reductionFactor
+			if hasMin && reduced < minValue {
+				reduced = math.Min(v, minValue)
+			}
+			adjustedValue = reduced
 		case int64:
-			adjustedValue = int64(float64(v) * reductionFactor)
+			reduced := int64(float64(v) * reductionFactor)
+			if hasMin && float64(reduced) < minValue {
+				reduced = int64(math.Min(float64(v), minValue))
+			}
+			adjustedValue = reduced
 		}
 		
 		adjustedLimits.Limits[limitName] = adjustedValue
@@ -352,25 +402,112 @@ func (cc *CostController) sendBudgetAlert(tenant string, costData *TenantCostDat
 		"daily_budget", budget.Daily,
 		"utilization", costData.BudgetUtilization.DailyPercent)
 
-	// TODO: Send alerts to configured channels (Slack, email, etc.)
+	currentCost, budgetLimit, violationLevel := costData.DailyCost, budget.Daily, "daily"
+	switch {
+	case budget.Daily > 0 && costData.DailyCost > budget.Daily:
+	case budget.Monthly > 0 && costData.MonthlyCost > budget.Monthly:
+		currentCost, budgetLimit, violationLevel = costData.MonthlyCost, budget.Monthly, "monthly"
+	case budget.Annual > 0 && costData.YearlyCost > budget.Annual:
+		currentCost, budgetLimit, violationLevel = costData.YearlyCost, budget.Annual, "annual"
+	}
+	metrics.CostControlMetricsInstance.IncBudgetViolations(tenant, violationLevel)
+
+	if cc.alertManager == nil {
+		return
+	}
+
+	alert := alerting.CreateCostViolationAlert(tenant, currentCost, budgetLimit, violationLevel)
+
+	// Hard enforcement reduces tenant limits, so escalate it to on-call
+	if budget.EnforceBudget && cc.config().CostControl.AutoLimitReduction {
+		alert.Priority = alerting.PriorityP1
+		alert.Details["enforced"] = true
+		cc.enforcedTenants[tenant] = true
+	}
+
+	cc.alertManager.SendAlert(alert)
 }
 
-func (cc *CostController) sumMetricValues(data []collector.MetricData) float64 {
-	total := 0.0
-	for _, d := range data {
-		total += d.Value
+// auditEnforcement audits the limit reduction of an over-budget tenant when it starts
+// and whenever its reduction factor changes by a percentage point or more
+func (cc *CostController) auditEnforcement(tenant string, limits, reduced *analyzer.TenantLimits, factor float64, costData *TenantCostData) {
+	if previous, enforced := cc.enforcementFactors[tenant]; enforced && math.Abs(previous-factor) < 0.01 {
+		return
+	}
+	cc.enforcementFactors[tenant] = factor
+
+	if cc.auditLogger == nil {
+		return
+	}
+	entry := auditlog.NewLimitUpdateEntry(tenant,
+		fmt.Sprintf("budget exceeded (%s), limits reduced to %.0f%%", describeSpend(costData), factor*100),
+		limits.Limits, reduced.Limits)
+	entry.Action = "budget_enforcement"
+	entry.Source = "cost-control"
+	entry.Component = "cost-control"
+	if err := cc.auditLogger.LogEntry(entry); err != nil {
+		cc.log.Error(err, "failed to audit budget enforcement", "tenant", tenant)
+	}
+}
+
+// auditEnforcementLifted audits that an enforced tenant is back within budget
+func (cc *CostController) auditEnforcementLifted(tenant string, costData *TenantCostData) {
+	if _, enforced := cc.enforcementFactors[tenant]; !enforced {
+		return
+	}
+	delete(cc.enforcementFactors, tenant)
+
+	if cc.auditLogger == nil {
+		return
+	}
+	entry := &auditlog.AuditEntry{
+		Tenant:    tenant,
+		Action:    "budget_enforcement_lifted",
+		Reason:    fmt.Sprintf("back within budget (%s)", describeSpend(costData)),
+		Changes:   map[string]interface{}{},
+		Source:    "cost-control",
+		Component: "cost-control",
+		Success:   true,
+	}
+	if err := cc.auditLogger.LogEntry(entry); err != nil {
+		cc.log.Error(err, "failed to audit lifted budget enforcement", "tenant", tenant)
+	}
+}
+
+// resolveBudgetAlert clears the hard-enforcement incident once a tenant is back within budget
+func (cc *CostController) resolveBudgetAlert(tenant string) {
+	if !cc.enforcedTenants[tenant] {
+		return
+	}
+
+	delete(cc.enforcedTenants, tenant)
+	delete(cc.budgetAlerts, tenant)
+
+	if cc.alertManager != nil {
+		cc.alertManager.SendAlert(alerting.CreateResolvedAlert(alerting.AlertTypeCostViolation, tenant,
+			fmt.Sprintf("Tenant %s is back within budget; budget enforcement lifted", tenant)))
 	}
-	return total
+}
+
+func (cc *CostController) sumMetricValues(data []collector.MetricData) float64 {
+	return sumValues(data)
 }
 
 func (cc *CostController) updateCostMetrics(tenant string, costData *TenantCostData) {
-	// Update Prometheus metrics with cost data
-	// TODO: Implement metrics updates when metrics package is enhanced
+	metrics.CostControlMetricsInstance.SetCostCurrent(tenant, PeriodDaily, costData.DailyCost)
+	metrics.CostControlMetricsInstance.SetCostCurrent(tenant, PeriodMonthly, costData.MonthlyCost)
+	metrics.CostControlMetricsInstance.SetCostCurrent(tenant, PeriodAnnual, costData.YearlyCost)
+
+	utilization := costData.BudgetUtilization
+	metrics.CostControlMetricsInstance.SetBudgetUsageRatio(tenant,
+		math.Max(utilization.DailyPercent, math.Max(utilization.MonthlyPercent, utilization.YearlyPercent))/100)
 }
 
 // PredictFutureCosts predicts future costs based on trends
 func (cc *CostController) PredictFutureCosts(tenant string, days int) (*TenantCostData, error) {
+	cc.mu.Lock()
 	current, exists := cc.costCache[tenant]
+	cc.mu.Unlock()
 	if !exists {
 		return nil, fmt.Errorf("no cost data available for tenant %s", tenant)
 	}
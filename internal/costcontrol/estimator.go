@@ -0,0 +1,323 @@
+package costcontrol
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// hoursInMonth is the average number of hours in a month
+const hoursInMonth = 730
+
+// UsageRates is the average usage of a tenant: samples and queries per second, active
+// series and megabytes of block storage
+type UsageRates struct {
+	IngestionRate float64 `json:"ingestion_rate"`
+	ActiveSeries  float64 `json:"active_series"`
+	QueryRate     float64 `json:"query_rate"`
+	StorageMB     float64 `json:"storage_mb"`
+}
+
+// usageObservation is the usage of a tenant observed in one collection
+type usageObservation struct {
+	at    time.Time
+	rates UsageRates
+}
+
+// CostEstimator projects the monthly cost of each tenant from its average usage over
+// costControl.estimationWindow, and what its applied limits would cost if reached
+type CostEstimator struct {
+	live *config.Live
+
+	mu           sync.Mutex
+	observations map[string][]usageObservation
+}
+
+// NewCostEstimator creates a cost estimator
+func NewCostEstimator(live *config.Live) *CostEstimator {
+	return &CostEstimator{
+		live:         live,
+		observations: make(map[string][]usageObservation),
+	}
+}
+
+// config returns the configuration in effect
+func (e *CostEstimator) config() *config.Config {
+	return e.live.Load()
+}
+
+// Observe records the usage of each collected tenant, drops observations older than
+// the estimation window and exports the projected monthly cost of each tenant
+func (e *CostEstimator) Observe(tenantMetrics map[string]*collector.TenantMetrics, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for tenant, tenantMetrics := range tenantMetrics {
+		if tenantMetrics == nil {
+			continue
+		}
+		e.observations[tenant] = append(e.observations[tenant], usageObservation{
+			at: now,
+			rates: UsageRates{
+				IngestionRate: averageRate(tenantMetrics.Metrics[samplesMetric]),
+				ActiveSeries:  averageRate(tenantMetrics.Metrics[seriesMetric]),
+				QueryRate:     averageRate(tenantMetrics.Metrics[queriesMetric]),
+				StorageMB:     averageRate(tenantMetrics.Metrics[storageMetric]) / bytesPerMB,
+			},
+		})
+	}
+
+	cutoff := now.Add(-e.window())
+	for tenant, observations := range e.observations {
+		kept := 0
+		for kept < len(observations) && observations[kept].at.Before(cutoff) {
+			kept++
+		}
+		if kept == len(observations) {
+			delete(e.observations, tenant)
+			metrics.CostControlMetricsInstance.DeleteCostEstimateMonthly(tenant)
+			continue
+		}
+		e.observations[tenant] = observations[kept:]
+		metrics.CostControlMetricsInstance.SetCostEstimateMonthly(tenant, e.MonthlyCost(averageUsage(observations[kept:])))
+	}
+}
+
+// Usage returns the average usage of a tenant over the estimation window and the
+// number of observations it is averaged from
+func (e *CostEstimator) Usage(tenant string) (UsageRates, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	observations := e.observations[tenant]
+	return averageUsage(observations), len(observations)
+}
+
+// Tenants returns the observed tenants
+func (e *CostEstimator) Tenants() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	tenants := make([]string, 0, len(e.observations))
+	for tenant := range e.observations {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// Coefficients returns the configured coefficients, deriving those not set from
+// costControl.costPerUnit and costControl.costWeights
+func (e *CostEstimator) Coefficients() config.CostCoefficients {
+	coefficients := e.config().CostControl.Coefficients
+	costPerUnit := e.config().CostControl.CostPerUnit / costUnit
+	weights := e.config().CostControl.CostWeights
+	if coefficients.SamplesCost == 0 {
+		coefficients.SamplesCost = costPerUnit * weights.Samples * time.Hour.Seconds()
+	}
+	if coefficients.SeriesCost == 0 {
+		coefficients.SeriesCost = costPerUnit * weights.Series
+	}
+	if coefficients.QueryCost == 0 {
+		coefficients.QueryCost = costPerUnit * weights.Queries * time.Hour.Seconds()
+	}
+	if coefficients.StorageCost == 0 {
+		coefficients.StorageCost = costPerUnit * weights.Storage
+	}
+	return coefficients
+}
+
+// MonthlyCost projects the cost of sustaining a usage for a month
+func (e *CostEstimator) MonthlyCost(usage UsageRates) float64 {
+	coefficients := e.Coefficients()
+	hourly := usage.IngestionRate*coefficients.SamplesCost +
+		usage.ActiveSeries*coefficients.SeriesCost +
+		usage.QueryRate*coefficients.QueryCost +
+		usage.StorageMB*coefficients.StorageCost
+	return hourly * hoursInMonth
+}
+
+// LimitMonthlyCost projects the monthly cost of a tenant ingesting at its applied
+// ingestion_rate with max_global_series_per_user active series, at its average query
+// rate and storage. It returns false when neither limit is set.
+func (e *CostEstimator) LimitMonthlyCost(limits *analyzer.TenantLimits, usage UsageRates) (float64, bool) {
+	if limits == nil {
+		return 0, false
+	}
+
+	atLimits := UsageRates{QueryRate: usage.QueryRate, StorageMB: usage.StorageMB}
+	ingestion, ingestionSet := config.LimitBoundValue(limits.Limits["ingestion_rate"], "rate")
+	series, seriesSet := config.LimitBoundValue(limits.Limits["max_global_series_per_user"], "count")
+	// Zero limits are unlimited and have no ceiling to price
+	ingestionSet = ingestionSet && ingestion > 0
+	seriesSet = seriesSet && series > 0
+	if !ingestionSet && !seriesSet {
+		return 0, false
+	}
+
+	atLimits.IngestionRate = usage.IngestionRate
+	if ingestionSet {
+		atLimits.IngestionRate = ingestion
+	}
+	atLimits.ActiveSeries = usage.ActiveSeries
+	if seriesSet {
+		atLimits.ActiveSeries = series
+	}
+	return e.MonthlyCost(atLimits), true
+}
+
+// window returns the estimation window, one day when it is not set
+func (e *CostEstimator) window() time.Duration {
+	if window := e.config().CostControl.EstimationWindow; window > 0 {
+		return window
+	}
+	return 24 * time.Hour
+}
+
+// averageUsage averages the observed usage
+func averageUsage(observations []usageObservation) UsageRates {
+	var total UsageRates
+	if len(observations) == 0 {
+		return total
+	}
+	for _, observation := range observations {
+		total.IngestionRate += observation.rates.IngestionRate
+		total.ActiveSeries += observation.rates.ActiveSeries
+		total.QueryRate += observation.rates.QueryRate
+		total.StorageMB += observation.rates.StorageMB
+	}
+	count := float64(len(observations))
+	return UsageRates{
+		IngestionRate: total.IngestionRate / count,
+		ActiveSeries:  total.ActiveSeries / count,
+		QueryRate:     total.QueryRate / count,
+		StorageMB:     total.StorageMB / count,
+	}
+}
+
+// averageRate sums the series of a metric at each timestamp and averages the sums
+func averageRate(data []collector.MetricData) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	totals := make(map[time.Time]float64)
+	for _, d := range data {
+		totals[d.Timestamp] += d.Value
+	}
+	sum := 0.0
+	for _, total := range totals {
+		sum += total
+	}
+	return sum / float64(len(totals))
+}
+
+// CostReport is the projected monthly cost of each tenant against its budget
+type CostReport struct {
+	Tenants                 []TenantCostEstimate    `json:"tenants"`
+	TotalMonthlyCost        float64                 `json:"total_estimated_monthly_cost"`
+	GlobalMonthlyBudget     float64                 `json:"global_monthly_budget,omitempty"`
+	GlobalBudgetUtilization float64                 `json:"global_budget_utilization_percent"`
+	BudgetAlert             bool                    `json:"budget_alert"`
+	Currency                string                  `json:"currency"`
+	EstimationWindow        string                  `json:"estimation_window"`
+	Coefficients            config.CostCoefficients `json:"coefficients"`
+	GeneratedAt             time.Time               `json:"generated_at"`
+}
+
+// TenantCostEstimate is the projected monthly cost of a tenant
+type TenantCostEstimate struct {
+	Tenant               string     `json:"tenant"`
+	AverageUsage         UsageRates `json:"average_usage"`
+	Observations         int        `json:"observations"`
+	EstimatedMonthlyCost float64    `json:"estimated_monthly_cost"`
+	// LimitMonthlyCost is the cost if the tenant used its applied limits in full
+	LimitMonthlyCost  *float64 `json:"limit_monthly_cost,omitempty"`
+	MonthlyBudget     float64  `json:"monthly_budget,omitempty"`
+	BudgetUtilization float64  `json:"budget_utilization_percent"`
+	// AlertThreshold is the highest costControl.alertThresholds percentage reached
+	AlertThreshold float64 `json:"alert_threshold_percent,omitempty"`
+	BudgetAlert    bool    `json:"budget_alert"`
+	Currency       string  `json:"currency"`
+}
+
+// CostReport projects the monthly cost of the observed tenants and of the tenants with
+// applied limits, most expensive first
+func (cc *CostController) CostReport(limits map[string]*analyzer.TenantLimits, now time.Time) *CostReport {
+	estimator := cc.estimator
+	report := &CostReport{
+		Tenants:          []TenantCostEstimate{},
+		Currency:         cc.config().CostControl.GlobalBudget.Currency,
+		EstimationWindow: estimator.window().String(),
+		Coefficients:     estimator.Coefficients(),
+		GeneratedAt:      now,
+	}
+
+	tenants := make(map[string]bool)
+	for _, tenant := range estimator.Tenants() {
+		tenants[tenant] = true
+	}
+	for tenant := range limits {
+		tenants[tenant] = true
+	}
+
+	for tenant := range tenants {
+		usage, observations := estimator.Usage(tenant)
+		budget := cc.getTenantBudget(tenant)
+		estimate := TenantCostEstimate{
+			Tenant:               tenant,
+			AverageUsage:         usage,
+			Observations:         observations,
+			EstimatedMonthlyCost: estimator.MonthlyCost(usage),
+			MonthlyBudget:        budget.Monthly,
+			Currency:             budget.Currency,
+		}
+		if estimate.Currency == "" {
+			estimate.Currency = report.Currency
+		}
+		if cost, ok := estimator.LimitMonthlyCost(limits[tenant], usage); ok {
+			estimate.LimitMonthlyCost = &cost
+		}
+		estimate.BudgetUtilization, estimate.AlertThreshold = cc.budgetAlertLevel(estimate.EstimatedMonthlyCost, budget.Monthly)
+		estimate.BudgetAlert = estimate.AlertThreshold > 0
+
+		report.TotalMonthlyCost += estimate.EstimatedMonthlyCost
+		report.BudgetAlert = report.BudgetAlert || estimate.BudgetAlert
+		report.Tenants = append(report.Tenants, estimate)
+	}
+
+	if global := cc.config().CostControl.GlobalBudget.Monthly; global > 0 {
+		report.GlobalMonthlyBudget = global
+		utilization, threshold := cc.budgetAlertLevel(report.TotalMonthlyCost, global)
+		report.GlobalBudgetUtilization = utilization
+		report.BudgetAlert = report.BudgetAlert || threshold > 0
+	}
+
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		if report.Tenants[i].EstimatedMonthlyCost != report.Tenants[j].EstimatedMonthlyCost {
+			return report.Tenants[i].EstimatedMonthlyCost > report.Tenants[j].EstimatedMonthlyCost
+		}
+		return report.Tenants[i].Tenant < report.Tenants[j].Tenant
+	})
+	return report
+}
+
+// budgetAlertLevel returns the utilization percentage of a monthly budget and the
+// highest costControl.alertThresholds percentage it reaches, 0 when none is reached
+func (cc *CostController) budgetAlertLevel(cost, budget float64) (float64, float64) {
+	if budget <= 0 {
+		return 0, 0
+	}
+	utilization := cost / budget * 100
+	reached := 0.0
+	for _, threshold := range cc.config().CostControl.AlertThresholds {
+		if threshold > 0 && utilization >= threshold && threshold > reached {
+			reached = threshold
+		}
+	}
+	return utilization, reached
+}
@@ -0,0 +1,205 @@
+package costcontrol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// estimatorConfig prices an hour of one sample per second at 0.0001, one active series
+// at 0.00002, one query per second at 0.001 and one megabyte stored at 0.0005
+func estimatorConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.CostControl.EstimationWindow = 2 * time.Hour
+	cfg.CostControl.Coefficients = config.CostCoefficients{
+		SamplesCost: 0.0001,
+		SeriesCost:  0.00002,
+		QueryCost:   0.001,
+		StorageCost: 0.0005,
+	}
+	cfg.CostControl.GlobalBudget = config.BudgetConfig{Currency: "USD"}
+	cfg.CostControl.AlertThresholds = []float64{80, 100}
+	return cfg
+}
+
+func TestMonthlyCostComposite(t *testing.T) {
+	estimator := NewCostEstimator(config.NewLive(estimatorConfig()))
+
+	tests := []struct {
+		name  string
+		usage UsageRates
+		want  float64
+	}{
+		{"no usage", UsageRates{}, 0},
+		{"ingestion only", UsageRates{IngestionRate: 1000}, 0.1 * hoursInMonth},
+		{"series only", UsageRates{ActiveSeries: 50000}, 1 * hoursInMonth},
+		{"every kind of usage",
+			UsageRates{IngestionRate: 1000, ActiveSeries: 50000, QueryRate: 5, StorageMB: 200},
+			(0.1 + 1 + 0.005 + 0.1) * hoursInMonth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCost(t, "monthly cost", estimator.MonthlyCost(tt.usage), tt.want)
+		})
+	}
+}
+
+func TestCoefficientsDerivedFromCostPerUnit(t *testing.T) {
+	cfg := estimatorConfig()
+	cfg.CostControl.Coefficients = config.CostCoefficients{SeriesCost: 0.5}
+	cfg.CostControl.CostPerUnit = 2
+	cfg.CostControl.CostWeights = config.CostWeights{Samples: 1, Series: 3, Queries: 10, Storage: 4}
+	estimator := NewCostEstimator(config.NewLive(cfg))
+
+	// costPerUnit prices a million units; rates are per second, sustained for an hour
+	coefficients := estimator.Coefficients()
+	assertCost(t, "samples cost", coefficients.SamplesCost, 2e-6*1*3600)
+	assertCost(t, "series cost", coefficients.SeriesCost, 0.5)
+	assertCost(t, "query cost", coefficients.QueryCost, 2e-6*10*3600)
+	assertCost(t, "storage cost", coefficients.StorageCost, 2e-6*4)
+}
+
+func TestObserveAveragesOverEstimationWindow(t *testing.T) {
+	metricstest.Register(t)
+	estimator := NewCostEstimator(config.NewLive(estimatorConfig()))
+	observeIngestion := func(rate float64, offset time.Duration) {
+		estimator.Observe(map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", rate, 0, 0, 0)},
+			spendEpoch.Add(offset))
+	}
+
+	observeIngestion(1000, 0)
+	observeIngestion(2000, time.Hour)
+	observeIngestion(3000, 3*time.Hour)
+
+	// The observation at 0h is older than the 2h window
+	usage, observations := estimator.Usage("tenant-a")
+	if observations != 2 {
+		t.Errorf("observations = %d, want 2 within the window", observations)
+	}
+	assertCost(t, "average ingestion rate", usage.IngestionRate, 2500)
+	wantCost := estimator.MonthlyCost(UsageRates{IngestionRate: 2500})
+	assertCost(t, "mimir_cost_estimate_monthly_usd",
+		metricstest.Value(t, "mimir_cost_estimate_monthly_usd", map[string]string{"tenant": "tenant-a"}), wantCost)
+
+	// A tenant no longer collected is dropped once its last observation leaves the window
+	estimator.Observe(map[string]*collector.TenantMetrics{}, spendEpoch.Add(6*time.Hour))
+	if tenants := estimator.Tenants(); len(tenants) != 0 {
+		t.Errorf("observed tenants = %v, want none", tenants)
+	}
+}
+
+func TestAverageRate(t *testing.T) {
+	first, second := spendEpoch, spendEpoch.Add(time.Minute)
+	tests := []struct {
+		name string
+		data []collector.MetricData
+		want float64
+	}{
+		{"no data", nil, 0},
+		{"series at one timestamp are summed", []collector.MetricData{
+			{Value: 100, Timestamp: first}, {Value: 300, Timestamp: first}}, 400},
+		{"timestamps are averaged", []collector.MetricData{
+			{Value: 100, Timestamp: first}, {Value: 300, Timestamp: first}, {Value: 200, Timestamp: second}}, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCost(t, "average rate", averageRate(tt.data), tt.want)
+		})
+	}
+}
+
+func TestLimitMonthlyCost(t *testing.T) {
+	estimator := NewCostEstimator(config.NewLive(estimatorConfig()))
+	usage := UsageRates{IngestionRate: 1000, ActiveSeries: 10000, QueryRate: 5, StorageMB: 200}
+	limitsOf := func(limits map[string]interface{}) *analyzer.TenantLimits {
+		return &analyzer.TenantLimits{Tenant: "tenant-a", Limits: limits}
+	}
+
+	tests := []struct {
+		name   string
+		limits *analyzer.TenantLimits
+		want   float64
+		wantOK bool
+	}{
+		{"both limits", limitsOf(map[string]interface{}{"ingestion_rate": 5000.0, "max_global_series_per_user": int64(50000)}),
+			estimator.MonthlyCost(UsageRates{IngestionRate: 5000, ActiveSeries: 50000, QueryRate: 5, StorageMB: 200}), true},
+		{"ingestion limit only", limitsOf(map[string]interface{}{"ingestion_rate": 5000.0}),
+			estimator.MonthlyCost(UsageRates{IngestionRate: 5000, ActiveSeries: 10000, QueryRate: 5, StorageMB: 200}), true},
+		{"unlimited series", limitsOf(map[string]interface{}{"ingestion_rate": 5000.0, "max_global_series_per_user": 0}),
+			estimator.MonthlyCost(UsageRates{IngestionRate: 5000, ActiveSeries: 10000, QueryRate: 5, StorageMB: 200}), true},
+		{"no priced limit", limitsOf(map[string]interface{}{"max_label_names_per_series": 30}), 0, false},
+		{"no limits", nil, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := estimator.LimitMonthlyCost(tt.limits, usage)
+			if ok != tt.wantOK {
+				t.Fatalf("LimitMonthlyCost() ok = %v, want %v", ok, tt.wantOK)
+			}
+			assertCost(t, "limit monthly cost", got, tt.want)
+		})
+	}
+}
+
+func TestCostReport(t *testing.T) {
+	cfg := estimatorConfig()
+	// tenant-a costs 73 a month at 1000 samples/s, tenant-b 36.5 at 500
+	cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{
+		"tenant-a": {Monthly: 80, Currency: "EUR"},
+		"tenant-b": {Monthly: 100},
+	}
+	cfg.CostControl.GlobalBudget = config.BudgetConfig{Monthly: 1000, Currency: "USD"}
+	cc := NewCostController(config.NewLive(cfg), logr.Discard())
+	cc.estimator.Observe(map[string]*collector.TenantMetrics{
+		"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0),
+		"tenant-b": tenantUsage("tenant-b", 500, 0, 0, 0),
+	}, spendEpoch)
+
+	limits := map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 2000.0}},
+		"tenant-c": {Tenant: "tenant-c", Limits: map[string]interface{}{"ingestion_rate": 100.0}},
+	}
+	report := cc.CostReport(limits, spendEpoch)
+
+	if len(report.Tenants) != 3 {
+		t.Fatalf("report has %d tenants, want the 2 observed and the one with limits", len(report.Tenants))
+	}
+	for i, want := range []string{"tenant-a", "tenant-b", "tenant-c"} {
+		if report.Tenants[i].Tenant != want {
+			t.Errorf("tenant %d = %s, want %s, most expensive first", i, report.Tenants[i].Tenant, want)
+		}
+	}
+
+	a, b, c := report.Tenants[0], report.Tenants[1], report.Tenants[2]
+	assertCost(t, "tenant-a estimated monthly cost", a.EstimatedMonthlyCost, 73)
+	assertCost(t, "tenant-a budget utilization", a.BudgetUtilization, 73.0/80*100)
+	if !a.BudgetAlert || a.AlertThreshold != 80 || a.Currency != "EUR" {
+		t.Errorf("tenant-a alert = %v at %v%% in %s, want an alert at 80%% in EUR", a.BudgetAlert, a.AlertThreshold, a.Currency)
+	}
+	if a.LimitMonthlyCost == nil {
+		t.Errorf("tenant-a has no cost at its limits")
+	} else {
+		assertCost(t, "tenant-a limit monthly cost", *a.LimitMonthlyCost, 146)
+	}
+
+	assertCost(t, "tenant-b estimated monthly cost", b.EstimatedMonthlyCost, 36.5)
+	if b.BudgetAlert || b.Currency != "USD" {
+		t.Errorf("tenant-b alert = %v in %s, want no alert in the global currency", b.BudgetAlert, b.Currency)
+	}
+	if c.Observations != 0 || c.EstimatedMonthlyCost != 0 || c.LimitMonthlyCost == nil {
+		t.Errorf("tenant-c = %d observations, cost %v, want none observed but a cost at its limits",
+			c.Observations, c.EstimatedMonthlyCost)
+	}
+
+	assertCost(t, "total monthly cost", report.TotalMonthlyCost, 109.5)
+	assertCost(t, "global budget utilization", report.GlobalBudgetUtilization, 10.95)
+	if !report.BudgetAlert {
+		t.Errorf("report budget_alert = false with tenant-a over its threshold")
+	}
+}
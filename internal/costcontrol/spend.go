@@ -0,0 +1,379 @@
+package costcontrol
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// Cost methods of costControl.costMethod
+const (
+	CostMethodSamples   = "samples"
+	CostMethodSeries    = "series"
+	CostMethodQueries   = "queries"
+	CostMethodComposite = "composite"
+)
+
+// Budget periods
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+	PeriodAnnual  = "annual"
+)
+
+// Metrics the spend is estimated from: the sample and query rates per second and the
+// active series
+const (
+	samplesMetric = "cortex_distributor_received_samples_total"
+	seriesMetric  = "cortex_ingester_memory_series"
+	queriesMetric = "cortex_querier_queries_total"
+	storageMetric = "cortex_ingester_tsdb_storage_blocks_bytes"
+)
+
+// bytesPerMB converts the stored bytes to the megabytes storage is weighted by
+const bytesPerMB = 1e6
+
+// costUnit is the usage billed at costControl.costPerUnit: a million samples,
+// series-hours or queries
+const costUnit = 1e6
+
+// Usage is what a tenant consumed over an accrual interval
+type Usage struct {
+	Samples        float64
+	SeriesHours    float64
+	Queries        float64
+	StorageMBHours float64
+}
+
+// tenantSpend accumulates the spend of a tenant, or of all tenants, in the current
+// day, month and year (UTC)
+type tenantSpend struct {
+	trackedSince time.Time
+	lastObserved time.Time
+
+	day, month, year       time.Time
+	daily, monthly, yearly float64
+
+	// alerted is the highest alert threshold crossed per period
+	alerted map[string]float64
+}
+
+func newTenantSpend(now time.Time) *tenantSpend {
+	spend := &tenantSpend{
+		trackedSince: now,
+		lastObserved: now,
+		alerted:      make(map[string]float64),
+	}
+	spend.rollover(now)
+	return spend
+}
+
+// rollover starts new periods once now is past the current ones
+func (s *tenantSpend) rollover(now time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	if !day.Equal(s.day) {
+		s.day, s.daily = day, 0
+		delete(s.alerted, PeriodDaily)
+	}
+	if !month.Equal(s.month) {
+		s.month, s.monthly = month, 0
+		delete(s.alerted, PeriodMonthly)
+	}
+	if !year.Equal(s.year) {
+		s.year, s.yearly = year, 0
+		delete(s.alerted, PeriodAnnual)
+	}
+}
+
+// add accrues a cost observed at now
+func (s *tenantSpend) add(cost float64, now time.Time) {
+	s.rollover(now)
+	s.daily += cost
+	s.monthly += cost
+	s.yearly += cost
+	s.lastObserved = now
+}
+
+// spent returns the spend of a period
+func (s *tenantSpend) spent(period string) float64 {
+	switch period {
+	case PeriodDaily:
+		return s.daily
+	case PeriodMonthly:
+		return s.monthly
+	default:
+		return s.yearly
+	}
+}
+
+// projected extrapolates the spend of a period linearly from the part of it observed
+// so far
+func (s *tenantSpend) projected(period string, now time.Time) float64 {
+	start, end := s.day, s.day.AddDate(0, 0, 1)
+	switch period {
+	case PeriodMonthly:
+		start, end = s.month, s.month.AddDate(0, 1, 0)
+	case PeriodAnnual:
+		start, end = s.year, s.year.AddDate(1, 0, 0)
+	}
+	if s.trackedSince.After(start) {
+		start = s.trackedSince
+	}
+
+	observed := now.Sub(start)
+	if observed < time.Minute {
+		return s.spent(period)
+	}
+	return s.spent(period) * float64(end.Sub(start)) / float64(observed)
+}
+
+// usageOf returns the usage of a tenant over elapsed at its collected rates
+func usageOf(metrics *collector.TenantMetrics, elapsed time.Duration) Usage {
+	if metrics == nil {
+		return Usage{}
+	}
+	return Usage{
+		Samples:        sumValues(metrics.Metrics[samplesMetric]) * elapsed.Seconds(),
+		SeriesHours:    sumValues(metrics.Metrics[seriesMetric]) * elapsed.Hours(),
+		Queries:        sumValues(metrics.Metrics[queriesMetric]) * elapsed.Seconds(),
+		StorageMBHours: sumValues(metrics.Metrics[storageMetric]) / bytesPerMB * elapsed.Hours(),
+	}
+}
+
+// costOf prices usage with costControl.costMethod and costPerUnit. The composite
+// method weights each kind of usage with costControl.costWeights.
+func (cc *CostController) costOf(usage Usage) float64 {
+	costPerUnit := cc.config().CostControl.CostPerUnit / costUnit
+	switch cc.config().CostControl.CostMethod {
+	case CostMethodSamples:
+		return usage.Samples * costPerUnit
+	case CostMethodSeries:
+		return usage.SeriesHours * costPerUnit
+	case CostMethodQueries:
+		return usage.Queries * costPerUnit
+	default:
+		weights := cc.config().CostControl.CostWeights
+		return (usage.Samples*weights.Samples +
+			usage.SeriesHours*weights.Series +
+			usage.Queries*weights.Queries +
+			usage.StorageMBHours*weights.Storage) * costPerUnit
+	}
+}
+
+// accrualInterval is the interval charged for an observation at now. Gaps longer than
+// two update intervals, while nothing was observed, are charged as one interval.
+func (cc *CostController) accrualInterval(spend *tenantSpend, now time.Time) time.Duration {
+	elapsed := now.Sub(spend.lastObserved)
+	if elapsed < 0 {
+		return 0
+	}
+	if interval := cc.config().UpdateInterval; interval > 0 && elapsed > 2*interval {
+		return interval
+	}
+	return elapsed
+}
+
+// checkAlertThresholds alerts once per period for each costControl.alertThresholds
+// percentage of the budget the spend crosses. An empty tenant is the global spend.
+func (cc *CostController) checkAlertThresholds(tenant string, spend *tenantSpend, budget config.BudgetConfig) {
+	budgets := map[string]float64{
+		PeriodDaily:   budget.Daily,
+		PeriodMonthly: budget.Monthly,
+		PeriodAnnual:  budget.Annual,
+	}
+
+	for _, period := range []string{PeriodDaily, PeriodMonthly, PeriodAnnual} {
+		limit := budgets[period]
+		if limit <= 0 {
+			continue
+		}
+		spent := spend.spent(period)
+		utilization := spent / limit * 100
+
+		crossed := 0.0
+		for _, threshold := range cc.config().CostControl.AlertThresholds {
+			if threshold > 0 && utilization >= threshold && threshold > crossed {
+				crossed = threshold
+			}
+		}
+		if crossed <= spend.alerted[period] {
+			continue
+		}
+		spend.alerted[period] = crossed
+
+		cc.log.Info("budget alert threshold crossed",
+			"tenant", tenant,
+			"period", period,
+			"threshold_percent", crossed,
+			"spent", spent,
+			"budget", limit)
+
+		if cc.alertManager != nil {
+			cc.alertManager.SendAlert(alerting.CreateBudgetThresholdAlert(tenant, period, spent, limit, crossed, budget.Currency))
+		}
+	}
+}
+
+// costData reports a spend against its budget
+func (cc *CostController) costData(tenant string, spend *tenantSpend, budget config.BudgetConfig, now time.Time) *TenantCostData {
+	data := &TenantCostData{
+		Tenant:           tenant,
+		DailyCost:        spend.daily,
+		MonthlyCost:      spend.monthly,
+		YearlyCost:       spend.yearly,
+		ProjectedDaily:   spend.projected(PeriodDaily, now),
+		ProjectedMonthly: spend.projected(PeriodMonthly, now),
+		ProjectedYearly:  spend.projected(PeriodAnnual, now),
+		Currency:         budget.Currency,
+		LastUpdated:      now,
+	}
+	if data.Currency == "" {
+		data.Currency = cc.config().CostControl.GlobalBudget.Currency
+	}
+	if budget.Daily > 0 {
+		data.BudgetUtilization.DailyPercent = data.DailyCost / budget.Daily * 100
+	}
+	if budget.Monthly > 0 {
+		data.BudgetUtilization.MonthlyPercent = data.MonthlyCost / budget.Monthly * 100
+	}
+	if budget.Annual > 0 {
+		data.BudgetUtilization.YearlyPercent = data.YearlyCost / budget.Annual * 100
+	}
+	return data
+}
+
+// budgetReductionFactor is the fraction of its limits an over-budget tenant keeps: its
+// budget over its spend for the most exceeded period, at least minBudgetReductionFactor
+func budgetReductionFactor(costData *TenantCostData, budget config.BudgetConfig) float64 {
+	factor := 1.0
+	for _, period := range []struct{ spent, budget float64 }{
+		{costData.DailyCost, budget.Daily},
+		{costData.MonthlyCost, budget.Monthly},
+		{costData.YearlyCost, budget.Annual},
+	} {
+		if period.budget > 0 && period.spent > period.budget {
+			factor = min(factor, period.budget/period.spent)
+		}
+	}
+	return max(factor, minBudgetReductionFactor)
+}
+
+func sumValues(data []collector.MetricData) float64 {
+	total := 0.0
+	for _, d := range data {
+		total += d.Value
+	}
+	return total
+}
+
+// describeSpend summarizes a spend for logs
+func describeSpend(data *TenantCostData) string {
+	return fmt.Sprintf("daily %.2f, monthly %.2f, yearly %.2f %s", data.DailyCost, data.MonthlyCost, data.YearlyCost, data.Currency)
+}
+
+// SpendReport is the spend so far of each tenant and of all tenants
+type SpendReport struct {
+	Tenants []SpendSummary `json:"tenants"`
+	Global  SpendSummary   `json:"global"`
+	// OverThreshold are the tenants whose daily or monthly spend reached one of the
+	// costControl.alertThresholds percentages of their budget
+	OverThreshold []string  `json:"over_threshold"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+// SpendSummary is the daily and monthly spend of a tenant, or of all tenants, against
+// its budget
+type SpendSummary struct {
+	Tenant                      string    `json:"tenant,omitempty"`
+	TrackedSince                time.Time `json:"tracked_since"`
+	DailySpend                  float64   `json:"daily_spend"`
+	MonthlySpend                float64   `json:"monthly_spend"`
+	DailyBudget                 float64   `json:"daily_budget,omitempty"`
+	MonthlyBudget               float64   `json:"monthly_budget,omitempty"`
+	DailyUtilization            float64   `json:"daily_budget_utilization_percent"`
+	MonthlyUtilization          float64   `json:"monthly_budget_utilization_percent"`
+	ProjectedMonthlySpend       float64   `json:"projected_month_end_spend"`
+	ProjectedMonthlyUtilization float64   `json:"projected_monthly_budget_utilization_percent"`
+	// AlertThreshold is the highest costControl.alertThresholds percentage reached
+	AlertThreshold float64 `json:"alert_threshold_percent,omitempty"`
+	OverThreshold  bool    `json:"over_threshold"`
+	Currency       string  `json:"currency"`
+}
+
+// SpendReport summarizes the accumulated spend of every tenant, or only of tenant when
+// it is not empty, and of all tenants. It reports false when the tenant has no spend.
+func (cc *CostController) SpendReport(tenant string, now time.Time) (*SpendReport, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	report := &SpendReport{
+		Tenants:       []SpendSummary{},
+		OverThreshold: []string{},
+		GeneratedAt:   now,
+	}
+	if cc.globalSpend != nil {
+		report.Global = cc.spendSummary("", cc.globalSpend, cc.config().CostControl.GlobalBudget, now)
+	}
+
+	for name, spend := range cc.spend {
+		if tenant != "" && name != tenant {
+			continue
+		}
+		summary := cc.spendSummary(name, spend, cc.getTenantBudget(name), now)
+		report.Tenants = append(report.Tenants, summary)
+		if summary.OverThreshold {
+			report.OverThreshold = append(report.OverThreshold, name)
+		}
+	}
+	if tenant != "" && len(report.Tenants) == 0 {
+		return nil, false
+	}
+
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		if report.Tenants[i].MonthlySpend != report.Tenants[j].MonthlySpend {
+			return report.Tenants[i].MonthlySpend > report.Tenants[j].MonthlySpend
+		}
+		return report.Tenants[i].Tenant < report.Tenants[j].Tenant
+	})
+	sort.Strings(report.OverThreshold)
+	return report, true
+}
+
+// spendSummary reports a spend as of now, with periods that ended since it was last
+// observed started over. The caller must hold cc.mu.
+func (cc *CostController) spendSummary(tenant string, spend *tenantSpend, budget config.BudgetConfig, now time.Time) SpendSummary {
+	current := *spend
+	// The copy must not clear the alert state of the tracked spend
+	current.alerted = nil
+	current.rollover(now)
+
+	summary := SpendSummary{
+		Tenant:                tenant,
+		TrackedSince:          current.trackedSince,
+		DailySpend:            current.daily,
+		MonthlySpend:          current.monthly,
+		DailyBudget:           budget.Daily,
+		MonthlyBudget:         budget.Monthly,
+		ProjectedMonthlySpend: current.projected(PeriodMonthly, now),
+		Currency:              budget.Currency,
+	}
+	if summary.Currency == "" {
+		summary.Currency = cc.config().CostControl.GlobalBudget.Currency
+	}
+
+	var dailyThreshold, monthlyThreshold float64
+	summary.DailyUtilization, dailyThreshold = cc.budgetAlertLevel(summary.DailySpend, budget.Daily)
+	summary.MonthlyUtilization, monthlyThreshold = cc.budgetAlertLevel(summary.MonthlySpend, budget.Monthly)
+	summary.ProjectedMonthlyUtilization, _ = cc.budgetAlertLevel(summary.ProjectedMonthlySpend, budget.Monthly)
+	summary.AlertThreshold = max(dailyThreshold, monthlyThreshold)
+	summary.OverThreshold = summary.AlertThreshold > 0
+	return summary
+}
@@ -0,0 +1,461 @@
+package costcontrol
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// spendEpoch is a Wednesday mid-month, so an hour of accrual stays in the same day
+var spendEpoch = time.Date(2026, time.June, 17, 8, 0, 0, 0, time.UTC)
+
+// tenantUsage builds collected metrics with a sample rate and a query rate per second,
+// active series and stored bytes
+func tenantUsage(tenant string, samplesPerSecond, series, queriesPerSecond, storedBytes float64) *collector.TenantMetrics {
+	metric := func(name string, value float64) []collector.MetricData {
+		return []collector.MetricData{{Tenant: tenant, MetricName: name, Value: value}}
+	}
+	return &collector.TenantMetrics{
+		Tenant: tenant,
+		Metrics: map[string][]collector.MetricData{
+			samplesMetric: metric(samplesMetric, samplesPerSecond),
+			seriesMetric:  metric(seriesMetric, series),
+			queriesMetric: metric(queriesMetric, queriesPerSecond),
+			storageMetric: metric(storageMetric, storedBytes),
+		},
+	}
+}
+
+// samplesConfig prices a million samples at 1, so 1000 samples per second cost 3.6 an hour
+func samplesConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.UpdateInterval = time.Hour
+	cfg.CostControl.CostMethod = CostMethodSamples
+	cfg.CostControl.CostPerUnit = 1
+	cfg.CostControl.GlobalBudget = config.BudgetConfig{Currency: "USD"}
+	cfg.CostControl.AlertThresholds = nil
+	return cfg
+}
+
+// observe collects the metrics at each offset from spendEpoch and returns the last costs
+func observe(cc *CostController, metrics map[string]*collector.TenantMetrics, offsets ...time.Duration) map[string]*TenantCostData {
+	var costs map[string]*TenantCostData
+	for _, offset := range offsets {
+		costs = cc.calculateCosts(metrics, spendEpoch.Add(offset))
+	}
+	return costs
+}
+
+func assertCost(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestSpendAccumulates(t *testing.T) {
+	cc := NewCostController(config.NewLive(samplesConfig()), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{
+		"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0),
+		"tenant-b": tenantUsage("tenant-b", 500, 0, 0, 0),
+	}
+
+	// The first observation only starts tracking
+	costs := observe(cc, usage, 0)
+	assertCost(t, "first observation daily cost", costs["tenant-a"].DailyCost, 0)
+
+	costs = observe(cc, usage, 30*time.Minute, time.Hour)
+	assertCost(t, "tenant-a daily cost", costs["tenant-a"].DailyCost, 3.6)
+	assertCost(t, "tenant-a monthly cost", costs["tenant-a"].MonthlyCost, 3.6)
+	assertCost(t, "tenant-a yearly cost", costs["tenant-a"].YearlyCost, 3.6)
+	assertCost(t, "tenant-b daily cost", costs["tenant-b"].DailyCost, 1.8)
+	assertCost(t, "global daily spend", cc.globalSpend.daily, 5.4)
+}
+
+func TestCostMethods(t *testing.T) {
+	// An hour of 1000 samples/s, 2000 series, 10 queries/s and 5 GB stored: 3.6M samples,
+	// 0.002M series-hours, 0.036M queries and 0.005M megabyte-hours
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 2000, 10, 5e9)}
+
+	tests := []struct {
+		method string
+		want   float64
+	}{
+		{CostMethodSamples, 7.2},
+		{CostMethodSeries, 0.004},
+		{CostMethodQueries, 0.072},
+		{CostMethodComposite, (3.6*1 + 0.002*100 + 0.036*10 + 0.005*1) * 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			cfg := samplesConfig()
+			cfg.CostControl.CostMethod = tt.method
+			cfg.CostControl.CostPerUnit = 2
+			cfg.CostControl.CostWeights = config.CostWeights{Samples: 1, Series: 100, Queries: 10, Storage: 1}
+			cc := NewCostController(config.NewLive(cfg), logr.Discard())
+
+			costs := observe(cc, usage, 0, time.Hour)
+			assertCost(t, "daily cost", costs["tenant-a"].DailyCost, tt.want)
+		})
+	}
+}
+
+func TestCompositeWeightsChangeRanking(t *testing.T) {
+	// tenant-samples ingests a lot into few series, tenant-series the opposite
+	usage := map[string]*collector.TenantMetrics{
+		"tenant-samples": tenantUsage("tenant-samples", 10000, 10000, 1, 0),
+		"tenant-series":  tenantUsage("tenant-series", 500, 2000000, 1, 0),
+	}
+
+	tests := []struct {
+		name    string
+		weights config.CostWeights
+		want    []string
+	}{
+		{"sample-dominated weights", config.CostWeights{Samples: 1, Series: 1, Queries: 1}, []string{"tenant-samples", "tenant-series"}},
+		{"series-dominated weights", config.CostWeights{Samples: 0.01, Series: 100, Queries: 1}, []string{"tenant-series", "tenant-samples"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := samplesConfig()
+			cfg.CostControl.CostMethod = CostMethodComposite
+			cfg.CostControl.CostWeights = tt.weights
+			cc := NewCostController(config.NewLive(cfg), logr.Discard())
+			observe(cc, usage, 0, time.Hour)
+
+			report, _ := cc.SpendReport("", spendEpoch.Add(time.Hour))
+			var ranking []string
+			for _, summary := range report.Tenants {
+				ranking = append(ranking, summary.Tenant)
+			}
+			if len(ranking) != 2 || ranking[0] != tt.want[0] || ranking[1] != tt.want[1] {
+				t.Errorf("cost ranking = %v, want %v", ranking, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpendChargesLongGapsAsOneInterval(t *testing.T) {
+	cc := NewCostController(config.NewLive(samplesConfig()), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}
+
+	costs := observe(cc, usage, 0, 5*time.Hour)
+	assertCost(t, "daily cost after a 5h gap", costs["tenant-a"].DailyCost, 3.6)
+}
+
+func TestSpendRollsOverPeriods(t *testing.T) {
+	cc := NewCostController(config.NewLive(samplesConfig()), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}
+
+	// Half an hour is observed before midnight at the end of June, the hour up to 00:30
+	// is charged to July 1st
+	lastHour := time.Date(2026, time.June, 30, 23, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{lastHour, lastHour.Add(30 * time.Minute), lastHour.Add(90 * time.Minute)} {
+		cc.calculateCosts(usage, at)
+	}
+
+	spend := cc.spend["tenant-a"]
+	assertCost(t, "daily spend of July 1st", spend.daily, 3.6)
+	assertCost(t, "monthly spend of July", spend.monthly, 3.6)
+	assertCost(t, "yearly spend", spend.yearly, 5.4)
+}
+
+func TestSpendProjection(t *testing.T) {
+	cc := NewCostController(config.NewLive(samplesConfig()), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}
+	costs := observe(cc, usage, 0, time.Hour)
+
+	// Tracking started at 08:00, so the rest of the day runs at the observed rate
+	assertCost(t, "projected daily cost", costs["tenant-a"].ProjectedDaily, 3.6*16)
+}
+
+// alertRecorder is a webhook receiving the default alert payload
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []recordedAlert
+}
+
+type recordedAlert struct {
+	Type     string                 `json:"type"`
+	Tenant   string                 `json:"tenant"`
+	Resolved bool                   `json:"resolved"`
+	Details  map[string]interface{} `json:"details"`
+}
+
+func (r *alertRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var alert recordedAlert
+	if err := json.NewDecoder(req.Body).Decode(&alert); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.alerts = append(r.alerts, alert)
+	r.mu.Unlock()
+}
+
+// waitForAlerts waits until count alerts were delivered and returns them
+func (r *alertRecorder) waitForAlerts(t *testing.T, count int) []recordedAlert {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		alerts := append([]recordedAlert(nil), r.alerts...)
+		r.mu.Unlock()
+		if len(alerts) >= count || time.Now().After(deadline) {
+			return alerts
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// newAlertingCostController returns a cost controller whose alerts are delivered to a
+// recording webhook
+func newAlertingCostController(t *testing.T, cfg *config.Config) (*CostController, *alertRecorder) {
+	recorder := &alertRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	cfg.Alerting = config.AlertingConfig{
+		Enabled:  true,
+		Webhooks: []config.WebhookConfig{{Name: "recorder", URL: server.URL, Enabled: true, Timeout: time.Second}},
+	}
+	manager := alerting.NewManager(&cfg.Alerting, logr.Discard())
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start alerting manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+
+	cc := NewCostController(config.NewLive(cfg), logr.Discard())
+	cc.SetAlertManager(manager)
+	return cc, recorder
+}
+
+func TestBudgetAlertThresholds(t *testing.T) {
+	cfg := samplesConfig()
+	cfg.CostControl.AlertThresholds = []float64{50, 80, 100}
+	cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{"tenant-a": {Daily: 10, Currency: "USD"}}
+	cc, recorder := newAlertingCostController(t, cfg)
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}
+
+	// 3.6 an hour: 36% after 1h, 72% after 2h, 108% after 3h, 144% after 4h
+	observe(cc, usage, 0, time.Hour, 2*time.Hour, 3*time.Hour, 4*time.Hour)
+
+	alerts := recorder.waitForAlerts(t, 2)
+	var thresholds []float64
+	for _, alert := range alerts {
+		if alert.Tenant != "tenant-a" || alert.Type != string(alerting.AlertTypeCostViolation) {
+			t.Errorf("unexpected alert %+v", alert)
+			continue
+		}
+		thresholds = append(thresholds, alert.Details["threshold_percent"].(float64))
+	}
+	if len(thresholds) != 2 || thresholds[0] != 50 || thresholds[1] != 100 {
+		t.Errorf("alerted thresholds = %v, want 50 then 100, each once", thresholds)
+	}
+}
+
+func TestBudgetAlertThresholdsResetEveryPeriod(t *testing.T) {
+	cfg := samplesConfig()
+	cfg.CostControl.AlertThresholds = []float64{50}
+	cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{"tenant-a": {Daily: 5}}
+	cc := NewCostController(config.NewLive(cfg), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}
+
+	observe(cc, usage, 0, time.Hour)
+	if got := cc.spend["tenant-a"].alerted[PeriodDaily]; got != 50 {
+		t.Fatalf("alerted daily threshold = %v, want 50", got)
+	}
+
+	// The next day starts a new daily budget, at a rate staying below the threshold
+	quiet := map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 100, 0, 0, 0)}
+	observe(cc, quiet, 16*time.Hour+time.Minute)
+	if got := cc.spend["tenant-a"].alerted[PeriodDaily]; got != 0 {
+		t.Errorf("alerted daily threshold after midnight = %v, want it reset", got)
+	}
+}
+
+func TestEnforceBudgets(t *testing.T) {
+	overBudget := &TenantCostData{Tenant: "tenant-a", DailyCost: 20}
+	withinBudget := &TenantCostData{Tenant: "tenant-a", DailyCost: 5}
+	limits := func() map[string]*analyzer.TenantLimits {
+		return map[string]*analyzer.TenantLimits{
+			"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 100000.0, "max_global_series_per_user": int64(200000)}},
+			"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"ingestion_rate": 100000.0}},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		enforce            bool
+		autoLimitReduction bool
+		wantIngestion      float64
+		wantSeries         int64
+	}{
+		{"enforced budget halves the limits", true, true, 50000, 100000},
+		{"without auto limit reduction", true, false, 100000, 200000},
+		{"budget not enforced", false, true, 100000, 200000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := samplesConfig()
+			cfg.CostControl.AutoLimitReduction = tt.autoLimitReduction
+			cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{"tenant-a": {Daily: 10, EnforceBudget: tt.enforce}}
+			cc := NewCostController(config.NewLive(cfg), logr.Discard())
+
+			adjusted, err := cc.EnforceBudgets(context.Background(), map[string]*TenantCostData{"tenant-a": overBudget}, limits())
+			if err != nil {
+				t.Fatalf("EnforceBudgets: %v", err)
+			}
+			if got := adjusted["tenant-a"].Limits["ingestion_rate"]; got != tt.wantIngestion {
+				t.Errorf("ingestion_rate = %v, want %v", got, tt.wantIngestion)
+			}
+			if got := adjusted["tenant-a"].Limits["max_global_series_per_user"]; got != tt.wantSeries {
+				t.Errorf("max_global_series_per_user = %v, want %v", got, tt.wantSeries)
+			}
+			if got := adjusted["tenant-b"].Limits["ingestion_rate"]; got != 100000.0 {
+				t.Errorf("tenant without cost data: ingestion_rate = %v, want it kept", got)
+			}
+		})
+	}
+
+	t.Run("enforcement and its lift are audited once", func(t *testing.T) {
+		cfg := samplesConfig()
+		cfg.CostControl.AutoLimitReduction = true
+		cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{"tenant-a": {Daily: 10, EnforceBudget: true}}
+		cc := NewCostController(config.NewLive(cfg), logr.Discard())
+		audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+		cc.SetAuditLogger(audit)
+
+		for _, costData := range []*TenantCostData{overBudget, overBudget, withinBudget, withinBudget} {
+			if _, err := cc.EnforceBudgets(context.Background(), map[string]*TenantCostData{"tenant-a": costData}, limits()); err != nil {
+				t.Fatalf("EnforceBudgets: %v", err)
+			}
+		}
+
+		entries, err := audit.GetEntries(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("GetEntries: %v", err)
+		}
+		actions := map[string]int{}
+		for _, entry := range entries {
+			actions[entry.Action]++
+		}
+		if actions["budget_enforcement"] != 1 || actions["budget_enforcement_lifted"] != 1 {
+			t.Errorf("audited actions = %v, want one enforcement and one lift", actions)
+		}
+	})
+}
+
+func TestBudgetReductionFactor(t *testing.T) {
+	budget := config.BudgetConfig{Daily: 10, Monthly: 100}
+	tests := []struct {
+		name string
+		cost TenantCostData
+		want float64
+	}{
+		{"within budget", TenantCostData{DailyCost: 5, MonthlyCost: 50}, 1},
+		{"daily budget exceeded", TenantCostData{DailyCost: 20, MonthlyCost: 50}, 0.5},
+		{"most exceeded period wins", TenantCostData{DailyCost: 20, MonthlyCost: 400}, 0.25},
+		{"floored at the minimum factor", TenantCostData{DailyCost: 1000}, minBudgetReductionFactor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCost(t, "reduction factor", budgetReductionFactor(&tt.cost, budget), tt.want)
+		})
+	}
+}
+
+func TestSpendReport(t *testing.T) {
+	cfg := samplesConfig()
+	cfg.CostControl.AlertThresholds = []float64{50, 80}
+	cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{
+		"tenant-a": {Daily: 5, Monthly: 5, Currency: "EUR"},
+		"tenant-b": {Daily: 100, Monthly: 100},
+	}
+	cfg.CostControl.GlobalBudget = config.BudgetConfig{Monthly: 1000, Currency: "USD"}
+	cc := NewCostController(config.NewLive(cfg), logr.Discard())
+	usage := map[string]*collector.TenantMetrics{
+		"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0),
+		"tenant-b": tenantUsage("tenant-b", 500, 0, 0, 0),
+	}
+	observe(cc, usage, 0, time.Hour)
+
+	report, found := cc.SpendReport("", spendEpoch.Add(time.Hour))
+	if !found || len(report.Tenants) != 2 {
+		t.Fatalf("SpendReport() = %v tenants, found %v, want both tenants", len(report.Tenants), found)
+	}
+	a, b := report.Tenants[0], report.Tenants[1]
+	if a.Tenant != "tenant-a" || b.Tenant != "tenant-b" {
+		t.Fatalf("tenants = %s, %s, want the highest monthly spend first", a.Tenant, b.Tenant)
+	}
+
+	// An hour of spend, tracked from June 17th 08:00, extrapolated to the 328 hours
+	// left until July 1st
+	assertCost(t, "tenant-a monthly spend", a.MonthlySpend, 3.6)
+	assertCost(t, "tenant-a projected month-end spend", a.ProjectedMonthlySpend, 3.6*328)
+	assertCost(t, "tenant-a projected utilization", a.ProjectedMonthlyUtilization, 3.6*328/5*100)
+	assertCost(t, "tenant-a monthly utilization", a.MonthlyUtilization, 72)
+	if !a.OverThreshold || a.AlertThreshold != 50 || a.Currency != "EUR" {
+		t.Errorf("tenant-a = over threshold %v at %v%% in %s, want over 50%% in EUR", a.OverThreshold, a.AlertThreshold, a.Currency)
+	}
+	if b.OverThreshold || b.Currency != "USD" {
+		t.Errorf("tenant-b = over threshold %v in %s, want within its thresholds in the global currency", b.OverThreshold, b.Currency)
+	}
+	if len(report.OverThreshold) != 1 || report.OverThreshold[0] != "tenant-a" {
+		t.Errorf("over threshold = %v, want [tenant-a]", report.OverThreshold)
+	}
+
+	assertCost(t, "global monthly spend", report.Global.MonthlySpend, 5.4)
+	assertCost(t, "global monthly utilization", report.Global.MonthlyUtilization, 0.54)
+	if report.Global.OverThreshold {
+		t.Errorf("global spend over threshold at %v%%", report.Global.MonthlyUtilization)
+	}
+}
+
+func TestSpendReportFiltersTenant(t *testing.T) {
+	cc := NewCostController(config.NewLive(samplesConfig()), logr.Discard())
+	observe(cc, map[string]*collector.TenantMetrics{
+		"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0),
+		"tenant-b": tenantUsage("tenant-b", 500, 0, 0, 0),
+	}, 0, time.Hour)
+
+	report, found := cc.SpendReport("tenant-b", spendEpoch.Add(time.Hour))
+	if !found || len(report.Tenants) != 1 || report.Tenants[0].Tenant != "tenant-b" {
+		t.Fatalf("SpendReport(tenant-b) = %+v, want only tenant-b", report)
+	}
+	assertCost(t, "global monthly spend", report.Global.MonthlySpend, 5.4)
+
+	if _, found := cc.SpendReport("tenant-unknown", spendEpoch.Add(time.Hour)); found {
+		t.Errorf("SpendReport reported a tenant without spend")
+	}
+}
+
+func TestSpendReportStartsNewPeriods(t *testing.T) {
+	cfg := samplesConfig()
+	cfg.CostControl.AlertThresholds = []float64{50}
+	cfg.CostControl.TenantBudgets = map[string]config.BudgetConfig{"tenant-a": {Monthly: 5}}
+	cc := NewCostController(config.NewLive(cfg), logr.Discard())
+	observe(cc, map[string]*collector.TenantMetrics{"tenant-a": tenantUsage("tenant-a", 1000, 0, 0, 0)}, 0, time.Hour)
+
+	// Reported in July without a new observation, June's spend no longer counts
+	report, _ := cc.SpendReport("", time.Date(2026, time.July, 2, 0, 0, 0, 0, time.UTC))
+	if got := report.Tenants[0]; got.MonthlySpend != 0 || got.OverThreshold {
+		t.Errorf("July spend = %v, over threshold %v, want a new month", got.MonthlySpend, got.OverThreshold)
+	}
+	if got := cc.spend["tenant-a"].alerted[PeriodMonthly]; got != 50 {
+		t.Errorf("reporting cleared the alerted monthly threshold: %v", got)
+	}
+}
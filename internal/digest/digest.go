@@ -0,0 +1,383 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/model"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// LimitChange is a single limit update recorded in the audit log
+type LimitChange struct {
+	Timestamp time.Time
+	Limit     string
+	Old       interface{}
+	New       interface{}
+	Direction string // "increase", "decrease", "changed"
+}
+
+// TenantSummary groups the limit changes of one tenant
+type TenantSummary struct {
+	Tenant    string
+	Increases int
+	Decreases int
+	Other     int
+	Changes   []LimitChange
+}
+
+// Event is a circuit-breaker or budget event recorded in the audit log
+type Event struct {
+	Timestamp time.Time
+	Tenant    string
+	Reason    string
+	Source    string
+}
+
+// Digest summarizes the optimizer activity within a time window
+type Digest struct {
+	From                 time.Time
+	To                   time.Time
+	Tenants              []*TenantSummary
+	TotalChanges         int
+	TotalIncreases       int
+	TotalDecreases       int
+	CircuitBreakerEvents []Event
+	BudgetEvents         []Event
+}
+
+// IsEmpty reports whether the digest has nothing worth sending
+func (d *Digest) IsEmpty() bool {
+	return d.TotalChanges == 0 && len(d.CircuitBreakerEvents) == 0 && len(d.BudgetEvents) == 0
+}
+
+// Scheduler sends the daily limit-change digest email
+type Scheduler struct {
+	live        *config.Live
+	auditLogger auditlog.AuditLogger
+	log         logr.Logger
+
+	mu         sync.Mutex
+	lastDigest time.Time
+}
+
+// NewScheduler creates a digest scheduler. The first digest covers the preceding 24 hours.
+func NewScheduler(live *config.Live, auditLogger auditlog.AuditLogger, log logr.Logger) *Scheduler {
+	return &Scheduler{
+		live:        live,
+		auditLogger: auditLogger,
+		log:         log,
+		lastDigest:  time.Now().Add(-24 * time.Hour),
+	}
+}
+
+// config returns the configuration in effect
+func (s *Scheduler) config() *config.Config {
+	return s.live.Load()
+}
+
+// Start runs the scheduler until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	digestConfig := s.config().Alerting.Email.Digest
+	location, err := time.LoadLocation(digestConfig.Timezone)
+	if err != nil {
+		s.log.Error(err, "invalid digest timezone, using UTC", "timezone", digestConfig.Timezone)
+		location = time.UTC
+	}
+
+	go func() {
+		for {
+			next, err := NextRun(time.Now(), digestConfig.SendAt, location)
+			if err != nil {
+				s.log.Error(err, "invalid digest send time, digest disabled", "send_at", digestConfig.SendAt)
+				return
+			}
+
+			s.log.V(1).Info("scheduled limit-change digest", "next_run", next)
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+				if err := s.SendDigest(ctx); err != nil {
+					s.log.Error(err, "failed to send limit-change digest")
+				}
+			}
+		}
+	}()
+}
+
+// SendDigest builds and emails the digest for all audit entries since the last digest.
+// Nothing is sent when no changes or events occurred.
+func (s *Scheduler) SendDigest(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from := s.lastDigest
+	to := time.Now()
+
+	entries, err := s.auditLogger.GetEntries(ctx, &auditlog.AuditFilter{
+		StartTime: &from,
+		EndTime:   &to,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read audit entries: %w", err)
+	}
+
+	digest := Build(entries, from, to)
+	if digest.IsEmpty() {
+		s.log.Info("no limit changes since last digest, skipping email", "since", from)
+		metrics.AlertingMetricsInstance.IncEmailSent("digest", "skipped")
+		s.lastDigest = to
+		return nil
+	}
+
+	body, err := Render(digest)
+	if err != nil {
+		return fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("[mimir-limit-optimizer] Daily digest: %d limit change(s) across %d tenant(s)",
+		digest.TotalChanges, len(digest.Tenants))
+	// The sender follows the SMTP settings of the configuration in effect
+	sender := alerting.NewSMTPSender(s.config().Alerting.Email)
+	msg := sender.BuildMessage(subject, "text/html", body, nil)
+
+	if err := sender.Send(ctx, "digest", msg); err != nil {
+		return fmt.Errorf("failed to send digest email: %w", err)
+	}
+
+	s.lastDigest = to
+	s.log.Info("sent limit-change digest",
+		"tenants", len(digest.Tenants),
+		"changes", digest.TotalChanges,
+		"circuit_breaker_events", len(digest.CircuitBreakerEvents),
+		"budget_events", len(digest.BudgetEvents))
+
+	return nil
+}
+
+// Build groups audit entries into a digest
+func Build(entries []*auditlog.AuditEntry, from, to time.Time) *Digest {
+	digest := &Digest{From: from, To: to}
+	tenants := make(map[string]*TenantSummary)
+
+	for _, entry := range entries {
+		if entry == nil || !entry.Success {
+			continue
+		}
+
+		switch {
+		case isBudgetEvent(entry):
+			digest.BudgetEvents = append(digest.BudgetEvents, newEvent(entry))
+		case isCircuitBreakerEvent(entry):
+			digest.CircuitBreakerEvents = append(digest.CircuitBreakerEvents, newEvent(entry))
+		}
+
+		if entry.Action != "update-limits" {
+			continue
+		}
+
+		summary, exists := tenants[entry.Tenant]
+		if !exists {
+			summary = &TenantSummary{Tenant: entry.Tenant}
+			tenants[entry.Tenant] = summary
+		}
+
+		for limitName, raw := range entry.Changes {
+			change, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			limitChange := LimitChange{
+				Timestamp: entry.Timestamp,
+				Limit:     limitName,
+				Old:       change["old"],
+				New:       change["new"],
+				Direction: direction(change["old"], change["new"]),
+			}
+			if limitChange.Direction == "" {
+				continue
+			}
+
+			switch limitChange.Direction {
+			case "increase":
+				summary.Increases++
+				digest.TotalIncreases++
+			case "decrease":
+				summary.Decreases++
+				digest.TotalDecreases++
+			default:
+				summary.Other++
+			}
+			summary.Changes = append(summary.Changes, limitChange)
+			digest.TotalChanges++
+		}
+	}
+
+	for _, summary := range tenants {
+		if len(summary.Changes) == 0 {
+			continue
+		}
+		sort.Slice(summary.Changes, func(i, j int) bool {
+			return summary.Changes[i].Timestamp.Before(summary.Changes[j].Timestamp)
+		})
+		digest.Tenants = append(digest.Tenants, summary)
+	}
+	sort.Slice(digest.Tenants, func(i, j int) bool {
+		return digest.Tenants[i].Tenant < digest.Tenants[j].Tenant
+	})
+
+	return digest
+}
+
+// Render renders the digest as an HTML email body
+func Render(digest *Digest) (string, error) {
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, digest); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NextRun returns the next occurrence of the HH:MM send time after now
+func NextRun(now time.Time, sendAt string, location *time.Location) (time.Time, error) {
+	clock, err := time.Parse("15:04", sendAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid send time %q: %w", sendAt, err)
+	}
+
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+func isBudgetEvent(entry *auditlog.AuditEntry) bool {
+	return entry.Source == "cost-control" || strings.Contains(entry.Reason, "budget")
+}
+
+func isCircuitBreakerEvent(entry *auditlog.AuditEntry) bool {
+	return entry.Source == "circuit-breaker" ||
+		strings.HasPrefix(entry.Reason, "circuit_breaker") ||
+		entry.Reason == "emergency_mode" ||
+		entry.Reason == "panic_mode"
+}
+
+func newEvent(entry *auditlog.AuditEntry) Event {
+	return Event{
+		Timestamp: entry.Timestamp,
+		Tenant:    entry.Tenant,
+		Reason:    entry.Reason,
+		Source:    entry.Source,
+	}
+}
+
+// direction classifies a change; it returns "" when the value did not change
+func direction(oldValue, newValue interface{}) string {
+	if newValue == nil && oldValue == nil {
+		return ""
+	}
+	if oldValue == nil {
+		return "increase"
+	}
+	if newValue == nil {
+		return "decrease"
+	}
+
+	oldNumber, oldOK := toFloat64(oldValue)
+	newNumber, newOK := toFloat64(newValue)
+	if oldOK && newOK {
+		switch {
+		case newNumber > oldNumber:
+			return "increase"
+		case newNumber < oldNumber:
+			return "decrease"
+		default:
+			return ""
+		}
+	}
+
+	if fmt.Sprintf("%v", oldValue) == fmt.Sprintf("%v", newValue) {
+		return ""
+	}
+	return "changed"
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case time.Duration:
+		return v.Seconds(), true
+	case string:
+		if d, err := model.ParseDuration(v); err == nil {
+			return time.Duration(d).Seconds(), true
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+}
+
+var digestTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"ts": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+	"value": func(v interface{}) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%v", v)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, Helvetica, Arial, sans-serif; color: #1d1c1d;">
+<h2>Mimir Limit Optimizer &mdash; daily digest</h2>
+<p>{{ts .From}} &ndash; {{ts .To}}</p>
+<p><strong>{{.TotalChanges}}</strong> limit change(s) across <strong>{{len .Tenants}}</strong> tenant(s):
+{{.TotalIncreases}} increase(s), {{.TotalDecreases}} decrease(s).</p>
+{{if .CircuitBreakerEvents}}
+<h3>Circuit breaker events ({{len .CircuitBreakerEvents}})</h3>
+<ul>
+{{range .CircuitBreakerEvents}}<li>{{ts .Timestamp}} &mdash; {{if .Tenant}}{{.Tenant}}: {{end}}{{.Reason}}</li>
+{{end}}</ul>
+{{end}}
+{{if .BudgetEvents}}
+<h3>Budget events ({{len .BudgetEvents}})</h3>
+<ul>
+{{range .BudgetEvents}}<li>{{ts .Timestamp}} &mdash; {{if .Tenant}}{{.Tenant}}: {{end}}{{.Reason}}</li>
+{{end}}</ul>
+{{end}}
+{{range .Tenants}}
+<h3>{{.Tenant}}</h3>
+<p>{{.Increases}} increase(s), {{.Decreases}} decrease(s){{if .Other}}, {{.Other}} other change(s){{end}}</p>
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse; font-size: 13px;">
+<tr style="background: #f4f4f4;"><th>Time</th><th>Limit</th><th>Before</th><th>After</th><th>Change</th></tr>
+{{range .Changes}}<tr><td>{{ts .Timestamp}}</td><td><code>{{.Limit}}</code></td><td>{{value .Old}}</td><td>{{value .New}}</td><td>{{.Direction}}</td></tr>
+{{end}}</table>
+{{end}}
+<p style="color: #616061; font-size: 12px;">Generated by Mimir Limit Optimizer</p>
+</body>
+</html>
+`))
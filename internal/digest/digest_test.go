@@ -0,0 +1,328 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+var digestStart = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// limitUpdate is a successful update-limits entry of tenant at minute, with the changes
+// as limit -> {old, new}
+func limitUpdate(tenant string, minute int, changes map[string][2]interface{}) *auditlog.AuditEntry {
+	entry := &auditlog.AuditEntry{
+		Timestamp: digestStart.Add(time.Duration(minute) * time.Minute),
+		Tenant:    tenant,
+		Action:    "update-limits",
+		Reason:    "usage-based",
+		Source:    "controller",
+		Success:   true,
+		Changes:   make(map[string]interface{}),
+	}
+	for limit, change := range changes {
+		entry.Changes[limit] = map[string]interface{}{"old": change[0], "new": change[1]}
+	}
+	return entry
+}
+
+// event is a successful entry of source with the reason
+func event(tenant, action, reason, source string) *auditlog.AuditEntry {
+	return &auditlog.AuditEntry{
+		Timestamp: digestStart,
+		Tenant:    tenant,
+		Action:    action,
+		Reason:    reason,
+		Source:    source,
+		Success:   true,
+	}
+}
+
+func TestBuild(t *testing.T) {
+	failed := limitUpdate("tenant-a", 5, map[string][2]interface{}{"ingestion_rate": {1000.0, 2000.0}})
+	failed.Success = false
+
+	tests := []struct {
+		name                string
+		entries             []*auditlog.AuditEntry
+		wantTenants         []string
+		wantChanges         int
+		wantIncreases       int
+		wantDecreases       int
+		wantOther           int
+		wantCircuitBreakers int
+		wantBudgetEvents    int
+		wantEmpty           bool
+	}{
+		{
+			name:      "no entries",
+			wantEmpty: true,
+		},
+		{
+			name: "increases, decreases and other changes",
+			entries: []*auditlog.AuditEntry{
+				limitUpdate("tenant-b", 2, map[string][2]interface{}{
+					"ingestion_rate":             {1000.0, 2000.0},
+					"max_global_series_per_user": {int64(50000), int64(40000)},
+				}),
+				limitUpdate("tenant-a", 1, map[string][2]interface{}{
+					"ingestion_burst_size":  {nil, 5000.0},
+					"max_query_lookback":    {"7d", "14d"},
+					"compactor_block_range": {"a", "b"},
+				}),
+			},
+			wantTenants:   []string{"tenant-a", "tenant-b"},
+			wantChanges:   5,
+			wantIncreases: 3,
+			wantDecreases: 1,
+			wantOther:     1,
+		},
+		{
+			name: "unchanged values and failed updates are left out",
+			entries: []*auditlog.AuditEntry{
+				limitUpdate("tenant-a", 1, map[string][2]interface{}{
+					"ingestion_rate":     {1000.0, 1000},
+					"max_query_lookback": {"24h", "1d"},
+				}),
+				failed,
+			},
+			wantEmpty: true,
+		},
+		{
+			name: "budget and circuit breaker events",
+			entries: []*auditlog.AuditEntry{
+				event("tenant-a", "budget-exceeded", "monthly budget exceeded", "cost-control"),
+				event("tenant-b", "enforce", "budget_violation", "controller"),
+				event("", "panic-mode", "panic_mode", "controller"),
+				event("tenant-c", "trip", "circuit_breaker_open", "controller"),
+				event("tenant-d", "open", "rate spike", "circuit-breaker"),
+				event("tenant-e", "noop", "scheduled", "controller"),
+			},
+			wantCircuitBreakers: 3,
+			wantBudgetEvents:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digest := Build(tt.entries, digestStart, digestStart.Add(24*time.Hour))
+
+			if got := digest.IsEmpty(); got != tt.wantEmpty {
+				t.Errorf("IsEmpty() = %v, want %v", got, tt.wantEmpty)
+			}
+			var tenants []string
+			other := 0
+			for _, summary := range digest.Tenants {
+				tenants = append(tenants, summary.Tenant)
+				other += summary.Other
+				for i := 1; i < len(summary.Changes); i++ {
+					if summary.Changes[i].Timestamp.Before(summary.Changes[i-1].Timestamp) {
+						t.Errorf("changes of %s are not in time order", summary.Tenant)
+					}
+				}
+			}
+			if len(tenants) != len(tt.wantTenants) {
+				t.Fatalf("tenants = %v, want %v", tenants, tt.wantTenants)
+			}
+			for i := range tenants {
+				if tenants[i] != tt.wantTenants[i] {
+					t.Errorf("tenants = %v, want %v", tenants, tt.wantTenants)
+					break
+				}
+			}
+			if digest.TotalChanges != tt.wantChanges {
+				t.Errorf("TotalChanges = %d, want %d", digest.TotalChanges, tt.wantChanges)
+			}
+			if digest.TotalIncreases != tt.wantIncreases {
+				t.Errorf("TotalIncreases = %d, want %d", digest.TotalIncreases, tt.wantIncreases)
+			}
+			if digest.TotalDecreases != tt.wantDecreases {
+				t.Errorf("TotalDecreases = %d, want %d", digest.TotalDecreases, tt.wantDecreases)
+			}
+			if other != tt.wantOther {
+				t.Errorf("other changes = %d, want %d", other, tt.wantOther)
+			}
+			if len(digest.CircuitBreakerEvents) != tt.wantCircuitBreakers {
+				t.Errorf("circuit breaker events = %d, want %d", len(digest.CircuitBreakerEvents), tt.wantCircuitBreakers)
+			}
+			if len(digest.BudgetEvents) != tt.wantBudgetEvents {
+				t.Errorf("budget events = %d, want %d", len(digest.BudgetEvents), tt.wantBudgetEvents)
+			}
+		})
+	}
+}
+
+func TestSendDigestSkipsEmptyDigest(t *testing.T) {
+	metricstest.Register(t)
+	skipped := map[string]string{"kind": "digest", "result": "skipped"}
+	before := metricstest.Value(t, "mimir_limit_optimizer_email_sent_total", skipped)
+
+	auditLogger := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	// Failed updates do not count, and the email settings point nowhere
+	failed := limitUpdate("tenant-a", 0, map[string][2]interface{}{"ingestion_rate": {1000.0, 2000.0}})
+	failed.Timestamp = time.Now().Add(-time.Hour)
+	failed.Success = false
+	if err := auditLogger.LogEntry(failed); err != nil {
+		t.Fatalf("LogEntry: %v", err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Alerting.Email.SMTPHost = "127.0.0.1"
+	cfg.Alerting.Email.SMTPPort = 1
+	scheduler := NewScheduler(config.NewLive(cfg), auditLogger, logr.Discard())
+	since := scheduler.lastDigest
+
+	if err := scheduler.SendDigest(context.Background()); err != nil {
+		t.Fatalf("SendDigest: %v", err)
+	}
+	if !scheduler.lastDigest.After(since) {
+		t.Errorf("lastDigest = %v, want it moved past %v", scheduler.lastDigest, since)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_email_sent_total", skipped); got != before+1 {
+		t.Errorf("skipped digests = %v, want %v", got, before+1)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		sendAt   string
+		location *time.Location
+		want     time.Time
+	}{
+		{
+			name:     "later the same day",
+			now:      time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC),
+			sendAt:   "08:00",
+			location: time.UTC,
+			want:     time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "already past, next day",
+			now:      time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC),
+			sendAt:   "08:00",
+			location: time.UTC,
+			want:     time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "exactly at the send time, next day",
+			now:      time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC),
+			sendAt:   "08:00",
+			location: time.UTC,
+			want:     time.Date(2026, 3, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "send time in the configured timezone",
+			now:      time.Date(2026, 3, 10, 6, 30, 0, 0, time.UTC), // 07:30 in Berlin
+			sendAt:   "08:00",
+			location: berlin,
+			want:     time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "past in the configured timezone but not in UTC",
+			now:      time.Date(2026, 3, 10, 7, 30, 0, 0, time.UTC), // 08:30 in Berlin
+			sendAt:   "08:00",
+			location: berlin,
+			want:     time.Date(2026, 3, 11, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "local date differs from the UTC date",
+			now:      time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC), // 00:30 on the 11th in Berlin
+			sendAt:   "06:00",
+			location: berlin,
+			want:     time.Date(2026, 3, 11, 5, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRun(tt.now, tt.sendAt, tt.location)
+			if err != nil {
+				t.Fatalf("NextRun: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRun(%v, %q) = %v, want %v", tt.now, tt.sendAt, got.UTC(), tt.want)
+			}
+			if got.Location() != tt.location {
+				t.Errorf("NextRun location = %v, want %v", got.Location(), tt.location)
+			}
+		})
+	}
+}
+
+func TestNextRunInvalidSendTime(t *testing.T) {
+	for _, sendAt := range []string{"", "8am", "25:00", "08:61"} {
+		if _, err := NextRun(digestStart, sendAt, time.UTC); err == nil {
+			t.Errorf("NextRun(%q) succeeded, want an error", sendAt)
+		}
+	}
+}
+
+func TestDirection(t *testing.T) {
+	tests := []struct {
+		old  interface{}
+		new  interface{}
+		want string
+	}{
+		{nil, nil, ""},
+		{nil, 100.0, "increase"},
+		{100.0, nil, "decrease"},
+		{100.0, 200.0, "increase"},
+		{200.0, 100.0, "decrease"},
+		{100.0, 100.0, ""},
+		{int64(100), 150.0, "increase"},
+		{100, int64(50), "decrease"},
+		{float32(1.5), 1.5, ""},
+		{"1h", "2h", "increase"},
+		{"2h", 30 * time.Minute, "decrease"},
+		{"24h", "1d", ""},
+		{time.Hour, 3600.0, ""},
+		{"a", "b", "changed"},
+		{"a", "a", ""},
+		{"a", 100.0, "changed"},
+		{true, false, "changed"},
+	}
+	for _, tt := range tests {
+		if got := direction(tt.old, tt.new); got != tt.want {
+			t.Errorf("direction(%#v, %#v) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestToFloat64(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{2.5, 2.5, true},
+		{float32(0.5), 0.5, true},
+		{int64(42), 42, true},
+		{7, 7, true},
+		{90 * time.Second, 90, true},
+		{"5m", 300, true},
+		{"1d", 86400, true},
+		{"100", 0, false},
+		{"fast", 0, false},
+		{true, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := toFloat64(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("toFloat64(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -3,7 +3,11 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -21,12 +25,21 @@ import (
 
 // AutonomousScanner provides comprehensive AI-enabled Mimir infrastructure scanning
 type AutonomousScanner struct {
-	client    kubernetes.Interface
-	config    *config.Config
-	log       logr.Logger
-	namespace string
+	client     kubernetes.Interface
+	live       *config.Live
+	log        logr.Logger
+	namespace  string
+	httpClient *http.Client
+	components *ComponentDetector
 }
 
+// Bounds for probing candidate metrics endpoints during a scan
+const (
+	endpointProbeTimeout     = 2 * time.Second
+	endpointProbeConcurrency = 16
+	endpointProbeBudget      = 30 * time.Second
+)
+
 // MimirInfrastructure represents the complete Mimir infrastructure discovery
 type MimirInfrastructure struct {
 	Namespace       string                          `json:"namespace"`
@@ -170,15 +183,37 @@ type ConfigSource struct {
 }
 
 // NewAutonomousScanner creates a new comprehensive Mimir scanner
-func NewAutonomousScanner(client kubernetes.Interface, cfg *config.Config, log logr.Logger) *AutonomousScanner {
+func NewAutonomousScanner(client kubernetes.Interface, live *config.Live, log logr.Logger) *AutonomousScanner {
+	cfg := live.Load()
 	return &AutonomousScanner{
 		client:    client,
-		config:    cfg,
+		live:      live,
 		log:       log.WithName("autonomous-scanner"),
 		namespace: cfg.Mimir.Namespace,
+		httpClient: &http.Client{
+			Timeout: endpointProbeTimeout,
+		},
+		components: NewComponentDetectorOrDefault(cfg.MetricsDiscovery, log),
 	}
 }
 
+// config returns the configuration in effect
+func (s *AutonomousScanner) config() *config.Config {
+	return s.live.Load()
+}
+
+// ScanMimirInfrastructureInNamespace scans a Mimir installation in a namespace other
+// than the configured one
+func (s *AutonomousScanner) ScanMimirInfrastructureInNamespace(ctx context.Context, namespace string) (*MimirInfrastructure, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	scoped := *s
+	scoped.namespace = namespace
+	return scoped.ScanMimirInfrastructure(ctx)
+}
+
 // ScanMimirInfrastructure performs a comprehensive scan of the entire Mimir infrastructure
 func (s *AutonomousScanner) ScanMimirInfrastructure(ctx context.Context) (*MimirInfrastructure, error) {
 	s.log.Info("Starting comprehensive Mimir infrastructure scan", "namespace", s.namespace)
@@ -250,6 +285,39 @@ func (s *AutonomousScanner) ScanMimirInfrastructure(ctx context.Context) (*Mimir
 	return infrastructure, nil
 }
 
+// ComponentMetricsURLs returns the answering metrics URLs of the components of one type,
+// such as "overrides-exporter", probing only their services rather than scanning the
+// whole infrastructure. URLs are ordered by component name.
+func (s *AutonomousScanner) ComponentMetricsURLs(ctx context.Context, componentType string) ([]string, error) {
+	resources, err := s.scanKubernetesResources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan Kubernetes resources: %w", err)
+	}
+	components, err := s.identifyMimirComponents(ctx, resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to identify Mimir components: %w", err)
+	}
+
+	matching := make(map[string]*MimirComponent)
+	names := make([]string, 0)
+	for name, component := range components {
+		if component.Type == componentType {
+			matching[name] = component
+			names = append(names, name)
+		}
+	}
+	if _, err := s.discoverMetricsEndpoints(ctx, matching); err != nil {
+		return nil, fmt.Errorf("failed to discover metrics endpoints: %w", err)
+	}
+
+	sort.Strings(names)
+	urls := make([]string, 0)
+	for _, name := range names {
+		urls = append(urls, matching[name].MetricsURLs...)
+	}
+	return urls, nil
+}
+
 // scanKubernetesResources scans all Kubernetes resources in the namespace
 func (s *AutonomousScanner) scanKubernetesResources(ctx context.Context) (*ResourceInventory, error) {
 	inventory := &ResourceInventory{}
@@ -502,37 +570,7 @@ func (s *AutonomousScanner) identifyMimirComponents(ctx context.Context, resourc
 
 // identifyComponentType identifies what type of Mimir component this is
 func (s *AutonomousScanner) identifyComponentType(name string, labels map[string]string) string {
-	name = strings.ToLower(name)
-
-	// Check common label patterns first
-	if labels != nil {
-		if component, ok := labels["app.kubernetes.io/component"]; ok {
-			return component
-		}
-		if component, ok := labels["component"]; ok {
-			return component
-		}
-	}
-
-	// Fallback to name-based detection
-	componentPatterns := []string{
-		"distributor", "ingester", "querier", "query-frontend", "query-scheduler",
-		"compactor", "store-gateway", "ruler", "alertmanager", "nginx", "gateway",
-		"overrides-exporter",
-	}
-
-	for _, pattern := range componentPatterns {
-		if strings.Contains(name, pattern) {
-			return pattern
-		}
-	}
-
-	// If it contains "mimir" but no specific component, it's likely a Mimir component
-	if strings.Contains(name, "mimir") {
-		return "unknown-mimir-component"
-	}
-
-	return ""
+	return s.components.ComponentType(name, labels)
 }
 
 // discoverMetricsEndpoints discovers all metrics endpoints from components
@@ -547,28 +585,39 @@ func (s *AutonomousScanner) discoverMetricsEndpoints(ctx context.Context, compon
 	// Standard metrics paths to try
 	metricsPaths := []string{"/metrics", "/prometheus/metrics", "/debug/pprof/metrics"}
 
+	var candidates []MetricsEndpoint
 	for _, component := range components {
 		for _, service := range component.Services {
 			for _, path := range metricsPaths {
-				// Try common metrics ports
-				ports := []int32{8080, 9090, 8081, 3000, 80}
-
-				for _, port := range ports {
-					endpoint := MetricsEndpoint{
-						URL:        fmt.Sprintf("http://%s.%s.svc.cluster.local:%d%s", service.Name, s.namespace, port, path),
-						Component:  component.Name,
-						Port:       port,
-						Path:       path,
-						Labels:     service.Labels,
-						Accessible: true, // We'll verify this later
-					}
-					discovery.Endpoints = append(discovery.Endpoints, endpoint)
-					component.MetricsURLs = append(component.MetricsURLs, endpoint.URL)
+				for _, port := range candidatePorts(service) {
+					candidates = append(candidates, MetricsEndpoint{
+						URL:       fmt.Sprintf("http://%s.%s.svc.cluster.local:%d%s", service.Name, s.namespace, port, path),
+						Component: component.Name,
+						Port:      port,
+						Path:      path,
+						Labels:    service.Labels,
+					})
 				}
 			}
 		}
 	}
 
+	// Only keep endpoints that actually answer
+	s.probeEndpoints(ctx, candidates)
+	for _, endpoint := range candidates {
+		if !endpoint.Accessible {
+			continue
+		}
+		discovery.Endpoints = append(discovery.Endpoints, endpoint)
+		if component, exists := components[endpoint.Component]; exists {
+			component.MetricsURLs = append(component.MetricsURLs, endpoint.URL)
+		}
+	}
+
+	s.log.Info("Probed metrics endpoints",
+		"candidates", len(candidates),
+		"accessible", len(discovery.Endpoints))
+
 	// Categorize metrics by component role
 	for _, component := range components {
 		role := component.Role
@@ -581,6 +630,80 @@ func (s *AutonomousScanner) discoverMetricsEndpoints(ctx context.Context, compon
 	return discovery, nil
 }
 
+// candidatePorts returns the service's declared ports followed by common metrics ports
+func candidatePorts(service ServiceInfo) []int32 {
+	seen := make(map[int32]bool)
+	var ports []int32
+
+	declared := make([]int32, 0, len(service.Ports))
+	for _, port := range service.Ports {
+		declared = append(declared, port)
+	}
+	sort.Slice(declared, func(i, j int) bool { return declared[i] < declared[j] })
+
+	for _, port := range append(declared, 8080, 9090, 8081, 3000, 80) {
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// probeEndpoints GETs every candidate concurrently and marks those answering 200 as
+// accessible. Concurrency and total probe time are bounded so large scans stay cheap.
+func (s *AutonomousScanner) probeEndpoints(ctx context.Context, endpoints []MetricsEndpoint) {
+	probeCtx, cancel := context.WithTimeout(ctx, endpointProbeBudget)
+	defer cancel()
+
+	semaphore := make(chan struct{}, endpointProbeConcurrency)
+	var wg sync.WaitGroup
+
+	for i := range endpoints {
+		wg.Add(1)
+		go func(endpoint *MetricsEndpoint) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-probeCtx.Done():
+				return
+			}
+
+			endpoint.Accessible = s.probeEndpoint(probeCtx, endpoint.URL)
+		}(&endpoints[i])
+	}
+
+	wg.Wait()
+}
+
+// probeEndpoint reports whether a metrics URL responds with 200 OK
+func (s *AutonomousScanner) probeEndpoint(ctx context.Context, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+
+	// Multi-tenant gateways may require tenant scoping even for metrics
+	if tenantID := s.config().MetricsDiscovery.TenantDiscovery.MetricsTenantID; tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", tenantID)
+	}
+	for key, value := range s.config().MetricsDiscovery.TenantDiscovery.TenantHeaders {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		s.log.V(2).Info("Metrics endpoint not reachable", "url", url, "error", err)
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode == http.StatusOK
+}
+
 // extractConfiguration extracts all Mimir configuration from ConfigMaps and Secrets
 func (s *AutonomousScanner) extractConfiguration(ctx context.Context) (*ConfigurationScan, map[string]*TenantConfiguration, error) {
 	configScan := &ConfigurationScan{
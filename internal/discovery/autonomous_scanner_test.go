@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func newTestScanner(cfg *config.Config) *AutonomousScanner {
+	return NewAutonomousScanner(fake.NewSimpleClientset(), config.NewLive(cfg), logr.Discard())
+}
+
+func TestProbeEndpointsMarksOnlyReachableEndpoints(t *testing.T) {
+	var mu sync.Mutex
+	tenantHeaders := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tenantHeaders[r.URL.Path] = r.Header.Get("X-Scope-OrgID")
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/metrics":
+			_, _ = w.Write([]byte("cortex_build_info 1\n"))
+		case "/prometheus/metrics":
+			w.WriteHeader(http.StatusNotFound)
+		case "/debug/pprof/metrics":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/redirected":
+			http.Redirect(w, r, "/metrics", http.StatusFound)
+		case "/slow":
+			time.Sleep(time.Second)
+		}
+	}))
+	defer server.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cfg := config.GetDefaultConfig()
+	cfg.MetricsDiscovery.TenantDiscovery.MetricsTenantID = "anonymous"
+	s := newTestScanner(cfg)
+	s.httpClient.Timeout = 100 * time.Millisecond
+
+	endpoints := []MetricsEndpoint{
+		{URL: server.URL + "/metrics"},
+		{URL: server.URL + "/prometheus/metrics"},
+		{URL: server.URL + "/debug/pprof/metrics"},
+		{URL: server.URL + "/redirected"},
+		{URL: server.URL + "/slow"},
+		{URL: closed.URL + "/metrics"},
+	}
+	s.probeEndpoints(context.Background(), endpoints)
+
+	want := []bool{true, false, false, true, false, false}
+	for i, endpoint := range endpoints {
+		if endpoint.Accessible != want[i] {
+			t.Errorf("%s accessible = %v, want %v", endpoint.URL, endpoint.Accessible, want[i])
+		}
+	}
+	if got := tenantHeaders["/metrics"]; got != "anonymous" {
+		t.Errorf("X-Scope-OrgID = %q, want the configured metrics tenant", got)
+	}
+}
+
+func TestProbeEndpointsBoundsConcurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	s := newTestScanner(config.GetDefaultConfig())
+	endpoints := make([]MetricsEndpoint, 3*endpointProbeConcurrency)
+	for i := range endpoints {
+		endpoints[i].URL = server.URL + "/metrics/" + strconv.Itoa(i)
+	}
+	s.probeEndpoints(context.Background(), endpoints)
+
+	if got := maxInFlight.Load(); got > endpointProbeConcurrency {
+		t.Errorf("%d probes in flight, want at most %d", got, endpointProbeConcurrency)
+	}
+	for _, endpoint := range endpoints {
+		if !endpoint.Accessible {
+			t.Fatalf("%s was not probed", endpoint.URL)
+		}
+	}
+}
+
+func TestProbeEndpointsStopsWhenCancelled(t *testing.T) {
+	s := newTestScanner(config.GetDefaultConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	endpoints := []MetricsEndpoint{{URL: "http://127.0.0.1:1/metrics"}}
+	s.probeEndpoints(ctx, endpoints)
+
+	if endpoints[0].Accessible {
+		t.Errorf("endpoint probed after cancellation is accessible")
+	}
+}
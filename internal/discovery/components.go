@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// Standard Kubernetes labels set by the Mimir Helm chart and most other installs
+const (
+	nameLabel                     = "app.kubernetes.io/name"
+	defaultComponentLabel         = "app.kubernetes.io/component"
+	defaultComponentLabelSelector = nameLabel + "=mimir"
+
+	// unknownMimirComponent is reported for Mimir workloads whose component cannot be told
+	unknownMimirComponent = "unknown-mimir-component"
+)
+
+// mimirComponentPatterns are the known Mimir components. Longer names come before
+// names they contain so that "store-gateway" is not detected as "gateway".
+var mimirComponentPatterns = []string{
+	"distributor", "ingester", "querier", "query-frontend", "query-scheduler",
+	"compactor", "store-gateway", "ruler", "alertmanager", "overrides-exporter",
+	"nginx", "gateway",
+}
+
+// ComponentDetector identifies the Mimir component a workload runs. Workloads
+// matching the configured label selector, or carrying the standard
+// app.kubernetes.io/name=mimir label, are identified by their component label, so
+// detection does not depend on Helm release prefixes or other naming conventions.
+// Unlabeled workloads fall back to name patterns.
+type ComponentDetector struct {
+	selector       labels.Selector
+	componentLabel string
+}
+
+// NewComponentDetector creates a detector from the metrics discovery configuration
+func NewComponentDetector(cfg config.MetricsDiscoveryConfig) (*ComponentDetector, error) {
+	expression := cfg.ComponentLabelSelector
+	if expression == "" {
+		expression = defaultComponentLabelSelector
+	}
+
+	selector, err := labels.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metricsDiscovery.componentLabelSelector %q: %w", expression, err)
+	}
+
+	componentLabel := cfg.ComponentLabel
+	if componentLabel == "" {
+		componentLabel = defaultComponentLabel
+	}
+
+	return &ComponentDetector{
+		selector:       selector,
+		componentLabel: componentLabel,
+	}, nil
+}
+
+// NewComponentDetectorOrDefault creates a detector for cfg, falling back to the default
+// selector when the configured one does not parse
+func NewComponentDetectorOrDefault(cfg config.MetricsDiscoveryConfig, log logr.Logger) *ComponentDetector {
+	detector, err := NewComponentDetector(cfg)
+	if err != nil {
+		log.Error(err, "falling back to default component label selector", "selector", defaultComponentLabelSelector)
+		cfg.ComponentLabelSelector = ""
+		detector, _ = NewComponentDetector(cfg)
+	}
+	return detector
+}
+
+// IsMimirWorkload reports whether the labels identify a Mimir workload
+func (d *ComponentDetector) IsMimirWorkload(workloadLabels map[string]string) bool {
+	if workloadLabels[nameLabel] == "mimir" {
+		return true
+	}
+	return len(workloadLabels) > 0 && d.selector.Matches(labels.Set(workloadLabels))
+}
+
+// ComponentType returns the Mimir component of a workload, or "" if it is not part
+// of Mimir
+func (d *ComponentDetector) ComponentType(name string, workloadLabels map[string]string) string {
+	if d.IsMimirWorkload(workloadLabels) {
+		if component := d.labeledComponent(workloadLabels); component != "" {
+			if known := matchComponentPattern(component); known != "" {
+				return known
+			}
+			return component
+		}
+		if component := matchComponentPattern(name); component != "" {
+			return component
+		}
+		return unknownMimirComponent
+	}
+
+	// Outside the selector only trust component labels naming a known Mimir component
+	if component := d.labeledComponent(workloadLabels); component != "" {
+		if known := matchComponentPattern(component); known != "" {
+			return known
+		}
+	}
+
+	if component := matchComponentPattern(name); component != "" {
+		return component
+	}
+
+	// If it contains "mimir" but no specific component, it's likely a Mimir component
+	if strings.Contains(strings.ToLower(name), "mimir") {
+		return unknownMimirComponent
+	}
+
+	return ""
+}
+
+func (d *ComponentDetector) labeledComponent(workloadLabels map[string]string) string {
+	for _, key := range []string{d.componentLabel, defaultComponentLabel, "component"} {
+		if component := workloadLabels[key]; component != "" {
+			return strings.ToLower(component)
+		}
+	}
+	return ""
+}
+
+// matchComponentPattern returns the known component contained in name
+func matchComponentPattern(name string) string {
+	name = strings.ToLower(name)
+	for _, pattern := range mimirComponentPatterns {
+		if strings.Contains(name, pattern) {
+			return pattern
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func TestComponentType(t *testing.T) {
+	mimirLabels := func(component string) map[string]string {
+		labels := map[string]string{"app.kubernetes.io/name": "mimir"}
+		if component != "" {
+			labels["app.kubernetes.io/component"] = component
+		}
+		return labels
+	}
+
+	tests := []struct {
+		name     string
+		workload string
+		labels   map[string]string
+		want     string
+	}{
+		{"labeled with an unrelated name", "ingest-tier-a", mimirLabels("ingester"), "ingester"},
+		{"label wins over the name", "blue-querier", mimirLabels("store-gateway"), "store-gateway"},
+		{"labeled with a release prefix", "myrelease-write-0", mimirLabels("distributor"), "distributor"},
+		{"labeled component outside the known ones", "team-a", mimirLabels("continuous-test"), "continuous-test"},
+		{"mimir workload without a component label", "blue", mimirLabels(""), unknownMimirComponent},
+		{"mimir workload named after its component", "green-compactor", mimirLabels(""), "compactor"},
+		{"known component label without the name label", "backend-7", map[string]string{"app.kubernetes.io/component": "querier"}, "querier"},
+		{"unknown component label without the name label", "redis", map[string]string{"app.kubernetes.io/component": "cache"}, ""},
+		{"unlabeled release-prefixed name", "myrelease-mimir-distributor", nil, "distributor"},
+		{"store-gateway is not the gateway", "myrelease-mimir-store-gateway", nil, "store-gateway"},
+		{"unlabeled mimir name", "mimir-tools", nil, unknownMimirComponent},
+		{"unrelated workload", "grafana", map[string]string{"app.kubernetes.io/name": "grafana"}, ""},
+	}
+
+	detector, err := NewComponentDetector(config.GetDefaultConfig().MetricsDiscovery)
+	if err != nil {
+		t.Fatalf("NewComponentDetector: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detector.ComponentType(tt.workload, tt.labels); got != tt.want {
+				t.Errorf("ComponentType(%q, %v) = %q, want %q", tt.workload, tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComponentTypeCustomSelector(t *testing.T) {
+	cfg := config.GetDefaultConfig().MetricsDiscovery
+	cfg.ComponentLabelSelector = "team=observability,tier in (write,read)"
+	cfg.ComponentLabel = "role"
+	detector, err := NewComponentDetector(cfg)
+	if err != nil {
+		t.Fatalf("NewComponentDetector: %v", err)
+	}
+
+	selected := map[string]string{"team": "observability", "tier": "write", "role": "Distributor"}
+	if got := detector.ComponentType("write-path", selected); got != "distributor" {
+		t.Errorf("ComponentType of a selected workload = %q, want %q", got, "distributor")
+	}
+	if got := detector.ComponentType("write-path", map[string]string{"team": "observability"}); got != "" {
+		t.Errorf("ComponentType of an unselected workload = %q, want none", got)
+	}
+	if !detector.IsMimirWorkload(map[string]string{"app.kubernetes.io/name": "mimir"}) {
+		t.Errorf("workload labeled app.kubernetes.io/name=mimir is not detected outside the selector")
+	}
+}
+
+func TestNewComponentDetectorInvalidSelector(t *testing.T) {
+	cfg := config.GetDefaultConfig().MetricsDiscovery
+	cfg.ComponentLabelSelector = "team in (observability"
+	if _, err := NewComponentDetector(cfg); err == nil {
+		t.Fatalf("NewComponentDetector with an invalid selector succeeded")
+	}
+}
@@ -16,7 +16,7 @@ import (
 // ServiceDiscovery handles automatic discovery of Mimir services
 type ServiceDiscovery struct {
 	client kubernetes.Interface
-	config *config.Config
+	live   *config.Live
 	log    logr.Logger
 }
 
@@ -30,17 +30,22 @@ type DiscoveredEndpoint struct {
 }
 
 // NewServiceDiscovery creates a new ServiceDiscovery instance
-func NewServiceDiscovery(client kubernetes.Interface, cfg *config.Config, log logr.Logger) *ServiceDiscovery {
+func NewServiceDiscovery(client kubernetes.Interface, live *config.Live, log logr.Logger) *ServiceDiscovery {
 	return &ServiceDiscovery{
 		client: client,
-		config: cfg,
+		live:   live,
 		log:    log,
 	}
 }
 
+// config returns the configuration in effect
+func (d *ServiceDiscovery) config() *config.Config {
+	return d.live.Load()
+}
+
 // DiscoverMetricsEndpoints discovers all available Mimir metrics endpoints
 func (d *ServiceDiscovery) DiscoverMetricsEndpoints(ctx context.Context) ([]string, error) {
-	if !d.config.MetricsDiscovery.Enabled {
+	if !d.config().MetricsDiscovery.Enabled {
 		return nil, nil
 	}
 
@@ -48,7 +53,7 @@ func (d *ServiceDiscovery) DiscoverMetricsEndpoints(ctx context.Context) ([]stri
 	var err error
 
 	// Try label selector discovery first
-	if d.config.MetricsDiscovery.ServiceLabelSelector != "" {
+	if d.config().MetricsDiscovery.ServiceLabelSelector != "" {
 		labelEndpoints, err := d.discoverByLabels(ctx)
 		if err != nil {
 			d.log.Error(err, "failed to discover services by labels")
@@ -58,7 +63,7 @@ func (d *ServiceDiscovery) DiscoverMetricsEndpoints(ctx context.Context) ([]stri
 	}
 
 	// Try known service names discovery
-	if len(d.config.MetricsDiscovery.ServiceNames) > 0 {
+	if len(d.config().MetricsDiscovery.ServiceNames) > 0 {
 		nameEndpoints, err := d.discoverByNames(ctx)
 		if err != nil {
 			d.log.Error(err, "failed to discover services by names")
@@ -90,12 +95,12 @@ func (d *ServiceDiscovery) DiscoverMetricsEndpoints(ctx context.Context) ([]stri
 
 // discoverByLabels discovers services using label selectors
 func (d *ServiceDiscovery) discoverByLabels(ctx context.Context) ([]DiscoveredEndpoint, error) {
-	selector, err := labels.Parse(d.config.MetricsDiscovery.ServiceLabelSelector)
+	selector, err := labels.Parse(d.config().MetricsDiscovery.ServiceLabelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("invalid label selector: %w", err)
 	}
 
-	services, err := d.client.CoreV1().Services(d.config.MetricsDiscovery.Namespace).List(ctx, metav1.ListOptions{
+	services, err := d.client.CoreV1().Services(d.config().MetricsDiscovery.Namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: selector.String(),
 	})
 	if err != nil {
@@ -118,8 +123,8 @@ func (d *ServiceDiscovery) discoverByLabels(ctx context.Context) ([]DiscoveredEn
 func (d *ServiceDiscovery) discoverByNames(ctx context.Context) ([]DiscoveredEndpoint, error) {
 	var endpoints []DiscoveredEndpoint
 
-	for _, serviceName := range d.config.MetricsDiscovery.ServiceNames {
-		service, err := d.client.CoreV1().Services(d.config.MetricsDiscovery.Namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	for _, serviceName := range d.config().MetricsDiscovery.ServiceNames {
+		service, err := d.client.CoreV1().Services(d.config().MetricsDiscovery.Namespace).Get(ctx, serviceName, metav1.GetOptions{})
 		if err != nil {
 			d.log.Error(err, "failed to get service", "service", serviceName)
 			continue
@@ -139,9 +144,9 @@ func (d *ServiceDiscovery) serviceToEndpoint(service *corev1.Service) *Discovere
 	var port int32
 
 	// Find the metrics port
-	if d.config.MetricsDiscovery.PortName != "" {
+	if d.config().MetricsDiscovery.PortName != "" {
 		for _, p := range service.Spec.Ports {
-			if p.Name == d.config.MetricsDiscovery.PortName {
+			if p.Name == d.config().MetricsDiscovery.PortName {
 				port = p.Port
 				break
 			}
@@ -149,8 +154,8 @@ func (d *ServiceDiscovery) serviceToEndpoint(service *corev1.Service) *Discovere
 	}
 
 	// Fallback to configured port number
-	if port == 0 && d.config.MetricsDiscovery.Port > 0 {
-		port = int32(d.config.MetricsDiscovery.Port)
+	if port == 0 && d.config().MetricsDiscovery.Port > 0 {
+		port = int32(d.config().MetricsDiscovery.Port)
 	}
 
 	// Default fallback
@@ -185,7 +190,7 @@ func (d *ServiceDiscovery) serviceToEndpoint(service *corev1.Service) *Discovere
 
 // buildMetricsURL builds the full metrics URL for an endpoint
 func (d *ServiceDiscovery) buildMetricsURL(endpoint DiscoveredEndpoint) string {
-	metricsPath := d.config.MetricsDiscovery.MetricsPath
+	metricsPath := d.config().MetricsDiscovery.MetricsPath
 	if metricsPath == "" {
 		metricsPath = "/metrics"
 	}
@@ -199,7 +204,7 @@ func (d *ServiceDiscovery) buildMetricsURL(endpoint DiscoveredEndpoint) string {
 
 // DiscoverAllServices discovers all services in the configured namespace
 func (d *ServiceDiscovery) DiscoverAllServices(ctx context.Context) ([]corev1.Service, error) {
-	services, err := d.client.CoreV1().Services(d.config.MetricsDiscovery.Namespace).List(ctx, metav1.ListOptions{})
+	services, err := d.client.CoreV1().Services(d.config().MetricsDiscovery.Namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list all services: %w", err)
 	}
@@ -209,7 +214,7 @@ func (d *ServiceDiscovery) DiscoverAllServices(ctx context.Context) ([]corev1.Se
 
 // GetServiceEndpoints gets endpoints for a specific service
 func (d *ServiceDiscovery) GetServiceEndpoints(ctx context.Context, serviceName string) (*corev1.Endpoints, error) {
-	endpoints, err := d.client.CoreV1().Endpoints(d.config.MetricsDiscovery.Namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	endpoints, err := d.client.CoreV1().Endpoints(d.config().MetricsDiscovery.Namespace).Get(ctx, serviceName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get endpoints for service %s: %w", serviceName, err)
 	}
@@ -220,8 +225,8 @@ func (d *ServiceDiscovery) GetServiceEndpoints(ctx context.Context, serviceName
 // ValidateService checks if a service is suitable for metrics collection
 func (d *ServiceDiscovery) ValidateService(service *corev1.Service) bool {
 	// Check if service has required labels (if using label selector)
-	if d.config.MetricsDiscovery.ServiceLabelSelector != "" {
-		selector, err := labels.Parse(d.config.MetricsDiscovery.ServiceLabelSelector)
+	if d.config().MetricsDiscovery.ServiceLabelSelector != "" {
+		selector, err := labels.Parse(d.config().MetricsDiscovery.ServiceLabelSelector)
 		if err != nil {
 			d.log.Error(err, "invalid label selector")
 			return false
@@ -0,0 +1,151 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HealthCache is a watch-based cache of the workloads the health scanner reads in the
+// Mimir namespace. The API server only sees the initial list and the watches; scans read
+// Deployments, StatefulSets, DaemonSets, Pods, Services and PVCs from memory.
+//
+// It is a manager Runnable that runs on every replica, not only on the leader.
+type HealthCache struct {
+	cache     cache.Cache
+	namespace string
+	log       logr.Logger
+
+	// synced is closed once every informer has listed its objects
+	synced chan struct{}
+
+	mu       sync.Mutex
+	onChange []func()
+}
+
+// NewHealthCache creates the watch cache of the scanned workload types in a namespace
+func NewHealthCache(restConfig *rest.Config, scheme *runtime.Scheme, namespace string, log logr.Logger) (*HealthCache, error) {
+	informers, err := cache.New(restConfig, cache.Options{
+		Scheme:            scheme,
+		DefaultNamespaces: map[string]cache.Config{namespace: {}},
+		DefaultTransform:  stripManagedFields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create health cache: %w", err)
+	}
+
+	return &HealthCache{
+		cache:     informers,
+		namespace: namespace,
+		log:       log,
+		synced:    make(chan struct{}),
+	}, nil
+}
+
+// Start starts the informers of the cached types and blocks until ctx is done
+func (c *HealthCache) Start(ctx context.Context) error {
+	handler := toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { c.changed() },
+		UpdateFunc: func(interface{}, interface{}) { c.changed() },
+		DeleteFunc: func(interface{}) { c.changed() },
+	}
+	for _, obj := range []client.Object{
+		&appsv1.Deployment{}, &appsv1.StatefulSet{}, &appsv1.DaemonSet{},
+		&corev1.Pod{}, &corev1.Service{}, &corev1.PersistentVolumeClaim{},
+	} {
+		informer, err := c.cache.GetInformer(ctx, obj)
+		if err != nil {
+			return fmt.Errorf("failed to start health cache informer for %T: %w", obj, err)
+		}
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return fmt.Errorf("failed to watch %T for the health cache: %w", obj, err)
+		}
+	}
+
+	go func() {
+		if c.cache.WaitForCacheSync(ctx) {
+			c.log.Info("health cache synced", "namespace", c.namespace)
+			close(c.synced)
+		}
+	}()
+
+	c.log.Info("starting health cache", "namespace", c.namespace)
+	return c.cache.Start(ctx)
+}
+
+// NeedLeaderElection reports false, as every replica serves health scans
+func (c *HealthCache) NeedLeaderElection() bool {
+	return false
+}
+
+// OnChange registers fn to be called on every watch event once the cache has synced
+func (c *HealthCache) OnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = append(c.onChange, fn)
+}
+
+// WaitForSync blocks until the cache has synced, or fails when ctx is done first
+func (c *HealthCache) WaitForSync(ctx context.Context) error {
+	select {
+	case <-c.synced:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("health cache of namespace %s has not synced yet: %w", c.namespace, ctx.Err())
+	}
+}
+
+// servesNamespace reports whether scans of the namespace read from the cache; a nil
+// cache serves none
+func (c *HealthCache) servesNamespace(namespace string) bool {
+	return c != nil && c.namespace == namespace
+}
+
+// serves reports whether lists of the type in the namespace are read from the cache
+func (c *HealthCache) serves(namespace string, list client.ObjectList) bool {
+	if !c.servesNamespace(namespace) {
+		return false
+	}
+	switch list.(type) {
+	case *appsv1.DeploymentList, *appsv1.StatefulSetList, *appsv1.DaemonSetList,
+		*corev1.PodList, *corev1.ServiceList, *corev1.PersistentVolumeClaimList:
+		return true
+	default:
+		return false
+	}
+}
+
+// changed notifies the registered callbacks of a watch event; events of the initial
+// list are skipped
+func (c *HealthCache) changed() {
+	select {
+	case <-c.synced:
+	default:
+		return
+	}
+
+	c.mu.Lock()
+	callbacks := c.onChange
+	c.mu.Unlock()
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// stripManagedFields drops the managed fields of cached objects, which scans never read
+func stripManagedFields(in interface{}) (interface{}, error) {
+	if obj, err := meta.Accessor(in); err == nil && obj.GetManagedFields() != nil {
+		obj.SetManagedFields(nil)
+	}
+	return in, nil
+}
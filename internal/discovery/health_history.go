@@ -0,0 +1,101 @@
+package discovery
+
+import (
+	"sync"
+	"time"
+)
+
+// maxHealthHistorySamples bounds the samples kept per namespace however short the scan
+// interval is, so a long retention cannot grow the history without limit
+const maxHealthHistorySamples = 2000
+
+// HealthSample is the outcome of one infrastructure health scan
+type HealthSample struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OverallScore float64   `json:"overall_score"`
+	Healthy      int       `json:"healthy"`
+	Warning      int       `json:"warning"`
+	Critical     int       `json:"critical"`
+	Unknown      int       `json:"unknown"`
+}
+
+// HealthHistory keeps the health scan results of each namespace for the retention
+// period, so health can be charted over time
+type HealthHistory struct {
+	mu        sync.Mutex
+	retention time.Duration
+	samples   map[string][]HealthSample
+
+	// now is replaceable so retention can be exercised without waiting
+	now func() time.Time
+}
+
+// NewHealthHistory creates a history keeping samples for retention. A retention of
+// zero disables the history.
+func NewHealthHistory(retention time.Duration) *HealthHistory {
+	return &HealthHistory{
+		retention: retention,
+		samples:   make(map[string][]HealthSample),
+		now:       time.Now,
+	}
+}
+
+// Record adds the result of a health scan of namespace
+func (h *HealthHistory) Record(namespace string, health *MimirInfrastructureHealth) {
+	if h == nil || h.retention <= 0 || health == nil {
+		return
+	}
+
+	sample := HealthSample{
+		Timestamp:    h.now(),
+		OverallScore: health.OverallScore,
+		Healthy:      health.HealthSummary.Healthy,
+		Warning:      health.HealthSummary.Warning,
+		Critical:     health.HealthSummary.Critical,
+		Unknown:      health.HealthSummary.Unknown,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := append(h.prune(h.samples[namespace], sample.Timestamp), sample)
+	if len(samples) > maxHealthHistorySamples {
+		samples = samples[len(samples)-maxHealthHistorySamples:]
+	}
+	h.samples[namespace] = samples
+}
+
+// Samples returns the retained samples of namespace, oldest first
+func (h *HealthHistory) Samples(namespace string) []HealthSample {
+	if h == nil {
+		return []HealthSample{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := h.prune(h.samples[namespace], h.now())
+	if len(samples) == 0 {
+		delete(h.samples, namespace)
+		return []HealthSample{}
+	}
+	h.samples[namespace] = samples
+
+	result := make([]HealthSample, len(samples))
+	copy(result, samples)
+	return result
+}
+
+// prune drops the samples older than the retention period at now
+func (h *HealthHistory) prune(samples []HealthSample, now time.Time) []HealthSample {
+	cutoff := now.Add(-h.retention)
+	first := 0
+	for first < len(samples) && samples[first].Timestamp.Before(cutoff) {
+		first++
+	}
+	if first == 0 {
+		return samples
+	}
+	// Copy so the dropped samples are not kept alive by the backing array
+	return append([]HealthSample(nil), samples[first:]...)
+}
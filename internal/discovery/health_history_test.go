@@ -0,0 +1,139 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// fakeClock is a settable clock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestHealthHistory(retention time.Duration) (*HealthHistory, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)}
+	history := NewHealthHistory(retention)
+	history.now = clock.Now
+	return history, clock
+}
+
+func scanResult(score float64, healthy, warning, critical int) *MimirInfrastructureHealth {
+	return &MimirInfrastructureHealth{
+		OverallScore:  score,
+		HealthSummary: HealthSummary{Healthy: healthy, Warning: warning, Critical: critical},
+	}
+}
+
+func TestHealthHistoryAccumulatesScans(t *testing.T) {
+	history, clock := newTestHealthHistory(6 * time.Hour)
+	scores := []float64{95, 90, 80, 70}
+	for i, score := range scores {
+		history.Record("mimir", scanResult(score, 10-i, i, 0))
+		clock.Advance(time.Hour)
+	}
+
+	samples := history.Samples("mimir")
+	if len(samples) != len(scores) {
+		t.Fatalf("samples = %d, want %d", len(samples), len(scores))
+	}
+	start := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	for i, sample := range samples {
+		if sample.OverallScore != scores[i] || sample.Healthy != 10-i || sample.Warning != i {
+			t.Errorf("sample %d = %+v, want score %v, %d healthy, %d warning", i, sample, scores[i], 10-i, i)
+		}
+		if want := start.Add(time.Duration(i) * time.Hour); !sample.Timestamp.Equal(want) {
+			t.Errorf("sample %d timestamp = %v, want %v", i, sample.Timestamp, want)
+		}
+	}
+	if other := history.Samples("mimir-staging"); len(other) != 0 {
+		t.Errorf("samples of another namespace = %v, want none", other)
+	}
+}
+
+func TestHealthHistoryRetention(t *testing.T) {
+	history, clock := newTestHealthHistory(6 * time.Hour)
+	for i := 0; i < 10; i++ {
+		history.Record("mimir", scanResult(float64(i), 1, 0, 0))
+		clock.Advance(time.Hour)
+	}
+
+	// At 22:00 the samples of 16:00 through 21:00 are within the six hours
+	samples := history.Samples("mimir")
+	if len(samples) != 6 || samples[0].OverallScore != 4 || samples[5].OverallScore != 9 {
+		t.Fatalf("samples = %+v, want the scores 4 to 9 of the last six hours", samples)
+	}
+
+	clock.Advance(7 * time.Hour)
+	if samples := history.Samples("mimir"); len(samples) != 0 {
+		t.Errorf("samples after the retention passed = %d, want none", len(samples))
+	}
+}
+
+func TestHealthHistoryBoundsSamples(t *testing.T) {
+	history, clock := newTestHealthHistory(24 * time.Hour)
+	for i := 0; i < maxHealthHistorySamples+10; i++ {
+		history.Record("mimir", scanResult(float64(i), 1, 0, 0))
+		clock.Advance(time.Second)
+	}
+
+	samples := history.Samples("mimir")
+	if len(samples) != maxHealthHistorySamples || samples[0].OverallScore != 10 {
+		t.Errorf("samples = %d starting at score %v, want the latest %d", len(samples), samples[0].OverallScore, maxHealthHistorySamples)
+	}
+}
+
+func TestHealthHistoryDisabled(t *testing.T) {
+	history, _ := newTestHealthHistory(0)
+	history.Record("mimir", scanResult(90, 1, 0, 0))
+	if samples := history.Samples("mimir"); len(samples) != 0 {
+		t.Errorf("samples with zero retention = %d, want none", len(samples))
+	}
+
+	var nilHistory *HealthHistory
+	nilHistory.Record("mimir", scanResult(90, 1, 0, 0))
+	if samples := nilHistory.Samples("mimir"); samples == nil || len(samples) != 0 {
+		t.Errorf("samples of a nil history = %v, want an empty list", samples)
+	}
+}
+
+func TestCachedScannerRecordsEveryScan(t *testing.T) {
+	history, clock := newTestHealthHistory(6 * time.Hour)
+	h := newTestHealthScanner(testDeployment("querier", 1, map[string]string{"app": "querier"}))
+	h.WithMetricsClient(&fakePodMetrics{})
+	scanner := NewCachedScanner(h, nil, 0, history, logr.Discard())
+
+	for i := 0; i < 3; i++ {
+		if _, err := scanner.ScanHealth(context.Background(), "mimir"); err != nil {
+			t.Fatalf("ScanHealth: %v", err)
+		}
+		clock.Advance(10 * time.Minute)
+	}
+
+	samples := scanner.HealthHistory("mimir")
+	if len(samples) != 3 {
+		t.Fatalf("history = %d samples, want one per scan", len(samples))
+	}
+	if samples[0].Healthy+samples[0].Warning+samples[0].Critical+samples[0].Unknown == 0 {
+		t.Errorf("sample %+v counts no resources", samples[0])
+	}
+	if got := samples[2].Timestamp.Sub(samples[0].Timestamp); got != 20*time.Minute {
+		t.Errorf("history spans %v, want 20m", got)
+	}
+}
@@ -2,24 +2,65 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/sync/errgroup"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
 )
 
 // HealthScanner provides comprehensive health monitoring for Mimir infrastructure
 type HealthScanner struct {
-	client client.Client
-	config *config.Config
-	log    logr.Logger
+	client        client.Client
+	live          *config.Live
+	log           logr.Logger
+	metricsClient PodMetricsClient
+
+	// reader lists the scanned resources, pageSize objects at a time when it reads from
+	// the API server; the cached client is never paged
+	reader   client.Reader
+	pageSize int64
+	// watchCache serves the workload types of its namespace from watches instead of
+	// reader, when set
+	watchCache *HealthCache
+
+	// MaxConcurrentScans caps the resource types listed in parallel by a scan
+	MaxConcurrentScans int
+
+	// namespace is the namespace scanned, defaulting to the configured Mimir namespace
+	namespace string
+
+	// StalenessThreshold is the age of a scan past which its score decays
+	StalenessThreshold time.Duration
+	// StalenessPenalty is the score points deducted per second past the threshold
+	StalenessPenalty float64
+	// now is the clock the age of scans is measured by
+	now func() time.Time
+
+	// podUsage is the live pod usage collected during the current scan; it may only
+	// be read once podUsageReady is closed
+	podUsage      *podUsageIndex
+	podUsageReady chan struct{}
 }
 
+// staleHealthScore is the decayed score below which a scan's overall health is "Stale"
+const staleHealthScore = 50.0
+
+// maxConcurrentResourceScans caps the resource types listed in parallel by a scan when
+// healthScanner.maxConcurrentScans is unset
+const maxConcurrentResourceScans = 8
+
 // ResourceHealth represents the health status of a Kubernetes resource
 type ResourceHealth struct {
 	Name          string              `json:"name"`
@@ -35,6 +76,9 @@ type ResourceHealth struct {
 	Metrics       map[string]float64  `json:"metrics"`
 	Labels        map[string]string   `json:"labels"`
 	Age           time.Duration       `json:"age"`
+	// Events are the recent Kubernetes Events of the resource, only read for the
+	// resource detail and never by a bulk scan
+	Events []ResourceEvent `json:"events,omitempty"`
 }
 
 // ResourceReplicas contains replica information for workloads
@@ -56,11 +100,13 @@ type ResourceCondition struct {
 
 // ResourceUsage contains resource utilization information
 type ResourceUsage struct {
-	CPUUsage     float64 `json:"cpu_usage"`
-	MemoryUsage  float64 `json:"memory_usage"`
+	CPUUsage     float64 `json:"cpu_usage"`    // cores, summed across the workload's pods
+	MemoryUsage  float64 `json:"memory_usage"` // bytes, summed across the workload's pods
 	CPULimit     string  `json:"cpu_limit,omitempty"`
 	MemoryLimit  string  `json:"memory_limit,omitempty"`
 	StorageUsage float64 `json:"storage_usage,omitempty"`
+	// MetricsUnavailable is set when live usage could not be read from metrics-server
+	MetricsUnavailable bool `json:"metrics_unavailable,omitempty"`
 }
 
 // HealthIssue represents a health issue detected
@@ -83,6 +129,18 @@ type MimirInfrastructureHealth struct {
 	ScanDuration    time.Duration         `json:"scan_duration"`
 	Alerts          []InfrastructureAlert `json:"alerts"`
 	Recommendations []AIRecommendation    `json:"recommendations"`
+	// DataFreshnessSeconds is the age of the scan when it was served
+	DataFreshnessSeconds float64 `json:"data_freshness_seconds"`
+	// ResourceScans describes the scan of each resource type, failed ones included
+	ResourceScans []ResourceScan `json:"resource_scans"`
+}
+
+// ResourceScan describes how the scan of one resource type went
+type ResourceScan struct {
+	Kind            string  `json:"kind"`
+	Count           int     `json:"count"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	Error           string  `json:"error,omitempty"`
 }
 
 // ResourceTypeCount contains counts by resource type
@@ -128,18 +186,92 @@ type AIRecommendation struct {
 }
 
 // NewHealthScanner creates a new HealthScanner instance
-func NewHealthScanner(client client.Client, cfg *config.Config, log logr.Logger) *HealthScanner {
+func NewHealthScanner(client client.Client, live *config.Live, log logr.Logger) *HealthScanner {
+	cfg := live.Load()
+	stalenessThreshold := cfg.HealthScanner.StalenessThreshold
+	if stalenessThreshold <= 0 {
+		stalenessThreshold = 2 * cfg.HealthScanner.CheckInterval
+	}
+	maxConcurrentScans := cfg.HealthScanner.MaxConcurrentScans
+	if maxConcurrentScans <= 0 {
+		maxConcurrentScans = maxConcurrentResourceScans
+	}
 	return &HealthScanner{
-		client: client,
-		config: cfg,
-		log:    log.WithName("health-scanner"),
+		client:             client,
+		live:               live,
+		log:                log.WithName("health-scanner"),
+		metricsClient:      NewMetricsAPIClient(client),
+		reader:             client,
+		MaxConcurrentScans: maxConcurrentScans,
+		namespace:          cfg.Mimir.Namespace,
+		StalenessThreshold: stalenessThreshold,
+		StalenessPenalty:   cfg.HealthScanner.StalenessPenalty,
+		now:                time.Now,
+	}
+}
+
+// config returns the configuration in effect
+func (h *HealthScanner) config() *config.Config {
+	return h.live.Load()
+}
+
+// WithMetricsClient replaces the client used to read live pod usage
+func (h *HealthScanner) WithMetricsClient(metricsClient PodMetricsClient) *HealthScanner {
+	h.metricsClient = metricsClient
+	return h
+}
+
+// WithAPIReader lists the scanned resources directly from the API server, by pages of
+// healthScanner.listPageSize objects, instead of through the client's cache
+func (h *HealthScanner) WithAPIReader(reader client.Reader) *HealthScanner {
+	h.reader = reader
+	h.pageSize = h.config().HealthScanner.ListPageSize
+	return h
+}
+
+// WithWatchCache reads the types the watch cache holds from it when scanning its
+// namespace; other types and namespaces are still listed through the reader
+func (h *HealthScanner) WithWatchCache(watchCache *HealthCache) *HealthScanner {
+	h.watchCache = watchCache
+	return h
+}
+
+// ScanMimirInfrastructureInNamespace scans a Mimir installation in a namespace other
+// than the configured one
+func (h *HealthScanner) ScanMimirInfrastructureInNamespace(ctx context.Context, namespace string) (*MimirInfrastructureHealth, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
 	}
+
+	scoped := *h
+	scoped.namespace = namespace
+	return scoped.ScanMimirInfrastructure(ctx)
+}
+
+// ScanMimirInfrastructureStream scans a Mimir installation like
+// ScanMimirInfrastructureInNamespace, handing the resources of each type to onScanned as
+// soon as that type is scanned, so slow types do not hold back the others. Calls of
+// onScanned are serialized; a failed type is passed with its error and no resources.
+func (h *HealthScanner) ScanMimirInfrastructureStream(ctx context.Context, namespace string, onScanned func(ResourceScan, []ResourceHealth)) (*MimirInfrastructureHealth, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	scoped := *h
+	scoped.namespace = namespace
+	return scoped.scan(ctx, onScanned)
 }
 
 // ScanMimirInfrastructure performs a comprehensive scan of Mimir infrastructure
 func (h *HealthScanner) ScanMimirInfrastructure(ctx context.Context) (*MimirInfrastructureHealth, error) {
+	return h.scan(ctx, nil)
+}
+
+// scan lists and analyzes every resource type of the scanned namespace, at most
+// MaxConcurrentScans types at a time, reporting each finished type to onScanned if set
+func (h *HealthScanner) scan(ctx context.Context, onScanned func(ResourceScan, []ResourceHealth)) (*MimirInfrastructureHealth, error) {
 	startTime := time.Now()
-	h.log.Info("starting Mimir infrastructure health scan", "namespace", h.config.Mimir.Namespace)
+	h.log.Info("starting Mimir infrastructure health scan", "namespace", h.namespace)
 
 	var allResources []ResourceHealth
 	var componentCount ResourceTypeCount
@@ -148,76 +280,106 @@ func (h *HealthScanner) ScanMimirInfrastructure(ctx context.Context) (*MimirInfr
 	scanCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
-	// Scan Deployments with error resilience
-	deployments, err := h.scanDeployments(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan deployments, continuing with other resources")
-	} else {
-		allResources = append(allResources, deployments...)
-		componentCount.Deployments = len(deployments)
-	}
-
-	// Scan StatefulSets with error resilience
-	statefulSets, err := h.scanStatefulSets(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan statefulsets, continuing with other resources")
-	} else {
-		allResources = append(allResources, statefulSets...)
-		componentCount.StatefulSets = len(statefulSets)
-	}
-
-	// Scan DaemonSets with error resilience
-	daemonSets, err := h.scanDaemonSets(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan daemonsets, continuing with other resources")
-	} else {
-		allResources = append(allResources, daemonSets...)
-		componentCount.DaemonSets = len(daemonSets)
-	}
-
-	// Scan Services with error resilience
-	services, err := h.scanServices(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan services, continuing with other resources")
-	} else {
-		allResources = append(allResources, services...)
-		componentCount.Services = len(services)
-	}
-
-	// Scan ConfigMaps with error resilience
-	configMaps, err := h.scanConfigMaps(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan configmaps, continuing with other resources")
-	} else {
-		allResources = append(allResources, configMaps...)
-		componentCount.ConfigMaps = len(configMaps)
+	// Until the watch cache has synced it would report an empty namespace
+	if h.watchCache.servesNamespace(h.namespace) {
+		if err := h.watchCache.WaitForSync(scanCtx); err != nil {
+			return nil, err
+		}
 	}
 
-	// Scan Secrets with error resilience (already updated)
-	secrets, err := h.scanSecrets(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan secrets, continuing with other resources")
-	} else {
-		allResources = append(allResources, secrets...)
-		componentCount.Secrets = len(secrets)
+	// Collect live pod usage for all workloads in the scan while the resources are
+	// listed; workload scans wait for it before computing usage
+	h.podUsageReady = make(chan struct{})
+	go func() {
+		defer close(h.podUsageReady)
+		h.podUsage = h.collectPodUsage(scanCtx)
+	}()
+
+	// Secrets are often not readable by the optimizer; their scan reports an empty
+	// list instead of failing, so it cannot tell whether the scan as a whole worked
+	scans := []struct {
+		kind     string
+		scan     func(context.Context) ([]ResourceHealth, error)
+		count    *int
+		optional bool
+	}{
+		{"deployments", h.scanDeployments, &componentCount.Deployments, false},
+		{"statefulsets", h.scanStatefulSets, &componentCount.StatefulSets, false},
+		{"daemonsets", h.scanDaemonSets, &componentCount.DaemonSets, false},
+		{"services", h.scanServices, &componentCount.Services, false},
+		{"configmaps", h.scanConfigMaps, &componentCount.ConfigMaps, false},
+		{"secrets", h.scanSecrets, &componentCount.Secrets, true},
+		{"pods", h.scanPods, &componentCount.Pods, false},
+		{"pvcs", h.scanPVCs, &componentCount.PVCs, false},
+	}
+
+	// Resource types are listed in parallel; a failing type is logged and the others
+	// are still reported
+	results := make([][]ResourceHealth, len(scans))
+	scanErrs := make([]error, len(scans))
+	resourceScans := make([]ResourceScan, len(scans))
+	var streamMu sync.Mutex
+
+	var group errgroup.Group
+	group.SetLimit(h.MaxConcurrentScans)
+	for i, scan := range scans {
+		i, scan := i, scan
+		group.Go(func() error {
+			typeStart := time.Now()
+			func() {
+				// A panic outside the request goroutine would crash the process
+				defer func() {
+					if r := recover(); r != nil {
+						results[i] = nil
+						scanErrs[i] = fmt.Errorf("panic while scanning %s: %v", scan.kind, r)
+					}
+				}()
+				results[i], scanErrs[i] = scan.scan(scanCtx)
+			}()
+
+			resourceScans[i] = ResourceScan{
+				Kind:            scan.kind,
+				Count:           len(results[i]),
+				DurationSeconds: time.Since(typeStart).Seconds(),
+			}
+			result := "success"
+			if scanErrs[i] != nil {
+				resourceScans[i].Count = 0
+				resourceScans[i].Error = scanErrs[i].Error()
+				result = "error"
+			}
+			metrics.HealthMetricsInstance.ObserveResourceScanDuration(scan.kind, result, resourceScans[i].DurationSeconds)
+
+			if onScanned != nil {
+				streamMu.Lock()
+				defer streamMu.Unlock()
+				if scanErrs[i] != nil {
+					onScanned(resourceScans[i], nil)
+				} else {
+					onScanned(resourceScans[i], results[i])
+				}
+			}
+			return nil
+		})
 	}
+	_ = group.Wait()
+	<-h.podUsageReady
 
-	// Scan Pods with error resilience
-	pods, err := h.scanPods(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan pods, continuing with other resources")
-	} else {
-		allResources = append(allResources, pods...)
-		componentCount.Pods = len(pods)
+	var failed []error
+	succeeded := false
+	for i, scan := range scans {
+		if scanErrs[i] != nil {
+			h.log.Error(scanErrs[i], fmt.Sprintf("failed to scan %s, continuing with other resources", scan.kind))
+			failed = append(failed, scanErrs[i])
+			continue
+		}
+		allResources = append(allResources, results[i]...)
+		*scan.count = len(results[i])
+		succeeded = succeeded || !scan.optional
 	}
 
-	// Scan PVCs with error resilience
-	pvcs, err := h.scanPVCs(scanCtx)
-	if err != nil {
-		h.log.Error(err, "failed to scan pvcs, continuing with other resources")
-	} else {
-		allResources = append(allResources, pvcs...)
-		componentCount.PVCs = len(pvcs)
+	if !succeeded {
+		return nil, fmt.Errorf("failed to scan any Mimir resources in namespace %s: %w", h.namespace, errors.Join(failed...))
 	}
 
 	// Calculate overall health
@@ -240,6 +402,7 @@ func (h *HealthScanner) ScanMimirInfrastructure(ctx context.Context) (*MimirInfr
 		ScanDuration:    scanDuration,
 		Alerts:          alerts,
 		Recommendations: recommendations,
+		ResourceScans:   resourceScans,
 	}
 
 	h.log.Info("completed Mimir infrastructure health scan",
@@ -261,29 +424,59 @@ func (h *HealthScanner) ScanMimirInfrastructure(ctx context.Context) (*MimirInfr
 	return result, nil
 }
 
+// listPages lists the objects of a type in the scanned namespace and hands each page to
+// visit before requesting the next, so a large namespace is never held in memory at
+// once. Only reads from the API server are paged, as the caches hold every object anyway
+// and cannot continue a list.
+func listPages[T any, L interface {
+	*T
+	client.ObjectList
+}](ctx context.Context, h *HealthScanner, visit func(L)) error {
+	reader, pageSize := h.reader, h.pageSize
+	if h.watchCache.serves(h.namespace, L(new(T))) {
+		reader, pageSize = h.watchCache.cache, 0
+	}
+
+	continueToken := ""
+	for {
+		list := L(new(T))
+		opts := []client.ListOption{client.InNamespace(h.namespace)}
+		if pageSize > 0 {
+			opts = append(opts, client.Limit(pageSize), client.Continue(continueToken))
+		}
+		if err := reader.List(ctx, list, opts...); err != nil {
+			return err
+		}
+		visit(list)
+
+		continueToken = list.GetContinue()
+		if pageSize <= 0 || continueToken == "" {
+			return nil
+		}
+	}
+}
+
 // scanDeployments scans all deployments in the Mimir namespace
 func (h *HealthScanner) scanDeployments(ctx context.Context) ([]ResourceHealth, error) {
 	// Add timeout for deployments scanning
 	timeoutCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	deploymentList := &appsv1.DeploymentList{}
-	err := h.client.List(timeoutCtx, deploymentList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(timeoutCtx, h, func(deploymentList *appsv1.DeploymentList) {
+		for i := range deploymentList.Items {
+			resources = append(resources, h.analyzeDeploymentHealth(&deploymentList.Items[i]))
+		}
+	})
 	if err != nil {
 		h.log.Error(err, "failed to list deployments",
-			"namespace", h.config.Mimir.Namespace,
+			"namespace", h.namespace,
 			"timeout", "15s")
 		return nil, fmt.Errorf("failed to list deployments: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, dep := range deploymentList.Items {
-		resource := h.analyzeDeploymentHealth(&dep)
-		resources = append(resources, resource)
-	}
-
 	h.log.V(1).Info("successfully scanned deployments",
-		"namespace", h.config.Mimir.Namespace,
+		"namespace", h.namespace,
 		"count", len(resources))
 
 	return resources, nil
@@ -323,7 +516,7 @@ func (h *HealthScanner) analyzeDeploymentHealth(dep *appsv1.Deployment) Resource
 		HealthScore:   healthScore,
 		Replicas:      replicas,
 		Conditions:    conditions,
-		ResourceUsage: h.getResourceUsage(dep.Spec.Template.Spec.Containers),
+		ResourceUsage: h.getResourceUsage(h.workloadPods(dep.Spec.Selector), dep.Spec.Template.Spec.Containers),
 		LastUpdated:   time.Now(),
 		Issues:        issues,
 		Metrics:       h.getDeploymentMetrics(dep),
@@ -416,13 +609,90 @@ func (h *HealthScanner) detectDeploymentIssues(dep *appsv1.Deployment) []HealthI
 	return issues
 }
 
-// getResourceUsage extracts resource usage information from containers
-func (h *HealthScanner) getResourceUsage(containers []corev1.Container) ResourceUsage {
-	// This is a placeholder - in production, you'd collect actual metrics
-	return ResourceUsage{
-		CPUUsage:    0.0, // Would be populated from metrics
-		MemoryUsage: 0.0, // Would be populated from metrics
+// collectPodUsage reads live pod usage for the Mimir namespace. It returns nil when
+// metrics-server is not installed or not reachable.
+func (h *HealthScanner) collectPodUsage(ctx context.Context) *podUsageIndex {
+	if h.metricsClient == nil {
+		return nil
 	}
+
+	pods, err := h.metricsClient.ListPodMetrics(ctx, h.namespace)
+	if err != nil {
+		if errors.Is(err, ErrMetricsUnavailable) {
+			h.log.V(1).Info("metrics API unavailable, resource usage will be reported as zero", "error", err.Error())
+		} else {
+			h.log.Error(err, "failed to read pod metrics, resource usage will be reported as zero")
+		}
+		return nil
+	}
+
+	return newPodUsageIndex(pods)
+}
+
+// usage returns the pod usage of the current scan, waiting until it is collected
+func (h *HealthScanner) usage() *podUsageIndex {
+	if h.podUsageReady != nil {
+		<-h.podUsageReady
+	}
+	return h.podUsage
+}
+
+// workloadPods returns the usage of the pods matched by a workload selector
+func (h *HealthScanner) workloadPods(selector *metav1.LabelSelector) []PodMetrics {
+	podUsage := h.usage()
+	if podUsage == nil || selector == nil {
+		return nil
+	}
+
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil || labelSelector.Empty() {
+		return nil
+	}
+	return podUsage.matching(labelSelector)
+}
+
+// namedPod returns the usage of a single pod
+func (h *HealthScanner) namedPod(name string) []PodMetrics {
+	podUsage := h.usage()
+	if podUsage == nil {
+		return nil
+	}
+	if pod, exists := podUsage.byName[name]; exists {
+		return []PodMetrics{pod}
+	}
+	return nil
+}
+
+// getResourceUsage sums the live usage of a workload's pods and reports the
+// per-pod limits declared by its containers
+func (h *HealthScanner) getResourceUsage(pods []PodMetrics, containers []corev1.Container) ResourceUsage {
+	usage := ResourceUsage{
+		MetricsUnavailable: h.usage() == nil,
+	}
+
+	for _, pod := range pods {
+		usage.CPUUsage += pod.CPU
+		usage.MemoryUsage += pod.Memory
+	}
+
+	cpuLimit := resource.Quantity{}
+	memoryLimit := resource.Quantity{}
+	for _, container := range containers {
+		if limit, exists := container.Resources.Limits[corev1.ResourceCPU]; exists {
+			cpuLimit.Add(limit)
+		}
+		if limit, exists := container.Resources.Limits[corev1.ResourceMemory]; exists {
+			memoryLimit.Add(limit)
+		}
+	}
+	if !cpuLimit.IsZero() {
+		usage.CPULimit = cpuLimit.String()
+	}
+	if !memoryLimit.IsZero() {
+		usage.MemoryLimit = memoryLimit.String()
+	}
+
+	return usage
 }
 
 // getDeploymentMetrics gets deployment-specific metrics
@@ -443,18 +713,16 @@ func (h *HealthScanner) getDeploymentMetrics(dep *appsv1.Deployment) map[string]
 
 // scanStatefulSets scans all statefulsets in the Mimir namespace
 func (h *HealthScanner) scanStatefulSets(ctx context.Context) ([]ResourceHealth, error) {
-	statefulSetList := &appsv1.StatefulSetList{}
-	err := h.client.List(ctx, statefulSetList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(ctx, h, func(statefulSetList *appsv1.StatefulSetList) {
+		for i := range statefulSetList.Items {
+			resources = append(resources, h.analyzeStatefulSetHealth(&statefulSetList.Items[i]))
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, sts := range statefulSetList.Items {
-		resource := h.analyzeStatefulSetHealth(&sts)
-		resources = append(resources, resource)
-	}
-
 	return resources, nil
 }
 
@@ -488,7 +756,7 @@ func (h *HealthScanner) analyzeStatefulSetHealth(sts *appsv1.StatefulSet) Resour
 		HealthScore:   healthScore,
 		Replicas:      replicas,
 		Conditions:    conditions,
-		ResourceUsage: h.getResourceUsage(sts.Spec.Template.Spec.Containers),
+		ResourceUsage: h.getResourceUsage(h.workloadPods(sts.Spec.Selector), sts.Spec.Template.Spec.Containers),
 		LastUpdated:   time.Now(),
 		Issues:        issues,
 		Metrics:       h.getStatefulSetMetrics(sts),
@@ -554,18 +822,16 @@ func (h *HealthScanner) getStatefulSetMetrics(sts *appsv1.StatefulSet) map[strin
 
 // scanDaemonSets scans all daemonsets in the Mimir namespace
 func (h *HealthScanner) scanDaemonSets(ctx context.Context) ([]ResourceHealth, error) {
-	daemonSetList := &appsv1.DaemonSetList{}
-	err := h.client.List(ctx, daemonSetList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(ctx, h, func(daemonSetList *appsv1.DaemonSetList) {
+		for i := range daemonSetList.Items {
+			resources = append(resources, h.analyzeDaemonSetHealth(&daemonSetList.Items[i]))
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, ds := range daemonSetList.Items {
-		resource := h.analyzeDaemonSetHealth(&ds)
-		resources = append(resources, resource)
-	}
-
 	return resources, nil
 }
 
@@ -600,7 +866,7 @@ func (h *HealthScanner) analyzeDaemonSetHealth(ds *appsv1.DaemonSet) ResourceHea
 		HealthScore:   healthScore,
 		Replicas:      replicas,
 		Conditions:    conditions,
-		ResourceUsage: h.getResourceUsage(ds.Spec.Template.Spec.Containers),
+		ResourceUsage: h.getResourceUsage(h.workloadPods(ds.Spec.Selector), ds.Spec.Template.Spec.Containers),
 		LastUpdated:   time.Now(),
 		Issues:        issues,
 		Metrics:       h.getDaemonSetMetrics(ds),
@@ -661,18 +927,16 @@ func (h *HealthScanner) getDaemonSetMetrics(ds *appsv1.DaemonSet) map[string]flo
 
 // scanServices scans all services in the Mimir namespace
 func (h *HealthScanner) scanServices(ctx context.Context) ([]ResourceHealth, error) {
-	serviceList := &corev1.ServiceList{}
-	err := h.client.List(ctx, serviceList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(ctx, h, func(serviceList *corev1.ServiceList) {
+		for i := range serviceList.Items {
+			resources = append(resources, h.analyzeServiceHealth(&serviceList.Items[i]))
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, svc := range serviceList.Items {
-		resource := h.analyzeServiceHealth(&svc)
-		resources = append(resources, resource)
-	}
-
 	return resources, nil
 }
 
@@ -735,18 +999,16 @@ func (h *HealthScanner) getServiceMetrics(svc *corev1.Service) map[string]float6
 
 // scanConfigMaps scans all configmaps in the Mimir namespace
 func (h *HealthScanner) scanConfigMaps(ctx context.Context) ([]ResourceHealth, error) {
-	configMapList := &corev1.ConfigMapList{}
-	err := h.client.List(ctx, configMapList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(ctx, h, func(configMapList *corev1.ConfigMapList) {
+		for i := range configMapList.Items {
+			resources = append(resources, h.analyzeConfigMapHealth(&configMapList.Items[i]))
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list configmaps: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, cm := range configMapList.Items {
-		resource := h.analyzeConfigMapHealth(&cm)
-		resources = append(resources, resource)
-	}
-
 	return resources, nil
 }
 
@@ -811,24 +1073,22 @@ func (h *HealthScanner) scanSecrets(ctx context.Context) ([]ResourceHealth, erro
 	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	secretList := &corev1.SecretList{}
-	err := h.client.List(timeoutCtx, secretList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(timeoutCtx, h, func(secretList *corev1.SecretList) {
+		for i := range secretList.Items {
+			resources = append(resources, h.analyzeSecretHealth(&secretList.Items[i]))
+		}
+	})
 	if err != nil {
 		// Log the error but don't fail completely - return empty list for resilience
 		h.log.Error(err, "failed to list secrets, skipping secrets scan",
-			"namespace", h.config.Mimir.Namespace,
+			"namespace", h.namespace,
 			"timeout", "10s")
 		return []ResourceHealth{}, nil // Return empty list instead of failing
 	}
 
-	var resources []ResourceHealth
-	for _, secret := range secretList.Items {
-		resource := h.analyzeSecretHealth(&secret)
-		resources = append(resources, resource)
-	}
-
 	h.log.V(1).Info("successfully scanned secrets",
-		"namespace", h.config.Mimir.Namespace,
+		"namespace", h.namespace,
 		"count", len(resources))
 
 	return resources, nil
@@ -894,23 +1154,21 @@ func (h *HealthScanner) scanPods(ctx context.Context) ([]ResourceHealth, error)
 	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
-	podList := &corev1.PodList{}
-	err := h.client.List(timeoutCtx, podList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(timeoutCtx, h, func(podList *corev1.PodList) {
+		for i := range podList.Items {
+			resources = append(resources, h.analyzePodHealth(&podList.Items[i]))
+		}
+	})
 	if err != nil {
 		h.log.Error(err, "failed to list pods",
-			"namespace", h.config.Mimir.Namespace,
+			"namespace", h.namespace,
 			"timeout", "20s")
 		return nil, fmt.Errorf("failed to list pods: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, pod := range podList.Items {
-		resource := h.analyzePodHealth(&pod)
-		resources = append(resources, resource)
-	}
-
 	h.log.V(1).Info("successfully scanned pods",
-		"namespace", h.config.Mimir.Namespace,
+		"namespace", h.namespace,
 		"count", len(resources))
 
 	return resources, nil
@@ -939,7 +1197,7 @@ func (h *HealthScanner) analyzePodHealth(pod *corev1.Pod) ResourceHealth {
 		Status:        status,
 		HealthScore:   healthScore,
 		Conditions:    conditions,
-		ResourceUsage: h.getResourceUsage(pod.Spec.Containers),
+		ResourceUsage: h.getResourceUsage(h.namedPod(pod.Name), pod.Spec.Containers),
 		LastUpdated:   time.Now(),
 		Issues:        issues,
 		Metrics:       h.getPodMetrics(pod),
@@ -1021,18 +1279,16 @@ func (h *HealthScanner) getPodMetrics(pod *corev1.Pod) map[string]float64 {
 
 // scanPVCs scans all persistent volume claims in the Mimir namespace
 func (h *HealthScanner) scanPVCs(ctx context.Context) ([]ResourceHealth, error) {
-	pvcList := &corev1.PersistentVolumeClaimList{}
-	err := h.client.List(ctx, pvcList, client.InNamespace(h.config.Mimir.Namespace))
+	var resources []ResourceHealth
+	err := listPages(ctx, h, func(pvcList *corev1.PersistentVolumeClaimList) {
+		for i := range pvcList.Items {
+			resources = append(resources, h.analyzePVCHealth(&pvcList.Items[i]))
+		}
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pvcs: %w", err)
 	}
 
-	var resources []ResourceHealth
-	for _, pvc := range pvcList.Items {
-		resource := h.analyzePVCHealth(&pvc)
-		resources = append(resources, resource)
-	}
-
 	return resources, nil
 }
 
@@ -1165,6 +1421,31 @@ func (h *HealthScanner) calculateOverallHealth(resources []ResourceHealth) (stri
 	}
 }
 
+// DecayStaleHealth returns a copy of a scan with its age as DataFreshnessSeconds. Once
+// the scan is older than StalenessThreshold, StalenessPenalty points are deducted from
+// its score per second past the threshold, and a score below 50 turns its overall
+// health "Stale", so a scan that is no longer refreshed stops reporting "Healthy".
+// Scans are shared between callers and are not modified.
+func (h *HealthScanner) DecayStaleHealth(health *MimirInfrastructureHealth) *MimirInfrastructureHealth {
+	decayed := *health
+	age := h.now().Sub(health.LastScanTime)
+	if age < 0 {
+		age = 0
+	}
+	decayed.DataFreshnessSeconds = age.Seconds()
+
+	if h.StalenessThreshold <= 0 || age <= h.StalenessThreshold {
+		return &decayed
+	}
+
+	excess := (age - h.StalenessThreshold).Seconds()
+	decayed.OverallScore = math.Max(0, health.OverallScore-h.StalenessPenalty*excess)
+	if decayed.OverallScore < staleHealthScore {
+		decayed.OverallHealth = "Stale"
+	}
+	return &decayed
+}
+
 // generateInfrastructureAlerts generates infrastructure-level alerts
 func (h *HealthScanner) generateInfrastructureAlerts(resources []ResourceHealth) []InfrastructureAlert {
 	var alerts []InfrastructureAlert
@@ -0,0 +1,400 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// fakePodMetrics serves a fixed pod usage, or err
+type fakePodMetrics struct {
+	pods []PodMetrics
+	err  error
+}
+
+func (f *fakePodMetrics) ListPodMetrics(ctx context.Context, namespace string) ([]PodMetrics, error) {
+	return f.pods, f.err
+}
+
+func newTestHealthScanner(objs ...client.Object) *HealthScanner {
+	return newInterceptedHealthScanner(interceptor.Funcs{}, objs...)
+}
+
+// newInterceptedHealthScanner creates a health scanner of the mimir namespace whose
+// fake client calls funcs
+func newInterceptedHealthScanner(funcs interceptor.Funcs, objs ...client.Object) *HealthScanner {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	c := fake.NewClientBuilder().WithObjects(objs...).WithInterceptorFuncs(funcs).Build()
+	return NewHealthScanner(c, config.NewLive(cfg), logr.Discard())
+}
+
+func testDeployment(name string, replicas int32, podLabels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "mimir"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: name,
+					Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("2"),
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					}},
+				}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: replicas, AvailableReplicas: replicas},
+	}
+}
+
+// scannedResource returns the resource of kind and name in a scan
+func scannedResource(t *testing.T, health *MimirInfrastructureHealth, kind, name string) ResourceHealth {
+	t.Helper()
+	for _, scanned := range health.Resources {
+		if scanned.Kind == kind && scanned.Name == name {
+			return scanned
+		}
+	}
+	t.Fatalf("scan did not report %s %s", kind, name)
+	return ResourceHealth{}
+}
+
+func TestScanReportsLivePodUsage(t *testing.T) {
+	querier := map[string]string{"app.kubernetes.io/component": "querier"}
+	h := newTestHealthScanner(testDeployment("querier", 2, querier))
+	h.WithMetricsClient(&fakePodMetrics{pods: []PodMetrics{
+		{Name: "querier-1", Namespace: "mimir", Labels: querier, CPU: 0.25, Memory: 512 << 20},
+		{Name: "querier-2", Namespace: "mimir", Labels: querier, CPU: 0.5, Memory: 256 << 20},
+		{Name: "distributor-1", Namespace: "mimir", Labels: map[string]string{"app.kubernetes.io/component": "distributor"}, CPU: 1},
+	}})
+
+	health, err := h.ScanMimirInfrastructure(context.Background())
+	if err != nil {
+		t.Fatalf("ScanMimirInfrastructure: %v", err)
+	}
+
+	usage := scannedResource(t, health, "Deployment", "querier").ResourceUsage
+	if usage.CPUUsage != 0.75 || usage.MemoryUsage != 768<<20 {
+		t.Errorf("usage = %v cores, %v bytes; want the querier pods summed", usage.CPUUsage, usage.MemoryUsage)
+	}
+	if usage.MetricsUnavailable {
+		t.Errorf("usage is flagged unavailable")
+	}
+	if usage.CPULimit != "2" || usage.MemoryLimit != "4Gi" {
+		t.Errorf("limits = %q, %q", usage.CPULimit, usage.MemoryLimit)
+	}
+}
+
+func TestScanWithoutMetricsServer(t *testing.T) {
+	for name, metricsErr := range map[string]error{
+		"metrics API not served": fmt.Errorf("%w: no matches for kind PodMetricsList", ErrMetricsUnavailable),
+		"metrics API failing":    fmt.Errorf("connection refused"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := newTestHealthScanner(testDeployment("querier", 1, map[string]string{"app": "querier"}))
+			h.WithMetricsClient(&fakePodMetrics{err: metricsErr})
+
+			health, err := h.ScanMimirInfrastructure(context.Background())
+			if err != nil {
+				t.Fatalf("ScanMimirInfrastructure: %v", err)
+			}
+
+			usage := scannedResource(t, health, "Deployment", "querier").ResourceUsage
+			if !usage.MetricsUnavailable || usage.CPUUsage != 0 || usage.MemoryUsage != 0 {
+				t.Errorf("usage = %+v, want zero usage flagged unavailable", usage)
+			}
+		})
+	}
+}
+
+func TestMetricsAPIClientSumsContainers(t *testing.T) {
+	podMetrics := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata": map[string]interface{}{
+			"name":      "ingester-0",
+			"namespace": "mimir",
+			"labels":    map[string]interface{}{"app": "ingester"},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "ingester", "usage": map[string]interface{}{"cpu": "1500m", "memory": "2Gi"}},
+			map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "250000000n", "memory": "64Mi"}},
+		},
+	}}
+	c := fake.NewClientBuilder().WithObjects(podMetrics).Build()
+
+	pods, err := NewMetricsAPIClient(c).ListPodMetrics(context.Background(), "mimir")
+	if err != nil {
+		t.Fatalf("ListPodMetrics: %v", err)
+	}
+	if len(pods) != 1 {
+		t.Fatalf("ListPodMetrics returned %d pods, want 1", len(pods))
+	}
+	if pods[0].CPU != 1.75 || pods[0].Memory != 2<<30+64<<20 || pods[0].Labels["app"] != "ingester" {
+		t.Errorf("pod metrics = %+v", pods[0])
+	}
+}
+
+func TestMetricsAPIClientWithoutClient(t *testing.T) {
+	if _, err := NewMetricsAPIClient(nil).ListPodMetrics(context.Background(), "mimir"); err != ErrMetricsUnavailable {
+		t.Errorf("ListPodMetrics without a client = %v, want ErrMetricsUnavailable", err)
+	}
+}
+
+// scannedTypes holds one object of each resource type the health scan lists
+func scannedTypes() []client.Object {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "mimir"}
+	}
+	labels := map[string]string{"app.kubernetes.io/component": "ingester"}
+	replicas := int32(1)
+	return []client.Object{
+		testDeployment("distributor", 1, map[string]string{"app.kubernetes.io/component": "distributor"}),
+		&appsv1.StatefulSet{ObjectMeta: meta("ingester"), Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+		}},
+		&appsv1.DaemonSet{ObjectMeta: meta("node-agent")},
+		&corev1.Service{ObjectMeta: meta("distributor")},
+		&corev1.ConfigMap{ObjectMeta: meta("mimir-runtime-overrides")},
+		&corev1.Secret{ObjectMeta: meta("mimir-objstore")},
+		&corev1.Pod{ObjectMeta: meta("ingester-0")},
+		&corev1.PersistentVolumeClaim{ObjectMeta: meta("storage-ingester-0")},
+	}
+}
+
+// isScannedList reports whether list is one of the resource types the health scan lists
+func isScannedList(list client.ObjectList) bool {
+	switch list.(type) {
+	case *appsv1.DeploymentList, *appsv1.StatefulSetList, *appsv1.DaemonSetList,
+		*corev1.ServiceList, *corev1.ConfigMapList, *corev1.SecretList,
+		*corev1.PodList, *corev1.PersistentVolumeClaimList:
+		return true
+	}
+	return false
+}
+
+// slowLists delays every list of a scanned type by latency, recording the peak number
+// of lists in flight
+func slowLists(latency time.Duration, peak *int32) interceptor.Funcs {
+	var inFlight int32
+	return interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if isScannedList(list) {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					seen := atomic.LoadInt32(peak)
+					if current <= seen || atomic.CompareAndSwapInt32(peak, seen, current) {
+						break
+					}
+				}
+				time.Sleep(latency)
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}
+}
+
+func TestScanPopulatesEveryResourceType(t *testing.T) {
+	h := newTestHealthScanner(scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+
+	health, err := h.ScanMimirInfrastructure(context.Background())
+	if err != nil {
+		t.Fatalf("ScanMimirInfrastructure: %v", err)
+	}
+
+	want := ResourceTypeCount{Deployments: 1, StatefulSets: 1, DaemonSets: 1, Services: 1, ConfigMaps: 1, Secrets: 1, Pods: 1, PVCs: 1}
+	if health.ComponentsCount != want {
+		t.Errorf("ComponentsCount = %+v, want one of each type", health.ComponentsCount)
+	}
+	if len(health.Resources) != 8 {
+		t.Errorf("resources = %d, want 8", len(health.Resources))
+	}
+	if len(health.ResourceScans) != 8 {
+		t.Errorf("resource scans = %d, want one per type", len(health.ResourceScans))
+	}
+	for _, scan := range health.ResourceScans {
+		if scan.Error != "" || scan.Count != 1 {
+			t.Errorf("scan of %s = %+v, want one resource without error", scan.Kind, scan)
+		}
+	}
+}
+
+func TestScanListsTypesConcurrently(t *testing.T) {
+	const latency = 100 * time.Millisecond
+	var peak int32
+	h := newInterceptedHealthScanner(slowLists(latency, &peak), scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+	h.MaxConcurrentScans = 8
+
+	start := time.Now()
+	health, err := h.ScanMimirInfrastructure(context.Background())
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("ScanMimirInfrastructure: %v", err)
+	}
+	if len(health.Resources) != 8 {
+		t.Errorf("resources = %d, want 8", len(health.Resources))
+	}
+	// Sequential lists would take 8 × latency
+	if elapsed >= 4*latency {
+		t.Errorf("scan took %v, want about the %v of the slowest list", elapsed, latency)
+	}
+}
+
+func TestScanBoundsConcurrency(t *testing.T) {
+	var peak int32
+	h := newInterceptedHealthScanner(slowLists(20*time.Millisecond, &peak), scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+	h.MaxConcurrentScans = 3
+
+	if _, err := h.ScanMimirInfrastructure(context.Background()); err != nil {
+		t.Fatalf("ScanMimirInfrastructure: %v", err)
+	}
+	if got := atomic.LoadInt32(&peak); got > 3 {
+		t.Errorf("peak concurrent lists = %d, want at most 3", got)
+	}
+}
+
+func TestScanContinuesWhenATypeFails(t *testing.T) {
+	h := newInterceptedHealthScanner(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, isConfigMaps := list.(*corev1.ConfigMapList); isConfigMaps {
+				return fmt.Errorf("configmaps is forbidden")
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}, scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+
+	health, err := h.ScanMimirInfrastructure(context.Background())
+	if err != nil {
+		t.Fatalf("ScanMimirInfrastructure: %v", err)
+	}
+	if health.ComponentsCount.ConfigMaps != 0 || health.ComponentsCount.Deployments != 1 {
+		t.Errorf("ComponentsCount = %+v, want every type but configmaps", health.ComponentsCount)
+	}
+	for _, scan := range health.ResourceScans {
+		if (scan.Error != "") != (scan.Kind == "configmaps") {
+			t.Errorf("scan of %s has error %q", scan.Kind, scan.Error)
+		}
+	}
+}
+
+func TestScanFailsWhenEveryTypeFails(t *testing.T) {
+	h := newInterceptedHealthScanner(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			return fmt.Errorf("connection refused")
+		},
+	}, scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+
+	if _, err := h.ScanMimirInfrastructure(context.Background()); err == nil {
+		t.Fatalf("ScanMimirInfrastructure succeeded without any listed resources")
+	}
+}
+
+func BenchmarkScanMimirInfrastructure(b *testing.B) {
+	var peak int32
+	h := newInterceptedHealthScanner(slowLists(5*time.Millisecond, &peak), scannedTypes()...)
+	h.WithMetricsClient(&fakePodMetrics{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.ScanMimirInfrastructure(context.Background()); err != nil {
+			b.Fatalf("ScanMimirInfrastructure: %v", err)
+		}
+	}
+}
+
+func TestDecayStaleHealth(t *testing.T) {
+	h := newTestHealthScanner()
+	if h.StalenessThreshold != 10*time.Minute {
+		t.Fatalf("staleness threshold = %v, want twice the 5m check interval", h.StalenessThreshold)
+	}
+	scannedAt := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: scannedAt}
+	h.now = clock.Now
+	scan := &MimirInfrastructureHealth{OverallHealth: "Healthy", OverallScore: 95, LastScanTime: scannedAt}
+
+	tests := []struct {
+		elapsed    time.Duration
+		wantScore  float64
+		wantHealth string
+	}{
+		{0, 95, "Healthy"},
+		{10 * time.Minute, 95, "Healthy"},
+		// 0.1 points per second past the threshold
+		{10*time.Minute + 100*time.Second, 85, "Healthy"},
+		{10*time.Minute + 450*time.Second, 50, "Healthy"},
+		{10*time.Minute + 460*time.Second, 49, "Stale"},
+		{10*time.Minute + time.Hour, 0, "Stale"},
+	}
+	previous := scan.OverallScore
+	for _, tt := range tests {
+		clock.now = scannedAt.Add(tt.elapsed)
+		decayed := h.DecayStaleHealth(scan)
+		if math.Abs(decayed.OverallScore-tt.wantScore) > 1e-9 || decayed.OverallHealth != tt.wantHealth {
+			t.Errorf("after %v score %v %s, want %v %s", tt.elapsed, decayed.OverallScore, decayed.OverallHealth, tt.wantScore, tt.wantHealth)
+		}
+		if decayed.OverallScore > previous {
+			t.Errorf("after %v score rose from %v to %v", tt.elapsed, previous, decayed.OverallScore)
+		}
+		previous = decayed.OverallScore
+		if decayed.DataFreshnessSeconds != tt.elapsed.Seconds() {
+			t.Errorf("after %v data freshness = %vs, want %vs", tt.elapsed, decayed.DataFreshnessSeconds, tt.elapsed.Seconds())
+		}
+	}
+	if scan.OverallScore != 95 || scan.OverallHealth != "Healthy" {
+		t.Errorf("decay modified the shared scan to %v %s", scan.OverallScore, scan.OverallHealth)
+	}
+}
+
+func TestCachedScannerDecaysStaleScans(t *testing.T) {
+	h := newTestHealthScanner(testDeployment("querier", 1, map[string]string{"app": "querier"}))
+	h.WithMetricsClient(&fakePodMetrics{})
+	scanner := NewCachedScanner(h, nil, 24*time.Hour, nil, logr.Discard())
+
+	fresh, err := scanner.ScanHealth(context.Background(), "mimir")
+	if err != nil {
+		t.Fatalf("ScanHealth: %v", err)
+	}
+
+	// The cached scan is served an hour after the scan, 50 minutes past the threshold
+	clock := &fakeClock{now: fresh.LastScanTime.Add(time.Hour)}
+	h.now = clock.Now
+	stale, err := scanner.ScanHealth(context.Background(), "mimir")
+	if err != nil {
+		t.Fatalf("ScanHealth: %v", err)
+	}
+	if !stale.LastScanTime.Equal(fresh.LastScanTime) {
+		t.Fatalf("second ScanHealth scanned again instead of serving the cached scan")
+	}
+	if stale.DataFreshnessSeconds != 3600 {
+		t.Errorf("data freshness = %vs, want 3600s", stale.DataFreshnessSeconds)
+	}
+	if stale.OverallScore != 0 || stale.OverallHealth != "Stale" {
+		t.Errorf("hour old scan scored %v %s, want 0 Stale", stale.OverallScore, stale.OverallHealth)
+	}
+}
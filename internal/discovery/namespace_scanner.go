@@ -10,6 +10,7 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
@@ -166,38 +167,46 @@ type FlowStep struct {
 
 // NamespaceScanner handles scanning of tenant namespaces
 type NamespaceScanner struct {
-	client kubernetes.Interface
-	config *config.Config
-	log    logr.Logger
+	client     kubernetes.Interface
+	live       *config.Live
+	log        logr.Logger
+	components *ComponentDetector
 }
 
 // NewNamespaceScanner creates a new NamespaceScanner
-func NewNamespaceScanner(client kubernetes.Interface, cfg *config.Config, log logr.Logger) *NamespaceScanner {
+func NewNamespaceScanner(client kubernetes.Interface, live *config.Live, log logr.Logger) *NamespaceScanner {
+	cfg := live.Load()
 	return &NamespaceScanner{
-		client: client,
-		config: cfg,
-		log:    log,
+		client:     client,
+		live:       live,
+		log:        log,
+		components: NewComponentDetectorOrDefault(cfg.MetricsDiscovery, log),
 	}
 }
 
-// ScanAllTenantNamespaces scans all tenant namespaces and returns detailed information
+// config returns the configuration in effect
+func (ns *NamespaceScanner) config() *config.Config {
+	return ns.live.Load()
+}
+
+// ScanAllTenantNamespaces scans all tenant namespaces and returns detailed information.
+// Under a namespace scan scope every configured namespace is scanned as a tenant namespace.
 func (ns *NamespaceScanner) ScanAllTenantNamespaces(ctx context.Context) ([]TenantNamespaceInfo, error) {
-	// Get all namespaces
-	namespaces, err := ns.client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	namespaces, scoped, err := ScanNamespaces(ctx, ns.client, ns.config(), ns.log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		return nil, err
 	}
 
 	var tenantNamespaces []TenantNamespaceInfo
 
-	for _, namespace := range namespaces.Items {
+	for _, namespace := range namespaces {
 		// Skip system namespaces
-		if ns.isSystemNamespace(namespace.Name) {
+		if !scoped && ns.isSystemNamespace(namespace.Name) {
 			continue
 		}
 
 		// Check if this looks like a tenant namespace
-		if ns.isTenantNamespace(&namespace) {
+		if scoped || ns.isTenantNamespace(&namespace) {
 			tenantInfo, err := ns.scanTenantNamespace(ctx, &namespace)
 			if err != nil {
 				ns.log.Error(err, "failed to scan tenant namespace", "namespace", namespace.Name)
@@ -506,44 +515,33 @@ func (ns *NamespaceScanner) scanSecrets(ctx context.Context, namespaceName strin
 func (ns *NamespaceScanner) identifyMimirComponents(deployments []DeploymentInfo, services []TenantServiceInfo) []MimirComponentInfo {
 	var components []MimirComponentInfo
 
-	mimirComponentTypes := []string{
-		"distributor",
-		"ingester",
-		"querier",
-		"query-frontend",
-		"compactor",
-		"store-gateway",
-		"ruler",
-		"alertmanager",
-	}
-
 	for _, deployment := range deployments {
-		for _, componentType := range mimirComponentTypes {
-			if strings.Contains(strings.ToLower(deployment.Name), componentType) {
-				// Find corresponding service
-				var endpoints []string
-				for _, service := range services {
-					if strings.Contains(strings.ToLower(service.Name), componentType) {
-						for _, port := range service.Ports {
-							endpoint := fmt.Sprintf("%s:%d", service.ClusterIP, port.Port)
-							endpoints = append(endpoints, endpoint)
-						}
-					}
-				}
+		componentType := ns.components.ComponentType(deployment.Name, deployment.Labels)
+		if componentType == "" {
+			continue
+		}
 
-				components = append(components, MimirComponentInfo{
-					Name:          deployment.Name,
-					Type:          componentType,
-					Status:        deployment.Status,
-					Replicas:      deployment.Replicas,
-					ReadyReplicas: deployment.ReadyReplicas,
-					Image:         deployment.Image,
-					Labels:        deployment.Labels,
-					Endpoints:     endpoints,
-				})
-				break
+		// Find corresponding service
+		var endpoints []string
+		for _, service := range services {
+			if ns.components.ComponentType(service.Name, service.Labels) == componentType {
+				for _, port := range service.Ports {
+					endpoint := fmt.Sprintf("%s:%d", service.ClusterIP, port.Port)
+					endpoints = append(endpoints, endpoint)
+				}
 			}
 		}
+
+		components = append(components, MimirComponentInfo{
+			Name:          deployment.Name,
+			Type:          componentType,
+			Status:        deployment.Status,
+			Replicas:      deployment.Replicas,
+			ReadyReplicas: deployment.ReadyReplicas,
+			Image:         deployment.Image,
+			Labels:        deployment.Labels,
+			Endpoints:     endpoints,
+		})
 	}
 
 	return components
@@ -665,3 +663,14 @@ func resourceListToMap(resourceList corev1.ResourceList) map[string]string {
 	}
 	return result
 }
+
+// ValidateNamespace checks that a namespace override is a valid Kubernetes namespace name
+func ValidateNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+	return nil
+}
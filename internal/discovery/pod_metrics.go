@@ -0,0 +1,126 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ErrMetricsUnavailable is returned when the metrics.k8s.io API (metrics-server) is not served
+var ErrMetricsUnavailable = errors.New("metrics API unavailable")
+
+// podMetricsListGVK is the metrics-server PodMetrics list kind
+var podMetricsListGVK = schema.GroupVersionKind{
+	Group:   "metrics.k8s.io",
+	Version: "v1beta1",
+	Kind:    "PodMetricsList",
+}
+
+// PodMetrics is the live usage of a single pod, summed across its containers
+type PodMetrics struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	// CPU usage in cores
+	CPU float64
+	// Memory working set in bytes
+	Memory float64
+}
+
+// PodMetricsClient lists live pod usage
+type PodMetricsClient interface {
+	ListPodMetrics(ctx context.Context, namespace string) ([]PodMetrics, error)
+}
+
+// metricsAPIClient reads PodMetrics from the metrics.k8s.io API through the
+// controller-runtime client
+type metricsAPIClient struct {
+	client client.Client
+}
+
+// NewMetricsAPIClient creates a PodMetricsClient backed by metrics-server
+func NewMetricsAPIClient(c client.Client) PodMetricsClient {
+	return &metricsAPIClient{client: c}
+}
+
+// ListPodMetrics returns the usage of every pod in the namespace
+func (m *metricsAPIClient) ListPodMetrics(ctx context.Context, namespace string) ([]PodMetrics, error) {
+	if m.client == nil {
+		return nil, ErrMetricsUnavailable
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(podMetricsListGVK)
+
+	if err := m.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+		}
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+
+	pods := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		pod := PodMetrics{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Labels:    item.GetLabels(),
+		}
+
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, raw := range containers {
+			container, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usage, _, _ := unstructured.NestedStringMap(container, "usage")
+			if cpu, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+				pod.CPU += float64(cpu.MilliValue()) / 1000
+			}
+			if memory, err := resource.ParseQuantity(usage["memory"]); err == nil {
+				pod.Memory += float64(memory.Value())
+			}
+		}
+
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+// podUsageIndex holds the pod usage collected for one scan
+type podUsageIndex struct {
+	pods   []PodMetrics
+	byName map[string]PodMetrics
+}
+
+func newPodUsageIndex(pods []PodMetrics) *podUsageIndex {
+	index := &podUsageIndex{
+		pods:   pods,
+		byName: make(map[string]PodMetrics, len(pods)),
+	}
+	for _, pod := range pods {
+		index.byName[pod.Name] = pod
+	}
+	return index
+}
+
+// matching returns the pods selected by a workload selector
+func (i *podUsageIndex) matching(selector labels.Selector) []PodMetrics {
+	var matched []PodMetrics
+	for _, pod := range i.pods {
+		if selector.Matches(labels.Set(pod.Labels)) {
+			matched = append(matched, pod)
+		}
+	}
+	return matched
+}
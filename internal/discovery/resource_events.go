@@ -0,0 +1,169 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maxResourceEvents caps the events returned for a resource, most recent first
+const maxResourceEvents = 20
+
+// repeatedOOMKills is the number of OOM kills reported by an event from which they are
+// treated as critical
+const repeatedOOMKills = 2
+
+// ResourceEvent is a Kubernetes Event recorded for a resource
+type ResourceEvent struct {
+	Type          string    `json:"type"` // Normal, Warning
+	Reason        string    `json:"reason"`
+	Message       string    `json:"message"`
+	Count         int32     `json:"count"`
+	LastTimestamp time.Time `json:"last_timestamp"`
+}
+
+// WithResourceEvents returns a copy of a scanned resource with its recent Events and the
+// issues notable event reasons point at, such as repeated OOM kills or failed
+// scheduling. The events are read from the API server by a field selector on the
+// involved object, so only the resource detail pays for them.
+func (h *HealthScanner) WithResourceEvents(ctx context.Context, resource ResourceHealth) (ResourceHealth, error) {
+	var list corev1.EventList
+	if err := h.reader.List(ctx, &list,
+		client.InNamespace(resource.Namespace),
+		client.MatchingFields{"involvedObject.name": resource.Name},
+	); err != nil {
+		return resource, fmt.Errorf("failed to list events of %s %s: %w", resource.Kind, resource.Name, err)
+	}
+
+	// Objects of other kinds may share the name, e.g. a Service of a Deployment
+	events := make([]ResourceEvent, 0, len(list.Items))
+	for _, event := range list.Items {
+		if event.InvolvedObject.Kind == resource.Kind {
+			events = append(events, resourceEvent(event))
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.After(events[j].LastTimestamp)
+	})
+	if len(events) > maxResourceEvents {
+		events = events[:maxResourceEvents]
+	}
+
+	detailed := resource
+	detailed.Events = events
+	detailed.Issues = append(append([]HealthIssue(nil), resource.Issues...), detectEventIssues(events)...)
+	return detailed, nil
+}
+
+// resourceEvent converts an Event, taking the count and last occurrence from its series
+// when the event is recorded through the events.k8s.io API
+func resourceEvent(event corev1.Event) ResourceEvent {
+	converted := ResourceEvent{
+		Type:          event.Type,
+		Reason:        event.Reason,
+		Message:       event.Message,
+		Count:         event.Count,
+		LastTimestamp: event.LastTimestamp.Time,
+	}
+	if event.Series != nil {
+		converted.Count = event.Series.Count
+		converted.LastTimestamp = event.Series.LastObservedTime.Time
+	}
+	if converted.LastTimestamp.IsZero() {
+		converted.LastTimestamp = event.EventTime.Time
+	}
+	if converted.LastTimestamp.IsZero() {
+		converted.LastTimestamp = event.FirstTimestamp.Time
+	}
+	if converted.Count == 0 {
+		converted.Count = 1
+	}
+	return converted
+}
+
+// detectEventIssues detects issues from the warning events of a resource, one of each
+// kind, from its most recent event
+func detectEventIssues(events []ResourceEvent) []HealthIssue {
+	var issues []HealthIssue
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+
+		var issue *HealthIssue
+		switch event.Reason {
+		case "OOMKilled", "OOMKilling":
+			severity := "Warning"
+			if event.Count >= repeatedOOMKills {
+				severity = "Critical"
+			}
+			issue = &HealthIssue{
+				Severity:    severity,
+				Category:    "Performance",
+				Title:       "Containers OOM Killed",
+				Description: fmt.Sprintf("Out of memory kill reported %d times: %s", event.Count, event.Message),
+				Suggestion:  "Raise the memory limits of the containers or reduce their memory usage",
+			}
+		case "FailedScheduling":
+			issue = &HealthIssue{
+				Severity:    "Critical",
+				Category:    "Availability",
+				Title:       "Pods Cannot Be Scheduled",
+				Description: fmt.Sprintf("Scheduling failed %d times: %s", event.Count, event.Message),
+				Suggestion:  "Check the nodes for CPU or memory pressure and the resource requests, node selectors and taints the pods must fit",
+			}
+		case "ErrImagePull", "ImagePullBackOff":
+			issue = imagePullIssue(event)
+		case "BackOff":
+			if strings.Contains(event.Message, "pulling image") {
+				issue = imagePullIssue(event)
+				break
+			}
+			issue = &HealthIssue{
+				Severity:    "Critical",
+				Category:    "Availability",
+				Title:       "Containers Crash Looping",
+				Description: fmt.Sprintf("Restart back-off reported %d times: %s", event.Count, event.Message),
+				Suggestion:  "Check the logs of the previous container run for the crash reason",
+			}
+		case "FailedMount", "FailedAttachVolume":
+			issue = &HealthIssue{
+				Severity:    "Warning",
+				Category:    "Availability",
+				Title:       "Volumes Cannot Be Mounted",
+				Description: fmt.Sprintf("Volume mount failed %d times: %s", event.Count, event.Message),
+				Suggestion:  "Check the PersistentVolumeClaims and storage class of the volumes",
+			}
+		case "Unhealthy":
+			issue = &HealthIssue{
+				Severity:    "Warning",
+				Category:    "Availability",
+				Title:       "Probes Failing",
+				Description: fmt.Sprintf("Probe failed %d times: %s", event.Count, event.Message),
+				Suggestion:  "Check the readiness and liveness probes against the startup and response times of the container",
+			}
+		}
+		if issue != nil && !seen[issue.Title] {
+			seen[issue.Title] = true
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// imagePullIssue is the issue of an event reporting failed image pulls
+func imagePullIssue(event ResourceEvent) *HealthIssue {
+	return &HealthIssue{
+		Severity:    "Critical",
+		Category:    "Configuration",
+		Title:       "Image Cannot Be Pulled",
+		Description: fmt.Sprintf("Image pull failed %d times: %s", event.Count, event.Message),
+		Suggestion:  "Check the image name and tag and the image pull secrets",
+	}
+}
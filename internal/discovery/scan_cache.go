@@ -0,0 +1,247 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+const (
+	// scanCacheBackend labels infrastructure scan lookups in the cache metrics
+	scanCacheBackend = "infrastructure-scan"
+
+	// cachedScanTimeout bounds a scan shared by several requests; it is detached from
+	// the request that started it so a cancelled request does not fail the others
+	cachedScanTimeout = 60 * time.Second
+)
+
+// ErrScannerUnavailable is returned when a scan is requested without a Kubernetes client
+var ErrScannerUnavailable = errors.New("infrastructure scanning requires a Kubernetes client")
+
+// CachedScanner serves infrastructure scans to the API handlers from a shared cache.
+// A scan younger than the TTL is served as is. An older scan, up to twice the TTL, is
+// still served while a single background scan refreshes it; beyond that callers wait
+// for a new scan. Concurrent requests for the same namespace share one scan.
+//
+// Results are shared between callers and must not be modified.
+type CachedScanner struct {
+	health     *HealthScanner
+	autonomous *AutonomousScanner
+	ttl        time.Duration
+	history    *HealthHistory
+	log        logr.Logger
+
+	healthScans     scanCache[*MimirInfrastructureHealth]
+	autonomousScans scanCache[*MimirInfrastructure]
+}
+
+// NewCachedScanner wraps the health and autonomous scanners. Either may be nil when
+// its client is unavailable. A ttl of zero disables caching but still shares
+// concurrent scans. Every completed health scan is recorded in history, which may
+// be nil.
+func NewCachedScanner(health *HealthScanner, autonomous *AutonomousScanner, ttl time.Duration, history *HealthHistory, log logr.Logger) *CachedScanner {
+	return &CachedScanner{
+		health:     health,
+		autonomous: autonomous,
+		ttl:        ttl,
+		history:    history,
+		log:        log,
+	}
+}
+
+// ScanHealth returns the health scan of the Mimir installation in a namespace, its score
+// decayed by the age of the scan
+func (c *CachedScanner) ScanHealth(ctx context.Context, namespace string) (*MimirInfrastructureHealth, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	if c.health == nil {
+		return nil, ErrScannerUnavailable
+	}
+	health, err := c.healthScans.get(ctx, namespace, c.ttl, c.log, func(scanCtx context.Context) (*MimirInfrastructureHealth, error) {
+		health, err := c.health.ScanMimirInfrastructureInNamespace(scanCtx, namespace)
+		if err == nil {
+			c.history.Record(namespace, health)
+		}
+		return health, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.health.DecayStaleHealth(health), nil
+}
+
+// DescribeResource returns a scanned resource with its recent Events and the issues
+// they point at, read for this request only
+func (c *CachedScanner) DescribeResource(ctx context.Context, resource ResourceHealth) (ResourceHealth, error) {
+	if c.health == nil {
+		return resource, ErrScannerUnavailable
+	}
+	return c.health.WithResourceEvents(ctx, resource)
+}
+
+// StreamHealth scans the health of the Mimir installation in a namespace without the
+// cache, handing the resources of each type to onScanned as soon as they are scanned.
+// The completed scan is recorded in the history.
+func (c *CachedScanner) StreamHealth(ctx context.Context, namespace string, onScanned func(ResourceScan, []ResourceHealth)) (*MimirInfrastructureHealth, error) {
+	if c.health == nil {
+		return nil, ErrScannerUnavailable
+	}
+	health, err := c.health.ScanMimirInfrastructureStream(ctx, namespace, onScanned)
+	if err != nil {
+		return nil, err
+	}
+	c.history.Record(namespace, health)
+	return health, nil
+}
+
+// HealthHistory returns the recorded health scans of a namespace, oldest first
+func (c *CachedScanner) HealthHistory(namespace string) []HealthSample {
+	return c.history.Samples(namespace)
+}
+
+// ScanInfrastructure returns the autonomous scan of the Mimir installation in a namespace
+func (c *CachedScanner) ScanInfrastructure(ctx context.Context, namespace string) (*MimirInfrastructure, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	if c.autonomous == nil {
+		return nil, ErrScannerUnavailable
+	}
+	return c.autonomousScans.get(ctx, namespace, c.ttl, c.log, func(scanCtx context.Context) (*MimirInfrastructure, error) {
+		return c.autonomous.ScanMimirInfrastructureInNamespace(scanCtx, namespace)
+	})
+}
+
+// Invalidate drops all cached scans, so the next request scans again
+func (c *CachedScanner) Invalidate() {
+	c.healthScans.clear()
+	c.autonomousScans.clear()
+}
+
+// InvalidateHealth drops the cached health scan of a namespace, so the next request
+// scans it again
+func (c *CachedScanner) InvalidateHealth(namespace string) {
+	c.healthScans.delete(namespace)
+}
+
+// scanCache caches scan results per namespace
+type scanCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]*scanEntry[T]
+	group   singleflight.Group
+
+	// now is the clock the age of the scans is measured with, time.Now when nil
+	now func() time.Time
+}
+
+type scanEntry[T any] struct {
+	value      T
+	scannedAt  time.Time
+	refreshing bool
+}
+
+// get returns the cached scan of key while it is fresh, serves it while a background
+// scan refreshes it when stale, or scans when there is no usable result
+func (c *scanCache[T]) get(ctx context.Context, key string, ttl time.Duration, log logr.Logger, scan func(context.Context) (T, error)) (T, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		age := c.clock().Sub(entry.scannedAt)
+		if age < ttl {
+			c.mu.Unlock()
+			metrics.CacheMetricsInstance.IncCacheRequest(scanCacheBackend, "hit")
+			return entry.value, nil
+		}
+		if age < 2*ttl {
+			if !entry.refreshing {
+				entry.refreshing = true
+				go c.refresh(key, ttl, log, scan)
+			}
+			c.mu.Unlock()
+			metrics.CacheMetricsInstance.IncCacheRequest(scanCacheBackend, "stale")
+			return entry.value, nil
+		}
+	}
+	c.mu.Unlock()
+
+	metrics.CacheMetricsInstance.IncCacheRequest(scanCacheBackend, "miss")
+	return c.load(ctx, key, ttl, scan)
+}
+
+// load runs the scan of key, sharing it with concurrent callers, and caches a successful
+// result. A scan cached by a shared scan that finished after the caller missed the cache
+// is returned without scanning again.
+func (c *scanCache[T]) load(ctx context.Context, key string, ttl time.Duration, scan func(context.Context) (T, error)) (T, error) {
+	results := c.group.DoChan(key, func() (interface{}, error) {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok && c.clock().Sub(entry.scannedAt) < ttl {
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+		c.mu.Unlock()
+
+		scanCtx, cancel := context.WithTimeout(context.Background(), cachedScanTimeout)
+		defer cancel()
+
+		value, err := scan(scanCtx)
+		if err != nil {
+			return value, err
+		}
+
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]*scanEntry[T])
+		}
+		c.entries[key] = &scanEntry[T]{value: value, scannedAt: c.clock()}
+		c.mu.Unlock()
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case result := <-results:
+		value, _ := result.Val.(T)
+		return value, result.Err
+	}
+}
+
+// refresh rescans key in the background; on failure the stale result is kept and the
+// next request past the TTL retries
+func (c *scanCache[T]) refresh(key string, ttl time.Duration, log logr.Logger, scan func(context.Context) (T, error)) {
+	if _, err := c.load(context.Background(), key, ttl, scan); err != nil {
+		log.Error(err, "background infrastructure scan failed, serving previous result", "namespace", key)
+
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok {
+			entry.refreshing = false
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *scanCache[T]) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *scanCache[T]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *scanCache[T]) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,89 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// countedScans counts the health scans by their Deployment lists, each delayed by
+// latency so concurrent requests overlap the scan
+func countedScans(scans *int32, latency time.Duration) interceptor.Funcs {
+	return interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if _, ok := list.(*appsv1.DeploymentList); ok {
+				atomic.AddInt32(scans, 1)
+				time.Sleep(latency)
+			}
+			return c.List(ctx, list, opts...)
+		},
+	}
+}
+
+// scanConcurrently requests the health scan of the mimir namespace from n goroutines
+func scanConcurrently(t *testing.T, scanner *CachedScanner, n int) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := scanner.ScanHealth(context.Background(), "mimir"); err != nil {
+				t.Errorf("ScanHealth: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// waitForScans waits until scans reaches want
+func waitForScans(t *testing.T, scans *int32, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(scans) < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d scans, want %d", atomic.LoadInt32(scans), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCachedScannerScansOncePerTTL(t *testing.T) {
+	const ttl = time.Minute
+	var scans int32
+	h := newInterceptedHealthScanner(countedScans(&scans, 20*time.Millisecond),
+		testDeployment("querier", 1, map[string]string{"app": "querier"}))
+	h.WithMetricsClient(&fakePodMetrics{})
+	clock := &fakeClock{now: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)}
+	scanner := NewCachedScanner(h, nil, ttl, nil, logr.Discard())
+	scanner.healthScans.now = clock.Now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    int32
+	}{
+		{"first requests share one scan", 0, 1},
+		{"within the TTL", ttl / 2, 1},
+		{"past the TTL one background scan refreshes", ttl, 2},
+		{"the refreshed scan is fresh", ttl / 2, 2},
+		{"past twice the TTL requests wait for one scan", 2*ttl + time.Second, 3},
+	}
+	for _, tt := range tests {
+		clock.Advance(tt.advance)
+		scanConcurrently(t, scanner, 20)
+		waitForScans(t, &scans, tt.want)
+		// Give a duplicate background scan the time to show up
+		time.Sleep(50 * time.Millisecond)
+		if got := atomic.LoadInt32(&scans); got != tt.want {
+			t.Errorf("%s: %d scans, want %d", tt.name, got, tt.want)
+		}
+	}
+}
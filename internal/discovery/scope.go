@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ScanNamespaces returns the namespaces to scan under metricsDiscovery.scanScope. The
+// cluster scope lists every namespace; when that is forbidden, or with the namespaces
+// scope, only the configured scanNamespaces are scanned, and scoped reports which it was.
+func ScanNamespaces(ctx context.Context, client kubernetes.Interface, cfg *config.Config, log logr.Logger) (namespaces []corev1.Namespace, scoped bool, err error) {
+	if cfg.MetricsDiscovery.ScanScope != config.ScanScopeNamespaces {
+		list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err == nil {
+			return list.Items, false, nil
+		}
+		if !apierrors.IsForbidden(err) {
+			return nil, false, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		log.Info("listing namespaces is forbidden, scanning only the configured namespaces; set metricsDiscovery.scanScope to namespaces to skip cluster-wide list calls",
+			"namespaces", cfg.ScopedNamespaces(),
+			"error", err.Error())
+	}
+
+	return scopedNamespaces(ctx, client, cfg, log), true, nil
+}
+
+// scopedNamespaces gets the configured scan namespaces. Namespaces the service account
+// may not get are still scanned by name, as only their workloads need to be listed.
+func scopedNamespaces(ctx context.Context, client kubernetes.Interface, cfg *config.Config, log logr.Logger) []corev1.Namespace {
+	var namespaces []corev1.Namespace
+	for _, name := range cfg.ScopedNamespaces() {
+		namespace, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			namespaces = append(namespaces, *namespace)
+		case apierrors.IsNotFound(err):
+			log.Info("configured scan namespace does not exist", "namespace", name)
+		default:
+			log.V(1).Info("cannot get scan namespace, scanning it by name", "namespace", name, "error", err.Error())
+			namespaces = append(namespaces, corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+			})
+		}
+	}
+	return namespaces
+}
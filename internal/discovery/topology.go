@@ -0,0 +1,190 @@
+package discovery
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Protocols Mimir components use to talk to each other
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+)
+
+// Default ports of the Mimir Helm chart services
+const (
+	mimirGRPCPort = 9095
+	mimirHTTPPort = 8080
+)
+
+// componentEdge is a request path from one Mimir component to another
+type componentEdge struct {
+	target   string
+	protocol string
+	// unless skips the edge when a component of this type is present, e.g. the
+	// query-frontend reaches queriers through the query-scheduler when one is deployed
+	unless string
+}
+
+// mimirComponentEdges are the request paths between Mimir components, keyed by the
+// component sending the requests. The compactor and overrides-exporter only talk
+// to object storage and Kubernetes, so they have no edges.
+var mimirComponentEdges = map[string][]componentEdge{
+	"gateway": {
+		{target: "distributor", protocol: protocolHTTP},
+		{target: "query-frontend", protocol: protocolHTTP},
+		{target: "alertmanager", protocol: protocolHTTP},
+		{target: "ruler", protocol: protocolHTTP},
+	},
+	"nginx": {
+		{target: "distributor", protocol: protocolHTTP},
+		{target: "query-frontend", protocol: protocolHTTP},
+		{target: "alertmanager", protocol: protocolHTTP},
+		{target: "ruler", protocol: protocolHTTP},
+	},
+	"distributor": {
+		{target: "ingester", protocol: protocolGRPC},
+	},
+	"query-frontend": {
+		{target: "query-scheduler", protocol: protocolGRPC},
+		{target: "querier", protocol: protocolGRPC, unless: "query-scheduler"},
+	},
+	"query-scheduler": {
+		{target: "querier", protocol: protocolGRPC},
+	},
+	"querier": {
+		{target: "ingester", protocol: protocolGRPC},
+		{target: "store-gateway", protocol: protocolGRPC},
+	},
+	"ruler": {
+		{target: "ingester", protocol: protocolGRPC},
+		{target: "store-gateway", protocol: protocolGRPC},
+		{target: "alertmanager", protocol: protocolHTTP},
+	},
+}
+
+// TopologyWorkload is a Mimir workload placed in the component topology
+type TopologyWorkload struct {
+	Name      string
+	Component string
+	// PodLabels are the labels of the workload's pod template, matched against
+	// service selectors
+	PodLabels map[string]string
+}
+
+// BuildComponentTopology returns the workloads each workload sends requests to.
+// A workload is only a target when a service selects its pods and exposes the
+// port the edge needs, so the topology follows what is actually reachable in the
+// cluster. When services is nil, service information is unavailable and every
+// workload of the target component is used.
+func BuildComponentTopology(workloads []TopologyWorkload, services []corev1.Service) map[string][]string {
+	targets := componentTargets(workloads, services)
+
+	topology := make(map[string][]string, len(workloads))
+	for _, source := range workloads {
+		names := make([]string, 0, len(targets[source.Name]))
+		for name := range targets[source.Name] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		topology[source.Name] = names
+	}
+
+	return topology
+}
+
+// componentTargets returns, per workload, the protocol it uses to reach each target
+// workload
+func componentTargets(workloads []TopologyWorkload, services []corev1.Service) map[string]map[string]string {
+	present := make(map[string]bool)
+	for _, workload := range workloads {
+		present[workload.Component] = true
+	}
+
+	protocols := servedProtocols(workloads, services)
+
+	targets := make(map[string]map[string]string, len(workloads))
+	for _, source := range workloads {
+		targets[source.Name] = make(map[string]string)
+		for _, edge := range mimirComponentEdges[source.Component] {
+			if edge.unless != "" && present[edge.unless] {
+				continue
+			}
+			for _, target := range workloads {
+				if target.Component != edge.target || target.Name == source.Name {
+					continue
+				}
+				if services == nil || protocols[target.Name][edge.protocol] {
+					targets[source.Name][target.Name] = edge.protocol
+				}
+			}
+		}
+	}
+
+	return targets
+}
+
+// servedProtocols returns, per workload, the protocols exposed by services selecting
+// its pods
+func servedProtocols(workloads []TopologyWorkload, services []corev1.Service) map[string]map[string]bool {
+	protocols := make(map[string]map[string]bool)
+	for _, service := range services {
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(service.Spec.Selector)
+
+		for _, workload := range workloads {
+			if len(workload.PodLabels) == 0 || !selector.Matches(labels.Set(workload.PodLabels)) {
+				continue
+			}
+			for _, port := range service.Spec.Ports {
+				protocol := portProtocol(port)
+				if protocol == "" {
+					continue
+				}
+				if protocols[workload.Name] == nil {
+					protocols[workload.Name] = make(map[string]bool)
+				}
+				protocols[workload.Name][protocol] = true
+			}
+		}
+	}
+	return protocols
+}
+
+// portProtocol identifies a service port as gRPC or HTTP from its app protocol,
+// name or the default Mimir port numbers
+func portProtocol(port corev1.ServicePort) string {
+	if port.AppProtocol != nil {
+		appProtocol := strings.ToLower(*port.AppProtocol)
+		if strings.Contains(appProtocol, protocolGRPC) {
+			return protocolGRPC
+		}
+		if strings.Contains(appProtocol, protocolHTTP) {
+			return protocolHTTP
+		}
+	}
+
+	name := strings.ToLower(port.Name)
+	switch {
+	case strings.Contains(name, protocolGRPC):
+		return protocolGRPC
+	case strings.Contains(name, protocolHTTP):
+		return protocolHTTP
+	}
+
+	switch int(port.Port) {
+	case mimirGRPCPort:
+		return protocolGRPC
+	case mimirHTTPPort, 80:
+		return protocolHTTP
+	}
+	if port.TargetPort.IntValue() == mimirGRPCPort {
+		return protocolGRPC
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TopologyGraph is the graph of Mimir components and the request paths between them
+type TopologyGraph struct {
+	Namespace string         `json:"namespace"`
+	Nodes     []TopologyNode `json:"nodes"`
+	Edges     []TopologyEdge `json:"edges"`
+}
+
+// TopologyNode is a Mimir component workload
+type TopologyNode struct {
+	ComponentName   string `json:"component_name"`
+	ComponentType   string `json:"component_type"`
+	Role            string `json:"role"`
+	HealthStatus    string `json:"health_status"`
+	ReplicaCount    int32  `json:"replica_count"`
+	MetricsEndpoint string `json:"metrics_endpoint,omitempty"`
+}
+
+// TopologyEdge is a request path from one component workload to another
+type TopologyEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Protocol string `json:"protocol"`
+	// LatencyP99Ms is the p99 latency of the requests served by the target over the
+	// protocol, when it could be queried
+	LatencyP99Ms *float64 `json:"latency_p99_ms,omitempty"`
+}
+
+// EdgeLatencyFunc returns the p99 latency in milliseconds of the requests the target
+// component serves over the protocol, or false when it is unknown
+type EdgeLatencyFunc func(target TopologyNode, protocol string) (float64, bool)
+
+// BuildTopologyGraph derives the component graph from an infrastructure scan. The
+// scan carries no pod selectors, so every workload of a target component is
+// connected. latency may be nil.
+func BuildTopologyGraph(infra *MimirInfrastructure, latency EdgeLatencyFunc) *TopologyGraph {
+	graph := &TopologyGraph{
+		Nodes: []TopologyNode{},
+		Edges: []TopologyEdge{},
+	}
+	if infra == nil {
+		return graph
+	}
+	graph.Namespace = infra.Namespace
+
+	names := make([]string, 0, len(infra.Components))
+	for name := range infra.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	nodes := make(map[string]TopologyNode, len(names))
+	workloads := make([]TopologyWorkload, 0, len(names))
+	for _, name := range names {
+		component := infra.Components[name]
+		node := TopologyNode{
+			ComponentName: component.Name,
+			ComponentType: component.Type,
+			Role:          component.Role,
+			HealthStatus:  component.Health.Status,
+			ReplicaCount:  component.Replicas,
+		}
+		if node.HealthStatus == "" {
+			node.HealthStatus = "unknown"
+		}
+		if len(component.MetricsURLs) > 0 {
+			node.MetricsEndpoint = component.MetricsURLs[0]
+		}
+		nodes[name] = node
+		graph.Nodes = append(graph.Nodes, node)
+		workloads = append(workloads, TopologyWorkload{Name: name, Component: component.Type})
+	}
+
+	targets := componentTargets(workloads, nil)
+	for _, source := range names {
+		targetNames := make([]string, 0, len(targets[source]))
+		for target := range targets[source] {
+			targetNames = append(targetNames, target)
+		}
+		sort.Strings(targetNames)
+
+		for _, target := range targetNames {
+			edge := TopologyEdge{
+				Source:   source,
+				Target:   target,
+				Protocol: targets[source][target],
+			}
+			if latency != nil {
+				if value, ok := latency(nodes[target], edge.Protocol); ok {
+					edge.LatencyP99Ms = &value
+				}
+			}
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	return graph
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *TopologyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph mimir {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range g.Nodes {
+		label := fmt.Sprintf("%s\\n%s, %d replicas", node.ComponentName, node.HealthStatus, node.ReplicaCount)
+		fmt.Fprintf(&b, "  %s [label=%s];\n", dotID(node.ComponentName), dotID(label))
+	}
+	for _, edge := range g.Edges {
+		label := edge.Protocol
+		if edge.LatencyP99Ms != nil {
+			label = fmt.Sprintf("%s p99 %.1fms", edge.Protocol, *edge.LatencyP99Ms)
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotID(edge.Source), dotID(edge.Target), dotID(label))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotID quotes a DOT identifier; backslashes are kept so \n line breaks work in labels
+func dotID(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
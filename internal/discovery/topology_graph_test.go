@@ -0,0 +1,289 @@
+package discovery
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// topologyInfrastructure is a scan result of a microservices Mimir deployment
+func topologyInfrastructure() *MimirInfrastructure {
+	component := func(name, componentType string, replicas int32, status string) *MimirComponent {
+		return &MimirComponent{
+			Name:        name,
+			Type:        componentType,
+			Role:        componentType,
+			Replicas:    replicas,
+			MetricsURLs: []string{fmt.Sprintf("http://%s.mimir.svc:8080/metrics", name)},
+			Health:      ComponentHealth{Status: status},
+		}
+	}
+	return &MimirInfrastructure{
+		Namespace: "mimir",
+		Components: map[string]*MimirComponent{
+			"mimir-distributor": component("mimir-distributor", "distributor", 3, "healthy"),
+			"mimir-ingester":    component("mimir-ingester", "ingester", 6, "healthy"),
+			"mimir-querier":     component("mimir-querier", "querier", 2, "degraded"),
+			// A quote in a name must not break the DOT output
+			`mimir-"compactor"`: component(`mimir-"compactor"`, "compactor", 1, ""),
+		},
+	}
+}
+
+// findEdge returns the edge from source to target
+func findEdge(graph *TopologyGraph, source, target string) (TopologyEdge, bool) {
+	for _, edge := range graph.Edges {
+		if edge.Source == source && edge.Target == target {
+			return edge, true
+		}
+	}
+	return TopologyEdge{}, false
+}
+
+func TestBuildTopologyGraph(t *testing.T) {
+	latency := func(target TopologyNode, protocol string) (float64, bool) {
+		if target.ComponentType == "ingester" && protocol == "grpc" {
+			return 12.5, true
+		}
+		return 0, false
+	}
+	graph := BuildTopologyGraph(topologyInfrastructure(), latency)
+
+	if graph.Namespace != "mimir" || len(graph.Nodes) != 4 {
+		t.Fatalf("graph = namespace %q, %d nodes; want the 4 components of mimir", graph.Namespace, len(graph.Nodes))
+	}
+	edge, exists := findEdge(graph, "mimir-distributor", "mimir-ingester")
+	if !exists {
+		t.Fatalf("no distributor -> ingester edge in %+v", graph.Edges)
+	}
+	if edge.Protocol != "grpc" || edge.LatencyP99Ms == nil || *edge.LatencyP99Ms != 12.5 {
+		t.Errorf("distributor -> ingester edge = %+v, want grpc with the queried latency", edge)
+	}
+	if edge, exists := findEdge(graph, "mimir-querier", "mimir-ingester"); !exists || edge.LatencyP99Ms == nil {
+		t.Errorf("querier -> ingester edge = %+v (exists %v), want it with the queried latency", edge, exists)
+	}
+	if _, exists := findEdge(graph, "mimir-ingester", "mimir-distributor"); exists {
+		t.Errorf("graph has an ingester -> distributor edge")
+	}
+
+	for _, node := range graph.Nodes {
+		if node.ComponentName == "mimir-distributor" &&
+			(node.ReplicaCount != 3 || node.HealthStatus != "healthy" || node.MetricsEndpoint != "http://mimir-distributor.mimir.svc:8080/metrics") {
+			t.Errorf("distributor node = %+v", node)
+		}
+		if node.ComponentType == "compactor" && node.HealthStatus != "unknown" {
+			t.Errorf("health of a component without status = %q, want unknown", node.HealthStatus)
+		}
+	}
+}
+
+func TestBuildTopologyGraphWithoutLatency(t *testing.T) {
+	graph := BuildTopologyGraph(topologyInfrastructure(), nil)
+	for _, edge := range graph.Edges {
+		if edge.LatencyP99Ms != nil {
+			t.Errorf("edge %s -> %s has latency %v without a latency lookup", edge.Source, edge.Target, *edge.LatencyP99Ms)
+		}
+	}
+
+	empty := BuildTopologyGraph(nil, nil)
+	if empty.Nodes == nil || empty.Edges == nil {
+		t.Errorf("graph of no scan = %+v, want empty node and edge lists", empty)
+	}
+}
+
+func TestTopologyGraphDOT(t *testing.T) {
+	latency := func(target TopologyNode, protocol string) (float64, bool) { return 3, true }
+	graph := BuildTopologyGraph(topologyInfrastructure(), latency)
+
+	dot := graph.DOT()
+	nodes, edges, err := parseDOT(dot)
+	if err != nil {
+		t.Fatalf("DOT output is invalid: %v\n%s", err, dot)
+	}
+	if len(nodes) != len(graph.Nodes) || len(edges) != len(graph.Edges) {
+		t.Errorf("DOT has %d nodes and %d edges, want %d and %d", len(nodes), len(edges), len(graph.Nodes), len(graph.Edges))
+	}
+	if !edges["mimir-distributor -> mimir-ingester"] {
+		t.Errorf("DOT edges = %v, want distributor -> ingester", edges)
+	}
+	if !nodes[`mimir-"compactor"`] {
+		t.Errorf("DOT nodes = %v, want the quoted compactor name", nodes)
+	}
+}
+
+// parseDOT checks that dot is a digraph of node, edge and attribute statements and
+// returns its node IDs and edges
+func parseDOT(dot string) (map[string]bool, map[string]bool, error) {
+	tokens, err := dotTokens(dot)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &dotParser{tokens: tokens}
+	nodes, edges := make(map[string]bool), make(map[string]bool)
+
+	if err := p.expect("digraph"); err != nil {
+		return nil, nil, err
+	}
+	if p.peek() != "{" {
+		p.next() // graph name
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, nil, err
+	}
+	for p.peek() != "}" {
+		if p.peek() == "" {
+			return nil, nil, fmt.Errorf("unterminated graph")
+		}
+		id, err := p.id()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch p.peek() {
+		case "=":
+			p.next()
+			if _, err := p.id(); err != nil {
+				return nil, nil, err
+			}
+		case "->":
+			p.next()
+			target, err := p.id()
+			if err != nil {
+				return nil, nil, err
+			}
+			edges[id+" -> "+target] = true
+			if err := p.attributes(); err != nil {
+				return nil, nil, err
+			}
+		default:
+			if id != "node" && id != "edge" && id != "graph" {
+				nodes[id] = true
+			}
+			if err := p.attributes(); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, nil, err
+		}
+	}
+	p.next()
+	if p.peek() != "" {
+		return nil, nil, fmt.Errorf("unexpected %q after the graph", p.peek())
+	}
+	return nodes, edges, nil
+}
+
+// dotToken is an identifier, a quoted string with its quotes removed, or punctuation
+type dotToken struct {
+	value  string
+	quoted bool
+}
+
+func dotTokens(dot string) ([]dotToken, error) {
+	var tokens []dotToken
+	runes := []rune(dot)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '"':
+			var value strings.Builder
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string")
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '"' {
+					value.WriteRune('"')
+					i++
+					continue
+				}
+				if runes[i] == '"' {
+					break
+				}
+				value.WriteRune(runes[i])
+			}
+			tokens = append(tokens, dotToken{value: value.String(), quoted: true})
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '>':
+			tokens = append(tokens, dotToken{value: "->"})
+			i++
+		case strings.ContainsRune("{}[];=,", r):
+			tokens = append(tokens, dotToken{value: string(r)})
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i+1 < len(runes) && (runes[i+1] == '_' || unicode.IsLetter(runes[i+1]) || unicode.IsDigit(runes[i+1])) {
+				i++
+			}
+			tokens = append(tokens, dotToken{value: string(runes[start : i+1])})
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+type dotParser struct {
+	tokens []dotToken
+	pos    int
+}
+
+func (p *dotParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	if p.tokens[p.pos].quoted {
+		return "\x00" + p.tokens[p.pos].value
+	}
+	return p.tokens[p.pos].value
+}
+
+func (p *dotParser) next() dotToken {
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *dotParser) expect(value string) error {
+	if p.peek() != value {
+		return fmt.Errorf("expected %q at token %d, got %q", value, p.pos, p.peek())
+	}
+	p.next()
+	return nil
+}
+
+// id consumes an identifier or quoted string
+func (p *dotParser) id() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("expected an ID at the end of the input")
+	}
+	token := p.tokens[p.pos]
+	if !token.quoted && (strings.ContainsAny(token.value, "{}[];=,") || token.value == "->") {
+		return "", fmt.Errorf("expected an ID at token %d, got %q", p.pos, token.value)
+	}
+	p.next()
+	return token.value, nil
+}
+
+// attributes consumes an optional [name=value, ...] list
+func (p *dotParser) attributes() error {
+	if p.peek() != "[" {
+		return nil
+	}
+	p.next()
+	for p.peek() != "]" {
+		if _, err := p.id(); err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		if _, err := p.id(); err != nil {
+			return err
+		}
+		if p.peek() == "," || p.peek() == ";" {
+			p.next()
+		}
+	}
+	p.next()
+	return nil
+}
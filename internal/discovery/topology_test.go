@@ -0,0 +1,134 @@
+package discovery
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// topologyWorkload is a workload of component whose pods carry the component label
+func topologyWorkload(name, component string) TopologyWorkload {
+	return TopologyWorkload{
+		Name:      name,
+		Component: component,
+		PodLabels: map[string]string{"app.kubernetes.io/component": component, "pod-template-hash": "abc"},
+	}
+}
+
+// componentService is a service selecting the pods of component and exposing ports
+func componentService(component string, ports ...corev1.ServicePort) corev1.Service {
+	return corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "svc-" + component},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app.kubernetes.io/component": component},
+			Ports:    ports,
+		},
+	}
+}
+
+var (
+	grpcPort = corev1.ServicePort{Name: "grpc", Port: 9095}
+	httpPort = corev1.ServicePort{Name: "http-metrics", Port: 8080}
+)
+
+func TestBuildComponentTopology(t *testing.T) {
+	workloads := []TopologyWorkload{
+		topologyWorkload("gw", "gateway"),
+		topologyWorkload("writes", "distributor"),
+		topologyWorkload("ingest-a", "ingester"),
+		topologyWorkload("ingest-b", "ingester"),
+		topologyWorkload("frontend", "query-frontend"),
+		topologyWorkload("scheduler", "query-scheduler"),
+		topologyWorkload("reads", "querier"),
+		topologyWorkload("blocks", "store-gateway"),
+		topologyWorkload("rules", "ruler"),
+		topologyWorkload("compact", "compactor"),
+	}
+	services := []corev1.Service{
+		componentService("distributor", httpPort, grpcPort),
+		componentService("ingester", grpcPort),
+		componentService("query-frontend", httpPort, grpcPort),
+		componentService("query-scheduler", grpcPort),
+		componentService("querier", grpcPort),
+		// The store-gateway service only exposes HTTP, so it cannot be reached over gRPC
+		componentService("store-gateway", httpPort),
+		componentService("ruler", httpPort),
+	}
+
+	got := BuildComponentTopology(workloads, services)
+	want := map[string][]string{
+		"gw":        {"frontend", "rules", "writes"},
+		"writes":    {"ingest-a", "ingest-b"},
+		"ingest-a":  {},
+		"ingest-b":  {},
+		"frontend":  {"scheduler"},
+		"scheduler": {"reads"},
+		"reads":     {"ingest-a", "ingest-b"},
+		"blocks":    {},
+		"rules":     {"ingest-a", "ingest-b"},
+		"compact":   {},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildComponentTopology =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestBuildComponentTopologyWithoutScheduler(t *testing.T) {
+	workloads := []TopologyWorkload{
+		topologyWorkload("frontend", "query-frontend"),
+		topologyWorkload("reads", "querier"),
+	}
+	services := []corev1.Service{componentService("querier", grpcPort)}
+
+	got := BuildComponentTopology(workloads, services)
+	if want := []string{"reads"}; !reflect.DeepEqual(got["frontend"], want) {
+		t.Errorf("query-frontend targets = %v, want %v", got["frontend"], want)
+	}
+}
+
+func TestBuildComponentTopologyWithoutServices(t *testing.T) {
+	workloads := []TopologyWorkload{
+		topologyWorkload("writes", "distributor"),
+		topologyWorkload("ingest", "ingester"),
+		topologyWorkload("reads", "querier"),
+	}
+
+	got := BuildComponentTopology(workloads, nil)
+	if want := []string{"ingest"}; !reflect.DeepEqual(got["writes"], want) {
+		t.Errorf("distributor targets = %v, want %v", got["writes"], want)
+	}
+	if want := []string{"ingest"}; !reflect.DeepEqual(got["reads"], want) {
+		t.Errorf("querier targets = %v, want %v; absent components are not targets", got["reads"], want)
+	}
+
+	// With service information but no matching services nothing is reachable
+	got = BuildComponentTopology(workloads, []corev1.Service{})
+	if len(got["writes"]) != 0 {
+		t.Errorf("distributor targets without services = %v, want none", got["writes"])
+	}
+}
+
+func TestPortProtocol(t *testing.T) {
+	grpcAppProtocol := "kubernetes.io/grpc"
+	tests := []struct {
+		name string
+		port corev1.ServicePort
+		want string
+	}{
+		{"app protocol", corev1.ServicePort{Name: "web", Port: 1234, AppProtocol: &grpcAppProtocol}, protocolGRPC},
+		{"port name", corev1.ServicePort{Name: "grpc-distributor", Port: 1234}, protocolGRPC},
+		{"http port name", corev1.ServicePort{Name: "http-metrics", Port: 1234}, protocolHTTP},
+		{"default gRPC port", corev1.ServicePort{Name: "server", Port: mimirGRPCPort}, protocolGRPC},
+		{"default HTTP port", corev1.ServicePort{Name: "server", Port: mimirHTTPPort}, protocolHTTP},
+		{"unknown port", corev1.ServicePort{Name: "memberlist", Port: 7946}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portProtocol(tt.port); got != tt.want {
+				t.Errorf("portProtocol(%+v) = %q, want %q", tt.port, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+)
+
+// Item describes how a single tenant limit compares between the primary and secondary cluster
+type Item struct {
+	LimitName        string      `json:"limit_name"`
+	PrimaryValue     interface{} `json:"primary_value"`
+	SecondaryValue   interface{} `json:"secondary_value"`
+	Delta            interface{} `json:"delta"`  // Percentage difference for numeric limits
+	Status           string      `json:"status"` // "identical", "mismatched", "primary_only", "secondary_only"
+	TenantID         string      `json:"tenant_id"`
+	ExceedsThreshold bool        `json:"exceeds_threshold"`
+}
+
+// Report is the result of comparing the overrides of both clusters
+type Report struct {
+	GeneratedAt        time.Time `json:"generated_at"`
+	PrimaryConfigMap   string    `json:"primary_configmap"`
+	SecondaryConfigMap string    `json:"secondary_configmap"`
+	ThresholdPercent   float64   `json:"threshold_percent"`
+	Items              []Item    `json:"items"`
+	IdenticalCount     int       `json:"identical_count"`
+	MismatchedCount    int       `json:"mismatched_count"`
+	PrimaryOnlyCount   int       `json:"primary_only_count"`
+	SecondaryOnlyCount int       `json:"secondary_only_count"`
+	DriftedCount       int       `json:"drifted_count"` // Items exceeding the alert threshold
+}
+
+// Drifted returns the items that exceed the alert threshold
+func (r *Report) Drifted() []Item {
+	var drifted []Item
+	for _, item := range r.Items {
+		if item.ExceedsThreshold {
+			drifted = append(drifted, item)
+		}
+	}
+	return drifted
+}
+
+// Detector compares the runtime overrides of this cluster with a secondary cluster
+type Detector struct {
+	live            *config.Live
+	primaryClient   kubernetes.Interface
+	secondaryClient kubernetes.Interface
+	log             logr.Logger
+
+	mu         sync.RWMutex
+	lastReport *Report
+}
+
+// NewDetector creates a drift detector. The secondary client is built from the
+// configured kubeconfig path, falling back to in-cluster configuration when empty.
+func NewDetector(live *config.Live, primaryClient kubernetes.Interface, log logr.Logger) (*Detector, error) {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", live.Load().Mimir.SecondaryCluster.KubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secondary cluster kubeconfig: %w", err)
+	}
+
+	secondaryClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create secondary cluster client: %w", err)
+	}
+
+	return NewDetectorWithClients(live, primaryClient, secondaryClient, log), nil
+}
+
+// NewDetectorWithClients creates a drift detector from existing clients
+func NewDetectorWithClients(live *config.Live, primaryClient, secondaryClient kubernetes.Interface, log logr.Logger) *Detector {
+	return &Detector{
+		live:            live,
+		primaryClient:   primaryClient,
+		secondaryClient: secondaryClient,
+		log:             log,
+	}
+}
+
+// config returns the configuration in effect
+func (d *Detector) config() *config.Config {
+	return d.live.Load()
+}
+
+// Check reads both ConfigMaps, computes a drift report and stores it as the latest report
+func (d *Detector) Check(ctx context.Context) (*Report, error) {
+	names, err := d.primaryConfigMapNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list primary overrides ConfigMaps: %w", err)
+	}
+	primary, err := readShardedOverrides(ctx, d.primaryClient, d.config().Mimir.Namespace, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read primary overrides: %w", err)
+	}
+
+	secondaryCfg := d.config().Mimir.SecondaryCluster
+	secondary, err := readOverrides(ctx, d.secondaryClient, secondaryCfg.Namespace, secondaryCfg.ConfigMapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secondary overrides: %w", err)
+	}
+
+	report := Compare(primary, secondary, d.config().Mimir.DriftAlertThresholdPercent)
+	report.PrimaryConfigMap = fmt.Sprintf("%s/%s", d.config().Mimir.Namespace, d.config().Mimir.ConfigMapName)
+	report.SecondaryConfigMap = fmt.Sprintf("%s/%s", secondaryCfg.Namespace, secondaryCfg.ConfigMapName)
+
+	d.mu.Lock()
+	d.lastReport = report
+	d.mu.Unlock()
+
+	d.log.V(1).Info("computed cluster drift report",
+		"items", len(report.Items),
+		"mismatched", report.MismatchedCount,
+		"drifted", report.DriftedCount)
+
+	return report, nil
+}
+
+// LastReport returns the most recent drift report, or nil if none has been computed
+func (d *Detector) LastReport() *Report {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lastReport
+}
+
+// Compare computes a drift report between two per-tenant override maps
+func Compare(primary, secondary map[string]map[string]interface{}, thresholdPercent float64) *Report {
+	report := &Report{
+		GeneratedAt:      time.Now(),
+		ThresholdPercent: thresholdPercent,
+		Items:            []Item{},
+	}
+
+	for tenant, primaryLimits := range primary {
+		secondaryLimits := secondary[tenant]
+		for limitName, primaryValue := range primaryLimits {
+			item := Item{
+				LimitName:    limitName,
+				TenantID:     tenant,
+				PrimaryValue: primaryValue,
+			}
+
+			if secondaryValue, exists := secondaryLimits[limitName]; exists {
+				item.SecondaryValue = secondaryValue
+				delta, equal := compareValues(primaryValue, secondaryValue)
+				item.Delta = delta
+				if equal {
+					item.Status = "identical"
+				} else {
+					item.Status = "mismatched"
+					item.ExceedsThreshold = delta == nil || delta.(float64) > thresholdPercent
+				}
+			} else {
+				item.Status = "primary_only"
+				item.ExceedsThreshold = true
+			}
+
+			report.Items = append(report.Items, item)
+		}
+	}
+
+	for tenant, secondaryLimits := range secondary {
+		primaryLimits := primary[tenant]
+		for limitName, secondaryValue := range secondaryLimits {
+			if _, exists := primaryLimits[limitName]; exists {
+				continue
+			}
+			report.Items = append(report.Items, Item{
+				LimitName:        limitName,
+				TenantID:         tenant,
+				SecondaryValue:   secondaryValue,
+				Status:           "secondary_only",
+				ExceedsThreshold: true,
+			})
+		}
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].TenantID != report.Items[j].TenantID {
+			return report.Items[i].TenantID < report.Items[j].TenantID
+		}
+		return report.Items[i].LimitName < report.Items[j].LimitName
+	})
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case "identical":
+			report.IdenticalCount++
+		case "mismatched":
+			report.MismatchedCount++
+		case "primary_only":
+			report.PrimaryOnlyCount++
+		case "secondary_only":
+			report.SecondaryOnlyCount++
+		}
+		if item.ExceedsThreshold {
+			report.DriftedCount++
+		}
+	}
+
+	return report
+}
+
+// compareValues reports whether two limit values are equal. For numeric values the
+// percentage difference relative to the larger magnitude is returned; for other
+// types the delta is nil.
+func compareValues(a, b interface{}) (interface{}, bool) {
+	af, aNumeric := toFloat64(a)
+	bf, bNumeric := toFloat64(b)
+	if aNumeric && bNumeric {
+		if af == bf {
+			return 0.0, true
+		}
+		base := math.Max(math.Abs(af), math.Abs(bf))
+		return math.Abs(af-bf) / base * 100, false
+	}
+
+	return nil, fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// primaryConfigMapNames returns the ConfigMaps holding the primary overrides: in the
+// sharded format the root ConfigMap followed by every tenant shard its index lists
+func (d *Detector) primaryConfigMapNames(ctx context.Context) ([]string, error) {
+	mimir := d.config().Mimir
+	if mimir.ConfigMapFormat != config.ConfigMapFormatSharded {
+		return mimir.OverridesConfigMapNames(), nil
+	}
+
+	root, err := d.primaryClient.CoreV1().ConfigMaps(mimir.Namespace).Get(ctx, mimir.ConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	index, err := patcher.ParseTenantShardIndex(root.Data[patcher.ShardIndexKey])
+	if err != nil {
+		return nil, err
+	}
+
+	shards := make([]string, 0, len(index.Shards))
+	for name := range index.Shards {
+		shards = append(shards, name)
+	}
+	sort.Strings(shards)
+	return append([]string{mimir.ConfigMapName}, shards...), nil
+}
+
+// readShardedOverrides merges the per-tenant overrides of several ConfigMaps. With a
+// single name it behaves like readOverrides; shards that do not exist yet are skipped.
+func readShardedOverrides(ctx context.Context, client kubernetes.Interface, namespace string, names []string) (map[string]map[string]interface{}, error) {
+	if len(names) == 1 {
+		return readOverrides(ctx, client, namespace, names[0])
+	}
+
+	result := make(map[string]map[string]interface{})
+	for _, name := range names {
+		shard, err := readOverrides(ctx, client, namespace, name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("shard %s: %w", name, err)
+		}
+		for tenant, limits := range shard {
+			result[tenant] = limits
+		}
+	}
+	return result, nil
+}
+
+// readOverrides reads the per-tenant overrides from a runtime overrides ConfigMap,
+// dropping the optimizer's commented metadata keys
+func readOverrides(ctx context.Context, client kubernetes.Interface, namespace, name string) (map[string]map[string]interface{}, error) {
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]interface{})
+	overridesYAML, exists := configMap.Data["overrides.yaml"]
+	if !exists {
+		return result, nil
+	}
+
+	// Either cluster may use the flat or mimir-native layout
+	overrides, _, err := patcher.ParseOverridesYAML(overridesYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	for tenant, tenantConfig := range patcher.TenantOverrides(overrides) {
+		limits, ok := tenantConfig.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result[tenant] = make(map[string]interface{})
+		for limitName, value := range limits {
+			if strings.HasPrefix(limitName, "#") {
+				continue
+			}
+			result[tenant][limitName] = value
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package drift
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func overridesConfigMap(namespace, name, overridesYAML string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Data:       map[string]string{"overrides.yaml": overridesYAML},
+	}
+}
+
+func TestCheckComputesDrift(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	cfg.Mimir.ConfigMapName = "mimir-runtime-overrides"
+	cfg.Mimir.SecondaryCluster = config.ClusterConfig{
+		Enabled:       true,
+		Namespace:     "mimir-dr",
+		ConfigMapName: "mimir-dr-overrides",
+	}
+	cfg.Mimir.DriftAlertThresholdPercent = 10
+
+	primary := fake.NewSimpleClientset(overridesConfigMap("mimir", "mimir-runtime-overrides", `overrides:
+  tenant-a:
+    "# last_updated": "2024-03-04T12:00:00Z"
+    ingestion_rate: 10000
+    max_global_series_per_user: 100000
+    ingestion_rate_strategy: global
+    ruler_max_rules_per_rule_group: 20
+  tenant-b:
+    ingestion_rate: 5000
+`))
+	// The secondary cluster uses the flat layout
+	secondary := fake.NewSimpleClientset(overridesConfigMap("mimir-dr", "mimir-dr-overrides", `tenant-a:
+  ingestion_rate: 9500
+  max_global_series_per_user: 50000
+  ingestion_rate_strategy: local
+tenant-c:
+  ingestion_burst_size: 200000
+`))
+	d := NewDetectorWithClients(config.NewLive(cfg), primary, secondary, logr.Discard())
+
+	report, err := d.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+
+	want := map[[2]string]struct {
+		status  string
+		delta   interface{}
+		drifted bool
+	}{
+		{"tenant-a", "ingestion_rate"}:                 {"mismatched", 5.0, false},
+		{"tenant-a", "max_global_series_per_user"}:     {"mismatched", 50.0, true},
+		{"tenant-a", "ingestion_rate_strategy"}:        {"mismatched", nil, true},
+		{"tenant-a", "ruler_max_rules_per_rule_group"}: {"primary_only", nil, true},
+		{"tenant-b", "ingestion_rate"}:                 {"primary_only", nil, true},
+		{"tenant-c", "ingestion_burst_size"}:           {"secondary_only", nil, true},
+	}
+	if len(report.Items) != len(want) {
+		t.Fatalf("report has %d items, want %d: %+v", len(report.Items), len(want), report.Items)
+	}
+	for _, item := range report.Items {
+		expected, ok := want[[2]string{item.TenantID, item.LimitName}]
+		if !ok {
+			t.Errorf("unexpected item %s/%s", item.TenantID, item.LimitName)
+			continue
+		}
+		if item.Status != expected.status || item.Delta != expected.delta || item.ExceedsThreshold != expected.drifted {
+			t.Errorf("%s/%s = status %q, delta %v, drifted %v; want %q, %v, %v", item.TenantID, item.LimitName,
+				item.Status, item.Delta, item.ExceedsThreshold, expected.status, expected.delta, expected.drifted)
+		}
+	}
+
+	if report.MismatchedCount != 3 || report.PrimaryOnlyCount != 2 || report.SecondaryOnlyCount != 1 || report.DriftedCount != 5 {
+		t.Errorf("counts = mismatched %d, primary only %d, secondary only %d, drifted %d; want 3, 2, 1, 5",
+			report.MismatchedCount, report.PrimaryOnlyCount, report.SecondaryOnlyCount, report.DriftedCount)
+	}
+	if report.PrimaryConfigMap != "mimir/mimir-runtime-overrides" || report.SecondaryConfigMap != "mimir-dr/mimir-dr-overrides" {
+		t.Errorf("configmaps = %q, %q", report.PrimaryConfigMap, report.SecondaryConfigMap)
+	}
+	if d.LastReport() != report {
+		t.Errorf("LastReport did not return the computed report")
+	}
+}
+
+func TestCompareIdenticalOverrides(t *testing.T) {
+	overrides := map[string]map[string]interface{}{
+		"tenant-a": {"ingestion_rate": 10000.0, "ingestion_rate_strategy": "global"},
+	}
+
+	report := Compare(overrides, overrides, 10)
+
+	if report.IdenticalCount != 2 || report.DriftedCount != 0 || len(report.Drifted()) != 0 {
+		t.Errorf("identical overrides: identical %d, drifted %d", report.IdenticalCount, report.DriftedCount)
+	}
+}
+
+func TestCheckMissingSecondaryConfigMap(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.SecondaryCluster = config.ClusterConfig{Enabled: true, Namespace: "mimir-dr", ConfigMapName: "missing"}
+	primary := fake.NewSimpleClientset(overridesConfigMap(cfg.Mimir.Namespace, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+	d := NewDetectorWithClients(config.NewLive(cfg), primary, fake.NewSimpleClientset(), logr.Discard())
+
+	if _, err := d.Check(context.Background()); err == nil {
+		t.Errorf("Check succeeded without a secondary ConfigMap")
+	}
+	if d.LastReport() != nil {
+		t.Errorf("a failed check stored a report")
+	}
+}
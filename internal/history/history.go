@@ -0,0 +1,337 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// maxConfigMapHistoryBytes keeps the stored history safely under the 1MiB ConfigMap limit
+const maxConfigMapHistoryBytes = 800 * 1024
+
+// historyDataKey is the ConfigMap key holding the recommendation history
+const historyDataKey = "history.json"
+
+// Recommendation is the limit recommended for a tenant at one point in time, with the
+// inputs it was calculated from
+type Recommendation struct {
+	Timestamp        time.Time   `json:"timestamp"`
+	ReconcileID      int64       `json:"reconcile_id"`
+	Tenant           string      `json:"tenant"`
+	Tier             string      `json:"tier,omitempty"`
+	Limit            string      `json:"limit"`
+	RecommendedValue interface{} `json:"recommended_value"`
+	CurrentValue     interface{} `json:"current_value,omitempty"`
+	PeakUsage        *float64    `json:"peak_usage,omitempty"`
+	BufferPercent    float64     `json:"buffer_percent"`
+}
+
+// Filter selects recommendations; empty fields match everything
+type Filter struct {
+	Tenant    string
+	Limit     string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// Store keeps the recommendation history of every tenant
+type Store interface {
+	// Record adds recommendations, dropping the oldest of a tenant beyond the per-tenant maximum
+	Record(ctx context.Context, recommendations []Recommendation) error
+	// Query returns the matching recommendations, oldest first
+	Query(ctx context.Context, filter Filter) ([]Recommendation, error)
+	// Prune drops recommendations recorded before olderThan
+	Prune(ctx context.Context, olderThan time.Time) error
+}
+
+// NewStore creates the history store selected by the configuration, using the same
+// storage types as the audit log
+func NewStore(cfg *config.Config, c client.Client, log logr.Logger) Store {
+	historyConfig := cfg.RecommendationHistory
+	if !historyConfig.Enabled {
+		return &NoOpStore{}
+	}
+
+	switch historyConfig.StorageType {
+	case "configmap":
+		return NewConfigMapStore(c, historyConfig.ConfigMapName, cfg.Mimir.Namespace, historyConfig.MaxEntriesPerTenant, log)
+	case "memory":
+		return NewMemoryStore(historyConfig.MaxEntriesPerTenant)
+	default:
+		log.Info("unknown recommendation history storage type, using memory", "type", historyConfig.StorageType)
+		return NewMemoryStore(historyConfig.MaxEntriesPerTenant)
+	}
+}
+
+// MemoryStore keeps the recommendation history in memory
+type MemoryStore struct {
+	mu                  sync.RWMutex
+	maxEntriesPerTenant int
+	tenants             map[string][]Recommendation
+}
+
+// NewMemoryStore creates an in-memory history store
+func NewMemoryStore(maxEntriesPerTenant int) *MemoryStore {
+	return &MemoryStore{
+		maxEntriesPerTenant: maxEntriesPerTenant,
+		tenants:             make(map[string][]Recommendation),
+	}
+}
+
+func (m *MemoryStore) Record(ctx context.Context, recommendations []Recommendation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, recommendation := range recommendations {
+		m.tenants[recommendation.Tenant] = append(m.tenants[recommendation.Tenant], recommendation)
+	}
+	for tenant, entries := range m.tenants {
+		m.tenants[tenant] = capEntries(entries, m.maxEntriesPerTenant)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Query(ctx context.Context, filter Filter) ([]Recommendation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var candidates []Recommendation
+	if filter.Tenant != "" {
+		candidates = m.tenants[filter.Tenant]
+	} else {
+		for _, entries := range m.tenants {
+			candidates = append(candidates, entries...)
+		}
+	}
+	return selectEntries(candidates, filter), nil
+}
+
+func (m *MemoryStore) Prune(ctx context.Context, olderThan time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for tenant, entries := range m.tenants {
+		kept := pruneEntries(entries, olderThan)
+		if len(kept) == 0 {
+			delete(m.tenants, tenant)
+			continue
+		}
+		m.tenants[tenant] = kept
+	}
+	return nil
+}
+
+// ConfigMapStore keeps the recommendation history in a ConfigMap, so it survives restarts
+type ConfigMapStore struct {
+	client              client.Client
+	configMapName       string
+	namespace           string
+	maxEntriesPerTenant int
+	log                 logr.Logger
+}
+
+// NewConfigMapStore creates a ConfigMap-backed history store
+func NewConfigMapStore(c client.Client, configMapName, namespace string, maxEntriesPerTenant int, log logr.Logger) *ConfigMapStore {
+	return &ConfigMapStore{
+		client:              c,
+		configMapName:       configMapName,
+		namespace:           namespace,
+		maxEntriesPerTenant: maxEntriesPerTenant,
+		log:                 log,
+	}
+}
+
+func (c *ConfigMapStore) Record(ctx context.Context, recommendations []Recommendation) error {
+	if len(recommendations) == 0 {
+		return nil
+	}
+	return c.update(ctx, func(entries []Recommendation) []Recommendation {
+		entries = append(entries, recommendations...)
+
+		byTenant := make(map[string][]Recommendation)
+		for _, entry := range entries {
+			byTenant[entry.Tenant] = append(byTenant[entry.Tenant], entry)
+		}
+		capped := make([]Recommendation, 0, len(entries))
+		for _, tenantEntries := range byTenant {
+			capped = append(capped, capEntries(tenantEntries, c.maxEntriesPerTenant)...)
+		}
+		return capped
+	})
+}
+
+func (c *ConfigMapStore) Query(ctx context.Context, filter Filter) ([]Recommendation, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.client.Get(ctx, types.NamespacedName{Name: c.configMapName, Namespace: c.namespace}, configMap)
+	if apierrors.IsNotFound(err) {
+		return []Recommendation{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendation history ConfigMap: %w", err)
+	}
+
+	entries, err := parseEntries(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return selectEntries(entries, filter), nil
+}
+
+func (c *ConfigMapStore) Prune(ctx context.Context, olderThan time.Time) error {
+	return c.update(ctx, func(entries []Recommendation) []Recommendation {
+		return pruneEntries(entries, olderThan)
+	})
+}
+
+// update rewrites the stored history with the result of change, retrying on conflicts.
+// The oldest recommendations are dropped while the history exceeds the size limit.
+func (c *ConfigMapStore) update(ctx context.Context, change func([]Recommendation) []Recommendation) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := c.client.Get(ctx, types.NamespacedName{Name: c.configMapName, Namespace: c.namespace}, configMap)
+		create := apierrors.IsNotFound(err)
+		if err != nil && !create {
+			return fmt.Errorf("failed to get recommendation history ConfigMap: %w", err)
+		}
+
+		var entries []Recommendation
+		if !create {
+			if entries, err = parseEntries(configMap); err != nil {
+				c.log.Error(err, "failed to parse recommendation history, starting a new one")
+				entries = nil
+			}
+		}
+
+		entries = change(entries)
+		sortEntries(entries)
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("failed to marshal recommendation history: %w", err)
+		}
+		for len(data) > maxConfigMapHistoryBytes && len(entries) > 0 {
+			// Drop about a tenth of the oldest entries per pass instead of re-marshalling per entry
+			drop := len(entries)/10 + 1
+			entries = entries[drop:]
+			if data, err = json.Marshal(entries); err != nil {
+				return fmt.Errorf("failed to marshal recommendation history: %w", err)
+			}
+		}
+
+		if create {
+			if len(entries) == 0 {
+				return nil
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      c.configMapName,
+					Namespace: c.namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       "mimir-limit-optimizer",
+						"app.kubernetes.io/component":  "recommendation-history",
+						"app.kubernetes.io/managed-by": "mimir-limit-optimizer",
+					},
+				},
+				Data: map[string]string{historyDataKey: string(data)},
+			}
+			return c.client.Create(ctx, configMap)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		if configMap.Data[historyDataKey] == string(data) {
+			return nil
+		}
+		configMap.Data[historyDataKey] = string(data)
+		return c.client.Update(ctx, configMap)
+	})
+}
+
+func parseEntries(configMap *corev1.ConfigMap) ([]Recommendation, error) {
+	data, exists := configMap.Data[historyDataKey]
+	if !exists || data == "" {
+		return nil, nil
+	}
+
+	var entries []Recommendation
+	if err := json.Unmarshal([]byte(data), &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recommendation history: %w", err)
+	}
+	return entries, nil
+}
+
+// NoOpStore discards recommendations when the history is disabled
+type NoOpStore struct{}
+
+func (n *NoOpStore) Record(ctx context.Context, recommendations []Recommendation) error { return nil }
+func (n *NoOpStore) Query(ctx context.Context, filter Filter) ([]Recommendation, error) {
+	return []Recommendation{}, nil
+}
+func (n *NoOpStore) Prune(ctx context.Context, olderThan time.Time) error { return nil }
+
+// capEntries keeps the newest maxEntries of one tenant's recommendations
+func capEntries(entries []Recommendation, maxEntries int) []Recommendation {
+	if maxEntries <= 0 || len(entries) <= maxEntries {
+		return entries
+	}
+	sortEntries(entries)
+	return append([]Recommendation(nil), entries[len(entries)-maxEntries:]...)
+}
+
+// pruneEntries drops the recommendations recorded before olderThan
+func pruneEntries(entries []Recommendation, olderThan time.Time) []Recommendation {
+	kept := entries[:0:0]
+	for _, entry := range entries {
+		if !entry.Timestamp.Before(olderThan) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// selectEntries returns copies of the matching recommendations, oldest first
+func selectEntries(entries []Recommendation, filter Filter) []Recommendation {
+	selected := make([]Recommendation, 0, len(entries))
+	for _, entry := range entries {
+		if filter.Tenant != "" && entry.Tenant != filter.Tenant {
+			continue
+		}
+		if filter.Limit != "" && entry.Limit != filter.Limit {
+			continue
+		}
+		if filter.StartTime != nil && entry.Timestamp.Before(*filter.StartTime) {
+			continue
+		}
+		if filter.EndTime != nil && entry.Timestamp.After(*filter.EndTime) {
+			continue
+		}
+		selected = append(selected, entry)
+	}
+	sortEntries(selected)
+	return selected
+}
+
+func sortEntries(entries []Recommendation) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		}
+		if entries[i].Tenant != entries[j].Tenant {
+			return entries[i].Tenant < entries[j].Tenant
+		}
+		return entries[i].Limit < entries[j].Limit
+	})
+}
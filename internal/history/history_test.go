@@ -0,0 +1,301 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var historyStart = time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+// recommendation is a recommendation of tenant for limit, recorded hours after historyStart
+func recommendation(tenant, limit string, hours int, value float64) Recommendation {
+	return Recommendation{
+		Timestamp:        historyStart.Add(time.Duration(hours) * time.Hour),
+		ReconcileID:      int64(hours),
+		Tenant:           tenant,
+		Limit:            limit,
+		RecommendedValue: value,
+		BufferPercent:    20,
+	}
+}
+
+// stores returns every store kind, each keeping at most maxEntriesPerTenant per tenant
+func stores(maxEntriesPerTenant int) map[string]func() Store {
+	return map[string]func() Store{
+		"memory": func() Store { return NewMemoryStore(maxEntriesPerTenant) },
+		"configmap": func() Store {
+			return NewConfigMapStore(fake.NewClientBuilder().Build(), "recommendation-history", "mimir", maxEntriesPerTenant, logr.Discard())
+		},
+	}
+}
+
+// describe returns the tenant, limit and hour of each recommendation
+func describe(entries []Recommendation) []string {
+	described := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		described = append(described, entry.Tenant+"/"+entry.Limit+"@"+entry.Timestamp.Sub(historyStart).String())
+	}
+	return described
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStoreQuery(t *testing.T) {
+	recommendations := []Recommendation{
+		recommendation("tenant-b", "ingestion_rate", 3, 3000),
+		recommendation("tenant-a", "ingestion_rate", 2, 2000),
+		recommendation("tenant-a", "max_global_series_per_user", 1, 50000),
+		recommendation("tenant-a", "ingestion_rate", 0, 1000),
+		recommendation("tenant-b", "max_global_series_per_user", 4, 60000),
+	}
+	hour := func(hours int) *time.Time {
+		at := historyStart.Add(time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name:   "everything, oldest first",
+			filter: Filter{},
+			want: []string{
+				"tenant-a/ingestion_rate@0s",
+				"tenant-a/max_global_series_per_user@1h0m0s",
+				"tenant-a/ingestion_rate@2h0m0s",
+				"tenant-b/ingestion_rate@3h0m0s",
+				"tenant-b/max_global_series_per_user@4h0m0s",
+			},
+		},
+		{
+			name:   "tenant",
+			filter: Filter{Tenant: "tenant-b"},
+			want:   []string{"tenant-b/ingestion_rate@3h0m0s", "tenant-b/max_global_series_per_user@4h0m0s"},
+		},
+		{
+			name:   "limit",
+			filter: Filter{Limit: "ingestion_rate"},
+			want:   []string{"tenant-a/ingestion_rate@0s", "tenant-a/ingestion_rate@2h0m0s", "tenant-b/ingestion_rate@3h0m0s"},
+		},
+		{
+			name:   "tenant and limit",
+			filter: Filter{Tenant: "tenant-a", Limit: "ingestion_rate"},
+			want:   []string{"tenant-a/ingestion_rate@0s", "tenant-a/ingestion_rate@2h0m0s"},
+		},
+		{
+			name:   "time range includes both ends",
+			filter: Filter{StartTime: hour(1), EndTime: hour(3)},
+			want: []string{
+				"tenant-a/max_global_series_per_user@1h0m0s",
+				"tenant-a/ingestion_rate@2h0m0s",
+				"tenant-b/ingestion_rate@3h0m0s",
+			},
+		},
+		{
+			name:   "start time only",
+			filter: Filter{Tenant: "tenant-a", StartTime: hour(2)},
+			want:   []string{"tenant-a/ingestion_rate@2h0m0s"},
+		},
+		{
+			name:   "unknown tenant",
+			filter: Filter{Tenant: "tenant-c"},
+			want:   []string{},
+		},
+	}
+
+	for kind, newStore := range stores(0) {
+		store := newStore()
+		if err := store.Record(context.Background(), recommendations); err != nil {
+			t.Fatalf("%s: Record: %v", kind, err)
+		}
+		for _, tt := range tests {
+			got, err := store.Query(context.Background(), tt.filter)
+			if err != nil {
+				t.Errorf("%s %s: Query: %v", kind, tt.name, err)
+				continue
+			}
+			if !equalStrings(describe(got), tt.want) {
+				t.Errorf("%s %s: Query = %v, want %v", kind, tt.name, describe(got), tt.want)
+			}
+		}
+	}
+}
+
+func TestStoreCapsEntriesPerTenant(t *testing.T) {
+	for kind, newStore := range stores(2) {
+		store := newStore()
+		ctx := context.Background()
+		// Recorded across two reconciles, out of order within the first
+		if err := store.Record(ctx, []Recommendation{
+			recommendation("tenant-a", "ingestion_rate", 1, 1000),
+			recommendation("tenant-a", "ingestion_rate", 0, 900),
+			recommendation("tenant-b", "ingestion_rate", 0, 500),
+		}); err != nil {
+			t.Fatalf("%s: Record: %v", kind, err)
+		}
+		if err := store.Record(ctx, []Recommendation{
+			recommendation("tenant-a", "ingestion_rate", 2, 1100),
+		}); err != nil {
+			t.Fatalf("%s: Record: %v", kind, err)
+		}
+
+		got, err := store.Query(ctx, Filter{})
+		if err != nil {
+			t.Fatalf("%s: Query: %v", kind, err)
+		}
+		want := []string{
+			"tenant-b/ingestion_rate@0s",
+			"tenant-a/ingestion_rate@1h0m0s",
+			"tenant-a/ingestion_rate@2h0m0s",
+		}
+		if !equalStrings(describe(got), want) {
+			t.Errorf("%s: entries = %v, want the newest 2 of tenant-a and tenant-b's %v", kind, describe(got), want)
+		}
+	}
+}
+
+func TestStorePrune(t *testing.T) {
+	for kind, newStore := range stores(0) {
+		store := newStore()
+		ctx := context.Background()
+		if err := store.Record(ctx, []Recommendation{
+			recommendation("tenant-a", "ingestion_rate", 0, 1000),
+			recommendation("tenant-a", "ingestion_rate", 24, 1100),
+			recommendation("tenant-b", "ingestion_rate", 1, 500),
+			recommendation("tenant-c", "ingestion_rate", 48, 700),
+		}); err != nil {
+			t.Fatalf("%s: Record: %v", kind, err)
+		}
+
+		// The cutoff itself is kept
+		if err := store.Prune(ctx, historyStart.Add(24*time.Hour)); err != nil {
+			t.Fatalf("%s: Prune: %v", kind, err)
+		}
+
+		got, err := store.Query(ctx, Filter{})
+		if err != nil {
+			t.Fatalf("%s: Query: %v", kind, err)
+		}
+		want := []string{"tenant-a/ingestion_rate@24h0m0s", "tenant-c/ingestion_rate@48h0m0s"}
+		if !equalStrings(describe(got), want) {
+			t.Errorf("%s: entries after prune = %v, want %v", kind, describe(got), want)
+		}
+		if got, _ := store.Query(ctx, Filter{Tenant: "tenant-b"}); len(got) != 0 {
+			t.Errorf("%s: tenant-b entries after prune = %v, want none", kind, describe(got))
+		}
+	}
+}
+
+func TestConfigMapStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().Build()
+	peak := 1800.5
+	recorded := recommendation("tenant-a", "ingestion_rate", 5, 2000)
+	recorded.Tier = "gold"
+	recorded.CurrentValue = 1500.0
+	recorded.PeakUsage = &peak
+
+	writer := NewConfigMapStore(c, "recommendation-history", "mimir", 10, logr.Discard())
+	if _, err := writer.Query(ctx, Filter{}); err != nil {
+		t.Fatalf("Query before the ConfigMap exists: %v", err)
+	}
+	if err := writer.Record(ctx, []Recommendation{recorded}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "recommendation-history", Namespace: "mimir"}, configMap); err != nil {
+		t.Fatalf("get history ConfigMap: %v", err)
+	}
+	var stored []Recommendation
+	if err := json.Unmarshal([]byte(configMap.Data[historyDataKey]), &stored); err != nil {
+		t.Fatalf("stored history is not JSON: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("stored %d entries, want 1", len(stored))
+	}
+
+	// A store created later, as after a restart, reads the same history back
+	reader := NewConfigMapStore(c, "recommendation-history", "mimir", 10, logr.Discard())
+	got, err := reader.Query(ctx, Filter{Tenant: "tenant-a"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Query returned %d entries, want 1", len(got))
+	}
+	entry := got[0]
+	if !entry.Timestamp.Equal(recorded.Timestamp) || entry.ReconcileID != recorded.ReconcileID ||
+		entry.Tier != "gold" || entry.Limit != "ingestion_rate" || entry.BufferPercent != 20 {
+		t.Errorf("read back %+v, want %+v", entry, recorded)
+	}
+	if entry.RecommendedValue != 2000.0 || entry.CurrentValue != 1500.0 {
+		t.Errorf("values = %v, %v, want 2000, 1500", entry.RecommendedValue, entry.CurrentValue)
+	}
+	if entry.PeakUsage == nil || *entry.PeakUsage != peak {
+		t.Errorf("PeakUsage = %v, want %v", entry.PeakUsage, peak)
+	}
+}
+
+func TestConfigMapStoreUnparsableHistory(t *testing.T) {
+	ctx := context.Background()
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = "recommendation-history"
+	configMap.Namespace = "mimir"
+	configMap.Data = map[string]string{historyDataKey: "not json"}
+	c := fake.NewClientBuilder().WithObjects(configMap).Build()
+
+	store := NewConfigMapStore(c, "recommendation-history", "mimir", 10, logr.Discard())
+	if _, err := store.Query(ctx, Filter{}); err == nil {
+		t.Error("Query of an unparsable history succeeded, want an error")
+	}
+
+	// Recording starts a new history instead of failing every reconcile
+	if err := store.Record(ctx, []Recommendation{recommendation("tenant-a", "ingestion_rate", 0, 1000)}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	got, err := store.Query(ctx, Filter{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Query returned %d entries, want 1", len(got))
+	}
+}
+
+func TestConfigMapStorePruneWithoutHistory(t *testing.T) {
+	c := fake.NewClientBuilder().Build()
+	store := NewConfigMapStore(c, "recommendation-history", "mimir", 10, logr.Discard())
+	if err := store.Prune(context.Background(), historyStart); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	// Pruning an absent history does not create an empty ConfigMap
+	configMaps := &corev1.ConfigMapList{}
+	if err := c.List(context.Background(), configMaps, client.InNamespace("mimir")); err != nil {
+		t.Fatalf("list ConfigMaps: %v", err)
+	}
+	if len(configMaps.Items) != 0 {
+		t.Errorf("ConfigMaps = %d, want none", len(configMaps.Items))
+	}
+}
@@ -0,0 +1,164 @@
+package locking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LeaseLock is a distributed lock backed by a coordination.k8s.io/v1 Lease. It
+// guards ConfigMap writes when several optimizer replicas run without leader election.
+type LeaseLock struct {
+	client        kubernetes.Interface
+	namespace     string
+	name          string
+	identity      string
+	ttl           time.Duration
+	retryInterval time.Duration
+	log           logr.Logger
+}
+
+// NewLeaseLock creates a lease lock held under the given identity
+func NewLeaseLock(client kubernetes.Interface, namespace, name, identity string, ttl, retryInterval time.Duration, log logr.Logger) *LeaseLock {
+	return &LeaseLock{
+		client:        client,
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		ttl:           ttl,
+		retryInterval: retryInterval,
+		log:           log,
+	}
+}
+
+// Identity returns the holder identity written to the lease
+func (l *LeaseLock) Identity() string {
+	return l.identity
+}
+
+// Acquire tries to take the lease until it succeeds or the timeout elapses. It
+// returns false without an error when another holder kept the lease.
+func (l *LeaseLock) Acquire(ctx context.Context, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		acquired, err := l.tryAcquire(ctx)
+		if err != nil {
+			l.log.V(1).Info("failed to acquire lease", "lease", l.name, "error", err)
+		}
+		if acquired {
+			return true, nil
+		}
+
+		if time.Now().Add(l.retryInterval).After(deadline) {
+			return false, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// Release gives up the lease if it is still held by this identity
+func (l *LeaseLock) Release(ctx context.Context) error {
+	lease, err := l.client.CoordinationV1().Leases(l.namespace).Get(ctx, l.name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get lease %s/%s: %w", l.namespace, l.name, err)
+	}
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.identity {
+		return nil
+	}
+
+	lease.Spec.HolderIdentity = nil
+	lease.Spec.AcquireTime = nil
+	lease.Spec.RenewTime = nil
+	if _, err := l.client.CoordinationV1().Leases(l.namespace).Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to release lease %s/%s: %w", l.namespace, l.name, err)
+	}
+
+	l.log.V(1).Info("released lease", "lease", l.name, "identity", l.identity)
+	return nil
+}
+
+// tryAcquire makes a single attempt to create or take over the lease. Updates rely
+// on the lease resourceVersion, so a concurrent writer loses with a conflict.
+func (l *LeaseLock) tryAcquire(ctx context.Context) (bool, error) {
+	now := metav1.NewMicroTime(time.Now())
+	leaseSeconds := int32(l.ttl.Seconds())
+
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	lease, err := leases.Get(ctx, l.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      l.name,
+				Namespace: l.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       "mimir-limit-optimizer",
+					"app.kubernetes.io/managed-by": "mimir-limit-optimizer",
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &l.identity,
+				LeaseDurationSeconds: &leaseSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to create lease %s/%s: %w", l.namespace, l.name, err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get lease %s/%s: %w", l.namespace, l.name, err)
+	}
+
+	if !l.canTakeOver(lease, now.Time) {
+		return false, nil
+	}
+
+	lease.Spec.HolderIdentity = &l.identity
+	lease.Spec.LeaseDurationSeconds = &leaseSeconds
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		if apierrors.IsConflict(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update lease %s/%s: %w", l.namespace, l.name, err)
+	}
+
+	return true, nil
+}
+
+// canTakeOver reports whether the lease is free, expired or already ours
+func (l *LeaseLock) canTakeOver(lease *coordinationv1.Lease, now time.Time) bool {
+	holder := lease.Spec.HolderIdentity
+	if holder == nil || *holder == "" || *holder == l.identity {
+		return true
+	}
+
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.After(expiry)
+}
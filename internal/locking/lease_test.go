@@ -0,0 +1,179 @@
+package locking
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newLeaseClient returns a fake clientset that rejects lease updates carrying a stale
+// resourceVersion, as the API server does; the fake tracker alone accepts them
+func newLeaseClient() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	leases := coordinationv1.SchemeGroupVersion.WithResource("leases")
+	version := 0
+
+	client.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		version++
+		action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease).ResourceVersion = strconv.Itoa(version)
+		return false, nil, nil
+	})
+	client.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.UpdateAction).GetObject().(*coordinationv1.Lease)
+		current, err := client.Tracker().Get(leases, lease.Namespace, lease.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		if current.(*coordinationv1.Lease).ResourceVersion != lease.ResourceVersion {
+			return true, nil, apierrors.NewConflict(leases.GroupResource(), lease.Name, errors.New("the object has been modified"))
+		}
+		version++
+		lease.ResourceVersion = strconv.Itoa(version)
+		return false, nil, nil
+	})
+	return client
+}
+
+func newTestLock(client *fake.Clientset, identity string) *LeaseLock {
+	return NewLeaseLock(client, "mimir", "mimir-limit-optimizer-write", identity, 30*time.Second, 10*time.Millisecond, logr.Discard())
+}
+
+// TestConcurrentReconcilesWriteOnce simulates two replicas reconciling at the same time:
+// the holder keeps the lease past the other's acquisition timeout, so only one writes
+func TestConcurrentReconcilesWriteOnce(t *testing.T) {
+	client := newLeaseClient()
+	var writes atomic.Int32
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for _, identity := range []string{"replica-a", "replica-b"} {
+		lock := newTestLock(client, identity)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			acquired, err := lock.Acquire(context.Background(), 200*time.Millisecond)
+			if err != nil {
+				t.Errorf("%s: Acquire: %v", lock.Identity(), err)
+			}
+			if !acquired {
+				return
+			}
+			defer func() {
+				if err := lock.Release(context.Background()); err != nil {
+					t.Errorf("%s: Release: %v", lock.Identity(), err)
+				}
+			}()
+			writes.Add(1)
+			time.Sleep(400 * time.Millisecond)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := writes.Load(); got != 1 {
+		t.Errorf("%d replicas wrote, want 1", got)
+	}
+}
+
+// TestConcurrentHoldersExclusive is meant for go test -race: replicas repeatedly take
+// and release the lease, and never hold it at the same time
+func TestConcurrentHoldersExclusive(t *testing.T) {
+	client := newLeaseClient()
+	var holders, overlaps, writes atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		lock := newTestLock(client, "replica-"+strconv.Itoa(i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 5; round++ {
+				acquired, err := lock.Acquire(context.Background(), 2*time.Second)
+				if err != nil || !acquired {
+					t.Errorf("%s: Acquire = %v, %v", lock.Identity(), acquired, err)
+					return
+				}
+				if holders.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				writes.Add(1)
+				time.Sleep(time.Millisecond)
+				holders.Add(-1)
+				if err := lock.Release(context.Background()); err != nil {
+					t.Errorf("%s: Release: %v", lock.Identity(), err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := overlaps.Load(); got != 0 {
+		t.Errorf("the lease was held by several replicas %d times", got)
+	}
+	if got := writes.Load(); got != 20 {
+		t.Errorf("%d writes, want 20", got)
+	}
+}
+
+func TestAcquireTimesOutWhileHeld(t *testing.T) {
+	client := newLeaseClient()
+	holder, other := newTestLock(client, "replica-a"), newTestLock(client, "replica-b")
+	ctx := context.Background()
+
+	if acquired, err := holder.Acquire(ctx, time.Second); !acquired || err != nil {
+		t.Fatalf("holder Acquire = %v, %v", acquired, err)
+	}
+	if acquired, err := other.Acquire(ctx, 50*time.Millisecond); acquired || err != nil {
+		t.Errorf("Acquire of a held lease = %v, %v; want false without an error", acquired, err)
+	}
+	// Releasing a lease held by another replica leaves it in place
+	if err := other.Release(ctx); err != nil {
+		t.Fatalf("other Release: %v", err)
+	}
+	if acquired, _ := other.Acquire(ctx, 50*time.Millisecond); acquired {
+		t.Errorf("lease was taken after a release by a replica not holding it")
+	}
+
+	if err := holder.Release(ctx); err != nil {
+		t.Fatalf("holder Release: %v", err)
+	}
+	if acquired, err := other.Acquire(ctx, time.Second); !acquired || err != nil {
+		t.Errorf("Acquire after release = %v, %v", acquired, err)
+	}
+}
+
+func TestAcquireTakesOverExpiredLease(t *testing.T) {
+	client := newLeaseClient()
+	holder := "crashed-replica"
+	leaseSeconds := int32(30)
+	renewed := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	_, err := client.CoordinationV1().Leases("mimir").Create(context.Background(), &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "mimir-limit-optimizer-write", Namespace: "mimir"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &leaseSeconds,
+			RenewTime:            &renewed,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("create lease: %v", err)
+	}
+
+	lock := newTestLock(client, "replica-a")
+	if acquired, err := lock.Acquire(context.Background(), time.Second); !acquired || err != nil {
+		t.Errorf("Acquire of an expired lease = %v, %v", acquired, err)
+	}
+}
@@ -0,0 +1,388 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DashboardUID is the UID of the generated Grafana dashboard, so provisioning
+	// replaces it rather than adding a copy
+	DashboardUID = "mimir-limit-optimizer"
+
+	// dashboardPanelWidth and dashboardPanelHeight size the panels two per row on
+	// Grafana's 24-column grid
+	dashboardPanelWidth  = 12
+	dashboardPanelHeight = 8
+)
+
+// descPattern extracts the name, help and variable labels from prometheus.Desc.String,
+// the only way the client library exposes them
+var descPattern = regexp.MustCompile(`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{.*\}, variableLabels: \{(.*)\}\}$`)
+
+// dashboardMetric is a registered metric charted on the dashboard
+type dashboardMetric struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+type grafanaDashboard struct {
+	UID           string            `json:"uid"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Tags          []string          `json:"tags"`
+	Editable      bool              `json:"editable"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Refresh       string            `json:"refresh"`
+	Time          grafanaTimeRange  `json:"time"`
+	Templating    grafanaTemplating `json:"templating"`
+	Panels        []grafanaPanel    `json:"panels"`
+}
+
+type grafanaTimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type grafanaTemplating struct {
+	List []grafanaVariable `json:"list"`
+}
+
+type grafanaVariable struct {
+	Name       string                 `json:"name"`
+	Label      string                 `json:"label"`
+	Type       string                 `json:"type"`
+	Query      interface{}            `json:"query"`
+	Datasource *grafanaDatasource     `json:"datasource,omitempty"`
+	Multi      bool                   `json:"multi,omitempty"`
+	IncludeAll bool                   `json:"includeAll,omitempty"`
+	AllValue   string                 `json:"allValue,omitempty"`
+	Refresh    int                    `json:"refresh,omitempty"`
+	Current    map[string]interface{} `json:"current,omitempty"`
+}
+
+type grafanaDatasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type grafanaPanel struct {
+	ID          int                 `json:"id"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	GridPos     grafanaGridPos      `json:"gridPos"`
+	Datasource  *grafanaDatasource  `json:"datasource,omitempty"`
+	Targets     []grafanaTarget     `json:"targets,omitempty"`
+	FieldConfig *grafanaFieldConfig `json:"fieldConfig,omitempty"`
+	Collapsed   bool                `json:"collapsed,omitempty"`
+}
+
+type grafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type grafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+type grafanaFieldConfig struct {
+	Defaults grafanaFieldDefaults `json:"defaults"`
+}
+
+type grafanaFieldDefaults struct {
+	Unit string `json:"unit"`
+}
+
+// dashboardDatasource is the datasource variable every panel queries
+var dashboardDatasource = &grafanaDatasource{Type: "prometheus", UID: "${datasource}"}
+
+// GenerateGrafanaDashboard returns a Grafana dashboard charting the optimizer: an
+// overview of tenant ingestion, limit buffer utilization, reconcile duration, circuit
+// breaker transitions, cost estimates and audit log size, followed by a panel for
+// every metric registered by RegisterMetrics
+func GenerateGrafanaDashboard() ([]byte, error) {
+	registered, err := dashboardMetrics(registeredCollectors())
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := grafanaDashboard{
+		UID:           DashboardUID,
+		Title:         "Mimir Limit Optimizer",
+		Description:   "Generated by mimir-limit-optimizer from its registered metrics",
+		Tags:          []string{"mimir", "mimir-limit-optimizer"},
+		Editable:      true,
+		SchemaVersion: 38,
+		Refresh:       "1m",
+		Time:          grafanaTimeRange{From: "now-6h", To: "now"},
+		Templating: grafanaTemplating{List: []grafanaVariable{
+			{
+				Name:    "datasource",
+				Label:   "Data source",
+				Type:    "datasource",
+				Query:   "prometheus",
+				Current: map[string]interface{}{},
+			},
+			{
+				Name:       "tenant",
+				Label:      "Tenant",
+				Type:       "query",
+				Datasource: dashboardDatasource,
+				Query:      "label_values(mimir_limit_optimizer_tenant_current_limits, tenant)",
+				Multi:      true,
+				IncludeAll: true,
+				AllValue:   ".*",
+				Refresh:    2,
+				Current:    map[string]interface{}{"text": "All", "value": "$__all"},
+			},
+		}},
+	}
+
+	layout := &dashboardLayout{}
+	dashboard.Panels = append(dashboard.Panels, layout.row("Overview"))
+	for _, panel := range overviewPanels() {
+		dashboard.Panels = append(dashboard.Panels, layout.place(panel))
+	}
+
+	dashboard.Panels = append(dashboard.Panels, layout.row("All metrics"))
+	for _, metric := range registered {
+		dashboard.Panels = append(dashboard.Panels, layout.place(metricPanel(metric)))
+	}
+
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Grafana dashboard: %w", err)
+	}
+	return data, nil
+}
+
+// overviewPanels chart the questions operators ask of the optimizer first
+func overviewPanels() []grafanaPanel {
+	return []grafanaPanel{
+		timeseriesPanel("Ingestion rate per tenant",
+			"Ingestion rate percentile the limits are calculated from",
+			"samples/s",
+			grafanaTarget{
+				Expr:         `max by (tenant) (mimir_limit_optimizer_tenant_usage_percentile{tenant=~"$tenant", metric_type="cortex_distributor_received_samples_total"})`,
+				LegendFormat: "{{tenant}}",
+			}),
+		timeseriesPanel("Limit buffer utilization",
+			"Ingestion rate percentile as a share of the applied ingestion_rate limit",
+			"percent",
+			grafanaTarget{
+				Expr: `100 * max by (tenant) (mimir_limit_optimizer_tenant_usage_percentile{tenant=~"$tenant", metric_type="cortex_distributor_received_samples_total"})` +
+					` / max by (tenant) (mimir_limit_optimizer_tenant_current_limits{tenant=~"$tenant", limit_type="ingestion_rate"})`,
+				LegendFormat: "{{tenant}}",
+			}),
+		timeseriesPanel("Reconcile duration",
+			"95th and 50th percentile reconcile duration",
+			"s",
+			grafanaTarget{
+				Expr:         `histogram_quantile(0.95, sum by (le) (rate(mimir_limit_optimizer_reconcile_duration_seconds_bucket[$__rate_interval])))`,
+				LegendFormat: "p95",
+			},
+			grafanaTarget{
+				Expr:         `histogram_quantile(0.5, sum by (le) (rate(mimir_limit_optimizer_reconcile_duration_seconds_bucket[$__rate_interval])))`,
+				LegendFormat: "p50",
+			}),
+		timeseriesPanel("Circuit breaker state transitions",
+			"Blast protection circuit breaker transitions",
+			"short",
+			grafanaTarget{
+				Expr:         `sum by (from, to) (increase(mimir_limit_optimizer_circuit_breaker_transitions_total[$__rate_interval]))`,
+				LegendFormat: "{{from}} → {{to}}",
+			}),
+		timeseriesPanel("Projected monthly cost",
+			"Monthly cost projected from each tenant's average usage",
+			"currencyUSD",
+			grafanaTarget{
+				Expr:         `sum by (tenant) (mimir_cost_estimate_monthly_usd{tenant=~"$tenant"})`,
+				LegendFormat: "{{tenant}}",
+			}),
+		timeseriesPanel("Audit log size",
+			"Entries kept in the audit log",
+			"short",
+			grafanaTarget{
+				Expr:         `sum by (storage) (mimir_limit_optimizer_audit_log_entries)`,
+				LegendFormat: "{{storage}}",
+			}),
+	}
+}
+
+// metricPanel charts a registered metric: counters as rates, histograms as their 95th
+// percentile and gauges as they are, by their labels
+func metricPanel(metric dashboardMetric) grafanaPanel {
+	selector := metric.name
+	for _, label := range metric.labels {
+		if label == "tenant" {
+			selector += `{tenant=~"$tenant"}`
+		}
+	}
+
+	legend := metric.name
+	if len(metric.labels) > 0 {
+		parts := make([]string, len(metric.labels))
+		for i, label := range metric.labels {
+			parts[i] = "{{" + label + "}}"
+		}
+		legend = strings.Join(parts, " ")
+	}
+
+	var expr, unit string
+	switch metric.kind {
+	case "counter":
+		expr = aggregate("sum", metric.labels, fmt.Sprintf("rate(%s[$__rate_interval])", selector))
+		unit = "ops"
+	case "histogram":
+		bucket := strings.Replace(selector, metric.name, metric.name+"_bucket", 1)
+		expr = fmt.Sprintf("histogram_quantile(0.95, %s)",
+			aggregate("sum", append([]string{"le"}, metric.labels...), fmt.Sprintf("rate(%s[$__rate_interval])", bucket)))
+		unit = "short"
+		if strings.HasSuffix(metric.name, "_seconds") {
+			unit = "s"
+		}
+	default:
+		expr = aggregate("sum", metric.labels, selector)
+		unit = "short"
+		if strings.HasSuffix(metric.name, "_bytes") {
+			unit = "bytes"
+		} else if strings.HasSuffix(metric.name, "_timestamp") || strings.HasSuffix(metric.name, "_timestamp_seconds") {
+			unit = "dateTimeFromNow"
+			expr = aggregate("max", metric.labels, selector) + " * 1000"
+		}
+	}
+	return timeseriesPanel(metric.name, metric.help, unit, grafanaTarget{Expr: expr, LegendFormat: legend})
+}
+
+// aggregate applies an aggregation operator by the labels, or over all series when
+// there are none
+func aggregate(operator string, labels []string, expr string) string {
+	if len(labels) == 0 {
+		return fmt.Sprintf("%s(%s)", operator, expr)
+	}
+	return fmt.Sprintf("%s by (%s) (%s)", operator, strings.Join(labels, ", "), expr)
+}
+
+// timeseriesPanel builds a time series panel querying the dashboard datasource
+func timeseriesPanel(title, description, unit string, targets ...grafanaTarget) grafanaPanel {
+	for i := range targets {
+		targets[i].RefID = string(rune('A' + i))
+	}
+	return grafanaPanel{
+		Type:        "timeseries",
+		Title:       title,
+		Description: description,
+		Datasource:  dashboardDatasource,
+		Targets:     targets,
+		FieldConfig: &grafanaFieldConfig{Defaults: grafanaFieldDefaults{Unit: unit}},
+	}
+}
+
+// dashboardLayout numbers the panels and places them two per row below row headers
+type dashboardLayout struct {
+	nextID int
+	column int
+	y      int
+}
+
+func (l *dashboardLayout) row(title string) grafanaPanel {
+	if l.column > 0 {
+		l.column = 0
+		l.y += dashboardPanelHeight
+	}
+	l.nextID++
+	panel := grafanaPanel{
+		ID:      l.nextID,
+		Type:    "row",
+		Title:   title,
+		GridPos: grafanaGridPos{H: 1, W: 24, X: 0, Y: l.y},
+	}
+	l.y++
+	return panel
+}
+
+func (l *dashboardLayout) place(panel grafanaPanel) grafanaPanel {
+	l.nextID++
+	panel.ID = l.nextID
+	panel.GridPos = grafanaGridPos{H: dashboardPanelHeight, W: dashboardPanelWidth, X: l.column * dashboardPanelWidth, Y: l.y}
+	l.column++
+	if l.column*dashboardPanelWidth >= 24 {
+		l.column = 0
+		l.y += dashboardPanelHeight
+	}
+	return panel
+}
+
+// dashboardMetrics describes the collectors: their name, help, labels and whether
+// they are counters, gauges or histograms
+func dashboardMetrics(collectors []prometheus.Collector) ([]dashboardMetric, error) {
+	var described []dashboardMetric
+	for _, collector := range collectors {
+		kind := "gauge"
+		switch collector.(type) {
+		case prometheus.Histogram, *prometheus.HistogramVec:
+			kind = "histogram"
+		case prometheus.Gauge, *prometheus.GaugeVec:
+			kind = "gauge"
+		case prometheus.Counter, *prometheus.CounterVec:
+			kind = "counter"
+		}
+
+		descs := make(chan *prometheus.Desc)
+		go func() {
+			collector.Describe(descs)
+			close(descs)
+		}()
+		for desc := range descs {
+			metric, err := parseDesc(desc)
+			if err != nil {
+				// Drain the remaining descriptors so the describing goroutine exits
+				for range descs {
+				}
+				return nil, err
+			}
+			metric.kind = kind
+			described = append(described, metric)
+		}
+	}
+	return described, nil
+}
+
+// parseDesc extracts a metric's name, help and variable labels from its descriptor
+func parseDesc(desc *prometheus.Desc) (dashboardMetric, error) {
+	match := descPattern.FindStringSubmatch(desc.String())
+	if match == nil {
+		return dashboardMetric{}, fmt.Errorf("failed to parse metric descriptor %s", desc)
+	}
+	name, err := strconv.Unquote(match[1])
+	if err != nil {
+		return dashboardMetric{}, fmt.Errorf("failed to parse metric name of %s: %w", desc, err)
+	}
+	help, err := strconv.Unquote(match[2])
+	if err != nil {
+		return dashboardMetric{}, fmt.Errorf("failed to parse metric help of %s: %w", desc, err)
+	}
+
+	metric := dashboardMetric{name: name, help: help}
+	if match[3] != "" {
+		for _, label := range strings.Split(match[3], ",") {
+			// Constrained labels are rendered as c(label)
+			label = strings.TrimSuffix(strings.TrimPrefix(label, "c("), ")")
+			metric.labels = append(metric.labels, label)
+		}
+	}
+	return metric, nil
+}
@@ -0,0 +1,180 @@
+package metrics
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// parsedDashboard is the part of the Grafana dashboard JSON the tests inspect
+type parsedDashboard struct {
+	UID        string `json:"uid"`
+	Templating struct {
+		List []struct {
+			Name string `json:"name"`
+		} `json:"list"`
+	} `json:"templating"`
+	Panels []struct {
+		ID      int    `json:"id"`
+		Type    string `json:"type"`
+		Title   string `json:"title"`
+		GridPos struct {
+			H, W, X, Y int
+		} `json:"gridPos"`
+		Targets []struct {
+			RefID string `json:"refId"`
+			Expr  string `json:"expr"`
+		} `json:"targets"`
+	} `json:"panels"`
+}
+
+func generateDashboard(t *testing.T) parsedDashboard {
+	t.Helper()
+	data, err := GenerateGrafanaDashboard()
+	if err != nil {
+		t.Fatalf("GenerateGrafanaDashboard: %v", err)
+	}
+	var dashboard parsedDashboard
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		t.Fatalf("generated dashboard is not valid JSON: %v", err)
+	}
+	return dashboard
+}
+
+func TestGenerateGrafanaDashboardLayout(t *testing.T) {
+	dashboard := generateDashboard(t)
+
+	if dashboard.UID != DashboardUID {
+		t.Errorf("uid = %q, want %q", dashboard.UID, DashboardUID)
+	}
+	var variables []string
+	for _, variable := range dashboard.Templating.List {
+		variables = append(variables, variable.Name)
+	}
+	if !reflect.DeepEqual(variables, []string{"datasource", "tenant"}) {
+		t.Errorf("template variables = %v, want datasource and tenant", variables)
+	}
+
+	ids := make(map[int]bool)
+	for _, panel := range dashboard.Panels {
+		if ids[panel.ID] {
+			t.Errorf("panel id %d is used twice", panel.ID)
+		}
+		ids[panel.ID] = true
+		if panel.GridPos.X+panel.GridPos.W > 24 {
+			t.Errorf("panel %q at x=%d, w=%d overflows the 24-column grid", panel.Title, panel.GridPos.X, panel.GridPos.W)
+		}
+		if panel.Type == "row" {
+			continue
+		}
+		if len(panel.Targets) == 0 {
+			t.Errorf("panel %q has no query", panel.Title)
+		}
+		for i, target := range panel.Targets {
+			if want := string(rune('A' + i)); target.RefID != want {
+				t.Errorf("panel %q target %d refId = %q, want %q", panel.Title, i, target.RefID, want)
+			}
+		}
+	}
+}
+
+func TestGenerateGrafanaDashboardOverview(t *testing.T) {
+	dashboard := generateDashboard(t)
+
+	queries := make(map[string]string)
+	for _, panel := range dashboard.Panels {
+		for _, target := range panel.Targets {
+			queries[panel.Title] += target.Expr + "\n"
+		}
+	}
+	for title, metric := range map[string]string{
+		"Ingestion rate per tenant":         "mimir_limit_optimizer_tenant_usage_percentile",
+		"Limit buffer utilization":          "mimir_limit_optimizer_tenant_current_limits",
+		"Reconcile duration":                "mimir_limit_optimizer_reconcile_duration_seconds_bucket",
+		"Circuit breaker state transitions": "mimir_limit_optimizer_circuit_breaker_transitions_total",
+		"Projected monthly cost":            "mimir_cost_estimate_monthly_usd",
+		"Audit log size":                    "mimir_limit_optimizer_audit_log_entries",
+	} {
+		if !strings.Contains(queries[title], metric) {
+			t.Errorf("overview panel %q queries %q, want %s", title, queries[title], metric)
+		}
+	}
+}
+
+func TestGenerateGrafanaDashboardChartsEveryMetric(t *testing.T) {
+	dashboard := generateDashboard(t)
+	registered, err := dashboardMetrics(registeredCollectors())
+	if err != nil {
+		t.Fatalf("describe registered metrics: %v", err)
+	}
+	if len(registered) < len(registeredCollectors()) {
+		t.Fatalf("described %d metrics from %d collectors", len(registered), len(registeredCollectors()))
+	}
+
+	panels := make(map[string]string)
+	for _, panel := range dashboard.Panels {
+		if len(panel.Targets) > 0 {
+			panels[panel.Title] = panel.Targets[0].Expr
+		}
+	}
+	for _, metric := range registered {
+		expr, charted := panels[metric.name]
+		if !charted {
+			t.Errorf("no panel for %s", metric.name)
+			continue
+		}
+		if !strings.Contains(expr, metric.name) {
+			t.Errorf("panel of %s queries %q", metric.name, expr)
+		}
+	}
+}
+
+func TestMetricPanel(t *testing.T) {
+	tests := []struct {
+		name     string
+		metric   dashboardMetric
+		wantExpr string
+		wantUnit string
+	}{
+		{"counter as a rate",
+			dashboardMetric{name: "requests_total", kind: "counter", labels: []string{"tenant", "result"}},
+			`sum by (tenant, result) (rate(requests_total{tenant=~"$tenant"}[$__rate_interval]))`, "ops"},
+		{"histogram as its 95th percentile",
+			dashboardMetric{name: "write_duration_seconds", kind: "histogram"},
+			`histogram_quantile(0.95, sum by (le) (rate(write_duration_seconds_bucket[$__rate_interval])))`, "s"},
+		{"gauge without labels",
+			dashboardMetric{name: "cache_size_bytes", kind: "gauge"},
+			`sum(cache_size_bytes)`, "bytes"},
+		{"timestamp gauge",
+			dashboardMetric{name: "last_run_timestamp", kind: "gauge", labels: []string{"source"}},
+			`max by (source) (last_run_timestamp) * 1000`, "dateTimeFromNow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			panel := metricPanel(tt.metric)
+			if got := panel.Targets[0].Expr; got != tt.wantExpr {
+				t.Errorf("expr = %s, want %s", got, tt.wantExpr)
+			}
+			if got := panel.FieldConfig.Defaults.Unit; got != tt.wantUnit {
+				t.Errorf("unit = %s, want %s", got, tt.wantUnit)
+			}
+		})
+	}
+}
+
+func TestParseDesc(t *testing.T) {
+	desc := prometheus.NewDesc("mimir_test_total", `Counts "quoted" help`, []string{"tenant", "reason"}, nil)
+	metric, err := parseDesc(desc)
+	if err != nil {
+		t.Fatalf("parseDesc: %v", err)
+	}
+	if metric.name != "mimir_test_total" || metric.help != `Counts "quoted" help` {
+		t.Errorf("parsed name %q, help %q", metric.name, metric.help)
+	}
+	if !reflect.DeepEqual(metric.labels, []string{"tenant", "reason"}) {
+		t.Errorf("labels = %v, want [tenant reason]", metric.labels)
+	}
+}
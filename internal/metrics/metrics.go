@@ -24,6 +24,15 @@ var (
 		[]string{"result"},
 	)
 
+	reconcileLockWaitDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mimir_limit_optimizer_reconcile_lock_wait_duration_seconds",
+			Help:    "Time spent waiting for the ConfigMap write lease",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"result"},
+	)
+
 	lastReconcileTime = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "mimir_limit_optimizer_last_reconcile_timestamp",
@@ -31,7 +40,23 @@ var (
 		},
 	)
 
+	reconcileTenantErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_reconcile_tenant_errors_total",
+			Help: "Total number of reconciles in which a tenant failed and was skipped",
+		},
+		[]string{"tenant"},
+	)
+
 	// Tenant metrics
+	inactiveTenantsCleaned = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_inactive_tenants_cleaned_total",
+			Help: "Total number of inactive tenants whose limits were removed, or would be in dry-run mode",
+		},
+		[]string{"result"},
+	)
+
 	tenantsMonitored = prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "mimir_limit_optimizer_tenants_monitored_total",
@@ -46,28 +71,44 @@ var (
 		},
 	)
 
-	tenantLimitsUpdated = prometheus.NewCounterVec(
+	anomalousTenants = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_anomalous_tenants",
+			Help: "Number of tenants whose ingestion rate deviates from the other tenants of their tier",
+		},
+	)
+
+	reconcileTenantsSkipped = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "mimir_limit_optimizer_tenant_limits_updated_total",
-			Help: "Total number of tenant limit updates",
+			Name: "mimir_limit_optimizer_reconcile_tenants_skipped_total",
+			Help: "Total number of tenants left unchanged by a reconciliation, by the reason they were skipped",
 		},
-		[]string{"tenant", "reason"},
+		[]string{"reason"},
 	)
 
-	tenantCurrentLimits = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "mimir_limit_optimizer_tenant_current_limits",
-			Help: "Current limits for each tenant",
+	limitChangesApplied = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_limit_changes_applied_total",
+			Help: "Total number of tenant limit values changed in the runtime overrides, by limit",
 		},
-		[]string{"tenant", "limit_type"},
+		[]string{"limit_type"},
 	)
 
-	tenantRecommendedLimits = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "mimir_limit_optimizer_tenant_recommended_limits",
-			Help: "Recommended limits for each tenant (dry-run mode)",
+	limitChangeRatio = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mimir_limit_optimizer_limit_change_ratio",
+			Help:    "Ratio of the new to the previous value of each limit change applied, by limit",
+			Buckets: []float64{0.25, 0.5, 0.75, 0.9, 0.95, 1, 1.05, 1.1, 1.25, 1.5, 2, 4},
 		},
-		[]string{"tenant", "limit_type"},
+		[]string{"limit_type"},
+	)
+
+	recommendationsClamped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_recommendations_clamped_total",
+			Help: "Total number of recommended limit values raised to their floor (min) or lowered to their ceiling (max)",
+		},
+		[]string{"reason"},
 	)
 
 	// Metrics collection
@@ -113,6 +154,22 @@ var (
 		[]string{"tenant"},
 	)
 
+	spikeState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_spike_state",
+			Help: "Spike state of each tenant (1 for the current state: active, cooling_down, decaying, decayed or none)",
+		},
+		[]string{"tenant", "state"},
+	)
+
+	predictiveSpikeActivations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_predictive_spike_activations_total",
+			Help: "Total number of ingestion limit pre-warmings started by a predicted spike, by result of the limit write (applied, failed or skipped)",
+		},
+		[]string{"tenant", "result"},
+	)
+
 	// ConfigMap operations
 	configMapUpdates = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -138,6 +195,30 @@ var (
 		},
 	)
 
+	configMapSizeBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_configmap_size_bytes",
+			Help: "Size of the data in each runtime overrides ConfigMap as last written",
+		},
+		[]string{"configmap"},
+	)
+
+	configMapSizeWarnings = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_configmap_size_warnings_total",
+			Help: "Number of times a runtime overrides ConfigMap crossed the size warning threshold",
+		},
+		[]string{"configmap"},
+	)
+
+	configMapTenantsChangedPerWrite = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "mimir_limit_optimizer_configmap_tenants_changed_per_write",
+			Help:    "Number of tenants whose limits changed in a single runtime overrides ConfigMap write",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 11),
+		},
+	)
+
 	// Health and error metrics
 	healthStatus = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -155,6 +236,15 @@ var (
 		[]string{"component", "error_type"},
 	)
 
+	healthScanResourceDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mimir_limit_optimizer_health_scan_resource_duration_seconds",
+			Help:    "Time spent listing and analyzing one resource type in an infrastructure health scan",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15},
+		},
+		[]string{"kind", "result"},
+	)
+
 	// Trend analysis metrics
 	trendAnalysisDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
@@ -164,14 +254,6 @@ var (
 		},
 	)
 
-	tenantUsagePercentile = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "mimir_limit_optimizer_tenant_usage_percentile",
-			Help: "Usage percentile for each tenant",
-		},
-		[]string{"tenant", "metric_type", "percentile"},
-	)
-
 	// Discovery metrics
 	servicesDiscovered = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -188,22 +270,6 @@ var (
 	)
 
 	// Cost Control metrics
-	costCurrent = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "mimir_limit_optimizer_cost_current",
-			Help: "Current cost for each tenant",
-		},
-		[]string{"tenant", "cost_type"},
-	)
-
-	budgetUsageRatio = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "mimir_limit_optimizer_budget_usage_ratio",
-			Help: "Budget usage ratio (0.0-1.0) for each tenant",
-		},
-		[]string{"tenant"},
-	)
-
 	costRecommendationsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "mimir_limit_optimizer_cost_recommendations_total",
@@ -229,6 +295,37 @@ var (
 		[]string{"tenant"},
 	)
 
+	circuitBreakerCurrentState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_circuit_breaker_current_state",
+			Help: "Whether the blast protection circuit breaker is in the state (1) or not (0)",
+		},
+		[]string{"state"},
+	)
+
+	circuitBreakerTransitionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_circuit_breaker_transitions_total",
+			Help: "Total number of blast protection circuit breaker state transitions",
+		},
+		[]string{"from", "to"},
+	)
+
+	circuitBreakerThresholdWarmupTenants = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_circuit_breaker_threshold_warmup_tenants",
+			Help: "Number of tenants whose blast detection uses manual thresholds while the auto thresholds warm up",
+		},
+	)
+
+	emergencyTriggerRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_emergency_trigger_threshold_ratio",
+			Help: "Last measured value of each emergency trigger as a fraction of its threshold",
+		},
+		[]string{"trigger"},
+	)
+
 	rateLimitRequestsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "mimir_limit_optimizer_rate_limit_requests_total",
@@ -237,6 +334,14 @@ var (
 		[]string{"tenant", "result"},
 	)
 
+	rateLimitTokens = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_rate_limit_tokens",
+			Help: "Tokens left in the rate limit bucket of each tenant",
+		},
+		[]string{"tenant"},
+	)
+
 	blastDetectionsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "mimir_limit_optimizer_blast_detections_total",
@@ -278,6 +383,27 @@ var (
 		[]string{"recovery_type", "result"},
 	)
 
+	emergencyModeActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_emergency_mode_active",
+			Help: "Whether the circuit breaker is in emergency mode (1) or not (0)",
+		},
+	)
+
+	panicModeActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_panic_mode_active",
+			Help: "Whether the circuit breaker is in panic mode (1) or not (0)",
+		},
+	)
+
+	emergencyFreezeActive = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_emergency_freeze_active",
+			Help: "Whether an emergency freeze is halting all limit changes (1) or not (0)",
+		},
+	)
+
 	resourceUsagePercent = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "mimir_limit_optimizer_resource_usage_percent",
@@ -368,20 +494,145 @@ var (
 		},
 		[]string{"channel"},
 	)
+
+	alertDeduplicatedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_alert_deduplicated_total",
+			Help: "Total number of alerts suppressed as duplicates within the dedup window",
+		},
+		[]string{"channel", "alert_type"},
+	)
+
+	alertsSuppressedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_alerts_suppressed_total",
+			Help: "Total number of alerts suppressed by a silence",
+		},
+		[]string{"alert_type"},
+	)
+
+	emailSentTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_email_sent_total",
+			Help: "Total number of emails sent by kind (alert, digest) and result",
+		},
+		[]string{"kind", "result"},
+	)
+
+	// Cache metrics
+	cacheRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_cache_requests_total",
+			Help: "Total number of cache lookups by backend and result (hit, miss, error)",
+		},
+		[]string{"backend", "result"},
+	)
+
+	cacheOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mimir_limit_optimizer_cache_operation_duration_seconds",
+			Help:    "Duration of cache operations by backend and operation",
+			Buckets: []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1},
+		},
+		[]string{"backend", "operation"},
+	)
+
+	cacheBackendAvailable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_cache_backend_available",
+			Help: "Whether a remote cache backend is currently in use (1) or bypassed for the in-memory fallback (0)",
+		},
+		[]string{"backend"},
+	)
+
+	// Remote override source metrics
+	remoteOverrideLastFetchSuccess = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_remote_override_last_fetch_success_timestamp",
+			Help: "Timestamp of the last successful fetch of the remote tenant limit overrides",
+		},
+	)
+
+	// Config reload metrics
+	configReloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_config_reloads_total",
+			Help: "Total number of config file reloads by result (applied, rejected)",
+		},
+		[]string{"result"},
+	)
+
+	configLastReloadSuccessful = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_config_last_reload_successful",
+			Help: "Whether the last config file reload was applied (1) or rejected (0)",
+		},
+	)
+
+	// Retry budget metrics
+	deadLetterEntriesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_dead_letter_entries_total",
+			Help: "Total number of times a tenant exhausted its ConfigMap write retry budget and was dead-lettered",
+		},
+	)
+
+	// Rollout metrics
+	rolloutSkippedPDBTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_rollout_skipped_pdb_total",
+			Help: "Total number of component rollouts deferred because their PodDisruptionBudget allowed no disruption",
+		},
+		[]string{"component"},
+	)
+
+	// Audit log metrics
+	auditLogEntries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_audit_log_entries",
+			Help: "Number of entries kept in the audit log",
+		},
+		[]string{"storage"},
+	)
+
+	auditEntriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_audit_entries_total",
+			Help: "Total number of audit entries written, by action",
+		},
+		[]string{"action", "result"},
+	)
 )
 
 // RegisterMetrics registers all metrics with the controller-runtime metrics registry
-func RegisterMetrics() error {
-	metrics.Registry.MustRegister(
+func RegisterMetrics(extraTenantLabels []string) error {
+	if err := setExtraTenantLabels(extraTenantLabels); err != nil {
+		return err
+	}
+	metrics.Registry.MustRegister(registeredCollectors()...)
+	return nil
+}
+
+// registeredCollectors returns the metrics registered by RegisterMetrics
+func registeredCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
 		// Controller metrics
 		reconcileTotal,
 		reconcileDuration,
+		reconcileLockWaitDuration,
+		reconcileTenantErrors,
 		lastReconcileTime,
 		
 		// Tenant metrics
 		tenantsMonitored,
+		inactiveTenantsCleaned,
 		tenantsSkipped,
+		anomalousTenants,
 		tenantLimitsUpdated,
+		reconcileTenantsSkipped,
+		limitChangesApplied,
+		limitChangeRatio,
+		recommendationsClamped,
 		tenantCurrentLimits,
 		tenantRecommendedLimits,
 		tenantUsagePercentile,
@@ -394,15 +645,21 @@ func RegisterMetrics() error {
 		// Spike detection metrics
 		spikesDetected,
 		spikeMultiplier,
+		spikeState,
+		predictiveSpikeActivations,
 		
 		// ConfigMap metrics
 		configMapUpdates,
 		configMapUpdateDuration,
 		lastConfigMapUpdate,
+		configMapTenantsChangedPerWrite,
+		configMapSizeBytes,
+		configMapSizeWarnings,
 		
 		// Health metrics
 		healthStatus,
 		errorTotal,
+		healthScanResourceDuration,
 		
 		// Trend analysis metrics
 		trendAnalysisDuration,
@@ -416,10 +673,16 @@ func RegisterMetrics() error {
 		budgetUsageRatio,
 		costRecommendationsTotal,
 		budgetViolationsTotal,
+		costEstimateMonthly,
 		
 		// Circuit Breaker metrics
 		circuitBreakerState,
+		circuitBreakerCurrentState,
+		circuitBreakerTransitionsTotal,
+		circuitBreakerThresholdWarmupTenants,
+		emergencyTriggerRatio,
 		rateLimitRequestsTotal,
+		rateLimitTokens,
 		blastDetectionsTotal,
 		throttledRequestsTotal,
 		
@@ -427,6 +690,9 @@ func RegisterMetrics() error {
 		panicModeActivationsTotal,
 		emergencyActionsTotal,
 		recoveryAttemptsTotal,
+		emergencyModeActive,
+		panicModeActive,
+		emergencyFreezeActive,
 		resourceUsagePercent,
 		
 		// Alerting Resilience metrics
@@ -440,8 +706,24 @@ func RegisterMetrics() error {
 		alertConfigurationErrors,
 		lastSuccessfulAlertTime,
 		alertChannelResponseTime,
-	)
-	return nil
+		alertDeduplicatedTotal,
+		alertsSuppressedTotal,
+		emailSentTotal,
+
+		// Cache metrics
+		cacheRequestsTotal,
+		cacheOperationDuration,
+		cacheBackendAvailable,
+		remoteOverrideLastFetchSuccess,
+		configReloadsTotal,
+		configLastReloadSuccessful,
+		deadLetterEntriesTotal,
+		rolloutSkippedPDBTotal,
+
+		// Audit log metrics
+		auditLogEntries,
+		auditEntriesTotal,
+	}
 }
 
 // ReconcileMetrics provides access to reconciliation metrics
@@ -455,10 +737,18 @@ func (r *ReconcileMetrics) ObserveReconcileDuration(result string, duration floa
 	reconcileDuration.WithLabelValues(result).Observe(duration)
 }
 
+func (r *ReconcileMetrics) ObserveLockWaitDuration(result string, duration float64) {
+	reconcileLockWaitDuration.WithLabelValues(result).Observe(duration)
+}
+
 func (r *ReconcileMetrics) SetLastReconcileTime(timestamp float64) {
 	lastReconcileTime.Set(timestamp)
 }
 
+func (r *ReconcileMetrics) IncTenantErrors(tenant string) {
+	reconcileTenantErrors.WithLabelValues(tenant).Inc()
+}
+
 // TenantMetrics provides access to tenant-related metrics
 type TenantMetrics struct{}
 
@@ -466,24 +756,59 @@ func (t *TenantMetrics) SetTenantsMonitored(count float64) {
 	tenantsMonitored.Set(count)
 }
 
+func (t *TenantMetrics) AddInactiveTenantsCleaned(result string, count int) {
+	inactiveTenantsCleaned.WithLabelValues(result).Add(float64(count))
+}
+
 func (t *TenantMetrics) SetTenantsSkipped(count float64) {
 	tenantsSkipped.Set(count)
 }
 
+func (t *TenantMetrics) SetAnomalousTenants(count float64) {
+	anomalousTenants.Set(count)
+}
+
 func (t *TenantMetrics) IncTenantLimitsUpdated(tenant, reason string) {
-	tenantLimitsUpdated.WithLabelValues(tenant, reason).Inc()
+	tenantLimitsUpdated.WithLabelValues(tenantLabels.labelValues(tenant, reason)...).Inc()
+}
+
+func (t *TenantMetrics) IncTenantsSkipped(reason string) {
+	reconcileTenantsSkipped.WithLabelValues(reason).Inc()
+}
+
+func (t *TenantMetrics) IncLimitChangesApplied(limitType string) {
+	limitChangesApplied.WithLabelValues(limitType).Inc()
+}
+
+// ObserveLimitChangeRatio records the ratio of the new to the previous value of an
+// applied limit change
+func (t *TenantMetrics) ObserveLimitChangeRatio(limitType string, ratio float64) {
+	limitChangeRatio.WithLabelValues(limitType).Observe(ratio)
+}
+
+// IncRecommendationsClamped counts a recommendation clamped to its floor ("min") or
+// ceiling ("max")
+func (t *TenantMetrics) IncRecommendationsClamped(reason string) {
+	recommendationsClamped.WithLabelValues(reason).Inc()
 }
 
 func (t *TenantMetrics) SetTenantCurrentLimits(tenant, limitType string, value float64) {
-	tenantCurrentLimits.WithLabelValues(tenant, limitType).Set(value)
+	tenantCurrentLimits.WithLabelValues(tenantLabels.labelValues(tenant, limitType)...).Set(value)
+}
+
+// DeleteTenantLimitSeries removes the current and recommended limit of every tenant for
+// a limit that is no longer optimized
+func (t *TenantMetrics) DeleteTenantLimitSeries(limitType string) {
+	tenantCurrentLimits.DeletePartialMatch(prometheus.Labels{"limit_type": limitType})
+	tenantRecommendedLimits.DeletePartialMatch(prometheus.Labels{"limit_type": limitType})
 }
 
 func (t *TenantMetrics) SetTenantRecommendedLimits(tenant, limitType string, value float64) {
-	tenantRecommendedLimits.WithLabelValues(tenant, limitType).Set(value)
+	tenantRecommendedLimits.WithLabelValues(tenantLabels.labelValues(tenant, limitType)...).Set(value)
 }
 
 func (t *TenantMetrics) SetTenantUsagePercentile(tenant, metricType, percentile string, value float64) {
-	tenantUsagePercentile.WithLabelValues(tenant, metricType, percentile).Set(value)
+	tenantUsagePercentile.WithLabelValues(tenantLabels.labelValues(tenant, metricType, percentile)...).Set(value)
 }
 
 // CollectionMetrics provides access to metrics collection metrics
@@ -512,6 +837,22 @@ func (s *SpikeMetrics) SetSpikeMultiplier(tenant string, multiplier float64) {
 	spikeMultiplier.WithLabelValues(tenant).Set(multiplier)
 }
 
+// IncPredictiveSpikeActivations counts a pre-warming of the tenant's ingestion limits
+func (s *SpikeMetrics) IncPredictiveSpikeActivations(tenant, result string) {
+	predictiveSpikeActivations.WithLabelValues(tenant, result).Inc()
+}
+
+// SetSpikeState marks state as the tenant's current spike state and clears the others
+func (s *SpikeMetrics) SetSpikeState(tenant, state string, states []string) {
+	for _, candidate := range states {
+		value := 0.0
+		if candidate == state {
+			value = 1
+		}
+		spikeState.WithLabelValues(tenant, candidate).Set(value)
+	}
+}
+
 // ConfigMapMetrics provides access to ConfigMap operation metrics
 type ConfigMapMetrics struct{}
 
@@ -527,6 +868,26 @@ func (c *ConfigMapMetrics) SetLastConfigMapUpdate(timestamp float64) {
 	lastConfigMapUpdate.Set(timestamp)
 }
 
+func (c *ConfigMapMetrics) ObserveTenantsChangedPerWrite(tenants int) {
+	configMapTenantsChangedPerWrite.Observe(float64(tenants))
+}
+
+func (c *ConfigMapMetrics) SetConfigMapSize(configMap string, bytes float64) {
+	configMapSizeBytes.WithLabelValues(configMap).Set(bytes)
+}
+
+func (c *ConfigMapMetrics) IncConfigMapSizeWarnings(configMap string) {
+	configMapSizeWarnings.WithLabelValues(configMap).Inc()
+}
+
+func (c *ConfigMapMetrics) IncDeadLetterEntries() {
+	deadLetterEntriesTotal.Inc()
+}
+
+func (c *ConfigMapMetrics) IncRolloutSkippedPDB(component string) {
+	rolloutSkippedPDBTotal.WithLabelValues(component).Inc()
+}
+
 // HealthMetrics provides access to health and error metrics
 type HealthMetrics struct{}
 
@@ -538,6 +899,12 @@ func (h *HealthMetrics) IncErrorTotal(component, errorType string) {
 	errorTotal.WithLabelValues(component, errorType).Inc()
 }
 
+// ObserveResourceScanDuration records how long a health scan took for a resource type;
+// result is "success" or "error"
+func (h *HealthMetrics) ObserveResourceScanDuration(kind, result string, seconds float64) {
+	healthScanResourceDuration.WithLabelValues(kind, result).Observe(seconds)
+}
+
 // TrendMetrics provides access to trend analysis metrics
 type TrendMetrics struct{}
 
@@ -560,11 +927,11 @@ func (d *DiscoveryMetrics) IncDiscoveryErrors() {
 type CostControlMetrics struct{}
 
 func (c *CostControlMetrics) SetCostCurrent(tenant, costType string, cost float64) {
-	costCurrent.WithLabelValues(tenant, costType).Set(cost)
+	costCurrent.WithLabelValues(tenantLabels.labelValues(tenant, costType)...).Set(cost)
 }
 
 func (c *CostControlMetrics) SetBudgetUsageRatio(tenant string, ratio float64) {
-	budgetUsageRatio.WithLabelValues(tenant).Set(ratio)
+	budgetUsageRatio.WithLabelValues(tenantLabels.labelValues(tenant)...).Set(ratio)
 }
 
 func (c *CostControlMetrics) IncCostRecommendations(tenant, recommendationType string) {
@@ -575,6 +942,15 @@ func (c *CostControlMetrics) IncBudgetViolations(tenant, violationLevel string)
 	budgetViolationsTotal.WithLabelValues(tenant, violationLevel).Inc()
 }
 
+func (c *CostControlMetrics) SetCostEstimateMonthly(tenant string, cost float64) {
+	costEstimateMonthly.WithLabelValues(tenantLabels.labelValues(tenant)...).Set(cost)
+}
+
+// DeleteCostEstimateMonthly drops the projected monthly cost of a tenant no longer observed
+func (c *CostControlMetrics) DeleteCostEstimateMonthly(tenant string) {
+	costEstimateMonthly.DeletePartialMatch(prometheus.Labels{"tenant": tenant})
+}
+
 // CircuitBreakerMetrics provides access to circuit breaker metrics
 type CircuitBreakerMetrics struct{}
 
@@ -582,10 +958,38 @@ func (c *CircuitBreakerMetrics) SetCircuitBreakerState(tenant string, state floa
 	circuitBreakerState.WithLabelValues(tenant).Set(state)
 }
 
+// SetCircuitBreakerCurrentState marks whether the circuit breaker is in the state
+func (c *CircuitBreakerMetrics) SetCircuitBreakerCurrentState(state string, active bool) {
+	value := 0.0
+	if active {
+		value = 1
+	}
+	circuitBreakerCurrentState.WithLabelValues(state).Set(value)
+}
+
+func (c *CircuitBreakerMetrics) IncCircuitBreakerTransitions(from, to string) {
+	circuitBreakerTransitionsTotal.WithLabelValues(from, to).Inc()
+}
+
+// SetThresholdWarmupTenants sets the number of tenants whose auto thresholds are warming up
+func (c *CircuitBreakerMetrics) SetThresholdWarmupTenants(count int) {
+	circuitBreakerThresholdWarmupTenants.Set(float64(count))
+}
+
+// SetEmergencyTriggerRatio sets the last measured value of a trigger relative to its threshold
+func (c *CircuitBreakerMetrics) SetEmergencyTriggerRatio(trigger string, ratio float64) {
+	emergencyTriggerRatio.WithLabelValues(trigger).Set(ratio)
+}
+
 func (c *CircuitBreakerMetrics) IncRateLimitRequests(tenant, result string) {
 	rateLimitRequestsTotal.WithLabelValues(tenant, result).Inc()
 }
 
+// SetRateLimitTokens sets the tokens left in the rate limit bucket of a tenant
+func (c *CircuitBreakerMetrics) SetRateLimitTokens(tenant string, tokens float64) {
+	rateLimitTokens.WithLabelValues(tenant).Set(tokens)
+}
+
 func (c *CircuitBreakerMetrics) IncBlastDetections(tenant, blastType string) {
 	blastDetectionsTotal.WithLabelValues(tenant, blastType).Inc()
 }
@@ -609,6 +1013,27 @@ func (e *EmergencyMetrics) IncRecoveryAttempts(recoveryType, result string) {
 	recoveryAttemptsTotal.WithLabelValues(recoveryType, result).Inc()
 }
 
+// SetProtectionModes exports whether the circuit breaker is in emergency and panic mode
+func (e *EmergencyMetrics) SetProtectionModes(emergencyMode, panicMode bool) {
+	emergencyValue, panicValue := 0.0, 0.0
+	if emergencyMode {
+		emergencyValue = 1
+	}
+	if panicMode {
+		panicValue = 1
+	}
+	emergencyModeActive.Set(emergencyValue)
+	panicModeActive.Set(panicValue)
+}
+
+func (e *EmergencyMetrics) SetEmergencyFreezeActive(active bool) {
+	value := 0.0
+	if active {
+		value = 1
+	}
+	emergencyFreezeActive.Set(value)
+}
+
 func (e *EmergencyMetrics) SetResourceUsage(resourceType string, percentage float64) {
 	resourceUsagePercent.WithLabelValues(resourceType).Set(percentage)
 }
@@ -656,18 +1081,83 @@ func (a *AlertingMetrics) ObserveAlertChannelResponseTime(channel string, durati
 	alertChannelResponseTime.WithLabelValues(channel).Observe(duration)
 }
 
+func (a *AlertingMetrics) IncAlertDeduplicated(channel, alertType string) {
+	alertDeduplicatedTotal.WithLabelValues(channel, alertType).Inc()
+}
+
+func (a *AlertingMetrics) IncAlertSuppressed(alertType string) {
+	alertsSuppressedTotal.WithLabelValues(alertType).Inc()
+}
+
+func (a *AlertingMetrics) IncEmailSent(kind, result string) {
+	emailSentTotal.WithLabelValues(kind, result).Inc()
+}
+
+// CacheMetrics provides access to cache metrics
+type CacheMetrics struct{}
+
+func (c *CacheMetrics) IncCacheRequest(backend, result string) {
+	cacheRequestsTotal.WithLabelValues(backend, result).Inc()
+}
+
+func (c *CacheMetrics) ObserveCacheOperation(backend, operation string, duration float64) {
+	cacheOperationDuration.WithLabelValues(backend, operation).Observe(duration)
+}
+
+func (c *CacheMetrics) SetCacheBackendAvailable(backend string, available bool) {
+	value := 0.0
+	if available {
+		value = 1
+	}
+	cacheBackendAvailable.WithLabelValues(backend).Set(value)
+}
+
+// RemoteOverrideMetrics provides access to remote override source metrics
+type RemoteOverrideMetrics struct{}
+
+func (r *RemoteOverrideMetrics) SetLastFetchSuccess(timestamp float64) {
+	remoteOverrideLastFetchSuccess.Set(timestamp)
+}
+
+// ConfigReloadMetrics provides access to config reload metrics
+type ConfigReloadMetrics struct{}
+
+func (c *ConfigReloadMetrics) IncConfigReload(result string) {
+	configReloadsTotal.WithLabelValues(result).Inc()
+	value := 0.0
+	if result == "applied" {
+		value = 1
+	}
+	configLastReloadSuccessful.Set(value)
+}
+
+// AuditLogMetrics provides access to audit log metrics
+type AuditLogMetrics struct{}
+
+func (a *AuditLogMetrics) SetAuditLogEntries(storage string, count int) {
+	auditLogEntries.WithLabelValues(storage).Set(float64(count))
+}
+
+func (a *AuditLogMetrics) IncAuditEntries(action, result string) {
+	auditEntriesTotal.WithLabelValues(action, result).Inc()
+}
+
 // Global metric instances
 var (
-	ReconcileMetricsInstance     = &ReconcileMetrics{}
-	TenantMetricsInstance        = &TenantMetrics{}
-	CollectionMetricsInstance    = &CollectionMetrics{}
-	SpikeMetricsInstance         = &SpikeMetrics{}
-	ConfigMapMetricsInstance     = &ConfigMapMetrics{}
-	HealthMetricsInstance        = &HealthMetrics{}
-	TrendMetricsInstance         = &TrendMetrics{}
-	DiscoveryMetricsInstance     = &DiscoveryMetrics{}
-	CostControlMetricsInstance   = &CostControlMetrics{}
+	ReconcileMetricsInstance      = &ReconcileMetrics{}
+	TenantMetricsInstance         = &TenantMetrics{}
+	CollectionMetricsInstance     = &CollectionMetrics{}
+	SpikeMetricsInstance          = &SpikeMetrics{}
+	ConfigMapMetricsInstance      = &ConfigMapMetrics{}
+	HealthMetricsInstance         = &HealthMetrics{}
+	TrendMetricsInstance          = &TrendMetrics{}
+	DiscoveryMetricsInstance      = &DiscoveryMetrics{}
+	CostControlMetricsInstance    = &CostControlMetrics{}
 	CircuitBreakerMetricsInstance = &CircuitBreakerMetrics{}
-	EmergencyMetricsInstance     = &EmergencyMetrics{}
-	AlertingMetricsInstance      = &AlertingMetrics{}
-) 
\ No newline at end of file
+	EmergencyMetricsInstance      = &EmergencyMetrics{}
+	AlertingMetricsInstance       = &AlertingMetrics{}
+	CacheMetricsInstance          = &CacheMetrics{}
+	RemoteOverrideMetricsInstance = &RemoteOverrideMetrics{}
+	ConfigReloadMetricsInstance   = &ConfigReloadMetrics{}
+	AuditLogMetricsInstance       = &AuditLogMetrics{}
+)
@@ -0,0 +1,85 @@
+// Package metricstest reads the optimizer metrics back from the controller-runtime
+// registry in tests
+package metricstest
+
+import (
+	"sync"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+var registerMetrics sync.Once
+
+// Register registers the metrics once per test binary, as the registry does not take the
+// same metrics twice. Registering recreates the tenant metrics, so it must happen before
+// the values a test checks are set.
+func Register(t testing.TB) {
+	t.Helper()
+	registerMetrics.Do(func() {
+		if err := metrics.RegisterMetrics(nil); err != nil {
+			t.Fatalf("RegisterMetrics: %v", err)
+		}
+	})
+}
+
+// Value returns the value of the counter or gauge name with the label values, or the
+// sample count of a histogram
+func Value(t testing.TB, name string, labels map[string]string) float64 {
+	t.Helper()
+	metric := find(t, name, labels)
+	switch {
+	case metric.GetCounter() != nil:
+		return metric.GetCounter().GetValue()
+	case metric.GetGauge() != nil:
+		return metric.GetGauge().GetValue()
+	case metric.GetHistogram() != nil:
+		return float64(metric.GetHistogram().GetSampleCount())
+	}
+	return 0
+}
+
+// Histogram returns the sample count and sum of the histogram name with the label values
+func Histogram(t testing.TB, name string, labels map[string]string) (uint64, float64) {
+	t.Helper()
+	histogram := find(t, name, labels).GetHistogram()
+	return histogram.GetSampleCount(), histogram.GetSampleSum()
+}
+
+// find returns the series of the metric name with the label values, or nil
+func find(t testing.TB, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	Register(t)
+
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if hasLabels(metric, labels) {
+				return metric
+			}
+		}
+	}
+	return nil
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if value, exists := labels[pair.GetName()]; exists {
+			if value != pair.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
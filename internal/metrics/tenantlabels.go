@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// MaxExtraTenantLabels bounds metrics.extraLabels, as every label multiplies the series
+// of the tenant metrics by its number of values
+const MaxExtraTenantLabels = 5
+
+// Per-tenant limit, usage and cost metrics. Besides the tenant label they carry the
+// extra tenant labels, so they can be aggregated by business unit, environment or SLA
+// tier. They are created by setupTenantMetrics.
+var (
+	tenantLimitsUpdated     *prometheus.CounterVec
+	tenantCurrentLimits     *prometheus.GaugeVec
+	tenantRecommendedLimits *prometheus.GaugeVec
+	tenantUsagePercentile   *prometheus.GaugeVec
+	costCurrent             *prometheus.GaugeVec
+	budgetUsageRatio        *prometheus.GaugeVec
+	costEstimateMonthly     *prometheus.GaugeVec
+)
+
+// tenantLabels holds the extra tenant labels and the values of each tenant
+var tenantLabels = &tenantLabelSet{}
+
+// tenantLabelSet is the set of extra labels of the tenant metrics
+type tenantLabelSet struct {
+	mu     sync.RWMutex
+	names  []string
+	values map[string]map[string]string
+}
+
+func init() {
+	setupTenantMetrics(nil)
+}
+
+// setupTenantMetrics creates the tenant metrics with the extra tenant labels after the
+// labels of each metric
+func setupTenantMetrics(extraLabels []string) {
+	labels := func(names ...string) []string {
+		return append(append([]string{"tenant"}, names...), extraLabels...)
+	}
+
+	tenantLimitsUpdated = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mimir_limit_optimizer_tenant_limits_updated_total",
+			Help: "Total number of tenant limit updates",
+		},
+		labels("reason"),
+	)
+
+	tenantCurrentLimits = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_tenant_current_limits",
+			Help: "Current limits for each tenant",
+		},
+		labels("limit_type"),
+	)
+
+	tenantRecommendedLimits = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_tenant_recommended_limits",
+			Help: "Recommended limits for each tenant (dry-run mode)",
+		},
+		labels("limit_type"),
+	)
+
+	tenantUsagePercentile = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_tenant_usage_percentile",
+			Help: "Usage percentile for each tenant",
+		},
+		labels("metric_type", "percentile"),
+	)
+
+	costCurrent = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_cost_current",
+			Help: "Current cost for each tenant",
+		},
+		labels("cost_type"),
+	)
+
+	budgetUsageRatio = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_limit_optimizer_budget_usage_ratio",
+			Help: "Budget usage ratio (0.0-1.0) for each tenant",
+		},
+		labels(),
+	)
+
+	costEstimateMonthly = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mimir_cost_estimate_monthly_usd",
+			Help: "Projected monthly cost of each tenant from its average usage over the estimation window",
+		},
+		labels(),
+	)
+}
+
+// tenantMetricLabelNames are the labels of the tenant metrics the extra labels must not
+// repeat
+var tenantMetricLabelNames = []string{"tenant", "reason", "limit_type", "metric_type", "percentile", "cost_type"}
+
+// setExtraTenantLabels creates the tenant metrics with the extra tenant labels
+func setExtraTenantLabels(extraLabels []string) error {
+	if len(extraLabels) > MaxExtraTenantLabels {
+		return fmt.Errorf("at most %d extra tenant labels are allowed, got %d", MaxExtraTenantLabels, len(extraLabels))
+	}
+	seen := make(map[string]bool, len(extraLabels)+len(tenantMetricLabelNames))
+	for _, name := range tenantMetricLabelNames {
+		seen[name] = true
+	}
+	for _, name := range extraLabels {
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+			return fmt.Errorf("extra tenant label %q is not a valid Prometheus label name", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("extra tenant label %q is already a label of the tenant metrics", name)
+		}
+		seen[name] = true
+	}
+
+	tenantLabels.mu.Lock()
+	defer tenantLabels.mu.Unlock()
+	tenantLabels.names = append([]string(nil), extraLabels...)
+	setupTenantMetrics(tenantLabels.names)
+	return nil
+}
+
+// ExtraTenantLabels returns the extra labels of the tenant metrics
+func ExtraTenantLabels() []string {
+	tenantLabels.mu.RLock()
+	defer tenantLabels.mu.RUnlock()
+	return append([]string(nil), tenantLabels.names...)
+}
+
+// SetTenantMetricLabels replaces the extra label values of each tenant; the labels of a
+// tenant without values are empty. The series of tenants whose values changed are
+// dropped, so no series is left with the previous values; they are exported again with
+// the new values when next updated.
+func (t *TenantMetrics) SetTenantMetricLabels(values map[string]map[string]string) {
+	tenantLabels.mu.Lock()
+	if len(tenantLabels.names) == 0 {
+		tenantLabels.mu.Unlock()
+		return
+	}
+	previous := tenantLabels.values
+	tenantLabels.values = make(map[string]map[string]string, len(values))
+	for tenant, tenantValues := range values {
+		tenantLabels.values[tenant] = tenantLabels.known(tenantValues)
+	}
+	var changed []string
+	for tenant := range previous {
+		if !sameLabelValues(previous[tenant], tenantLabels.values[tenant]) {
+			changed = append(changed, tenant)
+		}
+	}
+	for tenant := range tenantLabels.values {
+		if _, existed := previous[tenant]; !existed && len(tenantLabels.values[tenant]) > 0 {
+			// Series exported before the tenant had values carry empty labels
+			changed = append(changed, tenant)
+		}
+	}
+	tenantLabels.mu.Unlock()
+
+	for _, tenant := range changed {
+		deleteTenantSeries(tenant)
+	}
+}
+
+// known returns the values of the extra labels only. The caller must hold mu.
+func (s *tenantLabelSet) known(values map[string]string) map[string]string {
+	known := make(map[string]string, len(s.names))
+	for _, name := range s.names {
+		if value := values[name]; value != "" {
+			known[name] = value
+		}
+	}
+	return known
+}
+
+// sameLabelValues reports whether two tenants' label values are equal, a missing
+// tenant having none
+func sameLabelValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if b[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// labelValues returns the label values of a tenant metric: the tenant, the metric's own
+// values and the values of the extra tenant labels
+func (s *tenantLabelSet) labelValues(tenant string, values ...string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	labelValues := make([]string, 0, 1+len(values)+len(s.names))
+	labelValues = append(labelValues, tenant)
+	labelValues = append(labelValues, values...)
+	for _, name := range s.names {
+		labelValues = append(labelValues, s.values[tenant][name])
+	}
+	return labelValues
+}
+
+// deleteTenantSeries drops every series of a tenant from the tenant metrics
+func deleteTenantSeries(tenant string) {
+	match := prometheus.Labels{"tenant": tenant}
+	tenantLimitsUpdated.DeletePartialMatch(match)
+	for _, vec := range []*prometheus.GaugeVec{tenantCurrentLimits, tenantRecommendedLimits,
+		tenantUsagePercentile, costCurrent, budgetUsageRatio, costEstimateMonthly} {
+		vec.DeletePartialMatch(match)
+	}
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var registerWithEnv sync.Once
+
+// registerEnvLabel registers the metrics with the extra tenant label env, once per test
+// binary as the registry does not take the same metrics twice
+func registerEnvLabel(t *testing.T) {
+	t.Helper()
+	registerWithEnv.Do(func() {
+		if err := RegisterMetrics([]string{"env"}); err != nil {
+			t.Fatalf("RegisterMetrics: %v", err)
+		}
+	})
+}
+
+// tenantSeries returns the label sets of the series of a metric for tenant
+func tenantSeries(t *testing.T, name, tenant string) []map[string]string {
+	t.Helper()
+	families, err := ctrlmetrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("gather metrics: %v", err)
+	}
+	var series []map[string]string
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["tenant"] == tenant {
+				series = append(series, labels)
+			}
+		}
+	}
+	return series
+}
+
+func TestExtraTenantLabelExported(t *testing.T) {
+	registerEnvLabel(t)
+	if got := ExtraTenantLabels(); len(got) != 1 || got[0] != "env" {
+		t.Fatalf("ExtraTenantLabels = %v, want [env]", got)
+	}
+
+	TenantMetricsInstance.SetTenantMetricLabels(map[string]map[string]string{
+		// Values of labels that are not extra labels are not exported
+		"tenant-a": {"env": "prod", "team": "payments"},
+		"tenant-b": {},
+	})
+	TenantMetricsInstance.SetTenantCurrentLimits("tenant-a", "ingestion_rate", 25000)
+	TenantMetricsInstance.SetTenantCurrentLimits("tenant-b", "ingestion_rate", 10000)
+	CostControlMetricsInstance.SetCostEstimateMonthly("tenant-a", 120)
+
+	for _, name := range []string{"mimir_limit_optimizer_tenant_current_limits", "mimir_cost_estimate_monthly_usd"} {
+		series := tenantSeries(t, name, "tenant-a")
+		if len(series) != 1 || series[0]["env"] != "prod" {
+			t.Errorf("%s series of tenant-a = %v, want one with env=prod", name, series)
+			continue
+		}
+		if _, exported := series[0]["team"]; exported {
+			t.Errorf("%s of tenant-a carries the team label, which is not an extra label", name)
+		}
+	}
+	if series := tenantSeries(t, "mimir_limit_optimizer_tenant_current_limits", "tenant-b"); len(series) != 1 || series[0]["env"] != "" {
+		t.Errorf("series of tenant-b without values = %v, want one with an empty env", series)
+	}
+
+	// A tenant moving environment drops its series with the previous value
+	TenantMetricsInstance.SetTenantMetricLabels(map[string]map[string]string{
+		"tenant-a": {"env": "staging"},
+		"tenant-b": {},
+	})
+	if series := tenantSeries(t, "mimir_limit_optimizer_tenant_current_limits", "tenant-a"); len(series) != 0 {
+		t.Errorf("series of tenant-a after its env changed = %v, want the env=prod series dropped", series)
+	}
+	if series := tenantSeries(t, "mimir_limit_optimizer_tenant_current_limits", "tenant-b"); len(series) != 1 {
+		t.Errorf("series of tenant-b whose values did not change = %v, want it kept", series)
+	}
+	TenantMetricsInstance.SetTenantCurrentLimits("tenant-a", "ingestion_rate", 25000)
+	if series := tenantSeries(t, "mimir_limit_optimizer_tenant_current_limits", "tenant-a"); len(series) != 1 || series[0]["env"] != "staging" {
+		t.Errorf("series of tenant-a after the next update = %v, want one with env=staging", series)
+	}
+}
+
+func TestExtraTenantLabelsValidated(t *testing.T) {
+	registerEnvLabel(t)
+
+	tests := []struct {
+		name   string
+		labels []string
+		want   string
+	}{
+		{"more than five", []string{"a", "b", "c", "d", "e", "f"}, "at most 5"},
+		{"invalid name", []string{"business-unit"}, "not a valid"},
+		{"reserved name", []string{"__env"}, "not a valid"},
+		{"label of the tenant metrics", []string{"tenant"}, "already a label"},
+	}
+	for _, tt := range tests {
+		if err := setExtraTenantLabels(tt.labels); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: setExtraTenantLabels(%v) = %v, want an error containing %q", tt.name, tt.labels, err, tt.want)
+		}
+	}
+	if got := ExtraTenantLabels(); len(got) != 1 || got[0] != "env" {
+		t.Errorf("ExtraTenantLabels after rejected labels = %v, want [env] kept", got)
+	}
+}
@@ -0,0 +1,115 @@
+package patcher
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// overridesKey is the top-level key Mimir's runtime config nests tenant limits under
+const overridesKey = "overrides"
+
+// ParseOverridesYAML parses an overrides.yaml document in either supported format and
+// returns it in the canonical mimir-native shape, with tenant limits under the
+// overrides key. The detected format is returned alongside; it is empty for an
+// empty document.
+func ParseOverridesYAML(data string) (map[string]interface{}, string, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal overrides YAML: %w", err)
+	}
+
+	if len(document) == 0 {
+		return map[string]interface{}{overridesKey: make(map[string]interface{})}, "", nil
+	}
+
+	if value, exists := document[overridesKey]; exists {
+		if value == nil {
+			document[overridesKey] = make(map[string]interface{})
+			return document, config.ConfigMapFormatMimirNative, nil
+		}
+		if _, ok := value.(map[string]interface{}); ok {
+			return document, config.ConfigMapFormatMimirNative, nil
+		}
+	}
+
+	// Flat format: every top-level key is a tenant
+	return map[string]interface{}{overridesKey: document}, config.ConfigMapFormatFlat, nil
+}
+
+// MarshalOverrides serializes canonical overrides in the requested format. Other
+// top-level runtime config sections are only representable in mimir-native format.
+func MarshalOverrides(overrides map[string]interface{}, format string) ([]byte, error) {
+	if format == config.ConfigMapFormatFlat {
+		return yaml.Marshal(TenantOverrides(overrides))
+	}
+	return yaml.Marshal(overrides)
+}
+
+// TenantOverrides returns the per-tenant section of canonical overrides
+func TenantOverrides(overrides map[string]interface{}) map[string]interface{} {
+	if tenantOverrides, ok := overrides[overridesKey].(map[string]interface{}); ok {
+		return tenantOverrides
+	}
+	return make(map[string]interface{})
+}
+
+// documentFormat returns the layout of the overrides.yaml documents written in a
+// format; the per-tenant shards and their root ConfigMap are mimir-native
+func documentFormat(format string) string {
+	if format == config.ConfigMapFormatSharded {
+		return config.ConfigMapFormatMimirNative
+	}
+	return format
+}
+
+// emptyOverridesYAML returns the initial overrides.yaml document for a format
+func emptyOverridesYAML(format string) string {
+	if format == config.ConfigMapFormatFlat {
+		return "{}\n"
+	}
+	return "overrides: {}\n"
+}
+
+// HelmValues nests tenant limits under mimir.structuredConfig.overrides, the path
+// the mimir-distributed Helm chart renders into Mimir's configuration
+func HelmValues(limits map[string]*analyzer.TenantLimits) map[string]interface{} {
+	tenants := make(map[string]interface{}, len(limits))
+	for tenant, tenantLimits := range limits {
+		if tenantLimits == nil {
+			continue
+		}
+		values := make(map[string]interface{}, len(tenantLimits.Limits))
+		for name, value := range tenantLimits.Limits {
+			values[name] = value
+		}
+		tenants[tenant] = values
+	}
+	return map[string]interface{}{
+		"mimir": map[string]interface{}{
+			"structuredConfig": map[string]interface{}{
+				overridesKey: tenants,
+			},
+		},
+	}
+}
+
+// MarshalHelmValues encodes Helm values as "yaml" or indented "json", keys sorted
+func MarshalHelmValues(values map[string]interface{}, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		return yaml.Marshal(values)
+	case "json":
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported Helm values format %q, must be yaml or json", format)
+	}
+}
@@ -0,0 +1,101 @@
+package patcher
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+const mimirNativeOverrides = `multi_kv_config:
+  mirror_enabled: false
+  primary: consul
+overrides:
+  tenant-a:
+    ingestion_burst_size: 200000
+    ingestion_rate: 10000
+    native_histograms_ingestion_enabled: true
+  tenant-b:
+    max_global_series_per_user: 150000
+    query_timeout: 2m
+`
+
+func TestMimirNativeRoundTrip(t *testing.T) {
+	overrides, format, err := ParseOverridesYAML(mimirNativeOverrides)
+	if err != nil {
+		t.Fatalf("ParseOverridesYAML: %v", err)
+	}
+	if format != config.ConfigMapFormatMimirNative {
+		t.Errorf("format = %q, want %q", format, config.ConfigMapFormatMimirNative)
+	}
+
+	serialized, err := MarshalOverrides(overrides, format)
+	if err != nil {
+		t.Fatalf("MarshalOverrides: %v", err)
+	}
+	if string(serialized) != mimirNativeOverrides {
+		t.Errorf("round trip changed the document:\n%s\nwant:\n%s", serialized, mimirNativeOverrides)
+	}
+}
+
+func TestParseFlatOverrides(t *testing.T) {
+	flat := `tenant-a:
+  ingestion_rate: 10000
+`
+	overrides, format, err := ParseOverridesYAML(flat)
+	if err != nil {
+		t.Fatalf("ParseOverridesYAML: %v", err)
+	}
+	if format != config.ConfigMapFormatFlat {
+		t.Errorf("format = %q, want %q", format, config.ConfigMapFormatFlat)
+	}
+	want := map[string]interface{}{"tenant-a": map[string]interface{}{"ingestion_rate": 10000.0}}
+	if got := TenantOverrides(overrides); !reflect.DeepEqual(got, want) {
+		t.Errorf("tenant overrides = %v, want %v", got, want)
+	}
+
+	serialized, err := MarshalOverrides(overrides, format)
+	if err != nil {
+		t.Fatalf("MarshalOverrides: %v", err)
+	}
+	if string(serialized) != flat {
+		t.Errorf("flat round trip = %q, want %q", serialized, flat)
+	}
+}
+
+func TestParseEmptyOverrides(t *testing.T) {
+	for _, document := range []string{"", "{}\n", "overrides:\n", "overrides: {}\n"} {
+		overrides, _, err := ParseOverridesYAML(document)
+		if err != nil {
+			t.Fatalf("ParseOverridesYAML(%q): %v", document, err)
+		}
+		if tenants := TenantOverrides(overrides); len(tenants) != 0 {
+			t.Errorf("ParseOverridesYAML(%q) has tenants %v", document, tenants)
+		}
+	}
+}
+
+func TestApplyLimitsKeepsMimirNativeSections(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.ConfigMapFormat = config.ConfigMapFormatMimirNative
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, mimirNativeOverrides))
+
+	err := p.ApplyLimits(context.Background(), map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 12000.0}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	got := readTenantOverrides(t, c, cfg, "tenant-a")
+	if got["ingestion_rate"] != 12000.0 || got["ingestion_burst_size"] != 200000.0 {
+		t.Errorf("tenant-a overrides = %v, want the new rate and the existing burst size", got)
+	}
+	configMap := readConfigMap(t, c, cfg, cfg.Mimir.ConfigMapName)
+	if !strings.HasPrefix(configMap.Data["overrides.yaml"], "multi_kv_config:\n") {
+		t.Errorf("the write dropped the other runtime config sections:\n%s", configMap.Data["overrides.yaml"])
+	}
+}
@@ -3,8 +3,10 @@ package patcher
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -12,9 +14,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/yaml"
 
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
@@ -28,6 +31,8 @@ type Patcher interface {
 	PreviewLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) (*PreviewResult, error)
 	RollbackChanges(ctx context.Context) error
 	GetCurrentLimits(ctx context.Context) (map[string]*analyzer.TenantLimits, error)
+	RemoveTenants(ctx context.Context, tenants []string, reason string) ([]string, error)
+	RemoveLimits(ctx context.Context, limits map[string][]string, reason string) error
 }
 
 // PreviewResult contains the preview of changes to be made
@@ -39,165 +44,238 @@ type PreviewResult struct {
 	AffectedTenants   []string
 	EstimatedChanges  int
 	PreviewTime       time.Time
+	// Changes holds the current and proposed values of the limits that would change,
+	// by tenant
+	Changes map[string]*TenantLimitChange
 }
 
+// readOnlyKey marks contexts whose reads must not create missing ConfigMaps
+type readOnlyKey struct{}
+
 // ConfigMapPatcher implements the Patcher interface for ConfigMap-based runtime overrides
 type ConfigMapPatcher struct {
 	client        client.Client
 	kubeClient    kubernetes.Interface
-	config        *config.Config
+	live          *config.Live
 	auditLog      auditlog.AuditLogger
 	log           logr.Logger
-	lastBackup    *corev1.ConfigMap
+
+	// lastBackup holds the ConfigMaps as they were before the last write, for rollback
+	backupMu   sync.RWMutex
+	lastBackup []*corev1.ConfigMap
+
+	// sizeWarned tracks the ConfigMaps currently over the size warning threshold
+	sizeMu     sync.Mutex
+	sizeWarned map[string]bool
+
+	// shouldProcessTenant, when set, replaces the tenant scoping lists from the
+	// configuration so lists changed at runtime are honoured
+	shouldProcessTenant func(tenant string) bool
+
+	// isTenantPaused, when set, reports the tenants whose overrides must not be modified
+	isTenantPaused func(tenant string) bool
+
+	// pendingRollouts are the components whose rollout was deferred
+	rolloutMu       sync.Mutex
+	pendingRollouts []string
 }
 
 // NewConfigMapPatcher creates a new ConfigMapPatcher
-func NewConfigMapPatcher(c client.Client, kubeClient kubernetes.Interface, cfg *config.Config, auditLogger auditlog.AuditLogger, log logr.Logger) *ConfigMapPatcher {
+func NewConfigMapPatcher(c client.Client, kubeClient kubernetes.Interface, live *config.Live, auditLogger auditlog.AuditLogger, log logr.Logger) *ConfigMapPatcher {
 	return &ConfigMapPatcher{
 		client:     c,
 		kubeClient: kubeClient,
-		config:     cfg,
+		live:       live,
 		auditLog:   auditLogger,
 		log:        log,
+		sizeWarned: make(map[string]bool),
 	}
 }
 
-// ApplyLimits applies the calculated limits to the Mimir runtime overrides ConfigMap with retry logic for conflict resolution
+// config returns the configuration in effect
+func (p *ConfigMapPatcher) config() *config.Config {
+	return p.live.Load()
+}
+
+// configMapWriteBackoff paces re-reads of the runtime overrides ConfigMap after a write conflict
+var configMapWriteBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 150 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
+// TenantLimitChange holds the limits of one tenant that a write changes; an old value
+// is nil for a limit the tenant does not have yet
+type TenantLimitChange struct {
+	OldValues map[string]interface{}
+	NewValues map[string]interface{}
+}
+
+// ApplyLimits applies the calculated limits to the Mimir runtime overrides ConfigMap.
+// The overrides document for all tenants is computed and diffed against the current
+// ConfigMap first, so a reconcile issues at most one Update, retried on conflict, and
+// none at all when no tenant changed. With mimir.thanosModeEnabled, the ruler limits are
+// left out of the runtime overrides and the rule group limits are written to the Thanos
+// Ruler limits ConfigMap in a separate write.
 func (p *ConfigMapPatcher) ApplyLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) error {
+	if !p.config().Mimir.ThanosModeEnabled {
+		return p.applyOverrides(ctx, limits)
+	}
+
+	mimirLimits, rulerLimits := splitRulerLimits(limits)
+	if err := p.applyOverrides(ctx, mimirLimits); err != nil {
+		return err
+	}
+	return p.applyThanosRulerLimits(ctx, rulerLimits)
+}
+
+// applyOverrides writes the limits to the runtime overrides ConfigMap
+func (p *ConfigMapPatcher) applyOverrides(ctx context.Context, limits map[string]*analyzer.TenantLimits) (err error) {
 	startTime := time.Now()
 	defer func() {
-		duration := time.Since(startTime).Seconds()
-		metrics.ConfigMapMetricsInstance.ObserveConfigMapUpdateDuration("success", duration)
+		result := "success"
+		if err != nil {
+			result = "error"
+		}
+		metrics.ConfigMapMetricsInstance.ObserveConfigMapUpdateDuration(result, time.Since(startTime).Seconds())
 	}()
 
-	// Retry logic with exponential backoff for conflict resolution
-	maxRetries := 5
-	baseDelay := 150 * time.Millisecond
-	var currentOverrides, updatedOverrides map[string]interface{}
+	// Bound the whole write, including conflict retries, by the batch timeout
+	if batch := p.config().Performance.BatchProcessing; p.config().Performance.Enabled && batch.Enabled && batch.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, batch.Timeout)
+		defer cancel()
+	}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		// Get current ConfigMap (fresh read each time)
-		currentConfigMap, err := p.getCurrentConfigMap(ctx)
+	var changes map[string]*TenantLimitChange
+	attempt := 0
+
+	err = retry.RetryOnConflict(configMapWriteBackoff, func() error {
+		attempt++
+		if attempt > 1 {
+			p.log.V(1).Info("runtime overrides ConfigMap conflict, retrying",
+				"attempt", attempt,
+				"configmap", p.config().Mimir.ConfigMapName,
+				"tenants", len(limits))
+		}
+
+		// Get current overrides (fresh read each attempt)
+		state, err := p.readOverrides(ctx)
 		if err != nil {
-			metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
-			return fmt.Errorf("failed to get current ConfigMap: %w", err)
+			return err
 		}
 
 		// Create backup on first attempt only
-		if attempt == 0 {
-			p.createBackup(currentConfigMap)
+		if attempt == 1 {
+			p.createBackup(state.configMaps)
 		}
 
-		// Parse current overrides
-		currentOverrides, err = p.parseOverrides(currentConfigMap)
-		if err != nil {
-			metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
-			return fmt.Errorf("failed to parse current overrides: %w", err)
+		// Compute the desired document for all tenants and what differs from the current one
+		var updatedOverrides map[string]interface{}
+		updatedOverrides, changes = p.applyLimitsToOverrides(state.overrides, limits)
+		if len(changes) == 0 {
+			return nil
 		}
 
-		// Apply new limits
-		updatedOverrides = p.applyLimitsToOverrides(currentOverrides, limits)
-
-		// Try to update ConfigMap
-		if err := p.updateConfigMap(ctx, currentConfigMap, updatedOverrides); err != nil {
-			// Check if it's a conflict error
-			if apierrors.IsConflict(err) {
-				if attempt < maxRetries-1 {
-					// Wait with exponential backoff before retrying
-					delay := time.Duration(1<<attempt) * baseDelay
-					p.log.V(1).Info("runtime overrides ConfigMap conflict, retrying",
-						"attempt", attempt+1,
-						"delay", delay,
-						"configmap", p.config.Mimir.ConfigMapName,
-						"tenants", len(limits))
-					time.Sleep(delay)
-					continue
-				}
-				// Max retries exceeded
-				metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
-				return fmt.Errorf("failed to update runtime overrides ConfigMap after %d retries due to conflicts: %w", maxRetries, err)
-			}
-			// Non-conflict error, return immediately
-			metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
-			return fmt.Errorf("failed to update ConfigMap: %w", err)
+		return p.writeOverrides(ctx, state, updatedOverrides)
+	})
+	if err != nil {
+		metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to update runtime overrides ConfigMap after %d attempts due to conflicts: %w", attempt, err)
 		}
+		return fmt.Errorf("failed to update ConfigMap: %w", err)
+	}
 
-		// Success - break out of retry loop
-		break
+	if len(changes) == 0 {
+		metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("unchanged")
+		p.log.V(1).Info("runtime overrides already up to date, skipping ConfigMap write",
+			"tenants", len(limits),
+			"configmap", p.config().Mimir.ConfigMapName)
+
+		// Retry the rollouts deferred after an earlier write
+		if pending := p.PendingRollouts(); p.config().Mimir.TriggerRollout && len(pending) > 0 {
+			p.triggerRollout(ctx, pending)
+		}
+		return nil
 	}
 
-	// Log changes to audit trail (using the final successful values)
-	p.logChanges(currentOverrides, updatedOverrides, limits)
+	metrics.ConfigMapMetricsInstance.ObserveTenantsChangedPerWrite(len(changes))
+
+	// Log changes to audit trail (using the diff of the successful write)
+	p.logChanges(changes, limits)
 
 	// Trigger rollout if configured (optional - runtime overrides work without restarts)
-	if p.config.Mimir.TriggerRollout {
-		if err := p.triggerRollout(ctx); err != nil {
-			p.log.Error(err, "failed to trigger optional rollout (continuing anyway - runtime overrides still work)")
-		} else {
-			p.log.Info("triggered optional component rollouts", "note", "runtime overrides work without restarts")
-		}
+	if p.config().Mimir.TriggerRollout {
+		p.triggerRollout(ctx, p.config().Mimir.RolloutComponents)
 	}
 
 	metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("success")
 	metrics.ConfigMapMetricsInstance.SetLastConfigMapUpdate(float64(time.Now().Unix()))
 
-	if p.config.Mode == "dry-run" {
+	if p.config().Mode == "dry-run" {
 		p.log.Info("successfully wrote optimized limits to ConfigMap for verification", 
 			"tenants", len(limits),
+			"tenants_changed", len(changes),
+			"attempts", attempt,
 			"mode", "dry-run",
-			"configmap", p.config.Mimir.ConfigMapName,
-			"namespace", p.config.Mimir.Namespace)
+			"configmap", p.config().Mimir.ConfigMapName,
+			"namespace", p.config().Mimir.Namespace)
 	} else {
 		p.log.Info("successfully applied limits for production use", 
 			"tenants", len(limits),
+			"tenants_changed", len(changes),
+			"attempts", attempt,
 			"mode", "production",
-			"configmap", p.config.Mimir.ConfigMapName,
-			"namespace", p.config.Mimir.Namespace)
+			"configmap", p.config().Mimir.ConfigMapName,
+			"namespace", p.config().Mimir.Namespace)
 	}
 
 	return nil
 }
 
-// PreviewLimits previews the changes that would be made without applying them
+// PreviewLimits previews the changes that would be made without applying them. Nothing
+// is written, not even the runtime overrides ConfigMap when it does not exist yet.
 func (p *ConfigMapPatcher) PreviewLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) (*PreviewResult, error) {
-	// Get current ConfigMap
-	currentConfigMap, err := p.getCurrentConfigMap(ctx)
+	// Get current overrides
+	state, err := p.readOverrides(context.WithValue(ctx, readOnlyKey{}, true))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current ConfigMap: %w", err)
+		return nil, err
 	}
+	currentOverrides := state.overrides
 
-	// Parse current overrides
-	currentOverrides, err := p.parseOverrides(currentConfigMap)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse current overrides: %w", err)
+	// Apply new limits to a copy; ruler limits are not written with Thanos Ruler
+	if p.config().Mimir.ThanosModeEnabled {
+		limits, _ = splitRulerLimits(limits)
 	}
+	proposedOverrides, proposedChanges := p.applyLimitsToOverrides(copyOverrides(currentOverrides), limits)
 
-	// Apply new limits to a copy
-	proposedOverrides := p.applyLimitsToOverrides(copyOverrides(currentOverrides), limits)
-
-	// Calculate affected tenants
-	affectedTenants := make([]string, 0, len(limits))
-	for tenant := range limits {
+	// Affected tenants are those whose limits would change
+	affectedTenants := make([]string, 0, len(proposedChanges))
+	for tenant := range proposedChanges {
 		affectedTenants = append(affectedTenants, tenant)
 	}
-
-	// Count estimated changes
-	changes := p.countChanges(currentOverrides, proposedOverrides)
+	sort.Strings(affectedTenants)
 
 	return &PreviewResult{
-		ConfigMapName:    p.config.Mimir.ConfigMapName,
-		Namespace:        p.config.Mimir.Namespace,
+		ConfigMapName:    p.config().Mimir.ConfigMapName,
+		Namespace:        p.config().Mimir.Namespace,
 		CurrentData:      currentOverrides,
 		ProposedData:     proposedOverrides,
 		AffectedTenants:  affectedTenants,
-		EstimatedChanges: changes,
+		EstimatedChanges: len(proposedChanges),
 		PreviewTime:      time.Now(),
+		Changes:          proposedChanges,
 	}, nil
 }
 
 // RollbackChanges rolls back to the previous configuration with retry logic for conflict resolution
 func (p *ConfigMapPatcher) RollbackChanges(ctx context.Context) error {
-	if p.lastBackup == nil {
+	p.backupMu.RLock()
+	backups := p.lastBackup
+	p.backupMu.RUnlock()
+	if len(backups) == 0 {
 		return fmt.Errorf("no backup available for rollback")
 	}
 
@@ -207,44 +285,34 @@ func (p *ConfigMapPatcher) RollbackChanges(ctx context.Context) error {
 		metrics.ConfigMapMetricsInstance.ObserveConfigMapUpdateDuration("rollback", duration)
 	}()
 
-	// Retry logic with exponential backoff for conflict resolution
-	maxRetries := 5
-	baseDelay := 150 * time.Millisecond
+	// Restore every ConfigMap the backup covers, re-reading each one on conflict
+	for _, backup := range backups {
+		attempt := 0
+		err := retry.RetryOnConflict(configMapWriteBackoff, func() error {
+			attempt++
+			if attempt > 1 {
+				p.log.V(1).Info("rollback ConfigMap conflict, retrying",
+					"attempt", attempt,
+					"configmap", backup.Name)
+			}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		// Get current ConfigMap (fresh read each time)
-		currentConfigMap, err := p.getCurrentConfigMap(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to get current ConfigMap for rollback: %w", err)
-		}
+			// Get current ConfigMap (fresh read each attempt)
+			currentConfigMap, err := p.getConfigMap(ctx, backup.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get current ConfigMap for rollback: %w", err)
+			}
 
-		// Restore data from backup
-		currentConfigMap.Data = p.lastBackup.Data
-		
-		if err := p.client.Update(ctx, currentConfigMap); err != nil {
-			// Check if it's a conflict error
+			// Restore data from backup
+			currentConfigMap.Data = backup.Data
+			return p.client.Update(ctx, currentConfigMap)
+		})
+		if err != nil {
+			metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("rollback-error")
 			if apierrors.IsConflict(err) {
-				if attempt < maxRetries-1 {
-					// Wait with exponential backoff before retrying
-					delay := time.Duration(1<<attempt) * baseDelay
-					p.log.V(1).Info("rollback ConfigMap conflict, retrying",
-						"attempt", attempt+1,
-						"delay", delay,
-						"configmap", p.config.Mimir.ConfigMapName)
-					time.Sleep(delay)
-					continue
-				}
-				// Max retries exceeded
-				metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("rollback-error")
-				return fmt.Errorf("failed to rollback ConfigMap after %d retries due to conflicts: %w", maxRetries, err)
+				return fmt.Errorf("failed to rollback ConfigMap %s after %d attempts due to conflicts: %w", backup.Name, attempt, err)
 			}
-			// Non-conflict error, return immediately
-			metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("rollback-error")
-			return fmt.Errorf("failed to rollback ConfigMap: %w", err)
+			return fmt.Errorf("failed to rollback ConfigMap %s: %w", backup.Name, err)
 		}
-
-		// Success - break out of retry loop
-		break
 	}
 
 	// Log rollback to audit trail
@@ -271,41 +339,206 @@ func (p *ConfigMapPatcher) RollbackChanges(ctx context.Context) error {
 
 // GetCurrentLimits retrieves the current limits from the ConfigMap
 func (p *ConfigMapPatcher) GetCurrentLimits(ctx context.Context) (map[string]*analyzer.TenantLimits, error) {
-	currentConfigMap, err := p.getCurrentConfigMap(ctx)
+	state, err := p.readOverrides(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current ConfigMap: %w", err)
+		return nil, err
+	}
+
+	return p.parseCurrentLimits(state.overrides), nil
+}
+
+// RemoveTenants deletes the overrides of the given tenants in a single write
+// and returns the tenants that were actually present. Each removal is audited with the
+// limits the tenant had.
+func (p *ConfigMapPatcher) RemoveTenants(ctx context.Context, tenants []string, reason string) ([]string, error) {
+	if len(tenants) == 0 {
+		return nil, nil
 	}
 
-	overrides, err := p.parseOverrides(currentConfigMap)
+	var removed map[string]map[string]interface{}
+
+	err := retry.RetryOnConflict(configMapWriteBackoff, func() error {
+		state, err := p.readOverrides(ctx)
+		if err != nil {
+			return err
+		}
+		overrides := state.overrides
+
+		removed = make(map[string]map[string]interface{})
+		tenantOverrides, ok := overrides["overrides"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for _, tenant := range tenants {
+			tenantConfig, exists := tenantOverrides[tenant]
+			if !exists || p.isPaused(tenant) {
+				continue
+			}
+			oldLimits := make(map[string]interface{})
+			if limits, ok := tenantConfig.(map[string]interface{}); ok {
+				for name, value := range limits {
+					// Skip commented metadata fields
+					if !strings.HasPrefix(name, "#") {
+						oldLimits[name] = value
+					}
+				}
+			}
+			removed[tenant] = oldLimits
+			delete(tenantOverrides, tenant)
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+
+		return p.writeOverrides(ctx, state, overrides)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse overrides: %w", err)
+		metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
+		return nil, fmt.Errorf("failed to remove tenants from runtime overrides: %w", err)
+	}
+
+	removedTenants := make([]string, 0, len(removed))
+	for tenant := range removed {
+		removedTenants = append(removedTenants, tenant)
+	}
+	sort.Strings(removedTenants)
+
+	if len(removedTenants) == 0 {
+		return removedTenants, nil
 	}
 
-	return p.parseCurrentLimits(overrides), nil
+	metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("success")
+	metrics.ConfigMapMetricsInstance.SetLastConfigMapUpdate(float64(time.Now().Unix()))
+
+	if p.auditLog != nil {
+		for _, tenant := range removedTenants {
+			entry := auditlog.NewLimitUpdateEntry(tenant, reason, removed[tenant], nil)
+			entry.Action = "tenant_cleanup"
+			entry.Source = "inactive-tenant-cleanup"
+			entry.Component = "mimir-limit-optimizer"
+
+			if err := p.auditLog.LogEntry(entry); err != nil {
+				p.log.Error(err, "failed to log audit entry for tenant cleanup (audit failure is non-critical)",
+					"tenant", tenant,
+					"reason", reason)
+			}
+		}
+	}
+
+	return removedTenants, nil
+}
+
+// RemoveLimits deletes the named limits of each tenant in a single write, so Mimir
+// falls back to its defaults for them. Each tenant with removed limits is audited.
+func (p *ConfigMapPatcher) RemoveLimits(ctx context.Context, limits map[string][]string, reason string) error {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	var removed map[string]map[string]interface{}
+
+	err := retry.RetryOnConflict(configMapWriteBackoff, func() error {
+		state, err := p.readOverrides(ctx)
+		if err != nil {
+			return err
+		}
+		overrides := state.overrides
+
+		removed = make(map[string]map[string]interface{})
+		tenantOverrides, ok := overrides["overrides"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for tenant, names := range limits {
+			tenantConfig, ok := tenantOverrides[tenant].(map[string]interface{})
+			if !ok || p.isPaused(tenant) {
+				continue
+			}
+			for _, name := range names {
+				value, exists := tenantConfig[name]
+				if !exists {
+					continue
+				}
+				if removed[tenant] == nil {
+					removed[tenant] = make(map[string]interface{})
+				}
+				removed[tenant][name] = value
+				delete(tenantConfig, name)
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+
+		return p.writeOverrides(ctx, state, overrides)
+	})
+	if err != nil {
+		metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("error")
+		return fmt.Errorf("failed to remove limits from runtime overrides: %w", err)
+	}
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	metrics.ConfigMapMetricsInstance.IncConfigMapUpdates("success")
+	metrics.ConfigMapMetricsInstance.SetLastConfigMapUpdate(float64(time.Now().Unix()))
+
+	if p.auditLog != nil {
+		for tenant, oldLimits := range removed {
+			entry := auditlog.NewLimitUpdateEntry(tenant, reason, oldLimits, nil)
+			entry.Action = "limit_removal"
+			entry.Component = "mimir-limit-optimizer"
+
+			if err := p.auditLog.LogEntry(entry); err != nil {
+				p.log.Error(err, "failed to log audit entry for limit removal (audit failure is non-critical)",
+					"tenant", tenant,
+					"reason", reason)
+			}
+		}
+	}
+
+	return nil
 }
 
 // Helper methods
 
 func (p *ConfigMapPatcher) getCurrentConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	return p.getConfigMap(ctx, p.config().Mimir.ConfigMapName)
+}
+
+func (p *ConfigMapPatcher) getConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, error) {
+	configMap, _, err := p.getOrCreateConfigMap(ctx, name)
+	return configMap, err
+}
+
+// getOrCreateConfigMap returns the ConfigMap name, creating an empty one when it does not
+// exist, and reports whether it was missing
+func (p *ConfigMapPatcher) getOrCreateConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, bool, error) {
 	configMap := &corev1.ConfigMap{}
 	err := p.client.Get(ctx, types.NamespacedName{
-		Name:      p.config.Mimir.ConfigMapName,
-		Namespace: p.config.Mimir.Namespace,
+		Name:      name,
+		Namespace: p.config().Mimir.Namespace,
 	}, configMap)
 
 	if apierrors.IsNotFound(err) {
+		if readOnly, _ := ctx.Value(readOnlyKey{}).(bool); readOnly {
+			return p.initialConfigMap(name), true, nil
+		}
 		// Create empty ConfigMap if it doesn't exist
-		return p.createInitialConfigMap(ctx)
+		configMap, err = p.createInitialConfigMap(ctx, name)
+		return configMap, err == nil, err
 	}
 
-	return configMap, err
+	return configMap, false, err
 }
 
-func (p *ConfigMapPatcher) createInitialConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
-	configMap := &corev1.ConfigMap{
+// initialConfigMap is the empty runtime overrides ConfigMap created when none exists
+func (p *ConfigMapPatcher) initialConfigMap(name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      p.config.Mimir.ConfigMapName,
-			Namespace: p.config.Mimir.Namespace,
+			Name:      name,
+			Namespace: p.config().Mimir.Namespace,
 			Labels: map[string]string{
 				"app.kubernetes.io/name":       "mimir",
 				"app.kubernetes.io/component":  "runtime-overrides",
@@ -313,10 +546,13 @@ func (p *ConfigMapPatcher) createInitialConfigMap(ctx context.Context) (*corev1.
 			},
 		},
 		Data: map[string]string{
-			"overrides.yaml": "overrides: {}\n",
+			"overrides.yaml": emptyOverridesYAML(p.config().Mimir.ConfigMapFormat),
 		},
 	}
+}
 
+func (p *ConfigMapPatcher) createInitialConfigMap(ctx context.Context, name string) (*corev1.ConfigMap, error) {
+	configMap := p.initialConfigMap(name)
 	if err := p.client.Create(ctx, configMap); err != nil {
 		return nil, fmt.Errorf("failed to create initial ConfigMap: %w", err)
 	}
@@ -333,15 +569,26 @@ func (p *ConfigMapPatcher) parseOverrides(configMap *corev1.ConfigMap) (map[stri
 		}, nil
 	}
 
-	var overrides map[string]interface{}
-	if err := yaml.Unmarshal([]byte(overridesYAML), &overrides); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal overrides YAML: %w", err)
+	// Both flat and mimir-native layouts are accepted; writes use the configured format
+	overrides, format, err := ParseOverridesYAML(overridesYAML)
+	if err != nil {
+		return nil, err
+	}
+	if format != "" && format != documentFormat(p.config().Mimir.ConfigMapFormat) {
+		p.log.Info("existing overrides use a different format, converting on next write",
+			"configmap", configMap.Name,
+			"current_format", format,
+			"configured_format", p.config().Mimir.ConfigMapFormat)
 	}
 
 	return overrides, nil
 }
 
-func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface{}, limits map[string]*analyzer.TenantLimits) map[string]interface{} {
+// applyLimitsToOverrides merges limits into overrides and returns the per-tenant
+// changes, keyed by tenant; tenants whose limits already match are left untouched
+func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface{}, limits map[string]*analyzer.TenantLimits) (map[string]interface{}, map[string]*TenantLimitChange) {
+	changes := make(map[string]*TenantLimitChange)
+
 	// Ensure overrides structure exists
 	if overrides["overrides"] == nil {
 		overrides["overrides"] = make(map[string]interface{})
@@ -359,6 +606,10 @@ func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface
 		if p.shouldSkipTenant(tenant) {
 			continue
 		}
+		if p.isPaused(tenant) {
+			p.log.V(1).Info("tenant optimization paused, leaving its limits unchanged", "tenant", tenant)
+			continue
+		}
 
 		// PRESERVE EXISTING TENANT CONFIGURATION
 		// Get existing tenant config or create new one
@@ -376,11 +627,15 @@ func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface
 		// MERGE NEW LIMITS WITH EXISTING LIMITS (don't replace!)
 		updatedLimits := make([]string, 0)
 		hasUpdates := false
+		change := &TenantLimitChange{
+			OldValues: make(map[string]interface{}),
+			NewValues: make(map[string]interface{}),
+		}
 
 		// Apply all configured dynamic limits
 		for limitName, limitValue := range tenantLimits.Limits {
 			// Check if this limit is enabled in configuration
-			if limitDef, exists := p.config.DynamicLimits.LimitDefinitions[limitName]; exists && limitDef.Enabled {
+			if limitDef, exists := p.config().DynamicLimits.LimitDefinitions[limitName]; exists && limitDef.Enabled {
 				// Apply the limit value based on type
 				if limitValue != nil && !p.isZeroValue(limitValue) {
 					// CONVERT TO PROPER TYPE based on limit definition
@@ -395,7 +650,9 @@ func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface
 					}
 					
 					// Check if this is actually a change
-					if existingValue, hadExisting := existingTenantConfig[limitName]; !hadExisting || existingValue != convertedValue {
+					if existingValue, hadExisting := existingTenantConfig[limitName]; !hadExisting || !config.LimitValuesEqual(existingValue, convertedValue) {
+						change.OldValues[limitName] = existingValue // nil for a new limit
+						change.NewValues[limitName] = convertedValue
 						existingTenantConfig[limitName] = convertedValue // Use converted value
 						updatedLimits = append(updatedLimits, limitName)
 						hasUpdates = true
@@ -424,6 +681,7 @@ func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface
 
 			// Update the tenant configuration (preserving all existing limits)
 			tenantOverrides[tenant] = existingTenantConfig
+			changes[tenant] = change
 			
 			p.log.V(1).Info("updated tenant limits while preserving existing configuration",
 				"tenant", tenant,
@@ -433,7 +691,7 @@ func (p *ConfigMapPatcher) applyLimitsToOverrides(overrides map[string]interface
 		}
 	}
 
-	return overrides
+	return overrides, changes
 }
 
 // isZeroValue checks if a value is considered zero/empty for its type
@@ -447,86 +705,47 @@ func (p *ConfigMapPatcher) isZeroValue(value interface{}) bool {
 		return v <= 0
 	case string:
 		return v == "" || v == "0s"
+	case time.Duration:
+		return v <= 0
 	case bool:
-		return !v // false is the zero value for boolean
+		return false // Both true and false are meaningful boolean limit values
 	default:
 		return false
 	}
 }
 
-func (p *ConfigMapPatcher) updateConfigMap(ctx context.Context, configMap *corev1.ConfigMap, overrides map[string]interface{}) error {
-	// Convert overrides back to YAML
-	overridesYAML, err := yaml.Marshal(overrides)
-	if err != nil {
-		return fmt.Errorf("failed to marshal overrides to YAML: %w", err)
-	}
-
-	// Update ConfigMap data
-	if configMap.Data == nil {
-		configMap.Data = make(map[string]string)
+func (p *ConfigMapPatcher) createBackup(configMaps []*corev1.ConfigMap) {
+	backups := make([]*corev1.ConfigMap, len(configMaps))
+	for i, configMap := range configMaps {
+		backups[i] = configMap.DeepCopy()
 	}
-	configMap.Data["overrides.yaml"] = string(overridesYAML)
 
-	// Add labels for tracking
-	if configMap.Labels == nil {
-		configMap.Labels = make(map[string]string)
-	}
-	// Use Unix timestamp as it contains only digits and is Kubernetes label-safe
-	configMap.Labels["mimir-limit-optimizer/last-update"] = strconv.FormatInt(time.Now().Unix(), 10)
-
-	return p.client.Update(ctx, configMap)
+	p.backupMu.Lock()
+	p.lastBackup = backups
+	p.backupMu.Unlock()
 }
 
-func (p *ConfigMapPatcher) createBackup(configMap *corev1.ConfigMap) {
-	p.lastBackup = configMap.DeepCopy()
+// SetTenantFilter makes the patcher skip the tenants the given filter rejects
+func (p *ConfigMapPatcher) SetTenantFilter(shouldProcessTenant func(tenant string) bool) {
+	p.shouldProcessTenant = shouldProcessTenant
 }
 
-func (p *ConfigMapPatcher) shouldSkipTenant(tenant string) bool {
-	// Check skip list
-	for _, pattern := range p.config.TenantScoping.SkipList {
-		if p.matchPattern(tenant, pattern) {
-			return true
-		}
-	}
-
-	// Check include list (if specified, only include matching tenants)
-	if len(p.config.TenantScoping.IncludeList) > 0 {
-		for _, pattern := range p.config.TenantScoping.IncludeList {
-			if p.matchPattern(tenant, pattern) {
-				return false
-			}
-		}
-		return true // Not in include list
-	}
-
-	return false
+// SetPauseFilter makes the patcher leave the overrides of paused tenants untouched
+func (p *ConfigMapPatcher) SetPauseFilter(isTenantPaused func(tenant string) bool) {
+	p.isTenantPaused = isTenantPaused
 }
 
-func (p *ConfigMapPatcher) matchPattern(tenant, pattern string) bool {
-	if p.config.TenantScoping.UseRegex {
-		// TODO: Implement regex matching
-		return strings.Contains(tenant, strings.Trim(pattern, "*"))
-	}
-
-	// Simple glob matching
-	if strings.HasSuffix(pattern, "*") {
-		prefix := strings.TrimSuffix(pattern, "*")
-		return strings.HasPrefix(tenant, prefix)
-	}
-	if strings.HasPrefix(pattern, "*") {
-		suffix := strings.TrimPrefix(pattern, "*")
-		return strings.HasSuffix(tenant, suffix)
-	}
-	return tenant == pattern
+func (p *ConfigMapPatcher) isPaused(tenant string) bool {
+	return p.isTenantPaused != nil && p.isTenantPaused(tenant)
 }
 
-func (p *ConfigMapPatcher) triggerRollout(ctx context.Context) error {
-	for _, component := range p.config.Mimir.RolloutComponents {
-		if err := p.restartDeployment(ctx, component); err != nil {
-			p.log.Error(err, "failed to restart component", "component", component)
-		}
+// shouldSkipTenant reports whether the tenant filter rejects a tenant. Without a filter
+// the tenant scoping lists of the configuration are matched like the controller does.
+func (p *ConfigMapPatcher) shouldSkipTenant(tenant string) bool {
+	if p.shouldProcessTenant != nil {
+		return !p.shouldProcessTenant(tenant)
 	}
-	return nil
+	return !p.config().TenantScoping.Allows(tenant)
 }
 
 func (p *ConfigMapPatcher) restartDeployment(ctx context.Context, deploymentName string) error {
@@ -536,7 +755,7 @@ func (p *ConfigMapPatcher) restartDeployment(ctx context.Context, deploymentName
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		// Get the deployment (fresh read each time)
-		deployment, err := p.kubeClient.AppsV1().Deployments(p.config.Mimir.Namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+		deployment, err := p.kubeClient.AppsV1().Deployments(p.config().Mimir.Namespace).Get(ctx, deploymentName, metav1.GetOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to get deployment %s: %w", deploymentName, err)
 		}
@@ -549,7 +768,7 @@ func (p *ConfigMapPatcher) restartDeployment(ctx context.Context, deploymentName
 		deployment.Spec.Template.Annotations["mimir-limit-optimizer/restarted-at"] = strconv.FormatInt(time.Now().Unix(), 10)
 
 		// Update deployment
-		_, err = p.kubeClient.AppsV1().Deployments(p.config.Mimir.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+		_, err = p.kubeClient.AppsV1().Deployments(p.config().Mimir.Namespace).Update(ctx, deployment, metav1.UpdateOptions{})
 		if err != nil {
 			// Check if it's a conflict error (using apierrors works for all K8s API errors)
 			if apierrors.IsConflict(err) {
@@ -578,53 +797,26 @@ func (p *ConfigMapPatcher) restartDeployment(ctx context.Context, deploymentName
 	return nil
 }
 
-func (p *ConfigMapPatcher) logChanges(oldOverrides, newOverrides map[string]interface{}, limits map[string]*analyzer.TenantLimits) {
-	if p.auditLog == nil {
-		return
-	}
-
-	// Extract old tenant overrides (new values are in the limits parameter)
-	oldTenantOverrides := make(map[string]interface{})
-	
-	if oldOverrides["overrides"] != nil {
-		if tenantOverrides, ok := oldOverrides["overrides"].(map[string]interface{}); ok {
-			oldTenantOverrides = tenantOverrides
-		}
-	}
-
-	for tenant, limit := range limits {
-		// Extract old limits for this tenant (new limits are in the limit.Limits map)
-		oldTenantLimits := make(map[string]interface{})
-		
-		if oldTenantConfig, exists := oldTenantOverrides[tenant]; exists {
-			if oldLimits, ok := oldTenantConfig.(map[string]interface{}); ok {
-				oldTenantLimits = oldLimits
+// logChanges writes one audit entry for each tenant changed by a write
+func (p *ConfigMapPatcher) logChanges(changes map[string]*TenantLimitChange, limits map[string]*analyzer.TenantLimits) {
+	for tenant, change := range changes {
+		limit := limits[tenant]
+		metrics.TenantMetricsInstance.IncTenantLimitsUpdated(tenant, limit.Reason)
+		for limitName, newValue := range change.NewValues {
+			metrics.TenantMetricsInstance.IncLimitChangesApplied(limitName)
+			if ratio, ok := p.limitChangeRatio(limitName, change.OldValues[limitName], newValue); ok {
+				metrics.TenantMetricsInstance.ObserveLimitChangeRatio(limitName, ratio)
 			}
 		}
-		
-		// Build proper old vs new audit log entries
-		oldValues := make(map[string]interface{})
-		newValues := make(map[string]interface{})
-		
-		// Check each limit that was updated
-		for limitName, limitValue := range limit.Limits {
-			// Only log enabled limits
-			if limitDef, exists := p.config.DynamicLimits.LimitDefinitions[limitName]; exists && limitDef.Enabled {
-				// Get old value
-				if oldValue, hadOld := oldTenantLimits[limitName]; hadOld {
-					oldValues[limitName] = oldValue
-				} else {
-					oldValues[limitName] = nil // New limit
-				}
-				
-				// Get new value
-				newValues[limitName] = limitValue
-			}
+
+		if p.auditLog == nil {
+			continue
 		}
-		
+
 		// Create audit entry using the enhanced format
-		entry := auditlog.NewLimitUpdateEntry(tenant, limit.Reason, oldValues, newValues)
+		entry := auditlog.NewLimitUpdateEntry(tenant, limit.Reason, change.OldValues, change.NewValues)
 		entry.Source = limit.Source
+		entry.Tier = limit.Tier
 		entry.Component = "mimir-limit-optimizer"
 		
 		if err := p.auditLog.LogEntry(entry); err != nil {
@@ -635,10 +827,43 @@ func (p *ConfigMapPatcher) logChanges(oldOverrides, newOverrides map[string]inte
 				"action", entry.Action,
 				"reason", entry.Reason)
 		}
-		metrics.TenantMetricsInstance.IncTenantLimitsUpdated(tenant, limit.Reason)
 	}
 }
 
+// limitChangeRatio returns the ratio of the new to the previous value of a numeric or
+// duration limit, and false for a limit that was not set before or is not ordered
+func (p *ConfigMapPatcher) limitChangeRatio(limitName string, oldValue, newValue interface{}) (float64, bool) {
+	limitDef, exists := p.config().DynamicLimits.LimitDefinitions[limitName]
+	if !exists || oldValue == nil {
+		return 0, false
+	}
+	oldLimit, err := config.ParseLimitValue(oldValue, limitDef.Type)
+	if err != nil {
+		return 0, false
+	}
+	newLimit, err := config.ParseLimitValue(newValue, limitDef.Type)
+	if err != nil {
+		return 0, false
+	}
+
+	if oldDuration, ok := oldLimit.Duration(); ok {
+		newDuration, _ := newLimit.Duration()
+		if oldDuration <= 0 {
+			return 0, false
+		}
+		return float64(newDuration) / float64(oldDuration), true
+	}
+	oldNumber, ok := oldLimit.Number()
+	if !ok || oldNumber <= 0 {
+		return 0, false
+	}
+	newNumber, ok := newLimit.Number()
+	if !ok {
+		return 0, false
+	}
+	return newNumber / oldNumber, true
+}
+
 func (p *ConfigMapPatcher) parseCurrentLimits(overrides map[string]interface{}) map[string]*analyzer.TenantLimits {
 	limits := make(map[string]*analyzer.TenantLimits)
 
@@ -661,7 +886,7 @@ func (p *ConfigMapPatcher) parseCurrentLimits(overrides map[string]interface{})
 		}
 
 		// Parse all configured dynamic limits, skipping commented metadata fields
-		for limitName, limitDef := range p.config.DynamicLimits.LimitDefinitions {
+		for limitName, limitDef := range p.config().DynamicLimits.LimitDefinitions {
 			if limitDef.Enabled {
 				if val, exists := tenantLimits[limitName]; exists {
 					// Skip commented metadata fields (they start with #)
@@ -707,124 +932,19 @@ func (p *ConfigMapPatcher) parseCurrentLimits(overrides map[string]interface{})
 	return limits
 }
 
-// convertLimitValue converts a value to the appropriate type based on limit definition  
+// convertLimitValue converts a value to the representation Mimir reads for the limit
+// type: integer counts and sizes, numeric rates and percentages, duration strings such
+// as "13h", booleans and strings. Values that are not valid for the type are rejected
+// rather than written in a format Mimir would refuse.
 func (p *ConfigMapPatcher) convertLimitValue(value interface{}, limitType string) (interface{}, error) {
-	switch limitType {
-	case "count":
-		// COUNT LIMITS MUST BE INTEGERS (ingestion_rate, max_global_series_per_user, etc.)
-		switch v := value.(type) {
-		case float64:
-			return int64(v), nil // Convert float to integer
-		case int64:
-			return v, nil // Already integer
-		case int:
-			return int64(v), nil // Convert int to int64
-		case string:
-			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
-				return int64(parsed), nil // Parse string and convert to integer
-			}
-			return nil, fmt.Errorf("cannot convert string %q to integer value", v)
-		default:
-			return nil, fmt.Errorf("unsupported value type %T for count limit", value)
-		}
-	case "rate":
-		// RATE LIMITS CAN BE FLOAT (samples per second, requests per second)
-		switch v := value.(type) {
-		case float64:
-			return v, nil
-		case int64:
-			return float64(v), nil
-		case int:
-			return float64(v), nil
-		case string:
-			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
-				return parsed, nil
-			}
-			return nil, fmt.Errorf("cannot convert string %q to numeric value", v)
-		default:
-			return nil, fmt.Errorf("unsupported value type %T for rate limit", value)
-		}
-	case "size":
-		// SIZE LIMITS SHOULD BE INTEGERS (bytes, chunk counts, etc.)
-		switch v := value.(type) {
-		case float64:
-			return int64(v), nil // Convert float to integer
-		case int64:
-			return v, nil // Already integer
-		case int:
-			return int64(v), nil
-		case string:
-			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
-				return int64(parsed), nil
-			}
-			return nil, fmt.Errorf("cannot convert string %q to integer value", v)
-		default:
-			return nil, fmt.Errorf("unsupported value type %T for size limit", value)
-		}
-	case "percentage":
-		// PERCENTAGE LIMITS CAN BE FLOAT (0.0-100.0)
-		switch v := value.(type) {
-		case float64:
-			return v, nil
-		case int64:
-			return float64(v), nil
-		case int:
-			return float64(v), nil
-		case string:
-			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
-				return parsed, nil
-			}
-			return nil, fmt.Errorf("cannot convert string %q to percentage value", v)
-		default:
-			return nil, fmt.Errorf("unsupported value type %T for percentage limit", value)
-		}
-	case "duration":
-		// DURATION LIMITS SHOULD BE STRINGS ("5m", "1h", etc.)
-		switch v := value.(type) {
-		case string:
-			return v, nil // Keep as string for duration values
-		default:
-			return fmt.Sprintf("%v", v), nil
-		}
-	case "bool":
-		// BOOLEAN LIMITS SHOULD BE BOOLEANS (true/false)
-		switch v := value.(type) {
-		case bool:
-			return v, nil
-		case string:
-			if parsed, err := strconv.ParseBool(v); err == nil {
-				return parsed, nil
-			}
-			return nil, fmt.Errorf("cannot convert string %q to boolean value", v)
-		case int64:
-			return v != 0, nil // 0 = false, anything else = true
-		case int:
-			return v != 0, nil // 0 = false, anything else = true
-		case float64:
-			return v != 0.0, nil // 0.0 = false, anything else = true
-		default:
-			return nil, fmt.Errorf("unsupported value type %T for boolean limit", value)
-		}
-	case "string":
-		// STRING LIMITS SHOULD BE STRINGS
-		switch v := value.(type) {
-		case string:
-			return v, nil
-		default:
-			return fmt.Sprintf("%v", v), nil // Convert any type to string
-		}
-	default:
+	if _, known := config.LimitKindOf(limitType); !known {
 		return value, nil // Return as-is for unknown types
 	}
-}
-
-func (p *ConfigMapPatcher) countChanges(old, new map[string]interface{}) int {
-	// Simple implementation - count number of tenants being updated
-	newOverrides, ok := new["overrides"].(map[string]interface{})
-	if !ok {
-		return 0
+	converted, err := config.ParseLimitValue(value, limitType)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %v to a %s limit value: %w", value, limitType, err)
 	}
-	return len(newOverrides)
+	return converted.Interface(), nil
 }
 
 func copyOverrides(overrides map[string]interface{}) map[string]interface{} {
@@ -842,6 +962,6 @@ func copyOverrides(overrides map[string]interface{}) map[string]interface{} {
 }
 
 // NewPatcher creates the appropriate patcher based on configuration
-func NewPatcher(c client.Client, kubeClient kubernetes.Interface, cfg *config.Config, auditLogger auditlog.AuditLogger, log logr.Logger) Patcher {
-	return NewConfigMapPatcher(c, kubeClient, cfg, auditLogger, log)
+func NewPatcher(c client.Client, kubeClient kubernetes.Interface, live *config.Live, auditLogger auditlog.AuditLogger, log logr.Logger) Patcher {
+	return NewConfigMapPatcher(c, kubeClient, live, auditLogger, log)
 } 
\ No newline at end of file
@@ -0,0 +1,261 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+// newTestPatcher creates a patcher of cfg writing to a fake client holding objs
+func newTestPatcher(cfg *config.Config, objs ...client.Object) (*ConfigMapPatcher, client.Client) {
+	c := fake.NewClientBuilder().WithObjects(objs...).Build()
+	return NewConfigMapPatcher(c, nil, config.NewLive(cfg), nil, logr.Discard()), c
+}
+
+// overridesConfigMap is a runtime overrides ConfigMap holding overridesYAML
+func overridesConfigMap(cfg *config.Config, name, overridesYAML string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Mimir.Namespace},
+		Data:       map[string]string{"overrides.yaml": overridesYAML},
+	}
+}
+
+// readConfigMap returns the ConfigMap name in the Mimir namespace
+func readConfigMap(t *testing.T, c client.Client, cfg *config.Config, name string) *corev1.ConfigMap {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: name, Namespace: cfg.Mimir.Namespace}
+	if err := c.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get ConfigMap %s: %v", name, err)
+	}
+	return configMap
+}
+
+// readTenantOverrides returns the overrides of tenant in the runtime overrides ConfigMap
+func readTenantOverrides(t *testing.T, c client.Client, cfg *config.Config, tenant string) map[string]interface{} {
+	t.Helper()
+	configMap := readConfigMap(t, c, cfg, cfg.Mimir.ConfigMapName)
+	overrides, _, err := ParseOverridesYAML(configMap.Data["overrides.yaml"])
+	if err != nil {
+		t.Fatalf("parse runtime overrides: %v", err)
+	}
+	tenantOverrides, _ := TenantOverrides(overrides)[tenant].(map[string]interface{})
+	return tenantOverrides
+}
+
+// enableLimits enables the named limit definitions of cfg
+func enableLimits(cfg *config.Config, limitNames ...string) {
+	for _, limitName := range limitNames {
+		limitDef := cfg.DynamicLimits.LimitDefinitions[limitName]
+		limitDef.Enabled = true
+		cfg.DynamicLimits.LimitDefinitions[limitName] = limitDef
+	}
+}
+
+func TestPassthroughLimitsWrittenUnchanged(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	enableLimits(cfg, "native_histograms_ingestion_enabled", "ingestion_rate_strategy")
+	cfg.Limits.DefaultLimits = map[string]interface{}{
+		"native_histograms_ingestion_enabled": true,
+		"ingestion_rate_strategy":             "local",
+	}
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+	a := analyzer.NewTrendAnalyzer(config.NewLive(cfg), logr.Discard())
+	limits, err := a.CalculateLimits(context.Background(), map[string][]analyzer.AnalysisResult{
+		"tenant-a": {},
+	})
+	if err != nil {
+		t.Fatalf("CalculateLimits: %v", err)
+	}
+	if err := p.ApplyLimits(context.Background(), limits); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	tenant := readTenantOverrides(t, c, cfg, "tenant-a")
+	if got := tenant["native_histograms_ingestion_enabled"]; got != true {
+		t.Errorf("native_histograms_ingestion_enabled = %v (%T), want true", got, got)
+	}
+	if got := tenant["ingestion_rate_strategy"]; got != "local" {
+		t.Errorf("ingestion_rate_strategy = %v (%T), want %q", got, got, "local")
+	}
+}
+
+func TestFalseBoolLimitIsWritten(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	enableLimits(cfg, "native_histograms_ingestion_enabled")
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName,
+		"overrides:\n  tenant-a:\n    native_histograms_ingestion_enabled: true\n"))
+
+	err := p.ApplyLimits(context.Background(), map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"native_histograms_ingestion_enabled": false}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	if got := readTenantOverrides(t, c, cfg, "tenant-a")["native_histograms_ingestion_enabled"]; got != false {
+		t.Errorf("native_histograms_ingestion_enabled = %v (%T), want false", got, got)
+	}
+}
+
+func TestTypedLimitsWrittenInMimirFormat(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	enableLimits(cfg, "max_global_series_per_user", "max_fetched_chunk_bytes_per_query", "compactor_blocks_retention_period")
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+	err := p.ApplyLimits(context.Background(), map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{
+			// A count buffered by a float factor
+			"max_global_series_per_user":        1000000 * 1.2,
+			"max_fetched_chunk_bytes_per_query": "50MB",
+			"compactor_blocks_retention_period": "13h",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	overridesYAML := readConfigMap(t, c, cfg, cfg.Mimir.ConfigMapName).Data["overrides.yaml"]
+	for _, want := range []string{
+		"max_global_series_per_user: 1200000\n",
+		"max_fetched_chunk_bytes_per_query: 50000000\n",
+		"compactor_blocks_retention_period: 13h\n",
+	} {
+		if !strings.Contains(overridesYAML, want) {
+			t.Errorf("runtime overrides do not contain %q:\n%s", want, overridesYAML)
+		}
+	}
+}
+
+func TestLimitBoundsWrittenToConfigMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		minLimits   map[string]interface{}
+		maxLimits   map[string]interface{}
+		recommended float64
+		want        string
+	}{
+		{"raised to the floor", map[string]interface{}{"ingestion_rate": 10000}, nil, 5000, "10000"},
+		{"lowered to the ceiling", nil, map[string]interface{}{"ingestion_rate": 20000}, 50000, "20000"},
+		{"within the bounds", map[string]interface{}{"ingestion_rate": 1000}, map[string]interface{}{"ingestion_rate": 20000}, 5000, "5000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.GetDefaultConfig()
+			cfg.DynamicLimits.DefaultBuffer = 0
+			limitDef := cfg.DynamicLimits.LimitDefinitions["ingestion_rate"]
+			limitDef.BufferFactor = 0
+			cfg.DynamicLimits.LimitDefinitions["ingestion_rate"] = limitDef
+			cfg.Limits.MinLimits = tt.minLimits
+			cfg.Limits.MaxLimits = tt.maxLimits
+			p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+			a := analyzer.NewTrendAnalyzer(config.NewLive(cfg), logr.Discard())
+			limits, err := a.CalculateLimits(context.Background(), map[string][]analyzer.AnalysisResult{
+				"tenant-a": {{
+					Tenant:           "tenant-a",
+					MetricName:       "cortex_distributor_received_samples_total",
+					RecommendedLimit: tt.recommended,
+				}},
+			})
+			if err != nil {
+				t.Fatalf("CalculateLimits: %v", err)
+			}
+			if err := p.ApplyLimits(context.Background(), limits); err != nil {
+				t.Fatalf("ApplyLimits: %v", err)
+			}
+
+			got := readTenantOverrides(t, c, cfg, "tenant-a")["ingestion_rate"]
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("ingestion_rate = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitChangeRatioRecorded(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	enableLimits(cfg, "max_query_length")
+	p, _ := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName,
+		"overrides:\n  tenant-a:\n    ingestion_rate: 10000\n    max_query_length: 12h\n"))
+
+	ratio := func(limitName string) (uint64, float64) {
+		return metricstest.Histogram(t, "mimir_limit_optimizer_limit_change_ratio", map[string]string{"limit_type": limitName})
+	}
+	ingestionCount, ingestionSum := ratio("ingestion_rate")
+	durationCount, durationSum := ratio("max_query_length")
+
+	err := p.ApplyLimits(context.Background(), map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 20000.0, "max_query_length": "6h"}},
+		// A tenant without previous limits has no ratio to record
+		"tenant-new": {Tenant: "tenant-new", Limits: map[string]interface{}{"ingestion_rate": 5000.0}},
+	})
+	if err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	count, sum := ratio("ingestion_rate")
+	if count-ingestionCount != 1 || sum-ingestionSum != 2 {
+		t.Errorf("ingestion_rate ratios rose by %d summing %v, want the 1 doubling of tenant-a", count-ingestionCount, sum-ingestionSum)
+	}
+	count, sum = ratio("max_query_length")
+	if count-durationCount != 1 || sum-durationSum != 0.5 {
+		t.Errorf("max_query_length ratios rose by %d summing %v, want the 1 halving of tenant-a", count-durationCount, sum-durationSum)
+	}
+}
+
+func TestShardsSeededOnlyWhenCreated(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.ConfigMapFormat = config.ConfigMapFormatFlat
+	cfg.Mimir.Sharding.Enabled = true
+	cfg.Mimir.Sharding.Shards = 2
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName,
+		"overrides:\n  tenant-a:\n    ingestion_rate: 10000\n  tenant-b:\n    ingestion_rate: 20000\n"))
+	ctx := context.Background()
+
+	// The shards are created and seeded from the unsharded ConfigMap
+	state, err := p.readOverrides(ctx)
+	if err != nil {
+		t.Fatalf("readOverrides: %v", err)
+	}
+	if got := len(TenantOverrides(state.overrides)); got != 2 {
+		t.Fatalf("seeded tenants = %d, want 2", got)
+	}
+	if err := p.writeOverrides(ctx, state, state.overrides); err != nil {
+		t.Fatalf("writeOverrides: %v", err)
+	}
+	for _, name := range cfg.Mimir.OverridesConfigMapNames() {
+		readConfigMap(t, c, cfg, name)
+	}
+
+	// Emptying the shards does not bring the tenants of the unsharded ConfigMap back
+	state, err = p.readOverrides(ctx)
+	if err != nil {
+		t.Fatalf("readOverrides: %v", err)
+	}
+	emptied := map[string]interface{}{overridesKey: map[string]interface{}{}}
+	if err := p.writeOverrides(ctx, state, emptied); err != nil {
+		t.Fatalf("writeOverrides: %v", err)
+	}
+
+	state, err = p.readOverrides(ctx)
+	if err != nil {
+		t.Fatalf("readOverrides: %v", err)
+	}
+	if tenants := TenantOverrides(state.overrides); len(tenants) != 0 {
+		t.Errorf("tenants after emptying the shards = %v, want none", tenants)
+	}
+}
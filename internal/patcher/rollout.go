@@ -0,0 +1,123 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// RolloutRetryDelay is how soon a reconcile is requeued to retry deferred rollouts
+const RolloutRetryDelay = 5 * time.Minute
+
+// triggerRollout restarts the components, deferring every rollout while a node is
+// being drained and the rollout of each component whose PodDisruptionBudget allows no
+// disruption, so an already degraded cluster is not disrupted further. Deferred
+// components are kept to be retried by the next ApplyLimits.
+func (p *ConfigMapPatcher) triggerRollout(ctx context.Context, components []string) {
+	node, err := p.drainingNode(ctx)
+	if err != nil {
+		p.log.Error(err, "failed to check for node drains before rollout, continuing")
+	}
+	if node != "" {
+		p.log.Info("WARNING: node drain in progress, deferring component rollouts",
+			"node", node, "components", components, "retry_after", RolloutRetryDelay)
+		p.setPendingRollouts(components)
+		return
+	}
+
+	var restarted, deferred []string
+	for _, component := range components {
+		pdb, err := p.blockingPDB(ctx, component)
+		if err != nil {
+			p.log.V(1).Info("failed to check PodDisruptionBudgets before rollout, continuing",
+				"component", component, "error", err.Error())
+		}
+		if pdb != "" {
+			metrics.ConfigMapMetricsInstance.IncRolloutSkippedPDB(component)
+			p.log.Info("WARNING: PodDisruptionBudget allows no disruption, deferring component rollout",
+				"component", component, "pdb", pdb, "retry_after", RolloutRetryDelay)
+			deferred = append(deferred, component)
+			continue
+		}
+
+		if err := p.restartDeployment(ctx, component); err != nil {
+			p.log.Error(err, "failed to restart component", "component", component)
+			continue
+		}
+		restarted = append(restarted, component)
+	}
+	p.setPendingRollouts(deferred)
+
+	if len(restarted) > 0 {
+		p.log.Info("triggered optional component rollouts", "components", restarted,
+			"note", "runtime overrides work without restarts")
+	}
+}
+
+// drainingNode returns a node carrying the unschedulable taint of a cordon or drain,
+// or an empty name when there is none
+func (p *ConfigMapPatcher) drainingNode(ctx context.Context) (string, error) {
+	nodes, err := p.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == corev1.TaintNodeUnschedulable {
+				return node.Name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// blockingPDB returns a PodDisruptionBudget selecting the pods of a component that
+// allows no disruption, or an empty name when there is none
+func (p *ConfigMapPatcher) blockingPDB(ctx context.Context, component string) (string, error) {
+	namespace := p.config().Mimir.Namespace
+	deployment, err := p.kubeClient.AppsV1().Deployments(namespace).Get(ctx, component, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployment %s: %w", component, err)
+	}
+	pdbs, err := p.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list PodDisruptionBudgets: %w", err)
+	}
+
+	podLabels := labels.Set(deployment.Spec.Template.Labels)
+	for _, pdb := range pdbs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(podLabels) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed == 0 {
+			return pdb.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// setPendingRollouts keeps the components whose rollout was deferred
+func (p *ConfigMapPatcher) setPendingRollouts(components []string) {
+	pending := append([]string(nil), components...)
+	sort.Strings(pending)
+
+	p.rolloutMu.Lock()
+	defer p.rolloutMu.Unlock()
+	p.pendingRollouts = pending
+}
+
+// PendingRollouts returns the components whose rollout was deferred by a node drain or
+// a PodDisruptionBudget and is retried by the next ApplyLimits
+func (p *ConfigMapPatcher) PendingRollouts() []string {
+	p.rolloutMu.Lock()
+	defer p.rolloutMu.Unlock()
+	return append([]string(nil), p.pendingRollouts...)
+}
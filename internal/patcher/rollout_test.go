@@ -0,0 +1,152 @@
+package patcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics/metricstest"
+)
+
+func rolloutConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.TriggerRollout = true
+	cfg.Mimir.RolloutComponents = []string{"ingester", "querier"}
+	return cfg
+}
+
+// newRolloutPatcher creates a patcher of cfg restarting the deployments of a fake
+// clientset holding kubeObjs
+func newRolloutPatcher(cfg *config.Config, kubeObjs ...runtime.Object) (*ConfigMapPatcher, *kubefake.Clientset) {
+	c := fake.NewClientBuilder().WithObjects(overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n")).Build()
+	kubeClient := kubefake.NewSimpleClientset(kubeObjs...)
+	return NewConfigMapPatcher(c, kubeClient, config.NewLive(cfg), nil, logr.Discard()), kubeClient
+}
+
+func rolloutDeployment(cfg *config.Config, name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: cfg.Mimir.Namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app.kubernetes.io/component": name}},
+			},
+		},
+	}
+}
+
+// componentPDB is a PodDisruptionBudget of the component allowing disruptionsAllowed
+func componentPDB(cfg *config.Config, component string, disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: component + "-pdb", Namespace: cfg.Mimir.Namespace},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/component": component}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+	}
+}
+
+// restarted reports whether the deployment name carries the restart annotation
+func restarted(t *testing.T, kubeClient *kubefake.Clientset, cfg *config.Config, name string) bool {
+	t.Helper()
+	deployment, err := kubeClient.AppsV1().Deployments(cfg.Mimir.Namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment %s: %v", name, err)
+	}
+	_, exists := deployment.Spec.Template.Annotations["mimir-limit-optimizer/restarted-at"]
+	return exists
+}
+
+func TestRolloutDeferredByPDB(t *testing.T) {
+	cfg := rolloutConfig()
+	p, kubeClient := newRolloutPatcher(cfg,
+		rolloutDeployment(cfg, "ingester"),
+		rolloutDeployment(cfg, "querier"),
+		componentPDB(cfg, "ingester", 0),
+	)
+	skipped := metricstest.Value(t, "mimir_limit_optimizer_rollout_skipped_pdb_total", map[string]string{"component": "ingester"})
+
+	if err := p.ApplyLimits(context.Background(), ingestionLimits(25000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	if restarted(t, kubeClient, cfg, "ingester") {
+		t.Errorf("ingester was restarted while its PodDisruptionBudget allows no disruption")
+	}
+	if !restarted(t, kubeClient, cfg, "querier") {
+		t.Errorf("querier without a PodDisruptionBudget was not restarted")
+	}
+	if got := p.PendingRollouts(); !reflect.DeepEqual(got, []string{"ingester"}) {
+		t.Errorf("pending rollouts = %v, want [ingester]", got)
+	}
+	if got := metricstest.Value(t, "mimir_limit_optimizer_rollout_skipped_pdb_total", map[string]string{"component": "ingester"}); got != skipped+1 {
+		t.Errorf("mimir_limit_optimizer_rollout_skipped_pdb_total{component=ingester} = %v, want %v", got, skipped+1)
+	}
+}
+
+func TestDeferredRolloutRetriedOnceDisruptionAllowed(t *testing.T) {
+	cfg := rolloutConfig()
+	p, kubeClient := newRolloutPatcher(cfg,
+		rolloutDeployment(cfg, "ingester"),
+		rolloutDeployment(cfg, "querier"),
+		componentPDB(cfg, "ingester", 0),
+	)
+	ctx := context.Background()
+	if err := p.ApplyLimits(ctx, ingestionLimits(25000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	// Unchanged limits still leave the blocked rollout pending
+	if err := p.ApplyLimits(ctx, ingestionLimits(25000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	if restarted(t, kubeClient, cfg, "ingester") {
+		t.Fatalf("ingester was restarted by a retry while its PodDisruptionBudget allows no disruption")
+	}
+
+	if _, err := kubeClient.PolicyV1().PodDisruptionBudgets(cfg.Mimir.Namespace).Update(ctx, componentPDB(cfg, "ingester", 1), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update PodDisruptionBudget: %v", err)
+	}
+	if err := p.ApplyLimits(ctx, ingestionLimits(25000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	if !restarted(t, kubeClient, cfg, "ingester") {
+		t.Errorf("deferred ingester rollout was not retried once a disruption is allowed")
+	}
+	if got := p.PendingRollouts(); len(got) != 0 {
+		t.Errorf("pending rollouts after the retry = %v, want none", got)
+	}
+}
+
+func TestRolloutDeferredByNodeDrain(t *testing.T) {
+	cfg := rolloutConfig()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec: corev1.NodeSpec{
+			Unschedulable: true,
+			Taints:        []corev1.Taint{{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	p, kubeClient := newRolloutPatcher(cfg, rolloutDeployment(cfg, "ingester"), rolloutDeployment(cfg, "querier"), node)
+
+	if err := p.ApplyLimits(context.Background(), ingestionLimits(25000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	for _, component := range cfg.Mimir.RolloutComponents {
+		if restarted(t, kubeClient, cfg, component) {
+			t.Errorf("%s was restarted during a node drain", component)
+		}
+	}
+	if got := p.PendingRollouts(); !reflect.DeepEqual(got, []string{"ingester", "querier"}) {
+		t.Errorf("pending rollouts = %v, want [ingester querier]", got)
+	}
+}
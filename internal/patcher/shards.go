@@ -0,0 +1,279 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// maxConfigMapBytes is the Kubernetes limit on the data stored in a ConfigMap
+const maxConfigMapBytes = 1 << 20
+
+// overridesState is the runtime overrides document together with the ConfigMaps it
+// was read from: the single runtime overrides ConfigMap or, when sharding is enabled,
+// every shard in order. In the sharded format the root ConfigMap comes first, followed
+// by the tenant shards, which are also kept by name.
+type overridesState struct {
+	configMaps   []*corev1.ConfigMap
+	overrides    map[string]interface{}
+	tenantShards map[string]*corev1.ConfigMap
+}
+
+// ShardForTenant returns the shard holding a tenant's overrides. The FNV-1a hash of the
+// tenant ID keeps the assignment stable across restarts and replicas.
+func ShardForTenant(tenant string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tenant))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// sharded reports whether the runtime overrides are split across shard ConfigMaps
+func (p *ConfigMapPatcher) sharded() bool {
+	return p.config().Mimir.Sharding.Enabled && p.config().Mimir.Sharding.Shards > 0
+}
+
+// readOverrides reads the runtime overrides from every ConfigMap holding them,
+// creating missing ones, and merges them into a single canonical document
+func (p *ConfigMapPatcher) readOverrides(ctx context.Context) (*overridesState, error) {
+	if p.tenantSharded() {
+		return p.readTenantShards(ctx)
+	}
+	if !p.sharded() {
+		configMap, err := p.getCurrentConfigMap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current ConfigMap: %w", err)
+		}
+		overrides, err := p.parseOverrides(configMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse current overrides: %w", err)
+		}
+		return &overridesState{configMaps: []*corev1.ConfigMap{configMap}, overrides: overrides}, nil
+	}
+
+	state := &overridesState{}
+	tenantOverrides := make(map[string]interface{})
+	created := 0
+
+	for i, name := range p.config().Mimir.OverridesConfigMapNames() {
+		configMap, missing, err := p.getOrCreateConfigMap(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get runtime overrides shard %s: %w", name, err)
+		}
+		if missing {
+			created++
+		}
+		document, err := p.parseOverrides(configMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse runtime overrides shard %s: %w", name, err)
+		}
+
+		// Sections other than the tenant overrides live in the first shard
+		if i == 0 {
+			state.overrides = document
+		}
+		for tenant, tenantConfig := range TenantOverrides(document) {
+			tenantOverrides[tenant] = tenantConfig
+		}
+		state.configMaps = append(state.configMaps, configMap)
+	}
+
+	if created == len(state.configMaps) {
+		// Seed the shards from the unsharded ConfigMap when they were just created, so
+		// enabling sharding keeps the existing limits. Shards emptied later are not seeded
+		// again, and the unsharded ConfigMap itself is left untouched.
+		seed, err := p.readUnshardedOverrides(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if seed != nil {
+			p.log.Info("seeding runtime overrides shards from unsharded ConfigMap",
+				"configmap", p.config().Mimir.ConfigMapName,
+				"tenants", len(TenantOverrides(seed)),
+				"shards", len(state.configMaps))
+			state.overrides = seed
+			return state, nil
+		}
+	}
+
+	state.overrides[overridesKey] = tenantOverrides
+	return state, nil
+}
+
+// readUnshardedOverrides reads the unsharded runtime overrides ConfigMap without
+// creating it; it returns nil when the ConfigMap does not exist or has no tenants
+func (p *ConfigMapPatcher) readUnshardedOverrides(ctx context.Context) (map[string]interface{}, error) {
+	configMap := &corev1.ConfigMap{}
+	err := p.client.Get(ctx, types.NamespacedName{
+		Name:      p.config().Mimir.ConfigMapName,
+		Namespace: p.config().Mimir.Namespace,
+	}, configMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unsharded ConfigMap: %w", err)
+	}
+
+	overrides, err := p.parseOverrides(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unsharded overrides: %w", err)
+	}
+	if len(TenantOverrides(overrides)) == 0 {
+		return nil, nil
+	}
+	return overrides, nil
+}
+
+// writeOverrides writes the overrides document back to the ConfigMaps it was read
+// from. Only ConfigMaps whose content changes are updated, and every ConfigMap is
+// checked against the size limit before any of them is written.
+func (p *ConfigMapPatcher) writeOverrides(ctx context.Context, state *overridesState, overrides map[string]interface{}) error {
+	if p.tenantSharded() {
+		return p.writeTenantShards(ctx, state, overrides)
+	}
+
+	documents := p.splitOverrides(overrides, len(state.configMaps))
+
+	rendered := make([]string, len(documents))
+	for i, document := range documents {
+		data, err := MarshalOverrides(document, p.config().Mimir.ConfigMapFormat)
+		if err != nil {
+			return fmt.Errorf("failed to marshal overrides to YAML: %w", err)
+		}
+		rendered[i] = string(data)
+
+		if err := p.checkConfigMapSize(state.configMaps[i], rendered[i]); err != nil {
+			return err
+		}
+	}
+
+	for i, configMap := range state.configMaps {
+		if configMap.Data != nil && configMap.Data["overrides.yaml"] == rendered[i] {
+			continue
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data["overrides.yaml"] = rendered[i]
+
+		// Add labels for tracking
+		stampLastUpdate(configMap)
+
+		if err := p.client.Update(ctx, configMap); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitOverrides distributes the tenants of a canonical document across shards.
+// Sections other than the tenant overrides go to the first shard.
+func (p *ConfigMapPatcher) splitOverrides(overrides map[string]interface{}, shards int) []map[string]interface{} {
+	if shards <= 1 {
+		return []map[string]interface{}{overrides}
+	}
+
+	documents := make([]map[string]interface{}, shards)
+	for i := range documents {
+		documents[i] = map[string]interface{}{overridesKey: make(map[string]interface{})}
+	}
+	for key, value := range overrides {
+		if key != overridesKey {
+			documents[0][key] = value
+		}
+	}
+	for tenant, tenantConfig := range TenantOverrides(overrides) {
+		shard := documents[ShardForTenant(tenant, shards)]
+		shard[overridesKey].(map[string]interface{})[tenant] = tenantConfig
+	}
+
+	return documents
+}
+
+// checkConfigMapSize measures a ConfigMap as it would be written. Crossing the
+// configured warning threshold is logged and audited once; exceeding the Kubernetes
+// limit fails the write before the API server rejects it.
+func (p *ConfigMapPatcher) checkConfigMapSize(configMap *corev1.ConfigMap, overridesYAML string) error {
+	size := len("overrides.yaml") + len(overridesYAML)
+	for key, value := range configMap.Data {
+		if key != "overrides.yaml" {
+			size += len(key) + len(value)
+		}
+	}
+	for key, value := range configMap.BinaryData {
+		size += len(key) + len(value)
+	}
+
+	metrics.ConfigMapMetricsInstance.SetConfigMapSize(configMap.Name, float64(size))
+
+	threshold := p.config().Mimir.ConfigMapSizeWarningPercent
+	percent := float64(size) / float64(maxConfigMapBytes) * 100
+
+	if size > maxConfigMapBytes {
+		hint := "enable mimir.sharding to split tenants across ConfigMaps"
+		if p.tenantSharded() {
+			hint = "the overrides of a single tenant cannot be split further"
+		} else if p.sharded() {
+			hint = "increase mimir.sharding.shards"
+		}
+		return fmt.Errorf("runtime overrides ConfigMap %s would be %d bytes, over the %d byte ConfigMap limit; %s",
+			configMap.Name, size, maxConfigMapBytes, hint)
+	}
+
+	p.sizeMu.Lock()
+	defer p.sizeMu.Unlock()
+
+	if threshold <= 0 || percent < threshold {
+		delete(p.sizeWarned, configMap.Name)
+		return nil
+	}
+	if p.sizeWarned[configMap.Name] {
+		return nil
+	}
+	p.sizeWarned[configMap.Name] = true
+
+	metrics.ConfigMapMetricsInstance.IncConfigMapSizeWarnings(configMap.Name)
+	p.log.Info("WARNING: runtime overrides ConfigMap is approaching the size limit",
+		"configmap", configMap.Name,
+		"size_bytes", size,
+		"limit_bytes", maxConfigMapBytes,
+		"percent", fmt.Sprintf("%.1f", percent),
+		"threshold_percent", threshold,
+		"sharding_enabled", p.sharded())
+
+	if p.auditLog != nil {
+		entry := &auditlog.AuditEntry{
+			Timestamp: time.Now(),
+			Action:    "configmap-size-warning",
+			Reason:    "size-threshold-exceeded",
+			Source:    "patcher",
+			Component: "mimir-limit-optimizer",
+			Success:   true,
+			Changes: map[string]interface{}{
+				"configmap":         configMap.Name,
+				"size_bytes":        size,
+				"limit_bytes":       maxConfigMapBytes,
+				"threshold_percent": threshold,
+			},
+		}
+		if err := p.auditLog.LogEntry(entry); err != nil {
+			p.log.Error(err, "failed to log audit entry for ConfigMap size warning (audit failure is non-critical)",
+				"configmap", configMap.Name)
+		}
+	}
+
+	return nil
+}
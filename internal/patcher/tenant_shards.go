@@ -0,0 +1,253 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ShardIndexKey is the key of the root ConfigMap listing the per-tenant shards
+const ShardIndexKey = "shards.yaml"
+
+// TenantShardIndex lists the per-tenant shard ConfigMaps and the tenants each holds
+type TenantShardIndex struct {
+	Shards map[string][]string `json:"shards"`
+}
+
+// TenantShardName returns the ConfigMap holding a tenant's overrides in the sharded
+// format. The FNV-1a hash keeps the name valid and stable for any tenant ID.
+func TenantShardName(root, tenant string) string {
+	h := fnv.New64a()
+	h.Write([]byte(tenant))
+	return fmt.Sprintf("%s-%016x", root, h.Sum64())
+}
+
+// ParseTenantShardIndex parses the shard index of a root ConfigMap; an empty index
+// has no shards
+func ParseTenantShardIndex(data string) (*TenantShardIndex, error) {
+	index := &TenantShardIndex{}
+	if err := yaml.Unmarshal([]byte(data), index); err != nil {
+		return nil, fmt.Errorf("failed to parse tenant shard index: %w", err)
+	}
+	if index.Shards == nil {
+		index.Shards = make(map[string][]string)
+	}
+	return index, nil
+}
+
+// tenantSharded reports whether each tenant's overrides live in their own ConfigMap
+func (p *ConfigMapPatcher) tenantSharded() bool {
+	return p.config().Mimir.ConfigMapFormat == config.ConfigMapFormatSharded
+}
+
+// readTenantShards reads the root ConfigMap, creating it if missing, and every shard
+// its index lists. Tenants still in the root, as before switching to the sharded
+// format, are merged in and moved to their shards on the next write.
+func (p *ConfigMapPatcher) readTenantShards(ctx context.Context) (*overridesState, error) {
+	root, err := p.getCurrentConfigMap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root runtime overrides ConfigMap: %w", err)
+	}
+	overrides, err := p.parseOverrides(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root runtime overrides: %w", err)
+	}
+	index, err := ParseTenantShardIndex(root.Data[ShardIndexKey])
+	if err != nil {
+		return nil, err
+	}
+
+	state := &overridesState{
+		configMaps:   []*corev1.ConfigMap{root},
+		tenantShards: make(map[string]*corev1.ConfigMap),
+	}
+	tenantOverrides := TenantOverrides(overrides)
+
+	names := make([]string, 0, len(index.Shards))
+	for name := range index.Shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		shard := &corev1.ConfigMap{}
+		err := p.client.Get(ctx, types.NamespacedName{Name: name, Namespace: p.config().Mimir.Namespace}, shard)
+		if apierrors.IsNotFound(err) {
+			p.log.Info("tenant shard listed in the index is missing, its tenants are dropped",
+				"configmap", name, "tenants", index.Shards[name])
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tenant shard %s: %w", name, err)
+		}
+		document, err := p.parseOverrides(shard)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse tenant shard %s: %w", name, err)
+		}
+		for tenant, tenantConfig := range TenantOverrides(document) {
+			tenantOverrides[tenant] = tenantConfig
+		}
+		state.tenantShards[name] = shard
+		state.configMaps = append(state.configMaps, shard)
+	}
+
+	overrides[overridesKey] = tenantOverrides
+	state.overrides = overrides
+	return state, nil
+}
+
+// writeTenantShards writes each tenant to its shard ConfigMap, then the root with the
+// shard index and the runtime config sections other than the tenant overrides, and
+// finally deletes the shards no tenant is left in. Every ConfigMap is checked against
+// the size limit before any of them is written.
+func (p *ConfigMapPatcher) writeTenantShards(ctx context.Context, state *overridesState, overrides map[string]interface{}) error {
+	root := state.configMaps[0]
+
+	shards := make(map[string]map[string]interface{})
+	for tenant, tenantConfig := range TenantOverrides(overrides) {
+		name := TenantShardName(root.Name, tenant)
+		if shards[name] == nil {
+			shards[name] = make(map[string]interface{})
+		}
+		shards[name][tenant] = tenantConfig
+	}
+
+	index := &TenantShardIndex{Shards: make(map[string][]string, len(shards))}
+	rendered := make(map[string]string, len(shards))
+	for name, tenants := range shards {
+		data, err := MarshalOverrides(map[string]interface{}{overridesKey: tenants}, config.ConfigMapFormatMimirNative)
+		if err != nil {
+			return fmt.Errorf("failed to marshal overrides of tenant shard %s: %w", name, err)
+		}
+		rendered[name] = string(data)
+
+		existing := state.tenantShards[name]
+		if existing == nil {
+			existing = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		}
+		if err := p.checkConfigMapSize(existing, rendered[name]); err != nil {
+			return err
+		}
+
+		for tenant := range tenants {
+			index.Shards[name] = append(index.Shards[name], tenant)
+		}
+		sort.Strings(index.Shards[name])
+	}
+
+	rootDocument := map[string]interface{}{overridesKey: make(map[string]interface{})}
+	for key, value := range overrides {
+		if key != overridesKey {
+			rootDocument[key] = value
+		}
+	}
+	rootYAML, err := MarshalOverrides(rootDocument, config.ConfigMapFormatMimirNative)
+	if err != nil {
+		return fmt.Errorf("failed to marshal root overrides to YAML: %w", err)
+	}
+	indexYAML, err := yaml.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tenant shard index: %w", err)
+	}
+	if err := p.checkConfigMapSize(root, string(rootYAML)); err != nil {
+		return err
+	}
+
+	// Shards are written before the index lists them, and deleted only after it no
+	// longer does, so the index never points at a shard that does not exist yet
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := p.writeTenantShard(ctx, state.tenantShards[name], name, root.Name, rendered[name]); err != nil {
+			return err
+		}
+	}
+
+	if root.Data == nil || root.Data["overrides.yaml"] != string(rootYAML) || root.Data[ShardIndexKey] != string(indexYAML) {
+		if root.Data == nil {
+			root.Data = make(map[string]string)
+		}
+		root.Data["overrides.yaml"] = string(rootYAML)
+		root.Data[ShardIndexKey] = string(indexYAML)
+		stampLastUpdate(root)
+		if err := p.client.Update(ctx, root); err != nil {
+			return err
+		}
+	}
+
+	for name, shard := range state.tenantShards {
+		if _, kept := shards[name]; kept {
+			continue
+		}
+		if err := p.client.Delete(ctx, shard); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete empty tenant shard %s: %w", name, err)
+		}
+		p.log.Info("deleted tenant shard without tenants", "configmap", name)
+	}
+
+	return nil
+}
+
+// writeTenantShard creates or updates one tenant shard when its content changes. A
+// shard created concurrently by another writer is reported as a conflict so the
+// write is retried against a fresh read.
+func (p *ConfigMapPatcher) writeTenantShard(ctx context.Context, shard *corev1.ConfigMap, name, root, overridesYAML string) error {
+	if shard == nil {
+		shard = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: p.config().Mimir.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":            "mimir",
+					"app.kubernetes.io/component":       "runtime-overrides",
+					"app.kubernetes.io/managed-by":      "mimir-limit-optimizer",
+					"mimir-limit-optimizer/shard-of":    root,
+					"mimir-limit-optimizer/last-update": strconv.FormatInt(time.Now().Unix(), 10),
+				},
+			},
+			Data: map[string]string{"overrides.yaml": overridesYAML},
+		}
+		err := p.client.Create(ctx, shard)
+		if apierrors.IsAlreadyExists(err) {
+			return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, name, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create tenant shard %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if shard.Data != nil && shard.Data["overrides.yaml"] == overridesYAML {
+		return nil
+	}
+	if shard.Data == nil {
+		shard.Data = make(map[string]string)
+	}
+	shard.Data["overrides.yaml"] = overridesYAML
+	stampLastUpdate(shard)
+	return p.client.Update(ctx, shard)
+}
+
+// stampLastUpdate labels a ConfigMap with the time of the write
+func stampLastUpdate(configMap *corev1.ConfigMap) {
+	if configMap.Labels == nil {
+		configMap.Labels = make(map[string]string)
+	}
+	// Use Unix timestamp as it contains only digits and is Kubernetes label-safe
+	configMap.Labels["mimir-limit-optimizer/last-update"] = strconv.FormatInt(time.Now().Unix(), 10)
+}
@@ -0,0 +1,186 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func shardedConfig() *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.ConfigMapFormat = config.ConfigMapFormatSharded
+	return cfg
+}
+
+// ingestionLimits sets the ingestion_rate of each tenant
+func ingestionLimits(rate float64, tenants ...string) map[string]*analyzer.TenantLimits {
+	limits := make(map[string]*analyzer.TenantLimits, len(tenants))
+	for _, tenant := range tenants {
+		limits[tenant] = &analyzer.TenantLimits{
+			Tenant: tenant,
+			Limits: map[string]interface{}{"ingestion_rate": rate},
+		}
+	}
+	return limits
+}
+
+// tenantShards returns the shard ConfigMaps of the root runtime overrides ConfigMap
+func tenantShards(t *testing.T, c client.Client, cfg *config.Config) []corev1.ConfigMap {
+	t.Helper()
+	var list corev1.ConfigMapList
+	err := c.List(context.Background(), &list, client.InNamespace(cfg.Mimir.Namespace),
+		client.MatchingLabels{"mimir-limit-optimizer/shard-of": cfg.Mimir.ConfigMapName})
+	if err != nil {
+		t.Fatalf("list tenant shards: %v", err)
+	}
+	return list.Items
+}
+
+// shardIndex returns the shard index of the root ConfigMap
+func shardIndex(t *testing.T, c client.Client, cfg *config.Config) *TenantShardIndex {
+	t.Helper()
+	root := readConfigMap(t, c, cfg, cfg.Mimir.ConfigMapName)
+	index, err := ParseTenantShardIndex(root.Data[ShardIndexKey])
+	if err != nil {
+		t.Fatalf("parse shard index: %v", err)
+	}
+	return index
+}
+
+func tenantNames(count int) []string {
+	tenants := make([]string, count)
+	for i := range tenants {
+		tenants[i] = fmt.Sprintf("tenant-%d", i)
+	}
+	return tenants
+}
+
+func TestTenantShardsCreatedAndCleanedUp(t *testing.T) {
+	cfg := shardedConfig()
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+	ctx := context.Background()
+	tenants := tenantNames(10)
+
+	if err := p.ApplyLimits(ctx, ingestionLimits(50000, tenants...)); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	shards := tenantShards(t, c, cfg)
+	if len(shards) != 10 {
+		t.Fatalf("%d tenant shards created, want 10", len(shards))
+	}
+	for _, tenant := range tenants {
+		shard := readConfigMap(t, c, cfg, TenantShardName(cfg.Mimir.ConfigMapName, tenant))
+		overrides, _, err := ParseOverridesYAML(shard.Data["overrides.yaml"])
+		if err != nil {
+			t.Fatalf("parse shard of %s: %v", tenant, err)
+		}
+		shardTenants := TenantOverrides(overrides)
+		if len(shardTenants) != 1 || shardTenants[tenant] == nil {
+			t.Errorf("shard of %s holds tenants %v, want only %s", tenant, shardTenants, tenant)
+		}
+	}
+	if index := shardIndex(t, c, cfg); len(index.Shards) != 10 {
+		t.Errorf("shard index lists %d shards, want 10", len(index.Shards))
+	}
+	if root := readTenantOverrides(t, c, cfg, "tenant-0"); root != nil {
+		t.Errorf("root ConfigMap holds tenant overrides %v, want them in the shards", root)
+	}
+
+	removed, err := p.RemoveTenants(ctx, []string{"tenant-3"}, "inactive")
+	if err != nil {
+		t.Fatalf("RemoveTenants: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "tenant-3" {
+		t.Errorf("removed tenants = %v, want [tenant-3]", removed)
+	}
+
+	removedShard := TenantShardName(cfg.Mimir.ConfigMapName, "tenant-3")
+	shards = tenantShards(t, c, cfg)
+	if len(shards) != 9 {
+		t.Errorf("%d tenant shards left, want 9", len(shards))
+	}
+	for _, shard := range shards {
+		if shard.Name == removedShard {
+			t.Errorf("shard of the removed tenant was not deleted")
+		}
+	}
+	if _, listed := shardIndex(t, c, cfg).Shards[removedShard]; listed {
+		t.Errorf("shard index still lists the shard of the removed tenant")
+	}
+}
+
+func TestTenantShardsMergedOnRead(t *testing.T) {
+	cfg := shardedConfig()
+	p, _ := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+	ctx := context.Background()
+
+	if err := p.ApplyLimits(ctx, ingestionLimits(50000, tenantNames(3)...)); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	if err := p.ApplyLimits(ctx, ingestionLimits(80000, "tenant-1")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	current, err := p.GetCurrentLimits(ctx)
+	if err != nil {
+		t.Fatalf("GetCurrentLimits: %v", err)
+	}
+	tenants := make([]string, 0, len(current))
+	for tenant := range current {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	if strings.Join(tenants, ",") != "tenant-0,tenant-1,tenant-2" {
+		t.Fatalf("current limits of %v, want every shard merged", tenants)
+	}
+	if got := fmt.Sprint(current["tenant-1"].Limits["ingestion_rate"]); got != "80000" {
+		t.Errorf("tenant-1 ingestion_rate = %s, want the updated 80000", got)
+	}
+	if got := fmt.Sprint(current["tenant-0"].Limits["ingestion_rate"]); got != "50000" {
+		t.Errorf("tenant-0 ingestion_rate = %s, want 50000", got)
+	}
+}
+
+func TestTenantShardsMoveRootTenants(t *testing.T) {
+	cfg := shardedConfig()
+	root := overridesConfigMap(cfg, cfg.Mimir.ConfigMapName,
+		"overrides:\n  legacy-tenant:\n    ingestion_rate: 20000\n")
+	p, c := newTestPatcher(cfg, root)
+
+	if err := p.ApplyLimits(context.Background(), ingestionLimits(50000, "tenant-a")); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	if legacy := readTenantOverrides(t, c, cfg, "legacy-tenant"); legacy != nil {
+		t.Errorf("legacy tenant left in the root ConfigMap: %v", legacy)
+	}
+	shard := readConfigMap(t, c, cfg, TenantShardName(cfg.Mimir.ConfigMapName, "legacy-tenant"))
+	if !strings.Contains(shard.Data["overrides.yaml"], "ingestion_rate: 20000") {
+		t.Errorf("legacy tenant shard = %q, want its overrides kept", shard.Data["overrides.yaml"])
+	}
+	if len(tenantShards(t, c, cfg)) != 2 {
+		t.Errorf("want a shard for the legacy tenant and for tenant-a")
+	}
+}
+
+func TestTenantShardName(t *testing.T) {
+	name := TenantShardName("mimir-runtime-overrides", "Team/A")
+	if name != TenantShardName("mimir-runtime-overrides", "Team/A") {
+		t.Errorf("shard name is not stable")
+	}
+	if !strings.HasPrefix(name, "mimir-runtime-overrides-") || strings.ContainsAny(name, "/A") {
+		t.Errorf("shard name %q is not a valid ConfigMap name under the root's", name)
+	}
+	if name == TenantShardName("mimir-runtime-overrides", "Team/B") {
+		t.Errorf("different tenants share the shard %s", name)
+	}
+}
@@ -0,0 +1,185 @@
+package patcher
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// thanosRulerLimitsKey is the key of the Thanos Ruler limits file in its ConfigMap
+const thanosRulerLimitsKey = "limits.yaml"
+
+// splitRulerLimits separates the ruler limits from the other limits of each tenant. It
+// returns the limits without ruler limits, for the runtime overrides, and the ruler
+// limits Thanos Ruler enforces; the other ruler limits have no effect with Thanos Ruler
+// and are dropped.
+func splitRulerLimits(limits map[string]*analyzer.TenantLimits) (map[string]*analyzer.TenantLimits, map[string]*analyzer.TenantLimits) {
+	mimirLimits := make(map[string]*analyzer.TenantLimits, len(limits))
+	rulerLimits := make(map[string]*analyzer.TenantLimits)
+	for tenant, tenantLimits := range limits {
+		mimir, ruler := *tenantLimits, *tenantLimits
+		mimir.Limits = make(map[string]interface{}, len(tenantLimits.Limits))
+		ruler.Limits = make(map[string]interface{})
+		for limitName, value := range tenantLimits.Limits {
+			switch {
+			case !config.IsRulerLimit(limitName):
+				mimir.Limits[limitName] = value
+			case config.ThanosRulerLimits[limitName] != "":
+				ruler.Limits[limitName] = value
+			}
+		}
+
+		mimirLimits[tenant] = &mimir
+		if len(ruler.Limits) > 0 {
+			rulerLimits[tenant] = &ruler
+		}
+	}
+	return mimirLimits, rulerLimits
+}
+
+// applyThanosRulerLimits merges the rule group limits of each tenant into the Thanos
+// Ruler limits ConfigMap, in a single write retried on conflict and independent of the
+// runtime overrides write. Tenants skipped or paused for the runtime overrides are
+// skipped here too.
+func (p *ConfigMapPatcher) applyThanosRulerLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) error {
+	var changes map[string]*TenantLimitChange
+	err := retry.RetryOnConflict(configMapWriteBackoff, func() error {
+		configMap, err := p.getThanosRulerConfigMap(ctx)
+		if err != nil {
+			return err
+		}
+
+		document := make(map[string]interface{})
+		if data := configMap.Data[thanosRulerLimitsKey]; data != "" {
+			if err := yaml.Unmarshal([]byte(data), &document); err != nil {
+				return fmt.Errorf("failed to parse Thanos Ruler limits: %w", err)
+			}
+		}
+
+		changes = p.applyLimitsToThanosRuler(document, limits)
+		if len(changes) == 0 {
+			return nil
+		}
+
+		data, err := yaml.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to marshal Thanos Ruler limits: %w", err)
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[thanosRulerLimitsKey] = string(data)
+		stampLastUpdate(configMap)
+
+		if configMap.ResourceVersion == "" {
+			return p.client.Create(ctx, configMap)
+		}
+		return p.client.Update(ctx, configMap)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update Thanos Ruler limits ConfigMap: %w", err)
+	}
+
+	if len(changes) > 0 {
+		p.logChanges(changes, limits)
+		p.log.Info("wrote ruler limits to Thanos Ruler limits ConfigMap",
+			"tenants_changed", len(changes),
+			"configmap", p.config().Mimir.ThanosRulerConfigMapName,
+			"namespace", p.config().Mimir.Namespace)
+	}
+	return nil
+}
+
+// getThanosRulerConfigMap returns the Thanos Ruler limits ConfigMap, or a new one without
+// a resource version when it does not exist yet
+func (p *ConfigMapPatcher) getThanosRulerConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	configMap := &corev1.ConfigMap{}
+	err := p.client.Get(ctx, types.NamespacedName{
+		Name:      p.config().Mimir.ThanosRulerConfigMapName,
+		Namespace: p.config().Mimir.Namespace,
+	}, configMap)
+	if apierrors.IsNotFound(err) {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      p.config().Mimir.ThanosRulerConfigMapName,
+				Namespace: p.config().Mimir.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       "thanos-ruler",
+					"app.kubernetes.io/component":  "ruler-limits",
+					"app.kubernetes.io/managed-by": "mimir-limit-optimizer",
+				},
+			},
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Thanos Ruler limits ConfigMap: %w", err)
+	}
+	return configMap, nil
+}
+
+// applyLimitsToThanosRuler merges the rule group limits into a Thanos Ruler limits
+// document, whose tenants are under ruler.tenants keyed by the Thanos limit names, and
+// returns the per-tenant changes by ruler limit name
+func (p *ConfigMapPatcher) applyLimitsToThanosRuler(document map[string]interface{}, limits map[string]*analyzer.TenantLimits) map[string]*TenantLimitChange {
+	ruler, ok := document["ruler"].(map[string]interface{})
+	if !ok {
+		ruler = make(map[string]interface{})
+		document["ruler"] = ruler
+	}
+	tenants, ok := ruler["tenants"].(map[string]interface{})
+	if !ok {
+		tenants = make(map[string]interface{})
+		ruler["tenants"] = tenants
+	}
+
+	changes := make(map[string]*TenantLimitChange)
+	for tenant, tenantLimits := range limits {
+		if p.shouldSkipTenant(tenant) || p.isPaused(tenant) {
+			continue
+		}
+
+		tenantConfig, ok := tenants[tenant].(map[string]interface{})
+		if !ok {
+			tenantConfig = make(map[string]interface{})
+		}
+		change := &TenantLimitChange{
+			OldValues: make(map[string]interface{}),
+			NewValues: make(map[string]interface{}),
+		}
+
+		for limitName, value := range tenantLimits.Limits {
+			limitDef, exists := p.config().DynamicLimits.LimitDefinitions[limitName]
+			if !exists || !limitDef.Enabled || value == nil || p.isZeroValue(value) {
+				continue
+			}
+			converted, err := p.convertLimitValue(value, limitDef.Type)
+			if err != nil {
+				p.log.Error(err, "failed to convert ruler limit value to proper type",
+					"tenant", tenant, "limit", limitName, "value", value)
+				continue
+			}
+
+			thanosName := config.ThanosRulerLimits[limitName]
+			if existing, had := tenantConfig[thanosName]; !had || !config.LimitValuesEqual(existing, converted) {
+				change.OldValues[limitName] = existing
+				change.NewValues[limitName] = converted
+				tenantConfig[thanosName] = converted
+			}
+		}
+
+		if len(change.NewValues) > 0 {
+			tenants[tenant] = tenantConfig
+			changes[tenant] = change
+		}
+	}
+	return changes
+}
@@ -0,0 +1,170 @@
+package patcher
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// rulerConfig enables the ruler limits, Thanos Ruler enforcing the rule group ones
+// when thanosMode is set
+func rulerConfig(thanosMode bool) *config.Config {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.ThanosModeEnabled = thanosMode
+	enableLimits(cfg, "ruler_max_rules_per_rule_group", "ruler_max_rule_groups_per_tenant", "ruler_tenant_shard_size")
+	return cfg
+}
+
+func rulerLimits() map[string]*analyzer.TenantLimits {
+	return map[string]*analyzer.TenantLimits{
+		"tenant-a": {
+			Tenant: "tenant-a",
+			Limits: map[string]interface{}{
+				"ingestion_rate":                   20000.0,
+				"ruler_max_rules_per_rule_group":   50.0,
+				"ruler_max_rule_groups_per_tenant": 20.0,
+				"ruler_tenant_shard_size":          3.0,
+			},
+			Reason: "trend-analysis",
+		},
+	}
+}
+
+// readThanosRulerTenants returns the tenants of the Thanos Ruler limits ConfigMap
+func readThanosRulerTenants(t *testing.T, c client.Client, cfg *config.Config) map[string]map[string]interface{} {
+	t.Helper()
+	configMap := readConfigMap(t, c, cfg, cfg.Mimir.ThanosRulerConfigMapName)
+	var document struct {
+		Ruler struct {
+			Tenants map[string]map[string]interface{} `json:"tenants"`
+		} `json:"ruler"`
+	}
+	if err := yaml.Unmarshal([]byte(configMap.Data[thanosRulerLimitsKey]), &document); err != nil {
+		t.Fatalf("parse Thanos Ruler limits: %v", err)
+	}
+	return document.Ruler.Tenants
+}
+
+func TestThanosModeWritesRulerLimitsToThanosConfigMap(t *testing.T) {
+	cfg := rulerConfig(true)
+	thanosLimits := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Mimir.ThanosRulerConfigMapName, Namespace: cfg.Mimir.Namespace},
+		Data:       map[string]string{thanosRulerLimitsKey: "ruler:\n  tenants:\n    tenant-b:\n      max_rules_per_rule_group: 10\n"},
+	}
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"), thanosLimits)
+
+	if err := p.ApplyLimits(context.Background(), rulerLimits()); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+
+	overrides := readTenantOverrides(t, c, cfg, "tenant-a")
+	if overrides["ingestion_rate"] == nil {
+		t.Errorf("runtime overrides of tenant-a = %v, want its ingestion_rate", overrides)
+	}
+	for limitName := range overrides {
+		if config.IsRulerLimit(limitName) {
+			t.Errorf("runtime overrides hold %s in Thanos mode", limitName)
+		}
+	}
+
+	tenants := readThanosRulerTenants(t, c, cfg)
+	want := map[string]interface{}{"max_rules_per_rule_group": 50.0, "max_rule_groups_per_tenant": 20.0}
+	if got := tenants["tenant-a"]; len(got) != len(want) || got["max_rules_per_rule_group"] != want["max_rules_per_rule_group"] ||
+		got["max_rule_groups_per_tenant"] != want["max_rule_groups_per_tenant"] {
+		t.Errorf("Thanos Ruler limits of tenant-a = %v, want %v", got, want)
+	}
+	if got := tenants["tenant-b"]["max_rules_per_rule_group"]; got != 10.0 {
+		t.Errorf("Thanos Ruler limits of tenant-b = %v, want its 10 rules per group kept", tenants["tenant-b"])
+	}
+
+	// Unchanged ruler limits are not written again
+	before := readConfigMap(t, c, cfg, cfg.Mimir.ThanosRulerConfigMapName).ResourceVersion
+	if err := p.ApplyLimits(context.Background(), rulerLimits()); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	if after := readConfigMap(t, c, cfg, cfg.Mimir.ThanosRulerConfigMapName).ResourceVersion; after != before {
+		t.Errorf("unchanged ruler limits rewrote the Thanos Ruler limits ConfigMap")
+	}
+}
+
+func TestThanosModeCreatesThanosConfigMap(t *testing.T) {
+	cfg := rulerConfig(true)
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+	if err := p.ApplyLimits(context.Background(), rulerLimits()); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	configMap := readConfigMap(t, c, cfg, cfg.Mimir.ThanosRulerConfigMapName)
+	if configMap.Labels["app.kubernetes.io/managed-by"] != "mimir-limit-optimizer" {
+		t.Errorf("Thanos Ruler limits ConfigMap labels = %v, want managed by the optimizer", configMap.Labels)
+	}
+	if tenants := readThanosRulerTenants(t, c, cfg); tenants["tenant-a"]["max_rule_groups_per_tenant"] != 20.0 {
+		t.Errorf("Thanos Ruler limits = %v, want tenant-a's 20 rule groups", tenants)
+	}
+}
+
+func TestRulerLimitsInOverridesWithoutThanosMode(t *testing.T) {
+	cfg := rulerConfig(false)
+	p, c := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+	if err := p.ApplyLimits(context.Background(), rulerLimits()); err != nil {
+		t.Fatalf("ApplyLimits: %v", err)
+	}
+	overrides := readTenantOverrides(t, c, cfg, "tenant-a")
+	for _, limitName := range []string{"ruler_max_rules_per_rule_group", "ruler_max_rule_groups_per_tenant", "ruler_tenant_shard_size"} {
+		if overrides[limitName] == nil {
+			t.Errorf("runtime overrides of tenant-a lack %s without Thanos mode: %v", limitName, overrides)
+		}
+	}
+	err := c.Get(context.Background(), types.NamespacedName{Name: cfg.Mimir.ThanosRulerConfigMapName, Namespace: cfg.Mimir.Namespace}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Thanos Ruler limits ConfigMap lookup = %v, want it never created", err)
+	}
+}
+
+func TestThanosModePreviewLeavesOutRulerLimits(t *testing.T) {
+	cfg := rulerConfig(true)
+	p, _ := newTestPatcher(cfg, overridesConfigMap(cfg, cfg.Mimir.ConfigMapName, "overrides: {}\n"))
+
+	preview, err := p.PreviewLimits(context.Background(), rulerLimits())
+	if err != nil {
+		t.Fatalf("PreviewLimits: %v", err)
+	}
+	change := preview.Changes["tenant-a"]
+	if change == nil || change.NewValues["ingestion_rate"] == nil {
+		t.Fatalf("preview changes = %v, want tenant-a's ingestion_rate", preview.Changes)
+	}
+	for limitName := range change.NewValues {
+		if config.IsRulerLimit(limitName) {
+			t.Errorf("preview of the runtime overrides proposes %s in Thanos mode", limitName)
+		}
+	}
+}
+
+func TestSplitRulerLimits(t *testing.T) {
+	mimir, ruler := splitRulerLimits(map[string]*analyzer.TenantLimits{
+		"tenant-a": rulerLimits()["tenant-a"],
+		"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"ingestion_rate": 1000.0}},
+	})
+	if len(mimir["tenant-a"].Limits) != 1 || mimir["tenant-a"].Limits["ingestion_rate"] != 20000.0 {
+		t.Errorf("runtime overrides limits of tenant-a = %v, want only ingestion_rate", mimir["tenant-a"].Limits)
+	}
+	if len(ruler["tenant-a"].Limits) != 2 || ruler["tenant-a"].Limits["ruler_tenant_shard_size"] != nil {
+		t.Errorf("Thanos Ruler limits of tenant-a = %v, want the 2 rule group limits", ruler["tenant-a"].Limits)
+	}
+	if _, exists := ruler["tenant-b"]; exists {
+		t.Errorf("tenant-b without ruler limits has Thanos Ruler limits")
+	}
+	if mimir["tenant-b"] == nil {
+		t.Errorf("tenant-b is missing from the runtime overrides limits")
+	}
+}
@@ -0,0 +1,204 @@
+package remoteoverrides
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// overridesKey is the top-level key Mimir's runtime config nests tenant limits under.
+// Documents may use it or list tenants at the top level.
+const overridesKey = "overrides"
+
+// maxDocumentBytes bounds the size of a fetched overrides document
+const maxDocumentBytes = 8 << 20
+
+// TenantOverrides maps tenant IDs to limit names and values
+type TenantOverrides map[string]map[string]interface{}
+
+// Source fetches per-tenant limits from a remote document, such as a raw file served
+// from a GitOps repository. The last successfully fetched document is cached and
+// kept in use while fetches fail.
+type Source struct {
+	config config.RemoteOverrideConfig
+	client *http.Client
+	log    logr.Logger
+
+	mu          sync.RWMutex
+	overrides   TenantOverrides
+	etag        string
+	lastAttempt time.Time
+	lastSuccess time.Time
+	lastError   error
+}
+
+// NewSource creates a remote override source
+func NewSource(cfg config.RemoteOverrideConfig, log logr.Logger) *Source {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Source{
+		config: cfg,
+		client: &http.Client{Timeout: timeout},
+		log:    log,
+	}
+}
+
+// Overrides returns the remote tenant limits, fetching them first when the poll
+// interval has passed since the last attempt. On fetch failure the last cached
+// overrides are returned, or nil if none was ever fetched.
+func (s *Source) Overrides(ctx context.Context) TenantOverrides {
+	s.mu.RLock()
+	due := time.Since(s.lastAttempt) >= s.config.PollInterval
+	s.mu.RUnlock()
+
+	if due {
+		if err := s.Refresh(ctx); err != nil {
+			s.log.Error(err, "failed to fetch remote overrides, using last fetched copy",
+				"url", s.config.URL, "last_success", s.LastSuccess())
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.overrides
+}
+
+// Refresh fetches the remote document now. The cached overrides are only replaced
+// when the document is fetched and parsed successfully.
+func (s *Source) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.lastAttempt = time.Now()
+	etag := s.etag
+	s.mu.Unlock()
+
+	overrides, newETag, notModified, err := s.fetch(ctx, etag)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err
+	if err != nil {
+		return err
+	}
+
+	s.lastSuccess = time.Now()
+	metrics.RemoteOverrideMetricsInstance.SetLastFetchSuccess(float64(s.lastSuccess.Unix()))
+	if notModified {
+		s.log.V(1).Info("remote overrides not modified", "url", s.config.URL)
+		return nil
+	}
+
+	s.overrides = overrides
+	s.etag = newETag
+	s.log.Info("fetched remote overrides", "url", s.config.URL, "tenants", len(overrides))
+	return nil
+}
+
+// Cached returns the last fetched overrides without fetching
+func (s *Source) Cached() TenantOverrides {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.overrides
+}
+
+// LastSuccess returns when the document was last fetched successfully
+func (s *Source) LastSuccess() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastSuccess
+}
+
+// LastError returns the error of the last fetch, or nil if it succeeded
+func (s *Source) LastError() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastError
+}
+
+func (s *Source) fetch(ctx context.Context, etag string) (TenantOverrides, string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.URL, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, value := range s.config.Headers {
+		req.Header.Set(key, value)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to fetch remote overrides: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("remote overrides request returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentBytes+1))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to read remote overrides: %w", err)
+	}
+	if len(body) > maxDocumentBytes {
+		return nil, "", false, fmt.Errorf("remote overrides document exceeds %d bytes", maxDocumentBytes)
+	}
+
+	overrides, err := Parse(body, s.config.Format)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return overrides, resp.Header.Get("ETag"), false, nil
+}
+
+// Parse parses an overrides document in the given format ("yaml" or "json"). Tenants
+// may be listed at the top level or, as in Mimir's runtime config, under the
+// overrides key.
+func Parse(data []byte, format string) (TenantOverrides, error) {
+	var document map[string]interface{}
+	switch format {
+	case "json":
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse remote overrides JSON: %w", err)
+		}
+	case "yaml", "":
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse remote overrides YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported remote overrides format %q", format)
+	}
+
+	if nested, ok := document[overridesKey].(map[string]interface{}); ok {
+		document = nested
+	}
+
+	overrides := make(TenantOverrides, len(document))
+	for tenant, value := range document {
+		if value == nil {
+			continue
+		}
+		limits, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("limits of tenant %s must be a map of limit names to values, got %T", tenant, value)
+		}
+		overrides[tenant] = limits
+	}
+	return overrides, nil
+}
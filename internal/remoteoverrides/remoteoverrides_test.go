@@ -0,0 +1,182 @@
+package remoteoverrides
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+const fixtureYAML = `overrides:
+  tenant-a:
+    ingestion_rate: 50000
+    max_global_series_per_user: 2000000
+  tenant-b:
+    ingestion_rate_strategy: global
+  tenant-empty:
+`
+
+// fixtureServer serves body with etag, recording the requests it receives. Setting
+// status makes it fail with that status instead.
+type fixtureServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	body     string
+	etag     string
+	status   int
+	requests []*http.Request
+}
+
+func newFixtureServer(t *testing.T, body string) *fixtureServer {
+	f := &fixtureServer{body: body, etag: `"v1"`}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r)
+		switch {
+		case f.status != 0:
+			w.WriteHeader(f.status)
+		case r.Header.Get("If-None-Match") == f.etag:
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.Header().Set("ETag", f.etag)
+			_, _ = w.Write([]byte(f.body))
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fixtureServer) fail(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+}
+
+func (f *fixtureServer) lastRequest() *http.Request {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[len(f.requests)-1]
+}
+
+func newTestSource(url, format string) *Source {
+	return NewSource(config.RemoteOverrideConfig{
+		Enabled: true,
+		URL:     url,
+		Format:  format,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Timeout: time.Second,
+	}, logr.Discard())
+}
+
+func TestSourceFetchesFixture(t *testing.T) {
+	server := newFixtureServer(t, fixtureYAML)
+	source := newTestSource(server.URL, "yaml")
+
+	got := source.Overrides(context.Background())
+	want := TenantOverrides{
+		"tenant-a": {"ingestion_rate": float64(50000), "max_global_series_per_user": float64(2000000)},
+		"tenant-b": {"ingestion_rate_strategy": "global"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Overrides = %v, want %v", got, want)
+	}
+	if auth := server.lastRequest().Header.Get("Authorization"); auth != "Bearer token" {
+		t.Errorf("Authorization header = %q, want the configured header", auth)
+	}
+	if source.LastSuccess().IsZero() || source.LastError() != nil {
+		t.Errorf("LastSuccess = %v, LastError = %v, want a successful fetch", source.LastSuccess(), source.LastError())
+	}
+}
+
+func TestSourceKeepsCachedOverridesOnFailure(t *testing.T) {
+	server := newFixtureServer(t, fixtureYAML)
+	source := newTestSource(server.URL, "yaml")
+	ctx := context.Background()
+	if err := source.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	lastSuccess := source.LastSuccess()
+
+	server.fail(http.StatusInternalServerError)
+	got := source.Overrides(ctx)
+	if got["tenant-a"]["ingestion_rate"] != float64(50000) {
+		t.Errorf("Overrides after a failed fetch = %v, want the cached document", got)
+	}
+	if source.LastError() == nil {
+		t.Errorf("LastError after a failed fetch = nil")
+	}
+	if !source.LastSuccess().Equal(lastSuccess) {
+		t.Errorf("LastSuccess moved to %v on a failed fetch", source.LastSuccess())
+	}
+}
+
+func TestSourceNotModified(t *testing.T) {
+	server := newFixtureServer(t, fixtureYAML)
+	source := newTestSource(server.URL, "yaml")
+	ctx := context.Background()
+	if err := source.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	if err := source.Refresh(ctx); err != nil {
+		t.Fatalf("second Refresh: %v", err)
+	}
+	if etag := server.lastRequest().Header.Get("If-None-Match"); etag != `"v1"` {
+		t.Errorf("If-None-Match = %q, want the ETag of the first response", etag)
+	}
+	if len(source.Cached()) != 2 {
+		t.Errorf("Cached after 304 = %v, want the first document", source.Cached())
+	}
+}
+
+func TestSourceHonoursPollInterval(t *testing.T) {
+	server := newFixtureServer(t, fixtureYAML)
+	source := newTestSource(server.URL, "yaml")
+	source.config.PollInterval = time.Hour
+	ctx := context.Background()
+
+	source.Overrides(ctx)
+	source.Overrides(ctx)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.requests) != 1 {
+		t.Errorf("requests = %d, want 1 within the poll interval", len(server.requests))
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		want    TenantOverrides
+		wantErr bool
+	}{
+		{"top-level tenants", "tenant-a:\n  ingestion_rate: 100\n", "yaml", TenantOverrides{"tenant-a": {"ingestion_rate": float64(100)}}, false},
+		{"json", `{"overrides": {"tenant-a": {"ingestion_rate": 100}}}`, "json", TenantOverrides{"tenant-a": {"ingestion_rate": float64(100)}}, false},
+		{"limits not a map", "tenant-a: 100\n", "yaml", nil, true},
+		{"invalid yaml", "tenant-a: [\n", "yaml", nil, true},
+		{"unsupported format", "tenant-a: {}\n", "toml", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
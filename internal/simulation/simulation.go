@@ -0,0 +1,178 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// ingestionMetric is the metric the ingestion rate recommendations are followed on
+const ingestionMetric = "cortex_distributor_received_samples_total"
+
+// TenantReport is what the simulation observed for one synthetic tenant
+type TenantReport struct {
+	Tenant  string `json:"tenant"`
+	Profile string `json:"profile"`
+	// ConfiguredSpike is the multiplier of the tenant's configured spike, 0 without one
+	ConfiguredSpike float64 `json:"configured_spike,omitempty"`
+	SpikeDetected   bool    `json:"spike_detected"`
+	// SpikeMultiplier is the highest spike multiplier applied to its recommendations
+	SpikeMultiplier float64 `json:"spike_multiplier,omitempty"`
+	// BaselineRecommendation is the ingestion rate recommended before the spike started,
+	// or at the end of the simulation without a spike
+	BaselineRecommendation float64 `json:"baseline_recommendation"`
+	PeakRecommendation     float64 `json:"peak_recommendation"`
+}
+
+// Report is the result of a simulation
+type Report struct {
+	SimulatedTime string `json:"simulated_time"`
+	Collections   int    `json:"collections"`
+	// BreakerTrips counts the times the circuit breaker opened
+	BreakerTrips int            `json:"breaker_trips"`
+	BreakerState string         `json:"breaker_state"`
+	Tenants      []TenantReport `json:"tenants"`
+}
+
+// Run drives the trend analysis, spike detection and circuit breaker with the metrics of
+// the synthetic collector, on its simulated clock, until the analysis window and every
+// configured spike have been simulated
+func Run(ctx context.Context, cfg *config.Config, log logr.Logger) (*Report, error) {
+	if !cfg.Synthetic.Enabled {
+		return nil, fmt.Errorf("the simulation needs synthetic.enabled")
+	}
+
+	live := config.NewLive(cfg)
+	synthetic := collector.NewSyntheticCollector(live, log.WithName("synthetic"))
+	trendAnalyzer := analyzer.NewTrendAnalyzer(live, log.WithName("analyzer"))
+	trendAnalyzer.SetClock(synthetic.Now)
+	protector := circuitbreaker.NewBlastProtector(live, log.WithName("protection"))
+	protector.SetClock(synthetic.Now)
+
+	metricsConfig := cfg.Synthetic.MetricsConfig
+	start := synthetic.Now()
+	step := time.Duration(float64(cfg.UpdateInterval) * metricsConfig.TimeAcceleration)
+	if step <= 0 {
+		return nil, fmt.Errorf("updateInterval times synthetic.metricsConfig.timeAcceleration must be positive")
+	}
+	duration := cfg.TrendAnalysis.AnalysisWindow
+	spikes := make(map[string]*config.SyntheticSpikeConfig)
+	profiles := make(map[string]string)
+	for _, tenant := range synthetic.Tenants() {
+		profiles[tenant.Name] = tenant.Profile
+		if spike := tenant.Spike; spike != nil {
+			spikes[tenant.Name] = spike
+			if end := spike.After + spike.Duration; end > duration {
+				duration = end
+			}
+		}
+	}
+	collections := int(math.Ceil(float64(duration) / float64(step)))
+
+	tenantReports := make(map[string]*TenantReport)
+	report := &Report{}
+	state := circuitbreaker.StateClosed.String()
+	for i := 0; i < collections; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		tenantMetrics, err := synthetic.CollectMetrics(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect synthetic metrics: %w", err)
+		}
+		protectedMetrics, err := protector.ProcessMetrics(ctx, tenantMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply blast protection: %w", err)
+		}
+		if current := fmt.Sprint(protector.GetProtectionStatus()["circuit_breaker_state"]); current != state {
+			if current == circuitbreaker.StateOpen.String() {
+				report.BreakerTrips++
+			}
+			state = current
+		}
+		if _, err := trendAnalyzer.DetectSpikes(ctx, protectedMetrics); err != nil {
+			return nil, fmt.Errorf("failed to detect spikes: %w", err)
+		}
+		results, err := trendAnalyzer.AnalyzeTrends(ctx, protectedMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze trends: %w", err)
+		}
+
+		now := synthetic.Now()
+		for tenant, tenantResults := range results {
+			tenantReport := tenantReports[tenant]
+			if tenantReport == nil {
+				tenantReport = &TenantReport{Tenant: tenant}
+				tenantReports[tenant] = tenantReport
+			}
+			for _, result := range tenantResults {
+				if result.MetricName != ingestionMetric {
+					continue
+				}
+				if spike := spikes[tenant]; spike == nil || now.Before(start.Add(spike.After)) {
+					tenantReport.BaselineRecommendation = result.RecommendedLimit
+				}
+				tenantReport.PeakRecommendation = math.Max(tenantReport.PeakRecommendation, result.RecommendedLimit)
+				if result.SpikeDetected {
+					tenantReport.SpikeDetected = true
+					tenantReport.SpikeMultiplier = math.Max(tenantReport.SpikeMultiplier, result.SpikeMultiplier)
+				}
+			}
+		}
+	}
+
+	for tenant, tenantReport := range tenantReports {
+		tenantReport.Profile = profiles[tenant]
+		if spike := spikes[tenant]; spike != nil {
+			tenantReport.ConfiguredSpike = spike.Multiplier
+		}
+		report.Tenants = append(report.Tenants, *tenantReport)
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool { return report.Tenants[i].Tenant < report.Tenants[j].Tenant })
+
+	report.SimulatedTime = synthetic.Now().Sub(start).String()
+	report.Collections = collections
+	report.BreakerState = state
+	return report, nil
+}
+
+// Verify checks the report against what the configuration should have produced: each
+// configured spike above eventSpike.threshold must raise the tenant's recommendations by
+// a spike multiplier, and a spike above circuitBreaker.blastProtection.baselineMultiplier
+// must trip the circuit breaker
+func (r *Report) Verify(cfg *config.Config) error {
+	var failures []string
+	expectTrip := false
+	for _, tenant := range r.Tenants {
+		if tenant.ConfiguredSpike == 0 {
+			continue
+		}
+		if cfg.EventSpike.Enabled && tenant.ConfiguredSpike > cfg.EventSpike.Threshold {
+			if !tenant.SpikeDetected || tenant.PeakRecommendation <= tenant.BaselineRecommendation {
+				failures = append(failures, fmt.Sprintf("tenant %s: %.1fx spike did not produce a spike-adjusted recommendation", tenant.Tenant, tenant.ConfiguredSpike))
+			}
+		}
+		if tenant.ConfiguredSpike > cfg.CircuitBreaker.BlastProtection.BaselineMultiplier {
+			expectTrip = true
+		}
+	}
+	if expectTrip && cfg.CircuitBreaker.Enabled && cfg.CircuitBreaker.RuntimeEnabled && r.BreakerTrips == 0 {
+		failures = append(failures, fmt.Sprintf("circuit breaker did not trip at baseline multiplier %.1f", cfg.CircuitBreaker.BlastProtection.BaselineMultiplier))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("simulation failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
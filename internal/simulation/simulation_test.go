@@ -0,0 +1,127 @@
+package simulation
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// syntheticConfig loads the configuration make simulate replays
+func syntheticConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfigFromFile("../../synthetic-config.yaml")
+	if err != nil {
+		t.Fatalf("load synthetic-config.yaml: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("synthetic-config.yaml is invalid: %v", err)
+	}
+	return cfg
+}
+
+func tenantReport(t *testing.T, report *Report, tenant string) TenantReport {
+	t.Helper()
+	for _, tenantReport := range report.Tenants {
+		if tenantReport.Tenant == tenant {
+			return tenantReport
+		}
+	}
+	t.Fatalf("report has no tenant %s", tenant)
+	return TenantReport{}
+}
+
+func TestSimulatedSpikeHandled(t *testing.T) {
+	cfg := syntheticConfig(t)
+	report, err := Run(context.Background(), cfg, logr.Discard())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if err := report.Verify(cfg); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	// 48 collections of a simulated hour each
+	if report.Collections != 48 || report.SimulatedTime != "48h0m0s" {
+		t.Errorf("simulated %d collections over %s, want 48 over 48h", report.Collections, report.SimulatedTime)
+	}
+	if len(report.Tenants) != 5 {
+		t.Errorf("report has %d tenants, want the 5 configured", len(report.Tenants))
+	}
+
+	spiking := tenantReport(t, report, "spiking-tenant")
+	if !spiking.SpikeDetected || spiking.SpikeMultiplier < cfg.EventSpike.Threshold {
+		t.Errorf("spiking-tenant spike detected %v at %.2fx, want the 3x spike detected", spiking.SpikeDetected, spiking.SpikeMultiplier)
+	}
+	if spiking.PeakRecommendation <= spiking.BaselineRecommendation*2 {
+		t.Errorf("spiking-tenant recommendation rose from %.0f to %.0f, want spike-adjusted", spiking.BaselineRecommendation, spiking.PeakRecommendation)
+	}
+	if report.BreakerTrips == 0 {
+		t.Errorf("the 3x spike did not trip the circuit breaker at a %.1fx baseline multiplier", cfg.CircuitBreaker.BlastProtection.BaselineMultiplier)
+	}
+
+	for _, tenant := range []string{"steady-tenant", "diurnal-tenant", "bursty-tenant", "growing-tenant"} {
+		if tenantReport(t, report, tenant).SpikeDetected {
+			t.Errorf("%s without a spike has a spike detected", tenant)
+		}
+	}
+}
+
+func TestSimulationIsDeterministic(t *testing.T) {
+	cfg := syntheticConfig(t)
+	cfg.TrendAnalysis.AnalysisWindow = cfg.TrendAnalysis.AnalysisWindow / 4
+	cfg.Synthetic.MetricsConfig.Tenants = cfg.Synthetic.MetricsConfig.Tenants[:4]
+
+	first, err := Run(context.Background(), cfg, logr.Discard())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	second, err := Run(context.Background(), cfg, logr.Discard())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("two runs of seed %d differ:\n%+v\n%+v", cfg.Synthetic.MetricsConfig.Seed, first, second)
+	}
+}
+
+func TestVerifyReportsUnhandledSpikes(t *testing.T) {
+	cfg := syntheticConfig(t)
+	report := &Report{
+		Tenants: []TenantReport{{
+			Tenant:                 "spiking-tenant",
+			ConfiguredSpike:        3,
+			BaselineRecommendation: 12000,
+			PeakRecommendation:     12000,
+		}},
+	}
+	err := report.Verify(cfg)
+	if err == nil {
+		t.Fatalf("Verify of an undetected spike that did not trip the breaker succeeded")
+	}
+	for _, want := range []string{"did not produce a spike-adjusted recommendation", "circuit breaker did not trip"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Verify = %v, want it to contain %q", err, want)
+		}
+	}
+
+	// A spike below the baseline multiplier need not trip the breaker
+	cfg.CircuitBreaker.BlastProtection.BaselineMultiplier = 4
+	report.Tenants[0].SpikeDetected = true
+	report.Tenants[0].PeakRecommendation = 36000
+	if err := report.Verify(cfg); err != nil {
+		t.Errorf("Verify of a handled spike below the baseline multiplier: %v", err)
+	}
+}
+
+func TestRunNeedsSyntheticMode(t *testing.T) {
+	cfg := syntheticConfig(t)
+	cfg.Synthetic.Enabled = false
+	if _, err := Run(context.Background(), cfg, logr.Discard()); err == nil {
+		t.Errorf("Run without synthetic mode succeeded")
+	}
+}
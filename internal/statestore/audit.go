@@ -0,0 +1,160 @@
+package statestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+)
+
+// auditLogger stores the audit entries in the state database
+type auditLogger struct {
+	store *Store
+}
+
+// AuditLogger returns an audit logger writing to the state database. Closing it leaves
+// the database open; the store is closed by its owner.
+func (s *Store) AuditLogger() auditlog.AuditLogger {
+	return &auditLogger{store: s}
+}
+
+func (a *auditLogger) LogEntry(entry *auditlog.AuditEntry) error {
+	if entry.ID == "" {
+		entry.ID = fmt.Sprintf("audit_%d", time.Now().UnixNano())
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	if entry.Component == "" {
+		entry.Component = "mimir-limit-optimizer"
+	}
+	if entry.Source == "" {
+		entry.Source = "controller"
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if _, err := a.store.db.Exec(`INSERT OR REPLACE INTO audit_entries (id, timestamp, entry)
+		VALUES (?, ?, ?)`, entry.ID, entry.Timestamp.UnixNano(), string(data)); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	a.updateEntriesMetric()
+	return nil
+}
+
+// GetEntries returns the matching entries, oldest first
+func (a *auditLogger) GetEntries(ctx context.Context, filter *auditlog.AuditFilter) ([]*auditlog.AuditEntry, error) {
+	query := "SELECT entry FROM audit_entries WHERE 1 = 1"
+	var args []interface{}
+	if filter != nil && filter.StartTime != nil {
+		query += " AND timestamp >= ?"
+		args = append(args, filter.StartTime.UnixNano())
+	}
+	if filter != nil && filter.EndTime != nil {
+		query += " AND timestamp <= ?"
+		args = append(args, filter.EndTime.UnixNano())
+	}
+	query += " ORDER BY timestamp, id"
+
+	rows, err := a.store.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*auditlog.AuditEntry
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		var entry auditlog.AuditEntry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit entry: %w", err)
+		}
+		if matchesFilter(&entry, filter) {
+			entries = append(entries, &entry)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit entries: %w", err)
+	}
+
+	if filter != nil {
+		if filter.Offset > 0 && filter.Offset < len(entries) {
+			entries = entries[filter.Offset:]
+		}
+		if filter.Limit > 0 && filter.Limit < len(entries) {
+			entries = entries[:filter.Limit]
+		}
+	}
+	return entries, nil
+}
+
+func (a *auditLogger) GetEntry(ctx context.Context, id string) (*auditlog.AuditEntry, error) {
+	var data string
+	err := a.store.db.QueryRowContext(ctx, "SELECT entry FROM audit_entries WHERE id = ?", id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("audit entry not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit entry %s: %w", id, err)
+	}
+
+	var entry auditlog.AuditEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse audit entry %s: %w", id, err)
+	}
+	return &entry, nil
+}
+
+func (a *auditLogger) PurgeOldEntries(ctx context.Context, olderThan time.Time) error {
+	result, err := a.store.db.ExecContext(ctx, "DELETE FROM audit_entries WHERE timestamp <= ?", olderThan.UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to purge audit entries: %w", err)
+	}
+	purged, _ := result.RowsAffected()
+	a.updateEntriesMetric()
+	a.store.log.Info("purged old audit entries", "count", purged, "older_than", olderThan)
+	return nil
+}
+
+func (a *auditLogger) Close() error {
+	return nil
+}
+
+// updateEntriesMetric reports the number of stored entries
+func (a *auditLogger) updateEntriesMetric() {
+	var count int
+	if err := a.store.db.QueryRow("SELECT COUNT(*) FROM audit_entries").Scan(&count); err != nil {
+		a.store.log.Error(err, "failed to count audit entries")
+		return
+	}
+	metrics.AuditLogMetricsInstance.SetAuditLogEntries("sqlite", count)
+}
+
+// matchesFilter checks the filter criteria not applied by the query
+func matchesFilter(entry *auditlog.AuditEntry, filter *auditlog.AuditFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Tenant != "" && entry.Tenant != filter.Tenant {
+		return false
+	}
+	if filter.Action != "" && entry.Action != filter.Action {
+		return false
+	}
+	if filter.Success != nil && entry.Success != *filter.Success {
+		return false
+	}
+	if filter.ReconcileID != 0 && entry.ReconcileID != filter.ReconcileID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,389 @@
+// Package statestore persists the state of the optimizer in standalone mode to a SQLite
+// database, so trend analysis, limit history, audit entries and the circuit breaker
+// survive restarts.
+package statestore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+	// Pure Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+)
+
+// MetricsRetention is how long collected metric samples are kept
+const MetricsRetention = 7 * 24 * time.Hour
+
+// LimitHistoryRetention is how long computed limits are kept in the limit history. The
+// latest limits of each tenant are kept regardless, as LatestLimits reads them back.
+const LimitHistoryRetention = 30 * 24 * time.Hour
+
+// circuitBreakerStateKey is the state row of the circuit breaker
+const circuitBreakerStateKey = "circuit_breaker"
+
+// schema creates the tables of a new database. Times are stored as Unix nanoseconds.
+const schema = `
+CREATE TABLE IF NOT EXISTS metric_samples (
+	tenant    TEXT    NOT NULL,
+	metric    TEXT    NOT NULL,
+	timestamp INTEGER NOT NULL,
+	value     REAL    NOT NULL,
+	labels    TEXT,
+	source    TEXT,
+	PRIMARY KEY (tenant, metric, timestamp)
+);
+CREATE INDEX IF NOT EXISTS metric_samples_timestamp ON metric_samples (timestamp);
+
+CREATE TABLE IF NOT EXISTS limit_history (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	tenant    TEXT    NOT NULL,
+	timestamp INTEGER NOT NULL,
+	reason    TEXT,
+	source    TEXT,
+	tier      TEXT,
+	limits    TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS limit_history_tenant ON limit_history (tenant, timestamp);
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+	id        TEXT    PRIMARY KEY,
+	timestamp INTEGER NOT NULL,
+	entry     TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS audit_entries_timestamp ON audit_entries (timestamp);
+
+CREATE TABLE IF NOT EXISTS state (
+	key        TEXT    PRIMARY KEY,
+	value      TEXT    NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+`
+
+// Store is the state database of standalone mode. Writes are committed when they
+// return; Close checkpoints the write-ahead log into the database file.
+type Store struct {
+	db   *sql.DB
+	path string
+	log  logr.Logger
+	now  func() time.Time
+}
+
+// CircuitBreakerState is the persisted state of the circuit breaker
+type CircuitBreakerState struct {
+	State     string                                  `json:"state"`
+	Baselines map[string]circuitbreaker.BaselineRates `json:"baselines,omitempty"`
+	SavedAt   time.Time                               `json:"saved_at"`
+}
+
+// Open opens the state database at path, creating it and its tables when it does not
+// exist
+func Open(path string, log logr.Logger) (*Store, error) {
+	dsn := (&url.URL{
+		Scheme: "file",
+		Opaque: path,
+		RawQuery: url.Values{"_pragma": []string{
+			"busy_timeout(5000)",
+			"journal_mode(WAL)",
+			"synchronous(NORMAL)",
+		}}.Encode(),
+	}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database %s: %w", path, err)
+	}
+	// SQLite allows a single writer; one connection also keeps the pragmas in effect
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create state database schema in %s: %w", path, err)
+	}
+
+	store := &Store{db: db, path: path, log: log, now: time.Now}
+	log.Info("opened state database", "path", path)
+	return store, nil
+}
+
+// Close checkpoints the write-ahead log and closes the database
+func (s *Store) Close() error {
+	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		s.log.Error(err, "failed to checkpoint state database", "path", s.path)
+	}
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close state database %s: %w", s.path, err)
+	}
+	s.log.Info("closed state database", "path", s.path)
+	return nil
+}
+
+// SaveMetrics stores the collected samples of each tenant, replacing samples of the
+// same metric and timestamp, and drops the samples older than MetricsRetention
+func (s *Store) SaveMetrics(ctx context.Context, tenantMetrics map[string]*collector.TenantMetrics) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin metrics write: %w", err)
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO metric_samples
+		(tenant, metric, timestamp, value, labels, source) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare metrics write: %w", err)
+	}
+	defer insert.Close()
+
+	samples := 0
+	for tenant, tm := range tenantMetrics {
+		for metricName, data := range tm.Metrics {
+			for _, sample := range data {
+				labels, err := json.Marshal(sample.Labels)
+				if err != nil {
+					return fmt.Errorf("failed to marshal labels of %s for tenant %s: %w", metricName, tenant, err)
+				}
+				if _, err := insert.ExecContext(ctx, tenant, metricName, sample.Timestamp.UnixNano(),
+					sample.Value, string(labels), sample.Source); err != nil {
+					return fmt.Errorf("failed to write %s for tenant %s: %w", metricName, tenant, err)
+				}
+				samples++
+			}
+		}
+	}
+
+	cutoff := s.now().Add(-MetricsRetention).UnixNano()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM metric_samples WHERE timestamp < ?", cutoff); err != nil {
+		return fmt.Errorf("failed to drop expired metric samples: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit metrics write: %w", err)
+	}
+
+	s.log.V(1).Info("stored metric samples", "tenants", len(tenantMetrics), "samples", samples)
+	return nil
+}
+
+// LoadMetrics returns the stored samples since a time, by tenant and metric, oldest
+// first
+func (s *Store) LoadMetrics(ctx context.Context, since time.Time) (map[string]*collector.TenantMetrics, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tenant, metric, timestamp, value, labels, source
+		FROM metric_samples WHERE timestamp >= ? ORDER BY timestamp`, since.UnixNano())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric samples: %w", err)
+	}
+	defer rows.Close()
+
+	tenantMetrics := make(map[string]*collector.TenantMetrics)
+	for rows.Next() {
+		var (
+			tenant, metricName string
+			timestamp          int64
+			value              float64
+			labels, source     sql.NullString
+		)
+		if err := rows.Scan(&tenant, &metricName, &timestamp, &value, &labels, &source); err != nil {
+			return nil, fmt.Errorf("failed to read metric sample: %w", err)
+		}
+
+		sample := collector.MetricData{
+			Tenant:     tenant,
+			MetricName: metricName,
+			Value:      value,
+			Timestamp:  time.Unix(0, timestamp),
+			Source:     source.String,
+		}
+		if labels.Valid && labels.String != "" {
+			if err := json.Unmarshal([]byte(labels.String), &sample.Labels); err != nil {
+				return nil, fmt.Errorf("failed to parse labels of %s for tenant %s: %w", metricName, tenant, err)
+			}
+		}
+
+		tm := tenantMetrics[tenant]
+		if tm == nil {
+			tm = &collector.TenantMetrics{Tenant: tenant, Metrics: make(map[string][]collector.MetricData)}
+			tenantMetrics[tenant] = tm
+		}
+		tm.Metrics[metricName] = append(tm.Metrics[metricName], sample)
+		if sample.Timestamp.After(tm.LastUpdate) {
+			tm.LastUpdate = sample.Timestamp
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metric samples: %w", err)
+	}
+	return tenantMetrics, nil
+}
+
+// SaveLimits appends the computed limits of each tenant to the limit history, and
+// drops the entries older than LimitHistoryRetention that are not a tenant's latest
+func (s *Store) SaveLimits(ctx context.Context, limits map[string]*analyzer.TenantLimits) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin limits write: %w", err)
+	}
+	defer tx.Rollback()
+
+	for tenant, tenantLimits := range limits {
+		data, err := json.Marshal(storedLimits(tenantLimits.Limits))
+		if err != nil {
+			return fmt.Errorf("failed to marshal limits of tenant %s: %w", tenant, err)
+		}
+		timestamp := tenantLimits.LastUpdated
+		if timestamp.IsZero() {
+			timestamp = s.now()
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO limit_history
+			(tenant, timestamp, reason, source, tier, limits) VALUES (?, ?, ?, ?, ?, ?)`,
+			tenant, timestamp.UnixNano(), tenantLimits.Reason, tenantLimits.Source, tenantLimits.Tier, string(data)); err != nil {
+			return fmt.Errorf("failed to write limits of tenant %s: %w", tenant, err)
+		}
+	}
+
+	cutoff := s.now().Add(-LimitHistoryRetention).UnixNano()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM limit_history
+		WHERE timestamp < ? AND id NOT IN (SELECT MAX(id) FROM limit_history GROUP BY tenant)`, cutoff); err != nil {
+		return fmt.Errorf("failed to drop expired limit history: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit limits write: %w", err)
+	}
+	return nil
+}
+
+// storedLimits formats durations as Mimir does, so they read back as in the runtime
+// overrides rather than as nanoseconds
+func storedLimits(limits map[string]interface{}) map[string]interface{} {
+	stored := make(map[string]interface{}, len(limits))
+	for limitName, value := range limits {
+		if d, isDuration := value.(time.Duration); isDuration {
+			value = d.String()
+		}
+		stored[limitName] = value
+	}
+	return stored
+}
+
+// LimitHistory returns the limits stored for a tenant since a time, oldest first; an
+// empty tenant returns the history of every tenant
+func (s *Store) LimitHistory(ctx context.Context, tenant string, since time.Time) ([]*analyzer.TenantLimits, error) {
+	query := `SELECT tenant, timestamp, reason, source, tier, limits FROM limit_history
+		WHERE timestamp >= ?`
+	args := []interface{}{since.UnixNano()}
+	if tenant != "" {
+		query += " AND tenant = ?"
+		args = append(args, tenant)
+	}
+	query += " ORDER BY timestamp, id"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read limit history: %w", err)
+	}
+	return scanLimits(rows)
+}
+
+// LatestLimits returns the last limits stored for each tenant
+func (s *Store) LatestLimits(ctx context.Context) (map[string]*analyzer.TenantLimits, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT tenant, timestamp, reason, source, tier, limits
+		FROM limit_history
+		WHERE id IN (SELECT MAX(id) FROM limit_history GROUP BY tenant)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read limit history: %w", err)
+	}
+	history, err := scanLimits(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]*analyzer.TenantLimits, len(history))
+	for _, entry := range history {
+		latest[entry.Tenant] = entry
+	}
+	return latest, nil
+}
+
+// scanLimits reads and closes the rows of a limit history query
+func scanLimits(rows *sql.Rows) ([]*analyzer.TenantLimits, error) {
+	defer rows.Close()
+
+	var history []*analyzer.TenantLimits
+	for rows.Next() {
+		var (
+			entry                analyzer.TenantLimits
+			timestamp            int64
+			reason, source, tier sql.NullString
+			data                 string
+		)
+		if err := rows.Scan(&entry.Tenant, &timestamp, &reason, &source, &tier, &data); err != nil {
+			return nil, fmt.Errorf("failed to read limit history: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &entry.Limits); err != nil {
+			return nil, fmt.Errorf("failed to parse limits of tenant %s: %w", entry.Tenant, err)
+		}
+		entry.LastUpdated = time.Unix(0, timestamp)
+		entry.Reason, entry.Source, entry.Tier = reason.String, source.String, tier.String
+		history = append(history, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read limit history: %w", err)
+	}
+	return history, nil
+}
+
+// SaveCircuitBreakerState stores the state of the circuit breaker, replacing the
+// previous one
+func (s *Store) SaveCircuitBreakerState(ctx context.Context, state CircuitBreakerState) error {
+	if state.SavedAt.IsZero() {
+		state.SavedAt = s.now()
+	}
+	return s.saveState(ctx, circuitBreakerStateKey, state)
+}
+
+// LoadCircuitBreakerState returns the stored state of the circuit breaker, nil when
+// none was stored
+func (s *Store) LoadCircuitBreakerState(ctx context.Context) (*CircuitBreakerState, error) {
+	var state CircuitBreakerState
+	found, err := s.loadState(ctx, circuitBreakerStateKey, &state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// saveState stores a value as JSON under a key of the state table
+func (s *Store) saveState(ctx context.Context, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s state: %w", key, err)
+	}
+	if _, err := s.db.ExecContext(ctx, `INSERT OR REPLACE INTO state (key, value, updated_at)
+		VALUES (?, ?, ?)`, key, string(data), s.now().UnixNano()); err != nil {
+		return fmt.Errorf("failed to write %s state: %w", key, err)
+	}
+	return nil
+}
+
+// loadState reads the JSON value of a key of the state table into out, and reports
+// whether the key exists
+func (s *Store) loadState(ctx context.Context, key string, out interface{}) (bool, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM state WHERE key = ?", key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s state: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(data), out); err != nil {
+		return false, fmt.Errorf("failed to parse %s state: %w", key, err)
+	}
+	return true, nil
+}
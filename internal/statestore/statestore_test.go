@@ -0,0 +1,328 @@
+package statestore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+)
+
+var storeStart = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+// openTestStore opens the database at path with its clock at now
+func openTestStore(t *testing.T, path string, now *time.Time) *Store {
+	t.Helper()
+	store, err := Open(path, logr.Discard())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func closeStore(t *testing.T, store *Store) {
+	t.Helper()
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// ingestionSamples returns n ingestion rate samples of tenant a minute apart from start
+func ingestionSamples(tenant string, start time.Time, n int) *collector.TenantMetrics {
+	tm := &collector.TenantMetrics{Tenant: tenant, Metrics: make(map[string][]collector.MetricData)}
+	for i := 0; i < n; i++ {
+		tm.Metrics["cortex_distributor_received_samples_total"] = append(tm.Metrics["cortex_distributor_received_samples_total"], collector.MetricData{
+			Tenant:     tenant,
+			MetricName: "cortex_distributor_received_samples_total",
+			Value:      float64(1000 * (i + 1)),
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+			Labels:     map[string]string{"user": tenant},
+			Source:     "synthetic",
+		})
+	}
+	return tm
+}
+
+func TestStateSurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	now := storeStart
+	store := openTestStore(t, path, &now)
+
+	if err := store.SaveMetrics(ctx, map[string]*collector.TenantMetrics{
+		"tenant-a": ingestionSamples("tenant-a", storeStart.Add(-time.Hour), 5),
+	}); err != nil {
+		t.Fatalf("SaveMetrics: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := store.SaveLimits(ctx, map[string]*analyzer.TenantLimits{"tenant-a": {
+			Tenant:      "tenant-a",
+			Limits:      map[string]interface{}{"ingestion_rate": float64(10000 * (i + 1)), "max_query_length": 12 * time.Hour},
+			Reason:      "trend-analysis",
+			LastUpdated: storeStart.Add(time.Duration(i) * time.Minute),
+		}}); err != nil {
+			t.Fatalf("SaveLimits: %v", err)
+		}
+	}
+	audit := store.AuditLogger()
+	for i := 0; i < 5; i++ {
+		if err := audit.LogEntry(&auditlog.AuditEntry{
+			ID:        fmt.Sprintf("audit-%d", i),
+			Timestamp: storeStart.Add(time.Duration(i) * time.Minute),
+			Action:    "update-limits",
+			Tenant:    "tenant-a",
+			Success:   true,
+		}); err != nil {
+			t.Fatalf("LogEntry: %v", err)
+		}
+	}
+	if err := store.SaveCircuitBreakerState(ctx, CircuitBreakerState{
+		State:     "OPEN",
+		Baselines: map[string]circuitbreaker.BaselineRates{"tenant-a": {IngestionRate: 12000}},
+	}); err != nil {
+		t.Fatalf("SaveCircuitBreakerState: %v", err)
+	}
+	closeStore(t, store)
+
+	// Restart: the database is opened again from the same path
+	store = openTestStore(t, path, &now)
+	defer closeStore(t, store)
+
+	tenantMetrics, err := store.LoadMetrics(ctx, storeStart.Add(-MetricsRetention))
+	if err != nil {
+		t.Fatalf("LoadMetrics: %v", err)
+	}
+	samples := tenantMetrics["tenant-a"].Metrics["cortex_distributor_received_samples_total"]
+	if len(samples) != 5 {
+		t.Fatalf("%d metric samples read after reopening, want 5", len(samples))
+	}
+	want := ingestionSamples("tenant-a", storeStart.Add(-time.Hour), 5).Metrics["cortex_distributor_received_samples_total"]
+	for i, sample := range samples {
+		if sample.Value != want[i].Value || !sample.Timestamp.Equal(want[i].Timestamp) ||
+			sample.Labels["user"] != "tenant-a" || sample.Source != "synthetic" {
+			t.Errorf("metric sample %d = %+v, want %+v", i, sample, want[i])
+		}
+	}
+	if !tenantMetrics["tenant-a"].LastUpdate.Equal(want[4].Timestamp) {
+		t.Errorf("last update = %v, want the newest sample at %v", tenantMetrics["tenant-a"].LastUpdate, want[4].Timestamp)
+	}
+
+	history, err := store.LimitHistory(ctx, "tenant-a", time.Time{})
+	if err != nil {
+		t.Fatalf("LimitHistory: %v", err)
+	}
+	if len(history) != 5 {
+		t.Fatalf("%d limit history entries read after reopening, want 5", len(history))
+	}
+	for i, entry := range history {
+		if entry.Limits["ingestion_rate"] != float64(10000*(i+1)) || entry.Reason != "trend-analysis" {
+			t.Errorf("limit history entry %d = %+v, want ingestion_rate %d", i, entry, 10000*(i+1))
+		}
+		// Durations are stored as Mimir formats them
+		if entry.Limits["max_query_length"] != "12h0m0s" {
+			t.Errorf("max_query_length of entry %d = %v, want 12h0m0s", i, entry.Limits["max_query_length"])
+		}
+	}
+
+	entries, err := store.AuditLogger().GetEntries(ctx, nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("%d audit entries read after reopening, want 5", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.ID != fmt.Sprintf("audit-%d", i) || entry.Action != "update-limits" || !entry.Success {
+			t.Errorf("audit entry %d = %+v, want audit-%d", i, entry, i)
+		}
+	}
+
+	state, err := store.LoadCircuitBreakerState(ctx)
+	if err != nil {
+		t.Fatalf("LoadCircuitBreakerState: %v", err)
+	}
+	if state == nil || state.State != "OPEN" || state.Baselines["tenant-a"].IngestionRate != 12000 || !state.SavedAt.Equal(storeStart) {
+		t.Errorf("circuit breaker state after reopening = %+v, want OPEN with tenant-a's baseline", state)
+	}
+}
+
+func TestMetricsRetention(t *testing.T) {
+	ctx := context.Background()
+	now := storeStart
+	store := openTestStore(t, filepath.Join(t.TempDir(), "state.db"), &now)
+	defer closeStore(t, store)
+
+	if err := store.SaveMetrics(ctx, map[string]*collector.TenantMetrics{
+		"tenant-a": ingestionSamples("tenant-a", storeStart, 2),
+	}); err != nil {
+		t.Fatalf("SaveMetrics: %v", err)
+	}
+
+	// A week later the first samples expire on the next write
+	now = storeStart.Add(MetricsRetention).Add(30 * time.Second)
+	if err := store.SaveMetrics(ctx, map[string]*collector.TenantMetrics{
+		"tenant-b": ingestionSamples("tenant-b", now, 1),
+	}); err != nil {
+		t.Fatalf("SaveMetrics: %v", err)
+	}
+
+	tenantMetrics, err := store.LoadMetrics(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("LoadMetrics: %v", err)
+	}
+	if got := len(tenantMetrics["tenant-a"].Metrics["cortex_distributor_received_samples_total"]); got != 1 {
+		t.Errorf("tenant-a has %d samples after a week, want only the sample within the retention", got)
+	}
+	if tenantMetrics["tenant-b"] == nil {
+		t.Errorf("the new samples of tenant-b were dropped")
+	}
+}
+
+func TestLatestLimits(t *testing.T) {
+	ctx := context.Background()
+	now := storeStart
+	store := openTestStore(t, filepath.Join(t.TempDir(), "state.db"), &now)
+	defer closeStore(t, store)
+
+	for _, limits := range []map[string]*analyzer.TenantLimits{
+		{"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 10000.0}},
+			"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"ingestion_rate": 5000.0}}},
+		{"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 20000.0}}},
+	} {
+		if err := store.SaveLimits(ctx, limits); err != nil {
+			t.Fatalf("SaveLimits: %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	latest, err := store.LatestLimits(ctx)
+	if err != nil {
+		t.Fatalf("LatestLimits: %v", err)
+	}
+	if len(latest) != 2 || latest["tenant-a"].Limits["ingestion_rate"] != 20000.0 || latest["tenant-b"].Limits["ingestion_rate"] != 5000.0 {
+		t.Errorf("latest limits = tenant-a %v, tenant-b %v; want 20000 and 5000", latest["tenant-a"], latest["tenant-b"])
+	}
+	if !latest["tenant-a"].LastUpdated.Equal(storeStart.Add(time.Minute)) {
+		t.Errorf("tenant-a's latest limits stored at %v, want %v", latest["tenant-a"].LastUpdated, storeStart.Add(time.Minute))
+	}
+
+	if history, err := store.LimitHistory(ctx, "", time.Time{}); err != nil || len(history) != 3 {
+		t.Errorf("limit history of every tenant = %d entries (%v), want 3", len(history), err)
+	}
+}
+
+func TestLimitHistoryRetention(t *testing.T) {
+	ctx := context.Background()
+	now := storeStart
+	store := openTestStore(t, filepath.Join(t.TempDir(), "state.db"), &now)
+	defer closeStore(t, store)
+
+	save := func(limits map[string]*analyzer.TenantLimits) {
+		t.Helper()
+		if err := store.SaveLimits(ctx, limits); err != nil {
+			t.Fatalf("SaveLimits: %v", err)
+		}
+	}
+	save(map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 10000.0}},
+		"tenant-b": {Tenant: "tenant-b", Limits: map[string]interface{}{"ingestion_rate": 5000.0}},
+	})
+	now = now.Add(time.Minute)
+	save(map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 20000.0}},
+	})
+
+	// Past the retention the older entries of tenant-a expire on the next write, while
+	// the latest limits of tenant-b are kept although they are as old
+	now = storeStart.Add(LimitHistoryRetention).Add(30 * time.Second)
+	save(map[string]*analyzer.TenantLimits{
+		"tenant-a": {Tenant: "tenant-a", Limits: map[string]interface{}{"ingestion_rate": 30000.0}},
+	})
+
+	history, err := store.LimitHistory(ctx, "", time.Time{})
+	if err != nil {
+		t.Fatalf("LimitHistory: %v", err)
+	}
+	var rates []interface{}
+	for _, entry := range history {
+		rates = append(rates, entry.Limits["ingestion_rate"])
+	}
+	if len(history) != 3 {
+		t.Fatalf("limit history after the retention = %v, want tenant-b's 5000 and tenant-a's 20000 and 30000", rates)
+	}
+	if history[0].Tenant != "tenant-b" || history[1].Limits["ingestion_rate"] != 20000.0 || history[2].Limits["ingestion_rate"] != 30000.0 {
+		t.Errorf("limit history after the retention = %v, want tenant-b's 5000 and tenant-a's 20000 and 30000", rates)
+	}
+
+	latest, err := store.LatestLimits(ctx)
+	if err != nil {
+		t.Fatalf("LatestLimits: %v", err)
+	}
+	if latest["tenant-b"] == nil {
+		t.Errorf("the latest limits of tenant-b expired")
+	}
+}
+
+func TestAuditEntriesFiltered(t *testing.T) {
+	ctx := context.Background()
+	now := storeStart
+	store := openTestStore(t, filepath.Join(t.TempDir(), "state.db"), &now)
+	defer closeStore(t, store)
+
+	audit := store.AuditLogger()
+	for i, tenant := range []string{"tenant-a", "tenant-b", "tenant-a"} {
+		if err := audit.LogEntry(&auditlog.AuditEntry{
+			ID:        fmt.Sprintf("audit-%d", i),
+			Timestamp: storeStart.Add(time.Duration(i) * time.Hour),
+			Action:    "update-limits",
+			Tenant:    tenant,
+			Success:   i != 2,
+		}); err != nil {
+			t.Fatalf("LogEntry: %v", err)
+		}
+	}
+
+	failed := false
+	since := storeStart.Add(time.Hour)
+	tests := []struct {
+		name   string
+		filter *auditlog.AuditFilter
+		want   []string
+	}{
+		{"tenant", &auditlog.AuditFilter{Tenant: "tenant-a"}, []string{"audit-0", "audit-2"}},
+		{"failures", &auditlog.AuditFilter{Success: &failed}, []string{"audit-2"}},
+		{"since", &auditlog.AuditFilter{StartTime: &since}, []string{"audit-1", "audit-2"}},
+		{"page", &auditlog.AuditFilter{Offset: 1, Limit: 1}, []string{"audit-1"}},
+	}
+	for _, tt := range tests {
+		entries, err := audit.GetEntries(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("GetEntries: %v", err)
+		}
+		var ids []string
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: entries %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+
+	if err := audit.PurgeOldEntries(ctx, storeStart.Add(time.Hour)); err != nil {
+		t.Fatalf("PurgeOldEntries: %v", err)
+	}
+	if _, err := audit.GetEntry(ctx, "audit-1"); err == nil {
+		t.Errorf("audit-1 is still stored after purging the entries up to its time")
+	}
+	if entry, err := audit.GetEntry(ctx, "audit-2"); err != nil || entry.Tenant != "tenant-a" {
+		t.Errorf("GetEntry(audit-2) = %+v, %v; want tenant-a's entry", entry, err)
+	}
+}
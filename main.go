@@ -5,25 +5,36 @@ package main
 import (
 	"context"
 	"embed"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/controller"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/metrics"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/simulation"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/statestore"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/pkg/api"
 )
 
@@ -52,6 +63,11 @@ func main() {
 	var logLevel string
 	var showVersion bool
 	var healthCheck bool
+	var exportAlertRulesFile string
+	var exportLimitsFlag bool
+	var exportFormat string
+	var outputFile string
+	var simulate bool
 
 	flag.StringVar(&configFile, "config", "", "Path to the configuration file.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -62,6 +78,15 @@ func main() {
 	flag.StringVar(&logLevel, "log-level", "info", "Log level (debug, info, warn, error)")
 	flag.BoolVar(&showVersion, "version", false, "Show version information and exit.")
 	flag.BoolVar(&healthCheck, "health-check", false, "Perform health check and exit.")
+	flag.StringVar(&exportAlertRulesFile, "export-alert-rules-file", "",
+		"Write Prometheus alerting rules for tenant limit usage to this file and exit.")
+	flag.BoolVar(&exportLimitsFlag, "export-limits", false,
+		"Export the tenant limits of the runtime overrides ConfigMap as Helm values and exit.")
+	flag.StringVar(&exportFormat, "format", "yaml", "Format of --export-limits output (yaml, json).")
+	flag.StringVar(&outputFile, "output-file", "", "Write --export-limits output to this file instead of stdout.")
+	flag.BoolVar(&simulate, "simulate", false,
+		"Run the analysis and circuit breaker against the synthetic metrics, print a report and exit. "+
+			"Exits non-zero when a configured spike is not handled.")
 
 	opts := zap.Options{
 		Development: false,
@@ -111,6 +136,37 @@ func main() {
 		setupLog.Error(err, "invalid configuration")
 		os.Exit(1)
 	}
+	if err := validateAlertRouting(cfg); err != nil {
+		setupLog.Error(err, "invalid alert routing rules")
+		os.Exit(1)
+	}
+
+	// Handle limits export flag
+	if exportLimitsFlag {
+		if err := exportLimits(cfg, exportFormat, outputFile); err != nil {
+			setupLog.Error(err, "failed to export limits")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Handle alert rules export flag
+	if exportAlertRulesFile != "" {
+		if err := exportAlertRules(cfg, exportAlertRulesFile); err != nil {
+			setupLog.Error(err, "failed to export alert rules")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Handle synthetic simulation flag
+	if simulate {
+		if err := runSimulation(cfg); err != nil {
+			setupLog.Error(err, "synthetic simulation failed")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 
 	setupLog.Info("Starting mimir-limit-optimizer",
 		"version", getBuildInfo(),
@@ -131,6 +187,7 @@ func main() {
 	// Normal Kubernetes mode
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  managerCacheOptions(cfg),
 		Metrics: server.Options{
 			BindAddress: metricsAddr,
 		},
@@ -144,17 +201,22 @@ func main() {
 	}
 
 	// Initialize metrics
-	if err := metrics.RegisterMetrics(); err != nil {
+	if err := metrics.RegisterMetrics(cfg.Metrics.ExtraLabels); err != nil {
 		setupLog.Error(err, "unable to register metrics")
 		os.Exit(1)
 	}
 
+	// The components share the configuration, which reloads replace
+	liveConfig := config.NewLive(cfg)
+
 	// Setup the controller
 	mimirController := &controller.MimirLimitController{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-		Config: cfg,
+		Config: liveConfig,
 		Log:    ctrl.Log.WithName("controllers").WithName("MimirLimit"),
+
+		LeaderElection: enableLeaderElection,
 	}
 	if err = mimirController.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MimirLimit")
@@ -163,7 +225,7 @@ func main() {
 
 	// Setup the web UI server if enabled
 	if cfg.UI.Enabled {
-		apiServer := api.NewServer(mimirController, cfg, ctrl.Log.WithName("api"), uiAssets)
+		apiServer := api.NewServer(mimirController, liveConfig, ctrl.Log.WithName("api"), uiAssets)
 
 		// Start the UI server in a goroutine
 		go func() {
@@ -183,18 +245,57 @@ func main() {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+	if err := mgr.AddReadyzCheck("readyz", mimirController.ReadyzCheck); err != nil {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+
+	// Reload the config file when the mounted ConfigMap changes
+	if configFile != "" {
+		configWatcher := config.NewWatcher(configFile, cfg, ctrl.Log.WithName("config"))
+		configWatcher.AddValidator(validateAlertRouting)
+		configWatcher.OnReload(mimirController.ApplyConfig)
+		if err := configWatcher.Start(ctx); err != nil {
+			setupLog.Error(err, "unable to watch config file, config changes require a restart")
+		}
+	}
+
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// validateAlertRouting rejects routing rules with malformed conditions when alerting is
+// enabled; the config package cannot parse conditions itself
+func validateAlertRouting(cfg *config.Config) error {
+	if !cfg.Alerting.Enabled {
+		return nil
+	}
+	return alerting.ValidateRoutingRules(&cfg.Alerting)
+}
+
+// managerCacheOptions restricts the informers of the manager's client to the scanned,
+// Mimir and optimizer namespaces under the namespaces scan scope, so that reading
+// through the client needs no cluster-wide list and watch permissions
+func managerCacheOptions(cfg *config.Config) cache.Options {
+	if cfg.MetricsDiscovery.ScanScope != config.ScanScopeNamespaces {
+		return cache.Options{}
+	}
+
+	namespaces := map[string]cache.Config{cfg.Mimir.Namespace: {}}
+	for _, namespace := range cfg.ScopedNamespaces() {
+		namespaces[namespace] = cache.Config{}
+	}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		namespaces[namespace] = cache.Config{}
+	}
+	return cache.Options{DefaultNamespaces: namespaces}
+}
+
 func getBuildInfo() string {
 	return fmt.Sprintf("mimir-limit-optimizer version %s (commit: %s, built: %s)", Version, Commit, BuildDate)
 }
@@ -228,6 +329,125 @@ func performHealthCheck(probeAddr string) error {
 	return nil
 }
 
+// exportAlertRules writes Prometheus alerting rules for the enabled limits to path. The
+// current limits are read from the runtime overrides ConfigMap; without Kubernetes
+// connectivity only the rules for the default limit values are written.
+func exportAlertRules(cfg *config.Config, path string) error {
+	currentLimits := map[string]map[string]interface{}{}
+
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		setupLog.Info("Kubernetes is unreachable, exporting alert rules for default limits only", "error", err.Error())
+	} else {
+		k8sClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		limitsPatcher := patcher.NewConfigMapPatcher(k8sClient, nil, config.NewLive(cfg), nil, setupLog.WithName("patcher"))
+		tenantLimits, err := limitsPatcher.GetCurrentLimits(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read current limits: %w", err)
+		}
+		for tenant, limits := range tenantLimits {
+			currentLimits[tenant] = limits.Limits
+		}
+	}
+
+	rules := alerting.GenerateLimitAlertRules(cfg.DynamicLimits.LimitDefinitions, currentLimits, alerting.DefaultRuleTenantLabel)
+	format := "yaml"
+	if strings.HasSuffix(path, ".json") {
+		format = "json"
+	}
+	data, err := alerting.MarshalRuleFile(rules, format)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write alert rules file: %w", err)
+	}
+
+	setupLog.Info("Exported alert rules", "file", path, "groups", len(rules.Groups), "tenants", len(currentLimits))
+	return nil
+}
+
+// runSimulation replays the synthetic metrics through the trend analysis, spike
+// detection and circuit breaker, prints the report as JSON and checks that every
+// configured spike was handled
+func runSimulation(cfg *config.Config) error {
+	// The per-collection logs of the simulation are only shown at debug level
+	report, err := simulation.Run(context.Background(), cfg, setupLog.WithName("simulation").V(1))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode simulation report: %w", err)
+	}
+	fmt.Println(string(data))
+
+	return report.Verify(cfg)
+}
+
+// exportLimits writes the tenant limits of the runtime overrides ConfigMap as Helm
+// values to path, or to stdout when path is empty
+func exportLimits(cfg *config.Config, format, path string) error {
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get Kubernetes config: %w", err)
+	}
+	k8sClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	data, err := limitsHelmValues(ctx, k8sClient, cfg, format)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write limits file: %w", err)
+	}
+	setupLog.Info("Exported limits", "file", path, "format", format)
+	return nil
+}
+
+// limitsHelmValues reads the tenant limits of the runtime overrides ConfigMap, which
+// must exist, and encodes them as Helm values
+func limitsHelmValues(ctx context.Context, k8sClient client.Client, cfg *config.Config, format string) ([]byte, error) {
+	if format != "yaml" && format != "json" {
+		return nil, fmt.Errorf("unsupported format %q, must be yaml or json", format)
+	}
+
+	// The patcher creates a missing ConfigMap, which an export must not do
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Namespace: cfg.Mimir.Namespace, Name: cfg.Mimir.ConfigMapName}
+	if err := k8sClient.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("runtime overrides ConfigMap %s does not exist", key)
+		}
+		return nil, fmt.Errorf("failed to get runtime overrides ConfigMap %s: %w", key, err)
+	}
+
+	limitsPatcher := patcher.NewConfigMapPatcher(k8sClient, nil, config.NewLive(cfg), nil, setupLog.WithName("patcher"))
+	tenantLimits, err := limitsPatcher.GetCurrentLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current limits: %w", err)
+	}
+	return patcher.MarshalHelmValues(patcher.HelmValues(tenantLimits), format)
+}
+
 // canRunStandalone determines if the system can run without Kubernetes connectivity
 func canRunStandalone(cfg *config.Config) bool {
 	// Can run standalone if:
@@ -258,7 +478,7 @@ func runStandalone(cfg *config.Config) error {
 		"mode", cfg.Mode)
 
 	// Initialize metrics
-	if err := metrics.RegisterMetrics(); err != nil {
+	if err := metrics.RegisterMetrics(cfg.Metrics.ExtraLabels); err != nil {
 		return fmt.Errorf("unable to register metrics: %w", err)
 	}
 
@@ -272,11 +492,29 @@ func runStandalone(cfg *config.Config) error {
 		}
 	}
 
-	// Create a synthetic collector for tenant discovery
-	collector := createStandaloneCollector(cfg)
+	ctx, cancel := context.WithCancel(ctrl.SetupSignalHandler())
+	defer cancel()
+
+	// Reload the state of the previous run when a state database is configured
+	var store *statestore.Store
+	if cfg.Standalone.StateDB != "" {
+		var err error
+		if store, err = statestore.Open(cfg.Standalone.StateDB, setupLog.WithName("state-store")); err != nil {
+			return err
+		}
+		defer func() {
+			if err := store.Close(); err != nil {
+				setupLog.Error(err, "failed to close state database")
+			}
+		}()
+	}
+
+	// Create a collector discovering the tenants without Kubernetes
+	liveConfig := config.NewLive(cfg)
+	collector := createStandaloneCollector(liveConfig)
 
 	// Discover tenants
-	tenants, err := collector.GetTenantList(context.TODO())
+	tenants, err := collector.GetTenantList(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to discover tenants: %w", err)
 	}
@@ -285,64 +523,123 @@ func runStandalone(cfg *config.Config) error {
 		"count", len(tenants),
 		"tenants", tenants)
 
-	// Setup the web UI server if enabled in standalone mode
-	if cfg.UI.Enabled {
-		setupLog.Info("Starting UI server in standalone mode", "port", cfg.UI.Port)
-
-		// Create a mock controller for standalone mode
-		mockController := &controller.MimirLimitController{
-			Client:    nil, // No Kubernetes client in standalone mode
-			Scheme:    nil,
-			Config:    cfg,
-			Log:       setupLog.WithName("mock-controller"),
-			Collector: collector,
-		}
+	// Create the controller reconciling without Kubernetes; it writes no ConfigMap
+	standaloneController := &controller.MimirLimitController{
+		Client:    nil, // No Kubernetes client in standalone mode
+		Scheme:    nil,
+		Config:    liveConfig,
+		Log:       setupLog.WithName("standalone-controller"),
+		Collector: collector,
+	}
+	if store != nil {
+		standaloneController.AuditLogger = store.AuditLogger()
+	}
+	standaloneController.SetupStandalone(store)
 
-		// Create the full API server with health endpoints
-		apiServer := api.NewServer(mockController, cfg, setupLog.WithName("api"), uiAssets)
+	reconciler := &controller.StandaloneReconciler{
+		Controller: standaloneController,
+		Interval:   cfg.UpdateInterval,
+		Log:        setupLog.WithName("standalone-reconciler"),
+	}
 
-		setupLog.Info("Web UI enabled in standalone mode", "port", cfg.UI.Port, "url", fmt.Sprintf("http://localhost:%d", cfg.UI.Port))
+	if !cfg.UI.Enabled {
+		setupLog.Info("Web UI disabled, logging recommended limits only")
+		return reconciler.Run(ctx)
+	}
+
+	// Setup the web UI server in standalone mode, serving the latest recommendations
+	setupLog.Info("Starting UI server in standalone mode", "port", cfg.UI.Port)
+	apiServer := api.NewServer(standaloneController, liveConfig, setupLog.WithName("api"), uiAssets)
 
-		// Start server and block
-		if err := apiServer.Start(cfg.UI.Port); err != nil && err != http.ErrServerClosed {
+	scheme := "http"
+	if cfg.UI.TLS.Enabled() {
+		scheme = "https"
+	}
+	setupLog.Info("Web UI enabled in standalone mode", "port", cfg.UI.Port, "url", fmt.Sprintf("%s://localhost:%d", scheme, cfg.UI.Port))
+
+	reconcilerDone := make(chan error, 1)
+	go func() {
+		reconcilerDone <- reconciler.Run(ctx)
+	}()
+
+	// Serve until shutdown, then stop the server and wait for the reconciler so the state
+	// database is closed after the last write
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- apiServer.Start(cfg.UI.Port)
+	}()
+	select {
+	case err := <-serverErr:
+		cancel()
+		<-reconcilerDone
+		if err != nil && err != http.ErrServerClosed {
 			return fmt.Errorf("failed to start UI server: %w", err)
 		}
-	} else {
-		setupLog.Info("Standalone mode demonstration complete - tenant discovery successful")
-		setupLog.Info("Web UI disabled - exiting")
+	case <-ctx.Done():
+		setupLog.Info("Shutting down standalone mode")
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := apiServer.Stop(shutdownCtx); err != nil {
+			setupLog.Error(err, "failed to stop UI server")
+		}
+		return <-reconcilerDone
 	}
 
 	return nil
 }
 
 // createStandaloneCollector creates a collector that can work without Kubernetes
-func createStandaloneCollector(cfg *config.Config) *standaloneCollector {
-	return &standaloneCollector{
+func createStandaloneCollector(live *config.Live) *standaloneCollector {
+	cfg := live.Load()
+	s := &standaloneCollector{
 		cfg: cfg,
 		log: setupLog.WithName("standalone-collector"),
 	}
+	switch {
+	case cfg.Synthetic.Enabled:
+		s.source = collector.NewSyntheticCollector(live, s.log.WithName("synthetic"))
+	case cfg.MetricsEndpoint != "":
+		// Standalone mode runs without service discovery, so the collector only queries the
+		// metrics endpoint
+		s.source = collector.NewMimirCollector(live, nil, s.log.WithName("mimir"))
+	}
+	return s
 }
 
 // standaloneCollector implements basic tenant discovery without Kubernetes
 type standaloneCollector struct {
 	cfg *config.Config
 	log logr.Logger
+	// source collects the samples of the tenants: the synthetic collector with
+	// synthetic.enabled, the metrics endpoint otherwise; nil without either
+	source collector.Collector
 }
 
-// CollectMetrics implements basic metrics collection for standalone mode
+// CollectMetrics collects the samples of the discovered tenants from the source; tenants
+// without samples are reported with no metrics
 func (s *standaloneCollector) CollectMetrics(ctx context.Context) (map[string]*collector.TenantMetrics, error) {
-	// In standalone mode, return synthetic metrics data
 	tenants, err := s.GetTenantList(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	metrics := make(map[string]*collector.TenantMetrics)
+	if s.source != nil {
+		collected, err := s.source.CollectMetrics(ctx)
+		if err != nil && !collector.IsPartialFailure(err) {
+			s.log.Error(err, "failed to collect metrics, reporting the tenants without samples")
+		}
+		for tenant, tm := range collected {
+			metrics[tenant] = tm
+		}
+	}
 	for _, tenant := range tenants {
-		metrics[tenant] = &collector.TenantMetrics{
-			Tenant:     tenant,
-			Metrics:    make(map[string][]collector.MetricData),
-			LastUpdate: time.Now(),
+		if _, exists := metrics[tenant]; !exists {
+			metrics[tenant] = &collector.TenantMetrics{
+				Tenant:     tenant,
+				Metrics:    make(map[string][]collector.MetricData),
+				LastUpdate: time.Now(),
+			}
 		}
 	}
 
@@ -358,6 +655,11 @@ func (s *standaloneCollector) GetTenantList(ctx context.Context) ([]string, erro
 		return s.cfg.MetricsDiscovery.TenantDiscovery.FallbackTenants, nil
 	}
 
+	// The synthetic collector generates the samples of its own tenants
+	if synthetic, ok := s.source.(*collector.SyntheticCollector); ok {
+		return synthetic.GetTenantList(ctx)
+	}
+
 	// Try synthetic tenants
 	if s.cfg.MetricsDiscovery.TenantDiscovery.EnableSynthetic || s.cfg.Synthetic.Enabled {
 		count := s.cfg.MetricsDiscovery.TenantDiscovery.SyntheticCount
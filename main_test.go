@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// exportedOverrides is the runtime overrides ConfigMap the export tests read
+const exportedOverrides = `overrides:
+  tenant-a:
+    ingestion_rate: 25000
+    max_global_series_per_user: 150000
+  tenant-b:
+    ingestion_burst_size: 400000
+`
+
+// wantHelmValues are the exportedOverrides limits under the Helm values path
+var wantHelmValues = map[string]interface{}{
+	"mimir": map[string]interface{}{
+		"structuredConfig": map[string]interface{}{
+			"overrides": map[string]interface{}{
+				"tenant-a": map[string]interface{}{"ingestion_rate": 25000.0, "max_global_series_per_user": 150000.0},
+				"tenant-b": map[string]interface{}{"ingestion_burst_size": 400000.0},
+			},
+		},
+	},
+}
+
+func exportLimitsConfigMap(cfg *config.Config) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Mimir.ConfigMapName, Namespace: cfg.Mimir.Namespace},
+		Data:       map[string]string{"overrides.yaml": exportedOverrides},
+	}
+}
+
+func TestLimitsHelmValues(t *testing.T) {
+	tests := []struct {
+		format    string
+		unmarshal func([]byte, interface{}) error
+	}{
+		{"yaml", func(data []byte, v interface{}) error { return yaml.Unmarshal(data, v) }},
+		{"json", json.Unmarshal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg := config.GetDefaultConfig()
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(exportLimitsConfigMap(cfg)).Build()
+
+			data, err := limitsHelmValues(context.Background(), c, cfg, tt.format)
+			if err != nil {
+				t.Fatalf("limitsHelmValues: %v", err)
+			}
+			var values map[string]interface{}
+			if err := tt.unmarshal(data, &values); err != nil {
+				t.Fatalf("exported %s does not parse: %v\n%s", tt.format, err, data)
+			}
+			if !reflect.DeepEqual(values, wantHelmValues) {
+				t.Errorf("exported values = %v, want %v", values, wantHelmValues)
+			}
+		})
+	}
+}
+
+func TestLimitsHelmValuesYAMLLayout(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(exportLimitsConfigMap(cfg)).Build()
+
+	data, err := limitsHelmValues(context.Background(), c, cfg, "yaml")
+	if err != nil {
+		t.Fatalf("limitsHelmValues: %v", err)
+	}
+	want := `mimir:
+  structuredConfig:
+    overrides:
+      tenant-a:
+        ingestion_rate: 25000
+        max_global_series_per_user: 150000
+      tenant-b:
+        ingestion_burst_size: 400000
+`
+	if string(data) != want {
+		t.Errorf("exported YAML:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestLimitsHelmValuesErrors(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+
+	missing := fake.NewClientBuilder().WithScheme(scheme).Build()
+	_, err := limitsHelmValues(context.Background(), missing, cfg, "yaml")
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("export without the ConfigMap = %v, want a does not exist error", err)
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := missing.List(context.Background(), configMaps); err != nil {
+		t.Fatalf("list ConfigMaps: %v", err)
+	}
+	if len(configMaps.Items) != 0 {
+		t.Errorf("export created the missing ConfigMap")
+	}
+
+	existing := fake.NewClientBuilder().WithScheme(scheme).WithObjects(exportLimitsConfigMap(cfg)).Build()
+	if _, err := limitsHelmValues(context.Background(), existing, cfg, "toml"); err == nil {
+		t.Errorf("export as toml succeeded, want an unsupported format error")
+	}
+}
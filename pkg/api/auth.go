@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// API roles: viewers may send GET requests, admins any request
+const (
+	RoleViewer = "viewer"
+	RoleAdmin  = "admin"
+)
+
+// Identity is the authenticated caller of an API request
+type Identity struct {
+	User string `json:"user"`
+	Role string `json:"role"`
+	// Method is how the caller authenticated: "token" or "tokenreview"
+	Method string `json:"method"`
+}
+
+// identityKey is the request context key of the caller's Identity
+type identityKey struct{}
+
+// requestIdentity returns the authenticated caller of a request, nil when API
+// authentication is disabled
+func requestIdentity(r *http.Request) *Identity {
+	identity, _ := r.Context().Value(identityKey{}).(*Identity)
+	return identity
+}
+
+// requestUser returns the user recorded in the audit entries of a request: the
+// authenticated user or, when API authentication is disabled, the X-Forwarded-User
+// header marked as unverified, since any client can send it
+func requestUser(r *http.Request) string {
+	if identity := requestIdentity(r); identity != nil {
+		return identity.User
+	}
+	if user := r.Header.Get("X-Forwarded-User"); user != "" {
+		return fmt.Sprintf("unauthenticated (X-Forwarded-User: %s)", user)
+	}
+	return ""
+}
+
+// viewerPostPaths are the POST endpoints without side effects, which only evaluate the
+// request body, so viewers may call them
+var viewerPostPaths = map[string]bool{
+	"/api/v1/limits/validate": true,
+	"/api/v1/alerts/route":    true,
+}
+
+// requiredRole returns the role an API request needs
+func requiredRole(method, path string) string {
+	switch {
+	case method == http.MethodGet || method == http.MethodHead:
+		return RoleViewer
+	case method == http.MethodPost && viewerPostPaths[path]:
+		return RoleViewer
+	default:
+		return RoleAdmin
+	}
+}
+
+// roleAllows reports whether a role may send requests that need the required role
+func roleAllows(role, required string) bool {
+	return role == RoleAdmin || (role == RoleViewer && required == RoleViewer)
+}
+
+// staticToken is an entry of the tokens file
+type staticToken struct {
+	Token string `json:"token"`
+	User  string `json:"user"`
+	Role  string `json:"role"`
+}
+
+// tokensFile is the format of ui.auth.tokensFile:
+//
+//	tokens:
+//	  - token: "<random string>"
+//	    user: alice
+//	    role: admin
+type tokensFile struct {
+	Tokens []staticToken `json:"tokens"`
+}
+
+// reviewedToken is a TokenReview result trusted until expires
+type reviewedToken struct {
+	identity *Identity
+	expires  time.Time
+}
+
+// authenticator authenticates API requests with the static tokens of ui.auth.tokensFile
+// and, for other tokens, a Kubernetes TokenReview
+type authenticator struct {
+	live *config.Live
+	// kubeClient returns the client TokenReviews are created with, nil outside Kubernetes
+	kubeClient func() kubernetes.Interface
+	now        func() time.Time
+
+	mu sync.Mutex
+	// tokens are the valid entries of the tokens file, read at tokensModTime
+	tokens        []staticToken
+	tokensPath    string
+	tokensModTime time.Time
+	// reviewed caches TokenReview results by token hash
+	reviewed map[string]reviewedToken
+}
+
+// newAuthenticator creates the authenticator of the API requests
+func newAuthenticator(live *config.Live, kubeClient func() kubernetes.Interface) *authenticator {
+	return &authenticator{
+		live:       live,
+		kubeClient: kubeClient,
+		now:        time.Now,
+		reviewed:   make(map[string]reviewedToken),
+	}
+}
+
+// config returns the configuration in effect
+func (a *authenticator) config() *config.Config {
+	return a.live.Load()
+}
+
+// authenticate returns the identity of a bearer token, or nil when no token source
+// accepts it
+func (a *authenticator) authenticate(ctx context.Context, token string) (*Identity, error) {
+	settings := a.config().UI.Auth
+	if settings.TokensFile != "" {
+		tokens, err := a.staticTokens(settings.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		var match *staticToken
+		for i := range tokens {
+			// Compare every token in constant time so the response time does not tell
+			// how much of a token matched
+			if subtle.ConstantTimeCompare([]byte(tokens[i].Token), []byte(token)) == 1 {
+				match = &tokens[i]
+			}
+		}
+		if match != nil {
+			return &Identity{User: match.User, Role: match.Role, Method: "token"}, nil
+		}
+	}
+
+	if settings.TokenReview.Enabled {
+		return a.review(ctx, token, settings.TokenReview)
+	}
+	return nil, nil
+}
+
+// staticTokens returns the valid entries of the tokens file, reading it again when its
+// modification time changed, as when the Secret it is mounted from is updated
+func (a *authenticator) staticTokens(path string) ([]staticToken, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if path == a.tokensPath && info.ModTime().Equal(a.tokensModTime) {
+		return a.tokens, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokens file: %w", err)
+	}
+	var file tokensFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file: %w", err)
+	}
+
+	tokens := make([]staticToken, 0, len(file.Tokens))
+	for i, token := range file.Tokens {
+		if token.Token == "" || token.User == "" || (token.Role != RoleViewer && token.Role != RoleAdmin) {
+			return nil, fmt.Errorf("tokens file entry %d needs a token, a user and the role %s or %s", i, RoleViewer, RoleAdmin)
+		}
+		tokens = append(tokens, token)
+	}
+
+	a.tokens, a.tokensPath, a.tokensModTime = tokens, path, info.ModTime()
+	return tokens, nil
+}
+
+// review authenticates a token with a Kubernetes TokenReview and maps the groups of the
+// user to a role. Authenticated users in neither the admin nor the viewer groups get an
+// identity without a role.
+func (a *authenticator) review(ctx context.Context, token string, settings config.APITokenReviewConfig) (*Identity, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := a.now()
+
+	a.mu.Lock()
+	cached, exists := a.reviewed[key]
+	a.mu.Unlock()
+	if exists && now.Before(cached.expires) {
+		return cached.identity, nil
+	}
+
+	client := a.kubeClient()
+	if client == nil {
+		return nil, nil
+	}
+	review, err := client.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: settings.Audiences},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return nil, nil
+	}
+
+	identity := &Identity{User: review.Status.User.Username, Method: "tokenreview"}
+	switch {
+	case containsAny(review.Status.User.Groups, settings.AdminGroups):
+		identity.Role = RoleAdmin
+	case containsAny(review.Status.User.Groups, settings.ViewerGroups):
+		identity.Role = RoleViewer
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for cachedKey, entry := range a.reviewed {
+		if !now.Before(entry.expires) {
+			delete(a.reviewed, cachedKey)
+		}
+	}
+	if settings.CacheTTL > 0 {
+		a.reviewed[key] = reviewedToken{identity: identity, expires: now.Add(settings.CacheTTL)}
+	}
+	return identity, nil
+}
+
+// logAuthSettings logs the API authentication on startup, warning when the API is open
+// to anyone who can reach it
+func (s *Server) logAuthSettings() {
+	settings := s.config().UI.Auth
+	if !settings.Enabled {
+		s.log.Info("WARNING: API authentication is disabled, anyone who can reach the API server can change the configuration, limits and circuit breaker; set ui.auth.enabled to require bearer tokens")
+		return
+	}
+	if settings.TokenReview.Enabled && s.kubeClient() == nil {
+		s.log.Error(fmt.Errorf("no Kubernetes client"), "TokenReview authentication is unavailable, only tokens of the tokens file are accepted")
+	}
+	if settings.TokenReview.Enabled && len(settings.TokenReview.AdminGroups) == 0 {
+		s.log.Info("WARNING: ui.auth.tokenReview.adminGroups is empty, no reviewed token gets the admin role")
+	}
+	if settings.TokenReview.Enabled && len(settings.TokenReview.ViewerGroups) == 0 {
+		s.log.Info("WARNING: ui.auth.tokenReview.viewerGroups is empty, only reviewed tokens in the admin groups get a role")
+	}
+	s.log.Info("API authentication enabled", "tokens_file", settings.TokensFile,
+		"token_review", settings.TokenReview.Enabled)
+}
+
+// containsAny reports whether any of the wanted values is in values
+func containsAny(values, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if value == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bearerToken returns the bearer token of a request's Authorization header
+func bearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// authMiddleware rejects API requests without a valid bearer token with 401
+// Unauthorized, and requests the caller's role does not allow with 403 Forbidden, when
+// ui.auth is enabled. Admin requests are audited with the caller's identity.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config().UI.Auth.Enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := bearerToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mimir-limit-optimizer"`)
+			s.writeError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		identity, err := s.authenticator.authenticate(r.Context(), token)
+		if err != nil {
+			s.log.Error(err, "failed to authenticate API request", "path", r.URL.Path)
+			s.writeError(w, http.StatusServiceUnavailable, "Authentication unavailable")
+			return
+		}
+		if identity == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mimir-limit-optimizer", error="invalid_token"`)
+			s.writeError(w, http.StatusUnauthorized, "Invalid token")
+			return
+		}
+
+		required := requiredRole(r.Method, r.URL.Path)
+		if !roleAllows(identity.Role, required) {
+			s.log.Info("forbidden API request", "user", identity.User, "role", identity.Role,
+				"method", r.Method, "path", r.URL.Path)
+			s.writeError(w, http.StatusForbidden, fmt.Sprintf("The %s role is required", required))
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), identityKey{}, identity))
+		if required != RoleAdmin {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		s.auditAdminRequest(r, identity, recorder.status)
+	})
+}
+
+// auditAdminRequest records an admin request with the caller's identity and the
+// response status
+func (s *Server) auditAdminRequest(r *http.Request, identity *Identity, status int) {
+	if s.controller == nil || s.controller.AuditLogger == nil {
+		return
+	}
+
+	entry := &auditlog.AuditEntry{
+		Action: "api-request",
+		Reason: fmt.Sprintf("%s %s", r.Method, r.URL.Path),
+		Source: "api",
+		User:   identity.User,
+		Changes: map[string]interface{}{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      status,
+			"role":        identity.Role,
+			"auth_method": identity.Method,
+		},
+		Success:   status < http.StatusBadRequest,
+		Component: "api",
+	}
+	if !entry.Success {
+		entry.Error = http.StatusText(status)
+	}
+	if err := s.controller.AuditLogger.LogEntry(entry); err != nil {
+		s.log.Error(err, "failed to log API request", "user", identity.User, "path", r.URL.Path)
+	}
+}
+
+// statusRecorder records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,341 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+const testTokens = `tokens:
+  - token: admin-token
+    user: alice
+    role: admin
+  - token: viewer-token
+    user: bob
+    role: viewer
+`
+
+// writeTokensFile writes a tokens file to the test's directory and returns its path
+func writeTokensFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write tokens file: %v", err)
+	}
+	return path
+}
+
+func bearer(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+// newAuthTestServer creates a server requiring the tokens of testTokens, whose admin
+// requests are audited in the returned logger
+func newAuthTestServer(t *testing.T) (*Server, auditlog.AuditLogger) {
+	t.Helper()
+	cfg := config.GetDefaultConfig()
+	cfg.UI.Auth.Enabled = true
+	cfg.UI.Auth.TokensFile = writeTokensFile(t, testTokens)
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.Emergency.PanicMode.Actions = nil
+	cfg.Alerting.Slack.WebhookURL = "https://hooks.slack.com/services/T000/B000/secret"
+
+	s := newTestServer(cfg)
+	audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	s.controller.AuditLogger = audit
+	s.controller.BlastProtector = circuitbreaker.NewBlastProtector(s.live, logr.Discard())
+	return s, audit
+}
+
+// apiRequests returns the api-request audit entries
+func apiRequests(t *testing.T, audit auditlog.AuditLogger) []*auditlog.AuditEntry {
+	t.Helper()
+	entries, err := audit.GetEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	var requests []*auditlog.AuditEntry
+	for _, entry := range entries {
+		if entry.Action == "api-request" {
+			requests = append(requests, entry)
+		}
+	}
+	return requests
+}
+
+func TestAuthRequiresBearerToken(t *testing.T) {
+	s, _ := newAuthTestServer(t)
+
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{"no token", nil},
+		{"other scheme", http.Header{"Authorization": {"Basic YWxpY2U6c2VjcmV0"}}},
+		{"invalid token", bearer("guessed-token")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(s, http.MethodGet, "/api/status", "", tt.header)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401: %s", rec.Code, rec.Body)
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("401 response has no WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	// Probes stay unauthenticated
+	if rec := serve(s, http.MethodGet, "/health", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /health without a token status = %d, want 200", rec.Code)
+	}
+}
+
+func TestAuthRoles(t *testing.T) {
+	s, audit := newAuthTestServer(t)
+	trip := "/api/circuit-breaker/trip?reason=bad+deploy"
+
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("viewer-token")); rec.Code != http.StatusOK {
+		t.Errorf("viewer GET status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec := serve(s, http.MethodPost, trip, "", bearer("viewer-token")); rec.Code != http.StatusForbidden {
+		t.Errorf("viewer POST status = %d, want 403: %s", rec.Code, rec.Body)
+	}
+	if state := s.controller.BlastProtector.GetProtectionStatus()["circuit_breaker_state"]; state != "CLOSED" {
+		t.Errorf("circuit breaker state after the forbidden trip = %v, want CLOSED", state)
+	}
+	// POST endpoints without side effects are open to viewers
+	if rec := serve(s, http.MethodPost, "/api/v1/limits/validate", `{"limits": {"ingestion_rate": 25000}}`, bearer("viewer-token")); rec.Code != http.StatusOK {
+		t.Errorf("viewer POST /api/v1/limits/validate status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if requests := apiRequests(t, audit); len(requests) != 0 {
+		t.Errorf("viewer requests audited as admin requests: %+v", requests)
+	}
+
+	if rec := serve(s, http.MethodPost, trip, "", bearer("admin-token")); rec.Code != http.StatusOK {
+		t.Fatalf("admin POST status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	requests := apiRequests(t, audit)
+	if len(requests) != 1 {
+		t.Fatalf("%d admin requests audited, want 1", len(requests))
+	}
+	entry := requests[0]
+	if entry.User != "alice" || !entry.Success || entry.Changes["role"] != RoleAdmin || entry.Changes["auth_method"] != "token" {
+		t.Errorf("audited admin request = %+v, want alice's successful token request", entry)
+	}
+	if entry.Reason != "POST /api/circuit-breaker/trip" {
+		t.Errorf("audited admin request reason = %q, want the method and path", entry.Reason)
+	}
+}
+
+func TestConfigRedactedForViewers(t *testing.T) {
+	s, _ := newAuthTestServer(t)
+	secret := s.config().Alerting.Slack.WebhookURL
+
+	slackWebhook := func(token string) string {
+		t.Helper()
+		rec := serve(s, http.MethodGet, "/api/config", "", bearer(token))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/config status = %d: %s", rec.Code, rec.Body)
+		}
+		var cfg struct {
+			Alerting struct {
+				Slack struct {
+					WebhookURL string `json:"webhookURL"`
+				} `json:"slack"`
+			} `json:"alerting"`
+		}
+		decodeJSON(t, rec, &cfg)
+		return cfg.Alerting.Slack.WebhookURL
+	}
+
+	if got := slackWebhook("viewer-token"); got != config.RedactedValue {
+		t.Errorf("Slack webhook served to a viewer = %q, want %q", got, config.RedactedValue)
+	}
+	if got := slackWebhook("admin-token"); got != secret {
+		t.Errorf("Slack webhook served to an admin = %q, want %q", got, secret)
+	}
+}
+
+func TestTokensFileReloaded(t *testing.T) {
+	s, _ := newAuthTestServer(t)
+	path := s.config().UI.Auth.TokensFile
+
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("viewer-token")); rec.Code != http.StatusOK {
+		t.Fatalf("viewer GET status = %d, want 200", rec.Code)
+	}
+
+	// The Secret is updated with a rotated viewer token
+	rotated := "tokens:\n  - token: rotated-token\n    user: bob\n    role: viewer\n"
+	if err := os.WriteFile(path, []byte(rotated), 0o600); err != nil {
+		t.Fatalf("write tokens file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("touch tokens file: %v", err)
+	}
+
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("viewer-token")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET with the replaced token status = %d, want 401", rec.Code)
+	}
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("rotated-token")); rec.Code != http.StatusOK {
+		t.Errorf("GET with the rotated token status = %d, want 200", rec.Code)
+	}
+}
+
+func TestInvalidTokensFileUnavailable(t *testing.T) {
+	s, _ := newAuthTestServer(t)
+	if err := os.WriteFile(s.config().UI.Auth.TokensFile, []byte("tokens:\n  - token: t\n    user: eve\n    role: root\n"), 0o600); err != nil {
+		t.Fatalf("write tokens file: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(s.config().UI.Auth.TokensFile, later, later); err != nil {
+		t.Fatalf("touch tokens file: %v", err)
+	}
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("t")); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET with a tokens file of an unknown role status = %d, want 503", rec.Code)
+	}
+}
+
+// tokenReviewer answers TokenReviews from its users by token, counting the reviews
+type tokenReviewer struct {
+	mu      sync.Mutex
+	users   map[string]authenticationv1.UserInfo
+	reviews int
+}
+
+func (r *tokenReviewer) react(action k8stesting.Action) (bool, runtime.Object, error) {
+	review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviews++
+	user, authenticated := r.users[review.Spec.Token]
+	review.Status = authenticationv1.TokenReviewStatus{Authenticated: authenticated, User: user}
+	return true, review, nil
+}
+
+func (r *tokenReviewer) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reviews
+}
+
+func TestTokenReviewRoles(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.Auth.Enabled = true
+	cfg.UI.Auth.TokenReview = config.APITokenReviewConfig{
+		Enabled:      true,
+		AdminGroups:  []string{"mimir-admins"},
+		ViewerGroups: []string{"mimir-viewers"},
+		CacheTTL:     time.Minute,
+	}
+	reviewer := &tokenReviewer{users: map[string]authenticationv1.UserInfo{
+		"sa-admin":  {Username: "system:serviceaccount:ops:deployer", Groups: []string{"mimir-admins"}},
+		"sa-viewer": {Username: "system:serviceaccount:ops:dashboard", Groups: []string{"mimir-viewers"}},
+		"sa-other":  {Username: "system:serviceaccount:default:default", Groups: []string{"system:authenticated"}},
+	}}
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", reviewer.react)
+
+	s := newTestServer(cfg)
+	s.SetK8sClient(kubeClient)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.authenticator.now = func() time.Time { return now }
+
+	tests := []struct {
+		token  string
+		method string
+		want   int
+	}{
+		{"sa-admin", http.MethodDelete, http.StatusOK},
+		{"sa-viewer", http.MethodGet, http.StatusOK},
+		{"sa-viewer", http.MethodDelete, http.StatusForbidden},
+		// Authenticated, but in neither the admin nor the viewer groups
+		{"sa-other", http.MethodGet, http.StatusForbidden},
+		{"expired", http.MethodGet, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		path := "/api/status"
+		if tt.method == http.MethodDelete {
+			path = "/api/v1/emergency/freeze"
+		}
+		if rec := serve(s, tt.method, path, "", bearer(tt.token)); rec.Code != tt.want {
+			t.Errorf("%s %s with token %s status = %d, want %d: %s", tt.method, path, tt.token, rec.Code, tt.want, rec.Body)
+		}
+	}
+	reviews := reviewer.count()
+
+	// Reviewed tokens are trusted for the cache TTL, then reviewed again
+	serve(s, http.MethodGet, "/api/status", "", bearer("sa-viewer"))
+	if got := reviewer.count(); got != reviews {
+		t.Errorf("a cached token was reviewed again %d times", got-reviews)
+	}
+	now = now.Add(cfg.UI.Auth.TokenReview.CacheTTL)
+	serve(s, http.MethodGet, "/api/status", "", bearer("sa-viewer"))
+	if got := reviewer.count(); got != reviews+1 {
+		t.Errorf("a token past the cache TTL was reviewed %d times, want once", got-reviews)
+	}
+}
+
+func TestTokenReviewWithoutViewerGroups(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.Auth.Enabled = true
+	cfg.UI.Auth.TokenReview = config.APITokenReviewConfig{Enabled: true, CacheTTL: time.Minute}
+	reviewer := &tokenReviewer{users: map[string]authenticationv1.UserInfo{
+		"sa-other": {Username: "system:serviceaccount:default:default", Groups: []string{"system:authenticated"}},
+	}}
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", reviewer.react)
+
+	s := newTestServer(cfg)
+	s.SetK8sClient(kubeClient)
+	var logged []string
+	s.log = funcr.New(func(prefix, args string) { logged = append(logged, args) }, funcr.Options{})
+	s.logAuthSettings()
+	for _, field := range []string{"adminGroups", "viewerGroups"} {
+		if !strings.Contains(strings.Join(logged, "\n"), "ui.auth.tokenReview."+field+" is empty") {
+			t.Errorf("startup log %q does not warn about the empty %s", logged, field)
+		}
+	}
+
+	// Without groups to map, authenticated identities get no role
+	if rec := serve(s, http.MethodGet, "/api/status", "", bearer("sa-other")); rec.Code != http.StatusForbidden {
+		t.Errorf("GET with an authenticated token status = %d, want 403", rec.Code)
+	}
+	if rec := serve(s, http.MethodDelete, "/api/v1/emergency/freeze", "", bearer("sa-other")); rec.Code != http.StatusForbidden {
+		t.Errorf("DELETE with an authenticated token status = %d, want 403", rec.Code)
+	}
+}
+
+func TestAuthDisabledByDefault(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+	if rec := serve(s, http.MethodGet, "/api/status", "", nil); rec.Code != http.StatusOK {
+		t.Errorf("GET without a token status = %d, want 200", rec.Code)
+	}
+
+	// Without authentication the forwarded user cannot be trusted
+	req := httptest.NewRequest(http.MethodPost, "/api/config", nil)
+	req.Header.Set("X-Forwarded-User", "mallory")
+	if got, want := requestUser(req), "unauthenticated (X-Forwarded-User: mallory)"; got != want {
+		t.Errorf("requestUser = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteInfo describes an API route for the catalog
+type RouteInfo struct {
+	Description  string
+	AuthRequired bool
+}
+
+// RouteMetadata describes the registered routes, by method and path template. Routes
+// missing here are still listed by the catalog, without a description, and logged at
+// startup.
+var RouteMetadata = map[string]RouteInfo{
+	"GET /api/v1":                                          {Description: "Lists the API endpoints"},
+	"GET /api/status":                                      {Description: "Returns the current system status"},
+	"GET /api/config":                                      {Description: "Returns the running configuration"},
+	"POST /api/config":                                     {Description: "Updates the running configuration"},
+	"GET /api/config/effective":                            {Description: "Returns the running configuration with the hash and load time of its file"},
+	"GET /api/metrics":                                     {Description: "Returns the optimizer metrics"},
+	"POST /api/v1/emergency/freeze":                        {Description: "Halts all automated limit changes, for ?ttl= when given"},
+	"DELETE /api/v1/emergency/freeze":                      {Description: "Lifts the emergency freeze so limit changes resume"},
+	"GET /api/dashboard":                                   {Description: "Returns the dashboard data with namespace info and architecture flow"},
+	"GET /api/tenants":                                     {Description: "Lists the tenants with their basic info"},
+	"GET /api/tenants/scoping":                             {Description: "Returns the effective tenant skip, include and paused lists"},
+	"POST /api/tenants/scoping":                            {Description: "Adds a pattern to the tenant skip or include list"},
+	"DELETE /api/tenants/scoping":                          {Description: "Removes a pattern from the tenant skip or include list"},
+	"GET /api/tiers":                                       {Description: "Lists the tenant tiers with their monitored tenants"},
+	"GET /api/tenants/{tenant_id}":                         {Description: "Returns detailed information of a tenant"},
+	"GET /api/tenants/{tenant_id}/recommendations/history": {Description: "Returns the recommendation history of a tenant"},
+	"POST /api/tenants/{tenant_id}/pause":                  {Description: "Pauses the optimization of a tenant, leaving its limits unchanged"},
+	"POST /api/tenants/{tenant_id}/unpause":                {Description: "Resumes the optimization of a paused tenant"},
+	"POST /api/v1/tenants/{tenant_id}/simulate-spike":      {Description: "Multiplies the metrics of a tenant for a limited time, simulating a spike"},
+	"GET /api/v1/tenants/{tenant_id}/active-spikes":        {Description: "Returns the active synthetic spike of a tenant"},
+	"GET /api/v1/tenants/{tenant_id}/seasonality":          {Description: "Returns the weekly usage pattern of a tenant"},
+	"GET /api/namespaces":                                  {Description: "Returns detailed information of the tenant namespaces"},
+	"GET /api/architecture/flow":                           {Description: "Returns the Mimir architecture flow of a tenant or overall"},
+	"GET /api/diff":                                        {Description: "Returns the diff between dry-run and applied limits"},
+	"GET /api/preview":                                     {Description: "Returns the limit changes the next reconciliation would write, without writing them"},
+	"GET /api/audit":                                       {Description: "Returns audit log entries"},
+	"GET /api/v1/drift":                                    {Description: "Returns the latest limit drift report against the secondary cluster"},
+	"GET /api/cost":                                        {Description: "Returns the spend of each tenant against its budget"},
+	"GET /api/v1/cost/report":                              {Description: "Returns the projected monthly cost of each tenant against its budget"},
+	"GET /api/v1/limits/bounds":                            {Description: "Returns the floor, ceiling and default enforced for each limit"},
+	"POST /api/v1/limits/validate":                         {Description: "Validates a set of limit values without writing them"},
+	"POST /api/v1/import":                                  {Description: "Imports the tenant limits of a Mimir runtime overrides document into the runtime overrides ConfigMap"},
+	"GET /api/protection/status":                           {Description: "Returns the circuit breaker and emergency mode state"},
+	"GET /api/protection/thresholds":                       {Description: "Returns the effective blast detection thresholds per tenant"},
+	"GET /api/protection/baselines":                        {Description: "Returns the baseline rates blast detection compares each tenant against"},
+	"POST /api/protection/ratelimiters/{tenant_id}/reset":  {Description: "Refills the rate limiter token bucket of a tenant"},
+	"POST /api/circuit-breaker/trip":                       {Description: "Forces the circuit breaker open until it is reset"},
+	"POST /api/circuit-breaker/reset":                      {Description: "Forces the circuit breaker into half-open with its failure counters reset"},
+	"GET /api/reports/recommendations":                     {Description: "Streams the recommendations of a reconciliation as JSON or CSV"},
+	"GET /api/reconcile/last":                              {Description: "Returns the per-tenant outcome of the latest reconciliation"},
+	"GET /api/reconcile/{id}":                              {Description: "Returns the per-tenant outcome of a reconciliation"},
+	"POST /api/test/spike":                                 {Description: "Triggers a synthetic ingestion spike"},
+	"POST /api/test/alert":                                 {Description: "Triggers a test alert"},
+	"POST /api/test/reconcile":                             {Description: "Triggers a manual reconciliation"},
+	"GET /api/alerts":                                      {Description: "Returns the active alerts and the resolved history"},
+	"POST /api/alerts/{id}/ack":                            {Description: "Acknowledges an active alert, stopping its escalation"},
+	"POST /api/v1/alerts/route":                            {Description: "Returns the routing rule and channels of an alert without sending it"},
+	"GET /api/v1/alerts/rules":                             {Description: "Returns Prometheus alerting rules for the limit usage of each tenant"},
+	"GET /api/v1/silences":                                 {Description: "Lists the alert silences that have not ended"},
+	"POST /api/v1/silences":                                {Description: "Suppresses the alerts matching a tenant, limit or severity for a time"},
+	"DELETE /api/v1/silences/{id}":                         {Description: "Ends an alert silence, sending the suppressed alerts still firing"},
+	"GET /api/health/infrastructure":                       {Description: "Returns the Mimir infrastructure health status"},
+	"GET /api/health/infrastructure/stream":                {Description: "Streams the infrastructure health scan as newline-delimited JSON"},
+	"GET /api/health/metrics":                              {Description: "Returns aggregated health metrics for dashboards"},
+	"GET /api/health/alerts":                               {Description: "Returns the current health alerts"},
+	"GET /api/health/recommendations":                      {Description: "Returns the health recommendations"},
+	"GET /api/health/resources":                            {Description: "Returns the scanned resources matching the query parameters"},
+	"GET /api/health/resources/{kind}/{name}":              {Description: "Returns detailed health information and events of a resource"},
+	"GET /api/infrastructure/scan":                         {Description: "Performs an autonomous infrastructure scan"},
+	"GET /api/infrastructure/components":                   {Description: "Returns the discovered Mimir components"},
+	"GET /api/infrastructure/tenants":                      {Description: "Returns the discovered tenants with their configurations"},
+	"GET /api/infrastructure/analytics":                    {Description: "Returns the infrastructure analytics dashboard data"},
+	"GET /api/v1/infrastructure/topology":                  {Description: "Returns the Mimir component graph as JSON, or Graphviz DOT with ?format=dot"},
+	"GET /health":                                          {Description: "Performs a basic health check"},
+	"GET /metrics":                                         {Description: "Serves the Prometheus metrics of the optimizer"},
+}
+
+// CatalogEntry is an endpoint listed by the API catalog
+type CatalogEntry struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	// AuthRequired reports whether requests must authenticate
+	AuthRequired bool `json:"auth_required"`
+	// Role is the role requests need when they must authenticate
+	Role string `json:"role,omitempty"`
+	// RateLimitPerMinute is the requests per minute a client may send, 0 when the
+	// endpoint is not rate limited
+	RateLimitPerMinute int `json:"rate_limit_per_minute"`
+}
+
+// buildAPICatalog lists the routes of the router, one entry per method, sorted by path
+// and method, with the descriptions of RouteMetadata. Routes without a method matcher, the UI assets, are not API endpoints
+// and are left out.
+func (s *Server) buildAPICatalog() []CatalogEntry {
+	var catalog []CatalogEntry
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			info, described := RouteMetadata[method+" "+path]
+			if !described {
+				s.log.Info("API route has no catalog description", "method", method, "path", path)
+			}
+			catalog = append(catalog, CatalogEntry{
+				Method:       method,
+				Path:         path,
+				Description:  info.Description,
+				AuthRequired: info.AuthRequired,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Error(err, "failed to list the API routes")
+	}
+
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].Path != catalog[j].Path {
+			return catalog[i].Path < catalog[j].Path
+		}
+		return catalog[i].Method < catalog[j].Method
+	})
+	return catalog
+}
+
+// rateLimitPerMinute returns the requests per minute ui.rateLimit allows a client to
+// send to an endpoint, 0 for endpoints outside /api and when rate limiting is disabled
+func (s *Server) rateLimitPerMinute(path string) int {
+	settings := s.config().UI.RateLimit
+	if !settings.Enabled || !strings.HasPrefix(path, "/api/") {
+		return 0
+	}
+	requestsPerSecond := settings.RequestsPerSecond
+	if override, exists := settings.Endpoints[path]; exists {
+		requestsPerSecond = override.RequestsPerSecond
+	}
+	return int(math.Round(requestsPerSecond * 60))
+}
+
+// handleAPICatalog lists the API endpoints. Clients that do not accept JSON, such as
+// browsers, are redirected to the Swagger UI when ui.apiDocsURL is set.
+func (s *Server) handleAPICatalog(w http.ResponseWriter, r *http.Request) {
+	if docsURL := s.config().UI.APIDocsURL; docsURL != "" && !strings.Contains(r.Header.Get("Accept"), "application/json") {
+		http.Redirect(w, r, docsURL, http.StatusFound)
+		return
+	}
+
+	// Rate limits and authentication follow configuration reloads
+	endpoints := make([]CatalogEntry, len(s.catalog))
+	for i, entry := range s.catalog {
+		entry.RateLimitPerMinute = s.rateLimitPerMinute(entry.Path)
+		if s.config().UI.Auth.Enabled && strings.HasPrefix(entry.Path, "/api/") {
+			entry.AuthRequired = true
+		}
+		if entry.AuthRequired {
+			entry.Role = requiredRole(entry.Method, entry.Path)
+		}
+		endpoints[i] = entry
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"endpoints": endpoints,
+		"count":     len(endpoints),
+	})
+}
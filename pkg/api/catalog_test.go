@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// catalogResponse is the body of GET /api/v1
+type catalogResponse struct {
+	Endpoints []CatalogEntry `json:"endpoints"`
+	Count     int            `json:"count"`
+}
+
+// acceptJSON is the header of API clients
+var acceptJSON = http.Header{"Accept": []string{"application/json"}}
+
+// registeredRoutes counts the registrations of the method and path of every route of the
+// router; routes without methods are the subrouters and UI assets
+func registeredRoutes(t *testing.T, s *Server) map[string]int {
+	t.Helper()
+	routes := make(map[string]int)
+	err := s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, method := range methods {
+			routes[method+" "+path]++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+	return routes
+}
+
+func getCatalog(t *testing.T, s *Server) catalogResponse {
+	t.Helper()
+	rec := serve(s, http.MethodGet, "/api/v1", "", acceptJSON)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1 = %d: %s", rec.Code, rec.Body.String())
+	}
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Fatalf("catalog is not well-formed JSON: %s", rec.Body.String())
+	}
+	var catalog catalogResponse
+	decodeJSON(t, rec, &catalog)
+	return catalog
+}
+
+func TestAPICatalogListsEveryRouteOnce(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+	catalog := getCatalog(t, s)
+
+	routes := registeredRoutes(t, s)
+	if catalog.Count != len(catalog.Endpoints) {
+		t.Errorf("count = %d, want the %d endpoints listed", catalog.Count, len(catalog.Endpoints))
+	}
+	listed := make(map[string]int)
+	for _, entry := range catalog.Endpoints {
+		key := entry.Method + " " + entry.Path
+		listed[key]++
+		if entry.Description == "" {
+			t.Errorf("%s has no description in RouteMetadata", key)
+		}
+	}
+	for route, registrations := range routes {
+		if registrations != 1 {
+			t.Errorf("%s is registered %d times", route, registrations)
+		}
+		if listed[route] != 1 {
+			t.Errorf("%s is listed %d times, want once", route, listed[route])
+		}
+	}
+	for route := range listed {
+		if routes[route] == 0 {
+			t.Errorf("catalog lists %s, which is not registered", route)
+		}
+	}
+	for route := range RouteMetadata {
+		if routes[route] == 0 {
+			t.Errorf("RouteMetadata describes %s, which is not registered", route)
+		}
+	}
+
+	for i := 1; i < len(catalog.Endpoints); i++ {
+		previous, entry := catalog.Endpoints[i-1], catalog.Endpoints[i]
+		if previous.Path > entry.Path || (previous.Path == entry.Path && previous.Method > entry.Method) {
+			t.Errorf("%s %s is listed after %s %s, want sorted by path and method", entry.Method, entry.Path, previous.Method, previous.Path)
+		}
+	}
+}
+
+func TestAPICatalogRateLimitsAndAuth(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.RateLimit = config.APIRateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: 10,
+		BurstCapacity:     20,
+		Endpoints: map[string]config.APIEndpointRateLimit{
+			"/api/config": {RequestsPerSecond: 0.5, BurstCapacity: 1},
+		},
+	}
+	cfg.UI.Auth.Enabled = true
+	s := newTestServer(cfg)
+
+	// Authentication itself is tested with the auth middleware
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1", nil)
+	req.Header.Set("Accept", "application/json")
+	s.handleAPICatalog(rec, req)
+	var catalog catalogResponse
+	decodeJSON(t, rec, &catalog)
+
+	entries := make(map[string]CatalogEntry)
+	for _, entry := range catalog.Endpoints {
+		entries[entry.Method+" "+entry.Path] = entry
+	}
+	tests := []struct {
+		route        string
+		rateLimit    int
+		authRequired bool
+		role         string
+	}{
+		{"GET /api/status", 600, true, RoleViewer},
+		{"GET /api/config", 30, true, RoleViewer},
+		{"POST /api/v1/alerts/route", 600, true, RoleViewer},
+		{"POST /api/config", 30, true, RoleAdmin},
+		{"GET /health", 0, false, ""},
+		{"GET /metrics", 0, false, ""},
+	}
+	for _, tt := range tests {
+		entry, listed := entries[tt.route]
+		if !listed {
+			t.Errorf("%s is not listed", tt.route)
+			continue
+		}
+		if entry.RateLimitPerMinute != tt.rateLimit || entry.AuthRequired != tt.authRequired || entry.Role != tt.role {
+			t.Errorf("%s rate limit %d, auth %v, role %q; want %d, %v and %q", tt.route,
+				entry.RateLimitPerMinute, entry.AuthRequired, entry.Role, tt.rateLimit, tt.authRequired, tt.role)
+		}
+	}
+}
+
+func TestAPICatalogWithoutRateLimitOrAuth(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.RateLimit.Enabled = false
+	cfg.UI.Auth.Enabled = false
+	for _, entry := range getCatalog(t, newTestServer(cfg)).Endpoints {
+		if entry.RateLimitPerMinute != 0 || entry.AuthRequired {
+			t.Errorf("%s %s rate limit %d, auth %v; want neither", entry.Method, entry.Path, entry.RateLimitPerMinute, entry.AuthRequired)
+		}
+	}
+}
+
+func TestAPICatalogRedirectsBrowsersToDocs(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.APIDocsURL = "/swagger/"
+	s := newTestServer(cfg)
+
+	rec := serve(s, http.MethodGet, "/api/v1", "", http.Header{"Accept": []string{"text/html,application/xhtml+xml"}})
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/swagger/" {
+		t.Errorf("GET /api/v1 from a browser = %d to %q, want a redirect to /swagger/", rec.Code, rec.Header().Get("Location"))
+	}
+	if catalog := getCatalog(t, s); len(catalog.Endpoints) == 0 {
+		t.Errorf("catalog for a JSON client lists no endpoints")
+	}
+
+	// Without docs every client gets the catalog
+	rec = serve(newTestServer(config.GetDefaultConfig()), http.MethodGet, "/api/v1", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /api/v1 without docs = %d, want the catalog", rec.Code)
+	}
+}
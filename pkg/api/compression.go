@@ -0,0 +1,108 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metricsHandler serves the Prometheus metrics, gzip compressed for clients that accept
+// it when response compression is configured
+func (s *Server) metricsHandler() http.Handler {
+	compression := s.config().Performance.Compression
+	if !s.config().Performance.Enabled || !compression.Enabled {
+		return promhttp.Handler()
+	}
+	if compression.Algorithm != "gzip" {
+		s.log.Info("metrics endpoint only supports gzip compression, serving uncompressed",
+			"algorithm", compression.Algorithm)
+		return promhttp.Handler()
+	}
+
+	// Compression is done by gzipHandler so the configured level is used
+	handler := promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{DisableCompression: true}))
+	return gzipHandler(handler, compression.Level)
+}
+
+// gzipHandler compresses the responses of next for requests that accept gzip encoding
+func gzipHandler(next http.Handler, level int) http.Handler {
+	writers := sync.Pool{
+		New: func() interface{} {
+			// The level is validated with the configuration
+			writer, _ := gzip.NewWriterLevel(io.Discard, level)
+			return writer
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := writers.Get().(*gzip.Writer)
+		writer.Reset(w)
+		defer func() {
+			_ = writer.Close()
+			writers.Put(writer)
+		}()
+
+		w.Header().Set("Content-Encoding", "gzip")
+		next.ServeHTTP(&gzipResponseWriter{ResponseWriter: w, writer: writer}, r)
+	})
+}
+
+// gzipResponseWriter writes the response body through a gzip writer
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	// The compressed length differs from any length set by the handler
+	g.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(code)
+}
+
+func (g *gzipResponseWriter) Write(data []byte) (int, error) {
+	g.Header().Del("Content-Length")
+	return g.writer.Write(data)
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		accepted := !refusedQuality(params)
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip":
+			// An explicit gzip entry takes precedence over a wildcard
+			return accepted
+		case "*":
+			wildcard = accepted
+		}
+	}
+	return wildcard
+}
+
+// refusedQuality reports whether the parameters of an Accept-Encoding entry set q=0
+func refusedQuality(params string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return err == nil && quality == 0
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+func newCompressionTestServer(enabled bool) *Server {
+	cfg := config.GetDefaultConfig()
+	cfg.Performance.Enabled = true
+	cfg.Performance.Compression = config.CompressionConfig{Enabled: enabled, Algorithm: "gzip", Level: gzip.BestSpeed}
+	return newTestServer(cfg)
+}
+
+// parseExposition parses a Prometheus text exposition and returns its metric families
+func parseExposition(t *testing.T, body io.Reader) int {
+	t.Helper()
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(body)
+	if err != nil {
+		t.Fatalf("response is not valid Prometheus exposition format: %v", err)
+	}
+	return len(families)
+}
+
+func TestMetricsGzipCompressed(t *testing.T) {
+	s := newCompressionTestServer(true)
+
+	rec := serve(s, http.MethodGet, "/metrics", "", http.Header{"Accept-Encoding": {"gzip"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if rec.Header().Get("Content-Length") != "" {
+		t.Errorf("Content-Length %q set on a compressed response", rec.Header().Get("Content-Length"))
+	}
+
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("response is not gzip compressed: %v", err)
+	}
+	defer reader.Close()
+	if families := parseExposition(t, reader); families == 0 {
+		t.Errorf("decompressed response holds no metric families")
+	}
+}
+
+func TestMetricsUncompressed(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		acceptEncoding string
+	}{
+		{"without accept-encoding", true, ""},
+		{"with gzip refused", true, "gzip;q=0, identity"},
+		{"with compression disabled", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCompressionTestServer(tt.enabled)
+
+			header := http.Header{}
+			if tt.acceptEncoding != "" {
+				header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rec := serve(s, http.MethodGet, "/metrics", "", header)
+			if got := rec.Header().Get("Content-Encoding"); got != "" {
+				t.Fatalf("Content-Encoding = %q, want an uncompressed response", got)
+			}
+			if families := parseExposition(t, rec.Body); families == 0 {
+				t.Errorf("response holds no metric families")
+			}
+		})
+	}
+}
+
+func TestAPIResponsesNotCompressed(t *testing.T) {
+	s := newCompressionTestServer(true)
+
+	rec := serve(s, http.MethodGet, "/api/v1/limits/bounds", "", http.Header{"Accept-Encoding": {"gzip"}})
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("API response Content-Encoding = %q, want only /metrics compressed", got)
+	}
+	if !strings.HasPrefix(rec.Body.String(), "{") {
+		t.Errorf("API response body = %q, want plain JSON", rec.Body.String())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for header, want := range map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"deflate, GZIP;q=0.5": true,
+		"gzip;q=0":            false,
+		"*":                   true,
+		"*;q=0":               false,
+		"gzip;q=0, *":         false,
+		"br, deflate":         false,
+		"identity, *;q=0.1":   true,
+	} {
+		if got := acceptsGzip(header); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,914 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/alerting"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/analyzer"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/auditlog"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/cache"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/circuitbreaker"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/controller"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/costcontrol"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/patcher"
+)
+
+func testDeployment(namespace, name string) *appsv1.Deployment {
+	replicas := int32(1)
+	labels := map[string]string{"app.kubernetes.io/component": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1, AvailableReplicas: 1},
+	}
+}
+
+func TestInfrastructureHealthNamespace(t *testing.T) {
+	newServer := func(cfg *config.Config) *Server {
+		return newTestServer(cfg,
+			testDeployment("mimir", "distributor"),
+			testDeployment("mimir-staging", "querier"))
+	}
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       string
+	}{
+		{"default namespace when omitted", "", http.StatusOK, "mimir/distributor"},
+		{"namespace override", "?namespace=mimir-staging", http.StatusOK, "mimir-staging/querier"},
+		{"invalid namespace", "?namespace=Mimir_Staging", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(newServer(cfg), http.MethodGet, "/api/health/infrastructure"+tt.query, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var health discovery.MimirInfrastructureHealth
+			decodeJSON(t, rec, &health)
+			var deployments []string
+			for _, resource := range health.Resources {
+				if resource.Kind == "Deployment" {
+					deployments = append(deployments, resource.Namespace+"/"+resource.Name)
+				}
+			}
+			if len(deployments) != 1 || deployments[0] != tt.want {
+				t.Errorf("scanned deployments = %v, want only %s", deployments, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfrastructureHealthNamespaceOutsideScope(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	cfg.MetricsDiscovery.ScanScope = config.ScanScopeNamespaces
+	cfg.MetricsDiscovery.ScanNamespaces = []string{"mimir"}
+	s := newTestServer(cfg, testDeployment("mimir-staging", "querier"))
+
+	if rec := serve(s, http.MethodGet, "/api/health/infrastructure?namespace=mimir-staging", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestNamespaceDataWithoutDeploymentConditions(t *testing.T) {
+	rollingOut := testDeployment("mimir", "mimir-distributor")
+	rollingOut.Status = appsv1.DeploymentStatus{}
+	s := newTestServer(config.GetDefaultConfig())
+	s.SetK8sClient(kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "mimir"}},
+		rollingOut,
+	))
+
+	data := s.getNamespaceData(context.Background())
+
+	namespaces, _ := data["namespaces"].([]map[string]interface{})
+	if len(namespaces) != 1 {
+		t.Fatalf("namespace data = %v, want the mimir namespace", data)
+	}
+	components, _ := namespaces[0]["mimir_components"].([]map[string]interface{})
+	if len(components) != 1 || components[0]["status"] != "Unknown" {
+		t.Errorf("components = %v, want the distributor with status Unknown", components)
+	}
+}
+
+func TestDeploymentStatus(t *testing.T) {
+	condition := func(conditionType appsv1.DeploymentConditionType, status corev1.ConditionStatus) appsv1.DeploymentCondition {
+		return appsv1.DeploymentCondition{Type: conditionType, Status: status}
+	}
+	tests := []struct {
+		name       string
+		conditions []appsv1.DeploymentCondition
+		want       string
+	}{
+		{"no conditions", nil, "Unknown"},
+		{"available", []appsv1.DeploymentCondition{
+			condition(appsv1.DeploymentProgressing, corev1.ConditionTrue),
+			condition(appsv1.DeploymentAvailable, corev1.ConditionTrue),
+		}, "Available"},
+		{"rolling out", []appsv1.DeploymentCondition{condition(appsv1.DeploymentProgressing, corev1.ConditionTrue)}, "Progressing"},
+		{"unavailable", []appsv1.DeploymentCondition{condition(appsv1.DeploymentAvailable, corev1.ConditionFalse)}, "Unavailable"},
+		{"replica failure only", []appsv1.DeploymentCondition{condition(appsv1.DeploymentReplicaFailure, corev1.ConditionTrue)}, "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{Status: appsv1.DeploymentStatus{Conditions: tt.conditions}}
+			if got := deploymentStatus(deployment); got != tt.want {
+				t.Errorf("deploymentStatus = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitBounds(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Limits.MinLimits = map[string]interface{}{"ingestion_rate": 10000}
+	cfg.Limits.MaxLimits = map[string]interface{}{"ingestion_rate": 500000}
+	s := newTestServer(cfg)
+
+	rec := serve(s, http.MethodGet, "/api/v1/limits/bounds", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Limits []struct {
+			Name    string      `json:"name"`
+			Type    string      `json:"type"`
+			Floor   interface{} `json:"floor"`
+			Ceiling interface{} `json:"ceiling"`
+			Default interface{} `json:"default"`
+		} `json:"limits"`
+		Count int `json:"count"`
+	}
+	decodeJSON(t, rec, &resp)
+
+	if resp.Count != len(resp.Limits) || resp.Count != len(cfg.EnabledLimitNames()) {
+		t.Errorf("count = %d with %d limits, want the %d enabled limits", resp.Count, len(resp.Limits), len(cfg.EnabledLimitNames()))
+	}
+	found := false
+	for _, limit := range resp.Limits {
+		if !cfg.DynamicLimits.LimitDefinitions[limit.Name].Enabled {
+			t.Errorf("disabled limit %s is listed", limit.Name)
+		}
+		if limit.Name != "ingestion_rate" {
+			continue
+		}
+		found = true
+		if limit.Floor != float64(10000) || limit.Ceiling != float64(500000) || limit.Default != float64(25000) {
+			t.Errorf("ingestion_rate bounds = floor %v, ceiling %v, default %v, want 10000, 500000, 25000",
+				limit.Floor, limit.Ceiling, limit.Default)
+		}
+	}
+	if !found {
+		t.Errorf("ingestion_rate is not listed")
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+
+	rec := serve(s, http.MethodPost, "/api/v1/limits/validate",
+		`{"limits": {"ingestion_rate": 25000, "max_query_lookback": "later", "ingestion_rates": 1}}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var resp struct {
+		Valid  bool                     `json:"valid"`
+		Errors []config.ValidationError `json:"errors"`
+	}
+	decodeJSON(t, rec, &resp)
+	if resp.Valid || len(resp.Errors) != 2 {
+		t.Fatalf("response = %+v, want the two invalid limits", resp)
+	}
+	if resp.Errors[0].LimitName != "ingestion_rates" || resp.Errors[1].LimitName != "max_query_lookback" ||
+		resp.Errors[1].ExpectedType != "duration" {
+		t.Errorf("errors = %+v", resp.Errors)
+	}
+
+	rec = serve(s, http.MethodPost, "/api/v1/limits/validate", `{"limits": {"ingestion_rate": 25000}}`, nil)
+	decodeJSON(t, rec, &resp)
+	if !resp.Valid || resp.Errors == nil || len(resp.Errors) != 0 {
+		t.Errorf("response for valid limits = %+v, want valid with an empty error list", resp)
+	}
+
+	for _, body := range []string{`{"limits": {}}`, `not json`} {
+		if rec := serve(s, http.MethodPost, "/api/v1/limits/validate", body, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("status for %q = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestArchitectureFlowDetectsLabeledComponents(t *testing.T) {
+	labeled := func(name, component string) *appsv1.Deployment {
+		deployment := testDeployment("mimir", name)
+		deployment.Labels = map[string]string{
+			"app.kubernetes.io/name":      "mimir",
+			"app.kubernetes.io/component": component,
+		}
+		return deployment
+	}
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	s := newTestServer(cfg)
+	s.SetK8sClient(kubefake.NewSimpleClientset(
+		labeled("write-path", "distributor"),
+		labeled("prod-blue-ingest", "ingester"),
+		labeled("reads", "querier"),
+		testDeployment("mimir", "grafana"),
+	))
+
+	flow := s.getArchitectureFlow(context.Background())
+	components, _ := flow["flow"].([]map[string]interface{})
+	got := make(map[string]string)
+	for _, component := range components {
+		got[component["name"].(string)] = component["component"].(string)
+	}
+	want := map[string]string{
+		"write-path":       "distributor",
+		"prod-blue-ingest": "ingester",
+		"reads":            "querier",
+	}
+	if len(got) != len(want) {
+		t.Errorf("flow components = %v, want %v", got, want)
+	}
+	for name, component := range want {
+		if got[name] != component {
+			t.Errorf("component of %s = %q, want %q", name, got[name], component)
+		}
+	}
+}
+
+func TestAlertRules(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+
+	rec := serve(s, http.MethodGet, "/api/v1/alerts/rules?format=json", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var rules alerting.PrometheusRuleFile
+	decodeJSON(t, rec, &rules)
+	if len(rules.Groups) == 0 {
+		t.Errorf("rule file has no groups")
+	}
+
+	rec = serve(s, http.MethodGet, "/api/v1/alerts/rules", "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/yaml" {
+		t.Fatalf("YAML rules: status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if err := yaml.UnmarshalStrict(rec.Body.Bytes(), &alerting.PrometheusRuleFile{}); err != nil {
+		t.Errorf("YAML rules do not parse: %v", err)
+	}
+
+	if rec := serve(s, http.MethodGet, "/api/v1/alerts/rules?format=xml", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("status with format xml = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSimulateSpike(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+
+	rec := serve(s, http.MethodPost, "/api/v1/tenants/tenant-a/simulate-spike", `{"multiplier": 2, "duration": "5m"}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	rec = serve(s, http.MethodGet, "/api/v1/tenants/tenant-a/active-spikes", "", nil)
+	var active struct {
+		Tenant string                      `json:"tenant"`
+		Spikes []controller.SyntheticSpike `json:"spikes"`
+	}
+	decodeJSON(t, rec, &active)
+	if len(active.Spikes) != 1 || active.Spikes[0].Multiplier != 2 {
+		t.Errorf("active spikes = %+v, want the 2x spike", active.Spikes)
+	}
+
+	for name, body := range map[string]string{
+		"invalid duration":   `{"multiplier": 2, "duration": "soon"}`,
+		"invalid multiplier": `{"multiplier": 0.5, "duration": "5m"}`,
+		"invalid JSON":       `{`,
+	} {
+		if rec := serve(s, http.MethodPost, "/api/v1/tenants/tenant-a/simulate-spike", body, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHealthTrendDataReturnsHistory(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	cfg.Performance.Cache.Enabled = false
+	s := newTestServer(cfg, testDeployment("mimir", "distributor"))
+
+	// handleHealthMetrics also probes Prometheus endpoints for ingestion capacity, so the
+	// scan and trend data it serves are exercised directly
+	for i := 1; i <= 3; i++ {
+		health, err := s.infrastructureScanner().ScanHealth(context.Background(), "mimir")
+		if err != nil {
+			t.Fatalf("ScanHealth: %v", err)
+		}
+		trend := s.generateHealthTrendData(health.Resources, s.infrastructureScanner().HealthHistory("mimir"))
+		scores, _ := trend["health_scores"].([]discovery.HealthSample)
+		if len(scores) != i {
+			t.Errorf("health_scores after %d scans = %d samples", i, len(scores))
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	s := newTestServer(cfg)
+	s.controller.CostController = costcontrol.NewCostController(s.live, logr.Discard())
+	tenantMetrics := map[string]*collector.TenantMetrics{
+		"tenant-a": {Tenant: "tenant-a"},
+		"tenant-b": {Tenant: "tenant-b"},
+	}
+	if _, err := s.controller.CostController.CalculateCosts(context.Background(), tenantMetrics); err != nil {
+		t.Fatalf("CalculateCosts: %v", err)
+	}
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantTenants []string
+	}{
+		{"/api/cost", http.StatusOK, []string{"tenant-a", "tenant-b"}},
+		{"/api/cost?tenant=tenant-b", http.StatusOK, []string{"tenant-b"}},
+		{"/api/cost?tenant=tenant-unknown", http.StatusNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(s, http.MethodGet, tt.path, "", nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var report costcontrol.SpendReport
+			decodeJSON(t, rec, &report)
+			var tenants []string
+			for _, summary := range report.Tenants {
+				tenants = append(tenants, summary.Tenant)
+			}
+			if strings.Join(tenants, ",") != strings.Join(tt.wantTenants, ",") {
+				t.Errorf("tenants = %v, want %v", tenants, tt.wantTenants)
+			}
+		})
+	}
+
+	s.live.Update(func(cfg *config.Config) { cfg.CostControl.Enabled = false })
+	if rec := serve(s, http.MethodGet, "/api/cost", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status with cost control disabled = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestTenantSeasonality(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.TrendAnalysis.Seasonality.Enabled = true
+	s := newTestServer(cfg)
+	s.controller.Analyzer = analyzer.NewAnalyzer(s.live, logr.Discard())
+
+	// 14 days of hourly ingestion from a Monday, tripled on Mondays from 09:00
+	const metricName = "cortex_distributor_received_samples_total"
+	start := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	var data []collector.MetricData
+	for at := start; !at.After(start.AddDate(0, 0, 14)); at = at.Add(time.Hour) {
+		value := 1000.0
+		if at.Weekday() == time.Monday && at.Hour() == 9 {
+			value = 3000
+		}
+		data = append(data, collector.MetricData{Tenant: "tenant-a", MetricName: metricName, Value: value, Timestamp: at})
+	}
+	tenantMetrics := map[string]*collector.TenantMetrics{
+		"tenant-a": {Tenant: "tenant-a", Metrics: map[string][]collector.MetricData{metricName: data}},
+	}
+	if _, err := s.controller.Analyzer.AnalyzeTrends(context.Background(), tenantMetrics); err != nil {
+		t.Fatalf("AnalyzeTrends: %v", err)
+	}
+
+	rec := serve(s, http.MethodGet, "/api/v1/tenants/tenant-a/seasonality", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var profile analyzer.SeasonalityProfile
+	decodeJSON(t, rec, &profile)
+	if len(profile.Multipliers) != analyzer.HoursPerWeek {
+		t.Fatalf("%d multipliers, want one per hour of the week", len(profile.Multipliers))
+	}
+	if !profile.Ready || profile.Multipliers[9] != 3 || profile.Multipliers[10] != 1 {
+		t.Errorf("profile ready %v, Monday 09:00 multiplier %v, 10:00 multiplier %v; want ready with the spike at 09:00",
+			profile.Ready, profile.Multipliers[9], profile.Multipliers[10])
+	}
+
+	if rec := serve(s, http.MethodGet, "/api/v1/tenants/tenant-b/seasonality", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status for a tenant never observed = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	s.live.Update(func(cfg *config.Config) { cfg.TrendAnalysis.Seasonality.Enabled = false })
+	if rec := serve(s, http.MethodGet, "/api/v1/tenants/tenant-a/seasonality", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("status with seasonality disabled = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestCircuitBreakerTripAndReset(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.RuntimeEnabled = true
+	cfg.Emergency.PanicMode.Actions = nil
+	s := newTestServer(cfg)
+	audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	s.controller.BlastProtector = circuitbreaker.NewBlastProtector(s.live, logr.Discard())
+	s.controller.BlastProtector.SetAuditLogger(audit)
+
+	protectionStatus := func() map[string]interface{} {
+		t.Helper()
+		rec := serve(s, http.MethodGet, "/api/protection/status", "", nil)
+		var status map[string]interface{}
+		decodeJSON(t, rec, &status)
+		return status
+	}
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+
+	rec := serve(s, http.MethodPost, "/api/circuit-breaker/trip", `{"reason":"bad ingester deploy"}`, jsonHeader)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("trip status = %d: %s", rec.Code, rec.Body)
+	}
+	var tripped struct {
+		Status           string                 `json:"status"`
+		Reason           string                 `json:"reason"`
+		ProtectionStatus map[string]interface{} `json:"protection_status"`
+	}
+	decodeJSON(t, rec, &tripped)
+	if tripped.Status != "circuit_breaker_tripped" || tripped.Reason != "bad ingester deploy" {
+		t.Errorf("trip response = %+v, want circuit_breaker_tripped with the reason", tripped)
+	}
+	if tripped.ProtectionStatus["circuit_breaker_state"] != "OPEN" {
+		t.Errorf("protection status in the trip response = %v, want OPEN", tripped.ProtectionStatus["circuit_breaker_state"])
+	}
+	if status := protectionStatus(); status["circuit_breaker_state"] != "OPEN" || status["forced_open"] != true {
+		t.Errorf("protection status after trip = %v, forced %v; want forced OPEN", status["circuit_breaker_state"], status["forced_open"])
+	}
+
+	// The reason may also be a query parameter
+	rec = serve(s, http.MethodPost, "/api/circuit-breaker/reset?reason=ingesters+rolled+back", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reset status = %d: %s", rec.Code, rec.Body)
+	}
+	status := protectionStatus()
+	if status["circuit_breaker_state"] != "HALF_OPEN" || status["forced_open"] != false {
+		t.Errorf("protection status after reset = %v, forced %v; want HALF_OPEN not forced", status["circuit_breaker_state"], status["forced_open"])
+	}
+	if status["failures"] != 0.0 || status["requests"] != 0.0 {
+		t.Errorf("counters after reset = %v failures of %v requests, want reset", status["failures"], status["requests"])
+	}
+
+	entries, err := audit.GetEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 2 || actions[0] != "circuit_breaker_forced_open" || actions[1] != "circuit_breaker_force_closed" {
+		t.Errorf("audited actions = %v, want the forced open and force close", actions)
+	}
+}
+
+func TestCircuitBreakerTripRequiresReason(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.CircuitBreaker.Enabled = true
+	s := newTestServer(cfg)
+	s.controller.BlastProtector = circuitbreaker.NewBlastProtector(s.live, logr.Discard())
+
+	for _, path := range []string{"/api/circuit-breaker/trip", "/api/circuit-breaker/reset"} {
+		for _, body := range []string{"", `{"reason":"  "}`} {
+			if rec := serve(s, http.MethodPost, path, body, nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("POST %s with body %q status = %d, want 400", path, body, rec.Code)
+			}
+		}
+	}
+	if state := s.controller.BlastProtector.GetProtectionStatus()["circuit_breaker_state"]; state != "CLOSED" {
+		t.Errorf("circuit breaker state after rejected requests = %v, want CLOSED", state)
+	}
+
+	// Without blast protection there is no circuit breaker to force
+	s.controller.BlastProtector = nil
+	if rec := serve(s, http.MethodPost, "/api/circuit-breaker/trip?reason=deploy", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("trip without blast protection status = %d, want 404", rec.Code)
+	}
+}
+
+func TestTenantDetailAppliedLimits(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	overrides := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Mimir.ConfigMapName, Namespace: cfg.Mimir.Namespace},
+		Data:       map[string]string{"overrides.yaml": "overrides:\n  tenant-a:\n    ingestion_rate: 25000\n"},
+	}
+	s := newTestServer(cfg, overrides)
+	s.controller.Patcher = patcher.NewConfigMapPatcher(s.controller.Client, nil, s.live, nil, logr.Discard())
+
+	var detail struct {
+		Managed    bool `json:"managed"`
+		TenantInfo struct {
+			AppliedLimits map[string]interface{} `json:"applied_limits"`
+		} `json:"tenant_info"`
+	}
+	rec := serve(s, http.MethodGet, "/api/tenants/tenant-a", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	decodeJSON(t, rec, &detail)
+	if !detail.Managed || detail.TenantInfo.AppliedLimits["ingestion_rate"] != 25000.0 {
+		t.Errorf("tenant-a detail managed %v with applied limits %v, want the managed 25000 ingestion_rate",
+			detail.Managed, detail.TenantInfo.AppliedLimits)
+	}
+
+	detail.Managed = true
+	decodeJSON(t, serve(s, http.MethodGet, "/api/tenants/tenant-z", "", nil), &detail)
+	if detail.Managed {
+		t.Errorf("tenant-z without managed limits reported as managed")
+	}
+}
+
+// countingTenantDiscovery discovers a fixed tenant list and counts the discoveries
+type countingTenantDiscovery struct {
+	mu          sync.Mutex
+	discoveries int
+}
+
+func (d *countingTenantDiscovery) CollectMetrics(ctx context.Context) (map[string]*collector.TenantMetrics, error) {
+	return map[string]*collector.TenantMetrics{}, nil
+}
+
+func (d *countingTenantDiscovery) GetTenantList(ctx context.Context) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.discoveries++
+	return []string{"tenant-a", "tenant-b"}, nil
+}
+
+func TestTenantsServedFromCachedTenantList(t *testing.T) {
+	s := newTestServer(config.GetDefaultConfig())
+	tenantDiscovery := &countingTenantDiscovery{}
+	s.controller.Collector = collector.NewCachedCollector(tenantDiscovery, cache.NewMemoryCache(time.Minute, 1), time.Minute, 0, logr.Discard())
+
+	for i := 0; i < 5; i++ {
+		rec := serve(s, http.MethodGet, "/api/tenants", "", nil)
+		var tenants struct {
+			TotalTenants int `json:"total_tenants"`
+		}
+		decodeJSON(t, rec, &tenants)
+		if rec.Code != http.StatusOK || tenants.TotalTenants != 2 {
+			t.Fatalf("request %d status %d with %d tenants, want 200 with 2", i+1, rec.Code, tenants.TotalTenants)
+		}
+	}
+	if tenantDiscovery.discoveries != 1 {
+		t.Errorf("5 requests discovered tenants %d times, want once", tenantDiscovery.discoveries)
+	}
+
+	serve(s, http.MethodGet, "/api/tenants?refresh=true", "", nil)
+	if tenantDiscovery.discoveries != 2 {
+		t.Errorf("request with ?refresh=true left %d discoveries, want the cache bypassed", tenantDiscovery.discoveries)
+	}
+}
+
+func TestTenantPauseRoundTrip(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	s := newTestServer(cfg)
+	s.controller.KubeClient = kubefake.NewSimpleClientset()
+	audit := auditlog.NewMemoryAuditLogger(100, logr.Discard())
+	s.controller.AuditLogger = audit
+
+	rec := serve(s, http.MethodPost, "/api/tenants/tenant-a/pause", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("pause = %d: %s", rec.Code, rec.Body.String())
+	}
+	var change controller.PauseChange
+	decodeJSON(t, rec, &change)
+	if !change.Paused || !change.Persisted || len(change.PausedTenants) != 1 || change.PausedTenants[0] != "tenant-a" {
+		t.Errorf("pause response = %+v, want tenant-a paused and persisted", change)
+	}
+	if !s.controller.IsTenantPaused("tenant-a") || s.controller.IsTenantPaused("tenant-b") {
+		t.Errorf("only tenant-a should be paused")
+	}
+
+	var scoping struct {
+		PausedTenants []string `json:"paused_tenants"`
+		Source        string   `json:"source"`
+	}
+	decodeJSON(t, serve(s, http.MethodGet, "/api/tenants/scoping", "", nil), &scoping)
+	if len(scoping.PausedTenants) != 1 || scoping.PausedTenants[0] != "tenant-a" || scoping.Source != "configmap" {
+		t.Errorf("tenant scoping = %+v, want tenant-a paused by the runtime ConfigMap", scoping)
+	}
+
+	rec = serve(s, http.MethodPost, "/api/tenants/tenant-a/unpause", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unpause = %d: %s", rec.Code, rec.Body.String())
+	}
+	decodeJSON(t, rec, &change)
+	if change.Paused || len(change.PausedTenants) != 0 {
+		t.Errorf("unpause response = %+v, want no paused tenants", change)
+	}
+	if s.controller.IsTenantPaused("tenant-a") {
+		t.Errorf("tenant-a is still paused after unpause")
+	}
+
+	if rec := serve(s, http.MethodPost, "/api/tenants/tenant-a/unpause", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unpause of a tenant not paused = %d, want 404", rec.Code)
+	}
+
+	entries, err := audit.GetEntries(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetEntries: %v", err)
+	}
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	sort.Strings(actions)
+	if strings.Join(actions, ",") != "tenant-pause,tenant-unpause" {
+		t.Errorf("audited actions = %v, want a pause and an unpause", actions)
+	}
+}
+
+func TestTenantPauseByPatternConflicts(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.TenantScoping.PausedTenants = []string{"team-*"}
+	s := newTestServer(cfg)
+
+	if rec := serve(s, http.MethodPost, "/api/tenants/team-a/unpause", "", nil); rec.Code != http.StatusConflict {
+		t.Errorf("unpause of a tenant paused by pattern = %d, want 409", rec.Code)
+	}
+}
+
+func TestConfigUpdateScopingPatterns(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.TenantScoping.UseRegex = true
+	s := newTestServer(cfg)
+	filter := s.controller.GetTenantFilter()
+
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"invalid regex", `{"skip_list":["^ok$","team-("]}`, "skip_list[1]"},
+		{"empty pattern", `{"include_list":[" "]}`, "include_list[0]"},
+	}
+	for _, tt := range tests {
+		rec := serve(s, http.MethodPost, "/api/config", tt.body, nil)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.field) {
+			t.Errorf("%s: POST /api/config = %d: %s; want 400 naming %s", tt.name, rec.Code, rec.Body.String(), tt.field)
+		}
+	}
+	if got := s.config().TenantScoping.SkipList; len(got) != 0 {
+		t.Errorf("skip list after rejected updates = %v, want it unchanged", got)
+	}
+
+	if rec := serve(s, http.MethodPost, "/api/config", `{"skip_list":["^internal-[0-9]+$"]}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/config = %d: %s", rec.Code, rec.Body.String())
+	}
+	if filter.ShouldProcessTenant("internal-7") || !filter.ShouldProcessTenant("team-a") {
+		t.Errorf("tenants processed after the skip regex update: internal-7 %v, team-a %v; want only team-a",
+			filter.ShouldProcessTenant("internal-7"), filter.ShouldProcessTenant("team-a"))
+	}
+
+	// Glob mode rejects malformed globs
+	glob := config.GetDefaultConfig()
+	s = newTestServer(glob)
+	if rec := serve(s, http.MethodPost, "/api/config", `{"skip_list":["team-["]}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /api/config with a malformed glob = %d, want 400", rec.Code)
+	}
+}
+
+func TestSilenceRoundTrip(t *testing.T) {
+	cfg := config.GetDefaultConfig()
+	cfg.Alerting.Enabled = true
+	s := newTestServer(cfg)
+	manager := alerting.NewManager(&cfg.Alerting, logr.Discard())
+	manager.SetSilenceStore(alerting.NewConfigMapSilenceStore(s.controller.Client,
+		cfg.Alerting.SilenceConfigMapName, cfg.Mimir.Namespace))
+	if err := manager.Start(); err != nil {
+		t.Fatalf("start alerting manager: %v", err)
+	}
+	t.Cleanup(manager.Stop)
+	s.controller.SetAlertManager(manager)
+
+	rec := serve(s, http.MethodPost, "/api/v1/silences", `{"tenant_id": "tenant-a", "duration": "2h"}`,
+		http.Header{"X-Forwarded-User": []string{"alice"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/v1/silences = %d: %s", rec.Code, rec.Body.String())
+	}
+	var silence alerting.SilenceRule
+	decodeJSON(t, rec, &silence)
+	if silence.ID == "" || silence.EndsAt.Sub(silence.StartsAt) != 2*time.Hour || !strings.Contains(silence.CreatedBy, "alice") {
+		t.Errorf("silence = %+v, want 2 hours created by alice", silence)
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: cfg.Alerting.SilenceConfigMapName, Namespace: cfg.Mimir.Namespace}
+	if err := s.controller.Client.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get silences ConfigMap: %v", err)
+	}
+	if !strings.Contains(configMap.Data["silences.json"], silence.ID) {
+		t.Errorf("silences ConfigMap = %v, want %s persisted", configMap.Data, silence.ID)
+	}
+
+	var listed struct {
+		Silences []alerting.SilenceRule `json:"silences"`
+		Count    int                    `json:"count"`
+	}
+	decodeJSON(t, serve(s, http.MethodGet, "/api/v1/silences", "", nil), &listed)
+	if listed.Count != 1 || listed.Silences[0].ID != silence.ID {
+		t.Errorf("silences = %+v, want %s", listed, silence.ID)
+	}
+
+	if rec := serve(s, http.MethodDelete, "/api/v1/silences/"+silence.ID, "", nil); rec.Code != http.StatusOK {
+		t.Errorf("DELETE = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(s, http.MethodDelete, "/api/v1/silences/"+silence.ID, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("second DELETE = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	for name, body := range map[string]string{
+		"without matchers": `{"duration": "2h"}`,
+		"invalid duration": `{"tenant_id": "tenant-a", "duration": "soon"}`,
+		"already ended":    `{"tenant_id": "tenant-a", "ends_at": "2020-01-01T00:00:00Z"}`,
+	} {
+		if rec := serve(s, http.MethodPost, "/api/v1/silences", body, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// importFixture is a runtime overrides document of 10 valid tenants, where tenant-01
+// also sets a disabled limit, and of tenant-bad misspelling a limit
+func importFixture() string {
+	var b strings.Builder
+	b.WriteString("overrides:\n")
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&b, "  tenant-%02d:\n    ingestion_rate: %d\n    ingestion_burst_size: %d\n", i, i*10000, i*20000)
+		if i == 1 {
+			b.WriteString("    max_global_metadata_per_user: 8000\n")
+		}
+	}
+	b.WriteString("  tenant-bad:\n    ingestion_rat: 5000\n")
+	return b.String()
+}
+
+// newImportTestServer creates a server whose runtime overrides already hold tenant-01
+func newImportTestServer(t *testing.T) *Server {
+	t.Helper()
+	cfg := config.GetDefaultConfig()
+	overrides := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.Mimir.ConfigMapName, Namespace: cfg.Mimir.Namespace},
+		Data: map[string]string{"overrides.yaml": "overrides:\n  tenant-01:\n    ingestion_rate: 1000\n" +
+			"    max_global_series_per_user: 1500000\n"},
+	}
+	s := newTestServer(cfg, overrides)
+	s.controller.Patcher = patcher.NewConfigMapPatcher(s.controller.Client, nil, s.live, nil, logr.Discard())
+	return s
+}
+
+// writtenOverrides returns the tenant overrides of the runtime overrides ConfigMap
+func writtenOverrides(t *testing.T, s *Server) map[string]map[string]interface{} {
+	t.Helper()
+	cfg := s.live.Load()
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: cfg.Mimir.ConfigMapName, Namespace: cfg.Mimir.Namespace}
+	if err := s.controller.Client.Get(context.Background(), key, configMap); err != nil {
+		t.Fatalf("get runtime overrides ConfigMap: %v", err)
+	}
+	var document struct {
+		Overrides map[string]map[string]interface{} `json:"overrides"`
+	}
+	if err := yaml.Unmarshal([]byte(configMap.Data["overrides.yaml"]), &document); err != nil {
+		t.Fatalf("parse runtime overrides: %v", err)
+	}
+	return document.Overrides
+}
+
+func TestImportLimits(t *testing.T) {
+	s := newImportTestServer(t)
+
+	rec := serve(s, http.MethodPost, "/api/v1/import", importFixture(), http.Header{"Content-Type": []string{"application/yaml"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/v1/import = %d: %s", rec.Code, rec.Body.String())
+	}
+	var result controller.LimitImportResult
+	decodeJSON(t, rec, &result)
+	if result.Imported != 10 || result.Skipped != 0 || result.Errors != 1 {
+		t.Errorf("import summary imported %d, skipped %d, errors %d; want 10, 0 and 1", result.Imported, result.Skipped, result.Errors)
+	}
+	if len(result.ErrorDetails) != 1 || result.ErrorDetails[0].Tenant != "tenant-bad" || result.ErrorDetails[0].Limit != "ingestion_rat" {
+		t.Errorf("error details = %+v, want the unknown ingestion_rat of tenant-bad", result.ErrorDetails)
+	}
+	if len(result.IgnoredLimits) != 1 || result.IgnoredLimits[0] != "max_global_metadata_per_user" {
+		t.Errorf("ignored limits = %v, want the disabled max_global_metadata_per_user", result.IgnoredLimits)
+	}
+
+	overrides := writtenOverrides(t, s)
+	if _, exists := overrides["tenant-bad"]; exists {
+		t.Errorf("tenant-bad with an invalid limit was written")
+	}
+	for i := 1; i <= 10; i++ {
+		tenant := fmt.Sprintf("tenant-%02d", i)
+		limits, exists := overrides[tenant]
+		if !exists {
+			t.Errorf("%s was not written", tenant)
+			continue
+		}
+		if limits["ingestion_rate"] != float64(i*10000) || limits["ingestion_burst_size"] != float64(i*20000) {
+			t.Errorf("%s limits = %v, want ingestion_rate %d and ingestion_burst_size %d", tenant, limits, i*10000, i*20000)
+		}
+	}
+	// Imported values are merged over the limits already set
+	if limits := overrides["tenant-01"]; limits["max_global_series_per_user"] != 1500000.0 {
+		t.Errorf("tenant-01 limits = %v, want its max_global_series_per_user kept", limits)
+	}
+	if _, exists := overrides["tenant-01"]["max_global_metadata_per_user"]; exists {
+		t.Errorf("disabled max_global_metadata_per_user was written")
+	}
+}
+
+func TestImportLimitsWithoutOverwrite(t *testing.T) {
+	s := newImportTestServer(t)
+
+	rec := serve(s, http.MethodPost, "/api/v1/import?overwrite=false", importFixture(), nil)
+	var result controller.LimitImportResult
+	decodeJSON(t, rec, &result)
+	if result.Imported != 9 || result.SkippedTenants["tenant-01"] != controller.ImportReasonExists {
+		t.Errorf("import summary = %+v, want 9 tenants imported and tenant-01 skipped as existing", result)
+	}
+	if limits := writtenOverrides(t, s)["tenant-01"]; limits["ingestion_rate"] != 1000.0 {
+		t.Errorf("tenant-01 limits = %v, want its ingestion_rate of 1000 kept", limits)
+	}
+
+	if rec := serve(s, http.MethodPost, "/api/v1/import?overwrite=maybe", importFixture(), nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("overwrite=maybe: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestImportLimitsMultipartUpload(t *testing.T) {
+	s := newImportTestServer(t)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", "overrides.yaml")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	file.Write([]byte(importFixture()))
+	form.Close()
+
+	rec := serve(s, http.MethodPost, "/api/v1/import", body.String(), http.Header{"Content-Type": []string{form.FormDataContentType()}})
+	var result controller.LimitImportResult
+	decodeJSON(t, rec, &result)
+	if rec.Code != http.StatusOK || result.Imported != 10 {
+		t.Errorf("multipart import = %d with %d tenants imported, want 200 with 10", rec.Code, result.Imported)
+	}
+
+	for name, body := range map[string]string{
+		"no tenants":  "overrides: {}\n",
+		"not YAML":    "overrides: [",
+		"no document": "",
+	} {
+		if rec := serve(s, http.MethodPost, "/api/v1/import", body, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
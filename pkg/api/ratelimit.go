@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/time/rate"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// rateLimiterSweepInterval is how often the buckets of clients that stopped sending
+// requests are dropped
+const rateLimiterSweepInterval = time.Minute
+
+// clientRateLimiter keeps a token bucket per client IP, and per client IP and endpoint
+// for the endpoints with an override of ui.rateLimit
+type clientRateLimiter struct {
+	live *config.Live
+	// now is the clock the buckets are refilled by
+	now func() time.Time
+
+	mu        sync.Mutex
+	limiters  map[string]*rate.Limiter
+	lastSweep time.Time
+}
+
+// newClientRateLimiter creates a rate limiter for the API clients
+func newClientRateLimiter(live *config.Live) *clientRateLimiter {
+	return &clientRateLimiter{
+		live:     live,
+		now:      time.Now,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// config returns the configuration in effect
+func (l *clientRateLimiter) config() *config.Config {
+	return l.live.Load()
+}
+
+// reserve takes a token from the bucket of a client's requests to an endpoint. It
+// returns 0 when the request may proceed, and otherwise how long the client has to wait
+// for the next token.
+func (l *clientRateLimiter) reserve(client, endpoint string) time.Duration {
+	settings := l.config().UI.RateLimit
+	key := client
+	requestsPerSecond, burst := settings.RequestsPerSecond, settings.BurstCapacity
+	if override, exists := settings.Endpoints[endpoint]; exists {
+		key = client + " " + endpoint
+		requestsPerSecond, burst = override.RequestsPerSecond, override.BurstCapacity
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	limiter, exists := l.limiters[key]
+	if !exists {
+		limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+		l.limiters[key] = limiter
+	}
+	// Follow configuration reloads
+	if limiter.Limit() != rate.Limit(requestsPerSecond) {
+		limiter.SetLimitAt(now, rate.Limit(requestsPerSecond))
+	}
+	if limiter.Burst() != burst {
+		limiter.SetBurstAt(now, burst)
+	}
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return time.Second
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// Rejected requests do not consume a token
+		reservation.CancelAt(now)
+	}
+	return delay
+}
+
+// sweep drops the buckets that refilled completely, which behave like new ones. The
+// caller must hold l.mu.
+func (l *clientRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, limiter := range l.limiters {
+		if limiter.TokensAt(now) >= float64(limiter.Burst()) {
+			delete(l.limiters, key)
+		}
+	}
+}
+
+// rateLimitMiddleware rejects API requests beyond the client's ui.rateLimit with 429 Too
+// Many Requests and a Retry-After header of the seconds until the next token
+func (s *Server) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config().UI.RateLimit.Enabled || s.rateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		endpoint := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				endpoint = template
+			}
+		}
+
+		client := clientIP(r)
+		delay := s.rateLimiter.reserve(client, endpoint)
+		if delay <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		retryAfter := int(math.Ceil(delay.Seconds()))
+		s.log.V(1).Info("rate limited API request",
+			"client", client,
+			"endpoint", endpoint,
+			"retry_after_seconds", retryAfter)
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		s.writeError(w, http.StatusTooManyRequests, fmt.Sprintf("Rate limit exceeded, retry after %ds", retryAfter))
+	})
+}
+
+// clientIP returns the IP address a request was sent from
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
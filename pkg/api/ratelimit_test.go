@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// newRateLimitedServer creates a server allowing each client a burst of 5 requests and
+// one more every 4 seconds, rate limited on a clock that only moves when the test
+// advances it
+func newRateLimitedServer(endpoints map[string]config.APIEndpointRateLimit) (*Server, *time.Time) {
+	cfg := config.GetDefaultConfig()
+	cfg.UI.RateLimit = config.APIRateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: 0.25,
+		BurstCapacity:     5,
+		Endpoints:         endpoints,
+	}
+	s := newTestServer(cfg)
+	now := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+	s.rateLimiter.now = func() time.Time { return now }
+	return s, &now
+}
+
+// serveFrom sends a GET request from a client IP and returns the response status and
+// Retry-After header
+func serveFrom(s *Server, client, path string) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = client + ":43210"
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec.Code, rec.Header().Get("Retry-After")
+}
+
+func TestAPIRateLimitRejectsRequestsBeyondBurst(t *testing.T) {
+	s, _ := newRateLimitedServer(nil)
+
+	for i := 0; i < 20; i++ {
+		status, retryAfter := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds")
+		if i < 5 {
+			if status != http.StatusOK {
+				t.Errorf("request %d status = %d, want 200 within the burst", i+1, status)
+			}
+			continue
+		}
+		if status != http.StatusTooManyRequests {
+			t.Errorf("request %d status = %d, want 429 beyond the burst", i+1, status)
+		}
+		// Rejected requests take no token, so the next one is always 4 seconds away
+		if retryAfter != "4" {
+			t.Errorf("request %d Retry-After = %q, want 4", i+1, retryAfter)
+		}
+	}
+
+	// Another client has a bucket of its own
+	if status, _ := serveFrom(s, "192.0.2.2", "/api/v1/limits/bounds"); status != http.StatusOK {
+		t.Errorf("other client's request status = %d, want 200", status)
+	}
+}
+
+func TestAPIRateLimitRetryAfter(t *testing.T) {
+	s, now := newRateLimitedServer(nil)
+	for i := 0; i < 5; i++ {
+		serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds")
+	}
+
+	*now = now.Add(2500 * time.Millisecond)
+	if status, retryAfter := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds"); status != http.StatusTooManyRequests || retryAfter != "2" {
+		t.Errorf("request 1.5s before the next token = %d with Retry-After %q, want 429 and 2", status, retryAfter)
+	}
+
+	// Waiting as long as Retry-After said gets the request through
+	*now = now.Add(2 * time.Second)
+	if status, _ := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds"); status != http.StatusOK {
+		t.Errorf("request after Retry-After status = %d, want 200", status)
+	}
+	if status, retryAfter := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds"); status != http.StatusTooManyRequests || retryAfter != "4" {
+		t.Errorf("request after the refilled token = %d with Retry-After %q, want 429 and 4", status, retryAfter)
+	}
+}
+
+func TestAPIRateLimitEndpointOverride(t *testing.T) {
+	s, _ := newRateLimitedServer(map[string]config.APIEndpointRateLimit{
+		"/api/tenants/{tenant_id}": {RequestsPerSecond: 0.1, BurstCapacity: 1},
+	})
+
+	if status, _ := serveFrom(s, "192.0.2.1", "/api/tenants/tenant-a"); status == http.StatusTooManyRequests {
+		t.Fatalf("first request to the overridden endpoint was rate limited")
+	}
+	// Every tenant's detail is the same endpoint
+	status, retryAfter := serveFrom(s, "192.0.2.1", "/api/tenants/tenant-b")
+	if status != http.StatusTooManyRequests || retryAfter != "10" {
+		t.Errorf("second request to the overridden endpoint = %d with Retry-After %q, want 429 and 10", status, retryAfter)
+	}
+
+	// The override's bucket leaves the shared one alone
+	for i := 0; i < 5; i++ {
+		if status, _ := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds"); status != http.StatusOK {
+			t.Errorf("request %d to an endpoint without an override status = %d, want 200", i+1, status)
+		}
+	}
+}
+
+func TestAPIRateLimitDisabled(t *testing.T) {
+	s, _ := newRateLimitedServer(nil)
+	cfg := *s.config()
+	cfg.UI.RateLimit.Enabled = false
+	s.live.Store(&cfg)
+
+	for i := 0; i < 20; i++ {
+		if status, _ := serveFrom(s, "192.0.2.1", "/api/v1/limits/bounds"); status != http.StatusOK {
+			t.Fatalf("request %d status = %d with rate limiting disabled, want 200", i+1, status)
+		}
+	}
+}
@@ -8,48 +8,122 @@ import (
 	"io"
 	"io/fs"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
 	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/controller"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
 )
 
 // Server represents the API server for the web UI
 type Server struct {
 	controller *controller.MimirLimitController
-	config     *config.Config
+	live       *config.Live
 	log        logr.Logger
 	router     *mux.Router
 	httpServer *http.Server
 	uiAssets   embed.FS
 	k8sClient  kubernetes.Interface
+	components *discovery.ComponentDetector
+
+	// scanner caches infrastructure scans shared by the health and infrastructure handlers
+	scannerOnce sync.Once
+	scanner     *discovery.CachedScanner
+
+	// rateLimiter holds the token buckets of the API clients
+	rateLimiter *clientRateLimiter
+
+	// authenticator authenticates the API requests when ui.auth is enabled
+	authenticator *authenticator
+
+	// catalog lists the registered routes, built once the routes are set up
+	catalog []CatalogEntry
+
+	// stopCertWatch stops reloading the TLS certificate, nil without ui.tls
+	stopCertWatch context.CancelFunc
 }
 
 // NewServer creates a new API server instance
-func NewServer(controller *controller.MimirLimitController, cfg *config.Config, log logr.Logger, uiAssets embed.FS) *Server {
+func NewServer(controller *controller.MimirLimitController, live *config.Live, log logr.Logger, uiAssets embed.FS) *Server {
 	s := &Server{
-		controller: controller,
-		config:     cfg,
-		log:        log,
-		router:     mux.NewRouter(),
-		uiAssets:   uiAssets,
-		k8sClient:  nil, // Will be set if running in Kubernetes mode
+		controller:  controller,
+		live:        live,
+		log:         log,
+		router:      mux.NewRouter(),
+		uiAssets:    uiAssets,
+		k8sClient:   nil, // Will be set if running in Kubernetes mode
+		components:  discovery.NewComponentDetectorOrDefault(live.Load().MetricsDiscovery, log),
+		rateLimiter: newClientRateLimiter(live),
 	}
+	s.authenticator = newAuthenticator(live, s.kubeClient)
 
 	s.setupRoutes()
 	return s
 }
 
+// config returns the configuration in effect
+func (s *Server) config() *config.Config {
+	return s.live.Load()
+}
+
 // SetK8sClient sets the Kubernetes client for namespace scanning
 func (s *Server) SetK8sClient(client kubernetes.Interface) {
 	s.k8sClient = client
 }
 
+// kubeClient returns the Kubernetes client set by SetK8sClient, or the controller's, nil
+// outside Kubernetes
+func (s *Server) kubeClient() kubernetes.Interface {
+	if s.k8sClient != nil {
+		return s.k8sClient
+	}
+	if s.controller != nil {
+		return s.controller.KubeClient
+	}
+	return nil
+}
+
+// infrastructureScanner returns the scanner shared by all infrastructure and health
+// handlers, created on first use
+func (s *Server) infrastructureScanner() *discovery.CachedScanner {
+	s.scannerOnce.Do(func() {
+		var healthScanner *discovery.HealthScanner
+		var autonomousScanner *discovery.AutonomousScanner
+		if s.controller != nil && s.controller.Client != nil {
+			healthScanner = discovery.NewHealthScanner(s.controller.Client, s.live, s.log)
+			if s.controller.APIReader != nil {
+				healthScanner.WithAPIReader(s.controller.APIReader)
+			}
+			if s.controller.HealthCache != nil {
+				healthScanner.WithWatchCache(s.controller.HealthCache)
+			}
+		}
+		if s.controller != nil && s.controller.KubeClient != nil {
+			autonomousScanner = discovery.NewAutonomousScanner(s.controller.KubeClient, s.live, s.log)
+		}
+
+		var ttl time.Duration
+		if s.config().Performance.Cache.Enabled {
+			ttl = s.config().Performance.Cache.TTL
+		}
+		history := discovery.NewHealthHistory(s.config().HealthScanner.HistoryRetention)
+		s.scanner = discovery.NewCachedScanner(healthScanner, autonomousScanner, ttl, history, s.log.WithName("scan-cache"))
+
+		// A watched change makes the cached health scan of the namespace outdated; the
+		// next request rescans it from the watch cache
+		if healthScanner != nil && s.controller.HealthCache != nil {
+			namespace := s.config().Mimir.Namespace
+			s.controller.HealthCache.OnChange(func() { s.scanner.InvalidateHealth(namespace) })
+		}
+	})
+	return s.scanner
+}
+
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
 	// Add middleware
@@ -58,18 +132,32 @@ func (s *Server) setupRoutes() {
 
 	// API routes
 	api := s.router.PathPrefix("/api").Subrouter()
+	api.Use(s.rateLimitMiddleware)
+	api.Use(s.authMiddleware)
 
 	// System endpoints
+	api.HandleFunc("/v1", s.handleAPICatalog).Methods("GET")
 	api.HandleFunc("/status", s.handleStatus).Methods("GET")
 	api.HandleFunc("/config", s.handleConfig).Methods("GET", "POST")
+	api.HandleFunc("/config/effective", s.handleEffectiveConfig).Methods("GET")
 	api.HandleFunc("/metrics", s.handleMetrics).Methods("GET")
+	api.HandleFunc("/v1/emergency/freeze", s.handleEmergencyFreeze).Methods("POST")
+	api.HandleFunc("/v1/emergency/freeze", s.handleEmergencyUnfreeze).Methods("DELETE")
 
 	// Dashboard endpoints - NEW
 	api.HandleFunc("/dashboard", s.handleDashboardData).Methods("GET")
 
 	// Tenant endpoints
 	api.HandleFunc("/tenants", s.handleTenants).Methods("GET")
+	api.HandleFunc("/tenants/scoping", s.handleTenantScoping).Methods("GET", "POST", "DELETE")
+	api.HandleFunc("/tiers", s.handleTiers).Methods("GET")
 	api.HandleFunc("/tenants/{tenant_id}", s.handleTenantDetail).Methods("GET")
+	api.HandleFunc("/tenants/{tenant_id}/recommendations/history", s.handleRecommendationHistory).Methods("GET")
+	api.HandleFunc("/tenants/{tenant_id}/pause", s.handleTenantPause).Methods("POST")
+	api.HandleFunc("/tenants/{tenant_id}/unpause", s.handleTenantUnpause).Methods("POST")
+	api.HandleFunc("/v1/tenants/{tenant_id}/simulate-spike", s.handleSimulateSpike).Methods("POST")
+	api.HandleFunc("/v1/tenants/{tenant_id}/active-spikes", s.handleActiveSpikes).Methods("GET")
+	api.HandleFunc("/v1/tenants/{tenant_id}/seasonality", s.handleTenantSeasonality).Methods("GET")
 
 	// Namespace scanning endpoints - NEW
 	api.HandleFunc("/namespaces", s.handleNamespacesScan).Methods("GET")
@@ -77,15 +165,41 @@ func (s *Server) setupRoutes() {
 
 	// Analysis endpoints
 	api.HandleFunc("/diff", s.handleDiff).Methods("GET")
+	api.HandleFunc("/preview", s.handlePreview).Methods("GET")
 	api.HandleFunc("/audit", s.handleAudit).Methods("GET")
+	api.HandleFunc("/v1/drift", s.handleDrift).Methods("GET")
+	api.HandleFunc("/cost", s.handleCost).Methods("GET")
+	api.HandleFunc("/v1/cost/report", s.handleCostReport).Methods("GET")
+	api.HandleFunc("/v1/limits/bounds", s.handleLimitBounds).Methods("GET")
+	api.HandleFunc("/v1/limits/validate", s.handleValidateLimits).Methods("POST")
+	api.HandleFunc("/v1/import", s.handleImportLimits).Methods("POST")
+	api.HandleFunc("/protection/status", s.handleProtectionStatus).Methods("GET")
+	api.HandleFunc("/protection/thresholds", s.handleProtectionThresholds).Methods("GET")
+	api.HandleFunc("/protection/baselines", s.handleProtectionBaselines).Methods("GET")
+	api.HandleFunc("/protection/ratelimiters/{tenant_id}/reset", s.handleResetRateLimiter).Methods("POST")
+	api.HandleFunc("/circuit-breaker/trip", s.handleCircuitBreakerTrip).Methods("POST")
+	api.HandleFunc("/circuit-breaker/reset", s.handleCircuitBreakerReset).Methods("POST")
+	api.HandleFunc("/reports/recommendations", s.handleRecommendationReport).Methods("GET")
+	api.HandleFunc("/reconcile/last", s.handleReconcileResult).Methods("GET")
+	api.HandleFunc("/reconcile/{id}", s.handleReconcileResult).Methods("GET")
 
 	// Test endpoints
 	api.HandleFunc("/test/spike", s.handleTestSpike).Methods("POST")
 	api.HandleFunc("/test/alert", s.handleTestAlert).Methods("POST")
 	api.HandleFunc("/test/reconcile", s.handleTestReconcile).Methods("POST")
 
+	// Alert instance endpoints
+	api.HandleFunc("/alerts", s.handleAlerts).Methods("GET")
+	api.HandleFunc("/alerts/{id}/ack", s.handleAlertAck).Methods("POST")
+	api.HandleFunc("/v1/alerts/route", s.handleAlertRoutePreview).Methods("POST")
+	api.HandleFunc("/v1/alerts/rules", s.handleAlertRules).Methods("GET")
+	api.HandleFunc("/v1/silences", s.handleSilences).Methods("GET")
+	api.HandleFunc("/v1/silences", s.handleCreateSilence).Methods("POST")
+	api.HandleFunc("/v1/silences/{id}", s.handleDeleteSilence).Methods("DELETE")
+
 	// Health monitoring endpoints - NEW
 	api.HandleFunc("/health/infrastructure", s.handleInfrastructureHealth).Methods("GET")
+	api.HandleFunc("/health/infrastructure/stream", s.handleInfrastructureHealthStream).Methods("GET")
 	api.HandleFunc("/health/metrics", s.handleHealthMetrics).Methods("GET")
 	api.HandleFunc("/health/alerts", s.handleHealthAlerts).Methods("GET")
 	api.HandleFunc("/health/recommendations", s.handleHealthRecommendations).Methods("GET")
@@ -97,17 +211,20 @@ func (s *Server) setupRoutes() {
 	api.HandleFunc("/infrastructure/components", s.handleInfrastructureComponents).Methods("GET")
 	api.HandleFunc("/infrastructure/tenants", s.handleInfrastructureTenants).Methods("GET")
 	api.HandleFunc("/infrastructure/analytics", s.handleInfrastructureAnalytics).Methods("GET")
+	api.HandleFunc("/v1/infrastructure/topology", s.handleInfrastructureTopology).Methods("GET")
 
 	// Health check
 	s.router.HandleFunc("/health", s.handleHealthCheck).Methods("GET")
 
-	// Prometheus metrics endpoint
-	s.router.Handle("/metrics", promhttp.Handler())
+	// Prometheus metrics endpoint, compressed as it grows with tenants and limits
+	s.router.Handle("/metrics", s.metricsHandler()).Methods("GET")
 
 	// Setup UI static file serving - embed.FS is always valid, so check if we can access the UI directory
 	if _, err := s.uiAssets.Open("ui"); err == nil {
 		s.setupUIRoutes()
 	}
+
+	s.catalog = s.buildAPICatalog()
 }
 
 // setupUIRoutes configures UI static file serving
@@ -198,12 +315,36 @@ func (s *Server) Start(port int) error {
 		IdleTimeout:  120 * time.Second,
 	}
 
-	s.log.Info("starting API server", "port", port)
-	return s.httpServer.ListenAndServe()
+	s.logAuthSettings()
+
+	if !s.config().UI.TLS.Enabled() {
+		s.log.Info("WARNING: TLS is not configured, the API server serves plain HTTP; set ui.tls.certFile and ui.tls.keyFile to serve HTTPS")
+		s.log.Info("starting API server", "port", port)
+		return s.httpServer.ListenAndServe()
+	}
+
+	reloader, err := newCertReloader(s.config().UI.TLS, s.log.WithName("tls"))
+	if err != nil {
+		return err
+	}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	s.stopCertWatch = stopWatch
+	if err := reloader.watch(watchCtx); err != nil {
+		s.log.Error(err, "TLS certificate rotation is not watched, restart to load a new certificate")
+	}
+	s.httpServer.TLSConfig = reloader.tlsConfig()
+
+	s.log.Info("starting API server with TLS", "port", port,
+		"cert_file", s.config().UI.TLS.CertFile,
+		"client_auth", s.config().UI.TLS.ClientCAFile != "")
+	return s.httpServer.ListenAndServeTLS("", "")
 }
 
 // Stop gracefully stops the HTTP server
 func (s *Server) Stop(ctx context.Context) error {
+	if s.stopCertWatch != nil {
+		s.stopCertWatch()
+	}
 	if s.httpServer != nil {
 		return s.httpServer.Shutdown(ctx)
 	}
@@ -255,6 +396,20 @@ func (s *Server) writeError(w http.ResponseWriter, code int, message string) {
 	}
 }
 
+// writeFieldErrors rejects a request with 400, listing each invalid field
+func (s *Server) writeFieldErrors(w http.ResponseWriter, message string, errs config.FieldErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":     true,
+		"message":   message,
+		"errors":    errs,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}); err != nil {
+		s.log.Error(err, "failed to encode error response")
+	}
+}
+
 func (s *Server) writeJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/controller"
+)
+
+// testScheme returns the client-go scheme with the metrics-server pod metrics known as
+// unstructured objects. The fake client otherwise adds them to its scheme on every
+// list, racing the infrastructure scan's concurrent lists.
+func testScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	podMetrics := schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}
+	scheme.AddKnownTypeWithName(podMetrics.WithKind("PodMetrics"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(podMetrics.WithKind("PodMetricsList"), &unstructured.UnstructuredList{})
+	return scheme
+}
+
+// newTestServer creates a server of cfg whose controller reads from a fake client
+// holding objs
+func newTestServer(cfg *config.Config, objs ...client.Object) *Server {
+	live := config.NewLive(cfg)
+	c := &controller.MimirLimitController{
+		Client: fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objs...).Build(),
+		Config: live,
+		Log:    logr.Discard(),
+	}
+	return NewServer(c, live, logr.Discard(), embed.FS{})
+}
+
+// serve sends a request through the server's routes and middleware
+func serve(s *Server, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeJSON decodes the JSON body of a response into v
+func decodeJSON(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// certReloadDebounce coalesces the events of a single Secret rotation, which swaps the
+// ..data symlink and with it every file of the Secret
+const certReloadDebounce = time.Second
+
+// tlsCipherSuites are the TLS 1.2 cipher suites the listener accepts: forward secret
+// AEAD suites only. TLS 1.3 suites are not configurable and all of them are secure.
+var tlsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// certReloader serves the certificate and client CAs of ui.tls, reading them again when
+// their files change. New handshakes use the reloaded certificate; established
+// connections keep the one they were negotiated with.
+type certReloader struct {
+	settings config.APITLSConfig
+	log      logr.Logger
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// newCertReloader loads the certificate of ui.tls
+func newCertReloader(settings config.APITLSConfig, log logr.Logger) (*certReloader, error) {
+	r := &certReloader{settings: settings, log: log}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload reads the certificate, key and client CAs. On failure the loaded ones are kept.
+func (r *certReloader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(r.settings.CertFile, r.settings.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS certificate: %w", err)
+	}
+	certificate.Leaf = leaf
+
+	var clientCAs *x509.CertPool
+	if r.settings.ClientCAFile != "" {
+		data, err := os.ReadFile(r.settings.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no PEM certificates in TLS client CA file %s", r.settings.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.certificate, r.clientCAs = &certificate, clientCAs
+	r.mu.Unlock()
+
+	r.log.Info("loaded TLS certificate",
+		"subject", leaf.Subject.String(),
+		"dns_names", leaf.DNSNames,
+		"not_after", leaf.NotAfter,
+		"client_ca", r.settings.ClientCAFile != "")
+	return nil
+}
+
+// getCertificate returns the current certificate for a handshake
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.certificate, nil
+}
+
+// tlsConfig returns the TLS configuration of the listener: TLS 1.2 or newer with the
+// current certificate and, with a client CA file, required client certificates
+func (r *certReloader) tlsConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		CipherSuites:   tlsCipherSuites,
+		GetCertificate: r.getCertificate,
+	}
+	if r.settings.ClientCAFile == "" {
+		return base
+	}
+
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		// The client CAs are read per handshake so a rotated CA bundle applies to new
+		// connections
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		handshake := base.Clone()
+		handshake.GetConfigForClient = nil
+		handshake.ClientCAs = r.clientCAs
+		return handshake, nil
+	}
+	return base
+}
+
+// watch reloads the certificate when its files change until ctx is cancelled. The
+// directories holding the files are watched, as a mounted Secret is updated by swapping
+// a symlink rather than writing to the files.
+func (r *certReloader) watch(ctx context.Context) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create TLS certificate watcher: %w", err)
+	}
+
+	names := make(map[string]bool)
+	watched := make(map[string]bool)
+	for _, path := range []string{r.settings.CertFile, r.settings.KeyFile, r.settings.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		names[filepath.Base(path)] = true
+		dir := filepath.Dir(path)
+		if watched[dir] {
+			continue
+		}
+		if err := fsWatcher.Add(dir); err != nil {
+			_ = fsWatcher.Close()
+			return fmt.Errorf("failed to watch TLS certificate directory %s: %w", dir, err)
+		}
+		watched[dir] = true
+	}
+
+	go func() {
+		defer func() {
+			if err := fsWatcher.Close(); err != nil {
+				r.log.Error(err, "failed to close TLS certificate watcher")
+			}
+		}()
+
+		debounce := time.NewTimer(certReloadDebounce)
+		debounce.Stop()
+		defer debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				name := filepath.Base(event.Name)
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 &&
+					(names[name] || name == "..data") {
+					debounce.Reset(certReloadDebounce)
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				r.log.Error(err, "TLS certificate watcher error")
+			case <-debounce.C:
+				if err := r.reload(); err != nil {
+					r.log.Error(err, "failed to reload TLS certificate, keeping the current one")
+				}
+			}
+		}
+	}()
+
+	return nil
+}
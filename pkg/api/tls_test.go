@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+)
+
+// testCertificate is a generated certificate and its key
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCertificate creates a certificate for 127.0.0.1 signed by parent, or
+// self-signed when parent is nil
+func newTestCertificate(t *testing.T, serial int64, parent *testCertificate, usage x509.ExtKeyUsage) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "mimir-limit-optimizer"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{usage},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return &testCertificate{cert: cert, key: key}
+}
+
+func (c *testCertificate) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCertificate) keyPEM(t *testing.T) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	certificate, err := tls.X509KeyPair(c.certPEM(), c.keyPEM(t))
+	if err != nil {
+		t.Fatalf("key pair: %v", err)
+	}
+	return certificate
+}
+
+// writeFile replaces a file as the kubelet does, so a reader never sees it half written
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatalf("rename %s: %v", path, err)
+	}
+}
+
+// writeServingCertificate writes the certificate and key files of settings
+func writeServingCertificate(t *testing.T, settings config.APITLSConfig, c *testCertificate) {
+	t.Helper()
+	writeFile(t, settings.KeyFile, c.keyPEM(t))
+	writeFile(t, settings.CertFile, c.certPEM())
+}
+
+// serveTLS serves 200 OK with the TLS configuration of reloader on a local port and
+// returns its URL
+func serveTLS(t *testing.T, reloader *certReloader) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "ok")
+		}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() { _ = server.Serve(tls.NewListener(listener, reloader.tlsConfig())) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+// tlsClient trusts the roots and keeps connections alive between requests
+func tlsClient(config *tls.Config) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}, Timeout: 5 * time.Second}
+}
+
+// get sends a GET and returns the serial of the served certificate and whether the
+// request reused an established connection
+func get(t *testing.T, client *http.Client, url string) (int64, bool) {
+	t.Helper()
+	var reused bool
+	trace := &httptrace.ClientTrace{GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused }}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s status = %d", url, resp.StatusCode)
+	}
+	return resp.TLS.PeerCertificates[0].SerialNumber.Int64(), reused
+}
+
+func testTLSSettings(t *testing.T) config.APITLSConfig {
+	dir := t.TempDir()
+	return config.APITLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key")}
+}
+
+func TestCertificateRotationKeepsEstablishedConnections(t *testing.T) {
+	settings := testTLSSettings(t)
+	first := newTestCertificate(t, 1, nil, x509.ExtKeyUsageServerAuth)
+	second := newTestCertificate(t, 2, nil, x509.ExtKeyUsageServerAuth)
+	writeServingCertificate(t, settings, first)
+
+	reloader, err := newCertReloader(settings, logr.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := reloader.watch(ctx); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	url := serveTLS(t, reloader)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first.cert)
+	roots.AddCert(second.cert)
+	established := tlsClient(&tls.Config{RootCAs: roots})
+	if serial, _ := get(t, established, url); serial != 1 {
+		t.Fatalf("served certificate %d, want the first", serial)
+	}
+
+	// The mounted Secret rotates; new handshakes get the second certificate once the
+	// watcher reloaded it
+	writeServingCertificate(t, settings, second)
+	deadline := time.Now().Add(5 * certReloadDebounce)
+	for {
+		fresh := tlsClient(&tls.Config{RootCAs: roots})
+		fresh.Transport.(*http.Transport).DisableKeepAlives = true
+		if serial, _ := get(t, fresh, url); serial == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("new connections still get the first certificate after the rotation")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// The connection established before the rotation keeps serving requests
+	serial, reused := get(t, established, url)
+	if !reused || serial != 1 {
+		t.Errorf("request after the rotation reused the connection %v with certificate %d, want the established connection of the first", reused, serial)
+	}
+}
+
+func TestCertificateReloadFailureKeepsCertificate(t *testing.T) {
+	settings := testTLSSettings(t)
+	writeServingCertificate(t, settings, newTestCertificate(t, 1, nil, x509.ExtKeyUsageServerAuth))
+	reloader, err := newCertReloader(settings, logr.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+
+	// A rotation caught half way pairs the new certificate with the old key
+	writeFile(t, settings.CertFile, newTestCertificate(t, 2, nil, x509.ExtKeyUsageServerAuth).certPEM())
+	if err := reloader.reload(); err == nil {
+		t.Fatalf("reload of a mismatched certificate and key succeeded")
+	}
+	certificate, _ := reloader.getCertificate(nil)
+	if serial := certificate.Leaf.SerialNumber.Int64(); serial != 1 {
+		t.Errorf("certificate after the failed reload = %d, want the first kept", serial)
+	}
+
+	if _, err := newCertReloader(config.APITLSConfig{CertFile: settings.CertFile, KeyFile: filepath.Join(t.TempDir(), "missing.key")}, logr.Discard()); err == nil {
+		t.Errorf("newCertReloader without a key file succeeded")
+	}
+}
+
+func TestClientCertificateRequired(t *testing.T) {
+	settings := testTLSSettings(t)
+	server := newTestCertificate(t, 1, nil, x509.ExtKeyUsageServerAuth)
+	clientCA := newTestCertificate(t, 10, nil, x509.ExtKeyUsageClientAuth)
+	client := newTestCertificate(t, 11, clientCA, x509.ExtKeyUsageClientAuth)
+	writeServingCertificate(t, settings, server)
+	settings.ClientCAFile = filepath.Join(filepath.Dir(settings.CertFile), "ca.crt")
+	writeFile(t, settings.ClientCAFile, clientCA.certPEM())
+
+	reloader, err := newCertReloader(settings, logr.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	url := serveTLS(t, reloader)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.cert)
+
+	if _, err := tlsClient(&tls.Config{RootCAs: roots}).Get(url); err == nil {
+		t.Errorf("GET without a client certificate succeeded")
+	}
+	stranger := newTestCertificate(t, 12, nil, x509.ExtKeyUsageClientAuth)
+	if _, err := tlsClient(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{stranger.tlsCertificate(t)}}).Get(url); err == nil {
+		t.Errorf("GET with a client certificate of another CA succeeded")
+	}
+	get(t, tlsClient(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tlsCertificate(t)}}), url)
+}
+
+func TestTLSRequiresVersion12(t *testing.T) {
+	settings := testTLSSettings(t)
+	server := newTestCertificate(t, 1, nil, x509.ExtKeyUsageServerAuth)
+	writeServingCertificate(t, settings, server)
+	reloader, err := newCertReloader(settings, logr.Discard())
+	if err != nil {
+		t.Fatalf("newCertReloader: %v", err)
+	}
+	url := serveTLS(t, reloader)
+	roots := x509.NewCertPool()
+	roots.AddCert(server.cert)
+
+	old := tlsClient(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11})
+	if _, err := old.Get(url); err == nil {
+		t.Errorf("GET over TLS 1.1 succeeded")
+	}
+	get(t, tlsClient(&tls.Config{RootCAs: roots, MaxVersion: tls.VersionTLS12}), url)
+
+	if (config.APITLSConfig{}).Enabled() {
+		t.Errorf("TLS without a certificate is enabled, want plain HTTP")
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/collector"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+)
+
+// topologyLatencyTimeout bounds the latency queries of a topology request
+const topologyLatencyTimeout = 10 * time.Second
+
+// handleInfrastructureTopology returns the Mimir component graph, as JSON or as
+// Graphviz DOT with ?format=dot
+func (s *Server) handleInfrastructureTopology(w http.ResponseWriter, r *http.Request) {
+	namespace, err := s.scanNamespace(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		s.writeError(w, http.StatusBadRequest, "format must be json or dot")
+		return
+	}
+
+	infrastructure, err := s.infrastructureScanner().ScanInfrastructure(r.Context(), namespace)
+	if err != nil {
+		s.log.Error(err, "Failed to scan infrastructure topology")
+		s.writeError(w, http.StatusInternalServerError, "Failed to scan infrastructure")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), topologyLatencyTimeout)
+	defer cancel()
+	graph := discovery.BuildTopologyGraph(infrastructure, s.topologyLatency(ctx, namespace))
+
+	if format == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		_, _ = w.Write([]byte(graph.DOT()))
+		return
+	}
+	s.writeJSON(w, graph)
+}
+
+// topologyLatency returns the p99 latency lookup for topology edges, querying the
+// configured metrics endpoint once per target component and protocol. It returns nil
+// when no metrics endpoint is configured.
+func (s *Server) topologyLatency(ctx context.Context, namespace string) discovery.EdgeLatencyFunc {
+	if s.config().MetricsEndpoint == "" {
+		return nil
+	}
+
+	var kubeClient kubernetes.Interface
+	if s.controller != nil {
+		kubeClient = s.controller.KubeClient
+	}
+	mimirCollector := collector.NewMimirCollector(s.live, kubeClient, s.log.WithName("topology"))
+
+	type latencyKey struct{ component, protocol string }
+	type latencyResult struct {
+		value float64
+		known bool
+	}
+	latencies := make(map[latencyKey]latencyResult)
+
+	return func(target discovery.TopologyNode, protocol string) (float64, bool) {
+		key := latencyKey{component: target.ComponentType, protocol: protocol}
+		if result, queried := latencies[key]; queried {
+			return result.value, result.known
+		}
+		latencies[key] = latencyResult{}
+
+		// gRPC routes are method paths such as /cortex.Ingester/Push
+		route := `route=~"/.*"`
+		if protocol == "http" {
+			route = `route!~"/.*"`
+		}
+		query := fmt.Sprintf(`histogram_quantile(0.99, sum by (le) (rate(cortex_request_duration_seconds_bucket{namespace=%q,container=%q,%s}[5m]))) * 1000`,
+			namespace, target.ComponentType, route)
+
+		now := time.Now()
+		data, err := mimirCollector.QueryHistoricalData(ctx, query, now, now, time.Minute)
+		if err != nil {
+			s.log.V(1).Info("failed to query topology edge latency", "component", target.ComponentType, "protocol", protocol, "error", err)
+			return 0, false
+		}
+		if len(data) == 0 || math.IsNaN(data[len(data)-1].Value) {
+			return 0, false
+		}
+		latency := data[len(data)-1].Value
+		latencies[key] = latencyResult{value: latency, known: true}
+		return latency, true
+	}
+}
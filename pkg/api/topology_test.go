@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/config"
+	"github.com/AkshayDubey29/mimir-limit-optimizer/internal/discovery"
+)
+
+// newTopologyTestServer serves a Mimir namespace with a distributor and an ingester,
+// without a metrics endpoint to query latencies from
+func newTopologyTestServer() *Server {
+	cfg := config.GetDefaultConfig()
+	cfg.Mimir.Namespace = "mimir"
+	cfg.MetricsEndpoint = ""
+	s := newTestServer(cfg)
+	// The scan reads the cluster through the controller's client
+	s.controller.KubeClient = kubefake.NewSimpleClientset(
+		testDeployment("mimir", "distributor"),
+		testDeployment("mimir", "ingester"),
+	)
+	return s
+}
+
+func TestInfrastructureTopology(t *testing.T) {
+	s := newTopologyTestServer()
+
+	rec := serve(s, http.MethodGet, "/api/v1/infrastructure/topology", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var graph discovery.TopologyGraph
+	decodeJSON(t, rec, &graph)
+
+	found := false
+	for _, edge := range graph.Edges {
+		if edge.Source == "distributor" && edge.Target == "ingester" {
+			found = edge.Protocol == "grpc"
+		}
+	}
+	if !found {
+		t.Errorf("edges = %+v, want a grpc distributor -> ingester edge", graph.Edges)
+	}
+}
+
+func TestInfrastructureTopologyDOT(t *testing.T) {
+	s := newTopologyTestServer()
+
+	rec := serve(s, http.MethodGet, "/api/v1/infrastructure/topology?format=dot", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/vnd.graphviz") {
+		t.Errorf("Content-Type = %q, want text/vnd.graphviz", got)
+	}
+	dot := rec.Body.String()
+	if !strings.HasPrefix(dot, "digraph mimir {") || !strings.Contains(dot, `"distributor" -> "ingester" [label="grpc"];`) {
+		t.Errorf("DOT output =\n%s\nwant a digraph with the distributor -> ingester edge", dot)
+	}
+}
+
+func TestInfrastructureTopologyInvalidFormat(t *testing.T) {
+	rec := serve(newTopologyTestServer(), http.MethodGet, "/api/v1/infrastructure/topology?format=svg", "", nil)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
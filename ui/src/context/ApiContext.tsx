@@ -70,6 +70,7 @@ export interface AuditEntry {
   changes: Record<string, any>;
   success: boolean;
   error?: string;
+  reconcile_id?: number;
 }
 
 interface ApiContextType {
@@ -92,7 +93,7 @@ interface ApiContextType {
   // Testing
   triggerTestSpike: (tenantId: string, multiplier: number, duration: string) => Promise<void>;
   triggerTestAlert: (channel: string, message: string) => Promise<void>;
-  triggerReconcile: () => Promise<void>;
+  triggerReconcile: () => Promise<{ status: string; reconcile_id: number; }>;
   
   // State
   loading: boolean;